@@ -0,0 +1,182 @@
+// Command benchmark runs the engine subsystem benchmarks outside of go test and
+// compares them against a saved baseline to detect performance regressions.
+//
+//	benchmark -save baseline.json                 record a baseline
+//	benchmark -baseline baseline.json -threshold 15   fail if any case is >15% slower
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// Result is the outcome of one benchmark case
+type Result struct {
+	Name        string  `json:"name"`
+	Iterations  int     `json:"iterations"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+}
+
+// Baseline is a saved set of results
+type Baseline struct {
+	Recorded  time.Time `json:"recorded"`
+	GoVersion string    `json:"go_version"`
+	GOOS      string    `json:"goos"`
+	GOARCH    string    `json:"goarch"`
+	Results   []Result  `json:"results"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run parses flags, runs the selected cases and returns the exit code
+func run(args []string) int {
+	fs := flag.NewFlagSet("benchmark", flag.ContinueOnError)
+	filter := fs.String("run", "", "only run cases whose name matches this regular expression")
+	list := fs.Bool("list", false, "list case names and exit")
+	save := fs.String("save", "", "write results to this baseline file")
+	baselinePath := fs.String("baseline", "", "compare results against this baseline file")
+	threshold := fs.Float64("threshold", 10, "percent slowdown versus the baseline that counts as a regression")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// Engine debug output would swamp the results
+	logging.Default().SetDefaultLevel(logging.LevelError)
+
+	var pattern *regexp.Regexp
+	if *filter != "" {
+		var err error
+		if pattern, err = regexp.Compile(*filter); err != nil {
+			fmt.Fprintf(os.Stderr, "benchmark: invalid -run pattern: %v\n", err)
+			return 2
+		}
+	}
+
+	var baseline *Baseline
+	if *baselinePath != "" {
+		var err error
+		if baseline, err = loadBaseline(*baselinePath); err != nil {
+			fmt.Fprintf(os.Stderr, "benchmark: %v\n", err)
+			return 2
+		}
+	}
+
+	current := Baseline{
+		Recorded:  time.Now(),
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+	}
+
+	for _, c := range engine.BenchmarkCases() {
+		if pattern != nil && !pattern.MatchString(c.Name) {
+			continue
+		}
+		if *list {
+			fmt.Println(c.Name)
+			continue
+		}
+
+		r := testing.Benchmark(c.Run)
+		result := Result{
+			Name:        c.Name,
+			Iterations:  r.N,
+			NsPerOp:     float64(r.T.Nanoseconds()) / float64(max(r.N, 1)),
+			AllocsPerOp: r.AllocsPerOp(),
+			BytesPerOp:  r.AllocedBytesPerOp(),
+		}
+		current.Results = append(current.Results, result)
+		fmt.Printf("%-40s %10d %16.0f ns/op %10d B/op %8d allocs/op\n",
+			result.Name, result.Iterations, result.NsPerOp, result.BytesPerOp, result.AllocsPerOp)
+	}
+	if *list {
+		return 0
+	}
+
+	if *save != "" {
+		if err := saveBaseline(*save, &current); err != nil {
+			fmt.Fprintf(os.Stderr, "benchmark: %v\n", err)
+			return 2
+		}
+		fmt.Printf("\nBaseline written to %s\n", *save)
+	}
+
+	if baseline != nil && compare(baseline, &current, *threshold) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// compare prints the change of every case versus the baseline and returns the number of regressions
+func compare(baseline, current *Baseline, threshold float64) int {
+	previous := make(map[string]Result, len(baseline.Results))
+	for _, result := range baseline.Results {
+		previous[result.Name] = result
+	}
+
+	fmt.Printf("\nComparison with baseline from %s (%s %s/%s):\n",
+		baseline.Recorded.Format("2006-01-02 15:04"), baseline.GoVersion, baseline.GOOS, baseline.GOARCH)
+
+	regressions := make([]string, 0)
+	for _, result := range current.Results {
+		old, ok := previous[result.Name]
+		if !ok || old.NsPerOp == 0 {
+			fmt.Printf("%-40s %10s\n", result.Name, "new")
+			continue
+		}
+
+		change := (result.NsPerOp - old.NsPerOp) / old.NsPerOp * 100
+		status := ""
+		if change > threshold {
+			status = "  REGRESSION"
+			regressions = append(regressions, result.Name)
+		}
+		fmt.Printf("%-40s %+9.1f%%%s\n", result.Name, change, status)
+	}
+
+	if len(regressions) > 0 {
+		sort.Strings(regressions)
+		fmt.Printf("\n%d case(s) slower than the %.0f%% threshold: %v\n", len(regressions), threshold, regressions)
+	}
+	return len(regressions)
+}
+
+// loadBaseline reads a baseline file
+func loadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+// saveBaseline writes results as an indented JSON baseline file
+func saveBaseline(path string, baseline *Baseline) error {
+	content, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
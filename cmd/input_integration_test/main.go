@@ -1,14 +1,16 @@
+//go:build !js
+
 package main
 
 import (
 	"fmt"
 	"log"
-	"path/filepath"
 	"time"
 
 	"teraglest/internal/data"
 	"teraglest/internal/engine"
 	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/startup"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -222,8 +224,11 @@ func main() {
 	fmt.Println()
 
 	// Initialize asset manager
-	dataRoot := filepath.Join("megaglest-source", "data", "glest_game")
-	assetManager := data.NewAssetManager(filepath.Join(dataRoot, "techs", "megapack"))
+	dataRoot, err := startup.ParseDataRoot()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	assetManager := data.NewAssetManager(startup.TechTreeRoot(dataRoot, startup.DefaultTechTree))
 
 	// Create renderer
 	r, err := renderer.NewRenderer(assetManager, "Input Pipeline Test", 1024, 768)
@@ -234,7 +239,7 @@ func main() {
 
 	// Create game
 	gameSettings := engine.GameSettings{
-		TechTreePath:       filepath.Join(dataRoot, "techs", "megapack", "megapack.xml"),
+		TechTreePath:       startup.TechTreeFile(dataRoot, startup.DefaultTechTree),
 		MaxPlayers:         1,
 		GameSpeed:          1.0,
 		ResourceMultiplier: 1.0,
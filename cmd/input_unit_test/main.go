@@ -1,13 +1,15 @@
+//go:build !js
+
 package main
 
 import (
 	"fmt"
 	"log"
-	"path/filepath"
 
 	"teraglest/internal/data"
 	"teraglest/internal/engine"
 	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/startup"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -27,12 +29,11 @@ func (mih MockInputHandler) HandleKeyboard(window *glfw.Window, key glfw.Key, sc
 	fmt.Printf("   ⌨️  Mock: Keyboard key %d received\n", int(key))
 }
 
-func testScreenToWorldConversion() {
+func testScreenToWorldConversion(dataRoot string) {
 	fmt.Println("🧪 Testing Screen-to-World Conversion:")
 
 	// Create a renderer and camera for testing
-	dataRoot := filepath.Join("megaglest-source", "data", "glest_game")
-	assetManager := data.NewAssetManager(filepath.Join(dataRoot, "techs", "megapack"))
+	assetManager := data.NewAssetManager(startup.TechTreeRoot(dataRoot, startup.DefaultTechTree))
 
 	r, err := renderer.NewRenderer(assetManager, "Input Test", 800, 600)
 	if err != nil {
@@ -80,11 +81,10 @@ func testScreenToWorldConversion() {
 	fmt.Println("   ✅ Screen-to-world conversion functional!")
 }
 
-func testInputCallbackSetup() {
+func testInputCallbackSetup(dataRoot string) {
 	fmt.Println("🧪 Testing Input Callback Setup:")
 
-	dataRoot := filepath.Join("megaglest-source", "data", "glest_game")
-	assetManager := data.NewAssetManager(filepath.Join(dataRoot, "techs", "megapack"))
+	assetManager := data.NewAssetManager(startup.TechTreeRoot(dataRoot, startup.DefaultTechTree))
 
 	r, err := renderer.NewRenderer(assetManager, "Callback Test", 640, 480)
 	if err != nil {
@@ -143,14 +143,13 @@ func testCommandCreation() {
 	}
 }
 
-func testWorldCreation() {
+func testWorldCreation(dataRoot string) {
 	fmt.Println("🧪 Testing Minimal World Creation:")
 
-	dataRoot := filepath.Join("megaglest-source", "data", "glest_game")
-	assetManager := data.NewAssetManager(filepath.Join(dataRoot, "techs", "megapack"))
+	assetManager := data.NewAssetManager(startup.TechTreeRoot(dataRoot, startup.DefaultTechTree))
 
 	// Load tech tree
-	techTreePath := filepath.Join(dataRoot, "techs", "megapack", "megapack.xml")
+	techTreePath := startup.TechTreeFile(dataRoot, startup.DefaultTechTree)
 	techTree, err := data.LoadTechTree(techTreePath)
 	if err != nil {
 		fmt.Printf("   ❌ Failed to load tech tree: %v\n", err)
@@ -190,12 +189,17 @@ func main() {
 	fmt.Println("Testing individual input pipeline components")
 	fmt.Println()
 
+	dataRoot, err := startup.ParseDataRoot()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
 	// Test 1: Screen-to-world coordinate conversion
-	testScreenToWorldConversion()
+	testScreenToWorldConversion(dataRoot)
 	fmt.Println()
 
 	// Test 2: Input callback registration
-	testInputCallbackSetup()
+	testInputCallbackSetup(dataRoot)
 	fmt.Println()
 
 	// Test 3: Command creation
@@ -203,7 +207,7 @@ func main() {
 	fmt.Println()
 
 	// Test 4: Minimal world creation for command processor
-	testWorldCreation()
+	testWorldCreation(dataRoot)
 	fmt.Println()
 
 	fmt.Println("🎉 Input Pipeline Unit Tests Complete!")
@@ -1,3 +1,5 @@
+//go:build !js
+
 package main
 
 import (
@@ -9,6 +11,7 @@ import (
 	"teraglest/internal/data"
 	"teraglest/internal/engine"
 	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/startup"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -19,8 +22,11 @@ func main() {
 	fmt.Println()
 
 	// Initialize asset manager
-	dataRoot := filepath.Join("megaglest-source", "data", "glest_game")
-	assetManager := data.NewAssetManager(filepath.Join(dataRoot, "techs", "megapack"))
+	dataRoot, err := startup.ParseDataRoot()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	assetManager := data.NewAssetManager(startup.TechTreeRoot(dataRoot, startup.DefaultTechTree))
 
 	fmt.Printf("✅ AssetManager initialized with data root: %s\n", dataRoot)
 
@@ -1,14 +1,16 @@
+//go:build !js
+
 package main
 
 import (
 	"fmt"
 	"log"
-	"path/filepath"
 	"time"
 
 	"teraglest/internal/data"
 	"teraglest/internal/engine"
 	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/startup"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -19,8 +21,11 @@ func main() {
 	fmt.Println()
 
 	// Initialize asset manager
-	dataRoot := filepath.Join("megaglest-source", "data", "glest_game")
-	assetManager := data.NewAssetManager(filepath.Join(dataRoot, "techs", "megapack"))
+	dataRoot, err := startup.ParseDataRoot()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	assetManager := data.NewAssetManager(startup.TechTreeRoot(dataRoot, startup.DefaultTechTree))
 
 	fmt.Printf("✅ AssetManager initialized\n")
 
@@ -34,7 +39,7 @@ func main() {
 	fmt.Println("✅ Renderer initialized")
 
 	// Load tech tree
-	techTreePath := filepath.Join(dataRoot, "techs", "megapack", "megapack.xml")
+	techTreePath := startup.TechTreeFile(dataRoot, startup.DefaultTechTree)
 	techTree, err := data.LoadTechTree(techTreePath)
 	if err != nil {
 		log.Fatalf("Failed to load tech tree: %v", err)
@@ -0,0 +1,134 @@
+//go:build !js
+
+// Command modelviewer shows G3D models in a window for modders and for
+// debugging the G3D loader: orbit camera, animation playback, texture toggle,
+// and normal and bounding box display.
+//
+// Usage:
+//
+//	modelviewer model.g3d               # one model
+//	modelviewer path/to/units/worker    # every model under a directory
+//	modelviewer -unit tech/worker       # every model of a unit in the game data
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/startup"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+func main() {
+	flags := startup.RegisterFlags(flag.CommandLine)
+	unit := flag.String("unit", "", "show the models of a unit of the default tech tree, as faction/unit")
+	width := flag.Int("width", 1024, "window width")
+	height := flag.Int("height", 768, "window height")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <model.g3d | directory>\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	target := flag.Arg(0)
+	if *unit != "" {
+		dataRoot, err := flags.DiscoverDataRoot()
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		faction, name, found := strings.Cut(*unit, "/")
+		if !found {
+			log.Fatalf("-unit must be faction/unit, got %q", *unit)
+		}
+		target = filepath.Join(startup.TechTreeRoot(dataRoot, startup.DefaultTechTree), "factions", faction, "units", name)
+	}
+	if target == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	paths, err := findModels(target)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	context, err := renderer.NewRenderContext("Model viewer", *width, *height, false)
+	if err != nil {
+		log.Fatalf("Failed to create window: %v", err)
+	}
+	defer context.Destroy()
+
+	v, err := newViewer(context, paths)
+	if err != nil {
+		log.Fatalf("Failed to start viewer: %v", err)
+	}
+	defer v.destroy()
+	if v.model == nil {
+		log.Fatalf("None of the %d models could be loaded", len(paths))
+	}
+
+	printControls()
+	v.printStatus()
+	last := time.Now()
+	for !context.ShouldClose() {
+		now := time.Now()
+		v.update(now.Sub(last).Seconds())
+		last = now
+
+		v.render()
+		context.SwapBuffers()
+		glfw.PollEvents()
+	}
+}
+
+// findModels returns the G3D file at path, or every G3D file below a directory, sorted
+func findModels(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var paths []string
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(file), ".g3d") {
+			paths = append(paths, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", path, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no .g3d models found in %s", path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// printControls lists the keyboard and mouse controls
+func printControls() {
+	fmt.Println()
+	fmt.Println("=== CONTROLS ===")
+	fmt.Println("Left drag:   Orbit        Scroll: Zoom      R: Reset camera")
+	fmt.Println("Space:       Play/pause   Left/Right: Step frame   +/-: Speed")
+	fmt.Println("N/P:         Next/previous model (also PageDown/PageUp)")
+	fmt.Println("T: Textures  W: Wireframe  L: Normals  B: Bounding box  U: Z-up")
+	fmt.Println("C:           Next team color")
+	fmt.Println("ESC/Q:       Quit")
+	fmt.Println()
+}
@@ -0,0 +1,226 @@
+//go:build !js
+
+package main
+
+import (
+	"path/filepath"
+
+	"teraglest/internal/graphics"
+	"teraglest/internal/logging"
+	"teraglest/pkg/formats"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// floatsPerVertex is the interleaved layout uploaded per vertex: position, normal, texture coordinate
+const floatsPerVertex = 8
+
+// viewerMesh holds the GPU buffers of one G3D mesh. Vertex data is re-uploaded
+// whenever the animation frame changes, interpolating between key frames.
+type viewerMesh struct {
+	mesh        *formats.G3DMesh
+	vao         uint32
+	vbo         uint32
+	ebo         uint32
+	vertexCount int
+	frameCount  int
+	texture     *graphics.Texture // nil without a texture
+	twoSided    bool
+	vertices    []float32 // Interleaved vertex data of the uploaded frame
+	uploaded    float32   // Frame position of the uploaded data, -1 before the first upload
+}
+
+// viewerModel is a loaded G3D file
+type viewerModel struct {
+	path   string
+	g3d    *formats.G3DModel
+	meshes []*viewerMesh
+	bounds graphics.BoundingBox // Over every mesh and frame
+	frames int                  // Frames of the longest mesh animation
+}
+
+// loadViewerModel reads a G3D file and uploads its meshes
+func loadViewerModel(path string, textures *graphics.TextureManager) (*viewerModel, error) {
+	g3d, err := formats.LoadG3D(path)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &viewerModel{path: path, g3d: g3d, frames: 1}
+	first := true
+	for i := range g3d.Meshes {
+		mesh := &g3d.Meshes[i]
+		vm := newViewerMesh(mesh)
+		if len(mesh.TextureNames) > 0 {
+			texture, err := textures.FindTextureForModel(path, mesh.TextureNames)
+			if err != nil {
+				logging.Warnf(logging.CategoryRender, "No texture for mesh %s: %v", mesh.Name, err)
+			}
+			vm.texture = texture
+		}
+		model.meshes = append(model.meshes, vm)
+		if vm.frameCount > model.frames {
+			model.frames = vm.frameCount
+		}
+
+		for _, v := range mesh.Vertices {
+			point := mgl32.Vec3{v.X, v.Y, v.Z}
+			if first {
+				model.bounds = graphics.BoundingBox{Min: point, Max: point}
+				first = false
+				continue
+			}
+			for axis := 0; axis < 3; axis++ {
+				model.bounds.Min[axis] = min(model.bounds.Min[axis], point[axis])
+				model.bounds.Max[axis] = max(model.bounds.Max[axis], point[axis])
+			}
+		}
+	}
+	return model, nil
+}
+
+// name returns the file name of the model
+func (m *viewerModel) name() string {
+	return filepath.Base(m.path)
+}
+
+// cleanup releases the GPU buffers of the model
+func (m *viewerModel) cleanup() {
+	for _, mesh := range m.meshes {
+		gl.DeleteVertexArrays(1, &mesh.vao)
+		gl.DeleteBuffers(1, &mesh.vbo)
+		gl.DeleteBuffers(1, &mesh.ebo)
+	}
+}
+
+// newViewerMesh creates the GPU buffers of a mesh
+func newViewerMesh(mesh *formats.G3DMesh) *viewerMesh {
+	vm := &viewerMesh{
+		mesh:        mesh,
+		vertexCount: int(mesh.Header.VertexCount),
+		frameCount:  max(int(mesh.Header.FrameCount), 1),
+		twoSided:    mesh.TwoSided,
+		uploaded:    -1,
+	}
+	vm.vertices = make([]float32, vm.vertexCount*floatsPerVertex)
+
+	gl.GenVertexArrays(1, &vm.vao)
+	gl.GenBuffers(1, &vm.vbo)
+	gl.GenBuffers(1, &vm.ebo)
+	gl.BindVertexArray(vm.vao)
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, vm.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, max(len(vm.vertices), 1)*4, nil, gl.DYNAMIC_DRAW)
+	if len(mesh.Indices) > 0 {
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, vm.ebo)
+		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(mesh.Indices)*4, gl.Ptr(mesh.Indices), gl.STATIC_DRAW)
+	}
+
+	stride := int32(floatsPerVertex * 4)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(1, 3, gl.FLOAT, false, stride, gl.PtrOffset(3*4))
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(2, 2, gl.FLOAT, false, stride, gl.PtrOffset(6*4))
+	gl.EnableVertexAttribArray(2)
+
+	gl.BindVertexArray(0)
+	return vm
+}
+
+// setFrame uploads the vertices at an animation position, blending the two
+// nearest key frames; frame wraps around the mesh's frame count
+func (vm *viewerMesh) setFrame(frame float32) {
+	if vm.vertexCount == 0 {
+		return
+	}
+	frame = wrapFrame(frame, vm.frameCount)
+	if frame == vm.uploaded {
+		return
+	}
+	vm.uploaded = frame
+
+	current := int(frame)
+	next := (current + 1) % vm.frameCount
+	blend := frame - float32(current)
+	for i := 0; i < vm.vertexCount; i++ {
+		a, b := current*vm.vertexCount+i, next*vm.vertexCount+i
+		out := vm.vertices[i*floatsPerVertex:]
+		position := lerpVec(vm.mesh.Vertices, a, b, blend)
+		normal := lerpVec(vm.mesh.Normals, a, b, blend)
+		if normal.Len() > 0 {
+			normal = normal.Normalize()
+		}
+		copy(out, []float32{position.X(), position.Y(), position.Z(), normal.X(), normal.Y(), normal.Z()})
+		if i < len(vm.mesh.TexCoords) {
+			out[6], out[7] = vm.mesh.TexCoords[i].X, vm.mesh.TexCoords[i].Y
+		}
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, vm.vbo)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(vm.vertices)*4, gl.Ptr(vm.vertices))
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// normalLines returns line segments from each vertex of the uploaded frame along its normal
+func (vm *viewerMesh) normalLines(length float32) []float32 {
+	lines := make([]float32, 0, vm.vertexCount*6)
+	for i := 0; i < vm.vertexCount; i++ {
+		v := vm.vertices[i*floatsPerVertex:]
+		lines = append(lines, v[0], v[1], v[2],
+			v[0]+v[3]*length, v[1]+v[4]*length, v[2]+v[5]*length)
+	}
+	return lines
+}
+
+// boxLines returns the twelve edges of a bounding box as line segments
+func boxLines(box graphics.BoundingBox) []float32 {
+	corner := func(i int) mgl32.Vec3 {
+		c := box.Min
+		if i&1 != 0 {
+			c[0] = box.Max[0]
+		}
+		if i&2 != 0 {
+			c[1] = box.Max[1]
+		}
+		if i&4 != 0 {
+			c[2] = box.Max[2]
+		}
+		return c
+	}
+	var lines []float32
+	for i := 0; i < 8; i++ {
+		for _, bit := range []int{1, 2, 4} {
+			if i&bit == 0 {
+				a, b := corner(i), corner(i|bit)
+				lines = append(lines, a[0], a[1], a[2], b[0], b[1], b[2])
+			}
+		}
+	}
+	return lines
+}
+
+// wrapFrame maps an animation position into [0, frames)
+func wrapFrame(frame float32, frames int) float32 {
+	count := float32(frames)
+	for frame < 0 {
+		frame += count
+	}
+	for frame >= count {
+		frame -= count
+	}
+	return frame
+}
+
+// lerpVec blends two entries of a vector slice; missing entries are zero
+func lerpVec(values []formats.Vec3f, a, b int, t float32) mgl32.Vec3 {
+	var va, vb mgl32.Vec3
+	if a < len(values) {
+		va = mgl32.Vec3{values[a].X, values[a].Y, values[a].Z}
+	}
+	if b < len(values) {
+		vb = mgl32.Vec3{values[b].X, values[b].Y, values[b].Z}
+	}
+	return va.Mul(1 - t).Add(vb.Mul(t))
+}
@@ -0,0 +1,372 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"teraglest/internal/graphics"
+	"teraglest/internal/graphics/device"
+	"teraglest/internal/graphics/renderer"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Viewer defaults
+const (
+	defaultFPS      = 30.0 // Animation frames per second at speed 1
+	orbitSpeed      = 0.01 // Radians per pixel dragged
+	zoomStep        = 1.15 // Distance factor per scroll step
+	normalLength    = 0.05 // Normal line length relative to the model size
+	maxPitch        = 1.5  // Keeps the camera from flipping over the poles
+	defaultPitch    = 0.4
+	defaultYaw      = 0.6
+	fieldOfView     = 45.0
+	speedMultiplier = 1.5
+)
+
+const meshVertexShader = `#version 330 core
+layout (location = 0) in vec3 aPos;
+layout (location = 1) in vec3 aNormal;
+layout (location = 2) in vec2 aTexCoord;
+
+uniform mat4 model;
+uniform mat4 view;
+uniform mat4 projection;
+
+out vec3 normal;
+out vec2 texCoord;
+
+void main() {
+    gl_Position = projection * view * model * vec4(aPos, 1.0);
+    normal = mat3(model) * aNormal;
+    texCoord = aTexCoord;
+}`
+
+const meshFragmentShader = `#version 330 core
+in vec3 normal;
+in vec2 texCoord;
+
+uniform sampler2D diffuse;
+uniform bool useTexture;
+uniform vec3 color;
+uniform bool useTeamColor;
+uniform vec3 teamColor;
+
+out vec4 FragColor;
+
+void main() {
+    vec3 light = normalize(vec3(0.4, 1.0, 0.6));
+    float shade = 0.35 + 0.65 * max(dot(normalize(normal), light), 0.0);
+    vec4 base = useTexture ? texture(diffuse, texCoord) : vec4(color, 1.0);
+    if (useTeamColor) {
+        base = vec4(mix(teamColor, base.rgb, base.a), 1.0);
+    }
+    if (base.a < 0.1) {
+        discard;
+    }
+    FragColor = vec4(base.rgb * shade, base.a);
+}`
+
+const lineVertexShader = `#version 330 core
+layout (location = 0) in vec3 aPos;
+
+uniform mat4 model;
+uniform mat4 view;
+uniform mat4 projection;
+
+void main() {
+    gl_Position = projection * view * model * vec4(aPos, 1.0);
+}`
+
+const lineFragmentShader = `#version 330 core
+uniform vec3 color;
+out vec4 FragColor;
+
+void main() {
+    FragColor = vec4(color, 1.0);
+}`
+
+// viewer shows one model at a time with an orbit camera and animation playback
+type viewer struct {
+	context  *renderer.RenderContext
+	shaders  *renderer.ShaderManager
+	textures *graphics.TextureManager
+	paths    []string
+	current  int
+	model    *viewerModel
+
+	// Orbit camera around the model center
+	yaw, pitch, distance float32
+	dragging             bool
+	lastX, lastY         float64
+
+	// Animation playback
+	playing bool
+	speed   float32
+	frame   float32
+
+	// Display toggles
+	showTextures bool
+	wireframe    bool
+	showNormals  bool
+	showBounds   bool
+	zUp          bool // Rotate Z-up models upright
+	teamPlayer   int  // Player whose color custom color meshes show
+
+	lineVAO, lineVBO uint32
+}
+
+// newViewer creates a viewer for model files, showing the first
+func newViewer(context *renderer.RenderContext, paths []string) (*viewer, error) {
+	v := &viewer{
+		context:      context,
+		shaders:      renderer.NewShaderManager(device.NewGL()),
+		textures:     graphics.NewTextureManager(),
+		paths:        paths,
+		playing:      true,
+		speed:        1,
+		showTextures: true,
+		teamPlayer:   1,
+	}
+	if err := v.shaders.LoadShaderFromSource("mesh", meshVertexShader, meshFragmentShader); err != nil {
+		return nil, err
+	}
+	if err := v.shaders.LoadShaderFromSource("line", lineVertexShader, lineFragmentShader); err != nil {
+		return nil, err
+	}
+
+	gl.GenVertexArrays(1, &v.lineVAO)
+	gl.GenBuffers(1, &v.lineVBO)
+	gl.BindVertexArray(v.lineVAO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, v.lineVBO)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 3*4, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+	gl.BindVertexArray(0)
+
+	gl.Enable(gl.DEPTH_TEST)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.ClearColor(0.18, 0.2, 0.24, 1)
+
+	v.installCallbacks()
+	v.open(0)
+	return v, nil
+}
+
+// open shows the model at an index of the path list, wrapping around
+func (v *viewer) open(index int) {
+	count := len(v.paths)
+	index = (index%count + count) % count
+
+	model, err := loadViewerModel(v.paths[index], v.textures)
+	if err != nil {
+		fmt.Printf("Failed to load %s: %v\n", v.paths[index], err)
+		return
+	}
+	if v.model != nil {
+		v.model.cleanup()
+	}
+	v.model = model
+	v.current = index
+	v.frame = 0
+	v.resetCamera()
+
+	fmt.Printf("[%d/%d] %s\n", index+1, count, model.path)
+	model.g3d.PrintSummary()
+}
+
+// resetCamera frames the whole model
+func (v *viewer) resetCamera() {
+	v.yaw, v.pitch = defaultYaw, defaultPitch
+	size := v.model.bounds.Max.Sub(v.model.bounds.Min).Len()
+	v.distance = max(size*1.5, 1)
+}
+
+// installCallbacks connects the window input to the viewer controls
+func (v *viewer) installCallbacks() {
+	v.context.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		if action == glfw.Press || action == glfw.Repeat {
+			v.handleKey(w, key)
+		}
+	})
+	v.context.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		if button == glfw.MouseButtonLeft {
+			v.dragging = action == glfw.Press
+			v.lastX, v.lastY = w.GetCursorPos()
+		}
+	})
+	v.context.SetCursorPosCallback(func(w *glfw.Window, x, y float64) {
+		if v.dragging {
+			v.yaw -= float32(x-v.lastX) * orbitSpeed
+			v.pitch += float32(y-v.lastY) * orbitSpeed
+			v.pitch = mgl32.Clamp(v.pitch, -maxPitch, maxPitch)
+		}
+		v.lastX, v.lastY = x, y
+	})
+	v.context.SetScrollCallback(func(w *glfw.Window, xoff, yoff float64) {
+		v.distance *= float32(math.Pow(zoomStep, -yoff))
+	})
+}
+
+// handleKey applies a key press
+func (v *viewer) handleKey(w *glfw.Window, key glfw.Key) {
+	switch key {
+	case glfw.KeyEscape, glfw.KeyQ:
+		w.SetShouldClose(true)
+	case glfw.KeySpace:
+		v.playing = !v.playing
+	case glfw.KeyRight:
+		v.playing = false
+		v.frame = wrapFrame(float32(math.Floor(float64(v.frame)))+1, v.model.frames)
+	case glfw.KeyLeft:
+		v.playing = false
+		v.frame = wrapFrame(float32(math.Ceil(float64(v.frame)))-1, v.model.frames)
+	case glfw.KeyEqual, glfw.KeyKPAdd:
+		v.speed *= speedMultiplier
+	case glfw.KeyMinus, glfw.KeyKPSubtract:
+		v.speed /= speedMultiplier
+	case glfw.KeyPageDown, glfw.KeyN:
+		v.open(v.current + 1)
+	case glfw.KeyPageUp, glfw.KeyP:
+		v.open(v.current - 1)
+	case glfw.KeyT:
+		v.showTextures = !v.showTextures
+	case glfw.KeyW:
+		v.wireframe = !v.wireframe
+	case glfw.KeyL:
+		v.showNormals = !v.showNormals
+	case glfw.KeyB:
+		v.showBounds = !v.showBounds
+	case glfw.KeyU:
+		v.zUp = !v.zUp
+	case glfw.KeyC:
+		v.teamPlayer = v.teamPlayer%len(graphics.TeamColors) + 1
+	case glfw.KeyR:
+		v.resetCamera()
+	default:
+		return
+	}
+	v.printStatus()
+}
+
+// printStatus shows the playback and display state in the window title
+func (v *viewer) printStatus() {
+	state := "paused"
+	if v.playing {
+		state = fmt.Sprintf("playing x%.2f", v.speed)
+	}
+	v.context.GetWindow().SetTitle(fmt.Sprintf("Model viewer - %s - frame %d/%d %s",
+		v.model.name(), int(v.frame)+1, v.model.frames, state))
+}
+
+// update advances the animation by elapsed seconds
+func (v *viewer) update(elapsed float64) {
+	if v.playing && v.model.frames > 1 {
+		v.frame = wrapFrame(v.frame+float32(elapsed*defaultFPS)*v.speed, v.model.frames)
+	}
+}
+
+// render draws the current model and the enabled helpers
+func (v *viewer) render() {
+	width, height := v.context.GetWindow().GetFramebufferSize()
+	gl.Viewport(0, 0, int32(width), int32(height))
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	bounds := v.model.bounds
+	center := bounds.Min.Add(bounds.Max).Mul(0.5)
+	modelMatrix := mgl32.Translate3D(-center.X(), -center.Y(), -center.Z())
+	if v.zUp {
+		modelMatrix = mgl32.HomogRotate3DX(-math.Pi / 2).Mul4(modelMatrix)
+	}
+	eye := mgl32.Vec3{
+		v.distance * float32(math.Cos(float64(v.pitch))*math.Sin(float64(v.yaw))),
+		v.distance * float32(math.Sin(float64(v.pitch))),
+		v.distance * float32(math.Cos(float64(v.pitch))*math.Cos(float64(v.yaw))),
+	}
+	view := mgl32.LookAtV(eye, mgl32.Vec3{}, mgl32.Vec3{0, 1, 0})
+	aspect := float32(width) / float32(max(height, 1))
+	projection := mgl32.Perspective(mgl32.DegToRad(fieldOfView), aspect, v.distance*0.01, v.distance*10)
+
+	for _, name := range []string{"mesh", "line"} {
+		v.shaders.UseShader(name)
+		v.shaders.SetUniformMat4(name, "model", modelMatrix)
+		v.shaders.SetUniformMat4(name, "view", view)
+		v.shaders.SetUniformMat4(name, "projection", projection)
+	}
+
+	v.shaders.UseShader("mesh")
+	if v.wireframe {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	}
+	for _, mesh := range v.model.meshes {
+		mesh.setFrame(v.frame)
+		v.drawMesh(mesh)
+	}
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+
+	v.shaders.UseShader("line")
+	if v.showNormals {
+		length := bounds.Max.Sub(bounds.Min).Len() * normalLength
+		for _, mesh := range v.model.meshes {
+			v.drawLines(mesh.normalLines(length), mgl32.Vec3{0.2, 0.6, 1})
+		}
+	}
+	if v.showBounds {
+		v.drawLines(boxLines(bounds), mgl32.Vec3{1, 0.85, 0.2})
+	}
+}
+
+// drawMesh draws one mesh with its texture or diffuse color
+func (v *viewer) drawMesh(mesh *viewerMesh) {
+	if len(mesh.mesh.Indices) == 0 {
+		return
+	}
+	useTexture := v.showTextures && mesh.texture != nil
+	if useTexture {
+		mesh.texture.Bind(0)
+		v.shaders.SetUniformInt("mesh", "diffuse", 0)
+	}
+	v.shaders.SetUniformBool("mesh", "useTexture", useTexture)
+	v.shaders.SetUniformBool("mesh", "useTeamColor", useTexture && mesh.mesh.CustomColor)
+	v.shaders.SetUniformVec3("mesh", "teamColor", graphics.TeamColor(v.teamPlayer))
+	diffuse := mesh.mesh.Header.DiffuseColor
+	v.shaders.SetUniformVec3("mesh", "color", mgl32.Vec3{diffuse[0], diffuse[1], diffuse[2]})
+
+	if mesh.twoSided {
+		gl.Disable(gl.CULL_FACE)
+	} else {
+		gl.Enable(gl.CULL_FACE)
+	}
+	gl.BindVertexArray(mesh.vao)
+	gl.DrawElements(gl.TRIANGLES, int32(len(mesh.mesh.Indices)), gl.UNSIGNED_INT, gl.PtrOffset(0))
+	gl.BindVertexArray(0)
+	gl.Disable(gl.CULL_FACE)
+}
+
+// drawLines draws line segments in one color
+func (v *viewer) drawLines(lines []float32, color mgl32.Vec3) {
+	if len(lines) == 0 {
+		return
+	}
+	v.shaders.SetUniformVec3("line", "color", color)
+	gl.BindVertexArray(v.lineVAO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, v.lineVBO)
+	gl.BufferData(gl.ARRAY_BUFFER, len(lines)*4, gl.Ptr(lines), gl.STREAM_DRAW)
+	gl.DrawArrays(gl.LINES, 0, int32(len(lines)/3))
+	gl.BindVertexArray(0)
+}
+
+// destroy releases the GPU resources
+func (v *viewer) destroy() {
+	if v.model != nil {
+		v.model.cleanup()
+	}
+	gl.DeleteVertexArrays(1, &v.lineVAO)
+	gl.DeleteBuffers(1, &v.lineVBO)
+	v.textures.Cleanup()
+	v.shaders.Destroy()
+}
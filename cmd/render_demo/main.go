@@ -1,12 +1,14 @@
+//go:build !js
+
 package main
 
 import (
 	"fmt"
 	"log"
-	"path/filepath"
 
 	"teraglest/internal/data"
 	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/startup"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -16,8 +18,11 @@ func main() {
 	fmt.Println("================================================")
 
 	// Initialize asset manager
-	dataRoot := "/home/solifugus/development/teraglest/megaglest-source/data/glest_game"
-	assetManager := data.NewAssetManager(filepath.Join(dataRoot, "techs", "megapack"))
+	dataRoot, err := startup.ParseDataRoot()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	assetManager := data.NewAssetManager(startup.TechTreeRoot(dataRoot, startup.DefaultTechTree))
 
 	fmt.Printf("✅ AssetManager initialized with data root: %s\n", dataRoot)
 
@@ -31,7 +36,7 @@ func main() {
 	fmt.Println("✅ OpenGL renderer initialized")
 
 	// Create shader manager and load basic shaders
-	shaderMgr := renderer.NewShaderManager()
+	shaderMgr := renderer.NewShaderManager(r.Device())
 
 	// Load model shaders
 	err = shaderMgr.LoadShader("model",
@@ -1,17 +1,33 @@
+//go:build !js
+
 package main
 
 import (
+	"bufio"
+	"flag"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
+	"teraglest/internal/achievement"
 	"teraglest/internal/audio"
 	"teraglest/internal/data"
+	"teraglest/internal/debugdraw"
 	"teraglest/internal/engine"
+	"teraglest/internal/graphics"
 	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/logging"
+	"teraglest/internal/memstats"
+	"teraglest/internal/presence"
+	"teraglest/internal/profile"
+	"teraglest/internal/startup"
+	"teraglest/internal/tutorial"
 	"teraglest/internal/ui"
+	"teraglest/internal/userdata"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -22,11 +38,46 @@ type GameConfig struct {
 	WindowWidth    int
 	WindowHeight   int
 	DataRoot       string
+	TechTree       string // Tech tree in DataRoot/techs the match is played with
 	AudioEnabled   bool
 	VsyncEnabled   bool
 	TargetFPS      int
+	Tutorial       string // Tutorial scenario to play: a built-in ID or a JSON file ("" for a normal match)
+	GamepadEnabled bool   // Whether a connected gamepad drives the cursor, camera and commands
+	UnitUpkeep     bool   // Whether the match plays in upkeep mode, where large armies cost resources
+	Observer       bool   // Whether the player spectates with the observer panel, which ignores fog of war
+	HostAddress    string // Address to host a multiplayer lobby on ("" = single player)
+	JoinAddress    string // Address of a multiplayer lobby to join ("" = single player)
+
+	CommandLatency engine.LatencySettings // Artificial delay on the player's commands, for testing lag
+
+	DiscordClientID string // Discord application showing the player's rich presence ("" = off)
+
+	ModDir         string        // Directory of mod plugins to load ("" = no mods)
+	ModPermissions string        // Comma-separated permissions granted to mods (damage, units, commands, all, none)
+	ModHookBudget  time.Duration // Longest a mod hook may run before the mod is disabled (0 = no limit)
+
+	SoakWindow      int           // Match starts in a row memory must grow across to be flagged as a leak (0 = soak test mode off)
+	SoakMatchLength time.Duration // How long each match runs before the next starts in soak test mode (0 = until restarted by hand)
 }
 
+// localPlayerID is the player controlled on this machine; a multiplayer
+// launch sets it to the player of the local lobby slot
+var localPlayerID = 1
+
+// statsSampleInterval is how often match statistics are sampled for achievements
+const statsSampleInterval = time.Second
+
+// ambientRadius is how many tiles around the camera target make up the ambient sound mix
+const ambientRadius = 8
+
+// Log file settings
+const (
+	logFileName    = "teraglest.log"
+	logFileMaxSize = 10 * 1024 * 1024 // Rotate after 10 MB
+	logFileBackups = 5
+)
+
 // DefaultGameConfig returns a default configuration
 func DefaultGameConfig() GameConfig {
 	return GameConfig{
@@ -34,9 +85,11 @@ func DefaultGameConfig() GameConfig {
 		WindowWidth:    1024,
 		WindowHeight:   768,
 		DataRoot:       filepath.Join("megaglest-source", "data", "glest_game"),
+		TechTree:       startup.DefaultTechTree,
 		AudioEnabled:   true,
 		VsyncEnabled:   true,
 		TargetFPS:      60,
+		GamepadEnabled: true,
 	}
 }
 
@@ -48,8 +101,73 @@ type TeraGlest struct {
 	game         *engine.Game
 	world        *engine.World
 	inputHandler *ui.InputHandler
+	cameraCtrl   *ui.CameraControls
+	attackAlerts *ui.AttackAlertIndicator
+	gamepad      *ui.GamepadController
 	uiManager    *ui.SimpleUIManager
+	pauseMenu    *ui.PauseMenu
+	optionsPanel *ui.OptionsPanel
+	saveBrowser  *ui.SaveGameBrowser
 	audioManager *audio.AudioManager
+	music        *audio.MusicDirector
+	userPaths    userdata.Paths
+
+	// Rendering preferences from the user's config directory
+	graphicsSettings *graphics.GraphicsSettings
+
+	// Player profile
+	profiles      *profile.Store
+	profile       *profile.Profile
+	profileScreen *ui.ProfileScreen
+	matchStart    time.Time
+
+	// Multiplayer lobby shown before a networked match launches, and the
+	// lockstep session once it has
+	lobbyScreen *ui.LobbyScreen
+	netMatch    *netMatch
+
+	// Match statistics and achievements
+	statsRecorder *engine.StatsRecorder
+	achievements  *achievement.Tracker
+	notifier      *ui.AchievementNotifier
+	lastSample    time.Time
+
+	// Encyclopedia of the loaded tech tree and its model previews by path
+	encyclopedia  *ui.EncyclopediaScreen
+	previewModels map[string]*graphics.Model
+
+	// Resource trading at the player's market
+	marketPanel *ui.MarketPanel
+
+	// Tribute to allies
+	diplomacyPanel *ui.DiplomacyPanel
+
+	// Income and spending dashboard
+	economyPanel *ui.EconomyPanel
+
+	// Every player's production, value graphs and APM, for spectators and the post-game review
+	observerPanel *ui.ObserverPanel
+
+	// Buildings paused for lack of workers or energy
+	powerIndicator *ui.PowerIndicator
+
+	// Sprite HUD: stockpile across the top, the minimap under it, selection's
+	// commands and details in the corners, the popup of what is under the cursor, and renderer
+	// statistics and GPU memory while F2 stats are on
+	resourceBar        *ui.ResourceBar
+	commandCard        *ui.CommandCard
+	unitPanel          *ui.UnitPanel
+	buildingPanel      *ui.BuildingPanel
+	hoverInfo          *ui.HoverInfo
+	minimap            *ui.Minimap
+	performanceOverlay *ui.PerformanceOverlay
+
+	// Rich presence on Discord and other platforms (nil when off)
+	presence *presence.Presence
+
+	// Tutorial mode (nil in a normal match)
+	tutorial        *tutorial.Tutorial
+	tutorialOverlay *ui.TutorialOverlay
 
 	// Performance tracking
 	frameCount   int64
@@ -75,6 +193,41 @@ func NewTeraGlest(config GameConfig) (*TeraGlest, error) {
 		paused:        false,
 	}
 
+	// Resolve per-user directories for saves, logs and config
+	tg.userPaths = userdata.MustResolve()
+	if err := tg.userPaths.Ensure(); err != nil {
+		logging.Warnf(logging.CategoryGame, "%v", err)
+	}
+
+	// Mirror log output to a rotating file so long sessions can be diagnosed
+	logPath := tg.userPaths.LogFile(logFileName)
+	if err := logging.Default().EnableFileOutput(logPath, logFileMaxSize, logFileBackups); err != nil {
+		logging.Warnf(logging.CategoryGame, "File logging disabled: %v", err)
+	}
+
+	// Load the player profile; the game runs without one if the store is unusable
+	tg.profiles = profile.DefaultStore(tg.userPaths)
+	active, err := tg.profiles.Active(defaultProfileName())
+	if err != nil {
+		logging.Warnf(logging.CategoryGame, "Player profile unavailable: %v", err)
+		active = profile.New(defaultProfileName())
+	}
+	tg.profile = active
+
+	// Show what the player is doing on Discord when an application is configured
+	if config.DiscordClientID != "" {
+		tg.presence = presence.New(localPlayerID, presence.NewDiscordProvider(config.DiscordClientID, "logo"))
+	}
+
+	// Load the tutorial scenario before the match is set up for it
+	if config.Tutorial != "" {
+		scenario, err := loadTutorial(config.Tutorial)
+		if err != nil {
+			return nil, err
+		}
+		tg.tutorial = tutorial.NewTutorial(scenario)
+	}
+
 	// Initialize GLFW (done before other systems)
 	if err := tg.initializeGLFW(); err != nil {
 		return nil, fmt.Errorf("failed to initialize GLFW: %v", err)
@@ -93,11 +246,17 @@ func NewTeraGlest(config GameConfig) (*TeraGlest, error) {
 	// Initialize audio system
 	if config.AudioEnabled {
 		if err := tg.initializeAudio(); err != nil {
-			log.Printf("Warning: Audio initialization failed: %v", err)
+			logging.Warnf(logging.CategoryGame, "Audio initialization failed: %v", err)
 			// Continue without audio
 		}
 	}
 
+	// Report cache sizes to the memory stats, and watch them across matches in soak test mode
+	tg.registerMemoryStats()
+	if config.SoakWindow > 0 {
+		memstats.Default().EnableSoak(config.SoakWindow)
+	}
+
 	// Initialize game engine
 	if err := tg.initializeGame(); err != nil {
 		return nil, fmt.Errorf("failed to initialize game: %v", err)
@@ -108,10 +267,18 @@ func NewTeraGlest(config GameConfig) (*TeraGlest, error) {
 		return nil, fmt.Errorf("failed to initialize UI: %v", err)
 	}
 
-	log.Printf("TeraGlest initialized successfully")
-	log.Printf("  Window: %dx%d", config.WindowWidth, config.WindowHeight)
-	log.Printf("  Audio: %v", config.AudioEnabled)
-	log.Printf("  Target FPS: %d", config.TargetFPS)
+	// Gather the players of a multiplayer match in the lobby
+	if config.HostAddress != "" || config.JoinAddress != "" {
+		if err := tg.openLobby(); err != nil {
+			return nil, fmt.Errorf("failed to open the lobby: %v", err)
+		}
+	}
+
+	logging.Infof(logging.CategoryGame, "TeraGlest initialized successfully")
+	logging.Infof(logging.CategoryGame, "  Window: %dx%d", config.WindowWidth, config.WindowHeight)
+	logging.Infof(logging.CategoryGame, "  Audio: %v", config.AudioEnabled)
+	logging.Infof(logging.CategoryGame, "  Target FPS: %d", config.TargetFPS)
+	logging.Infof(logging.CategoryGame, "  User data: %s", tg.userPaths.Data)
 
 	return tg, nil
 }
@@ -130,10 +297,10 @@ func (tg *TeraGlest) initializeGLFW() error {
 
 // initializeAssetManager initializes the asset management system
 func (tg *TeraGlest) initializeAssetManager() error {
-	techPath := filepath.Join(tg.config.DataRoot, "techs", "megapack")
+	techPath := startup.TechTreeRoot(tg.config.DataRoot, tg.config.TechTree)
 	tg.assetManager = data.NewAssetManager(techPath)
 
-	log.Printf("Asset manager initialized with path: %s", techPath)
+	logging.Infof(logging.CategoryGame, "Asset manager initialized with path: %s", techPath)
 	return nil
 }
 
@@ -157,7 +324,21 @@ func (tg *TeraGlest) initializeRenderer() error {
 		glfw.SwapInterval(0) // Disable VSync
 	}
 
-	log.Printf("Renderer initialized: %dx%d", tg.config.WindowWidth, tg.config.WindowHeight)
+	// Apply graphics settings, writing the defaults on first run
+	path := tg.userPaths.ConfigFile("graphics_settings.json")
+	tg.graphicsSettings, err = graphics.LoadGraphicsSettings(path)
+	if err != nil {
+		logging.Warnf(logging.CategoryGame, "Using default graphics settings: %v", err)
+	} else if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+		if err := tg.graphicsSettings.Save(); err != nil {
+			logging.Warnf(logging.CategoryGame, "Failed to save graphics settings: %v", err)
+		}
+	}
+	if err := tg.renderer.ApplyGraphicsSettings(tg.graphicsSettings); err != nil {
+		logging.Warnf(logging.CategoryGame, "%v", err)
+	}
+
+	logging.Infof(logging.CategoryGame, "Renderer initialized: %dx%d", tg.config.WindowWidth, tg.config.WindowHeight)
 	return nil
 }
 
@@ -172,7 +353,12 @@ func (tg *TeraGlest) initializeAudio() error {
 		return err
 	}
 
-	log.Printf("Audio system initialized with mock backend")
+	// Music follows the fighting near the camera and base, and the match outcome
+	if musicManager := tg.audioManager.GetMusicManager(); musicManager != nil {
+		tg.music = audio.NewMusicDirector(musicManager, localPlayerID)
+	}
+
+	logging.Infof(logging.CategoryGame, "Audio system initialized with mock backend")
 	return nil
 }
 
@@ -180,12 +366,15 @@ func (tg *TeraGlest) initializeAudio() error {
 func (tg *TeraGlest) initializeGame() error {
 	// Create game settings
 	gameSettings := engine.GameSettings{
-		TechTreePath:       filepath.Join(tg.config.DataRoot, "techs", "megapack", "megapack.xml"),
+		TechTreePath:       startup.TechTreeFile(tg.config.DataRoot, tg.config.TechTree),
+		TechTree:           tg.config.TechTree,
+		DataRoot:           tg.config.DataRoot,
 		MaxPlayers:         1, // Start with single player
 		GameSpeed:          1.0,
 		ResourceMultiplier: 1.0,
+		UnitUpkeep:         tg.config.UnitUpkeep,
 		PlayerFactions: map[int]string{
-			1: "magic", // Default to magic faction
+			1: tg.playerFaction(),
 		},
 	}
 
@@ -196,11 +385,32 @@ func (tg *TeraGlest) initializeGame() error {
 		return fmt.Errorf("failed to create game: %v", err)
 	}
 
+	// Write crash bundles next to the log files
+	tg.game.SetCrashReportDir(tg.userPaths.Logs)
+
+	// Load the factions' unit sounds up front so their first plays do not hitch
+	if tg.audioManager != nil {
+		tg.preloadFactionSounds(gameSettings.PlayerFactions)
+	}
+
+	// Render the portraits mods do not ship as images now rather than mid-game
+	tg.generateFactionIcons(gameSettings.PlayerFactions)
+
 	// Start the game
 	err = tg.game.Start()
 	if err != nil {
 		return fmt.Errorf("failed to start game: %v", err)
 	}
+	return tg.setupMatch()
+}
+
+// setupMatch connects the client to the world of a match that has just
+// started: command latency, mods, statistics, ambience and unit sounds
+func (tg *TeraGlest) setupMatch() error {
+	tg.matchStart = time.Now()
+	if tg.presence != nil {
+		tg.presence.StartMatch(mapName(tg.game.GetSettings().MapPath), tg.playerFaction(), tg.matchStart)
+	}
 
 	// Get world reference
 	tg.world = tg.game.GetWorld()
@@ -208,12 +418,186 @@ func (tg *TeraGlest) initializeGame() error {
 		return fmt.Errorf("game world is nil after start")
 	}
 
-	log.Printf("Game initialized: World %dx%d", tg.world.Width, tg.world.Height)
+	// Simulate network lag on the player's commands when asked to
+	if tg.config.CommandLatency.Enabled() {
+		if processor, ok := tg.world.GetCommandProcessor().(*engine.CommandProcessor); ok {
+			if err := processor.SetLatency(tg.config.CommandLatency); err != nil {
+				return fmt.Errorf("invalid command latency: %v", err)
+			}
+			logging.Infof(logging.CategoryGame, "Delaying commands by %v (jitter %v)", tg.config.CommandLatency.Delay, tg.config.CommandLatency.Jitter)
+		}
+	}
+
+	// Load mods before the match starts running
+	if tg.config.ModDir != "" {
+		allowed, err := engine.ParseModPermissions(tg.config.ModPermissions, tg.config.ModHookBudget)
+		if err != nil {
+			return fmt.Errorf("invalid mod permissions: %v", err)
+		}
+		loaded, err := tg.world.LoadModPlugins(tg.config.ModDir, allowed)
+		if err != nil {
+			logging.Warnf(logging.CategoryGame, "Some mods failed to load: %v", err)
+		}
+		if len(loaded) > 0 {
+			logging.Infof(logging.CategoryGame, "Loaded mods: %s", strings.Join(loaded, ", "))
+		}
+	}
+
+	// Record match statistics from the starting state on
+	tg.statsRecorder = engine.NewStatsRecorder(tg.world)
+	tg.statsRecorder.Sample()
+
+	// Ambient sound comes from the map's tileset
+	if tg.audioManager != nil && tg.world.Map != nil && tg.world.Map.Tileset != nil {
+		tileset := tg.world.Map.Tileset
+		tg.audioManager.GetSpatialAudioManager().SetTilesetAmbience(tileset.AmbientSounds, tileset.BasePath)
+	}
+
+	// Footsteps, shots and blows sound on the animation frames that mark them
+	if tg.audioManager != nil {
+		tg.playAnimationSound(engine.MarkerFootstep, "footsteps", 0.3)
+		tg.playAnimationSound(engine.MarkerLaunch, "bow_attack", 0.8)
+		tg.playAnimationSound(engine.MarkerImpact, "sword_attack", 0.8)
+	}
+
+	logging.Infof(logging.CategoryGame, "Match started: World %dx%d", tg.world.Width, tg.world.Height)
+	tg.checkMemoryGrowth()
 	return nil
 }
 
+// registerMemoryStats reports the renderer's, audio's and pathfinding's
+// caches to the memory stats; the caches are looked up when a snapshot is
+// taken, so systems started later are included
+func (tg *TeraGlest) registerMemoryStats() {
+	stats := memstats.Default()
+	stats.Register("models", memstats.GPU, func() memstats.CacheStats {
+		residency := tg.renderer.Stats().Residency
+		return memstats.CacheStats{Entries: residency.Models, Bytes: residency.ModelBytes}
+	})
+	stats.Register("textures", memstats.GPU, func() memstats.CacheStats {
+		residency := tg.renderer.Stats().Residency
+		return memstats.CacheStats{Entries: residency.Textures, Bytes: residency.TextureBytes}
+	})
+	stats.Register("terrain", memstats.GPU, func() memstats.CacheStats {
+		terrain := tg.renderer.Stats().Terrain
+		return memstats.CacheStats{Entries: terrain.Chunks, Bytes: terrain.Bytes}
+	})
+	stats.Register("sounds", memstats.Host, func() memstats.CacheStats {
+		if tg.audioManager == nil {
+			return memstats.CacheStats{}
+		}
+		count, bytes := tg.audioManager.CachedSounds()
+		return memstats.CacheStats{Entries: count, Bytes: bytes}
+	})
+	stats.Register("paths", memstats.Host, func() memstats.CacheStats {
+		if tg.world == nil {
+			return memstats.CacheStats{}
+		}
+		paths := tg.world.PathStats()
+		return memstats.CacheStats{Entries: paths.Paths + paths.Nodes, Bytes: paths.Bytes}
+	})
+}
+
+// checkMemoryGrowth records the memory held at a match start in soak test
+// mode and warns about what has grown at every start in the window
+func (tg *TeraGlest) checkMemoryGrowth() {
+	for _, growth := range memstats.Default().MatchStarted() {
+		logging.Warnf(logging.CategoryGame, "Possible leak: %s over the last %d match starts", growth, tg.config.SoakWindow)
+	}
+}
+
+// playAnimationSound plays a sound where a unit's animation passes a marker
+func (tg *TeraGlest) playAnimationSound(marker, sound string, volume float32) {
+	tg.world.OnAnimationEvent(marker, func(event engine.AnimationEvent) {
+		position := audio.Vector3{X: float32(event.Position.X), Y: float32(event.Position.Y), Z: float32(event.Position.Z)}
+		if err := tg.audioManager.PlayCombatSound(sound, position, volume); err != nil {
+			logging.Debugf(logging.CategoryGame, "Failed to play %s sound: %v", marker, err)
+		}
+	})
+}
+
+// preloadFactionSounds loads the unit sounds of each faction in the match into the sound cache
+func (tg *TeraGlest) preloadFactionSounds(playerFactions map[int]string) {
+	preloaded := make(map[string]bool)
+	for _, faction := range playerFactions {
+		if preloaded[faction] {
+			continue
+		}
+		preloaded[faction] = true
+
+		stats, err := tg.assetManager.PreloadFactionSounds(faction)
+		if err != nil {
+			logging.Warnf(logging.CategoryGame, "Sound preload failed: %v", err)
+			continue
+		}
+		logging.Infof(logging.CategoryGame, "Preloaded %d/%d sounds of faction %s (%d KB) in %v",
+			stats.Loaded, stats.Sounds, faction, stats.Bytes/1024, stats.Elapsed.Round(time.Millisecond))
+	}
+}
+
+// generateFactionIcons makes the unit portraits of each faction in the match,
+// reusing the icons cached by earlier runs
+func (tg *TeraGlest) generateFactionIcons(playerFactions map[int]string) {
+	iconRenderer := tg.renderer.Icons()
+	if iconRenderer == nil {
+		return
+	}
+	iconRenderer.SetCacheDir(tg.userPaths.CacheDir("icons"))
+	generated := make(map[string]bool)
+	for _, faction := range playerFactions {
+		if generated[faction] {
+			continue
+		}
+		generated[faction] = true
+
+		count, err := iconRenderer.GenerateFactionIcons(faction)
+		if err != nil {
+			logging.Warnf(logging.CategoryGame, "Icon generation failed: %v", err)
+			continue
+		}
+		logging.Infof(logging.CategoryGame, "Prepared %d unit icons of faction %s", count, faction)
+	}
+}
+
 // initializeUI initializes the UI and input systems
 func (tg *TeraGlest) initializeUI() error {
+	// Create pause menu (opened with ESC)
+	tg.pauseMenu = ui.NewPauseMenu()
+	tg.optionsPanel = ui.NewOptionsPanel()
+	tg.saveBrowser = ui.NewSaveGameBrowser(tg.userPaths.Saves, tg.userPaths.Replays)
+	tg.setupPauseMenu()
+
+	// Create profile screen (opened from the pause menu)
+	tg.profileScreen = ui.NewProfileScreen(tg.profiles, tg.profile, tg.availableFactions())
+
+	// Track achievements of the active profile and announce unlocks
+	tg.notifier = ui.NewAchievementNotifier()
+	tg.trackAchievements()
+
+	// Create the encyclopedia from the loaded data (pause menu, or I on a selection)
+	book, err := data.BuildEncyclopedia(startup.TechTreeRoot(tg.config.DataRoot, tg.config.TechTree))
+	if err != nil {
+		logging.Warnf(logging.CategoryGame, "Encyclopedia unavailable: %v", err)
+		book = &data.Encyclopedia{TechTree: tg.config.TechTree}
+	}
+	tg.encyclopedia = ui.NewEncyclopediaScreen(book)
+	tg.previewModels = make(map[string]*graphics.Model)
+	tg.renderer.SetSceneOverlay(tg.renderEncyclopediaPreview)
+
+	tg.performanceOverlay = ui.NewPerformanceOverlay(tg.renderer)
+	tg.renderer.SetHUD(tg.drawHUD)
+
+	if err := tg.initializeMatchUI(); err != nil {
+		return err
+	}
+
+	logging.Infof(logging.CategoryGame, "UI and input systems initialized")
+	return nil
+}
+
+// initializeMatchUI creates the input handling, panels and HUD of the
+// current match's world; the menus and screens outside the match are kept
+func (tg *TeraGlest) initializeMatchUI() error {
 	// Create simple UI manager (without ImGui dependencies)
 	tg.uiManager = ui.NewSimpleUIManager(tg.world)
 
@@ -221,14 +605,326 @@ func (tg *TeraGlest) initializeUI() error {
 	tg.inputHandler = ui.NewInputHandler(tg.world, tg.uiManager)
 	tg.inputHandler.SetCamera(tg.renderer.GetCamera())
 	tg.inputHandler.SetScreenDimensions(tg.config.WindowWidth, tg.config.WindowHeight)
+	tg.inputHandler.SetCommandMarkers(tg.renderer.CommandMarkers())
+	tg.inputHandler.SetPicking(tg.renderer.Picking())
+	tg.inputHandler.SetPauseMenu(tg.pauseMenu)
+	tg.inputHandler.SetProfileScreen(tg.profileScreen)
+	tg.inputHandler.SetOptionsPanel(tg.optionsPanel)
+	tg.inputHandler.SetSaveGameBrowser(tg.saveBrowser)
+	tg.inputHandler.SetEncyclopediaScreen(tg.encyclopedia)
+
+	// Camera bookmarks, unit following and jumping to events
+	tg.cameraCtrl = ui.NewCameraControls(tg.renderer.GetCamera())
+	tg.inputHandler.SetCameraControls(tg.cameraCtrl)
+	tg.inputHandler.SetPauseToggle(tg.togglePause)
+
+	// Controller layer, idle until a gamepad is connected
+	if tg.config.GamepadEnabled {
+		tg.gamepad = ui.NewGamepadController(tg.renderer.GetContext().GetWindow(), tg.inputHandler,
+			tg.renderer.GetCamera(), ui.DefaultGamepadMapping())
+	}
+
+	// Minimap and screen-edge flashes for attacks on the local player
+	tileSize := tg.world.GetTileSize()
+	tg.attackAlerts = ui.NewAttackAlertIndicator(tg.renderer.GetCamera(),
+		float32(tg.world.Width)*tileSize, float32(tg.world.Height)*tileSize)
+
+	// Trade resources at a market with M
+	tg.marketPanel = ui.NewMarketPanel(tg.world, localPlayerID)
+	tg.inputHandler.SetMarketPanel(tg.marketPanel)
+
+	// Send tribute to allies with T
+	tg.diplomacyPanel = ui.NewDiplomacyPanel(tg.world, localPlayerID)
+	tg.inputHandler.SetDiplomacyPanel(tg.diplomacyPanel)
+
+	// Show the economy dashboard with E
+	tg.economyPanel = ui.NewEconomyPanel(tg.world, localPlayerID)
+	tg.inputHandler.SetEconomyPanel(tg.economyPanel)
+
+	// Spectators watch every player with O from the start; players get the
+	// panel once the match is decided
+	tg.observerPanel = ui.NewObserverPanel(tg.world, tg.statsRecorder)
+	if tg.config.Observer {
+		tg.inputHandler.SetObserverPanel(tg.observerPanel)
+		tg.observerPanel.Open()
+	}
+
+	// Announce buildings pausing for lack of workers or energy
+	tg.powerIndicator = ui.NewPowerIndicator()
+
+	// Draw the resource bar and command card with the sprite layer
+	resources, err := tg.assetManager.LoadResources()
+	if err != nil {
+		logging.Warnf(logging.CategoryGame, "Resource icons unavailable: %v", err)
+	}
+	tg.resourceBar = ui.NewResourceBar(tg.world, localPlayerID, resources)
+	tg.commandCard = ui.NewCommandCard(tg.world, localPlayerID, tg.uiManager)
+	tg.unitPanel = ui.NewUnitPanel(tg.world, localPlayerID, tg.uiManager)
+	tg.buildingPanel = ui.NewBuildingPanel(tg.world, localPlayerID, tg.uiManager)
+	tg.uiManager.AddPanel(tg.unitPanel)
+	tg.uiManager.AddPanel(tg.buildingPanel)
+	tg.hoverInfo = ui.NewHoverInfo(tg.world, localPlayerID, tg.inputHandler)
+	tg.minimap = ui.NewMinimap(tg.world, localPlayerID, tg.attackAlerts)
+	tg.minimap.Start()
+
+	// Keep own and selected units visible behind buildings
+	tg.renderer.SetSilhouettes(renderer.OwnedAndSelectedSilhouettes(localPlayerID, tg.uiManager.Selection().Contains))
+
+	// Show tutorial hints and let the player's actions complete its steps
+	if tg.tutorial != nil {
+		tg.tutorialOverlay = ui.NewTutorialOverlay(tg.tutorial.Scenario())
+		tg.tutorial.SetStepHandler(tg.tutorialOverlay.ShowStep)
+		tg.tutorial.SetFinishHandler(tg.tutorialOverlay.Finish)
+		tg.inputHandler.SetActionHandler(tg.tutorial.Notify)
+		tg.tutorial.Start(time.Now())
+	}
 
 	// Setup input callbacks in renderer
 	tg.renderer.SetupGameInputCallbacks(tg.inputHandler)
-
-	log.Printf("UI and input systems initialized")
 	return nil
 }
 
+// startNewMatch ends the current match and starts a fresh one with the same settings
+func (tg *TeraGlest) startNewMatch() error {
+	return tg.startMatch(tg.game.GetSettings())
+}
+
+// startMatch ends the current match and starts one with the given settings
+// in a new world, without restarting the process. Everything bound to the
+// old world is stopped or released first, so nothing leaks into the next
+// match; loaded models, textures and sounds stay cached.
+func (tg *TeraGlest) startMatch(settings engine.GameSettings) error {
+	tg.recordMatchResult()
+
+	// The next match is played locally; the network session ends with this one
+	if tg.netMatch != nil {
+		tg.netMatch.close()
+		tg.netMatch = nil
+	}
+
+	// Stop what still reads or plays the old world
+	tg.minimap.Stop()
+	if tg.audioManager != nil {
+		if err := tg.audioManager.EndMatch(); err != nil {
+			logging.Warnf(logging.CategoryGame, "%v", err)
+		}
+	}
+	tg.renderer.ReleaseWorld()
+
+	if err := tg.game.NewMatch(settings); err != nil {
+		return err
+	}
+
+	// Music moods and tutorial steps start over with the match
+	if tg.music != nil {
+		tg.music = audio.NewMusicDirector(tg.audioManager.GetMusicManager(), localPlayerID)
+	}
+	if tg.tutorial != nil {
+		tg.tutorial = tutorial.NewTutorial(tg.tutorial.Scenario())
+	}
+
+	if err := tg.setupMatch(); err != nil {
+		return err
+	}
+	return tg.initializeMatchUI()
+}
+
+// setupPauseMenu connects pause menu actions to the game
+func (tg *TeraGlest) setupPauseMenu() {
+	quickSavePath := tg.userPaths.SaveFile("quicksave" + engine.SaveGameExtension)
+
+	tg.pauseMenu.SetVisibilityHandler(func(open bool) {
+		if open {
+			tg.pauseGame()
+		} else {
+			tg.resumeGame()
+		}
+	})
+
+	tg.pauseMenu.SetHandler(ui.PauseMenuSaveGame, func() error {
+		if err := tg.game.SaveToFile(quickSavePath, "Quick save"); err != nil {
+			return err
+		}
+		logging.Infof(logging.CategoryGame, "Game saved to %s", quickSavePath)
+		return nil
+	})
+
+	tg.pauseMenu.SetHandler(ui.PauseMenuLoadGame, func() error {
+		tg.saveBrowser.Open()
+		return nil
+	})
+
+	tg.saveBrowser.SetLoadHandler(func(path string) error {
+		if err := tg.game.LoadFromFile(path); err != nil {
+			return err
+		}
+		// Selected objects belong to the replaced world state
+		tg.uiManager.ClearSelection()
+		logging.Infof(logging.CategoryGame, "Game loaded from %s", path)
+		return nil
+	})
+
+	tg.pauseMenu.SetHandler(ui.PauseMenuOptions, func() error {
+		tg.optionsPanel.SetOptions(tg.gameOptions())
+		tg.optionsPanel.Open()
+		return nil
+	})
+
+	tg.pauseMenu.SetHandler(ui.PauseMenuProfile, func() error {
+		tg.profileScreen.Open()
+		return nil
+	})
+
+	tg.pauseMenu.SetHandler(ui.PauseMenuEncyclopedia, func() error {
+		tg.encyclopedia.Open()
+		return nil
+	})
+
+	tg.pauseMenu.SetHandler(ui.PauseMenuSurrender, func() error {
+		return tg.game.GetWorld().RunChatCommand(localPlayerID, engine.ChatCommandSurrender, nil)
+	})
+
+	tg.pauseMenu.SetHandler(ui.PauseMenuRematch, func() error {
+		if err := tg.game.Rematch(); err != nil {
+			return err
+		}
+		// Selected objects belong to the finished match
+		tg.uiManager.ClearSelection()
+		if !tg.config.Observer {
+			tg.inputHandler.SetObserverPanel(nil)
+		}
+		logging.Infof(logging.CategoryGame, "Rematch started")
+		return nil
+	})
+
+	tg.pauseMenu.SetHandler(ui.PauseMenuNewMatch, func() error {
+		if err := tg.startNewMatch(); err != nil {
+			return err
+		}
+		logging.Infof(logging.CategoryGame, "New match started")
+		return nil
+	})
+
+	tg.pauseMenu.SetHandler(ui.PauseMenuQuitToMenu, func() error {
+		// There is no front-end menu yet, so leaving the match ends the session
+		tg.running = false
+		return nil
+	})
+}
+
+// Choices on the options panel
+var (
+	windowSizes  = [][2]int{{1024, 768}, {1280, 720}, {1366, 768}, {1600, 900}, {1920, 1080}}
+	frameRates   = []int{30, 60, 120, 144}
+	onOffChoices = []string{"Off", "On"}
+)
+
+// gameOptions returns the options panel's settings with their values in
+// effect; changing one applies it to the running game at once
+func (tg *TeraGlest) gameOptions() []*ui.Option {
+	sizes := make([]string, len(windowSizes))
+	currentSize := 0
+	for i, size := range windowSizes {
+		sizes[i] = fmt.Sprintf("%dx%d", size[0], size[1])
+		if size[0] == tg.config.WindowWidth && size[1] == tg.config.WindowHeight {
+			currentSize = i
+		}
+	}
+	rates := make([]string, len(frameRates))
+	currentRate := 0
+	for i, rate := range frameRates {
+		rates[i] = fmt.Sprintf("%d FPS", rate)
+		if rate == tg.config.TargetFPS {
+			currentRate = i
+		}
+	}
+	audioOn := tg.audioManager != nil && tg.audioManager.IsEnabled()
+
+	return []*ui.Option{
+		{Label: "Window", Values: sizes, Current: currentSize, Apply: func(index int) error {
+			width, height := windowSizes[index][0], windowSizes[index][1]
+			tg.renderer.GetContext().GetWindow().SetSize(width, height)
+			tg.renderer.ResizeViewport(width, height)
+			tg.inputHandler.SetScreenDimensions(width, height)
+			tg.config.WindowWidth, tg.config.WindowHeight = width, height
+			return nil
+		}},
+		{Label: "Audio", Values: onOffChoices, Current: boolChoice(audioOn), Apply: func(index int) error {
+			if tg.audioManager == nil {
+				return fmt.Errorf("audio failed to start")
+			}
+			tg.audioManager.SetEnabled(index == 1)
+			tg.config.AudioEnabled = index == 1
+			return nil
+		}},
+		{Label: "VSync", Values: onOffChoices, Current: boolChoice(tg.config.VsyncEnabled), Apply: func(index int) error {
+			glfw.SwapInterval(index)
+			tg.config.VsyncEnabled = index == 1
+			return nil
+		}},
+		{Label: "Frame rate", Values: rates, Current: currentRate, Apply: func(index int) error {
+			tg.config.TargetFPS = frameRates[index]
+			return nil
+		}},
+		{Label: "Post-processing", Values: onOffChoices, Current: boolChoice(tg.renderer.PostProcessingEnabled()), Apply: func(index int) error {
+			tg.graphicsSettings.PostProcessing = index == 1
+			if err := tg.renderer.ApplyGraphicsSettings(tg.graphicsSettings); err != nil {
+				return err
+			}
+			return tg.graphicsSettings.Save()
+		}},
+	}
+}
+
+// boolChoice returns the index of a setting's value in onOffChoices
+func boolChoice(on bool) int {
+	if on {
+		return 1
+	}
+	return 0
+}
+
+// pauseGame pauses the simulation while the pause menu is open; a network
+// match runs on
+func (tg *TeraGlest) pauseGame() {
+	if tg.netMatch == nil && tg.game.GetState() == engine.GameStatePlaying {
+		if err := tg.game.Pause(); err != nil {
+			logging.Errorf(logging.CategoryGame, "Failed to pause game: %v", err)
+		}
+	}
+	tg.paused = true
+}
+
+// resumeGame resumes the simulation after the pause menu closes
+func (tg *TeraGlest) resumeGame() {
+	if tg.netMatch == nil && tg.game.GetState() == engine.GameStatePaused {
+		if err := tg.game.Resume(); err != nil {
+			logging.Errorf(logging.CategoryGame, "Failed to resume game: %v", err)
+		}
+	}
+	tg.paused = false
+}
+
+// togglePause pauses or resumes the game without the pause menu; a single
+// player can keep giving orders, which run on resume
+func (tg *TeraGlest) togglePause() {
+	if tg.netMatch != nil {
+		logging.Infof(logging.CategoryNet, "A network match cannot be paused")
+		return
+	}
+	if tg.paused {
+		tg.resumeGame()
+		fmt.Println("Game resumed")
+		return
+	}
+	tg.pauseGame()
+	if tg.game.GetSettings().IsSinglePlayer() {
+		fmt.Println("Game paused - orders given now run on resume (P to resume)")
+	} else {
+		fmt.Println("Game paused (P to resume)")
+	}
+}
+
 // main entry point
 func main() {
 	// Print startup information
@@ -240,8 +936,39 @@ func main() {
 	// Create game configuration
 	config := DefaultGameConfig()
 
-	// TODO: Parse command line arguments to override config
-	// For now, use defaults
+	// Parse command line arguments and locate the game data
+	flags := startup.RegisterFlags(flag.CommandLine)
+	logSpec := flag.String("log-level", "info", "log levels, e.g. \"info\" or \"info,render=debug,ai=warn\"")
+	flag.BoolVar(&config.GamepadEnabled, "gamepad", config.GamepadEnabled, "drive the game with a connected gamepad")
+	flag.StringVar(&config.HostAddress, "host", "", "host a multiplayer lobby on this address, e.g. \":61357\"")
+	flag.StringVar(&config.JoinAddress, "join", "", "join the multiplayer lobby at this address, e.g. \"192.168.1.5:61357\"")
+	flag.BoolVar(&config.Observer, "observe", false, "spectate: watch every player's production, army and economy value with the observer panel (O)")
+	flag.BoolVar(&config.UnitUpkeep, "upkeep", false, fmt.Sprintf("play in upkeep mode, where armies above %d units cost resources every minute", engine.DefaultUpkeepFreeUnits))
+	flag.StringVar(&config.TechTree, "tech-tree", config.TechTree, "tech tree to play with, from the data directory's techs folder")
+	flag.StringVar(&config.Tutorial, "tutorial", "", "play a tutorial: "+strings.Join(tutorial.BuiltinIDs(), ", ")+" or a scenario .json file")
+	flag.DurationVar(&config.CommandLatency.Delay, "command-delay", 0, "delay every command by this long, to test how the game feels under network lag")
+	flag.DurationVar(&config.CommandLatency.Jitter, "command-jitter", 0, "vary the command delay randomly by up to this long either way")
+	flag.StringVar(&config.DiscordClientID, "discord-client-id", "", "show the map, faction and game phase as Discord rich presence of this application")
+	flag.StringVar(&config.ModDir, "mods", "", "load the mod plugins (.so files) in this directory")
+	flag.StringVar(&config.ModPermissions, "mod-permissions", "all", "permissions granted to mods: damage, units, commands, all or none, comma-separated")
+	flag.DurationVar(&config.ModHookBudget, "mod-hook-budget", 0, "disable a mod whose hook runs longer than this (single player only; 0 = no limit)")
+	flag.IntVar(&config.SoakWindow, "soak", 0, fmt.Sprintf("soak test mode: flag memory growing across this many match starts in a row (e.g. %d)", memstats.DefaultSoakWindow))
+	flag.DurationVar(&config.SoakMatchLength, "soak-match", 0, "in soak test mode, start a new match after each has run this long")
+	flag.Parse()
+	config.CommandLatency.Seed = time.Now().UnixNano()
+
+	if err := logging.Default().ApplySpec(*logSpec); err != nil {
+		log.Fatalf("Invalid --log-level: %v", err)
+	}
+
+	dataRoot, err := flags.DiscoverDataRoot()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	config.DataRoot = dataRoot
+	if _, err := data.FindTechTree(dataRoot, config.TechTree); err != nil {
+		log.Fatalf("Invalid --tech-tree: %v", err)
+	}
 
 	// Create and run game
 	game, err := NewTeraGlest(config)
@@ -261,31 +988,53 @@ func main() {
 // Run starts the main game loop
 func (tg *TeraGlest) Run() error {
 	defer tg.Cleanup()
+	defer tg.reportCrash()
 
-	log.Printf("Starting TeraGlest main game loop...")
+	logging.Infof(logging.CategoryGame, "Starting TeraGlest main game loop...")
 
 	// Display initial status
 	tg.printGameStatus()
 
-	// Calculate frame duration for target FPS
-	targetFrameTime := time.Duration(1000/tg.config.TargetFPS) * time.Millisecond
+	// Accept log verbosity commands typed into the terminal
+	go tg.readConsoleCommands()
 
 	// Main game loop
 	for tg.running && !tg.renderer.ShouldClose() {
 		frameStart := time.Now()
 
+		// Frame duration for the target FPS, which the options can change
+		targetFrameTime := time.Second / time.Duration(tg.config.TargetFPS)
+
 		// Update frame timing
 		tg.frameTime = frameStart.Sub(tg.lastFrameTime)
 		tg.lastFrameTime = frameStart
 
 		// Process window events (input)
 		glfw.PollEvents()
+		if tg.gamepad != nil {
+			tg.gamepad.Update(tg.frameTime)
+		}
+		if tg.lobbyScreen != nil {
+			tg.lobbyScreen.Update()
+		}
+
+		// A network match keeps running while the menus are open
+		if tg.netMatch != nil {
+			tg.netMatch.advance(tg.world, frameStart)
+		}
 
 		// Update game logic (if not paused)
 		if !tg.paused {
 			tg.updateGame(tg.frameTime)
 		}
 
+		// In soak test mode, play match after match to watch memory across them
+		if tg.config.SoakWindow > 0 && tg.config.SoakMatchLength > 0 && time.Since(tg.matchStart) >= tg.config.SoakMatchLength {
+			if err := tg.startNewMatch(); err != nil {
+				return fmt.Errorf("soak test match restart failed: %w", err)
+			}
+		}
+
 		// Render frame
 		tg.render()
 
@@ -302,7 +1051,7 @@ func (tg *TeraGlest) Run() error {
 		}
 	}
 
-	log.Printf("Main game loop ended")
+	logging.Infof(logging.CategoryGame, "Main game loop ended")
 	return nil
 }
 
@@ -325,25 +1074,162 @@ func (tg *TeraGlest) updateGame(deltaTime time.Duration) {
 		}
 		tg.audioManager.SetListenerPosition(position)
 
+		// Ambience follows the time of day and the terrain around the camera
+		if spatial := tg.audioManager.GetSpatialAudioManager(); spatial != nil && tg.world.Map != nil {
+			spatial.SetTimeOfDay(tg.world.Map.TimeOfDay(tg.world.GetGameTime().Seconds()))
+			spatial.SetEnvironmentMix(tg.world.Map.AmbientMixAt(float64(camera.Target.X()), float64(camera.Target.Z()), ambientRadius))
+		}
+
+		if tg.music != nil {
+			focus := []audio.Vector3{{X: camera.Target.X(), Y: camera.Target.Y(), Z: camera.Target.Z()}}
+			for _, building := range tg.world.ObjectManager.GetBuildingsForPlayer(localPlayerID) {
+				p := building.GetPosition()
+				focus = append(focus, audio.Vector3{X: float32(p.X), Y: float32(p.Y), Z: float32(p.Z)})
+			}
+			tg.music.SetFocus(focus...)
+			tg.music.Update(time.Now())
+		}
+
 		// Note: AudioManager uses internal update loop
 	}
 
 	// Process any game events for audio
 	tg.processAudioEvents()
+
+	// Feed match statistics to the achievements
+	tg.processGameEvents()
+	if time.Since(tg.lastSample) >= statsSampleInterval {
+		tg.lastSample = time.Now()
+		tg.statsRecorder.Sample()
+		tg.achievements.Update(tg.statsRecorder.Stats(localPlayerID))
+		if won, lost := tg.matchOutcome(); won || lost {
+			if tg.music != nil {
+				tg.music.SetOutcome(won)
+			}
+			if tg.presence != nil {
+				tg.presence.SetOutcome(won, time.Now())
+			}
+		}
+	}
+	if tg.presence != nil {
+		tg.presence.Update(tg.world.GetGameTime(), time.Now())
+	}
+	if tg.tutorial != nil {
+		tg.tutorial.Update(tg.statsRecorder.Stats(localPlayerID), time.Now())
+	}
+}
+
+// processGameEvents passes queued game events to the statistics recorder,
+// remembers where the local player's latest event happened, flashes
+// attacks on the local player, announces tribute from allies, marks
+// buildings paused for lack of workers or energy, warns when the local
+// player has lost the means to recover and offers a rematch once the
+// match is decided for it
+func (tg *TeraGlest) processGameEvents() {
+	for _, event := range tg.game.GetEvents() {
+		tg.statsRecorder.HandleEvent(event)
+		if tg.music != nil {
+			tg.music.HandleGameEvent(event)
+		}
+		if tg.presence != nil {
+			tg.presence.HandleEvent(event)
+		}
+		if event.PlayerID == localPlayerID {
+			tg.attackAlerts.HandleEvent(event)
+			tg.diplomacyPanel.HandleEvent(event)
+			tg.powerIndicator.HandleEvent(event)
+			if assessment, ok := event.Data.(engine.SurrenderAssessment); ok && assessment.Hopeless {
+				logging.Warnf(logging.CategoryGame, "%s", event.Message)
+			}
+			if event.Type == engine.EventTypePlayerVictory || event.Type == engine.EventTypePlayerDefeated {
+				logging.Infof(logging.CategoryGame, "%s; review the match, then choose Rematch to play again", event.Message)
+				tg.pauseMenu.Open()
+				tg.inputHandler.SetObserverPanel(tg.observerPanel)
+				tg.observerPanel.Open()
+			}
+		}
+		if location, ok := event.Location(); ok && (event.PlayerID == localPlayerID || event.PlayerID < 0) {
+			tg.cameraCtrl.RecordEvent(location)
+		}
+	}
 }
 
 // render renders the current frame
 func (tg *TeraGlest) render() {
+	// The camera keeps following units while the game is paused
+	tg.cameraCtrl.Update(tg.frameTime)
+
+	// Ring the unit or building under the cursor
+	tg.hoverInfo.Highlight(tg.renderer.CommandMarkers())
+
 	// Render the world
 	err := tg.renderer.RenderWorld(tg.world)
 	if err != nil {
-		log.Printf("Render error: %v", err)
+		logging.Errorf(logging.CategoryGame, "Render error: %v", err)
 	}
 
 	// Render UI elements
 	tg.renderUI()
 }
 
+// drawHUD draws the sprite HUD over the frame
+func (tg *TeraGlest) drawHUD(canvas *renderer.HUDCanvas) {
+	tg.resourceBar.Draw(canvas)
+	tg.minimap.Draw(canvas)
+	tg.commandCard.Draw(canvas)
+	tg.unitPanel.Draw(canvas)
+	tg.buildingPanel.Draw(canvas)
+	tg.hoverInfo.Draw(canvas)
+	tg.encyclopedia.DrawPortrait(canvas)
+	tg.performanceOverlay.Draw(canvas)
+
+	// Menus go over everything else
+	tg.pauseMenu.Draw(canvas)
+	tg.optionsPanel.Draw(canvas)
+	tg.profileScreen.Draw(canvas)
+	tg.encyclopedia.Draw(canvas)
+	tg.marketPanel.Draw(canvas)
+	tg.diplomacyPanel.Draw(canvas)
+	tg.economyPanel.Draw(canvas)
+	tg.observerPanel.Draw(canvas)
+	if tg.lobbyScreen != nil {
+		tg.lobbyScreen.Draw(canvas)
+	}
+	if tg.netMatch != nil {
+		tg.netMatch.indicator.Draw(canvas)
+	}
+}
+
+// renderEncyclopediaPreview draws the model of the shown encyclopedia entry at
+// the point the camera looks at, turning slowly
+func (tg *TeraGlest) renderEncyclopediaPreview() {
+	entry := tg.encyclopedia.Current()
+	if entry == nil || entry.Model == "" {
+		return
+	}
+
+	model, cached := tg.previewModels[entry.Model]
+	if !cached {
+		// Failed loads are cached as nil so they are not retried every frame
+		g3d, err := tg.assetManager.LoadG3DModel(entry.Model)
+		if err == nil {
+			model, err = graphics.NewModelFromG3D(g3d)
+		}
+		if err != nil {
+			logging.Warnf(logging.CategoryRender, "No preview for %s: %v", entry.Name, err)
+		}
+		tg.previewModels[entry.Model] = model
+	}
+	if model == nil {
+		return
+	}
+
+	target := tg.renderer.GetCamera().Target
+	if err := tg.renderer.RenderModelTurntable(model, target.X(), target.Y(), target.Z(), tg.encyclopedia.PreviewAngle()); err != nil {
+		logging.Debugf(logging.CategoryRender, "Preview render failed: %v", err)
+	}
+}
+
 // renderUI renders UI overlays
 func (tg *TeraGlest) renderUI() {
 	// TODO: Implement selection box rendering
@@ -355,6 +1241,25 @@ func (tg *TeraGlest) renderUI() {
 	// Render UI manager components
 	tg.uiManager.Render()
 
+	if tg.saveBrowser != nil {
+		tg.saveBrowser.Render()
+	}
+	if tg.notifier != nil {
+		tg.notifier.Render()
+	}
+	if tg.gamepad != nil {
+		tg.gamepad.Render()
+	}
+	if tg.attackAlerts != nil {
+		tg.attackAlerts.Render(tg.config.WindowWidth, tg.config.WindowHeight, time.Now())
+	}
+	if tg.tutorialOverlay != nil {
+		tg.tutorialOverlay.Render()
+	}
+	if tg.powerIndicator != nil {
+		tg.powerIndicator.Render()
+	}
+
 	// Render UI elements (health bars, resource counts, etc.)
 	tg.renderGameUI()
 }
@@ -372,7 +1277,7 @@ func (tg *TeraGlest) renderGameUI() {
 
 	// For now, just track selection count in console
 	if len(selectedUnits) > 0 && tg.frameCount%180 == 0 { // Every 3 seconds at 60fps
-		log.Printf("Selected units: %d", len(selectedUnits))
+		logging.Infof(logging.CategoryGame, "Selected units: %d", len(selectedUnits))
 	}
 }
 
@@ -448,7 +1353,7 @@ func (tg *TeraGlest) logPerformanceStats() {
 
 	selectedCount := len(tg.uiManager.GetSelectedUnits())
 
-	log.Printf("Performance: %.1f FPS | Units: %d | Buildings: %d | Selected: %d | Frame time: %.2fms",
+	logging.Infof(logging.CategoryGame, "Performance: %.1f FPS | Units: %d | Buildings: %d | Selected: %d | Frame time: %.2fms",
 		tg.currentFPS,
 		totalUnits,
 		totalBuildings,
@@ -477,22 +1382,46 @@ func (tg *TeraGlest) printGameStatus() {
 	fmt.Println()
 	fmt.Println("🎮 Controls:")
 	fmt.Println("  Left Click: Select unit or move selected units")
-	fmt.Println("  Right Click: Move/Attack/Gather command")
+	fmt.Println("  Shift/Ctrl/Alt+Click: Add unit, toggle unit, select only that unit")
+	fmt.Println("  Double Click: Select all visible units of that type")
+	fmt.Println("  Right Click: Move/Attack/Gather command, or staff a building that needs workers")
+	fmt.Println("  Alt+Right Click: Move straight across lava and other hazards")
 	fmt.Println("  Drag: Box selection")
 	fmt.Println("  Ctrl+A: Select all units")
 	fmt.Println("  S: Stop selected units")
 	fmt.Println("  H: Hold position")
-	fmt.Println("  P: Pause/Resume game")
-	fmt.Println("  ESC: Exit game")
+	fmt.Println("  P: Pause/Resume game (orders given while paused run on resume)")
+	fmt.Println("  Ctrl+F5..F8: Set camera bookmark, F5..F8: Jump to bookmark")
+	fmt.Println("  F: Follow selected unit, Space: Jump to last event")
+	fmt.Println("  M: Trade resources at your market")
+	fmt.Println("  T: Send tribute to an ally")
+	fmt.Println("  B: Drag out a wall with selected workers (Shift+B: gate)")
+	fmt.Println("  E: Economy dashboard (income, spending, stockpile trends)")
+	fmt.Println("  O: Observer panel (spectators, and every player once the match is decided)")
+	fmt.Println("  F4: Walkable/occupied tile overlay")
+	fmt.Println("  ESC: Pause menu (resume, save, load, options, quit)")
+	if tg.config.GamepadEnabled {
+		fmt.Println("  Gamepad: left stick cursor, right stick camera, triggers zoom, A select, B command")
+		fmt.Println("  Gamepad: hold LB + right stick for commands, RB adds, D-pad bookmarks, Back jumps to event")
+	}
+	fmt.Println("Console: type 'log status' or 'log level [category] <level>' in this terminal")
+	fmt.Printf("Console: 'debug <category|all> [on|off]' draws %v\n", debugdraw.Categories)
+	fmt.Println("Console: 'mem [dump|gc|soak]' shows cache sizes, heap and GPU memory")
+	fmt.Println("Console: 'save rename <name>' renames the file highlighted in Load Game")
 	fmt.Println("=== Game Running ===")
 	fmt.Println()
 }
 
 // Cleanup cleans up all game resources
 func (tg *TeraGlest) Cleanup() {
-	log.Printf("Cleaning up TeraGlest...")
+	logging.Infof(logging.CategoryGame, "Cleaning up TeraGlest...")
+
+	if tg.netMatch != nil {
+		tg.netMatch.close()
+	}
 
 	if tg.game != nil {
+		tg.recordMatchResult()
 		tg.game.Stop()
 	}
 
@@ -500,10 +1429,234 @@ func (tg *TeraGlest) Cleanup() {
 		tg.audioManager.Shutdown()
 	}
 
+	if tg.presence != nil {
+		tg.presence.Close()
+	}
+
+	if tg.minimap != nil {
+		tg.minimap.Stop()
+	}
+
 	if tg.renderer != nil {
 		tg.renderer.Destroy()
 	}
 
 	glfw.Terminate()
-	log.Printf("TeraGlest cleanup complete")
-}
\ No newline at end of file
+	logging.Infof(logging.CategoryGame, "TeraGlest cleanup complete")
+	logging.Default().Close()
+}
+
+// reportCrash writes a crash bundle if the main loop panics, then re-panics
+func (tg *TeraGlest) reportCrash() {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	if tg.game != nil {
+		path, err := tg.game.WriteCrashBundle(tg.userPaths.Logs, recovered)
+		if err != nil {
+			logging.Errorf(logging.CategoryGame, "Failed to write crash bundle: %v", err)
+		} else {
+			fmt.Printf("TeraGlest crashed. Please attach %s to your bug report.\n", path)
+		}
+	}
+	logging.Default().Close()
+
+	panic(recovered)
+}
+
+// readConsoleCommands applies "log ...", "debug ...", "mem ...", "profile ..." and "save ..." commands read from standard input
+func (tg *TeraGlest) readConsoleCommands() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "profile") {
+			tg.handleProfileCommand(strings.Fields(line))
+			continue
+		}
+		if strings.HasPrefix(line, "save") {
+			tg.handleSaveCommand(strings.Fields(line))
+			continue
+		}
+		if strings.HasPrefix(line, "mem") {
+			response, err := memstats.Default().HandleCommand(line)
+			if err != nil {
+				fmt.Printf("Console: %v\n", err)
+				continue
+			}
+			fmt.Printf("Console: %s\n", response)
+			continue
+		}
+		if strings.HasPrefix(line, "debug") {
+			response, err := debugdraw.Default().HandleCommand(line)
+			if err != nil {
+				fmt.Printf("Console: %v\n", err)
+				continue
+			}
+			fmt.Printf("Console: %s\n", response)
+			continue
+		}
+		if !strings.HasPrefix(line, "log") {
+			continue
+		}
+
+		response, err := logging.Default().HandleCommand(line)
+		if err != nil {
+			fmt.Printf("Console: %v\n", err)
+			continue
+		}
+		fmt.Printf("Console: %s\n", response)
+	}
+}
+// handleProfileCommand exports or imports the player profile:
+// "profile export <file>" or "profile import <file>"
+func (tg *TeraGlest) handleProfileCommand(fields []string) {
+	if len(fields) != 3 || tg.profileScreen == nil {
+		fmt.Println("Console: usage: profile export|import <file>")
+		return
+	}
+
+	var err error
+	switch fields[1] {
+	case "export":
+		err = tg.profileScreen.Export(fields[2])
+	case "import":
+		err = tg.profileScreen.Import(fields[2])
+		if err == nil {
+			tg.profile = tg.profileScreen.Profile()
+			tg.trackAchievements()
+		}
+	default:
+		err = fmt.Errorf("unknown profile command %q", fields[1])
+	}
+	if err != nil {
+		fmt.Printf("Console: %v\n", err)
+		return
+	}
+	fmt.Printf("Console: profile %s %s done\n", fields[1], fields[2])
+}
+
+// handleSaveCommand renames the savegame or replay highlighted in the
+// browser: "save rename <name>"
+func (tg *TeraGlest) handleSaveCommand(fields []string) {
+	if len(fields) < 3 || fields[1] != "rename" || tg.saveBrowser == nil {
+		fmt.Println("Console: usage: save rename <name>")
+		return
+	}
+	name := strings.Join(fields[2:], " ")
+	if err := tg.saveBrowser.Rename(name); err != nil {
+		fmt.Printf("Console: %v\n", err)
+		return
+	}
+	fmt.Printf("Console: renamed to %s\n", name)
+}
+
+// trackAchievements checks the achievements of the active profile from now on
+func (tg *TeraGlest) trackAchievements() {
+	tg.achievements = achievement.NewTracker(achievement.DefaultDefinitions(), tg.profile)
+	tg.achievements.SetUnlockHandler(tg.notifier.Notify)
+	tg.profileScreen.SetAchievementSource(tg.achievements.Statuses)
+}
+
+// playerFaction returns the faction the local player starts with
+func (tg *TeraGlest) playerFaction() string {
+	if tg.tutorial != nil && tg.tutorial.Scenario().Faction != "" {
+		return tg.tutorial.Scenario().Faction
+	}
+	if tg.profile != nil && tg.profile.PreferredFaction != "" {
+		return tg.profile.PreferredFaction
+	}
+	return "magic" // Default to magic faction
+}
+
+// loadTutorial reads a tutorial scenario: a JSON file or the ID of a built-in one
+func loadTutorial(name string) (*tutorial.Scenario, error) {
+	if strings.HasSuffix(name, ".json") {
+		return tutorial.LoadScenario(name)
+	}
+	return tutorial.Builtin(name)
+}
+
+// availableFactions lists the playable factions of the chosen tech tree,
+// including ones installed by mods
+func (tg *TeraGlest) availableFactions() []string {
+	assets := data.NewAssetManager(startup.TechTreeRoot(tg.config.DataRoot, tg.config.TechTree))
+	factions, err := assets.PlayableFactions()
+	if err != nil {
+		logging.Warnf(logging.CategoryGame, "Failed to list factions: %v", err)
+		return nil
+	}
+	return factions
+}
+
+// recordMatchResult adds the finished match to the player profile and checks
+// achievements. Matches without a decided outcome (quit while every side still
+// stands) count towards career statistics but not the win/loss record.
+func (tg *TeraGlest) recordMatchResult() {
+	if tg.world == nil || tg.profile == nil || tg.statsRecorder == nil {
+		return
+	}
+
+	tg.processGameEvents()
+	tg.statsRecorder.Sample()
+	won, lost := tg.matchOutcome()
+	if won || lost {
+		tg.statsRecorder.SetOutcome(localPlayerID, won)
+		tg.profile.RecordMatch(profile.MatchResult{
+			Faction:  tg.playerFaction(),
+			Map:      mapName(tg.game.GetSettings().MapPath),
+			Won:      won,
+			Duration: time.Since(tg.matchStart),
+			EndedAt:  time.Now(),
+		})
+	}
+	for _, unlocked := range tg.achievements.FinishMatch(tg.statsRecorder.Stats(localPlayerID)) {
+		logging.Infof(logging.CategoryGame, "Achievement unlocked: %s", unlocked.Name)
+	}
+
+	if err := tg.profiles.Save(tg.profile); err != nil {
+		logging.Warnf(logging.CategoryGame, "Failed to update profile statistics: %v", err)
+	}
+}
+
+// matchOutcome reports whether the local player has won, with every opponent
+// out of units and buildings, or lost all of their own
+func (tg *TeraGlest) matchOutcome() (won, lost bool) {
+	hasForces := func(playerID int) bool {
+		return len(tg.world.ObjectManager.GetUnitsForPlayer(playerID)) > 0 ||
+			len(tg.world.ObjectManager.GetBuildingsForPlayer(playerID)) > 0
+	}
+	opponents, opponentsStanding := 0, 0
+	for playerID := range tg.world.GetAllPlayers() {
+		if playerID == localPlayerID {
+			continue
+		}
+		opponents++
+		if hasForces(playerID) {
+			opponentsStanding++
+		}
+	}
+
+	won = hasForces(localPlayerID) && opponents > 0 && opponentsStanding == 0
+	lost = !hasForces(localPlayerID)
+	return won, lost
+}
+
+// mapName returns the display name of a map file
+func mapName(mapPath string) string {
+	if mapPath == "" {
+		return "default"
+	}
+	return strings.TrimSuffix(filepath.Base(mapPath), filepath.Ext(mapPath))
+}
+
+// defaultProfileName names the profile created on first start after the OS user
+func defaultProfileName() string {
+	for _, variable := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(variable); name != "" {
+			return name
+		}
+	}
+	return "Player"
+}
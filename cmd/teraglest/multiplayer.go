@@ -0,0 +1,188 @@
+//go:build !js
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+	"teraglest/internal/network"
+	"teraglest/internal/startup"
+	"teraglest/internal/ui"
+)
+
+// Multiplayer lobby and lockstep settings
+const (
+	lobbySlots         = 4                     // Player slots of a hosted lobby
+	lobbyJoinTimeout   = 10 * time.Second      // How long joining waits for the host
+	netTickDuration    = 50 * time.Millisecond // Game time of one lockstep tick
+	netCheckpointTicks = 600                   // Ticks between checkpoints for rejoining guests
+	netMaxCatchUpTicks = 5                     // Ticks the host runs at most in one frame
+)
+
+// netMatch drives a launched multiplayer match in lockstep from the main
+// loop, in place of the game's own update loop: the host closes a tick every
+// netTickDuration and the guests run the ticks it sends
+type netMatch struct {
+	host      *network.SessionHost   // Set on the hosting machine
+	client    *network.SessionClient // Set on a guest
+	indicator *ui.ConnectionIndicator
+	nextTick  time.Time // When the host closes the next tick
+	ended     bool      // The guest lost the host
+}
+
+// newNetMatch takes over the lobby's connections for the launched match
+func newNetMatch(lobby network.LobbySession, info network.LaunchInfo) (*netMatch, error) {
+	match := &netMatch{indicator: ui.NewConnectionIndicator(info.Lobby), nextTick: time.Now()}
+	switch session := lobby.(type) {
+	case *network.LobbyHost:
+		match.host = session.Session()
+		match.host.SetStatusHandler(match.indicator.SetStatus)
+	case *network.LobbyGuest:
+		client, err := session.StartSession(info)
+		if err != nil {
+			return nil, err
+		}
+		match.client = client
+		match.client.SetStatusHandler(match.indicator.SetStatus)
+	default:
+		return nil, fmt.Errorf("unknown lobby session %T", lobby)
+	}
+	return match, nil
+}
+
+// advance runs the ticks due by now on the world
+func (nm *netMatch) advance(world *engine.World, now time.Time) {
+	if nm.host != nil {
+		nm.advanceHost(world, now)
+		return
+	}
+	nm.advanceGuest(world)
+}
+
+// advanceHost closes the ticks due by now, running their commands here as
+// the guests do, and checkpoints the world now and then for rejoins
+func (nm *netMatch) advanceHost(world *engine.World, now time.Time) {
+	if now.Sub(nm.nextTick) > netMaxCatchUpTicks*netTickDuration {
+		nm.nextTick = now // Too far behind, e.g. after a stall; skip rather than rush
+	}
+	for !now.Before(nm.nextTick) {
+		tick := nm.host.AdvanceTick()
+		network.ApplyTick(world, tick)
+		world.Update(netTickDuration)
+		if tick.Tick%netCheckpointTicks == 0 {
+			nm.host.Checkpoint(world.CaptureSaveGame())
+		}
+		nm.nextTick = nm.nextTick.Add(netTickDuration)
+	}
+}
+
+// advanceGuest runs the ticks received from the host so far
+func (nm *netMatch) advanceGuest(world *engine.World) {
+	for !nm.ended {
+		select {
+		case tick := <-nm.client.Ticks():
+			network.ApplyTick(world, tick)
+			world.Update(netTickDuration)
+		case <-nm.client.Done():
+			nm.ended = true
+			if err := nm.client.Err(); err != nil {
+				logging.Errorf(logging.CategoryNet, "Match ended: %v", err)
+			} else {
+				logging.Infof(logging.CategoryNet, "Match ended: the host left")
+			}
+		default:
+			return
+		}
+	}
+}
+
+// close leaves the match, disconnecting the guests when hosting
+func (nm *netMatch) close() {
+	if nm.host != nil {
+		nm.host.Close()
+		return
+	}
+	nm.client.Close()
+}
+
+// openLobby hosts or joins the multiplayer lobby asked for on the command
+// line; it is shown over the paused match until the match launches or the
+// player leaves
+func (tg *TeraGlest) openLobby() error {
+	var session network.LobbySession
+	if tg.config.JoinAddress != "" {
+		guest, err := network.JoinLobby(tg.config.JoinAddress, tg.profile.Name, lobbyJoinTimeout)
+		if err != nil {
+			return err
+		}
+		logging.Infof(logging.CategoryNet, "Joined the lobby at %s", tg.config.JoinAddress)
+		session = guest
+	} else {
+		state := network.NewLobbyState(tg.profile.Name+"'s game", tg.config.TechTree, lobbySlots)
+		state.AvailableFactions = tg.availableFactions()
+		maps, err := engine.NewMapManager(tg.assetManager, tg.config.DataRoot).GetAvailableMaps()
+		if err != nil {
+			logging.Warnf(logging.CategoryNet, "No maps to offer: %v", err)
+		}
+		state.AvailableMaps = maps
+		if len(maps) > 0 {
+			state.MapName = maps[0]
+		}
+		state.SetUnitUpkeep(tg.config.UnitUpkeep)
+
+		host, err := network.NewLobbyHost(state, tg.profile.Name)
+		if err != nil {
+			return err
+		}
+		if err := host.Listen(tg.config.HostAddress); err != nil {
+			return err
+		}
+		logging.Infof(logging.CategoryNet, "Hosting a lobby on %s", host.Addr())
+		session = host
+	}
+
+	tg.lobbyScreen = ui.NewLobbyScreen(session)
+	tg.lobbyScreen.SetLaunchHandler(func(info network.LaunchInfo) {
+		tg.launchNetworkMatch(session, info)
+	})
+	tg.lobbyScreen.SetLeaveHandler(func() {
+		logging.Infof(logging.CategoryNet, "Left the lobby")
+		tg.resumeGame()
+	})
+	tg.inputHandler.SetLobbyScreen(tg.lobbyScreen)
+	tg.pauseGame()
+	return nil
+}
+
+// launchNetworkMatch starts the match agreed in the lobby, playing the
+// player the launch assigns to this machine; from then on the lockstep
+// session updates the world
+func (tg *TeraGlest) launchNetworkMatch(lobby network.LobbySession, info network.LaunchInfo) {
+	settings := info.Lobby.GameSettings()
+	settings.TechTree = info.Lobby.TechTree
+	settings.TechTreePath = startup.TechTreeFile(tg.config.DataRoot, info.Lobby.TechTree)
+	settings.DataRoot = tg.config.DataRoot
+	settings.MapPath = info.Lobby.MapName
+
+	localPlayerID = info.LocalPlayerID
+	if err := tg.startMatch(settings); err != nil {
+		logging.Errorf(logging.CategoryNet, "Failed to launch the match: %v", err)
+		return
+	}
+	match, err := newNetMatch(lobby, info)
+	if err != nil {
+		logging.Errorf(logging.CategoryNet, "Failed to join the launched match: %v", err)
+		return
+	}
+	tg.resumeGame()
+
+	// Only the lockstep ticks move the world from here on; pausing no longer stops them
+	if err := tg.game.Pause(); err != nil {
+		logging.Errorf(logging.CategoryNet, "Failed to stop the game's own updates: %v", err)
+	}
+	tg.netMatch = match
+	logging.Infof(logging.CategoryNet, "Match launched on %s as player %d", info.Lobby.MapName, localPlayerID)
+}
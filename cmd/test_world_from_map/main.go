@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"teraglest/internal/data"
 	"teraglest/internal/engine"
+	"teraglest/internal/startup"
 )
 
 func main() {
@@ -11,8 +12,12 @@ func main() {
 	fmt.Println("===============================================")
 
 	// Create AssetManager and load basic game data
-	dataRoot := "/home/solifugus/development/teraglest/megaglest-source/data/glest_game"
-	assetManager := data.NewAssetManager(dataRoot + "/techs/megapack")
+	dataRoot, err := startup.ParseDataRoot()
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+	assetManager := data.NewAssetManager(startup.TechTreeRoot(dataRoot, startup.DefaultTechTree))
 
 	// Load tech tree
 	techTree, err := assetManager.LoadTechTree()
@@ -27,6 +32,7 @@ func main() {
 	settings := engine.GameSettings{
 		PlayerFactions:     map[int]string{1: "romans", 2: "magic"},
 		MaxPlayers:        6,
+		DataRoot:          dataRoot,
 		ResourceMultiplier: 1.0,
 	}
 
@@ -0,0 +1,87 @@
+// Command tgbalance computes derived stats (DPS, cost efficiency, effective HP
+// per attack type, tech-tree depth) for every unit in a tech tree and writes
+// them as CSV or HTML to help modders balance factions.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"teraglest/internal/data"
+	"teraglest/internal/logging"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run parses arguments, analyzes the tech tree and returns the exit code
+func run(args []string) int {
+	fs := flag.NewFlagSet("tgbalance", flag.ContinueOnError)
+	output := fs.String("o", "-", "output file (- for stdout); the format defaults to the file extension")
+	format := fs.String("format", "", "output format: csv or html")
+	sortBy := fs.String("sort", "", "sort units by hp, total_cost, dps, hp_per_cost, dps_per_cost or tech_depth")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: tgbalance [flags] <tech tree directory>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	outputFormat := strings.ToLower(*format)
+	if outputFormat == "" {
+		outputFormat = "csv"
+		if ext := strings.ToLower(filepath.Ext(*output)); ext == ".html" || ext == ".htm" {
+			outputFormat = "html"
+		}
+	}
+	if outputFormat != "csv" && outputFormat != "html" {
+		fmt.Fprintf(os.Stderr, "tgbalance: unknown format %q\n", outputFormat)
+		return 2
+	}
+
+	logging.Default().SetDefaultLevel(logging.LevelError)
+
+	report, err := data.AnalyzeBalance(filepath.Clean(fs.Arg(0)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tgbalance: %v\n", err)
+		return 1
+	}
+	if *sortBy != "" {
+		if err := report.SortBy(*sortBy); err != nil {
+			fmt.Fprintf(os.Stderr, "tgbalance: %v\n", err)
+			return 2
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tgbalance: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if outputFormat == "html" {
+		err = report.WriteHTML(out)
+	} else {
+		err = report.WriteCSV(out)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tgbalance: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,319 @@
+// Command tgvalidate checks tech trees, factions, maps, tilesets and whole mod
+// directories for problems and reports them for modders and CI pipelines.
+//
+// Exit status: 0 when no issue reaches the -fail-on severity, 1 when one does,
+// 2 on usage errors.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// Exit codes
+const (
+	exitOK     = 0
+	exitIssues = 1
+	exitUsage  = 2
+)
+
+// Content kinds that can be validated
+const (
+	kindAuto     = "auto"
+	kindTechTree = "techtree"
+	kindFaction  = "faction"
+	kindMap      = "map"
+	kindTileset  = "tileset"
+	kindMod      = "mod"
+)
+
+// options holds the parsed command line
+type options struct {
+	kind        string
+	format      string
+	failOn      data.ValidationSeverity
+	minSeverity data.ValidationSeverity
+	dataRoot    string
+	graphPath   string
+	path        string
+	graph       *data.DependencyGraph // Collected when graphPath is set
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run parses arguments, validates the target and returns the exit code
+func run(args []string) int {
+	fs := flag.NewFlagSet("tgvalidate", flag.ContinueOnError)
+	kind := fs.String("kind", kindAuto, "content kind: auto, techtree, faction, map, tileset or mod")
+	format := fs.String("format", "text", "output format: text or json")
+	failOn := fs.String("fail-on", "error", "exit non-zero when an issue of this severity or worse is found: error, warning or info")
+	show := fs.String("show", "info", "only report issues of this severity or worse: error, warning or info")
+	dataRoot := fs.String("data", "", "data directory (glest_game) used to resolve map tilesets")
+	graphPath := fs.String("graph", "", "write the unit/upgrade/resource dependency graph to this file (.dot for Graphviz, - for stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: tgvalidate [flags] <path>")
+		fmt.Fprintln(fs.Output(), "Validates a tech tree, faction, map (.gbm/.mgm), tileset or mod directory.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	opts := options{
+		kind:      strings.ToLower(*kind),
+		format:    strings.ToLower(*format),
+		dataRoot:  *dataRoot,
+		graphPath: *graphPath,
+		path:      filepath.Clean(fs.Arg(0)),
+	}
+	if opts.graphPath != "" {
+		opts.graph = data.NewDependencyGraph()
+	}
+
+	var err error
+	if opts.failOn, err = data.ParseValidationSeverity(*failOn); err != nil {
+		fmt.Fprintf(os.Stderr, "tgvalidate: -fail-on: %v\n", err)
+		return exitUsage
+	}
+	if opts.minSeverity, err = data.ParseValidationSeverity(*show); err != nil {
+		fmt.Fprintf(os.Stderr, "tgvalidate: -show: %v\n", err)
+		return exitUsage
+	}
+	if opts.format != "text" && opts.format != "json" {
+		fmt.Fprintf(os.Stderr, "tgvalidate: unknown format %q\n", opts.format)
+		return exitUsage
+	}
+	if opts.graphPath == "-" && opts.format == "json" {
+		fmt.Fprintln(os.Stderr, "tgvalidate: -graph - would mix the graph into the JSON report; write it to a file")
+		return exitUsage
+	}
+
+	// Keep loader warnings out of machine-readable output
+	logging.Default().SetDefaultLevel(logging.LevelError)
+
+	if opts.kind == kindAuto {
+		opts.kind = detectKind(opts.path)
+	}
+
+	report, err := validate(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tgvalidate: %v\n", err)
+		return exitUsage
+	}
+
+	if opts.graph != nil {
+		if err := writeGraph(opts.graph, opts.graphPath); err != nil {
+			fmt.Fprintf(os.Stderr, "tgvalidate: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	shown := report.Filter(opts.minSeverity)
+	if opts.format == "json" {
+		if err := shown.WriteJSON(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "tgvalidate: %v\n", err)
+			return exitUsage
+		}
+	} else {
+		fmt.Printf("Validating %s: %s\n\n", opts.kind, opts.path)
+		shown.PrintReport()
+	}
+
+	if report.CountAtLeast(opts.failOn) > 0 {
+		return exitIssues
+	}
+	return exitOK
+}
+
+// validate dispatches to the validator for the chosen kind
+func validate(opts options) (*data.ValidationReport, error) {
+	switch opts.kind {
+	case kindTechTree:
+		return validateTechTree(opts.path, opts.graph), nil
+	case kindFaction:
+		return validateFaction(opts.path, opts.graph), nil
+	case kindMap:
+		return engine.ValidateMapFile(opts.path, opts.dataRoot), nil
+	case kindTileset:
+		return engine.ValidateTilesetDir(filepath.Dir(filepath.Dir(opts.path)), filepath.Base(opts.path)), nil
+	case kindMod:
+		return validateMod(opts.path, opts.dataRoot, opts.graph)
+	default:
+		return nil, fmt.Errorf("unknown kind %q", opts.kind)
+	}
+}
+
+// detectKind guesses the content kind from the path layout
+func detectKind(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".gbm" || ext == ".mgm" {
+		return kindMap
+	}
+
+	switch filepath.Base(filepath.Dir(path)) {
+	case "factions":
+		return kindFaction
+	case "tilesets":
+		return kindTileset
+	case "techs":
+		return kindTechTree
+	}
+
+	if isDir(filepath.Join(path, "factions")) && fileExists(data.TechTreeXMLPath(path)) {
+		return kindTechTree
+	}
+	return kindMod
+}
+
+// validateTechTree runs every rule against a tech tree directory, adding its
+// dependencies to graph when it is not nil
+func validateTechTree(techTreeRoot string, graph *data.DependencyGraph) *data.ValidationReport {
+	validator := data.NewDataValidator(techTreeRoot, data.NewAssetManager(techTreeRoot))
+	report, err := validator.ValidateAllData()
+	if graph != nil {
+		graph.Merge(validator.DependencyGraph())
+	}
+	if report == nil {
+		report = data.NewValidationReport()
+	}
+	if err != nil && len(report.Issues) == 0 {
+		report.AddIssue(data.ValidationIssue{
+			Severity: data.ValidationError,
+			Category: "Data Loading",
+			Message:  err.Error(),
+			File:     techTreeRoot,
+		})
+	}
+	return report
+}
+
+// validateFaction validates one faction directory (techs/<tree>/factions/<faction>)
+func validateFaction(factionDir string, graph *data.DependencyGraph) *data.ValidationReport {
+	techTreeRoot := filepath.Dir(filepath.Dir(factionDir))
+	factionName := filepath.Base(factionDir)
+
+	validator := data.NewDataValidator(techTreeRoot, data.NewAssetManager(techTreeRoot))
+	report, err := validator.ValidateFaction(factionName)
+	if graph != nil {
+		graph.Merge(validator.DependencyGraph())
+	}
+	if report == nil {
+		report = data.NewValidationReport()
+	}
+	if err != nil && len(report.Issues) == 0 {
+		report.AddIssue(data.ValidationIssue{
+			Severity: data.ValidationError,
+			Category: "Data Loading",
+			Message:  err.Error(),
+			File:     factionDir,
+		})
+	}
+	return report
+}
+
+// validateMod validates every tech tree, map and tileset in a mod directory laid
+// out like the MegaGlest data directory (techs/, maps/, tilesets/)
+func validateMod(modDir, dataRoot string, graph *data.DependencyGraph) (*data.ValidationReport, error) {
+	if !isDir(modDir) {
+		return nil, fmt.Errorf("%s is not a directory", modDir)
+	}
+
+	startTime := time.Now()
+	report := data.NewValidationReport()
+	found := false
+
+	// Resolve map tilesets against the mod first, then the main data directory
+	mapDataRoot := modDir
+	if dataRoot != "" && !isDir(filepath.Join(modDir, "tilesets")) {
+		mapDataRoot = dataRoot
+	}
+
+	for _, name := range subdirectories(filepath.Join(modDir, "techs")) {
+		found = true
+		report.Merge(validateTechTree(filepath.Join(modDir, "techs", name), graph))
+	}
+	for _, name := range subdirectories(filepath.Join(modDir, "tilesets")) {
+		found = true
+		report.Merge(engine.ValidateTilesetDir(modDir, name))
+	}
+
+	mapFiles, _ := filepath.Glob(filepath.Join(modDir, "maps", "*"))
+	sort.Strings(mapFiles)
+	for _, mapFile := range mapFiles {
+		ext := strings.ToLower(filepath.Ext(mapFile))
+		if ext == ".gbm" || ext == ".mgm" {
+			found = true
+			report.Merge(engine.ValidateMapFile(mapFile, mapDataRoot))
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%s contains no techs/, tilesets/ or maps/ content", modDir)
+	}
+
+	report.Duration = time.Since(startTime)
+	return report, nil
+}
+
+// writeGraph writes the dependency graph as DOT when path ends in .dot, as text otherwise
+func writeGraph(graph *data.DependencyGraph, path string) error {
+	out := os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create graph file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".dot") {
+		return graph.WriteDOT(out)
+	}
+	return graph.WriteText(out)
+}
+
+// subdirectories lists the directory names inside dir, sorted
+func subdirectories(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// fileExists reports whether path is an existing file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,110 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"sync"
+	"syscall/js"
+)
+
+// DOM mouse button numbers
+const (
+	mouseLeft  = 0
+	mouseRight = 2
+)
+
+// click is a mouse press in CSS pixels relative to the canvas
+type click struct {
+	X, Y   float64
+	Button int
+}
+
+// input collects browser keyboard, mouse and wheel events between frames
+type input struct {
+	mutex  sync.Mutex
+	keys   map[string]bool // Held keys by KeyboardEvent.code
+	clicks []click
+	wheel  float64 // Accumulated wheel delta, positive away from the user
+
+	handlers []js.Func // Kept alive while listening
+}
+
+// newInput listens for key events on the window and mouse events on the canvas
+func newInput(canvas js.Value) *input {
+	in := &input{keys: make(map[string]bool)}
+	window := js.Global()
+
+	in.listen(window, "keydown", func(event js.Value) {
+		in.keys[event.Get("code").String()] = true
+	})
+	in.listen(window, "keyup", func(event js.Value) {
+		delete(in.keys, event.Get("code").String())
+	})
+	in.listen(window, "blur", func(event js.Value) {
+		in.keys = make(map[string]bool)
+	})
+	in.listen(canvas, "mousedown", func(event js.Value) {
+		in.clicks = append(in.clicks, click{
+			X:      event.Get("offsetX").Float(),
+			Y:      event.Get("offsetY").Float(),
+			Button: event.Get("button").Int(),
+		})
+	})
+	in.listen(canvas, "wheel", func(event js.Value) {
+		event.Call("preventDefault")
+		in.wheel -= event.Get("deltaY").Float()
+	})
+	in.listen(canvas, "contextmenu", func(event js.Value) {
+		event.Call("preventDefault") // Right click gives orders
+	})
+	return in
+}
+
+// listen adds an event listener that runs with the input locked
+func (in *input) listen(target js.Value, event string, handle func(event js.Value)) {
+	handler := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		in.mutex.Lock()
+		defer in.mutex.Unlock()
+		handle(args[0])
+		return nil
+	})
+	in.handlers = append(in.handlers, handler)
+	target.Call("addEventListener", event, handler, map[string]interface{}{"passive": event != "wheel"})
+}
+
+// panDirection returns the camera pan direction from held WASD or arrow keys
+func (in *input) panDirection() (x, z float32) {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	if in.keys["KeyA"] || in.keys["ArrowLeft"] {
+		x--
+	}
+	if in.keys["KeyD"] || in.keys["ArrowRight"] {
+		x++
+	}
+	if in.keys["KeyW"] || in.keys["ArrowUp"] {
+		z--
+	}
+	if in.keys["KeyS"] || in.keys["ArrowDown"] {
+		z++
+	}
+	return x, z
+}
+
+// takeClicks returns the clicks since the last call
+func (in *input) takeClicks() []click {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	clicks := in.clicks
+	in.clicks = nil
+	return clicks
+}
+
+// takeWheel returns the wheel movement since the last call
+func (in *input) takeWheel() float64 {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	wheel := in.wheel
+	in.wheel = 0
+	return wheel
+}
@@ -0,0 +1,234 @@
+//go:build js && wasm
+
+// Command webclient runs a skirmish demo in the browser: the simulation runs
+// in WebAssembly and draws through the WebGL 2 device backend. Unit data is
+// fetched from a tech tree served with cmd/webserve; without one, built-in
+// units are used.
+//
+// Build with:
+//
+//	GOOS=js GOARCH=wasm go build -o cmd/webclient/teraglest.wasm ./cmd/webclient
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+	"time"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/device"
+	"teraglest/internal/logging"
+)
+
+const (
+	mapSize       = 48 // Tiles per side
+	unitsPerSide  = 6
+	maxFrameDelta = 100 * time.Millisecond // Longer frames (e.g. a hidden tab) are clamped
+)
+
+func main() {
+	document := js.Global().Get("document")
+	canvas := document.Call("getElementById", "teraglest")
+	status := document.Call("getElementById", "status")
+
+	if err := run(canvas); err != nil {
+		logging.Errorf(logging.CategoryGame, "Web client failed: %v", err)
+		if !status.IsNull() {
+			status.Set("textContent", err.Error())
+		}
+		return
+	}
+	if !status.IsNull() {
+		status.Set("textContent", "Left click selects, right click moves, WASD or arrows pan, wheel zooms")
+	}
+	select {} // Keep the module alive for the frame callbacks
+}
+
+// run sets up the device, world and input and starts the frame loop
+func run(canvas js.Value) error {
+	dev, err := device.NewWebGL(canvas)
+	if err != nil {
+		return err
+	}
+	view, err := newScene(dev)
+	if err != nil {
+		return fmt.Errorf("failed to create scene: %w", err)
+	}
+
+	unitDef := loadUnitDefinition(assetsURL())
+	world, err := newDemoWorld(unitDef)
+	if err != nil {
+		return fmt.Errorf("failed to create world: %w", err)
+	}
+	logging.Infof(logging.CategoryGame, "Web client running on %s with %d %s units", dev.Name(), 2*unitsPerSide, unitDef.Name)
+
+	game := &client{
+		world:    world,
+		commands: world.GetCommandProcessor().(*engine.CommandProcessor),
+		canvas:   canvas,
+		device:   dev,
+		scene:    view,
+		input:    newInput(canvas),
+		camera:   camera{X: mapSize / 2, Z: mapSize / 2, Zoom: 16},
+		selected: make(map[int]bool),
+	}
+	game.start()
+	return nil
+}
+
+// assetsURL returns the tech tree URL from the page's ?assets= parameter, empty if none
+func assetsURL() string {
+	location := js.Global().Get("location")
+	params := js.Global().Get("URLSearchParams").New(location.Get("search"))
+	assets := params.Call("get", "assets")
+	if assets.IsNull() {
+		return ""
+	}
+	return js.Global().Get("URL").New(assets.String(), location.Get("href")).Get("href").String()
+}
+
+// loadUnitDefinition fetches the first unit of the first faction of a served
+// tech tree, falling back to a built-in soldier
+func loadUnitDefinition(url string) *data.UnitDefinition {
+	fallback := data.NewSimpleUnit("soldier", 400, 4, "leather", map[string]int{"gold": 100})
+	if url == "" {
+		return fallback
+	}
+
+	provider := data.NewRemoteAssetProvider(url)
+	factions, err := provider.LoadFactions()
+	if err != nil || len(factions) == 0 {
+		logging.Warnf(logging.CategoryData, "No factions at %s, using built-in units: %v", url, err)
+		return fallback
+	}
+	names, err := provider.UnitNames(factions[0].Name)
+	if err != nil || len(names) == 0 {
+		logging.Warnf(logging.CategoryData, "Faction %s has no units, using built-in units", factions[0].Name)
+		return fallback
+	}
+	unitDef, err := provider.LoadUnit(factions[0].Name, names[0])
+	if err != nil {
+		logging.Warnf(logging.CategoryData, "Using built-in units: %v", err)
+		return fallback
+	}
+	return unitDef
+}
+
+// newDemoWorld creates a world with two armies facing each other
+func newDemoWorld(unitDef *data.UnitDefinition) (*engine.World, error) {
+	world, err := engine.NewHeadlessWorld(mapSize, mapSize)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < unitsPerSide; i++ {
+		z := float64(mapSize/2 - unitsPerSide + 2*i)
+		if _, err := world.ObjectManager.UnitManager.CreateUnit(1, unitDef.Name, engine.Vector3{X: 8, Z: z}, unitDef); err != nil {
+			return nil, err
+		}
+		if _, err := world.ObjectManager.UnitManager.CreateUnit(2, unitDef.Name, engine.Vector3{X: mapSize - 8, Z: z}, unitDef); err != nil {
+			return nil, err
+		}
+	}
+	return world, nil
+}
+
+// client ties the world to the browser: it steps the simulation and draws
+// once per animation frame
+type client struct {
+	world    *engine.World
+	commands *engine.CommandProcessor
+	canvas   js.Value
+	device   *device.WebGL
+	scene    *scene
+	input    *input
+	camera   camera
+	selected map[int]bool // Selected unit IDs
+
+	lastFrame float64 // Timestamp of the last frame, in milliseconds
+	frame     js.Func
+}
+
+// start schedules the first animation frame
+func (c *client) start() {
+	c.frame = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		c.tick(args[0].Float())
+		js.Global().Call("requestAnimationFrame", c.frame)
+		return nil
+	})
+	js.Global().Call("requestAnimationFrame", c.frame)
+}
+
+// tick handles input, advances the simulation and draws a frame
+func (c *client) tick(now float64) {
+	delta := time.Duration((now - c.lastFrame) * float64(time.Millisecond))
+	if c.lastFrame == 0 || delta > maxFrameDelta {
+		delta = maxFrameDelta
+	}
+	c.lastFrame = now
+
+	width, height := c.resize()
+	c.handleInput(delta, width, height)
+	c.world.Update(delta)
+
+	c.device.Viewport(width, height)
+	c.device.Clear(0.18, 0.24, 0.16)
+	c.scene.draw(c.world, c.camera, c.selected, width, height)
+}
+
+// resize matches the canvas backing store to its displayed size
+func (c *client) resize() (int, int) {
+	ratio := js.Global().Get("devicePixelRatio").Float()
+	width := int(c.canvas.Get("clientWidth").Float() * ratio)
+	height := int(c.canvas.Get("clientHeight").Float() * ratio)
+	if c.canvas.Get("width").Int() != width || c.canvas.Get("height").Int() != height {
+		c.canvas.Set("width", width)
+		c.canvas.Set("height", height)
+	}
+	return width, height
+}
+
+// handleInput pans and zooms the camera and turns clicks into selections and orders
+func (c *client) handleInput(delta time.Duration, width, height int) {
+	ratio := js.Global().Get("devicePixelRatio").Float()
+	x, z := c.input.panDirection()
+	c.camera.pan(x, z, delta)
+	c.camera.zoomBy(c.input.takeWheel())
+
+	for _, click := range c.input.takeClicks() {
+		target := c.camera.screenToWorld(click.X*ratio, click.Y*ratio, width, height)
+		switch click.Button {
+		case mouseLeft:
+			c.selectAt(target)
+		case mouseRight:
+			c.moveSelected(target)
+		}
+	}
+}
+
+// selectAt selects the player's unit nearest to a world position within its
+// selection radius, or nothing
+func (c *client) selectAt(target engine.Vector3) {
+	c.selected = make(map[int]bool)
+	nearest, best := 0, math.MaxFloat64
+	for id, unit := range c.world.ObjectManager.GetUnitsForPlayer(1) {
+		dx, dz := unit.Position.X-target.X, unit.Position.Z-target.Z
+		reach := unit.SelectionRadius() * float64(c.world.GetTileSize())
+		if distance := dx*dx + dz*dz; distance <= reach*reach && distance < best {
+			nearest, best = id, distance
+		}
+	}
+	if nearest != 0 {
+		c.selected[nearest] = true
+	}
+}
+
+// moveSelected orders the selected units to a world position
+func (c *client) moveSelected(target engine.Vector3) {
+	for id := range c.selected {
+		if err := c.commands.IssueCommand(id, engine.CreateMoveCommand(target, false)); err != nil {
+			logging.Warnf(logging.CategoryUI, "Move order for unit %d failed: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"math"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/device"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	panSpeed = 20.0 // Tiles per second
+	minZoom  = 4.0  // Pixels per tile
+	maxZoom  = 64.0
+)
+
+// camera looks straight down at the map; Zoom is in pixels per tile
+type camera struct {
+	X, Z float32
+	Zoom float32
+}
+
+// pan moves the camera in a direction for a frame
+func (c *camera) pan(x, z float32, delta time.Duration) {
+	step := panSpeed * float32(delta.Seconds())
+	c.X = clamp(c.X+x*step, 0, mapSize)
+	c.Z = clamp(c.Z+z*step, 0, mapSize)
+}
+
+// zoomBy zooms in for positive wheel movement and out for negative
+func (c *camera) zoomBy(wheel float64) {
+	if wheel != 0 {
+		c.Zoom = clamp(c.Zoom*float32(math.Pow(1.001, wheel)), minZoom, maxZoom)
+	}
+}
+
+// projection maps world X/Z to clip space, with Z growing down the screen
+func (c *camera) projection(width, height int) mgl32.Mat4 {
+	halfWidth := float32(width) / c.Zoom / 2
+	halfHeight := float32(height) / c.Zoom / 2
+	return mgl32.Ortho2D(c.X-halfWidth, c.X+halfWidth, c.Z+halfHeight, c.Z-halfHeight)
+}
+
+// screenToWorld converts a canvas pixel position to a world position on the ground
+func (c *camera) screenToWorld(x, y float64, width, height int) engine.Vector3 {
+	return engine.Vector3{
+		X: float64(c.X) + (x-float64(width)/2)/float64(c.Zoom),
+		Z: float64(c.Z) + (y-float64(height)/2)/float64(c.Zoom),
+	}
+}
+
+// clamp limits a value to [min, max]
+func clamp(value, min, max float32) float32 {
+	return float32(math.Max(float64(min), math.Min(float64(max), float64(value))))
+}
+
+// Team colors by player ID
+var teamColors = map[int]mgl32.Vec4{
+	1: {0.2, 0.45, 1.0, 1},
+	2: {0.95, 0.25, 0.2, 1},
+}
+
+var (
+	gridColor      = mgl32.Vec4{1, 1, 1, 0.08}
+	selectionColor = mgl32.Vec4{0.3, 1.0, 0.3, 1}
+	sceneLayout    = device.VertexLayout{2, 4} // Ground position, color
+)
+
+const sceneVertexShader = `#version 330 core
+layout (location = 0) in vec2 aPosition;
+layout (location = 1) in vec4 aColor;
+uniform mat4 uProjection;
+out vec4 vColor;
+void main() {
+	vColor = aColor;
+	gl_Position = uProjection * vec4(aPosition, 0.0, 1.0);
+}`
+
+const sceneFragmentShader = `#version 330 core
+in vec4 vColor;
+out vec4 FragColor;
+void main() {
+	FragColor = vColor;
+}`
+
+// scene draws the world from above: the tile grid, units as team colored
+// squares with health bars, and rings around the selection
+type scene struct {
+	device     device.GraphicsDevice
+	program    device.Program
+	projection int32
+	vao        device.VertexArray
+	vbo        device.Buffer
+
+	triangles []float32
+	lines     []float32
+}
+
+// newScene creates the scene's shader and vertex buffer
+func newScene(dev device.GraphicsDevice) (*scene, error) {
+	program, err := dev.CreateProgram(sceneVertexShader, sceneFragmentShader)
+	if err != nil {
+		return nil, err
+	}
+	vao, vbo := dev.CreateVertexArray(sceneLayout)
+	return &scene{
+		device:     dev,
+		program:    program,
+		projection: dev.UniformLocation(program, "uProjection"),
+		vao:        vao,
+		vbo:        vbo,
+	}, nil
+}
+
+// draw draws a frame of the world
+func (s *scene) draw(world *engine.World, view camera, selected map[int]bool, width, height int) {
+	s.triangles, s.lines = s.triangles[:0], s.lines[:0]
+
+	for i := 0; i <= mapSize; i++ {
+		s.line(float32(i), 0, float32(i), mapSize, gridColor)
+		s.line(0, float32(i), mapSize, float32(i), gridColor)
+	}
+	for playerID, color := range teamColors {
+		for id, unit := range world.ObjectManager.GetUnitsForPlayer(playerID) {
+			x, z := float32(unit.Position.X), float32(unit.Position.Z)
+			s.quad(x-0.4, z-0.4, x+0.4, z+0.4, color)
+			if unit.MaxHealth > 0 {
+				health := float32(unit.Health) / float32(unit.MaxHealth)
+				s.quad(x-0.4, z-0.6, x+0.4, z-0.5, mgl32.Vec4{0, 0, 0, 0.6})
+				s.quad(x-0.4, z-0.6, x-0.4+0.8*health, z-0.5, mgl32.Vec4{1 - health, health, 0, 1})
+			}
+			if selected[id] {
+				s.ring(x, z, 0.6, selectionColor)
+			}
+		}
+	}
+
+	previous := s.device.State()
+	s.device.SetState(device.OverlayState)
+	s.device.UseProgram(s.program)
+	s.device.SetUniformMat4(s.projection, view.projection(width, height))
+	s.flush(s.triangles, device.Triangles)
+	s.flush(s.lines, device.Lines)
+	s.device.SetState(previous)
+}
+
+// flush uploads and draws a batch of vertices
+func (s *scene) flush(vertices []float32, primitive device.Primitive) {
+	if len(vertices) == 0 {
+		return
+	}
+	s.device.UploadVertices(s.vbo, vertices, device.UsageStream)
+	s.device.Draw(s.vao, primitive, 0, len(vertices)/sceneLayout.Stride())
+}
+
+// quad adds a filled rectangle
+func (s *scene) quad(x0, z0, x1, z1 float32, color mgl32.Vec4) {
+	for _, corner := range [6][2]float32{{x0, z0}, {x1, z0}, {x1, z1}, {x0, z0}, {x1, z1}, {x0, z1}} {
+		s.triangles = append(s.triangles, corner[0], corner[1], color[0], color[1], color[2], color[3])
+	}
+}
+
+// line adds a line segment
+func (s *scene) line(x0, z0, x1, z1 float32, color mgl32.Vec4) {
+	s.lines = append(s.lines,
+		x0, z0, color[0], color[1], color[2], color[3],
+		x1, z1, color[0], color[1], color[2], color[3])
+}
+
+// ring adds a circle outline
+func (s *scene) ring(x, z, radius float32, color mgl32.Vec4) {
+	const segments = 16
+	for i := 0; i < segments; i++ {
+		a0 := 2 * math.Pi * float64(i) / segments
+		a1 := 2 * math.Pi * float64(i+1) / segments
+		s.line(x+radius*float32(math.Cos(a0)), z+radius*float32(math.Sin(a0)),
+			x+radius*float32(math.Cos(a1)), z+radius*float32(math.Sin(a1)), color)
+	}
+}
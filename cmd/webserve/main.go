@@ -0,0 +1,67 @@
+// Command webserve serves the browser client built from cmd/webclient, the
+// Go WebAssembly support script and optionally a tech tree for it to fetch.
+//
+//	GOOS=js GOARCH=wasm go build -o cmd/webclient/teraglest.wasm ./cmd/webclient
+//	go run ./cmd/webserve -techtree megaglest-source/data/glest_game/techs/megapack
+//
+// then open http://localhost:8080/?assets=assets/ (or / for built-in units).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"teraglest/internal/data"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	web := flag.String("web", "cmd/webclient", "directory holding index.html and teraglest.wasm")
+	techTree := flag.String("techtree", "", "tech tree directory to serve under /assets/")
+	flag.Parse()
+
+	if _, err := os.Stat(filepath.Join(*web, "teraglest.wasm")); err != nil {
+		fmt.Fprintf(os.Stderr, "No teraglest.wasm in %s; build it with GOOS=js GOARCH=wasm go build -o %s ./cmd/webclient\n",
+			*web, filepath.Join(*web, "teraglest.wasm"))
+		os.Exit(1)
+	}
+	wasmExec, err := findWasmExec()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir(*web)))
+	mux.HandleFunc("/wasm_exec.js", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, wasmExec)
+	})
+	if *techTree != "" {
+		if err := data.WriteAssetIndex(*techTree); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		mux.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.Dir(*techTree))))
+	}
+
+	fmt.Printf("Serving the web client on %s\n", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// findWasmExec returns the path of the wasm_exec.js matching this Go toolchain
+func findWasmExec() (string, error) {
+	for _, dir := range []string{"lib/wasm", "misc/wasm"} {
+		path := filepath.Join(runtime.GOROOT(), dir, "wasm_exec.js")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("wasm_exec.js not found under %s", runtime.GOROOT())
+}
@@ -0,0 +1,242 @@
+// Command worlddiff compares two savegames, or two per-tick state hash dumps
+// written by the determinism harness, and prints where they differ: players
+// and their resources, units, buildings, resource nodes, object IDs and the
+// combat RNG state. It is meant for tracking down desyncs and corrupt saves.
+//
+// Exit status: 0 when the inputs match, 1 when they differ, 2 on usage or
+// read errors.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitDiffs = 1
+	exitUsage = 2
+)
+
+// Input kinds that can be compared
+const (
+	kindAuto  = "auto"
+	kindSave  = "save"
+	kindTrace = "trace"
+)
+
+// tickDiff is a tick whose state hashes differ between two dumps
+type tickDiff struct {
+	Tick int    `json:"tick"`
+	A    string `json:"a"`
+	B    string `json:"b"`
+}
+
+// traceReport is the comparison of two hash dumps
+type traceReport struct {
+	TicksA         int        `json:"ticks_a"`
+	TicksB         int        `json:"ticks_b"`
+	FirstDivergent int        `json:"first_divergent"` // -1 when the common ticks match
+	DifferingTicks int        `json:"differing_ticks"`
+	Ticks          []tickDiff `json:"ticks,omitempty"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run parses arguments, compares the inputs and returns the exit code
+func run(args []string) int {
+	fs := flag.NewFlagSet("worlddiff", flag.ContinueOnError)
+	kind := fs.String("kind", kindAuto, "input kind: auto, save or trace")
+	format := fs.String("format", "text", "output format: text or json")
+	limit := fs.Int("max", 50, "report at most this many differences (0 = all)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: worlddiff [flags] <a> <b>")
+		fmt.Fprintln(fs.Output(), "Compares two savegames or two per-tick state hash dumps.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitUsage
+	}
+	pathA, pathB := fs.Arg(0), fs.Arg(1)
+	*kind = strings.ToLower(*kind)
+	*format = strings.ToLower(*format)
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "worlddiff: unknown format %q\n", *format)
+		return exitUsage
+	}
+	if *limit < 0 {
+		fmt.Fprintln(os.Stderr, "worlddiff: -max must not be negative")
+		return exitUsage
+	}
+
+	logging.Default().SetDefaultLevel(logging.LevelError)
+
+	if *kind == kindAuto {
+		var err error
+		if *kind, err = detectKind(pathA); err != nil {
+			fmt.Fprintf(os.Stderr, "worlddiff: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	var differ bool
+	var err error
+	switch *kind {
+	case kindSave:
+		differ, err = diffSaves(pathA, pathB, *format, *limit)
+	case kindTrace:
+		differ, err = diffTraces(pathA, pathB, *format, *limit)
+	default:
+		err = fmt.Errorf("unknown kind %q", *kind)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "worlddiff: %v\n", err)
+		return exitUsage
+	}
+	if differ {
+		return exitDiffs
+	}
+	return exitOK
+}
+
+// detectKind tells savegames, which are save containers or legacy JSON
+// objects, from hash dumps
+func detectKind(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	reader, err := engine.NewSaveFileReader(file)
+	if err != nil {
+		return "", err
+	}
+	if !reader.Legacy {
+		return kindSave, nil
+	}
+	start := make([]byte, 64)
+	n, _ := io.ReadFull(reader, start)
+	if bytes.HasPrefix(bytes.TrimSpace(start[:n]), []byte("{")) {
+		return kindSave, nil
+	}
+	return kindTrace, nil
+}
+
+// diffSaves prints the differences between two savegames
+func diffSaves(pathA, pathB, format string, limit int) (bool, error) {
+	saveA, err := engine.ReadSaveGame(pathA)
+	if err != nil {
+		return false, err
+	}
+	saveB, err := engine.ReadSaveGame(pathB)
+	if err != nil {
+		return false, err
+	}
+
+	diffs := engine.DiffSaveGames(saveA, saveB)
+	total := len(diffs)
+	if limit > 0 && len(diffs) > limit {
+		diffs = diffs[:limit]
+	}
+
+	if format == "json" {
+		return total > 0, writeJSON(struct {
+			Total       int               `json:"total"`
+			Differences []engine.SaveDiff `json:"differences"`
+		}{total, diffs})
+	}
+
+	fmt.Printf("Comparing savegames %s (a) and %s (b)\n", pathA, pathB)
+	fmt.Printf("Game time: %v / %v\n", saveA.Header.GameTime, saveB.Header.GameTime)
+	if saveA.CombatRNG == nil || saveB.CombatRNG == nil {
+		fmt.Println("Combat RNG state is not recorded in at least one save; it cannot be compared")
+	}
+	if total == 0 {
+		fmt.Println("\nNo differences")
+		return false, nil
+	}
+	fmt.Printf("\n%d differences:\n", total)
+	for _, diff := range diffs {
+		fmt.Printf("  %s\n", diff)
+	}
+	if total > len(diffs) {
+		fmt.Printf("  ... %d more (raise -max to see them)\n", total-len(diffs))
+	}
+	return true, nil
+}
+
+// diffTraces prints the ticks at which two hash dumps differ
+func diffTraces(pathA, pathB, format string, limit int) (bool, error) {
+	hashesA, err := engine.ReadHashTrace(pathA)
+	if err != nil {
+		return false, err
+	}
+	hashesB, err := engine.ReadHashTrace(pathB)
+	if err != nil {
+		return false, err
+	}
+
+	report := traceReport{TicksA: len(hashesA), TicksB: len(hashesB), FirstDivergent: -1}
+	var divergence *engine.DivergenceError
+	if errors.As(engine.CompareHashes(hashesA, hashesB), &divergence) {
+		report.FirstDivergent = divergence.Tick
+	}
+	for tick := 0; tick < len(hashesA) && tick < len(hashesB); tick++ {
+		if hashesA[tick] == hashesB[tick] {
+			continue
+		}
+		report.DifferingTicks++
+		if limit == 0 || len(report.Ticks) < limit {
+			report.Ticks = append(report.Ticks, tickDiff{Tick: tick, A: fmt.Sprintf("%016x", hashesA[tick]), B: fmt.Sprintf("%016x", hashesB[tick])})
+		}
+	}
+	differ := report.DifferingTicks > 0 || report.TicksA != report.TicksB
+
+	if format == "json" {
+		return differ, writeJSON(report)
+	}
+
+	fmt.Printf("Comparing hash dumps %s (a, %d ticks) and %s (b, %d ticks)\n", pathA, report.TicksA, pathB, report.TicksB)
+	if !differ {
+		fmt.Println("\nNo differences")
+		return false, nil
+	}
+	if report.FirstDivergent >= 0 {
+		fmt.Printf("\nFirst divergence at tick %d; %d ticks differ:\n", report.FirstDivergent, report.DifferingTicks)
+		for _, tick := range report.Ticks {
+			fmt.Printf("  tick %d: %s -> %s\n", tick.Tick, tick.A, tick.B)
+		}
+		if report.DifferingTicks > len(report.Ticks) {
+			fmt.Printf("  ... %d more (raise -max to see them)\n", report.DifferingTicks-len(report.Ticks))
+		}
+	}
+	if report.TicksA != report.TicksB {
+		fmt.Printf("\nThe dumps cover a different number of ticks\n")
+	}
+	return true, nil
+}
+
+// writeJSON prints a report as indented JSON
+func writeJSON(report interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
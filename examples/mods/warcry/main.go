@@ -0,0 +1,60 @@
+// Command warcry is a sample mod adding a custom unit ability: a war cry
+// that spends the caller's energy to heal the allied units around it, with
+// a cooldown. Build it as a Go plugin and put it in the game's mod
+// directory:
+//
+//	go build -buildmode=plugin -o mods/warcry.so ./examples/mods/warcry
+//
+// The mod needs the "units" permission.
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"teraglest/internal/engine"
+)
+
+// War cry tuning
+const (
+	warCryEnergy   = 50
+	warCryHealing  = 25
+	warCryRadius   = 6.0 // World units around the caller
+	warCryCooldown = 20 * time.Second
+)
+
+// NewMod creates the mod; it is the symbol the game looks up
+func NewMod() *engine.Mod {
+	lastCry := make(map[int]time.Duration) // Game time each unit last cried
+
+	return &engine.Mod{
+		Name:        "warcry",
+		Permissions: engine.ModPermissions{ModifyUnits: true},
+		Commands: map[string]engine.ModCommand{
+			"warcry": func(api *engine.ModAPI, unit engine.ModUnit, target *engine.Vector3) error {
+				now := api.GameTime()
+				if last, cried := lastCry[unit.ID]; cried && now-last < warCryCooldown {
+					return fmt.Errorf("war cry ready in %v", warCryCooldown-(now-last))
+				}
+				if err := api.SpendEnergy(unit.ID, warCryEnergy); err != nil {
+					return err
+				}
+				lastCry[unit.ID] = now
+
+				healed := 0
+				for _, ally := range api.UnitsNear(unit.Position, warCryRadius) {
+					if ally.PlayerID == unit.PlayerID && ally.Health < ally.MaxHealth {
+						if err := api.Heal(ally.ID, warCryHealing); err == nil {
+							healed++
+						}
+					}
+				}
+				api.Logf("unit %d cried, healing %d allies", unit.ID, healed)
+				return nil
+			},
+		},
+	}
+}
+
+// main is unused; the game loads the mod as a plugin
+func main() {}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+)
+
+// TestWarCry tests the sample ability through the mod API
+func TestWarCry(t *testing.T) {
+	world, err := engine.NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	if err := world.RegisterMod(NewMod()); err != nil {
+		t.Fatalf("RegisterMod failed: %v", err)
+	}
+
+	unitDef := &data.UnitDefinition{Name: "soldier"}
+	unitDef.Unit.Parameters.MaxHP.Value = 100
+	create := func(playerID int, x float64) *engine.GameUnit {
+		unit, err := world.ObjectManager.CreateUnit(playerID, "soldier", engine.Vector3{X: x, Z: 8}, unitDef)
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		unit.Health = 50
+		return unit
+	}
+	caller, ally, enemy, farAlly := create(1, 8), create(1, 10), create(2, 9), create(1, 30)
+
+	if err := world.RunModCommand(1, caller.ID, "warcry", nil); err != nil {
+		t.Fatalf("warcry failed: %v", err)
+	}
+	if caller.Health != 75 || ally.Health != 75 || enemy.Health != 50 || farAlly.Health != 50 {
+		t.Errorf("Expected only nearby allies healed, got caller %d, ally %d, enemy %d, far ally %d",
+			caller.Health, ally.Health, enemy.Health, farAlly.Health)
+	}
+	if caller.Energy != 50 {
+		t.Errorf("Expected the cry to cost 50 energy, got %d left", caller.Energy)
+	}
+
+	if err := world.RunModCommand(1, caller.ID, "warcry", nil); err == nil {
+		t.Error("Expected the war cry to be on cooldown")
+	}
+	world.Update(warCryCooldown)
+	if err := world.RunModCommand(1, caller.ID, "warcry", nil); err != nil {
+		t.Errorf("Expected the war cry after its cooldown, got %v", err)
+	}
+	if err := world.RunModCommand(1, ally.ID, "warcry", nil); err != nil {
+		t.Errorf("Expected another unit to cry, got %v", err)
+	}
+}
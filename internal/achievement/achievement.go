@@ -0,0 +1,293 @@
+// Package achievement unlocks data-defined achievements from the match
+// statistics of the StatsRecorder and records them in the player profile.
+package achievement
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/profile"
+)
+
+//go:embed definitions.json
+var defaultDefinitions []byte
+
+// Scope decides which statistics a definition is checked against
+type Scope string
+
+const (
+	ScopeMatch  Scope = "match"  // The statistics of a single match
+	ScopeCareer Scope = "career" // The statistics summed over every match
+)
+
+// Condition compares one statistic with a value
+type Condition struct {
+	Stat  string `json:"stat"`  // Statistic name, see the engine.Stat* constants
+	Op    string `json:"op"`    // One of >=, >, <=, <, ==, !=
+	Value int    `json:"value"` // Value compared with
+}
+
+// Met reports whether the statistics satisfy the condition
+func (c Condition) Met(stats map[string]int) bool {
+	value := stats[c.Stat]
+	switch c.Op {
+	case ">=":
+		return value >= c.Value
+	case ">":
+		return value > c.Value
+	case "<=":
+		return value <= c.Value
+	case "<":
+		return value < c.Value
+	case "==":
+		return value == c.Value
+	case "!=":
+		return value != c.Value
+	default:
+		return false
+	}
+}
+
+// progress returns how close the statistics are to meeting the condition (0-1)
+func (c Condition) progress(stats map[string]int) float64 {
+	if c.Met(stats) {
+		return 1
+	}
+	// Only thresholds to reach have partial progress
+	if (c.Op == ">=" || c.Op == ">") && c.Value > 0 {
+		return float64(stats[c.Stat]) / float64(c.Value)
+	}
+	return 0
+}
+
+// Definition describes an achievement and what unlocks it
+type Definition struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Scope       Scope       `json:"scope"`
+	Live        bool        `json:"live,omitempty"` // Match achievements also checked during play, not only at the end
+	Hidden      bool        `json:"hidden,omitempty"`
+	Conditions  []Condition `json:"conditions"`
+}
+
+// Validate checks a definition for mistakes
+func (d Definition) Validate() error {
+	if d.ID == "" {
+		return fmt.Errorf("achievement without an id")
+	}
+	if d.Scope != ScopeMatch && d.Scope != ScopeCareer {
+		return fmt.Errorf("achievement %s: unknown scope %q", d.ID, d.Scope)
+	}
+	if len(d.Conditions) == 0 {
+		return fmt.Errorf("achievement %s has no conditions", d.ID)
+	}
+	for _, condition := range d.Conditions {
+		if condition.Stat == "" {
+			return fmt.Errorf("achievement %s: condition without a statistic", d.ID)
+		}
+		if !condition.knownOp() {
+			return fmt.Errorf("achievement %s: unknown operator %q", d.ID, condition.Op)
+		}
+	}
+	return nil
+}
+
+// knownOp reports whether the condition's operator is supported
+func (c Condition) knownOp() bool {
+	switch c.Op {
+	case ">=", ">", "<=", "<", "==", "!=":
+		return true
+	}
+	return false
+}
+
+// Met reports whether the statistics satisfy every condition
+func (d Definition) Met(stats map[string]int) bool {
+	for _, condition := range d.Conditions {
+		if !condition.Met(stats) {
+			return false
+		}
+	}
+	return true
+}
+
+// Progress returns how close the statistics are to unlocking the achievement (0-1)
+func (d Definition) Progress(stats map[string]int) float64 {
+	progress := 1.0
+	for _, condition := range d.Conditions {
+		if p := condition.progress(stats); p < progress {
+			progress = p
+		}
+	}
+	return progress
+}
+
+// ParseDefinitions reads definitions from JSON
+func ParseDefinitions(data []byte) ([]Definition, error) {
+	var definitions []Definition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return nil, fmt.Errorf("failed to parse achievements: %w", err)
+	}
+	seen := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		if err := definition.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[definition.ID] {
+			return nil, fmt.Errorf("achievement %s is defined twice", definition.ID)
+		}
+		seen[definition.ID] = true
+	}
+	return definitions, nil
+}
+
+// LoadDefinitions reads definitions from a JSON file
+func LoadDefinitions(path string) ([]Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read achievements: %w", err)
+	}
+	return ParseDefinitions(data)
+}
+
+// DefaultDefinitions returns the achievements shipped with the game
+func DefaultDefinitions() []Definition {
+	definitions, err := ParseDefinitions(defaultDefinitions)
+	if err != nil {
+		panic(err) // The embedded file is covered by tests
+	}
+	return definitions
+}
+
+// Status is an achievement's state for the profile page
+type Status struct {
+	Definition
+	Unlocked   bool
+	UnlockedAt time.Time
+	Progress   float64 // 0-1; match achievements have no partial progress between matches
+}
+
+// Tracker checks the achievements of one player during and after a match and
+// unlocks them in the player's profile. The caller saves the profile.
+type Tracker struct {
+	definitions []Definition
+	profile     *profile.Profile
+	match       engine.PlayerStats // Latest statistics of the running match
+	onUnlock    func(definition Definition)
+	now         func() time.Time
+	mutex       sync.Mutex
+}
+
+// NewTracker creates a tracker for the achievements of a profile
+func NewTracker(definitions []Definition, player *profile.Profile) *Tracker {
+	return &Tracker{
+		definitions: definitions,
+		profile:     player,
+		match:       engine.PlayerStats{},
+		now:         time.Now,
+	}
+}
+
+// SetUnlockHandler registers a function called for every unlocked achievement
+func (t *Tracker) SetUnlockHandler(handler func(definition Definition)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.onUnlock = handler
+}
+
+// Update checks career and live match achievements against the statistics of
+// the running match, returning those newly unlocked
+func (t *Tracker) Update(stats engine.PlayerStats) []Definition {
+	t.mutex.Lock()
+	t.match = stats
+	career := t.careerWith(stats)
+	var unlocked []Definition
+	for _, definition := range t.definitions {
+		switch {
+		case definition.Scope == ScopeCareer && definition.Met(career),
+			definition.Scope == ScopeMatch && definition.Live && definition.Met(stats):
+			if t.profile.Unlock(definition.ID, t.now()) {
+				unlocked = append(unlocked, definition)
+			}
+		}
+	}
+	handler := t.onUnlock
+	t.mutex.Unlock()
+
+	notify(handler, unlocked)
+	return unlocked
+}
+
+// FinishMatch checks every achievement against the final statistics of a
+// match, adds them to the career counters and returns the newly unlocked achievements
+func (t *Tracker) FinishMatch(stats engine.PlayerStats) []Definition {
+	t.mutex.Lock()
+	t.profile.AddCounters(stats)
+	t.match = engine.PlayerStats{}
+	var unlocked []Definition
+	for _, definition := range t.definitions {
+		source := map[string]int(stats)
+		if definition.Scope == ScopeCareer {
+			source = t.profile.Stats.Counters
+		}
+		if definition.Met(source) && t.profile.Unlock(definition.ID, t.now()) {
+			unlocked = append(unlocked, definition)
+		}
+	}
+	handler := t.onUnlock
+	t.mutex.Unlock()
+
+	notify(handler, unlocked)
+	return unlocked
+}
+
+// Statuses returns the state of every achievement, in definition order
+func (t *Tracker) Statuses() []Status {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	career := t.careerWith(t.match)
+	statuses := make([]Status, 0, len(t.definitions))
+	for _, definition := range t.definitions {
+		status := Status{Definition: definition}
+		status.UnlockedAt, status.Unlocked = t.profile.Unlocked(definition.ID)
+		switch {
+		case status.Unlocked:
+			status.Progress = 1
+		case definition.Scope == ScopeCareer:
+			status.Progress = definition.Progress(career)
+		case definition.Live:
+			status.Progress = definition.Progress(t.match)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// careerWith returns the career counters including a running match (lock must be held)
+func (t *Tracker) careerWith(match engine.PlayerStats) map[string]int {
+	career := make(map[string]int, len(t.profile.Stats.Counters)+len(match))
+	for name, value := range t.profile.Stats.Counters {
+		career[name] = value
+	}
+	for name, value := range match {
+		career[name] += value
+	}
+	return career
+}
+
+// notify calls the unlock handler for each achievement
+func notify(handler func(definition Definition), unlocked []Definition) {
+	if handler == nil {
+		return
+	}
+	for _, definition := range unlocked {
+		handler(definition)
+	}
+}
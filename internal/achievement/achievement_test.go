@@ -0,0 +1,79 @@
+package achievement
+
+import (
+	"testing"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/profile"
+)
+
+func TestDefaultDefinitionsParse(t *testing.T) {
+	definitions := DefaultDefinitions()
+	if len(definitions) == 0 {
+		t.Fatal("Expected shipped achievements")
+	}
+}
+
+func TestParseDefinitionsRejectsMistakes(t *testing.T) {
+	invalid := []string{
+		`[{"id": "a", "scope": "forever", "conditions": [{"stat": "won", "op": ">=", "value": 1}]}]`,
+		`[{"id": "a", "scope": "match", "conditions": []}]`,
+		`[{"id": "a", "scope": "match", "conditions": [{"stat": "won", "op": "~", "value": 1}]}]`,
+		`[{"id": "a", "scope": "match", "conditions": [{"stat": "won", "op": ">=", "value": 1}]},
+		  {"id": "a", "scope": "match", "conditions": [{"stat": "won", "op": ">=", "value": 1}]}]`,
+	}
+	for _, data := range invalid {
+		if _, err := ParseDefinitions([]byte(data)); err == nil {
+			t.Errorf("Expected %s to be rejected", data)
+		}
+	}
+}
+
+func TestTrackerUnlocksAchievements(t *testing.T) {
+	definitions, err := ParseDefinitions([]byte(`[
+		{"id": "flawless", "scope": "match", "conditions": [
+			{"stat": "won", "op": ">=", "value": 1}, {"stat": "buildings_lost", "op": "==", "value": 0}]},
+		{"id": "legion", "scope": "match", "live": true, "conditions": [{"stat": "units_peak", "op": ">=", "value": 50}]},
+		{"id": "workforce", "scope": "career", "conditions": [{"stat": "units_trained:worker", "op": ">=", "value": 100}]}
+	]`))
+	if err != nil {
+		t.Fatalf("ParseDefinitions failed: %v", err)
+	}
+	player := profile.New("Alice")
+	player.AddCounters(map[string]int{"units_trained:worker": 60})
+	tracker := NewTracker(definitions, player)
+
+	var notified []string
+	tracker.SetUnlockHandler(func(definition Definition) { notified = append(notified, definition.ID) })
+
+	workers := engine.StatKey(engine.StatUnitsTrained, "worker")
+	if unlocked := tracker.Update(engine.PlayerStats{workers: 20, engine.StatUnitsPeak: 50}); len(unlocked) != 1 || unlocked[0].ID != "legion" {
+		t.Fatalf("Expected only the live match achievement during play, got %+v", unlocked)
+	}
+	for _, status := range tracker.Statuses() {
+		if status.ID == "workforce" && status.Progress != 0.8 {
+			t.Errorf("Expected career progress to include the running match, got %v", status.Progress)
+		}
+	}
+
+	// A won match without lost buildings that brings the career total to 100 workers
+	final := engine.PlayerStats{workers: 40, engine.StatWon: 1, engine.StatUnitsPeak: 50}
+	unlocked := tracker.FinishMatch(final)
+	if len(unlocked) != 2 {
+		t.Fatalf("Expected flawless and workforce at the end of the match, got %+v", unlocked)
+	}
+	if len(notified) != 3 {
+		t.Errorf("Expected a notification per unlock, got %v", notified)
+	}
+	if player.Stats.Counters[workers] != 100 {
+		t.Errorf("Expected the match to be added to the career counters, got %d", player.Stats.Counters[workers])
+	}
+
+	// Unlocks happen once
+	if again := tracker.FinishMatch(final); len(again) != 0 {
+		t.Errorf("Expected no repeated unlocks, got %+v", again)
+	}
+	if _, unlocked := player.Unlocked("flawless"); !unlocked {
+		t.Error("Expected the profile to record the unlock")
+	}
+}
@@ -0,0 +1,235 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// Ambient zone tuning
+const (
+	ambientCrossfade = 3 * time.Second  // Time for an environment layer to fade fully in or out
+	ambientCallMin   = 15 * time.Second // Shortest wait between random animal calls
+	ambientCallMax   = 45 * time.Second // Longest wait between random animal calls
+	ambientCallRange = 20.0             // Calls play up to this far from the listener
+	openLoopVolume   = 0.5              // Volume of the tileset loops over open ground, relative to forest
+)
+
+// ambientZones crossfades the environment layers towards the terrain around
+// the listener and plays the tileset's day and night sounds
+type ambientZones struct {
+	targets     map[string]float32      // Volume each environment layer fades towards
+	day, night  []*AmbientSoundInstance // Tileset loops, one per layer they play in
+	dayAlways   bool                    // Day loop plays at night too
+	nightAlways bool                    // Night loop plays by day too
+	dayCalls    []*Sound                // Random calls by day, e.g. birds
+	nightCalls  []*Sound                // Random calls by night, e.g. owls
+	daytime     *bool                   // Whether it was day at the last update, nil before the first
+	lastUpdate  time.Time
+	nextCall    time.Time
+	random      *rand.Rand
+}
+
+// initializeAmbientZones adds a silent layer for each terrain environment;
+// open ground is heard until the first environment mix arrives
+func (sam *SpatialAudioManager) initializeAmbientZones() {
+	sam.zones = ambientZones{
+		targets: make(map[string]float32),
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, environment := range engine.AmbientEnvironments {
+		name := environment.String()
+		sam.ambientLayers[name] = &AmbientLayer{
+			Name:     name,
+			Sounds:   []*AmbientSoundInstance{},
+			Priority: 1,
+		}
+		sam.zones.targets[name] = 0
+	}
+	sam.zones.targets[engine.AmbientOpen.String()] = 1
+}
+
+// SetTilesetAmbience loads a tileset's ambient sounds: the day and night loops
+// play over forest and open ground, and the day and night start sounds are
+// played as random calls. Paths are relative to basePath.
+func (sam *SpatialAudioManager) SetTilesetAmbience(sounds *engine.AmbientSounds, basePath string) {
+	sam.mutex.Lock()
+	defer sam.mutex.Unlock()
+
+	// Drop the loops of a previous tileset
+	for _, name := range []string{engine.AmbientForest.String(), engine.AmbientOpen.String()} {
+		sam.ambientLayers[name].Sounds = []*AmbientSoundInstance{}
+	}
+	sam.zones.day, sam.zones.night = nil, nil
+	sam.zones.dayCalls, sam.zones.nightCalls = nil, nil
+	sam.zones.daytime = nil
+	if sounds == nil {
+		return
+	}
+
+	sam.zones.day = sam.addTilesetLoop("day", sounds.DaySound, basePath)
+	sam.zones.night = sam.addTilesetLoop("night", sounds.NightSound, basePath)
+	sam.zones.dayAlways = sounds.DaySound.PlayAlways
+	sam.zones.nightAlways = sounds.NightSound.PlayAlways
+	sam.zones.dayCalls = loadAmbientCalls("day_call", sounds.DayStartSound, basePath)
+	sam.zones.nightCalls = loadAmbientCalls("night_call", sounds.NightStartSound, basePath)
+}
+
+// SetEnvironmentLoop sets the loop heard over one environment, e.g. waves over water
+func (sam *SpatialAudioManager) SetEnvironmentLoop(environment engine.AmbientEnvironment, path string, volume float32) error {
+	sound, err := NewSound("ambient_"+environment.String(), path, "ambient")
+	if err != nil {
+		return fmt.Errorf("failed to load %s ambience: %w", environment, err)
+	}
+
+	sam.mutex.Lock()
+	defer sam.mutex.Unlock()
+	sam.ambientLayers[environment.String()].Sounds = []*AmbientSoundInstance{{
+		ID:        sound.ID,
+		Sound:     sound,
+		Volume:    volume,
+		IsActive:  true,
+		LayerName: environment.String(),
+	}}
+	return nil
+}
+
+// SetEnvironmentMix sets the terrain mix around the listener; each
+// environment layer crossfades towards its share of the mix
+func (sam *SpatialAudioManager) SetEnvironmentMix(mix engine.AmbientMix) {
+	sam.mutex.Lock()
+	defer sam.mutex.Unlock()
+
+	for _, environment := range engine.AmbientEnvironments {
+		sam.zones.targets[environment.String()] = clampFloat32(mix[environment], 0.0, 1.0)
+	}
+}
+
+// AmbientLayerVolume returns the current volume of an ambient layer
+func (sam *SpatialAudioManager) AmbientLayerVolume(name string) float32 {
+	sam.mutex.RLock()
+	defer sam.mutex.RUnlock()
+
+	if layer, exists := sam.ambientLayers[name]; exists && layer.IsActive {
+		return layer.Volume
+	}
+	return 0
+}
+
+// updateAmbientZones fades the environment layers, switches between the day
+// and night loops and plays random calls (lock must be held)
+func (sam *SpatialAudioManager) updateAmbientZones(now time.Time) {
+	step := float32(1)
+	if !sam.zones.lastUpdate.IsZero() {
+		step = float32(now.Sub(sam.zones.lastUpdate).Seconds() / ambientCrossfade.Seconds())
+	}
+	sam.zones.lastUpdate = now
+
+	for name, target := range sam.zones.targets {
+		layer := sam.ambientLayers[name]
+		switch {
+		case layer.Volume < target:
+			layer.Volume = float32(math.Min(float64(target), float64(layer.Volume+step)))
+		case layer.Volume > target:
+			layer.Volume = float32(math.Max(float64(target), float64(layer.Volume-step)))
+		}
+		layer.IsActive = layer.Volume > 0
+	}
+
+	// Day runs from dawn (0.25) to dusk (0.75)
+	daytime := sam.timeOfDay >= 0.25 && sam.timeOfDay < 0.75
+	for _, loop := range sam.zones.day {
+		loop.IsActive = daytime || sam.zones.dayAlways
+	}
+	for _, loop := range sam.zones.night {
+		loop.IsActive = !daytime || sam.zones.nightAlways
+	}
+
+	// Dawn and dusk start with a call; more follow at random intervals,
+	// more often the more wildlife there is around the listener
+	changed := sam.zones.daytime != nil && *sam.zones.daytime != daytime
+	sam.zones.daytime = &daytime
+	if !changed && now.Before(sam.zones.nextCall) {
+		return
+	}
+	wait := ambientCallMin + time.Duration(sam.zones.random.Int63n(int64(ambientCallMax-ambientCallMin)))
+	sam.zones.nextCall = now.Add(wait)
+
+	calls := sam.zones.nightCalls
+	if daytime {
+		calls = sam.zones.dayCalls
+	}
+	wildlife := sam.zones.targets[engine.AmbientForest.String()] + openLoopVolume*sam.zones.targets[engine.AmbientOpen.String()]
+	if len(calls) == 0 || (!changed && sam.zones.random.Float32() >= wildlife) {
+		return
+	}
+
+	call := calls[sam.zones.random.Intn(len(calls))]
+	angle := sam.zones.random.Float64() * 2 * math.Pi
+	distance := sam.zones.random.Float64() * ambientCallRange
+	position := Vector3{
+		X: sam.listenerPosition.X + float32(math.Cos(angle)*distance),
+		Y: sam.listenerPosition.Y,
+		Z: sam.listenerPosition.Z + float32(math.Sin(angle)*distance),
+	}
+	if err := sam.backend.PlaySound3D(call, position); err != nil {
+		logging.Warnf(logging.CategoryAudio, "Failed to play ambient call %s: %v", call.Name, err)
+	}
+}
+
+// addTilesetLoop adds a tileset loop to the forest layer, and quieter to the
+// open ground layer (lock must be held)
+func (sam *SpatialAudioManager) addTilesetLoop(id string, config engine.AudioConfig, basePath string) []*AmbientSoundInstance {
+	if !config.Enabled || config.Path == "" {
+		return nil
+	}
+	sound, err := NewSound("ambient_"+id, filepath.Join(basePath, config.Path), "ambient")
+	if err != nil {
+		logging.Warnf(logging.CategoryAudio, "Skipping %s ambience: %v", id, err)
+		return nil
+	}
+	sound.CanLoop = true
+
+	var loops []*AmbientSoundInstance
+	for name, volume := range map[string]float32{engine.AmbientForest.String(): 1, engine.AmbientOpen.String(): openLoopVolume} {
+		loop := &AmbientSoundInstance{
+			ID:        fmt.Sprintf("ambient_%s_%s", id, name),
+			Sound:     sound,
+			Volume:    config.Volume * volume,
+			LayerName: name,
+		}
+		sam.ambientLayers[name].Sounds = append(sam.ambientLayers[name].Sounds, loop)
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+// loadAmbientCalls loads the alternatives of a tileset's random call sound
+func loadAmbientCalls(id string, config engine.AudioConfig, basePath string) []*Sound {
+	if !config.Enabled {
+		return nil
+	}
+	paths := config.Sounds
+	if len(paths) == 0 && config.Path != "" {
+		paths = []string{config.Path}
+	}
+
+	calls := make([]*Sound, 0, len(paths))
+	for i, path := range paths {
+		sound, err := NewSound(fmt.Sprintf("ambient_%s_%d", id, i), filepath.Join(basePath, path), "ambient")
+		if err != nil {
+			logging.Warnf(logging.CategoryAudio, "Skipping ambient call %s: %v", path, err)
+			continue
+		}
+		sound.DefaultVolume = config.Volume
+		sound.CanLoop = false
+		sound.Is3D = true
+		calls = append(calls, sound)
+	}
+	return calls
+}
@@ -392,6 +392,21 @@ func (am *AudioManager) StopMusic() error {
 	return am.music.StopMusic()
 }
 
+// EndMatch stops the sounds of a finished match and drops its tileset's
+// ambience; loaded sounds stay cached for the next match
+func (am *AudioManager) EndMatch() error {
+	if am.spatialAudio != nil {
+		am.spatialAudio.StopSpatialSounds()
+		am.spatialAudio.SetTilesetAmbience(nil, "")
+	}
+	if am.soundEffects != nil {
+		if err := am.soundEffects.StopAllSounds(); err != nil {
+			return fmt.Errorf("failed to stop match sounds: %w", err)
+		}
+	}
+	return nil
+}
+
 // Shutdown gracefully shuts down the audio system
 func (am *AudioManager) Shutdown() error {
 	am.cancel()
@@ -460,6 +475,25 @@ func (am *AudioManager) GetStats() AudioStats {
 	return stats
 }
 
+// CachedSounds returns the sounds and music loaded by the sound effects and
+// music libraries and the memory they hold
+func (am *AudioManager) CachedSounds() (count int, bytes int64) {
+	am.mutex.RLock()
+	defer am.mutex.RUnlock()
+
+	if am.soundEffects != nil {
+		stats := am.soundEffects.library.GetStats()
+		count += stats.LoadedSounds + stats.LoadedMusic
+		bytes += stats.TotalMemory
+	}
+	if am.music != nil {
+		stats := am.music.library.GetStats()
+		count += stats.LoadedSounds + stats.LoadedMusic
+		bytes += stats.TotalMemory
+	}
+	return count, bytes
+}
+
 // AudioStats provides information about the audio system state
 type AudioStats struct {
 	Enabled          bool
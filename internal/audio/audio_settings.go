@@ -4,8 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"sync"
+
+	"teraglest/internal/userdata"
 )
 
 // AudioSettings manages audio configuration and preferences
@@ -111,16 +112,10 @@ func NewAudioSettings() (*AudioSettings, error) {
 		LowLatencyMode:       false,
 	}
 
-	// Set config path
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		// Fallback to current directory
-		settings.configPath = filepath.Join(".", "audio_settings.json")
-	} else {
-		appDir := filepath.Join(configDir, "teraglest")
-		os.MkdirAll(appDir, 0755)
-		settings.configPath = filepath.Join(appDir, "audio_settings.json")
-	}
+	// Set config path (falls back to the current directory if no user directory is available)
+	userPaths := userdata.MustResolve()
+	os.MkdirAll(userPaths.Config, 0755)
+	settings.configPath = userPaths.ConfigFile("audio_settings.json")
 
 	// Try to load existing settings
 	if err := settings.Load(); err != nil {
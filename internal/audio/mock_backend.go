@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"teraglest/internal/logging"
 )
 
 // MockAudioBackend provides a mock implementation of AudioBackend for testing and development
@@ -84,7 +86,7 @@ func (m *MockAudioBackend) Initialize() error {
 	time.Sleep(10 * time.Millisecond)
 
 	m.initialized = true
-	fmt.Printf("[MockAudio] Backend initialized - Sample Rate: %d, Buffer: %d, Latency: %v\n",
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Backend initialized - Sample Rate: %d, Buffer: %d, Latency: %v",
 		m.sampleRate, m.bufferSize, m.latency)
 
 	return nil
@@ -108,7 +110,7 @@ func (m *MockAudioBackend) Shutdown() error {
 	m.currentMusic = nil
 
 	m.initialized = false
-	fmt.Printf("[MockAudio] Backend shutdown complete\n")
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Backend shutdown complete")
 
 	return nil
 }
@@ -142,7 +144,7 @@ func (m *MockAudioBackend) PlaySound(sound *Sound) error {
 	m.activeSounds[soundID] = playback
 	m.totalSoundsPlayed++
 
-	fmt.Printf("[MockAudio] Playing sound: %s (ID: %s, Volume: %.2f)\n",
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Playing sound: %s (ID: %s, Volume: %.2f)",
 		sound.Name, soundID, playback.Volume)
 
 	// Simulate non-looping sounds finishing
@@ -175,7 +177,7 @@ func (m *MockAudioBackend) StopSound(soundID string) error {
 	playback.IsActive = false
 	delete(m.activeSounds, soundID)
 
-	fmt.Printf("[MockAudio] Stopped sound: %s\n", soundID)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Stopped sound: %s", soundID)
 	return nil
 }
 
@@ -194,7 +196,7 @@ func (m *MockAudioBackend) PauseSound(soundID string) error {
 	}
 
 	playback.IsActive = false
-	fmt.Printf("[MockAudio] Paused sound: %s\n", soundID)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Paused sound: %s", soundID)
 	return nil
 }
 
@@ -213,7 +215,7 @@ func (m *MockAudioBackend) ResumeSound(soundID string) error {
 	}
 
 	playback.IsActive = true
-	fmt.Printf("[MockAudio] Resumed sound: %s\n", soundID)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Resumed sound: %s", soundID)
 	return nil
 }
 
@@ -232,7 +234,7 @@ func (m *MockAudioBackend) PlayMusic(music *Music) error {
 
 	// Stop current music if playing
 	if m.currentMusic != nil {
-		fmt.Printf("[MockAudio] Stopping current music: %s\n", m.currentMusic.MusicID)
+		logging.Debugf(logging.CategoryAudio, "[MockAudio] Stopping current music: %s", m.currentMusic.MusicID)
 	}
 
 	// Create new music playback
@@ -248,7 +250,7 @@ func (m *MockAudioBackend) PlayMusic(music *Music) error {
 
 	m.totalMusicPlayed++
 
-	fmt.Printf("[MockAudio] Playing music: %s (Duration: %v, Looping: %t)\n",
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Playing music: %s (Duration: %v, Looping: %t)",
 		music.Name, music.Duration, music.CanLoop)
 
 	return nil
@@ -267,7 +269,7 @@ func (m *MockAudioBackend) StopMusic() error {
 		return fmt.Errorf("no music currently playing")
 	}
 
-	fmt.Printf("[MockAudio] Stopping music: %s\n", m.currentMusic.MusicID)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Stopping music: %s", m.currentMusic.MusicID)
 	m.currentMusic = nil
 
 	return nil
@@ -289,9 +291,9 @@ func (m *MockAudioBackend) PauseMusicToggle() error {
 	m.currentMusic.IsPaused = !m.currentMusic.IsPaused
 
 	if m.currentMusic.IsPaused {
-		fmt.Printf("[MockAudio] Paused music: %s\n", m.currentMusic.MusicID)
+		logging.Debugf(logging.CategoryAudio, "[MockAudio] Paused music: %s", m.currentMusic.MusicID)
 	} else {
-		fmt.Printf("[MockAudio] Resumed music: %s\n", m.currentMusic.MusicID)
+		logging.Debugf(logging.CategoryAudio, "[MockAudio] Resumed music: %s", m.currentMusic.MusicID)
 	}
 
 	return nil
@@ -319,7 +321,7 @@ func (m *MockAudioBackend) SetMusicVolume(volume float32) error {
 		m.currentMusic.Volume = volume
 	}
 
-	fmt.Printf("[MockAudio] Set music volume: %.2f\n", volume)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Set music volume: %.2f", volume)
 	return nil
 }
 
@@ -335,7 +337,7 @@ func (m *MockAudioBackend) SetListenerPosition(pos Vector3) error {
 	m.listenerPos = pos
 
 	// Only print if position changed significantly (avoid spam)
-	fmt.Printf("[MockAudio] Listener position: (%.1f, %.1f, %.1f)\n",
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Listener position: (%.1f, %.1f, %.1f)",
 		pos.X, pos.Y, pos.Z)
 
 	return nil
@@ -353,7 +355,7 @@ func (m *MockAudioBackend) SetListenerOrientation(forward, up Vector3) error {
 	m.listenerForward = forward
 	m.listenerUp = up
 
-	fmt.Printf("[MockAudio] Listener orientation - Forward: (%.2f, %.2f, %.2f), Up: (%.2f, %.2f, %.2f)\n",
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Listener orientation - Forward: (%.2f, %.2f, %.2f), Up: (%.2f, %.2f, %.2f)",
 		forward.X, forward.Y, forward.Z, up.X, up.Y, up.Z)
 
 	return nil
@@ -391,7 +393,7 @@ func (m *MockAudioBackend) PlaySound3D(sound *Sound, position Vector3) error {
 	dz := position.Z - m.listenerPos.Z
 	distance := float32(dx*dx + dy*dy + dz*dz) // Squared distance for performance
 
-	fmt.Printf("[MockAudio] Playing 3D sound at (%.1f, %.1f, %.1f), Distance²: %.1f\n",
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Playing 3D sound at (%.1f, %.1f, %.1f), Distance²: %.1f",
 		position.X, position.Y, position.Z, distance)
 
 	return nil
@@ -415,7 +417,7 @@ func (m *MockAudioBackend) SetMasterVolume(volume float32) error {
 
 	m.masterVolume = volume
 
-	fmt.Printf("[MockAudio] Set master volume: %.2f\n", volume)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Set master volume: %.2f", volume)
 	return nil
 }
 
@@ -491,7 +493,7 @@ func (m *MockAudioBackend) SimulateLatency(duration time.Duration) {
 	defer m.mutex.Unlock()
 
 	m.latency = duration
-	fmt.Printf("[MockAudio] Simulating latency: %v\n", duration)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Simulating latency: %v", duration)
 }
 
 // SetBufferSize sets the audio buffer size for testing different configurations
@@ -508,7 +510,7 @@ func (m *MockAudioBackend) SetBufferSize(size int) error {
 	// Recalculate latency based on buffer size
 	m.latency = time.Duration(float64(size)/float64(m.sampleRate)*1000) * time.Millisecond
 
-	fmt.Printf("[MockAudio] Set buffer size: %d (Latency: %v)\n", size, m.latency)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Set buffer size: %d (Latency: %v)", size, m.latency)
 	return nil
 }
 
@@ -535,7 +537,7 @@ func (m *MockAudioBackend) SetSampleRate(rate int) error {
 	// Recalculate latency based on new sample rate
 	m.latency = time.Duration(float64(m.bufferSize)/float64(rate)*1000) * time.Millisecond
 
-	fmt.Printf("[MockAudio] Set sample rate: %d Hz (Latency: %v)\n", rate, m.latency)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Set sample rate: %d Hz (Latency: %v)", rate, m.latency)
 	return nil
 }
 
@@ -555,29 +557,28 @@ func (m *MockAudioBackend) CleanupFinishedSounds() {
 	}
 
 	if cleaned > 0 {
-		fmt.Printf("[MockAudio] Cleaned up %d finished sounds\n", cleaned)
+		logging.Debugf(logging.CategoryAudio, "[MockAudio] Cleaned up %d finished sounds", cleaned)
 	}
 }
 
-// PrintStatus prints the current backend status (useful for debugging)
+// PrintStatus logs the current backend status at debug level
 func (m *MockAudioBackend) PrintStatus() {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
-	fmt.Printf("\n[MockAudio] Backend Status:\n")
-	fmt.Printf("  Initialized: %t\n", m.initialized)
-	fmt.Printf("  Active Sounds: %d\n", len(m.activeSounds))
-	fmt.Printf("  Music Playing: %t\n", m.currentMusic != nil)
+	logging.Debugf(logging.CategoryAudio, "[MockAudio] Backend Status:")
+	logging.Debugf(logging.CategoryAudio, "  Initialized: %t", m.initialized)
+	logging.Debugf(logging.CategoryAudio, "  Active Sounds: %d", len(m.activeSounds))
+	logging.Debugf(logging.CategoryAudio, "  Music Playing: %t", m.currentMusic != nil)
 	if m.currentMusic != nil {
-		fmt.Printf("  Current Music: %s (Paused: %t)\n", m.currentMusic.MusicID, m.currentMusic.IsPaused)
-	}
-	fmt.Printf("  Master Volume: %.2f\n", m.masterVolume)
-	fmt.Printf("  Music Volume: %.2f\n", m.musicVolume)
-	fmt.Printf("  Listener: (%.1f, %.1f, %.1f)\n", m.listenerPos.X, m.listenerPos.Y, m.listenerPos.Z)
-	fmt.Printf("  Total Sounds Played: %d\n", m.totalSoundsPlayed)
-	fmt.Printf("  Total Music Played: %d\n", m.totalMusicPlayed)
-	fmt.Printf("  Sample Rate: %d Hz\n", m.sampleRate)
-	fmt.Printf("  Buffer Size: %d\n", m.bufferSize)
-	fmt.Printf("  Latency: %v\n", m.latency)
-	fmt.Printf("\n")
+		logging.Debugf(logging.CategoryAudio, "  Current Music: %s (Paused: %t)", m.currentMusic.MusicID, m.currentMusic.IsPaused)
+	}
+	logging.Debugf(logging.CategoryAudio, "  Master Volume: %.2f", m.masterVolume)
+	logging.Debugf(logging.CategoryAudio, "  Music Volume: %.2f", m.musicVolume)
+	logging.Debugf(logging.CategoryAudio, "  Listener: (%.1f, %.1f, %.1f)", m.listenerPos.X, m.listenerPos.Y, m.listenerPos.Z)
+	logging.Debugf(logging.CategoryAudio, "  Total Sounds Played: %d", m.totalSoundsPlayed)
+	logging.Debugf(logging.CategoryAudio, "  Total Music Played: %d", m.totalMusicPlayed)
+	logging.Debugf(logging.CategoryAudio, "  Sample Rate: %d Hz", m.sampleRate)
+	logging.Debugf(logging.CategoryAudio, "  Buffer Size: %d", m.bufferSize)
+	logging.Debugf(logging.CategoryAudio, "  Latency: %v", m.latency)
 }
\ No newline at end of file
@@ -0,0 +1,207 @@
+package audio
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// Music director tuning
+const (
+	combatHalfLife   = 6 * time.Second  // Time for a damage event's weight to halve
+	combatMemory     = 30 * time.Second // Damage older than this is forgotten
+	combatNearRadius = 25.0             // Damage within this distance of a focus point counts fully
+	combatFarRadius  = 60.0             // Damage beyond this distance of every focus point is ignored
+	ownDamageWeight  = 1.0              // Weight of damage to the local player's forces
+	nearDamageWeight = 0.5              // Weight of other fighting seen near a focus point
+	combatSaturation = 3.0              // Weighted damage giving about 63% intensity
+	tenseThreshold   = 0.15             // Intensity at which the music turns tense
+	combatThreshold  = 0.5              // Intensity at which the music turns to combat
+	combatRelease    = 0.3              // Intensity below which combat music calms down
+	minMoodDuration  = 8 * time.Second  // Shortest time a mood plays before the next change
+)
+
+// damageRecord is a damage event remembered for combat intensity
+type damageRecord struct {
+	position Vector3
+	weight   float64
+	time     time.Time
+}
+
+// MusicDirector picks the music mood and combat intensity from game events:
+// recent damage near the camera or the player's base raises the intensity,
+// which moves the music from peaceful to tense to combat, and the match
+// outcome switches to victory or defeat
+type MusicDirector struct {
+	music    *MusicManager
+	playerID int // Local player, whose forces under attack count the most
+
+	damage   []damageRecord
+	focus    []Vector3 // Camera target and the player's base
+	outcome  *MusicMood
+	mood     MusicMood
+	moodTime time.Time // When the current mood started
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewMusicDirector creates a director driving a music manager for the local player
+func NewMusicDirector(music *MusicManager, playerID int) *MusicDirector {
+	return &MusicDirector{music: music, playerID: playerID, mood: MoodPeaceful}
+}
+
+// HandleGameEvent takes damage and match outcome events into account
+func (md *MusicDirector) HandleGameEvent(event engine.GameEvent) {
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+
+	switch event.Type {
+	case engine.EventTypeUnderAttack:
+		location, ok := event.Location()
+		if !ok {
+			return
+		}
+		weight := nearDamageWeight
+		if event.PlayerID == md.playerID {
+			weight = ownDamageWeight
+		}
+		md.damage = append(md.damage, damageRecord{
+			position: Vector3{X: float32(location.X), Y: float32(location.Y), Z: float32(location.Z)},
+			weight:   weight,
+			time:     event.Timestamp,
+		})
+	case engine.EventTypePlayerVictory, engine.EventTypePlayerDefeated:
+		if event.PlayerID == md.playerID {
+			md.setOutcomeLocked(event.Type == engine.EventTypePlayerVictory)
+		}
+	}
+}
+
+// SetOutcome switches to victory or defeat music for the rest of the match
+func (md *MusicDirector) SetOutcome(won bool) {
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+	md.setOutcomeLocked(won)
+}
+
+// SetFocus sets the points fighting is measured against, e.g. the camera
+// target and the player's buildings
+func (md *MusicDirector) SetFocus(points ...Vector3) {
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+	md.focus = append(md.focus[:0], points...)
+}
+
+// Intensity returns the combat intensity at a time, 0-1
+func (md *MusicDirector) Intensity(now time.Time) float32 {
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+	return md.intensityLocked(now)
+}
+
+// Update recomputes the intensity and changes the music mood when needed
+func (md *MusicDirector) Update(now time.Time) {
+	md.mutex.Lock()
+	defer md.mutex.Unlock()
+
+	intensity := md.intensityLocked(now)
+	md.music.SetCombatIntensity(intensity)
+
+	mood := md.mood
+	switch {
+	case md.outcome != nil:
+		mood = *md.outcome
+	case intensity >= combatThreshold:
+		mood = MoodCombat
+	case md.mood == MoodCombat && intensity >= combatRelease:
+		// Stay in combat until the fighting has clearly died down
+	case intensity >= tenseThreshold:
+		mood = MoodTense
+	default:
+		mood = MoodPeaceful
+	}
+
+	// Escalating to combat and the outcome are immediate; calming down waits
+	if mood == md.mood || (now.Sub(md.moodTime) < minMoodDuration && mood != MoodCombat && md.outcome == nil) {
+		return
+	}
+	logging.Infof(logging.CategoryAudio, "Music mood %s -> %s (intensity %.2f)", moodName(md.mood), moodName(mood), intensity)
+	md.mood, md.moodTime = mood, now
+	md.music.SetMood(mood)
+}
+
+// setOutcomeLocked records the match outcome (lock must be held)
+func (md *MusicDirector) setOutcomeLocked(won bool) {
+	mood := MoodDefeat
+	if won {
+		mood = MoodVictory
+	}
+	md.outcome = &mood
+}
+
+// intensityLocked sums the decayed, distance-weighted damage and maps it to
+// 0-1, forgetting damage past combatMemory (lock must be held)
+func (md *MusicDirector) intensityLocked(now time.Time) float32 {
+	kept := md.damage[:0]
+	total := 0.0
+	for _, record := range md.damage {
+		age := now.Sub(record.time)
+		if age > combatMemory {
+			continue
+		}
+		kept = append(kept, record)
+		decay := math.Pow(0.5, age.Seconds()/combatHalfLife.Seconds())
+		total += record.weight * decay * md.proximityLocked(record.position)
+	}
+	md.damage = kept
+	return float32(1 - math.Exp(-total/combatSaturation))
+}
+
+// proximityLocked returns 1 for a position near a focus point, falling to 0
+// at combatFarRadius; without focus points every position counts (lock must be held)
+func (md *MusicDirector) proximityLocked(position Vector3) float64 {
+	if len(md.focus) == 0 {
+		return 1
+	}
+	nearest := math.Inf(1)
+	for _, point := range md.focus {
+		dx, dz := float64(position.X-point.X), float64(position.Z-point.Z)
+		nearest = math.Min(nearest, math.Hypot(dx, dz))
+	}
+	switch {
+	case nearest <= combatNearRadius:
+		return 1
+	case nearest >= combatFarRadius:
+		return 0
+	default:
+		return (combatFarRadius - nearest) / (combatFarRadius - combatNearRadius)
+	}
+}
+
+// moodName returns a readable name of a mood for logs
+func moodName(mood MusicMood) string {
+	switch mood {
+	case MoodPeaceful:
+		return "peaceful"
+	case MoodTense:
+		return "tense"
+	case MoodCombat:
+		return "combat"
+	case MoodVictory:
+		return "victory"
+	case MoodDefeat:
+		return "defeat"
+	case MoodExploration:
+		return "exploration"
+	case MoodStealth:
+		return "stealth"
+	case MoodBuilding:
+		return "building"
+	default:
+		return "unknown"
+	}
+}
@@ -40,6 +40,7 @@ type SpatialAudioManager struct {
 	ambientLayers    map[string]*AmbientLayer
 	weatherIntensity float32
 	timeOfDay        float32 // 0.0 = midnight, 0.5 = noon
+	zones            ambientZones
 
 	mutex sync.RWMutex
 }
@@ -89,6 +90,8 @@ type AmbientSoundInstance struct {
 	Volume      float32
 	IsActive    bool
 	LayerName   string
+
+	EffectiveVolume float32 // Volume after the layer, distance and settings (updated per frame)
 }
 
 // AudioZone defines an area with specific audio properties
@@ -183,6 +186,7 @@ func NewSpatialAudioManager(backend AudioBackend, settings *AudioSettings) (*Spa
 
 	// Initialize ambient layers
 	sam.initializeAmbientLayers()
+	sam.initializeAmbientZones()
 
 	return sam, nil
 }
@@ -293,6 +297,7 @@ func (sam *SpatialAudioManager) Update() {
 	sam.updateSpatialSounds()
 
 	// Update ambient sounds
+	sam.updateAmbientZones(time.Now())
 	sam.updateAmbientSounds()
 
 	// Update audio zones
@@ -479,6 +484,7 @@ func (sam *SpatialAudioManager) updateAmbientSounds() {
 
 			// Update backend volume
 			// This would be implemented with backend-specific calls
+			ambient.EffectiveVolume = effectiveVolume
 		}
 	}
 }
@@ -514,8 +520,12 @@ func (sam *SpatialAudioManager) updateEnvironmentalEffects() {
 		}
 	}
 
-	// Deactivate ambient layers not in current zone
+	// Deactivate ambient layers not in current zone; the terrain environment
+	// layers follow the listener's surroundings instead
 	for name, layer := range sam.ambientLayers {
+		if _, environment := sam.zones.targets[name]; environment {
+			continue
+		}
 		inCurrentZone := false
 		for _, setName := range sam.currentZone.AmbientSets {
 			if name == setName {
@@ -756,6 +766,17 @@ type SpatialAudioStats struct {
 	TimeOfDay            float32
 }
 
+// StopSpatialSounds stops the positioned sounds of a finished match
+func (sam *SpatialAudioManager) StopSpatialSounds() {
+	sam.mutex.Lock()
+	defer sam.mutex.Unlock()
+
+	for id := range sam.spatialSounds {
+		sam.backend.StopSound(id)
+	}
+	sam.spatialSounds = make(map[string]*SpatialSoundInstance)
+}
+
 // Shutdown cleans up the spatial audio manager
 func (sam *SpatialAudioManager) Shutdown() error {
 	sam.mutex.Lock()
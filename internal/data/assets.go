@@ -10,6 +10,7 @@ import (
 	"strings"
 	"sync"
 
+	"teraglest/internal/logging"
 	"teraglest/pkg/formats"
 )
 
@@ -28,6 +29,7 @@ const (
 // AssetManager handles loading, caching, and managing all game assets
 type AssetManager struct {
 	cache        *AssetCache
+	sounds       *AssetCache // Decoded sound effects, kept apart so models and textures do not evict them
 	techTreeRoot string    // Root path for tech tree assets
 	mutex        sync.Mutex // For thread-safe operations
 
@@ -35,12 +37,19 @@ type AssetManager struct {
 	techTree  *TechTree
 	resources []ResourceDefinition
 	factions  []FactionDefinition
+
+	// Faction discovery
+	factionsStamp  string           // Faction directories the factions were loaded from
+	brokenFactions map[string]error // Factions whose XML failed to load
+	catalog        []FactionInfo    // Validated faction metadata, for catalogStamp
+	catalogStamp   string
 }
 
 // NewAssetManager creates a new asset manager with the specified tech tree root
 func NewAssetManager(techTreeRoot string) *AssetManager {
 	return &AssetManager{
 		cache:        NewAssetCache(512, 1000), // 512MB cache, max 1000 entries
+		sounds:       NewAssetCache(soundCacheMB, soundCacheEntries),
 		techTreeRoot: techTreeRoot,
 	}
 }
@@ -54,7 +63,7 @@ func (am *AssetManager) LoadTechTree() (*TechTree, error) {
 		return am.techTree, nil
 	}
 
-	techTreePath := filepath.Join(am.techTreeRoot, "megapack.xml")
+	techTreePath := TechTreeXMLPath(am.techTreeRoot)
 
 	// Check cache first
 	if cached, found := am.cache.Get(techTreePath); found {
@@ -77,7 +86,7 @@ func (am *AssetManager) LoadTechTree() (*TechTree, error) {
 
 	err = am.cache.Put(techTreePath, techTree, string(AssetTypeXML), size)
 	if err != nil {
-		fmt.Printf("Warning: Failed to cache tech tree: %v\n", err)
+		logging.Warnf(logging.CategoryData, "Failed to cache tech tree: %v", err)
 	}
 
 	am.techTree = techTree
@@ -111,32 +120,38 @@ func (am *AssetManager) LoadResources() ([]ResourceDefinition, error) {
 	size := int64(len(resources) * 1024) // Rough estimate
 	err = am.cache.Put(resourcesPath, resources, string(AssetTypeXML), size)
 	if err != nil {
-		fmt.Printf("Warning: Failed to cache resources: %v\n", err)
+		logging.Warnf(logging.CategoryData, "Failed to cache resources: %v", err)
 	}
 
 	am.resources = resources
 	return resources, nil
 }
 
-// LoadFactions loads and caches all faction definitions
+// LoadFactions loads and caches all faction definitions. The factions
+// directory is rescanned on every call, so factions installed while the game
+// runs (e.g. by mods) are picked up. Factions whose XML fails to load are
+// skipped with a warning rather than failing the others; FactionCatalog
+// reports them.
 func (am *AssetManager) LoadFactions() ([]FactionDefinition, error) {
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
 
-	if am.factions != nil {
+	factionsPath := filepath.Join(am.techTreeRoot, "factions")
+	stamp := factionsStamp(factionsPath)
+	if am.factions != nil && stamp == am.factionsStamp {
 		return am.factions, nil
 	}
 
-	factionsPath := filepath.Join(am.techTreeRoot, "factions")
-
-	// Check cache first
-	if cached, found := am.cache.Get(factionsPath); found {
+	// Check cache first, unless the directory changed since it was filled
+	if stamp != am.factionsStamp {
+		am.cache.Remove(factionsPath)
+	} else if cached, found := am.cache.Get(factionsPath); found {
 		am.factions = cached.([]FactionDefinition)
 		return am.factions, nil
 	}
 
 	// Load from files
-	factions, err := LoadAllFactions(factionsPath)
+	factions, broken, err := loadFactionDirectory(factionsPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load factions: %w", err)
 	}
@@ -145,10 +160,12 @@ func (am *AssetManager) LoadFactions() ([]FactionDefinition, error) {
 	size := int64(len(factions) * 2048) // Rough estimate
 	err = am.cache.Put(factionsPath, factions, string(AssetTypeXML), size)
 	if err != nil {
-		fmt.Printf("Warning: Failed to cache factions: %v\n", err)
+		logging.Warnf(logging.CategoryData, "Failed to cache factions: %v", err)
 	}
 
 	am.factions = factions
+	am.brokenFactions = broken
+	am.factionsStamp = stamp
 	return factions, nil
 }
 
@@ -181,7 +198,7 @@ func (am *AssetManager) LoadUnit(factionName, unitName string) (*UnitDefinition,
 
 	err = am.cache.Put(unitPath, unitDef, string(AssetTypeXML), size)
 	if err != nil {
-		fmt.Printf("Warning: Failed to cache unit %s: %v\n", unitName, err)
+		logging.Warnf(logging.CategoryData, "Failed to cache unit %s: %v", unitName, err)
 	}
 
 	return unitDef, nil
@@ -212,7 +229,7 @@ func (am *AssetManager) LoadG3DModel(modelPath string) (*formats.G3DModel, error
 
 	err = am.cache.Put(fullPath, model, string(AssetTypeG3D), size)
 	if err != nil {
-		fmt.Printf("Warning: Failed to cache G3D model %s: %v\n", modelPath, err)
+		logging.Warnf(logging.CategoryData, "Failed to cache G3D model %s: %v", modelPath, err)
 	}
 
 	return model, nil
@@ -260,7 +277,7 @@ func (am *AssetManager) LoadTexture(texturePath string) (image.Image, error) {
 
 	err = am.cache.Put(fullPath, img, string(AssetTypeTexture), size)
 	if err != nil {
-		fmt.Printf("Warning: Failed to cache texture %s: %v\n", texturePath, err)
+		logging.Warnf(logging.CategoryData, "Failed to cache texture %s: %v", texturePath, err)
 	}
 
 	return img, nil
@@ -286,7 +303,7 @@ func (am *AssetManager) LoadAudio(audioPath string) ([]byte, error) {
 	size := int64(len(data))
 	err = am.cache.Put(fullPath, data, string(AssetTypeAudio), size)
 	if err != nil {
-		fmt.Printf("Warning: Failed to cache audio %s: %v\n", audioPath, err)
+		logging.Warnf(logging.CategoryData, "Failed to cache audio %s: %v", audioPath, err)
 	}
 
 	return data, nil
@@ -335,7 +352,7 @@ func (am *AssetManager) LoadFactionComplete(factionName string) (*FactionComplet
 		// Load unit definition
 		unit, err := am.LoadUnit(factionName, unitName)
 		if err != nil {
-			fmt.Printf("Warning: Failed to load unit %s: %v\n", unitName, err)
+			logging.Warnf(logging.CategoryData, "Failed to load unit %s: %v", unitName, err)
 			continue
 		}
 
@@ -351,7 +368,7 @@ func (am *AssetManager) LoadFactionComplete(factionName string) (*FactionComplet
 						modelPath := filepath.Join("factions", factionName, "units", unitName, "models", modelEntry.Name())
 						model, err := am.LoadG3DModel(modelPath)
 						if err != nil {
-							fmt.Printf("Warning: Failed to load model %s: %v\n", modelPath, err)
+							logging.Warnf(logging.CategoryData, "Failed to load model %s: %v", modelPath, err)
 							continue
 						}
 						result.Models[modelEntry.Name()] = model
@@ -379,12 +396,23 @@ func (am *AssetManager) resolvePath(assetPath string) string {
 	return filepath.Join(am.techTreeRoot, assetPath)
 }
 
+// TechTreeXMLPath returns the definition file of a tech tree directory, which
+// MegaGlest names after the directory (techs/megapack/megapack.xml)
+func TechTreeXMLPath(techTreeRoot string) string {
+	return filepath.Join(techTreeRoot, filepath.Base(filepath.Clean(techTreeRoot))+".xml")
+}
+
+// GetTechTreeRoot returns the root directory of the tech tree being loaded
+func (am *AssetManager) GetTechTreeRoot() string {
+	return am.techTreeRoot
+}
+
 // GetCacheStats returns current cache statistics
 func (am *AssetManager) GetCacheStats() CacheStats {
 	return am.cache.GetStats()
 }
 
-// PrintCacheStats prints cache statistics for debugging
+// PrintCacheStats logs cache statistics at debug level
 func (am *AssetManager) PrintCacheStats() {
 	am.cache.PrintStats()
 }
@@ -392,9 +420,14 @@ func (am *AssetManager) PrintCacheStats() {
 // ClearCache clears all cached assets
 func (am *AssetManager) ClearCache() {
 	am.cache.Clear()
+	am.sounds.Clear()
 	am.mutex.Lock()
 	am.techTree = nil
 	am.resources = nil
 	am.factions = nil
+	am.brokenFactions = nil
+	am.factionsStamp = ""
+	am.catalog = nil
+	am.catalogStamp = ""
 	am.mutex.Unlock()
 }
\ No newline at end of file
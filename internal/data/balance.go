@@ -0,0 +1,398 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// skillSpeedDivider converts MegaGlest skill speed into cycles per second:
+// one cycle of a skill with speed S takes 100/S seconds
+const skillSpeedDivider = 100.0
+
+// UnitBalanceStats holds the derived combat and economy stats of one unit type
+type UnitBalanceStats struct {
+	Faction          string
+	Unit             string
+	HP               int
+	Armor            int
+	ArmorType        string
+	Costs            map[string]int // Resource name -> amount
+	TotalCost        int            // Sum of positive resource costs
+	ProductionTime   int
+	AttackSkill      string // Highest DPS attack skill, empty for non-combat units
+	AttackType       string
+	Damage           int     // Average damage per hit before armor
+	AttacksPerSecond float64 // Attack cycles per second
+	DPS              float64 // Damage per second before armor and multipliers
+	AttackRange      int
+	HPPerCost        float64
+	DPSPerCost       float64
+	EffectiveHP      map[string]float64 // Attack type -> HP divided by the damage multiplier against this armor
+	TechDepth        int                // Production steps from the starting units, -1 if unreachable
+}
+
+// BalanceReport holds derived stats for every unit in a tech tree
+type BalanceReport struct {
+	TechTree    string
+	AttackTypes []string
+	Resources   []string // Resources that appear in any unit cost
+	Units       []UnitBalanceStats
+	Generated   time.Time
+}
+
+// AnalyzeBalance loads a tech tree and computes balance stats for all of its units
+func AnalyzeBalance(techTreeRoot string) (*BalanceReport, error) {
+	techTree, err := LoadTechTree(TechTreeXMLPath(techTreeRoot))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tech tree: %w", err)
+	}
+
+	factions, err := LoadAllFactions(filepath.Join(techTreeRoot, "factions"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load factions: %w", err)
+	}
+
+	report := &BalanceReport{
+		TechTree:  filepath.Base(techTreeRoot),
+		Generated: time.Now(),
+	}
+	for _, attackType := range techTree.AttackTypes {
+		report.AttackTypes = append(report.AttackTypes, attackType.Name)
+	}
+
+	resources := make(map[string]bool)
+	for _, faction := range factions {
+		factionDir := filepath.Join(techTreeRoot, "factions", faction.Name)
+
+		units := make(map[string]*Unit)
+		for _, name := range subdirectoryNames(filepath.Join(factionDir, "units")) {
+			unit, err := LoadUnit(filepath.Join(factionDir, "units", name, name+".xml"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to load unit %s/%s: %w", faction.Name, name, err)
+			}
+			units[name] = unit
+		}
+
+		upgrades, err := LoadAllUpgradesFromFaction(filepath.Join(factionDir, "upgrades"))
+		if err != nil {
+			return nil, err
+		}
+
+		depths := newTechDepthCalculator(&faction, units, upgrades)
+		for _, name := range sortedKeys(units) {
+			stats := analyzeUnit(techTree, faction.Name, name, units[name])
+			stats.TechDepth = depths.unitDepth(name)
+			for resource := range stats.Costs {
+				resources[resource] = true
+			}
+			report.Units = append(report.Units, stats)
+		}
+	}
+
+	report.Resources = sortedKeys(resources)
+	return report, nil
+}
+
+// analyzeUnit computes the stats that depend only on the unit itself and the tech tree
+func analyzeUnit(techTree *TechTree, factionName, unitName string, unit *Unit) UnitBalanceStats {
+	params := unit.Parameters
+	stats := UnitBalanceStats{
+		Faction:        factionName,
+		Unit:           unitName,
+		HP:             params.MaxHP.Value,
+		Armor:          params.Armor.Value,
+		ArmorType:      params.ArmorType.Value,
+		Costs:          make(map[string]int),
+		ProductionTime: params.Time.Value,
+		EffectiveHP:    make(map[string]float64),
+	}
+
+	for _, req := range params.ResourceRequirements {
+		stats.Costs[req.Name] += req.Amount
+		if req.Amount > 0 {
+			stats.TotalCost += req.Amount
+		}
+	}
+
+	for _, skill := range unit.Skills {
+		if skill.Type.Value != "attack" || skill.AttackStrength == nil {
+			continue
+		}
+		attacksPerSecond := float64(skill.Speed.Value) / skillSpeedDivider
+		dps := float64(skill.AttackStrength.Value) * attacksPerSecond
+		if stats.AttackSkill != "" && dps <= stats.DPS {
+			continue
+		}
+
+		stats.AttackSkill = skill.Name.Value
+		stats.Damage = skill.AttackStrength.Value
+		stats.AttacksPerSecond = attacksPerSecond
+		stats.DPS = dps
+		stats.AttackType = ""
+		if skill.AttackType != nil {
+			stats.AttackType = skill.AttackType.Value
+		}
+		stats.AttackRange = 0
+		if skill.AttackRange != nil {
+			stats.AttackRange = skill.AttackRange.Value
+		}
+	}
+
+	if stats.TotalCost > 0 {
+		stats.HPPerCost = float64(stats.HP) / float64(stats.TotalCost)
+		stats.DPSPerCost = stats.DPS / float64(stats.TotalCost)
+	}
+
+	for _, attackType := range techTree.AttackTypes {
+		multiplier := techTree.GetDamageMultiplier(attackType.Name, stats.ArmorType)
+		if multiplier > 0 {
+			stats.EffectiveHP[attackType.Name] = float64(stats.HP) / multiplier
+		}
+	}
+
+	return stats
+}
+
+// techDepthCalculator computes how many production steps each unit and upgrade
+// is from a faction's starting units
+type techDepthCalculator struct {
+	units     map[string]*Unit
+	upgrades  map[string]*Upgrade
+	producers map[string][]string // "unit:x" or "upgrade:x" -> units that create it
+	depths    map[string]int
+	visiting  map[string]bool
+}
+
+// newTechDepthCalculator indexes which units produce, build, morph into or research what
+func newTechDepthCalculator(faction *FactionDefinition, units map[string]*Unit, upgrades []UpgradeDefinition) *techDepthCalculator {
+	calc := &techDepthCalculator{
+		units:     units,
+		upgrades:  make(map[string]*Upgrade, len(upgrades)),
+		producers: make(map[string][]string),
+		depths:    make(map[string]int),
+		visiting:  make(map[string]bool),
+	}
+	for i := range upgrades {
+		calc.upgrades[upgrades[i].Name] = &upgrades[i].Upgrade
+	}
+
+	for _, starting := range faction.Faction.StartingUnits {
+		calc.depths["unit:"+starting.Name] = 0
+	}
+
+	for name, unit := range units {
+		for _, command := range unit.Commands {
+			if command.ProducedUnit != nil {
+				calc.addProducer("unit:"+command.ProducedUnit.Name, name)
+			}
+			if command.MorphUnit != nil {
+				calc.addProducer("unit:"+command.MorphUnit.Name, name)
+			}
+			for _, building := range command.Buildings {
+				calc.addProducer("unit:"+building.Name, name)
+			}
+			if command.ProducedUpgrade != nil {
+				calc.addProducer("upgrade:"+command.ProducedUpgrade.Name, name)
+			}
+		}
+	}
+	return calc
+}
+
+// addProducer records that producer can create key
+func (c *techDepthCalculator) addProducer(key, producer string) {
+	c.producers[key] = append(c.producers[key], producer)
+}
+
+// unitDepth returns the tech depth of a unit, -1 if it cannot be created
+func (c *techDepthCalculator) unitDepth(name string) int {
+	return c.depth("unit:" + name)
+}
+
+// depth is one more than the later of the cheapest producer and the deepest requirement
+func (c *techDepthCalculator) depth(key string) int {
+	if depth, known := c.depths[key]; known {
+		return depth
+	}
+	if c.visiting[key] {
+		return -1 // Cycle, not reachable through this path
+	}
+	c.visiting[key] = true
+	defer delete(c.visiting, key)
+
+	producerDepth := -1
+	for _, producer := range c.producers[key] {
+		if d := c.depth("unit:" + producer); d >= 0 && (producerDepth < 0 || d < producerDepth) {
+			producerDepth = d
+		}
+	}
+
+	result := -1
+	if producerDepth >= 0 {
+		deepest := producerDepth
+		reachable := true
+		for _, requirement := range c.requirements(key) {
+			d := c.depth(requirement)
+			if d < 0 {
+				reachable = false
+				break
+			}
+			if d > deepest {
+				deepest = d
+			}
+		}
+		if reachable {
+			result = deepest + 1
+		}
+	}
+
+	if result >= 0 {
+		c.depths[key] = result // Unreachable results may depend on the cycle guard, so they are not cached
+	}
+	return result
+}
+
+// requirements returns the unit and upgrade keys that must exist before key can be created
+func (c *techDepthCalculator) requirements(key string) []string {
+	var unitReqs, upgradeReqs []string
+	if name, ok := strings.CutPrefix(key, "unit:"); ok && c.units[name] != nil {
+		for _, req := range c.units[name].Parameters.UnitRequirements {
+			unitReqs = append(unitReqs, req.Name)
+		}
+		for _, req := range c.units[name].Parameters.UpgradeRequirements {
+			upgradeReqs = append(upgradeReqs, req.Name)
+		}
+	} else if name, ok := strings.CutPrefix(key, "upgrade:"); ok && c.upgrades[name] != nil {
+		for _, req := range c.upgrades[name].UnitRequirements {
+			unitReqs = append(unitReqs, req.Name)
+		}
+		for _, req := range c.upgrades[name].UpgradeRequirements {
+			upgradeReqs = append(upgradeReqs, req.Name)
+		}
+	}
+
+	keys := make([]string, 0, len(unitReqs)+len(upgradeReqs))
+	for _, name := range unitReqs {
+		keys = append(keys, "unit:"+name)
+	}
+	for _, name := range upgradeReqs {
+		keys = append(keys, "upgrade:"+name)
+	}
+	return keys
+}
+
+// formatBalanceFloat formats derived values with two decimals
+func formatBalanceFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// WriteCSV writes one row per unit, with a cost column per resource and an
+// effective HP column per attack type
+func (r *BalanceReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"faction", "unit", "hp", "armor", "armor_type", "total_cost", "production_time",
+		"attack_skill", "attack_type", "damage", "attacks_per_second", "dps", "attack_range",
+		"hp_per_cost", "dps_per_cost", "tech_depth"}
+	for _, resource := range r.Resources {
+		header = append(header, "cost_"+resource)
+	}
+	for _, attackType := range r.AttackTypes {
+		header = append(header, "ehp_vs_"+attackType)
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write balance CSV: %w", err)
+	}
+
+	for _, unit := range r.Units {
+		row := []string{unit.Faction, unit.Unit, strconv.Itoa(unit.HP), strconv.Itoa(unit.Armor), unit.ArmorType,
+			strconv.Itoa(unit.TotalCost), strconv.Itoa(unit.ProductionTime),
+			unit.AttackSkill, unit.AttackType, strconv.Itoa(unit.Damage),
+			formatBalanceFloat(unit.AttacksPerSecond), formatBalanceFloat(unit.DPS), strconv.Itoa(unit.AttackRange),
+			formatBalanceFloat(unit.HPPerCost), formatBalanceFloat(unit.DPSPerCost), strconv.Itoa(unit.TechDepth)}
+		for _, resource := range r.Resources {
+			row = append(row, strconv.Itoa(unit.Costs[resource]))
+		}
+		for _, attackType := range r.AttackTypes {
+			row = append(row, formatBalanceFloat(unit.EffectiveHP[attackType]))
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write balance CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write balance CSV: %w", err)
+	}
+	return nil
+}
+
+// balanceHTMLTemplate renders a report as a standalone HTML page
+var balanceHTMLTemplate = template.Must(template.New("balance").Funcs(template.FuncMap{
+	"num":  formatBalanceFloat,
+	"cost": func(unit UnitBalanceStats, resource string) int { return unit.Costs[resource] },
+	"ehp": func(unit UnitBalanceStats, attackType string) string {
+		return formatBalanceFloat(unit.EffectiveHP[attackType])
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Balance report: {{.TechTree}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: right; }
+th { background: #eee; }
+td.name { text-align: left; }
+tr.unreachable { color: #a00; }
+</style>
+</head>
+<body>
+<h1>Balance report: {{.TechTree}}</h1>
+<p>Generated {{.Generated.Format "2006-01-02 15:04"}}. DPS is before armor and damage multipliers; effective HP divides HP by the damage multiplier against the unit's armor type.</p>
+<table>
+<tr><th>Faction</th><th>Unit</th><th>HP</th><th>Armor</th><th>Armor type</th><th>Cost</th>{{range .Resources}}<th>{{.}}</th>{{end}}<th>Time</th><th>Attack</th><th>Attack type</th><th>Damage</th><th>DPS</th><th>Range</th><th>HP/cost</th><th>DPS/cost</th><th>Depth</th>{{range .AttackTypes}}<th>EHP vs {{.}}</th>{{end}}</tr>
+{{- $report := .}}
+{{- range .Units}}
+{{- $unit := .}}
+<tr{{if lt .TechDepth 0}} class="unreachable"{{end}}><td class="name">{{.Faction}}</td><td class="name">{{.Unit}}</td><td>{{.HP}}</td><td>{{.Armor}}</td><td class="name">{{.ArmorType}}</td><td>{{.TotalCost}}</td>{{range $report.Resources}}<td>{{cost $unit .}}</td>{{end}}<td>{{.ProductionTime}}</td><td class="name">{{.AttackSkill}}</td><td class="name">{{.AttackType}}</td><td>{{.Damage}}</td><td>{{num .DPS}}</td><td>{{.AttackRange}}</td><td>{{num .HPPerCost}}</td><td>{{num .DPSPerCost}}</td><td>{{.TechDepth}}</td>{{range $report.AttackTypes}}<td>{{ehp $unit .}}</td>{{end}}</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes the report as a standalone HTML table
+func (r *BalanceReport) WriteHTML(w io.Writer) error {
+	if err := balanceHTMLTemplate.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to write balance HTML: %w", err)
+	}
+	return nil
+}
+
+// SortBy orders units by a CSV column name (descending for numbers), keeping faction/unit order for ties
+func (r *BalanceReport) SortBy(column string) error {
+	keys := map[string]func(UnitBalanceStats) float64{
+		"hp":           func(u UnitBalanceStats) float64 { return float64(u.HP) },
+		"total_cost":   func(u UnitBalanceStats) float64 { return float64(u.TotalCost) },
+		"dps":          func(u UnitBalanceStats) float64 { return u.DPS },
+		"hp_per_cost":  func(u UnitBalanceStats) float64 { return u.HPPerCost },
+		"dps_per_cost": func(u UnitBalanceStats) float64 { return u.DPSPerCost },
+		"tech_depth":   func(u UnitBalanceStats) float64 { return float64(u.TechDepth) },
+	}
+	key, ok := keys[column]
+	if !ok {
+		return fmt.Errorf("cannot sort by %q", column)
+	}
+	sort.SliceStable(r.Units, func(i, j int) bool { return key(r.Units[i]) > key(r.Units[j]) })
+	return nil
+}
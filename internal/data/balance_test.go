@@ -0,0 +1,89 @@
+package data
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeBalance(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mini")
+	writeTestFile(t, root, "mini.xml", `<tech-tree>
+	<attack-types><attack-type name="sword"/><attack-type name="arrow"/></attack-types>
+	<armor-types><armor-type name="leather"/><armor-type name="stone"/></armor-types>
+	<damage-multipliers><damage-multiplier attack="arrow" armor="stone" value="0.5"/></damage-multipliers>
+</tech-tree>`)
+	writeTestFile(t, root, "factions/tech/tech.xml", `<faction>
+	<starting-resources><resource name="gold" amount="100"/></starting-resources>
+	<starting-units><unit name="worker" amount="1"/></starting-units>
+</faction>`)
+	writeTestFile(t, root, "factions/tech/units/worker/worker.xml", `<unit>
+	<parameters><max-hp value="100" regeneration="0"/><armor-type value="leather"/>
+		<resource-requirements><resource name="gold" amount="50"/></resource-requirements></parameters>
+	<commands><command><type value="build"/><name value="build"/><buildings><building name="barracks"/></buildings></command></commands>
+</unit>`)
+	writeTestFile(t, root, "factions/tech/units/barracks/barracks.xml", `<unit>
+	<parameters><max-hp value="1000" regeneration="0"/><armor-type value="stone"/>
+		<resource-requirements><resource name="gold" amount="200"/><resource name="wood" amount="100"/></resource-requirements></parameters>
+	<commands>
+		<command><type value="produce"/><name value="produce"/><produced-unit name="swordman"/></command>
+		<command><type value="upgrade"/><name value="research"/><produced-upgrade name="training"/></command>
+	</commands>
+</unit>`)
+	writeTestFile(t, root, "factions/tech/units/swordman/swordman.xml", `<unit>
+	<parameters><max-hp value="400" regeneration="0"/><armor-type value="leather"/><time value="30"/>
+		<resource-requirements><resource name="gold" amount="100"/><resource name="food" amount="1"/></resource-requirements>
+		<upgrade-requirements><upgrade name="training"/></upgrade-requirements></parameters>
+	<skills>
+		<skill><type value="attack"/><name value="slash"/><speed value="50"/><attack-strenght value="40"/><attack-type value="sword"/><attack-range value="1"/></skill>
+		<skill><type value="attack"/><name value="stab"/><speed value="100"/><attack-strenght value="30"/><attack-type value="sword"/><attack-range value="1"/></skill>
+	</skills>
+</unit>`)
+	writeTestFile(t, root, "factions/tech/units/golem/golem.xml", `<unit><parameters><max-hp value="10" regeneration="0"/></parameters></unit>`)
+	writeTestFile(t, root, "factions/tech/upgrades/training/training.xml", `<upgrade><effects><unit name="swordman"/></effects></upgrade>`)
+
+	report, err := AnalyzeBalance(root)
+	if err != nil {
+		t.Fatalf("AnalyzeBalance failed: %v", err)
+	}
+
+	stats := make(map[string]UnitBalanceStats)
+	for _, unit := range report.Units {
+		stats[unit.Unit] = unit
+	}
+
+	depths := map[string]int{"worker": 0, "barracks": 1, "swordman": 3, "golem": -1}
+	for name, want := range depths {
+		if stats[name].TechDepth != want {
+			t.Errorf("Expected %s tech depth %d, got %d", name, want, stats[name].TechDepth)
+		}
+	}
+
+	swordman := stats["swordman"]
+	if swordman.AttackSkill != "stab" || swordman.DPS != 30 || swordman.TotalCost != 101 {
+		t.Errorf("Unexpected swordman stats: %+v", swordman)
+	}
+	if barracks := stats["barracks"]; barracks.EffectiveHP["arrow"] != 2000 || barracks.EffectiveHP["sword"] != 1000 {
+		t.Errorf("Unexpected barracks effective HP: %v", barracks.EffectiveHP)
+	}
+	if strings.Join(report.Resources, ",") != "food,gold,wood" {
+		t.Errorf("Unexpected resources: %v", report.Resources)
+	}
+
+	var csvOut strings.Builder
+	if err := report.WriteCSV(&csvOut); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != 5 || !strings.HasSuffix(lines[0], "cost_food,cost_gold,cost_wood,ehp_vs_sword,ehp_vs_arrow") {
+		t.Errorf("Unexpected CSV output:\n%s", csvOut.String())
+	}
+
+	var htmlOut strings.Builder
+	if err := report.WriteHTML(&htmlOut); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(htmlOut.String(), "<td>2000.00</td>") || !strings.Contains(htmlOut.String(), `class="unreachable"`) {
+		t.Errorf("Unexpected HTML output:\n%s", htmlOut.String())
+	}
+}
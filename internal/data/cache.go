@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"teraglest/internal/logging"
 )
 
 // CacheEntry represents a cached asset with metadata
@@ -214,18 +216,18 @@ func (cache *AssetCache) evictOldest() {
 	}
 }
 
-// PrintStats prints cache statistics for debugging
+// PrintStats logs cache statistics at debug level
 func (cache *AssetCache) PrintStats() {
 	stats := cache.GetStats()
 
-	fmt.Println("Asset Cache Statistics:")
-	fmt.Printf("  Total Entries: %d\n", stats.TotalEntries)
-	fmt.Printf("  Cache Hits: %d\n", stats.Hits)
-	fmt.Printf("  Cache Misses: %d\n", stats.Misses)
-	fmt.Printf("  Hit Ratio: %.2f%%\n", stats.HitRatio*100)
-	fmt.Printf("  Memory Usage: %d MB\n", stats.MemoryUsageMB)
-	fmt.Println("  Assets by Type:")
+	logging.Debugf(logging.CategoryData, "Asset Cache Statistics:")
+	logging.Debugf(logging.CategoryData, "  Total Entries: %d", stats.TotalEntries)
+	logging.Debugf(logging.CategoryData, "  Cache Hits: %d", stats.Hits)
+	logging.Debugf(logging.CategoryData, "  Cache Misses: %d", stats.Misses)
+	logging.Debugf(logging.CategoryData, "  Hit Ratio: %.2f%%", stats.HitRatio*100)
+	logging.Debugf(logging.CategoryData, "  Memory Usage: %d MB", stats.MemoryUsageMB)
+	logging.Debugf(logging.CategoryData, "  Assets by Type:")
 	for assetType, count := range stats.AssetCounts {
-		fmt.Printf("    %s: %d\n", assetType, count)
+		logging.Debugf(logging.CategoryData, "    %s: %d", assetType, count)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,363 @@
+package data
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dependency node kinds
+const (
+	DependencyNodeUnit     = "unit"
+	DependencyNodeUpgrade  = "upgrade"
+	DependencyNodeResource = "resource"
+)
+
+// Dependency edge kinds
+const (
+	DependencyProduces   = "produces"
+	DependencyBuilds     = "builds"
+	DependencyMorphsInto = "morphs-into"
+	DependencyResearches = "researches"
+	DependencyHarvests   = "harvests"
+	DependencyAffects    = "affects"
+)
+
+// DependencyNode is a unit, upgrade or resource in the dependency graph
+type DependencyNode struct {
+	ID      string
+	Kind    string
+	Faction string // Empty for resources, which are shared by the tech tree
+	Name    string
+	Missing bool // Referenced but not defined
+}
+
+// DependencyEdge is a reference from one node to another
+type DependencyEdge struct {
+	From string
+	To   string
+	Kind string
+}
+
+// DependencyGraph records how units, upgrades and resources reference each other
+type DependencyGraph struct {
+	Nodes map[string]*DependencyNode
+	Edges []DependencyEdge
+}
+
+// NewDependencyGraph creates an empty dependency graph
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{Nodes: make(map[string]*DependencyNode)}
+}
+
+// dependencyNodeID returns the graph ID of a node
+func dependencyNodeID(kind, faction, name string) string {
+	if faction == "" {
+		return kind + ":" + name
+	}
+	return kind + ":" + faction + "/" + name
+}
+
+// AddNode adds a defined node and returns its ID
+func (g *DependencyGraph) AddNode(kind, faction, name string) string {
+	id := dependencyNodeID(kind, faction, name)
+	if node, exists := g.Nodes[id]; exists {
+		node.Missing = false
+		return id
+	}
+	g.Nodes[id] = &DependencyNode{ID: id, Kind: kind, Faction: faction, Name: name}
+	return id
+}
+
+// AddEdge adds a reference, creating the target as a missing node if it is not defined yet
+func (g *DependencyGraph) AddEdge(from, toKind, toFaction, toName, kind string) {
+	to := dependencyNodeID(toKind, toFaction, toName)
+	if _, exists := g.Nodes[to]; !exists {
+		g.Nodes[to] = &DependencyNode{ID: to, Kind: toKind, Faction: toFaction, Name: toName, Missing: true}
+	}
+	g.Edges = append(g.Edges, DependencyEdge{From: from, To: to, Kind: kind})
+}
+
+// Merge adds another graph's nodes and edges
+func (g *DependencyGraph) Merge(other *DependencyGraph) {
+	if other == nil {
+		return
+	}
+	for id, node := range other.Nodes {
+		if existing, exists := g.Nodes[id]; exists {
+			existing.Missing = existing.Missing && node.Missing
+			continue
+		}
+		copied := *node
+		g.Nodes[id] = &copied
+	}
+	g.Edges = append(g.Edges, other.Edges...)
+}
+
+// sortedNodeIDs returns node IDs in a stable order
+func (g *DependencyGraph) sortedNodeIDs() []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sortedEdges returns edges in a stable order
+func (g *DependencyGraph) sortedEdges() []DependencyEdge {
+	edges := append([]DependencyEdge(nil), g.Edges...)
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		return edges[i].Kind < edges[j].Kind
+	})
+	return edges
+}
+
+// WriteDOT writes the graph in Graphviz DOT format. Missing nodes are drawn dashed red.
+func (g *DependencyGraph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n\trankdir=LR;\n")
+
+	shapes := map[string]string{
+		DependencyNodeUnit:     "box",
+		DependencyNodeUpgrade:  "ellipse",
+		DependencyNodeResource: "diamond",
+	}
+	for _, id := range g.sortedNodeIDs() {
+		node := g.Nodes[id]
+		style := ""
+		if node.Missing {
+			style = `, style=dashed, color=red`
+		}
+		fmt.Fprintf(&b, "\t%q [label=%q, shape=%s%s];\n", id, node.Name, shapes[node.Kind], style)
+	}
+	for _, edge := range g.sortedEdges() {
+		fmt.Fprintf(&b, "\t%q -> %q [label=%q];\n", edge.From, edge.To, edge.Kind)
+	}
+	b.WriteString("}\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write dependency graph: %w", err)
+	}
+	return nil
+}
+
+// WriteText writes one "from -kind-> to" line per edge
+func (g *DependencyGraph) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, edge := range g.sortedEdges() {
+		missing := ""
+		if g.Nodes[edge.To].Missing {
+			missing = " (missing)"
+		}
+		fmt.Fprintf(&b, "%s -%s-> %s%s\n", edge.From, edge.Kind, edge.To, missing)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write dependency graph: %w", err)
+	}
+	return nil
+}
+
+// CrossReferenceRule checks that commands, skills and upgrades only reference things that exist
+type CrossReferenceRule struct{}
+
+func (r *CrossReferenceRule) Name() string { return "Cross Reference Validation" }
+func (r *CrossReferenceRule) Description() string {
+	return "Validates command skills, skill assets, produced units and upgrade effects"
+}
+
+func (r *CrossReferenceRule) Validate(validator *DataValidator, report *ValidationReport) error {
+	if validator.factions == nil {
+		return fmt.Errorf("factions not loaded for cross-reference validation")
+	}
+
+	validator.dependencyGraph = NewDependencyGraph()
+	for _, faction := range validator.factions {
+		validator.validateFactionReferences(faction.Name, report, validator.dependencyGraph)
+	}
+	return nil
+}
+
+// DependencyGraph returns the graph built by the last validation run, or nil
+func (v *DataValidator) DependencyGraph() *DependencyGraph {
+	return v.dependencyGraph
+}
+
+// validateFactionReferences checks every unit and upgrade of a faction and records their references in graph
+func (v *DataValidator) validateFactionReferences(factionName string, report *ValidationReport, graph *DependencyGraph) {
+	factionDir := filepath.Join(v.techTreeRoot, "factions", factionName)
+
+	units := make(map[string]*Unit)
+	for _, name := range subdirectoryNames(filepath.Join(factionDir, "units")) {
+		unit, err := LoadUnit(filepath.Join(factionDir, "units", name, name+".xml"))
+		if err != nil {
+			continue // Reported by the faction and schema rules
+		}
+		units[name] = unit
+		graph.AddNode(DependencyNodeUnit, factionName, name)
+	}
+
+	upgrades := make(map[string]*Upgrade)
+	for _, name := range subdirectoryNames(filepath.Join(factionDir, "upgrades")) {
+		upgrade, err := LoadUpgrade(filepath.Join(factionDir, "upgrades", name, name+".xml"))
+		if err != nil {
+			continue
+		}
+		upgrades[name] = upgrade
+		graph.AddNode(DependencyNodeUpgrade, factionName, name)
+	}
+
+	var resources map[string]bool
+	if v.resources != nil {
+		resources = make(map[string]bool, len(v.resources))
+		for _, res := range v.resources {
+			resources[res.Name] = true
+			graph.AddNode(DependencyNodeResource, "", res.Name)
+		}
+	}
+
+	for _, name := range sortedKeys(units) {
+		v.validateUnitReferences(factionName, name, units[name], units, upgrades, resources, report, graph)
+	}
+
+	for _, name := range sortedKeys(upgrades) {
+		upgradeFile := fmt.Sprintf("factions/%s/upgrades/%s/%s.xml", factionName, name, name)
+		from := dependencyNodeID(DependencyNodeUpgrade, factionName, name)
+		for _, effect := range upgrades[name].Effects {
+			graph.AddEdge(from, DependencyNodeUnit, factionName, effect.Name, DependencyAffects)
+			if units[effect.Name] == nil {
+				v.addIssue(report, ValidationError, "XML Reference",
+					fmt.Sprintf("Upgrade '%s' affects unknown unit '%s'", name, effect.Name),
+					upgradeFile, 0, "effects", effect.Name, "",
+					"Ensure the unit exists in this faction's units directory")
+			}
+		}
+	}
+}
+
+// validateUnitReferences checks one unit's commands and skills
+func (v *DataValidator) validateUnitReferences(factionName, unitName string, unit *Unit, units map[string]*Unit,
+	upgrades map[string]*Upgrade, resources map[string]bool, report *ValidationReport, graph *DependencyGraph) {
+	unitFile := fmt.Sprintf("factions/%s/units/%s/%s.xml", factionName, unitName, unitName)
+	unitDir := filepath.Join(v.techTreeRoot, "factions", factionName, "units", unitName)
+	from := dependencyNodeID(DependencyNodeUnit, factionName, unitName)
+
+	skills := make(map[string]bool, len(unit.Skills))
+	for _, skill := range unit.Skills {
+		skills[skill.Name.Value] = true
+
+		if !v.enableFileChecks {
+			continue
+		}
+		v.checkReferencedFile(report, unitDir, unitFile, unitName, skill.Name.Value, "animation", skill.Animation.Path)
+		if skill.Sound != nil && skill.Sound.Enabled {
+			for _, sound := range skill.Sound.SoundFiles {
+				v.checkReferencedFile(report, unitDir, unitFile, unitName, skill.Name.Value, "sound-file", sound.Path)
+			}
+		}
+	}
+
+	checkTarget := func(command, field, target string, exists bool, what, edgeKind, nodeKind string) {
+		graph.AddEdge(from, nodeKind, factionName, target, edgeKind)
+		if !exists {
+			v.addIssue(report, ValidationError, "XML Reference",
+				fmt.Sprintf("Command '%s' of unit '%s' references unknown %s '%s'", command, unitName, what, target),
+				unitFile, 0, field, target, "",
+				fmt.Sprintf("Ensure the %s exists in faction '%s'", what, factionName))
+		}
+	}
+
+	for _, command := range unit.Commands {
+		commandName := command.Name.Value
+
+		refs := command.SkillReferences()
+		elements := make([]string, 0, len(refs))
+		for element := range refs {
+			elements = append(elements, element)
+		}
+		sort.Strings(elements)
+		for _, element := range elements {
+			if !skills[refs[element]] {
+				v.addIssue(report, ValidationError, "XML Reference",
+					fmt.Sprintf("Command '%s' of unit '%s' references unknown skill '%s'", commandName, unitName, refs[element]),
+					unitFile, 0, element, refs[element], "",
+					"Define the skill in <skills> or fix the skill name")
+			}
+		}
+
+		if command.ProducedUnit != nil {
+			checkTarget(commandName, "produced-unit", command.ProducedUnit.Name,
+				units[command.ProducedUnit.Name] != nil, "unit", DependencyProduces, DependencyNodeUnit)
+		}
+		if command.MorphUnit != nil {
+			checkTarget(commandName, "morph-unit", command.MorphUnit.Name,
+				units[command.MorphUnit.Name] != nil, "unit", DependencyMorphsInto, DependencyNodeUnit)
+		}
+		for _, building := range command.Buildings {
+			checkTarget(commandName, "buildings", building.Name,
+				units[building.Name] != nil, "unit", DependencyBuilds, DependencyNodeUnit)
+		}
+		if command.ProducedUpgrade != nil {
+			checkTarget(commandName, "produced-upgrade", command.ProducedUpgrade.Name,
+				upgrades[command.ProducedUpgrade.Name] != nil, "upgrade", DependencyResearches, DependencyNodeUpgrade)
+		}
+		for _, resource := range command.HarvestedResources {
+			graph.AddEdge(from, DependencyNodeResource, "", resource.Name, DependencyHarvests)
+			if resources != nil && !resources[resource.Name] {
+				v.addIssue(report, ValidationError, "XML Reference",
+					fmt.Sprintf("Command '%s' of unit '%s' harvests unknown resource '%s'", commandName, unitName, resource.Name),
+					unitFile, 0, "harvested-resources", resource.Name, "",
+					"Ensure resource is defined in resources directory")
+			}
+		}
+	}
+}
+
+// checkReferencedFile reports a skill asset path (relative to the unit directory) that does not exist
+func (v *DataValidator) checkReferencedFile(report *ValidationReport, unitDir, unitFile, unitName, skillName, field, relPath string) {
+	if relPath == "" {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(unitDir, filepath.FromSlash(relPath))); os.IsNotExist(err) {
+		v.addIssue(report, ValidationError, "Asset Missing",
+			fmt.Sprintf("Skill '%s' of unit '%s' references missing %s: %s", skillName, unitName, field, relPath),
+			unitFile, 0, field, relPath, fmt.Sprintf("skill: %s", skillName),
+			"Fix the path or add the missing file")
+	}
+}
+
+// subdirectoryNames lists the directory names inside dir, or nil if it cannot be read
+func subdirectoryNames(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+// sortedKeys returns the keys of a name map in order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,107 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFile writes content to root/rel, creating directories as needed
+func writeTestFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCrossReferenceValidation(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "factions/tech/units/castle/castle.xml", `<unit>
+	<skills>
+		<skill><type value="stop"/><name value="stop_skill"/><animation path="models/castle.g3d"/></skill>
+		<skill><type value="produce"/><name value="produce_skill"/><animation path="models/castle.g3d"/></skill>
+	</skills>
+	<commands>
+		<command><type value="produce"/><name value="produce_worker"/>
+			<produce-skill value="produce_skill"/><produced-unit name="worker"/></command>
+		<command><type value="produce"/><name value="produce_knight"/>
+			<produce-skill value="produce_knigt"/><produced-unit name="knight"/></command>
+		<command><type value="upgrade"/><name value="research"/>
+			<upgrade-skill value="produce_skill"/><produced-upgrade name="armor"/></command>
+	</commands>
+</unit>`)
+	writeTestFile(t, root, "factions/tech/units/castle/models/castle.g3d", "")
+	writeTestFile(t, root, "factions/tech/units/worker/worker.xml", `<unit>
+	<skills>
+		<skill><type value="move"/><name value="move_skill"/><animation path="models/walk.g3d"/>
+			<sound enabled="true"><sound-file path="sounds/step.wav"/></sound></skill>
+	</skills>
+	<commands>
+		<command><type value="harvest"/><name value="harvest"/><move-skill value="move_skill"/>
+			<harvested-resources><resource name="gold"/><resource name="mana"/></harvested-resources></command>
+	</commands>
+</unit>`)
+	writeTestFile(t, root, "factions/tech/upgrades/armor/armor.xml", `<upgrade>
+	<effects><unit name="worker"/><unit name="archer"/></effects>
+</upgrade>`)
+
+	validator := NewDataValidator(root, NewAssetManager(root))
+	validator.resources = []ResourceDefinition{{Name: "gold"}}
+	report := NewValidationReport()
+	graph := NewDependencyGraph()
+	validator.validateFactionReferences("tech", report, graph)
+
+	expected := []string{
+		"references unknown skill 'produce_knigt'",
+		"references unknown unit 'knight'",
+		"harvests unknown resource 'mana'",
+		"references missing animation: models/walk.g3d",
+		"references missing sound-file: sounds/step.wav",
+		"affects unknown unit 'archer'",
+	}
+	for _, want := range expected {
+		found := false
+		for _, issue := range report.Issues {
+			if strings.Contains(issue.Message, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected issue containing %q", want)
+		}
+	}
+	if len(report.Issues) != len(expected) {
+		t.Errorf("Expected %d issues, got %d: %+v", len(expected), len(report.Issues), report.Issues)
+	}
+
+	var text strings.Builder
+	if err := graph.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"unit:tech/castle -produces-> unit:tech/worker\n",
+		"unit:tech/castle -produces-> unit:tech/knight (missing)\n",
+		"unit:tech/castle -researches-> upgrade:tech/armor\n",
+		"upgrade:tech/armor -affects-> unit:tech/worker\n",
+		"unit:tech/worker -harvests-> resource:gold\n",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Graph missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var dot strings.Builder
+	if err := graph.WriteDOT(&dot); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dot.String(), "digraph dependencies {") ||
+		!strings.Contains(dot.String(), `"unit:tech/knight" [label="knight", shape=box, style=dashed, color=red];`) {
+		t.Errorf("Unexpected DOT output:\n%s", dot.String())
+	}
+}
@@ -0,0 +1,263 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// loreFileName is the optional plain text file with the lore of a faction,
+// unit or upgrade, placed next to its XML definition
+const loreFileName = "lore.txt"
+
+// EntryKind groups encyclopedia entries
+type EntryKind string
+
+const (
+	EntryUnit     EntryKind = "unit"     // Units that can move
+	EntryBuilding EntryKind = "building" // Units without a move skill
+	EntryUpgrade  EntryKind = "upgrade"  // Researchable upgrades
+)
+
+// EntryKinds lists the kinds in the order the encyclopedia shows them
+var EntryKinds = []EntryKind{EntryUnit, EntryBuilding, EntryUpgrade}
+
+// EncyclopediaEntry describes one unit, building or upgrade of a faction
+type EncyclopediaEntry struct {
+	Kind         EntryKind
+	Faction      string
+	Name         string
+	Title        string            // Display name, e.g. "Battle Mage"
+	Lore         string            // Contents of the optional lore.txt
+	Model        string            // Preview model relative to the tech tree root, "" if none
+	Image        string            // Icon relative to the tech tree root, "" if none
+	Costs        map[string]int    // Resource name -> amount
+	Time         int               // Production or research time
+	Stats        *UnitBalanceStats // Combat and economy stats; nil for upgrades
+	Requirements []string          // Units and upgrades needed first
+	Produces     []string          // Units, buildings and upgrades this unit makes
+	Affects      []string          // Units an upgrade improves
+}
+
+// EncyclopediaFaction holds a faction's lore and entries
+type EncyclopediaFaction struct {
+	Name    string
+	Title   string
+	Lore    string
+	Entries []EncyclopediaEntry // Sorted by kind, then name
+}
+
+// EntriesOf returns the faction's entries of one kind
+func (f *EncyclopediaFaction) EntriesOf(kind EntryKind) []EncyclopediaEntry {
+	var entries []EncyclopediaEntry
+	for _, entry := range f.Entries {
+		if entry.Kind == kind {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Encyclopedia is the browsable reference of a tech tree, generated from its data
+type Encyclopedia struct {
+	TechTree string
+	Factions []EncyclopediaFaction // Sorted by name
+}
+
+// BuildEncyclopedia generates the encyclopedia of a tech tree
+func BuildEncyclopedia(techTreeRoot string) (*Encyclopedia, error) {
+	balance, err := AnalyzeBalance(techTreeRoot)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]UnitBalanceStats, len(balance.Units))
+	for _, unit := range balance.Units {
+		stats[unit.Faction+"/"+unit.Unit] = unit
+	}
+
+	factionNames := subdirectoryNames(filepath.Join(techTreeRoot, "factions"))
+	book := &Encyclopedia{TechTree: filepath.Base(techTreeRoot)}
+	for _, factionName := range factionNames {
+		factionDir := filepath.Join(techTreeRoot, "factions", factionName)
+		faction := EncyclopediaFaction{
+			Name:  factionName,
+			Title: DisplayName(factionName),
+			Lore:  readLore(factionDir),
+		}
+
+		var buildings []EncyclopediaEntry
+		for _, name := range subdirectoryNames(filepath.Join(factionDir, "units")) {
+			unitDir := filepath.Join(factionDir, "units", name)
+			unit, err := LoadUnit(filepath.Join(unitDir, name+".xml"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to load unit %s/%s: %w", factionName, name, err)
+			}
+			unitStats := stats[factionName+"/"+name]
+			entry := unitEntry(factionName, name, unitDir, unit, &unitStats)
+			if entry.Kind == EntryBuilding {
+				buildings = append(buildings, entry)
+			} else {
+				faction.Entries = append(faction.Entries, entry)
+			}
+		}
+		faction.Entries = append(faction.Entries, buildings...)
+
+		upgrades, err := LoadAllUpgradesFromFaction(filepath.Join(factionDir, "upgrades"))
+		if err != nil {
+			return nil, err
+		}
+		for _, upgrade := range upgrades {
+			upgradeDir := filepath.Join(factionDir, "upgrades", upgrade.Name)
+			faction.Entries = append(faction.Entries, upgradeEntry(factionName, upgrade, upgradeDir))
+		}
+
+		book.Factions = append(book.Factions, faction)
+	}
+	return book, nil
+}
+
+// Faction returns a faction by name, or nil
+func (e *Encyclopedia) Faction(name string) *EncyclopediaFaction {
+	for i := range e.Factions {
+		if e.Factions[i].Name == name {
+			return &e.Factions[i]
+		}
+	}
+	return nil
+}
+
+// Find returns the entry of a unit, building or upgrade, or nil
+func (e *Encyclopedia) Find(factionName, name string) *EncyclopediaEntry {
+	faction := e.Faction(factionName)
+	if faction == nil {
+		return nil
+	}
+	for i := range faction.Entries {
+		if faction.Entries[i].Name == name {
+			return &faction.Entries[i]
+		}
+	}
+	return nil
+}
+
+// DisplayName turns a data name into a title, e.g. "battle_mage" into "Battle Mage"
+func DisplayName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' })
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// unitKind tells buildings (no move skill) from units
+func unitKind(unit *Unit) EntryKind {
+	for _, skill := range unit.Skills {
+		if skill.Type.Value == "move" {
+			return EntryUnit
+		}
+	}
+	return EntryBuilding
+}
+
+// unitEntry builds the entry of a unit or building
+func unitEntry(factionName, name, unitDir string, unit *Unit, stats *UnitBalanceStats) EncyclopediaEntry {
+	params := unit.Parameters
+	relDir := path.Join("factions", factionName, "units", name)
+	entry := EncyclopediaEntry{
+		Kind:    unitKind(unit),
+		Faction: factionName,
+		Name:    name,
+		Title:   DisplayName(name),
+		Lore:    readLore(unitDir),
+		Model:   previewModel(relDir, unit),
+		Image:   relativeAsset(relDir, params.Image.Path),
+		Costs:   stats.Costs,
+		Time:    params.Time.Value,
+		Stats:   stats,
+	}
+	for _, req := range params.UnitRequirements {
+		entry.Requirements = append(entry.Requirements, req.Name)
+	}
+	for _, req := range params.UpgradeRequirements {
+		entry.Requirements = append(entry.Requirements, req.Name)
+	}
+	for _, command := range unit.Commands {
+		for _, building := range command.Buildings {
+			entry.Produces = append(entry.Produces, building.Name)
+		}
+		if command.ProducedUnit != nil {
+			entry.Produces = append(entry.Produces, command.ProducedUnit.Name)
+		}
+		if command.ProducedUpgrade != nil {
+			entry.Produces = append(entry.Produces, command.ProducedUpgrade.Name)
+		}
+		if command.MorphUnit != nil {
+			entry.Produces = append(entry.Produces, command.MorphUnit.Name)
+		}
+	}
+	return entry
+}
+
+// upgradeEntry builds the entry of an upgrade
+func upgradeEntry(factionName string, upgrade UpgradeDefinition, upgradeDir string) EncyclopediaEntry {
+	relDir := path.Join("factions", factionName, "upgrades", upgrade.Name)
+	entry := EncyclopediaEntry{
+		Kind:    EntryUpgrade,
+		Faction: factionName,
+		Name:    upgrade.Name,
+		Title:   DisplayName(upgrade.Name),
+		Lore:    readLore(upgradeDir),
+		Image:   relativeAsset(relDir, upgrade.Upgrade.Image.Path),
+		Costs:   make(map[string]int),
+		Time:    upgrade.Upgrade.Time.Value,
+	}
+	for _, req := range upgrade.Upgrade.ResourceRequirements {
+		entry.Costs[req.Name] += req.Amount
+	}
+	for _, req := range upgrade.Upgrade.UnitRequirements {
+		entry.Requirements = append(entry.Requirements, req.Name)
+	}
+	for _, req := range upgrade.Upgrade.UpgradeRequirements {
+		entry.Requirements = append(entry.Requirements, req.Name)
+	}
+	for _, effect := range upgrade.Upgrade.Effects {
+		entry.Affects = append(entry.Affects, effect.Name)
+	}
+	return entry
+}
+
+// previewModel picks the model of the standing animation, else of the first skill
+func previewModel(relDir string, unit *Unit) string {
+	model := ""
+	for _, skill := range unit.Skills {
+		if skill.Animation.Path == "" {
+			continue
+		}
+		if skill.Type.Value == "stop" {
+			return relativeAsset(relDir, skill.Animation.Path)
+		}
+		if model == "" {
+			model = relativeAsset(relDir, skill.Animation.Path)
+		}
+	}
+	return model
+}
+
+// relativeAsset resolves a path from a definition's directory against the tech tree root
+func relativeAsset(relDir, assetPath string) string {
+	if assetPath == "" {
+		return ""
+	}
+	return path.Join(relDir, filepath.ToSlash(assetPath))
+}
+
+// readLore returns the trimmed lore text of a definition directory, "" if it has none
+func readLore(dir string) string {
+	content, err := os.ReadFile(filepath.Join(dir, loreFileName))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
@@ -0,0 +1,76 @@
+package data
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildEncyclopedia(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mini")
+	writeTestFile(t, root, "mini.xml", `<tech-tree><attack-types><attack-type name="sword"/></attack-types></tech-tree>`)
+	writeTestFile(t, root, "factions/tech/tech.xml", `<faction><starting-units><unit name="worker" amount="1"/></starting-units></faction>`)
+	writeTestFile(t, root, "factions/tech/lore.txt", "Masters of steel.\n")
+	writeTestFile(t, root, "factions/tech/units/worker/worker.xml", `<unit>
+	<parameters><max-hp value="100" regeneration="0"/><image path="images/worker.bmp"/>
+		<resource-requirements><resource name="gold" amount="50"/></resource-requirements></parameters>
+	<skills>
+		<skill><type value="move"/><name value="move"/><animation path="models/worker_walking.g3d"/></skill>
+		<skill><type value="stop"/><name value="stop"/><animation path="models/worker_standing.g3d"/></skill>
+	</skills>
+	<commands><command><type value="build"/><name value="build"/><buildings><building name="barracks"/></buildings></command></commands>
+</unit>`)
+	writeTestFile(t, root, "factions/tech/units/worker/lore.txt", "Builds and gathers.")
+	writeTestFile(t, root, "factions/tech/units/barracks/barracks.xml", `<unit>
+	<parameters><max-hp value="1000" regeneration="0"/></parameters>
+	<skills><skill><type value="be_built"/><name value="be_built"/><animation path="models/barracks.g3d"/></skill></skills>
+	<commands><command><type value="upgrade"/><name value="research"/><produced-upgrade name="heavy_armor"/></command></commands>
+</unit>`)
+	writeTestFile(t, root, "factions/tech/upgrades/heavy_armor/heavy_armor.xml", `<upgrade>
+	<time value="60"/><unit-requirements><unit name="barracks"/></unit-requirements>
+	<resource-requirements><resource name="gold" amount="150"/></resource-requirements>
+	<effects><unit name="worker"/></effects>
+</upgrade>`)
+
+	book, err := BuildEncyclopedia(root)
+	if err != nil {
+		t.Fatalf("BuildEncyclopedia failed: %v", err)
+	}
+	faction := book.Faction("tech")
+	if faction == nil || faction.Lore != "Masters of steel." {
+		t.Fatalf("Unexpected faction: %+v", faction)
+	}
+
+	worker := book.Find("tech", "worker")
+	if worker == nil || worker.Kind != EntryUnit || worker.Lore != "Builds and gathers." {
+		t.Fatalf("Unexpected worker entry: %+v", worker)
+	}
+	if worker.Model != "factions/tech/units/worker/models/worker_standing.g3d" {
+		t.Errorf("Expected the standing model as preview, got %s", worker.Model)
+	}
+	if worker.Stats == nil || worker.Stats.HP != 100 || worker.Costs["gold"] != 50 {
+		t.Errorf("Unexpected worker stats: %+v", worker.Stats)
+	}
+	if strings.Join(worker.Produces, ",") != "barracks" {
+		t.Errorf("Unexpected worker products: %v", worker.Produces)
+	}
+
+	if barracks := book.Find("tech", "barracks"); barracks == nil || barracks.Kind != EntryBuilding {
+		t.Errorf("Expected barracks to be a building, got %+v", barracks)
+	}
+	armor := book.Find("tech", "heavy_armor")
+	if armor == nil || armor.Kind != EntryUpgrade || armor.Title != "Heavy Armor" || armor.Costs["gold"] != 150 {
+		t.Fatalf("Unexpected upgrade entry: %+v", armor)
+	}
+	if strings.Join(armor.Affects, ",") != "worker" || strings.Join(armor.Requirements, ",") != "barracks" {
+		t.Errorf("Unexpected upgrade references: %+v", armor)
+	}
+
+	kinds := make([]string, 0, len(faction.Entries))
+	for _, entry := range faction.Entries {
+		kinds = append(kinds, string(entry.Kind))
+	}
+	if strings.Join(kinds, ",") != "unit,building,upgrade" {
+		t.Errorf("Expected entries ordered by kind, got %v", kinds)
+	}
+}
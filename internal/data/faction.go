@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"teraglest/internal/logging"
 )
 
 // Faction represents a complete faction definition from faction.xml
@@ -48,6 +50,49 @@ type AIBehavior struct {
 	BuildingUnits        []AIUnit      `xml:"building-units>unit"`
 	Upgrades             []AIUpgrade   `xml:"upgrades>upgrade"`
 	StaticValues         []StaticValue `xml:"static-values>static"`
+
+	// Faction profile: how the AI adapts any personality to this faction
+	Compositions  []AIComposition        `xml:"compositions>composition"`
+	TechTimings   []AITechTiming         `xml:"tech-timings>tech"`
+	Counters      []AICounter            `xml:"counters>counter"`
+	Personalities []AIPersonalityProfile `xml:"personalities>personality"`
+}
+
+// AIComposition is the army the AI aims for in a phase of the game
+type AIComposition struct {
+	Phase string    `xml:"phase,attr"` // "early", "mid", "late" or "end"
+	Units []AIShare `xml:"unit"`
+}
+
+// AIShare is a unit type's share of an army composition
+type AIShare struct {
+	Name  string  `xml:"name,attr"`
+	Share float64 `xml:"share,attr"` // Fraction of the army, 0.0-1.0
+}
+
+// AITechTiming is when the AI starts researching an upgrade
+type AITechTiming struct {
+	Name   string  `xml:"name,attr"`
+	Minute float64 `xml:"minute,attr"` // Game minute from which the upgrade is due
+}
+
+// AICounter names a unit the AI builds against an enemy unit type
+type AICounter struct {
+	Unit    string `xml:"unit,attr"`    // Our unit
+	Against string `xml:"against,attr"` // Enemy unit type it counters
+}
+
+// AIPersonalityProfile adjusts a named AI personality for the faction;
+// attributes left out keep the personality's value
+type AIPersonalityProfile struct {
+	Name       string   `xml:"name,attr"` // Personality name, e.g. "balanced"
+	Aggression *float64 `xml:"aggression,attr"`
+	Economic   *float64 `xml:"economic,attr"`
+	Military   *float64 `xml:"military,attr"`
+	Expansion  *float64 `xml:"expansion,attr"`
+	Technology *float64 `xml:"technology,attr"`
+	Defensive  *float64 `xml:"defensive,attr"`
+	Risk       *float64 `xml:"risk,attr"`
 }
 
 // AIUnit represents an AI unit configuration with minimum requirements
@@ -111,7 +156,7 @@ func LoadAllFactions(factionsDir string) ([]FactionDefinition, error) {
 
 		// Check if the faction XML file exists
 		if _, err := os.Stat(factionXMLPath); os.IsNotExist(err) {
-			fmt.Printf("Warning: No XML file found for faction %s at %s\n", factionName, factionXMLPath)
+			logging.Warnf(logging.CategoryData, "No XML file found for faction %s at %s", factionName, factionXMLPath)
 			continue
 		}
 
@@ -130,38 +175,37 @@ func LoadAllFactions(factionsDir string) ([]FactionDefinition, error) {
 	return factions, nil
 }
 
-// PrintFactions prints all factions with their starting units and resources
+// PrintFactions logs all factions with their starting units and resources
 func PrintFactions(factions []FactionDefinition) {
-	fmt.Println("Factions:")
+	logging.Debugf(logging.CategoryData, "Factions:")
 	for i, faction := range factions {
-		fmt.Printf("  %d. %s\n", i+1, faction.Name)
+		logging.Debugf(logging.CategoryData, "  %d. %s", i+1, faction.Name)
 
 		// Print starting resources
-		fmt.Println("    Starting Resources:")
+		logging.Debugf(logging.CategoryData, "    Starting Resources:")
 		for _, res := range faction.Faction.StartingResources {
-			fmt.Printf("      %s: %d\n", res.Name, res.Amount)
+			logging.Debugf(logging.CategoryData, "      %s: %d", res.Name, res.Amount)
 		}
 
 		// Print starting units
-		fmt.Println("    Starting Units:")
+		logging.Debugf(logging.CategoryData, "    Starting Units:")
 		for _, unit := range faction.Faction.StartingUnits {
-			fmt.Printf("      %s: %d\n", unit.Name, unit.Amount)
+			logging.Debugf(logging.CategoryData, "      %s: %d", unit.Name, unit.Amount)
 		}
 
 		// Print music info if available
 		if faction.Faction.Music != nil && faction.Faction.Music.Value {
-			fmt.Printf("    Music: %s\n", faction.Faction.Music.Path)
+			logging.Debugf(logging.CategoryData, "    Music: %s", faction.Faction.Music.Path)
 		}
 
 		// Print AI behavior summary if available
 		if faction.Faction.AIBehavior != nil {
-			fmt.Printf("    AI: %d worker units, %d warrior units, %d upgrades\n",
+			logging.Debugf(logging.CategoryData, "    AI: %d worker units, %d warrior units, %d upgrades",
 				len(faction.Faction.AIBehavior.WorkerUnits),
 				len(faction.Faction.AIBehavior.WarriorUnits),
 				len(faction.Faction.AIBehavior.Upgrades))
 		}
 
-		fmt.Println()
 	}
 }
 
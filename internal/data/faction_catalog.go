@@ -0,0 +1,172 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"teraglest/internal/logging"
+)
+
+// factionIconNames are the files looked for, in order, as a faction's icon
+var factionIconNames = []string{"icon.png", "icon.jpg", "icon.bmp"}
+
+// FactionInfo describes a faction for the faction selection screen
+type FactionInfo struct {
+	Name        string // Directory name, used to select the faction
+	Title       string // Display name, e.g. "Tech"
+	Description string // Contents of the faction's optional lore.txt
+	Icon        string // Icon relative to the tech tree root, "" if none
+
+	// Validation
+	Playable  bool              // Loaded and validated without errors
+	LoadError string            // Why the faction XML failed to load ("" if it loaded)
+	Report    *ValidationReport // Validation result (nil when the XML failed to load)
+}
+
+// FactionCatalog lists every faction directory of the tech tree, including
+// ones installed since the last call, with its metadata and validation
+// result. Factions are validated once per change of the factions directory.
+func (am *AssetManager) FactionCatalog() ([]FactionInfo, error) {
+	factions, err := am.LoadFactions()
+	if err != nil {
+		return nil, err
+	}
+
+	am.mutex.Lock()
+	stamp, broken := am.factionsStamp, am.brokenFactions
+	if am.catalog != nil && am.catalogStamp == stamp {
+		catalog := append([]FactionInfo(nil), am.catalog...)
+		am.mutex.Unlock()
+		return catalog, nil
+	}
+	am.mutex.Unlock()
+
+	validator := NewDataValidator(am.techTreeRoot, am)
+	validator.resources, _ = am.LoadResources() // Starting resources are checked when available
+
+	catalog := make([]FactionInfo, 0, len(factions)+len(broken))
+	for i := range factions {
+		info := am.factionInfo(factions[i].Name, &factions[i])
+		report, err := validator.ValidateFaction(info.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate faction %s: %w", info.Name, err)
+		}
+		info.Report = report
+		info.Playable = report.ErrorCount == 0
+		if !info.Playable {
+			logging.Warnf(logging.CategoryData, "Faction %s has %d validation errors and cannot be played", info.Name, report.ErrorCount)
+		}
+		catalog = append(catalog, info)
+	}
+	for name, loadErr := range broken {
+		info := am.factionInfo(name, nil)
+		info.LoadError = loadErr.Error()
+		catalog = append(catalog, info)
+	}
+	sort.Slice(catalog, func(i, j int) bool { return catalog[i].Name < catalog[j].Name })
+
+	am.mutex.Lock()
+	am.catalog, am.catalogStamp = catalog, stamp
+	am.mutex.Unlock()
+	return append([]FactionInfo(nil), catalog...), nil
+}
+
+// PlayableFactions returns the names of the factions that can be picked for a match
+func (am *AssetManager) PlayableFactions() ([]string, error) {
+	catalog, err := am.FactionCatalog()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, info := range catalog {
+		if info.Playable {
+			names = append(names, info.Name)
+		}
+	}
+	return names, nil
+}
+
+// factionInfo gathers a faction's metadata; faction is nil when its XML
+// failed to load
+func (am *AssetManager) factionInfo(name string, faction *FactionDefinition) FactionInfo {
+	factionDir := filepath.Join(am.techTreeRoot, "factions", name)
+	info := FactionInfo{
+		Name:        name,
+		Title:       DisplayName(name),
+		Description: readLore(factionDir),
+	}
+
+	relDir := path.Join("factions", name)
+	for _, iconName := range factionIconNames {
+		if _, err := os.Stat(filepath.Join(factionDir, iconName)); err == nil {
+			info.Icon = path.Join(relDir, iconName)
+			return info
+		}
+	}
+
+	// Without an icon of its own, the faction shows its first starting unit
+	if faction != nil {
+		for _, start := range faction.Faction.StartingUnits {
+			unitDir := path.Join(relDir, "units", start.Name)
+			unit, err := LoadUnit(filepath.Join(am.techTreeRoot, filepath.FromSlash(unitDir), start.Name+".xml"))
+			if err == nil && unit.Parameters.Image.Path != "" {
+				info.Icon = relativeAsset(unitDir, unit.Parameters.Image.Path)
+				break
+			}
+		}
+	}
+	return info
+}
+
+// loadFactionDirectory loads every faction of a factions directory. Factions
+// whose XML fails to load are returned apart, so one broken faction does not
+// hide the others.
+func loadFactionDirectory(factionsDir string) ([]FactionDefinition, map[string]error, error) {
+	entries, err := os.ReadDir(factionsDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read factions directory %s: %w", factionsDir, err)
+	}
+
+	var factions []FactionDefinition
+	broken := make(map[string]error)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		factionName := entry.Name()
+		factionXMLPath := filepath.Join(factionsDir, factionName, factionName+".xml")
+		if _, err := os.Stat(factionXMLPath); os.IsNotExist(err) {
+			logging.Warnf(logging.CategoryData, "No XML file found for faction %s at %s", factionName, factionXMLPath)
+			continue
+		}
+
+		faction, err := LoadFaction(factionXMLPath)
+		if err != nil {
+			logging.Warnf(logging.CategoryData, "Skipping faction %s: %v", factionName, err)
+			broken[factionName] = err
+			continue
+		}
+		factions = append(factions, FactionDefinition{Name: factionName, Faction: *faction})
+	}
+	return factions, broken, nil
+}
+
+// factionsStamp identifies the state of a factions directory: its faction
+// directories and the size and modification time of their XML files. It
+// changes when a faction is installed, removed or edited.
+func factionsStamp(factionsDir string) string {
+	var stamp strings.Builder
+	for _, name := range subdirectoryNames(factionsDir) {
+		stamp.WriteString(name)
+		if info, err := os.Stat(filepath.Join(factionsDir, name, name+".xml")); err == nil {
+			fmt.Fprintf(&stamp, ":%d:%d", info.Size(), info.ModTime().UnixNano())
+		}
+		stamp.WriteByte('/')
+	}
+	return stamp.String()
+}
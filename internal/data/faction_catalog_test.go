@@ -0,0 +1,95 @@
+package data
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// writeTestWorker writes a complete worker unit and its model into a faction
+func writeTestWorker(t *testing.T, root, faction string) {
+	t.Helper()
+	unitDir := "factions/" + faction + "/units/worker/"
+	writeTestFile(t, root, unitDir+"worker.xml", `<unit>
+	<parameters>
+		<size value="1"/><height value="2"/><max-hp value="100" regeneration="0"/>
+		<armor value="0"/><armor-type value="leather"/><sight value="8"/><time value="20"/>
+		<fields><field value="land"/></fields><image path="images/worker.bmp"/>
+	</parameters>
+	<skills><skill><type value="stop"/><name value="stop"/><animation path="models/worker.g3d"/></skill></skills>
+	<commands/>
+</unit>`)
+	writeTestFile(t, root, unitDir+"models/worker.g3d", "g3d")
+	writeTestFile(t, root, unitDir+"images/worker.bmp", "bmp")
+}
+
+func TestFactionCatalog(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mini")
+	writeTestFile(t, root, "mini.xml", `<tech-tree>
+	<attack-types><attack-type name="sword"/></attack-types>
+	<armor-types><armor-type name="leather"/></armor-types>
+</tech-tree>`)
+	writeTestFile(t, root, "resources/gold/gold.xml", `<resource><image path="gold.bmp"/><type value="tech"/></resource>`)
+	writeTestFile(t, root, "factions/tech/tech.xml", `<faction>
+	<starting-resources><resource name="gold" amount="500"/></starting-resources>
+	<starting-units><unit name="worker" amount="1"/></starting-units>
+</faction>`)
+	writeTestFile(t, root, "factions/tech/lore.txt", "Masters of steel.\n")
+	writeTestWorker(t, root, "tech")
+
+	am := NewAssetManager(root)
+	catalog, err := am.FactionCatalog()
+	if err != nil {
+		t.Fatalf("FactionCatalog failed: %v", err)
+	}
+	if len(catalog) != 1 {
+		t.Fatalf("Expected 1 faction, got %+v", catalog)
+	}
+	tech := catalog[0]
+	if tech.Title != "Tech" || tech.Description != "Masters of steel." || tech.Icon != "factions/tech/units/worker/images/worker.bmp" {
+		t.Errorf("Unexpected faction metadata %+v", tech)
+	}
+
+	// Factions installed while the game runs are picked up and validated
+	writeTestFile(t, root, "factions/magic/magic.xml", `<faction><starting-resources/><starting-units/></faction>`)
+	writeTestFile(t, root, "factions/magic/icon.png", "png")
+	writeTestWorker(t, root, "magic")
+	writeTestFile(t, root, "factions/ghost/ghost.xml", `<faction><starting-units><unit name="phantom" amount="1"/></starting-units></faction>`)
+	writeTestFile(t, root, "factions/broken/broken.xml", `<faction><starting-units>`)
+
+	factions, err := am.LoadFactions()
+	if err != nil {
+		t.Fatalf("LoadFactions failed: %v", err)
+	}
+	if len(factions) != 3 {
+		t.Errorf("Expected the broken faction to be skipped, got %d factions", len(factions))
+	}
+
+	catalog, err = am.FactionCatalog()
+	if err != nil {
+		t.Fatalf("FactionCatalog failed: %v", err)
+	}
+	byName := make(map[string]FactionInfo)
+	for _, info := range catalog {
+		byName[info.Name] = info
+	}
+	if len(catalog) != 4 || catalog[0].Name != "broken" {
+		t.Fatalf("Expected 4 factions sorted by name, got %+v", catalog)
+	}
+	if magic := byName["magic"]; !magic.Playable || magic.Icon != "factions/magic/icon.png" {
+		t.Errorf("Expected magic to be playable with its own icon, got %+v", magic)
+	}
+	if ghost := byName["ghost"]; ghost.Playable || ghost.Report == nil || ghost.Report.ErrorCount == 0 {
+		t.Errorf("Expected ghost to fail validation for its missing starting unit, got %+v", ghost)
+	}
+	if broken := byName["broken"]; broken.Playable || broken.LoadError == "" || broken.Report != nil {
+		t.Errorf("Expected broken to report its load error, got %+v", broken)
+	}
+
+	playable, err := am.PlayableFactions()
+	if err != nil {
+		t.Fatalf("PlayableFactions failed: %v", err)
+	}
+	if len(playable) != 2 || playable[0] != "magic" || playable[1] != "tech" {
+		t.Errorf("Expected magic and tech to be playable, got %v", playable)
+	}
+}
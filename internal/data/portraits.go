@@ -0,0 +1,61 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UnitPortrait names the assets a unit's portrait is drawn from, relative to
+// the tech tree root
+type UnitPortrait struct {
+	Image string // Icon the unit ships in a format LoadTexture reads, "" if none
+	Model string // Model to render an icon from when there is no image, "" if none
+}
+
+// FactionUnitNames returns the names of a faction's units and buildings, sorted
+func (am *AssetManager) FactionUnitNames(factionName string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(am.techTreeRoot, "factions", factionName, "units"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read units of faction %s: %w", factionName, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// UnitPortrait returns where a unit's portrait comes from. Images in formats
+// the texture loader cannot read (MegaGlest ships .bmp) or that are missing
+// count as no image, so an icon is rendered from the model instead.
+func (am *AssetManager) UnitPortrait(factionName, unitName string) (UnitPortrait, error) {
+	unit, err := am.LoadUnit(factionName, unitName)
+	if err != nil {
+		return UnitPortrait{}, err
+	}
+	relDir := path.Join("factions", factionName, "units", unitName)
+	portrait := UnitPortrait{Model: previewModel(relDir, &unit.Unit)}
+	if image := relativeAsset(relDir, unit.Unit.Parameters.Image.Path); textureFormat(image) {
+		if _, err := os.Stat(am.resolvePath(filepath.FromSlash(image))); err == nil {
+			portrait.Image = image
+		}
+	}
+	return portrait, nil
+}
+
+// textureFormat reports whether LoadTexture can decode an image file
+func textureFormat(image string) bool {
+	switch strings.ToLower(path.Ext(image)) {
+	case ".png", ".jpg", ".jpeg":
+		return true
+	default:
+		return false
+	}
+}
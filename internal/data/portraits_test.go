@@ -0,0 +1,32 @@
+package data
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestUnitPortrait tests choosing between a shipped icon and a model to render one from
+func TestUnitPortrait(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mini")
+	writeTestWorker(t, root, "tech")
+	writeTestFile(t, root, "factions/tech/units/notes.txt", "not a unit")
+
+	am := NewAssetManager(root)
+	names, err := am.FactionUnitNames("tech")
+	if err != nil || len(names) != 1 || names[0] != "worker" {
+		t.Fatalf("Expected only the worker, got %v, %v", names, err)
+	}
+
+	// The worker ships a .bmp, which cannot be loaded as a texture
+	portrait, err := am.UnitPortrait("tech", "worker")
+	if err != nil {
+		t.Fatalf("UnitPortrait failed: %v", err)
+	}
+	if portrait.Image != "" || portrait.Model != "factions/tech/units/worker/models/worker.g3d" {
+		t.Errorf("Expected a portrait rendered from the model, got %+v", portrait)
+	}
+
+	if _, err := am.FactionUnitNames("ghost"); err == nil {
+		t.Error("Expected a missing faction to fail")
+	}
+}
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AssetProvider supplies the tech tree, resource, faction and unit data the
+// game engine needs. AssetManager reads it from a tech tree directory;
+// MemoryAssetProvider holds definitions built in code for tests and demos.
+type AssetProvider interface {
+	LoadTechTree() (*TechTree, error)
+	LoadResources() ([]ResourceDefinition, error)
+	LoadFactions() ([]FactionDefinition, error)
+	LoadUnit(factionName, unitName string) (*UnitDefinition, error)
+	GetTechTreeRoot() string
+}
+
+var (
+	_ AssetProvider = (*AssetManager)(nil)
+	_ AssetProvider = (*MemoryAssetProvider)(nil)
+)
+
+// MemoryAssetProvider is an in-memory AssetProvider with programmatically defined data
+type MemoryAssetProvider struct {
+	techTree  *TechTree
+	resources []ResourceDefinition
+	factions  map[string]*Faction
+	units     map[string]map[string]*UnitDefinition // faction -> unit name -> definition
+	mutex     sync.RWMutex
+}
+
+// NewMemoryAssetProvider creates an empty in-memory asset provider
+func NewMemoryAssetProvider() *MemoryAssetProvider {
+	return &MemoryAssetProvider{
+		techTree: &TechTree{},
+		factions: make(map[string]*Faction),
+		units:    make(map[string]map[string]*UnitDefinition),
+	}
+}
+
+// SetTechTree replaces the tech tree returned by LoadTechTree
+func (mp *MemoryAssetProvider) SetTechTree(techTree *TechTree) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	mp.techTree = techTree
+}
+
+// AddResource adds a resource type
+func (mp *MemoryAssetProvider) AddResource(name string, resource Resource) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	mp.resources = append(mp.resources, ResourceDefinition{Name: name, Resource: resource})
+}
+
+// AddFaction adds or replaces a faction
+func (mp *MemoryAssetProvider) AddFaction(name string, faction Faction) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	mp.factions[name] = &faction
+}
+
+// AddUnit adds or replaces a unit of a faction, creating the faction if needed
+func (mp *MemoryAssetProvider) AddUnit(factionName string, unit *UnitDefinition) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	if _, exists := mp.factions[factionName]; !exists {
+		mp.factions[factionName] = &Faction{}
+	}
+	if mp.units[factionName] == nil {
+		mp.units[factionName] = make(map[string]*UnitDefinition)
+	}
+	mp.units[factionName][unit.Name] = unit
+}
+
+// LoadTechTree returns the configured tech tree
+func (mp *MemoryAssetProvider) LoadTechTree() (*TechTree, error) {
+	mp.mutex.RLock()
+	defer mp.mutex.RUnlock()
+	return mp.techTree, nil
+}
+
+// LoadResources returns all added resource types
+func (mp *MemoryAssetProvider) LoadResources() ([]ResourceDefinition, error) {
+	mp.mutex.RLock()
+	defer mp.mutex.RUnlock()
+	return append([]ResourceDefinition(nil), mp.resources...), nil
+}
+
+// LoadFactions returns all factions sorted by name
+func (mp *MemoryAssetProvider) LoadFactions() ([]FactionDefinition, error) {
+	mp.mutex.RLock()
+	defer mp.mutex.RUnlock()
+
+	factions := make([]FactionDefinition, 0, len(mp.factions))
+	for _, name := range sortedKeys(mp.factions) {
+		factions = append(factions, FactionDefinition{Name: name, Faction: *mp.factions[name]})
+	}
+	return factions, nil
+}
+
+// LoadUnit returns a unit definition of a faction
+func (mp *MemoryAssetProvider) LoadUnit(factionName, unitName string) (*UnitDefinition, error) {
+	mp.mutex.RLock()
+	defer mp.mutex.RUnlock()
+
+	unit, exists := mp.units[factionName][unitName]
+	if !exists {
+		return nil, fmt.Errorf("failed to load unit %s/%s: not defined", factionName, unitName)
+	}
+	return unit, nil
+}
+
+// GetTechTreeRoot returns an empty root since nothing is read from disk
+func (mp *MemoryAssetProvider) GetTechTreeRoot() string {
+	return ""
+}
+
+// UnitNames returns the unit names defined for a faction, sorted
+func (mp *MemoryAssetProvider) UnitNames(factionName string) []string {
+	mp.mutex.RLock()
+	defer mp.mutex.RUnlock()
+
+	return sortedKeys(mp.units[factionName])
+}
+
+// NewSimpleUnit builds a unit definition with the stats the engine reads most
+// often, for tests and demos that don't need a full XML definition
+func NewSimpleUnit(name string, maxHP, armor int, armorType string, cost map[string]int) *UnitDefinition {
+	unit := &UnitDefinition{
+		Name: name,
+		Unit: Unit{
+			Parameters: UnitParameters{
+				MaxHP:     UnitHP{Value: maxHP},
+				Armor:     UnitArmor{Value: armor},
+				ArmorType: UnitArmorType{Value: armorType},
+			},
+		},
+	}
+	for _, resource := range sortedKeys(cost) {
+		unit.Unit.Parameters.ResourceRequirements = append(unit.Unit.Parameters.ResourceRequirements,
+			ResourceRequirement{Name: resource, Amount: cost[resource]})
+	}
+	return unit
+}
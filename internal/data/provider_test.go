@@ -0,0 +1,51 @@
+package data
+
+import "testing"
+
+func TestMemoryAssetProvider(t *testing.T) {
+	var provider AssetProvider = NewMemoryAssetProvider()
+	memory := provider.(*MemoryAssetProvider)
+
+	memory.AddFaction("tech", Faction{StartingResources: []StartingResource{{Name: "gold", Amount: 500}}})
+	memory.AddUnit("tech", NewSimpleUnit("worker", 300, 2, "leather", map[string]int{"gold": 50, "food": 1}))
+	memory.AddUnit("magic", NewSimpleUnit("initiate", 200, 0, "organic", nil))
+	memory.AddResource("gold", Resource{})
+
+	factions, err := provider.LoadFactions()
+	if err != nil {
+		t.Fatalf("LoadFactions failed: %v", err)
+	}
+	if len(factions) != 2 || factions[0].Name != "magic" || factions[1].Name != "tech" {
+		t.Fatalf("Expected factions [magic tech], got %v", factions)
+	}
+	if factions[1].Faction.StartingResources[0].Amount != 500 {
+		t.Error("Faction data should be preserved")
+	}
+
+	worker, err := provider.LoadUnit("tech", "worker")
+	if err != nil {
+		t.Fatalf("LoadUnit failed: %v", err)
+	}
+	if worker.Unit.Parameters.MaxHP.Value != 300 || worker.Unit.Parameters.ArmorType.Value != "leather" {
+		t.Errorf("Unexpected worker stats: %+v", worker.Unit.Parameters)
+	}
+	requirements := worker.Unit.Parameters.ResourceRequirements
+	if len(requirements) != 2 || requirements[0].Name != "food" || requirements[1].Amount != 50 {
+		t.Errorf("Expected sorted costs [food gold], got %v", requirements)
+	}
+
+	if _, err := provider.LoadUnit("tech", "knight"); err == nil {
+		t.Error("Expected an error for an undefined unit")
+	}
+	if names := memory.UnitNames("tech"); len(names) != 1 || names[0] != "worker" {
+		t.Errorf("Expected unit names [worker], got %v", names)
+	}
+
+	resources, _ := provider.LoadResources()
+	if len(resources) != 1 || resources[0].Name != "gold" {
+		t.Errorf("Expected resource gold, got %v", resources)
+	}
+	if techTree, _ := provider.LoadTechTree(); techTree == nil {
+		t.Error("LoadTechTree should return an empty tech tree by default")
+	}
+}
@@ -0,0 +1,256 @@
+package data
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AssetIndexFile is the name of the asset index at the root of a served tech tree
+const AssetIndexFile = "assets.json"
+
+// AssetIndex lists the data files of a tech tree, for providers that fetch
+// files one by one and cannot list directories
+type AssetIndex struct {
+	TechTree  string              `json:"techtree"`  // Tech tree XML, relative to the root
+	Resources []string            `json:"resources"` // Resource names, sorted
+	Factions  map[string][]string `json:"factions"`  // Faction name -> unit names, sorted
+}
+
+// BuildAssetIndex lists the resources, factions and units of a tech tree directory
+func BuildAssetIndex(techTreeRoot string) (*AssetIndex, error) {
+	index := &AssetIndex{
+		TechTree: filepath.ToSlash(filepath.Base(TechTreeXMLPath(techTreeRoot))),
+		Factions: make(map[string][]string),
+	}
+
+	resources, err := indexDirectory(filepath.Join(techTreeRoot, "resources"))
+	if err != nil {
+		return nil, err
+	}
+	index.Resources = resources
+
+	factions, err := indexDirectory(filepath.Join(techTreeRoot, "factions"))
+	if err != nil {
+		return nil, err
+	}
+	for _, faction := range factions {
+		units, err := indexDirectory(filepath.Join(techTreeRoot, "factions", faction, "units"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		index.Factions[faction] = units
+	}
+	return index, nil
+}
+
+// indexDirectory returns the sorted names of the subdirectories holding an XML file named after them
+func indexDirectory(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), entry.Name()+".xml")); err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// WriteAssetIndex builds the asset index of a tech tree and writes it to its root
+func WriteAssetIndex(techTreeRoot string) error {
+	index, err := BuildAssetIndex(techTreeRoot)
+	if err != nil {
+		return fmt.Errorf("failed to index tech tree %s: %w", techTreeRoot, err)
+	}
+	content, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(techTreeRoot, AssetIndexFile), content, 0644)
+}
+
+// RemoteAssetProvider is an AssetProvider reading a tech tree over HTTP, as
+// served with its asset index. In browser builds requests go through fetch.
+type RemoteAssetProvider struct {
+	baseURL string
+	client  *http.Client
+	mutex   sync.Mutex
+
+	index     *AssetIndex
+	techTree  *TechTree
+	resources []ResourceDefinition
+	factions  []FactionDefinition
+	units     map[string]*UnitDefinition // "faction/unit" -> definition
+}
+
+var _ AssetProvider = (*RemoteAssetProvider)(nil)
+
+// NewRemoteAssetProvider creates a provider reading the tech tree served at baseURL
+func NewRemoteAssetProvider(baseURL string) *RemoteAssetProvider {
+	return &RemoteAssetProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  http.DefaultClient,
+		units:   make(map[string]*UnitDefinition),
+	}
+}
+
+// fetch downloads a file relative to the tech tree root
+func (rp *RemoteAssetProvider) fetch(relPath string) ([]byte, error) {
+	url := rp.baseURL + "/" + path.Clean(relPath)
+	response, err := rp.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, response.Status)
+	}
+	return io.ReadAll(response.Body)
+}
+
+// fetchXML downloads and parses an XML file relative to the tech tree root
+func (rp *RemoteAssetProvider) fetchXML(relPath string, value interface{}) error {
+	content, err := rp.fetch(relPath)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(content, value); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", relPath, err)
+	}
+	return nil
+}
+
+// loadIndex fetches the asset index once. Callers hold the mutex.
+func (rp *RemoteAssetProvider) loadIndex() (*AssetIndex, error) {
+	if rp.index != nil {
+		return rp.index, nil
+	}
+	content, err := rp.fetch(AssetIndexFile)
+	if err != nil {
+		return nil, err
+	}
+	var index AssetIndex
+	if err := json.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", AssetIndexFile, err)
+	}
+	rp.index = &index
+	return rp.index, nil
+}
+
+// LoadTechTree fetches and caches the tech tree
+func (rp *RemoteAssetProvider) LoadTechTree() (*TechTree, error) {
+	rp.mutex.Lock()
+	defer rp.mutex.Unlock()
+
+	if rp.techTree != nil {
+		return rp.techTree, nil
+	}
+	index, err := rp.loadIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tech tree: %w", err)
+	}
+	var techTree TechTree
+	if err := rp.fetchXML(index.TechTree, &techTree); err != nil {
+		return nil, fmt.Errorf("failed to load tech tree: %w", err)
+	}
+	rp.techTree = &techTree
+	return rp.techTree, nil
+}
+
+// LoadResources fetches and caches the resources listed in the index
+func (rp *RemoteAssetProvider) LoadResources() ([]ResourceDefinition, error) {
+	rp.mutex.Lock()
+	defer rp.mutex.Unlock()
+
+	if rp.resources != nil {
+		return rp.resources, nil
+	}
+	index, err := rp.loadIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load resources: %w", err)
+	}
+	resources := make([]ResourceDefinition, 0, len(index.Resources))
+	for _, name := range index.Resources {
+		var resource Resource
+		if err := rp.fetchXML(path.Join("resources", name, name+".xml"), &resource); err != nil {
+			return nil, fmt.Errorf("failed to load resource %s: %w", name, err)
+		}
+		resources = append(resources, ResourceDefinition{Name: name, Resource: resource})
+	}
+	rp.resources = resources
+	return resources, nil
+}
+
+// LoadFactions fetches and caches the factions listed in the index, sorted by name
+func (rp *RemoteAssetProvider) LoadFactions() ([]FactionDefinition, error) {
+	rp.mutex.Lock()
+	defer rp.mutex.Unlock()
+
+	if rp.factions != nil {
+		return rp.factions, nil
+	}
+	index, err := rp.loadIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load factions: %w", err)
+	}
+	factions := make([]FactionDefinition, 0, len(index.Factions))
+	for _, name := range sortedKeys(index.Factions) {
+		var faction Faction
+		if err := rp.fetchXML(path.Join("factions", name, name+".xml"), &faction); err != nil {
+			return nil, fmt.Errorf("failed to load faction %s: %w", name, err)
+		}
+		factions = append(factions, FactionDefinition{Name: name, Faction: faction})
+	}
+	rp.factions = factions
+	return factions, nil
+}
+
+// LoadUnit fetches and caches a unit definition of a faction
+func (rp *RemoteAssetProvider) LoadUnit(factionName, unitName string) (*UnitDefinition, error) {
+	rp.mutex.Lock()
+	defer rp.mutex.Unlock()
+
+	key := factionName + "/" + unitName
+	if unit, cached := rp.units[key]; cached {
+		return unit, nil
+	}
+	var unit Unit
+	if err := rp.fetchXML(path.Join("factions", factionName, "units", unitName, unitName+".xml"), &unit); err != nil {
+		return nil, fmt.Errorf("failed to load unit %s/%s: %w", factionName, unitName, err)
+	}
+	definition := &UnitDefinition{Name: unitName, Unit: unit}
+	rp.units[key] = definition
+	return definition, nil
+}
+
+// UnitNames returns the unit names the index lists for a faction
+func (rp *RemoteAssetProvider) UnitNames(factionName string) ([]string, error) {
+	rp.mutex.Lock()
+	defer rp.mutex.Unlock()
+
+	index, err := rp.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	return index.Factions[factionName], nil
+}
+
+// GetTechTreeRoot returns the URL the tech tree is served at
+func (rp *RemoteAssetProvider) GetTechTreeRoot() string {
+	return rp.baseURL
+}
@@ -0,0 +1,61 @@
+package data
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoteAssetProvider(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mini")
+	writeTestFile(t, root, "mini.xml", `<tech-tree>
+	<attack-types><attack-type name="sword"/></attack-types>
+	<armor-types><armor-type name="leather"/></armor-types>
+</tech-tree>`)
+	writeTestFile(t, root, "resources/gold/gold.xml", `<resource><image path="gold.bmp"/><type value="tech"/></resource>`)
+	writeTestFile(t, root, "resources/notes/readme.txt", "not a resource")
+	writeTestFile(t, root, "factions/tech/tech.xml", `<faction>
+	<starting-resources><resource name="gold" amount="500"/></starting-resources>
+</faction>`)
+	writeTestWorker(t, root, "tech")
+
+	if err := WriteAssetIndex(root); err != nil {
+		t.Fatalf("WriteAssetIndex failed: %v", err)
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(root)))
+	defer server.Close()
+
+	var provider AssetProvider = NewRemoteAssetProvider(server.URL + "/")
+	techTree, err := provider.LoadTechTree()
+	if err != nil {
+		t.Fatalf("LoadTechTree failed: %v", err)
+	}
+	if len(techTree.AttackTypes) != 1 || techTree.AttackTypes[0].Name != "sword" {
+		t.Errorf("Unexpected attack types %+v", techTree.AttackTypes)
+	}
+
+	resources, err := provider.LoadResources()
+	if err != nil || len(resources) != 1 || resources[0].Name != "gold" {
+		t.Errorf("Expected only the gold resource, got %+v (%v)", resources, err)
+	}
+	factions, err := provider.LoadFactions()
+	if err != nil || len(factions) != 1 || factions[0].Faction.StartingResources[0].Amount != 500 {
+		t.Errorf("Expected the tech faction with 500 gold, got %+v (%v)", factions, err)
+	}
+
+	names, _ := provider.(*RemoteAssetProvider).UnitNames("tech")
+	if len(names) != 1 || names[0] != "worker" {
+		t.Fatalf("Expected the index to list the worker, got %v", names)
+	}
+	worker, err := provider.LoadUnit("tech", "worker")
+	if err != nil {
+		t.Fatalf("LoadUnit failed: %v", err)
+	}
+	if worker.Unit.Parameters.MaxHP.Value != 100 {
+		t.Errorf("Expected 100 HP, got %d", worker.Unit.Parameters.MaxHP.Value)
+	}
+	if _, err := provider.LoadUnit("tech", "knight"); err == nil {
+		t.Error("Expected an error for a unit the server does not have")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"teraglest/internal/logging"
 )
 
 // Resource represents a game resource definition from resource.xml files
@@ -86,7 +88,7 @@ func LoadAllResources(resourcesDir string) ([]ResourceDefinition, error) {
 
 		// Check if the resource XML file exists
 		if _, err := os.Stat(resourceXMLPath); os.IsNotExist(err) {
-			fmt.Printf("Warning: No XML file found for resource %s at %s\n", resourceName, resourceXMLPath)
+			logging.Warnf(logging.CategoryData, "No XML file found for resource %s at %s", resourceName, resourceXMLPath)
 			continue
 		}
 
@@ -105,18 +107,18 @@ func LoadAllResources(resourcesDir string) ([]ResourceDefinition, error) {
 	return resources, nil
 }
 
-// PrintResources prints all resources for debugging/validation
+// PrintResources logs all resources for debugging/validation
 func PrintResources(resources []ResourceDefinition) {
-	fmt.Println("Resources:")
+	logging.Debugf(logging.CategoryData, "Resources:")
 	for i, res := range resources {
-		fmt.Printf("  %d. %s (type: %s", i+1, res.Name, res.Resource.Type.Value)
+		line := fmt.Sprintf("  %d. %s (type: %s", i+1, res.Name, res.Resource.Type.Value)
 		if res.Resource.Type.DefaultAmount != nil && res.Resource.Type.DefaultAmount.Value > 0 {
-			fmt.Printf(", default: %d", res.Resource.Type.DefaultAmount.Value)
+			line += fmt.Sprintf(", default: %d", res.Resource.Type.DefaultAmount.Value)
 		}
 		if res.Resource.Type.ResourceNumber != nil && res.Resource.Type.ResourceNumber.Value > 0 {
-			fmt.Printf(", number: %d", res.Resource.Type.ResourceNumber.Value)
+			line += fmt.Sprintf(", number: %d", res.Resource.Type.ResourceNumber.Value)
 		}
-		fmt.Println(")")
+		logging.Debugf(logging.CategoryData, "%s)", line)
 	}
 }
 
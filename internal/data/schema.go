@@ -0,0 +1,651 @@
+package data
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaKind identifies an XML document type that has a schema
+type SchemaKind string
+
+const (
+	SchemaUnit    SchemaKind = "unit"
+	SchemaUpgrade SchemaKind = "upgrade"
+	SchemaFaction SchemaKind = "faction"
+	SchemaTileset SchemaKind = "tileset"
+)
+
+// AttrType is the expected type of an XML attribute value
+type AttrType int
+
+const (
+	AttrString AttrType = iota // Any text
+	AttrInt                    // Integer
+	AttrFloat                  // Decimal number
+	AttrBool                   // "true" or "false"
+	AttrEnum                   // One of AttrSpec.Enum
+)
+
+// AttrSpec describes an allowed attribute
+type AttrSpec struct {
+	Type     AttrType
+	Required bool
+	Enum     []string // Allowed values for AttrEnum
+}
+
+// ElementSpec describes an allowed element, its attributes and children.
+// MegaGlest silently ignores unknown names and defaults missing values to
+// zero, so the checker reports them instead.
+type ElementSpec struct {
+	Attrs     map[string]AttrSpec
+	Children  map[string]*ElementSpec
+	Required  []string // Child elements that must be present
+	Open      bool     // Unknown children are allowed; only near-misses of known names are reported
+	OpenAttrs bool     // Unknown attributes are allowed; only near-misses of known names are reported
+}
+
+// ValidateXMLSchema checks an XML file against the schema for its kind.
+// reportName is the file name used in issues (usually relative to the tech tree).
+// The error is only set when the file cannot be read.
+func ValidateXMLSchema(path, reportName string, kind SchemaKind) (*ValidationReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return validateXMLSchemaReader(file, reportName, kind), nil
+}
+
+// schemaFrame tracks an open element while walking a document
+type schemaFrame struct {
+	name string
+	spec *ElementSpec // nil when the element is not checked
+	line int
+	seen map[string]bool
+}
+
+// validateXMLSchemaReader walks a document and reports schema violations with line numbers
+func validateXMLSchemaReader(r io.Reader, reportName string, kind SchemaKind) *ValidationReport {
+	startTime := time.Now()
+	report := NewValidationReport()
+	report.FilesChecked++
+
+	root, ok := schemaRoots[kind]
+	if !ok {
+		report.AddIssue(ValidationIssue{
+			Severity: ValidationError,
+			Category: "XML Schema",
+			Message:  fmt.Sprintf("No schema for document kind '%s'", kind),
+			File:     reportName,
+		})
+		return report
+	}
+
+	addIssue := func(severity ValidationSeverity, line int, field, value, message, suggestion string) {
+		report.AddIssue(ValidationIssue{
+			Severity:   severity,
+			Category:   "XML Schema",
+			Message:    message,
+			File:       reportName,
+			Line:       line,
+			Field:      field,
+			Value:      value,
+			Suggestion: suggestion,
+		})
+	}
+
+	decoder := xml.NewDecoder(r)
+	stack := make([]*schemaFrame, 0, 8)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		line, _ := decoder.InputPos()
+		if err != nil {
+			addIssue(ValidationError, line, "", "", fmt.Sprintf("Malformed XML: %v", err), "Fix the XML syntax")
+			break
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			name := element.Name.Local
+			frame := &schemaFrame{name: name, line: line, seen: make(map[string]bool)}
+
+			if len(stack) == 0 {
+				if name != string(kind) {
+					addIssue(ValidationError, line, name, "",
+						fmt.Sprintf("Root element is <%s>, expected <%s>", name, kind), "")
+				} else {
+					frame.spec = root
+				}
+			} else {
+				parent := stack[len(stack)-1]
+				parent.seen[name] = true
+				if parent.spec != nil {
+					frame.spec = parent.spec.Children[name]
+					if frame.spec == nil {
+						reportUnknownName(addIssue, line, "element", name, parent.name,
+							childNames(parent.spec), parent.spec.Open)
+					}
+				}
+			}
+
+			if frame.spec != nil {
+				checkAttributes(addIssue, line, name, element.Attr, frame.spec)
+			}
+			stack = append(stack, frame)
+
+		case xml.EndElement:
+			if len(stack) == 0 {
+				break
+			}
+			frame := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if frame.spec != nil {
+				for _, required := range frame.spec.Required {
+					if !frame.seen[required] {
+						addIssue(ValidationError, frame.line, required, "",
+							fmt.Sprintf("<%s> is missing required element <%s>", frame.name, required),
+							fmt.Sprintf("Add <%s> inside <%s>", required, frame.name))
+					}
+				}
+			}
+		}
+	}
+
+	report.Duration = time.Since(startTime)
+	return report
+}
+
+// checkAttributes reports unknown, missing and mistyped attributes of one element
+func checkAttributes(addIssue func(ValidationSeverity, int, string, string, string, string), line int, element string, attrs []xml.Attr, spec *ElementSpec) {
+	present := make(map[string]bool, len(attrs))
+
+	for _, attr := range attrs {
+		name := attr.Name.Local
+		if attr.Name.Space != "" {
+			continue // Namespaced attributes (xmlns etc.) are not part of the schema
+		}
+		present[name] = true
+
+		attrSpec, known := spec.Attrs[name]
+		if !known {
+			reportUnknownName(addIssue, line, "attribute", name, element, attrNames(spec), spec.OpenAttrs)
+			continue
+		}
+
+		if problem := checkAttrValue(attrSpec, attr.Value); problem != "" {
+			addIssue(ValidationError, line, element+"@"+name, attr.Value,
+				fmt.Sprintf("<%s %s=\"%s\">: %s", element, name, attr.Value, problem), "")
+		}
+	}
+
+	for _, name := range attrNames(spec) {
+		if spec.Attrs[name].Required && !present[name] {
+			addIssue(ValidationError, line, element+"@"+name, "",
+				fmt.Sprintf("<%s> is missing required attribute '%s'", element, name),
+				fmt.Sprintf("Add %s=\"...\" to <%s>", name, element))
+		}
+	}
+}
+
+// checkAttrValue returns a description of why a value does not match its type, or ""
+func checkAttrValue(spec AttrSpec, value string) string {
+	value = strings.TrimSpace(value)
+	switch spec.Type {
+	case AttrInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "expected an integer"
+		}
+	case AttrFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "expected a number"
+		}
+	case AttrBool:
+		if value != "true" && value != "false" {
+			return "expected true or false"
+		}
+	case AttrEnum:
+		for _, allowed := range spec.Enum {
+			if value == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("expected one of %s", strings.Join(spec.Enum, ", "))
+	}
+	return ""
+}
+
+// reportUnknownName reports an unknown element or attribute, suggesting the
+// closest known name. In open specs only near-misses are reported.
+func reportUnknownName(addIssue func(ValidationSeverity, int, string, string, string, string), line int, what, name, parent string, known []string, open bool) {
+	closest := closestName(name, known)
+	if open && closest == "" {
+		return
+	}
+
+	suggestion := fmt.Sprintf("Remove it or check the spelling; MegaGlest ignores unknown %ss", what)
+	if closest != "" {
+		suggestion = fmt.Sprintf("Did you mean '%s'?", closest)
+	}
+	addIssue(ValidationWarning, line, name, "",
+		fmt.Sprintf("Unknown %s '%s' in <%s>", what, name, parent), suggestion)
+}
+
+// closestName returns the known name within a small edit distance of name, or ""
+func closestName(name string, known []string) string {
+	best := ""
+	bestDistance := len(name)/3 + 1
+	if bestDistance > 3 {
+		bestDistance = 3
+	}
+	for _, candidate := range known {
+		if d := editDistance(name, candidate); d <= bestDistance && (best == "" || d < editDistance(name, best)) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// minInt returns the smaller of two ints
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// childNames returns the sorted child element names of a spec
+func childNames(spec *ElementSpec) []string {
+	names := make([]string, 0, len(spec.Children))
+	for name := range spec.Children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// attrNames returns the sorted attribute names of a spec
+func attrNames(spec *ElementSpec) []string {
+	names := make([]string, 0, len(spec.Attrs))
+	for name := range spec.Attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Schema construction helpers
+
+// valueElement is an element with a single required value attribute
+func valueElement(attrType AttrType) *ElementSpec {
+	return &ElementSpec{Attrs: map[string]AttrSpec{"value": {Type: attrType, Required: true}}}
+}
+
+// enumElement is an element whose value attribute must be one of values
+func enumElement(values ...string) *ElementSpec {
+	return &ElementSpec{Attrs: map[string]AttrSpec{"value": {Type: AttrEnum, Required: true, Enum: values}}}
+}
+
+// pathElement is an element with a single required path attribute
+func pathElement() *ElementSpec {
+	return &ElementSpec{Attrs: map[string]AttrSpec{"path": {Type: AttrString, Required: true}}}
+}
+
+// nameElement is an element with a single required name attribute
+func nameElement() *ElementSpec {
+	return &ElementSpec{Attrs: map[string]AttrSpec{"name": {Type: AttrString, Required: true}}}
+}
+
+// listElement is a container of repeated child elements
+func listElement(child string, spec *ElementSpec) *ElementSpec {
+	return &ElementSpec{Children: map[string]*ElementSpec{child: spec}}
+}
+
+// resourceAmountList is a list of <resource name amount/> entries
+func resourceAmountList() *ElementSpec {
+	return listElement("resource", &ElementSpec{Attrs: map[string]AttrSpec{
+		"name":   {Type: AttrString, Required: true},
+		"amount": {Type: AttrInt, Required: true},
+	}})
+}
+
+// soundList is an element with an enabled flag and <sound path/> children
+func soundList(child string) *ElementSpec {
+	return &ElementSpec{
+		Attrs:    map[string]AttrSpec{"enabled": {Type: AttrBool, Required: true}},
+		Children: map[string]*ElementSpec{child: pathElement()},
+	}
+}
+
+// upgradeStat is an upgrade effect such as <max-hp value="50"/>
+func upgradeStat() *ElementSpec {
+	return &ElementSpec{Attrs: map[string]AttrSpec{
+		"value":         {Type: AttrInt, Required: true},
+		"value-percent": {Type: AttrBool},
+	}}
+}
+
+// fieldValues are the MegaGlest movement fields
+var fieldValues = []string{"land", "air"}
+
+// schemaRoots maps each document kind to its root element spec
+var schemaRoots = map[SchemaKind]*ElementSpec{
+	SchemaUnit:    unitSchema(),
+	SchemaUpgrade: upgradeSchema(),
+	SchemaFaction: factionSchema(),
+	SchemaTileset: tilesetSchema(),
+}
+
+// unitSchema describes units/<name>/<name>.xml
+func unitSchema() *ElementSpec {
+	parameters := &ElementSpec{
+		Children: map[string]*ElementSpec{
+			"size":   valueElement(AttrInt),
+			"height": valueElement(AttrInt),
+			"max-hp": {Attrs: map[string]AttrSpec{
+				"value":        {Type: AttrInt, Required: true},
+				"regeneration": {Type: AttrInt, Required: true},
+			}},
+			"max-ep": {Attrs: map[string]AttrSpec{
+				"value":            {Type: AttrInt, Required: true},
+				"regeneration":     {Type: AttrInt, Required: true},
+				"start-percentage": {Type: AttrInt},
+			}},
+			"armor":           valueElement(AttrInt),
+			"armor-type":      valueElement(AttrString),
+			"sight":           valueElement(AttrInt),
+			"time":            valueElement(AttrInt),
+			"multi-selection": valueElement(AttrBool),
+			"cellmap": {
+				Attrs:    map[string]AttrSpec{"value": {Type: AttrBool, Required: true}},
+				Children: map[string]*ElementSpec{"row": valueElement(AttrString)},
+			},
+			"levels": listElement("level", &ElementSpec{Attrs: map[string]AttrSpec{
+				"name":  {Type: AttrString, Required: true},
+				"kills": {Type: AttrInt, Required: true},
+			}}),
+			"fields":     listElement("field", enumElement(fieldValues...)),
+			"properties": listElement("property", enumElement("burnable", "rotated_climb")),
+			"light": {Attrs: map[string]AttrSpec{
+				"enabled": {Type: AttrBool, Required: true},
+				"red":     {Type: AttrFloat},
+				"green":   {Type: AttrFloat},
+				"blue":    {Type: AttrFloat},
+			}},
+			"unit-requirements":     listElement("unit", nameElement()),
+			"upgrade-requirements":  listElement("upgrade", nameElement()),
+			"resource-requirements": resourceAmountList(),
+			"resources-stored":      resourceAmountList(),
+			"image":                 pathElement(),
+			"image-cancel":          pathElement(),
+			"meeting-point": {Attrs: map[string]AttrSpec{
+				"value":      {Type: AttrBool, Required: true},
+				"image-path": {Type: AttrString},
+			}},
+			"selection-sounds":            soundList("sound"),
+			"command-sounds":              soundList("sound"),
+			"rotation-allowed":            valueElement(AttrBool),
+			"count-in-victory-conditions": valueElement(AttrBool),
+			"max-unit-count":              valueElement(AttrInt),
+			"ai-build-size":               valueElement(AttrInt),
+			"tags":                        listElement("tag", valueElement(AttrString)),
+			"health-bar":                  {Open: true, OpenAttrs: true},
+			"power-requirement": {Attrs: map[string]AttrSpec{
+				"workers": {Type: AttrInt},
+				"energy":  {Type: AttrInt},
+			}},
+			"garrison": {Attrs: map[string]AttrSpec{
+				"capacity": {Type: AttrInt, Required: true},
+			}},
+		},
+		Required: []string{"size", "height", "max-hp", "armor", "armor-type", "sight", "time", "fields"},
+	}
+
+	skill := &ElementSpec{
+		Children: map[string]*ElementSpec{
+			"type": enumElement("stop", "move", "attack", "build", "harvest", "repair",
+				"be_built", "produce", "upgrade", "morph", "die", "fog_of_war"),
+			"name":       valueElement(AttrString),
+			"ep-cost":    valueElement(AttrInt),
+			"hp-cost":    valueElement(AttrInt),
+			"speed":      valueElement(AttrInt),
+			"anim-speed": valueElement(AttrInt),
+			"animation":  pathElement(),
+			"sound": {
+				Attrs: map[string]AttrSpec{
+					"enabled":    {Type: AttrBool, Required: true},
+					"start-time": {Type: AttrFloat},
+				},
+				Children: map[string]*ElementSpec{"sound-file": pathElement()},
+			},
+			"attack-strenght":   valueElement(AttrInt), // Misspelled in MegaGlest itself
+			"attack-var":        valueElement(AttrInt),
+			"attack-range":      valueElement(AttrInt),
+			"attack-type":       valueElement(AttrString),
+			"attack-fields":     listElement("field", enumElement(fieldValues...)),
+			"attack-start-time": valueElement(AttrFloat),
+			"max-range":         valueElement(AttrInt),
+			"projectile":        {Attrs: map[string]AttrSpec{"value": {Type: AttrBool, Required: true}}, Open: true},
+			"splash":            {Attrs: map[string]AttrSpec{"value": {Type: AttrBool, Required: true}}, Open: true},
+		},
+		Required: []string{"type", "name"},
+		Open:     true,
+	}
+
+	skillRef := valueElement(AttrString)
+	command := &ElementSpec{
+		Children: map[string]*ElementSpec{
+			"type":                 valueElement(AttrString),
+			"name":                 valueElement(AttrString),
+			"image":                pathElement(),
+			"unit-requirements":    listElement("unit", nameElement()),
+			"upgrade-requirements": listElement("upgrade", nameElement()),
+			"move-skill":           skillRef,
+			"stop-skill":           skillRef,
+			"attack-skill":         skillRef,
+			"build-skill":          skillRef,
+			"harvest-skill":        skillRef,
+			"repair-skill":         skillRef,
+			"produce-skill":        skillRef,
+			"upgrade-skill":        skillRef,
+			"morph-skill":          skillRef,
+			"stop-loaded-skill":    skillRef,
+			"move-loaded-skill":    skillRef,
+			"produced-unit":        nameElement(),
+			"produced-upgrade":     nameElement(),
+			"morph-unit":           nameElement(),
+			"discount":             valueElement(AttrInt),
+			"buildings":            listElement("building", nameElement()),
+			"harvested-resources":  listElement("resource", nameElement()),
+			"repaired-units":       listElement("unit", nameElement()),
+			"max-load":             valueElement(AttrInt),
+			"hits-per-unit":        valueElement(AttrInt),
+			"start-sound":          soundList("sound"),
+			"built-sound":          soundList("sound"),
+		},
+		Required: []string{"type", "name"},
+		Open:     true,
+	}
+
+	return &ElementSpec{
+		Children: map[string]*ElementSpec{
+			"parameters": parameters,
+			"skills":     listElement("skill", skill),
+			"commands":   listElement("command", command),
+		},
+		Required: []string{"parameters", "skills", "commands"},
+	}
+}
+
+// upgradeSchema describes upgrades/<name>/<name>.xml
+func upgradeSchema() *ElementSpec {
+	return &ElementSpec{
+		Children: map[string]*ElementSpec{
+			"image":                 pathElement(),
+			"image-cancel":          pathElement(),
+			"time":                  valueElement(AttrInt),
+			"unit-requirements":     listElement("unit", nameElement()),
+			"upgrade-requirements":  listElement("upgrade", nameElement()),
+			"resource-requirements": resourceAmountList(),
+			"effects":               listElement("unit", nameElement()),
+			"max-hp":                upgradeStat(),
+			"max-hp-regeneration":   upgradeStat(),
+			"max-ep":                upgradeStat(),
+			"max-ep-regeneration":   upgradeStat(),
+			"sight":                 upgradeStat(),
+			"attack-strenght":       upgradeStat(),
+			"attack-range":          upgradeStat(),
+			"attack-speed":          upgradeStat(),
+			"armor":                 upgradeStat(),
+			"move-speed":            upgradeStat(),
+			"production-speed":      upgradeStat(),
+		},
+		Required: []string{"image", "time", "effects"},
+	}
+}
+
+// factionSchema describes factions/<name>/<name>.xml
+func factionSchema() *ElementSpec {
+	amountList := func(child string) *ElementSpec {
+		return listElement(child, &ElementSpec{Attrs: map[string]AttrSpec{
+			"name":   {Type: AttrString, Required: true},
+			"amount": {Type: AttrInt, Required: true},
+		}})
+	}
+
+	return &ElementSpec{
+		Children: map[string]*ElementSpec{
+			"starting-resources": amountList("resource"),
+			"starting-units":     amountList("unit"),
+			"music": {Attrs: map[string]AttrSpec{
+				"value": {Type: AttrBool, Required: true},
+				"path":  {Type: AttrString},
+			}},
+			"flat-particle-positions": valueElement(AttrBool),
+			"ai-behavior":             {Open: true, OpenAttrs: true},
+		},
+		Required: []string{"starting-resources", "starting-units"},
+	}
+}
+
+// tilesetSchema describes tilesets/<name>/<name>.xml
+func tilesetSchema() *ElementSpec {
+	ambientSound := &ElementSpec{
+		Attrs: map[string]AttrSpec{
+			"enabled":     {Type: AttrBool, Required: true},
+			"path":        {Type: AttrString},
+			"play-always": {Type: AttrBool},
+			"volume":      {Type: AttrFloat},
+		},
+		OpenAttrs: true,
+	}
+	// Day and night start sounds list random alternatives, e.g. animal calls
+	ambientCalls := &ElementSpec{
+		Attrs:     ambientSound.Attrs,
+		Children:  map[string]*ElementSpec{"sound": pathElement()},
+		OpenAttrs: true,
+	}
+
+	surface := &ElementSpec{
+		Children: map[string]*ElementSpec{
+			"texture": {Attrs: map[string]AttrSpec{
+				"path": {Type: AttrString, Required: true},
+				"prob": {Type: AttrFloat},
+			}},
+			// Harmful ground, e.g. lava, that pathfinding avoids
+			"hazard": {Attrs: map[string]AttrSpec{
+				"name":      {Type: AttrString},
+				"damage":    {Type: AttrInt},
+				"interval":  {Type: AttrFloat},
+				"effect":    {Type: AttrString},
+				"path-cost": {Type: AttrFloat},
+			}},
+			// Grass and detail meshes growing on the surface
+			"detail": {
+				Attrs: map[string]AttrSpec{
+					"density":      {Type: AttrFloat},
+					"height":       {Type: AttrFloat},
+					"color":        {Type: AttrString},
+					"model-chance": {Type: AttrFloat},
+				},
+				Children: map[string]*ElementSpec{"model": pathElement()},
+			},
+		},
+	}
+
+	return &ElementSpec{
+		Children: map[string]*ElementSpec{
+			"surfaces": listElement("surface", surface),
+			"objects": listElement("object", &ElementSpec{
+				Attrs:    map[string]AttrSpec{"walkable": {Type: AttrBool, Required: true}},
+				Children: map[string]*ElementSpec{"model": {Attrs: map[string]AttrSpec{"path": {Type: AttrString, Required: true}}, OpenAttrs: true}},
+			}),
+			"ambient-sounds": {
+				Children: map[string]*ElementSpec{
+					"day-sound":         ambientSound,
+					"night-sound":       ambientSound,
+					"rain-sound":        ambientSound,
+					"snow-sound":        ambientSound,
+					"day-start-sound":   ambientCalls,
+					"night-start-sound": ambientCalls,
+				},
+			},
+			"parameters": {
+				Children: map[string]*ElementSpec{
+					"water": {
+						Attrs:    map[string]AttrSpec{"effects": {Type: AttrBool, Required: true}},
+						Children: map[string]*ElementSpec{"texture": pathElement()},
+					},
+					"fog": {Attrs: map[string]AttrSpec{
+						"enabled":     {Type: AttrBool, Required: true},
+						"mode":        {Type: AttrInt},
+						"density":     {Type: AttrFloat},
+						"color-red":   {Type: AttrFloat},
+						"color-green": {Type: AttrFloat},
+						"color-blue":  {Type: AttrFloat},
+					}, OpenAttrs: true},
+					"weather": {Attrs: map[string]AttrSpec{
+						"sun":  {Type: AttrFloat, Required: true},
+						"rain": {Type: AttrFloat, Required: true},
+						"snow": {Type: AttrFloat, Required: true},
+					}},
+				},
+				Open: true,
+			},
+		},
+		Required: []string{"surfaces", "objects", "parameters"},
+	}
+}
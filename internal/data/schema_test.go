@@ -0,0 +1,175 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const schemaTestUnit = `<?xml version="1.0" standalone="no"?>
+<unit>
+	<parameters>
+		<size value="1"/>
+		<height value="2"/>
+		<max-hp value="450" regenration="1"/>
+		<armor value="abc"/>
+		<armor-type value="leather"/>
+		<sight value="9"/>
+		<time value="30"/>
+		<fields>
+			<field value="water"/>
+		</fields>
+	</parameters>
+	<skills>
+		<skill>
+			<type value="stop"/>
+			<name value="stop_skill"/>
+			<particles value="false"/>
+			<attack-strength value="10"/>
+		</skill>
+	</skills>
+	<commands/>
+</unit>
+`
+
+func TestValidateXMLSchemaUnit(t *testing.T) {
+	report := validateXMLSchemaReader(strings.NewReader(schemaTestUnit), "units/swordman/swordman.xml", SchemaUnit)
+
+	expected := []struct {
+		severity ValidationSeverity
+		line     int
+		contains string
+	}{
+		{ValidationWarning, 6, "Unknown attribute 'regenration'"},
+		{ValidationError, 6, "missing required attribute 'regeneration'"},
+		{ValidationError, 7, "expected an integer"},
+		{ValidationError, 12, "expected one of land, air"},
+		{ValidationWarning, 20, "Unknown element 'attack-strength'"},
+	}
+
+	for _, want := range expected {
+		found := false
+		for _, issue := range report.Issues {
+			if issue.Severity == want.severity && issue.Line == want.line && strings.Contains(issue.Message, want.contains) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected %s on line %d containing %q, got %+v", want.severity, want.line, want.contains, report.Issues)
+		}
+	}
+
+	// Unknown children of open skills are only reported when they look like typos
+	for _, issue := range report.Issues {
+		if strings.Contains(issue.Message, "particles") {
+			t.Errorf("Did not expect an issue for <particles>: %s", issue.Message)
+		}
+	}
+	if len(report.Issues) != len(expected) {
+		t.Errorf("Expected %d issues, got %d: %+v", len(expected), len(report.Issues), report.Issues)
+	}
+}
+
+func TestValidateXMLSchemaRequiredElements(t *testing.T) {
+	report := validateXMLSchemaReader(strings.NewReader("<faction>\n<starting-units/>\n</faction>"), "f.xml", SchemaFaction)
+	if report.ErrorCount != 1 || report.Issues[0].Field != "starting-resources" || report.Issues[0].Line != 1 {
+		t.Errorf("Expected missing starting-resources on line 1, got %+v", report.Issues)
+	}
+
+	report = validateXMLSchemaReader(strings.NewReader("<unit>"), "u.xml", SchemaUnit)
+	if report.ErrorCount == 0 || !strings.Contains(report.Issues[0].Message, "Malformed XML") {
+		t.Errorf("Expected malformed XML error, got %+v", report.Issues)
+	}
+
+	report = validateXMLSchemaReader(strings.NewReader("<upgrade/>"), "u.xml", SchemaUnit)
+	if report.ErrorCount != 1 || !strings.Contains(report.Issues[0].Message, "expected <unit>") {
+		t.Errorf("Expected wrong root error, got %+v", report.Issues)
+	}
+}
+
+func TestSchemaValidationRuleFindsFactionFiles(t *testing.T) {
+	root := t.TempDir()
+	unitDir := filepath.Join(root, "factions", "tech", "units", "worker")
+	if err := os.MkdirAll(unitDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(root, "factions", "tech", "tech.xml"),
+		[]byte(`<faction><starting-resources/><starting-units/></faction>`), 0644)
+	os.WriteFile(filepath.Join(unitDir, "worker.xml"), []byte(schemaTestUnit), 0644)
+
+	validator := NewDataValidator(root, NewAssetManager(root))
+	report := NewValidationReport()
+	validator.validateFactionSchema("tech", report)
+
+	if report.FilesChecked != 2 {
+		t.Errorf("Expected 2 files checked, got %d", report.FilesChecked)
+	}
+	if len(report.Issues) == 0 || report.Issues[0].File != "factions/tech/units/worker/worker.xml" {
+		t.Errorf("Expected issues reported against the unit file, got %+v", report.Issues)
+	}
+}
+
+func TestValidateXMLSchemaPowerRequirement(t *testing.T) {
+	unit := strings.Replace(schemaTestUnit, "<size value=\"1\"/>", "<size value=\"1\"/>\n\t\t<power-requirement workers=\"2\" energy=\"10\"/>", 1)
+	report := validateXMLSchemaReader(strings.NewReader(unit), "units/mill/mill.xml", SchemaUnit)
+	for _, issue := range report.Issues {
+		if issue.Field == "power-requirement" || strings.Contains(issue.Message, "power-requirement") {
+			t.Errorf("Did not expect an issue for <power-requirement>: %s", issue.Message)
+		}
+	}
+
+	unit = strings.Replace(schemaTestUnit, "<size value=\"1\"/>", "<size value=\"1\"/>\n\t\t<power-requirement workers=\"two\"/>", 1)
+	report = validateXMLSchemaReader(strings.NewReader(unit), "units/mill/mill.xml", SchemaUnit)
+	found := false
+	for _, issue := range report.Issues {
+		found = found || issue.Line == 5 && strings.Contains(issue.Message, "expected an integer")
+	}
+	if !found {
+		t.Errorf("Expected a non-integer worker count reported on line 5, got %+v", report.Issues)
+	}
+}
+
+const schemaTestTileset = `<?xml version="1.0" standalone="no"?>
+<tileset>
+	<surfaces>
+		<surface>
+			<texture path="textures/lava.png" prob="1.0"/>
+			%s
+		</surface>
+	</surfaces>
+	<objects/>
+	<parameters/>
+</tileset>
+`
+
+// validateTilesetSurface validates a tileset whose surface holds an element
+func validateTilesetSurface(element string) *ValidationReport {
+	return validateXMLSchemaReader(strings.NewReader(fmt.Sprintf(schemaTestTileset, element)), "tilesets/volcano/volcano.xml", SchemaTileset)
+}
+
+func TestValidateXMLSchemaSurfaceHazard(t *testing.T) {
+	report := validateTilesetSurface(`<hazard name="lava" damage="8" interval="1" effect="burn" path-cost="10"/>`)
+	if len(report.Issues) != 0 {
+		t.Errorf("Expected a surface hazard to validate, got %+v", report.Issues)
+	}
+
+	report = validateTilesetSurface(`<hazard name="lava" damage="8.5" interval="soon"/>`)
+	if report.ErrorCount != 2 || report.Issues[0].Line != 6 {
+		t.Errorf("Expected the damage and interval reported on line 6, got %+v", report.Issues)
+	}
+}
+
+func TestValidateXMLSchemaSurfaceDetail(t *testing.T) {
+	report := validateTilesetSurface(`<detail density="12" height="0.4" color="0.3 0.5 0.2" model-chance="0.05"><model path="models/flower.g3d"/></detail>`)
+	if len(report.Issues) != 0 {
+		t.Errorf("Expected surface detail to validate, got %+v", report.Issues)
+	}
+
+	report = validateTilesetSurface(`<detail density="thick"><model/></detail>`)
+	if report.ErrorCount != 2 {
+		t.Errorf("Expected the density and the model's missing path reported, got %+v", report.Issues)
+	}
+}
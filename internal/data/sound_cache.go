@@ -0,0 +1,212 @@
+package data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"teraglest/internal/logging"
+)
+
+// Sound cache limits; sounds are evicted least used first
+const (
+	soundCacheMB      = 64
+	soundCacheEntries = 512
+)
+
+// SoundData is a sound effect ready for playback
+type SoundData struct {
+	Path          string        // Resolved file path
+	Format        string        // File extension without the dot, e.g. "wav"
+	Decoded       bool          // Whether Data holds PCM samples rather than the encoded file
+	SampleRate    int           // Samples per second, when decoded
+	Channels      int           // Channel count, when decoded
+	BitsPerSample int           // Bits per sample, when decoded
+	Duration      time.Duration // Length, when decoded
+	Data          []byte        // PCM samples, or the encoded file for formats the backend decodes
+}
+
+// SoundPreloadStats reports a faction sound preload pass
+type SoundPreloadStats struct {
+	Faction string
+	Sounds  int           // Distinct sound files referenced by the faction's units
+	Loaded  int           // Sounds now in the cache
+	Failed  int           // Sounds that could not be loaded
+	Bytes   int64         // Size of the loaded sounds
+	Elapsed time.Duration // Time the pass took
+}
+
+// LoadSound loads a sound effect, decoding WAV files to PCM, and caches it;
+// frequently played sounds stay cached the longest
+func (am *AssetManager) LoadSound(soundPath string) (*SoundData, error) {
+	fullPath := am.resolvePath(soundPath)
+
+	if cached, found := am.sounds.Get(fullPath); found {
+		return cached.(*SoundData), nil
+	}
+
+	raw, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sound %s: %w", soundPath, err)
+	}
+
+	sound := &SoundData{Format: strings.TrimPrefix(strings.ToLower(filepath.Ext(fullPath)), "."), Data: raw}
+	if sound.Format == "wav" {
+		if sound, err = DecodeWAV(raw); err != nil {
+			return nil, fmt.Errorf("failed to decode sound %s: %w", soundPath, err)
+		}
+	}
+	sound.Path = fullPath
+
+	if err := am.sounds.Put(fullPath, sound, string(AssetTypeAudio), int64(len(sound.Data))); err != nil {
+		logging.Warnf(logging.CategoryData, "Failed to cache sound %s: %v", soundPath, err)
+	}
+	return sound, nil
+}
+
+// PreloadFactionSounds loads the sounds of every unit of a faction into the
+// sound cache, so they do not hitch the game the first time they play
+func (am *AssetManager) PreloadFactionSounds(factionName string) (SoundPreloadStats, error) {
+	start := time.Now()
+	stats := SoundPreloadStats{Faction: factionName}
+
+	unitsDir := filepath.Join(am.techTreeRoot, "factions", factionName, "units")
+	entries, err := os.ReadDir(unitsDir)
+	if err != nil {
+		return stats, fmt.Errorf("failed to read units directory for faction %s: %w", factionName, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		unit, err := am.LoadUnit(factionName, entry.Name())
+		if err != nil {
+			logging.Warnf(logging.CategoryData, "Skipping sounds of unit %s: %v", entry.Name(), err)
+			continue
+		}
+
+		// Unit sound paths are relative to the unit's directory
+		for _, path := range UnitSoundPaths(&unit.Unit) {
+			soundPath := filepath.Join("factions", factionName, "units", entry.Name(), filepath.FromSlash(path))
+			if seen[soundPath] {
+				continue
+			}
+			seen[soundPath] = true
+
+			sound, err := am.LoadSound(soundPath)
+			if err != nil {
+				logging.Warnf(logging.CategoryData, "Failed to preload sound: %v", err)
+				stats.Failed++
+				continue
+			}
+			stats.Loaded++
+			stats.Bytes += int64(len(sound.Data))
+		}
+	}
+
+	stats.Sounds = len(seen)
+	stats.Elapsed = time.Since(start)
+	return stats, nil
+}
+
+// GetSoundCacheStats returns sound cache statistics
+func (am *AssetManager) GetSoundCacheStats() CacheStats {
+	return am.sounds.GetStats()
+}
+
+// UnitSoundPaths lists the sound files a unit references: selection and
+// command acknowledgements, skill sounds and projectile sounds
+func UnitSoundPaths(unit *Unit) []string {
+	var paths []string
+	addGroup := func(group *SoundGroup) {
+		if group == nil || !group.Enabled {
+			return
+		}
+		for _, sound := range group.Sounds {
+			paths = append(paths, sound.Path)
+		}
+	}
+	addSkill := func(sound *SkillSound) {
+		if sound == nil || !sound.Enabled {
+			return
+		}
+		for _, file := range sound.SoundFiles {
+			paths = append(paths, file.Path)
+		}
+	}
+
+	addGroup(unit.Parameters.SelectionSounds)
+	addGroup(unit.Parameters.CommandSounds)
+	for _, skill := range unit.Skills {
+		addSkill(skill.Sound)
+		if skill.Projectile != nil {
+			addSkill(skill.Projectile.Sound)
+		}
+	}
+
+	kept := paths[:0]
+	for _, path := range paths {
+		if path != "" {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
+
+// DecodeWAV decodes an uncompressed PCM WAV file
+func DecodeWAV(raw []byte) (*SoundData, error) {
+	if len(raw) < 12 || string(raw[0:4]) != "RIFF" || string(raw[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a RIFF WAVE file")
+	}
+
+	sound := &SoundData{Format: "wav", Decoded: true}
+	haveFormat := false
+	reader := bytes.NewReader(raw[12:])
+	for {
+		var header struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+			break
+		}
+		if int64(header.Size) > int64(reader.Len()) {
+			return nil, fmt.Errorf("chunk %q is truncated", header.ID[:])
+		}
+		chunk := make([]byte, header.Size)
+		reader.Read(chunk)
+		if header.Size%2 == 1 {
+			reader.ReadByte() // Chunks are padded to an even size
+		}
+
+		switch string(header.ID[:]) {
+		case "fmt ":
+			if len(chunk) < 16 {
+				return nil, fmt.Errorf("format chunk is too short")
+			}
+			if format := binary.LittleEndian.Uint16(chunk[0:2]); format != 1 {
+				return nil, fmt.Errorf("unsupported WAV encoding %d, only PCM is supported", format)
+			}
+			sound.Channels = int(binary.LittleEndian.Uint16(chunk[2:4]))
+			sound.SampleRate = int(binary.LittleEndian.Uint32(chunk[4:8]))
+			sound.BitsPerSample = int(binary.LittleEndian.Uint16(chunk[14:16]))
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, fmt.Errorf("data chunk before the format chunk")
+			}
+			sound.Data = chunk
+			if bytesPerSecond := sound.SampleRate * sound.Channels * sound.BitsPerSample / 8; bytesPerSecond > 0 {
+				sound.Duration = time.Duration(len(chunk)) * time.Second / time.Duration(bytesPerSecond)
+			}
+			return sound, nil
+		}
+	}
+	return nil, fmt.Errorf("no data chunk")
+}
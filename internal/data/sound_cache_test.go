@@ -0,0 +1,93 @@
+package data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testWAV builds a mono 16-bit PCM WAV file with the given number of samples
+func testWAV(samples int) []byte {
+	var buf bytes.Buffer
+	data := make([]byte, samples*2)
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(data)))
+	buf.WriteString("WAVEfmt ")
+	for _, field := range []interface{}{uint32(16), uint16(1), uint16(1), uint32(8000), uint32(16000), uint16(2), uint16(16)} {
+		binary.Write(&buf, binary.LittleEndian, field)
+	}
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestDecodeWAV(t *testing.T) {
+	sound, err := DecodeWAV(testWAV(8000))
+	if err != nil {
+		t.Fatalf("Failed to decode WAV: %v", err)
+	}
+	if !sound.Decoded || sound.SampleRate != 8000 || sound.Channels != 1 || sound.BitsPerSample != 16 {
+		t.Errorf("Unexpected format: %+v", sound)
+	}
+	if len(sound.Data) != 16000 || sound.Duration != time.Second {
+		t.Errorf("Expected one second of samples, got %d bytes lasting %v", len(sound.Data), sound.Duration)
+	}
+
+	if _, err := DecodeWAV([]byte("not a wav file")); err == nil {
+		t.Error("Expected an error for a file that is not a WAV")
+	}
+}
+
+func TestPreloadFactionSounds(t *testing.T) {
+	root := t.TempDir()
+	unitDir := filepath.Join(root, "factions", "tech", "units", "worker")
+	if err := os.MkdirAll(filepath.Join(unitDir, "sounds"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	unitXML := `<unit>
+		<parameters>
+			<selection-sounds enabled="true"><sound path="sounds/select.wav"/></selection-sounds>
+			<command-sounds enabled="true"><sound path="sounds/select.wav"/><sound path="sounds/missing.wav"/></command-sounds>
+		</parameters>
+		<skills>
+			<skill><name value="attack"/><sound enabled="true"><sound-file path="sounds/hit.ogg"/></sound></skill>
+			<skill><name value="die"/><sound enabled="false"><sound-file path="sounds/die.wav"/></sound></skill>
+		</skills>
+	</unit>`
+	files := map[string][]byte{
+		"worker.xml":        []byte(unitXML),
+		"sounds/select.wav": testWAV(100),
+		"sounds/hit.ogg":    []byte("OggS encoded"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(unitDir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	am := NewAssetManager(root)
+	stats, err := am.PreloadFactionSounds("tech")
+	if err != nil {
+		t.Fatalf("Failed to preload sounds: %v", err)
+	}
+	if stats.Sounds != 3 || stats.Loaded != 2 || stats.Failed != 1 {
+		t.Errorf("Expected 3 sounds with 2 loaded and 1 failed, got %+v", stats)
+	}
+
+	// Preloaded sounds come from the cache
+	before := am.GetSoundCacheStats().Hits
+	sound, err := am.LoadSound("factions/tech/units/worker/sounds/hit.ogg")
+	if err != nil {
+		t.Fatalf("Failed to load sound: %v", err)
+	}
+	if sound.Decoded || sound.Format != "ogg" || string(sound.Data) != "OggS encoded" {
+		t.Errorf("Expected the encoded OGG file, got %+v", sound)
+	}
+	if am.GetSoundCacheStats().Hits != before+1 {
+		t.Error("Expected the preloaded sound to be a cache hit")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
+
+	"teraglest/internal/logging"
 )
 
 // TechTree represents the complete tech tree structure from megapack.xml
@@ -22,7 +24,8 @@ type TechTreeDescription struct {
 
 // AttackType represents an attack type definition
 type AttackType struct {
-	Name string `xml:"name,attr"`
+	Name  string `xml:"name,attr"`
+	Siege bool   `xml:"siege,attr"` // Built to break structures, e.g. <attack-type name="impact" siege="true"/>
 }
 
 // ArmorType represents an armor type definition
@@ -55,27 +58,27 @@ func LoadTechTree(xmlPath string) (*TechTree, error) {
 	return &techTree, nil
 }
 
-// PrintAttackTypes prints all attack types for debugging/validation
+// PrintAttackTypes logs all attack types for debugging/validation
 func (tt *TechTree) PrintAttackTypes() {
-	fmt.Println("Attack Types:")
+	logging.Debugf(logging.CategoryData, "Attack Types:")
 	for i, attackType := range tt.AttackTypes {
-		fmt.Printf("  %d. %s\n", i+1, attackType.Name)
+		logging.Debugf(logging.CategoryData, "  %d. %s", i+1, attackType.Name)
 	}
 }
 
-// PrintArmorTypes prints all armor types for debugging/validation
+// PrintArmorTypes logs all armor types for debugging/validation
 func (tt *TechTree) PrintArmorTypes() {
-	fmt.Println("Armor Types:")
+	logging.Debugf(logging.CategoryData, "Armor Types:")
 	for i, armorType := range tt.ArmorTypes {
-		fmt.Printf("  %d. %s\n", i+1, armorType.Name)
+		logging.Debugf(logging.CategoryData, "  %d. %s", i+1, armorType.Name)
 	}
 }
 
-// PrintDamageMultipliers prints all damage multipliers for debugging/validation
+// PrintDamageMultipliers logs all damage multipliers for debugging/validation
 func (tt *TechTree) PrintDamageMultipliers() {
-	fmt.Println("Damage Multipliers:")
+	logging.Debugf(logging.CategoryData, "Damage Multipliers:")
 	for _, dm := range tt.DamageMultipliers {
-		fmt.Printf("  %s vs %s: %.2fx\n", dm.Attack, dm.Armor, dm.Value)
+		logging.Debugf(logging.CategoryData, "  %s vs %s: %.2fx", dm.Attack, dm.Armor, dm.Value)
 	}
 }
 
@@ -108,4 +111,18 @@ func (tt *TechTree) HasArmorType(armorType string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// IsSiegeAttack checks if an attack type breaks structures: one named "siege"
+// or one the tech tree marks with siege="true"
+func (tt *TechTree) IsSiegeAttack(attackType string) bool {
+	if attackType == "siege" {
+		return true
+	}
+	for _, at := range tt.AttackTypes {
+		if at.Name == attackType {
+			return at.Siege
+		}
+	}
+	return false
+}
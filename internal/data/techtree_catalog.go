@@ -0,0 +1,72 @@
+package data
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TechTreeInfo describes a tech tree installed in a data directory
+type TechTreeInfo struct {
+	Name        string   // Directory name, used to select the tech tree
+	Root        string   // Tech tree directory, for NewAssetManager
+	Description string   // Description from the tech tree XML
+	Factions    []string // Faction directories, sorted
+}
+
+// TechTreesDir returns the directory holding the tech trees of a data directory
+func TechTreesDir(dataRoot string) string {
+	return filepath.Join(dataRoot, "techs")
+}
+
+// DiscoverTechTrees lists the tech trees installed in a data directory,
+// sorted by name. Directories without a tech tree XML file are skipped.
+func DiscoverTechTrees(dataRoot string) ([]TechTreeInfo, error) {
+	techsDir := TechTreesDir(dataRoot)
+	if _, err := os.Stat(techsDir); err != nil {
+		return nil, fmt.Errorf("failed to read tech trees directory %s: %w", techsDir, err)
+	}
+
+	var trees []TechTreeInfo
+	for _, name := range subdirectoryNames(techsDir) {
+		root := filepath.Join(techsDir, name)
+		techTree, err := LoadTechTree(TechTreeXMLPath(root))
+		if err != nil {
+			continue
+		}
+		factions := subdirectoryNames(filepath.Join(root, "factions"))
+		sort.Strings(factions)
+		trees = append(trees, TechTreeInfo{
+			Name:        name,
+			Root:        root,
+			Description: techTree.Description.Value,
+			Factions:    factions,
+		})
+	}
+	sort.Slice(trees, func(i, j int) bool { return trees[i].Name < trees[j].Name })
+	return trees, nil
+}
+
+// FindTechTree returns an installed tech tree by name
+func FindTechTree(dataRoot, name string) (TechTreeInfo, error) {
+	trees, err := DiscoverTechTrees(dataRoot)
+	if err != nil {
+		return TechTreeInfo{}, err
+	}
+	names := make([]string, 0, len(trees))
+	for _, tree := range trees {
+		if tree.Name == name {
+			return tree, nil
+		}
+		names = append(names, tree.Name)
+	}
+	return TechTreeInfo{}, fmt.Errorf("tech tree %q is not installed (available: %s)", name, strings.Join(names, ", "))
+}
+
+// HasFaction reports whether the tech tree has a faction
+func (t TechTreeInfo) HasFaction(name string) bool {
+	index := sort.SearchStrings(t.Factions, name)
+	return index < len(t.Factions) && t.Factions[index] == name
+}
@@ -0,0 +1,37 @@
+package data
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverTechTrees(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "techs/megapack/megapack.xml", `<tech-tree><description value="The classic factions"/></tech-tree>`)
+	writeTestFile(t, root, "techs/megapack/factions/tech/tech.xml", `<faction/>`)
+	writeTestFile(t, root, "techs/megapack/factions/magic/magic.xml", `<faction/>`)
+	writeTestFile(t, root, "techs/annex/annex.xml", `<tech-tree/>`)
+	writeTestFile(t, root, "techs/unfinished/notes.txt", "no tech tree XML yet")
+
+	trees, err := DiscoverTechTrees(root)
+	if err != nil {
+		t.Fatalf("DiscoverTechTrees failed: %v", err)
+	}
+	if len(trees) != 2 || trees[0].Name != "annex" || trees[1].Name != "megapack" {
+		t.Fatalf("Expected annex and megapack, got %+v", trees)
+	}
+	megapack := trees[1]
+	if megapack.Description != "The classic factions" || megapack.Root != filepath.Join(root, "techs", "megapack") {
+		t.Errorf("Unexpected tech tree %+v", megapack)
+	}
+	if !megapack.HasFaction("magic") || !megapack.HasFaction("tech") || megapack.HasFaction("elves") {
+		t.Errorf("Unexpected factions %v", megapack.Factions)
+	}
+
+	if _, err := FindTechTree(root, "unfinished"); err == nil {
+		t.Error("Expected a directory without tech tree XML not to be found")
+	}
+	if _, err := DiscoverTechTrees(filepath.Join(root, "missing")); err == nil {
+		t.Error("Expected an error for a data directory without techs")
+	}
+}
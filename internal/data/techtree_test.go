@@ -1,6 +1,8 @@
 package data
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -82,4 +84,30 @@ func TestTechTreeParsing(t *testing.T) {
 	if techTree.HasArmorType("nonexistent") {
 		t.Error("Expected HasArmorType('nonexistent') to return false")
 	}
-}
\ No newline at end of file
+}
+
+// TestTechTreeSiegeAttacks tests that attack types marked siege in the XML are
+// recognised, along with the built-in "siege" type
+func TestTechTreeSiegeAttacks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "siege.xml")
+	content := `<tech-tree>
+	<attack-types>
+		<attack-type name="slashing"/>
+		<attack-type name="impact" siege="true"/>
+	</attack-types>
+</tech-tree>`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write tech tree: %v", err)
+	}
+	techTree, err := LoadTechTree(path)
+	if err != nil {
+		t.Fatalf("Failed to load tech tree: %v", err)
+	}
+
+	if !techTree.IsSiegeAttack("impact") || !techTree.IsSiegeAttack("siege") {
+		t.Error("Expected impact and siege to be siege attacks")
+	}
+	if techTree.IsSiegeAttack("slashing") || techTree.IsSiegeAttack("nonexistent") {
+		t.Error("Expected other attack types not to be siege attacks")
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"teraglest/internal/logging"
 )
 
 // Unit represents a complete unit definition from unit.xml files
@@ -29,11 +32,15 @@ type UnitParameters struct {
 	Cellmap              UnitCellmap           `xml:"cellmap"`
 	Fields               []Field               `xml:"fields>field"`
 	ResourceRequirements []ResourceRequirement `xml:"resource-requirements>resource"`
+	UnitRequirements     []UnitRequirement     `xml:"unit-requirements>unit"`
+	UpgradeRequirements  []UpgradeRequirement  `xml:"upgrade-requirements>upgrade"`
 	Image                UnitImage             `xml:"image"`
 	ImageCancel          UnitImageCancel       `xml:"image-cancel"`
 	MeetingPoint         UnitMeetingPoint      `xml:"meeting-point"`
 	SelectionSounds      *SoundGroup           `xml:"selection-sounds,omitempty"`
 	CommandSounds        *SoundGroup           `xml:"command-sounds,omitempty"`
+	PowerRequirement     *UnitPowerRequirement `xml:"power-requirement,omitempty"`
+	Garrison             *UnitGarrison         `xml:"garrison,omitempty"`
 }
 
 // Unit parameter helper structs for XML parsing
@@ -45,6 +52,19 @@ type UnitHeight struct {
 	Value int `xml:"value,attr"`
 }
 
+// UnitPowerRequirement makes a building pause production unless workers are
+// garrisoned in it and its energy upkeep is paid,
+// e.g. <power-requirement workers="2" energy="10"/>
+type UnitPowerRequirement struct {
+	Workers int `xml:"workers,attr"` // Workers that must be garrisoned inside
+	Energy  int `xml:"energy,attr"`  // Energy paid per minute of game time
+}
+
+// UnitGarrison lets a building shelter units, e.g. <garrison capacity="5"/>
+type UnitGarrison struct {
+	Capacity int `xml:"capacity,attr"` // Units that fit inside
+}
+
 type UnitArmor struct {
 	Value int `xml:"value,attr"`
 }
@@ -105,6 +125,16 @@ type ResourceRequirement struct {
 	Amount int    `xml:"amount,attr"`
 }
 
+// UnitRequirement names a unit that must exist before this unit can be created
+type UnitRequirement struct {
+	Name string `xml:"name,attr"`
+}
+
+// UpgradeRequirement names an upgrade that must be researched before this unit can be created
+type UpgradeRequirement struct {
+	Name string `xml:"name,attr"`
+}
+
 // SoundGroup represents a collection of sound files for unit feedback
 type SoundGroup struct {
 	Enabled bool        `xml:"enabled,attr"`
@@ -125,6 +155,7 @@ type Skill struct {
 	AnimSpeed   SkillAnimSpeed   `xml:"anim-speed"`
 	Animation   SkillAnimation   `xml:"animation"`
 	Sound       *SkillSound      `xml:"sound,omitempty"`
+	Events      []SkillEvent     `xml:"events>event,omitempty"` // Named markers on the animation timeline
 
 	// Attack-specific fields
 	AttackStrength  *SkillAttackStrength  `xml:"attack-strenght,omitempty"` // Note: typo in original XML
@@ -181,6 +212,13 @@ type SkillAttackStartTime struct {
 	Value float64 `xml:"value,attr"`
 }
 
+// SkillEvent is a named marker on a skill's animation timeline, such as a
+// footstep, the launch of a projectile or the impact of a blow
+type SkillEvent struct {
+	Name string  `xml:"name,attr"`
+	Time float64 `xml:"time,attr"` // Share of the animation cycle, 0-1
+}
+
 // SkillSound represents sound configuration for skills
 type SkillSound struct {
 	Enabled   bool        `xml:"enabled,attr"`
@@ -215,16 +253,20 @@ type Command struct {
 	RepairSkill     *CommandRepairSkill  `xml:"repair-skill,omitempty"`
 	MorphSkill      *CommandMorphSkill   `xml:"morph-skill,omitempty"`
 	StopSkill       *CommandStopSkill    `xml:"stop-skill,omitempty"`
+	ProduceSkill    *CommandProduceSkill `xml:"produce-skill,omitempty"`
+	UpgradeSkill    *CommandUpgradeSkill `xml:"upgrade-skill,omitempty"`
 
 	// Command-specific configuration
-	AttackRange        *CommandAttackRange `xml:"attack-range,omitempty"`
-	AttackType         *CommandAttackType  `xml:"attack-type,omitempty"`
-	Buildings          []Building          `xml:"buildings>building,omitempty"`
-	HarvestedResources []HarvestedResource `xml:"harvested-resources>resource,omitempty"`
-	MaxLoad            *CommandMaxLoad     `xml:"max-load,omitempty"`
-	HitsPerUnit        *CommandHitsPerUnit `xml:"hits-per-unit,omitempty"`
-	MorphUnit          *CommandMorphUnit   `xml:"morph-unit,omitempty"`
-	Discount           *CommandDiscount    `xml:"discount,omitempty"`
+	AttackRange        *CommandAttackRange     `xml:"attack-range,omitempty"`
+	AttackType         *CommandAttackType      `xml:"attack-type,omitempty"`
+	Buildings          []Building              `xml:"buildings>building,omitempty"`
+	HarvestedResources []HarvestedResource     `xml:"harvested-resources>resource,omitempty"`
+	MaxLoad            *CommandMaxLoad         `xml:"max-load,omitempty"`
+	HitsPerUnit        *CommandHitsPerUnit     `xml:"hits-per-unit,omitempty"`
+	MorphUnit          *CommandMorphUnit       `xml:"morph-unit,omitempty"`
+	Discount           *CommandDiscount        `xml:"discount,omitempty"`
+	ProducedUnit       *CommandProducedUnit    `xml:"produced-unit,omitempty"`
+	ProducedUpgrade    *CommandProducedUpgrade `xml:"produced-upgrade,omitempty"`
 }
 
 // Command helper structs for XML parsing
@@ -268,6 +310,14 @@ type CommandStopSkill struct {
 	Value string `xml:"value,attr"`
 }
 
+type CommandProduceSkill struct {
+	Value string `xml:"value,attr"`
+}
+
+type CommandUpgradeSkill struct {
+	Value string `xml:"value,attr"`
+}
+
 type CommandAttackRange struct {
 	Value int `xml:"value,attr"`
 }
@@ -292,6 +342,14 @@ type CommandDiscount struct {
 	Value int `xml:"value,attr"`
 }
 
+type CommandProducedUnit struct {
+	Name string `xml:"name,attr"`
+}
+
+type CommandProducedUpgrade struct {
+	Name string `xml:"name,attr"`
+}
+
 // Building represents a building type that can be constructed
 type Building struct {
 	Name string `xml:"name,attr"`
@@ -346,7 +404,7 @@ func LoadAllUnitsFromFaction(unitsDir string) ([]UnitDefinition, error) {
 
 		// Check if the unit XML file exists
 		if _, err := os.Stat(unitXMLPath); os.IsNotExist(err) {
-			fmt.Printf("Warning: No XML file found for unit %s at %s\n", unitName, unitXMLPath)
+			logging.Warnf(logging.CategoryData, "No XML file found for unit %s at %s", unitName, unitXMLPath)
 			continue
 		}
 
@@ -365,31 +423,27 @@ func LoadAllUnitsFromFaction(unitsDir string) ([]UnitDefinition, error) {
 	return units, nil
 }
 
-// PrintUnits prints all units with their basic stats and costs
+// PrintUnits logs all units with their basic stats and costs
 func PrintUnits(units []UnitDefinition) {
-	fmt.Println("Units:")
+	logging.Debugf(logging.CategoryData, "Units:")
 	for i, unit := range units {
-		fmt.Printf("  %d. %s\n", i+1, unit.Name)
-		fmt.Printf("    HP: %d (armor: %d %s)\n",
+		logging.Debugf(logging.CategoryData, "  %d. %s", i+1, unit.Name)
+		logging.Debugf(logging.CategoryData, "    HP: %d (armor: %d %s)",
 			unit.Unit.Parameters.MaxHP.Value,
 			unit.Unit.Parameters.Armor,
 			unit.Unit.Parameters.ArmorType)
 
 		if len(unit.Unit.Parameters.ResourceRequirements) > 0 {
-			fmt.Print("    Cost: ")
+			costs := make([]string, len(unit.Unit.Parameters.ResourceRequirements))
 			for j, req := range unit.Unit.Parameters.ResourceRequirements {
-				if j > 0 {
-					fmt.Print(", ")
-				}
-				fmt.Printf("%s %d", req.Name, req.Amount)
+				costs[j] = fmt.Sprintf("%s %d", req.Name, req.Amount)
 			}
-			fmt.Println()
+			logging.Debugf(logging.CategoryData, "    Cost: %s", strings.Join(costs, ", "))
 		}
 
-		fmt.Printf("    Skills: %d, Commands: %d\n",
+		logging.Debugf(logging.CategoryData, "    Skills: %d, Commands: %d",
 			len(unit.Unit.Skills),
 			len(unit.Unit.Commands))
-		fmt.Println()
 	}
 }
 
@@ -433,6 +487,40 @@ func (ud *UnitDefinition) GetSkillByName(skillName string) *Skill {
 	return nil
 }
 
+// AttackSkill returns the unit's first attack skill that deals damage, or nil
+// when it cannot attack
+func (ud *UnitDefinition) AttackSkill() *Skill {
+	for i := range ud.Unit.Skills {
+		skill := &ud.Unit.Skills[i]
+		if skill.Type.Value == "attack" && skill.AttackStrength != nil {
+			return skill
+		}
+	}
+	return nil
+}
+
+// SkillOfType returns the unit's first skill of a type such as "move" or
+// "die", or nil when it has none
+func (ud *UnitDefinition) SkillOfType(skillType string) *Skill {
+	for i := range ud.Unit.Skills {
+		if skill := &ud.Unit.Skills[i]; skill.Type.Value == skillType {
+			return skill
+		}
+	}
+	return nil
+}
+
+// AnimationsPerSecond converts the skill's animation speed into how often its
+// animation plays per second
+func (s *Skill) AnimationsPerSecond() float64 {
+	return float64(s.AnimSpeed.Value) / skillSpeedDivider
+}
+
+// AttacksPerSecond converts the skill speed into how often it is used per second
+func (s *Skill) AttacksPerSecond() float64 {
+	return float64(s.Speed.Value) / skillSpeedDivider
+}
+
 // GetCommandByName finds a command by its name
 func (ud *UnitDefinition) GetCommandByName(commandName string) *Command {
 	for _, command := range ud.Unit.Commands {
@@ -441,4 +529,42 @@ func (ud *UnitDefinition) GetCommandByName(commandName string) *Command {
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+// SkillReferences returns the skill names a command uses, keyed by the XML element that names them
+func (c *Command) SkillReferences() map[string]string {
+	refs := make(map[string]string)
+	add := func(element, value string) {
+		if value != "" {
+			refs[element] = value
+		}
+	}
+
+	if c.MoveSkill != nil {
+		add("move-skill", c.MoveSkill.Value)
+	}
+	if c.AttackSkill != nil {
+		add("attack-skill", c.AttackSkill.Value)
+	}
+	if c.BuildSkill != nil {
+		add("build-skill", c.BuildSkill.Value)
+	}
+	if c.HarvestSkill != nil {
+		add("harvest-skill", c.HarvestSkill.Value)
+	}
+	if c.RepairSkill != nil {
+		add("repair-skill", c.RepairSkill.Value)
+	}
+	if c.MorphSkill != nil {
+		add("morph-skill", c.MorphSkill.Value)
+	}
+	if c.StopSkill != nil {
+		add("stop-skill", c.StopSkill.Value)
+	}
+	if c.ProduceSkill != nil {
+		add("produce-skill", c.ProduceSkill.Value)
+	}
+	if c.UpgradeSkill != nil {
+		add("upgrade-skill", c.UpgradeSkill.Value)
+	}
+	return refs
+}
@@ -0,0 +1,85 @@
+package data
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"teraglest/internal/logging"
+)
+
+// Upgrade represents a research definition from upgrades/<name>/<name>.xml
+type Upgrade struct {
+	XMLName              xml.Name              `xml:"upgrade"`
+	Image                UnitImage             `xml:"image"`
+	Time                 UnitTime              `xml:"time"`
+	UnitRequirements     []UpgradeUnitRef      `xml:"unit-requirements>unit"`
+	UpgradeRequirements  []UpgradeUnitRef      `xml:"upgrade-requirements>upgrade"`
+	ResourceRequirements []ResourceRequirement `xml:"resource-requirements>resource"`
+	Effects              []UpgradeUnitRef      `xml:"effects>unit"`
+}
+
+// UpgradeUnitRef names a unit or upgrade referenced by an upgrade
+type UpgradeUnitRef struct {
+	Name string `xml:"name,attr"`
+}
+
+// UpgradeDefinition represents a complete upgrade with its name and parsed data
+type UpgradeDefinition struct {
+	Name    string  // Upgrade name (derived from directory name)
+	Upgrade Upgrade // Parsed XML data
+}
+
+// LoadUpgrade parses a single upgrade XML file
+func LoadUpgrade(xmlPath string) (*Upgrade, error) {
+	data, err := os.ReadFile(xmlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upgrade file %s: %w", xmlPath, err)
+	}
+
+	var upgrade Upgrade
+	if err := xml.Unmarshal(data, &upgrade); err != nil {
+		return nil, fmt.Errorf("failed to parse upgrade XML %s: %w", xmlPath, err)
+	}
+
+	return &upgrade, nil
+}
+
+// LoadAllUpgradesFromFaction loads all upgrade definitions from a faction's upgrades directory.
+// A missing directory is not an error because many factions have no upgrades.
+func LoadAllUpgradesFromFaction(upgradesDir string) ([]UpgradeDefinition, error) {
+	entries, err := os.ReadDir(upgradesDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upgrades directory %s: %w", upgradesDir, err)
+	}
+
+	var upgrades []UpgradeDefinition
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		upgradeName := entry.Name()
+		upgradeXMLPath := filepath.Join(upgradesDir, upgradeName, upgradeName+".xml")
+		if _, err := os.Stat(upgradeXMLPath); os.IsNotExist(err) {
+			logging.Warnf(logging.CategoryData, "No XML file found for upgrade %s at %s", upgradeName, upgradeXMLPath)
+			continue
+		}
+
+		upgrade, err := LoadUpgrade(upgradeXMLPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load upgrade %s: %w", upgradeName, err)
+		}
+
+		upgrades = append(upgrades, UpgradeDefinition{
+			Name:    upgradeName,
+			Upgrade: *upgrade,
+		})
+	}
+
+	return upgrades, nil
+}
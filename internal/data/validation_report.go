@@ -0,0 +1,165 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ParseValidationSeverity converts a severity name (case-insensitive) into a ValidationSeverity
+func ParseValidationSeverity(name string) (ValidationSeverity, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "error", "errors":
+		return ValidationError, nil
+	case "warning", "warnings", "warn":
+		return ValidationWarning, nil
+	case "info":
+		return ValidationInfo, nil
+	default:
+		return ValidationError, fmt.Errorf("unknown severity %q (expected error, warning or info)", name)
+	}
+}
+
+// MarshalText encodes the severity by name so JSON reports are readable
+func (s ValidationSeverity) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(s.String())), nil
+}
+
+// UnmarshalText decodes a severity name
+func (s *ValidationSeverity) UnmarshalText(text []byte) error {
+	severity, err := ParseValidationSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// AtLeast reports whether the severity is as severe as threshold or more
+// (errors are the most severe)
+func (s ValidationSeverity) AtLeast(threshold ValidationSeverity) bool {
+	return s <= threshold
+}
+
+// NewValidationReport creates an empty report
+func NewValidationReport() *ValidationReport {
+	return &ValidationReport{
+		Issues:    make([]ValidationIssue, 0),
+		Timestamp: time.Now(),
+	}
+}
+
+// AddIssue appends an issue and updates the severity counts
+func (report *ValidationReport) AddIssue(issue ValidationIssue) {
+	if issue.Timestamp.IsZero() {
+		issue.Timestamp = time.Now()
+	}
+	report.Issues = append(report.Issues, issue)
+
+	switch issue.Severity {
+	case ValidationError:
+		report.ErrorCount++
+	case ValidationWarning:
+		report.WarningCount++
+	case ValidationInfo:
+		report.InfoCount++
+	}
+}
+
+// Merge appends another report's issues, file counts and duration
+func (report *ValidationReport) Merge(other *ValidationReport) {
+	if other == nil {
+		return
+	}
+	for _, issue := range other.Issues {
+		report.AddIssue(issue)
+	}
+	report.FilesChecked += other.FilesChecked
+	report.Duration += other.Duration
+}
+
+// CountAtLeast returns the number of issues at or above a severity
+func (report *ValidationReport) CountAtLeast(threshold ValidationSeverity) int {
+	count := 0
+	for _, issue := range report.Issues {
+		if issue.Severity.AtLeast(threshold) {
+			count++
+		}
+	}
+	return count
+}
+
+// Filter returns a copy of the report containing only issues at or above a severity
+func (report *ValidationReport) Filter(threshold ValidationSeverity) *ValidationReport {
+	filtered := &ValidationReport{
+		Issues:       make([]ValidationIssue, 0, len(report.Issues)),
+		FilesChecked: report.FilesChecked,
+		Duration:     report.Duration,
+		Timestamp:    report.Timestamp,
+	}
+	for _, issue := range report.Issues {
+		if issue.Severity.AtLeast(threshold) {
+			filtered.AddIssue(issue)
+		}
+	}
+	return filtered
+}
+
+// validationReportJSON is the machine-readable form of a report
+type validationReportJSON struct {
+	FilesChecked int                   `json:"files_checked"`
+	DurationMS   int64                 `json:"duration_ms"`
+	Timestamp    time.Time             `json:"timestamp"`
+	ErrorCount   int                   `json:"errors"`
+	WarningCount int                   `json:"warnings"`
+	InfoCount    int                   `json:"info"`
+	Issues       []validationIssueJSON `json:"issues"`
+}
+
+// validationIssueJSON is the machine-readable form of an issue
+type validationIssueJSON struct {
+	Severity   ValidationSeverity `json:"severity"`
+	Category   string             `json:"category"`
+	Message    string             `json:"message"`
+	File       string             `json:"file,omitempty"`
+	Line       int                `json:"line,omitempty"`
+	Field      string             `json:"field,omitempty"`
+	Value      string             `json:"value,omitempty"`
+	Context    string             `json:"context,omitempty"`
+	Suggestion string             `json:"suggestion,omitempty"`
+}
+
+// WriteJSON writes the report as indented JSON
+func (report *ValidationReport) WriteJSON(w io.Writer) error {
+	out := validationReportJSON{
+		FilesChecked: report.FilesChecked,
+		DurationMS:   report.Duration.Milliseconds(),
+		Timestamp:    report.Timestamp,
+		ErrorCount:   report.ErrorCount,
+		WarningCount: report.WarningCount,
+		InfoCount:    report.InfoCount,
+		Issues:       make([]validationIssueJSON, 0, len(report.Issues)),
+	}
+	for _, issue := range report.Issues {
+		out.Issues = append(out.Issues, validationIssueJSON{
+			Severity:   issue.Severity,
+			Category:   issue.Category,
+			Message:    issue.Message,
+			File:       issue.File,
+			Line:       issue.Line,
+			Field:      issue.Field,
+			Value:      issue.Value,
+			Context:    issue.Context,
+			Suggestion: issue.Suggestion,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode validation report: %w", err)
+	}
+	return nil
+}
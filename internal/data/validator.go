@@ -50,7 +50,8 @@ type DataValidator struct {
 	techTree         *TechTree
 	resources        []ResourceDefinition
 	factions         []FactionDefinition
-	enableFileChecks bool // Whether to perform file existence checks
+	enableFileChecks bool             // Whether to perform file existence checks
+	dependencyGraph  *DependencyGraph // Built by CrossReferenceRule
 }
 
 // ValidationRule defines a validation rule that can be applied to game data
@@ -113,7 +114,7 @@ func (v *DataValidator) ValidateTechTree() (*ValidationReport, error) {
 		if err != nil {
 			v.addIssue(report, ValidationError, "Data Loading",
 				"Failed to load tech tree for validation",
-				"megapack.xml", 0, "tech-tree", "", "", "Ensure tech tree XML is valid")
+				v.techTreeFileName(), 0, "tech-tree", "", "", "Ensure tech tree XML is valid")
 			return report, err
 		}
 	}
@@ -124,14 +125,14 @@ func (v *DataValidator) ValidateTechTree() (*ValidationReport, error) {
 	if len(v.techTree.AttackTypes) == 0 {
 		v.addIssue(report, ValidationWarning, "Data Completeness",
 			"No attack types defined in tech tree",
-			"megapack.xml", 0, "attack-types", "", "", "Add at least one attack type")
+			v.techTreeFileName(), 0, "attack-types", "", "", "Add at least one attack type")
 	}
 
 	// Validate armor types
 	if len(v.techTree.ArmorTypes) == 0 {
 		v.addIssue(report, ValidationWarning, "Data Completeness",
 			"No armor types defined in tech tree",
-			"megapack.xml", 0, "armor-types", "", "", "Add at least one armor type")
+			v.techTreeFileName(), 0, "armor-types", "", "", "Add at least one armor type")
 	}
 
 	// Validate damage multipliers reference valid attack and armor types
@@ -186,6 +187,13 @@ func (v *DataValidator) ValidateFaction(factionName string) (*ValidationReport,
 	// Load and validate all units in this faction
 	v.validateFactionUnits(faction, report)
 
+	// Check the raw XML for typos the loaders would silently ignore
+	v.validateFactionSchema(factionName, report)
+
+	// Check commands, skills and upgrades reference things that exist
+	v.dependencyGraph = NewDependencyGraph()
+	v.validateFactionReferences(factionName, report, v.dependencyGraph)
+
 	v.countIssues(report)
 	return report, nil
 }
@@ -231,8 +239,8 @@ func (v *DataValidator) loadCoreData(report *ValidationReport) error {
 		v.techTree, err = v.assetManager.LoadTechTree()
 		if err != nil {
 			v.addIssue(report, ValidationError, "Data Loading",
-				"Failed to load tech tree", "megapack.xml", 0, "tech-tree", "", "",
-				"Ensure megapack.xml exists and is valid")
+				"Failed to load tech tree", v.techTreeFileName(), 0, "tech-tree", "", "",
+				fmt.Sprintf("Ensure %s exists and is valid", v.techTreeFileName()))
 			return err
 		}
 	}
@@ -280,7 +288,7 @@ func (v *DataValidator) validateDamageMultipliers(report *ValidationReport) {
 		if !attackTypeMap[dm.Attack] {
 			v.addIssue(report, ValidationError, "XML Reference",
 				fmt.Sprintf("Damage multiplier references unknown attack type '%s'", dm.Attack),
-				"megapack.xml", 0, "damage-multiplier", dm.Attack,
+				v.techTreeFileName(), 0, "damage-multiplier", dm.Attack,
 				fmt.Sprintf("attack:%s -> armor:%s = %.1f", dm.Attack, dm.Armor, dm.Value),
 				"Ensure attack type is defined in attack-types section")
 		}
@@ -288,7 +296,7 @@ func (v *DataValidator) validateDamageMultipliers(report *ValidationReport) {
 		if !armorTypeMap[dm.Armor] {
 			v.addIssue(report, ValidationError, "XML Reference",
 				fmt.Sprintf("Damage multiplier references unknown armor type '%s'", dm.Armor),
-				"megapack.xml", 0, "damage-multiplier", dm.Armor,
+				v.techTreeFileName(), 0, "damage-multiplier", dm.Armor,
 				fmt.Sprintf("attack:%s -> armor:%s = %.1f", dm.Attack, dm.Armor, dm.Value),
 				"Ensure armor type is defined in armor-types section")
 		}
@@ -447,6 +455,14 @@ func (v *DataValidator) validateUnit(faction *FactionDefinition, unit *UnitDefin
 			fmt.Sprintf("value: %d", unit.Unit.Parameters.Size.Value),
 			"Units should have positive size values")
 	}
+
+	if power := unit.Unit.Parameters.PowerRequirement; power != nil && (power.Workers < 0 || power.Energy < 0) {
+		v.addIssue(report, ValidationError, "Data Consistency",
+			fmt.Sprintf("Unit '%s' has a negative power requirement", unit.Name),
+			unitFile, 0, "power-requirement", "",
+			fmt.Sprintf("workers: %d, energy: %d", power.Workers, power.Energy),
+			"Use non-negative worker and energy requirements")
+	}
 }
 
 // Validate assets referenced by a faction exist
@@ -556,6 +572,11 @@ func (v *DataValidator) validateUnitAssets(factionName, unitName string, report
 	}
 }
 
+// techTreeFileName returns the name of the tech tree definition file used in issue reports
+func (v *DataValidator) techTreeFileName() string {
+	return filepath.Base(TechTreeXMLPath(v.techTreeRoot))
+}
+
 // Helper method to add an issue to the validation report
 func (v *DataValidator) addIssue(report *ValidationReport, severity ValidationSeverity, category, message, file string, line int, field, value, context, suggestion string) {
 	issue := ValidationIssue{
@@ -701,14 +722,14 @@ func (r *TechTreeValidationRule) Validate(validator *DataValidator, report *Vali
 	// Validate attack types
 	if len(validator.techTree.AttackTypes) == 0 {
 		validator.addIssue(report, ValidationWarning, "Tech Tree Structure",
-			"No attack types defined", "megapack.xml", 0, "attack-types", "", "",
+			"No attack types defined", validator.techTreeFileName(), 0, "attack-types", "", "",
 			"Define at least one attack type")
 	}
 
 	// Validate armor types
 	if len(validator.techTree.ArmorTypes) == 0 {
 		validator.addIssue(report, ValidationWarning, "Tech Tree Structure",
-			"No armor types defined", "megapack.xml", 0, "armor-types", "", "",
+			"No armor types defined", validator.techTreeFileName(), 0, "armor-types", "", "",
 			"Define at least one armor type")
 	}
 
@@ -721,7 +742,7 @@ func (r *TechTreeValidationRule) Validate(validator *DataValidator, report *Vali
 		if attackNames[at.Name] {
 			validator.addIssue(report, ValidationError, "Data Consistency",
 				fmt.Sprintf("Duplicate attack type name: %s", at.Name),
-				"megapack.xml", 0, "attack-type", at.Name, "",
+				validator.techTreeFileName(), 0, "attack-type", at.Name, "",
 				"Use unique names for attack types")
 		}
 		attackNames[at.Name] = true
@@ -733,7 +754,7 @@ func (r *TechTreeValidationRule) Validate(validator *DataValidator, report *Vali
 		if armorNames[at.Name] {
 			validator.addIssue(report, ValidationError, "Data Consistency",
 				fmt.Sprintf("Duplicate armor type name: %s", at.Name),
-				"megapack.xml", 0, "armor-type", at.Name, "",
+				validator.techTreeFileName(), 0, "armor-type", at.Name, "",
 				"Use unique names for armor types")
 		}
 		armorNames[at.Name] = true
@@ -880,11 +901,12 @@ func (r *AssetExistenceRule) Validate(validator *DataValidator, report *Validati
 	}
 
 	// Validate tech tree file exists
-	techTreePath := filepath.Join(validator.techTreeRoot, "megapack.xml")
+	techTreePath := TechTreeXMLPath(validator.techTreeRoot)
+	techTreeFile := filepath.Base(techTreePath)
 	if _, err := os.Stat(techTreePath); os.IsNotExist(err) {
 		validator.addIssue(report, ValidationError, "Asset Missing",
-			"Tech tree file not found", "megapack.xml", 0, "file", "megapack.xml", "",
-			"Ensure megapack.xml exists in tech tree root")
+			"Tech tree file not found", techTreeFile, 0, "file", techTreeFile, "",
+			fmt.Sprintf("Ensure %s exists in tech tree root", techTreeFile))
 	}
 
 	// Validate resources directory exists
@@ -906,12 +928,69 @@ func (r *AssetExistenceRule) Validate(validator *DataValidator, report *Validati
 	return nil
 }
 
+// SchemaValidationRule checks faction, unit and upgrade XML against the MegaGlest schema
+type SchemaValidationRule struct{}
+
+func (r *SchemaValidationRule) Name() string { return "XML Schema Validation" }
+func (r *SchemaValidationRule) Description() string {
+	return "Validates element names, required values and attribute types in faction XML"
+}
+
+func (r *SchemaValidationRule) Validate(validator *DataValidator, report *ValidationReport) error {
+	if validator.factions == nil {
+		return fmt.Errorf("factions not loaded for schema validation")
+	}
+
+	for _, faction := range validator.factions {
+		validator.validateFactionSchema(faction.Name, report)
+	}
+	return nil
+}
+
+// validateFactionSchema checks the faction XML and every unit and upgrade XML beneath it
+func (v *DataValidator) validateFactionSchema(factionName string, report *ValidationReport) {
+	factionDir := filepath.Join(v.techTreeRoot, "factions", factionName)
+	v.validateSchemaFile(factionDir, factionName, SchemaFaction, report)
+
+	for _, kind := range []SchemaKind{SchemaUnit, SchemaUpgrade} {
+		entries, err := os.ReadDir(filepath.Join(factionDir, string(kind)+"s"))
+		if err != nil {
+			continue // Factions without upgrades are valid
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				v.validateSchemaFile(filepath.Join(factionDir, string(kind)+"s", entry.Name()), entry.Name(), kind, report)
+			}
+		}
+	}
+}
+
+// validateSchemaFile checks dir/<name>.xml and merges the issues into report
+func (v *DataValidator) validateSchemaFile(dir, name string, kind SchemaKind, report *ValidationReport) {
+	path := filepath.Join(dir, name+".xml")
+	reportName, err := filepath.Rel(v.techTreeRoot, path)
+	if err != nil {
+		reportName = path
+	}
+
+	schemaReport, err := ValidateXMLSchema(path, filepath.ToSlash(reportName), kind)
+	if err != nil {
+		v.addIssue(report, ValidationError, "Asset Missing",
+			fmt.Sprintf("Cannot read %s definition: %v", kind, err), filepath.ToSlash(reportName), 0,
+			string(kind), name, "", fmt.Sprintf("Add %s.xml to %s", name, filepath.Base(dir)))
+		return
+	}
+	report.Merge(schemaReport)
+}
+
 // Get default validation rules
 func getDefaultValidationRules() []ValidationRule {
 	return []ValidationRule{
 		&TechTreeValidationRule{},
 		&ResourceValidationRule{},
 		&FactionValidationRule{},
+		&SchemaValidationRule{},
+		&CrossReferenceRule{},
 		&AssetExistenceRule{},
 	}
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -280,6 +281,8 @@ func TestValidationRules(t *testing.T) {
 		"Tech Tree Validation",
 		"Resource Validation",
 		"Faction Validation",
+		"XML Schema Validation",
+		"Cross Reference Validation",
 		"Asset Existence Validation",
 	}
 
@@ -504,4 +507,39 @@ func TestValidationPerformance(t *testing.T) {
 	}
 
 	t.Logf("Validation performance: %v for %d files", report.Duration, report.FilesChecked)
-}
\ No newline at end of file
+}
+func TestValidationReportThresholds(t *testing.T) {
+	report := NewValidationReport()
+	report.AddIssue(ValidationIssue{Severity: ValidationWarning, Category: "Data Consistency", Message: "odd size"})
+
+	other := NewValidationReport()
+	other.FilesChecked = 2
+	other.AddIssue(ValidationIssue{Severity: ValidationError, Category: "XML Reference", Message: "missing skill"})
+	other.AddIssue(ValidationIssue{Severity: ValidationInfo, Category: "Asset Optional", Message: "no textures"})
+	report.Merge(other)
+
+	if report.ErrorCount != 1 || report.WarningCount != 1 || report.InfoCount != 1 || report.FilesChecked != 2 {
+		t.Errorf("Unexpected merged counts: %+v", report)
+	}
+	if report.CountAtLeast(ValidationError) != 1 || report.CountAtLeast(ValidationWarning) != 2 || report.CountAtLeast(ValidationInfo) != 3 {
+		t.Error("CountAtLeast does not respect severity ordering")
+	}
+	if filtered := report.Filter(ValidationWarning); len(filtered.Issues) != 2 || filtered.InfoCount != 0 {
+		t.Errorf("Filter kept wrong issues: %+v", filtered.Issues)
+	}
+
+	if severity, err := ParseValidationSeverity("Warnings"); err != nil || severity != ValidationWarning {
+		t.Errorf("ParseValidationSeverity failed: %v %v", severity, err)
+	}
+	if _, err := ParseValidationSeverity("fatal"); err == nil {
+		t.Error("Expected error for unknown severity")
+	}
+
+	var buf strings.Builder
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"severity": "error"`) || !strings.Contains(buf.String(), `"errors": 1`) {
+		t.Errorf("Unexpected JSON output: %s", buf.String())
+	}
+}
@@ -0,0 +1,327 @@
+// Package debugdraw collects world-space debug shapes (lines, spheres, grid
+// overlays and text labels) from engine systems for the renderer to draw.
+// Shapes are grouped by category, and only enabled categories are recorded.
+package debugdraw
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Category groups debug shapes by the system that draws them
+type Category string
+
+const (
+	CategoryPaths      Category = "paths"      // Unit movement paths
+	CategoryFormations Category = "formations" // Formation slots of unit groups
+	CategoryExpansion  Category = "expansion"  // AI expansion sites
+	CategoryOccupancy  Category = "occupancy"  // Occupied map tiles and the units on them
+	CategoryWalkable   Category = "walkable"   // Walkable and blocked map tiles
+	CategoryRanges     Category = "ranges"     // Unit attack ranges
+	CategoryRegions    Category = "regions"    // Map regions, chokepoints and expansion sites
+)
+
+// Categories lists every debug draw category
+var Categories = []Category{CategoryPaths, CategoryFormations, CategoryExpansion, CategoryOccupancy, CategoryWalkable, CategoryRanges, CategoryRegions}
+
+// Point is a world-space position; it has the layout of engine.Vector3 so
+// engine positions convert directly
+type Point struct {
+	X, Y, Z float64
+}
+
+// Color is an RGBA color with components in 0-1
+type Color struct {
+	R, G, B, A float32
+}
+
+// Common debug colors
+var (
+	Red     = Color{1, 0.2, 0.2, 1}
+	Green   = Color{0.2, 1, 0.2, 1}
+	Blue    = Color{0.3, 0.5, 1, 1}
+	Yellow  = Color{1, 1, 0.2, 1}
+	Cyan    = Color{0.2, 1, 1, 1}
+	Magenta = Color{1, 0.3, 1, 1}
+	White   = Color{1, 1, 1, 1}
+)
+
+// Line is a line segment
+type Line struct {
+	Category Category
+	From, To Point
+	Color    Color
+}
+
+// Sphere is a wire sphere
+type Sphere struct {
+	Category Category
+	Center   Point
+	Radius   float64
+	Color    Color
+}
+
+// Grid is a map-aligned overlay of colored cells on the ground plane
+type Grid struct {
+	Category Category
+	Origin   Point   // Corner of cell (0, 0)
+	CellSize float64 // Cell edge length in world units
+	Width    int
+	Height   int
+	Cells    []Color   // Width*Height, X within a row and rows along Z; cells with zero alpha are not drawn
+	Heights  []float64 // Ground height of each cell, in the layout of Cells; nil for a flat grid at Origin
+}
+
+// Label is text anchored at a world position
+type Label struct {
+	Category Category
+	Position Point
+	Text     string
+	Color    Color
+}
+
+// Shapes is one frame of debug shapes
+type Shapes struct {
+	Lines   []Line
+	Spheres []Sphere
+	Grids   []Grid
+	Labels  []Label
+}
+
+// Count returns the number of shapes
+func (s *Shapes) Count() int {
+	return len(s.Lines) + len(s.Spheres) + len(s.Grids) + len(s.Labels)
+}
+
+// reset empties the shapes, keeping their storage
+func (s *Shapes) reset() {
+	s.Lines = s.Lines[:0]
+	s.Spheres = s.Spheres[:0]
+	s.Grids = s.Grids[:0]
+	s.Labels = s.Labels[:0]
+}
+
+// Drawer records debug shapes. Systems draw into a pending frame that
+// Publish makes visible, so the renderer never sees a half-drawn frame.
+type Drawer struct {
+	mutex     sync.Mutex
+	enabled   map[Category]bool
+	pending   Shapes
+	published Shapes
+}
+
+// New creates a drawer with every category disabled
+func New() *Drawer {
+	return &Drawer{enabled: make(map[Category]bool)}
+}
+
+// std is the process-wide drawer used by engine systems and the renderer
+var std = New()
+
+// Default returns the process-wide drawer
+func Default() *Drawer {
+	return std
+}
+
+// SetEnabled turns recording of a category on or off
+func (d *Drawer) SetEnabled(category Category, enabled bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.enabled[category] = enabled
+}
+
+// Enabled reports whether a category is recorded, so systems can skip
+// gathering data for disabled categories
+func (d *Drawer) Enabled(category Category) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.enabled[category]
+}
+
+// Active reports whether any category is enabled
+func (d *Drawer) Active() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, enabled := range d.enabled {
+		if enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// Line draws a line segment
+func (d *Drawer) Line(category Category, from, to Point, color Color) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.enabled[category] {
+		d.pending.Lines = append(d.pending.Lines, Line{Category: category, From: from, To: to, Color: color})
+	}
+}
+
+// Polyline draws connected line segments through points
+func (d *Drawer) Polyline(category Category, points []Point, color Color) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if !d.enabled[category] {
+		return
+	}
+	for i := 1; i < len(points); i++ {
+		d.pending.Lines = append(d.pending.Lines, Line{Category: category, From: points[i-1], To: points[i], Color: color})
+	}
+}
+
+// Sphere draws a wire sphere
+func (d *Drawer) Sphere(category Category, center Point, radius float64, color Color) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.enabled[category] {
+		d.pending.Spheres = append(d.pending.Spheres, Sphere{Category: category, Center: center, Radius: radius, Color: color})
+	}
+}
+
+// Grid draws a cell overlay; the cells and heights are copied, so the caller may reuse its slices
+func (d *Drawer) Grid(category Category, grid Grid) error {
+	if len(grid.Cells) != grid.Width*grid.Height {
+		return fmt.Errorf("grid of %dx%d needs %d cells, got %d", grid.Width, grid.Height, grid.Width*grid.Height, len(grid.Cells))
+	}
+	if grid.Heights != nil && len(grid.Heights) != len(grid.Cells) {
+		return fmt.Errorf("grid of %d cells has %d heights", len(grid.Cells), len(grid.Heights))
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.enabled[category] {
+		grid.Category = category
+		grid.Cells = append([]Color(nil), grid.Cells...)
+		if grid.Heights != nil {
+			grid.Heights = append([]float64(nil), grid.Heights...)
+		}
+		d.pending.Grids = append(d.pending.Grids, grid)
+	}
+	return nil
+}
+
+// Label draws text at a world position
+func (d *Drawer) Label(category Category, position Point, text string, color Color) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.enabled[category] {
+		d.pending.Labels = append(d.pending.Labels, Label{Category: category, Position: position, Text: text, Color: color})
+	}
+}
+
+// Publish makes the shapes drawn since the last call visible and starts a new frame
+func (d *Drawer) Publish() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.published, d.pending = d.pending, d.published
+	d.pending.reset()
+}
+
+// Snapshot returns a copy of the published shapes of enabled categories
+func (d *Drawer) Snapshot() Shapes {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var shapes Shapes
+	for _, line := range d.published.Lines {
+		if d.enabled[line.Category] {
+			shapes.Lines = append(shapes.Lines, line)
+		}
+	}
+	for _, sphere := range d.published.Spheres {
+		if d.enabled[sphere.Category] {
+			shapes.Spheres = append(shapes.Spheres, sphere)
+		}
+	}
+	for _, grid := range d.published.Grids {
+		if d.enabled[grid.Category] {
+			shapes.Grids = append(shapes.Grids, grid)
+		}
+	}
+	for _, label := range d.published.Labels {
+		if d.enabled[label.Category] {
+			shapes.Labels = append(shapes.Labels, label)
+		}
+	}
+	return shapes
+}
+
+// HandleCommand applies a console command:
+//
+//	debug status                     show enabled categories
+//	debug <category|all> [on|off]    enable, disable or toggle categories
+func (d *Drawer) HandleCommand(command string) (string, error) {
+	fields := strings.Fields(command)
+	if len(fields) > 0 && fields[0] == "debug" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return "", fmt.Errorf("usage: debug status | debug <category|all> [on|off]")
+	}
+	if fields[0] == "status" {
+		return d.status(), nil
+	}
+
+	categories := Categories
+	if fields[0] != "all" {
+		category := Category(strings.ToLower(fields[0]))
+		if !isKnownCategory(category) {
+			return "", fmt.Errorf("unknown debug draw category %q", fields[0])
+		}
+		categories = []Category{category}
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, category := range categories {
+		enabled := !d.enabled[category]
+		if len(fields) == 2 {
+			switch fields[1] {
+			case "on":
+				enabled = true
+			case "off":
+				enabled = false
+			default:
+				return "", fmt.Errorf("expected on or off, got %q", fields[1])
+			}
+		}
+		d.enabled[category] = enabled
+	}
+	return d.statusLocked(), nil
+}
+
+// status lists the enabled categories
+func (d *Drawer) status() string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.statusLocked()
+}
+
+// statusLocked lists the enabled categories; the caller holds the mutex
+func (d *Drawer) statusLocked() string {
+	var names []string
+	for category, enabled := range d.enabled {
+		if enabled {
+			names = append(names, string(category))
+		}
+	}
+	if len(names) == 0 {
+		return "debug draw off"
+	}
+	sort.Strings(names)
+	return "debug draw: " + strings.Join(names, " ")
+}
+
+// isKnownCategory reports whether a category is in Categories
+func isKnownCategory(category Category) bool {
+	for _, known := range Categories {
+		if category == known {
+			return true
+		}
+	}
+	return false
+}
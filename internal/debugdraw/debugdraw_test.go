@@ -0,0 +1,107 @@
+package debugdraw
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDrawerRecordsEnabledCategories tests that only enabled categories are recorded
+func TestDrawerRecordsEnabledCategories(t *testing.T) {
+	d := New()
+	if d.Active() {
+		t.Fatal("Expected a new drawer to have every category disabled")
+	}
+	d.SetEnabled(CategoryPaths, true)
+
+	d.Line(CategoryPaths, Point{}, Point{X: 1}, Cyan)
+	d.Polyline(CategoryPaths, []Point{{}, {X: 1}, {X: 1, Z: 1}}, Cyan)
+	d.Sphere(CategoryRanges, Point{}, 2, Yellow)
+	d.Label(CategoryPaths, Point{}, "goal", White)
+
+	// Nothing is visible until the frame is published
+	if shapes := d.Snapshot(); shapes.Count() != 0 {
+		t.Errorf("Expected no shapes before publishing, got %d", shapes.Count())
+	}
+	d.Publish()
+
+	shapes := d.Snapshot()
+	if len(shapes.Lines) != 3 || len(shapes.Labels) != 1 {
+		t.Errorf("Expected 3 lines and 1 label, got %d and %d", len(shapes.Lines), len(shapes.Labels))
+	}
+	if len(shapes.Spheres) != 0 {
+		t.Error("Expected shapes of disabled categories to be dropped")
+	}
+
+	// Publishing again replaces the frame
+	d.Publish()
+	if shapes := d.Snapshot(); shapes.Count() != 0 {
+		t.Errorf("Expected an empty frame, got %d shapes", shapes.Count())
+	}
+
+	// Disabling a category hides shapes already published
+	d.Line(CategoryPaths, Point{}, Point{X: 1}, Cyan)
+	d.Publish()
+	d.SetEnabled(CategoryPaths, false)
+	if shapes := d.Snapshot(); shapes.Count() != 0 {
+		t.Errorf("Expected disabled categories to be hidden, got %d shapes", shapes.Count())
+	}
+}
+
+// TestDrawerGrid tests grid overlays
+func TestDrawerGrid(t *testing.T) {
+	d := New()
+	d.SetEnabled(CategoryOccupancy, true)
+
+	if err := d.Grid(CategoryOccupancy, Grid{Width: 2, Height: 2, Cells: make([]Color, 3)}); err == nil {
+		t.Error("Expected a grid with the wrong number of cells to fail")
+	}
+	if err := d.Grid(CategoryOccupancy, Grid{Width: 1, Height: 1, Cells: []Color{Red}, Heights: []float64{0, 1}}); err == nil {
+		t.Error("Expected a grid with the wrong number of heights to fail")
+	}
+
+	cells := []Color{Red, {}, {}, Green}
+	if err := d.Grid(CategoryOccupancy, Grid{CellSize: 1, Width: 2, Height: 2, Cells: cells}); err != nil {
+		t.Fatalf("Grid failed: %v", err)
+	}
+	cells[0] = Blue // The drawer keeps its own copy
+	d.Publish()
+
+	shapes := d.Snapshot()
+	if len(shapes.Grids) != 1 || shapes.Grids[0].Cells[0] != Red || shapes.Grids[0].Category != CategoryOccupancy {
+		t.Errorf("Expected the grid as drawn, got %+v", shapes.Grids)
+	}
+}
+
+// TestHandleCommand tests toggling categories from the console
+func TestHandleCommand(t *testing.T) {
+	d := New()
+
+	response, err := d.HandleCommand("debug paths")
+	if err != nil || !d.Enabled(CategoryPaths) || !strings.Contains(response, "paths") {
+		t.Errorf("Expected paths to toggle on, got %q (%v)", response, err)
+	}
+	if _, err := d.HandleCommand("debug paths"); err != nil || d.Enabled(CategoryPaths) {
+		t.Error("Expected paths to toggle off again")
+	}
+
+	if _, err := d.HandleCommand("debug all on"); err != nil {
+		t.Fatalf("debug all on failed: %v", err)
+	}
+	for _, category := range Categories {
+		if !d.Enabled(category) {
+			t.Errorf("Expected %s to be enabled", category)
+		}
+	}
+	if _, err := d.HandleCommand("debug ranges off"); err != nil || d.Enabled(CategoryRanges) {
+		t.Error("Expected ranges to be disabled")
+	}
+	if response, _ := d.HandleCommand("debug status"); strings.Contains(response, "ranges") {
+		t.Errorf("Expected status without ranges, got %q", response)
+	}
+
+	for _, command := range []string{"debug", "debug lasers", "debug paths maybe", "debug paths on now"} {
+		if _, err := d.HandleCommand(command); err == nil {
+			t.Errorf("Expected %q to fail", command)
+		}
+	}
+}
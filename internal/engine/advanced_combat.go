@@ -276,7 +276,7 @@ func (acs *AdvancedCombatSystem) ExecuteAdvancedAttack(attacker, target *GameUni
 	}
 
 	// Update attacker's last attack time
-	attacker.LastAttack = time.Now()
+	attacker.LastAttack = acs.world.now()
 
 	// Log advanced combat event
 	acs.logAdvancedCombatEvent(attacker, result, advancedDamage)
@@ -471,7 +471,7 @@ func (acs *AdvancedCombatSystem) findUnitsInRadius(center Vector3, radius float6
 	for _, player := range players {
 		units := acs.world.ObjectManager.GetUnitsForPlayer(player.ID)
 		for _, unit := range units {
-			if unit.IsAlive() {
+			if unit.IsAlive() && !unit.IsGarrisoned() {
 				distance := acs.world.CalculateDistance(center, unit.Position)
 				if distance <= radius {
 					allUnits = append(allUnits, unit)
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"teraglest/internal/logging"
+)
+
+// AI threat interrupts
+const (
+	interruptSearchRange = 12.0             // Tiles around an attacked spot searched for the attackers
+	interruptMergeRange  = 8.0              // Tiles within which alerts of one tick are answered together
+	workerPullRange      = 8.0              // Tiles from the fight within which workers are pulled back
+	allyHelpInterval     = 30 * time.Second // How often an AI under attack asks its allies for help
+)
+
+// HelpRequest is the data of an EventTypeHelpRequested event
+type HelpRequest struct {
+	PlayerID int     // Player under attack
+	Position Vector3 // Where the attack is
+}
+
+// threatInterrupt is an attack, or an ally's call for help, an AI answers
+// before its next strategic update
+type threatInterrupt struct {
+	position Vector3
+	helpFrom int // Ally asking for help, 0 for an attack on the AI itself
+}
+
+// onWorldEvent queues interrupts for the AI players an event concerns. It
+// runs as the event is raised, so it only takes note; the AIs answer on
+// their next tick.
+func (mgr *StrategicAIManager) onWorldEvent(event GameEvent) {
+	mgr.interruptMutex.Lock()
+	defer mgr.interruptMutex.Unlock()
+	switch event.Type {
+	case EventTypeUnderAttack:
+		if alert, ok := event.Data.(AttackAlert); ok {
+			mgr.pending[event.PlayerID] = append(mgr.pending[event.PlayerID], threatInterrupt{position: alert.Position})
+		}
+	case EventTypeHelpRequested:
+		// Whether the AIs are allied is checked when they answer, out of the event lock
+		if request, ok := event.Data.(HelpRequest); ok {
+			for playerID := range mgr.pending {
+				if playerID != request.PlayerID {
+					mgr.pending[playerID] = append(mgr.pending[playerID], threatInterrupt{position: request.Position, helpFrom: request.PlayerID})
+				}
+			}
+		}
+	}
+}
+
+// takeInterrupts returns and clears the interrupts queued for an AI player
+func (mgr *StrategicAIManager) takeInterrupts(playerID int) []threatInterrupt {
+	mgr.interruptMutex.Lock()
+	defer mgr.interruptMutex.Unlock()
+	interrupts := mgr.pending[playerID]
+	if len(interrupts) > 0 {
+		mgr.pending[playerID] = nil
+	}
+	return interrupts
+}
+
+// HandleInterrupts answers attacks right away instead of at the next
+// strategic update: workers near the fight are pulled back, the army rallies
+// to it, and allies are asked for help. Calls for help from allies send the
+// idle army to them.
+func (ai *StrategicAI) HandleInterrupts(interrupts []threatInterrupt) {
+	reach := interruptMergeRange * float64(ai.world.GetTileSize())
+	var answered []Vector3
+	for _, interrupt := range interrupts {
+		merged := false
+		for _, position := range answered {
+			merged = merged || ai.world.CalculateDistance(position, interrupt.position) <= reach
+		}
+		if merged {
+			continue
+		}
+		if interrupt.helpFrom != 0 {
+			if !ai.world.AreAllied(ai.playerID, interrupt.helpFrom) {
+				continue
+			}
+			ai.rallyArmy(interrupt.position, ai.visibleAttackers(interrupt.position), true)
+			logging.Debugf(logging.CategoryAI, "Player %d sent its army to help player %d", ai.playerID, interrupt.helpFrom)
+		} else {
+			attackers := ai.visibleAttackers(interrupt.position)
+			ai.pullWorkers(interrupt.position, attackers)
+			ai.rallyArmy(interrupt.position, attackers, false)
+			ai.requestAllyHelp(interrupt.position)
+
+			// The next update reassesses the strategy with the attack in mind
+			ai.state.ThreatLevel = 1.0
+			ai.lastUpdateTime = time.Time{}
+			logging.Debugf(logging.CategoryAI, "Player %d answered an attack at (%.0f, %.0f)", ai.playerID, interrupt.position.X, interrupt.position.Z)
+		}
+		answered = append(answered, interrupt.position)
+	}
+}
+
+// visibleAttackers returns the armed enemy units in sight around an attacked
+// spot, sorted by ID
+func (ai *StrategicAI) visibleAttackers(position Vector3) []*GameUnit {
+	var attackers []*GameUnit
+	for _, unit := range ai.world.engagedEnemies(ai.playerID, position, interruptSearchRange*float64(ai.world.GetTileSize())) {
+		if unit.AttackDamage > 0 && ai.world.CanSee(ai.playerID, unit.Position) {
+			attackers = append(attackers, unit)
+		}
+	}
+	return attackers
+}
+
+// pullWorkers moves the workers near a fight back to the nearest building,
+// or away from the fight when there is none, keeping out of the enemy's reach
+func (ai *StrategicAI) pullWorkers(position Vector3, attackers []*GameUnit) {
+	tileSize := float64(ai.world.GetTileSize())
+	for _, unit := range ai.sortedUnits() {
+		if !ai.faction.IsWorker(unit.UnitType) || !unit.IsAlive() || unit.GarrisonedIn != 0 ||
+			ai.world.CalculateDistance(unit.Position, position) > workerPullRange*tileSize {
+			continue
+		}
+		var route []Vector3
+		ok := true
+		if threat, _ := nearestUnit(unit, attackers); threat != nil {
+			route, ok = ai.world.retreatRoute(unit, threat, ai.influence)
+		} else {
+			route = ai.influence.SafeRoute(unit.Position, ai.world.stepAway(unit.Position, position, retreatDistance*tileSize))
+		}
+		if !ok {
+			continue
+		}
+		ai.world.ExecuteTactics([]TacticalOrder{{UnitID: unit.ID, Tactic: TacticRetreat, Position: route[0], Route: route[1:]}})
+	}
+}
+
+// rallyArmy sends military units against the attackers of a spot, or to the
+// spot while the attackers are out of sight. Units already fighting keep
+// their target; when helping an ally only idle units go.
+func (ai *StrategicAI) rallyArmy(position Vector3, attackers []*GameUnit, idleOnly bool) {
+	for _, unit := range ai.sortedUnits() {
+		if !unit.IsAlive() || unit.GarrisonedIn != 0 || !ai.militaryMgr.isMilitaryUnit(unit) {
+			continue
+		}
+		if command := unit.CurrentCommand; command != nil && (idleOnly || command.Type == CommandAttack) {
+			continue
+		}
+		if target, _ := nearestUnit(unit, attackers); target != nil {
+			ai.world.commandProcessor.IssueCommand(unit.ID, CreateAttackCommand(target, false))
+		} else {
+			ai.world.commandProcessor.IssueCommand(unit.ID, CreateMoveCommand(position, false))
+		}
+	}
+}
+
+// requestAllyHelp asks the AI's allies to come to an attacked spot, at most
+// once every allyHelpInterval
+func (ai *StrategicAI) requestAllyHelp(position Vector3) {
+	now := ai.world.now()
+	if !ai.lastHelpRequest.IsZero() && now.Sub(ai.lastHelpRequest) < allyHelpInterval {
+		return
+	}
+	if len(ai.world.GetAllies(ai.playerID)) == 0 {
+		return
+	}
+	ai.lastHelpRequest = now
+	ai.world.raiseEvent(GameEvent{
+		Type:      EventTypeHelpRequested,
+		Timestamp: now,
+		PlayerID:  ai.playerID,
+		Data:      HelpRequest{PlayerID: ai.playerID, Position: position},
+		Message:   fmt.Sprintf("Player %d is under attack at (%.0f, %.0f) and asks for help", ai.playerID, position.X, position.Z),
+	})
+}
+
+// sortedUnits returns the AI player's units sorted by ID
+func (ai *StrategicAI) sortedUnits() []*GameUnit {
+	var units []*GameUnit
+	for _, unit := range ai.world.ObjectManager.GetUnitsForPlayer(ai.playerID) {
+		units = append(units, unit)
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+	return units
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestAIThreatInterrupts tests that an AI answers an attack on the next
+// tick by pulling workers back, rallying its army and asking allies for
+// help, and that an allied AI sends its idle army
+func TestAIThreatInterrupts(t *testing.T) {
+	world, err := NewHeadlessWorld(48, 48)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+	var events []GameEvent
+	world.SetEventSink(func(event GameEvent) { events = append(events, event) })
+
+	world.players[1].IsAI, world.players[1].Team = true, 1
+	world.players[3] = &Player{ID: 3, Name: "Player 3", IsActive: true, IsAI: true, Team: 1, Resources: map[string]int{}}
+	for _, playerID := range []int{1, 3} {
+		if err := world.strategicAIMgr.InitializeAIPlayer(playerID, BalancedPersonality, DifficultyNormal); err != nil {
+			t.Fatalf("Failed to initialize AI %d: %v", playerID, err)
+		}
+	}
+
+	unit := func(playerID int, unitType string, x, z float64) *GameUnit {
+		created, err := world.ObjectManager.CreateUnit(playerID, unitType, Vector3{X: x, Z: z}, data.NewSimpleUnit(unitType, 100, 0, "leather", nil))
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		created.AttackDamage, created.AttackRange, created.AttackSpeed = 10, 1.5, 1
+		return created
+	}
+	if _, err := world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 5.5, Z: 5.5}, data.NewSimpleUnit("castle", 2000, 0, "stone", nil)); err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	worker := unit(1, "worker", 14.5, 5.5)
+	soldier := unit(1, "soldier", 5.5, 12.5)
+	allySoldier := unit(3, "soldier", 40.5, 40.5)
+	raider := unit(2, "raider", 15.5, 5.5)
+
+	world.commandProcessor.combatSystem.ApplyDamage(worker, 5)
+	world.Update(100 * time.Millisecond)
+
+	if command := worker.CurrentCommand; command == nil || command.Parameters[ParamTactic] != TacticRetreat || command.Target.X >= worker.Position.X {
+		t.Errorf("Expected the worker to fall back toward the castle, got %+v", command)
+	}
+	if command := soldier.CurrentCommand; command == nil || command.Type != CommandAttack || command.TargetUnit != raider {
+		t.Errorf("Expected the soldier to rally against the raider, got %+v", command)
+	}
+	if command := allySoldier.CurrentCommand; command == nil || command.Type != CommandMove || world.CalculateDistance(*command.Target, worker.Position) > interruptSearchRange {
+		t.Errorf("Expected the ally to send its soldier to the fight, got %+v", command)
+	}
+	requests := 0
+	for _, event := range events {
+		if event.Type == EventTypeHelpRequested {
+			requests++
+			if request := event.Data.(HelpRequest); request.PlayerID != 1 {
+				t.Errorf("Expected player 1 to ask for help, got %+v", request)
+			}
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("Expected one call for help, got %d", requests)
+	}
+
+	// Further attacks are answered, but allies are not asked again right away
+	world.commandProcessor.combatSystem.ApplyDamage(soldier, 5)
+	world.Update(100 * time.Millisecond)
+	requests = 0
+	for _, event := range events {
+		if event.Type == EventTypeHelpRequested {
+			requests++
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected allies to be asked at most every %v, got %d calls", allyHelpInterval, requests)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"math"
 	"sort"
 	"time"
+
+	"teraglest/internal/logging"
 )
 
 // EconomicManager handles AI economic decisions and resource management
@@ -12,6 +14,7 @@ type EconomicManager struct {
 	world          *World        // Game world reference
 	strategicAI    *StrategicAI  // Parent strategic AI
 	lastEvaluation time.Time     // Last economic evaluation time
+	lastTribute    time.Time     // When the AI last sent resources to an ally
 
 	// Economic state tracking
 	resourcePriorities map[string]float64 // Priority for each resource type
@@ -65,7 +68,10 @@ func (em *EconomicManager) Update(deltaTime time.Duration) {
 	// Evaluate economic situation every 3 seconds
 	if time.Since(em.lastEvaluation) >= 3*time.Second {
 		em.evaluateEconomicSituation()
+		em.tradeForBottleneck()
+		em.considerTribute()
 		em.updateResourcePriorities()
+		em.staffBuildings()
 		em.planProduction()
 		em.manageWorkerAllocation()
 		em.lastEvaluation = time.Now()
@@ -168,6 +174,11 @@ func (em *EconomicManager) updateResourcePriorities() {
 			em.resourcePriorities[resType] = math.Min(1.0, em.resourcePriorities[resType]*1.2)
 		}
 	}
+
+	// In upkeep mode the army's running cost has to be gathered on top
+	for resType := range em.world.ArmyUpkeep(em.playerID) {
+		em.resourcePriorities[resType] = math.Min(1.0, em.resourcePriorities[resType]+0.2)
+	}
 }
 
 // planProduction creates production orders for economic units and buildings
@@ -416,7 +427,7 @@ func (em *EconomicManager) countWorkers() int {
 	units := em.world.ObjectManager.GetUnitsForPlayer(em.playerID)
 	count := 0
 	for _, unit := range units {
-		if unit.UnitType == "worker" && unit.IsAlive() {
+		if em.strategicAI.GetFactionProfile().IsWorker(unit.UnitType) && unit.IsAlive() {
 			count++
 		}
 	}
@@ -500,7 +511,7 @@ func (em *EconomicManager) getAvailableWorkers() []*GameUnit {
 	var workers []*GameUnit
 
 	for _, unit := range units {
-		if unit.UnitType == "worker" && unit.IsAlive() && unit.State == UnitStateIdle {
+		if em.strategicAI.GetFactionProfile().IsWorker(unit.UnitType) && unit.IsAlive() && unit.State == UnitStateIdle {
 			workers = append(workers, unit)
 		}
 	}
@@ -513,10 +524,30 @@ func (em *EconomicManager) calculateOptimalResourceWorkers(resourceType string)
 	return int(priority * 4) // Max 4 workers per resource type
 }
 
+// assignWorkerToResource sends a worker to gather from the nearest known
+// node of a resource out of the enemy's reach, on a route around danger
 func (em *EconomicManager) assignWorkerToResource(worker *GameUnit, resourceType string) {
-	// Find nearest resource node of this type and assign worker
-	// This would interact with the behavior tree system
-	// Implementation would set worker's blackboard to target specific resource
+	influence := em.strategicAI.GetInfluence()
+	nodes := em.world.GetResources()
+	var target *ResourceNode
+	best := math.MaxFloat64
+	for _, sighting := range em.strategicAI.GetIntel().Resources() {
+		node := nodes[sighting.ID]
+		if node == nil || node.ResourceType != resourceType || node.Amount <= 0 || !influence.Safe(node.Position) {
+			continue
+		}
+		if distance := em.world.CalculateDistance(worker.Position, node.Position); distance < best {
+			target, best = node, distance
+		}
+	}
+	if target == nil {
+		return
+	}
+	route := influence.SafeRoute(worker.Position, target.Position)
+	for i, waypoint := range route[:len(route)-1] {
+		em.world.commandProcessor.IssueCommand(worker.ID, CreateMoveCommand(waypoint, i > 0))
+	}
+	em.world.commandProcessor.IssueCommand(worker.ID, CreateGatherCommand(target, len(route) > 1))
 }
 
 func (em *EconomicManager) findProducer(producerType, productType string) interface{} {
@@ -558,8 +589,13 @@ func (em *EconomicManager) calculateResourceValue(resource *ResourceNode) float6
 }
 
 func (em *EconomicManager) calculateResourceRisk(resource *ResourceNode) float64 {
-	// Calculate risk of accessing this resource (enemy proximity, etc.)
-	return 0.2 // Placeholder
+	// The share of the strength around the resource that is the enemy's
+	influence := em.strategicAI.GetInfluence()
+	danger := influence.Value(InfluenceDanger, resource.Position)
+	if danger <= 0 {
+		return 0
+	}
+	return danger / (danger + influence.Value(InfluenceFriendly, resource.Position))
 }
 
 func (em *EconomicManager) calculateDistanceFromBase(position Vector3) float64 {
@@ -568,6 +604,166 @@ func (em *EconomicManager) calculateDistanceFromBase(position Vector3) float64 {
 	return 15.0 // Placeholder
 }
 
+// AI market trading tuning, in days of estimated consumption
+const (
+	aiTradeShortDays   = 2.0  // A resource lasting less than this is a bottleneck
+	aiTradeSurplusDays = 10.0 // A resource lasting more than this can be traded away
+	aiTradeAmount      = 100  // Amount sold per trade
+)
+
+// tradeForBottleneck sells the most plentiful resource for the scarcest one
+// through the player's market when a resource is running out
+func (em *EconomicManager) tradeForBottleneck() {
+	if !em.world.HasMarket(em.playerID) {
+		return
+	}
+	resources := em.world.GetResourceStatus(em.playerID).Resources
+
+	// Sorted so the choice does not depend on map order
+	types := make([]string, 0, len(em.resourcePriorities))
+	for resType := range em.resourcePriorities {
+		types = append(types, resType)
+	}
+	sort.Strings(types)
+
+	short, surplus := "", ""
+	shortDays, surplusDays := aiTradeShortDays, aiTradeSurplusDays
+	for _, resType := range types {
+		days := float64(resources[resType]) / math.Max(em.estimateResourceConsumption(resType), 1.0)
+		if days < shortDays {
+			short, shortDays = resType, days
+		}
+		if days > surplusDays && resources[resType]-aiTradeAmount > 0 {
+			surplus, surplusDays = resType, days
+		}
+	}
+	if short == "" || surplus == "" {
+		return
+	}
+
+	bought, err := em.world.Trade(em.playerID, surplus, short, aiTradeAmount)
+	if err != nil {
+		logging.Debugf(logging.CategoryAI, "Player %d market trade failed: %v", em.playerID, err)
+		return
+	}
+	logging.Debugf(logging.CategoryAI, "Player %d traded %d %s for %d %s", em.playerID, aiTradeAmount, surplus, bought, short)
+}
+
+// staffBuildings assigns idle workers to buildings missing the workers their
+// power requirement asks for, and makes energy the top priority while a
+// staffed building is out of energy
+func (em *EconomicManager) staffBuildings() {
+	productionSys := em.world.GetProductionSystem()
+	if productionSys == nil {
+		return
+	}
+
+	// Sorted so the choice does not depend on map order
+	buildings := em.world.ObjectManager.GetBuildingsForPlayer(em.playerID)
+	ids := make([]int, 0, len(buildings))
+	for id := range buildings {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		building := buildings[id]
+		building.mutex.RLock()
+		needsStaff := building.IsBuilt && building.RequiredWorkers > 0 && building.RequiredWorkers > productionSys.staffCount(building)
+		outOfEnergy := building.IsBuilt && !building.Powered && building.PowerShortage == powerShortageEnergy
+		building.mutex.RUnlock()
+
+		if outOfEnergy {
+			em.resourcePriorities["energy"] = 1.0
+		}
+		if !needsStaff {
+			continue
+		}
+		workers := em.getAvailableWorkers()
+		if len(workers) == 0 {
+			return
+		}
+		if assigned, err := productionSys.StaffBuilding(id, workers); err == nil {
+			logging.Debugf(logging.CategoryAI, "Player %d assigned %d workers to %s", em.playerID, assigned, building.BuildingType)
+		}
+	}
+}
+
+// AI tribute tuning
+const (
+	aiTributeNeed       = 150              // An ally holding less than this of a resource is in need
+	aiTributeMax        = 200              // Most sent in one tribute, by the most generous AI
+	aiTributeMinimum    = 25               // Tributes smaller than this are not worth sending
+	aiTributeGenerosity = 0.3              // Generosity below this never sends tribute
+	aiTributeInterval   = 30 * time.Second // Least time between two tributes
+)
+
+// tributeGenerosity returns how willing the AI is to help allies, from 0 to 1:
+// economic and defensive personalities share more than aggressive ones
+func (em *EconomicManager) tributeGenerosity() float64 {
+	if em.strategicAI == nil {
+		return 0.5
+	}
+	personality := em.strategicAI.GetPersonality()
+	generosity := (personality.EconomicFocus + personality.DefensivePosture + 1 - personality.AggressionLevel) / 3
+	return math.Max(0, math.Min(1, generosity))
+}
+
+// considerTribute sends a surplus resource to the ally who needs it most,
+// keeping enough for the AI's own economy
+func (em *EconomicManager) considerTribute() {
+	generosity := em.tributeGenerosity()
+	if generosity < aiTributeGenerosity {
+		return
+	}
+	now := em.world.now()
+	if !em.lastTribute.IsZero() && now.Sub(em.lastTribute) < aiTributeInterval {
+		return
+	}
+	allies := em.world.GetAllies(em.playerID)
+	if len(allies) == 0 {
+		return
+	}
+	resources := em.world.GetResourceStatus(em.playerID).Resources
+
+	// Sorted so the choice does not depend on map order
+	types := make([]string, 0, len(em.resourcePriorities))
+	for resType := range em.resourcePriorities {
+		types = append(types, resType)
+	}
+	sort.Strings(types)
+
+	recipient, resource, amount := 0, "", 0
+	lowest := aiTributeNeed
+	for _, allyID := range allies {
+		allyResources := em.world.GetResourceStatus(allyID).Resources
+		for _, resType := range types {
+			if allyResources[resType] >= lowest {
+				continue
+			}
+			// Only give what stays above our own surplus level
+			reserve := int(aiTradeSurplusDays * math.Max(em.estimateResourceConsumption(resType), 1.0))
+			spare := resources[resType] - reserve
+			give := int(math.Min(float64(spare), aiTributeMax*generosity))
+			if give < aiTributeMinimum {
+				continue
+			}
+			recipient, resource, amount = allyID, resType, give
+			lowest = allyResources[resType]
+		}
+	}
+	if recipient == 0 {
+		return
+	}
+
+	if err := em.world.SendTribute(em.playerID, recipient, map[string]int{resource: amount}); err != nil {
+		logging.Debugf(logging.CategoryAI, "Player %d tribute failed: %v", em.playerID, err)
+		return
+	}
+	em.lastTribute = now
+	logging.Debugf(logging.CategoryAI, "Player %d sent %d %s to ally %d", em.playerID, amount, resource, recipient)
+}
+
 // MilitaryManager handles AI military decisions and army management
 type MilitaryManager struct {
 	playerID       int           // Player ID this manager controls
@@ -581,6 +777,8 @@ type MilitaryManager struct {
 	militaryTargets    []MilitaryTarget   // Military targets and threats
 	battleGroups      []BattleGroup      // Organized military units
 	defensivePositions []Vector3          // Key defensive positions
+	lastTactics        time.Time          // When squads in a fight were last given tactical orders
+	scoutID            int                // Unit out looking for the enemy, 0 for none
 }
 
 // RecruitmentOrder represents planned military unit production
@@ -779,11 +977,17 @@ func (mm *MilitaryManager) executeMilitaryOrders() {
 
 	// Execute battle group orders
 	mm.executeBattleGroupOrders()
+
+	// Look for the enemy while we know of none of their buildings
+	mm.scout()
 }
 
 // Helper methods for military management
 
 func (mm *MilitaryManager) isMilitaryUnit(unit *GameUnit) bool {
+	if mm.strategicAI.faction.IsWarrior(unit.UnitType) {
+		return true
+	}
 	militaryTypes := []string{"soldier", "warrior", "knight", "archer", "guard", "cavalry"}
 	for _, militaryType := range militaryTypes {
 		if unit.UnitType == militaryType {
@@ -799,6 +1003,18 @@ func (mm *MilitaryManager) calculateOptimalArmyComposition() map[string]int {
 
 	baseArmy := 8 // Base army size
 
+	// The faction's own composition for the phase, plus counters to the enemy units seen
+	if factionComposition := mm.strategicAI.faction.Composition(state.Phase, baseArmy); factionComposition != nil {
+		enemies := make(map[string]int)
+		for _, sighting := range mm.strategicAI.intel.Units() {
+			if sighting.Armed {
+				enemies[sighting.UnitType]++
+			}
+		}
+		mm.strategicAI.faction.AddCounters(factionComposition, enemies)
+		return factionComposition
+	}
+
 	// Scale with military strength and game phase
 	switch state.Phase {
 	case PhaseEarlyGame:
@@ -846,9 +1062,29 @@ func (mm *MilitaryManager) calculateRecruitmentPriority(unitType string, deficit
 	// Adjust for deficit severity
 	basePriority += math.Min(float64(deficit)/5.0, 0.2)
 
+	// Units that would pay upkeep are worth less the more of them there are
+	basePriority *= mm.upkeepWeight()
+
 	return math.Min(basePriority, 1.0)
 }
 
+// upkeepWeight scales recruitment in upkeep mode: 1.0 while another unit
+// would be free, less once each new unit adds to the army's running cost
+func (mm *MilitaryManager) upkeepWeight() float64 {
+	free, _, enabled := mm.world.upkeepSettings()
+	if !enabled {
+		return 1.0
+	}
+	switch size := mm.world.ArmySize(mm.playerID); {
+	case size >= 2*free:
+		return 0.3 // Another unit pays upkeep twice
+	case size >= free:
+		return 0.6
+	default:
+		return 1.0
+	}
+}
+
 func (mm *MilitaryManager) determineUnitPurpose(unitType string) string {
 	personality := mm.strategicAI.GetPersonality()
 
@@ -864,15 +1100,185 @@ func (mm *MilitaryManager) determineUnitPurpose(unitType string) string {
 // Placeholder implementations for complex military operations
 
 func (mm *MilitaryManager) identifyMilitaryTargets() {
-	// Identify enemy units, buildings, and strategic positions
+	// Identify the enemy units, buildings, and strategic positions we know of
 	mm.militaryTargets = mm.militaryTargets[:0]
-	// Implementation would scan for enemies and assess threats
+
+	intel := mm.strategicAI.GetIntel()
+	ownBuildings := mm.world.ObjectManager.GetBuildingsForPlayer(mm.playerID)
+	for _, building := range intel.Buildings() {
+		target := MilitaryTarget{
+			Type:        "enemy_base",
+			Location:    building.Position,
+			ThreatLevel: 0.1,
+			Opportunity: 0.5,
+			LastSeen:    mm.sightingTime(building.LastSeen),
+		}
+		if building.Defensive {
+			// Towers threaten whatever they reach, and cost an attack dearly
+			target.Type = "enemy_tower"
+			target.ThreatLevel = math.Max(mm.world.towerThreat(building.Building), 0.3)
+			target.Opportunity = 1.0 - target.ThreatLevel
+			if !mm.world.towerReaches(building.Building, ownBuildings) {
+				target.ThreatLevel /= 2
+			}
+		}
+		// Old sightings count for less, as the enemy may have moved on
+		confidence := intel.Confidence(building.LastSeen, intelBuildingMemory)
+		target.Priority = (0.6*target.ThreatLevel + 0.4*target.Opportunity) * (0.5 + 0.5*confidence)
+		mm.militaryTargets = append(mm.militaryTargets, target)
+	}
+	for _, army := range intel.Armies() {
+		threat := math.Min(float64(army.Units)/10.0, 1.0)
+		confidence := intel.Confidence(army.LastSeen, intelUnitMemory)
+		mm.militaryTargets = append(mm.militaryTargets, MilitaryTarget{
+			Type:        "enemy_army",
+			Location:    army.Position,
+			ThreatLevel: threat,
+			Opportunity: 1.0 - threat,
+			Priority:    (0.6*threat + 0.4*(1.0-threat)) * (0.5 + 0.5*confidence),
+			LastSeen:    mm.sightingTime(army.LastSeen),
+		})
+	}
+
+	// A wall between us and the enemy has to come down before anything else
+	if wall := mm.blockingWall(); wall != nil {
+		mm.militaryTargets = append(mm.militaryTargets, MilitaryTarget{
+			Type:        "enemy_wall",
+			Location:    wall.Position,
+			ThreatLevel: 0.2,
+			Opportunity: 1.0,
+			Priority:    0.9,
+			LastSeen:    time.Now(),
+		})
+	}
+
+	sort.Slice(mm.militaryTargets, func(i, j int) bool {
+		return mm.militaryTargets[i].Priority > mm.militaryTargets[j].Priority
+	})
+}
+
+// sightingTime converts the game time of a sighting to the wall clock time
+// military targets are kept in
+func (mm *MilitaryManager) sightingTime(lastSeen time.Duration) time.Time {
+	return mm.world.now().Add(lastSeen - mm.world.GetGameTime())
+}
+
+// scout sends an idle military unit to the part of the map that has gone
+// longest unseen, until an enemy building has been found
+func (mm *MilitaryManager) scout() {
+	intel := mm.strategicAI.GetIntel()
+	if len(intel.Buildings()) > 0 {
+		return
+	}
+	if scout := mm.world.ObjectManager.GetUnit(mm.scoutID); scout != nil && scout.IsAlive() && scout.CurrentCommand != nil {
+		return
+	}
+	mm.scoutID = 0
+	var scout *GameUnit
+	for _, unit := range mm.world.ObjectManager.GetUnitsForPlayer(mm.playerID) {
+		if unit.IsAlive() && mm.isMilitaryUnit(unit) && unit.GarrisonedIn == 0 && unit.CurrentCommand == nil &&
+			(scout == nil || unit.ID < scout.ID) {
+			scout = unit
+		}
+	}
+	if scout == nil {
+		return
+	}
+	target, ok := intel.ScoutTarget(scout.Position)
+	if !ok {
+		return
+	}
+	if err := mm.world.commandProcessor.IssueCommand(scout.ID, CreateMoveCommand(target, false)); err == nil {
+		mm.scoutID = scout.ID
+	}
+}
+
+// blockingWall returns the enemy wall or gate that blocks the way from our
+// army (or base) to the closest enemy building, or nil when the way is open
+func (mm *MilitaryManager) blockingWall() *GameBuilding {
+	from, ok := mm.attackOrigin()
+	if !ok {
+		return nil
+	}
+
+	var target *SightedBuilding
+	closest := math.MaxFloat64
+	known := mm.strategicAI.GetIntel().Buildings()
+	for i := range known {
+		building := &known[i]
+		if IsWallType(building.BuildingType) || IsGateType(building.BuildingType) {
+			continue
+		}
+		if distance := mm.world.CalculateDistance(mm.world.GridToWorld(from), building.Position); distance < closest {
+			target, closest = building, distance
+		}
+	}
+	if target == nil {
+		return nil
+	}
+	to, ok := mm.world.approachTile(target.Position, 0)
+	if !ok {
+		return nil
+	}
+
+	// Only when our units cannot get there, look for the way through enemy walls
+	pathfinder := NewPathfinder(mm.world)
+	request := PathRequest{Start: from, Target: to, UnitSize: 1, PlayerID: mm.playerID}
+	if result := pathfinder.FindPath(request); result.Success {
+		return nil
+	}
+	request.PassEnemyWalls = true
+	result := pathfinder.FindPath(request)
+	if !result.Success {
+		return nil
+	}
+	for _, cell := range result.GridPath {
+		if mm.world.isEnemyWall(cell.Grid, mm.playerID) {
+			return mm.world.ObjectManager.GetBuilding(mm.world.WallAt(cell.Grid))
+		}
+	}
+	return nil
+}
+
+// attackOrigin returns where an attack starts from: the first living
+// military unit, or a free tile next to one of our buildings
+func (mm *MilitaryManager) attackOrigin() (GridPosition, bool) {
+	for _, unit := range mm.world.ObjectManager.GetUnitsForPlayer(mm.playerID) {
+		if unit.IsAlive() && mm.isMilitaryUnit(unit) && unit.GarrisonedIn == 0 {
+			return unit.GetGridPosition(), true
+		}
+	}
+	for _, building := range mm.world.ObjectManager.GetBuildingsForPlayer(mm.playerID) {
+		if tile, ok := mm.world.approachTile(building.Position, mm.playerID); ok {
+			return tile, true
+		}
+	}
+	return GridPosition{}, false
 }
 
+// assessDefensivePositions takes the chokepoints leading out of our base's
+// region, nearest the base first, as the places to hold against attacks
 func (mm *MilitaryManager) assessDefensivePositions() {
-	// Evaluate key defensive positions around base
 	mm.defensivePositions = mm.defensivePositions[:0]
-	// Implementation would identify choke points and strategic locations
+	if mm.strategicAI == nil {
+		return
+	}
+	home, ok := mm.strategicAI.homePosition()
+	if !ok {
+		return
+	}
+	analysis := mm.world.MapAnalysis()
+	region := analysis.RegionAt(mm.world.WorldToGrid(home).Grid)
+	if region < 0 {
+		return
+	}
+	for _, id := range analysis.Regions[region].Chokepoints {
+		center := GridPosition{Grid: analysis.Chokepoints[id].Center, Offset: Vector2{X: 0.5, Y: 0.5}}
+		mm.defensivePositions = append(mm.defensivePositions, mm.world.GridToWorld(center))
+	}
+	sort.SliceStable(mm.defensivePositions, func(i, j int) bool {
+		return mm.world.CalculateDistance(home, mm.defensivePositions[i]) < mm.world.CalculateDistance(home, mm.defensivePositions[j])
+	})
 }
 
 func (mm *MilitaryManager) organizeBattleGroups() {
@@ -886,8 +1292,30 @@ func (mm *MilitaryManager) updateBattleGroups() {
 }
 
 func (mm *MilitaryManager) formNewBattleGroups() {
-	// Create new battle groups from available units
-	// Implementation would group unassigned military units
+	// Military units not in a squad join the player's first one, so squad
+	// tactics direct them
+	groupMgr := mm.world.groupMgr
+	if groupMgr == nil {
+		return
+	}
+	var recruits []*GameUnit
+	for _, unit := range mm.world.ObjectManager.GetUnitsForPlayer(mm.playerID) {
+		if unit.IsAlive() && mm.isMilitaryUnit(unit) && !groupMgr.IsUnitInGroup(unit.ID) {
+			recruits = append(recruits, unit)
+		}
+	}
+	if len(recruits) == 0 {
+		return
+	}
+	sort.Slice(recruits, func(i, j int) bool { return recruits[i].ID < recruits[j].ID })
+
+	groups := groupMgr.GetPlayerGroups(mm.playerID)
+	if len(groups) == 0 {
+		groupMgr.CreateGroup(mm.playerID, recruits, FormationLine)
+		return
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	groupMgr.AddUnitsToGroup(groups[0].ID, recruits)
 }
 
 func (mm *MilitaryManager) assignBattleGroupMissions() {
@@ -901,8 +1329,22 @@ func (mm *MilitaryManager) executeRecruitmentOrders() {
 }
 
 func (mm *MilitaryManager) executeBattleGroupOrders() {
-	// Execute battle group missions
-	// Implementation would issue movement and attack commands to groups
+	// Squads in a fight get tactical orders a few times a second
+	if mm.world.groupMgr == nil || mm.world.now().Sub(mm.lastTactics) < tacticsInterval {
+		return
+	}
+	mm.lastTactics = mm.world.now()
+	for _, group := range mm.world.groupMgr.GetPlayerGroups(mm.playerID) {
+		mm.world.ExecuteTactics(mm.world.PlanSquadTactics(group, mm.influence()))
+	}
+}
+
+// influence returns the strategic AI's influence map, nil without one
+func (mm *MilitaryManager) influence() *InfluenceMap {
+	if mm.strategicAI == nil {
+		return nil
+	}
+	return mm.strategicAI.GetInfluence()
 }
 
 func (mm *MilitaryManager) prioritizeArmyExpansion() {
@@ -919,6 +1361,44 @@ func (mm *MilitaryManager) prioritizeOffensiveUnits() {
 
 func (mm *MilitaryManager) planOffensiveOperation() {
 	// Plan and execute offensive military operation
+	// Walls in the way are broken down first, and the rest of the army
+	// gathers on the safe ground we hold nearest the enemy
+	mm.planSiegeOperation()
+	mm.stageArmy()
+}
+
+// stageArmy sends idle military units to the staging point toward the
+// remembered enemy building nearest our base
+func (mm *MilitaryManager) stageArmy() {
+	influence := mm.influence()
+	if influence == nil {
+		return
+	}
+	home, ok := mm.strategicAI.homePosition()
+	if !ok {
+		return
+	}
+	var target *SightedBuilding
+	for _, sighting := range mm.strategicAI.GetIntel().Buildings() {
+		if target == nil || mm.world.CalculateDistance(home, sighting.Position) < mm.world.CalculateDistance(home, target.Position) {
+			sighting := sighting
+			target = &sighting
+		}
+	}
+	if target == nil {
+		return
+	}
+	staging, ok := influence.StagingPoint(target.Position)
+	if !ok {
+		return
+	}
+	tileSize := float64(mm.world.GetTileSize())
+	for _, unit := range mm.world.ObjectManager.GetUnitsForPlayer(mm.playerID) {
+		if unit.IsAlive() && mm.isMilitaryUnit(unit) && unit.GarrisonedIn == 0 && unit.CurrentCommand == nil &&
+			mm.world.CalculateDistance(unit.GetPosition(), staging) > 2*tileSize {
+			mm.world.commandProcessor.IssueCommand(unit.ID, CreateMoveCommand(staging, false))
+		}
+	}
 }
 
 func (mm *MilitaryManager) planRaidOperation() {
@@ -927,10 +1407,44 @@ func (mm *MilitaryManager) planRaidOperation() {
 
 func (mm *MilitaryManager) planSiegeOperation() {
 	// Plan sustained siege of enemy fortification
+	wall := mm.blockingWall()
+	if wall == nil {
+		return
+	}
+	for _, unit := range mm.world.ObjectManager.GetUnitsForPlayer(mm.playerID) {
+		if unit.IsAlive() && mm.isMilitaryUnit(unit) && unit.GarrisonedIn == 0 && unit.CurrentCommand == nil {
+			mm.world.commandProcessor.IssueCommand(unit.ID, CreateAttackBuildingCommand(wall, false))
+		}
+	}
 }
 
+// strengthenDefenses sends idle military units to hold the defensive
+// positions, spread evenly over them, the most threatened first
 func (mm *MilitaryManager) strengthenDefenses() {
-	// Reinforce defensive positions
+	if len(mm.defensivePositions) == 0 {
+		return
+	}
+	positions := append([]Vector3(nil), mm.defensivePositions...)
+	if influence := mm.influence(); influence != nil {
+		sort.SliceStable(positions, func(i, j int) bool {
+			return influence.Value(InfluenceEnemy, positions[i]) > influence.Value(InfluenceEnemy, positions[j])
+		})
+	}
+	var idle []*GameUnit
+	for _, unit := range mm.world.ObjectManager.GetUnitsForPlayer(mm.playerID) {
+		if unit.IsAlive() && mm.isMilitaryUnit(unit) && unit.GarrisonedIn == 0 && unit.CurrentCommand == nil {
+			idle = append(idle, unit)
+		}
+	}
+	sort.Slice(idle, func(i, j int) bool { return idle[i].ID < idle[j].ID })
+
+	tileSize := float64(mm.world.GetTileSize())
+	for i, unit := range idle {
+		position := positions[i%len(positions)]
+		if mm.world.CalculateDistance(unit.GetPosition(), position) > 2*tileSize {
+			mm.world.commandProcessor.IssueCommand(unit.ID, CreateMoveCommand(position, false))
+		}
+	}
 }
 
 func (mm *MilitaryManager) fortifyPositions() {
@@ -0,0 +1,97 @@
+package engine
+
+import "math"
+
+// AmbientEnvironment classifies terrain for ambient sound
+type AmbientEnvironment int
+
+const (
+	AmbientOpen     AmbientEnvironment = iota // Grass, roads and bare ground
+	AmbientForest                             // Tiles with trees and other terrain objects
+	AmbientWater                              // Tiles below the water level
+	AmbientMountain                           // Stone surfaces and high ground
+)
+
+// AmbientEnvironments lists every environment, in classification priority
+var AmbientEnvironments = []AmbientEnvironment{AmbientWater, AmbientForest, AmbientMountain, AmbientOpen}
+
+// String returns the string representation of the environment
+func (e AmbientEnvironment) String() string {
+	switch e {
+	case AmbientOpen:
+		return "open"
+	case AmbientForest:
+		return "forest"
+	case AmbientWater:
+		return "water"
+	case AmbientMountain:
+		return "mountain"
+	default:
+		return "unknown"
+	}
+}
+
+// mountainHeight is how far above the water level ground counts as mountain
+const mountainHeight = 6.0
+
+// AmbientMix is the share of each environment around a point; the shares add up to 1
+type AmbientMix map[AmbientEnvironment]float32
+
+// AmbientEnvironmentAt classifies a single tile
+func (m *Map) AmbientEnvironmentAt(x, y int) AmbientEnvironment {
+	height := m.GetHeightAt(x, y)
+	switch {
+	case height < m.WaterLevel:
+		return AmbientWater
+	case m.GetObjectAt(x, y) != 0:
+		return AmbientForest
+	case m.GetSurfaceAt(x, y) == SurfaceStone || height >= m.WaterLevel+mountainHeight:
+		return AmbientMountain
+	default:
+		return AmbientOpen
+	}
+}
+
+// AmbientMixAt samples the tiles within radius tiles of a position; nearer
+// tiles weigh more so the mix changes smoothly as the position moves
+func (m *Map) AmbientMixAt(x, z float64, radius int) AmbientMix {
+	mix := AmbientMix{}
+	centerX, centerY := int(math.Round(x)), int(math.Round(z))
+	total := float32(0)
+	for y := centerY - radius; y <= centerY+radius; y++ {
+		for x := centerX - radius; x <= centerX+radius; x++ {
+			distance := math.Hypot(float64(x-centerX), float64(y-centerY))
+			if distance > float64(radius) || !m.IsValidPosition(x, y) {
+				continue
+			}
+			weight := float32(1 - distance/float64(radius+1))
+			mix[m.AmbientEnvironmentAt(x, y)] += weight
+			total += weight
+		}
+	}
+
+	if total == 0 {
+		return AmbientMix{AmbientOpen: 1}
+	}
+	for environment := range mix {
+		mix[environment] /= total
+	}
+	return mix
+}
+
+// TimeOfDay returns the time of day at a game time, 0 being midnight and
+// 0.5 noon. Matches start at dawn; the day and night last as long as the
+// map's tileset says, in game seconds.
+func (m *Map) TimeOfDay(gameSeconds float64) float32 {
+	day, night := 1000.0, 1000.0
+	if m != nil && m.Tileset != nil && m.Tileset.Parameters.Lighting.DayTime > 0 && m.Tileset.Parameters.Lighting.NightTime > 0 {
+		day = float64(m.Tileset.Parameters.Lighting.DayTime)
+		night = float64(m.Tileset.Parameters.Lighting.NightTime)
+	}
+
+	phase := math.Mod(gameSeconds, day+night)
+	if phase < day {
+		return float32(0.25 + 0.5*phase/day)
+	}
+	return float32(math.Mod(0.75+0.5*(phase-day)/night, 1))
+}
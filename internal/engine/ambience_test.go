@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"encoding/xml"
+	"math"
+	"testing"
+)
+
+// TestAmbientSoundParsing tests the play-always flag and random call lists
+func TestAmbientSoundParsing(t *testing.T) {
+	source := `<ambient-sounds>
+		<day-sound enabled="true" path="sounds/day.ogg" play-always="true" volume="0.5"/>
+		<night-sound enabled="true" path="sounds/night.ogg"/>
+		<rain-sound enabled="false"/>
+		<snow-sound enabled="false"/>
+		<day-start-sound enabled="true"><sound path="sounds/bird1.wav"/><sound path="sounds/bird2.wav"/></day-start-sound>
+		<night-start-sound enabled="true"><sound path="sounds/owl.wav"/></night-start-sound>
+	</ambient-sounds>`
+
+	var parsed AmbientSoundsXML
+	if err := xml.Unmarshal([]byte(source), &parsed); err != nil {
+		t.Fatalf("Failed to unmarshal ambient sounds: %v", err)
+	}
+	sounds, err := NewTilesetLoader("").convertAmbientSounds(parsed)
+	if err != nil {
+		t.Fatalf("Failed to convert ambient sounds: %v", err)
+	}
+
+	if !sounds.DaySound.PlayAlways || sounds.DaySound.Volume != 0.5 || sounds.NightSound.PlayAlways {
+		t.Errorf("Unexpected loops: day %+v, night %+v", sounds.DaySound, sounds.NightSound)
+	}
+	if len(sounds.DayStartSound.Sounds) != 2 || sounds.DayStartSound.Sounds[1] != "sounds/bird2.wav" {
+		t.Errorf("Expected two day calls, got %v", sounds.DayStartSound.Sounds)
+	}
+	if len(sounds.NightStartSound.Sounds) != 1 {
+		t.Errorf("Expected one night call, got %v", sounds.NightStartSound.Sounds)
+	}
+}
+
+// TestAmbientMix tests the terrain classification around a point
+func TestAmbientMix(t *testing.T) {
+	const size = 20
+	m := &Map{Width: size, Height: size, WaterLevel: 2}
+	m.HeightMap = make([][]float32, size)
+	m.SurfaceMap = make([][]int8, size)
+	m.ObjectMap = make([][]int8, size)
+	for y := 0; y < size; y++ {
+		m.HeightMap[y] = make([]float32, size)
+		m.SurfaceMap[y] = make([]int8, size)
+		m.ObjectMap[y] = make([]int8, size)
+		for x := 0; x < size; x++ {
+			m.HeightMap[y][x] = 3
+			m.SurfaceMap[y][x] = int8(SurfaceGrass)
+			switch {
+			case x < 5:
+				m.HeightMap[y][x] = 0 // Lake on the west
+			case x >= 15:
+				m.ObjectMap[y][x] = 1 // Forest on the east
+			case y >= 15:
+				m.HeightMap[y][x] = 10 // Hills on the south
+			}
+		}
+	}
+
+	expected := map[AmbientEnvironment][2]int{
+		AmbientWater:    {1, 10},
+		AmbientForest:   {18, 10},
+		AmbientMountain: {10, 18},
+		AmbientOpen:     {10, 8},
+	}
+	for environment, tile := range expected {
+		if got := m.AmbientEnvironmentAt(tile[0], tile[1]); got != environment {
+			t.Errorf("Expected %s at %v, got %s", environment, tile, got)
+		}
+	}
+
+	mix := m.AmbientMixAt(2, 10, 2)
+	if mix[AmbientWater] != 1 {
+		t.Errorf("Expected only water in the lake, got %v", mix)
+	}
+	edge := m.AmbientMixAt(15, 10, 4)
+	if edge[AmbientForest] <= edge[AmbientOpen] || edge[AmbientOpen] == 0 {
+		t.Errorf("Expected mostly forest with some open ground at the forest edge, got %v", edge)
+	}
+	total := float32(0)
+	for _, share := range edge {
+		total += share
+	}
+	if math.Abs(float64(total-1)) > 1e-5 {
+		t.Errorf("Expected shares adding up to 1, got %.4f", total)
+	}
+}
+
+// TestTimeOfDay tests the day and night cycle
+func TestTimeOfDay(t *testing.T) {
+	m := &Map{Tileset: &Tileset{}}
+	m.Tileset.Parameters.Lighting.DayTime = 100
+	m.Tileset.Parameters.Lighting.NightTime = 50
+
+	cases := []struct {
+		seconds  float64
+		expected float32
+	}{
+		{0, 0.25},   // Dawn
+		{50, 0.5},   // Noon
+		{100, 0.75}, // Dusk
+		{125, 0},    // Midnight
+		{150, 0.25}, // Next dawn
+	}
+	for _, c := range cases {
+		if got := m.TimeOfDay(c.seconds); math.Abs(float64(got-c.expected)) > 1e-5 {
+			t.Errorf("Expected time of day %.2f at %.0fs, got %.2f", c.expected, c.seconds, got)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// Animation marker names; skills may also name their own markers in XML
+const (
+	MarkerFootstep = "footstep" // A foot touches the ground
+	MarkerLaunch   = "launch"   // A projectile leaves the attacker
+	MarkerImpact   = "impact"   // A blow lands on the target
+)
+
+// AnimationMarker is a named point on a skill's animation timeline
+type AnimationMarker struct {
+	Name     string
+	Fraction float64 // Share of the animation cycle at which the marker fires, 0-1
+}
+
+// AnimationEvent is a unit's animation passing a marker
+type AnimationEvent struct {
+	Name     string
+	Unit     *GameUnit
+	Skill    *data.Skill
+	Position Vector3 // Where the unit stands
+	Target   Vector3 // What the unit attacks, for launch and impact markers
+}
+
+// AnimationEventSettings configures the markers of skills whose XML defines
+// none: footsteps on move skills, and a launch or impact marker at the attack
+// skill's attack-start-time
+type AnimationEventSettings struct {
+	Footsteps []float64 // Fractions of a move cycle at which a foot touches the ground
+}
+
+// DefaultAnimationEvents is used when GameSettings does not set AnimationEvents
+var DefaultAnimationEvents = AnimationEventSettings{
+	Footsteps: []float64{0.25, 0.75},
+}
+
+// animationTracker holds the functions called when animation markers are passed
+type animationTracker struct {
+	mutex    sync.Mutex
+	handlers map[string][]func(AnimationEvent)
+}
+
+// OnAnimationEvent adds a function called whenever a unit's animation passes
+// the named marker, such as MarkerFootstep for footstep sounds. Handlers run
+// during the world update, so they must only take note of the event.
+func (w *World) OnAnimationEvent(name string, handle func(AnimationEvent)) {
+	tracker := &w.animations
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if tracker.handlers == nil {
+		tracker.handlers = make(map[string][]func(AnimationEvent))
+	}
+	tracker.handlers[name] = append(tracker.handlers[name], handle)
+}
+
+// fireAnimationEvent calls the handlers of an event's marker
+func (w *World) fireAnimationEvent(event AnimationEvent) {
+	w.animations.mutex.Lock()
+	handlers := w.animations.handlers[event.Name]
+	w.animations.mutex.Unlock()
+	for _, handle := range handlers {
+		handle(event)
+	}
+}
+
+// animationEventSettings returns the configured automatic markers
+func (w *World) animationEventSettings() AnimationEventSettings {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	if w.settings.AnimationEvents == nil {
+		return DefaultAnimationEvents
+	}
+	return *w.settings.AnimationEvents
+}
+
+// SkillMarkers returns the markers on a skill's animation timeline in the
+// order they fire: those its XML defines, or else the automatic ones
+func SkillMarkers(skill *data.Skill, settings AnimationEventSettings) []AnimationMarker {
+	if skill == nil {
+		return nil
+	}
+	var markers []AnimationMarker
+	if len(skill.Events) > 0 {
+		for _, event := range skill.Events {
+			markers = append(markers, AnimationMarker{Name: event.Name, Fraction: clampFraction(event.Time)})
+		}
+		sort.SliceStable(markers, func(i, j int) bool { return markers[i].Fraction < markers[j].Fraction })
+		return markers
+	}
+
+	switch skill.Type.Value {
+	case "move":
+		for _, fraction := range settings.Footsteps {
+			markers = append(markers, AnimationMarker{Name: MarkerFootstep, Fraction: clampFraction(fraction)})
+		}
+		sort.SliceStable(markers, func(i, j int) bool { return markers[i].Fraction < markers[j].Fraction })
+	case "attack":
+		markers = append(markers, AnimationMarker{Name: hitMarker(skill), Fraction: attackHitFraction(skill)})
+	}
+	return markers
+}
+
+// hitMarker returns the marker an attack skill's hit fires: the launch of its
+// projectile, or the impact of its blow
+func hitMarker(skill *data.Skill) string {
+	if skill != nil && skill.Projectile != nil && skill.Projectile.Value {
+		return MarkerLaunch
+	}
+	return MarkerImpact
+}
+
+// isHitMarker reports whether a marker is fired by an attack's hit rather
+// than by the animation timeline
+func isHitMarker(name string) bool {
+	return name == MarkerLaunch || name == MarkerImpact
+}
+
+// attackHitFraction returns the share of an attack cycle after which the
+// attack hits: the skill's launch or impact marker if its XML has one, or
+// else its attack-start-time
+func attackHitFraction(skill *data.Skill) float64 {
+	if skill == nil {
+		return 0
+	}
+	for _, event := range skill.Events {
+		if isHitMarker(event.Name) {
+			return clampFraction(event.Time)
+		}
+	}
+	if skill.AttackStartTime == nil {
+		return 0
+	}
+	return clampFraction(skill.AttackStartTime.Value)
+}
+
+// clampFraction limits a timeline fraction to one cycle
+func clampFraction(fraction float64) float64 {
+	return math.Max(0, math.Min(1, fraction))
+}
+
+// fireHitMarker fires the launch or impact marker of a unit's attack as its
+// hit lands, so projectiles leave and blows land on the animation frame the
+// skill marks
+func (w *World) fireHitMarker(unit *GameUnit, target Vector3) {
+	var skill *data.Skill
+	if unit.UnitDef != nil {
+		skill = unit.UnitDef.AttackSkill()
+	}
+	w.fireAnimationEvent(AnimationEvent{
+		Name:     hitMarker(skill),
+		Unit:     unit,
+		Skill:    skill,
+		Position: unit.Position,
+		Target:   target,
+	})
+}
+
+// updateAnimations moves the units' animations on by a tick and fires the
+// markers they pass. Launch and impact markers are left to the attacks, which
+// fire them when they hit.
+func (w *World) updateAnimations(deltaTime time.Duration) {
+	w.animations.mutex.Lock()
+	listening := len(w.animations.handlers) > 0
+	w.animations.mutex.Unlock()
+
+	settings := w.animationEventSettings()
+	var events []AnimationEvent
+	for _, player := range w.GetAllPlayers() {
+		units := w.ObjectManager.GetUnitsForPlayer(player.ID)
+		for _, id := range sortedKeys(units) {
+			unit := units[id]
+			last := unit.GetAnimation()
+			skill := w.advanceAnimation(unit, deltaTime)
+			if skill == nil || !listening {
+				continue
+			}
+
+			// A new clip, or a swing starting over, fires the markers at its
+			// very start too; a repeating clip that came round passes its end
+			current := unit.GetAnimation()
+			from, to, restarted := last.Time, current.Time, false
+			switch {
+			case last.Clip != current.Clip:
+				from, restarted = 0, true
+			case to < from && current.Loop == AnimationLoopRepeat:
+				to++
+			case to < from:
+				from, restarted = 0, true
+			}
+			for _, marker := range SkillMarkers(skill, settings) {
+				if skill.Type.Value == "attack" && isHitMarker(marker.Name) {
+					continue
+				}
+				if markerPassed(marker.Fraction, from, to, restarted) {
+					events = append(events, AnimationEvent{
+						Name:     marker.Name,
+						Unit:     unit,
+						Skill:    skill,
+						Position: unit.GetPosition(),
+					})
+				}
+			}
+		}
+	}
+
+	for _, event := range events {
+		w.fireAnimationEvent(event)
+	}
+}
+
+// markerPassed reports whether an animation moving from one share of its clip
+// to another passed a marker; a move past the end comes round to the start
+func markerPassed(marker, from, to float64, restarted bool) bool {
+	for at := marker; at <= to; at++ {
+		if at > from || restarted && at == from {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestAnimationEvents tests that walking units fire footsteps at the
+// configured fractions of their move cycle, and that attack markers from the
+// XML fire in order with the damage applied at the impact marker
+func TestAnimationEvents(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+
+	// One step cycle and one attack a second; the blow is wound up before it lands
+	def := data.NewSimpleUnit("swordsman", 100, 0, "leather", nil)
+	def.Unit.Skills = []data.Skill{
+		{
+			Type:      data.SkillType{Value: "move"},
+			AnimSpeed: data.SkillAnimSpeed{Value: 100},
+		},
+		{
+			Type:            data.SkillType{Value: "attack"},
+			Speed:           data.SkillSpeed{Value: 100},
+			AttackStrength:  &data.SkillAttackStrength{Value: 10},
+			AttackType:      &data.SkillAttackType{Value: "sword"},
+			AttackStartTime: &data.SkillAttackStartTime{Value: 0.8},
+			Events: []data.SkillEvent{
+				{Name: MarkerImpact, Time: 0.4},
+				{Name: "swing", Time: 0.1},
+			},
+		},
+	}
+	walker, _ := world.ObjectManager.CreateUnit(1, "swordsman", Vector3{X: 2.5, Z: 2.5}, def)
+	attacker, _ := world.ObjectManager.CreateUnit(1, "swordsman", Vector3{X: 10.5, Z: 10.5}, def)
+	target, _ := world.ObjectManager.CreateUnit(2, "swordsman", Vector3{X: 11.5, Z: 10.5}, def)
+	if attacker.AttackWindUp != 400*time.Millisecond {
+		t.Fatalf("Expected the impact marker to set the wind-up, got %v", attacker.AttackWindUp)
+	}
+	attacker.AttackDamage, attacker.AttackRange = 10, 1.5
+
+	var fired []string
+	footsteps := 0
+	healthAtImpact := -1
+	world.OnAnimationEvent(MarkerFootstep, func(event AnimationEvent) {
+		if event.Unit == walker {
+			footsteps++
+		}
+	})
+	world.OnAnimationEvent("swing", func(event AnimationEvent) { fired = append(fired, event.Name) })
+	world.OnAnimationEvent(MarkerImpact, func(event AnimationEvent) {
+		fired = append(fired, event.Name)
+		healthAtImpact = target.Health
+		if event.Target != target.Position {
+			t.Errorf("Expected the impact at the target, got %v", event.Target)
+		}
+	})
+
+	step := func(duration time.Duration) {
+		for elapsed := time.Duration(0); elapsed < duration; elapsed += 100 * time.Millisecond {
+			clock.Advance(100 * time.Millisecond)
+			world.commandProcessor.Update(100 * time.Millisecond)
+			world.updateAnimations(100 * time.Millisecond)
+		}
+	}
+
+	walker.Speed = 0.5
+	world.commandProcessor.IssueCommand(walker.ID, CreateMoveCommand(Vector3{X: 20.5, Z: 2.5}, false))
+	world.commandProcessor.IssueCommand(attacker.ID, CreateAttackCommand(target, false))
+	step(300 * time.Millisecond)
+	if len(fired) != 1 || fired[0] != "swing" || target.Health != 100 {
+		t.Fatalf("Expected only the swing before the impact, got %v with health %d", fired, target.Health)
+	}
+	step(200 * time.Millisecond)
+	if len(fired) != 2 || fired[1] != MarkerImpact {
+		t.Fatalf("Expected the impact marker once the wind-up completes, got %v", fired)
+	}
+	if healthAtImpact != 100 || target.Health >= 100 {
+		t.Errorf("Expected the damage right at the impact, health %d at impact and %d after", healthAtImpact, target.Health)
+	}
+
+	step(500 * time.Millisecond)
+	if footsteps != 2 {
+		t.Errorf("Expected two footsteps in one move cycle, got %d", footsteps)
+	}
+}
+
+// TestSkillMarkers tests the automatic markers of skills whose XML defines none
+func TestSkillMarkers(t *testing.T) {
+	settings := AnimationEventSettings{Footsteps: []float64{0.6, 0.1}}
+	move := &data.Skill{Type: data.SkillType{Value: "move"}}
+	markers := SkillMarkers(move, settings)
+	if len(markers) != 2 || markers[0] != (AnimationMarker{MarkerFootstep, 0.1}) || markers[1].Fraction != 0.6 {
+		t.Errorf("Expected footsteps in timeline order, got %v", markers)
+	}
+
+	bow := &data.Skill{
+		Type:            data.SkillType{Value: "attack"},
+		AttackStartTime: &data.SkillAttackStartTime{Value: 0.7},
+		Projectile:      &data.Projectile{Value: true},
+	}
+	if markers := SkillMarkers(bow, settings); len(markers) != 1 || markers[0] != (AnimationMarker{MarkerLaunch, 0.7}) {
+		t.Errorf("Expected a launch marker at the attack start time, got %v", markers)
+	}
+	bow.Projectile = nil
+	if markers := SkillMarkers(bow, settings); len(markers) != 1 || markers[0].Name != MarkerImpact {
+		t.Errorf("Expected melee attacks to mark their impact, got %v", markers)
+	}
+}
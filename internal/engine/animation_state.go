@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"math"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// AnimationLoop is how a clip plays once it reaches its end
+type AnimationLoop int
+
+const (
+	AnimationLoopRepeat AnimationLoop = iota // Starts over, like walking or standing
+	AnimationLoopOnce                        // Holds its last frame, like a swing waiting for the next or dying
+)
+
+// AnimationState is the clip a unit plays and how far into it the unit is.
+// The simulation advances it by game time only, so it is the same in every
+// run of a match; the renderer samples it and never changes it.
+type AnimationState struct {
+	Clip string        `json:"clip"` // Name of the skill whose animation plays, empty for none
+	Time float64       `json:"time"` // Share of the clip played, 0-1
+	Loop AnimationLoop `json:"loop"`
+}
+
+// Frame returns the frame of a clip with frameCount frames to show
+func (a AnimationState) Frame(frameCount int) int {
+	if frameCount <= 1 {
+		return 0
+	}
+	frame := int(a.Time * float64(frameCount))
+	if frame >= frameCount {
+		return frameCount - 1
+	}
+	return frame
+}
+
+// stateSkillTypes is the skill type whose animation each unit state plays
+var stateSkillTypes = map[UnitState]string{
+	UnitStateIdle:      "stop",
+	UnitStateMoving:    "move",
+	UnitStateAttacking: "attack",
+	UnitStateGathering: "harvest",
+	UnitStateBuilding:  "build",
+	UnitStateDead:      "die",
+}
+
+// GetAnimation returns the unit's animation state
+func (u *GameUnit) GetAnimation() AnimationState {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	return u.Animation
+}
+
+// animationSkill returns the skill whose animation a unit plays in its
+// current state; the caller holds the unit lock
+func (u *GameUnit) animationSkill() *data.Skill {
+	if u.UnitDef == nil {
+		return nil
+	}
+	if u.State == UnitStateAttacking {
+		return u.UnitDef.AttackSkill()
+	}
+	return u.UnitDef.SkillOfType(stateSkillTypes[u.State])
+}
+
+// clipName names the clip of a skill: its name, or its type if unnamed
+func clipName(skill *data.Skill) string {
+	if skill.Name.Value != "" {
+		return skill.Name.Value
+	}
+	return skill.Type.Value
+}
+
+// advanceAnimation moves a unit's animation on by a tick of game time and
+// returns the skill it plays. A new clip starts from its beginning; attack
+// clips follow the unit's swing and cooldown, so hits land on the frame the
+// skill marks. The caller must not hold the unit lock.
+func (w *World) advanceAnimation(unit *GameUnit, deltaTime time.Duration) *data.Skill {
+	unit.mutex.Lock()
+	defer unit.mutex.Unlock()
+
+	skill := unit.animationSkill()
+	if skill == nil || unit.GarrisonedIn != 0 {
+		unit.Animation = AnimationState{}
+		return nil
+	}
+	state := AnimationState{Clip: clipName(skill), Loop: AnimationLoopRepeat}
+	if unit.Animation.Clip == state.Clip {
+		state.Time = unit.Animation.Time
+	}
+
+	switch unit.State {
+	case UnitStateAttacking:
+		state.Loop = AnimationLoopOnce
+		state.Time = w.swingTime(unit)
+	case UnitStateDead:
+		state.Loop = AnimationLoopOnce
+		state.Time = math.Min(state.Time+deltaTime.Seconds()*skill.AnimationsPerSecond(), 1)
+	default:
+		state.Time += deltaTime.Seconds() * skill.AnimationsPerSecond()
+		state.Time -= math.Floor(state.Time)
+	}
+	unit.Animation = state
+	return skill
+}
+
+// swingTime returns how far through its attack cycle a unit is: in the swing
+// up to the hit, then in the cooldown after it, holding at the end until the
+// next swing starts. The caller holds the unit lock.
+func (w *World) swingTime(unit *GameUnit) float64 {
+	if unit.AttackSpeed <= 0 {
+		return 0
+	}
+	cycle := time.Duration(float64(time.Second) / float64(unit.AttackSpeed))
+	var elapsed time.Duration
+	switch {
+	case !unit.WindUpStart.IsZero():
+		elapsed = w.now().Sub(unit.WindUpStart)
+	case !unit.LastAttack.IsZero():
+		elapsed = w.now().Sub(unit.LastAttack) + unit.AttackWindUp
+	}
+	return clampFraction(float64(elapsed) / float64(cycle))
+}
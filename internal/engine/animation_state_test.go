@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestAnimationState tests that units play the clip of their state's skill,
+// advanced by game time only: walk clips repeat, attack clips follow the
+// swing and hold at their end until the next
+func TestAnimationState(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+
+	def := data.NewSimpleUnit("swordsman", 100, 0, "leather", nil)
+	def.Unit.Skills = []data.Skill{
+		{Type: data.SkillType{Value: "stop"}, Name: data.SkillName{Value: "stand"}, AnimSpeed: data.SkillAnimSpeed{Value: 50}},
+		{Type: data.SkillType{Value: "move"}, Name: data.SkillName{Value: "walk"}, AnimSpeed: data.SkillAnimSpeed{Value: 80}},
+		{
+			Type:            data.SkillType{Value: "attack"},
+			Speed:           data.SkillSpeed{Value: 50},
+			AttackStrength:  &data.SkillAttackStrength{Value: 10},
+			AttackStartTime: &data.SkillAttackStartTime{Value: 0.5},
+		},
+	}
+	unit, _ := world.ObjectManager.CreateUnit(1, "swordsman", Vector3{X: 10.5, Z: 10.5}, def)
+	step := func(ticks int) {
+		for i := 0; i < ticks; i++ {
+			clock.Advance(100 * time.Millisecond)
+			world.updateAnimations(100 * time.Millisecond)
+		}
+	}
+
+	step(3)
+	if animation := unit.GetAnimation(); animation.Clip != "stand" || !closeTo(animation.Time, 0.15) {
+		t.Errorf("Expected the stand clip 15%% in, got %+v", animation)
+	}
+
+	// A new clip starts over, and repeating clips come round
+	unit.State = UnitStateMoving
+	step(15)
+	animation := unit.GetAnimation()
+	if animation.Clip != "walk" || animation.Loop != AnimationLoopRepeat || !closeTo(animation.Time, 0.2) {
+		t.Errorf("Expected the walk clip come round to 20%%, got %+v", animation)
+	}
+	if frame := animation.Frame(10); frame != 2 {
+		t.Errorf("Expected frame 2 of 10, got %d", frame)
+	}
+
+	// Attack clips follow the swing: the hit lands half way, a second in
+	unit.State = UnitStateAttacking
+	unit.WindUpStart = clock.Now()
+	step(5)
+	if animation := unit.GetAnimation(); animation.Clip != "attack" || animation.Loop != AnimationLoopOnce || !closeTo(animation.Time, 0.25) {
+		t.Errorf("Expected the attack clip a quarter through the swing, got %+v", animation)
+	}
+	unit.WindUpStart, unit.LastAttack = time.Time{}, clock.Now()
+	step(15)
+	if animation := unit.GetAnimation(); animation.Time != 1 || animation.Frame(10) != 9 {
+		t.Errorf("Expected the attack clip to hold its last frame, got %+v", animation)
+	}
+}
+
+// closeTo reports whether two clip times match up to rounding
+func closeTo(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
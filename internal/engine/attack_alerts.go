@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AttackAlertInterval is how long damage to the same unit or building stays
+// quiet after raising an under-attack event, so a fight is one alert rather
+// than one per hit
+const AttackAlertInterval = 5 * time.Second
+
+// AttackAlert is the data of an EventTypeUnderAttack event
+type AttackAlert struct {
+	TargetID   int     // ID of the damaged unit or building
+	TargetType string  // Unit or building type name
+	IsBuilding bool    // Whether the target is a building
+	Position   Vector3 // Where the target was hit
+	Damage     int     // Damage of the hit that raised the alert
+}
+
+// attackKey identifies an alert target; units and buildings are numbered separately
+type attackKey struct {
+	id       int
+	building bool
+}
+
+// worldEvents forwards world events to the game's event queue
+type worldEvents struct {
+	mutex      sync.Mutex
+	send       func(GameEvent)         // Receives the events, nil when nothing listens
+	listeners  []func(GameEvent)       // World systems receiving the events as they are raised
+	lastAttack map[attackKey]time.Time // When each target last raised an attack alert
+}
+
+// SetEventSink sets the function receiving events raised by world systems
+func (w *World) SetEventSink(send func(GameEvent)) {
+	w.events.mutex.Lock()
+	defer w.events.mutex.Unlock()
+	w.events.send = send
+}
+
+// AddEventListener adds a function receiving every world event as it is
+// raised, alongside the event sink. Listeners run while the world or one of
+// its objects may be locked, so they must only take note of the event.
+func (w *World) AddEventListener(listen func(GameEvent)) {
+	w.events.mutex.Lock()
+	defer w.events.mutex.Unlock()
+	w.events.listeners = append(w.events.listeners, listen)
+}
+
+// raiseEvent forwards an event to the event sink, if one is set, and the listeners
+func (w *World) raiseEvent(event GameEvent) {
+	w.events.mutex.Lock()
+	defer w.events.mutex.Unlock()
+	w.events.deliver(event)
+}
+
+// deliver hands an event to the sink and listeners; the events lock is held
+func (e *worldEvents) deliver(event GameEvent) {
+	if e.send != nil {
+		e.send(event)
+	}
+	for _, listen := range e.listeners {
+		listen(event)
+	}
+}
+
+// reportAttack raises an under-attack event for a player's damaged unit or
+// building, unless the same target raised one within AttackAlertInterval
+func (w *World) reportAttack(playerID int, alert AttackAlert) {
+	if w == nil {
+		return
+	}
+	now := w.now()
+
+	w.events.mutex.Lock()
+	defer w.events.mutex.Unlock()
+	if w.events.send == nil && len(w.events.listeners) == 0 {
+		return
+	}
+
+	key := attackKey{id: alert.TargetID, building: alert.IsBuilding}
+	if last, exists := w.events.lastAttack[key]; exists && now.Sub(last) < AttackAlertInterval {
+		return
+	}
+	if w.events.lastAttack == nil {
+		w.events.lastAttack = make(map[attackKey]time.Time)
+	}
+	for target, last := range w.events.lastAttack {
+		if now.Sub(last) >= AttackAlertInterval {
+			delete(w.events.lastAttack, target)
+		}
+	}
+	w.events.lastAttack[key] = now
+
+	kind := "Unit"
+	if alert.IsBuilding {
+		kind = "Building"
+	}
+	w.events.deliver(GameEvent{
+		Type:      EventTypeUnderAttack,
+		Timestamp: now,
+		PlayerID:  playerID,
+		Data:      alert,
+		Message: fmt.Sprintf("%s %s %d is under attack at (%.1f, %.1f)",
+			kind, alert.TargetType, alert.TargetID, alert.Position.X, alert.Position.Z),
+	})
+}
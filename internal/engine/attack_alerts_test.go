@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestWorldAttackAlerts tests that damage raises throttled under-attack events
+func TestWorldAttackAlerts(t *testing.T) {
+	world, err := NewHeadlessWorld(8, 8)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+
+	var events []GameEvent
+	world.SetEventSink(func(event GameEvent) { events = append(events, event) })
+
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 3, Z: 4}, soldier)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	house := data.NewSimpleUnit("house", 500, 0, "stone", nil)
+	building, err := world.ObjectManager.CreateBuilding(2, "house", Vector3{X: 6, Z: 6}, house)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+
+	combat := NewCombatSystem(world)
+	combat.ApplyDamage(unit, 10)
+	combat.ApplyDamage(unit, 10)
+	if len(events) != 1 {
+		t.Fatalf("Expected repeated hits to raise one alert, got %d", len(events))
+	}
+	event := events[0]
+	if event.Type != EventTypeUnderAttack || event.PlayerID != 1 {
+		t.Errorf("Expected an under-attack event for player 1, got %s for player %d", event.Type, event.PlayerID)
+	}
+	if location, ok := event.Location(); !ok || location != (Vector3{X: 3, Z: 4}) {
+		t.Errorf("Expected the alert at the unit, got %v (%v)", location, ok)
+	}
+
+	clock.Advance(AttackAlertInterval)
+	combat.ApplyDamage(unit, 10)
+	if len(events) != 2 {
+		t.Errorf("Expected a new alert after %v, got %d alerts", AttackAlertInterval, len(events))
+	}
+
+	if destroyed := combat.ApplyBuildingDamage(building, 50); destroyed {
+		t.Errorf("Expected the building to survive 50 damage")
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected the building hit to raise an alert, got %d alerts", len(events))
+	}
+	if alert := events[2].Data.(AttackAlert); !alert.IsBuilding || events[2].PlayerID != 2 || building.GetHealth() != 450 {
+		t.Errorf("Expected a building alert for player 2 and 450 health, got %+v for player %d, health %d",
+			alert, events[2].PlayerID, building.GetHealth())
+	}
+}
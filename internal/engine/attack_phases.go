@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// AttackPhase is where a unit is in its attack cycle
+type AttackPhase int
+
+const (
+	AttackPhaseReady    AttackPhase = iota // Free to start a swing
+	AttackPhaseWindUp                      // Swinging; a new order now loses the attack
+	AttackPhaseCooldown                    // Recovering after the hit; the unit may move freely
+)
+
+// String returns the name of an attack phase
+func (p AttackPhase) String() string {
+	switch p {
+	case AttackPhaseReady:
+		return "Ready"
+	case AttackPhaseWindUp:
+		return "WindUp"
+	case AttackPhaseCooldown:
+		return "Cooldown"
+	default:
+		return "Unknown"
+	}
+}
+
+// attackWindUp returns how long an attack skill swings before it hits: the
+// share of one attack cycle at which its launch or impact marker sits, which
+// is the skill's attack-start-time unless its XML marks the hit (0 = instant)
+func attackWindUp(skill *data.Skill) time.Duration {
+	if skill == nil || skill.AttacksPerSecond() <= 0 {
+		return 0
+	}
+	return time.Duration(attackHitFraction(skill) * float64(time.Second) / skill.AttacksPerSecond())
+}
+
+// AttackPhase returns where a unit is in its attack cycle
+func (cs *CombatSystem) AttackPhase(unit *GameUnit) AttackPhase {
+	if !unit.WindUpStart.IsZero() {
+		return AttackPhaseWindUp
+	}
+	if !cs.hasRecovered(unit) {
+		return AttackPhaseCooldown
+	}
+	return AttackPhaseReady
+}
+
+// hasRecovered reports whether a unit may start its next swing: one attack
+// cycle separates two hits, and the swing takes the last part of it
+func (cs *CombatSystem) hasRecovered(unit *GameUnit) bool {
+	if unit.AttackSpeed <= 0 {
+		return true
+	}
+	cycle := time.Duration(float64(time.Second) / float64(unit.AttackSpeed))
+	return cs.world.now().Sub(unit.LastAttack) >= cycle-unit.AttackWindUp
+}
+
+// advanceAttack moves a unit in range of its target through its attack cycle:
+// it starts a swing once the cooldown is over and returns true when the swing
+// completes and the hit lands now
+func (cs *CombatSystem) advanceAttack(unit *GameUnit) bool {
+	now := cs.world.now()
+	if unit.WindUpStart.IsZero() {
+		if !cs.hasRecovered(unit) {
+			return false
+		}
+		unit.WindUpStart = now
+	}
+	if now.Sub(unit.WindUpStart) < unit.AttackWindUp {
+		return false
+	}
+
+	unit.WindUpStart = time.Time{}
+	unit.LastAttack = now
+	return true
+}
+
+// cancelWindUp interrupts a swing in progress; the attack is lost but no
+// cooldown starts. The caller holds the unit lock or owns the unit.
+func (u *GameUnit) cancelWindUp() bool {
+	if u.WindUpStart.IsZero() {
+		return false
+	}
+	u.WindUpStart = time.Time{}
+	return true
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestAttackWindUp tests that attacks swing for the skill's attack-start-time
+// before they hit, that moving during the cooldown keeps the hit and that
+// moving during the swing loses it
+func TestAttackWindUp(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+
+	// One attack a second, hitting half way through the animation
+	def := data.NewSimpleUnit("swordsman", 100, 0, "leather", nil)
+	def.Unit.Skills = []data.Skill{{
+		Type:            data.SkillType{Value: "attack"},
+		Speed:           data.SkillSpeed{Value: 100},
+		AttackStrength:  &data.SkillAttackStrength{Value: 10},
+		AttackType:      &data.SkillAttackType{Value: "sword"},
+		AttackStartTime: &data.SkillAttackStartTime{Value: 0.5},
+	}}
+	attacker, _ := world.ObjectManager.CreateUnit(1, "swordsman", Vector3{X: 10.5, Z: 10.5}, def)
+	target, _ := world.ObjectManager.CreateUnit(2, "swordsman", Vector3{X: 11.5, Z: 10.5}, def)
+	if attacker.AttackSpeed != 1 || attacker.AttackWindUp != 500*time.Millisecond {
+		t.Fatalf("Expected timings from the skill, got %.1f/s and %v", attacker.AttackSpeed, attacker.AttackWindUp)
+	}
+	attacker.AttackDamage, attacker.AttackRange = 10, 1.5
+
+	processor := world.commandProcessor
+	combat := processor.combatSystem
+	step := func(duration time.Duration) {
+		for elapsed := time.Duration(0); elapsed < duration; elapsed += 100 * time.Millisecond {
+			clock.Advance(100 * time.Millisecond)
+			processor.Update(100 * time.Millisecond)
+		}
+	}
+
+	processor.IssueCommand(attacker.ID, CreateAttackCommand(target, false))
+	step(500 * time.Millisecond)
+	if phase := combat.AttackPhase(attacker); phase != AttackPhaseWindUp || target.Health != 100 {
+		t.Fatalf("Expected the swing not to have hit yet, phase %v health %d", phase, target.Health)
+	}
+	step(200 * time.Millisecond)
+	health := target.Health
+	if health >= 100 || combat.AttackPhase(attacker) != AttackPhaseCooldown {
+		t.Fatalf("Expected the hit once the wind-up completes, phase %v health %d", combat.AttackPhase(attacker), health)
+	}
+	firstHit := attacker.LastAttack
+
+	// Moving away during the cooldown keeps the hit, and the next hit still
+	// comes a full cycle after it
+	processor.IssueCommand(attacker.ID, CreateMoveCommand(Vector3{X: 9.5, Z: 10.5}, false))
+	step(200 * time.Millisecond)
+	processor.IssueCommand(attacker.ID, CreateAttackCommand(target, false))
+	step(300 * time.Millisecond)
+	if target.Health != health {
+		t.Fatalf("Expected no hit during the cooldown, health %d", target.Health)
+	}
+	step(500 * time.Millisecond)
+	if target.Health >= health || attacker.LastAttack.Sub(firstHit) != time.Second {
+		t.Fatalf("Expected the second hit a second after the first, got %v", attacker.LastAttack.Sub(firstHit))
+	}
+	health = target.Health
+
+	// A new order during the swing loses the attack without a cooldown
+	step(700 * time.Millisecond)
+	if combat.AttackPhase(attacker) != AttackPhaseWindUp {
+		t.Fatalf("Expected the next swing to have started, phase %v", combat.AttackPhase(attacker))
+	}
+	processor.IssueCommand(attacker.ID, CreateStopCommand())
+	if phase := combat.AttackPhase(attacker); phase != AttackPhaseReady {
+		t.Errorf("Expected a cancelled swing to leave the unit ready, phase %v", phase)
+	}
+	step(time.Second)
+	if target.Health != health {
+		t.Errorf("Expected the cancelled swing not to hit, health %d", target.Health)
+	}
+}
@@ -398,8 +398,8 @@ func (condition *IsEnemyInRangeCondition) Execute(context *BehaviorContext) Node
 	closestDistance := condition.range_ + 1
 
 	for _, enemy := range enemyUnits {
-		// Skip friendly and dead units
-		if enemy.PlayerID == unit.PlayerID || !enemy.IsAlive() {
+		// Skip friendly, dead and garrisoned units
+		if enemy.PlayerID == unit.PlayerID || !enemy.IsAlive() || enemy.IsGarrisoned() {
 			continue
 		}
 
@@ -0,0 +1,393 @@
+package engine
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"teraglest/internal/logging"
+)
+
+// behaviorReloadInterval is how often watched behavior files are checked for edits
+const behaviorReloadInterval = 2 * time.Second
+
+// BehaviorTreeSpec is a behavior tree template in its file format, JSON
+// or XML, so modders can write and tune unit AI without recompiling
+type BehaviorTreeSpec struct {
+	XMLName     xml.Name         `json:"-" xml:"behavior-tree"`
+	Name        string           `json:"name" xml:"name,attr"`
+	Description string           `json:"description,omitempty" xml:"description,attr,omitempty"`
+	UnitTypes   []string         `json:"unit_types,omitempty" xml:"unit-type"`
+	Root        BehaviorNodeSpec `json:"root" xml:"node"`
+}
+
+// BehaviorNodeSpec is one node of a behavior tree file. Which parameters
+// apply depends on the node type; see behaviorNodeTypes.
+type BehaviorNodeSpec struct {
+	Type      string             `json:"type" xml:"type,attr"`
+	Name      string             `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Key       string             `json:"key,omitempty" xml:"key,attr,omitempty"`             // Blackboard key read or written
+	Range     float64            `json:"range,omitempty" xml:"range,attr,omitempty"`         // Search range in world units
+	Tolerance float64            `json:"tolerance,omitempty" xml:"tolerance,attr,omitempty"` // How close counts as arrived
+	Threshold float64            `json:"threshold,omitempty" xml:"threshold,attr,omitempty"` // Health share, 0-1
+	Duration  string             `json:"duration,omitempty" xml:"duration,attr,omitempty"`   // Go duration such as "2s"
+	Repeats   int                `json:"repeats,omitempty" xml:"repeats,attr,omitempty"`     // 0 repeats forever
+	Amount    int                `json:"amount,omitempty" xml:"amount,attr,omitempty"`       // Resources carried
+	Policy    string             `json:"policy,omitempty" xml:"policy,attr,omitempty"`       // Parallel policy: "one" or "all"
+	Building  string             `json:"building,omitempty" xml:"building,attr,omitempty"`   // Building type to construct
+	Value     string             `json:"value,omitempty" xml:"value,attr,omitempty"`         // Blackboard value to set
+	Children  []BehaviorNodeSpec `json:"children,omitempty" xml:"node"`
+}
+
+// behaviorNodeType describes how a node type is checked and built
+type behaviorNodeType struct {
+	children string                                   // "many", "one" or "" for leaves
+	validate func(spec BehaviorNodeSpec) error        // Checks the node's own parameters
+	build    func(spec BehaviorNodeSpec) BehaviorNode // Builds a validated node without its children
+}
+
+// behaviorNodeTypes are the node types behavior tree files can use
+var behaviorNodeTypes = map[string]behaviorNodeType{
+	"sequence": {children: "many", build: func(s BehaviorNodeSpec) BehaviorNode { return NewSequenceNode(s.Name) }},
+	"selector": {children: "many", build: func(s BehaviorNodeSpec) BehaviorNode { return NewSelectorNode(s.Name) }},
+	"parallel": {
+		children: "many",
+		validate: func(s BehaviorNodeSpec) error {
+			if s.Policy != "" && s.Policy != "one" && s.Policy != "all" {
+				return fmt.Errorf("policy must be \"one\" or \"all\", not %q", s.Policy)
+			}
+			return nil
+		},
+		build: func(s BehaviorNodeSpec) BehaviorNode {
+			if s.Policy == "all" {
+				return NewParallelNode(s.Name, ParallelPolicyRequireAll)
+			}
+			return NewParallelNode(s.Name, ParallelPolicyRequireOne)
+		},
+	},
+	"inverter":  {children: "one", build: func(s BehaviorNodeSpec) BehaviorNode { return NewInverterNode(s.Name) }},
+	"succeeder": {children: "one", build: func(s BehaviorNodeSpec) BehaviorNode { return NewSucceederNode(s.Name) }},
+	"repeater": {
+		children: "one",
+		validate: func(s BehaviorNodeSpec) error { return requireNonNegative("repeats", float64(s.Repeats)) },
+		build: func(s BehaviorNodeSpec) BehaviorNode {
+			if s.Repeats == 0 {
+				return NewRepeaterNode(s.Name, -1)
+			}
+			return NewRepeaterNode(s.Name, s.Repeats)
+		},
+	},
+	"move_to": {
+		validate: func(s BehaviorNodeSpec) error {
+			return firstError(requireKey(s), requireNonNegative("tolerance", s.Tolerance))
+		},
+		build: func(s BehaviorNodeSpec) BehaviorNode { return NewMoveToPositionAction(s.Name, s.Key, s.Tolerance) },
+	},
+	"attack": {
+		validate: requireKey,
+		build:    func(s BehaviorNodeSpec) BehaviorNode { return NewAttackTargetAction(s.Name, s.Key) },
+	},
+	"gather": {
+		validate: requireKey,
+		build:    func(s BehaviorNodeSpec) BehaviorNode { return NewGatherResourceAction(s.Name, s.Key) },
+	},
+	"build": {
+		validate: func(s BehaviorNodeSpec) error {
+			if s.Building == "" {
+				return fmt.Errorf("building is required")
+			}
+			return requireKey(s)
+		},
+		build: func(s BehaviorNodeSpec) BehaviorNode { return NewBuildStructureAction(s.Name, s.Key, s.Building) },
+	},
+	"wait": {
+		validate: func(s BehaviorNodeSpec) error {
+			duration, err := time.ParseDuration(s.Duration)
+			if err != nil || duration <= 0 {
+				return fmt.Errorf("duration must be a positive duration such as \"2s\", not %q", s.Duration)
+			}
+			return nil
+		},
+		build: func(s BehaviorNodeSpec) BehaviorNode {
+			duration, _ := time.ParseDuration(s.Duration)
+			return NewWaitAction(s.Name, duration)
+		},
+	},
+	"set_value": {
+		validate: requireKey,
+		build:    func(s BehaviorNodeSpec) BehaviorNode { return NewSetBlackboardValueAction(s.Name, s.Key, s.Value) },
+	},
+	"health_low": {
+		validate: func(s BehaviorNodeSpec) error {
+			if s.Threshold <= 0 || s.Threshold > 1 {
+				return fmt.Errorf("threshold must be a health share above 0 and at most 1, not %g", s.Threshold)
+			}
+			return nil
+		},
+		build: func(s BehaviorNodeSpec) BehaviorNode { return NewIsHealthLowCondition(s.Name, s.Threshold) },
+	},
+	"enemy_in_range": {
+		validate: func(s BehaviorNodeSpec) error { return firstError(requireKey(s), requirePositive("range", s.Range)) },
+		build:    func(s BehaviorNodeSpec) BehaviorNode { return NewIsEnemyInRangeCondition(s.Name, s.Range, s.Key) },
+	},
+	"resource_in_range": {
+		validate: func(s BehaviorNodeSpec) error { return firstError(requireKey(s), requirePositive("range", s.Range)) },
+		build:    func(s BehaviorNodeSpec) BehaviorNode { return NewIsResourceInRangeCondition(s.Name, s.Range, s.Key) },
+	},
+	"carrying": {
+		validate: func(s BehaviorNodeSpec) error { return requireNonNegative("amount", float64(s.Amount)) },
+		build:    func(s BehaviorNodeSpec) BehaviorNode { return NewIsCarryingResourcesCondition(s.Name, s.Amount) },
+	},
+	"key_set": {
+		validate: requireKey,
+		build:    func(s BehaviorNodeSpec) BehaviorNode { return NewIsBlackboardKeySetCondition(s.Name, s.Key) },
+	},
+	"idle": {build: func(s BehaviorNodeSpec) BehaviorNode { return NewIsUnitIdleCondition(s.Name) }},
+}
+
+// requireKey checks that a node names its blackboard key
+func requireKey(s BehaviorNodeSpec) error {
+	if s.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+	return nil
+}
+
+// requirePositive checks a parameter that must be above zero
+func requirePositive(name string, value float64) error {
+	if value <= 0 {
+		return fmt.Errorf("%s must be positive, not %g", name, value)
+	}
+	return nil
+}
+
+// requireNonNegative checks a parameter that may be zero but not below
+func requireNonNegative(name string, value float64) error {
+	if value < 0 {
+		return fmt.Errorf("%s must not be negative, not %g", name, value)
+	}
+	return nil
+}
+
+// firstError returns the first non-nil error
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseBehaviorTree reads a behavior tree file's contents; format is
+// "json" or "xml". The tree is validated before it is returned.
+func ParseBehaviorTree(content []byte, format string) (*BehaviorTreeSpec, error) {
+	spec := &BehaviorTreeSpec{}
+	var err error
+	switch format {
+	case "json":
+		err = json.Unmarshal(content, spec)
+	case "xml":
+		err = xml.Unmarshal(content, spec)
+	default:
+		return nil, fmt.Errorf("unknown behavior tree format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse behavior tree: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// LoadBehaviorTreeFile reads a .json or .xml behavior tree file
+func LoadBehaviorTreeFile(path string) (*BehaviorTreeSpec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read behavior tree: %w", err)
+	}
+	spec, err := ParseBehaviorTree(content, strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Validate checks that a tree uses known node types with the children and
+// parameters they need, reporting every problem with its node path
+func (spec *BehaviorTreeSpec) Validate() error {
+	if spec.Name == "" {
+		return fmt.Errorf("behavior tree has no name")
+	}
+	var problems []string
+	validateBehaviorNode(spec.Root, spec.Name, &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("behavior tree %s is invalid: %s", spec.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateBehaviorNode checks a node and its subtree
+func validateBehaviorNode(node BehaviorNodeSpec, parent string, problems *[]string) {
+	path := parent + "/" + node.Type
+	if node.Name != "" {
+		path = parent + "/" + node.Name
+	}
+	nodeType, ok := behaviorNodeTypes[node.Type]
+	if !ok {
+		*problems = append(*problems, fmt.Sprintf("%s: unknown node type %q", path, node.Type))
+		return
+	}
+	if nodeType.validate != nil {
+		if err := nodeType.validate(node); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	switch children := len(node.Children); {
+	case nodeType.children == "many" && children == 0:
+		*problems = append(*problems, fmt.Sprintf("%s: needs at least one child", path))
+	case nodeType.children == "one" && children != 1:
+		*problems = append(*problems, fmt.Sprintf("%s: needs exactly one child, has %d", path, children))
+	case nodeType.children == "" && children > 0:
+		*problems = append(*problems, fmt.Sprintf("%s: cannot have children", path))
+	}
+	for _, child := range node.Children {
+		validateBehaviorNode(child, path, problems)
+	}
+}
+
+// Build creates the node tree of a validated spec
+func (spec *BehaviorTreeSpec) Build() BehaviorNode {
+	return buildBehaviorNode(spec.Root)
+}
+
+// buildBehaviorNode creates a node and its subtree
+func buildBehaviorNode(spec BehaviorNodeSpec) BehaviorNode {
+	if spec.Name == "" {
+		spec.Name = spec.Type
+	}
+	node := behaviorNodeTypes[spec.Type].build(spec)
+	for _, child := range spec.Children {
+		node.AddChild(buildBehaviorNode(child))
+	}
+	return node
+}
+
+// RegisterSpec adds or replaces a template built from a behavior tree file
+func (btl *BehaviorTreeLibrary) RegisterSpec(spec *BehaviorTreeSpec) {
+	btl.RegisterTemplate(&BehaviorTreeTemplate{
+		Name:        spec.Name,
+		Description: spec.Description,
+		UnitTypes:   spec.UnitTypes,
+		Builder:     spec.Build,
+	})
+}
+
+// BehaviorLoader loads the behavior tree files of a directory and finds
+// the ones edited since, for hot-reloading
+type BehaviorLoader struct {
+	dir   string
+	files map[string]time.Time // Path -> modification time when last loaded
+}
+
+// NewBehaviorLoader creates a loader for a directory of .json and .xml trees
+func NewBehaviorLoader(dir string) *BehaviorLoader {
+	return &BehaviorLoader{dir: dir, files: make(map[string]time.Time)}
+}
+
+// Changes returns the trees of files that are new or were edited since the
+// last call. A file that fails to load is reported in the error and tried
+// again once it is edited; the trees of the other files are still returned.
+func (bl *BehaviorLoader) Changes() ([]*BehaviorTreeSpec, error) {
+	entries, err := os.ReadDir(bl.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read behavior directory: %w", err)
+	}
+
+	var specs []*BehaviorTreeSpec
+	var problems []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".json" && ext != ".xml") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(bl.dir, entry.Name())
+		if loaded, seen := bl.files[path]; seen && loaded.Equal(info.ModTime()) {
+			continue
+		}
+
+		bl.files[path] = info.ModTime()
+		spec, err := LoadBehaviorTreeFile(path)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+
+	if len(problems) > 0 {
+		return specs, fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+	return specs, nil
+}
+
+// WatchBehaviors loads the behavior tree files of a directory into the
+// manager's library and reloads them as they are edited; units running a
+// reloaded tree restart on its new version
+func (btm *BehaviorTreeManager) WatchBehaviors(dir string) error {
+	loader := NewBehaviorLoader(dir)
+	specs, err := loader.Changes()
+	for _, spec := range specs {
+		btm.library.RegisterSpec(spec)
+	}
+	btm.loader, btm.lastReload = loader, btm.world.now()
+	return err
+}
+
+// reloadBehaviors applies edits to watched behavior files
+func (btm *BehaviorTreeManager) reloadBehaviors() {
+	if btm.loader == nil || btm.world.now().Sub(btm.lastReload) < behaviorReloadInterval {
+		return
+	}
+	btm.lastReload = btm.world.now()
+
+	specs, err := btm.loader.Changes()
+	if err != nil {
+		logging.Warnf(logging.CategoryAI, "Behavior tree reload: %v", err)
+	}
+	for _, spec := range specs {
+		btm.library.RegisterSpec(spec)
+		restarted := btm.RestartTrees(spec.Name)
+		logging.Infof(logging.CategoryAI, "Reloaded behavior tree %s (%d units restarted)", spec.Name, restarted)
+	}
+}
+
+// RestartTrees gives units running a template a fresh tree built from its
+// current version, returning how many were restarted
+func (btm *BehaviorTreeManager) RestartTrees(templateName string) int {
+	restarted := 0
+	for unitID, tree := range btm.trees {
+		if tree.Template() != templateName {
+			continue
+		}
+		fresh, err := btm.library.CreateBehaviorTree(templateName)
+		if err != nil {
+			continue
+		}
+		if btm.SetBehaviorTree(unitID, fresh) == nil {
+			restarted++
+		}
+	}
+	return restarted
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+const testGuardJSON = `{
+	"name": "guard_post",
+	"description": "Attack enemies near the post, otherwise wait",
+	"unit_types": ["guard"],
+	"root": {"type": "selector", "children": [
+		{"type": "sequence", "name": "Fight", "children": [
+			{"type": "enemy_in_range", "range": 8, "key": "enemy"},
+			{"type": "attack", "key": "enemy"}
+		]},
+		{"type": "wait", "duration": "2s"}
+	]}
+}`
+
+const testGuardXML = `<behavior-tree name="guard_post" description="Attack enemies near the post, otherwise wait">
+	<unit-type>guard</unit-type>
+	<node type="selector">
+		<node type="sequence" name="Fight">
+			<node type="enemy_in_range" range="8" key="enemy"/>
+			<node type="attack" key="enemy"/>
+		</node>
+		<node type="wait" duration="2s"/>
+	</node>
+</behavior-tree>`
+
+// TestBehaviorTreeFormat tests that JSON and XML behavior trees load to the
+// same tree and that invalid trees report every problem
+func TestBehaviorTreeFormat(t *testing.T) {
+	fromJSON, err := ParseBehaviorTree([]byte(testGuardJSON), "json")
+	if err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	fromXML, err := ParseBehaviorTree([]byte(testGuardXML), "xml")
+	if err != nil {
+		t.Fatalf("Failed to parse XML: %v", err)
+	}
+	if fromXML.Name != fromJSON.Name || len(fromXML.UnitTypes) != 1 || len(fromXML.Root.Children) != 2 ||
+		fromXML.Root.Children[0].Children[0].Range != 8 {
+		t.Errorf("Expected the XML tree to match the JSON one, got %+v", fromXML)
+	}
+
+	library := NewBehaviorTreeLibrary()
+	library.RegisterSpec(fromJSON)
+	offered := false
+	for _, template := range library.GetTemplatesForUnitType("guard") {
+		offered = offered || template.Name == "guard_post"
+	}
+	if !offered {
+		t.Fatal("Expected the tree to be offered to guards")
+	}
+	tree, err := library.CreateBehaviorTree("guard_post")
+	if err != nil {
+		t.Fatalf("Failed to create tree: %v", err)
+	}
+	root := tree.root
+	if tree.Template() != "guard_post" || len(root.GetChildren()) != 2 || root.GetChildren()[0].GetName() != "Fight" {
+		t.Errorf("Expected the tree from the file, got template %q", tree.Template())
+	}
+
+	invalid := `{"name": "broken", "root": {"type": "sequence", "children": [
+		{"type": "teleport"},
+		{"type": "wait", "duration": "soon"},
+		{"type": "inverter"},
+		{"type": "idle", "children": [{"type": "idle"}]}
+	]}}`
+	_, err = ParseBehaviorTree([]byte(invalid), "json")
+	if err == nil {
+		t.Fatal("Expected an invalid tree to be rejected")
+	}
+	for _, problem := range []string{"broken/sequence/teleport", "broken/sequence/wait: duration", "broken/sequence/inverter: needs exactly one child", "broken/sequence/idle: cannot have children"} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("Expected %q in %v", problem, err)
+		}
+	}
+}
+
+// TestBehaviorTreeHotReload tests that edited behavior files replace their
+// template and restart the units running it, while broken edits are skipped
+func TestBehaviorTreeHotReload(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "guard.json")
+	write := func(content string, modified time.Time) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write behavior file: %v", err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatalf("Failed to touch behavior file: %v", err)
+		}
+	}
+	write(testGuardJSON, time.Unix(1000, 0))
+
+	manager := world.behaviorTreeMgr
+	if err := manager.WatchBehaviors(dir); err != nil {
+		t.Fatalf("Failed to load behaviors: %v", err)
+	}
+	guard, _ := world.ObjectManager.CreateUnit(1, "guard", Vector3{X: 5, Z: 5}, data.NewSimpleUnit("guard", 100, 0, "leather", nil))
+	tree, err := manager.Library().CreateBehaviorTree("guard_post")
+	if err != nil {
+		t.Fatalf("Failed to create tree: %v", err)
+	}
+	manager.SetBehaviorTree(guard.ID, tree)
+
+	// A broken edit keeps the running version
+	write(`{"name": "guard_post", "root": {"type": "wait"}}`, time.Unix(2000, 0))
+	clock.Advance(behaviorReloadInterval)
+	manager.Update(100 * time.Millisecond)
+	if current, _ := manager.GetBehaviorTree(guard.ID); current != tree {
+		t.Fatal("Expected a broken edit not to replace the tree")
+	}
+
+	write(strings.Replace(testGuardJSON, `"duration": "2s"`, `"duration": "5s"`, 1), time.Unix(3000, 0))
+	clock.Advance(behaviorReloadInterval / 2)
+	manager.Update(100 * time.Millisecond)
+	if current, _ := manager.GetBehaviorTree(guard.ID); current != tree {
+		t.Fatal("Expected files to be checked only every reload interval")
+	}
+	clock.Advance(behaviorReloadInterval / 2)
+	manager.Update(100 * time.Millisecond)
+	current, _ := manager.GetBehaviorTree(guard.ID)
+	if current == tree || current.Template() != "guard_post" || !current.IsActive() {
+		t.Fatal("Expected the guard to restart on the edited tree")
+	}
+	if wait := current.root.GetChildren()[1].(*WaitAction); wait.duration != 5*time.Second {
+		t.Errorf("Expected the edited wait, got %v", wait.duration)
+	}
+}
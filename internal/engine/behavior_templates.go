@@ -63,8 +63,9 @@ func (btl *BehaviorTreeLibrary) CreateBehaviorTree(templateName string) (*Behavi
 		return nil, fmt.Errorf("template %s not found", templateName)
 	}
 
-	root := template.Builder()
-	return NewBehaviorTree(root), nil
+	tree := NewBehaviorTree(template.Builder())
+	tree.template = templateName
+	return tree, nil
 }
 
 // GetAllTemplateNames returns names of all registered templates
@@ -532,6 +532,7 @@ func (sn *SucceederNode) Execute(context *BehaviorContext) NodeStatus {
 // BehaviorTree represents a complete behavior tree for a unit
 type BehaviorTree struct {
 	root     BehaviorNode      // Root node of the tree
+	template string            // Library template the tree was built from ("" = built in code)
 	context  *BehaviorContext  // Execution context
 	status   NodeStatus        // Current tree status
 	isActive bool              // Whether the tree is currently active
@@ -582,6 +583,11 @@ func (bt *BehaviorTree) Update(deltaTime time.Duration) NodeStatus {
 	return bt.status
 }
 
+// Template returns the name of the library template the tree was built from
+func (bt *BehaviorTree) Template() string {
+	return bt.template
+}
+
 // GetStatus returns the current status of the behavior tree
 func (bt *BehaviorTree) GetStatus() NodeStatus {
 	return bt.status
@@ -609,16 +615,26 @@ func (bt *BehaviorTree) Reset() {
 type BehaviorTreeManager struct {
 	trees map[int]*BehaviorTree // Unit ID -> Behavior Tree mapping
 	world *World               // World reference
+
+	library    *BehaviorTreeLibrary // Templates, built in and loaded from files
+	loader     *BehaviorLoader      // Watched behavior directory (nil = none)
+	lastReload time.Time            // When the watched files were last checked
 }
 
 // NewBehaviorTreeManager creates a new behavior tree manager
 func NewBehaviorTreeManager(world *World) *BehaviorTreeManager {
 	return &BehaviorTreeManager{
-		trees: make(map[int]*BehaviorTree),
-		world: world,
+		trees:   make(map[int]*BehaviorTree),
+		world:   world,
+		library: NewBehaviorTreeLibrary(),
 	}
 }
 
+// Library returns the templates the manager builds trees from
+func (btm *BehaviorTreeManager) Library() *BehaviorTreeLibrary {
+	return btm.library
+}
+
 // SetBehaviorTree assigns a behavior tree to a unit
 func (btm *BehaviorTreeManager) SetBehaviorTree(unitID int, tree *BehaviorTree) error {
 	unit := btm.world.ObjectManager.GetUnit(unitID)
@@ -648,6 +664,8 @@ func (btm *BehaviorTreeManager) RemoveBehaviorTree(unitID int) {
 
 // Update updates all active behavior trees
 func (btm *BehaviorTreeManager) Update(deltaTime time.Duration) {
+	btm.reloadBehaviors()
+
 	for unitID, tree := range btm.trees {
 		// Check if unit still exists
 		unit := btm.world.ObjectManager.GetUnit(unitID)
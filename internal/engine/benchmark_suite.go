@@ -0,0 +1,316 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+	"teraglest/pkg/formats"
+)
+
+// BenchmarkCase is a named benchmark shared by `go test -bench` and cmd/benchmark
+type BenchmarkCase struct {
+	Name string
+	Run  func(b *testing.B)
+}
+
+// BenchmarkCases returns the engine subsystem benchmarks: pathfinding on several
+// map sizes and unit counts, ObjectManager updates, combat resolution, and
+// XML/G3D loading
+func BenchmarkCases() []BenchmarkCase {
+	var cases []BenchmarkCase
+
+	for _, size := range []int{32, 64, 128} {
+		for _, units := range []int{1, 10, 50} {
+			size, units := size, units
+			cases = append(cases, BenchmarkCase{
+				Name: fmt.Sprintf("pathfinding/map=%d/units=%d", size, units),
+				Run:  func(b *testing.B) { benchmarkPathfinding(b, size, units) },
+			})
+		}
+	}
+
+	for _, units := range []int{200, 1000, 5000} {
+		units := units
+		cases = append(cases, BenchmarkCase{
+			Name: fmt.Sprintf("objectmanager/units=%d", units),
+			Run:  func(b *testing.B) { benchmarkObjectManagerUpdate(b, units) },
+		})
+	}
+
+	cases = append(cases,
+		BenchmarkCase{Name: "combat/execute-attack", Run: benchmarkCombat},
+		BenchmarkCase{Name: "load/unit-xml", Run: benchmarkUnitXMLLoad},
+		BenchmarkCase{Name: "load/g3d", Run: benchmarkG3DLoad},
+	)
+	return cases
+}
+
+// NewHeadlessWorld creates a world of the given size with no map or tileset and
+// an empty in-memory asset provider, for benchmarks and tests. Players 1 and 2
+// exist with resources.
+func NewHeadlessWorld(width, height int) (*World, error) {
+	world, err := NewWorld(GameSettings{MaxPlayers: 2, ResourceMultiplier: 1.0}, &data.TechTree{}, data.NewMemoryAssetProvider())
+	if err != nil {
+		return nil, err
+	}
+
+	world.Width = width
+	world.Height = height
+	if err := world.initializeGrid(); err != nil {
+		return nil, fmt.Errorf("failed to initialize grid system: %w", err)
+	}
+	world.pathfindingMgr = NewPathfindingManager(world)
+
+	for id := 1; id <= 2; id++ {
+		world.players[id] = &Player{
+			ID:        id,
+			Name:      fmt.Sprintf("Player %d", id),
+			IsActive:  true,
+			Resources: map[string]int{"gold": 1000, "wood": 1000, "stone": 500, "energy": 500},
+
+			ResourcesGathered: make(map[string]int),
+			ResourcesSpent:    make(map[string]int),
+		}
+	}
+	world.initialized = true
+	return world, nil
+}
+
+// benchmarkUnitDefinition is a minimal soldier definition used by the benchmarks
+func benchmarkUnitDefinition() *data.UnitDefinition {
+	return &data.UnitDefinition{
+		Name: "soldier",
+		Unit: data.Unit{
+			Parameters: data.UnitParameters{
+				MaxHP:                data.UnitHP{Value: 400},
+				Armor:                data.UnitArmor{Value: 4},
+				ArmorType:            data.UnitArmorType{Value: "leather"},
+				ResourceRequirements: []data.ResourceRequirement{{Name: "gold", Amount: 100}},
+			},
+		},
+	}
+}
+
+// benchmarkPathfinding finds one path per unit across a map with ~15% random walls
+func benchmarkPathfinding(b *testing.B, size, units int) {
+	world, err := NewHeadlessWorld(size, size)
+	if err != nil {
+		b.Fatalf("Failed to create world: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for y := 1; y < size-1; y++ {
+		for x := 1; x < size-1; x++ {
+			if rng.Float64() < 0.15 {
+				world.SetWalkable(Vector2i{X: x, Y: y}, false)
+			}
+		}
+	}
+
+	requests := make([]PathRequest, units)
+	for i := range requests {
+		requests[i] = PathRequest{
+			Start:        GridPosition{Grid: Vector2i{X: 0, Y: rng.Intn(size)}},
+			Target:       GridPosition{Grid: Vector2i{X: size - 1, Y: rng.Intn(size)}},
+			UnitSize:     1,
+			AllowPartial: true,
+		}
+	}
+
+	pathfinder := NewPathfinder(world)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, request := range requests {
+			pathfinder.FindPath(request)
+		}
+	}
+}
+
+// benchmarkObjectManagerUpdate measures one simulation update of the given number of units
+func benchmarkObjectManagerUpdate(b *testing.B, units int) {
+	size := 16
+	for size*size < units*2 {
+		size *= 2
+	}
+	world, err := NewHeadlessWorld(size, size)
+	if err != nil {
+		b.Fatalf("Failed to create world: %v", err)
+	}
+
+	unitDef := benchmarkUnitDefinition()
+	for i := 0; i < units; i++ {
+		position := Vector3{X: float64(i % size), Z: float64(i / size)}
+		if _, err := world.ObjectManager.UnitManager.CreateUnit(1+i%2, "soldier", position, unitDef); err != nil {
+			b.Fatalf("Failed to create unit: %v", err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		world.ObjectManager.Update(50 * time.Millisecond)
+	}
+}
+
+// benchmarkCombat measures one complete attack, restoring the target's health each time
+func benchmarkCombat(b *testing.B) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		b.Fatalf("Failed to create world: %v", err)
+	}
+
+	unitDef := benchmarkUnitDefinition()
+	attacker, err := world.ObjectManager.UnitManager.CreateUnit(1, "soldier", Vector3{X: 4, Z: 4}, unitDef)
+	if err != nil {
+		b.Fatalf("Failed to create attacker: %v", err)
+	}
+	target, err := world.ObjectManager.UnitManager.CreateUnit(2, "soldier", Vector3{X: 5, Z: 4}, unitDef)
+	if err != nil {
+		b.Fatalf("Failed to create target: %v", err)
+	}
+	attacker.AttackSpeed = 0 // No cooldown between benchmark iterations
+
+	combat := NewCombatSystem(world)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		target.Health = target.MaxHealth
+		if result := combat.ExecuteAttack(attacker, target); !result.CanAttack {
+			b.Fatalf("Attack failed: %s", result.ErrorMessage)
+		}
+	}
+}
+
+// benchmarkUnitXMLLoad measures parsing a typical unit definition
+func benchmarkUnitXMLLoad(b *testing.B) {
+	dir, err := os.MkdirTemp("", "teraglest-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "soldier.xml")
+	if err := os.WriteFile(path, []byte(benchmarkUnitXML), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := data.LoadUnit(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkG3DLoad measures loading a 10-frame, 500-vertex G3D model
+func benchmarkG3DLoad(b *testing.B) {
+	dir, err := os.MkdirTemp("", "teraglest-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "soldier.g3d")
+	model := benchmarkG3DModel(10, 500)
+	if err := os.WriteFile(path, model, 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(model)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := formats.LoadG3D(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkG3DModel encodes a single-mesh textured G3D v4 model
+func benchmarkG3DModel(frames, vertices int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("G3D")
+	buf.WriteByte(formats.G3DVersion4)
+	binary.Write(&buf, binary.LittleEndian, formats.G3DModelHeader{MeshCount: 1, Type: formats.MorphMesh})
+
+	header := formats.G3DMeshHeader{
+		FrameCount:  uint32(frames),
+		VertexCount: uint32(vertices),
+		IndexCount:  uint32(vertices * 3),
+		Opacity:     1,
+		Textures:    1,
+	}
+	copy(header.Name[:], "body")
+	binary.Write(&buf, binary.LittleEndian, header)
+
+	texture := make([]byte, formats.MapPathSize)
+	copy(texture, "soldier.tga")
+	buf.Write(texture)
+
+	positions := make([]formats.Vec3f, frames*vertices)
+	for i := range positions {
+		positions[i] = formats.Vec3f{X: float32(i % 7), Y: float32(i % 11), Z: float32(i % 13)}
+	}
+	binary.Write(&buf, binary.LittleEndian, positions) // Vertices
+	binary.Write(&buf, binary.LittleEndian, positions) // Normals
+	binary.Write(&buf, binary.LittleEndian, make([]formats.Vec2f, vertices))
+
+	indices := make([]uint32, vertices*3)
+	for i := range indices {
+		indices[i] = uint32(i % vertices)
+	}
+	binary.Write(&buf, binary.LittleEndian, indices)
+	return buf.Bytes()
+}
+
+// benchmarkUnitXML is a representative MegaGlest unit definition
+const benchmarkUnitXML = `<?xml version="1.0" standalone="no"?>
+<unit>
+	<parameters>
+		<size value="1"/>
+		<height value="2"/>
+		<max-hp value="400" regeneration="0"/>
+		<max-ep value="0" regeneration="0"/>
+		<armor value="4"/>
+		<armor-type value="leather"/>
+		<sight value="9"/>
+		<time value="30"/>
+		<multi-selection value="true"/>
+		<cellmap value="false"/>
+		<levels><level name="veteran" kills="5"/></levels>
+		<fields><field value="land"/></fields>
+		<properties/>
+		<light enabled="false"/>
+		<unit-requirements/>
+		<upgrade-requirements/>
+		<resource-requirements>
+			<resource name="gold" amount="100"/>
+			<resource name="food" amount="1"/>
+		</resource-requirements>
+		<resources-stored/>
+		<image path="images/soldier.bmp"/>
+		<image-cancel path="../../../../tech/cancel.bmp"/>
+		<meeting-point value="false"/>
+		<selection-sounds enabled="true"><sound path="sounds/select1.wav"/><sound path="sounds/select2.wav"/></selection-sounds>
+		<command-sounds enabled="true"><sound path="sounds/ok1.wav"/></command-sounds>
+	</parameters>
+	<skills>
+		<skill><type value="stop"/><name value="stop_skill"/><ep-cost value="0"/><speed value="1000"/><anim-speed value="40"/><animation path="models/soldier_standing.g3d"/><sound enabled="false"/></skill>
+		<skill><type value="move"/><name value="move_skill"/><ep-cost value="0"/><speed value="110"/><anim-speed value="65"/><animation path="models/soldier_walking.g3d"/><sound enabled="false"/></skill>
+		<skill><type value="attack"/><name value="attack_skill"/><ep-cost value="0"/><speed value="100"/><anim-speed value="100"/><animation path="models/soldier_attacking.g3d"/>
+			<sound enabled="true" start-time="0.5"><sound-file path="sounds/hit1.wav"/></sound>
+			<attack-strenght value="60"/><attack-var value="20"/><attack-range value="1"/><attack-type value="sword"/>
+			<attack-fields><field value="land"/></attack-fields><attack-start-time value="0.5"/><projectile value="false"/><splash value="false"/></skill>
+		<skill><type value="die"/><name value="die_skill"/><ep-cost value="0"/><speed value="60"/><anim-speed value="60"/><animation path="models/soldier_dying.g3d"/><sound enabled="false"/><fade value="false"/></skill>
+	</skills>
+	<commands>
+		<command><type value="stop"/><name value="stop"/><image path="../../../../tech/stop.bmp"/><unit-requirements/><upgrade-requirements/><stop-skill value="stop_skill"/></command>
+		<command><type value="move"/><name value="move"/><image path="../../../../tech/move.bmp"/><unit-requirements/><upgrade-requirements/><move-skill value="move_skill"/></command>
+		<command><type value="attack"/><name value="attack"/><image path="images/attack.bmp"/><unit-requirements/><upgrade-requirements/><move-skill value="move_skill"/><attack-skill value="attack_skill"/></command>
+	</commands>
+</unit>
+`
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"teraglest/pkg/formats"
+)
+
+// BenchmarkSubsystems runs the shared engine benchmark suite, e.g.
+// go test ./internal/engine -run '^$' -bench 'Subsystems/pathfinding'
+func BenchmarkSubsystems(b *testing.B) {
+	for _, c := range BenchmarkCases() {
+		b.Run(c.Name, c.Run)
+	}
+}
+
+func TestNewHeadlessWorld(t *testing.T) {
+	world, err := NewHeadlessWorld(40, 24)
+	if err != nil {
+		t.Fatalf("NewHeadlessWorld failed: %v", err)
+	}
+	if world.Width != 40 || world.Height != 24 || len(world.walkableGrid) != 24 || len(world.walkableGrid[0]) != 40 {
+		t.Errorf("Unexpected world dimensions %dx%d", world.Width, world.Height)
+	}
+	if world.GetPlayer(1) == nil || world.GetPlayer(2) == nil {
+		t.Error("Expected players 1 and 2")
+	}
+
+	result := NewPathfinder(world).FindPath(PathRequest{
+		Start:    GridPosition{Grid: Vector2i{X: 0, Y: 0}},
+		Target:   GridPosition{Grid: Vector2i{X: 39, Y: 23}},
+		UnitSize: 1,
+	})
+	if !result.Success {
+		t.Error("Expected a path across the empty headless world")
+	}
+}
+
+func TestBenchmarkG3DFixture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.g3d")
+	if err := os.WriteFile(path, benchmarkG3DModel(2, 4), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := formats.LoadG3D(path)
+	if err != nil {
+		t.Fatalf("Generated G3D does not load: %v", err)
+	}
+	if model.GetTotalVertexCount() != 8 || len(model.Meshes[0].Indices) != 12 || model.Meshes[0].TextureNames[0] != "soldier.tga" {
+		t.Errorf("Unexpected model contents: %+v", model.Meshes[0].Header)
+	}
+}
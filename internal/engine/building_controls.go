@@ -0,0 +1,318 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetRallyPoint sets the position produced units move to after spawning
+func (ps *ProductionSystem) SetRallyPoint(buildingID int, position Vector3) error {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+
+	building.mutex.Lock()
+	defer building.mutex.Unlock()
+
+	rally := position
+	building.RallyPoint = &rally
+	building.RallyResource = nil
+	return nil
+}
+
+// SetGatherPoint sets a resource node that produced units will immediately gather from
+func (ps *ProductionSystem) SetGatherPoint(buildingID int, resourceNodeID int) error {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+
+	resource := ps.world.GetResourceNode(resourceNodeID)
+	if resource == nil {
+		return fmt.Errorf("resource node %d not found", resourceNodeID)
+	}
+
+	building.mutex.Lock()
+	defer building.mutex.Unlock()
+
+	building.RallyResource = resource
+	rally := resource.Position
+	building.RallyPoint = &rally
+	return nil
+}
+
+// ClearRallyPoint removes any rally or gather point from a building
+func (ps *ProductionSystem) ClearRallyPoint(buildingID int) error {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+
+	building.mutex.Lock()
+	defer building.mutex.Unlock()
+
+	building.RallyPoint = nil
+	building.RallyResource = nil
+	return nil
+}
+
+// SetProductionGroup flags a building so the units it produces join a
+// control group (1-9) and an existing army group of its owner; 0 leaves
+// either out. Army groups are not saved, so only the control group survives
+// a save and load.
+func (ps *ProductionSystem) SetProductionGroup(buildingID, controlGroup, armyGroup int) error {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+	if controlGroup < 0 || controlGroup > MaxControlGroup {
+		return fmt.Errorf("control group %d out of range 1-%d", controlGroup, MaxControlGroup)
+	}
+	if armyGroup != 0 {
+		group, exists := ps.world.groupMgr.GetGroup(armyGroup)
+		if !exists || group.PlayerID != building.PlayerID {
+			return fmt.Errorf("army group %d not found", armyGroup)
+		}
+	}
+
+	building.mutex.Lock()
+	defer building.mutex.Unlock()
+
+	building.JoinControlGroup = controlGroup
+	building.JoinArmyGroup = armyGroup
+	return nil
+}
+
+// SetAutoProduction toggles automatic re-queueing of the last produced unit
+func (ps *ProductionSystem) SetAutoProduction(buildingID int, enabled bool) error {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+
+	building.mutex.Lock()
+	defer building.mutex.Unlock()
+
+	building.AutoProduction = enabled
+	return nil
+}
+
+// CancelQueuedProduction removes a queued (not yet started) item and refunds its full cost
+func (ps *ProductionSystem) CancelQueuedProduction(buildingID int, queueIndex int) error {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+
+	building.mutex.Lock()
+	if queueIndex < 0 || queueIndex >= len(building.ProductionQueue) {
+		queueLength := len(building.ProductionQueue)
+		building.mutex.Unlock()
+		return fmt.Errorf("queue index %d out of range (queue length %d)", queueIndex, queueLength)
+	}
+
+	item := building.ProductionQueue[queueIndex]
+	building.ProductionQueue = append(building.ProductionQueue[:queueIndex], building.ProductionQueue[queueIndex+1:]...)
+	playerID := building.PlayerID
+	building.mutex.Unlock()
+
+	// Refund outside the building lock so world and building locks are never held together
+	if len(item.Cost) > 0 {
+		ps.world.AddResources(playerID, item.Cost, "production_queue_cancellation")
+	}
+
+	return nil
+}
+
+// GarrisonUnit moves a unit inside a building, removing it from the map
+func (ps *ProductionSystem) GarrisonUnit(buildingID int, unitID int) error {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+
+	unit := ps.world.ObjectManager.GetUnit(unitID)
+	if unit == nil {
+		return fmt.Errorf("unit %d not found", unitID)
+	}
+
+	if unit.PlayerID != building.PlayerID {
+		return fmt.Errorf("unit %d does not belong to building owner", unitID)
+	}
+
+	building.mutex.Lock()
+	defer building.mutex.Unlock()
+
+	if !building.IsBuilt {
+		return fmt.Errorf("building is not complete")
+	}
+
+	if len(building.GarrisonedUnits) >= building.GarrisonCapacity {
+		return fmt.Errorf("garrison full (%d/%d)", len(building.GarrisonedUnits), building.GarrisonCapacity)
+	}
+
+	unit.mutex.Lock()
+	defer unit.mutex.Unlock()
+
+	if unit.GarrisonedIn != 0 {
+		return fmt.Errorf("unit %d is already garrisoned in building %d", unitID, unit.GarrisonedIn)
+	}
+
+	// Free the tiles the unit was standing on
+	ps.world.releaseFootprint(unit, unit.GridPos.Grid)
+
+	unit.GarrisonedIn = building.ID
+	unit.cancelWindUp()
+	unit.CurrentCommand = nil
+	unit.CommandQueue = []UnitCommand{}
+	unit.Path = nil
+	unit.PathIndex = 0
+	unit.Target = nil
+	unit.AttackTarget = nil
+	unit.GatherTarget = nil
+	unit.State = UnitStateIdle
+	unit.Position = building.Position
+	unit.LastUpdate = time.Now()
+
+	building.GarrisonedUnits = append(building.GarrisonedUnits, unit.ID)
+	return nil
+}
+
+// EjectUnit releases a single garrisoned unit next to the building
+func (ps *ProductionSystem) EjectUnit(buildingID int, unitID int) error {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+
+	building.mutex.Lock()
+	defer building.mutex.Unlock()
+
+	for i, garrisonedID := range building.GarrisonedUnits {
+		if garrisonedID == unitID {
+			building.GarrisonedUnits = append(building.GarrisonedUnits[:i], building.GarrisonedUnits[i+1:]...)
+			ps.releaseGarrisonedUnit(building, unitID)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unit %d is not garrisoned in building %d", unitID, buildingID)
+}
+
+// EjectAll releases every garrisoned unit from a building
+func (ps *ProductionSystem) EjectAll(buildingID int) error {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+
+	building.mutex.Lock()
+	defer building.mutex.Unlock()
+
+	for _, unitID := range building.GarrisonedUnits {
+		ps.releaseGarrisonedUnit(building, unitID)
+	}
+	building.GarrisonedUnits = building.GarrisonedUnits[:0]
+	return nil
+}
+
+// GetGarrisonedUnits returns the units currently sheltered in a building
+func (ps *ProductionSystem) GetGarrisonedUnits(buildingID int) ([]*GameUnit, error) {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return nil, fmt.Errorf("building %d not found", buildingID)
+	}
+
+	building.mutex.RLock()
+	defer building.mutex.RUnlock()
+
+	units := make([]*GameUnit, 0, len(building.GarrisonedUnits))
+	for _, unitID := range building.GarrisonedUnits {
+		if unit := ps.world.ObjectManager.GetUnit(unitID); unit != nil {
+			units = append(units, unit)
+		}
+	}
+	return units, nil
+}
+
+// releaseGarrisonedUnit places a garrisoned unit back on the map (building lock must be held)
+func (ps *ProductionSystem) releaseGarrisonedUnit(building *GameBuilding, unitID int) {
+	unit := ps.world.ObjectManager.GetUnit(unitID)
+	if unit == nil {
+		return
+	}
+
+	spawnPos := ps.findUnitSpawnPosition(building)
+	unit.UpdatePositions(spawnPos, ps.world.GetTileSize())
+
+	unit.mutex.Lock()
+	unit.GarrisonedIn = 0
+	gridPos := unit.GridPos.Grid
+	unit.mutex.Unlock()
+
+	ps.world.occupyFootprint(unit, gridPos)
+	ps.applyRallyPoint(building, unit)
+}
+
+// applyRallyPoint sends a unit to the building's gather or rally point (building lock must be held)
+func (ps *ProductionSystem) applyRallyPoint(building *GameBuilding, unit *GameUnit) {
+	if ps.world.commandProcessor == nil {
+		return
+	}
+
+	if building.RallyResource != nil && building.RallyResource.Amount > 0 {
+		ps.world.commandProcessor.IssueCommand(unit.ID, CreateGatherCommand(building.RallyResource, false))
+		return
+	}
+
+	if building.RallyPoint != nil {
+		ps.world.commandProcessor.IssueCommand(unit.ID, CreateMoveCommand(*building.RallyPoint, false))
+	}
+}
+
+// applyProductionGroup adds a produced unit to the building's control group
+// and army group; a unit joining an army group moves to its formation
+// position instead of the rally point (building lock must be held)
+func (ps *ProductionSystem) applyProductionGroup(building *GameBuilding, unit *GameUnit) {
+	if building.JoinControlGroup != 0 {
+		ps.world.controlGroups.Add(building.PlayerID, building.JoinControlGroup, unit)
+	}
+	if building.JoinArmyGroup == 0 {
+		return
+	}
+
+	// The group disbands once its last unit dies; stop reinforcing it then
+	if err := ps.world.groupMgr.AddUnitsToGroup(building.JoinArmyGroup, []*GameUnit{unit}); err != nil {
+		building.JoinArmyGroup = 0
+		return
+	}
+	group, _ := ps.world.groupMgr.GetGroup(building.JoinArmyGroup)
+	if position, ok := group.GetFormationPosition(unit.ID); ok && ps.world.commandProcessor != nil {
+		ps.world.commandProcessor.IssueCommand(unit.ID, CreateMoveCommand(position, false))
+	}
+}
+
+// requeueAutoProduction queues another copy of the last produced unit (building lock must be held)
+func (ps *ProductionSystem) requeueAutoProduction(building *GameBuilding) {
+	if building.LastProduced == nil {
+		return
+	}
+
+	// Population is checked again when the unit spawns
+	template := building.LastProduced
+	if len(template.Cost) > 0 {
+		if err := ps.world.DeductResources(building.PlayerID, template.Cost, "auto_production"); err != nil {
+			return // Wait until the player can afford it
+		}
+	}
+
+	building.ProductionQueue = append(building.ProductionQueue, ProductionItem{
+		ItemType:  template.ItemType,
+		ItemName:  template.ItemName,
+		Progress:  0.0,
+		Duration:  template.Duration,
+		Cost:      template.Cost,
+		StartTime: time.Time{}, // Will be set when production starts
+	})
+}
@@ -0,0 +1,315 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// createTestBuildingForControls creates a completed building owned by player 1
+// with room to garrison 5 units
+func createTestBuildingForControls(t *testing.T, world *World, buildingType string) *GameBuilding {
+	def := &data.UnitDefinition{Name: buildingType}
+	def.Unit.Parameters.MaxHP.Value = 1000
+	def.Unit.Parameters.Garrison = &data.UnitGarrison{Capacity: 5}
+
+	building, err := world.ObjectManager.CreateBuilding(1, buildingType, Vector3{X: 20, Y: 0, Z: 20}, def)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	building.IsBuilt = true
+	return building
+}
+
+// TestRallyPoint tests setting and clearing rally and gather points
+func TestRallyPoint(t *testing.T) {
+	world := createTestWorldForProduction(t)
+	ps := world.productionSys
+	building := createTestBuildingForControls(t, world, "barracks")
+
+	if err := ps.SetRallyPoint(building.ID, Vector3{X: 30, Y: 0, Z: 30}); err != nil {
+		t.Fatalf("SetRallyPoint failed: %v", err)
+	}
+	if building.RallyPoint == nil || building.RallyPoint.X != 30 {
+		t.Errorf("Expected rally point at X=30, got %v", building.RallyPoint)
+	}
+
+	node := &ResourceNode{ID: 500, ResourceType: "gold", Position: Vector3{X: 40, Z: 40}, Amount: 100}
+	world.resources[node.ID] = node
+	if err := ps.SetGatherPoint(building.ID, node.ID); err != nil {
+		t.Fatalf("SetGatherPoint failed: %v", err)
+	}
+	if building.RallyResource != node {
+		t.Error("Expected gather point to reference resource node")
+	}
+
+	if err := ps.SetGatherPoint(building.ID, 9999); err == nil {
+		t.Error("Expected error for unknown resource node")
+	}
+
+	if err := ps.ClearRallyPoint(building.ID); err != nil {
+		t.Fatalf("ClearRallyPoint failed: %v", err)
+	}
+	if building.RallyPoint != nil || building.RallyResource != nil {
+		t.Error("Expected rally point to be cleared")
+	}
+}
+
+// TestCancelQueuedProduction tests removing a specific queue entry with refund
+func TestCancelQueuedProduction(t *testing.T) {
+	world := createTestWorldForProduction(t)
+	ps := world.productionSys
+	building := createTestBuildingForControls(t, world, "barracks")
+
+	building.ProductionQueue = []ProductionItem{
+		{ItemType: "unit", ItemName: "swordman", Cost: map[string]int{"gold": 50}},
+		{ItemType: "unit", ItemName: "archer", Cost: map[string]int{"gold": 75}},
+	}
+	world.players[1].ResourcesGathered = make(map[string]int)
+	goldBefore := world.players[1].Resources["gold"]
+
+	if err := ps.CancelQueuedProduction(building.ID, 1); err != nil {
+		t.Fatalf("CancelQueuedProduction failed: %v", err)
+	}
+
+	if len(building.ProductionQueue) != 1 || building.ProductionQueue[0].ItemName != "swordman" {
+		t.Errorf("Expected only swordman left in queue, got %v", building.ProductionQueue)
+	}
+	if gold := world.players[1].Resources["gold"]; gold != goldBefore+75 {
+		t.Errorf("Expected gold refund to %d, got %d", goldBefore+75, gold)
+	}
+
+	if err := ps.CancelQueuedProduction(building.ID, 5); err == nil {
+		t.Error("Expected error for out of range queue index")
+	}
+}
+
+// TestGarrisonAndEject tests garrisoning units and ejecting them again
+func TestGarrisonAndEject(t *testing.T) {
+	world := createTestWorldForProduction(t)
+	ps := world.productionSys
+	building := createTestBuildingForControls(t, world, "barracks")
+
+	unitDef := &data.UnitDefinition{Name: "swordman"}
+	unitDef.Unit.Parameters.MaxHP.Value = 100
+	unit, err := world.ObjectManager.CreateUnit(1, "swordman", Vector3{X: 22, Y: 0, Z: 22}, unitDef)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+
+	if err := ps.GarrisonUnit(building.ID, unit.ID); err != nil {
+		t.Fatalf("GarrisonUnit failed: %v", err)
+	}
+	if unit.GarrisonedIn != building.ID {
+		t.Errorf("Expected unit garrisoned in %d, got %d", building.ID, unit.GarrisonedIn)
+	}
+	if err := ps.GarrisonUnit(building.ID, unit.ID); err == nil {
+		t.Error("Expected error garrisoning an already garrisoned unit")
+	}
+
+	garrisoned, err := ps.GetGarrisonedUnits(building.ID)
+	if err != nil || len(garrisoned) != 1 {
+		t.Fatalf("Expected 1 garrisoned unit, got %d (err %v)", len(garrisoned), err)
+	}
+
+	if err := ps.EjectUnit(building.ID, unit.ID); err != nil {
+		t.Fatalf("EjectUnit failed: %v", err)
+	}
+	if unit.GarrisonedIn != 0 {
+		t.Error("Expected unit to leave the garrison")
+	}
+	if len(building.GarrisonedUnits) != 0 {
+		t.Errorf("Expected empty garrison, got %v", building.GarrisonedUnits)
+	}
+	if err := ps.EjectUnit(building.ID, unit.ID); err == nil {
+		t.Error("Expected error ejecting a unit that is not garrisoned")
+	}
+}
+
+// TestGarrisonCapacity tests that the garrison capacity comes from the
+// building's definition and that buildings without one reject garrisons
+func TestGarrisonCapacity(t *testing.T) {
+	world := createTestWorldForProduction(t)
+	ps := world.productionSys
+	if barracks := createTestBuildingForControls(t, world, "barracks"); barracks.GarrisonCapacity != 5 {
+		t.Errorf("Expected the capacity of 5 from the definition, got %d", barracks.GarrisonCapacity)
+	}
+	building, err := world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 30, Z: 30}, data.NewSimpleUnit("castle", 2000, 0, "stone", nil))
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	building.IsBuilt = true
+	if building.GarrisonCapacity != 0 {
+		t.Errorf("Expected no garrison without one in the definition, got %d", building.GarrisonCapacity)
+	}
+
+	unitDef := &data.UnitDefinition{Name: "worker"}
+	unitDef.Unit.Parameters.MaxHP.Value = 50
+	unit, err := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 22, Y: 0, Z: 22}, unitDef)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+
+	if err := ps.GarrisonUnit(building.ID, unit.ID); err == nil {
+		t.Error("Expected garrison to fail for building with zero capacity")
+	}
+}
+
+// TestAutoProductionRequeue tests that an empty queue refills from the last produced item
+func TestAutoProductionRequeue(t *testing.T) {
+	world := createTestWorldForProduction(t)
+	ps := world.productionSys
+	building := createTestBuildingForControls(t, world, "barracks")
+
+	building.LastProduced = &ProductionItem{
+		ItemType: "unit",
+		ItemName: "swordman",
+		Duration: time.Hour,
+		Cost:     map[string]int{"gold": 100},
+	}
+	goldBefore := world.players[1].Resources["gold"]
+
+	// Disabled: nothing is queued
+	ps.processBuildingProductionQueue(building, 0)
+	if building.CurrentProduction != nil {
+		t.Fatal("Expected no production while auto-production is disabled")
+	}
+
+	if err := ps.SetAutoProduction(building.ID, true); err != nil {
+		t.Fatalf("SetAutoProduction failed: %v", err)
+	}
+	ps.processBuildingProductionQueue(building, 0)
+
+	if building.CurrentProduction == nil || building.CurrentProduction.ItemName != "swordman" {
+		t.Fatalf("Expected swordman auto-production, got %v", building.CurrentProduction)
+	}
+	if gold := world.players[1].Resources["gold"]; gold != goldBefore-100 {
+		t.Errorf("Expected gold %d after auto-production, got %d", goldBefore-100, gold)
+	}
+}
+
+// TestProductionGroup tests produced units joining a control group and an army group
+func TestProductionGroup(t *testing.T) {
+	world := createTestWorldForProduction(t)
+	ps := world.productionSys
+	building := createTestBuildingForControls(t, world, "barracks")
+	swordman := data.NewSimpleUnit("swordman", 100, 0, "leather", nil)
+	assets := data.NewMemoryAssetProvider()
+	assets.AddUnit("romans", swordman)
+	world.assetMgr = assets
+	world.players[1].FactionData = &data.FactionDefinition{Name: "romans"}
+
+	veteran, err := world.ObjectManager.CreateUnit(1, "swordman", Vector3{X: 40, Z: 40}, swordman)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	army, err := world.groupMgr.CreateGroup(1, []*GameUnit{veteran}, FormationLine)
+	if err != nil {
+		t.Fatalf("Failed to create army group: %v", err)
+	}
+
+	if err := ps.SetProductionGroup(building.ID, MaxControlGroup+1, 0); err == nil {
+		t.Error("Expected error for out of range control group")
+	}
+	if err := ps.SetProductionGroup(building.ID, 0, army.ID+1); err == nil {
+		t.Error("Expected error for unknown army group")
+	}
+	if err := ps.SetProductionGroup(building.ID, 3, army.ID); err != nil {
+		t.Fatalf("SetProductionGroup failed: %v", err)
+	}
+
+	ps.spawnUnit(building, &ProductionItem{ItemType: "unit", ItemName: "swordman"})
+	recruits := world.GetControlGroups().Units(world.ObjectManager, 1, 3)
+	if len(recruits) != 1 || recruits[0] == veteran {
+		t.Fatalf("Expected the new unit in control group 3, got %v", recruits)
+	}
+	if group, ok := world.groupMgr.GetUnitGroup(recruits[0].ID); !ok || group != army {
+		t.Error("Expected the new unit to join the army group")
+	}
+
+	// Reinforcing stops once the army group is gone
+	world.groupMgr.DisbandGroup(army.ID)
+	ps.spawnUnit(building, &ProductionItem{ItemType: "unit", ItemName: "swordman"})
+	if building.JoinArmyGroup != 0 {
+		t.Error("Expected the building to stop reinforcing a disbanded army group")
+	}
+	if recruits := world.GetControlGroups().Units(world.ObjectManager, 1, 3); len(recruits) != 2 {
+		t.Errorf("Expected 2 units in control group 3, got %d", len(recruits))
+	}
+
+	// Dead units drop out of control groups
+	recruits[0].Health = 0
+	if recruits := world.GetControlGroups().Units(world.ObjectManager, 1, 3); len(recruits) != 1 {
+		t.Errorf("Expected 1 living unit in control group 3, got %d", len(recruits))
+	}
+	if err := world.GetControlGroups().Add(2, 1, veteran); err == nil {
+		t.Error("Expected error adding another player's unit to a control group")
+	}
+}
+
+// TestGarrisonedUnitsSheltered tests that a garrisoned unit drops its orders
+// and can be neither ordered nor attacked until it is ejected
+func TestGarrisonedUnitsSheltered(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	castle := data.NewSimpleUnit("castle", 2000, 0, "stone", nil)
+	castle.Unit.Parameters.Garrison = &data.UnitGarrison{Capacity: 2}
+	building, err := world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 10.5, Z: 10.5}, castle)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	building.IsBuilt = true
+	guard, _ := world.ObjectManager.CreateUnit(1, "guard", Vector3{X: 12.5, Z: 10.5}, data.NewSimpleUnit("guard", 100, 0, "leather", nil))
+	raider, _ := world.ObjectManager.CreateUnit(2, "raider", Vector3{X: 13.5, Z: 10.5}, data.NewSimpleUnit("raider", 100, 0, "leather", nil))
+	raider.AttackDamage = 10
+	raider.AttackRange = 2
+
+	cp := world.commandProcessor
+	if err := cp.IssueCommand(raider.ID, CreateAttackCommand(guard, false)); err != nil {
+		t.Fatalf("Failed to order the attack: %v", err)
+	}
+	if err := cp.IssueCommand(guard.ID, CreateMoveCommand(Vector3{X: 25.5, Z: 25.5}, false)); err != nil {
+		t.Fatalf("Failed to order the move: %v", err)
+	}
+	cp.processMoveCommand(guard, guard.CurrentCommand, 10*time.Millisecond)
+	if len(guard.Path) == 0 {
+		t.Fatal("Expected the guard on its way")
+	}
+
+	if err := world.productionSys.GarrisonUnit(building.ID, guard.ID); err != nil {
+		t.Fatalf("GarrisonUnit failed: %v", err)
+	}
+	if guard.CurrentCommand != nil || guard.Path != nil {
+		t.Errorf("Expected the garrisoned guard to drop its order and path, got %+v", guard.CurrentCommand)
+	}
+	if err := cp.IssueCommand(guard.ID, CreateMoveCommand(Vector3{X: 25.5, Z: 25.5}, false)); err == nil {
+		t.Error("Expected orders to a garrisoned unit to be refused")
+	}
+	if err := cp.IssueCommand(raider.ID, CreateAttackCommand(guard, false)); err == nil {
+		t.Error("Expected attacks on a garrisoned unit to be refused")
+	}
+	if ok, reason := cp.combatSystem.CanAttack(raider, guard); ok || reason != "target is garrisoned" {
+		t.Errorf("Expected the garrisoned guard out of reach, got %v %q", ok, reason)
+	}
+	for _, unit := range cp.combatSystem.findUnitsInRadius(guard.Position, 5) {
+		if unit == guard {
+			t.Error("Expected splash damage to miss the garrisoned guard")
+		}
+	}
+
+	// The attack ordered before the guard went inside is called off
+	cp.processAttackCommand(raider, raider.CurrentCommand, 10*time.Millisecond)
+	if raider.CurrentCommand != nil {
+		t.Error("Expected the attack on the garrisoned guard to be called off")
+	}
+
+	if err := world.productionSys.EjectUnit(building.ID, guard.ID); err != nil {
+		t.Fatalf("EjectUnit failed: %v", err)
+	}
+	if err := cp.IssueCommand(raider.ID, CreateAttackCommand(guard, false)); err != nil {
+		t.Errorf("Expected the ejected guard open to attack again, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+)
+
+// Building power requirement
+const (
+	buildingUpkeepSource = "building_upkeep" // Transaction source of energy upkeep payments
+	powerShortageEnergy  = "out of energy"   // Shortage of a staffed building the player cannot pay upkeep for
+)
+
+// BuildingPower is the data of an EventTypeBuildingPower event, raised when a
+// building with a power requirement pauses or resumes production
+type BuildingPower struct {
+	BuildingID   int
+	BuildingType string
+	Position     Vector3
+	Powered      bool
+	Shortage     string // What is missing while unpowered, e.g. "needs 2 more workers"
+}
+
+// IsWorkerType reports whether units of a type can staff buildings
+func IsWorkerType(unitType string) bool {
+	switch unitType {
+	case "worker", "peasant", "initiate", "engineer", "master_builder":
+		return true
+	}
+	return false
+}
+
+// staffCount returns the workers garrisoned in a building (building lock must be held)
+func (ps *ProductionSystem) staffCount(building *GameBuilding) int {
+	staff := 0
+	for _, unitID := range building.GarrisonedUnits {
+		if unit := ps.world.ObjectManager.GetUnit(unitID); unit != nil && unit.IsAlive() && IsWorkerType(unit.UnitType) {
+			staff++
+		}
+	}
+	return staff
+}
+
+// StaffBuilding garrisons workers from units in a building until it has the
+// workers its power requirement asks for; it returns how many were assigned
+func (ps *ProductionSystem) StaffBuilding(buildingID int, units []*GameUnit) (int, error) {
+	building := ps.world.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return 0, fmt.Errorf("building %d not found", buildingID)
+	}
+
+	building.mutex.RLock()
+	missing := building.RequiredWorkers - ps.staffCount(building)
+	building.mutex.RUnlock()
+	if missing <= 0 {
+		return 0, fmt.Errorf("building %d needs no more workers", buildingID)
+	}
+
+	assigned := 0
+	for _, unit := range units {
+		if assigned == missing {
+			break
+		}
+		if !IsWorkerType(unit.UnitType) || !unit.IsAlive() {
+			continue
+		}
+		if err := ps.GarrisonUnit(buildingID, unit.ID); err != nil {
+			continue // Already inside a building or belongs to another player
+		}
+		assigned++
+	}
+	if assigned == 0 {
+		return 0, fmt.Errorf("no workers could be assigned to building %d", buildingID)
+	}
+	return assigned, nil
+}
+
+// updateBuildingPower checks a built building's power requirement, paying
+// its energy upkeep, and pauses or resumes its production when that changes.
+// It returns whether the building is powered.
+func (ps *ProductionSystem) updateBuildingPower(building *GameBuilding, deltaTime time.Duration) bool {
+	building.mutex.Lock()
+	if building.RequiredWorkers == 0 && building.EnergyUpkeep == 0 {
+		building.mutex.Unlock()
+		return true
+	}
+
+	shortage := ""
+	due := 0
+	if missing := building.RequiredWorkers - ps.staffCount(building); missing > 0 {
+		shortage = fmt.Sprintf("needs %d more workers", missing)
+	} else if building.EnergyUpkeep > 0 {
+		// Staffed buildings accrue upkeep and pay it in whole units
+		building.energyOwed += float64(building.EnergyUpkeep) * deltaTime.Minutes()
+		due = int(building.energyOwed)
+	}
+	playerID := building.PlayerID
+	building.mutex.Unlock()
+
+	// Pay outside the building lock so world and building locks are never held together
+	if due > 0 {
+		err := ps.world.DeductResources(playerID, map[string]int{"energy": due}, buildingUpkeepSource)
+		building.mutex.Lock()
+		if err != nil {
+			// Only the upkeep of this update stays owed until energy is available again
+			building.energyOwed -= float64(building.EnergyUpkeep) * deltaTime.Minutes()
+			shortage = powerShortageEnergy
+		} else {
+			building.energyOwed -= float64(due)
+		}
+		building.mutex.Unlock()
+	}
+
+	return ps.setBuildingPowered(building, shortage == "", shortage)
+}
+
+// setBuildingPowered pauses or resumes a building's production, raising an
+// event when its power state changes; it returns powered
+func (ps *ProductionSystem) setBuildingPowered(building *GameBuilding, powered bool, shortage string) bool {
+	now := ps.world.now()
+
+	building.mutex.Lock()
+	changed := building.Powered != powered
+	building.Powered, building.PowerShortage = powered, shortage
+	if changed && !powered {
+		building.UnpoweredSince = now
+	}
+	if changed && powered {
+		// Move the start of paused work forward so its progress continues where it stopped
+		paused := now.Sub(building.UnpoweredSince)
+		if building.CurrentProduction != nil {
+			building.CurrentProduction.StartTime = building.CurrentProduction.StartTime.Add(paused)
+		}
+		if building.CurrentUpgrade != nil {
+			building.CurrentUpgrade.StartTime = building.CurrentUpgrade.StartTime.Add(paused)
+		}
+		building.UnpoweredSince = time.Time{}
+	}
+	power := BuildingPower{
+		BuildingID:   building.ID,
+		BuildingType: building.BuildingType,
+		Position:     building.Position,
+		Powered:      powered,
+		Shortage:     shortage,
+	}
+	building.mutex.Unlock()
+
+	if changed {
+		message := fmt.Sprintf("%s resumed production", power.BuildingType)
+		if !powered {
+			message = fmt.Sprintf("%s paused production: %s", power.BuildingType, shortage)
+		}
+		ps.world.raiseEvent(GameEvent{
+			Type:      EventTypeBuildingPower,
+			Timestamp: now,
+			PlayerID:  building.PlayerID,
+			Data:      power,
+			Message:   message,
+		})
+	}
+	return powered
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestBuildingPowerRequirement tests that a building pauses production until
+// it is staffed and resumes it while its energy upkeep is paid
+func TestBuildingPowerRequirement(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+	var events []GameEvent
+	world.SetEventSink(func(event GameEvent) {
+		if event.Type == EventTypeBuildingPower {
+			events = append(events, event)
+		}
+	})
+
+	def := data.NewSimpleUnit("workshop", 600, 0, "stone", nil)
+	def.Unit.Parameters.PowerRequirement = &data.UnitPowerRequirement{Workers: 2, Energy: 60}
+	workshop, err := world.ObjectManager.CreateBuilding(1, "workshop", Vector3{X: 8, Z: 8}, def)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	workshop.IsBuilt = true
+	if workshop.GarrisonCapacity < 2 {
+		t.Errorf("Expected room for the 2 required workers, capacity is %d", workshop.GarrisonCapacity)
+	}
+
+	productionSys := world.GetProductionSystem()
+	if err := productionSys.IssueProductionCommand(workshop.ID, "soldier", nil, 10*time.Second); err != nil {
+		t.Fatalf("Failed to issue production command: %v", err)
+	}
+	step := func(seconds int) {
+		for i := 0; i < seconds; i++ {
+			clock.Advance(time.Second)
+			productionSys.ProcessBuildingProduction(time.Second)
+		}
+	}
+
+	// Without workers the building pauses and its work does not progress
+	step(5)
+	if workshop.Powered || len(events) != 1 || events[0].Data.(BuildingPower).Shortage != "needs 2 more workers" {
+		t.Fatalf("Expected the unstaffed workshop to pause for 2 workers, got powered=%v, events %v", workshop.Powered, events)
+	}
+	if workshop.CurrentProduction != nil || len(workshop.ProductionQueue) != 1 {
+		t.Fatalf("Expected production to wait in the queue, got %+v", workshop.CurrentProduction)
+	}
+
+	worker := data.NewSimpleUnit("worker", 50, 0, "leather", nil)
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	var units []*GameUnit
+	for _, unitType := range []string{"soldier", "worker", "worker", "worker"} {
+		unitDef := worker
+		if unitType == "soldier" {
+			unitDef = soldier
+		}
+		unit, err := world.ObjectManager.CreateUnit(1, unitType, Vector3{X: 4, Z: 4}, unitDef)
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		units = append(units, unit)
+	}
+	assigned, err := productionSys.StaffBuilding(workshop.ID, units)
+	if err != nil || assigned != 2 {
+		t.Fatalf("Expected 2 workers assigned, got %d (%v)", assigned, err)
+	}
+	if units[0].GarrisonedIn != 0 || units[3].GarrisonedIn != 0 {
+		t.Error("Expected the soldier and the spare worker to stay outside")
+	}
+
+	// Staffed, it resumes and pays 1 energy per second
+	energy := world.GetPlayer(1).Resources["energy"]
+	step(5)
+	if !workshop.Powered || len(events) != 2 || !events[1].Data.(BuildingPower).Powered {
+		t.Fatalf("Expected the staffed workshop to resume, got powered=%v, events %v", workshop.Powered, events)
+	}
+	if paid := energy - world.GetPlayer(1).Resources["energy"]; paid != 5 {
+		t.Errorf("Expected 5 energy upkeep, paid %d", paid)
+	}
+	if workshop.CurrentProduction == nil || workshop.CurrentProduction.Progress < 0.39 || workshop.CurrentProduction.Progress > 0.41 {
+		t.Fatalf("Expected production to start on the first powered update and reach 40%%, got %+v", workshop.CurrentProduction)
+	}
+
+	// Running out of energy pauses it again
+	world.GetPlayer(1).Resources["energy"] = 0
+	step(2)
+	if workshop.Powered || workshop.PowerShortage != powerShortageEnergy {
+		t.Errorf("Expected the workshop to pause out of energy, got powered=%v (%s)", workshop.Powered, workshop.PowerShortage)
+	}
+	progress := workshop.CurrentProduction.Progress
+	world.GetPlayer(1).Resources["energy"] = 100
+	step(1)
+	if workshop.CurrentProduction.Progress-progress > 0.11 {
+		t.Errorf("Expected production to continue from where it paused, went from %.2f to %.2f", progress, workshop.CurrentProduction.Progress)
+	}
+	step(5)
+	if workshop.CurrentProduction != nil {
+		t.Errorf("Expected production to finish once energy returned, progress %.2f", workshop.CurrentProduction.Progress)
+	}
+}
+
+// TestAIStaffsBuildings tests that the AI assigns idle workers to buildings needing them
+func TestAIStaffsBuildings(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	def := data.NewSimpleUnit("workshop", 600, 0, "stone", nil)
+	def.Unit.Parameters.PowerRequirement = &data.UnitPowerRequirement{Workers: 1}
+	workshop, err := world.ObjectManager.CreateBuilding(2, "workshop", Vector3{X: 8, Z: 8}, def)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	workshop.IsBuilt = true
+	worker := data.NewSimpleUnit("worker", 50, 0, "leather", nil)
+	for i := 0; i < 2; i++ {
+		if _, err := world.ObjectManager.CreateUnit(2, "worker", Vector3{X: 4, Z: 4}, worker); err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+	}
+
+	manager := NewEconomicManager(2, world, nil)
+	manager.staffBuildings()
+	if len(workshop.GarrisonedUnits) != 1 {
+		t.Fatalf("Expected the AI to assign one worker, got %v", workshop.GarrisonedUnits)
+	}
+	manager.staffBuildings()
+	if len(workshop.GarrisonedUnits) != 1 {
+		t.Errorf("Expected no more workers once staffed, got %v", workshop.GarrisonedUnits)
+	}
+}
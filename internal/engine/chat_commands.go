@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChatCommandPrefix starts a chat line that is a command rather than a message
+const ChatCommandPrefix = "/"
+
+// Chat commands a player can type
+const (
+	ChatCommandSurrender = "surrender" // Concede the match
+)
+
+// chatCommandAliases maps alternative spellings to chat commands
+var chatCommandAliases = map[string]string{
+	"gg": ChatCommandSurrender,
+	"ff": ChatCommandSurrender,
+}
+
+// ParseChatCommand returns the command a chat line gives and its arguments;
+// ok is false for ordinary messages
+func ParseChatCommand(line string) (command string, args []string, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, ChatCommandPrefix) {
+		return "", nil, false
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, ChatCommandPrefix))
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	command = strings.ToLower(fields[0])
+	if alias, exists := chatCommandAliases[command]; exists {
+		command = alias
+	}
+	return command, fields[1:], true
+}
+
+// RunChatCommand carries out a chat command typed by a player. Players
+// sharing another's faction cannot surrender it for them.
+func (w *World) RunChatCommand(playerID int, command string, args []string) error {
+	switch command {
+	case ChatCommandSurrender:
+		if w.CommandedPlayer(playerID) != playerID {
+			return fmt.Errorf("only the faction's owner can surrender it")
+		}
+		return w.Surrender(playerID)
+	default:
+		return fmt.Errorf("unknown command %s%s", ChatCommandPrefix, command)
+	}
+}
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock supplies the wall-clock time used for production progress, attack
+// cooldowns and timestamps, so tests can control it
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock reads the system time
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// ManualClock is a Clock that only moves when advanced, for deterministic tests
+type ManualClock struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+// NewManualClock creates a manual clock starting at start
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the current manual time
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// SetClock replaces the world's clock; nil restores the system clock
+func (w *World) SetClock(clock Clock) {
+	if clock == nil {
+		clock = realClock{}
+	}
+	w.clock = clock
+}
+
+// now returns the world clock's current time
+func (w *World) now() time.Time {
+	if w == nil || w.clock == nil {
+		return time.Now()
+	}
+	return w.clock.Now()
+}
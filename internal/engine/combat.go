@@ -3,18 +3,25 @@ package engine
 import (
 	"fmt"
 	"math"
+	"math/rand"
 	"time"
 )
 
 // CombatSystem handles all combat-related calculations and mechanics
 type CombatSystem struct {
 	world *World
+	rng   *rand.Rand // Hit rolls; seeded so every peer rolls the same
+
+	rngSource *countingSource // rng's source, which savegames record
 }
 
 // NewCombatSystem creates a new combat system instance
 func NewCombatSystem(world *World) *CombatSystem {
+	source := newCountingSource(1)
 	return &CombatSystem{
-		world: world,
+		world:     world,
+		rng:       rand.New(source),
+		rngSource: source,
 	}
 }
 
@@ -22,7 +29,8 @@ func NewCombatSystem(world *World) *CombatSystem {
 type CombatResult struct {
 	Damage        int     // Final damage dealt
 	BaseDamage    int     // Base damage before modifiers
-	Multiplier    float64 // Attack vs armor type multiplier
+	Multiplier    float64 // Attack vs armor type multiplier, high ground included
+	Elevation     int     // Height steps the attacker stands above the target
 	AttackType    string  // Type of attack used
 	ArmorType     string  // Type of armor defending
 	WasKilled     bool    // Whether target was killed
@@ -73,9 +81,11 @@ func (cs *CombatSystem) CalculateDamage(attacker, target *GameUnit) CombatResult
 	result.ArmorType = armorType
 	result.BaseDamage = attacker.AttackDamage
 
-	// Get damage multiplier from tech tree
+	// Get damage multiplier from tech tree, then for the high ground
 	multiplier := cs.world.techTree.GetDamageMultiplier(attackType, armorType)
+	multiplier *= cs.elevationMultiplier(attacker.Position, target.Position)
 	result.Multiplier = multiplier
+	result.Elevation = cs.world.Elevation(attacker.Position, target.Position)
 
 	// Calculate final damage
 	finalDamage := float64(attacker.AttackDamage) * multiplier
@@ -95,17 +105,40 @@ func (cs *CombatSystem) CalculateDamage(attacker, target *GameUnit) CombatResult
 	return result
 }
 
+// CalculateBuildingDamage calculates the damage a unit deals to a building
+// with one attack: the armor model of unit targets, with siege attacks
+// getting a bonus against structures and all other attacks a penalty
+func (cs *CombatSystem) CalculateBuildingDamage(attacker *GameUnit, target *GameBuilding) int {
+	attackType := cs.getAttackType(attacker)
+	multiplier := cs.structureMultiplier(attackType) * cs.elevationMultiplier(attacker.Position, target.Position)
+	if cs.world.techTree != nil {
+		multiplier *= cs.world.techTree.GetDamageMultiplier(attackType, target.ArmorType)
+	}
+	damage := float64(attacker.AttackDamage)*multiplier - float64(target.Armor)
+	if damage < 1 {
+		damage = 1 // Minimum 1 damage
+	}
+	return int(math.Round(damage))
+}
+
 // ApplyDamage applies damage to a target unit and handles death
 func (cs *CombatSystem) ApplyDamage(target *GameUnit, damage int) bool {
 	if target == nil || !target.IsAlive() {
 		return false
 	}
+	damage = cs.world.modsDamage(target, damage)
 
 	target.mutex.Lock()
 	defer target.mutex.Unlock()
 
 	// Apply damage
 	target.Health -= damage
+	cs.world.reportAttack(target.PlayerID, AttackAlert{
+		TargetID:   target.ID,
+		TargetType: target.UnitType,
+		Position:   target.Position,
+		Damage:     damage,
+	})
 	if target.Health <= 0 {
 		target.Health = 0
 		target.State = UnitStateDead
@@ -118,6 +151,36 @@ func (cs *CombatSystem) ApplyDamage(target *GameUnit, damage int) bool {
 	return false // Unit survived
 }
 
+// ApplyBuildingDamage applies damage to a building; it returns true when the building is destroyed
+func (cs *CombatSystem) ApplyBuildingDamage(target *GameBuilding, damage int) bool {
+	if target == nil || !target.IsAlive() {
+		return false
+	}
+
+	target.mutex.Lock()
+	target.Health -= damage
+	destroyed := target.Health <= 0
+	if destroyed {
+		target.Health = 0
+	}
+	target.mutex.Unlock()
+
+	cs.world.reportAttack(target.PlayerID, AttackAlert{
+		TargetID:   target.ID,
+		TargetType: target.BuildingType,
+		IsBuilding: true,
+		Position:   target.Position,
+		Damage:     damage,
+	})
+	if destroyed {
+		// The rubble no longer blocks units
+		cs.world.releaseBuildingTile(target.Position)
+		cs.world.markBuildingDestroyed(target)
+		cs.world.handleBuildingDestroyed(target)
+	}
+	return destroyed
+}
+
 // CanAttack checks if an attacker can attack a target (range, line of sight, etc.)
 func (cs *CombatSystem) CanAttack(attacker, target *GameUnit) (bool, string) {
 	if attacker == nil || target == nil {
@@ -132,6 +195,14 @@ func (cs *CombatSystem) CanAttack(attacker, target *GameUnit) (bool, string) {
 		return false, "target is dead"
 	}
 
+	// Garrisoned units are sheltered inside their building
+	if attacker.IsGarrisoned() {
+		return false, "attacker is garrisoned"
+	}
+	if target.IsGarrisoned() {
+		return false, "target is garrisoned"
+	}
+
 	// Same player check
 	if attacker.PlayerID == target.PlayerID {
 		return false, "cannot attack same player units"
@@ -170,7 +241,7 @@ func (cs *CombatSystem) ExecuteAttack(attacker, target *GameUnit) CombatResult {
 	result.WasKilled = killed
 
 	// Update attacker's last attack time
-	attacker.LastAttack = time.Now()
+	attacker.LastAttack = cs.world.now()
 
 	// Create combat event for logging/statistics
 	cs.logCombatEvent(attacker, target, result)
@@ -216,7 +287,7 @@ func (cs *CombatSystem) canAttackNow(unit *GameUnit) bool {
 	}
 
 	// Calculate time since last attack
-	timeSinceLastAttack := time.Since(unit.LastAttack)
+	timeSinceLastAttack := cs.world.now().Sub(unit.LastAttack)
 
 	// Convert attack speed to cooldown duration
 	// AttackSpeed is attacks per second, so cooldown = 1/AttackSpeed seconds
@@ -225,6 +296,14 @@ func (cs *CombatSystem) canAttackNow(unit *GameUnit) bool {
 	return timeSinceLastAttack >= cooldownDuration
 }
 
+// CanAttackNow reports whether a unit's attack cooldown has passed
+func (w *World) CanAttackNow(unit *GameUnit) bool {
+	if w.commandProcessor == nil || w.commandProcessor.combatSystem == nil {
+		return true
+	}
+	return w.commandProcessor.combatSystem.canAttackNow(unit)
+}
+
 // hasLineOfSight performs line of sight checking using Bresenham's line algorithm
 func (cs *CombatSystem) hasLineOfSight(attacker, target *GameUnit) bool {
 	attackerGrid := attacker.GetGridPosition()
@@ -438,9 +517,8 @@ func (cs *CombatSystem) handleUnitDeath(unit *GameUnit) {
 	// Cancel any buildings this unit was constructing
 	cs.handleConstructionCancellation(unit)
 
-	// Free up the grid position
-	gridPos := unit.GetGridPosition()
-	cs.world.SetOccupied(gridPos.Grid, false)
+	// Free up the tiles it covered (ApplyDamage holds the unit lock, so read it directly)
+	cs.world.releaseFootprint(unit, unit.GridPos.Grid)
 
 	// Update player statistics
 	player := cs.world.GetPlayer(unit.PlayerID)
@@ -490,9 +568,7 @@ func (cs *CombatSystem) handleConstructionCancellation(unit *GameUnit) {
 		building.Health = 0 // Mark as destroyed
 
 		// Free up the building's grid position
-		buildingGrid := WorldToGrid(building.Position, cs.world.GetTileSize())
-		cs.world.SetOccupied(buildingGrid.Grid, false)
-		cs.world.SetWalkable(buildingGrid.Grid, true)
+		cs.world.releaseBuildingTile(building.Position)
 
 		// Remove building from ObjectManager
 		cs.world.ObjectManager.RemoveBuilding(building.ID)
@@ -0,0 +1,171 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"teraglest/internal/logging"
+)
+
+// LatencySettings configure the artificial delay added to player commands,
+// for testing how the game feels under lockstep latency. The zero value
+// adds no delay.
+type LatencySettings struct {
+	Delay  time.Duration // Average delay before a command runs
+	Jitter time.Duration // Maximum random deviation from Delay, either way
+	Seed   int64         // Random seed, so a run can be repeated
+}
+
+// Enabled reports whether the settings delay commands at all
+func (ls LatencySettings) Enabled() bool {
+	return ls.Delay > 0 || ls.Jitter > 0
+}
+
+// Validate checks that the settings are usable
+func (ls LatencySettings) Validate() error {
+	if ls.Delay < 0 || ls.Jitter < 0 {
+		return fmt.Errorf("command delay and jitter must not be negative")
+	}
+	return nil
+}
+
+// LatencySampler draws command delays for a set of latency settings. Like a
+// lockstep network it never lets a command overtake an earlier one, however
+// the jitter falls.
+type LatencySampler struct {
+	settings LatencySettings
+	rng      *rand.Rand
+	lastDue  time.Duration
+}
+
+// NewLatencySampler creates a sampler for the given settings
+func NewLatencySampler(settings LatencySettings) *LatencySampler {
+	return &LatencySampler{settings: settings, rng: rand.New(rand.NewSource(settings.Seed))}
+}
+
+// Due returns when a command submitted at now should run
+func (ls *LatencySampler) Due(now time.Duration) time.Duration {
+	delay := ls.settings.Delay
+	if jitter := ls.settings.Jitter; jitter > 0 {
+		delay += time.Duration(ls.rng.Int63n(int64(2*jitter)+1)) - jitter
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	due := now + delay
+	if due < ls.lastDue {
+		due = ls.lastDue
+	}
+	ls.lastDue = due
+	return due
+}
+
+// delayedCommand is a player command waiting out its artificial delay
+type delayedCommand struct {
+	heldCommand
+	due time.Duration // Game time at which the command runs
+}
+
+// commandLatency holds player commands back by the configured latency
+type commandLatency struct {
+	mutex    sync.Mutex
+	settings LatencySettings
+	sampler  *LatencySampler
+	commands []delayedCommand // In the order they are due
+}
+
+// SetLatency makes the processor delay commands submitted with SubmitCommand
+// and SubmitBuildingCommand. Commands already waiting keep their delay.
+func (cp *CommandProcessor) SetLatency(settings LatencySettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	cp.latency.mutex.Lock()
+	defer cp.latency.mutex.Unlock()
+	sampler := NewLatencySampler(settings)
+	if cp.latency.sampler != nil {
+		sampler.lastDue = cp.latency.sampler.lastDue // New commands still queue behind waiting ones
+	}
+	cp.latency.settings, cp.latency.sampler = settings, sampler
+	return nil
+}
+
+// Latency returns the current command latency settings
+func (cp *CommandProcessor) Latency() LatencySettings {
+	cp.latency.mutex.Lock()
+	defer cp.latency.mutex.Unlock()
+	return cp.latency.settings
+}
+
+// DelayedCommandCount returns the number of commands waiting out their delay
+func (cp *CommandProcessor) DelayedCommandCount() int {
+	cp.latency.mutex.Lock()
+	defer cp.latency.mutex.Unlock()
+	return len(cp.latency.commands)
+}
+
+// SubmitCommand issues a command a player gave to a unit, after the
+// configured latency. Without latency it is IssueCommand; with it, the
+// command is validated when it runs and only a missing unit is reported now.
+func (cp *CommandProcessor) SubmitCommand(unitID int, command UnitCommand) error {
+	if cp.world.ObjectManager.GetUnit(unitID) == nil {
+		return fmt.Errorf("unit %d not found", unitID)
+	}
+	if cp.delay(heldCommand{targetID: unitID, command: command}) {
+		return nil
+	}
+	return cp.IssueCommand(unitID, command)
+}
+
+// SubmitBuildingCommand issues a command a player gave to a building, after
+// the configured latency
+func (cp *CommandProcessor) SubmitBuildingCommand(buildingID int, command UnitCommand) error {
+	if cp.world.ObjectManager.GetBuilding(buildingID) == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+	if cp.delay(heldCommand{targetID: buildingID, building: true, command: command}) {
+		return nil
+	}
+	return cp.IssueBuildingCommand(buildingID, command)
+}
+
+// delay keeps a command until its latency has passed; it reports false when
+// no latency is configured and the command should run now
+func (cp *CommandProcessor) delay(command heldCommand) bool {
+	now := cp.world.GetGameTime()
+	cp.latency.mutex.Lock()
+	defer cp.latency.mutex.Unlock()
+	if !cp.latency.settings.Enabled() {
+		return false
+	}
+	due := cp.latency.sampler.Due(now)
+	cp.latency.commands = append(cp.latency.commands, delayedCommand{heldCommand: command, due: due})
+	return true
+}
+
+// releaseDelayed issues the delayed commands whose time has come, in order
+func (cp *CommandProcessor) releaseDelayed() {
+	now := cp.world.GetGameTime()
+	cp.latency.mutex.Lock()
+	count := 0
+	for count < len(cp.latency.commands) && cp.latency.commands[count].due <= now {
+		count++
+	}
+	due := append([]delayedCommand(nil), cp.latency.commands[:count]...)
+	cp.latency.commands = cp.latency.commands[count:]
+	cp.latency.mutex.Unlock()
+
+	for _, delayed := range due {
+		var err error
+		if delayed.building {
+			err = cp.IssueBuildingCommand(delayed.targetID, delayed.command)
+		} else {
+			err = cp.IssueCommand(delayed.targetID, delayed.command)
+		}
+		if err != nil {
+			logging.Debugf(logging.CategoryEngine, "Delayed command for %d failed: %v", delayed.targetID, err)
+		}
+	}
+}
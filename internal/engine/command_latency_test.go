@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestCommandLatency tests that submitted commands wait out the configured
+// delay and jitter and still run in the order they were given
+func TestCommandLatency(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 1, Z: 1}, soldier)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	processor := world.commandProcessor
+
+	if err := processor.SetLatency(LatencySettings{Delay: -time.Millisecond}); err == nil {
+		t.Error("Expected negative latency to be rejected")
+	}
+
+	// Without latency a submitted command runs at once
+	if err := processor.SubmitCommand(unit.ID, CreateMoveCommand(Vector3{X: 2, Z: 1}, false)); err != nil {
+		t.Fatalf("SubmitCommand failed: %v", err)
+	}
+	if unit.CurrentCommand == nil || processor.DelayedCommandCount() != 0 {
+		t.Fatal("Expected the command to run at once without latency")
+	}
+
+	settings := LatencySettings{Delay: 200 * time.Millisecond, Jitter: 100 * time.Millisecond, Seed: 7}
+	if err := processor.SetLatency(settings); err != nil {
+		t.Fatalf("SetLatency failed: %v", err)
+	}
+	if processor.Latency() != settings {
+		t.Errorf("Expected latency %+v, got %+v", settings, processor.Latency())
+	}
+	if err := processor.SubmitCommand(9999, CreateMoveCommand(Vector3{}, false)); err == nil {
+		t.Error("Expected a command for a missing unit to fail at once")
+	}
+
+	targets := []Vector3{{X: 5, Z: 5}, {X: 6, Z: 5}, {X: 7, Z: 5}, {X: 8, Z: 5}}
+	for _, target := range targets {
+		if err := processor.SubmitCommand(unit.ID, CreateMoveCommand(target, true)); err != nil {
+			t.Fatalf("SubmitCommand failed: %v", err)
+		}
+		world.Update(20 * time.Millisecond)
+	}
+	if len(unit.CommandQueue) != 0 || processor.DelayedCommandCount() != len(targets) {
+		t.Fatalf("Expected all commands delayed before the minimum latency, got queue %d and %d delayed",
+			len(unit.CommandQueue), processor.DelayedCommandCount())
+	}
+
+	for step := 0; step < 20; step++ {
+		world.Update(20 * time.Millisecond)
+	}
+	if processor.DelayedCommandCount() != 0 {
+		t.Fatalf("Expected every command to run after the maximum latency, %d still delayed", processor.DelayedCommandCount())
+	}
+	var order []Vector3
+	if unit.CurrentCommand != nil && unit.CurrentCommand.Target != nil && *unit.CurrentCommand.Target != (Vector3{X: 2, Z: 1}) {
+		order = append(order, *unit.CurrentCommand.Target)
+	}
+	for _, command := range unit.CommandQueue {
+		order = append(order, *command.Target)
+	}
+	if len(order) == 0 {
+		t.Fatal("Expected the delayed commands to be running or queued")
+	}
+	for i := range order {
+		if offset := len(targets) - len(order); order[i] != targets[offset+i] {
+			t.Errorf("Expected commands in the order given, got %+v", order)
+			break
+		}
+	}
+
+	// However wild the jitter, a command never overtakes an earlier one
+	sampler := NewLatencySampler(LatencySettings{Delay: time.Millisecond, Jitter: time.Second, Seed: 3})
+	last := time.Duration(0)
+	for now := time.Duration(0); now < time.Second; now += 10 * time.Millisecond {
+		due := sampler.Due(now)
+		if due < last || due < now {
+			t.Fatalf("Command due at %v after one due at %v (submitted at %v)", due, last, now)
+		}
+		last = due
+	}
+}
@@ -21,6 +21,7 @@ type UnitCommand struct {
 	StartedAt   time.Time       `json:"started_at"`
 	Priority    int             `json:"priority"`
 	IsQueued    bool            `json:"is_queued"`
+	Path        []Vector3       `json:"path,omitempty"`  // Way planned for a move by a group order
 }
 
 // CommandType represents different types of commands
@@ -50,6 +51,8 @@ type CommandProcessor struct {
 	combatSystem    *AdvancedCombatSystem
 	statusEffectMgr *StatusEffectManager
 	visualSystem    *CombatVisualSystem
+	held            commandHold    // Commands issued while the game is paused
+	latency         commandLatency // Player commands waiting out an artificial delay
 }
 
 // NewCommandProcessor creates a new command processor
@@ -69,18 +72,35 @@ func NewCommandProcessor(world *World) *CommandProcessor {
 
 // IssueCommand issues a command to a unit
 func (cp *CommandProcessor) IssueCommand(unitID int, command UnitCommand) error {
+	return cp.issueCommand(unitID, command, true)
+}
+
+// issueCommand issues a command to a unit, recording it in the command log
+// unless it was already recorded when it was held during a pause
+func (cp *CommandProcessor) issueCommand(unitID int, command UnitCommand, logged bool) (err error) {
+	playerID := 0
+	if logged {
+		defer func() { cp.world.commandLog.add(playerID, unitID, false, command, err) }()
+	}
+
 	unit := cp.world.ObjectManager.GetUnit(unitID)
 	if unit == nil {
 		return fmt.Errorf("unit %d not found", unitID)
 	}
+	playerID = unit.PlayerID
 
-	command.CreatedAt = time.Now()
+	command.CreatedAt = cp.world.now()
 
 	// Validate command based on unit capabilities
 	if err := cp.validateCommand(unit, command); err != nil {
 		return fmt.Errorf("invalid command: %w", err)
 	}
 
+	// While paused the command waits, and is validated again when it runs
+	if cp.hold(heldCommand{targetID: unitID, command: command}) {
+		return nil
+	}
+
 	unit.mutex.Lock()
 	defer unit.mutex.Unlock()
 
@@ -89,7 +109,8 @@ func (cp *CommandProcessor) IssueCommand(unitID int, command UnitCommand) error
 		// Add to queue
 		unit.CommandQueue = append(unit.CommandQueue, command)
 	} else {
-		// Replace current command
+		// Replace current command; a swing in progress is lost
+		unit.cancelWindUp()
 		unit.CurrentCommand = &command
 		unit.CommandQueue = []UnitCommand{} // Clear queue if not queuing
 		cp.startCommand(unit, &command)
@@ -100,12 +121,27 @@ func (cp *CommandProcessor) IssueCommand(unitID int, command UnitCommand) error
 
 // IssueBuildingCommand issues a command to a building
 func (cp *CommandProcessor) IssueBuildingCommand(buildingID int, command UnitCommand) error {
+	return cp.issueBuildingCommand(buildingID, command, true)
+}
+
+// issueBuildingCommand issues a command to a building, recording it in the
+// command log unless it was already recorded when it was held
+func (cp *CommandProcessor) issueBuildingCommand(buildingID int, command UnitCommand, logged bool) (err error) {
+	playerID := 0
+	if logged {
+		defer func() { cp.world.commandLog.add(playerID, buildingID, true, command, err) }()
+	}
+
 	building := cp.world.ObjectManager.GetBuilding(buildingID)
 	if building == nil {
 		return fmt.Errorf("building %d not found", buildingID)
 	}
+	playerID = building.PlayerID
 
-	command.CreatedAt = time.Now()
+	command.CreatedAt = cp.world.now()
+	if cp.hold(heldCommand{targetID: buildingID, building: true, command: command}) {
+		return nil
+	}
 
 	building.mutex.Lock()
 	defer building.mutex.Unlock()
@@ -163,7 +199,7 @@ func (cp *CommandProcessor) ProcessCommand(unit *GameUnit, command *UnitCommand,
 
 	// Mark as started if not already
 	if command.StartedAt.IsZero() {
-		command.StartedAt = time.Now()
+		command.StartedAt = cp.world.now()
 		cp.startCommand(unit, command)
 	}
 
@@ -209,25 +245,30 @@ func (cp *CommandProcessor) Update(deltaTime time.Duration) {
 			if unit.CurrentCommand != nil {
 				cp.ProcessCommand(unit, unit.CurrentCommand, deltaTime)
 			}
+			if unit.IsAlive() {
+				cp.turnUnit(unit, deltaTime)
+			}
 			// Process command queue progression
 			unit.processCommandQueue()
 		}
-
-		// Process building production/upgrade commands for this player
-		playerBuildings := cp.world.ObjectManager.GetBuildingsForPlayer(player.ID)
-		for _, building := range playerBuildings {
-			cp.processBuildingCommands(building, deltaTime)
-		}
 	}
+
+	// Towers and other defensive buildings fire at enemies in range
+	cp.updateDefensiveBuildings(allPlayers)
 }
 
 // UpdateWithPlayers processes commands with players already available (avoids nested locking)
 func (cp *CommandProcessor) UpdateWithPlayers(deltaTime time.Duration, players map[int]*Player) {
-	// Process all active unit commands for all players
-	for _, player := range players {
+	// Run the player commands whose artificial delay has passed
+	cp.releaseDelayed()
+
+	// Process all active unit commands for all players, in ID order so
+	// units contending for a tile resolve the same way on every machine
+	for _, playerID := range sortedKeys(players) {
 		// Get units for this player
-		playerUnits := cp.world.ObjectManager.GetUnitsForPlayer(player.ID)
-		for _, unit := range playerUnits {
+		playerUnits := cp.world.ObjectManager.GetUnitsForPlayer(playerID)
+		for _, unitID := range sortedKeys(playerUnits) {
+			unit := playerUnits[unitID]
 			// Process health regeneration for living units
 			if unit.IsAlive() {
 				cp.combatSystem.RegenerateHealth(unit, deltaTime)
@@ -237,136 +278,16 @@ func (cp *CommandProcessor) UpdateWithPlayers(deltaTime time.Duration, players m
 			if unit.CurrentCommand != nil {
 				cp.ProcessCommand(unit, unit.CurrentCommand, deltaTime)
 			}
+			if unit.IsAlive() {
+				cp.turnUnit(unit, deltaTime)
+			}
 			// Process command queue progression
 			unit.processCommandQueue()
 		}
-
-		// Process building commands for this player
-		playerBuildings := cp.world.ObjectManager.GetBuildingsForPlayer(player.ID)
-		for _, building := range playerBuildings {
-			cp.processBuildingCommands(building, deltaTime)
-		}
-	}
-}
-
-// processBuildingCommands handles building production and upgrade processing
-func (cp *CommandProcessor) processBuildingCommands(building *GameBuilding, deltaTime time.Duration) {
-	building.mutex.Lock()
-	defer building.mutex.Unlock()
-
-	// Process ongoing production
-	if building.CurrentProduction != nil {
-		cp.processUnitProduction(building, deltaTime)
-	}
-
-	// Process ongoing upgrades
-	if building.CurrentUpgrade != nil {
-		cp.processUpgradeProgress(building, deltaTime)
-	}
-}
-
-// processUnitProduction handles unit production progress for buildings
-func (cp *CommandProcessor) processUnitProduction(building *GameBuilding, deltaTime time.Duration) {
-	if building.CurrentProduction == nil {
-		return
-	}
-
-	production := building.CurrentProduction
-	production.Progress += float32(deltaTime.Seconds()) / float32(production.Duration.Seconds())
-
-	if production.Progress >= 1.0 {
-		// Production complete - spawn unit
-		spawnPos := cp.findUnitSpawnPosition(building)
-		_, err := cp.world.ObjectManager.CreateUnit(
-			building.PlayerID,
-			production.ItemName,
-			spawnPos,
-			nil) // faction data will be looked up
-
-		if err == nil {
-			// Success - clear production
-			building.CurrentProduction = nil
-
-			// Process next item in queue
-			if len(building.ProductionQueue) > 0 {
-				building.CurrentProduction = &building.ProductionQueue[0]
-				building.ProductionQueue = building.ProductionQueue[1:]
-			}
-		}
-		// On failure, production continues (retry next frame)
-	}
-}
-
-// processUpgradeProgress handles building upgrade progress
-func (cp *CommandProcessor) processUpgradeProgress(building *GameBuilding, deltaTime time.Duration) {
-	if building.CurrentUpgrade == nil {
-		return
-	}
-
-	upgrade := building.CurrentUpgrade
-	upgrade.Progress += float32(deltaTime.Seconds()) / float32(upgrade.Duration.Seconds())
-
-	if upgrade.Progress >= 1.0 {
-		// Upgrade complete
-		building.UpgradeLevel++
-		building.CurrentUpgrade = nil
-
-		// Apply upgrade effects (enhanced resource generation, etc.)
-		cp.applyUpgradeEffects(building, upgrade.UpgradeType)
-	}
-}
-
-// findUnitSpawnPosition finds a suitable position near a building to spawn a unit
-func (cp *CommandProcessor) findUnitSpawnPosition(building *GameBuilding) Vector3 {
-	// Start from building position and search for free space nearby
-	basePos := building.Position
-
-	// Try positions in a spiral pattern around the building
-	for radius := 2.0; radius <= 10.0; radius += 1.0 {
-		for angle := 0.0; angle < 360.0; angle += 45.0 {
-			// Convert angle to radians
-			angleRad := angle * math.Pi / 180.0
-
-			testPos := Vector3{
-				X: basePos.X + radius*math.Cos(angleRad),
-				Y: basePos.Y,
-				Z: basePos.Z + radius*math.Sin(angleRad),
-			}
-
-			// Convert to grid position for walkability check
-			gridPos := Vector2i{
-				X: int(testPos.X / float64(cp.world.GetTileSize())),
-				Y: int(testPos.Z / float64(cp.world.GetTileSize())),
-			}
-
-			// Check if position is walkable and not occupied
-			if cp.world.IsPositionWalkable(gridPos) {
-				return testPos
-			}
-		}
 	}
 
-	// Fallback to building position if no free space found
-	return basePos
-}
-
-// applyUpgradeEffects applies the effects of a completed upgrade to a building
-func (cp *CommandProcessor) applyUpgradeEffects(building *GameBuilding, upgradeType string) {
-	// Apply upgrade-specific effects
-	switch upgradeType {
-	case "resource_efficiency":
-		// Increase resource generation by 25%
-		for resType, rate := range building.ResourceGeneration {
-			building.ResourceGeneration[resType] = rate * 1.25
-		}
-	case "production_speed":
-		// Increase production rate
-		building.ProductionRate *= 1.3
-	case "durability":
-		// Increase max health
-		building.MaxHealth = int(float32(building.MaxHealth) * 1.2)
-		building.Health = building.MaxHealth // Heal to full
-	}
+	// Towers and other defensive buildings fire at enemies in range
+	cp.updateDefensiveBuildings(players)
 }
 
 // Validation methods
@@ -375,6 +296,9 @@ func (cp *CommandProcessor) validateCommand(unit *GameUnit, command UnitCommand)
 	if !unit.IsAlive() {
 		return fmt.Errorf("unit is dead")
 	}
+	if unit.IsGarrisoned() {
+		return fmt.Errorf("unit is garrisoned")
+	}
 
 	switch command.Type {
 	case CommandMove:
@@ -382,12 +306,21 @@ func (cp *CommandProcessor) validateCommand(unit *GameUnit, command UnitCommand)
 			return fmt.Errorf("move command requires target position")
 		}
 	case CommandAttack:
+		if command.TargetUnit == nil && command.TargetBuilding != nil {
+			if !command.TargetBuilding.IsAlive() {
+				return fmt.Errorf("cannot attack destroyed building")
+			}
+			break
+		}
 		if command.TargetUnit == nil {
 			return fmt.Errorf("attack command requires target unit")
 		}
 		if !command.TargetUnit.IsAlive() {
 			return fmt.Errorf("cannot attack dead unit")
 		}
+		if command.TargetUnit.IsGarrisoned() {
+			return fmt.Errorf("cannot attack garrisoned unit")
+		}
 	case CommandGather:
 		if command.TargetResource == nil {
 			return fmt.Errorf("gather command requires target resource")
@@ -507,6 +440,13 @@ func (cp *CommandProcessor) processMoveCommand(unit *GameUnit, command *UnitComm
 		return
 	}
 
+	// A group order brings the way it planned for the unit
+	if len(command.Path) > 0 {
+		unit.Path = command.Path
+		unit.PathIndex = 0
+		command.Path = nil
+	}
+
 	// Initialize pathfinding if unit doesn't have a computed path
 	if unit.Path == nil || len(unit.Path) == 0 || unit.PathIndex >= len(unit.Path) {
 		// Request new path from pathfinding system
@@ -567,9 +507,8 @@ func (cp *CommandProcessor) processMoveCommand(unit *GameUnit, command *UnitComm
 		newGrid := cp.world.WorldToGrid(currentWaypoint)
 
 		if currentGrid.Grid.X != newGrid.Grid.X || currentGrid.Grid.Y != newGrid.Grid.Y {
-			cp.world.SetOccupied(currentGrid.Grid, false)
-			cp.world.SetOccupied(newGrid.Grid, true)
 			unit.UpdatePositions(currentWaypoint, cp.world.tileSize)
+			cp.world.moveFootprint(unit, currentGrid.Grid, newGrid.Grid)
 		}
 
 		// Check if this was the final waypoint
@@ -591,8 +530,10 @@ func (cp *CommandProcessor) processMoveCommand(unit *GameUnit, command *UnitComm
 	nextPos := cp.calculateNextPosition(unit, currentWaypoint, deltaTime)
 	nextGrid := cp.world.WorldToGrid(nextPos)
 
-	// Check if next position is still walkable (dynamic obstacles)
-	if cp.world.IsWalkable(nextGrid) && !cp.world.IsOccupied(nextGrid) {
+	// Check if next position is still walkable (dynamic obstacles); the unit's
+	// own tiles are marked occupied by the unit itself, so moving within them is always allowed
+	sameTile := nextGrid.Grid == unit.GetGridPosition().Grid
+	if sameTile || cp.footprintClear(unit, nextGrid) {
 		// Path is clear, continue movement
 		oldGridPos := unit.GetGridPosition()
 		unit.UpdatePositions(nextPos, cp.world.tileSize)
@@ -600,8 +541,7 @@ func (cp *CommandProcessor) processMoveCommand(unit *GameUnit, command *UnitComm
 		// Update occupancy grid if unit moved to different tile
 		newGridPos := cp.world.WorldToGrid(nextPos)
 		if oldGridPos.Grid.X != newGridPos.Grid.X || oldGridPos.Grid.Y != newGridPos.Grid.Y {
-			cp.world.SetOccupied(oldGridPos.Grid, false)
-			cp.world.SetOccupied(newGridPos.Grid, true)
+			cp.world.moveFootprint(unit, oldGridPos.Grid, newGridPos.Grid)
 		}
 	} else {
 		// Path blocked by dynamic obstacle, recalculate path
@@ -625,6 +565,10 @@ func (cp *CommandProcessor) processMoveCommand(unit *GameUnit, command *UnitComm
 }
 
 func (cp *CommandProcessor) processAttackCommand(unit *GameUnit, command *UnitCommand, deltaTime time.Duration) {
+	if command.TargetUnit == nil && command.TargetBuilding != nil {
+		cp.processBuildingAttack(unit, command.TargetBuilding, deltaTime)
+		return
+	}
 	target := command.TargetUnit
 
 	// Validate target
@@ -632,25 +576,86 @@ func (cp *CommandProcessor) processAttackCommand(unit *GameUnit, command *UnitCo
 		cp.cancelAttackCommand(unit, "target is dead or invalid")
 		return
 	}
+	if target.IsGarrisoned() {
+		cp.cancelAttackCommand(unit, "target is garrisoned")
+		return
+	}
+
+	// A target that leaves range is chased, and the swing at it is lost
+	if !cp.combatSystem.isInAttackRange(unit, target) {
+		unit.cancelWindUp()
+		cp.moveToAttackPosition(unit, target)
+		return
+	}
 
-	// Check if unit can still attack this target
+	// Check if unit can still attack this target; the cooldown only delays it
 	canAttack, reason := cp.combatSystem.CanAttack(unit, target)
-	if !canAttack {
-		if reason == "target out of range" {
-			// Try to move closer to attack
-			cp.moveToAttackPosition(unit, target)
-			return
-		} else {
-			// Cannot attack for other reasons (no line of sight, cooldown, etc.)
-			cp.cancelAttackCommand(unit, reason)
-			return
-		}
+	if !canAttack && reason != "attack on cooldown" {
+		// Cannot attack for other reasons (no line of sight, etc.)
+		cp.cancelAttackCommand(unit, reason)
+		return
 	}
 
-	// Unit is in position and can attack - execute the attack
+	// Unit is in position: turn to the target, swing once it is in the
+	// attack arc, and strike once the wind-up completes
+	unit.State = UnitStateAttacking
+	unit.AttackTarget = target
+	unit.Target = nil
+	if !cp.world.turnToward(unit, target.Position, deltaTime) {
+		return
+	}
+	if !cp.combatSystem.advanceAttack(unit) {
+		return
+	}
+	cp.world.fireHitMarker(unit, target.Position)
+	if cp.combatSystem.missesUphill(unit.Position, target.Position, cp.combatSystem.getAttackType(unit)) {
+		return // Shooting at higher ground can miss; the cooldown runs either way
+	}
 	cp.executeAttack(unit, target)
 }
 
+// processBuildingAttack moves a unit next to a building and strikes it until it is destroyed
+func (cp *CommandProcessor) processBuildingAttack(unit *GameUnit, target *GameBuilding, deltaTime time.Duration) {
+	if !target.IsAlive() {
+		cp.cancelAttackCommand(unit, "building is destroyed")
+		return
+	}
+
+	// Buildings block their tile, so attackers stand next to it
+	if cp.calculateDistance(unit.Position, target.Position) > unit.AttackRange+1.0 {
+		unit.cancelWindUp()
+		if unit.Target == nil || unit.State != UnitStateMoving {
+			targetGrid := cp.world.WorldToGrid(target.Position).Grid
+			for _, neighbor := range GetNeighbors(targetGrid) {
+				if cp.world.CanPass(neighbor, unit.PlayerID) {
+					position := GetGridCenter(neighbor, cp.world.tileSize)
+					unit.State = UnitStateMoving
+					unit.Target = &position
+					return
+				}
+			}
+			cp.cancelAttackCommand(unit, "no position next to the building")
+		}
+		return
+	}
+
+	unit.State = UnitStateAttacking
+	unit.Target = nil
+	if !cp.world.turnToward(unit, target.Position, deltaTime) {
+		return
+	}
+	if !cp.combatSystem.advanceAttack(unit) {
+		return
+	}
+	cp.world.fireHitMarker(unit, target.Position)
+	if cp.combatSystem.missesUphill(unit.Position, target.Position, cp.combatSystem.getAttackType(unit)) {
+		return
+	}
+	if cp.combatSystem.ApplyBuildingDamage(target, cp.combatSystem.CalculateBuildingDamage(unit, target)) {
+		cp.cancelAttackCommand(unit, "building destroyed")
+	}
+}
+
 // moveToAttackPosition moves a unit to the optimal position for attacking a target
 func (cp *CommandProcessor) moveToAttackPosition(unit *GameUnit, target *GameUnit) {
 	// Get optimal attack position from combat system
@@ -701,6 +706,7 @@ func (cp *CommandProcessor) executeAttack(unit *GameUnit, target *GameUnit) {
 				damageType.Name,
 				allVictims,
 			)
+			cp.world.markExplosion(target.Position, damageType.SplashRadius*float64(cp.world.GetTileSize()))
 		} else {
 			// Single target attack - create appropriate effect
 			if cp.isRangedAttack(damageType) {
@@ -800,6 +806,7 @@ func (cp *CommandProcessor) cancelAttackCommand(unit *GameUnit, reason string) {
 	unit.State = UnitStateIdle
 	unit.AttackTarget = nil
 	unit.Target = nil
+	unit.cancelWindUp()
 
 	// Log cancellation reason for debugging
 	// fmt.Printf("Attack command cancelled for unit %d: %s\n", unit.ID, reason)
@@ -907,8 +914,9 @@ func (cp *CommandProcessor) processBuildCommand(unit *GameUnit, command *UnitCom
 		command.Target = &worldTarget
 	}
 
-	// Check if we're adjacent to the build location (builders need to be next to build site)
-	unitGrid := unit.GetGridPosition()
+	// Check if we're adjacent to the build location (builders need to be next to build site);
+	// walking up to the site moves the unit without updating its grid position
+	unitGrid := cp.world.WorldToGrid(unit.GetPosition())
 	buildDistance := CalculateGridDistance(unitGrid.Grid, buildGrid.Grid)
 
 	if buildDistance <= 1 {
@@ -962,6 +970,9 @@ func (cp *CommandProcessor) processBuildCommand(unit *GameUnit, command *UnitCom
 				return
 			}
 
+			// Walls and gates block their tile for pathfinding from now on
+			cp.world.registerWall(building, buildGrid.Grid)
+
 			// Set build target and track construction progress
 			unit.BuildTarget = building
 		}
@@ -1163,7 +1174,7 @@ func (cp *CommandProcessor) startProduction(building *GameBuilding, command Unit
 		Progress:  0.0,
 		Duration:  duration,
 		Cost:      cost,
-		StartTime: time.Now(),
+		StartTime: cp.world.now(),
 	}
 
 	// Add to production queue
@@ -1212,7 +1223,7 @@ func (cp *CommandProcessor) startUpgrade(building *GameBuilding, command UnitCom
 		Progress:    0.0,
 		Duration:    duration,
 		Cost:        cost,
-		StartTime:   time.Now(),
+		StartTime:   cp.world.now(),
 	}
 
 	building.CurrentUpgrade = &upgradeItem
@@ -1229,6 +1240,16 @@ func (cp *CommandProcessor) calculateDistance(pos1, pos2 Vector3) float32 {
 	return float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
 }
 
+// isOccupiedByOther checks if a tile holds any unit other than the given one
+func (cp *CommandProcessor) isOccupiedByOther(unit *GameUnit, gridPos GridPosition) bool {
+	for _, other := range cp.world.ObjectManager.UnitManager.GetUnitsAtPosition(gridPos.Grid) {
+		if other.ID != unit.ID {
+			return true
+		}
+	}
+	return false
+}
+
 // calculateNextPosition calculates the next position for a unit moving toward target
 func (cp *CommandProcessor) calculateNextPosition(unit *GameUnit, target Vector3, deltaTime time.Duration) Vector3 {
 	currentPos := unit.GetPosition()
@@ -1297,6 +1318,16 @@ func CreateAttackCommand(target *GameUnit, queued bool) UnitCommand {
 	}
 }
 
+// CreateAttackBuildingCommand creates an attack command against a building
+func CreateAttackBuildingCommand(target *GameBuilding, queued bool) UnitCommand {
+	return UnitCommand{
+		Type:           CommandAttack,
+		TargetBuilding: target,
+		Parameters:     make(map[string]interface{}),
+		IsQueued:       queued,
+	}
+}
+
 // CreateGatherCommand creates a gather command
 func CreateGatherCommand(target *ResourceNode, queued bool) UnitCommand {
 	return UnitCommand{
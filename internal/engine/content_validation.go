@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// ValidateMapFile checks that a map file parses and that its dimensions, player
+// count and start positions are sane. When dataRoot is set, the map's tileset
+// is loaded too.
+func ValidateMapFile(mapPath, dataRoot string) *data.ValidationReport {
+	startTime := time.Now()
+	report := data.NewValidationReport()
+	report.FilesChecked++
+
+	mapData, err := NewMapLoader().ParseMapFile(mapPath)
+	if err != nil {
+		report.AddIssue(data.ValidationIssue{
+			Severity:   data.ValidationError,
+			Category:   "Map Format",
+			Message:    fmt.Sprintf("Failed to parse map: %v", err),
+			File:       mapPath,
+			Suggestion: "Re-save the map with the MegaGlest map editor",
+		})
+		report.Duration = time.Since(startTime)
+		return report
+	}
+
+	if dataRoot != "" {
+		tileset, err := NewTilesetLoader(dataRoot).LoadTileset(mapData.TilesetName)
+		if err != nil {
+			report.AddIssue(data.ValidationIssue{
+				Severity:   data.ValidationWarning,
+				Category:   "Map Reference",
+				Message:    fmt.Sprintf("Tileset '%s' could not be loaded: %v", mapData.TilesetName, err),
+				File:       mapPath,
+				Field:      "tileset",
+				Value:      mapData.TilesetName,
+				Suggestion: "Install the tileset or choose another one in the map editor",
+			})
+		}
+		mapData.Tileset = tileset
+	}
+
+	for _, problem := range (&MapManager{dataRoot: dataRoot}).ValidateMap(mapData) {
+		if problem == "tileset not loaded" {
+			continue // Reported above with more detail, or skipped without a data root
+		}
+		report.AddIssue(data.ValidationIssue{
+			Severity: data.ValidationError,
+			Category: "Map Consistency",
+			Message:  problem,
+			File:     mapPath,
+		})
+	}
+
+	if len(mapData.StartPositions) < mapData.MaxPlayers {
+		report.AddIssue(data.ValidationIssue{
+			Severity: data.ValidationError,
+			Category: "Map Consistency",
+			Message: fmt.Sprintf("Map declares %d players but has %d start positions",
+				mapData.MaxPlayers, len(mapData.StartPositions)),
+			File:  mapPath,
+			Field: "start-positions",
+		})
+	}
+
+	report.Duration = time.Since(startTime)
+	return report
+}
+
+// ValidateTilesetDir checks that a tileset parses and that the textures and
+// models it references exist. basePath is the directory containing "tilesets".
+func ValidateTilesetDir(basePath, tilesetName string) *data.ValidationReport {
+	startTime := time.Now()
+	report := data.NewValidationReport()
+	report.FilesChecked++
+
+	xmlFile := filepath.Join("tilesets", tilesetName, tilesetName+".xml")
+	if schemaReport, err := data.ValidateXMLSchema(filepath.Join(basePath, xmlFile), filepath.ToSlash(xmlFile), data.SchemaTileset); err == nil {
+		schemaReport.FilesChecked = 0 // Counted above
+		report.Merge(schemaReport)
+	}
+
+	tileset, err := NewTilesetLoader(basePath).LoadTileset(tilesetName)
+	if err != nil {
+		report.AddIssue(data.ValidationIssue{
+			Severity:   data.ValidationError,
+			Category:   "XML Structure",
+			Message:    fmt.Sprintf("Failed to load tileset '%s': %v", tilesetName, err),
+			File:       xmlFile,
+			Suggestion: "Check XML syntax and structure",
+		})
+		report.Duration = time.Since(startTime)
+		return report
+	}
+
+	if len(tileset.Surfaces) == 0 {
+		report.AddIssue(data.ValidationIssue{
+			Severity:   data.ValidationError,
+			Category:   "Tileset Structure",
+			Message:    "Tileset defines no surfaces",
+			File:       xmlFile,
+			Field:      "surfaces",
+			Suggestion: "Define the five MegaGlest surface types",
+		})
+	}
+
+	for _, surface := range tileset.Surfaces {
+		if len(surface.Textures) == 0 {
+			report.AddIssue(data.ValidationIssue{
+				Severity: data.ValidationError,
+				Category: "Tileset Structure",
+				Message:  fmt.Sprintf("Surface %d has no textures", surface.Index),
+				File:     xmlFile,
+				Field:    "surface",
+			})
+		}
+		for _, texture := range surface.Textures {
+			checkTilesetAsset(report, tileset.BasePath, xmlFile, "texture", texture.Path)
+		}
+	}
+
+	for _, object := range tileset.Objects {
+		for _, model := range object.Models {
+			checkTilesetAsset(report, tileset.BasePath, xmlFile, "model", model)
+		}
+	}
+
+	report.Duration = time.Since(startTime)
+	return report
+}
+
+// checkTilesetAsset reports a referenced tileset file that does not exist
+func checkTilesetAsset(report *data.ValidationReport, tilesetDir, xmlFile, field, relPath string) {
+	if relPath == "" {
+		return
+	}
+	report.FilesChecked++
+
+	path := filepath.Join(tilesetDir, filepath.FromSlash(strings.TrimPrefix(relPath, "./")))
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		report.AddIssue(data.ValidationIssue{
+			Severity:   data.ValidationError,
+			Category:   "Asset Missing",
+			Message:    fmt.Sprintf("Referenced %s not found: %s", field, relPath),
+			File:       xmlFile,
+			Field:      field,
+			Value:      relPath,
+			Suggestion: "Fix the path or add the missing file",
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MaxControlGroup is the highest control group number; groups are numbered
+// from 1 so 0 can mean "no group"
+const MaxControlGroup = 9
+
+// ControlGroups are each player's numbered unit groups for quick selection.
+// Dead units drop out of a group when it is read.
+type ControlGroups struct {
+	mutex  sync.Mutex
+	groups map[int]map[int][]int // Player ID -> group number -> unit IDs
+}
+
+// Assign replaces a control group with the given units
+func (cg *ControlGroups) Assign(playerID, group int, units []*GameUnit) error {
+	return cg.change(playerID, group, units, true)
+}
+
+// Add appends units to a control group, skipping units already in it
+func (cg *ControlGroups) Add(playerID, group int, units ...*GameUnit) error {
+	return cg.change(playerID, group, units, false)
+}
+
+// change sets or extends a control group
+func (cg *ControlGroups) change(playerID, group int, units []*GameUnit, replace bool) error {
+	if group < 1 || group > MaxControlGroup {
+		return fmt.Errorf("control group %d out of range 1-%d", group, MaxControlGroup)
+	}
+	for _, unit := range units {
+		if unit.PlayerID != playerID {
+			return fmt.Errorf("unit %d does not belong to player %d", unit.ID, playerID)
+		}
+	}
+
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+	if cg.groups == nil {
+		cg.groups = make(map[int]map[int][]int)
+	}
+	if cg.groups[playerID] == nil {
+		cg.groups[playerID] = make(map[int][]int)
+	}
+	ids := cg.groups[playerID][group]
+	if replace {
+		ids = nil
+	}
+	for _, unit := range units {
+		if !containsInt(ids, unit.ID) {
+			ids = append(ids, unit.ID)
+		}
+	}
+	cg.groups[playerID][group] = ids
+	return nil
+}
+
+// Units returns the living units of a control group in the order they joined
+func (cg *ControlGroups) Units(objects *ObjectManager, playerID, group int) []*GameUnit {
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+
+	ids := cg.groups[playerID][group]
+	units := make([]*GameUnit, 0, len(ids))
+	alive := ids[:0]
+	for _, id := range ids {
+		if unit := objects.GetUnit(id); unit != nil && unit.IsAlive() {
+			units = append(units, unit)
+			alive = append(alive, id)
+		}
+	}
+	if len(ids) > 0 {
+		cg.groups[playerID][group] = alive
+	}
+	return units
+}
+
+// containsInt returns whether a list holds a number
+func containsInt(list []int, value int) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetControlGroups returns the players' control groups
+func (w *World) GetControlGroups() *ControlGroups {
+	return &w.controlGroups
+}
+
+// GetGroupManager returns the army groups and their formations
+func (w *World) GetGroupManager() *GroupManager {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.groupMgr
+}
@@ -0,0 +1,308 @@
+package engine
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"teraglest/internal/logging"
+)
+
+// Crash report limits
+const (
+	MaxRecentEvents      = 100             // Game events kept for crash reports
+	MaxRecentCommands    = 50              // Issued commands kept for crash reports
+	crashSnapshotTimeout = 2 * time.Second // Give up on the world snapshot if its lock is stuck
+)
+
+// EventRecord is a serializable summary of a GameEvent
+type EventRecord struct {
+	Type      GameEventType `json:"type"`
+	Timestamp time.Time     `json:"timestamp"`
+	PlayerID  int           `json:"player_id"`
+	Message   string        `json:"message"`
+}
+
+// CommandRecord describes a command issued to a unit or building
+type CommandRecord struct {
+	IssuedAt   time.Time   `json:"issued_at"`
+	PlayerID   int         `json:"player_id"`   // Owner of the target, 0 if it was not found
+	TargetID   int         `json:"target_id"`   // Unit or building ID
+	IsBuilding bool        `json:"is_building"` // Whether TargetID is a building
+	Type       CommandType `json:"type"`
+	TypeName   string      `json:"type_name"`
+	IsQueued   bool        `json:"is_queued"`
+	Error      string      `json:"error,omitempty"` // Rejection reason, if the command failed
+}
+
+// CrashReport is the metadata written to a crash bundle
+type CrashReport struct {
+	Time           time.Time       `json:"time"`
+	Panic          string          `json:"panic"`
+	GoVersion      string          `json:"go_version"`
+	OS             string          `json:"os"`
+	Arch           string          `json:"arch"`
+	GameState      GameState       `json:"game_state"`
+	Settings       GameSettings    `json:"settings"`
+	RecentEvents   []EventRecord   `json:"recent_events"`
+	RecentCommands []CommandRecord `json:"recent_commands"`
+	SnapshotError  string          `json:"snapshot_error,omitempty"` // Why world.json is missing, if it is
+}
+
+// eventHistory keeps the most recent game events
+type eventHistory struct {
+	mutex   sync.Mutex
+	records []EventRecord
+}
+
+// add records an event, dropping the oldest beyond MaxRecentEvents
+func (h *eventHistory) add(event GameEvent) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.records = append(h.records, EventRecord{
+		Type:      event.Type,
+		Timestamp: event.Timestamp,
+		PlayerID:  event.PlayerID,
+		Message:   event.Message,
+	})
+	if len(h.records) > MaxRecentEvents {
+		h.records = h.records[len(h.records)-MaxRecentEvents:]
+	}
+}
+
+// clear forgets the recorded events
+func (h *eventHistory) clear() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.records = nil
+}
+
+// snapshot returns a copy of the recorded events, oldest first
+func (h *eventHistory) snapshot() []EventRecord {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]EventRecord(nil), h.records...)
+}
+
+// commandHistory keeps the most recently issued commands and counts each
+// player's actions. Commands a player gives within actionMergeWindow of each
+// other, such as one order to a whole selection, are one action.
+type commandHistory struct {
+	mutex      sync.Mutex
+	records    []CommandRecord
+	actions    map[int]int       // Player ID -> actions taken
+	lastAction map[int]time.Time // Player ID -> when the last counted command was given
+}
+
+// add records a command, dropping the oldest beyond MaxRecentCommands
+func (h *commandHistory) add(playerID, targetID int, isBuilding bool, command UnitCommand, err error) {
+	record := CommandRecord{
+		IssuedAt:   time.Now(),
+		PlayerID:   playerID,
+		TargetID:   targetID,
+		IsBuilding: isBuilding,
+		Type:       command.Type,
+		TypeName:   command.Type.String(),
+		IsQueued:   command.IsQueued,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.records = append(h.records, record)
+	if len(h.records) > MaxRecentCommands {
+		h.records = h.records[len(h.records)-MaxRecentCommands:]
+	}
+
+	if err != nil || playerID == 0 {
+		return
+	}
+	if h.actions == nil {
+		h.actions, h.lastAction = make(map[int]int), make(map[int]time.Time)
+	}
+	if last, ok := h.lastAction[playerID]; !ok || command.CreatedAt.Sub(last) > actionMergeWindow {
+		h.actions[playerID]++
+		h.lastAction[playerID] = command.CreatedAt
+	}
+}
+
+// actionCount returns the actions a player has taken
+func (h *commandHistory) actionCount(playerID int) int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.actions[playerID]
+}
+
+// snapshot returns a copy of the recorded commands, oldest first
+func (h *commandHistory) snapshot() []CommandRecord {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]CommandRecord(nil), h.records...)
+}
+
+// RecentEvents returns the most recent game events, oldest first
+func (g *Game) RecentEvents() []EventRecord {
+	return g.eventLog.snapshot()
+}
+
+// RecentCommands returns the most recently issued commands, oldest first
+func (w *World) RecentCommands() []CommandRecord {
+	return w.commandLog.snapshot()
+}
+
+// SetCrashReportDir sets where crash bundles are written when the game loop panics
+// (empty disables crash bundles)
+func (g *Game) SetCrashReportDir(dir string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.crashReportDir = dir
+}
+
+// WriteCrashBundle writes a zip archive with the panic value, all goroutine
+// stacks, recent events and commands, and a world snapshot. It returns the
+// path of the bundle. It avoids the game mutex, which the panicking goroutine
+// may have held.
+func (g *Game) WriteCrashBundle(dir string, recovered interface{}) (string, error) {
+	stacks := CaptureGoroutineStacks()
+
+	report := CrashReport{
+		Time:         time.Now(),
+		Panic:        fmt.Sprint(recovered),
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		GameState:    g.state,
+		Settings:     g.settings,
+		RecentEvents: g.eventLog.snapshot(),
+	}
+
+	var snapshot *SaveGame
+	if g.world != nil {
+		report.RecentCommands = g.world.commandLog.snapshot()
+
+		var err error
+		snapshot, err = captureWorldForCrash(g.world, crashSnapshotTimeout)
+		if err != nil {
+			report.SnapshotError = err.Error()
+		}
+	} else {
+		report.SnapshotError = "no world"
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create crash directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s.zip", report.Time.Format("20060102-150405")))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create crash bundle: %w", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	if err := writeZipJSON(archive, "report.json", report); err != nil {
+		return "", err
+	}
+	if err := writeZipEntry(archive, "goroutines.txt", stacks); err != nil {
+		return "", err
+	}
+	if snapshot != nil {
+		if err := writeZipJSON(archive, "world.json", snapshot); err != nil {
+			return "", err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return "", fmt.Errorf("failed to finish crash bundle: %w", err)
+	}
+
+	return path, nil
+}
+
+// CaptureGoroutineStacks returns the stack traces of all goroutines
+func CaptureGoroutineStacks() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// recoverCrash writes a crash bundle for a panic in the game loop, then re-panics
+func (g *Game) recoverCrash() {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	if g.crashReportDir != "" {
+		if path, err := g.WriteCrashBundle(g.crashReportDir, recovered); err != nil {
+			logging.Errorf(logging.CategoryEngine, "Game loop panic: %v (crash bundle failed: %v)", recovered, err)
+		} else {
+			logging.Errorf(logging.CategoryEngine, "Game loop panic: %v (crash bundle written to %s)", recovered, path)
+		}
+	}
+
+	panic(recovered)
+}
+
+// captureWorldForCrash takes a savegame snapshot, giving up if the world lock
+// stays held (e.g. by the goroutine that panicked)
+func captureWorldForCrash(w *World, timeout time.Duration) (*SaveGame, error) {
+	result := make(chan *SaveGame, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- nil
+			}
+		}()
+		result <- w.CaptureSaveGame()
+	}()
+
+	select {
+	case snapshot := <-result:
+		if snapshot == nil {
+			return nil, fmt.Errorf("world snapshot panicked")
+		}
+		return snapshot, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("world snapshot timed out after %v", timeout)
+	}
+}
+
+// writeZipJSON adds a compressed JSON entry to a zip archive
+func writeZipJSON(archive *zip.Writer, name string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return writeZipEntry(archive, name, content)
+}
+
+// writeZipEntry adds a compressed entry to a zip archive
+func writeZipEntry(archive *zip.Writer, name string, content []byte) error {
+	entry, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to crash bundle: %w", name, err)
+	}
+	if _, err := entry.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s to crash bundle: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandAndEventHistoryLimits(t *testing.T) {
+	var events eventHistory
+	for i := 0; i < MaxRecentEvents+10; i++ {
+		events.add(GameEvent{Type: EventTypeUnitCreated, PlayerID: i})
+	}
+	recorded := events.snapshot()
+	if len(recorded) != MaxRecentEvents || recorded[0].PlayerID != 10 {
+		t.Errorf("Expected last %d events starting at 10, got %d starting at %d",
+			MaxRecentEvents, len(recorded), recorded[0].PlayerID)
+	}
+
+	world := createTestWorldForProduction(t)
+	world.commandProcessor.IssueCommand(999, CreateMoveCommand(Vector3{X: 1}, false))
+	commands := world.RecentCommands()
+	if len(commands) != 1 || commands[0].TargetID != 999 || commands[0].Error == "" {
+		t.Errorf("Expected failed command to be recorded, got %+v", commands)
+	}
+}
+
+func TestWriteCrashBundle(t *testing.T) {
+	world := createTestWorldForProduction(t)
+	world.commandProcessor.IssueCommand(42, CreateMoveCommand(Vector3{}, false))
+
+	game := &Game{world: world, state: GameStatePlaying}
+	game.sendEvent(GameEvent{Type: EventTypeGameStart, Timestamp: time.Now(), PlayerID: -1, Message: "Game started"})
+
+	path, err := game.WriteCrashBundle(t.TempDir(), "boom")
+	if err != nil {
+		t.Fatalf("WriteCrashBundle failed: %v", err)
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Failed to open crash bundle: %v", err)
+	}
+	defer archive.Close()
+
+	entries := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		entries[file.Name] = file
+	}
+	for _, name := range []string{"report.json", "goroutines.txt", "world.json"} {
+		if entries[name] == nil {
+			t.Fatalf("Crash bundle missing %s", name)
+		}
+	}
+
+	reader, _ := entries["report.json"].Open()
+	defer reader.Close()
+	var report CrashReport
+	if err := json.NewDecoder(reader).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Panic != "boom" || len(report.RecentEvents) != 1 || len(report.RecentCommands) != 1 {
+		t.Errorf("Unexpected report contents: %+v", report)
+	}
+
+	stacks, _ := entries["goroutines.txt"].Open()
+	defer stacks.Close()
+	buf := make([]byte, 64)
+	n, _ := stacks.Read(buf)
+	if !strings.HasPrefix(string(buf[:n]), "goroutine") {
+		t.Errorf("Expected goroutine dump, got %q", buf[:n])
+	}
+}
@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"teraglest/internal/debugdraw"
+)
+
+// Tile overlay colors
+var (
+	unitTileColor       = debugdraw.Color{R: 1, G: 0.5, B: 0.1, A: 0.4}    // Occupied, with units on it
+	occupiedTileColor   = debugdraw.Color{R: 0.6, G: 0.3, B: 1, A: 0.4}    // Occupied by a building, resource or reservation
+	unmarkedUnitColor   = debugdraw.Color{R: 1, G: 1, B: 0.1, A: 0.6}      // Units on a tile not marked occupied
+	walkableTileColor   = debugdraw.Color{R: 0.2, G: 0.9, B: 0.3, A: 0.15} // Free to walk on
+	unwalkableTileColor = debugdraw.Color{R: 0.8, G: 0.1, B: 0.1, A: 0.4}  // Blocked terrain
+)
+
+// Region overlay colors, taken in turn by region ID
+var regionColors = []debugdraw.Color{
+	{R: 0.9, G: 0.3, B: 0.3, A: 0.3},
+	{R: 0.3, G: 0.8, B: 0.3, A: 0.3},
+	{R: 0.3, G: 0.5, B: 1, A: 0.3},
+	{R: 0.9, G: 0.8, B: 0.2, A: 0.3},
+	{R: 0.8, G: 0.3, B: 0.9, A: 0.3},
+	{R: 0.2, G: 0.8, B: 0.8, A: 0.3},
+}
+
+// drawDebug records the enabled debug draw categories from the world's
+// systems and publishes them as one frame
+func (w *World) drawDebug(dd *debugdraw.Drawer) {
+	defer dd.Publish()
+	if !dd.Active() {
+		return
+	}
+
+	w.mutex.RLock()
+	playerIDs := make([]int, 0, len(w.players))
+	for id := range w.players {
+		playerIDs = append(playerIDs, id)
+	}
+	w.mutex.RUnlock()
+
+	var units []*GameUnit
+	for _, id := range playerIDs {
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(id) {
+			if unit.IsAlive() {
+				units = append(units, unit)
+			}
+		}
+	}
+
+	if dd.Enabled(debugdraw.CategoryPaths) {
+		drawUnitPaths(dd, units)
+	}
+	if dd.Enabled(debugdraw.CategoryRanges) {
+		drawAttackRanges(dd, units)
+	}
+	if dd.Enabled(debugdraw.CategoryFormations) && w.groupMgr != nil {
+		w.groupMgr.drawDebug(dd)
+	}
+	if dd.Enabled(debugdraw.CategoryExpansion) && w.strategicAIMgr != nil {
+		w.strategicAIMgr.drawDebug(dd)
+	}
+	if dd.Enabled(debugdraw.CategoryOccupancy) {
+		w.drawOccupancy(dd, units)
+	}
+	if dd.Enabled(debugdraw.CategoryWalkable) {
+		w.drawWalkability(dd)
+	}
+	if dd.Enabled(debugdraw.CategoryRegions) {
+		w.drawMapAnalysis(dd)
+	}
+}
+
+// drawUnitPaths draws the remaining path of each moving unit
+func drawUnitPaths(dd *debugdraw.Drawer, units []*GameUnit) {
+	for _, unit := range units {
+		unit.mutex.RLock()
+		if unit.PathIndex < len(unit.Path) {
+			points := []debugdraw.Point{debugdraw.Point(unit.Position)}
+			for _, waypoint := range unit.Path[unit.PathIndex:] {
+				points = append(points, debugdraw.Point(waypoint))
+			}
+			dd.Polyline(debugdraw.CategoryPaths, points, debugdraw.Cyan)
+		}
+		unit.mutex.RUnlock()
+	}
+}
+
+// drawAttackRanges draws a sphere of each armed unit's attack range
+func drawAttackRanges(dd *debugdraw.Drawer, units []*GameUnit) {
+	for _, unit := range units {
+		unit.mutex.RLock()
+		if unit.AttackDamage > 0 && unit.AttackRange > 0 {
+			color := debugdraw.Yellow
+			if unit.AttackTarget != nil {
+				color = debugdraw.Red
+			}
+			dd.Sphere(debugdraw.CategoryRanges, debugdraw.Point(unit.Position), float64(unit.AttackRange), color)
+		}
+		unit.mutex.RUnlock()
+	}
+}
+
+// drawDebug draws each group's formation slots, linked to the unit holding them
+func (gm *GroupManager) drawDebug(dd *debugdraw.Drawer) {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+
+	for _, group := range gm.groups {
+		group.mutex.RLock()
+		for unitID, slot := range group.Positions {
+			position := debugdraw.Point(group.transformToWorldPosition(slot.RelativePos))
+			color := debugdraw.Green
+			if slot.Priority == 0 {
+				color = debugdraw.White // Leader slot
+			}
+			dd.Sphere(debugdraw.CategoryFormations, position, 0.3, color)
+			if unit, exists := group.Units[unitID]; exists {
+				dd.Line(debugdraw.CategoryFormations, debugdraw.Point(unit.GetPosition()), position, color)
+			}
+		}
+		group.mutex.RUnlock()
+	}
+}
+
+// drawDebug draws the expansion targets each AI player is considering
+func (mgr *StrategicAIManager) drawDebug(dd *debugdraw.Drawer) {
+	for playerID, ai := range mgr.aiPlayers {
+		if ai.economicMgr == nil {
+			continue
+		}
+		for rank, target := range ai.economicMgr.economicTargets {
+			// Radius grows with priority so the preferred sites stand out
+			radius := 0.5 + math.Min(target.Priority, 5)*0.3
+			dd.Sphere(debugdraw.CategoryExpansion, debugdraw.Point(target.Location), radius, debugdraw.Magenta)
+			dd.Label(debugdraw.CategoryExpansion, debugdraw.Point(target.Location),
+				fmt.Sprintf("P%d #%d %s %.2f", playerID, rank+1, target.Type, target.Priority), debugdraw.Magenta)
+		}
+	}
+}
+
+// drawOccupancy overlays occupied tiles, telling tiles with units apart and
+// flagging units standing on tiles the grid does not mark occupied
+func (w *World) drawOccupancy(dd *debugdraw.Drawer, units []*GameUnit) {
+	// Unit tiles are collected first, so the grid lock is never held while waiting on a unit
+	unitTiles := make(map[Vector2i]bool, len(units))
+	for _, unit := range units {
+		unit.mutex.RLock()
+		unitTiles[unit.GridPos.Grid] = true
+		unit.mutex.RUnlock()
+	}
+
+	w.gridMutex.RLock()
+	defer w.gridMutex.RUnlock()
+	if w.occupancyGrid == nil {
+		return
+	}
+
+	cells := make([]debugdraw.Color, w.Width*w.Height)
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			occupied, hasUnits := w.occupancyGrid[y][x], unitTiles[Vector2i{X: x, Y: y}]
+			switch {
+			case occupied && hasUnits:
+				cells[y*w.Width+x] = unitTileColor
+			case occupied:
+				cells[y*w.Width+x] = occupiedTileColor
+			case hasUnits:
+				cells[y*w.Width+x] = unmarkedUnitColor
+			}
+		}
+	}
+	dd.Grid(debugdraw.CategoryOccupancy, w.debugGrid(cells))
+}
+
+// drawWalkability overlays walkable and blocked tiles
+func (w *World) drawWalkability(dd *debugdraw.Drawer) {
+	w.gridMutex.RLock()
+	defer w.gridMutex.RUnlock()
+	if w.walkableGrid == nil {
+		return
+	}
+
+	cells := make([]debugdraw.Color, w.Width*w.Height)
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			if w.walkableGrid[y][x] {
+				cells[y*w.Width+x] = walkableTileColor
+			} else {
+				cells[y*w.Width+x] = unwalkableTileColor
+			}
+		}
+	}
+	dd.Grid(debugdraw.CategoryWalkable, w.debugGrid(cells))
+}
+
+// drawMapAnalysis overlays the regions of the map analysis, circles its
+// chokepoints as wide as the passage and marks its expansion sites
+func (w *World) drawMapAnalysis(dd *debugdraw.Drawer) {
+	analysis := w.MapAnalysis()
+	tileSize := float64(w.tileSize)
+	tileCenter := func(tile Vector2i) debugdraw.Point {
+		position := w.GridToWorld(GridPosition{Grid: tile, Offset: Vector2{X: 0.5, Y: 0.5}})
+		position.Y = float64(w.GetHeight(tile))
+		return debugdraw.Point(position)
+	}
+
+	cells := make([]debugdraw.Color, w.Width*w.Height)
+	for y := 0; y < w.Height; y++ {
+		for x := 0; x < w.Width; x++ {
+			if region := analysis.RegionAt(Vector2i{X: x, Y: y}); region >= 0 {
+				cells[y*w.Width+x] = regionColors[region%len(regionColors)]
+			}
+		}
+	}
+	w.gridMutex.RLock()
+	dd.Grid(debugdraw.CategoryRegions, w.debugGrid(cells))
+	w.gridMutex.RUnlock()
+
+	for _, region := range analysis.Regions {
+		dd.Label(debugdraw.CategoryRegions, tileCenter(region.Center),
+			fmt.Sprintf("region %d island %d", region.ID, region.Island), debugdraw.White)
+	}
+	for _, choke := range analysis.Chokepoints {
+		dd.Sphere(debugdraw.CategoryRegions, tileCenter(choke.Center), float64(choke.Width)*tileSize/2, debugdraw.Yellow)
+		dd.Label(debugdraw.CategoryRegions, tileCenter(choke.Center),
+			fmt.Sprintf("choke %d: %d-%d, %d wide", choke.ID, choke.Regions[0], choke.Regions[1], choke.Width), debugdraw.Yellow)
+	}
+	for _, site := range analysis.Expansions {
+		dd.Sphere(debugdraw.CategoryRegions, tileCenter(site.Position), expansionSiteClearance*tileSize, debugdraw.Green)
+		dd.Label(debugdraw.CategoryRegions, tileCenter(site.Position),
+			fmt.Sprintf("expansion: %d resources", site.Amount), debugdraw.Green)
+	}
+}
+
+// debugGrid returns a map-sized overlay following the terrain heights; the
+// caller holds the grid lock
+func (w *World) debugGrid(cells []debugdraw.Color) debugdraw.Grid {
+	grid := debugdraw.Grid{
+		CellSize: float64(w.tileSize),
+		Width:    w.Width,
+		Height:   w.Height,
+		Cells:    cells,
+	}
+	if w.heightMap != nil {
+		grid.Heights = make([]float64, len(cells))
+		for y := 0; y < w.Height; y++ {
+			for x := 0; x < w.Width; x++ {
+				grid.Heights[y*w.Width+x] = float64(w.heightMap[y][x])
+			}
+		}
+	}
+	return grid
+}
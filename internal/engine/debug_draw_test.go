@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+	"teraglest/internal/debugdraw"
+)
+
+// TestWorldDrawDebug tests the shapes engine systems publish for debug drawing
+func TestWorldDrawDebug(t *testing.T) {
+	world, err := NewHeadlessWorld(8, 8)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 1, Z: 1}, soldier)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	unit.AttackDamage, unit.AttackRange = 10, 3
+	unit.Path = []Vector3{{X: 2, Z: 1}, {X: 3, Z: 2}}
+	world.SetOccupied(Vector2i{X: 4, Y: 5}, true)
+
+	dd := debugdraw.New()
+	world.drawDebug(dd)
+	if shapes := dd.Snapshot(); shapes.Count() != 0 {
+		t.Errorf("Expected nothing with every category disabled, got %d shapes", shapes.Count())
+	}
+
+	for _, category := range []debugdraw.Category{debugdraw.CategoryPaths, debugdraw.CategoryRanges, debugdraw.CategoryOccupancy} {
+		dd.SetEnabled(category, true)
+	}
+	world.drawDebug(dd)
+	shapes := dd.Snapshot()
+
+	if len(shapes.Lines) != 2 || shapes.Lines[0].From != (debugdraw.Point{X: 1, Z: 1}) {
+		t.Errorf("Expected the path from the unit through 2 waypoints, got %+v", shapes.Lines)
+	}
+	if len(shapes.Spheres) != 1 || shapes.Spheres[0].Radius != 3 {
+		t.Errorf("Expected the attack range sphere, got %+v", shapes.Spheres)
+	}
+	if len(shapes.Grids) != 1 {
+		t.Fatalf("Expected the occupancy grid, got %d grids", len(shapes.Grids))
+	}
+	if grid := shapes.Grids[0]; grid.Cells[5*grid.Width+4] != occupiedTileColor {
+		t.Errorf("Expected tile (4, 5) to be marked occupied")
+	}
+}
+
+// TestWorldTileOverlays tests the occupancy and walkability overlays
+func TestWorldTileOverlays(t *testing.T) {
+	world, err := NewHeadlessWorld(4, 4)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 1, Z: 1}, soldier)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	tile := unit.GridPos.Grid
+	world.SetOccupied(Vector2i{X: 3, Y: 0}, true)
+	world.SetWalkable(Vector2i{X: 0, Y: 3}, false)
+	world.SetHeight(Vector2i{X: 2, Y: 2}, 1.5)
+
+	dd := debugdraw.New()
+	dd.SetEnabled(debugdraw.CategoryOccupancy, true)
+	dd.SetEnabled(debugdraw.CategoryWalkable, true)
+	world.drawDebug(dd)
+	shapes := dd.Snapshot()
+	if len(shapes.Grids) != 2 {
+		t.Fatalf("Expected occupancy and walkability grids, got %d", len(shapes.Grids))
+	}
+	occupancy, walkable := shapes.Grids[0], shapes.Grids[1]
+
+	if got := occupancy.Cells[tile.Y*4+tile.X]; got != unitTileColor {
+		t.Errorf("Expected the unit's tile to be marked as occupied by a unit, got %v", got)
+	}
+	if got := occupancy.Cells[3]; got != occupiedTileColor {
+		t.Errorf("Expected tile (3, 0) to be occupied without units, got %v", got)
+	}
+	if occupancy.Heights[2*4+2] != 1.5 {
+		t.Errorf("Expected the overlay to follow terrain heights, got %v", occupancy.Heights[2*4+2])
+	}
+	if walkable.Cells[3*4] != unwalkableTileColor || walkable.Cells[0] != walkableTileColor {
+		t.Error("Expected blocked and walkable tiles to be told apart")
+	}
+
+	// A unit on a tile the grid lost track of is flagged
+	world.SetOccupied(tile, false)
+	world.drawDebug(dd)
+	if got := dd.Snapshot().Grids[0].Cells[tile.Y*4+tile.X]; got != unmarkedUnitColor {
+		t.Errorf("Expected a unit on an unoccupied tile to be flagged, got %v", got)
+	}
+}
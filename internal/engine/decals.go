@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// DecalKind is the kind of mark left on the ground
+type DecalKind int
+
+const (
+	DecalScorch DecalKind = iota // Burn mark of an explosion
+	DecalCrater                  // Blast crater at the center of a large explosion
+	DecalRubble                  // Debris of a destroyed building
+)
+
+// Decal sizes
+const (
+	craterMinRadius     = 2.0 // Explosions smaller than this leave only a scorch mark
+	buildingDecalRadius = 1.5 // Radius of the rubble a destroyed building leaves
+)
+
+// DecalSettings configures the marks explosions and destroyed buildings leave
+type DecalSettings struct {
+	MaxDecals   int           // Most decals kept; the oldest is reused when full
+	Lifetime    time.Duration // Game time a decal lasts
+	FadeTime    time.Duration // Time at the end of the lifetime it fades out over
+	Deformation bool          // Whether craters dent the terrain height
+	CraterDepth float32       // Height a crater lowers its center tile by
+	MaxDepth    float32       // Most a tile is lowered by repeated craters
+}
+
+// DefaultDecals is used when GameSettings does not set Decals
+var DefaultDecals = DecalSettings{
+	MaxDecals:   256,
+	Lifetime:    3 * time.Minute,
+	FadeTime:    30 * time.Second,
+	Deformation: false,
+	CraterDepth: 0.3,
+	MaxDepth:    1.0,
+}
+
+// Decal is a mark on the ground
+type Decal struct {
+	Kind     DecalKind
+	Position Vector3
+	Radius   float64       // In world units
+	Rotation float64       // Radians around the vertical axis, so repeated marks differ
+	Age      time.Duration // Game time since the decal was made
+	Lifetime time.Duration
+	FadeTime time.Duration
+}
+
+// Opacity returns how visible the decal is: 1 until it starts fading at the
+// end of its lifetime, then down to 0
+func (d Decal) Opacity() float64 {
+	left := d.Lifetime - d.Age
+	if left <= 0 {
+		return 0
+	}
+	if d.FadeTime <= 0 || left >= d.FadeTime {
+		return 1
+	}
+	return float64(left) / float64(d.FadeTime)
+}
+
+// decalPool holds the decals in a fixed number of slots, reusing the oldest
+// decal's slot when all are taken
+type decalPool struct {
+	mutex     sync.Mutex
+	decals    []Decal
+	next      int                  // Slot the next decal goes to once the pool is full
+	deformed  map[Vector2i]float32 // Height each tile was lowered by craters
+	generated int                  // Decals made so far, for their rotation
+}
+
+// decalSettings returns the configured decal settings
+func (w *World) decalSettings() DecalSettings {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	if w.settings.Decals == nil {
+		return DefaultDecals
+	}
+	return *w.settings.Decals
+}
+
+// AddDecal leaves a mark on the ground
+func (w *World) AddDecal(kind DecalKind, position Vector3, radius float64) {
+	settings := w.decalSettings()
+	if settings.MaxDecals <= 0 || radius <= 0 {
+		return
+	}
+
+	pool := &w.decals
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	pool.generated++
+	decal := Decal{
+		Kind:     kind,
+		Position: position,
+		Radius:   radius,
+		Rotation: math.Mod(float64(pool.generated)*2.39996, 2*math.Pi), // Golden angle steps
+		Lifetime: settings.Lifetime,
+		FadeTime: settings.FadeTime,
+	}
+	if len(pool.decals) < settings.MaxDecals {
+		pool.decals = append(pool.decals, decal)
+		return
+	}
+	// Full: replace the oldest decal
+	oldest := 0
+	for i := range pool.decals {
+		if pool.decals[i].Age > pool.decals[oldest].Age {
+			oldest = i
+		}
+	}
+	pool.decals[oldest] = decal
+}
+
+// Decals returns the decals on the ground, for rendering
+func (w *World) Decals() []Decal {
+	w.decals.mutex.Lock()
+	defer w.decals.mutex.Unlock()
+	return append([]Decal(nil), w.decals.decals...)
+}
+
+// updateDecals ages the decals and drops those past their lifetime
+func (w *World) updateDecals(deltaTime time.Duration) {
+	pool := &w.decals
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	kept := pool.decals[:0]
+	for _, decal := range pool.decals {
+		decal.Age += deltaTime
+		if decal.Age < decal.Lifetime {
+			kept = append(kept, decal)
+		}
+	}
+	pool.decals = kept
+}
+
+// markExplosion scorches the ground under an explosion, and leaves a crater
+// under large ones, denting the terrain when deformation is on
+func (w *World) markExplosion(center Vector3, radius float64) {
+	w.AddDecal(DecalScorch, center, radius)
+	if radius < craterMinRadius {
+		return
+	}
+	w.AddDecal(DecalCrater, center, radius/2)
+
+	if settings := w.decalSettings(); settings.Deformation {
+		w.deformTerrain(center, radius/2, settings.CraterDepth, settings.MaxDepth)
+	}
+}
+
+// markBuildingDestroyed leaves rubble and scorch marks where a building stood
+func (w *World) markBuildingDestroyed(building *GameBuilding) {
+	w.AddDecal(DecalScorch, building.Position, buildingDecalRadius*1.5)
+	w.AddDecal(DecalRubble, building.Position, buildingDecalRadius)
+}
+
+// deformTerrain lowers the tiles within radius of center, most at the center,
+// never lowering a tile by more than maxDepth in total
+func (w *World) deformTerrain(center Vector3, radius float64, depth, maxDepth float32) {
+	tileSize := float64(w.GetTileSize())
+	if tileSize <= 0 || depth <= 0 {
+		return
+	}
+	middle := w.WorldToGrid(center).Grid
+	reach := int(math.Ceil(radius / tileSize))
+
+	pool := &w.decals
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if pool.deformed == nil {
+		pool.deformed = make(map[Vector2i]float32)
+	}
+
+	for dy := -reach; dy <= reach; dy++ {
+		for dx := -reach; dx <= reach; dx++ {
+			distance := math.Hypot(float64(dx), float64(dy)) * tileSize
+			if distance > radius {
+				continue
+			}
+			cell := Vector2i{X: middle.X + dx, Y: middle.Y + dy}
+			if !w.isValidGridPosition(cell) {
+				continue
+			}
+			lower := depth * float32(1-distance/(radius+tileSize))
+			if room := maxDepth - pool.deformed[cell]; lower > room {
+				lower = room
+			}
+			if lower <= 0 {
+				continue
+			}
+			pool.deformed[cell] += lower
+			w.SetHeight(cell, w.GetHeight(cell)-lower)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestDecalPoolAndFade tests that decals fade with age, expire, and that a
+// full pool reuses the oldest slot
+func TestDecalPoolAndFade(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.settings.Decals = &DecalSettings{MaxDecals: 2, Lifetime: 10 * time.Second, FadeTime: 4 * time.Second}
+
+	world.AddDecal(DecalScorch, Vector3{X: 1, Z: 1}, 1)
+	world.updateDecals(3 * time.Second)
+	world.AddDecal(DecalScorch, Vector3{X: 2, Z: 2}, 1)
+	world.updateDecals(5 * time.Second)
+
+	decals := world.Decals()
+	if len(decals) != 2 {
+		t.Fatalf("Expected 2 decals, got %d", len(decals))
+	}
+	if opacity := decals[0].Opacity(); opacity != 0.5 {
+		t.Errorf("Expected the 8s old decal half faded, got opacity %.2f", opacity)
+	}
+	if opacity := decals[1].Opacity(); opacity != 1 {
+		t.Errorf("Expected the 5s old decal fully visible, got opacity %.2f", opacity)
+	}
+
+	world.AddDecal(DecalRubble, Vector3{X: 3, Z: 3}, 1)
+	decals = world.Decals()
+	if len(decals) != 2 || decals[0].Kind != DecalRubble || decals[1].Position.X != 2 {
+		t.Fatalf("Expected the oldest decal replaced by rubble, got %+v", decals)
+	}
+
+	world.updateDecals(6 * time.Second)
+	if decals = world.Decals(); len(decals) != 1 || decals[0].Kind != DecalRubble {
+		t.Errorf("Expected only the rubble left after the scorch mark expired, got %+v", decals)
+	}
+}
+
+// TestExplosionMarks tests scorch marks, craters, bounded terrain deformation
+// and the rubble of destroyed buildings
+func TestExplosionMarks(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	center := Vector3{X: 8, Z: 8}
+
+	world.markExplosion(center, 1)
+	if decals := world.Decals(); len(decals) != 1 || decals[0].Kind != DecalScorch {
+		t.Fatalf("Expected a small explosion to only scorch the ground, got %+v", decals)
+	}
+	if height := world.HeightAt(center); height != 0 {
+		t.Errorf("Expected no deformation by default, got height %.2f", height)
+	}
+
+	settings := DefaultDecals
+	settings.Deformation = true
+	world.settings.Decals = &settings
+	for i := 0; i < 10; i++ {
+		world.markExplosion(center, 4)
+	}
+	if decals := world.Decals(); decals[2].Kind != DecalCrater || decals[2].Radius != 2 {
+		t.Errorf("Expected a crater of half the blast radius, got %+v", decals[2])
+	}
+	if height := world.HeightAt(center); height != -settings.MaxDepth {
+		t.Errorf("Expected repeated craters to stop at depth %.1f, got height %.2f", settings.MaxDepth, height)
+	}
+	if height := world.HeightAt(Vector3{X: 12, Z: 8}); height != 0 {
+		t.Errorf("Expected ground outside the crater untouched, got height %.2f", height)
+	}
+
+	building, err := world.ObjectManager.CreateBuilding(2, "house", Vector3{X: 3, Z: 3}, data.NewSimpleUnit("house", 100, 0, "stone", nil))
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	before := len(world.Decals())
+	NewCombatSystem(world).ApplyBuildingDamage(building, 1000)
+	decals := world.Decals()
+	if len(decals) != before+2 || decals[len(decals)-1].Kind != DecalRubble {
+		t.Errorf("Expected a destroyed building to leave scorch and rubble, got %+v", decals[before:])
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+// SurfaceDetail is the grass and small detail meshes growing on a surface
+type SurfaceDetail struct {
+	Density     float32    `json:"density"`      // Detail objects per tile inside an unbroken patch of the surface
+	Height      float32    `json:"height"`       // Grass tuft height, in world units
+	Color       [3]float32 `json:"color"`        // Grass color at the tips; roots are shaded darker
+	Models      []string   `json:"models"`       // G3D detail meshes such as flowers and pebbles, relative to the tileset
+	ModelChance float32    `json:"model_chance"` // Share of detail objects that are meshes instead of grass tufts
+}
+
+// DefaultSurfaceDetails grow on the grass surfaces of maps whose tileset
+// defines no detail, as the original tilesets predate it
+var DefaultSurfaceDetails = map[MapSurfaceType]SurfaceDetail{
+	SurfaceGrass:          {Density: 10, Height: 0.45, Color: [3]float32{0.32, 0.50, 0.18}},
+	SurfaceSecondaryGrass: {Density: 6, Height: 0.35, Color: [3]float32{0.46, 0.50, 0.22}},
+}
+
+// detailEdgeDensity is the density left on a tile none of whose neighbours
+// share its detail, so patches thin out toward their edges
+const detailEdgeDensity = 0.25
+
+// DetailDensityMap is how densely detail grows on each tile of a map
+type DetailDensityMap struct {
+	Width, Height int
+	Density       []float32        // 0-1 per tile, row by row
+	Detail        []*SurfaceDetail // What grows on each tile, nil for bare ground
+}
+
+// At returns the density and detail of a tile, 0 and nil outside the map
+func (d *DetailDensityMap) At(x, y int) (float32, *SurfaceDetail) {
+	if x < 0 || y < 0 || x >= d.Width || y >= d.Height {
+		return 0, nil
+	}
+	i := y*d.Width + x
+	return d.Density[i], d.Detail[i]
+}
+
+// definesDetail reports whether any of the tileset's surfaces has detail
+func (t *Tileset) definesDetail() bool {
+	for _, surface := range t.Surfaces {
+		if surface.Detail != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// DetailDensity computes what grows on each tile: the tileset detail of its
+// surface, full inside a patch and thinning toward the patch edges, with
+// nothing under water or terrain objects
+func (m *Map) DetailDensity() *DetailDensityMap {
+	details := make(map[MapSurfaceType]*SurfaceDetail)
+	if m.Tileset != nil && m.Tileset.definesDetail() {
+		for i := range m.Tileset.Surfaces {
+			if surface := &m.Tileset.Surfaces[i]; surface.Detail != nil {
+				details[MapSurfaceType(surface.Index)] = surface.Detail
+			}
+		}
+	} else {
+		for surface, detail := range DefaultSurfaceDetails {
+			detail := detail
+			details[surface] = &detail
+		}
+	}
+
+	density := &DetailDensityMap{
+		Width:   m.Width,
+		Height:  m.Height,
+		Density: make([]float32, m.Width*m.Height),
+		Detail:  make([]*SurfaceDetail, m.Width*m.Height),
+	}
+	grows := func(x, y int) *SurfaceDetail {
+		if !m.IsValidPosition(x, y) || m.GetHeightAt(x, y) <= m.WaterLevel || m.GetObjectAt(x, y) != 0 {
+			return nil
+		}
+		return details[m.GetSurfaceAt(x, y)]
+	}
+
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			detail := grows(x, y)
+			if detail == nil {
+				continue
+			}
+			same := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && grows(x+dx, y+dy) == detail {
+						same++
+					}
+				}
+			}
+			i := y*m.Width + x
+			density.Detail[i] = detail
+			density.Density[i] = detailEdgeDensity + (1-detailEdgeDensity)*float32(same)/8
+		}
+	}
+	return density
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTilesetDetail tests that tileset surfaces can define grass and detail meshes
+func TestTilesetDetail(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "tilesets", "meadow")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create tileset directory: %v", err)
+	}
+	content := `<tileset>
+	<surfaces>
+		<surface>
+			<texture path="textures/grass.bmp" prob="1.0"/>
+			<detail density="14" color="0.2 0.6 0.1" model-chance="0.1">
+				<model path="models/flower.g3d"/>
+			</detail>
+		</surface>
+		<surface><texture path="textures/road.bmp" prob="1.0"/></surface>
+	</surfaces>
+</tileset>`
+	if err := os.WriteFile(filepath.Join(dir, "meadow.xml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write tileset: %v", err)
+	}
+
+	tileset, err := NewTilesetLoader(root).LoadTileset("meadow")
+	if err != nil {
+		t.Fatalf("Failed to load tileset: %v", err)
+	}
+	detail := tileset.GetSurface(1).Detail
+	if detail == nil || detail.Density != 14 || detail.Color != [3]float32{0.2, 0.6, 0.1} || detail.ModelChance != 0.1 {
+		t.Fatalf("Expected the grass detail from the XML, got %+v", detail)
+	}
+	if detail.Height != DefaultSurfaceDetails[SurfaceGrass].Height || len(detail.Models) != 1 {
+		t.Errorf("Expected the default height and one detail mesh, got %+v", detail)
+	}
+	if tileset.GetSurface(2).Detail != nil {
+		t.Error("Expected surfaces without detail to stay bare")
+	}
+}
+
+// TestDetailDensity tests that detail grows on grass, thins at patch edges and
+// stays off water, terrain objects and bare surfaces
+func TestDetailDensity(t *testing.T) {
+	const size = 10
+	m := &Map{Width: size, Height: size, WaterLevel: 1}
+	m.HeightMap = make([][]float32, size)
+	m.SurfaceMap = make([][]int8, size)
+	m.ObjectMap = make([][]int8, size)
+	for y := 0; y < size; y++ {
+		m.HeightMap[y] = make([]float32, size)
+		m.SurfaceMap[y] = make([]int8, size)
+		m.ObjectMap[y] = make([]int8, size)
+		for x := 0; x < size; x++ {
+			m.HeightMap[y][x] = 2
+			m.SurfaceMap[y][x] = int8(SurfaceGrass)
+			if x >= 5 {
+				m.SurfaceMap[y][x] = int8(SurfaceRoad) // Road on the east
+			}
+		}
+	}
+	m.HeightMap[0][0] = 0 // Puddle
+	m.ObjectMap[5][2] = 1 // Tree
+
+	density := m.DetailDensity()
+	if value, detail := density.At(2, 2); value != 1 || detail == nil || detail.Density != DefaultSurfaceDetails[SurfaceGrass].Density {
+		t.Errorf("Expected full default grass inside the patch, got %.2f %+v", value, detail)
+	}
+	if value, _ := density.At(4, 2); value >= 1 || value <= detailEdgeDensity {
+		t.Errorf("Expected grass thinning at the road, got %.2f", value)
+	}
+	for _, tile := range []Vector2i{{X: 0, Y: 0}, {X: 2, Y: 5}, {X: 7, Y: 2}, {X: -1, Y: 3}} {
+		if value, detail := density.At(tile.X, tile.Y); value != 0 || detail != nil {
+			t.Errorf("Expected no detail at %v, got %.2f", tile, value)
+		}
+	}
+
+	// A tileset defining its own detail replaces the default grass
+	flowers := &SurfaceDetail{Density: 3, Height: 0.2}
+	m.Tileset = &Tileset{Surfaces: []SurfaceType{{Index: 1}, {Index: 2}, {Index: 3, Detail: flowers}}}
+	density = m.DetailDensity()
+	if _, detail := density.At(2, 2); detail != nil {
+		t.Errorf("Expected no default grass when the tileset defines detail, got %+v", detail)
+	}
+	if _, detail := density.At(7, 2); detail != flowers {
+		t.Errorf("Expected the tileset detail on its surface, got %+v", detail)
+	}
+}
@@ -0,0 +1,294 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// determinismEpoch is the manual clock's start in determinism runs, so wall
+// time stamps hash the same in every run
+var determinismEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// StateHash returns a hash of the simulation state that must match on every
+// machine running the same match: game time, player resources, combat rolls
+// drawn, and every unit, building and resource node. Floats are hashed bit for bit, so any
+// drift shows up. Presentation-only state is left out.
+func (w *World) StateHash() uint64 {
+	h := stateHasher{hash: fnv.New64a()}
+
+	w.mutex.RLock()
+	h.int(int64(w.gameTime))
+	playerIDs := make([]int, 0, len(w.players))
+	for id := range w.players {
+		playerIDs = append(playerIDs, id)
+	}
+	sort.Ints(playerIDs)
+	for _, id := range playerIDs {
+		player := w.players[id]
+		h.int(int64(id))
+		h.bool(player.IsActive)
+		h.resources(player.Resources)
+	}
+	nodeIDs := make([]int, 0, len(w.resources))
+	for id := range w.resources {
+		nodeIDs = append(nodeIDs, id)
+	}
+	sort.Ints(nodeIDs)
+	for _, id := range nodeIDs {
+		node := w.resources[id]
+		h.int(int64(id))
+		h.string(node.ResourceType)
+		h.vector(node.Position)
+		h.int(int64(node.Amount))
+	}
+	w.mutex.RUnlock()
+	if source := w.combatRNG(); source != nil {
+		h.int(int64(source.state.Draws))
+	}
+
+	for _, id := range playerIDs {
+		units := w.ObjectManager.GetUnitsForPlayer(id)
+		for _, unitID := range sortedKeys(units) {
+			units[unitID].hashState(&h)
+		}
+		buildings := w.ObjectManager.GetBuildingsForPlayer(id)
+		for _, buildingID := range sortedKeys(buildings) {
+			buildings[buildingID].hashState(&h)
+		}
+	}
+	return h.hash.Sum64()
+}
+
+// sortedKeys returns the IDs of an object map in ascending order
+func sortedKeys[T any](objects map[int]T) []int {
+	ids := make([]int, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// hashState adds a unit's simulation state to a state hash
+func (u *GameUnit) hashState(h *stateHasher) {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	h.int(int64(u.ID))
+	h.string(u.UnitType)
+	h.vector(u.Position)
+	h.int(int64(u.Health))
+	h.int(int64(u.Energy))
+	h.int(int64(u.State))
+	h.int(int64(u.GarrisonedIn))
+	h.resources(u.CarriedResources)
+	h.float(float64(u.BuildProgress))
+	if u.CurrentCommand != nil {
+		h.int(int64(u.CurrentCommand.Type))
+		if u.CurrentCommand.Target != nil {
+			h.vector(*u.CurrentCommand.Target)
+		}
+	}
+	h.int(int64(len(u.CommandQueue)))
+	if u.AttackTarget != nil {
+		h.int(int64(u.AttackTarget.ID))
+	}
+	h.string(u.Animation.Clip)
+	h.float(u.Animation.Time)
+}
+
+// hashState adds a building's simulation state to a state hash
+func (b *GameBuilding) hashState(h *stateHasher) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	h.int(int64(b.ID))
+	h.string(b.BuildingType)
+	h.vector(b.Position)
+	h.int(int64(b.Health))
+	h.bool(b.IsBuilt)
+	h.float(float64(b.BuildProgress))
+	h.int(int64(b.UpgradeLevel))
+	if b.CurrentProduction != nil {
+		h.string(b.CurrentProduction.ItemName)
+		h.float(float64(b.CurrentProduction.Progress))
+	}
+	for _, item := range b.ProductionQueue {
+		h.string(item.ItemName)
+	}
+}
+
+// stateHasher writes values to a hash in a fixed binary form
+type stateHasher struct {
+	hash hash.Hash64
+	buf  [8]byte
+}
+
+func (h *stateHasher) int(value int64) {
+	binary.LittleEndian.PutUint64(h.buf[:], uint64(value))
+	h.hash.Write(h.buf[:])
+}
+
+func (h *stateHasher) float(value float64) {
+	binary.LittleEndian.PutUint64(h.buf[:], math.Float64bits(value))
+	h.hash.Write(h.buf[:])
+}
+
+func (h *stateHasher) bool(value bool) {
+	if value {
+		h.int(1)
+	} else {
+		h.int(0)
+	}
+}
+
+func (h *stateHasher) string(value string) {
+	h.int(int64(len(value)))
+	h.hash.Write([]byte(value))
+}
+
+func (h *stateHasher) vector(v Vector3) {
+	h.float(v.X)
+	h.float(v.Y)
+	h.float(v.Z)
+}
+
+// resources hashes a resource map in name order
+func (h *stateHasher) resources(amounts map[string]int) {
+	names := make([]string, 0, len(amounts))
+	for name, amount := range amounts {
+		if amount != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	h.int(int64(len(names)))
+	for _, name := range names {
+		h.string(name)
+		h.int(int64(amounts[name]))
+	}
+}
+
+// DeterminismScenario is a scripted match played headless for determinism
+// checks. Every run starts from a fresh world with a manual clock, so the
+// same scenario must give the same state hash after every tick.
+type DeterminismScenario struct {
+	Width, Height int
+	Ticks         int
+	TickDuration  time.Duration
+	Setup         func(w *World) error     // Places the starting units, buildings and resources
+	Script        func(w *World, tick int) // Issues the commands given before a tick (may be nil)
+}
+
+// Run plays the scenario and returns the state hash after every tick
+func (s DeterminismScenario) Run() ([]uint64, error) {
+	if s.Ticks <= 0 || s.TickDuration <= 0 {
+		return nil, fmt.Errorf("determinism scenario needs positive ticks and tick duration")
+	}
+	world, err := NewHeadlessWorld(s.Width, s.Height)
+	if err != nil {
+		return nil, err
+	}
+	clock := NewManualClock(determinismEpoch)
+	world.SetClock(clock)
+	if s.Setup != nil {
+		if err := s.Setup(world); err != nil {
+			return nil, fmt.Errorf("scenario setup failed: %w", err)
+		}
+	}
+
+	hashes := make([]uint64, s.Ticks)
+	for tick := 0; tick < s.Ticks; tick++ {
+		if s.Script != nil {
+			s.Script(world, tick)
+		}
+		clock.Advance(s.TickDuration)
+		world.Update(s.TickDuration)
+		hashes[tick] = world.StateHash()
+	}
+	return hashes, nil
+}
+
+// DivergenceError reports the first tick at which two runs of a match differ
+type DivergenceError struct {
+	Tick      int    // First tick whose state differs
+	Want, Got uint64 // State hashes of the reference and compared run
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("simulation diverged at tick %d: state hash %016x, expected %016x", e.Tick, e.Got, e.Want)
+}
+
+// CompareHashes checks two per-tick hash traces, returning a
+// *DivergenceError at the first tick that differs
+func CompareHashes(want, got []uint64) error {
+	for tick := 0; tick < len(want) && tick < len(got); tick++ {
+		if want[tick] != got[tick] {
+			return &DivergenceError{Tick: tick, Want: want[tick], Got: got[tick]}
+		}
+	}
+	if len(want) != len(got) {
+		return fmt.Errorf("hash traces cover %d and %d ticks", len(want), len(got))
+	}
+	return nil
+}
+
+// CheckDeterminism plays a scenario twice and compares the runs tick by tick.
+// It returns the first run's hashes, for comparing with other machines.
+func CheckDeterminism(s DeterminismScenario) ([]uint64, error) {
+	first, err := s.Run()
+	if err != nil {
+		return nil, err
+	}
+	second, err := s.Run()
+	if err != nil {
+		return nil, err
+	}
+	return first, CompareHashes(first, second)
+}
+
+// WriteHashTrace saves a hash trace, one hex hash per tick, so runs on other
+// architectures can be compared with it
+func WriteHashTrace(path string, hashes []uint64) error {
+	var b strings.Builder
+	for _, hash := range hashes {
+		fmt.Fprintf(&b, "%016x\n", hash)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write hash trace: %w", err)
+	}
+	return nil
+}
+
+// ReadHashTrace loads a hash trace written by WriteHashTrace
+func ReadHashTrace(path string) ([]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hash trace: %w", err)
+	}
+	defer file.Close()
+
+	var hashes []uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		hash, err := strconv.ParseUint(line, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("hash trace %s line %d: %w", path, len(hashes)+1, err)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, scanner.Err()
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// Environment variables of the cross-architecture determinism check: one
+// build writes its hash trace, the others compare theirs with it
+const (
+	hashTraceWriteEnv   = "TERAGLEST_HASH_TRACE"
+	hashTraceCompareEnv = "TERAGLEST_HASH_COMPARE"
+)
+
+// skirmishScenario is a small match: two armies meet in the middle while
+// workers gather, and a barracks trains reinforcements
+func skirmishScenario() DeterminismScenario {
+	var soldiers [2][]*GameUnit
+	var workers []*GameUnit
+	var node *ResourceNode
+	return DeterminismScenario{
+		Width: 32, Height: 32, Ticks: 1200, TickDuration: 50 * time.Millisecond,
+		Setup: func(w *World) error {
+			soldiers, workers = [2][]*GameUnit{}, nil
+			soldier := data.NewSimpleUnit("soldier", 200, 2, "leather", map[string]int{"gold": 50})
+			worker := data.NewSimpleUnit("worker", 100, 0, "leather", map[string]int{"gold": 25})
+			for player := 1; player <= 2; player++ {
+				x := 4.5 + float64(player-1)*22
+				for i := 0; i < 6; i++ {
+					unit, err := w.ObjectManager.CreateUnit(player, "soldier", Vector3{X: x, Z: 8.5 + float64(i)*2}, soldier)
+					if err != nil {
+						return err
+					}
+					soldiers[player-1] = append(soldiers[player-1], unit)
+				}
+			}
+			for i := 0; i < 3; i++ {
+				unit, err := w.ObjectManager.CreateUnit(1, "worker", Vector3{X: 2.5, Z: 24.5 + float64(i)}, worker)
+				if err != nil {
+					return err
+				}
+				workers = append(workers, unit)
+			}
+			node = &ResourceNode{ID: 9000, ResourceType: "gold", Position: Vector3{X: 6.5, Z: 28.5}, Amount: 2000, MaxAmount: 2000, IsDepletable: true}
+			w.resources[node.ID] = node
+
+			assets := data.NewMemoryAssetProvider()
+			assets.AddUnit("tech", soldier)
+			w.assetMgr = assets
+			w.players[2].FactionName = "tech"
+			w.players[2].FactionData = &data.FactionDefinition{Name: "tech"}
+
+			barracks, err := w.ObjectManager.CreateBuilding(2, "barracks", Vector3{X: 28.5, Z: 28.5}, data.NewSimpleUnit("barracks", 1000, 0, "stone", nil))
+			if err != nil {
+				return err
+			}
+			barracks.IsBuilt = true
+			barracks.ProductionQueue = []ProductionItem{
+				{ItemType: "unit", ItemName: "soldier", Duration: 5 * time.Second},
+				{ItemType: "unit", ItemName: "soldier", Duration: 5 * time.Second},
+			}
+			return nil
+		},
+		Script: func(w *World, tick int) {
+			processor := w.commandProcessor
+			switch tick {
+			case 0:
+				for _, unit := range soldiers[0] {
+					processor.IssueCommand(unit.ID, CreateMoveCommand(Vector3{X: 16.5, Z: unit.Position.Z}, false))
+				}
+				for _, unit := range workers {
+					processor.IssueCommand(unit.ID, CreateGatherCommand(node, false))
+				}
+			case 40:
+				for i, unit := range soldiers[1] {
+					processor.IssueCommand(unit.ID, CreateAttackCommand(soldiers[0][i], false))
+				}
+			case 200:
+				for _, unit := range soldiers[0] {
+					processor.IssueCommand(unit.ID, CreateMoveCommand(Vector3{X: 10.5, Z: 16.5}, true))
+				}
+			}
+		},
+	}
+}
+
+// TestDeterminism plays the same match twice and fails at the first tick
+// whose state hash differs. Set TERAGLEST_HASH_TRACE to save the hashes and
+// TERAGLEST_HASH_COMPARE to compare with a trace saved by another build,
+// e.g. another GOARCH.
+func TestDeterminism(t *testing.T) {
+	hashes, err := CheckDeterminism(skirmishScenario())
+	var divergence *DivergenceError
+	if errors.As(err, &divergence) {
+		t.Fatalf("Same match, different outcome: %v", divergence)
+	}
+	if err != nil {
+		t.Fatalf("Determinism check failed: %v", err)
+	}
+
+	if path := os.Getenv(hashTraceWriteEnv); path != "" {
+		if err := WriteHashTrace(path, hashes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if path := os.Getenv(hashTraceCompareEnv); path != "" {
+		reference, err := ReadHashTrace(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := CompareHashes(reference, hashes); err != nil {
+			t.Fatalf("Match differs from the trace in %s: %v", path, err)
+		}
+	}
+}
+
+// TestCompareHashes tests finding the first divergent tick of two traces
+func TestCompareHashes(t *testing.T) {
+	if err := CompareHashes([]uint64{1, 2, 3}, []uint64{1, 2, 3}); err != nil {
+		t.Errorf("Expected identical traces to match: %v", err)
+	}
+	var divergence *DivergenceError
+	if err := CompareHashes([]uint64{1, 2, 3}, []uint64{1, 5, 6}); !errors.As(err, &divergence) || divergence.Tick != 1 {
+		t.Errorf("Expected divergence at tick 1, got %v", err)
+	}
+	if err := CompareHashes([]uint64{1, 2}, []uint64{1}); err == nil {
+		t.Error("Expected traces of different length to differ")
+	}
+
+	path := t.TempDir() + "/trace.txt"
+	trace := []uint64{0, 42, 0xfedcba9876543210}
+	if err := WriteHashTrace(path, trace); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadHashTrace(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CompareHashes(trace, loaded); err != nil {
+		t.Errorf("Expected the trace to round-trip: %v", err)
+	}
+}
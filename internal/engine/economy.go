@@ -0,0 +1,227 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Economy statistics tuning
+const (
+	EconomyWindow          = 5 * time.Minute // Span of game time the economy report covers
+	economySampleInterval  = 5 * time.Second // Game time between stockpile samples
+	economyTopConsumers    = 3               // Consumers listed per resource
+	economyTransactionType = "deduction"     // Transaction type counted as an expense
+)
+
+// EconomyReport summarizes a player's recent income and spending
+type EconomyReport struct {
+	PlayerID  int                     `json:"player_id"`
+	GameTime  time.Duration           `json:"game_time"`
+	Window    time.Duration           `json:"window"` // Span covered; shorter than EconomyWindow early in a match
+	Resources map[string]ResourceFlow `json:"resources"`
+}
+
+// ResourceFlow is the recent flow of one resource
+type ResourceFlow struct {
+	Stock        int               `json:"stock"`         // Current amount
+	IncomeRate   float64           `json:"income_rate"`   // Gained per minute over the window
+	ExpenseRate  float64           `json:"expense_rate"`  // Spent per minute over the window
+	Trend        []int             `json:"trend"`         // Stockpile samples, oldest first, ending with the current amount
+	TopConsumers []EconomyConsumer `json:"top_consumers"` // Largest spending purposes, largest first
+}
+
+// EconomyConsumer is a purpose resources were spent on
+type EconomyConsumer struct {
+	Source string `json:"source"`
+	Amount int    `json:"amount"`
+}
+
+// economyEntry is one logged resource transaction
+type economyEntry struct {
+	time     time.Duration
+	resource string
+	source   string
+	amount   int
+	expense  bool
+}
+
+// economySample is a player's stockpile at one moment
+type economySample struct {
+	time  time.Duration
+	stock map[string]int
+}
+
+// economyLedger holds a player's transactions and samples within the window
+type economyLedger struct {
+	entries []economyEntry
+	samples []economySample
+}
+
+// economyTracker keeps the recent resource transactions of every player
+type economyTracker struct {
+	mutex      sync.Mutex
+	ledgers    map[int]*economyLedger
+	lastSample time.Duration
+	sampled    bool // Whether any sample has been taken
+}
+
+// ledger returns a player's ledger, creating it (lock must be held)
+func (et *economyTracker) ledger(playerID int) *economyLedger {
+	if et.ledgers == nil {
+		et.ledgers = make(map[int]*economyLedger)
+	}
+	ledger, exists := et.ledgers[playerID]
+	if !exists {
+		ledger = &economyLedger{}
+		et.ledgers[playerID] = ledger
+	}
+	return ledger
+}
+
+// record logs a resource transaction
+func (et *economyTracker) record(playerID int, resource string, amount int, source, transactionType string, now time.Duration) {
+	et.mutex.Lock()
+	defer et.mutex.Unlock()
+
+	ledger := et.ledger(playerID)
+	ledger.entries = append(ledger.entries, economyEntry{
+		time:     now,
+		resource: resource,
+		source:   source,
+		amount:   amount,
+		expense:  transactionType == economyTransactionType,
+	})
+}
+
+// sample stores every player's stockpile once per sample interval and drops
+// data older than the window (world lock must be held)
+func (et *economyTracker) sample(players map[int]*Player, now time.Duration) {
+	et.mutex.Lock()
+	defer et.mutex.Unlock()
+
+	if et.sampled && now-et.lastSample < economySampleInterval {
+		return
+	}
+	et.lastSample, et.sampled = now, true
+
+	for _, player := range players {
+		ledger := et.ledger(player.ID)
+		ledger.samples = append(ledger.samples, economySample{time: now, stock: copyIntMap(player.Resources)})
+	}
+
+	cutoff := now - EconomyWindow
+	for _, ledger := range et.ledgers {
+		kept := 0
+		for kept < len(ledger.entries) && ledger.entries[kept].time < cutoff {
+			kept++
+		}
+		ledger.entries = ledger.entries[kept:]
+		kept = 0
+		for kept < len(ledger.samples) && ledger.samples[kept].time < cutoff {
+			kept++
+		}
+		ledger.samples = ledger.samples[kept:]
+	}
+}
+
+// report builds a player's economy report from the current stockpile
+func (et *economyTracker) report(playerID int, stock map[string]int, now time.Duration) EconomyReport {
+	et.mutex.Lock()
+	defer et.mutex.Unlock()
+
+	window := EconomyWindow
+	if now < window {
+		window = now
+	}
+	report := EconomyReport{PlayerID: playerID, GameTime: now, Window: window, Resources: make(map[string]ResourceFlow)}
+
+	flows := make(map[string]*ResourceFlow)
+	flow := func(resource string) *ResourceFlow {
+		if f, exists := flows[resource]; exists {
+			return f
+		}
+		f := &ResourceFlow{Stock: stock[resource]}
+		flows[resource] = f
+		return f
+	}
+	for resource := range stock {
+		flow(resource)
+	}
+
+	ledger := et.ledgers[playerID]
+	if ledger == nil {
+		ledger = &economyLedger{}
+	}
+	cutoff := now - window
+	income := make(map[string]int)
+	expense := make(map[string]int)
+	consumers := make(map[string]map[string]int)
+	for _, entry := range ledger.entries {
+		if entry.time < cutoff {
+			continue
+		}
+		flow(entry.resource)
+		if !entry.expense {
+			income[entry.resource] += entry.amount
+			continue
+		}
+		expense[entry.resource] += entry.amount
+		if consumers[entry.resource] == nil {
+			consumers[entry.resource] = make(map[string]int)
+		}
+		consumers[entry.resource][economySourceName(entry.source)] += entry.amount
+	}
+
+	minutes := window.Minutes()
+	for resource, f := range flows {
+		if minutes > 0 {
+			f.IncomeRate = float64(income[resource]) / minutes
+			f.ExpenseRate = float64(expense[resource]) / minutes
+		}
+		for _, sample := range ledger.samples {
+			if sample.time >= cutoff && sample.time < now {
+				f.Trend = append(f.Trend, sample.stock[resource])
+			}
+		}
+		f.Trend = append(f.Trend, f.Stock)
+
+		for source, amount := range consumers[resource] {
+			f.TopConsumers = append(f.TopConsumers, EconomyConsumer{Source: source, Amount: amount})
+		}
+		sort.Slice(f.TopConsumers, func(i, j int) bool {
+			if f.TopConsumers[i].Amount != f.TopConsumers[j].Amount {
+				return f.TopConsumers[i].Amount > f.TopConsumers[j].Amount
+			}
+			return f.TopConsumers[i].Source < f.TopConsumers[j].Source
+		})
+		if len(f.TopConsumers) > economyTopConsumers {
+			f.TopConsumers = f.TopConsumers[:economyTopConsumers]
+		}
+		report.Resources[resource] = *f
+	}
+	return report
+}
+
+// economySourceName groups per-player transaction sources, e.g. every
+// "tribute to player N" counts as "tribute"
+func economySourceName(source string) string {
+	if strings.HasPrefix(source, "tribute ") {
+		return "tribute"
+	}
+	return source
+}
+
+// GetEconomyReport returns a player's income and expense rates, stockpile
+// trends and top consumers over the last EconomyWindow of game time
+func (w *World) GetEconomyReport(playerID int) EconomyReport {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	var stock map[string]int
+	if player := w.players[playerID]; player != nil {
+		stock = copyIntMap(player.Resources)
+	}
+	return w.economy.report(playerID, stock, w.gameTime)
+}
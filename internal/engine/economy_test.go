@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+// TestEconomyReport tests income and expense rates, trends and top consumers
+func TestEconomyReport(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	for i := 0; i < 12; i++ {
+		world.Update(economySampleInterval)
+	}
+	if world.GetGameTime() != time.Minute {
+		t.Fatalf("Expected one minute of game time, got %v", world.GetGameTime())
+	}
+
+	if err := world.AddResources(1, map[string]int{"gold": 120}, "resource_dropoff"); err != nil {
+		t.Fatalf("Failed to add resources: %v", err)
+	}
+	for _, spend := range []struct {
+		amount  int
+		purpose string
+	}{
+		{30, "unit_production"},
+		{20, "unit_production"},
+		{40, "building_construction"},
+		{10, "upgrade"},
+		{5, "tribute to player 2"},
+		{5, "tribute to player 3"},
+	} {
+		if err := world.DeductResources(1, map[string]int{"gold": spend.amount}, spend.purpose); err != nil {
+			t.Fatalf("Failed to deduct resources: %v", err)
+		}
+	}
+
+	report := world.GetEconomyReport(1)
+	if report.Window != time.Minute {
+		t.Errorf("Expected the window to be the elapsed minute, got %v", report.Window)
+	}
+	gold := report.Resources["gold"]
+	if gold.Stock != 1010 || gold.IncomeRate != 120 || gold.ExpenseRate != 110 {
+		t.Errorf("Expected 1010 gold earned at 120/min and spent at 110/min, got %+v", gold)
+	}
+	if len(gold.Trend) != 12 || gold.Trend[0] != 1000 || gold.Trend[len(gold.Trend)-1] != 1010 {
+		t.Errorf("Expected 12 trend samples from 1000 to 1010, got %v", gold.Trend)
+	}
+	want := []EconomyConsumer{{"unit_production", 50}, {"building_construction", 40}, {"tribute", 10}}
+	if len(gold.TopConsumers) != len(want) {
+		t.Fatalf("Expected top consumers %v, got %v", want, gold.TopConsumers)
+	}
+	for i := range want {
+		if gold.TopConsumers[i] != want[i] {
+			t.Errorf("Expected top consumers %v, got %v", want, gold.TopConsumers)
+			break
+		}
+	}
+	if wood := report.Resources["wood"]; wood.IncomeRate != 0 || wood.ExpenseRate != 0 || wood.Stock != 1000 {
+		t.Errorf("Expected idle wood at 1000, got %+v", wood)
+	}
+
+	// Transactions older than the window drop out of the report
+	for elapsed := time.Duration(0); elapsed <= EconomyWindow; elapsed += economySampleInterval {
+		world.Update(economySampleInterval)
+	}
+	gold = world.GetEconomyReport(1).Resources["gold"]
+	if gold.IncomeRate != 0 || gold.ExpenseRate != 0 || len(gold.TopConsumers) != 0 {
+		t.Errorf("Expected old transactions to expire, got %+v", gold)
+	}
+	if len(gold.Trend) > int(EconomyWindow/economySampleInterval)+1 {
+		t.Errorf("Expected at most one window of trend samples, got %d", len(gold.Trend))
+	}
+}
@@ -0,0 +1,341 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Environment defaults
+const (
+	DefaultEnvironmentStep = 100 * time.Millisecond // Game time one Step simulates when the config sets none
+	environmentKillReward  = 1.0                    // DefaultReward per enemy unit or building destroyed
+	environmentWinReward   = 10.0                   // DefaultReward for winning or losing the episode
+)
+
+// environmentEpoch is where every episode's clock starts, so episodes with
+// the same setup and actions play out the same
+var environmentEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Observation channels, in tensor order
+const (
+	ChannelOccupancy    = iota // 1 where a unit, building or obstacle fills the tile
+	ChannelVisibility          // 1 where the agent has sight of the tile
+	ChannelOwnUnits            // Health share of the agent's unit on the tile
+	ChannelEnemyUnits          // Health share of a visible enemy unit on the tile
+	ObservationChannels        // Number of channels
+)
+
+// Observation is what the agent sees after a reset or step: a
+// channels x height x width tensor in row-major order, plus its stockpile
+type Observation struct {
+	Shape     [3]int         // Channels, height, width
+	Data      []float32      // Tensor values, Data[(c*height+y)*width+x]
+	Resources map[string]int // The agent's resources
+	GameTime  time.Duration  // Game time since the episode started
+}
+
+// At returns the tensor value of a channel at a tile
+func (o Observation) At(channel, x, y int) float32 {
+	return o.Data[(channel*o.Shape[1]+y)*o.Shape[2]+x]
+}
+
+// EnvironmentState summarises the game for reward hooks
+type EnvironmentState struct {
+	OwnUnits       int  // Living units of the agent
+	OwnBuildings   int  // Standing buildings of the agent
+	OwnHealth      int  // Total health of the agent's units and buildings
+	EnemyUnits     int  // Living enemy units
+	EnemyBuildings int  // Standing enemy buildings
+	EnemyHealth    int  // Total health of enemy units and buildings
+	Resources      int  // Sum of the agent's stockpiles
+	Won            bool // Whether every enemy is out of the game
+	Lost           bool // Whether the agent is out of the game
+}
+
+// RewardFunc scores one step from the game before and after it
+type RewardFunc func(before, after EnvironmentState) float64
+
+// DefaultReward rewards trading health favourably, destroying enemy units
+// and buildings, and winning
+func DefaultReward(before, after EnvironmentState) float64 {
+	reward := float64((before.EnemyHealth-after.EnemyHealth)-(before.OwnHealth-after.OwnHealth)) / 100
+	reward += environmentKillReward * float64((before.EnemyUnits+before.EnemyBuildings)-(after.EnemyUnits+after.EnemyBuildings))
+	reward -= environmentKillReward * float64((before.OwnUnits+before.OwnBuildings)-(after.OwnUnits+after.OwnBuildings))
+	switch {
+	case after.Won:
+		reward += environmentWinReward
+	case after.Lost:
+		reward -= environmentWinReward
+	}
+	return reward
+}
+
+// EnvAction is an order the agent gives one of its units
+type EnvAction struct {
+	UnitID  int
+	Command UnitCommand
+}
+
+// Policy chooses the actions of a scripted player each step
+type Policy func(world *World, playerID int) []EnvAction
+
+// EnvironmentConfig sets up the episodes of an environment
+type EnvironmentConfig struct {
+	Width, Height int                // Map size in tiles
+	PlayerID      int                // Player the agent controls (0 = player 1)
+	StepDuration  time.Duration      // Game time each step simulates (0 = DefaultEnvironmentStep)
+	MaxSteps      int                // Steps before an episode is truncated (0 = no limit)
+	Setup         func(*World) error // Places units and buildings at the start of each episode
+	Reward        RewardFunc         // Reward hook (nil = DefaultReward)
+	Opponents     map[int]Policy     // Scripted players acting alongside the agent
+}
+
+// StepResult is the outcome of one environment step
+type StepResult struct {
+	Observation Observation
+	Reward      float64
+	Done        bool             // The episode ended in a win or loss
+	Truncated   bool             // The episode hit MaxSteps
+	State       EnvironmentState // Game summary after the step
+}
+
+// Environment wraps a headless world in a reset/step interface for bots
+// and for training agents against the engine. Episodes run on a manual
+// clock, so they are reproducible and as fast as the machine allows.
+type Environment struct {
+	config EnvironmentConfig
+	world  *World
+	clock  *ManualClock
+	steps  int
+	state  EnvironmentState
+	done   bool
+}
+
+// NewEnvironment creates an environment; call Reset to start an episode
+func NewEnvironment(config EnvironmentConfig) *Environment {
+	if config.PlayerID == 0 {
+		config.PlayerID = 1
+	}
+	if config.StepDuration <= 0 {
+		config.StepDuration = DefaultEnvironmentStep
+	}
+	if config.Reward == nil {
+		config.Reward = DefaultReward
+	}
+	return &Environment{config: config}
+}
+
+// World returns the world of the current episode
+func (e *Environment) World() *World {
+	return e.world
+}
+
+// Reset starts a new episode and returns its first observation
+func (e *Environment) Reset() (Observation, error) {
+	world, err := NewHeadlessWorld(e.config.Width, e.config.Height)
+	if err != nil {
+		return Observation{}, fmt.Errorf("failed to create world: %w", err)
+	}
+	if world.GetPlayer(e.config.PlayerID) == nil {
+		return Observation{}, fmt.Errorf("player %d is not in the game", e.config.PlayerID)
+	}
+	e.clock = NewManualClock(environmentEpoch)
+	world.SetClock(e.clock)
+	if e.config.Setup != nil {
+		if err := e.config.Setup(world); err != nil {
+			return Observation{}, fmt.Errorf("failed to set up episode: %w", err)
+		}
+	}
+
+	e.world, e.steps, e.done = world, 0, false
+	e.state = e.summarise()
+	return e.observe(), nil
+}
+
+// Step gives the agent's actions, simulates one step of game time and
+// returns what followed. An action for a unit the agent does not own, or
+// that the unit cannot carry out, fails the step before any time passes;
+// the actions before it stand.
+func (e *Environment) Step(actions []EnvAction) (StepResult, error) {
+	if e.world == nil {
+		return StepResult{}, fmt.Errorf("environment has not been reset")
+	}
+	if e.done {
+		return StepResult{}, fmt.Errorf("episode is over; call Reset")
+	}
+
+	for _, action := range actions {
+		if err := e.issue(e.config.PlayerID, action); err != nil {
+			return StepResult{}, err
+		}
+	}
+	opponents := make([]int, 0, len(e.config.Opponents))
+	for playerID := range e.config.Opponents {
+		opponents = append(opponents, playerID)
+	}
+	sort.Ints(opponents)
+	for _, playerID := range opponents {
+		// Scripted players act as well as they can; bad orders are dropped
+		for _, action := range e.config.Opponents[playerID](e.world, playerID) {
+			e.issue(playerID, action)
+		}
+	}
+
+	e.clock.Advance(e.config.StepDuration)
+	e.world.Update(e.config.StepDuration)
+	e.steps++
+
+	before := e.state
+	e.state = e.summarise()
+	result := StepResult{
+		Observation: e.observe(),
+		Reward:      e.config.Reward(before, e.state),
+		Done:        e.state.Won || e.state.Lost,
+		State:       e.state,
+	}
+	result.Truncated = !result.Done && e.config.MaxSteps > 0 && e.steps >= e.config.MaxSteps
+	e.done = result.Done || result.Truncated
+	return result, nil
+}
+
+// issue gives a player's order to one of its units
+func (e *Environment) issue(playerID int, action EnvAction) error {
+	unit := e.world.ObjectManager.GetUnit(action.UnitID)
+	if unit == nil || unit.PlayerID != playerID {
+		return fmt.Errorf("unit %d does not belong to player %d", action.UnitID, playerID)
+	}
+	if err := e.world.commandProcessor.IssueCommand(action.UnitID, action.Command); err != nil {
+		return fmt.Errorf("unit %d: %w", action.UnitID, err)
+	}
+	return nil
+}
+
+// summarise counts what the agent and its enemies have left
+func (e *Environment) summarise() EnvironmentState {
+	state := EnvironmentState{Won: true}
+	for id, player := range e.world.GetAllPlayers() {
+		units, buildings, health := 0, 0, 0
+		for _, unit := range e.world.ObjectManager.GetUnitsForPlayer(id) {
+			if unit.IsAlive() {
+				units++
+				health += unit.GetHealth()
+			}
+		}
+		for _, building := range e.world.ObjectManager.GetBuildingsForPlayer(id) {
+			if building.IsAlive() {
+				buildings++
+				health += building.GetHealth()
+			}
+		}
+		inGame := player.IsActive && units+buildings > 0
+
+		if id == e.config.PlayerID {
+			state.OwnUnits, state.OwnBuildings, state.OwnHealth = units, buildings, health
+			state.Lost = !inGame
+			for _, amount := range player.Resources {
+				state.Resources += amount
+			}
+			continue
+		}
+		if e.world.AreAllied(e.config.PlayerID, id) {
+			continue
+		}
+		state.EnemyUnits += units
+		state.EnemyBuildings += buildings
+		state.EnemyHealth += health
+		if inGame {
+			state.Won = false
+		}
+	}
+	if state.Lost {
+		state.Won = false
+	}
+	return state
+}
+
+// observe builds the agent's observation of the world
+func (e *Environment) observe() Observation {
+	world, width, height := e.world, e.world.Width, e.world.Height
+	obs := Observation{
+		Shape:     [3]int{ObservationChannels, height, width},
+		Data:      make([]float32, ObservationChannels*height*width),
+		Resources: make(map[string]int),
+		GameTime:  world.GetGameTime(),
+	}
+	set := func(channel int, cell Vector2i, value float32) {
+		if cell.X >= 0 && cell.X < width && cell.Y >= 0 && cell.Y < height {
+			obs.Data[(channel*height+cell.Y)*width+cell.X] = value
+		}
+	}
+	visible := func(cell Vector2i) bool {
+		return obs.At(ChannelVisibility, cell.X, cell.Y) > 0
+	}
+
+	world.gridMutex.RLock()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if world.occupancyGrid[y][x] || !world.walkableGrid[y][x] {
+				set(ChannelOccupancy, Vector2i{X: x, Y: y}, 1)
+			}
+		}
+	}
+	world.gridMutex.RUnlock()
+
+	// Sight of the agent and its allies
+	for id := range world.GetAllPlayers() {
+		if id != e.config.PlayerID && !world.AreAllied(e.config.PlayerID, id) {
+			continue
+		}
+		for _, unit := range world.ObjectManager.GetUnitsForPlayer(id) {
+			if unit.IsAlive() {
+				e.reveal(set, unit.Position, world.SightRange(unit))
+			}
+		}
+		for _, building := range world.ObjectManager.GetBuildingsForPlayer(id) {
+			e.reveal(set, building.Position, BuildingSightRange(building))
+		}
+	}
+
+	for id := range world.GetAllPlayers() {
+		channel := ChannelEnemyUnits
+		if id == e.config.PlayerID {
+			channel = ChannelOwnUnits
+		}
+		for _, unit := range world.ObjectManager.GetUnitsForPlayer(id) {
+			if !unit.IsAlive() {
+				continue
+			}
+			cell := unit.GetGridPosition().Grid
+			if channel == ChannelEnemyUnits && (world.AreAllied(e.config.PlayerID, id) || !visible(cell)) {
+				continue
+			}
+			share := float32(1)
+			if maxHealth := unit.GetMaxHealth(); maxHealth > 0 {
+				share = float32(unit.GetHealth()) / float32(maxHealth)
+			}
+			set(channel, cell, share)
+		}
+	}
+
+	if player := world.GetPlayer(e.config.PlayerID); player != nil {
+		for resource, amount := range player.Resources {
+			obs.Resources[resource] = amount
+		}
+	}
+	return obs
+}
+
+// reveal marks the tiles within sight tiles of a position as visible
+func (e *Environment) reveal(set func(int, Vector2i, float32), position Vector3, sight float64) {
+	center := e.world.WorldToGrid(position).Grid
+	radius := int(math.Ceil(sight))
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if float64(dx*dx+dy*dy) <= sight*sight {
+				set(ChannelVisibility, Vector2i{X: center.X + dx, Y: center.Y + dy}, 1)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestEnvironment tests that episodes reset to the same start, observe the
+// agent's sight, and reward the agent for winning a fight
+func TestEnvironment(t *testing.T) {
+	def := data.NewSimpleUnit("soldier", 50, 0, "leather", nil)
+	def.Unit.Parameters.Sight.Value = 4
+	var soldier, enemy int
+	env := NewEnvironment(EnvironmentConfig{
+		Width: 24, Height: 24, MaxSteps: 200,
+		Setup: func(world *World) error {
+			unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 5.5, Z: 5.5}, def)
+			if err != nil {
+				return err
+			}
+			unit.AttackDamage, unit.AttackRange, unit.AttackSpeed = 20, 1.5, 2
+			target, err := world.ObjectManager.CreateUnit(2, "soldier", Vector3{X: 12.5, Z: 5.5}, def)
+			if err != nil {
+				return err
+			}
+			soldier, enemy = unit.ID, target.ID
+			return nil
+		},
+	})
+
+	if _, err := env.Step(nil); err == nil {
+		t.Error("Expected stepping before a reset to fail")
+	}
+	obs, err := env.Reset()
+	if err != nil {
+		t.Fatalf("Failed to reset: %v", err)
+	}
+	if obs.Shape != [3]int{ObservationChannels, 24, 24} || len(obs.Data) != ObservationChannels*24*24 {
+		t.Fatalf("Expected a channel per grid, got shape %v", obs.Shape)
+	}
+	if obs.At(ChannelOwnUnits, 5, 5) != 1 || obs.At(ChannelVisibility, 8, 5) != 1 || obs.At(ChannelVisibility, 10, 5) != 0 {
+		t.Error("Expected the soldier to see four tiles around it")
+	}
+	if obs.At(ChannelEnemyUnits, 12, 5) != 0 {
+		t.Error("Expected the enemy out of sight to be hidden")
+	}
+	if obs.Resources["gold"] != 1000 {
+		t.Errorf("Expected the stockpile in the observation, got %v", obs.Resources)
+	}
+
+	if _, err := env.Step([]EnvAction{{UnitID: enemy, Command: CreateStopCommand()}}); err == nil {
+		t.Error("Expected orders to enemy units to fail")
+	}
+
+	run := func() (float64, int) {
+		if _, err := env.Reset(); err != nil {
+			t.Fatalf("Failed to reset: %v", err)
+		}
+		target := env.World().ObjectManager.GetUnit(enemy)
+		actions := []EnvAction{{UnitID: soldier, Command: CreateAttackCommand(target, false)}}
+		total := 0.0
+		for step := 1; ; step++ {
+			result, err := env.Step(actions)
+			if err != nil {
+				t.Fatalf("Step %d failed: %v", step, err)
+			}
+			actions = nil
+			total += result.Reward
+			if result.Done || result.Truncated {
+				if !result.State.Won {
+					t.Fatalf("Expected the soldier to win, got %+v", result.State)
+				}
+				return total, step
+			}
+		}
+	}
+	reward, steps := run()
+	if reward < environmentWinReward {
+		t.Errorf("Expected the win and the kill to be rewarded, got %.2f", reward)
+	}
+	if _, err := env.Step(nil); err == nil {
+		t.Error("Expected stepping a finished episode to fail")
+	}
+	if again, againSteps := run(); again != reward || againSteps != steps {
+		t.Errorf("Expected episodes to replay the same, got %.2f in %d steps and %.2f in %d", reward, steps, again, againSteps)
+	}
+}
@@ -0,0 +1,355 @@
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"teraglest/internal/logging"
+)
+
+// AI expansion
+const (
+	expansionWorkers     = 2               // Workers sent to build an expansion
+	expansionEscortSize  = 3               // Military units escorting them
+	expansionSiteRadius  = 8.0             // Tiles around a site that belong to the expansion
+	expansionSpacing     = 3.0             // Tiles between the buildings of an expansion
+	expansionMinDistance = 15.0            // Tiles from our buildings within which a site is already covered
+	contestedSiteMemory  = 3 * time.Minute // Game time a contested site is avoided
+)
+
+// Expansion stages
+const (
+	ExpansionTravel    = "travel"    // Workers and escort on the way to the site
+	ExpansionClear     = "clear"     // Escort clearing enemies off the site
+	ExpansionBuild     = "build"     // Workers putting up the build order
+	ExpansionComplete  = "complete"  // Every building of the build order stands
+	ExpansionAbandoned = "abandoned" // No site left to go to, or no workers left
+)
+
+// ExpansionPlan is an AI expansion under way: the workers and escort sent
+// to a site and the buildings still to put up there
+type ExpansionPlan struct {
+	Site       Vector3
+	Stage      string   // One of the Expansion stage constants
+	Workers    []int    // IDs of the workers building the expansion
+	Escort     []int    // IDs of the military units guarding them
+	BuildOrder []string // Base building first, then the economy buildings supporting it
+	Step       int      // Index in the build order of the building under way
+	Replans    int      // Times the plan moved on from a contested site
+}
+
+// Active reports whether the plan is still being carried out
+func (p *ExpansionPlan) Active() bool {
+	return p.Stage != ExpansionComplete && p.Stage != ExpansionAbandoned
+}
+
+// contestedSite is an expansion site the AI gave up because of the enemy
+type contestedSite struct {
+	position Vector3
+	since    time.Duration // Game time the site was given up
+}
+
+// GetExpansion returns the AI's current or last expansion plan, nil before
+// the first
+func (ai *StrategicAI) GetExpansion() *ExpansionPlan {
+	return ai.expansion
+}
+
+// findExpansionSites returns places to expand to next to the resources the
+// AI knows of, nearest our base first. Sites our buildings already cover,
+// near known enemy buildings, recently contested or on terrain no walk from
+// our base reaches are left out.
+func (ai *StrategicAI) findExpansionSites() []Vector3 {
+	tileSize := float64(ai.world.GetTileSize())
+	home, ok := ai.homePosition()
+	if !ok {
+		return nil
+	}
+	ownBuildings := ai.world.ObjectManager.GetBuildingsForPlayer(ai.playerID)
+	enemyBuildings := ai.intel.Buildings()
+	now := ai.world.GetGameTime()
+	analysis := ai.world.MapAnalysis()
+	homeTile := ai.world.WorldToGrid(home).Grid
+
+	var sites []Vector3
+	for _, node := range ai.intel.Resources() {
+		// Build beside the resource on the side facing home
+		site := ai.world.stepAway(node.Position, home, -expansionSpacing*tileSize)
+		usable := !analysis.Separated(homeTile, ai.world.WorldToGrid(site).Grid)
+		for _, building := range ownBuildings {
+			usable = usable && ai.world.CalculateDistance(site, building.Position) > expansionMinDistance*tileSize
+		}
+		for _, building := range enemyBuildings {
+			usable = usable && ai.world.CalculateDistance(site, building.Position) > expansionMinDistance*tileSize
+		}
+		for _, contested := range ai.contested {
+			if now-contested.since < contestedSiteMemory {
+				usable = usable && ai.world.CalculateDistance(site, contested.position) > expansionSiteRadius*tileSize
+			}
+		}
+		if usable {
+			sites = append(sites, site)
+		}
+	}
+	sort.SliceStable(sites, func(i, j int) bool {
+		return ai.world.CalculateDistance(home, sites[i]) < ai.world.CalculateDistance(home, sites[j])
+	})
+	return sites
+}
+
+// homePosition returns the position of the AI's oldest building
+func (ai *StrategicAI) homePosition() (Vector3, bool) {
+	var home *GameBuilding
+	for _, building := range ai.world.ObjectManager.GetBuildingsForPlayer(ai.playerID) {
+		if building.IsAlive() && (home == nil || building.ID < home.ID) {
+			home = building
+		}
+	}
+	if home == nil {
+		return Vector3{}, false
+	}
+	return home.Position, true
+}
+
+// startExpansion sends idle workers and military units to a site; it is
+// false when there are no idle workers to send
+func (ai *StrategicAI) startExpansion(site Vector3) bool {
+	workers := ai.findAvailableWorkers()
+	if len(workers) == 0 {
+		return false
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].ID < workers[j].ID })
+	if len(workers) > expansionWorkers {
+		workers = workers[:expansionWorkers]
+	}
+
+	var escort []*GameUnit
+	for _, unit := range ai.world.ObjectManager.GetUnitsForPlayer(ai.playerID) {
+		if unit.IsAlive() && ai.militaryMgr.isMilitaryUnit(unit) && unit.GarrisonedIn == 0 && unit.CurrentCommand == nil {
+			escort = append(escort, unit)
+		}
+	}
+	sort.Slice(escort, func(i, j int) bool { return escort[i].ID < escort[j].ID })
+	if len(escort) > expansionEscortSize {
+		escort = escort[:expansionEscortSize]
+	}
+
+	plan := &ExpansionPlan{Site: site, Stage: ExpansionTravel, BuildOrder: ai.expansionBuildOrder(site)}
+	for _, worker := range workers {
+		plan.Workers = append(plan.Workers, worker.ID)
+	}
+	for _, unit := range escort {
+		plan.Escort = append(plan.Escort, unit.ID)
+	}
+	if previous := ai.expansion; previous != nil && previous.Active() {
+		plan.Replans = previous.Replans + 1
+	}
+	ai.expansion = plan
+	ai.sendToSite(workers, site)
+	ai.sendToSite(escort, site)
+	logging.Infof(logging.CategoryAI, "Player %d expanding to (%.0f, %.0f) with %d workers and %d escorts",
+		ai.playerID, site.X, site.Z, len(workers), len(escort))
+	return true
+}
+
+// expansionBuildOrder lists what an expansion builds: a base, storage, the
+// gathering building for the resource nearest the site, and a house
+func (ai *StrategicAI) expansionBuildOrder(site Vector3) []string {
+	base := "castle"
+	if ai.personality.EconomicFocus > 0.6 {
+		base = "town_center"
+	}
+	order := []string{base, "storage"}
+	var nearest *SightedResource
+	resources := ai.intel.Resources()
+	for i := range resources {
+		if nearest == nil || ai.world.CalculateDistance(site, resources[i].Position) < ai.world.CalculateDistance(site, nearest.Position) {
+			nearest = &resources[i]
+		}
+	}
+	if nearest != nil {
+		order = append(order, ai.economicMgr.getResourceBuildingType(nearest.ResourceType))
+	}
+	return append(order, "house")
+}
+
+// updateExpansion carries the expansion plan forward: the escort clears
+// enemies off the site, the workers put up the build order one building at
+// a time, and a site held by more enemies than the escort can take is
+// given up for the next one
+func (ai *StrategicAI) updateExpansion() {
+	plan := ai.expansion
+	if plan == nil || !plan.Active() {
+		return
+	}
+	workers := ai.liveUnits(&plan.Workers)
+	escort := ai.liveUnits(&plan.Escort)
+	if len(workers) == 0 {
+		plan.Stage = ExpansionAbandoned
+		logging.Infof(logging.CategoryAI, "Player %d abandoned its expansion: no workers left", ai.playerID)
+		return
+	}
+
+	enemies, held := ai.siteThreats(plan.Site)
+	if held || len(enemies) > len(escort) {
+		ai.replanExpansion()
+		return
+	}
+	if len(enemies) > 0 {
+		plan.Stage = ExpansionClear
+		for _, unit := range escort {
+			if unit.CurrentCommand == nil || unit.CurrentCommand.Type != CommandAttack {
+				if target, _ := nearestUnit(unit, enemies); target != nil {
+					ai.world.commandProcessor.IssueCommand(unit.ID, CreateAttackCommand(target, false))
+				}
+			}
+		}
+		return
+	}
+
+	if plan.Stage != ExpansionBuild {
+		plan.Stage = ExpansionTravel
+		if !ai.unitsNear(workers, plan.Site, expansionSpacing) {
+			var idle []*GameUnit
+			for _, worker := range workers {
+				if worker.CurrentCommand == nil {
+					idle = append(idle, worker)
+				}
+			}
+			ai.sendToSite(idle, plan.Site)
+			return
+		}
+		plan.Stage = ExpansionBuild
+	}
+
+	for plan.Step < len(plan.BuildOrder) {
+		buildingType := plan.BuildOrder[plan.Step]
+		building := ai.expansionBuilding(buildingType, plan.Site)
+		if building == nil {
+			ai.orderExpansionBuilding(workers, buildingType, plan.buildPosition(plan.Step, float64(ai.world.GetTileSize())))
+			return
+		}
+		if !building.IsBuilt {
+			return
+		}
+		plan.Step++
+	}
+	plan.Stage = ExpansionComplete
+	logging.Infof(logging.CategoryAI, "Player %d completed its expansion at (%.0f, %.0f)", ai.playerID, plan.Site.X, plan.Site.Z)
+}
+
+// replanExpansion gives up a contested site and moves the expansion to the
+// next site, or abandons it when there is none
+func (ai *StrategicAI) replanExpansion() {
+	plan := ai.expansion
+	ai.contested = append(ai.contested, contestedSite{position: plan.Site, since: ai.world.GetGameTime()})
+	logging.Infof(logging.CategoryAI, "Player %d expansion site (%.0f, %.0f) is contested", ai.playerID, plan.Site.X, plan.Site.Z)
+
+	// The units still sent there are free for the new plan
+	for _, id := range append(append([]int{}, plan.Workers...), plan.Escort...) {
+		ai.world.commandProcessor.CancelCommand(id)
+	}
+	if sites := ai.findExpansionSites(); len(sites) > 0 && ai.startExpansion(sites[0]) {
+		return
+	}
+	plan.Stage = ExpansionAbandoned
+}
+
+// siteThreats returns the armed enemy units last seen on a site, and
+// whether a known enemy building holds it
+func (ai *StrategicAI) siteThreats(site Vector3) ([]*GameUnit, bool) {
+	reach := expansionSiteRadius * float64(ai.world.GetTileSize())
+	for _, building := range ai.intel.Buildings() {
+		if ai.world.CalculateDistance(site, building.Position) <= reach {
+			return nil, true
+		}
+	}
+	var enemies []*GameUnit
+	for _, sighting := range ai.intel.Units() {
+		if !sighting.Armed || ai.world.CalculateDistance(site, sighting.Position) > reach {
+			continue
+		}
+		if unit := ai.world.ObjectManager.GetUnit(sighting.ID); unit != nil && unit.IsAlive() {
+			enemies = append(enemies, unit)
+		}
+	}
+	return enemies, false
+}
+
+// expansionBuilding returns our building of a type on a site, nil if there
+// is none yet
+func (ai *StrategicAI) expansionBuilding(buildingType string, site Vector3) *GameBuilding {
+	reach := expansionSiteRadius * float64(ai.world.GetTileSize())
+	for _, building := range ai.world.ObjectManager.GetBuildingsForPlayer(ai.playerID) {
+		if building.BuildingType == buildingType && building.IsAlive() && ai.world.CalculateDistance(site, building.Position) <= reach {
+			return building
+		}
+	}
+	return nil
+}
+
+// orderExpansionBuilding has a worker put up a building, unless one is
+// already on its way to do so
+func (ai *StrategicAI) orderExpansionBuilding(workers []*GameUnit, buildingType string, position Vector3) {
+	var free *GameUnit
+	for _, worker := range workers {
+		if command := worker.CurrentCommand; command != nil && command.Type == CommandBuild {
+			if pending, _ := command.Parameters["building_type"].(string); pending == buildingType {
+				return
+			}
+			continue
+		}
+		if free == nil {
+			free = worker
+		}
+	}
+	if free != nil {
+		ai.world.commandProcessor.IssueCommand(free.ID, CreateBuildCommand(position, buildingType, false))
+	}
+}
+
+// buildPosition returns where a step of the build order goes: the base on
+// the site, the rest around it
+func (p *ExpansionPlan) buildPosition(step int, tileSize float64) Vector3 {
+	if step == 0 {
+		return p.Site
+	}
+	offsets := [][2]float64{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+	offset := offsets[(step-1)%len(offsets)]
+	spacing := expansionSpacing * tileSize * float64((step-1)/len(offsets)+1)
+	return Vector3{X: p.Site.X + offset[0]*spacing, Y: p.Site.Y, Z: p.Site.Z + offset[1]*spacing}
+}
+
+// sendToSite moves units to an expansion site
+func (ai *StrategicAI) sendToSite(units []*GameUnit, site Vector3) {
+	for _, unit := range units {
+		ai.world.commandProcessor.IssueCommand(unit.ID, CreateMoveCommand(site, false))
+	}
+}
+
+// liveUnits returns the units of a list of IDs still alive, and drops the
+// dead from the list
+func (ai *StrategicAI) liveUnits(ids *[]int) []*GameUnit {
+	var units []*GameUnit
+	alive := (*ids)[:0]
+	for _, id := range *ids {
+		if unit := ai.world.ObjectManager.GetUnit(id); unit != nil && unit.IsAlive() {
+			units = append(units, unit)
+			alive = append(alive, id)
+		}
+	}
+	*ids = alive
+	return units
+}
+
+// unitsNear reports whether any of the units is within a number of tiles
+// of a position
+func (ai *StrategicAI) unitsNear(units []*GameUnit, position Vector3, tiles float64) bool {
+	reach := tiles * float64(ai.world.GetTileSize())
+	for _, unit := range units {
+		if ai.world.CalculateDistance(unit.Position, position) <= reach {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestAIExpansion tests that the AI escorts workers to a known resource,
+// puts up the expansion build order there, clears a site its escort can
+// hold and gives up a site the enemy holds in force
+func TestAIExpansion(t *testing.T) {
+	world, err := NewHeadlessWorld(64, 64)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+	assets := data.NewMemoryAssetProvider()
+	for _, buildingType := range []string{"castle", "storage", "gold_mine", "house"} {
+		assets.AddUnit("testers", data.NewSimpleUnit(buildingType, 1000, 0, "stone", nil))
+	}
+	world.assetMgr = assets
+	world.players[1].FactionName, world.players[1].FactionData = "testers", &data.FactionDefinition{}
+
+	unit := func(playerID int, unitType string, x, z float64) *GameUnit {
+		created, err := world.ObjectManager.CreateUnit(playerID, unitType, Vector3{X: x, Z: z}, data.NewSimpleUnit(unitType, 100, 0, "leather", nil))
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		return created
+	}
+	if _, err := world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 5.5, Z: 5.5}, data.NewSimpleUnit("castle", 2000, 0, "stone", nil)); err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	first, second := unit(1, "worker", 7.5, 7.5), unit(1, "worker", 8.5, 7.5)
+	soldier := unit(1, "soldier", 7.5, 8.5)
+	soldier.AttackDamage, soldier.AttackRange, soldier.AttackSpeed = 30, 1.5, 2
+	for _, node := range []Vector2i{{X: 30, Y: 8}, {X: 8, Y: 44}} {
+		if err := world.placeResourceNode(node.X, node.Y, "gold"); err != nil {
+			t.Fatalf("Failed to place resource: %v", err)
+		}
+	}
+
+	ai := NewStrategicAI(1, world, BalancedPersonality, DifficultyNormal)
+	if sites := ai.findExpansionSites(); len(sites) != 0 {
+		t.Fatalf("Expected no sites before any resource was seen, got %v", sites)
+	}
+
+	// A scout that sees the whole map has found both resources
+	lookout := unit(1, "scout", 32.5, 32.5)
+	lookout.UnitDef.Unit.Parameters.Sight.Value = 64
+	ai.GetIntel().Update()
+	lookout.Health = 0
+
+	sites := ai.findExpansionSites()
+	if len(sites) != 2 || sites[0].X < sites[0].Z {
+		t.Fatalf("Expected the nearer gold to come first, got %v", sites)
+	}
+	if !ai.startExpansion(sites[0]) {
+		t.Fatal("Expected the expansion to start")
+	}
+	plan := ai.GetExpansion()
+	if len(plan.Workers) != 2 || len(plan.Escort) != 1 || plan.BuildOrder[0] != "castle" || plan.BuildOrder[2] != "gold_mine" {
+		t.Fatalf("Expected both workers, the soldier and the build order in the plan, got %+v", plan)
+	}
+	if soldier.CurrentCommand == nil || soldier.CurrentCommand.Type != CommandMove {
+		t.Error("Expected the soldier to escort the workers")
+	}
+
+	for i := 0; i < 3000 && plan.Active(); i++ {
+		clock.Advance(100 * time.Millisecond)
+		world.Update(100 * time.Millisecond)
+		ai.updateExpansion()
+	}
+	if plan.Stage != ExpansionComplete {
+		t.Fatalf("Expected the expansion to complete, stopped at %q step %d", plan.Stage, plan.Step)
+	}
+	for _, buildingType := range plan.BuildOrder {
+		if building := ai.expansionBuilding(buildingType, plan.Site); building == nil || !building.IsBuilt {
+			t.Errorf("Expected a finished %s on the site", buildingType)
+		}
+	}
+
+	// The next site is covered by a raider the soldier can take on
+	sites = ai.findExpansionSites()
+	if len(sites) != 1 {
+		t.Fatalf("Expected the other gold to be left, got %v", sites)
+	}
+	first.Position, second.Position, soldier.Position = Vector3{X: 8.5, Z: 36.5}, Vector3{X: 9.5, Z: 36.5}, Vector3{X: 8.5, Z: 37.5}
+	raider := unit(2, "raider", sites[0].X+1, sites[0].Z)
+	raider.AttackDamage = 5
+	if !ai.startExpansion(sites[0]) {
+		t.Fatal("Expected the second expansion to start")
+	}
+	plan = ai.GetExpansion()
+	ai.GetIntel().Update()
+	ai.updateExpansion()
+	if plan.Stage != ExpansionClear || soldier.CurrentCommand == nil || soldier.CurrentCommand.TargetUnit != raider {
+		t.Fatalf("Expected the escort to clear the raider off the site, got %q", plan.Stage)
+	}
+
+	// More enemies than the escort can take make the AI give the site up
+	unit(2, "raider", sites[0].X, sites[0].Z+1).AttackDamage = 5
+	unit(2, "raider", sites[0].X-1, sites[0].Z).AttackDamage = 5
+	ai.GetIntel().Update()
+	ai.updateExpansion()
+	if plan.Stage != ExpansionAbandoned || len(ai.contested) != 1 {
+		t.Fatalf("Expected the contested site to be given up, got %q", plan.Stage)
+	}
+	if first.CurrentCommand != nil || soldier.CurrentCommand != nil {
+		t.Error("Expected the units sent to the contested site to be recalled")
+	}
+	if sites := ai.findExpansionSites(); len(sites) != 0 {
+		t.Errorf("Expected the contested site to be avoided, got %v", sites)
+	}
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"math"
+	"time"
+)
+
+// Headings are radians around the vertical axis: 0 faces +Z, and headings
+// grow turning toward +X, as models are rotated when drawn
+
+// FacingSettings configures how units turn toward where they move and strike
+type FacingSettings struct {
+	TurnRate  float64 // Radians a second units turn at, unless their own TurnRate is set
+	AttackArc float64 // Width in radians of the arc in front of a unit that it can strike into
+}
+
+// DefaultFacing is used when GameSettings does not set Facing
+var DefaultFacing = FacingSettings{
+	TurnRate:  3 * math.Pi,
+	AttackArc: 2 * math.Pi / 3,
+}
+
+// facingSettings returns the configured facing settings
+func (w *World) facingSettings() FacingSettings {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	if w.settings.Facing == nil {
+		return DefaultFacing
+	}
+	return *w.settings.Facing
+}
+
+// HeadingTo returns the heading that faces from one point toward another,
+// and false when they are at the same spot
+func HeadingTo(from, to Vector3) (float32, bool) {
+	dx, dz := to.X-from.X, to.Z-from.Z
+	if dx*dx+dz*dz < 1e-6 {
+		return 0, false
+	}
+	return float32(math.Atan2(dx, dz)), true
+}
+
+// headingOffset returns how far a heading is from another, in radians 0-pi
+func headingOffset(from, to float32) float64 {
+	return math.Abs(math.Remainder(float64(to-from), 2*math.Pi))
+}
+
+// GetHeading returns the way the unit faces
+func (u *GameUnit) GetHeading() float32 {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	return u.Rotation
+}
+
+// turnTo turns a unit toward a heading by up to one tick of turning, the
+// short way round. The caller holds the unit lock or owns the unit.
+func (w *World) turnTo(unit *GameUnit, heading float32, deltaTime time.Duration) {
+	rate := float64(unit.TurnRate)
+	if rate <= 0 {
+		rate = w.facingSettings().TurnRate
+	}
+	delta := math.Remainder(float64(heading-unit.Rotation), 2*math.Pi)
+	step := rate * deltaTime.Seconds()
+	if math.Abs(delta) <= step {
+		unit.Rotation = heading
+	} else {
+		unit.Rotation += float32(math.Copysign(step, delta))
+	}
+	unit.Rotation = float32(math.Remainder(float64(unit.Rotation), 2*math.Pi))
+}
+
+// turnToward turns a unit toward a point and reports whether the point is
+// then within its attack arc
+func (w *World) turnToward(unit *GameUnit, point Vector3, deltaTime time.Duration) bool {
+	heading, apart := HeadingTo(unit.Position, point)
+	if !apart {
+		return true
+	}
+	w.turnTo(unit, heading, deltaTime)
+	return headingOffset(unit.Rotation, heading) <= w.facingSettings().AttackArc/2
+}
+
+// turnUnit turns a unit after its command ran: toward where it is heading
+// while moving, toward its target while attacking outside an attack order,
+// and toward its formation's facing once it stands in formation
+func (cp *CommandProcessor) turnUnit(unit *GameUnit, deltaTime time.Duration) {
+	switch unit.State {
+	case UnitStateMoving:
+		if unit.Target != nil {
+			cp.world.turnToward(unit, *unit.Target, deltaTime)
+		}
+	case UnitStateAttacking:
+		// Attack orders turn the unit themselves, so it can swing the same tick
+		if unit.AttackTarget != nil && (unit.CurrentCommand == nil || unit.CurrentCommand.Type != CommandAttack) {
+			cp.world.turnToward(unit, unit.AttackTarget.GetPosition(), deltaTime)
+		}
+	case UnitStateIdle:
+		if cp.world.groupMgr == nil {
+			return
+		}
+		if group, grouped := cp.world.groupMgr.GetUnitGroup(unit.ID); grouped {
+			if heading, formed := group.FormationHeading(unit.ID); formed {
+				cp.world.turnTo(unit, heading, deltaTime)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestAttackTurn tests that a unit turns to face a target behind it at its
+// turn rate and only starts its swing once the target is in its attack arc
+func TestAttackTurn(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+	world.settings.Facing = &FacingSettings{TurnRate: math.Pi / 2, AttackArc: math.Pi}
+
+	def := data.NewSimpleUnit("swordsman", 100, 0, "leather", nil)
+	def.Unit.Skills = []data.Skill{{
+		Type:           data.SkillType{Value: "attack"},
+		Speed:          data.SkillSpeed{Value: 100},
+		AttackStrength: &data.SkillAttackStrength{Value: 10},
+	}}
+	attacker, _ := world.ObjectManager.CreateUnit(1, "swordsman", Vector3{X: 10.5, Z: 10.5}, def)
+	target, _ := world.ObjectManager.CreateUnit(2, "swordsman", Vector3{X: 10.5, Z: 9.5}, def)
+	attacker.AttackDamage, attacker.AttackRange = 10, 1.5
+
+	step := func(duration time.Duration) {
+		for elapsed := time.Duration(0); elapsed < duration; elapsed += 100 * time.Millisecond {
+			clock.Advance(100 * time.Millisecond)
+			world.commandProcessor.Update(100 * time.Millisecond)
+		}
+	}
+
+	// The target is straight behind: a quarter turn brings it to the arc's edge
+	world.commandProcessor.IssueCommand(attacker.ID, CreateAttackCommand(target, false))
+	step(900 * time.Millisecond)
+	if target.Health != 100 {
+		t.Fatalf("Expected no hit while turning, health %d heading %.2f", target.Health, attacker.GetHeading())
+	}
+	step(200 * time.Millisecond)
+	if target.Health >= 100 {
+		t.Errorf("Expected the hit once the target is in the attack arc, heading %.2f", attacker.GetHeading())
+	}
+	step(2 * time.Second)
+	if heading := attacker.GetHeading(); headingOffset(heading, math.Pi) > 1e-5 {
+		t.Errorf("Expected the attacker to end up facing the target, heading %.2f", heading)
+	}
+
+	// Moving units turn toward where they go
+	walker, _ := world.ObjectManager.CreateUnit(1, "swordsman", Vector3{X: 3.5, Z: 20.5}, def)
+	world.commandProcessor.IssueCommand(walker.ID, CreateMoveCommand(Vector3{X: 20.5, Z: 20.5}, false))
+	step(2 * time.Second)
+	if heading := walker.GetHeading(); headingOffset(heading, math.Pi/2) > 1e-5 {
+		t.Errorf("Expected the unit to face east while moving east, heading %.2f", heading)
+	}
+}
+
+// TestFormationFacing tests that formations turn to face the way they moved
+func TestFormationFacing(t *testing.T) {
+	units := createTestUnits(3, 0)
+	group := NewUnitGroup(1, 0, units, FormationLine)
+	group.MoveToPosition(Vector3{X: 20, Z: group.CenterPos.Z})
+	group.IsMoving, group.IsFormed = false, true
+	group.CenterPos = Vector3{X: 20}
+
+	heading, formed := group.FormationHeading(units[0].ID)
+	if !formed || math.Abs(float64(heading)-math.Pi/2) > 1e-5 {
+		t.Fatalf("Expected the formation to face east, got %.2f", heading)
+	}
+
+	// A line across +Z turns into a line along Z when facing east
+	for _, unit := range units {
+		position, _ := group.GetFormationPosition(unit.ID)
+		if math.Abs(position.X-20) > 1e-6 {
+			t.Errorf("Expected unit %d in a line across the way east, got %v", unit.ID, position)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TechTiming is when the AI researches an upgrade
+type TechTiming struct {
+	Upgrade string        // Upgrade name
+	At      time.Duration // Game time from which the upgrade is due
+}
+
+// FactionAIProfile is how the AI plays a faction: the units it counts as
+// workers and warriors, the army it aims for in each phase, when it
+// researches key upgrades and what it builds against enemy units. The same
+// personality plays a magic faction and a tech faction differently through
+// their profiles. It comes from the faction's ai-behavior section; a faction
+// without one, or a nil profile, gets the built-in defaults.
+type FactionAIProfile struct {
+	Faction      string
+	Workers      []string                             // Unit types that gather and build
+	Warriors     []string                             // Unit types that fight
+	Compositions map[StrategyPhase]map[string]float64 // Army share of each unit type by phase
+	TechTimings  []TechTiming                         // Key upgrades, sorted by timing
+	Upgrades     []string                             // Other upgrades, in order of preference
+	Counters     map[string][]string                  // Enemy unit type -> our units that counter it
+
+	personalities map[string]data.AIPersonalityProfile // Faction adjustments by personality name
+}
+
+// aiPhases maps the phase names of faction files to strategy phases
+var aiPhases = map[string]StrategyPhase{
+	"early": PhaseEarlyGame,
+	"mid":   PhaseMidGame,
+	"late":  PhaseLateGame,
+	"end":   PhaseEndGame,
+}
+
+// NewFactionAIProfile builds the AI profile of a faction; faction may be nil
+func NewFactionAIProfile(faction *data.FactionDefinition) *FactionAIProfile {
+	profile := &FactionAIProfile{
+		Compositions:  make(map[StrategyPhase]map[string]float64),
+		Counters:      make(map[string][]string),
+		personalities: make(map[string]data.AIPersonalityProfile),
+	}
+	if faction == nil {
+		return profile
+	}
+	profile.Faction = faction.Name
+	behavior := faction.Faction.AIBehavior
+	if behavior == nil {
+		return profile
+	}
+
+	for _, unit := range behavior.WorkerUnits {
+		profile.Workers = append(profile.Workers, unit.Name)
+	}
+	for _, unit := range behavior.WarriorUnits {
+		profile.Warriors = append(profile.Warriors, unit.Name)
+	}
+	for _, composition := range behavior.Compositions {
+		phase, ok := aiPhases[strings.ToLower(composition.Phase)]
+		if !ok {
+			continue
+		}
+		shares := make(map[string]float64, len(composition.Units))
+		for _, unit := range composition.Units {
+			if unit.Share > 0 {
+				shares[unit.Name] += unit.Share
+			}
+		}
+		profile.Compositions[phase] = shares
+	}
+	for _, timing := range behavior.TechTimings {
+		profile.TechTimings = append(profile.TechTimings, TechTiming{
+			Upgrade: timing.Name,
+			At:      time.Duration(timing.Minute * float64(time.Minute)),
+		})
+	}
+	sort.SliceStable(profile.TechTimings, func(i, j int) bool {
+		return profile.TechTimings[i].At < profile.TechTimings[j].At
+	})
+	for _, upgrade := range behavior.Upgrades {
+		profile.Upgrades = append(profile.Upgrades, upgrade.Name)
+	}
+	for _, counter := range behavior.Counters {
+		profile.Counters[counter.Against] = append(profile.Counters[counter.Against], counter.Unit)
+	}
+	for _, personality := range behavior.Personalities {
+		profile.personalities[strings.ToLower(personality.Name)] = personality
+	}
+	return profile
+}
+
+// Adapt returns the personality with the faction's adjustments for it applied
+func (p *FactionAIProfile) Adapt(personality AIPersonality) AIPersonality {
+	if p == nil {
+		return personality
+	}
+	adjust, ok := p.personalities[strings.ToLower(personality.Name)]
+	if !ok {
+		return personality
+	}
+	for _, value := range []struct {
+		from *float64
+		to   *float64
+	}{
+		{adjust.Aggression, &personality.AggressionLevel},
+		{adjust.Economic, &personality.EconomicFocus},
+		{adjust.Military, &personality.MilitaryFocus},
+		{adjust.Expansion, &personality.ExpansionTendency},
+		{adjust.Technology, &personality.TechnologyPriority},
+		{adjust.Defensive, &personality.DefensivePosture},
+		{adjust.Risk, &personality.RiskTolerance},
+	} {
+		if value.from != nil {
+			*value.to = math.Max(0, math.Min(1, *value.from))
+		}
+	}
+	return personality
+}
+
+// IsWorker returns whether a unit type gathers and builds for the faction
+func (p *FactionAIProfile) IsWorker(unitType string) bool {
+	if p == nil || len(p.Workers) == 0 {
+		return unitType == "worker"
+	}
+	return containsString(p.Workers, unitType)
+}
+
+// IsWarrior returns whether the faction lists a unit type as a warrior
+func (p *FactionAIProfile) IsWarrior(unitType string) bool {
+	if p == nil {
+		return false
+	}
+	return containsString(p.Warriors, unitType)
+}
+
+// Composition returns the army the faction aims for in a phase, scaled to an
+// army size, or nil when the faction has no composition for the phase
+func (p *FactionAIProfile) Composition(phase StrategyPhase, armySize int) map[string]int {
+	if p == nil {
+		return nil
+	}
+	shares, ok := p.Compositions[phase]
+	if !ok {
+		return nil
+	}
+	composition := make(map[string]int, len(shares))
+	for unitType, share := range shares {
+		if count := int(math.Round(share * float64(armySize))); count > 0 {
+			composition[unitType] = count
+		}
+	}
+	return composition
+}
+
+// AddCounters adds the units that counter the enemy units seen to a
+// composition, one counter per enemy, taking turns when a type has several
+func (p *FactionAIProfile) AddCounters(composition map[string]int, enemies map[string]int) {
+	if p == nil {
+		return
+	}
+	for enemyType, count := range enemies {
+		counters := p.Counters[enemyType]
+		for i := 0; i < count && len(counters) > 0; i++ {
+			composition[counters[i%len(counters)]]++
+		}
+	}
+}
+
+// DueUpgrades returns the key upgrades whose timing has come, earliest
+// first, followed by the faction's other upgrades. Key upgrades are held
+// back until their timing even when also listed as other upgrades.
+func (p *FactionAIProfile) DueUpgrades(gameTime time.Duration) []string {
+	if p == nil {
+		return nil
+	}
+	var upgrades, timed []string
+	for _, timing := range p.TechTimings {
+		timed = append(timed, timing.Upgrade)
+		if timing.At <= gameTime && !containsString(upgrades, timing.Upgrade) {
+			upgrades = append(upgrades, timing.Upgrade)
+		}
+	}
+	for _, upgrade := range p.Upgrades {
+		if !containsString(timed, upgrade) && !containsString(upgrades, upgrade) {
+			upgrades = append(upgrades, upgrade)
+		}
+	}
+	return upgrades
+}
+
+// containsString returns whether a list holds a string
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestFactionAIProfiles tests that the same personality plays two factions
+// differently through the compositions, tech timings, counters and
+// personality adjustments of their faction files
+func TestFactionAIProfiles(t *testing.T) {
+	factions := map[string]string{
+		"magic": `<faction><ai-behavior>
+			<worker-units><unit name="initiate" minimum="4"/></worker-units>
+			<warrior-units><unit name="battlemage" minimum="2"/><unit name="golem" minimum="0"/></warrior-units>
+			<compositions>
+				<composition phase="early"><unit name="battlemage" share="0.5"/><unit name="golem" share="0.25"/></composition>
+			</compositions>
+			<tech-timings><tech name="ice_nova" minute="8"/><tech name="fire_ball" minute="2"/></tech-timings>
+			<counters><counter unit="golem" against="swordman"/></counters>
+			<personalities><personality name="balanced" aggression="0.2" technology="1.5"/></personalities>
+		</ai-behavior></faction>`,
+		"tech": `<faction><ai-behavior>
+			<warrior-units><unit name="swordman" minimum="2"/></warrior-units>
+			<compositions>
+				<composition phase="early"><unit name="swordman" share="0.75"/></composition>
+			</compositions>
+			<upgrades><upgrade name="bronze_swords"/></upgrades>
+			<personalities><personality name="Balanced" aggression="0.9"/></personalities>
+		</ai-behavior></faction>`,
+	}
+
+	world, err := NewHeadlessWorld(48, 48)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	ais := make(map[string]*StrategicAI)
+	for playerID, name := range map[int]string{1: "magic", 2: "tech"} {
+		var faction data.Faction
+		if err := xml.Unmarshal([]byte(factions[name]), &faction); err != nil {
+			t.Fatalf("Failed to parse the %s faction: %v", name, err)
+		}
+		world.players[playerID].FactionName = name
+		world.players[playerID].FactionData = &data.FactionDefinition{Name: name, Faction: faction}
+		ais[name] = NewStrategicAI(playerID, world, BalancedPersonality, DifficultyNormal)
+	}
+	magic, tech := ais["magic"], ais["tech"]
+
+	// Personalities are adjusted per faction, clamped to 0-1
+	if personality := magic.GetPersonality(); personality.AggressionLevel != 0.2 || personality.TechnologyPriority != 1 || personality.EconomicFocus != BalancedPersonality.EconomicFocus {
+		t.Errorf("Expected the magic faction to play a cautious, technical balanced AI, got %+v", personality)
+	}
+	if personality := tech.GetPersonality(); personality.AggressionLevel != 0.9 {
+		t.Errorf("Expected the tech faction to play an aggressive balanced AI, got %+v", personality)
+	}
+
+	// Each faction aims for its own army
+	if composition := magic.militaryMgr.calculateOptimalArmyComposition(); len(composition) != 2 || composition["battlemage"] != 4 || composition["golem"] != 2 {
+		t.Errorf("Expected battlemages and golems for the magic faction, got %v", composition)
+	}
+	if composition := tech.militaryMgr.calculateOptimalArmyComposition(); len(composition) != 1 || composition["swordman"] != 6 {
+		t.Errorf("Expected swordmen for the tech faction, got %v", composition)
+	}
+
+	// Faction units count as workers and warriors
+	initiate, err := world.ObjectManager.CreateUnit(1, "initiate", Vector3{X: 5.5, Z: 5.5}, data.NewSimpleUnit("initiate", 100, 0, "leather", nil))
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	if workers := magic.findAvailableWorkers(); len(workers) != 1 || workers[0] != initiate {
+		t.Errorf("Expected the initiate to work for the magic faction, got %v", workers)
+	}
+	swordman, err := world.ObjectManager.CreateUnit(2, "swordman", Vector3{X: 10.5, Z: 5.5}, data.NewSimpleUnit("swordman", 100, 0, "leather", nil))
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	swordman.AttackDamage = 10
+	if !tech.militaryMgr.isMilitaryUnit(swordman) || magic.militaryMgr.isMilitaryUnit(initiate) {
+		t.Error("Expected faction warriors, and only them, to count as military units")
+	}
+
+	// Enemy units seen add their counters to the army
+	magic.GetIntel().Update()
+	if composition := magic.militaryMgr.calculateOptimalArmyComposition(); composition["golem"] != 3 {
+		t.Errorf("Expected a golem to counter the swordman seen, got %v", composition)
+	}
+
+	// Key upgrades wait for their timing
+	if upgrades := magic.identifyPriorityUpgrades(); len(upgrades) != 0 {
+		t.Errorf("Expected no upgrade due at the start, got %v", upgrades)
+	}
+	world.gameTime = 5 * time.Minute
+	if upgrades := magic.identifyPriorityUpgrades(); len(upgrades) != 1 || upgrades[0] != "fire_ball" {
+		t.Errorf("Expected fire ball to be due after five minutes, got %v", upgrades)
+	}
+	world.gameTime = 10 * time.Minute
+	if upgrades := magic.identifyPriorityUpgrades(); len(upgrades) != 2 || upgrades[1] != "ice_nova" {
+		t.Errorf("Expected ice nova after fire ball, got %v", upgrades)
+	}
+	if upgrades := tech.identifyPriorityUpgrades(); len(upgrades) != 1 || upgrades[0] != "bronze_swords" {
+		t.Errorf("Expected the tech faction's own upgrades, got %v", upgrades)
+	}
+}
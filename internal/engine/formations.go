@@ -171,6 +171,17 @@ func (g *UnitGroup) GetUnitCount() int {
 	return len(g.Units)
 }
 
+// GetUnits returns the units in the group
+func (g *UnitGroup) GetUnits() []*GameUnit {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	units := make([]*GameUnit, 0, len(g.Units))
+	for _, unit := range g.Units {
+		units = append(units, unit)
+	}
+	return units
+}
+
 // IsEmpty returns whether the group has no units
 func (g *UnitGroup) IsEmpty() bool {
 	return g.GetUnitCount() == 0
@@ -315,7 +326,7 @@ func (g *UnitGroup) getSortedUnits() []*GameUnit {
 
 // generateLineFormation creates a horizontal line formation
 func (g *UnitGroup) generateLineFormation(units []*GameUnit) {
-	spacing := g.Parameters.UnitSpacing
+	spacing := g.unitSpacing(units)
 	unitCount := len(units)
 	totalWidth := float32(unitCount-1) * spacing
 	startOffset := -totalWidth / 2
@@ -336,7 +347,7 @@ func (g *UnitGroup) generateLineFormation(units []*GameUnit) {
 
 // generateColumnFormation creates a vertical column formation
 func (g *UnitGroup) generateColumnFormation(units []*GameUnit) {
-	spacing := g.Parameters.UnitSpacing
+	spacing := g.unitSpacing(units)
 
 	for i, unit := range units {
 		position := FormationPosition{
@@ -354,7 +365,7 @@ func (g *UnitGroup) generateColumnFormation(units []*GameUnit) {
 
 // generateWedgeFormation creates a V-shaped wedge formation
 func (g *UnitGroup) generateWedgeFormation(units []*GameUnit) {
-	spacing := g.Parameters.UnitSpacing
+	spacing := g.unitSpacing(units)
 
 	for i, unit := range units {
 		var relPos Vector3
@@ -387,7 +398,7 @@ func (g *UnitGroup) generateWedgeFormation(units []*GameUnit) {
 
 // generateBoxFormation creates a rectangular box formation
 func (g *UnitGroup) generateBoxFormation(units []*GameUnit) {
-	spacing := g.Parameters.UnitSpacing
+	spacing := g.unitSpacing(units)
 	unitCount := len(units)
 
 	// Calculate box dimensions (roughly square)
@@ -423,9 +434,10 @@ func (g *UnitGroup) generateCircleFormation(units []*GameUnit) {
 		return
 	}
 
-	radius := g.Parameters.UnitSpacing * float32(unitCount) / (2 * math.Pi)
-	if radius < g.Parameters.UnitSpacing {
-		radius = g.Parameters.UnitSpacing
+	spacing := g.unitSpacing(units)
+	radius := spacing * float32(unitCount) / (2 * math.Pi)
+	if radius < spacing {
+		radius = spacing
 	}
 
 	for i, unit := range units {
@@ -446,7 +458,7 @@ func (g *UnitGroup) generateCircleFormation(units []*GameUnit) {
 
 // generateScatterFormation creates a loose scattered formation
 func (g *UnitGroup) generateScatterFormation(units []*GameUnit) {
-	spacing := g.Parameters.UnitSpacing * 1.5 // Wider spacing
+	spacing := g.unitSpacing(units) * 1.5 // Wider spacing
 
 	for i, unit := range units {
 		// Use pseudo-random positioning based on unit ID for consistency
@@ -465,6 +477,18 @@ func (g *UnitGroup) generateScatterFormation(units []*GameUnit) {
 	}
 }
 
+// unitSpacing returns the distance between units in formation: the
+// configured spacing, widened so the largest bodies keep a gap between them
+func (g *UnitGroup) unitSpacing(units []*GameUnit) float32 {
+	spacing := g.Parameters.UnitSpacing
+	for _, unit := range units {
+		if body := float32(2*unit.CollisionRadius() + formationGap); body > spacing {
+			spacing = body
+		}
+	}
+	return spacing
+}
+
 // checkFormationCohesion determines if formation should be maintained or broken
 func (g *UnitGroup) checkFormationCohesion() {
 	if !g.IsFormed {
@@ -542,15 +566,32 @@ func (g *UnitGroup) transformToWorldPosition(relativePos Vector3) Vector3 {
 		center = g.CenterPos
 	}
 
-	// Apply rotation based on formation direction
-	// For simplicity, assume direction is along Z-axis for now
-	// TODO: Implement full rotation matrix
-
+	// Formations are laid out facing +Z; turn them to face their direction
+	sin, cos := math.Sincos(float64(g.heading()))
 	return Vector3{
-		X: center.X + relativePos.X,
+		X: center.X + relativePos.X*cos + relativePos.Z*sin,
 		Y: center.Y + relativePos.Y,
-		Z: center.Z + relativePos.Z,
+		Z: center.Z - relativePos.X*sin + relativePos.Z*cos,
+	}
+}
+
+// heading returns the heading the formation faces, along its direction
+func (g *UnitGroup) heading() float32 {
+	heading, _ := HeadingTo(Vector3{}, g.Direction)
+	return heading
+}
+
+// FormationHeading returns the heading a unit faces once the group stands in
+// formation, and false while the group moves or the unit has no place in it
+func (g *UnitGroup) FormationHeading(unitID int) (float32, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	formPos, exists := g.Positions[unitID]
+	if !exists || g.IsMoving || !g.IsFormed {
+		return 0, false
 	}
+	return g.heading() + formPos.Rotation, true
 }
 
 // Helper functions
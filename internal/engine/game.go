@@ -3,10 +3,13 @@ package engine
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"teraglest/internal/data"
+	"teraglest/internal/logging"
 )
 
 // GameState represents the current state of the game
@@ -22,15 +25,48 @@ const (
 // GameSettings contains configurable game parameters
 type GameSettings struct {
 	TechTreePath     string            // Path to tech tree data
+	TechTree         string            // Name of the tech tree in DataRoot/techs ("" = the one at TechTreePath)
+	DataRoot         string            // Root of the game data directory (glest_game)
 	MapPath          string            // Path to map file (optional for now)
 	PlayerFactions   map[int]string    // Player ID to faction name mapping
 	AIFactions       map[int]string    // AI player ID to faction name mapping
+	AISlots          map[int]AISlotSettings // AI player ID to personality, difficulty and handicap (missing = defaults)
+	Teams            map[int]int       // Player ID to alliance team (0 or missing = no allies)
 	GameSpeed        float32           // Game speed multiplier (1.0 = normal)
 	ResourceMultiplier float32         // Resource generation multiplier
 	MaxPlayers       int               // Maximum number of players
 	GameTimeLimit    time.Duration     // Game time limit (0 = no limit)
 	EnableFogOfWar   bool              // Whether fog of war is enabled
 	AllowCheats      bool              // Whether cheat codes are allowed
+	UnitUpkeep       bool              // Whether armies above UpkeepFreeUnits drain resources every minute
+	UpkeepFreeUnits  int               // Army size kept for free in upkeep mode (0 = DefaultUpkeepFreeUnits)
+	UpkeepCost       map[string]int    // Per-minute cost of each unit above the free size (nil = DefaultUpkeepCost)
+	HighGround       *HighGroundModifiers // Elevation combat and sight modifiers (nil = DefaultHighGround)
+	Decals           *DecalSettings    // Marks explosions and destroyed buildings leave, and crater deformation (nil = DefaultDecals)
+	AnimationEvents  *AnimationEventSettings // Markers of skills whose XML defines none (nil = DefaultAnimationEvents)
+	Facing           *FacingSettings   // How fast units turn and how wide they can strike (nil = DefaultFacing)
+	SharedControl    map[int]int       // Co-op controller ID -> human player whose faction it commands too
+	SharedConflictPolicy SharedConflictPolicy // Which order stands when co-op players order the same object at once
+	TeamVision       bool              // Whether allies share line of sight from the start
+	TeamSharedControl bool             // Whether human allies may order each other's units (toggle with World.SetTeamSharedControl)
+	TeamResourcePool bool              // Whether each team gathers into and spends from one shared stockpile
+	InactivityTimeout time.Duration    // Game time a human player may stay idle or disconnected (0 = no limit)
+	InactivityAction InactivityAction  // What happens to a player idle past InactivityTimeout
+	SurrenderDisposal SurrenderDisposal // Whether a surrendered player's forces turn neutral or are removed
+}
+
+// AISlotSettings configures one AI player of a skirmish. Difficulty scales
+// the AI's decision priorities (see StrategicAI.applyDifficultyModifier); the
+// handicap scales its starting resources and all its income.
+type AISlotSettings struct {
+	Personality string // One of AIPersonalityNames ("" = balanced)
+	Difficulty  string // One of AIDifficultyNames ("" = normal)
+	Handicap    int    // Percent of normal starting resources and income (0 = 100)
+}
+
+// IsSinglePlayer reports whether one human plays, against AI players only
+func (s GameSettings) IsSinglePlayer() bool {
+	return len(s.PlayerFactions) <= 1
 }
 
 // GameStats tracks game performance and statistics
@@ -52,14 +88,16 @@ type Game struct {
 	settings    GameSettings          // Game configuration
 	stats       GameStats             // Game performance statistics
 	world       *World                // Game world state
-	assetMgr    *data.AssetManager    // Asset management system
+	assetMgr    data.AssetProvider    // Asset management system
 	techTree    *data.TechTree        // Loaded tech tree data
 
 	// Lifecycle management
+	startSave   *SaveGame             // World state at the start of the match, for rematches
 	ctx         context.Context       // Game context for cancellation
 	cancel      context.CancelFunc    // Function to cancel game operations
 	updateTicker *time.Ticker         // Game update timer
 	isRunning   bool                  // Whether game loop is running
+	loopDone    chan struct{}         // Closed when the game loop has exited
 
 	// Game loop timing
 	targetFPS   int                   // Target frames per second
@@ -69,6 +107,10 @@ type Game struct {
 	// Event system (basic for now)
 	eventQueue  chan GameEvent        // Game event queue
 	maxEvents   int                   // Maximum events in queue
+	eventLog    eventHistory          // Recent events kept for crash reports
+
+	// Crash reporting
+	crashReportDir string             // Where crash bundles are written (empty = disabled)
 }
 
 // GameEvent represents an event that occurs during gameplay
@@ -80,6 +122,23 @@ type GameEvent struct {
 	Message     string                // Human-readable message
 }
 
+// Location returns where an event happened, for events tied to a place on the map
+func (e GameEvent) Location() (Vector3, bool) {
+	switch data := e.Data.(type) {
+	case Vector3:
+		return data, true
+	case AttackAlert:
+		return data.Position, true
+	case BuildingPower:
+		return data.Position, true
+	case map[string]interface{}:
+		if position, ok := data["position"].(Vector3); ok {
+			return position, true
+		}
+	}
+	return Vector3{}, false
+}
+
 // ResourceEvent represents a resource transaction event
 type ResourceEvent struct {
 	PlayerID     int                   // Player involved in transaction
@@ -87,6 +146,7 @@ type ResourceEvent struct {
 	Amount       int                   // Amount of resource
 	Source       string                // Source of transaction (building_generation, unit_cost, etc.)
 	Timestamp    time.Time             // When the transaction occurred
+	GameTime     time.Duration         // Game time of the transaction
 	TransactionType string             // "addition" or "deduction"
 }
 
@@ -108,13 +168,21 @@ const (
 	EventTypeBuildingCompleted                 // Building construction completed
 	EventTypePlayerDefeated                    // Player was defeated
 	EventTypePlayerVictory                     // Player achieved victory
+	EventTypeUnderAttack                       // A player's unit or building took damage
+	EventTypeTribute                           // A player sent resources to an ally
+	EventTypeBuildingPower                     // A building paused or resumed production for lack of workers or energy
+	EventTypeSurrenderEvaluation               // A player lost a key building and its outlook was assessed
+	EventTypeHelpRequested                     // A player under attack asked its allies for help
+	EventTypeCommandIssued                     // A player ordered an object of a shared faction
+	EventTypeCommandConflict                   // Two players sharing a faction ordered the same object at once
+	EventTypePlayerInactive                    // A human player went idle or disconnected, or was replaced for it
 )
 
 // NewGame creates a new game instance with the specified settings
-func NewGame(settings GameSettings, assetMgr *data.AssetManager) (*Game, error) {
+func NewGame(settings GameSettings, assetMgr data.AssetProvider) (*Game, error) {
 	// Validate settings
-	if err := validateGameSettings(settings); err != nil {
-		return nil, fmt.Errorf("invalid game settings: %w", err)
+	if err := validateMatchSettings(settings, assetMgr); err != nil {
+		return nil, err
 	}
 
 	// Create game context
@@ -147,15 +215,39 @@ func NewGame(settings GameSettings, assetMgr *data.AssetManager) (*Game, error)
 	}
 
 	// Initialize world
-	world, err := NewWorld(settings, game.techTree, assetMgr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize world: %w", err)
+	if err := game.createWorld(); err != nil {
+		return nil, err
 	}
-	game.world = world
 
 	return game, nil
 }
 
+// validateMatchSettings checks settings can start a match with assets
+func validateMatchSettings(settings GameSettings, assetMgr data.AssetProvider) error {
+	if err := validateGameSettings(settings); err != nil {
+		return fmt.Errorf("invalid game settings: %w", err)
+	}
+	if issues := ValidateSetup(settings); len(issues) > 0 {
+		return fmt.Errorf("invalid game setup: %s", strings.Join(issues, "; "))
+	}
+	if settings.TechTree != "" && assetMgr != nil && assetMgr.GetTechTreeRoot() != "" &&
+		filepath.Clean(assetMgr.GetTechTreeRoot()) != filepath.Clean(settings.TechTreeRoot()) {
+		return fmt.Errorf("assets are loaded from %s, but tech tree %s is chosen", assetMgr.GetTechTreeRoot(), settings.TechTree)
+	}
+	return nil
+}
+
+// createWorld creates the world of a match from the game's settings
+func (g *Game) createWorld() error {
+	world, err := NewWorld(g.settings, g.techTree, g.assetMgr)
+	if err != nil {
+		return fmt.Errorf("failed to initialize world: %w", err)
+	}
+	g.world = world
+	world.SetEventSink(g.sendEvent)
+	return nil
+}
+
 // Start begins the game and starts the game loop
 func (g *Game) Start() error {
 	g.mutex.Lock()
@@ -168,11 +260,15 @@ func (g *Game) Start() error {
 	if g.state != GameStateLoading {
 		return fmt.Errorf("game must be in loading state to start")
 	}
+	if g.world == nil {
+		return fmt.Errorf("no match is set up; start one with NewMatch")
+	}
 
 	// Initialize world state
 	if err := g.world.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize world: %w", err)
 	}
+	g.startSave = g.world.CaptureSaveGame()
 
 	// Transition to playing state
 	g.setState(GameStatePlaying)
@@ -180,7 +276,8 @@ func (g *Game) Start() error {
 
 	// Start game loop
 	g.updateTicker = time.NewTicker(g.frameTime)
-	go g.gameLoop()
+	g.loopDone = make(chan struct{})
+	go g.gameLoop(g.ctx, g.updateTicker, g.loopDone)
 
 	// Send game start event
 	g.sendEvent(GameEvent{
@@ -297,9 +394,10 @@ func (g *Game) GetStats() GameStats {
 	return stats
 }
 
-// GetWorld returns the game world (world pointer is immutable after creation)
+// GetWorld returns the world of the current match, nil between Reset and NewMatch
 func (g *Game) GetWorld() *World {
-	// No lock needed - world pointer is set once during creation and never changes
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
 	return g.world
 }
 
@@ -327,13 +425,17 @@ func (g *Game) GetEvents() []GameEvent {
 
 // Internal methods
 
-// gameLoop runs the main game update loop
-func (g *Game) gameLoop() {
-	for g.isRunning {
+// gameLoop runs the main game update loop until Stop cancels its context,
+// closing done when it exits
+func (g *Game) gameLoop(ctx context.Context, ticker *time.Ticker, done chan<- struct{}) {
+	defer close(done)
+	defer g.recoverCrash()
+
+	for {
 		select {
-		case <-g.ctx.Done():
+		case <-ctx.Done():
 			return
-		case <-g.updateTicker.C:
+		case <-ticker.C:
 			g.update()
 		}
 	}
@@ -379,9 +481,17 @@ func (g *Game) setState(newState GameState) {
 	// Handle state transition logic
 	switch newState {
 	case GameStatePlaying:
-		// Game is now active
+		// Game is now active; commands given during a pause run now
+		if g.world != nil && g.world.commandProcessor != nil {
+			if err := g.world.commandProcessor.SetPaused(false); err != nil {
+				logging.Warnf(logging.CategoryEngine, "Commands issued while paused failed: %v", err)
+			}
+		}
 	case GameStatePaused:
-		// Game is paused, stop certain systems
+		// Game is paused, stop certain systems; a single player can keep giving orders
+		if g.world != nil && g.world.commandProcessor != nil && g.settings.IsSinglePlayer() {
+			g.world.commandProcessor.SetPaused(true)
+		}
 	case GameStateEnded:
 		// Game has ended, begin cleanup
 	}
@@ -391,6 +501,8 @@ func (g *Game) setState(newState GameState) {
 
 // sendEvent adds an event to the event queue
 func (g *Game) sendEvent(event GameEvent) {
+	g.eventLog.add(event)
+
 	// Non-blocking send to avoid deadlocks
 	select {
 	case g.eventQueue <- event:
@@ -482,7 +594,7 @@ func (g *Game) loadTechTree() error {
 
 // validateGameSettings validates the provided game settings
 func validateGameSettings(settings GameSettings) error {
-	if settings.TechTreePath == "" {
+	if settings.TechTreePath == "" && settings.TechTree == "" {
 		return fmt.Errorf("tech tree path cannot be empty")
 	}
 
@@ -507,6 +619,40 @@ func validateGameSettings(settings GameSettings) error {
 		return fmt.Errorf("at least one player must be configured")
 	}
 
+	for controllerID, ownerID := range settings.SharedControl {
+		if _, ok := settings.PlayerFactions[ownerID]; !ok {
+			return fmt.Errorf("shared faction of player %d is not a human player's", ownerID)
+		}
+		_, human := settings.PlayerFactions[controllerID]
+		_, ai := settings.AIFactions[controllerID]
+		if human || ai {
+			return fmt.Errorf("player %d cannot share a faction while playing one", controllerID)
+		}
+	}
+
+	if settings.InactivityTimeout < 0 {
+		return fmt.Errorf("inactivity timeout cannot be negative")
+	}
+
+	if err := validateTeamOptions(settings); err != nil {
+		return err
+	}
+
+	for playerID, slot := range settings.AISlots {
+		if _, ok := settings.AIFactions[playerID]; !ok {
+			return fmt.Errorf("AI settings given for player %d, which is not an AI player", playerID)
+		}
+		if slot.Personality != "" && !containsString(AIPersonalityNames, slot.Personality) {
+			return fmt.Errorf("unknown AI personality %q for player %d", slot.Personality, playerID)
+		}
+		if slot.Difficulty != "" && !containsString(AIDifficultyNames, slot.Difficulty) {
+			return fmt.Errorf("unknown AI difficulty %q for player %d", slot.Difficulty, playerID)
+		}
+		if slot.Handicap < 0 {
+			return fmt.Errorf("AI handicap for player %d cannot be negative", playerID)
+		}
+	}
+
 	return nil
 }
 
@@ -552,6 +698,20 @@ func (et GameEventType) String() string {
 		return "PlayerDefeated"
 	case EventTypePlayerVictory:
 		return "PlayerVictory"
+	case EventTypeUnderAttack:
+		return "UnderAttack"
+	case EventTypeTribute:
+		return "Tribute"
+	case EventTypeBuildingPower:
+		return "BuildingPower"
+	case EventTypeSurrenderEvaluation:
+		return "SurrenderEvaluation"
+	case EventTypeCommandIssued:
+		return "CommandIssued"
+	case EventTypeCommandConflict:
+		return "CommandConflict"
+	case EventTypePlayerInactive:
+		return "PlayerInactive"
 	default:
 		return "Unknown"
 	}
@@ -308,6 +308,26 @@ func TestGameEventTypeString(t *testing.T) {
 	}
 }
 
+func TestGameEventLocation(t *testing.T) {
+	position := Vector3{X: 4, Z: 7}
+	located := []GameEvent{
+		{Type: EventTypeResourceDepleted, Data: map[string]interface{}{"nodeID": 3, "position": position}},
+		{Type: EventTypeBuildingCompleted, Data: position},
+	}
+	for _, event := range located {
+		if got, ok := event.Location(); !ok || got != position {
+			t.Errorf("Expected %v to be located at %v, got %v (%v)", event.Type, position, got, ok)
+		}
+	}
+
+	if _, ok := (GameEvent{Type: EventTypeGamePause}).Location(); ok {
+		t.Error("Expected an event without data to have no location")
+	}
+	if _, ok := (GameEvent{Data: map[string]int{"currentPop": 5}}).Location(); ok {
+		t.Error("Expected an event without a position to have no location")
+	}
+}
+
 func TestGameConcurrency(t *testing.T) {
 	game := createTestGame(t)
 
@@ -0,0 +1,323 @@
+package engine
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Group move orders
+const (
+	groupFlowMargin = 16 // Tiles around the units and the target a group move's flow field covers
+	groupSlotSlack  = 2  // Slots considered per unit when spreading a group over its destination
+)
+
+// GroupMovePlan is one shared plan for moving many units to a point: a
+// single flow field toward the target, which every unit follows, and a slot
+// for each unit around the target, spread the way the units stood. Its cost
+// is one search over the tiles around the units and the target, however
+// many units there are.
+type GroupMovePlan struct {
+	Target     Vector3 // Where the order points
+	Orders     []GroupMoveOrder
+	FieldTiles int // Tiles the flow field covers
+}
+
+// GroupMoveOrder is one unit's part of a group move
+type GroupMoveOrder struct {
+	UnitID int
+	Slot   Vector3   // Where the unit ends up
+	Path   []Vector3 // Way to the slot along the flow field; nil when the unit is beyond the field and finds its own way
+}
+
+// flowField holds the cost to a target from every tile of an area and the
+// next tile on the way there
+type flowField struct {
+	origin        Vector2i // Top left tile of the area
+	width, height int
+	cost          []float64
+	next          []int // Index of the next tile toward the target, -1 at the target or where unreached
+	order         []int // Tiles by cost, nearest the target first
+	members       map[Vector2i]bool
+}
+
+// PlanGroupMove plans a move of units to a target, across hazards when
+// ordered through them; units are given slots in ID order
+func (pm *PathfindingManager) PlanGroupMove(units []*GameUnit, target Vector3, throughHazards bool) (*GroupMovePlan, error) {
+	if len(units) == 0 {
+		return nil, fmt.Errorf("no units to move")
+	}
+	units = append([]*GameUnit(nil), units...)
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+
+	// The field covers the units and the target with room to go around
+	// what lies between them
+	goal := pm.world.WorldToGrid(target).Grid
+	low, high := goal, goal
+	members := make(map[Vector2i]bool, len(units))
+	for _, unit := range units {
+		tile := pm.world.WorldToGrid(unit.Position).Grid
+		for _, covered := range footprintTiles(tile, unit.Footprint()) {
+			members[covered] = true
+		}
+		if tile.X < low.X {
+			low.X = tile.X
+		}
+		if tile.Y < low.Y {
+			low.Y = tile.Y
+		}
+		if tile.X > high.X {
+			high.X = tile.X
+		}
+		if tile.Y > high.Y {
+			high.Y = tile.Y
+		}
+	}
+	low = Vector2i{X: low.X - groupFlowMargin, Y: low.Y - groupFlowMargin}
+	high = Vector2i{X: high.X + groupFlowMargin, Y: high.Y + groupFlowMargin}
+	if low.X < 0 {
+		low.X = 0
+	}
+	if low.Y < 0 {
+		low.Y = 0
+	}
+	if high.X >= pm.world.Width {
+		high.X = pm.world.Width - 1
+	}
+	if high.Y >= pm.world.Height {
+		high.Y = pm.world.Height - 1
+	}
+
+	field := &flowField{
+		origin:  low,
+		width:   high.X - low.X + 1,
+		height:  high.Y - low.Y + 1,
+		members: members,
+	}
+	request := PathRequest{UnitSize: 1, PlayerID: units[0].PlayerID, IgnoreHazards: throughHazards}
+	if !field.contains(goal) || !pm.flowPassable(field, goal, request) {
+		goal = pm.world.ObjectManager.UnitManager.FindNearestFreePosition(goal)
+	}
+	if !field.contains(goal) {
+		return nil, fmt.Errorf("no free tile near the target")
+	}
+	pm.fillFlowField(field, goal, request)
+
+	plan := &GroupMovePlan{Target: target, FieldTiles: field.width * field.height}
+	slots := pm.groupSlots(field, units, goal)
+	for i, unit := range units {
+		order := GroupMoveOrder{UnitID: unit.ID, Slot: pm.pathfinder.gridToWorld(GridPosition{Grid: slots[i], Offset: Vector2{X: 0.5, Y: 0.5}})}
+		order.Path = field.path(pm.world.WorldToGrid(unit.Position).Grid, slots[i], pm.pathfinder)
+		if order.Path != nil {
+			order.Path[len(order.Path)-1] = order.Slot
+		}
+		plan.Orders = append(plan.Orders, order)
+	}
+	return plan, nil
+}
+
+// flowPassable reports whether a group may cross a tile; tiles the group's
+// own units stand on are open to it
+func (pm *PathfindingManager) flowPassable(field *flowField, tile Vector2i, request PathRequest) bool {
+	if field.members[tile] {
+		return pm.world.groundWalkable(tile)
+	}
+	return pm.pathfinder.isPassable(tile.X, tile.Y, request)
+}
+
+// groundWalkable reports whether a tile's ground is walkable, whoever
+// stands on it
+func (w *World) groundWalkable(tile Vector2i) bool {
+	w.gridMutex.RLock()
+	defer w.gridMutex.RUnlock()
+	return w.isValidGridPosition(tile) && w.walkableGrid[tile.Y][tile.X]
+}
+
+// fillFlowField searches outward from the goal over the field's area, with
+// the costs the pathfinder gives terrain and hazards
+func (pm *PathfindingManager) fillFlowField(field *flowField, goal Vector2i, request PathRequest) {
+	field.cost = make([]float64, field.width*field.height)
+	field.next = make([]int, field.width*field.height)
+	for i := range field.cost {
+		field.cost[i], field.next[i] = math.Inf(1), -1
+	}
+	field.cost[field.index(goal)] = 0
+	open := &cellQueue{{cell: goal}}
+	for open.Len() > 0 {
+		current := heap.Pop(open).(cellQueueItem)
+		if current.cost > field.cost[field.index(current.cell)] {
+			continue
+		}
+		field.order = append(field.order, field.index(current.cell))
+		for _, step := range neighborSteps {
+			tile := Vector2i{X: current.cell.X + step.X, Y: current.cell.Y + step.Y}
+			if !field.contains(tile) || !pm.flowPassable(field, tile, request) {
+				continue
+			}
+			length := 1.0
+			if step.X != 0 && step.Y != 0 {
+				length = math.Sqrt2
+			}
+			terrain := pm.pathfinder.getTerrainCost(tile.X, tile.Y) + pm.pathfinder.getHazardCost(tile.X, tile.Y, request)
+			cost := current.cost + length*float64(terrain)
+			if cost < field.cost[field.index(tile)] {
+				field.cost[field.index(tile)], field.next[field.index(tile)] = cost, field.index(current.cell)
+				heap.Push(open, cellQueueItem{cell: tile, cost: cost})
+			}
+		}
+	}
+}
+
+// groupSlots gives each unit a tile near the goal, keeping the units' places
+// relative to each other, squeezed to fit the tiles nearest the goal; a
+// unit's slot leaves room for its whole body
+func (pm *PathfindingManager) groupSlots(field *flowField, units []*GameUnit, goal Vector2i) []Vector2i {
+	area := 0
+	for _, unit := range units {
+		area += unit.Footprint() * unit.Footprint()
+	}
+	candidates := field.order
+	if limit := area * groupSlotSlack; len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	var center Vector2
+	tiles := make([]Vector2i, len(units))
+	for i, unit := range units {
+		tiles[i] = pm.world.WorldToGrid(unit.Position).Grid
+		center.X += float64(tiles[i].X) / float64(len(units))
+		center.Y += float64(tiles[i].Y) / float64(len(units))
+	}
+	spread := 0.0
+	for _, tile := range tiles {
+		spread = math.Max(spread, math.Hypot(float64(tile.X)-center.X, float64(tile.Y)-center.Y))
+	}
+	squeeze := 1.0
+	if fit := math.Sqrt(float64(area)); spread > fit {
+		squeeze = fit / spread
+	}
+
+	taken := make(map[Vector2i]bool, area)
+	slots := make([]Vector2i, len(units))
+	for i, tile := range tiles {
+		size := units[i].Footprint()
+		wantX := float64(goal.X) + (float64(tile.X)-center.X)*squeeze
+		wantY := float64(goal.Y) + (float64(tile.Y)-center.Y)*squeeze
+		best, bestDistance := -1, math.MaxFloat64
+		for _, candidate := range candidates {
+			slot := field.tileAt(candidate)
+			distance := math.Hypot(float64(slot.X)-wantX, float64(slot.Y)-wantY)
+			if distance < bestDistance && pm.slotFree(field, slot, size, taken) {
+				best, bestDistance = candidate, distance
+			}
+		}
+		if best < 0 {
+			slots[i] = goal // More units than open tiles; they crowd the goal
+			continue
+		}
+		slots[i] = field.tileAt(best)
+		for _, covered := range footprintTiles(slots[i], size) {
+			taken[covered] = true
+		}
+	}
+	return slots
+}
+
+// slotFree reports whether a unit of a size fits on a slot: every tile its
+// body would cover lies reached in the field and is not taken by another
+// unit's slot
+func (pm *PathfindingManager) slotFree(field *flowField, slot Vector2i, size int, taken map[Vector2i]bool) bool {
+	for _, covered := range footprintTiles(slot, size) {
+		if taken[covered] || !field.contains(covered) || math.IsInf(field.cost[field.index(covered)], 1) {
+			return false
+		}
+	}
+	return true
+}
+
+// path returns the way from a tile to a slot through the field: down the
+// field until it meets the slot's own way to the goal, then back up that
+// way to the slot. It is nil when the tile lies outside the field or the
+// field never reached it.
+func (field *flowField) path(from, slot Vector2i, pf *Pathfinder) []Vector3 {
+	if !field.contains(from) || math.IsInf(field.cost[field.index(from)], 1) || math.IsInf(field.cost[field.index(slot)], 1) {
+		return nil
+	}
+	slotWay := make(map[int]int) // Tile to its place on the slot's way
+	var fromSlot []int
+	for i := field.index(slot); i >= 0; i = field.next[i] {
+		slotWay[i] = len(fromSlot)
+		fromSlot = append(fromSlot, i)
+	}
+
+	var tiles []int
+	meet := 0
+	for i := field.index(from); ; i = field.next[i] {
+		tiles = append(tiles, i)
+		if place, ok := slotWay[i]; ok {
+			meet = place
+			break
+		}
+	}
+	for place := meet - 1; place >= 0; place-- {
+		tiles = append(tiles, fromSlot[place])
+	}
+
+	path := make([]Vector3, len(tiles))
+	for i, index := range tiles {
+		path[i] = pf.gridToWorld(GridPosition{Grid: field.tileAt(index)})
+	}
+	return path
+}
+
+// contains reports whether a tile lies within the field's area
+func (field *flowField) contains(tile Vector2i) bool {
+	return tile.X >= field.origin.X && tile.Y >= field.origin.Y &&
+		tile.X < field.origin.X+field.width && tile.Y < field.origin.Y+field.height
+}
+
+// index returns a tile's place in the field's rows
+func (field *flowField) index(tile Vector2i) int {
+	return (tile.Y-field.origin.Y)*field.width + tile.X - field.origin.X
+}
+
+// tileAt returns the tile at a place in the field's rows
+func (field *flowField) tileAt(index int) Vector2i {
+	return Vector2i{X: field.origin.X + index%field.width, Y: field.origin.Y + index/field.width}
+}
+
+// SubmitGroupMove gives units a player selected a move command with one
+// shared plan, after the configured latency: each unit gets a copy of the
+// command sending it to its slot. A queued order only spreads the units
+// over their slots, as they set out from wherever their earlier orders
+// leave them.
+func (cp *CommandProcessor) SubmitGroupMove(unitIDs []int, command UnitCommand) error {
+	if command.Type != CommandMove || command.Target == nil {
+		return fmt.Errorf("group orders need a move command with a target")
+	}
+	units := make([]*GameUnit, 0, len(unitIDs))
+	for _, id := range unitIDs {
+		unit := cp.world.ObjectManager.GetUnit(id)
+		if unit == nil {
+			return fmt.Errorf("unit %d not found", id)
+		}
+		units = append(units, unit)
+	}
+	plan, err := cp.world.pathfindingMgr.PlanGroupMove(units, *command.Target, command.ThroughHazards())
+	if err != nil {
+		return fmt.Errorf("failed to plan group move: %w", err)
+	}
+	for _, order := range plan.Orders {
+		unitCommand := command
+		slot := order.Slot
+		unitCommand.Target = &slot
+		unitCommand.GridTarget = nil
+		if !command.IsQueued {
+			unitCommand.Path = order.Path
+		}
+		if err := cp.SubmitCommand(order.UnitID, unitCommand); err != nil {
+			return fmt.Errorf("failed to issue command to unit %d: %w", order.UnitID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestGroupMove tests that a move of many units is planned as one flow
+// field that takes every unit around a wall to its own slot at the target
+func TestGroupMove(t *testing.T) {
+	world, err := NewHeadlessWorld(64, 64)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	for y := 0; y <= 20; y++ {
+		world.SetWalkable(Vector2i{X: 30, Y: y}, false)
+	}
+	var ids []int
+	var units []*GameUnit
+	for y := 10; y < 15; y++ {
+		for x := 5; x < 15; x++ {
+			unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: float64(x) + 0.5, Z: float64(y) + 0.5}, data.NewSimpleUnit("soldier", 100, 0, "leather", nil))
+			if err != nil {
+				t.Fatalf("Failed to create unit: %v", err)
+			}
+			ids = append(ids, unit.ID)
+			units = append(units, unit)
+		}
+	}
+
+	target := Vector3{X: 50.5, Z: 12.5}
+	plan, err := world.pathfindingMgr.PlanGroupMove(units, target, false)
+	if err != nil {
+		t.Fatalf("Failed to plan group move: %v", err)
+	}
+	if len(plan.Orders) != len(units) || plan.FieldTiles >= world.Width*world.Height {
+		t.Fatalf("Expected an order per unit from a field short of the whole map, got %d orders over %d tiles", len(plan.Orders), plan.FieldTiles)
+	}
+	slots := make(map[Vector2i]bool)
+	for _, order := range plan.Orders {
+		slot := world.WorldToGrid(order.Slot).Grid
+		if slots[slot] {
+			t.Errorf("Expected each unit a slot of its own, %v given twice", slot)
+		}
+		slots[slot] = true
+		if math.Hypot(order.Slot.X-target.X, order.Slot.Z-target.Z) > math.Sqrt(float64(len(units)))+2 {
+			t.Errorf("Expected the slot near the target, got %v", order.Slot)
+		}
+		if len(order.Path) == 0 || order.Path[len(order.Path)-1] != order.Slot {
+			t.Fatalf("Expected unit %d a path to its slot, got %v", order.UnitID, order.Path)
+		}
+		aroundWall := false
+		for i, waypoint := range order.Path {
+			tile := world.WorldToGrid(waypoint).Grid
+			if !world.groundWalkable(tile) {
+				t.Fatalf("Expected unit %d's path on walkable ground, got %v", order.UnitID, tile)
+			}
+			if i > 0 {
+				last := world.WorldToGrid(order.Path[i-1]).Grid
+				if absInt(tile.X-last.X) > 1 || absInt(tile.Y-last.Y) > 1 {
+					t.Fatalf("Expected unit %d's path tile by tile, got %v after %v", order.UnitID, tile, last)
+				}
+			}
+			aroundWall = aroundWall || tile.X == 30 && tile.Y > 20
+		}
+		if !aroundWall {
+			t.Errorf("Expected unit %d to go around the wall", order.UnitID)
+		}
+	}
+
+	// The units keep their places relative to each other
+	first, last := plan.Orders[0], plan.Orders[len(plan.Orders)-1]
+	if first.Slot.X >= last.Slot.X || first.Slot.Z >= last.Slot.Z {
+		t.Errorf("Expected the first unit's slot above and left of the last's, got %v and %v", first.Slot, last.Slot)
+	}
+
+	// Submitted orders carry their planned way, which the units follow
+	// without searching for their own
+	command := CreateMoveCommand(target, false)
+	if err := world.commandProcessor.SubmitGroupMove(ids, command); err != nil {
+		t.Fatalf("Failed to submit group move: %v", err)
+	}
+	unit := units[0]
+	if unit.CurrentCommand == nil || *unit.CurrentCommand.Target != first.Slot || len(unit.CurrentCommand.Path) != len(first.Path) {
+		t.Fatalf("Expected the unit ordered to its slot on its planned way, got %+v", unit.CurrentCommand)
+	}
+	world.commandProcessor.processMoveCommand(unit, unit.CurrentCommand, 10*time.Millisecond)
+	if len(unit.Path) != len(first.Path) || unit.CurrentCommand.Path != nil {
+		t.Errorf("Expected the unit to take up its planned way, got %d waypoints", len(unit.Path))
+	}
+	if err := world.commandProcessor.SubmitGroupMove(ids, CreateStopCommand()); err == nil {
+		t.Error("Expected group orders other than moves to be refused")
+	}
+}
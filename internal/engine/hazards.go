@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"time"
+)
+
+// Hazard surfaces
+const (
+	defaultHazardInterval = time.Second // Time between hazard ticks when the tileset sets none
+	defaultHazardPathCost = 8.0         // Extra cost of entering a hazard tile when the tileset sets none
+
+	// ParamThroughHazards is the move command parameter ordering a unit
+	// straight across hazards instead of around them
+	ParamThroughHazards = "through_hazards"
+)
+
+// SurfaceHazard is a harmful surface such as lava or a poison swamp that
+// hurts units standing on it
+type SurfaceHazard struct {
+	Name     string        `json:"name"`
+	Damage   int           `json:"damage"`    // Damage each tick, ignoring armor
+	Interval time.Duration `json:"interval"`  // Time between ticks
+	Effect   string        `json:"effect"`    // Status effect applied each tick ("" = none)
+	PathCost float32       `json:"path_cost"` // Extra pathfinding cost of entering the tile
+}
+
+// hazardTracker times how long units have stood on hazards
+type hazardTracker struct {
+	exposure map[int]time.Duration // Unit ID -> time on a hazard since its last tick
+}
+
+// SetHazard makes a tile hazardous, or safe again with a nil hazard
+func (w *World) SetHazard(cell Vector2i, hazard *SurfaceHazard) {
+	w.gridMutex.Lock()
+	defer w.gridMutex.Unlock()
+	if hazard == nil {
+		delete(w.hazards, cell)
+		return
+	}
+	if w.hazards == nil {
+		w.hazards = make(map[Vector2i]*SurfaceHazard)
+	}
+	w.hazards[cell] = hazard
+}
+
+// HazardAt returns the hazard on a tile, or nil when it is safe
+func (w *World) HazardAt(cell Vector2i) *SurfaceHazard {
+	w.gridMutex.RLock()
+	defer w.gridMutex.RUnlock()
+	return w.hazards[cell]
+}
+
+// applyTilesetHazards marks the tiles whose tileset surface is hazardous
+func (w *World) applyTilesetHazards(mapData *Map) {
+	if mapData.Tileset == nil {
+		return
+	}
+	for y := 0; y < mapData.Height; y++ {
+		for x := 0; x < mapData.Width; x++ {
+			if surface := mapData.Tileset.GetSurface(int(mapData.SurfaceMap[y][x])); surface != nil && surface.Hazard != nil {
+				w.SetHazard(Vector2i{X: x, Y: y}, surface.Hazard)
+			}
+		}
+	}
+}
+
+// updateHazards damages units standing on hazards once per hazard interval
+// and applies the hazard's status effect
+func (w *World) updateHazards(deltaTime time.Duration) {
+	w.gridMutex.RLock()
+	empty := len(w.hazards) == 0
+	w.gridMutex.RUnlock()
+	if empty && len(w.hazardTracker.exposure) == 0 {
+		return
+	}
+
+	exposure := make(map[int]time.Duration)
+	for _, player := range w.GetAllPlayers() {
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(player.ID) {
+			if !unit.IsAlive() || unit.GarrisonedIn != 0 {
+				continue
+			}
+			hazard := w.HazardAt(unit.GetGridPosition().Grid)
+			if hazard == nil {
+				continue
+			}
+
+			exposed := w.hazardTracker.exposure[unit.ID] + deltaTime
+			for exposed >= hazard.Interval && unit.IsAlive() {
+				exposed -= hazard.Interval
+				w.applyHazard(unit, hazard)
+			}
+			exposure[unit.ID] = exposed
+		}
+	}
+	w.hazardTracker.exposure = exposure
+}
+
+// applyHazard deals one tick of a hazard to a unit
+func (w *World) applyHazard(unit *GameUnit, hazard *SurfaceHazard) {
+	if hazard.Effect != "" {
+		w.commandProcessor.statusEffectMgr.ApplyStatusEffect(unit, hazard.Effect, nil)
+	}
+	if hazard.Damage > 0 {
+		w.commandProcessor.combatSystem.ApplyDamage(unit, hazard.Damage)
+	}
+}
+
+// ThroughHazards reports whether a move command orders the unit straight
+// across hazards
+func (c *UnitCommand) ThroughHazards() bool {
+	through, _ := c.Parameters[ParamThroughHazards].(bool)
+	return through
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestTilesetHazards tests that tileset surfaces can be tagged as hazards
+func TestTilesetHazards(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "tilesets", "volcano")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create tileset directory: %v", err)
+	}
+	content := `<tileset>
+	<surfaces>
+		<surface><texture path="textures/rock.bmp" prob="1.0"/></surface>
+		<surface>
+			<texture path="textures/lava.bmp" prob="1.0"/>
+			<hazard name="lava" damage="8" interval="0.5" effect="burn" path-cost="12"/>
+		</surface>
+		<surface>
+			<texture path="textures/swamp.bmp" prob="1.0"/>
+			<hazard name="swamp" effect="poison"/>
+		</surface>
+	</surfaces>
+</tileset>`
+	if err := os.WriteFile(filepath.Join(dir, "volcano.xml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write tileset: %v", err)
+	}
+
+	tileset, err := NewTilesetLoader(root).LoadTileset("volcano")
+	if err != nil {
+		t.Fatalf("Failed to load tileset: %v", err)
+	}
+	if tileset.GetSurface(1).Hazard != nil {
+		t.Error("Expected untagged surfaces to be safe")
+	}
+	lava := tileset.GetSurface(2).Hazard
+	if lava == nil || lava.Damage != 8 || lava.Interval != 500*time.Millisecond || lava.Effect != "burn" || lava.PathCost != 12 {
+		t.Errorf("Expected the lava hazard from the XML, got %+v", lava)
+	}
+	swamp := tileset.GetSurface(3).Hazard
+	if swamp == nil || swamp.Interval != defaultHazardInterval || swamp.PathCost != defaultHazardPathCost {
+		t.Errorf("Expected defaults for the swamp hazard, got %+v", swamp)
+	}
+}
+
+// TestHazardDamage tests that units standing on a hazard take damage and its
+// status effect every interval, and only while they stay on it
+func TestHazardDamage(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	lava := &SurfaceHazard{Name: "lava", Damage: 5, Interval: time.Second, Effect: "burn", PathCost: 10}
+	world.SetHazard(Vector2i{X: 10, Y: 10}, lava)
+
+	soldier := data.NewSimpleUnit("soldier", 100, 3, "leather", nil)
+	burnt, _ := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 10.5, Z: 10.5}, soldier)
+	safe, _ := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 12.5, Z: 10.5}, soldier)
+
+	world.updateHazards(600 * time.Millisecond)
+	if burnt.Health != 100 {
+		t.Errorf("Expected no damage before a full interval, health %d", burnt.Health)
+	}
+	world.updateHazards(600 * time.Millisecond)
+	if burnt.Health != 95 || safe.Health != 100 {
+		t.Errorf("Expected one tick ignoring armor on the hazard only, health %d and %d", burnt.Health, safe.Health)
+	}
+	if !world.commandProcessor.statusEffectMgr.HasEffect(burnt.ID, "burn") {
+		t.Error("Expected the hazard to set the unit on fire")
+	}
+	world.updateHazards(2 * time.Second)
+	if burnt.Health != 85 {
+		t.Errorf("Expected a tick for every interval, health %d", burnt.Health)
+	}
+
+	// Leaving the hazard resets the exposure
+	world.SetHazard(Vector2i{X: 10, Y: 10}, nil)
+	world.updateHazards(900 * time.Millisecond)
+	world.SetHazard(Vector2i{X: 10, Y: 10}, lava)
+	world.updateHazards(900 * time.Millisecond)
+	if burnt.Health != 85 {
+		t.Errorf("Expected the time away from the hazard not to count, health %d", burnt.Health)
+	}
+}
+
+// TestHazardPathing tests that paths go around hazards unless the unit is
+// ordered through them
+func TestHazardPathing(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	swamp := &SurfaceHazard{Name: "swamp", Effect: "poison", Interval: time.Second, PathCost: defaultHazardPathCost}
+	for x := 9; x <= 11; x++ {
+		for y := 8; y <= 12; y++ {
+			world.SetHazard(Vector2i{X: x, Y: y}, swamp)
+		}
+	}
+
+	crosses := func(path []Vector3) bool {
+		for _, point := range path {
+			if world.HazardAt(world.WorldToGrid(point).Grid) != nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	unit, _ := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 5.5, Z: 10.5}, data.NewSimpleUnit("soldier", 100, 0, "leather", nil))
+	target := Vector3{X: 15.5, Z: 10.5}
+
+	around := CreateMoveCommand(target, false)
+	unit.CurrentCommand = &around
+	result, err := world.pathfindingMgr.RequestPath(unit, target)
+	if err != nil || !result.Success {
+		t.Fatalf("Expected a path around the swamp: %v", err)
+	}
+	if crosses(result.Path) {
+		t.Error("Expected the path to avoid the swamp")
+	}
+
+	through := CreateMoveCommand(target, false)
+	through.Parameters = map[string]interface{}{ParamThroughHazards: true}
+	unit.CurrentCommand = &through
+	result, err = world.pathfindingMgr.RequestPath(unit, target)
+	if err != nil || !result.Success {
+		t.Fatalf("Expected a path through the swamp: %v", err)
+	}
+	if !crosses(result.Path) || len(result.Path) > 11 {
+		t.Errorf("Expected the straight path across the swamp, got %d steps", len(result.Path))
+	}
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"math"
+)
+
+// DefaultSightRange is the sight in tiles of units whose definition sets none
+const DefaultSightRange = 10
+
+// HighGroundModifiers configures how terrain height affects combat and sight
+type HighGroundModifiers struct {
+	HeightStep    float32 // Height difference that puts one side on the high ground
+	DamageBonus   float64 // Extra damage share of attacks made downhill (0.25 = +25%)
+	DamagePenalty float64 // Damage share lost by attacks made uphill
+	MissChance    float64 // Chance that a ranged attack made uphill misses
+	SightPerStep  float64 // Extra tiles of sight per HeightStep above the average ground
+	MaxSightBonus float64 // Most extra tiles of sight elevation can give
+}
+
+// DefaultHighGround is used when GameSettings does not set HighGround
+var DefaultHighGround = HighGroundModifiers{
+	HeightStep:    2.0,
+	DamageBonus:   0.25,
+	DamagePenalty: 0.25,
+	MissChance:    0.25,
+	SightPerStep:  1.0,
+	MaxSightBonus: 4.0,
+}
+
+// highGround returns the configured high-ground modifiers
+func (w *World) highGround() HighGroundModifiers {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	if w.settings.HighGround == nil {
+		return DefaultHighGround
+	}
+	return *w.settings.HighGround
+}
+
+// HeightAt returns the terrain height under a world position
+func (w *World) HeightAt(position Vector3) float32 {
+	return w.GetHeight(w.WorldToGrid(position).Grid)
+}
+
+// Elevation returns how many height steps the attacker stands above the
+// target: positive on the high ground, negative below it, 0 when level
+func (w *World) Elevation(from, to Vector3) int {
+	step := w.highGround().HeightStep
+	if step <= 0 {
+		return 0
+	}
+	difference := w.HeightAt(from) - w.HeightAt(to)
+	return int(difference / step)
+}
+
+// elevationMultiplier returns the damage multiplier of an attack made from
+// one position at another
+func (cs *CombatSystem) elevationMultiplier(from, to Vector3) float64 {
+	modifiers := cs.world.highGround()
+	switch elevation := cs.world.Elevation(from, to); {
+	case elevation > 0:
+		return 1 + modifiers.DamageBonus
+	case elevation < 0:
+		return math.Max(1-modifiers.DamagePenalty, 0)
+	default:
+		return 1
+	}
+}
+
+// missesUphill rolls whether a ranged attack made at higher ground misses
+func (cs *CombatSystem) missesUphill(from, to Vector3, attackType string) bool {
+	if cs.isMeleeAttack(attackType) || cs.world.Elevation(from, to) >= 0 {
+		return false
+	}
+	chance := cs.world.highGround().MissChance
+	return chance > 0 && cs.rng.Float64() < chance
+}
+
+// averageGround returns the average terrain height of the map, which
+// elevation sight bonuses are measured from
+func (w *World) averageGround() float32 {
+	w.gridMutex.Lock()
+	defer w.gridMutex.Unlock()
+	if w.groundLevelKnown {
+		return w.groundLevel
+	}
+
+	total, tiles := 0.0, 0
+	for _, row := range w.heightMap {
+		for _, height := range row {
+			total += float64(height)
+			tiles++
+		}
+	}
+	w.groundLevel = 0
+	if tiles > 0 {
+		w.groundLevel = float32(total / float64(tiles))
+	}
+	w.groundLevelKnown = true
+	return w.groundLevel
+}
+
+// SightRange returns a unit's sight in tiles, including the extra sight it
+// has standing above the average ground
+func (w *World) SightRange(unit *GameUnit) float64 {
+	sight := float64(DefaultSightRange)
+	if unit.UnitDef != nil && unit.UnitDef.Unit.Parameters.Sight.Value > 0 {
+		sight = float64(unit.UnitDef.Unit.Parameters.Sight.Value)
+	}
+
+	modifiers := w.highGround()
+	if modifiers.HeightStep <= 0 || modifiers.SightPerStep <= 0 {
+		return sight
+	}
+	steps := math.Floor(float64((w.HeightAt(unit.Position) - w.averageGround()) / modifiers.HeightStep))
+	if steps <= 0 {
+		return sight
+	}
+	bonus := steps * modifiers.SightPerStep
+	if modifiers.MaxSightBonus > 0 {
+		bonus = math.Min(bonus, modifiers.MaxSightBonus)
+	}
+	return sight + bonus
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestHighGround tests that attacks downhill hit harder, attacks uphill hit
+// softer and can miss, and that elevated units see further
+func TestHighGround(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	for x := 10; x < 14; x++ {
+		for y := 10; y < 14; y++ {
+			world.SetHeight(Vector2i{X: x, Y: y}, 6)
+		}
+	}
+
+	def := data.NewSimpleUnit("archer", 100, 0, "leather", nil)
+	def.Unit.Parameters.Sight.Value = 8
+	archer := func(playerID int, x, z float64) *GameUnit {
+		unit, _ := world.ObjectManager.CreateUnit(playerID, "archer", Vector3{X: x, Z: z}, def)
+		unit.AttackDamage, unit.AttackRange = 20, 6
+		return unit
+	}
+	hill, valley, plain := archer(1, 11.5, 11.5), archer(2, 15.5, 11.5), archer(2, 15.5, 15.5)
+
+	combat := world.commandProcessor.combatSystem
+	down, up := combat.CalculateDamage(hill, valley), combat.CalculateDamage(valley, hill)
+	if down.Elevation != 3 || down.Damage != 25 {
+		t.Errorf("Expected +25%% damage three steps downhill, got %d at elevation %d", down.Damage, down.Elevation)
+	}
+	if up.Elevation != -3 || up.Damage != 15 {
+		t.Errorf("Expected -25%% damage uphill, got %d at elevation %d", up.Damage, up.Elevation)
+	}
+	if level := combat.CalculateDamage(valley, plain); level.Elevation != 0 || level.Damage != 20 {
+		t.Errorf("Expected no modifier on level ground, got %d", level.Damage)
+	}
+
+	// Sight grows a tile per step above the average ground, up to the cap
+	if sight := world.SightRange(plain); sight != 8 {
+		t.Errorf("Expected the XML sight on the plain, got %.1f", sight)
+	}
+	if sight := world.SightRange(hill); sight != 10 {
+		t.Errorf("Expected two extra tiles on the hill, got %.1f", sight)
+	}
+
+	// Configurable, and ranged attacks uphill miss at the configured rate
+	modifiers := DefaultHighGround
+	modifiers.MissChance = 1
+	modifiers.MaxSightBonus = 1
+	world.settings.HighGround = &modifiers
+	if !combat.missesUphill(valley.Position, hill.Position, "arrow") {
+		t.Error("Expected a certain miss shooting uphill")
+	}
+	if combat.missesUphill(hill.Position, valley.Position, "arrow") || combat.missesUphill(valley.Position, hill.Position, "sword") {
+		t.Error("Expected only ranged attacks uphill to miss")
+	}
+	if sight := world.SightRange(hill); sight != 9 {
+		t.Errorf("Expected the sight bonus to be capped, got %.1f", sight)
+	}
+
+	world.settings.HighGround = &HighGroundModifiers{}
+	if damage := combat.CalculateDamage(hill, valley).Damage; damage != 20 || world.SightRange(hill) != 8 {
+		t.Errorf("Expected no modifiers when disabled, got %d damage", damage)
+	}
+}
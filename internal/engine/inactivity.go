@@ -0,0 +1,222 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A human player who gives no orders for GameSettings.InactivityTimeout of
+// game time, or who disconnects and does not come back within it, is handed
+// to the AI or surrendered. Everyone is warned InactivityWarning before.
+// Activity is tracked in game time from the commands the world executes, so
+// every peer of a lockstep match reaches the same verdict on the same tick.
+
+// DefaultInactivityWarning is how long before the timeout an idle player is
+// warned, when the timeout is longer than twice that
+const DefaultInactivityWarning = 30 * time.Second
+
+// InactivityAction is what happens to a player who stays idle past the timeout
+type InactivityAction int
+
+const (
+	InactivityTakeOver  InactivityAction = iota // An AI takes over the player's faction
+	InactivitySurrender                         // The player surrenders
+)
+
+// String returns the string representation of an InactivityAction
+func (a InactivityAction) String() string {
+	switch a {
+	case InactivityTakeOver:
+		return "ai"
+	case InactivitySurrender:
+		return "surrender"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParseInactivityAction parses an action name as printed by String
+func ParseInactivityAction(name string) (InactivityAction, error) {
+	switch strings.ToLower(name) {
+	case "ai", "":
+		return InactivityTakeOver, nil
+	case "surrender":
+		return InactivitySurrender, nil
+	default:
+		return 0, fmt.Errorf("unknown inactivity action %q (valid: ai, surrender)", name)
+	}
+}
+
+// InactivityNotice is the data of an EventTypePlayerInactive event
+type InactivityNotice struct {
+	PlayerID     int              // Idle player
+	IdleFor      time.Duration    // Game time since the player's last order or disconnect
+	Remaining    time.Duration    // Game time left before the action is taken (0 once taken)
+	Disconnected bool             // Whether the player lost its connection
+	Action       InactivityAction // What happens, or happened, at the timeout
+}
+
+// inactivityTracker follows when each human player last acted
+type inactivityTracker struct {
+	mutex        sync.Mutex
+	lastActive   map[int]time.Duration // Player ID -> game time of its last order or disconnect
+	disconnected map[int]bool
+	warned       map[int]bool
+}
+
+// init creates the tracker's maps on first use (lock must be held)
+func (t *inactivityTracker) init() {
+	if t.lastActive == nil {
+		t.lastActive = make(map[int]time.Duration)
+		t.disconnected = make(map[int]bool)
+		t.warned = make(map[int]bool)
+	}
+}
+
+// inactivitySettings returns the timeout, warning lead and action; a zero
+// timeout means detection is off
+func (w *World) inactivitySettings() (time.Duration, time.Duration, InactivityAction) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	timeout := w.settings.InactivityTimeout
+	warning := DefaultInactivityWarning
+	if timeout < 2*warning {
+		warning = timeout / 2
+	}
+	return timeout, warning, w.settings.InactivityAction
+}
+
+// MarkPlayerActive records that a player just acted, clearing any inactivity
+// warning. Orders given through IssuePlayerCommand count automatically.
+func (w *World) MarkPlayerActive(playerID int) {
+	now := w.GetGameTime()
+	w.inactivity.mutex.Lock()
+	defer w.inactivity.mutex.Unlock()
+	w.inactivity.init()
+	if w.inactivity.disconnected[playerID] {
+		return // Orders still queued from before the disconnect
+	}
+	w.inactivity.lastActive[playerID] = now
+	delete(w.inactivity.warned, playerID)
+}
+
+// SetPlayerConnected records a player losing or regaining its connection.
+// A disconnected player counts as idle from the moment it dropped.
+func (w *World) SetPlayerConnected(playerID int, connected bool) {
+	now := w.GetGameTime()
+	w.inactivity.mutex.Lock()
+	defer w.inactivity.mutex.Unlock()
+	w.inactivity.init()
+	if connected == !w.inactivity.disconnected[playerID] {
+		return
+	}
+	if connected {
+		delete(w.inactivity.disconnected, playerID)
+	} else {
+		w.inactivity.disconnected[playerID] = true
+	}
+	w.inactivity.lastActive[playerID] = now
+	delete(w.inactivity.warned, playerID)
+}
+
+// IdleTime returns the game time since a player's last order or disconnect
+func (w *World) IdleTime(playerID int) time.Duration {
+	now := w.GetGameTime()
+	w.inactivity.mutex.Lock()
+	defer w.inactivity.mutex.Unlock()
+	return now - w.inactivity.lastActive[playerID]
+}
+
+// updateInactivity warns about idle human players and takes action on those
+// past the timeout
+func (w *World) updateInactivity() {
+	timeout, warning, action := w.inactivitySettings()
+	if timeout <= 0 {
+		return
+	}
+	now := w.GetGameTime()
+
+	var notices []InactivityNotice
+	w.mutex.RLock()
+	w.inactivity.mutex.Lock()
+	w.inactivity.init()
+	for _, playerID := range sortedKeys(w.players) {
+		player := w.players[playerID]
+		if player.IsAI || !player.IsActive {
+			continue
+		}
+		idle := now - w.inactivity.lastActive[playerID]
+		notice := InactivityNotice{
+			PlayerID:     playerID,
+			IdleFor:      idle,
+			Disconnected: w.inactivity.disconnected[playerID],
+			Action:       action,
+		}
+		if idle >= timeout {
+			delete(w.inactivity.warned, playerID)
+			notices = append(notices, notice)
+		} else if idle >= timeout-warning && !w.inactivity.warned[playerID] {
+			w.inactivity.warned[playerID] = true
+			notice.Remaining = timeout - idle
+			notices = append(notices, notice)
+		}
+	}
+	w.inactivity.mutex.Unlock()
+	w.mutex.RUnlock()
+
+	for _, notice := range notices {
+		if notice.Remaining > 0 {
+			w.raiseEvent(GameEvent{
+				Type:      EventTypePlayerInactive,
+				Timestamp: w.now(),
+				PlayerID:  notice.PlayerID,
+				Data:      notice,
+				Message:   fmt.Sprintf("Player %d is inactive; %s in %v", notice.PlayerID, inactivityOutcome(action), notice.Remaining.Round(time.Second)),
+			})
+			continue
+		}
+		w.takeInactivityAction(notice)
+	}
+}
+
+// takeInactivityAction hands an idle player to the AI or surrenders it
+func (w *World) takeInactivityAction(notice InactivityNotice) {
+	if notice.Action == InactivitySurrender {
+		w.raiseEvent(GameEvent{
+			Type:      EventTypePlayerInactive,
+			Timestamp: w.now(),
+			PlayerID:  notice.PlayerID,
+			Data:      notice,
+			Message:   fmt.Sprintf("Player %d was inactive for %v and surrenders", notice.PlayerID, notice.IdleFor.Round(time.Second)),
+		})
+		w.Surrender(notice.PlayerID)
+		return
+	}
+
+	w.mutex.Lock()
+	player := w.players[notice.PlayerID]
+	player.IsAI = true
+	w.mutex.Unlock()
+	if err := w.InitializeAIPlayer(notice.PlayerID, "", ""); err != nil {
+		w.Surrender(notice.PlayerID) // Nobody can play the faction
+		return
+	}
+	w.raiseEvent(GameEvent{
+		Type:      EventTypePlayerInactive,
+		Timestamp: w.now(),
+		PlayerID:  notice.PlayerID,
+		Data:      notice,
+		Message:   fmt.Sprintf("Player %d was inactive for %v; the AI takes over", notice.PlayerID, notice.IdleFor.Round(time.Second)),
+	})
+}
+
+// inactivityOutcome describes an action for warnings
+func inactivityOutcome(action InactivityAction) string {
+	if action == InactivitySurrender {
+		return "surrendering"
+	}
+	return "AI takes over"
+}
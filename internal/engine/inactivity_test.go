@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestInactivity tests warning about and replacing idle players
+func TestInactivity(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.settings.InactivityTimeout = 2 * time.Minute
+	var notices []InactivityNotice
+	world.AddEventListener(func(event GameEvent) {
+		if event.Type == EventTypePlayerInactive {
+			notices = append(notices, event.Data.(InactivityNotice))
+		}
+	})
+	unitDef := &data.UnitDefinition{Name: "soldier"}
+	unitDef.Unit.Parameters.MaxHP.Value = 100
+	unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 5, Z: 5}, unitDef)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+
+	// Giving orders keeps a player active
+	world.Update(80 * time.Second)
+	if err := world.IssuePlayerCommand(1, unit.ID, CreateMoveCommand(Vector3{X: 8, Z: 8}, false)); err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	world.Update(30 * time.Second)
+	if len(notices) != 1 || notices[0].PlayerID != 2 || notices[0].Remaining != 10*time.Second {
+		t.Fatalf("Expected only the idle player 2 to be warned, got %+v", notices)
+	}
+
+	// Past the timeout the AI takes over
+	world.Update(10 * time.Second)
+	if len(notices) != 2 || notices[1].Remaining != 0 || !world.GetPlayer(2).IsAI {
+		t.Fatalf("Expected the AI to take over player 2, got %+v", notices)
+	}
+	world.Update(20 * time.Second)
+	if len(notices) != 2 {
+		t.Errorf("Expected no notices about an AI player, got %+v", notices[2:])
+	}
+
+	// A disconnected player is idle from the drop, even with orders queued before it
+	world.settings.InactivityAction = InactivitySurrender
+	world.SetPlayerConnected(1, false)
+	world.MarkPlayerActive(1)
+	if idle := world.IdleTime(1); idle != 0 {
+		t.Errorf("Expected the idle clock to start at the disconnect, got %v", idle)
+	}
+	save := world.CaptureSaveGame()
+	if err := world.RestoreSaveGame(save); err != nil {
+		t.Fatalf("RestoreSaveGame failed: %v", err)
+	}
+	world.Update(2 * time.Minute)
+	if last := notices[len(notices)-1]; !last.Disconnected || last.Action != InactivitySurrender || world.GetPlayer(1).IsActive {
+		t.Errorf("Expected the disconnected player to surrender, got %+v", last)
+	}
+
+	if action, err := ParseInactivityAction("surrender"); err != nil || action != InactivitySurrender {
+		t.Errorf("Expected surrender, got %v, %v", action, err)
+	}
+	if _, err := ParseInactivityAction("kick"); err == nil {
+		t.Error("Expected an unknown action to be rejected")
+	}
+}
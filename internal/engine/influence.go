@@ -0,0 +1,482 @@
+package engine
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Influence maps
+const (
+	influenceCellSize      = 2           // Tiles per side of an influence cell
+	influenceInterval      = time.Second // How often an AI brings its influence map up to date
+	influenceSpread        = 8.0         // Tiles over which a unit's strength fades out
+	influenceDangerMargin  = 3.0         // Tiles beyond its attack range an enemy is a danger
+	influenceEconomySpread = 3.0         // Tiles over which economic value fades out
+	influenceTolerance     = 0.1         // Share a source's strength may drift before it is restamped
+	influenceSafeDanger    = 1.0         // Danger below which a cell counts as safe
+	influenceDangerCost    = 0.5         // Extra steps a route pays per point of danger it crosses
+	influenceWorkerValue   = 1.0         // Economic value of a worker
+	influenceBuildingValue = 2.0         // Economic value of a building
+	influenceResourceValue = 1.0 / 1000  // Economic value of each unit of a remembered resource
+)
+
+// InfluenceLayer is one kind of influence an influence map keeps
+type InfluenceLayer int
+
+const (
+	InfluenceFriendly InfluenceLayer = iota // Military strength of our and our allies' forces
+	InfluenceEnemy                          // Military strength of the enemy forces we know of
+	InfluenceEconomy                        // Value of our workers and buildings and the resources we know of
+	InfluenceDanger                         // Enemy strength that can strike a cell
+	influenceLayers
+)
+
+// String returns the name of the layer
+func (layer InfluenceLayer) String() string {
+	switch layer {
+	case InfluenceFriendly:
+		return "friendly"
+	case InfluenceEnemy:
+		return "enemy"
+	case InfluenceEconomy:
+		return "economy"
+	case InfluenceDanger:
+		return "danger"
+	default:
+		return "unknown"
+	}
+}
+
+// influenceStamp is what a source adds to one layer around its cell
+type influenceStamp struct {
+	layer    InfluenceLayer
+	cell     Vector2i
+	strength float64
+	radius   float64 // Tiles
+}
+
+// InfluenceMap is an AI player's view of who holds which part of the map,
+// in cells of a few tiles: the strength of both sides' forces, what the
+// economy has at stake, and where the enemy can strike. Enemies come from
+// the player's intel, fading with their sightings. Each source is stamped
+// onto its layers and only restamped when it moves to another cell or its
+// strength changes noticeably, so updates cost little while armies stand.
+type InfluenceMap struct {
+	playerID      int
+	world         *World
+	intel         *IntelMemory
+	width, height int // Cells
+	layers        [influenceLayers][]float64
+	stamps        map[string][]influenceStamp // By source
+	updated       time.Time
+	restamped     int // Sources restamped by the last update
+}
+
+// NewInfluenceMap creates an empty influence map for a player, fed by its intel
+func NewInfluenceMap(playerID int, world *World, intel *IntelMemory) *InfluenceMap {
+	return &InfluenceMap{
+		playerID: playerID,
+		world:    world,
+		intel:    intel,
+		stamps:   make(map[string][]influenceStamp),
+	}
+}
+
+// Updated returns when the map was last brought up to date
+func (im *InfluenceMap) Updated() time.Time {
+	return im.updated
+}
+
+// Update restamps the sources that moved or changed strength since the
+// last update and removes those gone
+func (im *InfluenceMap) Update() {
+	w := im.world
+	im.updated = w.now()
+	im.restamped = 0
+	width := (w.Width + influenceCellSize - 1) / influenceCellSize
+	height := (w.Height + influenceCellSize - 1) / influenceCellSize
+	if width != im.width || height != im.height {
+		im.width, im.height = width, height
+		for layer := range im.layers {
+			im.layers[layer] = make([]float64, width*height)
+		}
+		im.stamps = make(map[string][]influenceStamp)
+	}
+
+	sources := im.sources()
+	for key, stamps := range im.stamps {
+		if _, ok := sources[key]; !ok {
+			im.apply(stamps, -1)
+			delete(im.stamps, key)
+			im.restamped++
+		}
+	}
+	keys := make([]string, 0, len(sources))
+	for key := range sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		stamps := sources[key]
+		if old, ok := im.stamps[key]; ok {
+			if !restampNeeded(old, stamps) {
+				continue
+			}
+			im.apply(old, -1)
+		}
+		im.apply(stamps, 1)
+		im.stamps[key] = stamps
+		im.restamped++
+	}
+}
+
+// sources returns the stamps each source of influence makes now
+func (im *InfluenceMap) sources() map[string][]influenceStamp {
+	w := im.world
+	tileSize := float64(w.GetTileSize())
+	sources := make(map[string][]influenceStamp)
+	stamp := func(layer InfluenceLayer, position Vector3, strength, radius float64) influenceStamp {
+		return influenceStamp{layer: layer, cell: im.cellOf(position), strength: strength, radius: radius}
+	}
+
+	for id := range w.GetAllPlayers() {
+		if id != im.playerID && !w.AreAllied(im.playerID, id) {
+			continue
+		}
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(id) {
+			if !unit.IsAlive() || unit.GarrisonedIn != 0 {
+				continue
+			}
+			var stamps []influenceStamp
+			if strength := unitStrength(unit); strength > 0 {
+				stamps = append(stamps, stamp(InfluenceFriendly, unit.Position, strength, influenceSpread))
+			}
+			if id == im.playerID && IsWorkerType(unit.UnitType) {
+				stamps = append(stamps, stamp(InfluenceEconomy, unit.Position, influenceWorkerValue, influenceEconomySpread))
+			}
+			if len(stamps) > 0 {
+				sources[fmt.Sprintf("unit %d", unit.ID)] = stamps
+			}
+		}
+		for _, building := range w.ObjectManager.GetBuildingsForPlayer(id) {
+			if !building.IsAlive() {
+				continue
+			}
+			var stamps []influenceStamp
+			if strength, reach := buildingStrength(building); strength > 0 {
+				stamps = append(stamps, stamp(InfluenceFriendly, building.Position, strength, reach))
+			}
+			if id == im.playerID {
+				stamps = append(stamps, stamp(InfluenceEconomy, building.Position, influenceBuildingValue, influenceEconomySpread))
+			}
+			if len(stamps) > 0 {
+				sources[fmt.Sprintf("building %d", building.ID)] = stamps
+			}
+		}
+	}
+
+	if im.intel == nil {
+		return sources
+	}
+	for _, sighting := range im.intel.Units() {
+		if sighting.Strength <= 0 {
+			continue
+		}
+		strength := sighting.Strength * im.intel.Confidence(sighting.LastSeen, intelUnitMemory)
+		sources[fmt.Sprintf("sighted unit %d", sighting.ID)] = []influenceStamp{
+			stamp(InfluenceEnemy, sighting.Position, strength, influenceSpread),
+			stamp(InfluenceDanger, sighting.Position, strength, sighting.Reach/tileSize+influenceDangerMargin),
+		}
+	}
+	for _, sighting := range im.intel.Buildings() {
+		if !sighting.Defensive || sighting.Building == nil {
+			continue
+		}
+		strength, reach := buildingStrength(sighting.Building)
+		strength *= im.intel.Confidence(sighting.LastSeen, intelBuildingMemory)
+		sources[fmt.Sprintf("sighted building %d", sighting.ID)] = []influenceStamp{
+			stamp(InfluenceEnemy, sighting.Position, strength, reach),
+			stamp(InfluenceDanger, sighting.Position, strength, reach+influenceDangerMargin),
+		}
+	}
+	for _, sighting := range im.intel.Resources() {
+		sources[fmt.Sprintf("sighted resource %d", sighting.ID)] = []influenceStamp{
+			stamp(InfluenceEconomy, sighting.Position, float64(sighting.Amount)*influenceResourceValue, influenceEconomySpread),
+		}
+	}
+	return sources
+}
+
+// unitStrength rates a unit's fighting strength as its attack damage
+// weighted by its health share
+func unitStrength(unit *GameUnit) float64 {
+	if unit.AttackDamage <= 0 {
+		return 0
+	}
+	if maxHealth := unit.GetMaxHealth(); maxHealth > 0 {
+		return float64(unit.AttackDamage) * float64(unit.GetHealth()) / float64(maxHealth)
+	}
+	return float64(unit.AttackDamage)
+}
+
+// buildingStrength rates a defensive building's fighting strength as
+// unitStrength does, and returns its reach in tiles
+func buildingStrength(building *GameBuilding) (float64, float64) {
+	building.mutex.RLock()
+	defer building.mutex.RUnlock()
+	if building.AttackDamage <= 0 {
+		return 0, 0
+	}
+	strength := float64(building.AttackDamage)
+	if building.MaxHealth > 0 {
+		strength *= float64(building.Health) / float64(building.MaxHealth)
+	}
+	return strength, float64(building.AttackRange)
+}
+
+// restampNeeded reports whether a source's stamps moved, or their strength
+// drifted past the tolerance
+func restampNeeded(old, stamps []influenceStamp) bool {
+	if len(old) != len(stamps) {
+		return true
+	}
+	for i := range stamps {
+		if old[i].layer != stamps[i].layer || old[i].cell != stamps[i].cell || old[i].radius != stamps[i].radius {
+			return true
+		}
+		if math.Abs(stamps[i].strength-old[i].strength) > influenceTolerance*old[i].strength {
+			return true
+		}
+	}
+	return false
+}
+
+// apply adds stamps to their layers, or takes them off for a sign of -1;
+// influence falls off linearly to nothing past the stamp's radius
+func (im *InfluenceMap) apply(stamps []influenceStamp, sign float64) {
+	for _, stamp := range stamps {
+		reach := stamp.radius/influenceCellSize + 1
+		cells := int(math.Ceil(reach))
+		for dy := -cells; dy <= cells; dy++ {
+			for dx := -cells; dx <= cells; dx++ {
+				x, y := stamp.cell.X+dx, stamp.cell.Y+dy
+				if x < 0 || y < 0 || x >= im.width || y >= im.height {
+					continue
+				}
+				falloff := 1 - math.Sqrt(float64(dx*dx+dy*dy))/reach
+				if falloff <= 0 {
+					continue
+				}
+				im.layers[stamp.layer][y*im.width+x] += sign * stamp.strength * falloff
+			}
+		}
+	}
+}
+
+// cellOf returns the cell a world position lies in, kept on the map
+func (im *InfluenceMap) cellOf(position Vector3) Vector2i {
+	tile := im.world.WorldToGrid(position).Grid
+	cell := Vector2i{X: tile.X / influenceCellSize, Y: tile.Y / influenceCellSize}
+	if cell.X < 0 {
+		cell.X = 0
+	}
+	if cell.Y < 0 {
+		cell.Y = 0
+	}
+	if cell.X >= im.width {
+		cell.X = im.width - 1
+	}
+	if cell.Y >= im.height {
+		cell.Y = im.height - 1
+	}
+	return cell
+}
+
+// cellCenter returns the world position at the middle of a cell
+func (im *InfluenceMap) cellCenter(cell Vector2i) Vector3 {
+	tileSize := float64(im.world.GetTileSize())
+	return Vector3{
+		X: (float64(cell.X) + 0.5) * influenceCellSize * tileSize,
+		Z: (float64(cell.Y) + 0.5) * influenceCellSize * tileSize,
+	}
+}
+
+// cellValue returns a layer's influence in a cell; what is left of stamps
+// taken off again is rounded away
+func (im *InfluenceMap) cellValue(layer InfluenceLayer, cell Vector2i) float64 {
+	value := im.layers[layer][cell.Y*im.width+cell.X]
+	if math.Abs(value) < 1e-9 {
+		return 0
+	}
+	return value
+}
+
+// Value returns a layer's influence at a position; it is 0 before the
+// first update
+func (im *InfluenceMap) Value(layer InfluenceLayer, position Vector3) float64 {
+	if im.width == 0 {
+		return 0
+	}
+	return im.cellValue(layer, im.cellOf(position))
+}
+
+// Balance returns our strength less the enemy's at a position: positive
+// where we hold the ground
+func (im *InfluenceMap) Balance(position Vector3) float64 {
+	return im.Value(InfluenceFriendly, position) - im.Value(InfluenceEnemy, position)
+}
+
+// Safe reports whether the enemy cannot strike a position, as far as we know
+func (im *InfluenceMap) Safe(position Vector3) bool {
+	return im.Value(InfluenceDanger, position) < influenceSafeDanger
+}
+
+// SafestPoint returns the center of the cell within reach, in tiles, of a
+// position where the enemy is least able to strike, the nearest among equals
+func (im *InfluenceMap) SafestPoint(from Vector3, reach float64) Vector3 {
+	if im.width == 0 {
+		return from
+	}
+	origin := im.cellOf(from)
+	cells := int(reach / influenceCellSize)
+	best, bestDanger, bestSteps := origin, im.cellValue(InfluenceDanger, origin), 0
+	for dy := -cells; dy <= cells; dy++ {
+		for dx := -cells; dx <= cells; dx++ {
+			cell := Vector2i{X: origin.X + dx, Y: origin.Y + dy}
+			if cell.X < 0 || cell.Y < 0 || cell.X >= im.width || cell.Y >= im.height || dx*dx+dy*dy > cells*cells {
+				continue
+			}
+			danger, steps := im.cellValue(InfluenceDanger, cell), dx*dx+dy*dy
+			if danger < bestDanger || danger == bestDanger && steps < bestSteps {
+				best, bestDanger, bestSteps = cell, danger, steps
+			}
+		}
+	}
+	if best == origin {
+		return from
+	}
+	point := im.cellCenter(best)
+	point.Y = from.Y
+	return point
+}
+
+// StagingPoint returns where an army gathers before moving on a target: the
+// center of the safe cell we hold nearest the target. It is false when we
+// hold no safe ground.
+func (im *InfluenceMap) StagingPoint(target Vector3) (Vector3, bool) {
+	if im.width == 0 {
+		return Vector3{}, false
+	}
+	goal := im.cellOf(target)
+	best, bestDistance := Vector2i{}, math.MaxInt
+	for y := 0; y < im.height; y++ {
+		for x := 0; x < im.width; x++ {
+			cell := Vector2i{X: x, Y: y}
+			friendly := im.cellValue(InfluenceFriendly, cell)
+			if friendly <= 0 || friendly < im.cellValue(InfluenceEnemy, cell) || im.cellValue(InfluenceDanger, cell) >= influenceSafeDanger {
+				continue
+			}
+			if distance := (x-goal.X)*(x-goal.X) + (y-goal.Y)*(y-goal.Y); distance < bestDistance {
+				best, bestDistance = cell, distance
+			}
+		}
+	}
+	if bestDistance == math.MaxInt {
+		return Vector3{}, false
+	}
+	return im.cellCenter(best), true
+}
+
+// SafeRoute returns the waypoints of a walk between two positions that
+// keeps out of danger where it can, through the centers of the cells where
+// it turns and ending at the destination. Cells whose middle tile is not
+// walkable ground are kept out of.
+func (im *InfluenceMap) SafeRoute(from, to Vector3) []Vector3 {
+	if im.width == 0 {
+		return []Vector3{to}
+	}
+	start, goal := im.cellOf(from), im.cellOf(to)
+	analysis := im.world.MapAnalysis()
+	passable := func(cell Vector2i) bool {
+		if cell == start || cell == goal {
+			return true
+		}
+		middle := Vector2i{X: cell.X*influenceCellSize + influenceCellSize/2, Y: cell.Y*influenceCellSize + influenceCellSize/2}
+		return analysis.RegionAt(middle) >= 0
+	}
+
+	index := func(cell Vector2i) int { return cell.Y*im.width + cell.X }
+	cost := make([]float64, im.width*im.height)
+	previous := make([]int, im.width*im.height)
+	for i := range cost {
+		cost[i], previous[i] = math.Inf(1), -1
+	}
+	cost[index(start)] = 0
+	open := &cellQueue{{cell: start}}
+	for open.Len() > 0 {
+		current := heap.Pop(open).(cellQueueItem)
+		if current.cell == goal {
+			break
+		}
+		if current.cost > cost[index(current.cell)] {
+			continue
+		}
+		for _, step := range neighborSteps {
+			next := Vector2i{X: current.cell.X + step.X, Y: current.cell.Y + step.Y}
+			if next.X < 0 || next.Y < 0 || next.X >= im.width || next.Y >= im.height || !passable(next) {
+				continue
+			}
+			length := 1.0
+			if step.X != 0 && step.Y != 0 {
+				length = math.Sqrt2
+			}
+			nextCost := current.cost + length*(1+influenceDangerCost*math.Max(0, im.cellValue(InfluenceDanger, next)))
+			if nextCost < cost[index(next)] {
+				cost[index(next)], previous[index(next)] = nextCost, index(current.cell)
+				heap.Push(open, cellQueueItem{cell: next, cost: nextCost})
+			}
+		}
+	}
+	if previous[index(goal)] < 0 {
+		return []Vector3{to}
+	}
+
+	var cells []Vector2i
+	for i := index(goal); i >= 0; i = previous[i] {
+		cells = append(cells, Vector2i{X: i % im.width, Y: i / im.width})
+	}
+	var route []Vector3
+	for i := len(cells) - 2; i > 0; i-- {
+		// cells runs from the goal back to the start; keep the turns
+		before, after := cells[i+1], cells[i-1]
+		if cells[i].X-before.X != after.X-cells[i].X || cells[i].Y-before.Y != after.Y-cells[i].Y {
+			point := im.cellCenter(cells[i])
+			point.Y = to.Y
+			route = append(route, point)
+		}
+	}
+	return append(route, to)
+}
+
+// cellQueueItem is a cell waiting in a search over cells or tiles
+type cellQueueItem struct {
+	cell Vector2i
+	cost float64
+}
+
+// cellQueue orders a search over cells or tiles by cost
+type cellQueue []cellQueueItem
+
+func (q cellQueue) Len() int            { return len(q) }
+func (q cellQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q cellQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *cellQueue) Push(x interface{}) { *q = append(*q, x.(cellQueueItem)) }
+func (q *cellQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestInfluenceMap tests that the influence map rates who holds the ground
+// around known forces, is restamped only where sources change, and routes
+// around the enemy's reach
+func TestInfluenceMap(t *testing.T) {
+	world, err := NewHeadlessWorld(64, 64)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	soldier, _ := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 14.5, Z: 20.5}, data.NewSimpleUnit("soldier", 100, 0, "leather", nil))
+	soldier.AttackDamage = 10
+	raider, _ := world.ObjectManager.CreateUnit(2, "raider", Vector3{X: 20.5, Z: 20.5}, data.NewSimpleUnit("raider", 100, 0, "leather", nil))
+	raider.AttackDamage = 20
+	raider.AttackRange = 1
+
+	ai := NewStrategicAI(1, world, BalancedPersonality, DifficultyNormal)
+	ai.GetIntel().Update()
+	if len(ai.GetIntel().Units()) != 1 {
+		t.Fatal("Expected the raider to be sighted")
+	}
+	soldier.Position = Vector3{X: 6.5, Z: 20.5}
+	influence := ai.GetInfluence()
+	influence.Update()
+
+	if influence.Balance(soldier.Position) <= 0 || influence.Balance(raider.Position) >= 0 {
+		t.Errorf("Expected us to hold the ground around the soldier and the raider around it, got %.1f and %.1f",
+			influence.Balance(soldier.Position), influence.Balance(raider.Position))
+	}
+	if !influence.Safe(soldier.Position) || influence.Safe(raider.Position) {
+		t.Error("Expected danger around the raider only")
+	}
+	if influence.Value(InfluenceEconomy, soldier.Position) != 0 {
+		t.Error("Expected no economic value without workers, buildings or resources")
+	}
+
+	// Routes and retreats keep out of the raider's reach
+	from, to := Vector3{X: 20.5, Z: 10.5}, Vector3{X: 20.5, Z: 30.5}
+	route := influence.SafeRoute(from, to)
+	if len(route) < 2 || route[len(route)-1] != to {
+		t.Fatalf("Expected a route turning around the raider, got %+v", route)
+	}
+	for _, waypoint := range route {
+		if !influence.Safe(waypoint) {
+			t.Errorf("Expected the route to keep out of danger, got %+v", route)
+			break
+		}
+	}
+	if point := influence.SafestPoint(raider.Position, retreatDistance); !influence.Safe(point) {
+		t.Errorf("Expected a safe point near the raider, got %v", point)
+	}
+	staging, ok := influence.StagingPoint(raider.Position)
+	if !ok || !influence.Safe(staging) || world.CalculateDistance(staging, raider.Position) >= world.CalculateDistance(soldier.Position, raider.Position) {
+		t.Errorf("Expected to stage between the soldier and the raider, got %v", staging)
+	}
+
+	// Only sources that change are restamped
+	influence.Update()
+	if influence.restamped != 0 {
+		t.Errorf("Expected nothing restamped while nothing moved, got %d", influence.restamped)
+	}
+	soldier.Position.X += 0.4
+	influence.Update()
+	if influence.restamped != 0 {
+		t.Errorf("Expected nothing restamped for a move within a cell, got %d", influence.restamped)
+	}
+	old := soldier.Position
+	soldier.Position = Vector3{X: 50.5, Z: 50.5}
+	influence.Update()
+	if influence.restamped != 1 || influence.Value(InfluenceFriendly, old) != 0 || influence.Value(InfluenceFriendly, soldier.Position) <= 0 {
+		t.Errorf("Expected the soldier's strength to move with it, restamped %d", influence.restamped)
+	}
+}
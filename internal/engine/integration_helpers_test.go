@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// integrationStart is the fixed start time of every integration test clock
+var integrationStart = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// fixtureFactionXML gives each player two soldiers and some resources
+const fixtureFactionXML = `<?xml version="1.0" standalone="no"?>
+<faction>
+	<starting-resources>
+		<resource name="gold" amount="500"/>
+		<resource name="wood" amount="300"/>
+	</starting-resources>
+	<starting-units>
+		<unit name="soldier" amount="2"/>
+	</starting-units>
+</faction>
+`
+
+// fixtureBarracksXML is a minimal production building
+const fixtureBarracksXML = `<?xml version="1.0" standalone="no"?>
+<unit>
+	<parameters>
+		<size value="2"/>
+		<max-hp value="1000" regeneration="0"/>
+		<armor value="10"/>
+		<armor-type value="stone"/>
+		<sight value="8"/>
+		<time value="60"/>
+		<resource-requirements>
+			<resource name="wood" amount="150"/>
+		</resource-requirements>
+	</parameters>
+	<skills/>
+	<commands/>
+</unit>
+`
+
+// writeFixtureTechTree writes a tiny tech tree with one faction ("testers")
+// to a temporary directory and returns its root
+func writeFixtureTechTree(t *testing.T) string {
+	t.Helper()
+
+	root := filepath.Join(t.TempDir(), "fixture")
+	files := map[string]string{
+		"fixture.xml":                                  `<tech-tree><description value="fixture"/></tech-tree>`,
+		"factions/testers/testers.xml":                 fixtureFactionXML,
+		"factions/testers/units/soldier/soldier.xml":   benchmarkUnitXML,
+		"factions/testers/units/barracks/barracks.xml": fixtureBarracksXML,
+	}
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create fixture directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write fixture %s: %v", rel, err)
+		}
+	}
+	return root
+}
+
+// newIntegrationWorld creates an initialized two-player world backed by the
+// fixture tech tree and driven by a manual clock
+func newIntegrationWorld(t *testing.T) (*World, *ManualClock) {
+	t.Helper()
+
+	settings := GameSettings{
+		MaxPlayers:         2,
+		GameSpeed:          1.0,
+		ResourceMultiplier: 1.0,
+		PlayerFactions:     map[int]string{1: "testers", 2: "testers"},
+	}
+	world, err := NewWorld(settings, &data.TechTree{}, data.NewAssetManager(writeFixtureTechTree(t)))
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+
+	clock := NewManualClock(integrationStart)
+	world.SetClock(clock)
+	if err := world.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize world: %v", err)
+	}
+	return world, clock
+}
+
+// stepWorld runs count updates of step, advancing the clock alongside game time
+func stepWorld(world *World, clock *ManualClock, step time.Duration, count int) {
+	for i := 0; i < count; i++ {
+		clock.Advance(step)
+		world.Update(step)
+	}
+}
+
+// firstUnit returns the player's unit with the lowest ID
+func firstUnit(t *testing.T, world *World, playerID int) *GameUnit {
+	t.Helper()
+
+	var first *GameUnit
+	for _, unit := range world.ObjectManager.GetUnitsForPlayer(playerID) {
+		if first == nil || unit.ID < first.ID {
+			first = unit
+		}
+	}
+	if first == nil {
+		t.Fatalf("Player %d has no units", playerID)
+	}
+	return first
+}
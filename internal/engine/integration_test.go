@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestManualClock tests that the manual clock only moves when advanced
+func TestManualClock(t *testing.T) {
+	clock := NewManualClock(integrationStart)
+	if !clock.Now().Equal(integrationStart) {
+		t.Fatalf("Expected clock to start at %v, got %v", integrationStart, clock.Now())
+	}
+
+	clock.Advance(1500 * time.Millisecond)
+	if got := clock.Now().Sub(integrationStart); got != 1500*time.Millisecond {
+		t.Errorf("Expected clock to advance 1.5s, advanced %v", got)
+	}
+
+	world, err := NewHeadlessWorld(8, 8)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.SetClock(clock)
+	if !world.now().Equal(clock.Now()) {
+		t.Error("World should read time from its clock")
+	}
+}
+
+// TestIntegrationWorldStartingState tests players and starting units loaded from the fixture tech tree
+func TestIntegrationWorldStartingState(t *testing.T) {
+	world, _ := newIntegrationWorld(t)
+
+	for _, playerID := range []int{1, 2} {
+		player := world.GetPlayer(playerID)
+		if player == nil {
+			t.Fatalf("Player %d not created", playerID)
+		}
+		if player.FactionData == nil {
+			t.Errorf("Player %d has no faction data", playerID)
+		}
+		if player.Resources["gold"] != 500 || player.Resources["wood"] != 300 {
+			t.Errorf("Player %d has wrong starting resources: %v", playerID, player.Resources)
+		}
+
+		units := world.ObjectManager.GetUnitsForPlayer(playerID)
+		if len(units) != 2 {
+			t.Fatalf("Expected 2 starting units for player %d, got %d", playerID, len(units))
+		}
+		for _, unit := range units {
+			if unit.UnitType != "soldier" || unit.MaxHealth != 400 {
+				t.Errorf("Starting unit should be a 400 HP soldier, got %s with %d HP", unit.UnitType, unit.MaxHealth)
+			}
+		}
+	}
+}
+
+// TestIntegrationMoveCommand tests that a move command issued through the
+// CommandProcessor is carried out by world updates
+func TestIntegrationMoveCommand(t *testing.T) {
+	world, clock := newIntegrationWorld(t)
+
+	unit := firstUnit(t, world, 1)
+	start := unit.Position
+	target := Vector3{X: start.X + 6, Y: 0, Z: start.Z + 6}
+
+	if err := world.commandProcessor.IssueCommand(unit.ID, CreateMoveCommand(target, false)); err != nil {
+		t.Fatalf("Failed to issue move command: %v", err)
+	}
+	if unit.CurrentCommand == nil && len(unit.CommandQueue) == 0 {
+		t.Fatal("Move command should be active or queued")
+	}
+
+	stepWorld(world, clock, 50*time.Millisecond, 400)
+
+	if calculateDistance(unit.Position, target) >= calculateDistance(start, target) {
+		t.Errorf("Unit did not approach target: start %v, now %v, target %v", start, unit.Position, target)
+	}
+	if calculateDistance(unit.Position, target) > 1.5 {
+		t.Errorf("Unit should reach the target after 20s of game time, still at %v", unit.Position)
+	}
+}
+
+// TestIntegrationPathfinding tests that a path is found around a wall on the fixture world
+func TestIntegrationPathfinding(t *testing.T) {
+	world, _ := newIntegrationWorld(t)
+
+	for y := 0; y < 20; y++ {
+		world.SetWalkable(Vector2i{X: 30, Y: y}, false)
+	}
+
+	result := NewPathfinder(world).FindPath(PathRequest{
+		Start:    GridPosition{Grid: Vector2i{X: 25, Y: 5}},
+		Target:   GridPosition{Grid: Vector2i{X: 35, Y: 5}},
+		UnitSize: 1,
+	})
+	if !result.Success || result.Partial {
+		t.Fatal("Expected a path around the wall")
+	}
+	for _, node := range result.GridPath {
+		if node.Grid.X == 30 && node.Grid.Y < 20 {
+			t.Fatalf("Path crosses the wall at %v", node)
+		}
+	}
+}
+
+// TestIntegrationProductionUsesClock tests that production completes only once
+// the world clock passes the production duration
+func TestIntegrationProductionUsesClock(t *testing.T) {
+	world, clock := newIntegrationWorld(t)
+
+	barracksDef, err := world.assetMgr.LoadUnit("testers", "barracks")
+	if err != nil {
+		t.Fatalf("Failed to load barracks definition: %v", err)
+	}
+	building, err := world.ObjectManager.CreateBuilding(1, "barracks", Vector3{X: 30, Y: 0, Z: 30}, barracksDef)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	building.IsBuilt = true
+
+	err = world.productionSys.IssueProductionCommand(building.ID, "soldier", map[string]int{"gold": 100}, 10*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to issue production command: %v", err)
+	}
+	if gold := world.GetPlayer(1).Resources["gold"]; gold != 400 {
+		t.Errorf("Expected production cost to be deducted leaving 400 gold, got %d", gold)
+	}
+
+	initialUnits := len(world.ObjectManager.GetUnitsForPlayer(1))
+
+	stepWorld(world, clock, time.Second, 5)
+	if got := len(world.ObjectManager.GetUnitsForPlayer(1)); got != initialUnits {
+		t.Fatalf("Production should not complete halfway, unit count changed to %d", got)
+	}
+
+	stepWorld(world, clock, time.Second, 6)
+	units := world.ObjectManager.GetUnitsForPlayer(1)
+	if len(units) != initialUnits+1 {
+		t.Fatalf("Expected %d units after production, got %d", initialUnits+1, len(units))
+	}
+
+	var produced *GameUnit
+	for _, unit := range units {
+		if produced == nil || unit.ID > produced.ID {
+			produced = unit
+		}
+	}
+	if produced.MaxHealth != 400 {
+		t.Errorf("Produced unit should use the fixture definition (400 HP), got %d", produced.MaxHealth)
+	}
+}
+
+// TestIntegrationCombatCooldown tests that attack cooldowns follow the world clock
+func TestIntegrationCombatCooldown(t *testing.T) {
+	world, clock := newIntegrationWorld(t)
+
+	attacker := firstUnit(t, world, 1)
+	target := firstUnit(t, world, 2)
+	target.Position = Vector3{X: attacker.Position.X + 1, Y: 0, Z: attacker.Position.Z}
+	attacker.AttackSpeed = 1.0
+
+	combat := NewCombatSystem(world)
+	if result := combat.ExecuteAttack(attacker, target); !result.CanAttack {
+		t.Fatalf("First attack failed: %s", result.ErrorMessage)
+	}
+	health := target.Health
+	if health >= target.MaxHealth {
+		t.Fatal("First attack should damage the target")
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if ok, reason := combat.CanAttack(attacker, target); ok || reason != "attack on cooldown" {
+		t.Errorf("Attack should be on cooldown half a second later, got ok=%v reason=%q", ok, reason)
+	}
+
+	clock.Advance(600 * time.Millisecond)
+	if ok, reason := combat.CanAttack(attacker, target); !ok {
+		t.Errorf("Attack should be ready after the cooldown: %s", reason)
+	}
+}
+
+// TestIntegrationMemoryAssets tests a world built from programmatically defined factions and units
+func TestIntegrationMemoryAssets(t *testing.T) {
+	assets := data.NewMemoryAssetProvider()
+	assets.AddFaction("testers", data.Faction{
+		StartingResources: []data.StartingResource{{Name: "gold", Amount: 200}},
+		StartingUnits:     []data.StartingUnit{{Name: "scout", Amount: 1}},
+	})
+	assets.AddUnit("testers", data.NewSimpleUnit("scout", 150, 1, "leather", nil))
+	assets.AddUnit("testers", data.NewSimpleUnit("barracks", 1000, 10, "stone", nil))
+	assets.AddUnit("testers", data.NewSimpleUnit("knight", 600, 8, "metal", map[string]int{"gold": 150}))
+
+	settings := GameSettings{MaxPlayers: 1, ResourceMultiplier: 1.0, PlayerFactions: map[int]string{1: "testers"}}
+	world, err := NewWorld(settings, &data.TechTree{}, assets)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(integrationStart)
+	world.SetClock(clock)
+	if err := world.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize world: %v", err)
+	}
+
+	scout := firstUnit(t, world, 1)
+	if scout.UnitType != "scout" || scout.MaxHealth != 150 {
+		t.Fatalf("Expected a 150 HP scout, got %s with %d HP", scout.UnitType, scout.MaxHealth)
+	}
+
+	barracksDef, _ := assets.LoadUnit("testers", "barracks")
+	building, err := world.ObjectManager.CreateBuilding(1, "barracks", Vector3{X: 20, Y: 0, Z: 20}, barracksDef)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	building.IsBuilt = true
+
+	if err := world.productionSys.IssueProductionCommand(building.ID, "knight", map[string]int{"gold": 150}, 5*time.Second); err != nil {
+		t.Fatalf("Failed to issue production command: %v", err)
+	}
+	stepWorld(world, clock, time.Second, 7)
+
+	var knight *GameUnit
+	for _, unit := range world.ObjectManager.GetUnitsForPlayer(1) {
+		if unit.UnitType == "knight" {
+			knight = unit
+		}
+	}
+	if knight == nil {
+		t.Fatal("Knight was not produced")
+	}
+	if knight.MaxHealth != 600 {
+		t.Errorf("Knight should use its in-memory definition (600 HP), got %d", knight.MaxHealth)
+	}
+}
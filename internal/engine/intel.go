@@ -0,0 +1,351 @@
+package engine
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// AI intelligence
+const (
+	intelUnitMemory     = 60 * time.Second // Game time after which an enemy unit out of sight is forgotten
+	intelBuildingMemory = 5 * time.Minute  // Game time after which an enemy building out of sight is forgotten
+	intelResourceMemory = 10 * time.Minute // Game time after which a resource node out of sight is forgotten
+	intelSectorSize     = 8                // Tiles per side of the sectors scouting is planned in
+	armyClusterRange    = 6.0              // Tiles within which sighted armed units count as one army
+	intelThreatRange    = 15.0             // Tiles from our buildings within which sighted armed units threaten the base
+)
+
+// SightedUnit is what an AI remembers of an enemy unit
+type SightedUnit struct {
+	ID       int
+	PlayerID int
+	UnitType string
+	Position Vector3       // Where the unit was last seen
+	Armed    bool          // Whether the unit could attack
+	Strength float64       // Fighting strength at the sighting, as unitStrength rates it
+	Reach    float64       // Attack range
+	LastSeen time.Duration // Game time of the last sighting
+}
+
+// SightedBuilding is what an AI remembers of an enemy building
+type SightedBuilding struct {
+	Building     *GameBuilding // The building seen, for the figures its type reveals
+	ID           int
+	PlayerID     int
+	BuildingType string
+	Position     Vector3
+	Defensive    bool
+	LastSeen     time.Duration
+}
+
+// SightedResource is what an AI remembers of a resource node
+type SightedResource struct {
+	ID           int
+	ResourceType string
+	Position     Vector3
+	Amount       int // Amount left at the last sighting
+	LastSeen     time.Duration
+}
+
+// ArmySighting is the last known position of a group of armed enemy units
+type ArmySighting struct {
+	PlayerID int
+	Position Vector3 // Center of the group
+	Units    int
+	LastSeen time.Duration // Game time the latest unit of the group was seen
+}
+
+// IntelMemory is an AI player's picture of the map: what its units and
+// buildings have seen, and when. Sightings fade as they age and are
+// dropped once their place is in sight again without them.
+type IntelMemory struct {
+	playerID  int
+	world     *World
+	units     map[int]*SightedUnit
+	buildings map[int]*SightedBuilding
+	resources map[int]*SightedResource
+	scouted   map[Vector2i]time.Duration // Game time each sector was last in sight
+}
+
+// NewIntelMemory creates an empty memory for a player
+func NewIntelMemory(playerID int, world *World) *IntelMemory {
+	return &IntelMemory{
+		playerID:  playerID,
+		world:     world,
+		units:     make(map[int]*SightedUnit),
+		buildings: make(map[int]*SightedBuilding),
+		resources: make(map[int]*SightedResource),
+		scouted:   make(map[Vector2i]time.Duration),
+	}
+}
+
+// CanSee reports whether a position is within sight of one of the player's
+// units, which see further from high ground, or buildings, or of its allies'
+// when the team shares vision
+func (w *World) CanSee(playerID int, position Vector3) bool {
+	tileSize := float64(w.GetTileSize())
+	for _, sourceID := range w.visionSources(playerID) {
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(sourceID) {
+			if unit.IsAlive() && w.CalculateDistance(unit.Position, position) <= w.SightRange(unit)*tileSize {
+				return true
+			}
+		}
+		for _, building := range w.ObjectManager.GetBuildingsForPlayer(sourceID) {
+			if w.CalculateDistance(building.Position, position) <= BuildingSightRange(building)*tileSize {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VisibleTiles returns which tiles' centers the player sees, as CanSee
+// judges them, in rows of Width tiles. It stamps the sight of each unit and
+// building rather than asking CanSee tile by tile, so a whole map costs about
+// as much as the tiles in sight.
+func (w *World) VisibleTiles(playerID int) []bool {
+	visible := make([]bool, w.Width*w.Height)
+	tileSize := float64(w.GetTileSize())
+	stamp := func(position Vector3, sight float64) {
+		reach := sight * tileSize
+		minX, maxX := int(math.Floor((position.X-reach)/tileSize)), int(math.Floor((position.X+reach)/tileSize))
+		minY, maxY := int(math.Floor((position.Z-reach)/tileSize)), int(math.Floor((position.Z+reach)/tileSize))
+		if minX < 0 {
+			minX = 0
+		}
+		if minY < 0 {
+			minY = 0
+		}
+		if maxX >= w.Width {
+			maxX = w.Width - 1
+		}
+		if maxY >= w.Height {
+			maxY = w.Height - 1
+		}
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				center := Vector3{X: (float64(x) + 0.5) * tileSize, Y: position.Y, Z: (float64(y) + 0.5) * tileSize}
+				if !visible[y*w.Width+x] && w.CalculateDistance(position, center) <= reach {
+					visible[y*w.Width+x] = true
+				}
+			}
+		}
+	}
+	for _, sourceID := range w.visionSources(playerID) {
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(sourceID) {
+			if unit.IsAlive() {
+				stamp(unit.GetPosition(), w.SightRange(unit))
+			}
+		}
+		for _, building := range w.ObjectManager.GetBuildingsForPlayer(sourceID) {
+			stamp(building.GetPosition(), BuildingSightRange(building))
+		}
+	}
+	return visible
+}
+
+// BuildingSightRange returns the sight in tiles of a building
+func BuildingSightRange(building *GameBuilding) float64 {
+	if building.UnitDef == nil || building.UnitDef.Unit.Parameters.Sight.Value <= 0 {
+		return DefaultSightRange
+	}
+	return float64(building.UnitDef.Unit.Parameters.Sight.Value)
+}
+
+// Update records what the player sees now and forgets what has gone stale
+// or is no longer where it was seen
+func (im *IntelMemory) Update() {
+	w := im.world
+	now := w.GetGameTime()
+	seen := func(position Vector3) bool { return w.CanSee(im.playerID, position) }
+
+	for _, sector := range im.sectors() {
+		if seen(im.sectorCenter(sector)) {
+			im.scouted[sector] = now
+		}
+	}
+
+	sightedUnits := make(map[int]bool)
+	sightedBuildings := make(map[int]bool)
+	for id := range w.GetAllPlayers() {
+		if id == im.playerID || w.AreAllied(im.playerID, id) {
+			continue
+		}
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(id) {
+			if !unit.IsAlive() || unit.GarrisonedIn != 0 || !seen(unit.Position) {
+				continue
+			}
+			sightedUnits[unit.ID] = true
+			im.units[unit.ID] = &SightedUnit{
+				ID:       unit.ID,
+				PlayerID: id,
+				UnitType: unit.UnitType,
+				Position: unit.Position,
+				Armed:    unit.AttackDamage > 0,
+				Strength: unitStrength(unit),
+				Reach:    float64(unit.AttackRange),
+				LastSeen: now,
+			}
+		}
+		for _, building := range w.ObjectManager.GetBuildingsForPlayer(id) {
+			if !building.IsAlive() || !seen(building.Position) {
+				continue
+			}
+			sightedBuildings[building.ID] = true
+			im.buildings[building.ID] = &SightedBuilding{
+				Building:     building,
+				ID:           building.ID,
+				PlayerID:     id,
+				BuildingType: building.BuildingType,
+				Position:     building.Position,
+				Defensive:    building.IsDefensive(),
+				LastSeen:     now,
+			}
+		}
+	}
+
+	sightedResources := make(map[int]bool)
+	for id, node := range w.GetResources() {
+		if node.Amount > 0 && seen(node.Position) {
+			sightedResources[id] = true
+			im.resources[id] = &SightedResource{
+				ID:           id,
+				ResourceType: node.ResourceType,
+				Position:     node.Position,
+				Amount:       node.Amount,
+				LastSeen:     now,
+			}
+		}
+	}
+
+	// Forget what aged out, and what is missing from where it was seen
+	for id, unit := range im.units {
+		if !sightedUnits[id] && (now-unit.LastSeen > intelUnitMemory || seen(unit.Position)) {
+			delete(im.units, id)
+		}
+	}
+	for id, building := range im.buildings {
+		if !sightedBuildings[id] && (now-building.LastSeen > intelBuildingMemory || seen(building.Position)) {
+			delete(im.buildings, id)
+		}
+	}
+	for id, node := range im.resources {
+		if !sightedResources[id] && (now-node.LastSeen > intelResourceMemory || seen(node.Position)) {
+			delete(im.resources, id)
+		}
+	}
+}
+
+// Units returns the remembered enemy units, sorted by ID
+func (im *IntelMemory) Units() []SightedUnit {
+	units := make([]SightedUnit, 0, len(im.units))
+	for _, unit := range im.units {
+		units = append(units, *unit)
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+	return units
+}
+
+// Buildings returns the remembered enemy buildings, sorted by ID
+func (im *IntelMemory) Buildings() []SightedBuilding {
+	buildings := make([]SightedBuilding, 0, len(im.buildings))
+	for _, building := range im.buildings {
+		buildings = append(buildings, *building)
+	}
+	sort.Slice(buildings, func(i, j int) bool { return buildings[i].ID < buildings[j].ID })
+	return buildings
+}
+
+// Resources returns the remembered resource nodes, sorted by ID
+func (im *IntelMemory) Resources() []SightedResource {
+	resources := make([]SightedResource, 0, len(im.resources))
+	for _, node := range im.resources {
+		resources = append(resources, *node)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].ID < resources[j].ID })
+	return resources
+}
+
+// Armies groups the remembered armed enemy units of each player into the
+// last known positions of their armies
+func (im *IntelMemory) Armies() []ArmySighting {
+	reach := armyClusterRange * float64(im.world.GetTileSize())
+	var armies []ArmySighting
+	for _, unit := range im.Units() {
+		if !unit.Armed {
+			continue
+		}
+		joined := false
+		for i := range armies {
+			army := &armies[i]
+			if army.PlayerID != unit.PlayerID || im.world.CalculateDistance(army.Position, unit.Position) > reach {
+				continue
+			}
+			count := float64(army.Units)
+			army.Position.X = (army.Position.X*count + unit.Position.X) / (count + 1)
+			army.Position.Z = (army.Position.Z*count + unit.Position.Z) / (count + 1)
+			army.Units++
+			if unit.LastSeen > army.LastSeen {
+				army.LastSeen = unit.LastSeen
+			}
+			joined = true
+			break
+		}
+		if !joined {
+			armies = append(armies, ArmySighting{PlayerID: unit.PlayerID, Position: unit.Position, Units: 1, LastSeen: unit.LastSeen})
+		}
+	}
+	return armies
+}
+
+// Confidence rates from 1.0 down to 0.0 how much a sighting from the given
+// game time is still worth, over the memory span of its kind
+func (im *IntelMemory) Confidence(lastSeen, memory time.Duration) float64 {
+	if memory <= 0 {
+		return 0
+	}
+	age := im.world.GetGameTime() - lastSeen
+	return math.Max(0, 1-float64(age)/float64(memory))
+}
+
+// ScoutTarget returns the center of the sector that has gone longest
+// without being seen, the nearest to a position among equals. It is false
+// when the map is too small to have sectors.
+func (im *IntelMemory) ScoutTarget(from Vector3) (Vector3, bool) {
+	var target Vector3
+	found := false
+	var oldest time.Duration
+	best := math.MaxFloat64
+	for _, sector := range im.sectors() {
+		center := im.sectorCenter(sector)
+		lastSeen, known := im.scouted[sector]
+		if !known {
+			lastSeen = -1
+		}
+		distance := im.world.CalculateDistance(from, center)
+		if !found || lastSeen < oldest || lastSeen == oldest && distance < best {
+			target, oldest, best, found = center, lastSeen, distance, true
+		}
+	}
+	return target, found
+}
+
+// sectors returns the sectors the map is divided into for scouting
+func (im *IntelMemory) sectors() []Vector2i {
+	var sectors []Vector2i
+	for y := 0; y < im.world.Height/intelSectorSize; y++ {
+		for x := 0; x < im.world.Width/intelSectorSize; x++ {
+			sectors = append(sectors, Vector2i{X: x, Y: y})
+		}
+	}
+	return sectors
+}
+
+// sectorCenter returns the world position at the middle of a sector
+func (im *IntelMemory) sectorCenter(sector Vector2i) Vector3 {
+	return im.world.GridToWorld(GridPosition{Grid: Vector2i{
+		X: sector.X*intelSectorSize + intelSectorSize/2,
+		Y: sector.Y*intelSectorSize + intelSectorSize/2,
+	}})
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestAIIntel tests that the AI knows only what its units have seen, keeps
+// sightings out of sight until they age out, drops them once it sees they
+// are gone, and scouts the parts of the map it has not seen
+func TestAIIntel(t *testing.T) {
+	world, err := NewHeadlessWorld(64, 64)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	scout, _ := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 5.5, Z: 5.5}, data.NewSimpleUnit("soldier", 100, 0, "leather", nil))
+	raider, _ := world.ObjectManager.CreateUnit(2, "raider", Vector3{X: 10.5, Z: 5.5}, data.NewSimpleUnit("raider", 100, 0, "leather", nil))
+	raider.AttackDamage = 10
+	castle, _ := world.ObjectManager.CreateBuilding(2, "castle", Vector3{X: 50.5, Z: 50.5}, data.NewSimpleUnit("castle", 2000, 0, "stone", nil))
+	if err := world.placeResourceNode(8, 8, "gold"); err != nil {
+		t.Fatalf("Failed to place resource: %v", err)
+	}
+
+	ai := NewStrategicAI(1, world, BalancedPersonality, DifficultyNormal)
+	intel := ai.GetIntel()
+	intel.Update()
+	if units := intel.Units(); len(units) != 1 || units[0].ID != raider.ID || !units[0].Armed {
+		t.Fatalf("Expected the raider in sight to be known, got %+v", units)
+	}
+	if len(intel.Buildings()) != 0 {
+		t.Error("Expected the castle out of sight to be unknown")
+	}
+	if resources := intel.Resources(); len(resources) != 1 || resources[0].ResourceType != "gold" {
+		t.Errorf("Expected the gold in sight to be known, got %+v", resources)
+	}
+	if armies := intel.Armies(); len(armies) != 1 || armies[0].PlayerID != 2 || armies[0].Units != 1 {
+		t.Errorf("Expected the raider as an army sighting, got %+v", armies)
+	}
+	if target, ok := intel.ScoutTarget(scout.Position); !ok || world.CanSee(1, target) {
+		t.Errorf("Expected to scout a sector out of sight, got %v", target)
+	}
+
+	// The scout finds the enemy base and leaves; the base is remembered
+	scout.Position = Vector3{X: 44.5, Z: 50.5}
+	world.gameTime = 10 * time.Second
+	intel.Update()
+	scout.Position = Vector3{X: 5.5, Z: 30.5}
+	world.gameTime = 20 * time.Second
+	intel.Update()
+	buildings := intel.Buildings()
+	if len(buildings) != 1 || buildings[0].ID != castle.ID || buildings[0].LastSeen != 10*time.Second {
+		t.Fatalf("Expected the castle to be remembered from its sighting, got %+v", buildings)
+	}
+	if confidence := intel.Confidence(buildings[0].LastSeen, intelBuildingMemory); confidence <= 0 || confidence >= 1 {
+		t.Errorf("Expected the sighting to have started to fade, got %.2f", confidence)
+	}
+	military := NewMilitaryManager(1, world, ai)
+	military.identifyMilitaryTargets()
+	targeted := false
+	for _, target := range military.militaryTargets {
+		targeted = targeted || target.Type == "enemy_base" && target.Location == castle.Position
+	}
+	if !targeted {
+		t.Errorf("Expected the remembered castle as a target, got %+v", military.militaryTargets)
+	}
+
+	// The raider was last seen near the start and is forgotten in time
+	if len(intel.Units()) != 1 {
+		t.Fatal("Expected the raider out of sight to be remembered")
+	}
+	world.gameTime += intelUnitMemory
+	intel.Update()
+	if len(intel.Units()) != 0 {
+		t.Error("Expected the raider to be forgotten after the memory span")
+	}
+
+	// A destroyed building is dropped once its place is seen again
+	castle.Health = 0
+	intel.Update()
+	if len(intel.Buildings()) != 1 {
+		t.Fatal("Expected the castle to be remembered until its place is seen")
+	}
+	scout.Position = Vector3{X: 44.5, Z: 50.5}
+	intel.Update()
+	if len(intel.Buildings()) != 0 {
+		t.Error("Expected the destroyed castle to be dropped once seen gone")
+	}
+}
+
+// TestVisibleTiles tests that the tiles a player sees are those whose
+// centers CanSee reports in sight
+func TestVisibleTiles(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 1.5, Z: 1.5}, data.NewSimpleUnit("soldier", 100, 0, "leather", nil))
+	world.ObjectManager.CreateBuilding(1, "tower", Vector3{X: 20.5, Z: 25.5}, data.NewSimpleUnit("tower", 500, 0, "stone", nil))
+
+	visible := world.VisibleTiles(1)
+	if len(visible) != world.Width*world.Height {
+		t.Fatalf("Expected %d tiles, got %d", world.Width*world.Height, len(visible))
+	}
+	tileSize := float64(world.GetTileSize())
+	seen := 0
+	for y := 0; y < world.Height; y++ {
+		for x := 0; x < world.Width; x++ {
+			center := Vector3{X: (float64(x) + 0.5) * tileSize, Z: (float64(y) + 0.5) * tileSize}
+			if visible[y*world.Width+x] != world.CanSee(1, center) {
+				t.Fatalf("Expected tile %d,%d to be visible only if CanSee sees it", x, y)
+			}
+			if visible[y*world.Width+x] {
+				seen++
+			}
+		}
+	}
+	if seen == 0 || seen == len(visible) {
+		t.Errorf("Expected part of the map in sight, got %d of %d tiles", seen, len(visible))
+	}
+	if len(world.VisibleTiles(2)) != len(visible) || world.VisibleTiles(2)[world.Width+1] {
+		t.Error("Expected the other player to see nothing")
+	}
+}
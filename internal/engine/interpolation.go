@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// unitTransform is where a unit stood and faced at the end of a tick
+type unitTransform struct {
+	Position Vector3
+	Rotation float32 // Radians around the vertical axis
+}
+
+// tickTransforms keeps the unit transforms of the last two ticks, so frames
+// drawn between ticks can place units part of the way from one to the other.
+// Tick times are wall time: they only pace drawing and never feed the simulation.
+type tickTransforms struct {
+	mutex    sync.RWMutex
+	previous map[int]unitTransform // By unit ID
+	current  map[int]unitTransform
+	tickAt   time.Time     // When the last tick ended
+	interval time.Duration // Time between the last two ticks
+}
+
+// recordTickTransforms stores the unit transforms at the end of a tick; units
+// new this tick start from where they are
+func (w *World) recordTickTransforms(now time.Time) {
+	current := make(map[int]unitTransform)
+	for _, player := range w.GetAllPlayers() {
+		for id, unit := range w.ObjectManager.GetUnitsForPlayer(player.ID) {
+			unit.mutex.RLock()
+			current[id] = unitTransform{Position: unit.Position, Rotation: unit.Rotation}
+			unit.mutex.RUnlock()
+		}
+	}
+
+	ticks := &w.transforms
+	ticks.mutex.Lock()
+	defer ticks.mutex.Unlock()
+	ticks.previous = ticks.current
+	ticks.current = current
+	if !ticks.tickAt.IsZero() {
+		ticks.interval = now.Sub(ticks.tickAt)
+	}
+	ticks.tickAt = now
+}
+
+// TickAlpha returns how far a frame drawn at now is from the previous tick
+// toward the last one, as a share of the time between them: 0 draws units
+// where they were a tick ago, 1 where they are now. Frames are drawn one tick
+// behind the simulation, so units move smoothly whatever the tick rate.
+func (w *World) TickAlpha(now time.Time) float32 {
+	ticks := &w.transforms
+	ticks.mutex.RLock()
+	defer ticks.mutex.RUnlock()
+	if ticks.interval <= 0 {
+		return 1
+	}
+	alpha := float64(now.Sub(ticks.tickAt)) / float64(ticks.interval)
+	return float32(math.Max(0, math.Min(1, alpha)))
+}
+
+// InterpolatedTransform returns where a unit is drawn a share alpha of the way
+// from the previous tick to the last one, and which way it faces. Units not
+// seen at both ticks are drawn where they are.
+func (w *World) InterpolatedTransform(unit *GameUnit, alpha float32) (Vector3, float32) {
+	ticks := &w.transforms
+	ticks.mutex.RLock()
+	from, seenBefore := ticks.previous[unit.ID]
+	to, seenNow := ticks.current[unit.ID]
+	ticks.mutex.RUnlock()
+	if !seenBefore || !seenNow {
+		unit.mutex.RLock()
+		defer unit.mutex.RUnlock()
+		return unit.Position, unit.Rotation
+	}
+
+	t := float64(alpha)
+	position := Vector3{
+		X: from.Position.X + (to.Position.X-from.Position.X)*t,
+		Y: from.Position.Y + (to.Position.Y-from.Position.Y)*t,
+		Z: from.Position.Z + (to.Position.Z-from.Position.Z)*t,
+	}
+	return position, LerpAngle(from.Rotation, to.Rotation, alpha)
+}
+
+// LerpAngle turns from one angle, in radians, toward another the short way round
+func LerpAngle(from, to, t float32) float32 {
+	delta := math.Remainder(float64(to-from), 2*math.Pi)
+	return from + float32(delta)*t
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestInterpolatedTransform tests that frames between ticks place units part
+// of the way from the previous tick to the last, turning the short way round
+func TestInterpolatedTransform(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	def := data.NewSimpleUnit("worker", 50, 0, "leather", nil)
+	unit, _ := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 4, Z: 4}, def)
+	unit.Rotation = 3
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	world.recordTickTransforms(start)
+	if alpha := world.TickAlpha(start); alpha != 1 {
+		t.Errorf("Expected frames before the second tick drawn at the last tick, got %.2f", alpha)
+	}
+	if position, _ := world.InterpolatedTransform(unit, 0.5); position != unit.Position {
+		t.Errorf("Expected a unit seen at one tick only drawn where it is, got %v", position)
+	}
+
+	// A 25 Hz tick moves the unit one tile east and turns it across -pi
+	unit.Position.X = 5
+	unit.Rotation = -3
+	world.recordTickTransforms(start.Add(40 * time.Millisecond))
+
+	alpha := world.TickAlpha(start.Add(60 * time.Millisecond))
+	if alpha != 0.5 {
+		t.Fatalf("Expected a frame half a tick after the last to be half way, got %.2f", alpha)
+	}
+	position, rotation := world.InterpolatedTransform(unit, alpha)
+	if position.X != 4.5 || position.Z != 4 {
+		t.Errorf("Expected the unit half way along its move, got %v", position)
+	}
+	if math.Abs(float64(rotation)-math.Pi) > 1e-5 {
+		t.Errorf("Expected the unit to face pi half way through the turn, got %.3f", rotation)
+	}
+	if alpha := world.TickAlpha(start.Add(time.Second)); alpha != 1 {
+		t.Errorf("Expected late frames to stop at the last tick, got %.2f", alpha)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"os"
 	"strings"
+
+	"teraglest/internal/logging"
 )
 
 // Constants from MegaGlest map format specification
@@ -421,24 +423,24 @@ func (m *Map) GetPlayerStartPosition(playerIndex int) (Vector2i, bool) {
 	return Vector2i{}, false
 }
 
-// PrintSummary prints a summary of the map information
+// PrintSummary logs a summary of the map information at debug level
 func (m *Map) PrintSummary() {
-	fmt.Printf("Map: %s\n", m.Title)
-	fmt.Printf("  Author: %s\n", m.Author)
-	fmt.Printf("  Description: %s\n", m.Description)
-	fmt.Printf("  Dimensions: %dx%d\n", m.Width, m.Height)
-	fmt.Printf("  Max Players: %d\n", m.MaxPlayers)
-	fmt.Printf("  Version: %d\n", int(m.Version))
-	fmt.Printf("  Tileset: %s\n", m.TilesetName)
-	fmt.Printf("  Water Level: %.1f\n", m.WaterLevel)
-	fmt.Printf("  Height Factor: %.1f\n", m.HeightFactor)
+	logging.Debugf(logging.CategoryData, "Map: %s", m.Title)
+	logging.Debugf(logging.CategoryData, "  Author: %s", m.Author)
+	logging.Debugf(logging.CategoryData, "  Description: %s", m.Description)
+	logging.Debugf(logging.CategoryData, "  Dimensions: %dx%d", m.Width, m.Height)
+	logging.Debugf(logging.CategoryData, "  Max Players: %d", m.MaxPlayers)
+	logging.Debugf(logging.CategoryData, "  Version: %d", int(m.Version))
+	logging.Debugf(logging.CategoryData, "  Tileset: %s", m.TilesetName)
+	logging.Debugf(logging.CategoryData, "  Water Level: %.1f", m.WaterLevel)
+	logging.Debugf(logging.CategoryData, "  Height Factor: %.1f", m.HeightFactor)
 	if m.Version == MapVersionMGM {
-		fmt.Printf("  Cliff Level: %.1f\n", m.CliffLevel)
-		fmt.Printf("  Camera Height: %.1f\n", m.CameraHeight)
+		logging.Debugf(logging.CategoryData, "  Cliff Level: %.1f", m.CliffLevel)
+		logging.Debugf(logging.CategoryData, "  Camera Height: %.1f", m.CameraHeight)
 	}
 
-	fmt.Printf("  Start Positions:\n")
+	logging.Debugf(logging.CategoryData, "  Start Positions:")
 	for i, pos := range m.StartPositions {
-		fmt.Printf("    Player %d: (%d, %d)\n", i+1, pos.X, pos.Y)
+		logging.Debugf(logging.CategoryData, "    Player %d: (%d, %d)", i+1, pos.X, pos.Y)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,558 @@
+package engine
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"teraglest/internal/logging"
+)
+
+// Map analysis
+const (
+	regionMinClearance     = 3   // Clearance of the most open tile below which an area is too small to be a region of its own
+	chokepointNarrowing    = 0.6 // Share of the smaller region's clearance below which a passage between two regions is a chokepoint
+	expansionClusterRadius = 6   // Tiles within which resource nodes belong to the same expansion
+	expansionSiteReach     = 8   // Tiles from the center of a resource cluster an expansion site may be
+	expansionSiteClearance = 3   // Clearance a site needs to fit a base, which is also kept from the resources
+)
+
+// neighborSteps are the eight tiles around a tile
+var neighborSteps = [8]Vector2i{{X: -1, Y: -1}, {X: 0, Y: -1}, {X: 1, Y: -1}, {X: -1, Y: 0}, {X: 1, Y: 0}, {X: -1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 1}}
+
+// MapAnalysis is the walkable terrain of a map divided into regions joined
+// at chokepoints, with the sites next to resource clusters a base can expand
+// to. It is computed once a match from the terrain before any building
+// stands and does not change after: pathfinding plans long walks through
+// its chokepoints, the AI picks defense and expansion sites from it, and mods
+// and the regions debug overlay read it.
+type MapAnalysis struct {
+	Width, Height int
+	Regions       []Region     // By ID
+	Chokepoints   []Chokepoint // By ID
+	Expansions    []ExpansionSite
+
+	regionOf  []int // Region of each tile, row by row, -1 where blocked
+	clearance []int // Tiles from each tile to the nearest blocked tile or map edge, 1 next to them and 0 where blocked
+}
+
+// Region is an open area of walkable tiles
+type Region struct {
+	ID          int
+	Island      int // Regions on the same island are connected by walking
+	Tiles       int
+	Center      Vector2i // Most open tile
+	Clearance   int      // Clearance at the center
+	Chokepoints []int    // IDs of the chokepoints leading out of the region
+}
+
+// Chokepoint is a narrow passage between two regions
+type Chokepoint struct {
+	ID      int
+	Center  Vector2i // Narrowest tile of the passage
+	Width   int      // Tiles across the passage
+	Regions [2]int   // IDs of the regions it joins
+}
+
+// ExpansionSite is an open place beside a cluster of resources with room for a base
+type ExpansionSite struct {
+	Position  Vector2i
+	Region    int
+	Resources []int // IDs of the resource nodes in the cluster
+	Amount    int   // Resources in the cluster when the map was analyzed
+}
+
+// AnalyzeMap divides the walkable tiles of a grid, indexed [y][x], into
+// regions and chokepoints and finds the expansion sites beside resources
+func AnalyzeMap(walkable [][]bool, resources []*ResourceNode, tileSize float32) *MapAnalysis {
+	a := &MapAnalysis{Height: len(walkable)}
+	if a.Height > 0 {
+		a.Width = len(walkable[0])
+	}
+	a.clearance = tileClearance(walkable, a.Width, a.Height)
+	a.regionOf = make([]int, a.Width*a.Height)
+	a.segment()
+	a.locateExpansions(resources, tileSize)
+	return a
+}
+
+// tileClearance returns how many steps, diagonals counting as one, each
+// walkable tile is from the nearest blocked tile or the map edge
+func tileClearance(walkable [][]bool, width, height int) []int {
+	clearance := make([]int, width*height)
+	var queue []int
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !walkable[y][x] {
+				continue
+			}
+			edge := x == 0 || y == 0 || x == width-1 || y == height-1
+			for _, step := range neighborSteps {
+				edge = edge || !walkable[y+step.Y][x+step.X]
+				if edge {
+					break
+				}
+			}
+			if edge {
+				clearance[y*width+x] = 1
+				queue = append(queue, y*width+x)
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		tile := queue[0]
+		queue = queue[1:]
+		x, y := tile%width, tile/width
+		for _, step := range neighborSteps {
+			nx, ny := x+step.X, y+step.Y
+			if nx < 0 || ny < 0 || nx >= width || ny >= height || !walkable[ny][nx] || clearance[ny*width+nx] != 0 {
+				continue
+			}
+			clearance[ny*width+nx] = clearance[tile] + 1
+			queue = append(queue, ny*width+nx)
+		}
+	}
+	return clearance
+}
+
+// basins is a union-find of the tiles flooded so far; each root keeps the
+// clearance and tile of its basin's most open tile
+type basins struct {
+	parent   []int // -1 for tiles not flooded yet
+	peak     []int
+	peakTile []int
+}
+
+// find returns the root of a flooded tile's basin
+func (b *basins) find(tile int) int {
+	for b.parent[tile] != tile {
+		b.parent[tile] = b.parent[b.parent[tile]]
+		tile = b.parent[tile]
+	}
+	return tile
+}
+
+// union merges two basins under the one with the more open peak
+func (b *basins) union(first, second int) int {
+	if b.peak[second] > b.peak[first] || (b.peak[second] == b.peak[first] && b.peakTile[second] < b.peakTile[first]) {
+		first, second = second, first
+	}
+	b.parent[second] = first
+	return first
+}
+
+// narrow reports whether two basins meeting at a clearance are both large
+// enough to be regions and the passage is much narrower than the smaller
+func (b *basins) narrow(first, second, level int) bool {
+	smaller := b.peak[first]
+	if b.peak[second] < smaller {
+		smaller = b.peak[second]
+	}
+	return smaller >= regionMinClearance && float64(level) < chokepointNarrowing*float64(smaller)
+}
+
+// basinContact is where two basins met without merging
+type basinContact struct {
+	tile, level int
+	first       int // Basin roots when they met
+	second      int
+}
+
+// segment floods the walkable tiles from the most open down: each tile joins
+// the basins of its flooded neighbors, and where two basins meet through a
+// passage much narrower than both they stay apart, the passage becoming a
+// chokepoint between their regions
+func (a *MapAnalysis) segment() {
+	var order []int
+	for tile := range a.regionOf {
+		a.regionOf[tile] = -1
+		if a.clearance[tile] > 0 {
+			order = append(order, tile)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return a.clearance[order[i]] > a.clearance[order[j]] })
+
+	flood := &basins{
+		parent:   make([]int, len(a.regionOf)),
+		peak:     make([]int, len(a.regionOf)),
+		peakTile: make([]int, len(a.regionOf)),
+	}
+	for tile := range flood.parent {
+		flood.parent[tile] = -1
+	}
+	var contacts []basinContact
+	for _, tile := range order {
+		level := a.clearance[tile]
+		x, y := tile%a.Width, tile/a.Width
+		// Join the basin of the most open neighbor first, so a tile beside a
+		// passage does not carry one basin along the far side of a wall
+		flooded := make([]int, 0, len(neighborSteps))
+		for _, step := range neighborSteps {
+			nx, ny := x+step.X, y+step.Y
+			if nx >= 0 && ny >= 0 && nx < a.Width && ny < a.Height && flood.parent[ny*a.Width+nx] >= 0 {
+				flooded = append(flooded, ny*a.Width+nx)
+			}
+		}
+		sort.SliceStable(flooded, func(i, j int) bool { return a.clearance[flooded[i]] > a.clearance[flooded[j]] })
+		own := -1
+		for _, neighbor := range flooded {
+			other := flood.find(neighbor)
+			switch {
+			case own < 0:
+				own = other
+				flood.parent[tile] = own
+			case other == own:
+			case flood.narrow(own, other, level):
+				contacts = append(contacts, basinContact{tile: tile, level: level, first: own, second: other})
+			default:
+				own = flood.union(own, other)
+			}
+		}
+		if own < 0 {
+			flood.parent[tile], flood.peak[tile], flood.peakTile[tile] = tile, level, tile
+		}
+	}
+
+	// Number the regions in the order their tiles come row by row
+	regionOfRoot := make(map[int]int)
+	for tile := range a.regionOf {
+		if a.clearance[tile] == 0 {
+			continue
+		}
+		root := flood.find(tile)
+		if _, numbered := regionOfRoot[root]; !numbered {
+			regionOfRoot[root] = len(a.Regions)
+			a.Regions = append(a.Regions, Region{
+				ID:        len(a.Regions),
+				Island:    -1,
+				Center:    Vector2i{X: flood.peakTile[root] % a.Width, Y: flood.peakTile[root] / a.Width},
+				Clearance: flood.peak[root],
+			})
+		}
+		a.regionOf[tile] = regionOfRoot[root]
+		a.Regions[a.regionOf[tile]].Tiles++
+	}
+
+	a.addChokepoints(contacts, flood, regionOfRoot)
+	a.markIslands()
+}
+
+// addChokepoints turns the places basins met without merging into
+// chokepoints; contacts near one already made between the same regions are
+// the same passage, met again lower down its sides
+func (a *MapAnalysis) addChokepoints(contacts []basinContact, flood *basins, regionOfRoot map[int]int) {
+	for _, contact := range contacts {
+		first, second := regionOfRoot[flood.find(contact.first)], regionOfRoot[flood.find(contact.second)]
+		if first == second {
+			continue
+		}
+		if first > second {
+			first, second = second, first
+		}
+		center := a.narrowestNear(Vector2i{X: contact.tile % a.Width, Y: contact.tile / a.Width})
+		known := false
+		for _, choke := range a.Chokepoints {
+			known = known || (choke.Regions == [2]int{first, second} && tileSteps(choke.Center, center) <= choke.Width+2)
+		}
+		if known {
+			continue
+		}
+		id := len(a.Chokepoints)
+		a.Chokepoints = append(a.Chokepoints, Chokepoint{ID: id, Center: center, Width: a.passageWidth(center), Regions: [2]int{first, second}})
+		a.Regions[first].Chokepoints = append(a.Regions[first].Chokepoints, id)
+		a.Regions[second].Chokepoints = append(a.Regions[second].Chokepoints, id)
+	}
+}
+
+// markIslands numbers the groups of regions joined by chokepoints
+func (a *MapAnalysis) markIslands() {
+	islands := 0
+	for start := range a.Regions {
+		if a.Regions[start].Island >= 0 {
+			continue
+		}
+		a.Regions[start].Island = islands
+		queue := []int{start}
+		for len(queue) > 0 {
+			region := queue[0]
+			queue = queue[1:]
+			for _, id := range a.Regions[region].Chokepoints {
+				for _, next := range a.Chokepoints[id].Regions {
+					if a.Regions[next].Island < 0 {
+						a.Regions[next].Island = islands
+						queue = append(queue, next)
+					}
+				}
+			}
+		}
+		islands++
+	}
+}
+
+// narrowestNear returns the tile within two steps of where basins met with
+// the narrowest passage, in its middle, as they may meet a little to one side
+func (a *MapAnalysis) narrowestNear(contact Vector2i) Vector2i {
+	best, bestWidth := contact, a.passageWidth(contact)
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			tile := Vector2i{X: contact.X + dx, Y: contact.Y + dy}
+			if a.clearanceAt(tile) == 0 {
+				continue
+			}
+			width, clearance := a.passageWidth(tile), a.clearanceAt(tile)
+			switch {
+			case width > bestWidth:
+				continue
+			case width == bestWidth && clearance < a.clearanceAt(best):
+				continue
+			case width == bestWidth && clearance == a.clearanceAt(best) && tileSteps(tile, contact) >= tileSteps(best, contact):
+				continue
+			}
+			best, bestWidth = tile, width
+		}
+	}
+	return best
+}
+
+// passageWidth returns the walkable tiles across a passage: the shorter of
+// the runs through a tile along the rows and the columns
+func (a *MapAnalysis) passageWidth(center Vector2i) int {
+	run := func(step Vector2i) int {
+		length := 1
+		for _, direction := range [2]int{-1, 1} {
+			tile := center
+			for {
+				tile = Vector2i{X: tile.X + direction*step.X, Y: tile.Y + direction*step.Y}
+				if a.clearanceAt(tile) == 0 {
+					break
+				}
+				length++
+			}
+		}
+		return length
+	}
+	across, down := run(Vector2i{X: 1}), run(Vector2i{Y: 1})
+	if down < across {
+		return down
+	}
+	return across
+}
+
+// locateExpansions groups the resource nodes into clusters and finds each
+// cluster an open site near its center clear of the resources
+func (a *MapAnalysis) locateExpansions(resources []*ResourceNode, tileSize float32) {
+	nodes := append([]*ResourceNode(nil), resources...)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	type cluster struct {
+		tiles  []Vector2i
+		site   ExpansionSite
+		center Vector2i
+	}
+	var clusters []*cluster
+	for _, node := range nodes {
+		tile := WorldToGrid(node.Position, tileSize).Grid
+		var joined *cluster
+		for _, candidate := range clusters {
+			for _, other := range candidate.tiles {
+				if tileSteps(tile, other) <= expansionClusterRadius {
+					joined = candidate
+					break
+				}
+			}
+			if joined != nil {
+				break
+			}
+		}
+		if joined == nil {
+			joined = &cluster{}
+			clusters = append(clusters, joined)
+		}
+		joined.tiles = append(joined.tiles, tile)
+		joined.center.X += tile.X
+		joined.center.Y += tile.Y
+		joined.site.Resources = append(joined.site.Resources, node.ID)
+		joined.site.Amount += node.Amount
+	}
+
+	for _, cluster := range clusters {
+		center := Vector2i{X: cluster.center.X / len(cluster.tiles), Y: cluster.center.Y / len(cluster.tiles)}
+		site, found := a.expansionSite(cluster.tiles, center)
+		if !found {
+			continue
+		}
+		cluster.site.Position, cluster.site.Region = site, a.RegionAt(site)
+		a.Expansions = append(a.Expansions, cluster.site)
+	}
+}
+
+// expansionSite returns the open tile nearest a cluster's center with room
+// for a base and clear of the cluster's resources
+func (a *MapAnalysis) expansionSite(resources []Vector2i, center Vector2i) (Vector2i, bool) {
+	best, bestDistance := Vector2i{}, -1
+	for y := center.Y - expansionSiteReach; y <= center.Y+expansionSiteReach; y++ {
+		for x := center.X - expansionSiteReach; x <= center.X+expansionSiteReach; x++ {
+			tile := Vector2i{X: x, Y: y}
+			if a.clearanceAt(tile) < expansionSiteClearance {
+				continue
+			}
+			clear := true
+			for _, resource := range resources {
+				clear = clear && tileSteps(tile, resource) >= expansionSiteClearance
+			}
+			dx, dy := x-center.X, y-center.Y
+			if clear && (bestDistance < 0 || dx*dx+dy*dy < bestDistance) {
+				best, bestDistance = tile, dx*dx+dy*dy
+			}
+		}
+	}
+	return best, bestDistance >= 0
+}
+
+// clearanceAt returns a tile's clearance, 0 if it is blocked or off the map
+func (a *MapAnalysis) clearanceAt(tile Vector2i) int {
+	if tile.X < 0 || tile.Y < 0 || tile.X >= a.Width || tile.Y >= a.Height {
+		return 0
+	}
+	return a.clearance[tile.Y*a.Width+tile.X]
+}
+
+// RegionAt returns the ID of a tile's region, -1 if it is blocked or off the map
+func (a *MapAnalysis) RegionAt(tile Vector2i) int {
+	if a.clearanceAt(tile) == 0 {
+		return -1
+	}
+	return a.regionOf[tile.Y*a.Width+tile.X]
+}
+
+// Connected reports whether a walk over open terrain leads between two tiles
+func (a *MapAnalysis) Connected(from, to Vector2i) bool {
+	first, second := a.RegionAt(from), a.RegionAt(to)
+	return first >= 0 && second >= 0 && a.Regions[first].Island == a.Regions[second].Island
+}
+
+// Separated reports whether two walkable tiles lie on islands no walk
+// connects; a blocked tile or one off the map is separated from nothing
+func (a *MapAnalysis) Separated(from, to Vector2i) bool {
+	first, second := a.RegionAt(from), a.RegionAt(to)
+	return first >= 0 && second >= 0 && a.Regions[first].Island != a.Regions[second].Island
+}
+
+// Route returns the chokepoints a walk from one tile to another passes, in
+// order, along the shortest line through their centers: none within a
+// region, and false when no walk connects the tiles
+func (a *MapAnalysis) Route(from, to Vector2i) ([]Chokepoint, bool) {
+	start, goal := a.RegionAt(from), a.RegionAt(to)
+	if !a.Connected(from, to) {
+		return nil, false
+	}
+	if start == goal {
+		return nil, true
+	}
+
+	distance := make([]float64, len(a.Chokepoints))
+	previous := make([]int, len(a.Chokepoints))
+	done := make([]bool, len(a.Chokepoints))
+	for id := range distance {
+		distance[id], previous[id] = math.Inf(1), -1
+	}
+	for _, id := range a.Regions[start].Chokepoints {
+		distance[id] = tileDistance(from, a.Chokepoints[id].Center)
+	}
+
+	last, shortest := -1, math.Inf(1)
+	for {
+		current := -1
+		for id := range distance {
+			if !done[id] && distance[id] < shortest && (current < 0 || distance[id] < distance[current]) {
+				current = id
+			}
+		}
+		if current < 0 {
+			break
+		}
+		done[current] = true
+
+		choke := a.Chokepoints[current]
+		if choke.Regions[0] == goal || choke.Regions[1] == goal {
+			if total := distance[current] + tileDistance(choke.Center, to); total < shortest {
+				last, shortest = current, total
+			}
+		}
+		for _, region := range choke.Regions {
+			for _, next := range a.Regions[region].Chokepoints {
+				if through := distance[current] + tileDistance(choke.Center, a.Chokepoints[next].Center); !done[next] && through < distance[next] {
+					distance[next], previous[next] = through, current
+				}
+			}
+		}
+	}
+	if last < 0 {
+		return nil, false
+	}
+
+	var route []Chokepoint
+	for id := last; id >= 0; id = previous[id] {
+		route = append(route, a.Chokepoints[id])
+	}
+	for i, j := 0, len(route)-1; i < j; i, j = i+1, j-1 {
+		route[i], route[j] = route[j], route[i]
+	}
+	return route, true
+}
+
+// tileSteps returns how many steps apart two tiles are, diagonals counting as one
+func tileSteps(a, b Vector2i) int {
+	dx, dy := absPath(a.X-b.X), absPath(a.Y-b.Y)
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+// tileDistance returns the straight line distance in tiles between two tiles
+func tileDistance(a, b Vector2i) float64 {
+	return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+}
+
+// mapAnalysisState holds the analysis of a world's map
+type mapAnalysisState struct {
+	mutex    sync.Mutex
+	analysis *MapAnalysis
+}
+
+// MapAnalysis returns the regions, chokepoints and expansion sites of the
+// map; a world never initialized analyzes its terrain as it is now
+func (w *World) MapAnalysis() *MapAnalysis {
+	w.mapAnalysis.mutex.Lock()
+	defer w.mapAnalysis.mutex.Unlock()
+	if w.mapAnalysis.analysis == nil {
+		w.mapAnalysis.analysis = AnalyzeMap(w.walkableSnapshot(), w.GetAllResourceNodes(), w.tileSize)
+	}
+	return w.mapAnalysis.analysis
+}
+
+// analyzeMap analyzes the terrain taken before the starting buildings went
+// up, so their footprints do not split regions, with the resources placed
+func (w *World) analyzeMap(terrain [][]bool) {
+	analysis := AnalyzeMap(terrain, w.GetAllResourceNodes(), w.tileSize)
+	logging.Infof(logging.CategoryEngine, "Map analysis: %d regions, %d chokepoints, %d expansion sites",
+		len(analysis.Regions), len(analysis.Chokepoints), len(analysis.Expansions))
+
+	w.mapAnalysis.mutex.Lock()
+	defer w.mapAnalysis.mutex.Unlock()
+	w.mapAnalysis.analysis = analysis
+}
+
+// walkableSnapshot returns a copy of which tiles' terrain is walkable
+func (w *World) walkableSnapshot() [][]bool {
+	w.gridMutex.RLock()
+	defer w.gridMutex.RUnlock()
+
+	snapshot := make([][]bool, len(w.walkableGrid))
+	for y, row := range w.walkableGrid {
+		snapshot[y] = append([]bool(nil), row...)
+	}
+	return snapshot
+}
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// twoRooms returns two 15x15 rooms side by side, split by a wall at x=15
+// with a gap three tiles wide at y=6..8 unless closed
+func twoRooms(closed bool) [][]bool {
+	walkable := make([][]bool, 15)
+	for y := range walkable {
+		walkable[y] = make([]bool, 31)
+		for x := range walkable[y] {
+			walkable[y][x] = x != 15 || !closed && y >= 6 && y <= 8
+		}
+	}
+	return walkable
+}
+
+// TestAnalyzeMap tests that a map is divided into regions at its narrow
+// passages, that routes run through the chokepoints between them and that
+// resources give expansion sites
+func TestAnalyzeMap(t *testing.T) {
+	west, east := Vector2i{X: 3, Y: 7}, Vector2i{X: 25, Y: 7}
+	resources := []*ResourceNode{
+		{ID: 1, ResourceType: "gold", Position: Vector3{X: 5.5, Z: 1.5}, Amount: 500},
+		{ID: 2, ResourceType: "gold", Position: Vector3{X: 6.5, Z: 1.5}, Amount: 300},
+	}
+	analysis := AnalyzeMap(twoRooms(false), resources, 1)
+	if len(analysis.Regions) != 2 || len(analysis.Chokepoints) != 1 {
+		t.Fatalf("Expected 2 regions and 1 chokepoint, got %d and %d", len(analysis.Regions), len(analysis.Chokepoints))
+	}
+	if analysis.RegionAt(west) == analysis.RegionAt(east) || analysis.RegionAt(Vector2i{X: 15, Y: 2}) != -1 {
+		t.Errorf("Expected the rooms in different regions and the wall in none")
+	}
+	choke := analysis.Chokepoints[0]
+	if choke.Center != (Vector2i{X: 15, Y: 7}) || choke.Width != 3 {
+		t.Errorf("Expected the chokepoint in the gap 3 tiles wide, got %+v", choke)
+	}
+	if route, ok := analysis.Route(west, east); !ok || len(route) != 1 || route[0].ID != choke.ID {
+		t.Errorf("Expected the route through the gap, got %+v", route)
+	}
+	if route, ok := analysis.Route(west, Vector2i{X: 10, Y: 10}); !ok || len(route) != 0 {
+		t.Errorf("Expected no chokepoints within a region, got %+v", route)
+	}
+
+	if len(analysis.Expansions) != 1 {
+		t.Fatalf("Expected 1 expansion site, got %+v", analysis.Expansions)
+	}
+	site := analysis.Expansions[0]
+	if site.Region != analysis.RegionAt(west) || site.Amount != 800 || len(site.Resources) != 2 {
+		t.Errorf("Expected the gold in the west room as one site, got %+v", site)
+	}
+	if analysis.clearanceAt(site.Position) < expansionSiteClearance {
+		t.Errorf("Expected the site on open ground, got %v", site.Position)
+	}
+
+	closed := AnalyzeMap(twoRooms(true), nil, 1)
+	if closed.Connected(west, east) || !closed.Separated(west, east) {
+		t.Error("Expected the walled off rooms to be separated")
+	}
+	if _, ok := closed.Route(west, east); ok {
+		t.Error("Expected no route between the walled off rooms")
+	}
+
+	ground := make([][]bool, 15)
+	for y := range ground {
+		ground[y] = make([]bool, 31)
+		for x := range ground[y] {
+			ground[y][x] = true
+		}
+	}
+	open := AnalyzeMap(ground, nil, 1)
+	if len(open.Regions) != 1 || len(open.Chokepoints) != 0 {
+		t.Errorf("Expected open ground as 1 region, got %d regions and %d chokepoints", len(open.Regions), len(open.Chokepoints))
+	}
+}
+
+// TestMapAnalysisWorld tests that paths are planned through chokepoints and
+// the AI holds the chokepoints leading out of its base
+func TestMapAnalysisWorld(t *testing.T) {
+	world, err := NewHeadlessWorld(31, 15)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	for y, row := range twoRooms(false) {
+		for x, walkable := range row {
+			world.SetWalkable(Vector2i{X: x, Y: y}, walkable)
+		}
+	}
+	world.analyzeMap(world.walkableSnapshot())
+
+	request := PathRequest{
+		Start:    GridPosition{Grid: Vector2i{X: 3, Y: 2}},
+		Target:   GridPosition{Grid: Vector2i{X: 25, Y: 12}},
+		UnitSize: 1,
+	}
+	path, ok := world.pathfindingMgr.findRegionPath(request)
+	if !ok || path.Partial || path.GridPath[len(path.GridPath)-1].Grid != request.Target.Grid {
+		t.Fatalf("Expected a path to the target, got %+v", path)
+	}
+	throughGap := false
+	for _, step := range path.GridPath {
+		throughGap = throughGap || step.Grid == Vector2i{X: 15, Y: 7}
+	}
+	if !throughGap {
+		t.Error("Expected the path to pass the chokepoint")
+	}
+
+	world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 5.5, Z: 7.5}, data.NewSimpleUnit("castle", 2000, 0, "stone", nil))
+	ai := NewStrategicAI(1, world, BalancedPersonality, DifficultyNormal)
+	military := NewMilitaryManager(1, world, ai)
+	military.assessDefensivePositions()
+	if len(military.defensivePositions) != 1 || military.defensivePositions[0] != (Vector3{X: 15.5, Z: 7.5}) {
+		t.Errorf("Expected to hold the gap, got %+v", military.defensivePositions)
+	}
+}
@@ -6,16 +6,17 @@ import (
 	"path/filepath"
 
 	"teraglest/internal/data"
+	"teraglest/internal/logging"
 )
 
 // MapManager handles loading and caching of maps using AssetManager
 type MapManager struct {
-	assetManager *data.AssetManager
+	assetManager data.AssetProvider
 	dataRoot     string // Root path for game data (maps, tilesets)
 }
 
 // NewMapManager creates a new map manager with the specified asset manager and data root
-func NewMapManager(assetManager *data.AssetManager, dataRoot string) *MapManager {
+func NewMapManager(assetManager data.AssetProvider, dataRoot string) *MapManager {
 	return &MapManager{
 		assetManager: assetManager,
 		dataRoot:     dataRoot,
@@ -217,39 +218,39 @@ func (mm *MapManager) isDirectory(path string) bool {
 	return info.IsDir()
 }
 
-// PrintSummary prints a summary of available maps and tilesets
+// PrintSummary logs a summary of available maps and tilesets at debug level
 func (mm *MapManager) PrintSummary() {
-	fmt.Println("Map Manager Summary:")
-	fmt.Printf("  Data Root: %s\n", mm.dataRoot)
+	logging.Debugf(logging.CategoryData, "Map Manager Summary:")
+	logging.Debugf(logging.CategoryData, "  Data Root: %s", mm.dataRoot)
 
 	maps, err := mm.GetAvailableMaps()
 	if err != nil {
-		fmt.Printf("  Maps: Error - %v\n", err)
+		logging.Debugf(logging.CategoryData, "  Maps: Error - %v", err)
 	} else {
-		fmt.Printf("  Available Maps: %d\n", len(maps))
+		logging.Debugf(logging.CategoryData, "  Available Maps: %d", len(maps))
 		if len(maps) > 0 && len(maps) <= 10 {
 			for i, mapName := range maps {
 				if i >= 5 { // Limit output
-					fmt.Printf("    ... and %d more\n", len(maps)-5)
+					logging.Debugf(logging.CategoryData, "    ... and %d more", len(maps)-5)
 					break
 				}
-				fmt.Printf("    - %s\n", mapName)
+				logging.Debugf(logging.CategoryData, "    - %s", mapName)
 			}
 		}
 	}
 
 	tilesets, err := mm.GetAvailableTilesets()
 	if err != nil {
-		fmt.Printf("  Tilesets: Error - %v\n", err)
+		logging.Debugf(logging.CategoryData, "  Tilesets: Error - %v", err)
 	} else {
-		fmt.Printf("  Available Tilesets: %d\n", len(tilesets))
+		logging.Debugf(logging.CategoryData, "  Available Tilesets: %d", len(tilesets))
 		if len(tilesets) > 0 && len(tilesets) <= 10 {
 			for i, tilesetName := range tilesets {
 				if i >= 5 { // Limit output
-					fmt.Printf("    ... and %d more\n", len(tilesets)-5)
+					logging.Debugf(logging.CategoryData, "    ... and %d more", len(tilesets)-5)
 					break
 				}
-				fmt.Printf("    - %s\n", tilesetName)
+				logging.Debugf(logging.CategoryData, "    - %s", tilesetName)
 			}
 		}
 	}
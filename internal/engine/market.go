@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Market tuning
+const (
+	marketBasePrice = 100.0  // Price every resource starts at and returns to
+	marketMinPrice  = 25.0   // Lowest price a resource can be sold down to
+	marketMaxPrice  = 400.0  // Highest price a resource can be bought up to
+	marketSpread    = 0.3    // Share of a trade's value kept by the market, half on each side
+	marketImpact    = 0.0015 // Relative price change per unit sold or bought
+	marketRecovery  = 0.01   // Share of the gap to the base price recovered per second
+)
+
+// isMarketBuilding reports whether a building type lets its owner trade resources
+func isMarketBuilding(buildingType string) bool {
+	switch buildingType {
+	case "market", "marketplace", "trading_post":
+		return true
+	default:
+		return false
+	}
+}
+
+// Market exchanges resources at prices shared by all players: selling a
+// resource lowers its price and buying one raises it, and prices drift back
+// to the base price over time
+type Market struct {
+	mutex  sync.Mutex
+	prices map[string]float64 // Current price per resource; missing ones are at the base price
+}
+
+// Price returns the current price of a resource
+func (m *Market) Price(resource string) float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.priceLocked(resource)
+}
+
+// Quote returns how much of one resource selling an amount of another buys
+func (m *Market) Quote(sell, buy string, amount int) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.quoteLocked(sell, buy, amount)
+}
+
+// quoteLocked computes a quote (lock must be held)
+func (m *Market) quoteLocked(sell, buy string, amount int) int {
+	if amount <= 0 || sell == buy {
+		return 0
+	}
+	value := float64(amount) * m.priceLocked(sell) * (1 - marketSpread/2)
+	return int(value / (m.priceLocked(buy) * (1 + marketSpread/2)))
+}
+
+// priceLocked returns a resource's price (lock must be held)
+func (m *Market) priceLocked(resource string) float64 {
+	if price, exists := m.prices[resource]; exists {
+		return price
+	}
+	return marketBasePrice
+}
+
+// recordTradeLocked moves the prices after a trade (lock must be held)
+func (m *Market) recordTradeLocked(sell, buy string, sold, bought int) {
+	if m.prices == nil {
+		m.prices = make(map[string]float64)
+	}
+	m.prices[sell] = math.Max(marketMinPrice, m.priceLocked(sell)*math.Pow(1-marketImpact, float64(sold)))
+	m.prices[buy] = math.Min(marketMaxPrice, m.priceLocked(buy)*math.Pow(1+marketImpact, float64(bought)))
+}
+
+// update lets prices recover towards the base price
+func (m *Market) update(deltaTime time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	recovery := math.Min(1, marketRecovery*deltaTime.Seconds())
+	for resource, price := range m.prices {
+		price += (marketBasePrice - price) * recovery
+		if math.Abs(price-marketBasePrice) < 0.01 {
+			delete(m.prices, resource)
+			continue
+		}
+		m.prices[resource] = price
+	}
+}
+
+// GetMarket returns the market shared by all players
+func (w *World) GetMarket() *Market {
+	return &w.market
+}
+
+// HasMarket reports whether a player owns a finished market building
+func (w *World) HasMarket(playerID int) bool {
+	for _, building := range w.ObjectManager.GetBuildingsForPlayer(playerID) {
+		if building.IsBuilt && building.Health > 0 && isMarketBuilding(building.BuildingType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Trade sells an amount of one resource for another through the player's
+// market at the current rate; it returns the amount bought
+func (w *World) Trade(playerID int, sell, buy string, amount int) (int, error) {
+	if amount <= 0 {
+		return 0, fmt.Errorf("trade amount must be positive, got %d", amount)
+	}
+	if sell == buy {
+		return 0, fmt.Errorf("cannot trade %s for itself", sell)
+	}
+	player := w.GetPlayer(playerID)
+	if player == nil {
+		return 0, fmt.Errorf("player %d not found", playerID)
+	}
+	if _, known := w.GetResourceStatus(playerID).Resources[buy]; !known {
+		return 0, fmt.Errorf("unknown resource %s", buy)
+	}
+	if !w.HasMarket(playerID) {
+		return 0, fmt.Errorf("player %d has no market", playerID)
+	}
+
+	// Hold the market while paying so concurrent trades see the moved prices
+	w.market.mutex.Lock()
+	defer w.market.mutex.Unlock()
+
+	bought := w.market.quoteLocked(sell, buy, amount)
+	if bought <= 0 {
+		return 0, fmt.Errorf("%d %s is too little to buy any %s", amount, sell, buy)
+	}
+	if err := w.DeductResources(playerID, map[string]int{sell: amount}, "market"); err != nil {
+		return 0, fmt.Errorf("failed to pay for trade: %w", err)
+	}
+	if err := w.AddResources(playerID, map[string]int{buy: bought}, "market"); err != nil {
+		return 0, fmt.Errorf("failed to deliver trade: %w", err)
+	}
+	w.market.recordTradeLocked(sell, buy, amount, bought)
+	return bought, nil
+}
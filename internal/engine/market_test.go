@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestMarketTrade tests trading through a market and the dynamic rates
+func TestMarketTrade(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	if _, err := world.Trade(1, "gold", "wood", 100); err == nil {
+		t.Fatal("Expected trading without a market to fail")
+	}
+
+	def := data.NewSimpleUnit("market", 800, 0, "stone", nil)
+	market, err := world.ObjectManager.CreateBuilding(1, "market", Vector3{X: 5, Z: 5}, def)
+	if err != nil {
+		t.Fatalf("Failed to create market: %v", err)
+	}
+	market.IsBuilt = true
+
+	quote := world.GetMarket().Quote("gold", "wood", 100)
+	bought, err := world.Trade(1, "gold", "wood", 100)
+	if err != nil {
+		t.Fatalf("Failed to trade: %v", err)
+	}
+	if bought != quote || bought != 73 {
+		t.Errorf("Expected to buy the quoted 73 wood, got %d (quote %d)", bought, quote)
+	}
+	resources := world.GetResourceStatus(1).Resources
+	if resources["gold"] != 900 || resources["wood"] != 1073 {
+		t.Errorf("Expected 900 gold and 1073 wood, got %d and %d", resources["gold"], resources["wood"])
+	}
+
+	// Selling gold made it cheaper and buying wood made it dearer
+	if world.GetMarket().Price("gold") >= marketBasePrice || world.GetMarket().Price("wood") <= marketBasePrice {
+		t.Errorf("Expected gold below and wood above the base price, got %.1f and %.1f",
+			world.GetMarket().Price("gold"), world.GetMarket().Price("wood"))
+	}
+	if again := world.GetMarket().Quote("gold", "wood", 100); again >= bought {
+		t.Errorf("Expected a worse rate for the same trade, got %d after %d", again, bought)
+	}
+
+	for _, bad := range []struct {
+		sell, buy string
+		amount    int
+	}{
+		{"gold", "gold", 100},
+		{"gold", "wood", 0},
+		{"gold", "wood", 5000},
+		{"gold", "mithril", 100},
+	} {
+		if _, err := world.Trade(1, bad.sell, bad.buy, bad.amount); err == nil {
+			t.Errorf("Expected trading %d %s for %s to fail", bad.amount, bad.sell, bad.buy)
+		}
+	}
+
+	world.GetMarket().update(10 * time.Minute)
+	if world.GetMarket().Price("gold") != marketBasePrice || world.GetMarket().Price("wood") != marketBasePrice {
+		t.Errorf("Expected prices to recover to the base price")
+	}
+}
+
+// TestAITradesForBottleneck tests that the AI trades a surplus for a scarce resource
+func TestAITradesForBottleneck(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	def := data.NewSimpleUnit("market", 800, 0, "stone", nil)
+	market, err := world.ObjectManager.CreateBuilding(2, "market", Vector3{X: 5, Z: 5}, def)
+	if err != nil {
+		t.Fatalf("Failed to create market: %v", err)
+	}
+	market.IsBuilt = true
+	world.GetPlayer(2).Resources["wood"] = 50
+
+	NewEconomicManager(2, world, nil).tradeForBottleneck()
+
+	resources := world.GetResourceStatus(2).Resources
+	if resources["gold"] != 900 || resources["wood"] <= 50 {
+		t.Errorf("Expected 100 gold traded for wood, got %d gold and %d wood", resources["gold"], resources["wood"])
+	}
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NeutralPlayerID owns the units and buildings of players who surrendered,
+// when GameSettings.SurrenderDisposal keeps them on the map. It is no player
+// of the match, so its units take no orders and AI ignores them.
+const NeutralPlayerID = 0
+
+// SurrenderDisposal is what happens to a surrendered player's forces
+type SurrenderDisposal int
+
+const (
+	SurrenderNeutralForces SurrenderDisposal = iota // Units and buildings stay, idle, as neutral
+	SurrenderRemoveForces                           // Units and buildings are removed from the map
+)
+
+// String returns the string representation of a SurrenderDisposal
+func (d SurrenderDisposal) String() string {
+	switch d {
+	case SurrenderNeutralForces:
+		return "neutral"
+	case SurrenderRemoveForces:
+		return "remove"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParseSurrenderDisposal parses a disposal name as printed by String
+func ParseSurrenderDisposal(name string) (SurrenderDisposal, error) {
+	switch strings.ToLower(name) {
+	case "neutral", "":
+		return SurrenderNeutralForces, nil
+	case "remove":
+		return SurrenderRemoveForces, nil
+	default:
+		return 0, fmt.Errorf("unknown surrender disposal %q (valid: neutral, remove)", name)
+	}
+}
+
+// matchOutcome records whether the match is decided and who won
+type matchOutcome struct {
+	mutex   sync.Mutex
+	over    bool
+	winners []int
+}
+
+// MatchOver reports whether the match is decided, and its winners sorted
+func (w *World) MatchOver() ([]int, bool) {
+	w.outcome.mutex.Lock()
+	defer w.outcome.mutex.Unlock()
+	return append([]int(nil), w.outcome.winners...), w.outcome.over
+}
+
+// evaluateVictory ends the match when the players still in it are all on
+// one side: a single player, or allies of each other. Every winner gets an
+// EventTypePlayerVictory, once.
+func (w *World) evaluateVictory() {
+	var remaining []int
+	for _, player := range w.GetAllPlayers() {
+		if player.IsActive {
+			remaining = append(remaining, player.ID)
+		}
+	}
+	if len(remaining) == 0 {
+		return
+	}
+	sort.Ints(remaining)
+	for _, playerID := range remaining[1:] {
+		if !w.AreAllied(remaining[0], playerID) {
+			return
+		}
+	}
+
+	w.outcome.mutex.Lock()
+	if w.outcome.over {
+		w.outcome.mutex.Unlock()
+		return
+	}
+	w.outcome.over, w.outcome.winners = true, remaining
+	w.outcome.mutex.Unlock()
+
+	for _, playerID := range remaining {
+		w.raiseEvent(GameEvent{
+			Type:      EventTypePlayerVictory,
+			Timestamp: w.now(),
+			PlayerID:  playerID,
+			Data:      append([]int(nil), remaining...),
+			Message:   fmt.Sprintf("Player %d is victorious", playerID),
+		})
+	}
+}
+
+// resetOutcome undecides the match, for restored saves and rematches
+func (w *World) resetOutcome() {
+	w.outcome.mutex.Lock()
+	defer w.outcome.mutex.Unlock()
+	w.outcome.over, w.outcome.winners = false, nil
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestSurrenderDecidesMatch tests surrendering by chat command, neutral
+// forces, victory and a rematch
+func TestSurrenderDecidesMatch(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	var victors []int
+	world.AddEventListener(func(event GameEvent) {
+		if event.Type == EventTypePlayerVictory {
+			victors = append(victors, event.PlayerID)
+		}
+	})
+	unitDef := &data.UnitDefinition{Name: "soldier"}
+	unitDef.Unit.Parameters.MaxHP.Value = 100
+	unit, err := world.ObjectManager.CreateUnit(2, "soldier", Vector3{X: 5, Z: 5}, unitDef)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	building, err := world.ObjectManager.CreateBuilding(2, "barracks", Vector3{X: 10, Z: 10}, unitDef)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	start := world.CaptureSaveGame()
+
+	if _, _, ok := ParseChatCommand("good game all"); ok {
+		t.Error("Expected an ordinary message not to be a command")
+	}
+	command, args, ok := ParseChatCommand(" /GG ")
+	if !ok || command != ChatCommandSurrender || len(args) != 0 {
+		t.Fatalf("Expected /gg to mean surrender, got %q %v", command, args)
+	}
+	if err := world.RunChatCommand(2, command, args); err != nil {
+		t.Fatalf("Surrender failed: %v", err)
+	}
+
+	// The forces stay on the map, owned by no one
+	if unit.PlayerID != NeutralPlayerID || building.PlayerID != NeutralPlayerID || len(world.ObjectManager.GetUnitsForPlayer(2)) != 0 {
+		t.Errorf("Expected player 2's forces to turn neutral, got unit of %d and building of %d", unit.PlayerID, building.PlayerID)
+	}
+	if winners, over := world.MatchOver(); !over || len(winners) != 1 || winners[0] != 1 || len(victors) != 1 {
+		t.Fatalf("Expected player 1 to win, got %v (over %v, %d events)", winners, over, len(victors))
+	}
+	if err := world.RunChatCommand(2, ChatCommandSurrender, nil); err == nil {
+		t.Error("Expected a second surrender to fail")
+	}
+
+	// Saves keep the neutral forces
+	if err := world.RestoreSaveGame(world.CaptureSaveGame()); err != nil {
+		t.Fatalf("RestoreSaveGame failed: %v", err)
+	}
+	if neutral := world.ObjectManager.GetUnitsForPlayer(NeutralPlayerID); len(neutral) != 1 {
+		t.Errorf("Expected the neutral unit to be saved, got %d", len(neutral))
+	}
+
+	// A rematch starts from the beginning, undecided
+	if err := world.RestoreSaveGame(start); err != nil {
+		t.Fatalf("RestoreSaveGame failed: %v", err)
+	}
+	if _, over := world.MatchOver(); over || !world.GetPlayer(2).IsActive {
+		t.Error("Expected the restarted match to be undecided")
+	}
+
+	// Allies win together, and removed forces leave the map
+	world.GetPlayer(1).Team, world.GetPlayer(2).Team = 1, 1
+	world.settings.SurrenderDisposal = SurrenderRemoveForces
+	world.players[3] = &Player{ID: 3, IsActive: true, Resources: map[string]int{}}
+	world.ObjectManager.CreateUnit(3, "soldier", Vector3{X: 20, Z: 20}, unitDef)
+	victors = nil
+	if err := world.Surrender(3); err != nil {
+		t.Fatalf("Surrender failed: %v", err)
+	}
+	if len(victors) != 2 || len(world.ObjectManager.GetUnitsForPlayer(3)) != 0 || len(world.ObjectManager.GetUnitsForPlayer(NeutralPlayerID)) != 0 {
+		t.Errorf("Expected both allies to win and player 3's units to be removed, got victors %v", victors)
+	}
+}
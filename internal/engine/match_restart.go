@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Reset ends the current match and tears down its world: the game loop
+// stops, the world stops advancing and raising events, and the events and
+// statistics the match left behind are dropped. The game then has no world
+// until NewMatch starts the next match.
+func (g *Game) Reset() {
+	g.mutex.RLock()
+	running, done := g.isRunning, g.loopDone
+	g.mutex.RUnlock()
+	if running {
+		g.Stop()
+		<-done // The loop may be waiting to run one last update
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.world != nil {
+		g.world.teardown()
+	}
+	g.world = nil
+	g.startSave = nil
+	g.ctx, g.cancel = context.WithCancel(context.Background())
+	g.state = GameStateLoading
+	g.stats = GameStats{
+		StartTime:      time.Now(),
+		LastUpdateTime: time.Now(),
+		ResourcesTotal: make(map[string]int64),
+	}
+	g.lastUpdate = time.Now()
+	g.eventLog.clear()
+	g.GetEvents()
+}
+
+// NewMatch ends the current match, if any, and starts a fresh one with new
+// settings in a new world. The loaded tech tree is kept, so the settings
+// must choose the tech tree the game's assets come from.
+func (g *Game) NewMatch(settings GameSettings) error {
+	if err := validateMatchSettings(settings, g.assetMgr); err != nil {
+		return err
+	}
+	g.Reset()
+
+	g.mutex.Lock()
+	g.settings = settings
+	err := g.createWorld()
+	g.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to set up the new match: %w", err)
+	}
+	return g.Start()
+}
+
+// teardown stops a finished match's world advancing, raising events or
+// running its AI, so nothing still holding it reaches the next match
+func (w *World) teardown() {
+	w.events.mutex.Lock()
+	w.events.send = nil
+	w.events.listeners = nil
+	w.events.mutex.Unlock()
+
+	// Commands held during a pause are for the finished match
+	if w.commandProcessor != nil {
+		w.commandProcessor.dropHeldCommands()
+	}
+
+	// The AI players stop planning for the finished match
+	for playerID := range w.settings.AIFactions {
+		w.strategicAIMgr.RemoveAIPlayer(playerID)
+	}
+
+	w.mutex.Lock()
+	w.initialized = false
+	w.mutex.Unlock()
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestNewMatch tests that a new match starts in a fresh world and nothing of
+// the match before reaches it: not its units, events, statistics or updates
+func TestNewMatch(t *testing.T) {
+	assets := data.NewMemoryAssetProvider()
+	assets.AddFaction("testers", data.Faction{StartingUnits: []data.StartingUnit{{Name: "scout", Amount: 2}}})
+	assets.AddUnit("testers", data.NewSimpleUnit("scout", 150, 1, "leather", nil))
+	settings := GameSettings{
+		TechTreePath:   "memory",
+		MaxPlayers:     2,
+		PlayerFactions: map[int]string{1: "testers"},
+		AIFactions:     map[int]string{2: "testers"},
+	}
+
+	game, err := NewGame(settings, assets)
+	if err != nil {
+		t.Fatalf("Failed to create game: %v", err)
+	}
+	if err := game.Start(); err != nil {
+		t.Fatalf("Failed to start game: %v", err)
+	}
+	goroutines := runtime.NumGoroutine()
+
+	first := game.GetWorld()
+	def, _ := assets.LoadUnit("testers", "scout")
+	first.ObjectManager.CreateUnit(1, "scout", Vector3{X: 5, Z: 5}, def)
+	time.Sleep(50 * time.Millisecond)
+
+	for match := 0; match < 3; match++ {
+		if err := game.NewMatch(settings); err != nil {
+			t.Fatalf("Failed to start match %d: %v", match+2, err)
+		}
+	}
+	world := game.GetWorld()
+	if world == first || game.GetState() != GameStatePlaying {
+		t.Fatalf("Expected a new world playing, got the same world %v in state %v", world == first, game.GetState())
+	}
+	if count := len(world.ObjectManager.GetUnitsForPlayer(1)); count != 2 {
+		t.Errorf("Expected only the 2 starting units in the new match, got %d", count)
+	}
+	if events := game.GetEvents(); len(events) != 1 || events[0].Type != EventTypeGameStart {
+		t.Errorf("Expected only the new match's start event, got %d events", len(events))
+	}
+	if frames := game.GetStats().FrameCount; frames > 2 {
+		t.Errorf("Expected frame statistics to start over, got %d frames", frames)
+	}
+
+	// The old world stands still
+	before := first.GetGameTime()
+	time.Sleep(50 * time.Millisecond)
+	if first.GetGameTime() != before {
+		t.Errorf("Expected the torn down world not to advance")
+	}
+	if count := first.strategicAIMgr.GetAIPlayerCount(); count != 0 {
+		t.Errorf("Expected the torn down world's AI to be removed, %d AI players left", count)
+	}
+	if after := runtime.NumGoroutine(); after > goroutines {
+		t.Errorf("Expected no goroutines left behind by finished matches, had %d and now %d", goroutines, after)
+	}
+
+	game.Reset()
+	if game.GetWorld() != nil || game.GetState() != GameStateLoading {
+		t.Errorf("Expected no world after a reset")
+	}
+	if err := game.Start(); err == nil {
+		t.Errorf("Expected starting without a match set up to fail")
+	}
+}
@@ -0,0 +1,336 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"teraglest/internal/logging"
+)
+
+// ErrModPermission is returned when a mod calls something its sandbox forbids
+var ErrModPermission = errors.New("mod lacks permission")
+
+// ModPermissions are a mod's sandbox: what its hooks may change through the
+// ModAPI. Hooks never get the world itself, so a mod can only read and
+// change what the API offers. Mods run in-process, so this restricts game
+// access; it is no protection against hostile native code.
+type ModPermissions struct {
+	ModifyDamage  bool // OnDamage may change the damage dealt
+	ModifyUnits   bool // Hooks may heal units and spend their energy
+	IssueCommands bool // Hooks may order units around
+
+	// Longest a single hook call may run before the mod is disabled (0 = no
+	// limit). Timing differs between machines, so only set it for single
+	// player: in lockstep, one peer disabling a mod desyncs the match.
+	HookBudget time.Duration
+}
+
+// ModCommand is a custom unit command added by a mod; target is the point
+// the player aimed it at, nil for commands without one
+type ModCommand func(api *ModAPI, unit ModUnit, target *Vector3) error
+
+// Mod is a set of hooks a mod adds to the game. Every hook is optional.
+// A hook that panics disables its mod for the rest of the match.
+type Mod struct {
+	Name        string
+	Permissions ModPermissions
+
+	OnUnitCreated func(api *ModAPI, unit ModUnit)
+	OnDamage      func(api *ModAPI, target ModUnit, damage int) int // Returns the damage to deal
+	OnTick        func(api *ModAPI, deltaTime time.Duration)
+	Commands      map[string]ModCommand
+}
+
+// ModUnit is a snapshot of a unit handed to mod hooks
+type ModUnit struct {
+	ID        int
+	PlayerID  int
+	UnitType  string
+	Position  Vector3
+	Health    int
+	MaxHealth int
+	Energy    int
+	MaxEnergy int
+}
+
+// ModInfo describes a registered mod
+type ModInfo struct {
+	Name     string
+	Commands []string // Custom command names, sorted
+	Disabled string   // Why the mod was disabled ("" while active)
+}
+
+// loadedMod is a registered mod and its state
+type loadedMod struct {
+	mod      *Mod
+	api      *ModAPI
+	disabled string
+}
+
+// modRegistry holds a world's mods in registration order
+type modRegistry struct {
+	mutex sync.RWMutex
+	mods  []*loadedMod
+}
+
+// RegisterMod adds a mod's hooks to the world. Mod names and custom command
+// names must be unique.
+func (w *World) RegisterMod(mod *Mod) error {
+	if mod == nil || mod.Name == "" {
+		return fmt.Errorf("mod must have a name")
+	}
+	w.mods.mutex.Lock()
+	defer w.mods.mutex.Unlock()
+	for _, loaded := range w.mods.mods {
+		if loaded.mod.Name == mod.Name {
+			return fmt.Errorf("mod %q is already registered", mod.Name)
+		}
+		for name := range mod.Commands {
+			if _, taken := loaded.mod.Commands[name]; taken {
+				return fmt.Errorf("mod %q: command %q is already added by mod %q", mod.Name, name, loaded.mod.Name)
+			}
+		}
+	}
+	for name, command := range mod.Commands {
+		if name == "" || command == nil {
+			return fmt.Errorf("mod %q: commands need a name and a handler", mod.Name)
+		}
+	}
+
+	loaded := &loadedMod{mod: mod}
+	loaded.api = &ModAPI{world: w, mod: loaded}
+	w.mods.mods = append(w.mods.mods, loaded)
+	logging.Infof(logging.CategoryEngine, "Mod %s registered", mod.Name)
+	return nil
+}
+
+// Mods describes the registered mods in registration order
+func (w *World) Mods() []ModInfo {
+	w.mods.mutex.RLock()
+	defer w.mods.mutex.RUnlock()
+	infos := make([]ModInfo, 0, len(w.mods.mods))
+	for _, loaded := range w.mods.mods {
+		info := ModInfo{Name: loaded.mod.Name, Disabled: loaded.disabled}
+		for name := range loaded.mod.Commands {
+			info.Commands = append(info.Commands, name)
+		}
+		sort.Strings(info.Commands)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// RunModCommand runs a mod's custom command on one of a player's units
+func (w *World) RunModCommand(playerID, unitID int, command string, target *Vector3) error {
+	unit := w.ObjectManager.GetUnit(unitID)
+	if unit == nil || !unit.IsAlive() || unit.PlayerID != playerID {
+		return fmt.Errorf("player %d has no unit %d", playerID, unitID)
+	}
+	for _, loaded := range w.activeMods() {
+		handler, ok := loaded.mod.Commands[command]
+		if !ok {
+			continue
+		}
+		var err error
+		loaded.run("command "+command, func() {
+			err = handler(loaded.api, unit.modSnapshot(), target)
+		})
+		return err
+	}
+	return fmt.Errorf("no mod adds command %q", command)
+}
+
+// activeMods returns the mods not disabled
+func (w *World) activeMods() []*loadedMod {
+	w.mods.mutex.RLock()
+	defer w.mods.mutex.RUnlock()
+	active := make([]*loadedMod, 0, len(w.mods.mods))
+	for _, loaded := range w.mods.mods {
+		if loaded.disabled == "" {
+			active = append(active, loaded)
+		}
+	}
+	return active
+}
+
+// modsUnitCreated runs the OnUnitCreated hooks
+func (w *World) modsUnitCreated(unit *GameUnit) {
+	for _, loaded := range w.activeMods() {
+		if hook := loaded.mod.OnUnitCreated; hook != nil {
+			snapshot := unit.modSnapshot()
+			loaded.run("OnUnitCreated", func() { hook(loaded.api, snapshot) })
+		}
+	}
+}
+
+// modsDamage runs the OnDamage hooks of mods allowed to change damage and
+// returns the damage to deal
+func (w *World) modsDamage(target *GameUnit, damage int) int {
+	for _, loaded := range w.activeMods() {
+		hook := loaded.mod.OnDamage
+		if hook == nil || !loaded.mod.Permissions.ModifyDamage {
+			continue
+		}
+		snapshot, dealt := target.modSnapshot(), damage
+		loaded.run("OnDamage", func() { dealt = hook(loaded.api, snapshot, damage) })
+		if dealt >= 0 {
+			damage = dealt
+		}
+	}
+	return damage
+}
+
+// modsTick runs the OnTick hooks
+func (w *World) modsTick(deltaTime time.Duration) {
+	for _, loaded := range w.activeMods() {
+		if hook := loaded.mod.OnTick; hook != nil {
+			loaded.run("OnTick", func() { hook(loaded.api, deltaTime) })
+		}
+	}
+}
+
+// run calls one of the mod's hooks, disabling the mod when the hook panics
+// or overruns its budget
+func (m *loadedMod) run(hook string, call func()) {
+	start := time.Now()
+	defer func() {
+		reason := ""
+		if recovered := recover(); recovered != nil {
+			reason = fmt.Sprintf("%s panicked: %v", hook, recovered)
+		} else if budget := m.mod.Permissions.HookBudget; budget > 0 && time.Since(start) > budget {
+			reason = fmt.Sprintf("%s ran %v, over its %v budget", hook, time.Since(start), budget)
+		}
+		if reason != "" {
+			m.disable(reason)
+		}
+	}()
+	call()
+}
+
+// disable stops calling the mod's hooks
+func (m *loadedMod) disable(reason string) {
+	world := m.api.world
+	world.mods.mutex.Lock()
+	m.disabled = reason
+	world.mods.mutex.Unlock()
+	logging.Warnf(logging.CategoryEngine, "Mod %s disabled: %s", m.mod.Name, reason)
+}
+
+// modSnapshot copies the unit state mods may read
+func (u *GameUnit) modSnapshot() ModUnit {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	return ModUnit{
+		ID:        u.ID,
+		PlayerID:  u.PlayerID,
+		UnitType:  u.UnitType,
+		Position:  u.Position,
+		Health:    u.Health,
+		MaxHealth: u.MaxHealth,
+		Energy:    u.Energy,
+		MaxEnergy: u.MaxEnergy,
+	}
+}
+
+// ModAPI is what a mod's hooks may see and do in the game, within the
+// mod's permissions
+type ModAPI struct {
+	world *World
+	mod   *loadedMod
+}
+
+// GameTime returns the game time elapsed
+func (api *ModAPI) GameTime() time.Duration {
+	return api.world.GetGameTime()
+}
+
+// MapAnalysis returns the regions, chokepoints and expansion sites of the
+// map; mods must not change it
+func (api *ModAPI) MapAnalysis() *MapAnalysis {
+	return api.world.MapAnalysis()
+}
+
+// Unit returns a living unit by ID
+func (api *ModAPI) Unit(unitID int) (ModUnit, bool) {
+	unit := api.world.ObjectManager.GetUnit(unitID)
+	if unit == nil || !unit.IsAlive() {
+		return ModUnit{}, false
+	}
+	return unit.modSnapshot(), true
+}
+
+// UnitsNear returns the living units within a radius of a point, ordered by
+// ID so every peer sees them in the same order
+func (api *ModAPI) UnitsNear(center Vector3, radius float64) []ModUnit {
+	var units []ModUnit
+	for _, player := range api.world.GetPlayers() {
+		for _, unit := range api.world.ObjectManager.GetUnitsForPlayer(player.ID) {
+			if unit.IsAlive() && unit.GarrisonedIn == 0 && api.world.CalculateDistance(center, unit.GetPosition()) <= radius {
+				units = append(units, unit.modSnapshot())
+			}
+		}
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+	return units
+}
+
+// Heal restores a unit's health, up to its maximum
+func (api *ModAPI) Heal(unitID, amount int) error {
+	unit, err := api.modifiableUnit(unitID)
+	if err != nil {
+		return err
+	}
+	if amount < 0 {
+		return fmt.Errorf("heal amount must not be negative")
+	}
+	unit.mutex.Lock()
+	defer unit.mutex.Unlock()
+	unit.Health += amount
+	if unit.Health > unit.MaxHealth {
+		unit.Health = unit.MaxHealth
+	}
+	return nil
+}
+
+// SpendEnergy takes energy from a unit, failing when it has too little
+func (api *ModAPI) SpendEnergy(unitID, amount int) error {
+	unit, err := api.modifiableUnit(unitID)
+	if err != nil {
+		return err
+	}
+	unit.mutex.Lock()
+	defer unit.mutex.Unlock()
+	if amount < 0 || unit.Energy < amount {
+		return fmt.Errorf("unit %d has %d energy, needs %d", unitID, unit.Energy, amount)
+	}
+	unit.Energy -= amount
+	return nil
+}
+
+// Move orders a unit to move to a point
+func (api *ModAPI) Move(unitID int, target Vector3) error {
+	if !api.mod.mod.Permissions.IssueCommands {
+		return fmt.Errorf("%w to issue commands", ErrModPermission)
+	}
+	return api.world.commandProcessor.IssueCommand(unitID, UnitCommand{Type: CommandMove, Target: &target})
+}
+
+// Logf writes a message to the game log, tagged with the mod name
+func (api *ModAPI) Logf(format string, args ...interface{}) {
+	logging.Infof(logging.CategoryEngine, "[%s] %s", api.mod.mod.Name, fmt.Sprintf(format, args...))
+}
+
+// modifiableUnit returns a living unit the mod may change
+func (api *ModAPI) modifiableUnit(unitID int) (*GameUnit, error) {
+	if !api.mod.mod.Permissions.ModifyUnits {
+		return nil, fmt.Errorf("%w to modify units", ErrModPermission)
+	}
+	unit := api.world.ObjectManager.GetUnit(unitID)
+	if unit == nil || !unit.IsAlive() {
+		return nil, fmt.Errorf("unit %d not found", unitID)
+	}
+	return unit, nil
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestModHooks tests the mod extension points and their sandbox
+func TestModHooks(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	unitDef := &data.UnitDefinition{Name: "guard"}
+	unitDef.Unit.Parameters.MaxHP.Value = 100
+
+	var created []string
+	var ticked time.Duration
+	mod := &Mod{
+		Name:        "test",
+		Permissions: ModPermissions{ModifyDamage: true, ModifyUnits: true},
+		OnUnitCreated: func(api *ModAPI, unit ModUnit) {
+			created = append(created, unit.UnitType)
+		},
+		OnDamage: func(api *ModAPI, target ModUnit, damage int) int {
+			return damage / 2 // Armored mod halves every hit
+		},
+		OnTick: func(api *ModAPI, deltaTime time.Duration) { ticked += deltaTime },
+		Commands: map[string]ModCommand{
+			"mend": func(api *ModAPI, unit ModUnit, target *Vector3) error {
+				if err := api.SpendEnergy(unit.ID, 40); err != nil {
+					return err
+				}
+				return api.Heal(unit.ID, 30)
+			},
+			"march": func(api *ModAPI, unit ModUnit, target *Vector3) error {
+				return api.Move(unit.ID, *target)
+			},
+		},
+	}
+	if err := world.RegisterMod(mod); err != nil {
+		t.Fatalf("RegisterMod failed: %v", err)
+	}
+	if err := world.RegisterMod(&Mod{Name: "copy", Commands: map[string]ModCommand{"mend": mod.Commands["mend"]}}); err == nil {
+		t.Error("Expected a clash of command names to fail")
+	}
+
+	unit, err := world.ObjectManager.CreateUnit(1, "guard", Vector3{X: 8, Z: 8}, unitDef)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	if len(created) != 1 || created[0] != "guard" {
+		t.Errorf("Expected OnUnitCreated for the guard, got %v", created)
+	}
+
+	world.commandProcessor.combatSystem.ApplyDamage(unit, 60)
+	if unit.Health != 70 {
+		t.Errorf("Expected the mod to halve 60 damage, health 70, got %d", unit.Health)
+	}
+
+	world.Update(100 * time.Millisecond)
+	if ticked != 100*time.Millisecond {
+		t.Errorf("Expected OnTick to see 100ms, got %v", ticked)
+	}
+
+	if err := world.RunModCommand(1, unit.ID, "mend", nil); err != nil {
+		t.Fatalf("mend failed: %v", err)
+	}
+	if unit.Health != 100 || unit.Energy != 60 {
+		t.Errorf("Expected health 100 and energy 60 after mending, got %d and %d", unit.Health, unit.Energy)
+	}
+	if err := world.RunModCommand(2, unit.ID, "mend", nil); err == nil {
+		t.Error("Expected another player's unit to be refused")
+	}
+	target := Vector3{X: 12, Z: 8}
+	if err := world.RunModCommand(1, unit.ID, "march", &target); !errors.Is(err, ErrModPermission) {
+		t.Errorf("Expected march to need the commands permission, got %v", err)
+	}
+
+	// A panicking hook disables its mod, and the game carries on without it
+	mod.OnTick = func(api *ModAPI, deltaTime time.Duration) { panic("broken mod") }
+	world.Update(100 * time.Millisecond)
+	if infos := world.Mods(); len(infos) != 1 || infos[0].Disabled == "" {
+		t.Fatalf("Expected the mod to be disabled, got %+v", infos)
+	}
+	world.commandProcessor.combatSystem.ApplyDamage(unit, 10)
+	if unit.Health != 90 {
+		t.Errorf("Expected full damage from a disabled mod, health 90, got %d", unit.Health)
+	}
+}
+
+// TestModPermissions tests the host restricting what mods may do
+func TestModPermissions(t *testing.T) {
+	allowed, err := ParseModPermissions("damage, units", time.Millisecond)
+	if err != nil {
+		t.Fatalf("ParseModPermissions failed: %v", err)
+	}
+	wanted := ModPermissions{ModifyDamage: true, IssueCommands: true, HookBudget: time.Second}
+	if got := wanted.Restrict(allowed); got != (ModPermissions{ModifyDamage: true, HookBudget: time.Millisecond}) {
+		t.Errorf("Unexpected restricted permissions %+v", got)
+	}
+	if _, err := ParseModPermissions("files", 0); err == nil {
+		t.Error("Expected an unknown permission to fail")
+	}
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ModPluginSymbol is the function a compiled Go plugin mod exports:
+//
+//	func NewMod() *engine.Mod
+//
+// Plugins must be built with -buildmode=plugin against the same engine
+// source as the game, and only load on platforms Go supports plugins on.
+const ModPluginSymbol = "NewMod"
+
+// ModPluginExtension is the file extension of compiled mods
+const ModPluginExtension = ".so"
+
+// Restrict limits permissions to those a host allows; the stricter hook
+// budget applies
+func (p ModPermissions) Restrict(allowed ModPermissions) ModPermissions {
+	restricted := ModPermissions{
+		ModifyDamage:  p.ModifyDamage && allowed.ModifyDamage,
+		ModifyUnits:   p.ModifyUnits && allowed.ModifyUnits,
+		IssueCommands: p.IssueCommands && allowed.IssueCommands,
+		HookBudget:    p.HookBudget,
+	}
+	if allowed.HookBudget > 0 && (restricted.HookBudget == 0 || allowed.HookBudget < restricted.HookBudget) {
+		restricted.HookBudget = allowed.HookBudget
+	}
+	return restricted
+}
+
+// ParseModPermissions parses a comma-separated permission list ("damage",
+// "units", "commands", "all" or "none") and a hook budget into a sandbox
+func ParseModPermissions(list string, hookBudget time.Duration) (ModPermissions, error) {
+	permissions := ModPermissions{HookBudget: hookBudget}
+	if hookBudget < 0 {
+		return permissions, fmt.Errorf("mod hook budget must not be negative")
+	}
+	for _, name := range strings.Split(list, ",") {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case "", "none":
+		case "all":
+			permissions.ModifyDamage, permissions.ModifyUnits, permissions.IssueCommands = true, true, true
+		case "damage":
+			permissions.ModifyDamage = true
+		case "units":
+			permissions.ModifyUnits = true
+		case "commands":
+			permissions.IssueCommands = true
+		default:
+			return permissions, fmt.Errorf("unknown mod permission %q (valid: damage, units, commands, all, none)", name)
+		}
+	}
+	return permissions, nil
+}
+
+// LoadModPlugin opens a compiled Go plugin mod
+func LoadModPlugin(path string) (*Mod, error) {
+	opened, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mod plugin %s: %w", path, err)
+	}
+	symbol, err := opened.Lookup(ModPluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("mod plugin %s: %w", path, err)
+	}
+	newMod, ok := symbol.(func() *Mod)
+	if !ok {
+		return nil, fmt.Errorf("mod plugin %s: %s is a %T, not func() *engine.Mod", path, ModPluginSymbol, symbol)
+	}
+	mod := newMod()
+	if mod == nil {
+		return nil, fmt.Errorf("mod plugin %s returned no mod", path)
+	}
+	return mod, nil
+}
+
+// LoadModPlugins registers every compiled mod in a directory, in file name
+// order, with its permissions restricted to the allowed ones. A missing
+// directory has no mods; mods that fail to load are reported together
+// without stopping the others.
+func (w *World) LoadModPlugins(dir string, allowed ModPermissions) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read mod directory: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var loaded []string
+	var failures []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ModPluginExtension {
+			continue
+		}
+		mod, err := LoadModPlugin(filepath.Join(dir, entry.Name()))
+		if err == nil {
+			mod.Permissions = mod.Permissions.Restrict(allowed)
+			err = w.RegisterMod(mod)
+		}
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		loaded = append(loaded, mod.Name)
+	}
+	if len(failures) > 0 {
+		return loaded, fmt.Errorf("%d mods failed to load: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return loaded, nil
+}
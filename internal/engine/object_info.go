@@ -0,0 +1,125 @@
+package engine
+
+// Knowledge is how much a player knows of a unit or building
+type Knowledge int
+
+const (
+	KnowledgeNone Knowledge = iota // Out of sight of an enemy: nothing is known
+	KnowledgeSeen                  // An enemy's in sight: type, owner, health and combat figures
+	KnowledgeFull                  // The player's own or an ally's: also what it is doing
+)
+
+// ObjectInfo is what a player knows of a unit or building, as hover popups
+// and the unit panel show it. Fields beyond Knowledge are zero when nothing
+// is known, and Activity is empty unless everything is.
+type ObjectInfo struct {
+	ID           int
+	PlayerID     int
+	Name         string // Unit or building type
+	Owner        string // Owner's player name
+	Building     bool
+	Knowledge    Knowledge
+	Controlled   bool // Whether the player can order it, i.e. owns it
+	Health       int
+	MaxHealth    int
+	Armor        int
+	AttackDamage int
+	AttackRange  float32 // Tiles
+	AttackSpeed  float32 // Attacks per second
+	Activity     string  // E.g. "Moving" or "Producing archer"
+}
+
+// UnitInfo returns what a player knows of a unit
+func (w *World) UnitInfo(viewerID int, unit *GameUnit) ObjectInfo {
+	unit.mutex.RLock()
+	defer unit.mutex.RUnlock()
+
+	knowledge := w.knowledgeOf(viewerID, unit.PlayerID, unit.Position)
+	if knowledge == KnowledgeSeen && unit.GarrisonedIn != 0 {
+		knowledge = KnowledgeNone // Enemies cannot see into buildings
+	}
+	if knowledge == KnowledgeNone {
+		return ObjectInfo{}
+	}
+	info := ObjectInfo{
+		ID:           unit.ID,
+		PlayerID:     unit.PlayerID,
+		Name:         unit.UnitType,
+		Owner:        w.playerName(unit.PlayerID),
+		Knowledge:    knowledge,
+		Controlled:   unit.PlayerID == viewerID,
+		Health:       unit.Health,
+		MaxHealth:    unit.MaxHealth,
+		Armor:        unit.Armor,
+		AttackDamage: unit.AttackDamage,
+		AttackRange:  unit.AttackRange,
+		AttackSpeed:  unit.AttackSpeed,
+	}
+	if knowledge == KnowledgeFull {
+		info.Activity = unit.State.String()
+	}
+	return info
+}
+
+// BuildingInfo returns what a player knows of a building
+func (w *World) BuildingInfo(viewerID int, building *GameBuilding) ObjectInfo {
+	building.mutex.RLock()
+	knowledge := w.knowledgeOf(viewerID, building.PlayerID, building.Position)
+	if knowledge == KnowledgeNone {
+		building.mutex.RUnlock()
+		return ObjectInfo{}
+	}
+	info := ObjectInfo{
+		ID:           building.ID,
+		PlayerID:     building.PlayerID,
+		Name:         building.BuildingType,
+		Owner:        w.playerName(building.PlayerID),
+		Building:     true,
+		Knowledge:    knowledge,
+		Controlled:   building.PlayerID == viewerID,
+		Health:       building.Health,
+		MaxHealth:    building.MaxHealth,
+		Armor:        building.Armor,
+		AttackDamage: building.AttackDamage,
+		AttackRange:  building.AttackRange,
+		AttackSpeed:  building.AttackSpeed,
+	}
+	built := building.IsBuilt
+	building.mutex.RUnlock()
+
+	if knowledge == KnowledgeFull {
+		switch production := building.Production(); {
+		case !built:
+			info.Activity = "Under construction"
+		case production.Current != "":
+			info.Activity = "Producing " + production.Current
+		case production.Upgrade != "":
+			info.Activity = "Upgrading " + production.Upgrade
+		default:
+			info.Activity = "Idle"
+		}
+	}
+	return info
+}
+
+// knowledgeOf returns how much a player knows of an object of an owner at a
+// position: everything of its own and its allies', what can be seen of
+// enemies in its sight, and nothing of the others
+func (w *World) knowledgeOf(viewerID, ownerID int, position Vector3) Knowledge {
+	switch {
+	case ownerID == viewerID || w.AreAllied(viewerID, ownerID):
+		return KnowledgeFull
+	case w.CanSee(viewerID, position):
+		return KnowledgeSeen
+	default:
+		return KnowledgeNone
+	}
+}
+
+// playerName returns a player's name, or "" for an unknown player
+func (w *World) playerName(playerID int) string {
+	if player := w.GetPlayer(playerID); player != nil {
+		return player.Name
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestObjectInfo tests that players know everything of their own units,
+// what they see of enemies in sight, and nothing of enemies out of sight
+func TestObjectInfo(t *testing.T) {
+	world, err := NewHeadlessWorld(48, 48)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	def := data.NewSimpleUnit("archer", 80, 0, "leather", nil)
+	create := func(playerID int, x float64) *GameUnit {
+		unit, err := world.ObjectManager.CreateUnit(playerID, "archer", Vector3{X: x, Z: 5.5}, def)
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		unit.AttackDamage = 8
+		return unit
+	}
+	own, nearEnemy, farEnemy := create(1, 5.5), create(2, 8.5), create(2, 40.5)
+
+	info := world.UnitInfo(1, own)
+	if info.Knowledge != KnowledgeFull || !info.Controlled || info.Activity != "Idle" || info.Owner != "Player 1" {
+		t.Errorf("Expected full knowledge of an own unit, got %+v", info)
+	}
+	info = world.UnitInfo(1, nearEnemy)
+	if info.Knowledge != KnowledgeSeen || info.Controlled || info.Health != 80 || info.AttackDamage != 8 || info.Activity != "" {
+		t.Errorf("Expected an enemy in sight seen without its activity, got %+v", info)
+	}
+	if info := world.UnitInfo(1, farEnemy); info != (ObjectInfo{}) {
+		t.Errorf("Expected nothing known of an enemy out of sight, got %+v", info)
+	}
+
+	building, err := world.ObjectManager.CreateBuilding(2, "barracks", Vector3{X: 10.5, Z: 5.5}, data.NewSimpleUnit("barracks", 1000, 0, "stone", nil))
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	info = world.BuildingInfo(1, building)
+	if info.Knowledge != KnowledgeSeen || !info.Building || info.Name != "barracks" || info.Activity != "" {
+		t.Errorf("Expected an enemy building in sight seen, got %+v", info)
+	}
+	if info := world.BuildingInfo(2, building); info.Activity == "" || !info.Controlled {
+		t.Errorf("Expected the owner to see what its building does, got %+v", info)
+	}
+}
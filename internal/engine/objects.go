@@ -43,6 +43,7 @@ type GameBuilding struct {
 	Health       int                 `json:"health"`
 	MaxHealth    int                 `json:"max_health"`
 	Armor        int                 `json:"armor"`
+	ArmorType    string              `json:"armor_type"` // From the XML armor-type, "stone" when unset
 
 	// Construction lifecycle
 	IsBuilt         bool              `json:"is_built"`
@@ -66,6 +67,32 @@ type GameBuilding struct {
 	UpgradeProgress float32               `json:"upgrade_progress"`
 	CurrentUpgrade  *UpgradeItem          `json:"current_upgrade"`
 
+	// Rally, garrison and automation
+	RallyPoint       *Vector3             `json:"rally_point"`       // Where produced units move after spawning
+	RallyResource    *ResourceNode        `json:"rally_resource"`    // Resource produced units gather from (overrides RallyPoint)
+	GarrisonedUnits  []int                `json:"garrisoned_units"`  // IDs of units sheltered inside the building
+	GarrisonCapacity int                  `json:"garrison_capacity"` // Maximum garrisoned units (0 = cannot garrison)
+	AutoProduction   bool                 `json:"auto_production"`   // Re-queue the last produced unit when the queue empties
+	LastProduced     *ProductionItem      `json:"last_produced"`     // Template for auto-production
+	JoinControlGroup int                  `json:"join_control_group"` // Control group produced units join (0 = none)
+	JoinArmyGroup    int                  `json:"join_army_group"`    // Army group produced units join and move to (0 = none)
+
+	// Power requirement (see building_power.go)
+	RequiredWorkers int                   `json:"required_workers"` // Garrisoned workers needed to function
+	EnergyUpkeep    int                   `json:"energy_upkeep"`    // Energy paid per minute to function
+	Powered         bool                  `json:"powered"`          // Whether the requirements are met
+	PowerShortage   string                `json:"power_shortage"`   // What is missing while unpowered
+	UnpoweredSince  time.Time             `json:"unpowered_since"`  // When production paused
+	energyOwed      float64               // Upkeep accrued but not yet paid
+
+	// Defensive attack (see towers.go)
+	AttackDamage    int                   `json:"attack_damage"`    // Damage per shot (0 = cannot attack)
+	AttackRange     float32               `json:"attack_range"`     // Reach in tiles
+	AttackSpeed     float32               `json:"attack_speed"`     // Shots per second with an empty garrison
+	AttackType      string                `json:"attack_type"`      // Tech tree attack type, e.g. "piercing"
+	AttackTargetID  int                   `json:"attack_target_id"` // Unit being fired at (0 = none)
+	LastAttackTime  time.Time             `json:"last_attack_time"` // When the last shot was fired
+
 	// Building definition data
 	UnitDef      *data.UnitDefinition     `json:"-"`
 
@@ -154,6 +181,7 @@ func (om *ObjectManager) CreateBuilding(playerID int, buildingType string, posit
 		Health:          unitDef.Unit.Parameters.MaxHP.Value,
 		MaxHealth:       unitDef.Unit.Parameters.MaxHP.Value,
 		Armor:           unitDef.Unit.Parameters.Armor.Value,
+		ArmorType:       unitDef.Unit.Parameters.ArmorType.Value,
 		IsBuilt:         false,
 		BuildProgress:   0.0,
 		ConstructionTime: 30 * time.Second, // Default construction time
@@ -164,9 +192,44 @@ func (om *ObjectManager) CreateBuilding(playerID int, buildingType string, posit
 		LastResourceGen: time.Now(),
 		UpgradeLevel:    1,
 		MaxUpgradeLevel: 3,
+		GarrisonedUnits: make([]int, 0),
+		Powered:         true,
 		UnitDef:         unitDef,
 	}
 
+	if building.ArmorType == "" {
+		building.ArmorType = defaultBuildingArmorType
+	}
+
+	// Buildings shelter as many units as their definition gives room for
+	if garrison := unitDef.Unit.Parameters.Garrison; garrison != nil {
+		building.GarrisonCapacity = garrison.Capacity
+	}
+
+	// Buildings that need workers must have room to garrison them
+	if power := unitDef.Unit.Parameters.PowerRequirement; power != nil {
+		building.RequiredWorkers = power.Workers
+		building.EnergyUpkeep = power.Energy
+		if building.GarrisonCapacity < power.Workers {
+			building.GarrisonCapacity = power.Workers
+		}
+	}
+
+	// Buildings with an attack skill defend themselves
+	if skill := unitDef.AttackSkill(); skill != nil {
+		building.AttackDamage = skill.AttackStrength.Value
+		building.AttackSpeed = float32(skill.AttacksPerSecond())
+		if building.AttackSpeed <= 0 {
+			building.AttackSpeed = 1.0
+		}
+		if skill.AttackRange != nil {
+			building.AttackRange = float32(skill.AttackRange.Value)
+		}
+		if skill.AttackType != nil {
+			building.AttackType = skill.AttackType.Value
+		}
+	}
+
 	// Set default resource generation for certain building types
 	switch buildingType {
 	case "mage_tower", "energy_source":
@@ -204,6 +267,20 @@ func (om *ObjectManager) GetUnitsForPlayer(playerID int) map[int]*GameUnit {
 	return om.UnitManager.GetUnitsForPlayer(playerID)
 }
 
+// NextBuildingID returns the ID the next created building will get
+func (om *ObjectManager) NextBuildingID() int {
+	om.mutex.RLock()
+	defer om.mutex.RUnlock()
+	return om.nextID
+}
+
+// setNextBuildingID sets the ID the next created building will get (used when restoring saves)
+func (om *ObjectManager) setNextBuildingID(id int) {
+	om.mutex.Lock()
+	defer om.mutex.Unlock()
+	om.nextID = id
+}
+
 // GetBuildingsForPlayer returns all buildings owned by a player
 func (om *ObjectManager) GetBuildingsForPlayer(playerID int) map[int]*GameBuilding {
 	om.mutex.RLock()
@@ -220,7 +297,11 @@ func (om *ObjectManager) GetBuildingsForPlayer(playerID int) map[int]*GameBuildi
 
 // CreateUnit creates a new game unit (delegates to UnitManager)
 func (om *ObjectManager) CreateUnit(playerID int, unitType string, position Vector3, unitDef *data.UnitDefinition) (*GameUnit, error) {
-	return om.UnitManager.CreateUnit(playerID, unitType, position, unitDef)
+	unit, err := om.UnitManager.CreateUnit(playerID, unitType, position, unitDef)
+	if err == nil {
+		om.UnitManager.world.modsUnitCreated(unit)
+	}
+	return unit, err
 }
 
 // RemoveUnit removes a unit from the game (delegates to UnitManager)
@@ -253,6 +334,26 @@ func (om *ObjectManager) RemoveBuilding(buildingID int) error {
 	return nil
 }
 
+// transferBuilding hands a building to another player
+func (om *ObjectManager) transferBuilding(buildingID, playerID int) error {
+	om.mutex.Lock()
+	defer om.mutex.Unlock()
+
+	building, exists := om.buildings[buildingID]
+	if !exists {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+	if playerBuildings, exists := om.buildingsByPlayer[building.PlayerID]; exists {
+		delete(playerBuildings, buildingID)
+	}
+	if om.buildingsByPlayer[playerID] == nil {
+		om.buildingsByPlayer[playerID] = make(map[int]*GameBuilding)
+	}
+	om.buildingsByPlayer[playerID][buildingID] = building
+	building.PlayerID = playerID
+	return nil
+}
+
 // Update updates all game objects
 func (om *ObjectManager) Update(deltaTime time.Duration) {
 	// Update units through UnitManager
@@ -368,15 +469,10 @@ func (b *GameBuilding) Update(deltaTime time.Duration) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
-	// Update construction progress
+	// Update construction progress; production and upgrades of finished
+	// buildings are driven by the ProductionSystem
 	if !b.IsBuilt {
 		b.updateConstruction(deltaTime)
-	} else {
-		// Update production
-		b.updateProduction(deltaTime)
-
-		// Update upgrades
-		b.updateUpgrades(deltaTime)
 	}
 }
 
@@ -416,36 +512,4 @@ func (b *GameBuilding) updateConstruction(deltaTime time.Duration) {
 		b.IsBuilt = true
 		b.CompletionTime = time.Now()
 	}
-}
-
-func (b *GameBuilding) updateProduction(deltaTime time.Duration) {
-	if b.CurrentProduction == nil && len(b.ProductionQueue) > 0 {
-		// Start next production item
-		b.CurrentProduction = &b.ProductionQueue[0]
-		b.ProductionQueue = b.ProductionQueue[1:]
-	}
-
-	if b.CurrentProduction != nil {
-		progressRate := 1.0 / b.CurrentProduction.Duration.Seconds()
-		b.CurrentProduction.Progress += float32(progressRate * deltaTime.Seconds())
-
-		if b.CurrentProduction.Progress >= 1.0 {
-			// Production complete
-			// Would create new unit or trigger completion event here
-			b.CurrentProduction = nil
-		}
-	}
-}
-
-func (b *GameBuilding) updateUpgrades(deltaTime time.Duration) {
-	if b.CurrentUpgrade != nil {
-		progressRate := 1.0 / b.CurrentUpgrade.Duration.Seconds()
-		b.CurrentUpgrade.Progress += float32(progressRate * deltaTime.Seconds())
-
-		if b.CurrentUpgrade.Progress >= 1.0 {
-			// Upgrade complete
-			b.UpgradeLevel++
-			b.CurrentUpgrade = nil
-		}
-	}
 }
\ No newline at end of file
@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"sort"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// Observer statistics
+const (
+	actionMergeWindow = 50 * time.Millisecond // Commands given this close together are one action
+	apmWindow         = time.Minute           // Game time the APM meter averages over
+	maxValueSamples   = 7200                  // Value samples kept per player (two hours at one per second)
+)
+
+// ValueSample is one sample of a player's army and economy for the observer
+// graphs. Values are the resource cost of what the player has standing.
+type ValueSample struct {
+	GameTime time.Duration
+	Army     int // Cost of the fighting units alive
+	Economy  int // Cost of the workers and other unarmed units alive and the finished buildings
+	Actions  int // Actions taken since the match started
+}
+
+// definitionValue returns the resources a unit or building costs to make
+func definitionValue(def *data.UnitDefinition) int {
+	if def == nil {
+		return 0
+	}
+	value := 0
+	for _, requirement := range def.Unit.Parameters.ResourceRequirements {
+		if requirement.Amount > 0 {
+			value += requirement.Amount
+		}
+	}
+	return value
+}
+
+// ValueHistory returns a player's army and economy samples, oldest first
+func (sr *StatsRecorder) ValueHistory(playerID int) []ValueSample {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+	return append([]ValueSample(nil), sr.history[playerID]...)
+}
+
+// APM returns a player's actions per minute over the last minute of game
+// time sampled, 0 before two samples span any time
+func (sr *StatsRecorder) APM(playerID int) float64 {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+
+	history := sr.history[playerID]
+	if len(history) < 2 {
+		return 0
+	}
+	last := history[len(history)-1]
+	first := sort.Search(len(history), func(i int) bool { return history[i].GameTime >= last.GameTime-apmWindow })
+	span := last.GameTime - history[first].GameTime
+	if span <= 0 {
+		return 0
+	}
+	return float64(last.Actions-history[first].Actions) / span.Minutes()
+}
+
+// recordValues appends a value sample for a player (lock must be held)
+func (sr *StatsRecorder) recordValues(playerID int, sample ValueSample) {
+	if sr.history == nil {
+		sr.history = make(map[int][]ValueSample)
+	}
+	history := append(sr.history[playerID], sample)
+	if len(history) > maxValueSamples {
+		history = history[len(history)-maxValueSamples:]
+	}
+	sr.history[playerID] = history
+}
+
+// BuildingProduction is what one building is making and has queued
+type BuildingProduction struct {
+	BuildingID   int
+	BuildingType string
+	Current      string   // Item in production, "" when idle
+	Progress     float32  // Progress of the current item, 0.0-1.0
+	Queued       []string // Items waiting, in order
+	Upgrade      string   // Building upgrade in progress, "" when none
+}
+
+// ObserverView shows spectators and post-game review what every player is
+// doing: their production queues, army and economy graphs and APM. It reads
+// the whole world regardless of fog of war, so it must never be handed to
+// someone playing the match.
+type ObserverView struct {
+	world    *World
+	recorder *StatsRecorder
+}
+
+// NewObserverView creates an observer view of a world and its stats recorder
+func NewObserverView(world *World, recorder *StatsRecorder) *ObserverView {
+	return &ObserverView{world: world, recorder: recorder}
+}
+
+// Players returns the IDs of all players, sorted
+func (ov *ObserverView) Players() []int {
+	players := ov.world.GetAllPlayers()
+	ids := make([]int, 0, len(players))
+	for id := range players {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// PlayerName returns a player's display name
+func (ov *ObserverView) PlayerName(playerID int) string {
+	if player := ov.world.GetPlayer(playerID); player != nil {
+		return player.Name
+	}
+	return ""
+}
+
+// Production returns the buildings of a player that are making something,
+// sorted by ID
+func (ov *ObserverView) Production(playerID int) []BuildingProduction {
+	buildings := ov.world.ObjectManager.GetBuildingsForPlayer(playerID)
+	ids := make([]int, 0, len(buildings))
+	for id := range buildings {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var production []BuildingProduction
+	for _, id := range ids {
+		if entry := buildings[id].Production(); entry.Current != "" || len(entry.Queued) > 0 || entry.Upgrade != "" {
+			production = append(production, entry)
+		}
+	}
+	return production
+}
+
+// Production returns what a building is making and has queued
+func (b *GameBuilding) Production() BuildingProduction {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	entry := BuildingProduction{BuildingID: b.ID, BuildingType: b.BuildingType}
+	if current := b.CurrentProduction; current != nil {
+		entry.Current, entry.Progress = current.ItemName, current.Progress
+	}
+	for _, item := range b.ProductionQueue {
+		entry.Queued = append(entry.Queued, item.ItemName)
+	}
+	if b.CurrentUpgrade != nil {
+		entry.Upgrade = b.CurrentUpgrade.UpgradeName
+	}
+	return entry
+}
+
+// Values returns a player's army and economy samples, oldest first
+func (ov *ObserverView) Values(playerID int) []ValueSample {
+	return ov.recorder.ValueHistory(playerID)
+}
+
+// APM returns a player's actions per minute over the last minute
+func (ov *ObserverView) APM(playerID int) float64 {
+	return ov.recorder.APM(playerID)
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestObserverView tests the observer's production tab, army and economy
+// values and APM meter
+func TestObserverView(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+	recorder := NewStatsRecorder(world)
+	observer := NewObserverView(world, recorder)
+
+	worker, err := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 2.5, Z: 2.5}, data.NewSimpleUnit("worker", 50, 0, "leather", map[string]int{"gold": 50}))
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	soldier, err := world.ObjectManager.CreateUnit(2, "soldier", Vector3{X: 20.5, Z: 20.5}, data.NewSimpleUnit("soldier", 100, 0, "leather", map[string]int{"gold": 75, "wood": 25}))
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	soldier.AttackDamage = 10
+	castle, err := world.ObjectManager.CreateBuilding(2, "castle", Vector3{X: 25.5, Z: 25.5}, data.NewSimpleUnit("castle", 2000, 0, "stone", map[string]int{"stone": 300}))
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	castle.IsBuilt = true
+	castle.CurrentProduction = &ProductionItem{ItemName: "soldier", Progress: 0.5}
+	castle.ProductionQueue = []ProductionItem{{ItemName: "archer"}, {ItemName: "soldier"}}
+
+	// Player 2's queues are visible though player 1 cannot see the castle
+	production := observer.Production(2)
+	if len(production) != 1 || production[0].Current != "soldier" || len(production[0].Queued) != 2 || production[0].Queued[0] != "archer" {
+		t.Errorf("Expected the castle's queue in the production tab, got %+v", production)
+	}
+	if production := observer.Production(1); len(production) != 0 {
+		t.Errorf("Expected nothing in production for player 1, got %+v", production)
+	}
+
+	recorder.Sample()
+	values := observer.Values(2)
+	if len(values) != 1 || values[0].Army != 100 || values[0].Economy != 300 {
+		t.Errorf("Expected the soldier as army and the castle as economy, got %+v", values)
+	}
+	if values := observer.Values(1); len(values) != 1 || values[0].Army != 0 || values[0].Economy != 50 {
+		t.Errorf("Expected the worker as economy, got %+v", values)
+	}
+
+	// Orders given together count once; thirty seconds of orders every second make 60 APM
+	for i := 0; i < 30; i++ {
+		for repeat := 0; repeat < 3; repeat++ {
+			if err := world.commandProcessor.IssueCommand(worker.ID, CreateMoveCommand(Vector3{X: 5.5, Z: float64(i%20) + 2.5}, false)); err != nil {
+				t.Fatalf("IssueCommand failed: %v", err)
+			}
+		}
+		clock.Advance(time.Second)
+		world.gameTime += time.Second
+		recorder.Sample()
+	}
+	if apm := observer.APM(1); apm < 59 || apm > 61 {
+		t.Errorf("Expected about 60 APM, got %.1f", apm)
+	}
+	if apm := observer.APM(2); apm != 0 {
+		t.Errorf("Expected no APM for a player who gave no orders, got %.1f", apm)
+	}
+	if world.commandProcessor.IssueCommand(-1, CreateStopCommand()) == nil {
+		t.Fatal("Expected a command to a missing unit to fail")
+	}
+	if actions := world.commandLog.actionCount(1); actions != 30 {
+		t.Errorf("Expected 30 actions, got %d", actions)
+	}
+}
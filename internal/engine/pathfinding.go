@@ -4,6 +4,7 @@ import (
 	"container/heap"
 	"fmt"
 	"math"
+	"unsafe"
 )
 
 // PathNode represents a node in the A* pathfinding algorithm
@@ -50,6 +51,9 @@ type PathRequest struct {
 	UnitSize   int     // Size of the unit (for collision detection)
 	MaxRange   float32 // Maximum search range (0 = unlimited)
 	AllowPartial bool  // Allow partial paths when target unreachable
+	PlayerID     int   // Player whose units move, for passing its gates (0 = none)
+	PassEnemyWalls bool // Treat enemy walls and gates as open, to find the walls blocking an attack
+	IgnoreHazards  bool // Cross hazards as if they were safe ground, when ordered through them
 }
 
 // PathResult contains the result of pathfinding
@@ -164,7 +168,7 @@ func (pf *Pathfinder) exploreNeighbors(currentNode *PathNode, request PathReques
 		}
 
 		// Check if position is walkable for unit
-		if !pf.isWalkable(neighborX, neighborY, request.UnitSize) {
+		if !pf.isPassable(neighborX, neighborY, request) {
 			continue
 		}
 
@@ -175,8 +179,8 @@ func (pf *Pathfinder) exploreNeighbors(currentNode *PathNode, request PathReques
 			movementCost = float32(math.Sqrt2) // ~1.414 for diagonal movement
 		}
 
-		// Apply terrain cost modifiers
-		terrainCost := pf.getTerrainCost(neighborX, neighborY)
+		// Apply terrain cost modifiers; hazards are avoided unless ordered through
+		terrainCost := pf.getTerrainCost(neighborX, neighborY) + pf.getHazardCost(neighborX, neighborY, request)
 		movementCost *= terrainCost
 
 		newGCost := currentNode.GCost + movementCost
@@ -246,10 +250,12 @@ func (pf *Pathfinder) findPartialPath(request PathRequest) PathResult {
 	var bestNode *PathNode
 	bestDistance := float32(math.MaxFloat32)
 
-	// Find the closed node closest to target
+	// Find the closed node closest to target; ties go to the lowest
+	// coordinates so every machine picks the same node whatever the map order
 	for _, node := range pf.closedSet {
 		distance := pf.heuristic(GridPosition{Grid: Vector2i{X: node.X, Y: node.Y}}, request.Target)
-		if distance < bestDistance {
+		if distance < bestDistance || (distance == bestDistance &&
+			pf.packCoordinates(node.X, node.Y) < pf.packCoordinates(bestNode.X, bestNode.Y)) {
 			bestDistance = distance
 			bestNode = node
 		}
@@ -286,26 +292,35 @@ func (pf *Pathfinder) isValidPosition(pos GridPosition) bool {
 		   pos.Grid.Y >= 0 && pos.Grid.Y < pf.world.TerrainMap.Height
 }
 
-// isWalkable checks if a position is walkable for a unit of given size
-func (pf *Pathfinder) isWalkable(x, y, unitSize int) bool {
+// isWalkable checks if a position is walkable for a unit of given size,
+// whose body covers the square of that size around it; tiles the unit covers
+// where it starts are its own and only need walkable ground
+func (pf *Pathfinder) isWalkable(x, y int, request PathRequest) bool {
 	// Check all grid cells that the unit would occupy
-	for dx := 0; dx < unitSize; dx++ {
-		for dy := 0; dy < unitSize; dy++ {
-			checkX := x + dx
-			checkY := y + dy
+	low, high := footprintBounds(Vector2i{X: x, Y: y}, request.UnitSize)
+	for checkY := low.Y; checkY <= high.Y; checkY++ {
+		for checkX := low.X; checkX <= high.X; checkX++ {
+			cell := Vector2i{X: checkX, Y: checkY}
 
 			// Check bounds
-			if !pf.isValidPosition(GridPosition{Grid: Vector2i{X: checkX, Y: checkY}}) {
+			if !pf.isValidPosition(GridPosition{Grid: cell}) {
 				return false
 			}
 
+			if request.UnitSize > 1 && inFootprint(cell, request.Start.Grid, request.UnitSize) {
+				if !pf.world.groundWalkable(cell) {
+					return false
+				}
+				continue
+			}
+
 			// Check terrain walkability
-			if !pf.world.IsWalkable(GridPosition{Grid: Vector2i{X: checkX, Y: checkY}}) {
+			if !pf.world.IsWalkable(GridPosition{Grid: cell}) {
 				return false
 			}
 
 			// Check for unit/building occupation
-			if pf.world.IsOccupied(GridPosition{Grid: Vector2i{X: checkX, Y: checkY}}) {
+			if pf.world.IsOccupied(GridPosition{Grid: cell}) {
 				return false
 			}
 		}
@@ -314,6 +329,19 @@ func (pf *Pathfinder) isWalkable(x, y, unitSize int) bool {
 	return true
 }
 
+// isPassable checks whether a unit may enter a position, letting it through
+// its own and allied gates and, when asked, through enemy walls
+func (pf *Pathfinder) isPassable(x, y int, request PathRequest) bool {
+	cell := Vector2i{X: x, Y: y}
+	if request.PlayerID != 0 && pf.world.WallAt(cell) != 0 {
+		if request.PassEnemyWalls && pf.world.isEnemyWall(cell, request.PlayerID) {
+			return true
+		}
+		return pf.world.CanPass(cell, request.PlayerID)
+	}
+	return pf.isWalkable(x, y, request)
+}
+
 // getTerrainCost returns the movement cost for a terrain type
 func (pf *Pathfinder) getTerrainCost(x, y int) float32 {
 	if pf.world == nil || pf.world.TerrainMap == nil {
@@ -341,6 +369,17 @@ func (pf *Pathfinder) getTerrainCost(x, y int) float32 {
 	}
 }
 
+// getHazardCost returns the extra movement cost of a hazardous position
+func (pf *Pathfinder) getHazardCost(x, y int, request PathRequest) float32 {
+	if pf.world == nil || request.IgnoreHazards {
+		return 0
+	}
+	if hazard := pf.world.HazardAt(Vector2i{X: x, Y: y}); hazard != nil {
+		return hazard.PathCost
+	}
+	return 0
+}
+
 // gridToWorld converts grid coordinates to world coordinates
 func (pf *Pathfinder) gridToWorld(gridPos GridPosition) Vector3 {
 	// Convert grid position to world position
@@ -357,12 +396,7 @@ func (pf *Pathfinder) getNode(x, y int) *PathNode {
 	// Try to reuse a node from the pool
 	for _, node := range pf.nodePool {
 		if node.X == x && node.Y == y {
-			// Reset node state
-			node.GCost = 0
-			node.HCost = 0
-			node.FCost = 0
-			node.Parent = nil
-			node.HeapIndex = -1
+			// State was cleared by reset(); keep it intact while the node is open
 			return node
 		}
 	}
@@ -451,16 +485,61 @@ func (pm *PathfindingManager) RequestPath(unit *GameUnit, target Vector3) (*Path
 	request := PathRequest{
 		Start:        startGrid,
 		Target:       targetGrid,
-		UnitSize:     1, // Default unit size, could be read from unit properties
+		UnitSize:     unit.Footprint(),
 		MaxRange:     0, // No range limit
 		AllowPartial: true, // Allow partial paths
+		PlayerID:     unit.PlayerID,
+	}
+	if command := unit.CurrentCommand; command != nil && command.Type == CommandMove {
+		request.IgnoreHazards = command.ThroughHazards()
 	}
 
-	// Find path
+	// Find path; walks the search gives up on between regions are planned
+	// chokepoint by chokepoint instead
 	result := pm.pathfinder.FindPath(request)
+	if !result.Success || result.Partial {
+		if routed, ok := pm.findRegionPath(request); ok {
+			result = routed
+		}
+	}
 	return &result, nil
 }
 
+// findRegionPath finds a path leg by leg through the chokepoints between the
+// start's and the target's regions; false if they share a region or a leg
+// cannot be walked
+func (pm *PathfindingManager) findRegionPath(request PathRequest) (PathResult, bool) {
+	route, connected := pm.world.MapAnalysis().Route(request.Start.Grid, request.Target.Grid)
+	if !connected || len(route) == 0 {
+		return PathResult{}, false
+	}
+	waypoints := make([]GridPosition, 0, len(route)+1)
+	for _, choke := range route {
+		waypoints = append(waypoints, GridPosition{Grid: choke.Center})
+	}
+	waypoints = append(waypoints, request.Target)
+
+	path := PathResult{Success: true}
+	leg := request
+	leg.AllowPartial = false
+	for _, waypoint := range waypoints {
+		leg.Target = waypoint
+		result := pm.pathfinder.FindPath(leg)
+		if !result.Success {
+			return PathResult{}, false
+		}
+		joined := 0
+		if len(path.GridPath) > 0 {
+			joined = 1 // The leg starts where the last one ended
+		}
+		path.Path = append(path.Path, result.Path[joined:]...)
+		path.GridPath = append(path.GridPath, result.GridPath[joined:]...)
+		path.Distance += result.Distance
+		leg.Start = waypoint
+	}
+	return path, true
+}
+
 // RequestPathWithRange requests a path with a maximum range limit
 func (pm *PathfindingManager) RequestPathWithRange(unit *GameUnit, target Vector3, maxRange float32) (*PathResult, error) {
 	if unit == nil {
@@ -473,11 +552,38 @@ func (pm *PathfindingManager) RequestPathWithRange(unit *GameUnit, target Vector
 	request := PathRequest{
 		Start:        startGrid,
 		Target:       targetGrid,
-		UnitSize:     1,
+		UnitSize:     unit.Footprint(),
 		MaxRange:     maxRange,
 		AllowPartial: true,
+		PlayerID:     unit.PlayerID,
 	}
 
 	result := pm.pathfinder.FindPath(request)
 	return &result, nil
-}
\ No newline at end of file
+}
+// PathStats are the memory held by unit paths and the pathfinder
+type PathStats struct {
+	Paths     int   // Units holding a path
+	Waypoints int   // Waypoints across those paths
+	Nodes     int   // Search nodes the pathfinder keeps for reuse
+	Bytes     int64 // Estimated bytes of the waypoints and nodes
+}
+
+// PathStats returns the memory held by unit paths and the pathfinder
+func (w *World) PathStats() PathStats {
+	var stats PathStats
+	for _, player := range w.GetAllPlayers() {
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(player.ID) {
+			if len(unit.Path) > 0 {
+				stats.Paths++
+				stats.Waypoints += cap(unit.Path)
+			}
+		}
+	}
+	if w.pathfindingMgr != nil {
+		stats.Nodes = len(w.pathfindingMgr.pathfinder.nodePool)
+	}
+	stats.Bytes = int64(stats.Waypoints)*int64(unsafe.Sizeof(Vector3{})) +
+		int64(stats.Nodes)*int64(unsafe.Sizeof(PathNode{})+unsafe.Sizeof(&PathNode{}))
+	return stats
+}
@@ -460,4 +460,29 @@ func calculateDistance3D(a, b Vector3) float32 {
 	dy := a.Y - b.Y
 	dz := a.Z - b.Z
 	return float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
-}
\ No newline at end of file
+}
+// TestPathStats tests that path memory counts the units' paths and the
+// search nodes the pathfinder keeps
+func TestPathStats(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	def := data.NewSimpleUnit("worker", 50, 0, "leather", nil)
+	unit, _ := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 2, Z: 2}, def)
+	world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 4, Z: 2}, def)
+
+	if stats := world.PathStats(); stats.Paths != 0 || stats.Nodes != 0 || stats.Bytes != 0 {
+		t.Errorf("Expected no path memory before any search, got %+v", stats)
+	}
+
+	result, err := world.pathfindingMgr.RequestPath(unit, Vector3{X: 12, Z: 9})
+	if err != nil || !result.Success {
+		t.Fatalf("Expected a path, got %v", err)
+	}
+	unit.Path = result.Path
+	stats := world.PathStats()
+	if stats.Paths != 1 || stats.Waypoints < len(result.Path) || stats.Nodes == 0 || stats.Bytes == 0 {
+		t.Errorf("Expected one path and the searched nodes counted, got %+v", stats)
+	}
+}
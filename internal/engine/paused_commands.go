@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"errors"
+	"sync"
+)
+
+// heldCommand is a command issued while the game was paused
+type heldCommand struct {
+	targetID int  // Unit or building the command is for
+	building bool // Whether targetID is a building
+	command  UnitCommand
+}
+
+// commandHold keeps the commands issued while the game is paused until it
+// resumes, so a single player can give orders during a pause
+type commandHold struct {
+	mutex    sync.Mutex
+	paused   bool
+	commands []heldCommand // In the order they were issued
+}
+
+// SetPaused makes the processor hold issued commands while paused. Unpausing
+// issues the held commands in order; it returns the errors of those that
+// failed. Held commands were logged when they were given, so they are not
+// logged again when they run.
+func (cp *CommandProcessor) SetPaused(paused bool) error {
+	cp.held.mutex.Lock()
+	cp.held.paused = paused
+	var commands []heldCommand
+	if !paused {
+		commands, cp.held.commands = cp.held.commands, nil
+	}
+	cp.held.mutex.Unlock()
+
+	var errs []error
+	for _, held := range commands {
+		var err error
+		if held.building {
+			err = cp.issueBuildingCommand(held.targetID, held.command, false)
+		} else {
+			err = cp.issueCommand(held.targetID, held.command, false)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HeldCommandCount returns the number of commands waiting for the game to resume
+func (cp *CommandProcessor) HeldCommandCount() int {
+	cp.held.mutex.Lock()
+	defer cp.held.mutex.Unlock()
+	return len(cp.held.commands)
+}
+
+// dropHeldCommands forgets the commands held for a world state that is being
+// replaced, so they do not run against the objects that take their IDs
+func (cp *CommandProcessor) dropHeldCommands() {
+	cp.held.mutex.Lock()
+	defer cp.held.mutex.Unlock()
+	cp.held.commands = nil
+}
+
+// hold keeps a command for when the game resumes; it reports false when the
+// game is not paused and the command should run now
+func (cp *CommandProcessor) hold(command heldCommand) bool {
+	cp.held.mutex.Lock()
+	defer cp.held.mutex.Unlock()
+	if !cp.held.paused {
+		return false
+	}
+	cp.held.commands = append(cp.held.commands, command)
+	return true
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestCommandsHeldWhilePaused tests that commands issued during a pause run on resume
+func TestCommandsHeldWhilePaused(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 1, Z: 1}, soldier)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+
+	game := &Game{settings: GameSettings{PlayerFactions: map[int]string{1: "tech"}}, world: world}
+	game.setState(GameStatePaused)
+
+	processor := world.commandProcessor
+	if err := processor.IssueCommand(unit.ID, CreateMoveCommand(Vector3{X: 5, Z: 5}, false)); err != nil {
+		t.Fatalf("Expected the command to be accepted while paused: %v", err)
+	}
+	if err := processor.IssueCommand(unit.ID, CreateMoveCommand(Vector3{X: 8, Z: 5}, true)); err != nil {
+		t.Fatalf("Expected the queued command to be accepted while paused: %v", err)
+	}
+	if unit.CurrentCommand != nil || processor.HeldCommandCount() != 2 {
+		t.Fatalf("Expected both commands held, got current %v and %d held", unit.CurrentCommand, processor.HeldCommandCount())
+	}
+	if err := processor.IssueCommand(9999, CreateMoveCommand(Vector3{}, false)); err == nil {
+		t.Errorf("Expected a command for a missing unit to fail even while paused")
+	}
+
+	logged := len(world.commandLog.snapshot())
+
+	game.setState(GameStatePlaying)
+	if recorded := len(world.commandLog.snapshot()); recorded != logged {
+		t.Errorf("Expected held commands to be logged once, got %d records after resuming instead of %d", recorded, logged)
+	}
+	if processor.HeldCommandCount() != 0 {
+		t.Errorf("Expected no held commands after resuming, got %d", processor.HeldCommandCount())
+	}
+	if unit.CurrentCommand == nil || *unit.CurrentCommand.Target != (Vector3{X: 5, Z: 5}) || len(unit.CommandQueue) != 1 {
+		t.Errorf("Expected the commands to run in order, got current %+v and queue %+v", unit.CurrentCommand, unit.CommandQueue)
+	}
+
+	// With several human players a pause does not hold commands
+	multiplayer := &Game{settings: GameSettings{PlayerFactions: map[int]string{1: "tech", 2: "magic"}}, world: world}
+	multiplayer.setState(GameStatePaused)
+	if err := processor.IssueCommand(unit.ID, CreateMoveCommand(Vector3{X: 2, Z: 2}, false)); err != nil {
+		t.Fatalf("Failed to issue command: %v", err)
+	}
+	if processor.HeldCommandCount() != 0 || *unit.CurrentCommand.Target != (Vector3{X: 2, Z: 2}) {
+		t.Errorf("Expected the command to run at once in multiplayer")
+	}
+}
+
+// TestHeldCommandsDroppedWithWorldState tests that loading a save or ending
+// the match forgets the commands held for the replaced objects
+func TestHeldCommandsDroppedWithWorldState(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 1, Z: 1}, data.NewSimpleUnit("soldier", 100, 0, "leather", nil))
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	save := world.CaptureSaveGame()
+
+	game := &Game{settings: GameSettings{PlayerFactions: map[int]string{1: "tech"}}, world: world}
+	game.setState(GameStatePaused)
+	processor := world.commandProcessor
+	if err := processor.IssueCommand(unit.ID, CreateMoveCommand(Vector3{X: 5, Z: 5}, false)); err != nil {
+		t.Fatalf("Failed to issue command: %v", err)
+	}
+	if err := world.RestoreSaveGame(save); err != nil {
+		t.Fatalf("RestoreSaveGame failed: %v", err)
+	}
+	if processor.HeldCommandCount() != 0 {
+		t.Errorf("Expected the restore to drop the held command, got %d held", processor.HeldCommandCount())
+	}
+
+	if err := processor.IssueCommand(unit.ID, CreateMoveCommand(Vector3{X: 5, Z: 5}, false)); err != nil || processor.HeldCommandCount() != 1 {
+		t.Fatalf("Expected the command held while still paused: %v", err)
+	}
+	game.Reset()
+	if processor.HeldCommandCount() != 0 {
+		t.Errorf("Expected Reset to drop the held command, got %d held", processor.HeldCommandCount())
+	}
+}
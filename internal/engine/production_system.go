@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"teraglest/internal/data"
+	"teraglest/internal/logging"
 )
 
 // ProductionSystem manages unit production and technology research for buildings
@@ -35,7 +36,8 @@ func (ps *ProductionSystem) ProcessBuildingProduction(deltaTime time.Duration) {
 	for playerID := range ps.world.players {
 		buildings := ps.world.ObjectManager.GetBuildingsForPlayer(playerID)
 		for _, building := range buildings {
-			if building.IsBuilt {
+			// Buildings missing their workers or energy upkeep pause production
+			if building.IsBuilt && ps.updateBuildingPower(building, deltaTime) {
 				ps.processBuildingProductionQueue(building, deltaTime)
 				ps.processBuildingUpgrades(building, deltaTime)
 			}
@@ -48,11 +50,16 @@ func (ps *ProductionSystem) processBuildingProductionQueue(building *GameBuildin
 	building.mutex.Lock()
 	defer building.mutex.Unlock()
 
+	// Refill an empty queue from the last produced item when auto-production is on
+	if building.CurrentProduction == nil && len(building.ProductionQueue) == 0 && building.AutoProduction {
+		ps.requeueAutoProduction(building)
+	}
+
 	// Start next production if nothing is currently being produced
 	if building.CurrentProduction == nil && len(building.ProductionQueue) > 0 {
 		building.CurrentProduction = &building.ProductionQueue[0]
 		building.ProductionQueue = building.ProductionQueue[1:]
-		building.CurrentProduction.StartTime = time.Now()
+		building.CurrentProduction.StartTime = ps.world.now()
 	}
 
 	// Process current production
@@ -60,7 +67,7 @@ func (ps *ProductionSystem) processBuildingProductionQueue(building *GameBuildin
 		production := building.CurrentProduction
 
 		// Calculate production progress
-		elapsed := time.Since(production.StartTime)
+		elapsed := ps.world.now().Sub(production.StartTime)
 		totalDuration := production.Duration
 		production.Progress = float32(elapsed.Seconds()) / float32(totalDuration.Seconds())
 
@@ -81,11 +88,12 @@ func (ps *ProductionSystem) processBuildingUpgrades(building *GameBuilding, delt
 		upgrade := building.CurrentUpgrade
 
 		// Calculate upgrade progress
-		elapsed := time.Since(upgrade.StartTime)
+		elapsed := ps.world.now().Sub(upgrade.StartTime)
 		upgrade.Progress = float32(elapsed.Seconds()) / float32(upgrade.Duration.Seconds())
 
 		// Check if upgrade is complete
 		if upgrade.Progress >= 1.0 {
+			building.UpgradeLevel++
 			ps.applyUpgrade(building, upgrade)
 			building.CurrentUpgrade = nil
 		}
@@ -96,6 +104,8 @@ func (ps *ProductionSystem) processBuildingUpgrades(building *GameBuilding, delt
 func (ps *ProductionSystem) completeProduction(building *GameBuilding, production *ProductionItem) {
 	switch production.ItemType {
 	case "unit":
+		lastProduced := *production
+		building.LastProduced = &lastProduced
 		ps.spawnUnit(building, production)
 	case "upgrade":
 		// Convert ProductionItem to UpgradeItem for upgrade processing
@@ -122,7 +132,7 @@ func (ps *ProductionSystem) spawnUnit(building *GameBuilding, production *Produc
 		if len(production.Cost) > 0 {
 			ps.world.AddResources(building.PlayerID, production.Cost, "production_refund_population_limit")
 		}
-		fmt.Printf("Unit production failed for player %d: %s\n", building.PlayerID, reason)
+		logging.Warnf(logging.CategoryEngine, "Unit production failed for player %d: %s", building.PlayerID, reason)
 		return
 	}
 
@@ -155,6 +165,11 @@ func (ps *ProductionSystem) spawnUnit(building *GameBuilding, production *Produc
 	// Unit creation successful - population tracking is handled by existing systems
 	// The PopulationManager will query units when needed rather than tracking directly
 
+	// Send the new unit to the building's rally point or gather point, or
+	// into the army group it reinforces
+	ps.applyRallyPoint(building, unit)
+	ps.applyProductionGroup(building, unit)
+
 	// Emit production complete event
 	ps.emitProductionEvent(building, production, unit.ID)
 }
@@ -305,7 +320,7 @@ func (ps *ProductionSystem) processUnitConstruction(unit *GameUnit, deltaTime ti
 	if building.BuildProgress >= 1.0 {
 		building.BuildProgress = 1.0
 		building.IsBuilt = true
-		building.CompletionTime = time.Now()
+		building.CompletionTime = ps.world.now()
 
 		// Construction complete - worker becomes idle
 		unit.BuildTarget = nil
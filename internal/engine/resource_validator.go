@@ -47,6 +47,11 @@ func (rv *ResourceValidator) ValidateResources(check ResourceCheck) ValidationRe
 		}
 	}
 
+	return validatePlayerResources(player, check)
+}
+
+// validatePlayerResources checks a player's resources without taking the world lock
+func validatePlayerResources(player *Player, check ResourceCheck) ValidationResult {
 	// Check each required resource
 	missing := make(map[string]int)
 	for resourceType, requiredAmount := range check.Required {
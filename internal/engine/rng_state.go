@@ -0,0 +1,48 @@
+package engine
+
+import "math/rand"
+
+// RNGState records where a seeded random generator stands: its seed and the
+// number of values drawn from it since. Replaying the draws restores it.
+type RNGState struct {
+	Seed  int64  `json:"seed"`
+	Draws uint64 `json:"draws"`
+}
+
+// countingSource is a random source that counts its draws, so a generator
+// built on it can be saved as an RNGState
+type countingSource struct {
+	source rand.Source64
+	state  RNGState
+}
+
+// newCountingSource creates a counting source with the given seed
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{
+		source: rand.NewSource(seed).(rand.Source64),
+		state:  RNGState{Seed: seed},
+	}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.state.Draws++
+	return s.source.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.state.Draws++
+	return s.source.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.source.Seed(seed)
+	s.state = RNGState{Seed: seed}
+}
+
+// restore reseeds the source and replays draws up to a saved state
+func (s *countingSource) restore(state RNGState) {
+	s.Seed(state.Seed)
+	for s.state.Draws < state.Draws {
+		s.Int63()
+	}
+}
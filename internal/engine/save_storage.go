@@ -0,0 +1,318 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Savegames and replays are stored in a small container around their JSON:
+//
+//	magic "TGSF" | container version (1 byte) | compression (1 byte)
+//	payload, compressed as the header says
+//	trailer: CRC-32C (4 bytes) and length (8 bytes) of the uncompressed payload
+//
+// The payload is streamed in both directions, so long replays are never held
+// in memory as bytes. Files without the magic are plain JSON from before the
+// container and are still read, without an integrity check.
+
+// saveFileMagic starts every container file
+var saveFileMagic = []byte("TGSF")
+
+// SaveFileFormatVersion is the version of the container layout
+const SaveFileFormatVersion = 1
+
+const (
+	saveFileHeaderSize  = 6
+	saveFileTrailerSize = 12
+)
+
+// saveFileTable is the CRC-32C table of the integrity checksums
+var saveFileTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrSaveFileCorrupt is returned when a save file fails its integrity check
+var ErrSaveFileCorrupt = errors.New("save file is corrupt")
+
+// SaveCompression is the compression of a save file's payload
+type SaveCompression byte
+
+const (
+	SaveCompressionNone SaveCompression = iota // Plain JSON payload
+	SaveCompressionGzip                        // Gzip-compressed payload
+)
+
+// DefaultSaveCompression is used by WriteSaveGame and replay writers
+const DefaultSaveCompression = SaveCompressionGzip
+
+// String returns the string representation of a SaveCompression
+func (c SaveCompression) String() string {
+	switch c {
+	case SaveCompressionNone:
+		return "none"
+	case SaveCompressionGzip:
+		return "gzip"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParseSaveCompression parses a compression name as printed by String
+func ParseSaveCompression(name string) (SaveCompression, error) {
+	switch strings.ToLower(name) {
+	case "none", "":
+		return SaveCompressionNone, nil
+	case "gzip":
+		return SaveCompressionGzip, nil
+	default:
+		return 0, fmt.Errorf("unknown save compression %q (valid: none, gzip)", name)
+	}
+}
+
+// saveFileWriter streams a payload into a container
+type saveFileWriter struct {
+	out        io.Writer
+	compressor io.WriteCloser // nil for uncompressed payloads
+	checksum   hash.Hash32
+	length     uint64
+}
+
+// NewSaveFileWriter writes a container header to w and returns a writer for
+// the payload. Closing it finishes the payload and writes the trailer; it
+// does not close w.
+func NewSaveFileWriter(w io.Writer, compression SaveCompression) (io.WriteCloser, error) {
+	sw := &saveFileWriter{out: w, checksum: crc32.New(saveFileTable)}
+	switch compression {
+	case SaveCompressionNone:
+	case SaveCompressionGzip:
+		sw.compressor = gzip.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported save compression %d", compression)
+	}
+
+	header := append(append([]byte{}, saveFileMagic...), SaveFileFormatVersion, byte(compression))
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+func (sw *saveFileWriter) Write(p []byte) (int, error) {
+	var n int
+	var err error
+	if sw.compressor != nil {
+		n, err = sw.compressor.Write(p)
+	} else {
+		n, err = sw.out.Write(p)
+	}
+	sw.checksum.Write(p[:n])
+	sw.length += uint64(n)
+	return n, err
+}
+
+func (sw *saveFileWriter) Close() error {
+	if sw.compressor != nil {
+		if err := sw.compressor.Close(); err != nil {
+			return err
+		}
+	}
+	var trailer [saveFileTrailerSize]byte
+	binary.LittleEndian.PutUint32(trailer[:4], sw.checksum.Sum32())
+	binary.LittleEndian.PutUint64(trailer[4:], sw.length)
+	_, err := sw.out.Write(trailer[:])
+	return err
+}
+
+// SaveFileReader streams the payload out of a container, checking its
+// integrity when the payload ends
+type SaveFileReader struct {
+	Compression SaveCompression // Compression the file was written with
+	Legacy      bool            // Plain JSON file from before the container
+
+	in       *bufio.Reader
+	payload  io.Reader
+	checksum hash.Hash32
+	length   uint64
+	err      error // Sticky end of payload: io.EOF, or the integrity failure
+}
+
+// NewSaveFileReader reads a container header from r. Legacy plain JSON files
+// are passed through unchanged.
+func NewSaveFileReader(r io.Reader) (*SaveFileReader, error) {
+	in := bufio.NewReader(r)
+	sr := &SaveFileReader{in: in, checksum: crc32.New(saveFileTable)}
+
+	header, err := in.Peek(saveFileHeaderSize)
+	if err != nil || !bytes.Equal(header[:len(saveFileMagic)], saveFileMagic) {
+		sr.Legacy = true
+		sr.payload = in
+		return sr, nil
+	}
+	in.Discard(saveFileHeaderSize)
+	if header[4] > SaveFileFormatVersion {
+		return nil, fmt.Errorf("save file format version %d is newer than supported version %d", header[4], SaveFileFormatVersion)
+	}
+
+	sr.Compression = SaveCompression(header[5])
+	switch sr.Compression {
+	case SaveCompressionNone:
+		// The payload runs up to the trailer
+		sr.payload = &trailerStripper{in: in}
+	case SaveCompressionGzip:
+		decompressor, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSaveFileCorrupt, err)
+		}
+		decompressor.Multistream(false) // The trailer follows the gzip stream
+		sr.payload = decompressor
+	default:
+		return nil, fmt.Errorf("unsupported save compression %d", sr.Compression)
+	}
+	return sr, nil
+}
+
+// Read reads the payload. At its end the checksum and length are checked,
+// and a mismatch is reported as ErrSaveFileCorrupt.
+func (sr *SaveFileReader) Read(p []byte) (int, error) {
+	if sr.err != nil {
+		return 0, sr.err
+	}
+	n, err := sr.payload.Read(p)
+	sr.checksum.Write(p[:n])
+	sr.length += uint64(n)
+	switch {
+	case err == nil || sr.Legacy:
+	case err == io.EOF:
+		if verr := sr.verify(); verr != nil {
+			err = verr
+		}
+		sr.err = err
+	default:
+		err = fmt.Errorf("%w: %v", ErrSaveFileCorrupt, err)
+		sr.err = err
+	}
+	return n, err
+}
+
+// verify checks the trailer after the payload
+func (sr *SaveFileReader) verify() error {
+	var trailer []byte
+	if stripper, ok := sr.payload.(*trailerStripper); ok {
+		trailer = stripper.held
+	} else {
+		trailer = make([]byte, saveFileTrailerSize)
+		if _, err := io.ReadFull(sr.in, trailer); err != nil {
+			return fmt.Errorf("%w: missing trailer", ErrSaveFileCorrupt)
+		}
+	}
+	if len(trailer) != saveFileTrailerSize {
+		return fmt.Errorf("%w: missing trailer", ErrSaveFileCorrupt)
+	}
+	if binary.LittleEndian.Uint32(trailer[:4]) != sr.checksum.Sum32() ||
+		binary.LittleEndian.Uint64(trailer[4:]) != sr.length {
+		return fmt.Errorf("%w: checksum mismatch", ErrSaveFileCorrupt)
+	}
+	return nil
+}
+
+// trailerStripper reads an uncompressed payload, holding back the last
+// bytes of the stream, which are the trailer
+type trailerStripper struct {
+	in   io.Reader
+	held []byte
+	eof  bool
+}
+
+func (ts *trailerStripper) Read(p []byte) (int, error) {
+	for !ts.eof && len(ts.held) < saveFileTrailerSize+len(p) {
+		buf := make([]byte, saveFileTrailerSize+len(p)-len(ts.held))
+		n, err := ts.in.Read(buf)
+		ts.held = append(ts.held, buf[:n]...)
+		if err == io.EOF {
+			ts.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	available := len(ts.held) - saveFileTrailerSize
+	if available <= 0 {
+		if ts.eof {
+			return 0, io.EOF
+		}
+		return 0, nil
+	}
+	n := copy(p, ts.held[:available])
+	ts.held = ts.held[n:]
+	return n, nil
+}
+
+// WriteSaveFile streams a value as JSON into a container file. The file is
+// written under a temporary name and renamed, so a failed save never
+// replaces a good one.
+func WriteSaveFile(path string, compression SaveCompression, value interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create save directory: %w", err)
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create save file: %w", err)
+	}
+	defer os.Remove(file.Name()) // No-op once renamed
+
+	buffered := bufio.NewWriter(file)
+	payload, err := NewSaveFileWriter(buffered, compression)
+	if err == nil {
+		err = json.NewEncoder(payload).Encode(value)
+	}
+	if err == nil {
+		err = payload.Close()
+	}
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write save file: %w", err)
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write save file: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write save file: %w", err)
+	}
+	return nil
+}
+
+// ReadSaveFile decodes the JSON payload of a container or legacy save file
+// into value, checking the file's integrity
+func ReadSaveFile(path string, value interface{}) (SaveCompression, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader, err := NewSaveFileReader(file)
+	if err != nil {
+		return 0, err
+	}
+	if err := json.NewDecoder(reader).Decode(value); err != nil {
+		return reader.Compression, err
+	}
+	// Read to the end so the trailer is checked
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return reader.Compression, err
+	}
+	return reader.Compression, nil
+}
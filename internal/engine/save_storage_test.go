@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSaveFileStorage tests compressed and uncompressed save files, legacy
+// plain JSON files and the integrity check
+func TestSaveFileStorage(t *testing.T) {
+	dir := t.TempDir()
+	save := &SaveGame{
+		Header:     SaveGameHeader{Version: SaveGameVersion, Description: "storage"},
+		Players:    []PlayerSave{{ID: 1, Name: "Player 1", Resources: map[string]int{"gold": 100}}},
+		NextUnitID: 42,
+	}
+
+	for _, compression := range []SaveCompression{SaveCompressionNone, SaveCompressionGzip} {
+		path := filepath.Join(dir, compression.String()+SaveGameExtension)
+		if err := WriteSaveGameCompressed(path, save, compression); err != nil {
+			t.Fatalf("%v: WriteSaveGameCompressed failed: %v", compression, err)
+		}
+		loaded, err := ReadSaveGame(path)
+		if err != nil {
+			t.Fatalf("%v: ReadSaveGame failed: %v", compression, err)
+		}
+		if diffs := DiffSaveGames(save, loaded); len(diffs) != 0 {
+			t.Errorf("%v: round trip changed the save: %v", compression, diffs)
+		}
+
+		files, err := ListSaveFiles(dir, SaveFileKindSaveGame)
+		if err != nil {
+			t.Fatalf("ListSaveFiles failed: %v", err)
+		}
+		for _, file := range files {
+			if file.Path == path && (!file.Valid || file.Compression != compression || file.Header.Description != "storage") {
+				t.Errorf("%v: unexpected catalog entry %+v", compression, file)
+			}
+		}
+
+		// Any flipped byte in the payload or trailer fails the integrity check
+		content, _ := os.ReadFile(path)
+		content[len(content)-14] ^= 0xff
+		corrupt := filepath.Join(dir, "corrupt"+SaveGameExtension)
+		os.WriteFile(corrupt, content, 0644)
+		if _, err := ReadSaveGame(corrupt); err == nil {
+			t.Errorf("%v: expected an error reading a corrupt save", compression)
+		}
+		content, _ = os.ReadFile(path)
+		os.WriteFile(corrupt, content[:len(content)-4], 0644)
+		if _, err := ReadSaveGame(corrupt); !errors.Is(err, ErrSaveFileCorrupt) {
+			t.Errorf("%v: expected ErrSaveFileCorrupt for a truncated save, got %v", compression, err)
+		}
+	}
+
+	// Saves written before the container are plain JSON
+	legacy := filepath.Join(dir, "legacy"+SaveGameExtension)
+	os.WriteFile(legacy, []byte(`{"header":{"version":1,"description":"old"},"next_unit_id":7}`), 0644)
+	loaded, err := ReadSaveGame(legacy)
+	if err != nil {
+		t.Fatalf("Failed to read legacy save: %v", err)
+	}
+	if loaded.Header.Description != "old" || loaded.NextUnitID != 7 {
+		t.Errorf("Unexpected legacy save %+v", loaded)
+	}
+
+	if _, err := ParseSaveCompression("zip"); err == nil {
+		t.Error("Expected error for unknown compression")
+	}
+}
@@ -0,0 +1,484 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// SaveGameVersion is the current savegame format version
+const SaveGameVersion = 1
+
+// SaveGameHeader contains metadata describing a savegame
+type SaveGameHeader struct {
+	Version     int           `json:"version"`      // Savegame format version
+	SavedAt     time.Time     `json:"saved_at"`     // When the save was written
+	MapPath     string        `json:"map_path"`     // Map the match is played on
+	GameTime    time.Duration `json:"game_time"`    // In-game time elapsed
+	PlayerCount int           `json:"player_count"` // Number of players in the match
+	PlayerNames []string      `json:"player_names"` // Player names for display in save browsers
+	Description string        `json:"description"`  // User-facing save name
+}
+
+// SaveGame is a serializable snapshot of a match
+type SaveGame struct {
+	Header    SaveGameHeader `json:"header"`
+	Settings  GameSettings   `json:"settings"`
+	Players   []PlayerSave   `json:"players"`
+	Units     []UnitSave     `json:"units"`
+	Buildings []BuildingSave `json:"buildings"`
+	Resources []ResourceNode `json:"resources"`
+
+	// Next object IDs, so objects created after a restore get the same IDs as in the original match
+	NextUnitID     int `json:"next_unit_id,omitempty"`
+	NextBuildingID int `json:"next_building_id,omitempty"`
+
+	// Combat hit roll generator, so a restored match rolls the same as the original
+	CombatRNG *RNGState `json:"combat_rng,omitempty"`
+}
+
+// PlayerSave holds the persistent state of a player
+type PlayerSave struct {
+	ID                int            `json:"id"`
+	Name              string         `json:"name"`
+	FactionName       string         `json:"faction_name"`
+	IsAI              bool           `json:"is_ai"`
+	IsActive          bool           `json:"is_active"`
+	Team              int            `json:"team,omitempty"`
+	Resources         map[string]int `json:"resources"`
+	UnitsCreated      int            `json:"units_created"`
+	UnitsLost         int            `json:"units_lost"`
+	BuildingsBuilt    int            `json:"buildings_built"`
+	ResourcesGathered map[string]int `json:"resources_gathered"`
+	ResourcesSpent    map[string]int `json:"resources_spent"`
+	LastActive        time.Duration  `json:"last_active,omitempty"`  // Game time of the player's last order
+	Disconnected      bool           `json:"disconnected,omitempty"` // Whether the player's connection was lost
+}
+
+// UnitSave holds the persistent state of a unit
+type UnitSave struct {
+	ID               int            `json:"id"`
+	PlayerID         int            `json:"player_id"`
+	UnitType         string         `json:"unit_type"`
+	Position         Vector3        `json:"position"`
+	Rotation         float32        `json:"rotation"`
+	Health           int            `json:"health"`
+	MaxHealth        int            `json:"max_health"`
+	Energy           int            `json:"energy"`
+	MaxEnergy        int            `json:"max_energy"`
+	CarriedResources map[string]int `json:"carried_resources"`
+	GarrisonedIn     int            `json:"garrisoned_in"`
+}
+
+// BuildingSave holds the persistent state of a building
+type BuildingSave struct {
+	ID                int              `json:"id"`
+	PlayerID          int              `json:"player_id"`
+	BuildingType      string           `json:"building_type"`
+	Position          Vector3          `json:"position"`
+	Rotation          float32          `json:"rotation"`
+	Health            int              `json:"health"`
+	MaxHealth         int              `json:"max_health"`
+	IsBuilt           bool             `json:"is_built"`
+	BuildProgress     float32          `json:"build_progress"`
+	ProductionQueue   []ProductionItem `json:"production_queue"`
+	CurrentProduction *ProductionItem  `json:"current_production"`
+	UpgradeLevel      int              `json:"upgrade_level"`
+	RallyPoint        *Vector3         `json:"rally_point"`
+	GarrisonedUnits   []int            `json:"garrisoned_units"`
+	AutoProduction    bool             `json:"auto_production"`
+	LastProduced      *ProductionItem  `json:"last_produced"`
+	JoinControlGroup  int              `json:"join_control_group,omitempty"`
+}
+
+// CaptureSaveGame creates a snapshot of the current world state
+func (w *World) CaptureSaveGame() *SaveGame {
+	w.mutex.RLock()
+	save := &SaveGame{
+		Header: SaveGameHeader{
+			Version:     SaveGameVersion,
+			SavedAt:     time.Now(),
+			MapPath:     w.settings.MapPath,
+			GameTime:    w.gameTime,
+			PlayerCount: len(w.players),
+		},
+		Settings:  w.settings,
+		Players:   make([]PlayerSave, 0, len(w.players)),
+		Resources: make([]ResourceNode, 0, len(w.resources)),
+	}
+
+	// Players in ID order, so saves of the same state compare equal
+	playerIDs := sortedKeys(w.players)
+	for _, id := range playerIDs {
+		player := w.players[id]
+		save.Header.PlayerNames = append(save.Header.PlayerNames, player.Name)
+		save.Players = append(save.Players, PlayerSave{
+			ID:                player.ID,
+			Name:              player.Name,
+			FactionName:       player.FactionName,
+			IsAI:              player.IsAI,
+			IsActive:          player.IsActive,
+			Team:              player.Team,
+			Resources:         copyIntMap(player.Resources),
+			UnitsCreated:      player.UnitsCreated,
+			UnitsLost:         player.UnitsLost,
+			BuildingsBuilt:    player.BuildingsBuilt,
+			ResourcesGathered: copyIntMap(player.ResourcesGathered),
+			ResourcesSpent:    copyIntMap(player.ResourcesSpent),
+		})
+	}
+	w.inactivity.mutex.Lock()
+	for i := range save.Players {
+		save.Players[i].LastActive = w.inactivity.lastActive[save.Players[i].ID]
+		save.Players[i].Disconnected = w.inactivity.disconnected[save.Players[i].ID]
+	}
+	w.inactivity.mutex.Unlock()
+	for _, node := range w.resources {
+		save.Resources = append(save.Resources, *node)
+	}
+	w.mutex.RUnlock()
+
+	// Object managers have their own locks
+	save.NextUnitID = w.ObjectManager.UnitManager.NextID()
+	save.NextBuildingID = w.ObjectManager.NextBuildingID()
+	// Surrendered players' forces may stay on the map as neutral
+	for _, playerID := range append([]int{NeutralPlayerID}, playerIDs...) {
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(playerID) {
+			save.Units = append(save.Units, captureUnit(unit))
+		}
+		for _, building := range w.ObjectManager.GetBuildingsForPlayer(playerID) {
+			save.Buildings = append(save.Buildings, captureBuilding(building))
+		}
+	}
+	if source := w.combatRNG(); source != nil {
+		state := source.state
+		save.CombatRNG = &state
+	}
+
+	return save
+}
+
+// RestoreSaveGame replaces the world state with the contents of a savegame.
+// The simulation must not be running while the save is restored.
+func (w *World) RestoreSaveGame(save *SaveGame) error {
+	if save == nil {
+		return fmt.Errorf("savegame is nil")
+	}
+	if save.Header.Version > SaveGameVersion {
+		return fmt.Errorf("savegame version %d is newer than supported version %d", save.Header.Version, SaveGameVersion)
+	}
+
+	// Commands held during a pause were given to the objects being replaced
+	if w.commandProcessor != nil {
+		w.commandProcessor.dropHeldCommands()
+	}
+
+	// Remove existing objects, neutral ones included (these calls take the world lock themselves)
+	ownerIDs := []int{NeutralPlayerID}
+	for _, player := range w.GetPlayers() {
+		ownerIDs = append(ownerIDs, player.ID)
+	}
+	for _, ownerID := range ownerIDs {
+		for unitID := range w.ObjectManager.GetUnitsForPlayer(ownerID) {
+			w.ObjectManager.RemoveUnit(unitID)
+		}
+		for buildingID := range w.ObjectManager.GetBuildingsForPlayer(ownerID) {
+			w.ObjectManager.RemoveBuilding(buildingID)
+		}
+	}
+	w.clearWalls()
+
+	w.mutex.Lock()
+	players := make(map[int]*Player, len(save.Players))
+	for _, saved := range save.Players {
+		player := &Player{
+			ID:                saved.ID,
+			Name:              saved.Name,
+			FactionName:       saved.FactionName,
+			IsAI:              saved.IsAI,
+			IsActive:          saved.IsActive,
+			Team:              saved.Team,
+			Resources:         copyIntMap(saved.Resources),
+			UnitsCreated:      saved.UnitsCreated,
+			UnitsLost:         saved.UnitsLost,
+			BuildingsBuilt:    saved.BuildingsBuilt,
+			ResourcesGathered: copyIntMap(saved.ResourcesGathered),
+			ResourcesSpent:    copyIntMap(saved.ResourcesSpent),
+		}
+		// Keep loaded faction data when the same faction is still in play
+		if existing, ok := w.players[saved.ID]; ok && existing.FactionName == saved.FactionName {
+			player.FactionData = existing.FactionData
+		}
+		players[saved.ID] = player
+	}
+	w.players = players
+
+	w.resources = make(map[int]*ResourceNode, len(save.Resources))
+	for i := range save.Resources {
+		node := save.Resources[i]
+		w.resources[node.ID] = &node
+	}
+	w.gameTime = save.Header.GameTime
+	w.mutex.Unlock()
+	w.inactivity.mutex.Lock()
+	w.inactivity.lastActive, w.inactivity.disconnected, w.inactivity.warned = nil, nil, nil
+	w.inactivity.init()
+	for _, saved := range save.Players {
+		w.inactivity.lastActive[saved.ID] = saved.LastActive
+		if saved.Disconnected {
+			w.inactivity.disconnected[saved.ID] = true
+		}
+	}
+	w.inactivity.mutex.Unlock()
+	w.resetOutcome()
+	w.linkTeamResources(false)
+
+	// Recreate objects under their saved IDs so commands that reference them stay valid;
+	// saves without IDs get fresh ones, so garrison references are still remapped
+	nextUnitID := w.ObjectManager.UnitManager.NextID()
+	unitIDs := make(map[int]int, len(save.Units))
+	for _, saved := range save.Units {
+		if saved.ID > 0 {
+			w.ObjectManager.UnitManager.setNextID(saved.ID)
+		}
+		unit, err := w.ObjectManager.CreateUnit(saved.PlayerID, saved.UnitType, saved.Position, w.loadSavedUnitDefinition(saved.PlayerID, saved.UnitType))
+		if err != nil {
+			return fmt.Errorf("failed to restore unit %d: %w", saved.ID, err)
+		}
+		unit.mutex.Lock()
+		unit.Rotation = saved.Rotation
+		unit.Health = saved.Health
+		unit.MaxHealth = saved.MaxHealth
+		unit.Energy = saved.Energy
+		unit.MaxEnergy = saved.MaxEnergy
+		unit.CarriedResources = copyIntMap(saved.CarriedResources)
+		unit.mutex.Unlock()
+		unitIDs[saved.ID] = unit.ID
+		if unit.ID >= nextUnitID {
+			nextUnitID = unit.ID + 1
+		}
+	}
+	if save.NextUnitID > nextUnitID {
+		nextUnitID = save.NextUnitID
+	}
+	w.ObjectManager.UnitManager.setNextID(nextUnitID)
+
+	nextBuildingID := w.ObjectManager.NextBuildingID()
+	for _, saved := range save.Buildings {
+		if saved.ID > 0 {
+			w.ObjectManager.setNextBuildingID(saved.ID)
+		}
+		building, err := w.ObjectManager.CreateBuilding(saved.PlayerID, saved.BuildingType, saved.Position, w.loadSavedUnitDefinition(saved.PlayerID, saved.BuildingType))
+		if err != nil {
+			return fmt.Errorf("failed to restore building %d: %w", saved.ID, err)
+		}
+		building.mutex.Lock()
+		building.Rotation = saved.Rotation
+		building.Health = saved.Health
+		building.MaxHealth = saved.MaxHealth
+		building.IsBuilt = saved.IsBuilt
+		building.BuildProgress = saved.BuildProgress
+		building.ProductionQueue = append([]ProductionItem{}, saved.ProductionQueue...)
+		building.CurrentProduction = saved.CurrentProduction
+		building.UpgradeLevel = saved.UpgradeLevel
+		building.RallyPoint = saved.RallyPoint
+		building.AutoProduction = saved.AutoProduction
+		building.LastProduced = saved.LastProduced
+		building.JoinControlGroup = saved.JoinControlGroup
+		for _, oldID := range saved.GarrisonedUnits {
+			newID, ok := unitIDs[oldID]
+			if !ok {
+				continue
+			}
+			building.GarrisonedUnits = append(building.GarrisonedUnits, newID)
+			if unit := w.ObjectManager.GetUnit(newID); unit != nil {
+				unit.mutex.Lock()
+				unit.GarrisonedIn = building.ID
+				unit.mutex.Unlock()
+				w.releaseFootprint(unit, unit.GridPos.Grid)
+			}
+		}
+		building.mutex.Unlock()
+		w.restoreWall(building)
+		if building.ID >= nextBuildingID {
+			nextBuildingID = building.ID + 1
+		}
+	}
+	if save.NextBuildingID > nextBuildingID {
+		nextBuildingID = save.NextBuildingID
+	}
+	w.ObjectManager.setNextBuildingID(nextBuildingID)
+
+	if source := w.combatRNG(); source != nil && save.CombatRNG != nil {
+		source.restore(*save.CombatRNG)
+	}
+
+	return nil
+}
+
+// combatRNG returns the source of the combat hit rolls, nil without a command processor
+func (w *World) combatRNG() *countingSource {
+	if w.commandProcessor == nil || w.commandProcessor.combatSystem == nil {
+		return nil
+	}
+	return w.commandProcessor.combatSystem.rngSource
+}
+
+// loadSavedUnitDefinition loads a unit definition for a restored object, falling back to a stub
+func (w *World) loadSavedUnitDefinition(playerID int, unitType string) *data.UnitDefinition {
+	if w.assetMgr != nil {
+		if player := w.GetPlayer(playerID); player != nil {
+			if unitDef, err := w.assetMgr.LoadUnit(player.FactionName, unitType); err == nil {
+				return unitDef
+			}
+		}
+	}
+	return &data.UnitDefinition{Name: unitType}
+}
+
+// WriteSaveGame writes a savegame to disk with DefaultSaveCompression
+func WriteSaveGame(path string, save *SaveGame) error {
+	return WriteSaveGameCompressed(path, save, DefaultSaveCompression)
+}
+
+// WriteSaveGameCompressed writes a savegame to disk with the given compression
+func WriteSaveGameCompressed(path string, save *SaveGame, compression SaveCompression) error {
+	if err := WriteSaveFile(path, compression, save); err != nil {
+		return fmt.Errorf("failed to write savegame: %w", err)
+	}
+	return nil
+}
+
+// ReadSaveGame reads a savegame from disk, compressed or not. A missing
+// .tgs file falls back to a legacy .json file of the same name.
+func ReadSaveGame(path string) (*SaveGame, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if legacy := legacySaveFilePath(path); legacy != "" {
+			if _, err := os.Stat(legacy); err == nil {
+				path = legacy
+			}
+		}
+	}
+
+	var save SaveGame
+	if _, err := ReadSaveFile(path, &save); err != nil {
+		return nil, fmt.Errorf("failed to read savegame: %w", err)
+	}
+	if save.Header.Version == 0 {
+		return nil, fmt.Errorf("savegame %s has no version header", path)
+	}
+	return &save, nil
+}
+
+// SaveToFile saves the current match to disk
+func (g *Game) SaveToFile(path string, description string) error {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	if g.world == nil {
+		return fmt.Errorf("no world to save")
+	}
+
+	save := g.world.CaptureSaveGame()
+	save.Header.Description = description
+	return WriteSaveGame(path, save)
+}
+
+// LoadFromFile restores a match from disk; the game is left paused
+func (g *Game) LoadFromFile(path string) error {
+	save, err := ReadSaveGame(path)
+	if err != nil {
+		return err
+	}
+
+	// Holding the game lock keeps the update loop from touching the world
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.world == nil {
+		return fmt.Errorf("no world to load into")
+	}
+	if err := g.world.RestoreSaveGame(save); err != nil {
+		return fmt.Errorf("failed to restore savegame: %w", err)
+	}
+
+	if g.state == GameStatePlaying {
+		g.setState(GameStatePaused)
+	}
+	g.lastUpdate = time.Now()
+	return nil
+}
+
+// Rematch restarts the match with the same settings, from the world state
+// captured when it started. Unlike LoadFromFile the game keeps its state,
+// so a finished match can be played again right away.
+func (g *Game) Rematch() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.startSave == nil {
+		return fmt.Errorf("the match has not started")
+	}
+	if err := g.world.RestoreSaveGame(g.startSave); err != nil {
+		return fmt.Errorf("failed to restart the match: %w", err)
+	}
+	g.lastUpdate = time.Now()
+	return nil
+}
+
+// captureUnit converts a unit into its saved form
+func captureUnit(unit *GameUnit) UnitSave {
+	unit.mutex.RLock()
+	defer unit.mutex.RUnlock()
+
+	return UnitSave{
+		ID:               unit.ID,
+		PlayerID:         unit.PlayerID,
+		UnitType:         unit.UnitType,
+		Position:         unit.Position,
+		Rotation:         unit.Rotation,
+		Health:           unit.Health,
+		MaxHealth:        unit.MaxHealth,
+		Energy:           unit.Energy,
+		MaxEnergy:        unit.MaxEnergy,
+		CarriedResources: copyIntMap(unit.CarriedResources),
+		GarrisonedIn:     unit.GarrisonedIn,
+	}
+}
+
+// captureBuilding converts a building into its saved form
+func captureBuilding(building *GameBuilding) BuildingSave {
+	building.mutex.RLock()
+	defer building.mutex.RUnlock()
+
+	return BuildingSave{
+		ID:                building.ID,
+		PlayerID:          building.PlayerID,
+		BuildingType:      building.BuildingType,
+		Position:          building.Position,
+		Rotation:          building.Rotation,
+		Health:            building.Health,
+		MaxHealth:         building.MaxHealth,
+		IsBuilt:           building.IsBuilt,
+		BuildProgress:     building.BuildProgress,
+		ProductionQueue:   append([]ProductionItem{}, building.ProductionQueue...),
+		CurrentProduction: building.CurrentProduction,
+		UpgradeLevel:      building.UpgradeLevel,
+		RallyPoint:        building.RallyPoint,
+		GarrisonedUnits:   append([]int{}, building.GarrisonedUnits...),
+		AutoProduction:    building.AutoProduction,
+		LastProduced:      building.LastProduced,
+		JoinControlGroup:  building.JoinControlGroup,
+	}
+}
+
+// copyIntMap returns a shallow copy of a resource map
+func copyIntMap(source map[string]int) map[string]int {
+	result := make(map[string]int, len(source))
+	for key, value := range source {
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,260 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SaveFileKind distinguishes savegames from replays
+type SaveFileKind int
+
+const (
+	SaveFileKindSaveGame SaveFileKind = iota // Resumable match snapshot
+	SaveFileKindReplay                       // Recorded match for playback
+)
+
+// File extensions used for each kind of save file
+const (
+	SaveGameExtension = ".tgs"
+	ReplayExtension   = ".replay.tgs"
+)
+
+// Extensions of save files written before the compressed container, which
+// are still listed and loaded
+const (
+	legacySaveGameExtension = ".json"
+	legacyReplayExtension   = ".replay.json"
+)
+
+// String returns the string representation of a SaveFileKind
+func (k SaveFileKind) String() string {
+	switch k {
+	case SaveFileKindSaveGame:
+		return "Savegame"
+	case SaveFileKindReplay:
+		return "Replay"
+	default:
+		return "Unknown"
+	}
+}
+
+// SaveFileSortField selects how save files are ordered
+type SaveFileSortField int
+
+const (
+	SortSaveFilesByDate     SaveFileSortField = iota // Order by save time
+	SortSaveFilesByName                              // Order by file name
+	SortSaveFilesByMap                               // Order by map path
+	SortSaveFilesByDuration                          // Order by in-game time
+)
+
+// SaveFileInfo describes a savegame or replay file on disk
+type SaveFileInfo struct {
+	Path        string          // Full path to the file
+	Name        string          // File name without extension
+	Kind        SaveFileKind    // Savegame or replay
+	Header      SaveGameHeader  // Metadata read from the file
+	Compression SaveCompression // Payload compression (none for legacy files)
+	Size        int64           // File size in bytes
+	ModTime     time.Time       // Last modification time
+	Valid       bool            // Whether the header could be read
+}
+
+// ReadSaveGameHeader reads only the metadata header of a savegame or replay file
+func ReadSaveGameHeader(path string) (SaveGameHeader, error) {
+	header, _, err := readSaveFileHeader(path)
+	return header, err
+}
+
+// readSaveFileHeader reads the metadata header and the payload compression
+// of a save file. The rest of the payload is not read or integrity checked.
+func readSaveFileHeader(path string) (SaveGameHeader, SaveCompression, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return SaveGameHeader{}, 0, fmt.Errorf("failed to open save file: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := NewSaveFileReader(file)
+	if err != nil {
+		return SaveGameHeader{}, 0, fmt.Errorf("failed to open save file: %w", err)
+	}
+	var envelope struct {
+		Header SaveGameHeader `json:"header"`
+	}
+	if err := json.NewDecoder(reader).Decode(&envelope); err != nil {
+		return SaveGameHeader{}, 0, fmt.Errorf("failed to decode save header: %w", err)
+	}
+	if envelope.Header.Version == 0 {
+		return SaveGameHeader{}, 0, fmt.Errorf("save file %s has no version header", path)
+	}
+	return envelope.Header, reader.Compression, nil
+}
+
+// ListSaveFiles returns all files of the given kind in a directory.
+// A missing directory is treated as empty; unreadable files are listed as invalid.
+func ListSaveFiles(dir string, kind SaveFileKind) ([]SaveFileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SaveFileInfo{}, nil
+		}
+		return nil, fmt.Errorf("failed to read save directory: %w", err)
+	}
+
+	files := make([]SaveFileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || saveFileKindOf(entry.Name()) != kind {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		info := SaveFileInfo{
+			Path: path,
+			Name: trimSaveFileExtension(entry.Name()),
+			Kind: kind,
+		}
+		if stat, err := entry.Info(); err == nil {
+			info.Size = stat.Size()
+			info.ModTime = stat.ModTime()
+		}
+		if header, compression, err := readSaveFileHeader(path); err == nil {
+			info.Header = header
+			info.Compression = compression
+			info.Valid = true
+		}
+		files = append(files, info)
+	}
+
+	SortSaveFiles(files, SortSaveFilesByDate, false)
+	return files, nil
+}
+
+// SortSaveFiles orders save files in place by the given field
+func SortSaveFiles(files []SaveFileInfo, field SaveFileSortField, ascending bool) {
+	less := func(a, b SaveFileInfo) bool {
+		switch field {
+		case SortSaveFilesByName:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		case SortSaveFilesByMap:
+			return a.Header.MapPath < b.Header.MapPath
+		case SortSaveFilesByDuration:
+			return a.Header.GameTime < b.Header.GameTime
+		default:
+			return saveFileTime(a).Before(saveFileTime(b))
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if ascending {
+			return less(files[i], files[j])
+		}
+		return less(files[j], files[i])
+	})
+}
+
+// RenameSaveFile renames a save file within its directory, keeping its extension
+func RenameSaveFile(path string, newName string) (string, error) {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return "", fmt.Errorf("save name cannot be empty")
+	}
+	if strings.ContainsAny(newName, `/\`) || newName == "." || newName == ".." {
+		return "", fmt.Errorf("invalid save name %q", newName)
+	}
+
+	extension := saveFileExtension(filepath.Base(path))
+	newPath := filepath.Join(filepath.Dir(path), newName+extension)
+	if newPath == path {
+		return path, nil
+	}
+
+	// A legacy file of the same name would show up under that name too
+	for _, taken := range saveFileExtensions(saveFileKindOf(filepath.Base(path))) {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(path), newName+taken)); err == nil {
+			return "", fmt.Errorf("a save named %q already exists", newName)
+		}
+	}
+
+	if err := os.Rename(path, newPath); err != nil {
+		return "", fmt.Errorf("failed to rename save file: %w", err)
+	}
+	return newPath, nil
+}
+
+// DeleteSaveFile removes a savegame or replay file
+func DeleteSaveFile(path string) error {
+	if saveFileKindOf(filepath.Base(path)) < 0 {
+		return fmt.Errorf("%s is not a save file", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete save file: %w", err)
+	}
+	return nil
+}
+
+// saveFileExtensions returns the extensions of a kind of save file, the
+// current one first
+func saveFileExtensions(kind SaveFileKind) []string {
+	if kind == SaveFileKindReplay {
+		return []string{ReplayExtension, legacyReplayExtension}
+	}
+	return []string{SaveGameExtension, legacySaveGameExtension}
+}
+
+// saveFileExtension returns the savegame or replay extension a file name
+// ends with, "" if none
+func saveFileExtension(name string) string {
+	// Replays first: their legacy extension ends like a legacy savegame's
+	for _, kind := range []SaveFileKind{SaveFileKindReplay, SaveFileKindSaveGame} {
+		for _, extension := range saveFileExtensions(kind) {
+			if strings.HasSuffix(name, extension) {
+				return extension
+			}
+		}
+	}
+	return ""
+}
+
+// saveFileKindOf determines a file's kind from its name (-1 if it is not a save file)
+func saveFileKindOf(name string) SaveFileKind {
+	switch saveFileExtension(name) {
+	case ReplayExtension, legacyReplayExtension:
+		return SaveFileKindReplay
+	case SaveGameExtension, legacySaveGameExtension:
+		return SaveFileKindSaveGame
+	default:
+		return -1
+	}
+}
+
+// trimSaveFileExtension strips the savegame or replay extension from a file name
+func trimSaveFileExtension(name string) string {
+	return strings.TrimSuffix(name, saveFileExtension(name))
+}
+
+// legacySaveFilePath returns the path a save file had before the current
+// extension, or "" if the path does not end with the current extension
+func legacySaveFilePath(path string) string {
+	switch saveFileExtension(filepath.Base(path)) {
+	case ReplayExtension:
+		return strings.TrimSuffix(path, ReplayExtension) + legacyReplayExtension
+	case SaveGameExtension:
+		return strings.TrimSuffix(path, SaveGameExtension) + legacySaveGameExtension
+	default:
+		return ""
+	}
+}
+
+// saveFileTime returns the save time from the header, falling back to the file time
+func saveFileTime(info SaveFileInfo) time.Time {
+	if info.Valid && !info.Header.SavedAt.IsZero() {
+		return info.Header.SavedAt
+	}
+	return info.ModTime
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestSaveFile writes a minimal save file with the given header
+func writeTestSaveFile(t *testing.T, path string, header SaveGameHeader) {
+	header.Version = SaveGameVersion
+	if err := WriteSaveGame(path, &SaveGame{Header: header}); err != nil {
+		t.Fatalf("Failed to write save file: %v", err)
+	}
+}
+
+// TestListSaveFiles tests listing and sorting savegames and replays, legacy
+// .json files included
+func TestListSaveFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	writeTestSaveFile(t, filepath.Join(dir, "alpha.tgs"), SaveGameHeader{SavedAt: now.Add(-time.Hour), MapPath: "maps/b", GameTime: 5 * time.Minute})
+	writeTestSaveFile(t, filepath.Join(dir, "beta.json"), SaveGameHeader{SavedAt: now.Add(time.Hour), MapPath: "maps/a", GameTime: time.Minute})
+	writeTestSaveFile(t, filepath.Join(dir, "match.replay.tgs"), SaveGameHeader{SavedAt: now})
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("not json"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+
+	saves, err := ListSaveFiles(dir, SaveFileKindSaveGame)
+	if err != nil {
+		t.Fatalf("ListSaveFiles failed: %v", err)
+	}
+	if len(saves) != 3 {
+		t.Fatalf("Expected 3 savegames, got %d", len(saves))
+	}
+	if saves[0].Name != "beta" {
+		t.Errorf("Expected newest save first, got %s", saves[0].Name)
+	}
+
+	invalid := 0
+	for _, save := range saves {
+		if !save.Valid {
+			invalid++
+		}
+	}
+	if invalid != 1 {
+		t.Errorf("Expected 1 invalid save, got %d", invalid)
+	}
+
+	SortSaveFiles(saves, SortSaveFilesByName, true)
+	if saves[0].Name != "alpha" || saves[2].Name != "broken" {
+		t.Errorf("Unexpected name order: %s, %s, %s", saves[0].Name, saves[1].Name, saves[2].Name)
+	}
+
+	SortSaveFiles(saves, SortSaveFilesByDuration, false)
+	if saves[0].Name != "alpha" {
+		t.Errorf("Expected longest match first, got %s", saves[0].Name)
+	}
+
+	replays, err := ListSaveFiles(dir, SaveFileKindReplay)
+	if err != nil {
+		t.Fatalf("ListSaveFiles failed: %v", err)
+	}
+	if len(replays) != 1 || replays[0].Name != "match" {
+		t.Errorf("Expected single replay named match, got %v", replays)
+	}
+
+	missing, err := ListSaveFiles(filepath.Join(dir, "missing"), SaveFileKindSaveGame)
+	if err != nil || len(missing) != 0 {
+		t.Errorf("Expected empty list for missing directory, got %v (err %v)", missing, err)
+	}
+}
+
+// TestRenameAndDeleteSaveFile tests renaming and deleting save files
+func TestRenameAndDeleteSaveFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "old.replay.tgs")
+	writeTestSaveFile(t, path, SaveGameHeader{SavedAt: time.Now()})
+	writeTestSaveFile(t, filepath.Join(dir, "taken.replay.json"), SaveGameHeader{SavedAt: time.Now()})
+
+	if _, err := RenameSaveFile(path, "../escape"); err == nil {
+		t.Error("Expected error for name containing a path separator")
+	}
+	if _, err := RenameSaveFile(path, "taken"); err == nil {
+		t.Error("Expected error renaming onto an existing legacy save")
+	}
+
+	newPath, err := RenameSaveFile(path, "new")
+	if err != nil {
+		t.Fatalf("RenameSaveFile failed: %v", err)
+	}
+	if filepath.Base(newPath) != "new.replay.tgs" {
+		t.Errorf("Expected replay extension to be kept, got %s", newPath)
+	}
+
+	if err := DeleteSaveFile(newPath); err != nil {
+		t.Fatalf("DeleteSaveFile failed: %v", err)
+	}
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		t.Error("Expected save file to be deleted")
+	}
+}
+
+// TestReadLegacySaveGame tests that a savegame written with the legacy .json
+// extension loads from its .tgs path
+func TestReadLegacySaveGame(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSaveFile(t, filepath.Join(dir, "quicksave.json"), SaveGameHeader{MapPath: "maps/legacy"})
+
+	save, err := ReadSaveGame(filepath.Join(dir, "quicksave"+SaveGameExtension))
+	if err != nil {
+		t.Fatalf("ReadSaveGame failed: %v", err)
+	}
+	if save.Header.MapPath != "maps/legacy" {
+		t.Errorf("Expected the legacy savegame, got map %q", save.Header.MapPath)
+	}
+	if _, err := ReadSaveGame(filepath.Join(dir, "missing"+SaveGameExtension)); err == nil {
+		t.Error("Expected an error for a missing savegame")
+	}
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SaveDiff is one difference between two savegames
+type SaveDiff struct {
+	Section string `json:"section"`      // header, settings, player, unit, building, resource, ids or rng
+	ID      int    `json:"id,omitempty"` // Object or player ID, for the per-object sections
+	Field   string `json:"field"`        // Differing field; "exists" when the object is in only one save
+	A       string `json:"a"`            // Value in the first save
+	B       string `json:"b"`            // Value in the second save
+}
+
+func (d SaveDiff) String() string {
+	if d.ID != 0 {
+		return fmt.Sprintf("%s %d %s: %s -> %s", d.Section, d.ID, d.Field, d.A, d.B)
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", d.Section, d.Field, d.A, d.B)
+}
+
+// DiffSaveGames compares two savegames field by field. Players, units,
+// buildings and resource nodes are matched by ID. When the saves are
+// snapshots of the same tick, the first difference usually points at the
+// desync or the corrupted value. The wall clock time a save was written and
+// its description are not compared.
+func DiffSaveGames(a, b *SaveGame) []SaveDiff {
+	var diffs []SaveDiff
+	add := func(section string, id int, field string, valueA, valueB interface{}) {
+		diffs = append(diffs, SaveDiff{Section: section, ID: id, Field: field, A: formatDiffValue(valueA), B: formatDiffValue(valueB)})
+	}
+
+	diffFields("header", 0, a.Header, b.Header, add, "SavedAt", "Description")
+	diffFields("settings", 0, a.Settings, b.Settings, add)
+
+	playersA, playersB := indexByID(a.Players, func(p PlayerSave) int { return p.ID }), indexByID(b.Players, func(p PlayerSave) int { return p.ID })
+	diffObjects("player", playersA, playersB, add)
+	unitsA, unitsB := indexByID(a.Units, func(u UnitSave) int { return u.ID }), indexByID(b.Units, func(u UnitSave) int { return u.ID })
+	diffObjects("unit", unitsA, unitsB, add)
+	buildingsA, buildingsB := indexByID(a.Buildings, func(s BuildingSave) int { return s.ID }), indexByID(b.Buildings, func(s BuildingSave) int { return s.ID })
+	diffObjects("building", buildingsA, buildingsB, add)
+	nodesA, nodesB := indexByID(a.Resources, func(n ResourceNode) int { return n.ID }), indexByID(b.Resources, func(n ResourceNode) int { return n.ID })
+	diffObjects("resource", nodesA, nodesB, add)
+
+	if a.NextUnitID != b.NextUnitID {
+		add("ids", 0, "next_unit_id", a.NextUnitID, b.NextUnitID)
+	}
+	if a.NextBuildingID != b.NextBuildingID {
+		add("ids", 0, "next_building_id", a.NextBuildingID, b.NextBuildingID)
+	}
+
+	switch {
+	case a.CombatRNG == nil && b.CombatRNG == nil:
+	case a.CombatRNG == nil || b.CombatRNG == nil:
+		add("rng", 0, "combat", a.CombatRNG, b.CombatRNG)
+	default:
+		diffFields("rng", 0, *a.CombatRNG, *b.CombatRNG, add)
+	}
+	return diffs
+}
+
+// indexByID maps saved objects by their ID
+func indexByID[T any](objects []T, id func(T) int) map[int]T {
+	index := make(map[int]T, len(objects))
+	for _, object := range objects {
+		index[id(object)] = object
+	}
+	return index
+}
+
+// diffObjects compares objects matched by ID, in ID order
+func diffObjects[T any](section string, a, b map[int]T, add func(string, int, string, interface{}, interface{})) {
+	ids := sortedKeys(a)
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		objectA, inA := a[id]
+		objectB, inB := b[id]
+		switch {
+		case !inB:
+			add(section, id, "exists", true, false)
+		case !inA:
+			add(section, id, "exists", false, true)
+		default:
+			diffFields(section, id, objectA, objectB, add)
+		}
+	}
+}
+
+// diffFields compares the exported fields of two structs of the same type,
+// naming fields by their JSON key where they have one
+func diffFields(section string, id int, a, b interface{}, add func(string, int, string, interface{}, interface{}), skip ...string) {
+	valueA, valueB := reflect.ValueOf(a), reflect.ValueOf(b)
+	fields := valueA.Type()
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		if !field.IsExported() || containsString(skip, field.Name) {
+			continue
+		}
+		fieldA, fieldB := valueA.Field(i).Interface(), valueB.Field(i).Interface()
+		if diffValuesEqual(valueA.Field(i), valueB.Field(i)) {
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+			name = tag
+		}
+		add(section, id, name, fieldA, fieldB)
+	}
+}
+
+// diffValuesEqual compares two field values; empty and nil maps and slices
+// are equal, since a JSON round trip does not keep them apart
+func diffValuesEqual(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Map, reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// formatDiffValue prints a field value, following pointers
+func formatDiffValue(value interface{}) string {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return "<none>"
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "<none>"
+		}
+		return fmt.Sprintf("%+v", v.Elem().Interface())
+	}
+	return fmt.Sprintf("%+v", value)
+}
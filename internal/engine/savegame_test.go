@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestSaveGameRoundTrip tests saving a world to disk and restoring it
+func TestSaveGameRoundTrip(t *testing.T) {
+	world := createTestWorldForProduction(t)
+	world.assetMgr = nil // Restore falls back to stub definitions
+	world.gameTime = 90 * time.Second
+
+	unitDef := &data.UnitDefinition{Name: "swordman"}
+	unitDef.Unit.Parameters.MaxHP.Value = 100
+	unit, err := world.ObjectManager.CreateUnit(1, "swordman", Vector3{X: 12, Y: 0, Z: 12}, unitDef)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	unit.Health = 42
+
+	building := createTestBuildingForControls(t, world, "barracks")
+	building.ProductionQueue = []ProductionItem{{ItemType: "unit", ItemName: "archer", Cost: map[string]int{"gold": 75}}}
+	if err := world.productionSys.GarrisonUnit(building.ID, unit.ID); err != nil {
+		t.Fatalf("GarrisonUnit failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "saves", "test.json")
+	save := world.CaptureSaveGame()
+	save.Header.Description = "round trip"
+	if err := WriteSaveGame(path, save); err != nil {
+		t.Fatalf("WriteSaveGame failed: %v", err)
+	}
+
+	// Change state so the restore is observable
+	world.players[1].Resources["gold"] = 0
+	world.ObjectManager.RemoveUnit(unit.ID)
+
+	loaded, err := ReadSaveGame(path)
+	if err != nil {
+		t.Fatalf("ReadSaveGame failed: %v", err)
+	}
+	if loaded.Header.Version != SaveGameVersion || loaded.Header.Description != "round trip" {
+		t.Errorf("Unexpected header: %+v", loaded.Header)
+	}
+
+	if err := world.RestoreSaveGame(loaded); err != nil {
+		t.Fatalf("RestoreSaveGame failed: %v", err)
+	}
+
+	if gold := world.players[1].Resources["gold"]; gold != 1000 {
+		t.Errorf("Expected gold 1000 after restore, got %d", gold)
+	}
+	if world.gameTime != 90*time.Second {
+		t.Errorf("Expected game time 90s, got %v", world.gameTime)
+	}
+
+	units := world.ObjectManager.GetUnitsForPlayer(1)
+	if len(units) != 1 {
+		t.Fatalf("Expected 1 restored unit, got %d", len(units))
+	}
+	var restoredUnit *GameUnit
+	for _, u := range units {
+		restoredUnit = u
+	}
+	if restoredUnit.Health != 42 {
+		t.Errorf("Expected restored health 42, got %d", restoredUnit.Health)
+	}
+
+	buildings := world.ObjectManager.GetBuildingsForPlayer(1)
+	if len(buildings) != 1 {
+		t.Fatalf("Expected 1 restored building, got %d", len(buildings))
+	}
+	for _, b := range buildings {
+		if len(b.ProductionQueue) != 1 || b.ProductionQueue[0].ItemName != "archer" {
+			t.Errorf("Expected archer in restored queue, got %v", b.ProductionQueue)
+		}
+		if len(b.GarrisonedUnits) != 1 || b.GarrisonedUnits[0] != restoredUnit.ID {
+			t.Errorf("Expected garrison remapped to unit %d, got %v", restoredUnit.ID, b.GarrisonedUnits)
+		}
+		if restoredUnit.GarrisonedIn != b.ID {
+			t.Errorf("Expected unit garrisoned in %d, got %d", b.ID, restoredUnit.GarrisonedIn)
+		}
+	}
+}
+
+// TestReadSaveGameRejectsInvalid tests that files without a version header are rejected
+func TestReadSaveGameRejectsInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	if err := WriteSaveGame(path, &SaveGame{}); err != nil {
+		t.Fatalf("WriteSaveGame failed: %v", err)
+	}
+	if _, err := ReadSaveGame(path); err == nil {
+		t.Error("Expected error reading savegame without version")
+	}
+
+	if err := (&World{}).RestoreSaveGame(&SaveGame{Header: SaveGameHeader{Version: SaveGameVersion + 1}}); err == nil {
+		t.Error("Expected error restoring newer savegame version")
+	}
+}
+
+// TestDiffSaveGames tests the structured diff of two snapshots and the saved combat RNG
+func TestDiffSaveGames(t *testing.T) {
+	world := createTestWorldForProduction(t)
+	world.assetMgr = nil
+	unit, err := world.ObjectManager.CreateUnit(1, "swordman", Vector3{X: 12, Y: 0, Z: 12}, &data.UnitDefinition{Name: "swordman"})
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+
+	before := world.CaptureSaveGame()
+	if diffs := DiffSaveGames(before, world.CaptureSaveGame()); len(diffs) != 0 {
+		t.Fatalf("Expected identical snapshots, got %v", diffs)
+	}
+
+	world.players[1].Resources["gold"] = 5
+	unit.Health = 3
+	world.commandProcessor.combatSystem.rng.Float64()
+	world.ObjectManager.CreateUnit(1, "archer", Vector3{X: 20, Y: 0, Z: 20}, &data.UnitDefinition{Name: "archer"})
+	after := world.CaptureSaveGame()
+
+	found := make(map[string]SaveDiff)
+	for _, diff := range DiffSaveGames(before, after) {
+		found[fmt.Sprintf("%s %d %s", diff.Section, diff.ID, diff.Field)] = diff
+	}
+	for _, key := range []string{
+		"player 1 resources",
+		fmt.Sprintf("unit %d health", unit.ID),
+		fmt.Sprintf("unit %d exists", after.NextUnitID-1),
+		"ids 0 next_unit_id",
+		"rng 0 draws",
+	} {
+		if _, ok := found[key]; !ok {
+			t.Errorf("Expected diff %q, got %v", key, found)
+		}
+	}
+	if diff := found[fmt.Sprintf("unit %d health", unit.ID)]; diff.B != "3" {
+		t.Errorf("Expected new health 3, got %+v", diff)
+	}
+
+	// Restoring replays the combat rolls drawn before the save
+	rng := world.commandProcessor.combatSystem.rng
+	want := rng.Float64()
+	if err := world.RestoreSaveGame(after); err != nil {
+		t.Fatalf("RestoreSaveGame failed: %v", err)
+	}
+	if got := rng.Float64(); got != want {
+		t.Errorf("Expected restored combat roll %v, got %v", want, got)
+	}
+}
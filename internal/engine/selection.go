@@ -0,0 +1,361 @@
+package engine
+
+import (
+	"sort"
+	"sync"
+)
+
+// SelectionGesture is a way of clicking a unit
+type SelectionGesture int
+
+const (
+	GestureSelect     SelectionGesture = iota // Plain click: select the unit instead of the selection
+	GestureAdd                                // Shift+click: add the unit to the selection
+	GestureToggle                             // Ctrl+click: add the unit, or remove it when selected
+	GestureSelectOnly                         // Alt+click: select only the unit, even when it would group
+	GestureSelectType                         // Double-click: select every visible unit of the unit's type
+)
+
+func (g SelectionGesture) String() string {
+	switch g {
+	case GestureSelect:
+		return "Select"
+	case GestureAdd:
+		return "Add"
+	case GestureToggle:
+		return "Toggle"
+	case GestureSelectOnly:
+		return "SelectOnly"
+	case GestureSelectType:
+		return "SelectType"
+	default:
+		return "Unknown"
+	}
+}
+
+// SelectionFilters configures which of the units in a band selection are kept
+type SelectionFilters struct {
+	PreferMilitary   bool // Keep only the military units of bands that hold any
+	ExcludeBuildings bool // Drop buildings from bands that hold other units
+}
+
+// DefaultSelectionFilters is what new selection managers filter bands with
+var DefaultSelectionFilters = SelectionFilters{PreferMilitary: true, ExcludeBuildings: true}
+
+// SelectionManager holds a unit selection and applies selection gestures to
+// it. The game UI and programmatic clients share it, so a gesture selects the
+// same units whether it came from the mouse or from code.
+type SelectionManager struct {
+	world   *World
+	units   []*GameUnit // Selected units, in selection order
+	filters SelectionFilters
+
+	townCenter int // ID of the town center NextTownCenter returned last
+
+	// Threading
+	mutex sync.RWMutex
+}
+
+// NewSelectionManager creates an empty selection of a world's units
+func NewSelectionManager(world *World) *SelectionManager {
+	return &SelectionManager{world: world, filters: DefaultSelectionFilters}
+}
+
+// Filters returns the filters band selections apply
+func (sm *SelectionManager) Filters() SelectionFilters {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return sm.filters
+}
+
+// SetFilters sets the filters band selections apply
+func (sm *SelectionManager) SetFilters(filters SelectionFilters) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.filters = filters
+}
+
+// Units returns the selected units that are still alive
+func (sm *SelectionManager) Units() []*GameUnit {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	units := make([]*GameUnit, 0, len(sm.units))
+	for _, unit := range sm.units {
+		if unit.IsAlive() {
+			units = append(units, unit)
+		}
+	}
+	return units
+}
+
+// Contains reports whether a unit is selected
+func (sm *SelectionManager) Contains(unit *GameUnit) bool {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return sm.indexLocked(unit) >= 0
+}
+
+// Set replaces the selection
+func (sm *SelectionManager) Set(units []*GameUnit) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.units = sm.units[:0]
+	sm.addLocked(units)
+}
+
+// Add adds units to the selection; units already selected keep their place
+func (sm *SelectionManager) Add(units ...*GameUnit) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.addLocked(units)
+}
+
+// Clear empties the selection
+func (sm *SelectionManager) Clear() {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.units = sm.units[:0]
+}
+
+// Apply applies a gesture on a unit. visible limits GestureSelectType to the
+// units the player can see, e.g. those on screen; nil counts every unit as
+// visible. It returns the selection afterwards.
+func (sm *SelectionManager) Apply(gesture SelectionGesture, unit *GameUnit, visible func(*GameUnit) bool) []*GameUnit {
+	if unit == nil {
+		return sm.Units()
+	}
+
+	switch gesture {
+	case GestureAdd:
+		sm.Add(unit)
+	case GestureToggle:
+		sm.mutex.Lock()
+		if index := sm.indexLocked(unit); index >= 0 {
+			sm.units = append(sm.units[:index], sm.units[index+1:]...)
+		} else {
+			sm.addLocked([]*GameUnit{unit})
+		}
+		sm.mutex.Unlock()
+	case GestureSelectType:
+		sm.Set(sm.unitsOfType(unit, visible))
+	default: // GestureSelect and GestureSelectOnly
+		sm.Set([]*GameUnit{unit})
+	}
+	return sm.Units()
+}
+
+// SelectBand selects the living units in a band, e.g. a dragged selection
+// box, through the selection filters; add keeps the current selection. It
+// returns the selection afterwards.
+func (sm *SelectionManager) SelectBand(units []*GameUnit, add bool) []*GameUnit {
+	filters := sm.Filters()
+	var band []*GameUnit
+	for _, unit := range units {
+		if unit != nil && unit.IsAlive() {
+			band = append(band, unit)
+		}
+	}
+	if filters.ExcludeBuildings {
+		band = preferUnits(band, func(unit *GameUnit) bool { return !IsBuildingUnit(unit) })
+	}
+	if filters.PreferMilitary {
+		band = preferUnits(band, IsMilitaryUnit)
+	}
+
+	if add {
+		sm.Add(band...)
+	} else if len(band) > 0 {
+		sm.Set(band)
+	}
+	return sm.Units()
+}
+
+// DropWorkers removes the workers from the selection and returns the selection afterwards
+func (sm *SelectionManager) DropWorkers() []*GameUnit {
+	sm.mutex.Lock()
+	kept := sm.units[:0]
+	for _, unit := range sm.units {
+		if !IsWorkerType(unit.UnitType) {
+			kept = append(kept, unit)
+		}
+	}
+	sm.units = kept
+	sm.mutex.Unlock()
+	return sm.Units()
+}
+
+// preferUnits returns the units that prefer matches, or all units when none does
+func preferUnits(units []*GameUnit, prefer func(*GameUnit) bool) []*GameUnit {
+	var preferred []*GameUnit
+	for _, unit := range units {
+		if prefer(unit) {
+			preferred = append(preferred, unit)
+		}
+	}
+	if len(preferred) == 0 {
+		return units
+	}
+	return preferred
+}
+
+// IsMilitaryUnit reports whether a unit fights: it is armed and not a worker
+func IsMilitaryUnit(unit *GameUnit) bool {
+	return unit.AttackDamage > 0 && !IsWorkerType(unit.UnitType)
+}
+
+// IsBuildingUnit reports whether a unit is a building, i.e. its definition
+// has skills but none to move with
+func IsBuildingUnit(unit *GameUnit) bool {
+	if unit.UnitDef == nil || len(unit.UnitDef.Unit.Skills) == 0 {
+		return false
+	}
+	return unit.UnitDef.SkillOfType("move") == nil
+}
+
+// unitsOfType returns the living, visible units of a unit's player and type,
+// ordered by ID; the unit itself is always included
+func (sm *SelectionManager) unitsOfType(unit *GameUnit, visible func(*GameUnit) bool) []*GameUnit {
+	if sm.world == nil {
+		return []*GameUnit{unit}
+	}
+
+	var units []*GameUnit
+	for _, candidate := range sm.world.ObjectManager.GetUnitsForPlayer(unit.PlayerID) {
+		if candidate == unit || (candidate.UnitType == unit.UnitType && candidate.IsAlive() &&
+			(visible == nil || visible(candidate))) {
+			units = append(units, candidate)
+		}
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+	return units
+}
+
+// addLocked appends the units not selected yet (lock must be held)
+func (sm *SelectionManager) addLocked(units []*GameUnit) {
+	for _, unit := range units {
+		if unit != nil && sm.indexLocked(unit) < 0 {
+			sm.units = append(sm.units, unit)
+		}
+	}
+}
+
+// indexLocked returns a unit's position in the selection, or -1 (lock must be held)
+func (sm *SelectionManager) indexLocked(unit *GameUnit) int {
+	for i, selected := range sm.units {
+		if selected == unit {
+			return i
+		}
+	}
+	return -1
+}
+
+// armyGroupRange is the tiles within which a player's fighting units count as one army group
+const armyGroupRange = 6
+
+// IsTownCenter reports whether a building is a main base building: one that
+// trains workers, or a castle or town center when its definition has no commands
+func IsTownCenter(building *GameBuilding) bool {
+	if building.UnitDef != nil && len(building.UnitDef.Unit.Commands) > 0 {
+		for _, command := range building.UnitDef.Unit.Commands {
+			if command.ProducedUnit != nil && IsWorkerType(command.ProducedUnit.Name) {
+				return true
+			}
+		}
+		return false
+	}
+	switch building.BuildingType {
+	case "castle", "town_center", "keep":
+		return true
+	}
+	return false
+}
+
+// NextTownCenter returns the player's town center after the one returned
+// last, cycling through them in ID order; nil when the player has none
+func (sm *SelectionManager) NextTownCenter(playerID int) *GameBuilding {
+	var centers []*GameBuilding
+	for _, building := range sm.world.ObjectManager.GetBuildingsForPlayer(playerID) {
+		if building.IsBuilt && building.IsAlive() && IsTownCenter(building) {
+			centers = append(centers, building)
+		}
+	}
+	if len(centers) == 0 {
+		return nil
+	}
+	sort.Slice(centers, func(i, j int) bool { return centers[i].ID < centers[j].ID })
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	next := centers[0]
+	for _, center := range centers {
+		if center.ID > sm.townCenter {
+			next = center
+			break
+		}
+	}
+	sm.townCenter = next.ID
+	return next
+}
+
+// SelectLargestArmy selects the player's largest group of fighting units and
+// returns its center; it is false when the player has no army
+func (sm *SelectionManager) SelectLargestArmy(playerID int) (Vector3, bool) {
+	groups := sm.ArmyGroups(playerID)
+	if len(groups) == 0 {
+		return Vector3{}, false
+	}
+	sm.Set(groups[0])
+
+	var center Vector3
+	for _, unit := range groups[0] {
+		position := unit.GetPosition()
+		center.X += position.X
+		center.Y += position.Y
+		center.Z += position.Z
+	}
+	count := float64(len(groups[0]))
+	return Vector3{X: center.X / count, Y: center.Y / count, Z: center.Z / count}, true
+}
+
+// ArmyGroups splits a player's living fighting units (armed units that are
+// not workers) into groups of units within armyGroupRange tiles of each
+// other, largest first. Each group is ordered by ID.
+func (sm *SelectionManager) ArmyGroups(playerID int) [][]*GameUnit {
+	army := make(map[int]*GameUnit)
+	for id, unit := range sm.world.ObjectManager.GetUnitsForPlayer(playerID) {
+		if unit.IsAlive() && unit.GarrisonedIn == 0 && IsMilitaryUnit(unit) {
+			army[id] = unit
+		}
+	}
+	ids := make([]int, 0, len(army))
+	for id := range army {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	// Flood fill over the units near each other
+	reach := armyGroupRange * float64(sm.world.GetTileSize())
+	grouped := make(map[int]bool, len(army))
+	var groups [][]*GameUnit
+	for _, id := range ids {
+		if grouped[id] {
+			continue
+		}
+		grouped[id] = true
+		group := []*GameUnit{army[id]}
+		for next := 0; next < len(group); next++ {
+			position := group[next].GetPosition()
+			for _, other := range ids {
+				if !grouped[other] && sm.world.CalculateDistance(position, army[other].GetPosition()) <= reach {
+					grouped[other] = true
+					group = append(group, army[other])
+				}
+			}
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].ID < group[j].ID })
+		groups = append(groups, group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return len(groups[i]) > len(groups[j]) })
+	return groups
+}
@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestSelectionGestures tests the selection each gesture produces
+func TestSelectionGestures(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	archer := data.NewSimpleUnit("archer", 80, 0, "leather", nil)
+	create := func(playerID int, unitType string, def *data.UnitDefinition, x float64) *GameUnit {
+		unit, err := world.ObjectManager.CreateUnit(playerID, unitType, Vector3{X: x, Z: 1}, def)
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		return unit
+	}
+	s1, s2, farSoldier := create(1, "soldier", soldier, 1), create(1, "soldier", soldier, 2), create(1, "soldier", soldier, 12)
+	a1 := create(1, "archer", archer, 3)
+	create(2, "soldier", soldier, 4) // Enemy of the same type
+
+	selection := NewSelectionManager(world)
+	expect := func(name string, got []*GameUnit, want ...*GameUnit) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %d units, got %d", name, len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: expected unit %d at %d, got %d", name, want[i].ID, i, got[i].ID)
+			}
+		}
+	}
+
+	expect("select", selection.Apply(GestureSelect, s1, nil), s1)
+	expect("add", selection.Apply(GestureAdd, a1, nil), s1, a1)
+	expect("add again", selection.Apply(GestureAdd, a1, nil), s1, a1)
+	expect("toggle on", selection.Apply(GestureToggle, s2, nil), s1, a1, s2)
+	expect("toggle off", selection.Apply(GestureToggle, s1, nil), a1, s2)
+	expect("select only", selection.Apply(GestureSelectOnly, s2, nil), s2)
+
+	onScreen := func(unit *GameUnit) bool { return unit.Position.X < 10 }
+	expect("select visible type", selection.Apply(GestureSelectType, s1, onScreen), s1, s2)
+	expect("select type", selection.Apply(GestureSelectType, s1, nil), s1, s2, farSoldier)
+
+	s2.SetHealth(0)
+	expect("dead units drop out", selection.Units(), s1, farSoldier)
+}
+
+// TestCameraJumpTargets tests cycling through town centers and finding the
+// largest army group
+func TestCameraJumpTargets(t *testing.T) {
+	world, err := NewHeadlessWorld(48, 48)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	building := func(buildingType string, x float64, built bool) *GameBuilding {
+		created, err := world.ObjectManager.CreateBuilding(1, buildingType, Vector3{X: x, Z: 5.5}, data.NewSimpleUnit(buildingType, 1000, 0, "stone", nil))
+		if err != nil {
+			t.Fatalf("Failed to create building: %v", err)
+		}
+		created.IsBuilt = built
+		return created
+	}
+	first, second := building("castle", 5.5, true), building("town_center", 30.5, true)
+	building("barracks", 15.5, true)
+	building("castle", 40.5, false) // Still under construction
+
+	selection := NewSelectionManager(world)
+	for i, want := range []*GameBuilding{first, second, first} {
+		if got := selection.NextTownCenter(1); got != want {
+			t.Errorf("Jump %d: expected town center %d, got %+v", i, want.ID, got)
+		}
+	}
+	if selection.NextTownCenter(2) != nil {
+		t.Error("Expected no town center for a player without one")
+	}
+	if _, ok := selection.SelectLargestArmy(1); ok {
+		t.Error("Expected no army before any soldier exists")
+	}
+
+	unit := func(unitType string, x, z float64) *GameUnit {
+		created, err := world.ObjectManager.CreateUnit(1, unitType, Vector3{X: x, Z: z}, data.NewSimpleUnit(unitType, 100, 0, "leather", nil))
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		created.AttackDamage = 10
+		return created
+	}
+	unit("soldier", 5.5, 40.5)
+	unit("soldier", 8.5, 40.5)
+	// A chain of soldiers further apart than the group range from end to end
+	chain := []*GameUnit{unit("soldier", 30.5, 20.5), unit("archer", 34.5, 20.5), unit("soldier", 38.5, 20.5)}
+	unit("worker", 32.5, 21.5)
+
+	center, ok := selection.SelectLargestArmy(1)
+	if !ok || center.X != 34.5 || center.Z != 20.5 {
+		t.Fatalf("Expected the camera to jump to the middle of the chain, got %+v", center)
+	}
+	selected := selection.Units()
+	if len(selected) != len(chain) {
+		t.Fatalf("Expected the chain to be selected, got %d units", len(selected))
+	}
+	for i := range chain {
+		if selected[i] != chain[i] {
+			t.Errorf("Expected unit %d selected at %d, got %d", chain[i].ID, i, selected[i].ID)
+		}
+	}
+}
+
+// TestSelectionFilters tests that band selections drop workers and buildings
+// from mixed bands, and that workers can be dropped from the selection
+func TestSelectionFilters(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	create := func(unitType string, damage int, skills ...string) *GameUnit {
+		def := data.NewSimpleUnit(unitType, 100, 0, "leather", nil)
+		for _, skill := range skills {
+			def.Unit.Skills = append(def.Unit.Skills, data.Skill{Type: data.SkillType{Value: skill}})
+		}
+		unit, err := world.ObjectManager.CreateUnit(1, unitType, Vector3{X: 1, Z: 1}, def)
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		unit.AttackDamage = damage
+		return unit
+	}
+	worker, soldier := create("worker", 2, "move"), create("soldier", 10, "move")
+	tower := create("tower", 20, "stop", "attack")
+
+	selection := NewSelectionManager(world)
+	count := func(name string, got []*GameUnit, want int) {
+		t.Helper()
+		if len(got) != want {
+			t.Errorf("%s: expected %d units, got %d", name, want, len(got))
+		}
+	}
+	count("military first", selection.SelectBand([]*GameUnit{worker, soldier, tower}, false), 1)
+	count("workers when no military", selection.SelectBand([]*GameUnit{worker, tower}, false), 1)
+	if !selection.Contains(worker) {
+		t.Errorf("Expected the worker selected over the tower")
+	}
+	count("buildings alone", selection.SelectBand([]*GameUnit{tower}, false), 1)
+
+	selection.SetFilters(SelectionFilters{})
+	count("unfiltered", selection.SelectBand([]*GameUnit{worker, soldier, tower}, false), 3)
+	count("drop workers", selection.DropWorkers(), 2)
+	if selection.Contains(worker) {
+		t.Errorf("Expected the worker dropped from the selection")
+	}
+}
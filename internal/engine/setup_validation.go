@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"teraglest/internal/data"
+)
+
+// TechTreeRoot returns the directory of the tech tree the match is played
+// with: the one named by TechTree inside DataRoot, else the one TechTreePath
+// points at (its directory or its XML file)
+func (s GameSettings) TechTreeRoot() string {
+	if s.TechTree != "" {
+		return filepath.Join(data.TechTreesDir(s.DataRoot), s.TechTree)
+	}
+	if strings.EqualFold(filepath.Ext(s.TechTreePath), ".xml") {
+		return filepath.Dir(s.TechTreePath)
+	}
+	return s.TechTreePath
+}
+
+// ResolveMapPath returns the map file of the settings: MapPath itself when
+// it is a file, else the map of that name in DataRoot/maps
+func (s GameSettings) ResolveMapPath() (string, error) {
+	if info, err := os.Stat(s.MapPath); err == nil && !info.IsDir() {
+		return s.MapPath, nil
+	}
+	if s.DataRoot != "" {
+		for _, extension := range []string{".mgm", ".gbm"} {
+			path := filepath.Join(s.DataRoot, "maps", s.MapPath+extension)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("map %s not found", s.MapPath)
+}
+
+// ValidateSetup checks that a match setup fits together: the chosen tech
+// tree is installed and has every player's faction, and the map has room
+// for the players and its tileset is installed. Checks that need data the
+// settings do not locate (no TechTree name, no MapPath) are skipped. It
+// returns every problem found, empty when the setup can be played.
+func ValidateSetup(settings GameSettings) []string {
+	var issues []string
+
+	if settings.TechTree != "" {
+		if settings.DataRoot == "" {
+			issues = append(issues, fmt.Sprintf("tech tree %q is chosen by name, but no data directory is set", settings.TechTree))
+		} else if techTree, err := data.FindTechTree(settings.DataRoot, settings.TechTree); err != nil {
+			issues = append(issues, err.Error())
+		} else {
+			for _, playerID := range setupPlayerIDs(settings) {
+				faction := setupFaction(settings, playerID)
+				if !techTree.HasFaction(faction) {
+					issues = append(issues, fmt.Sprintf("player %d's faction %q is not in tech tree %s (available: %s)",
+						playerID, faction, techTree.Name, strings.Join(techTree.Factions, ", ")))
+				}
+			}
+		}
+	}
+
+	if settings.MapPath != "" {
+		issues = append(issues, validateSetupMap(settings)...)
+	}
+	return issues
+}
+
+// validateSetupMap checks the map has room for the players and its tileset
+// is installed
+func validateSetupMap(settings GameSettings) []string {
+	mapPath, err := settings.ResolveMapPath()
+	if err != nil {
+		return []string{err.Error()}
+	}
+	mapData, err := NewMapLoader().ParseMapFile(mapPath)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to read map %s: %v", mapPath, err)}
+	}
+
+	var issues []string
+	if players := len(setupPlayerIDs(settings)); players > mapData.MaxPlayers {
+		issues = append(issues, fmt.Sprintf("map %s has room for %d players, but %d are configured",
+			filepath.Base(mapPath), mapData.MaxPlayers, players))
+	}
+	for _, playerID := range setupPlayerIDs(settings) {
+		if playerID < 1 || playerID > mapData.MaxPlayers {
+			issues = append(issues, fmt.Sprintf("player %d has no start position on map %s", playerID, filepath.Base(mapPath)))
+		}
+	}
+	if settings.DataRoot != "" && mapData.TilesetName != "" {
+		tilesetXML := filepath.Join(settings.DataRoot, "tilesets", mapData.TilesetName, mapData.TilesetName+".xml")
+		if _, err := os.Stat(tilesetXML); err != nil {
+			issues = append(issues, fmt.Sprintf("map %s needs tileset %s, which is not installed", filepath.Base(mapPath), mapData.TilesetName))
+		}
+	}
+	return issues
+}
+
+// setupPlayerIDs returns the human and AI player IDs in order
+func setupPlayerIDs(settings GameSettings) []int {
+	ids := make([]int, 0, len(settings.PlayerFactions)+len(settings.AIFactions))
+	for id := range settings.PlayerFactions {
+		ids = append(ids, id)
+	}
+	for id := range settings.AIFactions {
+		if _, human := settings.PlayerFactions[id]; !human {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// setupFaction returns a player's faction
+func setupFaction(settings GameSettings, playerID int) string {
+	if faction, ok := settings.PlayerFactions[playerID]; ok {
+		return faction
+	}
+	return settings.AIFactions[playerID]
+}
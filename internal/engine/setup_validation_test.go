@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSetupTestFile writes content to root/rel, creating directories as needed
+func writeSetupTestFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeSetupTestMap writes a flat 16x16 GBM map for some players
+func writeSetupTestMap(t *testing.T, root, name, title string, players int) {
+	t.Helper()
+	header := MapFileHeader{Version: int32(MapVersionGBM), MaxFactions: int32(players), Width: 16, Height: 16}
+	copy(header.Title[:], title)
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, header)
+	for i := 0; i < players; i++ {
+		binary.Write(&buf, binary.LittleEndian, StartPosition{X: int32(2 + i*10), Y: 2})
+	}
+	binary.Write(&buf, binary.LittleEndian, make([]float32, 16*16))
+	binary.Write(&buf, binary.LittleEndian, make([]int8, 16*16)) // Surfaces
+	binary.Write(&buf, binary.LittleEndian, make([]int8, 16*16)) // Objects
+	writeSetupTestFile(t, root, "maps/"+name+".gbm", buf.String())
+}
+
+func TestValidateSetup(t *testing.T) {
+	root := t.TempDir()
+	writeSetupTestFile(t, root, "techs/megapack/megapack.xml", `<tech-tree><description value="The classic factions"/></tech-tree>`)
+	writeSetupTestFile(t, root, "techs/megapack/factions/magic/magic.xml", `<faction/>`)
+	writeSetupTestFile(t, root, "techs/megapack/factions/tech/tech.xml", `<faction/>`)
+	writeSetupTestFile(t, root, "techs/annex/annex.xml", `<tech-tree/>`)
+	writeSetupTestFile(t, root, "techs/annex/factions/elves/elves.xml", `<faction/>`)
+	writeSetupTestFile(t, root, "techs/notes/readme.txt", "not a tech tree")
+	writeSetupTestFile(t, root, "tilesets/forest/forest.xml", `<tileset/>`)
+	writeSetupTestMap(t, root, "duel", "Forest duel", 2)
+	writeSetupTestMap(t, root, "winter_duel", "Winter duel", 2)
+
+	settings := GameSettings{
+		DataRoot:       root,
+		TechTree:       "annex",
+		MapPath:        "duel",
+		PlayerFactions: map[int]string{1: "elves"},
+		AIFactions:     map[int]string{2: "elves"},
+	}
+	if issues := ValidateSetup(settings); len(issues) != 0 {
+		t.Errorf("Expected a valid setup, got %v", issues)
+	}
+	if root := settings.TechTreeRoot(); root != filepath.Join(settings.DataRoot, "techs", "annex") {
+		t.Errorf("Unexpected tech tree root %s", root)
+	}
+
+	// Factions must come from the chosen tech tree
+	settings.AIFactions = map[int]string{2: "magic"}
+	if issues := ValidateSetup(settings); len(issues) != 1 || !strings.Contains(issues[0], `"magic" is not in tech tree annex`) {
+		t.Errorf("Expected the faction to be rejected, got %v", issues)
+	}
+	settings.TechTree = "notes"
+	if issues := ValidateSetup(settings); len(issues) != 1 || !strings.Contains(issues[0], "available: annex, megapack") {
+		t.Errorf("Expected an unknown tech tree to list the installed ones, got %v", issues)
+	}
+	settings.TechTree = "megapack"
+	settings.PlayerFactions = map[int]string{1: "tech"}
+
+	// The map must have room for every player and its tileset must be installed
+	settings.AIFactions = map[int]string{2: "magic", 3: "tech"}
+	if issues := ValidateSetup(settings); len(issues) != 2 || !strings.Contains(issues[0], "room for 2 players, but 3") {
+		t.Errorf("Expected too many players for the map, got %v", issues)
+	}
+	settings.AIFactions = map[int]string{2: "magic"}
+	settings.MapPath = filepath.Join(root, "maps", "winter_duel.gbm")
+	if issues := ValidateSetup(settings); len(issues) != 1 || !strings.Contains(issues[0], "tileset winter") {
+		t.Errorf("Expected the missing tileset to be reported, got %v", issues)
+	}
+	settings.MapPath = "nowhere"
+	if issues := ValidateSetup(settings); len(issues) != 1 || !strings.Contains(issues[0], "not found") {
+		t.Errorf("Expected the missing map to be reported, got %v", issues)
+	}
+
+	// Without a tech tree name or map there is nothing to cross-check
+	if issues := ValidateSetup(GameSettings{TechTreePath: "techs", AIFactions: map[int]string{1: "anything"}}); len(issues) != 0 {
+		t.Errorf("Expected no issues without a named tech tree or map, got %v", issues)
+	}
+}
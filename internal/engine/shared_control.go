@@ -0,0 +1,258 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// In shared control (co-op), extra human players command a faction together
+// with its owner instead of playing one of their own. Such a controller owns
+// no objects: its commands are checked against the faction it shares, its
+// orders are attributed to it in the event log, and orders two players give
+// the same object in quick succession are resolved by a SharedConflictPolicy.
+
+// SharedConflictWindow is how long after one player's order to an object an
+// order from another player sharing the faction counts as a conflict
+const SharedConflictWindow = 2 * time.Second
+
+// ErrCommandConflict is returned when a shared-control order is refused
+// because another player ordered the object first
+var ErrCommandConflict = errors.New("another player just ordered this")
+
+// SharedConflictPolicy decides which order stands when two players sharing a
+// faction order the same object within SharedConflictWindow
+type SharedConflictPolicy int
+
+const (
+	SharedConflictLatestWins SharedConflictPolicy = iota // The later order replaces the earlier one
+	SharedConflictFirstWins                              // The earlier order stands until the window passes
+)
+
+// String returns the string representation of a SharedConflictPolicy
+func (p SharedConflictPolicy) String() string {
+	switch p {
+	case SharedConflictLatestWins:
+		return "latest"
+	case SharedConflictFirstWins:
+		return "first"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParseSharedConflictPolicy parses a policy name as printed by String
+func ParseSharedConflictPolicy(name string) (SharedConflictPolicy, error) {
+	switch strings.ToLower(name) {
+	case "latest", "":
+		return SharedConflictLatestWins, nil
+	case "first":
+		return SharedConflictFirstWins, nil
+	default:
+		return 0, fmt.Errorf("unknown conflict policy %q (valid: latest, first)", name)
+	}
+}
+
+// CommandAttribution is the data of an EventTypeCommandIssued event
+type CommandAttribution struct {
+	IssuerID   int         // Player who gave the order
+	OwnerID    int         // Player whose faction the object belongs to
+	UnitID     int         // Unit ordered (0 for a building)
+	BuildingID int         // Building ordered (0 for a unit)
+	Command    CommandType // What was ordered
+}
+
+// CommandConflict is the data of an EventTypeCommandConflict event
+type CommandConflict struct {
+	UnitID     int // Unit ordered twice (0 for a building)
+	BuildingID int // Building ordered twice (0 for a unit)
+	FirstID    int // Player who ordered the object first
+	SecondID   int // Player whose order came within the window
+	KeptID     int // Player whose order stands
+}
+
+// sharedOrder is the last order a controller gave an object
+type sharedOrder struct {
+	issuerID int
+	at       time.Duration // Game time of the order
+}
+
+// sharedObject identifies a unit or building ordered under shared control
+type sharedObject struct {
+	id       int
+	building bool
+}
+
+// sharedControl tracks who shares which faction and their recent orders
+type sharedControl struct {
+	mutex       sync.Mutex
+	controllers map[int]int // Controller ID -> player whose faction it commands
+	policy      SharedConflictPolicy
+	orders      map[sharedObject]sharedOrder
+	teamControl bool // Whether human allies may order each other's units
+}
+
+// ShareControl lets a controller command a player's faction alongside its
+// owner. The controller must not own a faction itself.
+func (w *World) ShareControl(controllerID, ownerID int) error {
+	if controllerID == ownerID {
+		return fmt.Errorf("player %d already commands its own faction", ownerID)
+	}
+	if w.GetPlayer(ownerID) == nil {
+		return fmt.Errorf("player %d does not exist", ownerID)
+	}
+	if w.GetPlayer(controllerID) != nil {
+		return fmt.Errorf("player %d has a faction of its own", controllerID)
+	}
+
+	w.shared.mutex.Lock()
+	defer w.shared.mutex.Unlock()
+	if w.shared.controllers == nil {
+		w.shared.controllers = make(map[int]int)
+		w.shared.orders = make(map[sharedObject]sharedOrder)
+	}
+	w.shared.controllers[controllerID] = ownerID
+	return nil
+}
+
+// SetSharedConflictPolicy chooses how conflicting shared-control orders are resolved
+func (w *World) SetSharedConflictPolicy(policy SharedConflictPolicy) {
+	w.shared.mutex.Lock()
+	defer w.shared.mutex.Unlock()
+	w.shared.policy = policy
+}
+
+// CommandedPlayer returns the player whose faction a player commands: the
+// shared faction for a controller, else the player itself
+func (w *World) CommandedPlayer(playerID int) int {
+	w.shared.mutex.Lock()
+	defer w.shared.mutex.Unlock()
+	if ownerID, ok := w.shared.controllers[playerID]; ok {
+		return ownerID
+	}
+	return playerID
+}
+
+// CanCommand reports whether a player may order objects of an owner: its
+// own, those of the faction it shares, or a human ally's under shared team
+// control
+func (w *World) CanCommand(playerID, ownerID int) bool {
+	commanded := w.CommandedPlayer(playerID)
+	return commanded == ownerID || w.commandsAlly(commanded, ownerID)
+}
+
+// SharedControllers returns the controllers sharing a player's faction, sorted
+func (w *World) SharedControllers(ownerID int) []int {
+	w.shared.mutex.Lock()
+	defer w.shared.mutex.Unlock()
+	var controllers []int
+	for controllerID, owner := range w.shared.controllers {
+		if owner == ownerID {
+			controllers = append(controllers, controllerID)
+		}
+	}
+	sort.Ints(controllers)
+	return controllers
+}
+
+// IssuePlayerCommand orders a unit on behalf of a player, who must own it or
+// share its faction. Orders to a shared faction or an ally's units are attributed to the player
+// in the event log and resolved against other players' recent orders.
+func (w *World) IssuePlayerCommand(playerID, unitID int, command UnitCommand) error {
+	unit := w.ObjectManager.GetUnit(unitID)
+	if unit == nil || !unit.IsAlive() {
+		return fmt.Errorf("unit %d not found", unitID)
+	}
+	return w.issueShared(playerID, unit.PlayerID, sharedObject{id: unitID}, command, func() error {
+		return w.commandProcessor.IssueCommand(unitID, command)
+	})
+}
+
+// IssuePlayerBuildingCommand orders a building on behalf of a player, like
+// IssuePlayerCommand
+func (w *World) IssuePlayerBuildingCommand(playerID, buildingID int, command UnitCommand) error {
+	building := w.ObjectManager.GetBuilding(buildingID)
+	if building == nil {
+		return fmt.Errorf("building %d not found", buildingID)
+	}
+	return w.issueShared(playerID, building.PlayerID, sharedObject{id: buildingID, building: true}, command, func() error {
+		return w.commandProcessor.IssueBuildingCommand(buildingID, command)
+	})
+}
+
+// issueShared checks a player may order an object and, for shared factions,
+// resolves conflicts and attributes the order
+func (w *World) issueShared(playerID, ownerID int, object sharedObject, command UnitCommand, issue func() error) error {
+	if !w.CanCommand(playerID, ownerID) {
+		return fmt.Errorf("player %d cannot command player %d's objects", playerID, ownerID)
+	}
+	w.MarkPlayerActive(w.CommandedPlayer(playerID))
+	if len(w.SharedControllers(ownerID)) == 0 && w.CommandedPlayer(playerID) == ownerID {
+		return issue() // Nobody shares the faction
+	}
+
+	now := w.GetGameTime()
+	w.shared.mutex.Lock()
+	previous, ordered := w.shared.orders[object]
+	policy := w.shared.policy
+	w.shared.mutex.Unlock()
+
+	var conflict *CommandConflict
+	if ordered && previous.issuerID != playerID && now-previous.at < SharedConflictWindow {
+		conflict = &CommandConflict{FirstID: previous.issuerID, SecondID: playerID, KeptID: playerID}
+		if policy == SharedConflictFirstWins {
+			conflict.KeptID = previous.issuerID
+		}
+		if object.building {
+			conflict.BuildingID = object.id
+		} else {
+			conflict.UnitID = object.id
+		}
+		w.raiseEvent(GameEvent{
+			Type:      EventTypeCommandConflict,
+			Timestamp: w.now(),
+			PlayerID:  ownerID,
+			Data:      *conflict,
+			Message:   fmt.Sprintf("Players %d and %d both ordered %s; player %d's order stands", previous.issuerID, playerID, object, conflict.KeptID),
+		})
+		if conflict.KeptID != playerID {
+			return fmt.Errorf("%w: player %d ordered %s %v ago", ErrCommandConflict, previous.issuerID, object, now-previous.at)
+		}
+	}
+
+	if err := issue(); err != nil {
+		return err
+	}
+	w.shared.mutex.Lock()
+	if w.shared.orders == nil {
+		w.shared.orders = make(map[sharedObject]sharedOrder)
+	}
+	w.shared.orders[object] = sharedOrder{issuerID: playerID, at: now}
+	w.shared.mutex.Unlock()
+
+	attribution := CommandAttribution{IssuerID: playerID, OwnerID: ownerID, Command: command.Type}
+	if object.building {
+		attribution.BuildingID = object.id
+	} else {
+		attribution.UnitID = object.id
+	}
+	w.raiseEvent(GameEvent{
+		Type:      EventTypeCommandIssued,
+		Timestamp: w.now(),
+		PlayerID:  playerID,
+		Data:      attribution,
+		Message:   fmt.Sprintf("Player %d ordered %s to %s", playerID, object, command.Type),
+	})
+	return nil
+}
+
+// String names the object, e.g. "unit 12"
+func (o sharedObject) String() string {
+	if o.building {
+		return fmt.Sprintf("building %d", o.id)
+	}
+	return fmt.Sprintf("unit %d", o.id)
+}
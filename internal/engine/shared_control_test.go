@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestSharedControl tests two players commanding one faction
+func TestSharedControl(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	var events []GameEvent
+	world.AddEventListener(func(event GameEvent) {
+		if event.Type == EventTypeCommandIssued || event.Type == EventTypeCommandConflict {
+			events = append(events, event)
+		}
+	})
+
+	unitDef := &data.UnitDefinition{Name: "soldier"}
+	unitDef.Unit.Parameters.MaxHP.Value = 100
+	unit, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 5, Z: 5}, unitDef)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	move := CreateMoveCommand(Vector3{X: 10, Z: 10}, false)
+
+	// Without shared control only the owner commands, and nothing is attributed
+	if err := world.IssuePlayerCommand(3, unit.ID, move); err == nil {
+		t.Error("Expected a stranger's command to be refused")
+	}
+	if err := world.IssuePlayerCommand(1, unit.ID, move); err != nil || len(events) != 0 {
+		t.Errorf("Expected an unshared order without events, got %v and %d events", err, len(events))
+	}
+
+	if err := world.ShareControl(2, 1); err == nil {
+		t.Error("Expected a player with a faction not to share another")
+	}
+	if err := world.ShareControl(3, 1); err != nil {
+		t.Fatalf("ShareControl failed: %v", err)
+	}
+	if !world.CanCommand(3, 1) || world.CanCommand(3, 2) || world.CommandedPlayer(3) != 1 {
+		t.Error("Expected the controller to command player 1's faction only")
+	}
+
+	// The controller's orders are attributed to it
+	if err := world.IssuePlayerCommand(3, unit.ID, move); err != nil {
+		t.Fatalf("Controller command failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventTypeCommandIssued {
+		t.Fatalf("Expected one attribution event, got %+v", events)
+	}
+	if attribution := events[0].Data.(CommandAttribution); attribution.IssuerID != 3 || attribution.OwnerID != 1 || attribution.UnitID != unit.ID {
+		t.Errorf("Unexpected attribution %+v", attribution)
+	}
+
+	// By default the latest order wins a conflict
+	events = nil
+	if err := world.IssuePlayerCommand(1, unit.ID, move); err != nil {
+		t.Fatalf("Owner command failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != EventTypeCommandConflict {
+		t.Fatalf("Expected a conflict and an attribution, got %+v", events)
+	}
+	if conflict := events[0].Data.(CommandConflict); conflict.FirstID != 3 || conflict.SecondID != 1 || conflict.KeptID != 1 {
+		t.Errorf("Unexpected conflict %+v", conflict)
+	}
+
+	// With first-wins the earlier order stands until the window passes
+	world.SetSharedConflictPolicy(SharedConflictFirstWins)
+	events = nil
+	if err := world.IssuePlayerCommand(3, unit.ID, move); !errors.Is(err, ErrCommandConflict) {
+		t.Errorf("Expected the controller's order to be refused, got %v", err)
+	}
+	if len(events) != 1 || events[0].Data.(CommandConflict).KeptID != 1 {
+		t.Errorf("Expected a conflict keeping the owner's order, got %+v", events)
+	}
+	world.Update(SharedConflictWindow)
+	if err := world.IssuePlayerCommand(3, unit.ID, move); err != nil {
+		t.Errorf("Expected the order after the window, got %v", err)
+	}
+}
+
+// TestSharedControlSettings tests validating co-op settings
+func TestSharedControlSettings(t *testing.T) {
+	settings := GameSettings{
+		TechTreePath:   "techs",
+		PlayerFactions: map[int]string{1: "tech"},
+		AIFactions:     map[int]string{2: "magic"},
+		SharedControl:  map[int]int{3: 1},
+	}
+	if err := validateGameSettings(settings); err != nil {
+		t.Errorf("Expected valid co-op settings, got %v", err)
+	}
+	settings.SharedControl = map[int]int{3: 2}
+	if err := validateGameSettings(settings); err == nil {
+		t.Error("Expected sharing an AI's faction to be rejected")
+	}
+	settings.SharedControl = map[int]int{2: 1}
+	if err := validateGameSettings(settings); err == nil {
+		t.Error("Expected an AI player not to share a faction")
+	}
+
+	if policy, err := ParseSharedConflictPolicy("first"); err != nil || policy != SharedConflictFirstWins {
+		t.Errorf("Expected first-wins, got %v, %v", policy, err)
+	}
+	if _, err := ParseSharedConflictPolicy("loudest"); err == nil {
+		t.Error("Expected an unknown policy to be rejected")
+	}
+}
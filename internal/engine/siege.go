@@ -0,0 +1,17 @@
+package engine
+
+// Damage against structures
+const (
+	defaultBuildingArmorType = "stone" // Armor type of buildings whose XML has none
+	siegeStructureBonus      = 2.0     // Damage multiplier of siege attacks against buildings
+	structurePenalty         = 0.5     // Damage multiplier of all other attacks against buildings
+)
+
+// structureMultiplier returns how much harder (or softer) an attack type hits
+// buildings than units
+func (cs *CombatSystem) structureMultiplier(attackType string) float64 {
+	if attackType == "siege" || (cs.world.techTree != nil && cs.world.techTree.IsSiegeAttack(attackType)) {
+		return siegeStructureBonus
+	}
+	return structurePenalty
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestStructureDamage tests that siege attacks hit buildings harder and other
+// attacks softer, against the armor the building's XML gives it
+func TestStructureDamage(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.techTree = &data.TechTree{
+		AttackTypes:       []data.AttackType{{Name: "slashing"}, {Name: "impact", Siege: true}},
+		DamageMultipliers: []data.DamageMultiplier{{Attack: "impact", Armor: "wood", Value: 1.5}},
+	}
+
+	castle, _ := world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 10, Z: 10}, data.NewSimpleUnit("castle", 2000, 5, "", nil))
+	palisade, _ := world.ObjectManager.CreateBuilding(1, "palisade", Vector3{X: 14, Z: 10}, data.NewSimpleUnit("palisade", 300, 2, "wood", nil))
+	if castle.ArmorType != "stone" || palisade.ArmorType != "wood" || palisade.Armor != 2 {
+		t.Fatalf("Expected armor from the XML, got %s and %s/%d", castle.ArmorType, palisade.ArmorType, palisade.Armor)
+	}
+
+	attacker := func(attackType string) *GameUnit {
+		def := data.NewSimpleUnit(attackType, 100, 0, "leather", nil)
+		def.Unit.Skills = []data.Skill{{
+			Type:       data.SkillType{Value: "attack"},
+			AttackType: &data.SkillAttackType{Value: attackType},
+		}}
+		unit, err := world.ObjectManager.CreateUnit(2, attackType, Vector3{X: 12, Z: 12}, def)
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		unit.AttackDamage = 20
+		return unit
+	}
+	combat := world.commandProcessor.combatSystem
+	swordsman, catapult := attacker("slashing"), attacker("impact")
+
+	if damage := combat.CalculateBuildingDamage(swordsman, castle); damage != 5 {
+		t.Errorf("Expected a sword to do half damage to a castle, got %d", damage)
+	}
+	if damage := combat.CalculateBuildingDamage(catapult, castle); damage != 35 {
+		t.Errorf("Expected a catapult to do double damage to a castle, got %d", damage)
+	}
+	if damage := combat.CalculateBuildingDamage(catapult, palisade); damage != 58 {
+		t.Errorf("Expected the wood multiplier on top of the siege bonus, got %d", damage)
+	}
+}
+
+// TestSurrenderEvaluation tests that losing the last production building makes
+// an outgunned AI concede, while other losses are only assessed
+func TestSurrenderEvaluation(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.GetPlayer(2).IsAI = true
+	var assessments []SurrenderAssessment
+	var defeated []int
+	world.SetEventSink(func(event GameEvent) {
+		switch event.Type {
+		case EventTypeSurrenderEvaluation:
+			assessments = append(assessments, event.Data.(SurrenderAssessment))
+		case EventTypePlayerDefeated:
+			defeated = append(defeated, event.PlayerID)
+		}
+	})
+
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	for i := 0; i < 8; i++ {
+		world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: float64(i + 2), Z: 4}, soldier)
+	}
+	world.ObjectManager.CreateUnit(2, "soldier", Vector3{X: 20, Z: 20}, soldier)
+	world.ObjectManager.CreateUnit(2, "worker", Vector3{X: 21, Z: 20}, data.NewSimpleUnit("worker", 50, 0, "leather", nil))
+
+	building := data.NewSimpleUnit("building", 100, 0, "stone", nil)
+	house, _ := world.ObjectManager.CreateBuilding(2, "house", Vector3{X: 24, Z: 24}, building)
+	castle, _ := world.ObjectManager.CreateBuilding(2, "castle", Vector3{X: 26, Z: 24}, building)
+	barracks, _ := world.ObjectManager.CreateBuilding(2, "barracks", Vector3{X: 28, Z: 24}, building)
+	if IsProductionBuilding(house) || !IsProductionBuilding(barracks) {
+		t.Fatal("Expected barracks, not houses, to count as production")
+	}
+
+	combat := world.commandProcessor.combatSystem
+	combat.ApplyBuildingDamage(house, 100)
+	if len(assessments) != 0 {
+		t.Errorf("Expected no evaluation for a house, got %+v", assessments)
+	}
+
+	combat.ApplyBuildingDamage(castle, 100)
+	if len(assessments) != 1 || assessments[0].ProductionLeft != 1 || assessments[0].Hopeless {
+		t.Fatalf("Expected the barracks to keep the game going, got %+v", assessments)
+	}
+
+	combat.ApplyBuildingDamage(barracks, 100)
+	if len(assessments) != 2 || !assessments[1].Hopeless || assessments[1].EnemyArmySize != 8 {
+		t.Fatalf("Expected an outgunned player without production to be hopeless, got %+v", assessments)
+	}
+	if len(defeated) != 1 || defeated[0] != 2 || world.GetPlayer(2).IsActive {
+		t.Errorf("Expected the AI to surrender, defeated %v", defeated)
+	}
+	if err := world.Surrender(2); err == nil {
+		t.Error("Expected a player to surrender only once")
+	}
+
+	// A human player with the army to rebuild is only assessed
+	world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 2, Z: 8}, data.NewSimpleUnit("worker", 50, 0, "leather", nil))
+	lastCastle, _ := world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 4, Z: 10}, building)
+	combat.ApplyBuildingDamage(lastCastle, 100)
+	if last := assessments[len(assessments)-1]; last.PlayerID != 1 || last.Hopeless || !last.CanRebuild {
+		t.Errorf("Expected player 1 to be able to rebuild, got %+v", last)
+	}
+	if !world.GetPlayer(1).IsActive {
+		t.Error("Expected player 1 to stay in the game")
+	}
+}
@@ -0,0 +1,106 @@
+package engine
+
+import "sync"
+
+// World streaming
+const (
+	WorldChunkSize   = 32  // Side in tiles of the chunks objects are bucketed and terrain is streamed in
+	StreamingMapSize = 256 // Map side in tiles from which renderers keep only chunks near the camera
+)
+
+// spatialBuckets files units and buildings by the chunk they stood in at the
+// end of the last tick, so renderers and other systems looking around a
+// point visit only the chunks there rather than every object on the map
+type spatialBuckets struct {
+	mutex     sync.RWMutex
+	recorded  bool
+	units     map[Vector2i][]*GameUnit
+	buildings map[Vector2i][]*GameBuilding
+}
+
+// recordSpatialBuckets refiles every unit and building by its chunk at the
+// end of a tick
+func (w *World) recordSpatialBuckets() {
+	units := make(map[Vector2i][]*GameUnit)
+	buildings := make(map[Vector2i][]*GameBuilding)
+	for _, player := range w.GetAllPlayers() {
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(player.ID) {
+			chunk := w.ChunkAt(unit.GetPosition())
+			units[chunk] = append(units[chunk], unit)
+		}
+		for _, building := range w.ObjectManager.GetBuildingsForPlayer(player.ID) {
+			chunk := w.ChunkAt(building.GetPosition())
+			buildings[chunk] = append(buildings[chunk], building)
+		}
+	}
+
+	buckets := &w.buckets
+	buckets.mutex.Lock()
+	defer buckets.mutex.Unlock()
+	buckets.units, buckets.buildings, buckets.recorded = units, buildings, true
+}
+
+// ChunkAt returns the chunk of a world position
+func (w *World) ChunkAt(position Vector3) Vector2i {
+	grid := w.WorldToGrid(position).Grid
+	return Vector2i{X: floorDiv(grid.X, WorldChunkSize), Y: floorDiv(grid.Y, WorldChunkSize)}
+}
+
+// ChunkCount returns how many chunks cover the map across and down
+func (w *World) ChunkCount() (int, int) {
+	return (w.Width + WorldChunkSize - 1) / WorldChunkSize, (w.Height + WorldChunkSize - 1) / WorldChunkSize
+}
+
+// UnitsInChunks returns the units that stood in the chunks from one corner
+// to the other, inclusive, at the end of the last tick. Units created since
+// show up after the next tick.
+func (w *World) UnitsInChunks(from, to Vector2i) []*GameUnit {
+	w.ensureSpatialBuckets()
+	buckets := &w.buckets
+	buckets.mutex.RLock()
+	defer buckets.mutex.RUnlock()
+
+	var units []*GameUnit
+	for y := from.Y; y <= to.Y; y++ {
+		for x := from.X; x <= to.X; x++ {
+			units = append(units, buckets.units[Vector2i{X: x, Y: y}]...)
+		}
+	}
+	return units
+}
+
+// BuildingsInChunks returns the buildings in the chunks from one corner to
+// the other, inclusive, as of the end of the last tick
+func (w *World) BuildingsInChunks(from, to Vector2i) []*GameBuilding {
+	w.ensureSpatialBuckets()
+	buckets := &w.buckets
+	buckets.mutex.RLock()
+	defer buckets.mutex.RUnlock()
+
+	var buildings []*GameBuilding
+	for y := from.Y; y <= to.Y; y++ {
+		for x := from.X; x <= to.X; x++ {
+			buildings = append(buildings, buckets.buildings[Vector2i{X: x, Y: y}]...)
+		}
+	}
+	return buildings
+}
+
+// ensureSpatialBuckets files the objects if no tick has yet
+func (w *World) ensureSpatialBuckets() {
+	w.buckets.mutex.RLock()
+	recorded := w.buckets.recorded
+	w.buckets.mutex.RUnlock()
+	if !recorded {
+		w.recordSpatialBuckets()
+	}
+}
+
+// floorDiv divides rounding toward negative infinity, so positions off the
+// map's top and left edges fall in chunks of their own
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}
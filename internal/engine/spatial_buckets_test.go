@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestSpatialBuckets tests that objects are found in the chunks they ended
+// the last tick in and only there
+func TestSpatialBuckets(t *testing.T) {
+	world, err := NewHeadlessWorld(96, 96)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	tileSize := float64(world.GetTileSize())
+	def := data.NewSimpleUnit("worker", 50, 0, "leather", nil)
+	near, _ := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 4 * tileSize, Z: 4 * tileSize}, def)
+	far, _ := world.ObjectManager.CreateUnit(2, "worker", Vector3{X: 70 * tileSize, Z: 40 * tileSize}, def)
+
+	if chunk := world.ChunkAt(far.GetPosition()); chunk != (Vector2i{X: 2, Y: 1}) {
+		t.Errorf("Expected tile (70, 40) in chunk (2, 1), got %v", chunk)
+	}
+	if across, down := world.ChunkCount(); across != 3 || down != 3 {
+		t.Errorf("Expected a 96 tile map in 3x3 chunks, got %dx%d", across, down)
+	}
+
+	// Before any tick the buckets are filled on demand
+	units := world.UnitsInChunks(Vector2i{}, Vector2i{})
+	if len(units) != 1 || units[0] != near {
+		t.Fatalf("Expected only the near unit in chunk (0, 0), got %d units", len(units))
+	}
+	if units := world.UnitsInChunks(Vector2i{}, Vector2i{X: 2, Y: 2}); len(units) != 2 {
+		t.Errorf("Expected both units over the whole map, got %d", len(units))
+	}
+
+	// A unit moving is refiled at the end of the tick
+	near.Position = Vector3{X: 40 * tileSize, Z: 70 * tileSize}
+	if units := world.UnitsInChunks(Vector2i{}, Vector2i{}); len(units) != 1 {
+		t.Errorf("Expected the unit still filed where it was before the tick, got %d units", len(units))
+	}
+	world.recordSpatialBuckets()
+	if units := world.UnitsInChunks(Vector2i{}, Vector2i{}); len(units) != 0 {
+		t.Errorf("Expected chunk (0, 0) empty after the tick, got %d units", len(units))
+	}
+	if units := world.UnitsInChunks(Vector2i{X: 1, Y: 2}, Vector2i{X: 1, Y: 2}); len(units) != 1 || units[0] != near {
+		t.Errorf("Expected the unit moved into chunk (1, 2)")
+	}
+}
@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// Statistic names recorded by the StatsRecorder. Per-type and per-resource
+// counters append ":<name>", e.g. "units_trained:worker" or "resources_gathered:gold".
+const (
+	StatUnitsTrained       = "units_trained"       // Units that appeared after the first sample
+	StatUnitsLost          = "units_lost"          // Units that died or were removed
+	StatBuildingsCompleted = "buildings_completed" // Buildings that finished construction
+	StatBuildingsLost      = "buildings_lost"      // Buildings that were destroyed or removed
+	StatResourcesGathered  = "resources_gathered"  // Resource increases
+	StatResourcesSpent     = "resources_spent"     // Resource decreases
+	StatTechsResearched    = "techs_researched"    // Technologies researched
+	StatUnitsPeak          = "units_peak"          // Most units alive at once
+	StatWon                = "won"                 // 1 once the player achieved victory
+	StatDefeated           = "defeated"            // 1 once the player was defeated
+	StatMatchSeconds       = "match_seconds"       // Game time of the last sample
+)
+
+// StatKey returns the per-type counter of a statistic, e.g. StatKey(StatUnitsTrained, "worker")
+func StatKey(stat, name string) string {
+	return stat + ":" + name
+}
+
+// PlayerStats holds the counters recorded for one player
+type PlayerStats map[string]int
+
+// Get returns a counter, 0 if it was never recorded
+func (s PlayerStats) Get(stat string) int {
+	return s[stat]
+}
+
+// StatsRecorder accumulates per-player match statistics by sampling the world
+// and listening to game events. Call Sample regularly (e.g. once per second);
+// changes between samples are attributed to the player owning the objects.
+// It also keeps every resource transaction event for post-game analysis.
+type StatsRecorder struct {
+	world        *World
+	stats        map[int]PlayerStats
+	transactions []ResourceEvent        // Resource transaction events, oldest first
+	units        map[int]int            // Unit ID -> owner, alive at the last sample
+	unitTypes    map[int]string         // Unit ID -> type
+	buildings    map[int]*GameBuilding  // Building ID -> building at the last sample
+	built        map[int]bool           // Building ID -> finished at the last sample
+	resources    map[int]map[string]int // Player ID -> resources at the last sample
+	history      map[int][]ValueSample  // Player ID -> army and economy samples, oldest first
+	sampled      bool
+	mutex        sync.RWMutex
+}
+
+// NewStatsRecorder creates a recorder for a world
+func NewStatsRecorder(world *World) *StatsRecorder {
+	return &StatsRecorder{
+		world:     world,
+		stats:     make(map[int]PlayerStats),
+		units:     make(map[int]int),
+		unitTypes: make(map[int]string),
+		buildings: make(map[int]*GameBuilding),
+		built:     make(map[int]bool),
+		resources: make(map[int]map[string]int),
+	}
+}
+
+// Sample compares the world with the previous sample and updates the counters.
+// The first sample only records the starting state.
+func (sr *StatsRecorder) Sample() {
+	players := sr.world.GetAllPlayers()
+
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	seconds := int(sr.world.GetGameTime() / time.Second)
+	units := make(map[int]int)
+	buildings := make(map[int]*GameBuilding)
+	for playerID, player := range players {
+		stats := sr.playerStats(playerID)
+		stats[StatMatchSeconds] = seconds
+
+		alive := 0
+		values := ValueSample{GameTime: sr.world.GetGameTime(), Actions: sr.world.commandLog.actionCount(playerID)}
+		for id, unit := range sr.world.ObjectManager.GetUnitsForPlayer(playerID) {
+			if !unit.IsAlive() {
+				continue
+			}
+			alive++
+			if unit.AttackDamage > 0 && !IsWorkerType(unit.UnitType) {
+				values.Army += definitionValue(unit.UnitDef)
+			} else {
+				values.Economy += definitionValue(unit.UnitDef)
+			}
+			units[id] = playerID
+			if _, known := sr.units[id]; !known && sr.sampled {
+				stats[StatUnitsTrained]++
+				stats[StatKey(StatUnitsTrained, unit.UnitType)]++
+			}
+			sr.unitTypes[id] = unit.UnitType
+		}
+		if alive > stats[StatUnitsPeak] {
+			stats[StatUnitsPeak] = alive
+		}
+
+		for id, building := range sr.world.ObjectManager.GetBuildingsForPlayer(playerID) {
+			buildings[id] = building
+			if building.IsBuilt {
+				values.Economy += definitionValue(building.UnitDef)
+			}
+			if building.IsBuilt && !sr.built[id] && sr.sampled {
+				stats[StatBuildingsCompleted]++
+				stats[StatKey(StatBuildingsCompleted, building.BuildingType)]++
+			}
+			sr.built[id] = building.IsBuilt
+		}
+
+		current := make(map[string]int, len(player.Resources))
+		for resource, amount := range player.Resources {
+			current[resource] = amount
+			if !sr.sampled {
+				continue
+			}
+			if change := amount - sr.resources[playerID][resource]; change > 0 {
+				stats[StatResourcesGathered] += change
+				stats[StatKey(StatResourcesGathered, resource)] += change
+			} else if change < 0 {
+				stats[StatResourcesSpent] -= change
+				stats[StatKey(StatResourcesSpent, resource)] -= change
+			}
+		}
+		sr.resources[playerID] = current
+		sr.recordValues(playerID, values)
+	}
+
+	for id, owner := range sr.units {
+		if _, alive := units[id]; !alive {
+			stats := sr.playerStats(owner)
+			stats[StatUnitsLost]++
+			stats[StatKey(StatUnitsLost, sr.unitTypes[id])]++
+			delete(sr.unitTypes, id)
+		}
+	}
+	for id, building := range sr.buildings {
+		if _, exists := buildings[id]; !exists {
+			stats := sr.playerStats(building.PlayerID)
+			stats[StatBuildingsLost]++
+			stats[StatKey(StatBuildingsLost, building.BuildingType)]++
+			delete(sr.built, id)
+		}
+	}
+	sr.units = units
+	sr.buildings = buildings
+	sr.sampled = true
+}
+
+// HandleEvent records statistics carried by game events
+func (sr *StatsRecorder) HandleEvent(event GameEvent) {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	switch event.Type {
+	case EventTypeTechResearched:
+		sr.playerStats(event.PlayerID)[StatTechsResearched]++
+	case EventTypePlayerVictory:
+		sr.playerStats(event.PlayerID)[StatWon] = 1
+	case EventTypePlayerDefeated:
+		sr.playerStats(event.PlayerID)[StatDefeated] = 1
+	case EventTypeResourceGained, EventTypeResourceSpent:
+		if transaction, ok := event.Data.(ResourceEvent); ok {
+			sr.transactions = append(sr.transactions, transaction)
+		}
+	}
+}
+
+// SetOutcome records the match result of a player when no event reported it
+func (sr *StatsRecorder) SetOutcome(playerID int, won bool) {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	stats := sr.playerStats(playerID)
+	if won {
+		stats[StatWon] = 1
+	} else {
+		stats[StatDefeated] = 1
+	}
+}
+
+// Stats returns a copy of a player's counters
+func (sr *StatsRecorder) Stats(playerID int) PlayerStats {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+
+	stats := make(PlayerStats, len(sr.stats[playerID]))
+	for stat, value := range sr.stats[playerID] {
+		stats[stat] = value
+	}
+	return stats
+}
+
+// playerStats returns the counters of a player, creating them if needed (lock must be held)
+func (sr *StatsRecorder) playerStats(playerID int) PlayerStats {
+	stats, exists := sr.stats[playerID]
+	if !exists {
+		stats = make(PlayerStats)
+		sr.stats[playerID] = stats
+	}
+	return stats
+}
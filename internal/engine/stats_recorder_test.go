@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+func TestStatsRecorderSamplesWorldChanges(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	worker := data.NewSimpleUnit("worker", 50, 0, "leather", map[string]int{"gold": 50})
+	castle := data.NewSimpleUnit("castle", 1000, 10, "stone", nil)
+
+	starting, err := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 2, Z: 2}, worker)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	recorder := NewStatsRecorder(world)
+	recorder.Sample() // Starting units are not counted as trained
+
+	for i := 0; i < 3; i++ {
+		if _, err := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 4, Z: 4}, worker); err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+	}
+	building, err := world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 10, Z: 10}, castle)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	if err := world.DeductResources(1, map[string]int{"gold": 150}, "test"); err != nil {
+		t.Fatalf("DeductResources failed: %v", err)
+	}
+	recorder.Sample()
+
+	building.IsBuilt = true
+	if err := world.ObjectManager.RemoveUnit(starting.ID); err != nil {
+		t.Fatalf("RemoveUnit failed: %v", err)
+	}
+	if err := world.AddResources(1, map[string]int{"wood": 40}, "test"); err != nil {
+		t.Fatalf("AddResources failed: %v", err)
+	}
+	recorder.Sample()
+
+	if err := world.ObjectManager.RemoveBuilding(building.ID); err != nil {
+		t.Fatalf("RemoveBuilding failed: %v", err)
+	}
+	recorder.Sample()
+	recorder.HandleEvent(GameEvent{Type: EventTypeTechResearched, PlayerID: 1})
+
+	stats := recorder.Stats(1)
+	expected := map[string]int{
+		StatUnitsTrained:                          3,
+		StatKey(StatUnitsTrained, "worker"):       3,
+		StatUnitsLost:                             1,
+		StatUnitsPeak:                             4,
+		StatBuildingsCompleted:                    1,
+		StatKey(StatBuildingsCompleted, "castle"): 1,
+		StatBuildingsLost:                         1,
+		StatKey(StatResourcesSpent, "gold"):       150,
+		StatKey(StatResourcesGathered, "wood"):    40,
+		StatTechsResearched:                       1,
+	}
+	for stat, want := range expected {
+		if got := stats.Get(stat); got != want {
+			t.Errorf("Expected %s = %d, got %d", stat, want, got)
+		}
+	}
+	if recorder.Stats(2).Get(StatUnitsTrained) != 0 {
+		t.Error("Expected no statistics for player 2")
+	}
+}
+
+func TestStatsRecorderTransactionLog(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	recorder := NewStatsRecorder(world)
+	world.SetEventSink(recorder.HandleEvent)
+
+	if err := world.AddResources(1, map[string]int{"gold": 100}, "resource_dropoff"); err != nil {
+		t.Fatalf("AddResources failed: %v", err)
+	}
+	world.Update(10 * time.Second)
+	if err := world.DeductResources(1, map[string]int{"gold": 60, "wood": 30}, "unit_production"); err != nil {
+		t.Fatalf("DeductResources failed: %v", err)
+	}
+	if err := world.AddResources(2, map[string]int{"wood": 50}, "resource_dropoff"); err != nil {
+		t.Fatalf("AddResources failed: %v", err)
+	}
+	world.Update(15 * time.Second)
+	if err := world.AddResources(1, map[string]int{"gold": 25}, "resource_dropoff"); err != nil {
+		t.Fatalf("AddResources failed: %v", err)
+	}
+
+	if all := recorder.Transactions(TransactionQuery{}); len(all) != 5 {
+		t.Fatalf("Expected 5 logged transactions, got %v", all)
+	}
+	player1 := recorder.Transactions(TransactionQuery{PlayerID: 1})
+	if len(player1) != 4 || player1[0].GameTime != 0 || player1[3].GameTime != 25*time.Second {
+		t.Errorf("Expected player 1's 4 transactions at game time 0 to 25s, got %v", player1)
+	}
+	if spent := recorder.Transactions(TransactionQuery{PlayerID: 1, TransactionType: "deduction", Resource: "gold"}); len(spent) != 1 || spent[0].Amount != 60 {
+		t.Errorf("Expected one 60 gold deduction, got %v", spent)
+	}
+
+	sources := recorder.TransactionSources(TransactionQuery{PlayerID: 1})
+	if sources["resource_dropoff"]["gold"] != 125 || sources["unit_production"]["wood"] != 30 || len(sources) != 2 {
+		t.Errorf("Unexpected per-source totals %v", sources)
+	}
+
+	buckets := recorder.TransactionBuckets(TransactionQuery{PlayerID: 1}, 10*time.Second)
+	if len(buckets) != 3 {
+		t.Fatalf("Expected 3 ten-second buckets, got %v", buckets)
+	}
+	if buckets[0].Gained["gold"] != 100 || buckets[1].Spent["gold"] != 60 || buckets[1].Spent["wood"] != 30 || buckets[2].Gained["gold"] != 25 {
+		t.Errorf("Unexpected buckets %v", buckets)
+	}
+	if late := recorder.TransactionBuckets(TransactionQuery{From: 20 * time.Second, To: 40 * time.Second}, 10*time.Second); len(late) != 2 || late[0].Gained["gold"] != 25 || len(late[1].Gained) != 0 {
+		t.Errorf("Expected the 25 gold in the first of two late buckets, got %v", late)
+	}
+}
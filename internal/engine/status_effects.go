@@ -24,6 +24,14 @@ func (sem *StatusEffectManager) SetWorld(world *World) {
 	sem.world = world
 }
 
+// now returns the time on the world clock, which effects tick by
+func (sem *StatusEffectManager) now() time.Time {
+	if sem.world != nil {
+		return sem.world.now()
+	}
+	return time.Now()
+}
+
 // ActiveStatusEffect represents an active effect on a unit
 type ActiveStatusEffect struct {
 	Effect      StatusEffect  `json:"effect"`
@@ -202,7 +210,7 @@ func (sem *StatusEffectManager) addStackableEffect(unit *GameUnit, effect Status
 		if activeEffect.Effect.ID == effect.ID {
 			// Increase stack count
 			sem.unitEffects[unit.ID][i].StackCount++
-			sem.unitEffects[unit.ID][i].StartTime = time.Now() // Refresh duration
+			sem.unitEffects[unit.ID][i].StartTime = sem.now() // Refresh duration
 			return
 		}
 	}
@@ -210,8 +218,8 @@ func (sem *StatusEffectManager) addStackableEffect(unit *GameUnit, effect Status
 	// Add new effect
 	newEffect := ActiveStatusEffect{
 		Effect:     effect,
-		StartTime:  time.Now(),
-		LastTick:   time.Now(),
+		StartTime:  sem.now(),
+		LastTick:   sem.now(),
 		TicksLeft:  int(effect.Duration / effect.TickInterval),
 		Source:     source,
 		StackCount: 1,
@@ -228,8 +236,8 @@ func (sem *StatusEffectManager) addOrRefreshEffect(unit *GameUnit, effect Status
 	for i, activeEffect := range unitEffects {
 		if activeEffect.Effect.ID == effect.ID {
 			// Refresh existing effect
-			sem.unitEffects[unit.ID][i].StartTime = time.Now()
-			sem.unitEffects[unit.ID][i].LastTick = time.Now()
+			sem.unitEffects[unit.ID][i].StartTime = sem.now()
+			sem.unitEffects[unit.ID][i].LastTick = sem.now()
 			sem.unitEffects[unit.ID][i].TicksLeft = int(effect.Duration / effect.TickInterval)
 			return
 		}
@@ -238,8 +246,8 @@ func (sem *StatusEffectManager) addOrRefreshEffect(unit *GameUnit, effect Status
 	// Add new effect
 	newEffect := ActiveStatusEffect{
 		Effect:     effect,
-		StartTime:  time.Now(),
-		LastTick:   time.Now(),
+		StartTime:  sem.now(),
+		LastTick:   sem.now(),
 		TicksLeft:  int(effect.Duration / effect.TickInterval),
 		Source:     source,
 		StackCount: 1,
@@ -258,20 +266,20 @@ func (sem *StatusEffectManager) Update(deltaTime time.Duration) {
 
 		for _, effect := range effects {
 			// Check if effect should tick
-			if time.Since(effect.LastTick) >= effect.Effect.TickInterval {
+			if sem.now().Sub(effect.LastTick) >= effect.Effect.TickInterval {
 				// Get the unit
 				unit := sem.getUnitByID(unitID)
 				if unit != nil && unit.IsAlive() {
 					// Process effect tick
 					sem.processEffectTick(unit, &effect)
 
-					effect.LastTick = time.Now()
+					effect.LastTick = sem.now()
 					effect.TicksLeft--
 				}
 			}
 
 			// Check if effect is still active
-			if effect.TicksLeft > 0 && time.Since(effect.StartTime) < effect.Effect.Duration {
+			if effect.TicksLeft > 0 && sem.now().Sub(effect.StartTime) < effect.Effect.Duration {
 				remainingEffects = append(remainingEffects, effect)
 			} else {
 				// Effect expired, remove its influence
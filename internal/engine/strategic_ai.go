@@ -5,7 +5,10 @@ import (
 	"math"
 	"math/rand"
 	"sort"
+	"sync"
 	"time"
+
+	"teraglest/internal/data"
 )
 
 // AIPersonality defines different AI behavior characteristics
@@ -198,6 +201,12 @@ type StrategicAI struct {
 	lastUpdateTime  time.Time              // Last AI update time
 	updateInterval  time.Duration          // How often to make decisions
 	random          *rand.Rand             // Random number generator for decisions
+	intel           *IntelMemory           // What the AI has seen of the map
+	influence       *InfluenceMap          // Who holds which part of the map
+	faction         *FactionAIProfile      // How the AI plays its faction
+	expansion       *ExpansionPlan         // Current or last expansion
+	contested       []contestedSite        // Expansion sites given up to the enemy
+	lastHelpRequest time.Time              // When the AI last asked its allies for help
 }
 
 // AIDifficulty represents different AI skill levels
@@ -236,7 +245,19 @@ func NewStrategicAI(playerID int, world *World, personality AIPersonality, diffi
 		decisions:      make([]StrategicDecision, 0),
 		updateInterval: 5 * time.Second, // Update every 5 seconds
 		random:         rand.New(rand.NewSource(time.Now().UnixNano() + int64(playerID))),
+		intel:          NewIntelMemory(playerID, world),
+	}
+	ai.influence = NewInfluenceMap(playerID, world, ai.intel)
+
+	// The faction's profile adapts the personality to how the faction plays
+	var faction *data.FactionDefinition
+	if world != nil {
+		if player := world.GetPlayer(playerID); player != nil {
+			faction = player.FactionData
+		}
 	}
+	ai.faction = NewFactionAIProfile(faction)
+	ai.personality = ai.faction.Adapt(personality)
 
 	// Initialize sub-managers
 	ai.economicMgr = NewEconomicManager(playerID, world, ai)
@@ -265,11 +286,22 @@ func NewStrategicAI(playerID int, world *World, personality AIPersonality, diffi
 
 // Update performs strategic AI decision-making
 func (ai *StrategicAI) Update(deltaTime time.Duration) {
+	// An expansion under way is looked after between strategic updates
+	ai.updateExpansion()
+
+	// The influence map follows the armies more closely than strategy does
+	if ai.world.now().Sub(ai.influence.Updated()) >= influenceInterval {
+		ai.influence.Update()
+	}
+
 	// Check if it's time for a strategic update
 	if time.Since(ai.lastUpdateTime) < ai.updateInterval {
 		return
 	}
 
+	// Take in what our units and buildings see, and let old sightings fade
+	ai.intel.Update()
+
 	// Update strategic state assessment
 	ai.updateStrategyState()
 
@@ -396,11 +428,27 @@ func (ai *StrategicAI) assessThreatLevel() float64 {
 	// Recent attacks or aggressive actions
 	recentThreat := ai.assessRecentThreats()
 
+	// Enemy towers within reach of our base
+	towerThreat := ai.assessEnemyTowerThreat()
+
 	// Normalize and combine
 	proximityThreat := math.Min(float64(nearbyEnemies)/20.0, 1.0)
 	strengthThreat := enemyStrength
 
-	return math.Max(proximityThreat, math.Max(strengthThreat, recentThreat))
+	return math.Max(math.Max(proximityThreat, towerThreat), math.Max(strengthThreat, recentThreat))
+}
+
+// assessEnemyTowerThreat rates the most dangerous known enemy tower that
+// can fire on one of our buildings
+func (ai *StrategicAI) assessEnemyTowerThreat() float64 {
+	ownBuildings := ai.world.ObjectManager.GetBuildingsForPlayer(ai.playerID)
+	threat := 0.0
+	for _, sighting := range ai.intel.Buildings() {
+		if sighting.Defensive && ai.world.towerReaches(sighting.Building, ownBuildings) {
+			threat = math.Max(threat, ai.world.towerThreat(sighting.Building))
+		}
+	}
+	return threat
 }
 
 // assessResourceSecurity evaluates security of resource access
@@ -655,12 +703,11 @@ func (ai *StrategicAI) logDecision(decision StrategicDecision) {
 
 // executeExpansionStrategy implements expansion decisions
 func (ai *StrategicAI) executeExpansionStrategy(params map[string]interface{}) {
-	// Find good expansion locations
-	expansionSites := ai.findExpansionSites()
-
-	if len(expansionSites) > 0 {
-		// Order construction of expansion base
-		ai.orderExpansionBase(expansionSites[0])
+	// One expansion at a time; the plan under way carries on by itself
+	if ai.expansion == nil || !ai.expansion.Active() {
+		if sites := ai.findExpansionSites(); len(sites) > 0 {
+			ai.startExpansion(sites[0])
+		}
 	}
 
 	// Also order worker production to support expansion
@@ -760,8 +807,22 @@ func (ai *StrategicAI) assessUnitQuality() float64 {
 }
 
 func (ai *StrategicAI) countNearbyEnemyUnits() int {
-	// Count enemy units within threatening range
-	return 0 // Placeholder
+	// Count the armed enemy units last seen within threatening range of our buildings
+	reach := intelThreatRange * float64(ai.world.GetTileSize())
+	ownBuildings := ai.world.ObjectManager.GetBuildingsForPlayer(ai.playerID)
+	count := 0
+	for _, unit := range ai.intel.Units() {
+		if !unit.Armed {
+			continue
+		}
+		for _, building := range ownBuildings {
+			if ai.world.CalculateDistance(unit.Position, building.Position) <= reach {
+				count++
+				break
+			}
+		}
+	}
+	return count
 }
 
 func (ai *StrategicAI) assessEnemyStrength() float64 {
@@ -819,39 +880,7 @@ func (ai *StrategicAI) getMaxPopulation() int {
 	return 50 // Placeholder
 }
 
-// Stub implementations for expansion/research/scouting
-
-func (ai *StrategicAI) findExpansionSites() []Vector3 {
-	// Find good locations for expansion bases
-	return []Vector3{{X: 20, Y: 0, Z: 20}} // Placeholder
-}
-
-func (ai *StrategicAI) orderExpansionBase(location Vector3) {
-	// Find a worker to send for construction
-	workers := ai.findAvailableWorkers()
-	if len(workers) == 0 {
-		return
-	}
-
-	worker := workers[0]
-
-	// Create build command for expansion building (e.g., town center or barracks)
-	expansionBuildingType := "castle" // Default expansion building
-	if ai.personality.EconomicFocus > 0.6 {
-		expansionBuildingType = "town_center"
-	}
-
-	command := UnitCommand{
-		Type:     CommandBuild,
-		Target:   &location,
-		Parameters: map[string]interface{}{
-			"building_type": expansionBuildingType,
-		},
-	}
-
-	// Issue the build command
-	ai.world.commandProcessor.IssueCommand(worker.ID, command)
-}
+// Stub implementations for research/scouting
 
 // orderWorkerProduction orders production of additional workers
 func (ai *StrategicAI) orderWorkerProduction() {
@@ -892,7 +921,7 @@ func (ai *StrategicAI) findAvailableWorkers() []*GameUnit {
 	var workers []*GameUnit
 
 	for _, unit := range units {
-		if unit.UnitType == "worker" && unit.State == UnitStateIdle && unit.IsAlive() {
+		if ai.faction.IsWorker(unit.UnitType) && unit.State == UnitStateIdle && unit.IsAlive() {
 			workers = append(workers, unit)
 		}
 	}
@@ -900,9 +929,24 @@ func (ai *StrategicAI) findAvailableWorkers() []*GameUnit {
 	return workers
 }
 
+// identifyPriorityUpgrades returns the upgrades to research, most important
+// first: the faction's key upgrades once their timing has come, then its other
+// upgrades, skipping those already researched
 func (ai *StrategicAI) identifyPriorityUpgrades() []string {
-	// Identify most important upgrades to research
-	return []string{"weapon_upgrade"} // Placeholder
+	var techTree *TechnologyTree
+	if ai.world.productionSys != nil {
+		techTree = ai.world.productionSys.GetTechnologyTree()
+	}
+	var upgrades []string
+	for _, upgrade := range ai.faction.DueUpgrades(ai.world.GetGameTime()) {
+		if techTree == nil || !techTree.HasTechnology(ai.playerID, upgrade) {
+			upgrades = append(upgrades, upgrade)
+		}
+	}
+	if len(upgrades) == 0 && len(ai.faction.TechTimings) == 0 && len(ai.faction.Upgrades) == 0 {
+		return []string{"weapon_upgrade"} // Placeholder
+	}
+	return upgrades
 }
 
 func (ai *StrategicAI) orderUpgrade(upgradeType string) {
@@ -969,6 +1013,24 @@ func (ai *StrategicAI) GetStrategyState() StrategyState {
 	return ai.state
 }
 
+// GetFactionProfile returns how the AI plays its faction
+func (ai *StrategicAI) GetFactionProfile() *FactionAIProfile {
+	if ai == nil {
+		return nil
+	}
+	return ai.faction
+}
+
+// GetIntel returns the AI's memory of what it has seen
+func (ai *StrategicAI) GetIntel() *IntelMemory {
+	return ai.intel
+}
+
+// GetInfluence returns the AI's influence map
+func (ai *StrategicAI) GetInfluence() *InfluenceMap {
+	return ai.influence
+}
+
 // GetRecentDecisions returns recent strategic decisions made
 func (ai *StrategicAI) GetRecentDecisions() []StrategicDecision {
 	return ai.decisions
@@ -979,9 +1041,9 @@ func (ai *StrategicAI) SetDifficulty(difficulty AIDifficulty) {
 	ai.difficulty = difficulty
 }
 
-// SetPersonality changes the AI personality
+// SetPersonality changes the AI personality, adapted to the AI's faction
 func (ai *StrategicAI) SetPersonality(personality AIPersonality) {
-	ai.personality = personality
+	ai.personality = ai.faction.Adapt(personality)
 }
 
 // StrategicAIManager coordinates all AI players in the game
@@ -990,15 +1052,23 @@ type StrategicAIManager struct {
 	aiPlayers   map[int]*StrategicAI     // AI instance for each AI player
 	updateTimer time.Duration            // Time since last update
 	updateRate  time.Duration            // How often to update AI (reduces CPU load)
+
+	interruptMutex sync.Mutex                  // Guards pending, filled as events are raised
+	pending        map[int][]threatInterrupt // Interrupts each AI player answers on its next tick
 }
 
 // NewStrategicAIManager creates a new strategic AI manager
 func NewStrategicAIManager(world *World) *StrategicAIManager {
-	return &StrategicAIManager{
+	mgr := &StrategicAIManager{
 		world:       world,
 		aiPlayers:   make(map[int]*StrategicAI),
 		updateRate:  time.Millisecond * 500, // Update AI every 500ms
+		pending:     make(map[int][]threatInterrupt),
+	}
+	if world != nil {
+		world.AddEventListener(mgr.onWorldEvent)
 	}
+	return mgr
 }
 
 // InitializeAIPlayer creates and initializes AI for a player
@@ -1020,12 +1090,27 @@ func (mgr *StrategicAIManager) InitializeAIPlayer(playerID int, personality AIPe
 	// Create strategic AI instance
 	ai := NewStrategicAI(playerID, mgr.world, personality, difficulty)
 	mgr.aiPlayers[playerID] = ai
+	mgr.interruptMutex.Lock()
+	mgr.pending[playerID] = nil
+	mgr.interruptMutex.Unlock()
 
 	return nil
 }
 
 // Update updates all AI players
 func (mgr *StrategicAIManager) Update(deltaTime time.Duration) {
+	// Attacks are answered on the tick after they happen
+	playerIDs := make([]int, 0, len(mgr.aiPlayers))
+	for playerID := range mgr.aiPlayers {
+		playerIDs = append(playerIDs, playerID)
+	}
+	sort.Ints(playerIDs)
+	for _, playerID := range playerIDs {
+		if interrupts := mgr.takeInterrupts(playerID); len(interrupts) > 0 {
+			mgr.aiPlayers[playerID].HandleInterrupts(interrupts)
+		}
+	}
+
 	mgr.updateTimer += deltaTime
 
 	// Only update AI at reduced frequency to save CPU
@@ -1052,6 +1137,9 @@ func (mgr *StrategicAIManager) Update(deltaTime time.Duration) {
 // RemoveAIPlayer removes AI control for a player (e.g., when player is defeated)
 func (mgr *StrategicAIManager) RemoveAIPlayer(playerID int) {
 	delete(mgr.aiPlayers, playerID)
+	mgr.interruptMutex.Lock()
+	delete(mgr.pending, playerID)
+	mgr.interruptMutex.Unlock()
 }
 
 // GetAIPlayer returns the AI instance for a player
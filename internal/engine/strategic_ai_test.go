@@ -236,6 +236,56 @@ func TestWorldAIIntegration(t *testing.T) {
 	}
 }
 
+// TestAISkirmishSettings tests that AI slots start with their configured
+// personality and difficulty, and that the resource handicap scales their
+// starting resources and income
+func TestAISkirmishSettings(t *testing.T) {
+	assets := data.NewMemoryAssetProvider()
+	assets.AddFaction("tech", data.Faction{StartingResources: []data.StartingResource{{Name: "gold", Amount: 400}}})
+	settings := GameSettings{
+		PlayerFactions:     map[int]string{1: "tech"},
+		AIFactions:         map[int]string{2: "tech", 3: "tech"},
+		AISlots:            map[int]AISlotSettings{2: {Personality: "aggressive", Difficulty: "hard", Handicap: 50}},
+		MaxPlayers:         4,
+		GameSpeed:          1.0,
+		ResourceMultiplier: 1.0,
+	}
+	world, err := NewWorld(settings, &data.TechTree{}, assets)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	if err := world.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize world: %v", err)
+	}
+
+	ai := world.strategicAIMgr.GetAIPlayer(2)
+	if ai == nil || ai.GetPersonality().Name != "Aggressive" || ai.difficulty != DifficultyHard {
+		t.Fatalf("Expected a hard, aggressive AI for player 2, got %+v", ai)
+	}
+	if ai := world.strategicAIMgr.GetAIPlayer(3); ai == nil || ai.GetPersonality().Name != "Balanced" || ai.difficulty != DifficultyNormal {
+		t.Errorf("Expected the default AI for player 3, got %+v", ai)
+	}
+	if world.strategicAIMgr.GetAIPlayer(1) != nil {
+		t.Error("Expected no AI for the human player")
+	}
+
+	if gold := world.GetPlayer(2).Resources["gold"]; gold != 200 {
+		t.Errorf("Expected half the starting gold for the handicapped AI, got %d", gold)
+	}
+	if gold := world.GetPlayer(3).Resources["gold"]; gold != 400 {
+		t.Errorf("Expected full starting gold without handicap, got %d", gold)
+	}
+	mine := &GameBuilding{PlayerID: 2, UpgradeLevel: 1}
+	if generated := world.calculateResourceGeneration(10, 10*time.Second, mine); generated != 50 {
+		t.Errorf("Expected the handicap to halve income, got %d", generated)
+	}
+
+	settings.AISlots[2] = AISlotSettings{Difficulty: "impossible"}
+	if err := validateGameSettings(GameSettings{TechTreePath: "techs", AIFactions: settings.AIFactions, AISlots: settings.AISlots}); err == nil {
+		t.Error("Expected an unknown difficulty to be rejected")
+	}
+}
+
 // TestAICommandExecution tests that AI decisions result in actual commands
 func TestAICommandExecution(t *testing.T) {
 	world := createTestWorldForAI()
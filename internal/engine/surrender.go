@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"fmt"
+)
+
+// surrenderArmyRatio is the share of the strongest enemy army below which a
+// player without production has no way back
+const surrenderArmyRatio = 0.25
+
+// SurrenderAssessment is a player's outlook after losing a key building
+type SurrenderAssessment struct {
+	PlayerID       int
+	LostBuilding   string // Type of the key building that was destroyed
+	ProductionLeft int    // Production buildings still standing
+	ArmySize       int    // Living non-worker units
+	EnemyArmySize  int    // Army size of the strongest enemy
+	CanRebuild     bool   // A worker is alive and the lost building is affordable
+	Hopeless       bool   // No production and no realistic way to recover
+}
+
+// IsProductionBuilding reports whether a building produces units, from its
+// XML commands or, without a definition, from its type
+func IsProductionBuilding(building *GameBuilding) bool {
+	if building.UnitDef != nil && len(building.UnitDef.Unit.Commands) > 0 {
+		for _, command := range building.UnitDef.Unit.Commands {
+			if command.ProducedUnit != nil {
+				return true
+			}
+		}
+		return false
+	}
+	switch building.BuildingType {
+	case "castle", "town_center", "barracks":
+		return true
+	}
+	return false
+}
+
+// EvaluateSurrender assesses whether a player can still recover after losing
+// a building of the given type
+func (w *World) EvaluateSurrender(playerID int, lostBuilding string) SurrenderAssessment {
+	assessment := SurrenderAssessment{
+		PlayerID:     playerID,
+		LostBuilding: lostBuilding,
+		ArmySize:     w.ArmySize(playerID),
+	}
+	for _, building := range w.ObjectManager.GetBuildingsForPlayer(playerID) {
+		if building.IsAlive() && IsProductionBuilding(building) {
+			assessment.ProductionLeft++
+		}
+	}
+
+	for _, player := range w.GetAllPlayers() {
+		if player.ID == playerID || !player.IsActive || w.AreAllied(playerID, player.ID) {
+			continue
+		}
+		if size := w.ArmySize(player.ID); size > assessment.EnemyArmySize {
+			assessment.EnemyArmySize = size
+		}
+	}
+
+	hasWorker := false
+	for _, unit := range w.ObjectManager.GetUnitsForPlayer(playerID) {
+		if unit.IsAlive() && IsWorkerType(unit.UnitType) && unit.GarrisonedIn == 0 {
+			hasWorker = true
+			break
+		}
+	}
+	assessment.CanRebuild = hasWorker && w.canAfford(playerID, w.commandProcessor.getBuildingCost(lostBuilding, playerID))
+
+	outgunned := float64(assessment.ArmySize) < surrenderArmyRatio*float64(assessment.EnemyArmySize)
+	assessment.Hopeless = assessment.ProductionLeft == 0 && (!assessment.CanRebuild || outgunned)
+	return assessment
+}
+
+// handleBuildingDestroyed evaluates surrender when a player loses a key
+// building; AI players concede a hopeless game, others are told where they stand
+func (w *World) handleBuildingDestroyed(building *GameBuilding) {
+	if !IsProductionBuilding(building) {
+		return
+	}
+	player := w.GetPlayer(building.PlayerID)
+	if player == nil || !player.IsActive {
+		return
+	}
+
+	assessment := w.EvaluateSurrender(building.PlayerID, building.BuildingType)
+	message := fmt.Sprintf("%s destroyed, %d production buildings left", building.BuildingType, assessment.ProductionLeft)
+	if assessment.Hopeless {
+		message = fmt.Sprintf("%s destroyed and no production left: the game is lost", building.BuildingType)
+	}
+	w.raiseEvent(GameEvent{
+		Type:      EventTypeSurrenderEvaluation,
+		Timestamp: w.now(),
+		PlayerID:  building.PlayerID,
+		Data:      assessment,
+		Message:   message,
+	})
+
+	if assessment.Hopeless && player.IsAI {
+		w.Surrender(building.PlayerID)
+	}
+}
+
+// Surrender takes a player out of the game as defeated: its units and
+// buildings go to the neutral player or are removed, as the settings say,
+// and the match is over if only one side is left
+func (w *World) Surrender(playerID int) error {
+	w.mutex.Lock()
+	player := w.players[playerID]
+	if player == nil || !player.IsActive {
+		w.mutex.Unlock()
+		return fmt.Errorf("player %d is not in the game", playerID)
+	}
+	player.IsActive = false
+	disposal := w.settings.SurrenderDisposal
+	w.mutex.Unlock()
+
+	w.disposeOfForces(playerID, disposal)
+	w.raiseEvent(GameEvent{
+		Type:      EventTypePlayerDefeated,
+		Timestamp: w.now(),
+		PlayerID:  playerID,
+		Data:      "surrender",
+		Message:   fmt.Sprintf("Player %d surrendered", playerID),
+	})
+	w.evaluateVictory()
+	return nil
+}
+
+// disposeOfForces hands a defeated player's units and buildings to the
+// neutral player, idle, or removes them
+func (w *World) disposeOfForces(playerID int, disposal SurrenderDisposal) {
+	for _, unitID := range sortedKeys(w.ObjectManager.GetUnitsForPlayer(playerID)) {
+		if disposal == SurrenderRemoveForces {
+			w.ObjectManager.RemoveUnit(unitID)
+			continue
+		}
+		w.commandProcessor.CancelCommand(unitID)
+		w.commandProcessor.ClearCommandQueue(unitID)
+		w.ObjectManager.UnitManager.transferUnit(unitID, NeutralPlayerID)
+	}
+	for _, buildingID := range sortedKeys(w.ObjectManager.GetBuildingsForPlayer(playerID)) {
+		if disposal == SurrenderRemoveForces {
+			w.ObjectManager.RemoveBuilding(buildingID)
+			continue
+		}
+		if building := w.ObjectManager.GetBuilding(buildingID); building != nil {
+			building.mutex.Lock()
+			building.ProductionQueue = building.ProductionQueue[:0]
+			building.mutex.Unlock()
+		}
+		w.ObjectManager.transferBuilding(buildingID, NeutralPlayerID)
+	}
+}
+
+// canAfford reports whether a player has at least the given resources
+func (w *World) canAfford(playerID int, cost map[string]int) bool {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	player := w.players[playerID]
+	if player == nil {
+		return false
+	}
+	for resource, amount := range cost {
+		if player.Resources[resource] < amount {
+			return false
+		}
+	}
+	return true
+}
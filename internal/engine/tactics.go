@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Squad tactics
+const (
+	tacticsInterval    = 500 * time.Millisecond // How often AI squads in a fight are given orders
+	tacticsEngageRange = 12.0                   // Tiles from a squad's center within which enemies count as engaged
+	retreatHealthShare = 0.3                    // Health share below which a unit falls back
+	retreatDistance    = 8.0                    // Tiles a retreating unit falls back without a building to run to
+	meleeRangeLimit    = 2.0                    // Attack range in tiles up to which a unit fights in melee
+	kiteThreatDistance = 3.0                    // Tiles within which a melee enemy makes a ranged unit step back
+	kiteStep           = 2.0                    // Tiles a ranged unit steps back while kiting
+	ParamTactic        = "tactic"               // Command parameter naming the tactic that issued a move
+)
+
+// Tactics a squad unit can be ordered to carry out
+const (
+	TacticFocus   = "focus"   // Attack the squad's focus target
+	TacticRetreat = "retreat" // Fall back out of the fight
+	TacticKite    = "kite"    // Step away from melee enemies during the attack cooldown
+	TacticSpread  = "spread"  // Step away from squadmates against splash damage
+)
+
+// TacticalOrder is one order of the tactical combat controller
+type TacticalOrder struct {
+	UnitID   int
+	Tactic   string    // One of the Tactic constants
+	Target   *GameUnit // Unit to attack for TacticFocus
+	Position Vector3   // Where to move for the other tactics
+	Route    []Vector3 // Further points a retreat passes on its way, around danger
+}
+
+// PlanSquadTactics decides how a group in a fight uses its units: damaged
+// units retreat, ranged units kite melee enemies and spread out against
+// splash damage while their attack recovers, and the rest focus fire on the
+// weakest enemy in reach. A group out of combat gets no orders. With the
+// player's influence map, retreats keep out of the enemy's reach.
+func (w *World) PlanSquadTactics(group *UnitGroup, influence *InfluenceMap) []TacticalOrder {
+	squad := group.GetUnits()
+	sort.Slice(squad, func(i, j int) bool { return squad[i].ID < squad[j].ID })
+	var fighters []*GameUnit
+	var center Vector3
+	for _, unit := range squad {
+		if unit.IsAlive() && unit.GarrisonedIn == 0 {
+			fighters = append(fighters, unit)
+			center.X += unit.Position.X
+			center.Z += unit.Position.Z
+		}
+	}
+	if len(fighters) == 0 {
+		return nil
+	}
+	center.X /= float64(len(fighters))
+	center.Z /= float64(len(fighters))
+
+	tileSize := float64(w.GetTileSize())
+	enemies := w.engagedEnemies(group.PlayerID, center, tacticsEngageRange*tileSize)
+	if len(enemies) == 0 {
+		return nil
+	}
+	focus := enemies[0]
+	for _, enemy := range enemies[1:] {
+		if enemy.GetHealth() < focus.GetHealth() {
+			focus = enemy
+		}
+	}
+	splash := w.widestSplash(enemies)
+	combat := w.commandProcessor.combatSystem
+
+	var orders []TacticalOrder
+	for _, unit := range fighters {
+		// Units already carrying out a tactical move finish it first
+		if command := unit.CurrentCommand; command != nil && command.Type == CommandMove {
+			if _, tactical := command.Parameters[ParamTactic]; tactical {
+				continue
+			}
+		}
+		nearest, distance := nearestUnit(unit, enemies)
+
+		if unit.GetMaxHealth() > 0 && float64(unit.GetHealth()) < retreatHealthShare*float64(unit.GetMaxHealth()) {
+			if route, ok := w.retreatRoute(unit, nearest, influence); ok {
+				orders = append(orders, TacticalOrder{UnitID: unit.ID, Tactic: TacticRetreat, Position: route[0], Route: route[1:]})
+			}
+			continue
+		}
+
+		ranged := float64(unit.AttackRange) > meleeRangeLimit*tileSize
+		if ranged && combat.AttackPhase(unit) == AttackPhaseCooldown {
+			if float64(nearest.AttackRange) <= meleeRangeLimit*tileSize && nearest.AttackDamage > 0 && distance < kiteThreatDistance*tileSize {
+				orders = append(orders, TacticalOrder{UnitID: unit.ID, Tactic: TacticKite, Position: w.stepAway(unit.Position, nearest.Position, kiteStep*tileSize)})
+				continue
+			}
+			if mate, gap := nearestUnit(unit, fighters); mate != nil && gap < splash*tileSize {
+				orders = append(orders, TacticalOrder{UnitID: unit.ID, Tactic: TacticSpread, Position: w.stepAway(unit.Position, mate.Position, splash*tileSize-gap)})
+				continue
+			}
+		}
+
+		if unit.AttackDamage > 0 && unit.AttackTarget != focus {
+			orders = append(orders, TacticalOrder{UnitID: unit.ID, Tactic: TacticFocus, Target: focus})
+		}
+	}
+	return orders
+}
+
+// ExecuteTactics issues tactical orders; orders a unit cannot carry out are skipped
+func (w *World) ExecuteTactics(orders []TacticalOrder) {
+	for _, order := range orders {
+		if order.Tactic == TacticFocus {
+			w.commandProcessor.IssueCommand(order.UnitID, CreateAttackCommand(order.Target, false))
+			continue
+		}
+		for i, position := range append([]Vector3{order.Position}, order.Route...) {
+			command := CreateMoveCommand(position, i > 0)
+			command.Parameters = map[string]interface{}{ParamTactic: order.Tactic}
+			w.commandProcessor.IssueCommand(order.UnitID, command)
+		}
+	}
+}
+
+// engagedEnemies returns the living enemy units within reach of a
+// position, sorted by ID
+func (w *World) engagedEnemies(playerID int, center Vector3, reach float64) []*GameUnit {
+	var enemies []*GameUnit
+	for id := range w.GetAllPlayers() {
+		if id == playerID || w.AreAllied(playerID, id) {
+			continue
+		}
+		for _, unit := range w.ObjectManager.GetUnitsForPlayer(id) {
+			if unit.IsAlive() && unit.GarrisonedIn == 0 && w.CalculateDistance(center, unit.Position) <= reach {
+				enemies = append(enemies, unit)
+			}
+		}
+	}
+	sort.Slice(enemies, func(i, j int) bool { return enemies[i].ID < enemies[j].ID })
+	return enemies
+}
+
+// widestSplash returns the largest splash radius in tiles among enemy attacks
+func (w *World) widestSplash(enemies []*GameUnit) float64 {
+	widest := 0.0
+	for _, enemy := range enemies {
+		if damageType, ok := AdvancedDamageTypes[w.commandProcessor.combatSystem.getAttackType(enemy)]; ok {
+			widest = math.Max(widest, damageType.SplashRadius)
+		}
+	}
+	return widest
+}
+
+// nearestUnit returns the unit of a list closest to another, other than
+// itself, and its distance
+func nearestUnit(unit *GameUnit, units []*GameUnit) (*GameUnit, float64) {
+	var nearest *GameUnit
+	best := math.MaxFloat64
+	for _, other := range units {
+		if other == unit {
+			continue
+		}
+		dx, dz := other.Position.X-unit.Position.X, other.Position.Z-unit.Position.Z
+		if distance := math.Sqrt(dx*dx + dz*dz); distance < best {
+			nearest, best = other, distance
+		}
+	}
+	return nearest, best
+}
+
+// retreatPoint returns where a damaged unit falls back to: toward its
+// player's nearest building, or else straight away from the enemy, to the
+// safest ground there with an influence map. It is false when the unit is
+// already back at the building.
+func (w *World) retreatPoint(unit, enemy *GameUnit, influence *InfluenceMap) (Vector3, bool) {
+	tileSize := float64(w.GetTileSize())
+	var home *GameBuilding
+	best := math.MaxFloat64
+	for _, building := range w.ObjectManager.GetBuildingsForPlayer(unit.PlayerID) {
+		if distance := w.CalculateDistance(unit.Position, building.Position); building.IsAlive() && distance < best {
+			home, best = building, distance
+		}
+	}
+	if home == nil {
+		away := w.stepAway(unit.Position, enemy.Position, retreatDistance*tileSize)
+		if influence != nil {
+			away = influence.SafestPoint(away, retreatDistance)
+		}
+		return away, true
+	}
+	if best <= meleeRangeLimit*tileSize {
+		return Vector3{}, false
+	}
+	// Buildings block their tiles, so stop short of it
+	return w.stepAway(unit.Position, home.Position, -math.Min(best-meleeRangeLimit*tileSize, retreatDistance*tileSize)), true
+}
+
+// retreatRoute returns the waypoints of a damaged unit's retreat to its
+// retreat point, around danger when there is an influence map
+func (w *World) retreatRoute(unit, enemy *GameUnit, influence *InfluenceMap) ([]Vector3, bool) {
+	destination, ok := w.retreatPoint(unit, enemy, influence)
+	if !ok {
+		return nil, false
+	}
+	if influence == nil {
+		return []Vector3{destination}, true
+	}
+	return influence.SafeRoute(unit.Position, destination), true
+}
+
+// stepAway returns the point a distance from a position directly away from
+// another (toward it for a negative distance), kept on the map
+func (w *World) stepAway(from, away Vector3, distance float64) Vector3 {
+	dx, dz := from.X-away.X, from.Z-away.Z
+	length := math.Sqrt(dx*dx + dz*dz)
+	if length == 0 {
+		dx, dz, length = 1, 0, 1
+	}
+	tileSize := float64(w.GetTileSize())
+	clamp := func(value float64, tiles int) float64 {
+		return math.Max(tileSize/2, math.Min(value, float64(tiles)*tileSize-tileSize/2))
+	}
+	return Vector3{
+		X: clamp(from.X+dx/length*distance, w.Width),
+		Y: from.Y,
+		Z: clamp(from.Z+dz/length*distance, w.Height),
+	}
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestSquadTactics tests that a squad in a fight focuses the weakest enemy,
+// pulls back damaged units, kites melee enemies with ranged units and
+// spreads out against splash damage
+func TestSquadTactics(t *testing.T) {
+	world, err := NewHeadlessWorld(48, 48)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+
+	unit := func(playerID int, attackType string, x, z float64, attackRange float32) *GameUnit {
+		def := data.NewSimpleUnit(attackType, 100, 0, "leather", nil)
+		def.Unit.Skills = []data.Skill{{Type: data.SkillType{Value: "attack"}, AttackType: &data.SkillAttackType{Value: attackType}}}
+		created, _ := world.ObjectManager.CreateUnit(playerID, attackType, Vector3{X: x, Z: z}, def)
+		created.AttackDamage, created.AttackRange, created.AttackSpeed = 10, attackRange, 1
+		return created
+	}
+	swordsman := unit(1, "sword", 20.5, 20.5, 1.5)
+	wounded := unit(1, "sword", 21.5, 20.5, 1.5)
+	archer := unit(1, "arrow", 19.5, 22.5, 6)
+	wounded.Health = 20
+	archer.LastAttack = clock.Now() // Recovering from a shot
+
+	group, err := world.groupMgr.CreateGroup(1, []*GameUnit{swordsman, wounded, archer}, FormationLine)
+	if err != nil {
+		t.Fatalf("Failed to create group: %v", err)
+	}
+	if orders := world.PlanSquadTactics(group, nil); len(orders) != 0 {
+		t.Fatalf("Expected no orders out of combat, got %+v", orders)
+	}
+
+	brute := unit(2, "sword", 19.5, 24.0, 1.5)
+	weakling := unit(2, "sword", 26.5, 20.5, 1.5)
+	weakling.Health = 40
+
+	tactics := map[int]TacticalOrder{}
+	for _, order := range world.PlanSquadTactics(group, nil) {
+		tactics[order.UnitID] = order
+	}
+	if order := tactics[swordsman.ID]; order.Tactic != TacticFocus || order.Target != weakling {
+		t.Errorf("Expected the swordsman to focus the weakest enemy, got %+v", order)
+	}
+	if order := tactics[wounded.ID]; order.Tactic != TacticRetreat || order.Position.Z >= wounded.Position.Z && order.Position.X >= wounded.Position.X {
+		t.Errorf("Expected the wounded unit to fall back, got %+v", order)
+	}
+	if order := tactics[archer.ID]; order.Tactic != TacticKite || order.Position.Z >= archer.Position.Z {
+		t.Errorf("Expected the archer to step back from the brute, got %+v", order)
+	}
+
+	// Tactical moves run to completion before the unit gets new orders
+	world.ExecuteTactics(world.PlanSquadTactics(group, nil))
+	if archer.CurrentCommand == nil || archer.CurrentCommand.Parameters[ParamTactic] != TacticKite {
+		t.Fatalf("Expected the archer to be kiting, got %+v", archer.CurrentCommand)
+	}
+	for _, order := range world.PlanSquadTactics(group, nil) {
+		if order.UnitID == archer.ID || order.UnitID == swordsman.ID {
+			t.Errorf("Expected no new order while one is carried out, got %+v", order)
+		}
+	}
+
+	// Against splash damage, ranged units bunched up step apart
+	brute.Health = 0
+	archer.CurrentCommand = nil
+	unit(2, "catapult", 30.5, 22.5, 10)
+	second := unit(1, "arrow", 20.5, 22.5, 6)
+	world.groupMgr.AddUnitsToGroup(group.ID, []*GameUnit{second})
+	tactics = map[int]TacticalOrder{}
+	for _, order := range world.PlanSquadTactics(group, nil) {
+		tactics[order.UnitID] = order
+	}
+	if order := tactics[archer.ID]; order.Tactic != TacticSpread || order.Position.X >= archer.Position.X {
+		t.Errorf("Expected the recovering archer to spread away from its neighbour, got %+v", order)
+	}
+	if order := tactics[second.ID]; order.Tactic != TacticFocus || order.Target != weakling {
+		t.Errorf("Expected the ready archer to focus, got %+v", order)
+	}
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Team options let allied players (same non-zero GameSettings.Teams entry)
+// play closer together: TeamVision shares line of sight from the start,
+// TeamSharedControl lets human allies order each other's units and can be
+// toggled during the match, and TeamResourcePool gives each team a single
+// stockpile every member gathers into and spends from.
+
+// validateTeamOptions checks team options are only used with teams
+func validateTeamOptions(settings GameSettings) error {
+	if !settings.TeamVision && !settings.TeamSharedControl && !settings.TeamResourcePool {
+		return nil
+	}
+	for _, team := range settings.Teams {
+		if team != 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("team options need players assigned to teams")
+}
+
+// SetTeamSharedControl turns shared control of allied units on or off
+func (w *World) SetTeamSharedControl(enabled bool) {
+	w.shared.mutex.Lock()
+	defer w.shared.mutex.Unlock()
+	w.shared.teamControl = enabled
+}
+
+// TeamSharedControl reports whether human allies may order each other's units
+func (w *World) TeamSharedControl() bool {
+	w.shared.mutex.Lock()
+	defer w.shared.mutex.Unlock()
+	return w.shared.teamControl
+}
+
+// commandsAlly reports whether shared team control lets a player order an
+// ally's objects. AI allies keep sole command of their own units.
+func (w *World) commandsAlly(playerID, ownerID int) bool {
+	if !w.TeamSharedControl() || !w.AreAllied(playerID, ownerID) {
+		return false
+	}
+	owner := w.GetPlayer(ownerID)
+	return owner != nil && !owner.IsAI
+}
+
+// visionSources returns the players whose units and buildings a player sees
+// with: itself, plus its allies when the team shares vision
+func (w *World) visionSources(playerID int) []int {
+	if !w.settings.TeamVision {
+		return []int{playerID}
+	}
+	return append([]int{playerID}, w.GetAllies(playerID)...)
+}
+
+// linkTeamResources points every member of a team at one resource map when
+// the team pools resources. With merge the members' stockpiles are summed
+// into it (match start); without, the lowest member's is kept, as restored
+// saves hold the pool once per member.
+func (w *World) linkTeamResources(merge bool) {
+	if !w.settings.TeamResourcePool {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	teams := make(map[int][]*Player)
+	for _, player := range w.players {
+		if player.Team != 0 {
+			teams[player.Team] = append(teams[player.Team], player)
+		}
+	}
+	for _, members := range teams {
+		sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+		pool := copyIntMap(members[0].Resources)
+		if merge {
+			for _, member := range members[1:] {
+				for resource, amount := range member.Resources {
+					pool[resource] += amount
+				}
+			}
+		}
+		for _, member := range members {
+			member.Resources = pool
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+)
+
+// TestTeamOptions tests shared vision, control and resources between allies
+func TestTeamOptions(t *testing.T) {
+	world, err := NewHeadlessWorld(64, 64)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.GetPlayer(1).Team = 1
+	world.GetPlayer(2).Team = 1
+	unitDef := &data.UnitDefinition{Name: "scout"}
+	unitDef.Unit.Parameters.MaxHP.Value = 100
+	unitDef.Unit.Parameters.Sight.Value = 5
+	unit, err := world.ObjectManager.CreateUnit(2, "scout", Vector3{X: 40, Z: 40}, unitDef)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	near := Vector3{X: 41, Z: 41}
+
+	// Without team options allies see and command only their own
+	if world.CanSee(1, near) {
+		t.Error("Expected player 1 not to see through its ally's unit")
+	}
+	if err := world.IssuePlayerCommand(1, unit.ID, CreateMoveCommand(near, false)); err == nil {
+		t.Error("Expected an ally's unit to refuse orders")
+	}
+
+	world.settings.TeamVision = true
+	if !world.CanSee(1, near) {
+		t.Error("Expected shared vision to show the ally's surroundings")
+	}
+
+	world.SetTeamSharedControl(true)
+	if !world.CanCommand(1, 2) {
+		t.Fatal("Expected shared control to allow ordering the ally's units")
+	}
+	var attributed []CommandAttribution
+	world.AddEventListener(func(event GameEvent) {
+		if event.Type == EventTypeCommandIssued {
+			attributed = append(attributed, event.Data.(CommandAttribution))
+		}
+	})
+	if err := world.IssuePlayerCommand(1, unit.ID, CreateMoveCommand(near, false)); err != nil {
+		t.Fatalf("Ally command failed: %v", err)
+	}
+	if len(attributed) != 1 || attributed[0].IssuerID != 1 || attributed[0].OwnerID != 2 {
+		t.Errorf("Expected the order to be attributed to player 1, got %+v", attributed)
+	}
+	world.GetPlayer(2).IsAI = true
+	if world.CanCommand(1, 2) {
+		t.Error("Expected an AI ally to keep command of its units")
+	}
+	world.GetPlayer(2).IsAI = false
+	world.SetTeamSharedControl(false)
+	if world.CanCommand(1, 2) {
+		t.Error("Expected toggling shared control off to take effect")
+	}
+
+	// Pooled teams spend from one stockpile
+	world.settings.TeamResourcePool = true
+	world.linkTeamResources(true)
+	if gold := world.GetResourceStatus(2).Resources["gold"]; gold != 2000 {
+		t.Fatalf("Expected the pool to hold both players' gold, got %d", gold)
+	}
+	if err := world.DeductResources(1, map[string]int{"gold": 1500}, "test"); err != nil {
+		t.Fatalf("Deduct from pool failed: %v", err)
+	}
+	if gold := world.GetResourceStatus(2).Resources["gold"]; gold != 500 {
+		t.Errorf("Expected the ally to see the pooled gold spent, got %d", gold)
+	}
+
+	// Restoring a save keeps the pool shared without doubling it
+	if err := world.RestoreSaveGame(world.CaptureSaveGame()); err != nil {
+		t.Fatalf("RestoreSaveGame failed: %v", err)
+	}
+	world.AddResources(2, map[string]int{"gold": 100}, "test")
+	if gold := world.GetResourceStatus(1).Resources["gold"]; gold != 600 {
+		t.Errorf("Expected the restored pool to stay shared, got %d", gold)
+	}
+
+	settings := GameSettings{
+		TechTreePath:   "techs",
+		PlayerFactions: map[int]string{1: "tech"},
+		AIFactions:     map[int]string{2: "magic"},
+		TeamVision:     true,
+	}
+	if err := validateGameSettings(settings); err == nil {
+		t.Error("Expected team options without teams to be rejected")
+	}
+	settings.Teams = map[int]int{1: 1, 2: 1}
+	if err := validateGameSettings(settings); err != nil {
+		t.Errorf("Expected team options with teams to be valid, got %v", err)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+
+	"teraglest/internal/logging"
 )
 
 // Tileset represents a complete terrain tileset with all visual and gameplay data
@@ -24,6 +27,8 @@ type SurfaceType struct {
 	Index         int               `json:"index"`
 	Textures      []SurfaceTexture  `json:"textures"`  // Multiple textures with probabilities
 	TotalProbability float32        `json:"total_probability"` // Sum of all texture probabilities
+	Hazard        *SurfaceHazard    `json:"hazard,omitempty"` // Damage to units standing on it (nil = safe)
+	Detail        *SurfaceDetail    `json:"detail,omitempty"` // Grass and detail meshes growing on it (nil = bare)
 }
 
 // SurfaceTexture represents a single texture variation for a surface
@@ -51,9 +56,11 @@ type AmbientSounds struct {
 
 // AudioConfig represents audio file configuration
 type AudioConfig struct {
-	Enabled bool   `json:"enabled"`
-	Path    string `json:"path"`
-	Volume  float32 `json:"volume"` // 0.0 to 1.0
+	Enabled    bool     `json:"enabled"`
+	Path       string   `json:"path"`
+	Volume     float32  `json:"volume"`      // 0.0 to 1.0
+	PlayAlways bool     `json:"play_always"` // Loop plays day and night
+	Sounds     []string `json:"sounds"`      // Alternatives picked at random, e.g. animal calls
 }
 
 // TerrainParameters contains visual and environmental parameters
@@ -123,6 +130,28 @@ type SurfacesXML struct {
 // SurfaceXML represents a single surface definition
 type SurfaceXML struct {
 	Textures []TextureXML `xml:"texture"`
+	Hazard   *HazardXML   `xml:"hazard"`
+	Detail   *DetailXML   `xml:"detail"`
+}
+
+// HazardXML marks a surface as harmful, e.g.
+// <hazard name="lava" damage="8" interval="1" effect="burn" path-cost="10"/>
+type HazardXML struct {
+	Name     string `xml:"name,attr"`
+	Damage   string `xml:"damage,attr"`
+	Interval string `xml:"interval,attr"` // Seconds between ticks
+	Effect   string `xml:"effect,attr"`
+	PathCost string `xml:"path-cost,attr"`
+}
+
+// DetailXML is the grass and detail meshes growing on a surface, e.g.
+// <detail density="12" height="0.4" color="0.3 0.5 0.2" model-chance="0.05"><model path="models/flower.g3d"/></detail>
+type DetailXML struct {
+	Density     string     `xml:"density,attr"`
+	Height      string     `xml:"height,attr"`
+	Color       string     `xml:"color,attr"` // Red, green and blue, 0-1
+	ModelChance string     `xml:"model-chance,attr"`
+	Models      []ModelXML `xml:"model"`
 }
 
 // TextureXML represents a texture variation
@@ -159,9 +188,16 @@ type AmbientSoundsXML struct {
 
 // SoundXML represents sound configuration
 type SoundXML struct {
-	Enabled string `xml:"enabled,attr"`
-	Path    string `xml:"path,attr"`
-	Volume  string `xml:"volume,attr"`
+	Enabled    string         `xml:"enabled,attr"`
+	Path       string         `xml:"path,attr"`
+	Volume     string         `xml:"volume,attr"`
+	PlayAlways string         `xml:"play-always,attr"`
+	Sounds     []SoundFileXML `xml:"sound"`
+}
+
+// SoundFileXML is one of several alternative sound files
+type SoundFileXML struct {
+	Path string `xml:"path,attr"`
 }
 
 // ParametersXML contains environmental parameters
@@ -290,6 +326,21 @@ func (tl *TilesetLoader) convertXMLToTileset(name string, xmlData TilesetXML) (*
 		}
 
 		surface.TotalProbability = totalProb
+
+		if surfaceXML.Hazard != nil {
+			hazard, err := tl.convertHazard(*surfaceXML.Hazard)
+			if err != nil {
+				return nil, fmt.Errorf("invalid hazard on surface %d: %w", i+1, err)
+			}
+			surface.Hazard = hazard
+		}
+		if surfaceXML.Detail != nil {
+			detail, err := tl.convertDetail(*surfaceXML.Detail)
+			if err != nil {
+				return nil, fmt.Errorf("invalid detail on surface %d: %w", i+1, err)
+			}
+			surface.Detail = detail
+		}
 		tileset.Surfaces[i] = surface
 	}
 
@@ -331,6 +382,77 @@ func (tl *TilesetLoader) convertXMLToTileset(name string, xmlData TilesetXML) (*
 	return tileset, nil
 }
 
+// convertHazard converts a surface hazard XML to internal format
+func (tl *TilesetLoader) convertHazard(xmlHazard HazardXML) (*SurfaceHazard, error) {
+	damage := 0
+	if xmlHazard.Damage != "" {
+		value, err := strconv.Atoi(xmlHazard.Damage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid damage: %s", xmlHazard.Damage)
+		}
+		damage = value
+	}
+	interval, err := tl.parseFloat32(xmlHazard.Interval, float32(defaultHazardInterval.Seconds()))
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid interval: %s", xmlHazard.Interval)
+	}
+	pathCost, err := tl.parseFloat32(xmlHazard.PathCost, defaultHazardPathCost)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path cost: %s", xmlHazard.PathCost)
+	}
+	if damage <= 0 && xmlHazard.Effect == "" {
+		return nil, fmt.Errorf("hazard has neither damage nor an effect")
+	}
+
+	return &SurfaceHazard{
+		Name:     xmlHazard.Name,
+		Damage:   damage,
+		Interval: time.Duration(float64(interval) * float64(time.Second)),
+		Effect:   xmlHazard.Effect,
+		PathCost: pathCost,
+	}, nil
+}
+
+// convertDetail converts a surface detail XML to internal format; unset
+// attributes take the values of default grass
+func (tl *TilesetLoader) convertDetail(xmlDetail DetailXML) (*SurfaceDetail, error) {
+	defaults := DefaultSurfaceDetails[SurfaceGrass]
+	density, err := tl.parseFloat32(xmlDetail.Density, defaults.Density)
+	if err != nil || density < 0 {
+		return nil, fmt.Errorf("invalid density: %s", xmlDetail.Density)
+	}
+	height, err := tl.parseFloat32(xmlDetail.Height, defaults.Height)
+	if err != nil || height <= 0 {
+		return nil, fmt.Errorf("invalid height: %s", xmlDetail.Height)
+	}
+	modelChance, err := tl.parseFloat32(xmlDetail.ModelChance, 0)
+	if err != nil || modelChance < 0 || modelChance > 1 {
+		return nil, fmt.Errorf("invalid model chance: %s", xmlDetail.ModelChance)
+	}
+
+	color := defaults.Color
+	if xmlDetail.Color != "" {
+		components := strings.Fields(xmlDetail.Color)
+		if len(components) != 3 {
+			return nil, fmt.Errorf("invalid color: %s", xmlDetail.Color)
+		}
+		for i, component := range components {
+			if color[i], err = tl.parseFloat32(component, 0); err != nil {
+				return nil, fmt.Errorf("invalid color: %s", xmlDetail.Color)
+			}
+		}
+	}
+
+	detail := &SurfaceDetail{Density: density, Height: height, Color: color, ModelChance: modelChance}
+	for _, model := range xmlDetail.Models {
+		detail.Models = append(detail.Models, model.Path)
+	}
+	if len(detail.Models) == 0 {
+		detail.ModelChance = 0
+	}
+	return detail, nil
+}
+
 // convertAmbientSounds converts ambient sounds XML to internal format
 func (tl *TilesetLoader) convertAmbientSounds(xmlSounds AmbientSoundsXML) (*AmbientSounds, error) {
 	sounds := &AmbientSounds{}
@@ -370,10 +492,24 @@ func (tl *TilesetLoader) convertAudioConfig(xmlAudio SoundXML) (AudioConfig, err
 		return AudioConfig{}, fmt.Errorf("invalid volume value: %s", xmlAudio.Volume)
 	}
 
+	playAlways, err := tl.parseBool(xmlAudio.PlayAlways, false)
+	if err != nil {
+		return AudioConfig{}, fmt.Errorf("invalid play-always value: %s", xmlAudio.PlayAlways)
+	}
+
+	sounds := make([]string, 0, len(xmlAudio.Sounds))
+	for _, sound := range xmlAudio.Sounds {
+		if sound.Path != "" {
+			sounds = append(sounds, sound.Path)
+		}
+	}
+
 	return AudioConfig{
-		Enabled: enabled,
-		Path:    xmlAudio.Path,
-		Volume:  volume,
+		Enabled:    enabled,
+		Path:       xmlAudio.Path,
+		Volume:     volume,
+		PlayAlways: playAlways,
+		Sounds:     sounds,
 	}, nil
 }
 
@@ -515,34 +651,32 @@ func (st *SurfaceType) GetRandomTexture(randomValue float32) string {
 	return st.Textures[0].Path
 }
 
-// PrintSummary prints a summary of the tileset information
+// PrintSummary logs a summary of the tileset information at debug level
 func (t *Tileset) PrintSummary() {
-	fmt.Printf("Tileset: %s\n", t.Name)
-	fmt.Printf("  Base Path: %s\n", t.BasePath)
-	fmt.Printf("  Surfaces: %d types\n", len(t.Surfaces))
-	fmt.Printf("  Objects: %d types\n", len(t.Objects))
+	logging.Debugf(logging.CategoryData, "Tileset: %s", t.Name)
+	logging.Debugf(logging.CategoryData, "  Base Path: %s", t.BasePath)
+	logging.Debugf(logging.CategoryData, "  Surfaces: %d types", len(t.Surfaces))
+	logging.Debugf(logging.CategoryData, "  Objects: %d types", len(t.Objects))
 
-	fmt.Printf("  Water: %t", t.Parameters.Water.Effects)
 	if t.Parameters.Water.Effects {
-		fmt.Printf(" (%d frames)\n", t.Parameters.Water.FrameCount)
+		logging.Debugf(logging.CategoryData, "  Water: true (%d frames)", t.Parameters.Water.FrameCount)
 	} else {
-		fmt.Printf("\n")
+		logging.Debugf(logging.CategoryData, "  Water: false")
 	}
 
-	fmt.Printf("  Fog: %t", t.Parameters.Fog.Enabled)
 	if t.Parameters.Fog.Enabled {
-		fmt.Printf(" (density: %.3f)\n", t.Parameters.Fog.Density)
+		logging.Debugf(logging.CategoryData, "  Fog: true (density: %.3f)", t.Parameters.Fog.Density)
 	} else {
-		fmt.Printf("\n")
+		logging.Debugf(logging.CategoryData, "  Fog: false")
 	}
 
-	fmt.Printf("  Weather: Sun %.1f%%, Rain %.1f%%, Snow %.1f%%\n",
+	logging.Debugf(logging.CategoryData, "  Weather: Sun %.1f%%, Rain %.1f%%, Snow %.1f%%",
 		t.Parameters.Weather.SunProbability*100,
 		t.Parameters.Weather.RainProbability*100,
 		t.Parameters.Weather.SnowProbability*100)
 
 	if t.AmbientSounds != nil {
-		fmt.Printf("  Audio: Day=%t, Night=%t, Rain=%t, Snow=%t\n",
+		logging.Debugf(logging.CategoryData, "  Audio: Day=%t, Night=%t, Rain=%t, Snow=%t",
 			t.AmbientSounds.DaySound.Enabled,
 			t.AmbientSounds.NightSound.Enabled,
 			t.AmbientSounds.RainSound.Enabled,
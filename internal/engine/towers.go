@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"math"
+	"time"
+)
+
+// Defensive buildings
+const (
+	towerGarrisonFireBonus = 0.5 // Extra fire rate for each garrisoned combat unit (0.5 = +50%)
+	towerMaxFireMultiplier = 3.0 // Cap on the garrison fire rate multiplier
+	towerThreatDPS         = 60  // Damage per second the AI rates as the highest threat
+	towerReachMargin       = 5   // Tiles beyond its range where the AI still counts a tower as a threat to its base
+)
+
+// IsDefensive reports whether a building can attack
+func (b *GameBuilding) IsDefensive() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.AttackDamage > 0
+}
+
+// TowerFireRate returns how many shots per second a defensive building fires;
+// each garrisoned combat unit shoots from it too, raising the rate
+func (w *World) TowerFireRate(building *GameBuilding) float64 {
+	building.mutex.RLock()
+	defer building.mutex.RUnlock()
+
+	if building.AttackDamage <= 0 {
+		return 0
+	}
+	multiplier := 1.0
+	for _, unitID := range building.GarrisonedUnits {
+		if unit := w.ObjectManager.GetUnit(unitID); unit != nil && unit.IsAlive() && !IsWorkerType(unit.UnitType) {
+			multiplier += towerGarrisonFireBonus
+		}
+	}
+	return float64(building.AttackSpeed) * math.Min(multiplier, towerMaxFireMultiplier)
+}
+
+// updateDefensiveBuildings lets every built defensive building fire at the
+// enemies in its range
+func (cp *CommandProcessor) updateDefensiveBuildings(players map[int]*Player) {
+	now := cp.world.now()
+	for _, player := range players {
+		for _, building := range cp.world.ObjectManager.GetBuildingsForPlayer(player.ID) {
+			if building.IsAlive() && building.IsDefensive() {
+				cp.updateDefensiveBuilding(building, now)
+			}
+		}
+	}
+}
+
+// updateDefensiveBuilding fires one shot when the building's cooldown has passed
+// and an enemy is in range
+func (cp *CommandProcessor) updateDefensiveBuilding(building *GameBuilding, now time.Time) {
+	rate := cp.world.TowerFireRate(building)
+
+	building.mutex.RLock()
+	ready := building.IsBuilt && rate > 0 && now.Sub(building.LastAttackTime) >= time.Duration(float64(time.Second)/rate)
+	building.mutex.RUnlock()
+	if !ready {
+		return
+	}
+
+	target := cp.towerTarget(building)
+	building.mutex.Lock()
+	if target == nil {
+		building.AttackTargetID = 0
+		building.mutex.Unlock()
+		return
+	}
+	building.AttackTargetID = target.ID
+	building.LastAttackTime = now
+	position, damage, attackType := building.Position, building.AttackDamage, building.AttackType
+	building.mutex.Unlock()
+
+	cp.visualSystem.CreateRangedAttackEffect(position, target.Position, attackType, "arrow")
+	if cp.combatSystem.missesUphill(position, target.Position, attackType) {
+		return
+	}
+
+	// Same damage model as unit attacks: tech tree and high-ground
+	// multipliers, then armor
+	multiplier := cp.combatSystem.elevationMultiplier(position, target.Position)
+	if cp.world.techTree != nil {
+		multiplier *= cp.world.techTree.GetDamageMultiplier(attackType, cp.combatSystem.getArmorType(target))
+	}
+	dealt := int(math.Round(math.Max(float64(damage)*multiplier-float64(target.Armor), 1)))
+	cp.combatSystem.ApplyDamage(target, dealt)
+}
+
+// towerTarget keeps firing at the current target while it stays in range,
+// otherwise it picks the closest enemy unit in range; nil when there is none
+func (cp *CommandProcessor) towerTarget(building *GameBuilding) *GameUnit {
+	building.mutex.RLock()
+	playerID, position, reach, current := building.PlayerID, building.Position, float64(building.AttackRange), building.AttackTargetID
+	building.mutex.RUnlock()
+
+	inRange := func(unit *GameUnit) bool {
+		return unit != nil && unit.IsAlive() && unit.GarrisonedIn == 0 &&
+			unit.PlayerID != playerID && !cp.world.AreAllied(playerID, unit.PlayerID) &&
+			cp.world.CalculateDistance(position, unit.Position) <= reach
+	}
+
+	if current != 0 {
+		if unit := cp.world.ObjectManager.GetUnit(current); inRange(unit) {
+			return unit
+		}
+	}
+
+	var closest *GameUnit
+	closestDistance := math.MaxFloat64
+	for _, player := range cp.world.GetAllPlayers() {
+		if player.ID == playerID || cp.world.AreAllied(playerID, player.ID) {
+			continue
+		}
+		for _, unit := range cp.world.ObjectManager.GetUnitsForPlayer(player.ID) {
+			if !inRange(unit) {
+				continue
+			}
+			if distance := cp.world.CalculateDistance(position, unit.Position); distance < closestDistance ||
+				(distance == closestDistance && unit.ID < closest.ID) {
+				closest, closestDistance = unit, distance
+			}
+		}
+	}
+	return closest
+}
+
+// towerThreat rates how dangerous a defensive building is from 0.0 to 1.0 by
+// the damage it deals per second, garrison included
+func (w *World) towerThreat(building *GameBuilding) float64 {
+	building.mutex.RLock()
+	damage := float64(building.AttackDamage)
+	building.mutex.RUnlock()
+	return math.Min(damage*w.TowerFireRate(building)/towerThreatDPS, 1.0)
+}
+
+// towerReaches reports whether a defensive building can fire on any of the
+// given buildings, with a margin for the units working around them
+func (w *World) towerReaches(tower *GameBuilding, buildings map[int]*GameBuilding) bool {
+	tower.mutex.RLock()
+	position, reach := tower.Position, float64(tower.AttackRange)+towerReachMargin
+	tower.mutex.RUnlock()
+
+	for _, building := range buildings {
+		if w.CalculateDistance(position, building.Position) <= reach {
+			return true
+		}
+	}
+	return false
+}
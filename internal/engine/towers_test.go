@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// newTestTowerDef returns a tower that shoots once a second for 10 damage over 6 tiles
+func newTestTowerDef() *data.UnitDefinition {
+	def := data.NewSimpleUnit("tower", 800, 0, "stone", nil)
+	def.Unit.Parameters.Garrison = &data.UnitGarrison{Capacity: 4}
+	def.Unit.Skills = []data.Skill{{
+		Type:           data.SkillType{Value: "attack"},
+		Name:           data.SkillName{Value: "attack_skill"},
+		Speed:          data.SkillSpeed{Value: 100},
+		AttackStrength: &data.SkillAttackStrength{Value: 10},
+		AttackRange:    &data.SkillAttackRange{Value: 6},
+		AttackType:     &data.SkillAttackType{Value: "piercing"},
+	}}
+	return def
+}
+
+// TestDefensiveTower tests that a tower fires at the closest enemy in range
+// and fires faster with soldiers garrisoned
+func TestDefensiveTower(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+
+	tower, err := world.ObjectManager.CreateBuilding(1, "tower", Vector3{X: 10, Z: 10}, newTestTowerDef())
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	if !tower.IsDefensive() || tower.AttackRange != 6 || tower.AttackSpeed != 1 {
+		t.Fatalf("Expected attack stats from the skill, got damage %d range %.0f speed %.1f", tower.AttackDamage, tower.AttackRange, tower.AttackSpeed)
+	}
+	tower.IsBuilt = true
+
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	near, _ := world.ObjectManager.CreateUnit(2, "soldier", Vector3{X: 13, Z: 10}, soldier)
+	farther, _ := world.ObjectManager.CreateUnit(2, "soldier", Vector3{X: 10, Z: 15}, soldier)
+	outside, _ := world.ObjectManager.CreateUnit(2, "soldier", Vector3{X: 20, Z: 20}, soldier)
+	friendly, _ := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 11, Z: 10}, soldier)
+
+	processor := world.commandProcessor
+	step := func(seconds int) {
+		for i := 0; i < seconds*10; i++ {
+			clock.Advance(100 * time.Millisecond)
+			processor.updateDefensiveBuildings(world.GetAllPlayers())
+		}
+	}
+
+	step(3)
+	if near.Health != 70 || farther.Health != 100 {
+		t.Errorf("Expected 3 shots at the closest enemy, health %d and %d", near.Health, farther.Health)
+	}
+	if outside.Health != 100 || friendly.Health != 100 {
+		t.Error("Expected units out of range and friendly units to be left alone")
+	}
+
+	// Two garrisoned soldiers double the fire rate
+	for i := 0; i < 2; i++ {
+		guard, _ := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 10, Z: 11}, soldier)
+		if err := world.GetProductionSystem().GarrisonUnit(tower.ID, guard.ID); err != nil {
+			t.Fatalf("Failed to garrison: %v", err)
+		}
+	}
+	if rate := world.TowerFireRate(tower); rate != 2 {
+		t.Fatalf("Expected 2 shots per second with 2 soldiers inside, got %.1f", rate)
+	}
+	step(3)
+	if near.Health != 10 {
+		t.Errorf("Expected 6 more shots at the same target, health %d", near.Health)
+	}
+
+	// Once it dies the tower switches to the next enemy in range
+	step(2)
+	if near.IsAlive() || farther.Health >= 100 {
+		t.Errorf("Expected the tower to kill its target and move on, health %d and %d", near.Health, farther.Health)
+	}
+}
+
+// TestAITowerThreat tests that the AI rates enemy towers by their firepower
+// and whether they reach its base
+func TestAITowerThreat(t *testing.T) {
+	world, err := NewHeadlessWorld(64, 64)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	base := data.NewSimpleUnit("castle", 2000, 0, "stone", nil)
+	if _, err := world.ObjectManager.CreateBuilding(2, "castle", Vector3{X: 10, Z: 10}, base); err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	if _, err := world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 50, Z: 50}, base); err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	distant, _ := world.ObjectManager.CreateBuilding(1, "tower", Vector3{X: 45, Z: 50}, newTestTowerDef())
+
+	// A scout has seen the enemy base
+	scout, _ := world.ObjectManager.CreateUnit(2, "scout", Vector3{X: 47, Z: 50}, data.NewSimpleUnit("scout", 50, 0, "leather", nil))
+	ai := NewStrategicAI(2, world, BalancedPersonality, DifficultyNormal)
+	ai.GetIntel().Update()
+	scout.Health = 0
+	if threat := ai.assessEnemyTowerThreat(); threat != 0 {
+		t.Errorf("Expected no tower threat from a tower far from the base, got %.2f", threat)
+	}
+
+	close, _ := world.ObjectManager.CreateBuilding(1, "tower", Vector3{X: 15, Z: 10}, newTestTowerDef())
+	ai.GetIntel().Update()
+	if threat := ai.assessEnemyTowerThreat(); threat <= 0 {
+		t.Errorf("Expected a tower next to the base to be a threat, got %.2f", threat)
+	}
+
+	military := NewMilitaryManager(2, world, ai)
+	military.identifyMilitaryTargets()
+	if len(military.militaryTargets) != 3 {
+		t.Fatalf("Expected the 3 enemy buildings as targets, got %d", len(military.militaryTargets))
+	}
+	first := military.militaryTargets[0]
+	if first.Type != "enemy_tower" || first.Location != close.Position {
+		t.Errorf("Expected the tower next to the base to come first, got %+v", first)
+	}
+	for _, target := range military.militaryTargets {
+		if target.Location == distant.Position && target.ThreatLevel >= first.ThreatLevel {
+			t.Errorf("Expected the distant tower to rate lower than the close one, got %.2f", target.ThreatLevel)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"sort"
+	"time"
+)
+
+// TransactionQuery selects resource transactions from the StatsRecorder's
+// log. Zero fields match every transaction.
+type TransactionQuery struct {
+	PlayerID        int           // Player involved, 0 for every player
+	Resource        string        // Resource type, e.g. "gold"
+	Source          string        // Transaction source, e.g. "resource_dropoff"
+	TransactionType string        // "addition" or "deduction"
+	From            time.Duration // Earliest game time included
+	To              time.Duration // Game time the range ends before, 0 for no end
+}
+
+// matches reports whether a transaction is selected by the query
+func (q TransactionQuery) matches(transaction ResourceEvent) bool {
+	return (q.PlayerID == 0 || transaction.PlayerID == q.PlayerID) &&
+		(q.Resource == "" || transaction.ResourceType == q.Resource) &&
+		(q.Source == "" || transaction.Source == q.Source) &&
+		(q.TransactionType == "" || transaction.TransactionType == q.TransactionType) &&
+		transaction.GameTime >= q.From &&
+		(q.To == 0 || transaction.GameTime < q.To)
+}
+
+// TransactionBucket totals the transactions of one span of game time
+type TransactionBucket struct {
+	Start  time.Duration  // Game time the bucket starts at
+	Gained map[string]int // Resource type -> amount added
+	Spent  map[string]int // Resource type -> amount deducted
+}
+
+// Transactions returns the logged transactions matching a query, oldest first
+func (sr *StatsRecorder) Transactions(query TransactionQuery) []ResourceEvent {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+
+	var transactions []ResourceEvent
+	for _, transaction := range sr.transactions {
+		if query.matches(transaction) {
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions
+}
+
+// TransactionSources totals the matching transactions per source:
+// source -> resource type -> amount
+func (sr *StatsRecorder) TransactionSources(query TransactionQuery) map[string]map[string]int {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+
+	sources := make(map[string]map[string]int)
+	for _, transaction := range sr.transactions {
+		if !query.matches(transaction) {
+			continue
+		}
+		if sources[transaction.Source] == nil {
+			sources[transaction.Source] = make(map[string]int)
+		}
+		sources[transaction.Source][transaction.ResourceType] += transaction.Amount
+	}
+	return sources
+}
+
+// TransactionBuckets totals the matching transactions in consecutive spans of
+// game time of the given width, from the query's From up to its To (or the
+// last matching transaction). Spans without transactions are included.
+func (sr *StatsRecorder) TransactionBuckets(query TransactionQuery, width time.Duration) []TransactionBucket {
+	if width <= 0 {
+		return nil
+	}
+	transactions := sr.Transactions(query)
+	sort.SliceStable(transactions, func(i, j int) bool { return transactions[i].GameTime < transactions[j].GameTime })
+
+	end := query.To
+	if end == 0 {
+		if len(transactions) == 0 {
+			return nil
+		}
+		end = transactions[len(transactions)-1].GameTime + 1
+	}
+
+	var buckets []TransactionBucket
+	for start := query.From; start < end; start += width {
+		buckets = append(buckets, TransactionBucket{Start: start, Gained: make(map[string]int), Spent: make(map[string]int)})
+	}
+	for _, transaction := range transactions {
+		bucket := &buckets[int((transaction.GameTime-query.From)/width)]
+		switch transaction.TransactionType {
+		case "addition":
+			bucket.Gained[transaction.ResourceType] += transaction.Amount
+		case "deduction":
+			bucket.Spent[transaction.ResourceType] += transaction.Amount
+		}
+	}
+	return buckets
+}
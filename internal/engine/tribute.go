@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tribute is the data of an EventTypeTribute event
+type Tribute struct {
+	FromPlayerID int            // Player who sent the resources
+	ToPlayerID   int            // Ally who received them
+	Resources    map[string]int // Amount sent per resource
+}
+
+// String describes the tribute, e.g. "100 gold, 50 wood"
+func (t Tribute) String() string {
+	names := make([]string, 0, len(t.Resources))
+	for resource := range t.Resources {
+		names = append(names, resource)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, resource := range names {
+		parts = append(parts, fmt.Sprintf("%d %s", t.Resources[resource], resource))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// AreAllied reports whether two different players are on the same team
+func (w *World) AreAllied(playerA, playerB int) bool {
+	if playerA == playerB {
+		return false
+	}
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	a, b := w.players[playerA], w.players[playerB]
+	return a != nil && b != nil && a.Team != 0 && a.Team == b.Team
+}
+
+// GetAllies returns the IDs of a player's active allies, sorted
+func (w *World) GetAllies(playerID int) []int {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	player := w.players[playerID]
+	if player == nil || player.Team == 0 {
+		return nil
+	}
+	var allies []int
+	for id, other := range w.players {
+		if id != playerID && other.IsActive && other.Team == player.Team {
+			allies = append(allies, id)
+		}
+	}
+	sort.Ints(allies)
+	return allies
+}
+
+// SendTribute transfers resources from a player to an active ally and raises
+// a tribute event
+func (w *World) SendTribute(fromPlayerID, toPlayerID int, resources map[string]int) error {
+	if len(resources) == 0 {
+		return fmt.Errorf("tribute has no resources")
+	}
+	for resource, amount := range resources {
+		if amount <= 0 {
+			return fmt.Errorf("tribute of %s must be positive, got %d", resource, amount)
+		}
+	}
+	if !w.AreAllied(fromPlayerID, toPlayerID) {
+		return fmt.Errorf("player %d is not allied with player %d", fromPlayerID, toPlayerID)
+	}
+	if recipient := w.GetPlayer(toPlayerID); !recipient.IsActive {
+		return fmt.Errorf("player %d is no longer in the game", toPlayerID)
+	}
+
+	if err := w.DeductResources(fromPlayerID, resources, fmt.Sprintf("tribute to player %d", toPlayerID)); err != nil {
+		return fmt.Errorf("failed to pay tribute: %w", err)
+	}
+	if err := w.AddResources(toPlayerID, resources, fmt.Sprintf("tribute from player %d", fromPlayerID)); err != nil {
+		return fmt.Errorf("failed to deliver tribute: %w", err)
+	}
+
+	tribute := Tribute{FromPlayerID: fromPlayerID, ToPlayerID: toPlayerID, Resources: copyIntMap(resources)}
+	w.raiseEvent(GameEvent{
+		Type:      EventTypeTribute,
+		Timestamp: w.now(),
+		PlayerID:  toPlayerID,
+		Data:      tribute,
+		Message:   fmt.Sprintf("Player %d sent %s to player %d", fromPlayerID, tribute, toPlayerID),
+	})
+	return nil
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"testing"
+)
+
+// newAlliedWorld creates a headless world where players 1 and 2 are allies
+func newAlliedWorld(t *testing.T) *World {
+	t.Helper()
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.GetPlayer(1).Team = 1
+	world.GetPlayer(2).Team = 1
+	return world
+}
+
+// TestSendTribute tests sending resources to an ally and the tribute event
+func TestSendTribute(t *testing.T) {
+	world, err := NewHeadlessWorld(16, 16)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	if err := world.SendTribute(1, 2, map[string]int{"gold": 100}); err == nil {
+		t.Fatal("Expected tribute to a player without a team to fail")
+	}
+
+	world = newAlliedWorld(t)
+	var events []GameEvent
+	world.SetEventSink(func(event GameEvent) {
+		// Resource transactions raise their own events
+		if event.Type == EventTypeTribute {
+			events = append(events, event)
+		}
+	})
+
+	if !world.AreAllied(1, 2) || world.AreAllied(1, 1) {
+		t.Fatal("Expected players 1 and 2 to be allied, and no player to be its own ally")
+	}
+	if allies := world.GetAllies(1); len(allies) != 1 || allies[0] != 2 {
+		t.Errorf("Expected player 2 as the only ally, got %v", allies)
+	}
+
+	if err := world.SendTribute(1, 2, map[string]int{"gold": 100, "wood": 50}); err != nil {
+		t.Fatalf("Failed to send tribute: %v", err)
+	}
+	from, to := world.GetResourceStatus(1).Resources, world.GetResourceStatus(2).Resources
+	if from["gold"] != 900 || from["wood"] != 950 || to["gold"] != 1100 || to["wood"] != 1050 {
+		t.Errorf("Unexpected resources after tribute: sender %v, recipient %v", from, to)
+	}
+
+	if len(events) != 1 || events[0].Type != EventTypeTribute {
+		t.Fatalf("Expected one tribute event, got %v", events)
+	}
+	tribute, ok := events[0].Data.(Tribute)
+	if !ok || tribute.FromPlayerID != 1 || tribute.ToPlayerID != 2 || tribute.String() != "100 gold, 50 wood" {
+		t.Errorf("Unexpected tribute event data: %+v", events[0].Data)
+	}
+
+	for name, resources := range map[string]map[string]int{
+		"empty":        {},
+		"negative":     {"gold": -10},
+		"unaffordable": {"gold": 5000},
+	} {
+		if err := world.SendTribute(1, 2, resources); err == nil {
+			t.Errorf("%s: expected tribute to fail", name)
+		}
+	}
+	if len(events) != 1 {
+		t.Errorf("Expected failed tributes to raise no events, got %d events", len(events))
+	}
+}
+
+// TestAITributeToAllyInNeed tests that the AI sends a surplus to an ally running out
+func TestAITributeToAllyInNeed(t *testing.T) {
+	world := newAlliedWorld(t)
+	world.GetPlayer(1).Resources["wood"] = 50
+
+	manager := NewEconomicManager(2, world, nil)
+	manager.considerTribute()
+
+	if wood := world.GetResourceStatus(1).Resources["wood"]; wood <= 50 {
+		t.Fatalf("Expected the AI to send wood to its ally, ally has %d", wood)
+	}
+	sent := world.GetResourceStatus(1).Resources["wood"] - 50
+	if world.GetResourceStatus(2).Resources["wood"] != 1000-sent {
+		t.Errorf("Expected the AI to pay the %d wood it sent", sent)
+	}
+
+	// The AI waits before sending again
+	manager.considerTribute()
+	if world.GetResourceStatus(1).Resources["wood"] != 50+sent {
+		t.Error("Expected no second tribute within the tribute interval")
+	}
+
+	// A warlike AI keeps its resources
+	aggressive := NewStrategicAI(2, world, AIPersonality{AggressionLevel: 1}, DifficultyNormal)
+	world.GetPlayer(1).Resources["stone"] = 0
+	NewEconomicManager(2, world, aggressive).considerTribute()
+	if world.GetResourceStatus(1).Resources["stone"] != 0 {
+		t.Error("Expected an aggressive AI not to send tribute")
+	}
+}
@@ -50,7 +50,8 @@ type GameUnit struct {
 	// State management
 	Position     Vector3             `json:"position"`      // World coordinates (continuous)
 	GridPos      GridPosition        `json:"grid_pos"`      // Grid coordinates + sub-tile offset
-	Rotation     float32             `json:"rotation"`
+	Rotation     float32             `json:"rotation"`   // Heading, turned toward where the unit moves and strikes (see facing.go)
+	TurnRate     float32             `json:"turn_rate"`  // Radians a second the unit turns at (0 = FacingSettings.TurnRate)
 	Health       int                 `json:"health"`
 	MaxHealth    int                 `json:"max_health"`
 	Armor        int                 `json:"armor"`
@@ -78,6 +79,11 @@ type GameUnit struct {
 	AttackSpeed  float32             `json:"attack_speed"`
 	LastAttack   time.Time           `json:"last_attack"`
 	AttackTarget *GameUnit           `json:"attack_target"`
+	AttackWindUp time.Duration       `json:"attack_wind_up"` // Swing time before the hit, from the skill's attack-start-time
+	WindUpStart  time.Time           `json:"wind_up_start"`  // When the current swing started (zero = not swinging)
+
+	// Animation
+	Animation    AnimationState      `json:"animation"` // Clip played and how far, advanced by the simulation
 
 	// Resource gathering
 	CarriedResources map[string]int   `json:"carried_resources"`
@@ -88,6 +94,9 @@ type GameUnit struct {
 	BuildTarget     *GameBuilding     `json:"build_target"`
 	BuildProgress   float32           `json:"build_progress"`
 
+	// Garrison
+	GarrisonedIn    int               `json:"garrisoned_in"` // Building ID sheltering this unit (0 = not garrisoned)
+
 	// Unit definition data
 	UnitDef      *data.UnitDefinition `json:"-"`
 
@@ -188,6 +197,14 @@ func (u *GameUnit) IsAlive() bool {
 	return u.Health > 0 && u.State != UnitStateDead
 }
 
+// IsGarrisoned reports whether the unit is sheltering inside a building,
+// where it can neither be ordered nor attacked
+func (u *GameUnit) IsGarrisoned() bool {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	return u.GarrisonedIn != 0
+}
+
 func (u *GameUnit) GetType() string {
 	u.mutex.RLock()
 	defer u.mutex.RUnlock()
@@ -265,6 +282,11 @@ func (u *GameUnit) updateMovement(deltaTime time.Duration) {
 }
 
 func (u *GameUnit) updateCombat(deltaTime time.Duration) {
+	// Attack orders are resolved by the CommandProcessor, wind-up and cooldown included
+	if u.CurrentCommand != nil && u.CurrentCommand.Type == CommandAttack {
+		return
+	}
+
 	if u.AttackTarget == nil || !u.AttackTarget.IsAlive() {
 		u.State = UnitStateIdle
 		u.AttackTarget = nil
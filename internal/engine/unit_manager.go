@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"teraglest/internal/data"
+	"teraglest/internal/logging"
 )
 
 // UnitManager handles unit creation, tracking, and spatial queries
@@ -43,38 +44,35 @@ func (um *UnitManager) CreateUnit(playerID int, unitType string, position Vector
 	unitID := um.nextID
 	um.nextID++
 
-	// DEBUG: Add logging to see where panic occurs
-	fmt.Printf("DEBUG: Creating unit, accessing unitDef.Name: %s\n", unitDef.Name)
-	fmt.Printf("DEBUG: Calling WorldToGrid with position (%.1f,%.1f,%.1f) and tileSize %.1f\n",
+	logging.Debugf(logging.CategoryEngine, "Creating unit, accessing unitDef.Name: %s", unitDef.Name)
+	logging.Debugf(logging.CategoryEngine, "Calling WorldToGrid with position (%.1f,%.1f,%.1f) and tileSize %.1f",
 		position.X, position.Y, position.Z, um.world.tileSize)
 
 	gridPos := WorldToGrid(position, um.world.tileSize)
-	fmt.Printf("DEBUG: WorldToGrid succeeded, result: (%d,%d)\n", gridPos.Grid.X, gridPos.Grid.Y)
+	logging.Debugf(logging.CategoryEngine, "WorldToGrid succeeded, result: (%d,%d)", gridPos.Grid.X, gridPos.Grid.Y)
 
-	// DEBUG: Access fields individually to isolate the panic
-	fmt.Printf("DEBUG: About to access unitDef.Name\n")
+	logging.Debugf(logging.CategoryEngine, "About to access unitDef.Name")
 	unitName := unitDef.Name
-	fmt.Printf("DEBUG: unitName = %s\n", unitName)
+	logging.Debugf(logging.CategoryEngine, "unitName = %s", unitName)
 
-	fmt.Printf("DEBUG: About to access unitDef.Unit.Parameters.MaxHP.Value\n")
+	logging.Debugf(logging.CategoryEngine, "About to access unitDef.Unit.Parameters.MaxHP.Value")
 	maxHP := unitDef.Unit.Parameters.MaxHP.Value
-	fmt.Printf("DEBUG: maxHP = %d\n", maxHP)
+	logging.Debugf(logging.CategoryEngine, "maxHP = %d", maxHP)
 
-	fmt.Printf("DEBUG: About to access unitDef.Unit.Parameters.Armor.Value\n")
+	logging.Debugf(logging.CategoryEngine, "About to access unitDef.Unit.Parameters.Armor.Value")
 	armor := unitDef.Unit.Parameters.Armor.Value
-	fmt.Printf("DEBUG: armor = %d\n", armor)
+	logging.Debugf(logging.CategoryEngine, "armor = %d", armor)
 
-	fmt.Printf("DEBUG: About to create GameUnit struct\n")
+	logging.Debugf(logging.CategoryEngine, "About to create GameUnit struct")
 
-	// DEBUG: Create fields step by step to isolate the issue
-	fmt.Printf("DEBUG: Creating CommandQueue slice\n")
+	logging.Debugf(logging.CategoryEngine, "Creating CommandQueue slice")
 	commandQueue := make([]UnitCommand, 0)
-	fmt.Printf("DEBUG: Creating CarriedResources map\n")
+	logging.Debugf(logging.CategoryEngine, "Creating CarriedResources map")
 	carriedRes := make(map[string]int)
-	fmt.Printf("DEBUG: Creating GatherRate map\n")
+	logging.Debugf(logging.CategoryEngine, "Creating GatherRate map")
 	gatherRate := map[string]float32{"wood": 10.0, "stone": 8.0, "gold": 12.0}
 
-	fmt.Printf("DEBUG: About to allocate GameUnit struct\n")
+	logging.Debugf(logging.CategoryEngine, "About to allocate GameUnit struct")
 	unit := &GameUnit{
 		ID:           unitID,
 		PlayerID:     playerID,
@@ -88,47 +86,67 @@ func (um *UnitManager) CreateUnit(playerID int, unitType string, position Vector
 		Energy:       100,
 		MaxEnergy:    100,
 		State:        UnitStateIdle,
-		CreationTime: time.Now(),
-		LastUpdate:   time.Now(),
+		CreationTime: um.world.now(),
+		LastUpdate:   um.world.now(),
 		CommandQueue: commandQueue,
 		Speed:        2.0,
 		CarriedResources: carriedRes,
 		GatherRate:   gatherRate,
 		UnitDef:      unitDef,
 	}
-	fmt.Printf("DEBUG: GameUnit struct created successfully\n")
+	logging.Debugf(logging.CategoryEngine, "GameUnit struct created successfully")
 
 	// Set combat stats based on unit definition
-	fmt.Printf("DEBUG: About to access unitDef.Unit.Parameters.ResourceRequirements\n")
+	logging.Debugf(logging.CategoryEngine, "About to access unitDef.Unit.Parameters.ResourceRequirements")
 	if len(unitDef.Unit.Parameters.ResourceRequirements) > 0 {
 		// Infer combat stats from cost and armor
 		unit.AttackDamage = 10 + unit.Armor/2 // Simple damage calculation
 		unit.AttackRange = 1.0 + float32(unit.Armor)/10.0 // Range based on armor
 		unit.AttackSpeed = 1.0 // Attacks per second
 	}
-	fmt.Printf("DEBUG: Combat stats processing complete\n")
+
+	// The attack skill's timings drive the attack cycle
+	if skill := unitDef.AttackSkill(); skill != nil && skill.AttacksPerSecond() > 0 {
+		unit.AttackSpeed = float32(skill.AttacksPerSecond())
+		unit.AttackWindUp = attackWindUp(skill)
+	}
+	logging.Debugf(logging.CategoryEngine, "Combat stats processing complete")
 
 	// Store unit
-	fmt.Printf("DEBUG: About to store unit in um.units map\n")
+	logging.Debugf(logging.CategoryEngine, "About to store unit in um.units map")
 	um.units[unitID] = unit
-	fmt.Printf("DEBUG: Unit stored in um.units\n")
+	logging.Debugf(logging.CategoryEngine, "Unit stored in um.units")
 
 	// Index by player
-	fmt.Printf("DEBUG: About to index by player\n")
+	logging.Debugf(logging.CategoryEngine, "About to index by player")
 	if um.unitsByPlayer[playerID] == nil {
 		um.unitsByPlayer[playerID] = make(map[int]*GameUnit)
 	}
 	um.unitsByPlayer[playerID][unitID] = unit
-	fmt.Printf("DEBUG: Unit indexed by player\n")
+	logging.Debugf(logging.CategoryEngine, "Unit indexed by player")
 
-	// Mark grid position as occupied
-	fmt.Printf("DEBUG: About to call SetOccupied\n")
-	um.world.SetOccupied(unit.GridPos.Grid, true)
-	fmt.Printf("DEBUG: SetOccupied completed\n")
+	// Mark the tiles the unit covers as occupied
+	logging.Debugf(logging.CategoryEngine, "About to occupy footprint")
+	um.world.occupyFootprint(unit, unit.GridPos.Grid)
+	logging.Debugf(logging.CategoryEngine, "Footprint occupied")
 
 	return unit, nil
 }
 
+// NextID returns the ID the next created unit will get
+func (um *UnitManager) NextID() int {
+	um.mutex.RLock()
+	defer um.mutex.RUnlock()
+	return um.nextID
+}
+
+// setNextID sets the ID the next created unit will get (used when restoring saves)
+func (um *UnitManager) setNextID(id int) {
+	um.mutex.Lock()
+	defer um.mutex.Unlock()
+	um.nextID = id
+}
+
 // GetUnit returns a unit by ID (thread-safe)
 func (um *UnitManager) GetUnit(unitID int) *GameUnit {
 	um.mutex.RLock()
@@ -161,8 +179,8 @@ func (um *UnitManager) RemoveUnit(unitID int) error {
 		return fmt.Errorf("unit with ID %d not found", unitID)
 	}
 
-	// Free grid position
-	um.world.SetOccupied(unit.GridPos.Grid, false)
+	// Free the tiles the unit covered
+	um.releaseFootprint(unit, unit.GridPos.Grid)
 
 	// Remove from global index
 	delete(um.units, unitID)
@@ -179,14 +197,40 @@ func (um *UnitManager) RemoveUnit(unitID int) error {
 	return nil
 }
 
-// GetUnitsAtPosition returns all units at a specific grid position
+// transferUnit hands a unit to another player (thread-safe)
+func (um *UnitManager) transferUnit(unitID, playerID int) error {
+	um.mutex.Lock()
+	defer um.mutex.Unlock()
+
+	unit, exists := um.units[unitID]
+	if !exists {
+		return fmt.Errorf("unit with ID %d not found", unitID)
+	}
+	if playerUnits, exists := um.unitsByPlayer[unit.PlayerID]; exists {
+		delete(playerUnits, unitID)
+		if len(playerUnits) == 0 {
+			delete(um.unitsByPlayer, unit.PlayerID)
+		}
+	}
+	if um.unitsByPlayer[playerID] == nil {
+		um.unitsByPlayer[playerID] = make(map[int]*GameUnit)
+	}
+	um.unitsByPlayer[playerID][unitID] = unit
+
+	unit.mutex.Lock()
+	unit.PlayerID = playerID
+	unit.mutex.Unlock()
+	return nil
+}
+
+// GetUnitsAtPosition returns all units whose bodies cover a grid position
 func (um *UnitManager) GetUnitsAtPosition(gridPos Vector2i) []*GameUnit {
 	um.mutex.RLock()
 	defer um.mutex.RUnlock()
 
 	var unitsAtPosition []*GameUnit
 	for _, unit := range um.units {
-		if unit.GridPos.Grid.X == gridPos.X && unit.GridPos.Grid.Y == gridPos.Y {
+		if unit.coversTile(gridPos) {
 			unitsAtPosition = append(unitsAtPosition, unit)
 		}
 	}
@@ -288,15 +332,10 @@ func (um *UnitManager) Update(deltaTime time.Duration) {
 	}
 }
 
-// updateUnitGridPosition updates occupancy grid when a unit moves
+// updateUnitGridPosition updates occupancy grid when a unit moves, freeing
+// the tiles it left that no other unit covers
 func (um *UnitManager) updateUnitGridPosition(unit *GameUnit, oldPos, newPos Vector2i) {
-	// Free old position if no other units are there
-	if len(um.GetUnitsAtPosition(oldPos)) == 0 {
-		um.world.SetOccupied(oldPos, false)
-	}
-
-	// Occupy new position
-	um.world.SetOccupied(newPos, true)
+	um.world.moveFootprint(unit, oldPos, newPos)
 }
 
 // GetStats returns statistics about the units
@@ -0,0 +1,123 @@
+package engine
+
+// Unit sizes
+const (
+	unitSelectionMargin = 0.5 // Tiles beyond a unit's body a click still selects it
+	formationGap        = 1.0 // Tiles kept clear between the bodies of units in formation
+)
+
+// Footprint returns the width in tiles of the square a unit covers, from the
+// size in its XML definition; units without one cover a single tile
+func (u *GameUnit) Footprint() int {
+	if u.UnitDef == nil || u.UnitDef.Unit.Parameters.Size.Value < 1 {
+		return 1
+	}
+	return u.UnitDef.Unit.Parameters.Size.Value
+}
+
+// CollisionRadius returns the radius in tiles of a unit's body
+func (u *GameUnit) CollisionRadius() float64 {
+	return float64(u.Footprint()) / 2
+}
+
+// SelectionRadius returns the radius in tiles around a unit's position that
+// a click selects it within
+func (u *GameUnit) SelectionRadius() float64 {
+	return u.CollisionRadius() + unitSelectionMargin
+}
+
+// footprintBounds returns the first and last tiles of the square of a size
+// around a tile; even sizes reach further right and down
+func footprintBounds(tile Vector2i, size int) (Vector2i, Vector2i) {
+	if size < 1 {
+		size = 1
+	}
+	low := Vector2i{X: tile.X - (size-1)/2, Y: tile.Y - (size-1)/2}
+	return low, Vector2i{X: low.X + size - 1, Y: low.Y + size - 1}
+}
+
+// footprintTiles returns the tiles of the square of a size around a tile
+func footprintTiles(tile Vector2i, size int) []Vector2i {
+	low, high := footprintBounds(tile, size)
+	tiles := make([]Vector2i, 0, (high.X-low.X+1)*(high.Y-low.Y+1))
+	for y := low.Y; y <= high.Y; y++ {
+		for x := low.X; x <= high.X; x++ {
+			tiles = append(tiles, Vector2i{X: x, Y: y})
+		}
+	}
+	return tiles
+}
+
+// inFootprint reports whether a tile lies in the square of a size around
+// another
+func inFootprint(tile, center Vector2i, size int) bool {
+	low, high := footprintBounds(center, size)
+	return tile.X >= low.X && tile.X <= high.X && tile.Y >= low.Y && tile.Y <= high.Y
+}
+
+// coversTile reports whether a unit's body covers a tile where it stands
+func (u *GameUnit) coversTile(tile Vector2i) bool {
+	return inFootprint(tile, u.GridPos.Grid, u.Footprint())
+}
+
+// occupyFootprint marks the tiles a unit covers standing on a tile as
+// occupied
+func (w *World) occupyFootprint(unit *GameUnit, tile Vector2i) {
+	for _, covered := range footprintTiles(tile, unit.Footprint()) {
+		w.SetOccupied(covered, true)
+	}
+}
+
+// releaseFootprint frees the tiles a unit covered standing on a tile, but
+// for those other units still cover
+func (w *World) releaseFootprint(unit *GameUnit, tile Vector2i) {
+	um := w.ObjectManager.UnitManager
+	um.mutex.RLock()
+	defer um.mutex.RUnlock()
+	um.releaseFootprint(unit, tile)
+}
+
+// moveFootprint moves the tiles a unit occupies from where it stood to
+// where it stands
+func (w *World) moveFootprint(unit *GameUnit, from, to Vector2i) {
+	w.releaseFootprint(unit, from)
+	w.occupyFootprint(unit, to)
+}
+
+// releaseFootprint frees the tiles a unit covered standing on a tile, but
+// for those other units still cover (mutex must be held)
+func (um *UnitManager) releaseFootprint(unit *GameUnit, tile Vector2i) {
+	for _, covered := range footprintTiles(tile, unit.Footprint()) {
+		if !um.coveredByOther(unit, covered) {
+			um.world.SetOccupied(covered, false)
+		}
+	}
+}
+
+// coveredByOther reports whether a unit other than the given one covers a
+// tile (mutex must be held)
+func (um *UnitManager) coveredByOther(unit *GameUnit, tile Vector2i) bool {
+	for _, other := range um.units {
+		if other.ID != unit.ID && other.coversTile(tile) {
+			return true
+		}
+	}
+	return false
+}
+
+// footprintClear reports whether a unit may step onto a tile: each tile its
+// body would newly cover must be passable to its player and hold no other
+// unit
+func (cp *CommandProcessor) footprintClear(unit *GameUnit, tile GridPosition) bool {
+	size := unit.Footprint()
+	current := unit.GetGridPosition().Grid
+	for _, covered := range footprintTiles(tile.Grid, size) {
+		if inFootprint(covered, current, size) {
+			continue // Already under the unit
+		}
+		if !cp.world.CanPass(covered, unit.PlayerID) || cp.isOccupiedByOther(unit, GridPosition{Grid: covered}) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// sizedUnit returns a unit definition of a size in tiles
+func sizedUnit(name string, size int) *data.UnitDefinition {
+	def := data.NewSimpleUnit(name, 100, 0, "leather", nil)
+	def.Unit.Parameters.Size.Value = size
+	return def
+}
+
+// TestUnitSize tests that a unit's collision radius and footprint follow the
+// size in its definition, and that its whole footprint is occupied, kept
+// clear on its paths and spaced in formation
+func TestUnitSize(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	soldier, _ := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: 2.5, Z: 2.5}, sizedUnit("soldier", 1))
+	ram, err := world.ObjectManager.CreateUnit(1, "ram", Vector3{X: 10.5, Z: 10.5}, sizedUnit("ram", 3))
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	unsized, _ := world.ObjectManager.CreateUnit(1, "scout", Vector3{X: 20.5, Z: 2.5}, sizedUnit("scout", 0))
+
+	if soldier.Footprint() != 1 || soldier.SelectionRadius() != 1 || unsized.Footprint() != 1 {
+		t.Errorf("Expected single tile units selected within 1 tile, got %d and %.1f", soldier.Footprint(), soldier.SelectionRadius())
+	}
+	if ram.Footprint() != 3 || ram.CollisionRadius() != 1.5 || ram.SelectionRadius() != 2 {
+		t.Errorf("Expected the ram 3 tiles wide, got footprint %d, radius %.1f", ram.Footprint(), ram.CollisionRadius())
+	}
+
+	// The ram's whole body is occupied, and freed as it moves on
+	for _, tile := range footprintTiles(Vector2i{X: 10, Y: 10}, 3) {
+		if world.IsPositionWalkable(tile) || len(world.ObjectManager.UnitManager.GetUnitsAtPosition(tile)) != 1 {
+			t.Fatalf("Expected the ram to occupy %v", tile)
+		}
+	}
+	ram.UpdatePositions(Vector3{X: 11.5, Z: 10.5}, world.tileSize)
+	world.ObjectManager.UnitManager.updateUnitGridPosition(ram, Vector2i{X: 10, Y: 10}, Vector2i{X: 11, Y: 10})
+	if !world.IsPositionWalkable(Vector2i{X: 9, Y: 10}) || world.IsPositionWalkable(Vector2i{X: 12, Y: 11}) {
+		t.Error("Expected the ram's footprint to move with it")
+	}
+
+	// Paths leave room for the ram's body: it cannot squeeze through a gap
+	// a soldier fits through
+	for y := 0; y < 32; y++ {
+		if y != 20 {
+			world.SetWalkable(Vector2i{X: 16, Y: y}, false)
+		}
+	}
+	target := Vector3{X: 24.5, Z: 20.5}
+	if path, err := world.pathfindingMgr.RequestPath(soldier, target); err != nil || !path.Success || path.Partial {
+		t.Fatalf("Expected the soldier through the gap, got %+v", path)
+	}
+	path, err := world.pathfindingMgr.RequestPath(ram, target)
+	if err != nil || path.Success && !path.Partial {
+		t.Fatalf("Expected the ram kept out of the gap, got %+v", path)
+	}
+	for y := 19; y <= 21; y++ {
+		world.SetWalkable(Vector2i{X: 16, Y: y}, true)
+	}
+	path, err = world.pathfindingMgr.RequestPath(ram, target)
+	if err != nil || !path.Success || path.Partial {
+		t.Fatalf("Expected the ram through a gap as wide as itself, got %+v", path)
+	}
+	for _, step := range path.GridPath {
+		if step.Grid.X == 16 && step.Grid.Y != 20 {
+			t.Errorf("Expected the ram through the middle of the gap, got %v", step.Grid)
+		}
+	}
+
+	// A soldier cannot walk into the ram
+	world.SetWalkable(Vector2i{X: 16, Y: 0}, true)
+	soldier.UpdatePositions(Vector3{X: 13.5, Z: 10.5}, world.tileSize)
+	world.ObjectManager.UnitManager.updateUnitGridPosition(soldier, Vector2i{X: 2, Y: 2}, Vector2i{X: 13, Y: 10})
+	into := Vector3{X: 12.5, Z: 10.5}
+	soldier.CurrentCommand = &UnitCommand{Type: CommandMove, Target: &into, Path: []Vector3{into}}
+	world.commandProcessor.processMoveCommand(soldier, soldier.CurrentCommand, time.Second)
+	if soldier.GetGridPosition().Grid != (Vector2i{X: 13, Y: 10}) {
+		t.Errorf("Expected the soldier stopped at the ram's side, got %v", soldier.GetGridPosition().Grid)
+	}
+
+	// Formations keep the ram's body clear of its neighbors
+	group := NewUnitGroup(1, 1, []*GameUnit{soldier, ram}, FormationLine)
+	if spacing := group.unitSpacing(group.getSortedUnits()); spacing != 4 {
+		t.Errorf("Expected units 4 tiles apart beside the ram, got %.1f", spacing)
+	}
+	if spacing := group.unitSpacing([]*GameUnit{soldier}); spacing != group.Parameters.UnitSpacing {
+		t.Errorf("Expected the configured spacing for soldiers, got %.1f", spacing)
+	}
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"time"
+)
+
+// Unit upkeep mode
+const (
+	DefaultUpkeepFreeUnits = 20            // Army size kept for free when GameSettings leaves it at 0
+	unitUpkeepSource       = "unit_upkeep" // Transaction source of upkeep payments
+)
+
+// DefaultUpkeepCost is what each unit above the free army size costs per
+// minute when GameSettings does not set UpkeepCost
+var DefaultUpkeepCost = map[string]int{"gold": 2}
+
+// upkeepTracker accrues army upkeep between payments, which are made in whole units
+type upkeepTracker struct {
+	owed map[int]map[string]float64 // Player ID -> resource -> upkeep not paid yet
+}
+
+// upkeepSettings returns the free army size and per-unit cost of upkeep mode,
+// and whether it is enabled
+func (w *World) upkeepSettings() (int, map[string]int, bool) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	free, cost := w.settings.UpkeepFreeUnits, w.settings.UpkeepCost
+	if free <= 0 {
+		free = DefaultUpkeepFreeUnits
+	}
+	if cost == nil {
+		cost = DefaultUpkeepCost
+	}
+	return free, cost, w.settings.UnitUpkeep
+}
+
+// ArmySize returns how many living combat units (anything that is not a
+// worker) a player has, which is what upkeep is charged on
+func (w *World) ArmySize(playerID int) int {
+	size := 0
+	for _, unit := range w.ObjectManager.GetUnitsForPlayer(playerID) {
+		if unit.IsAlive() && !IsWorkerType(unit.UnitType) {
+			size++
+		}
+	}
+	return size
+}
+
+// ArmyUpkeep returns what a player's army costs per minute in upkeep mode;
+// it is nil when the mode is off or the army is within the free size
+func (w *World) ArmyUpkeep(playerID int) map[string]int {
+	free, cost, enabled := w.upkeepSettings()
+	if !enabled {
+		return nil
+	}
+	charged := upkeepUnits(w.ArmySize(playerID), free)
+	if charged == 0 {
+		return nil
+	}
+	upkeep := make(map[string]int, len(cost))
+	for resource, amount := range cost {
+		upkeep[resource] = amount * charged
+	}
+	return upkeep
+}
+
+// upkeepUnits returns how many units of an army pay upkeep: each unit above
+// the free size pays once, and each above twice the free size pays again for
+// its overstretched supply lines
+func upkeepUnits(armySize, free int) int {
+	charged := 0
+	if armySize > free {
+		charged += armySize - free
+	}
+	if armySize > 2*free {
+		charged += armySize - 2*free
+	}
+	return charged
+}
+
+// updateUnitUpkeep charges every active player the upkeep of its army for
+// deltaTime. Whatever a player cannot afford is waived, so upkeep drains the
+// stockpile to zero but never runs up a debt.
+func (w *World) updateUnitUpkeep(deltaTime time.Duration) {
+	if _, _, enabled := w.upkeepSettings(); !enabled {
+		return
+	}
+
+	for _, player := range w.GetAllPlayers() {
+		if !player.IsActive {
+			continue
+		}
+		upkeep := w.ArmyUpkeep(player.ID)
+		if upkeep == nil {
+			continue
+		}
+
+		w.mutex.Lock()
+		if w.upkeep.owed == nil {
+			w.upkeep.owed = make(map[int]map[string]float64)
+		}
+		owed := w.upkeep.owed[player.ID]
+		if owed == nil {
+			owed = make(map[string]float64)
+			w.upkeep.owed[player.ID] = owed
+		}
+		due := make(map[string]int)
+		for resource, perMinute := range upkeep {
+			owed[resource] += float64(perMinute) * deltaTime.Minutes()
+			amount := int(owed[resource])
+			if amount == 0 {
+				continue
+			}
+			owed[resource] -= float64(amount)
+			if available := w.players[player.ID].Resources[resource]; amount > available {
+				amount = available
+			}
+			if amount > 0 {
+				due[resource] = amount
+			}
+		}
+		w.mutex.Unlock()
+
+		if len(due) > 0 {
+			w.DeductResources(player.ID, due, unitUpkeepSource)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// TestUnitUpkeep tests that armies above the free size drain resources every
+// minute in upkeep mode, and that the AI weighs recruitment accordingly
+func TestUnitUpkeep(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.settings.UpkeepFreeUnits = 2
+	world.settings.UpkeepCost = map[string]int{"gold": 6}
+
+	soldier := data.NewSimpleUnit("soldier", 100, 0, "leather", nil)
+	worker := data.NewSimpleUnit("worker", 50, 0, "leather", nil)
+	for i := 0; i < 5; i++ {
+		if _, err := world.ObjectManager.CreateUnit(1, "soldier", Vector3{X: float64(i + 2), Z: 4}, soldier); err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+	}
+	if _, err := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 2, Z: 8}, worker); err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+
+	// Upkeep mode is off by default
+	world.updateUnitUpkeep(time.Minute)
+	if gold := world.GetPlayer(1).Resources["gold"]; gold != 1000 {
+		t.Fatalf("Expected no upkeep outside upkeep mode, gold is %d", gold)
+	}
+
+	// 5 soldiers with 2 free: 3 pay once and the one above 4 pays again
+	world.settings.UnitUpkeep = true
+	if size := world.ArmySize(1); size != 5 {
+		t.Errorf("Expected workers not to count towards the army, size %d", size)
+	}
+	if upkeep := world.ArmyUpkeep(1); upkeep["gold"] != 24 {
+		t.Fatalf("Expected 24 gold per minute, got %v", upkeep)
+	}
+	if upkeep := world.ArmyUpkeep(2); upkeep != nil {
+		t.Errorf("Expected no upkeep for an empty army, got %v", upkeep)
+	}
+
+	for i := 0; i < 30; i++ {
+		world.updateUnitUpkeep(time.Second)
+	}
+	if gold := world.GetPlayer(1).Resources["gold"]; gold != 988 {
+		t.Errorf("Expected half a minute to cost 12 gold, gold is %d", gold)
+	}
+
+	// Upkeep drains the stockpile to zero but does not run up a debt
+	world.GetPlayer(1).Resources["gold"] = 5
+	world.updateUnitUpkeep(time.Minute)
+	if gold := world.GetPlayer(1).Resources["gold"]; gold != 0 {
+		t.Errorf("Expected upkeep to take the remaining gold, gold is %d", gold)
+	}
+	world.GetPlayer(1).Resources["gold"] = 100
+	world.updateUnitUpkeep(time.Second)
+	if gold := world.GetPlayer(1).Resources["gold"]; gold < 99 {
+		t.Errorf("Expected the unpaid upkeep to be waived, gold is %d", gold)
+	}
+
+	// The AI recruits less eagerly once new units pay upkeep
+	strategicAI := &StrategicAI{}
+	military := NewMilitaryManager(1, world, strategicAI)
+	if weight := military.upkeepWeight(); weight >= 0.5 {
+		t.Errorf("Expected a low recruitment weight above twice the free size, got %.2f", weight)
+	}
+	military = NewMilitaryManager(2, world, strategicAI)
+	if weight := military.upkeepWeight(); weight != 1.0 {
+		t.Errorf("Expected full recruitment weight for an empty army, got %.2f", weight)
+	}
+}
@@ -0,0 +1,204 @@
+package engine
+
+import (
+	"fmt"
+)
+
+// MaxWallSegments caps how many segments a single wall placement creates
+const MaxWallSegments = 24
+
+// wallCell is a grid tile blocked by a wall segment or a gate
+type wallCell struct {
+	BuildingID int
+	PlayerID   int
+	Gate       bool // Gates let their owner and its allies through
+}
+
+// IsWallType reports whether a building type is a wall segment
+func IsWallType(buildingType string) bool {
+	switch buildingType {
+	case "wall", "stone_wall", "palisade":
+		return true
+	}
+	return false
+}
+
+// IsGateType reports whether a building type is a gate
+func IsGateType(buildingType string) bool {
+	switch buildingType {
+	case "gate", "stone_gate":
+		return true
+	}
+	return false
+}
+
+// WallLine returns the tiles of a straight wall from start to end, one
+// segment per tile, at most MaxWallSegments long
+func WallLine(start, end Vector2i) []Vector2i {
+	dx, dy := absInt(end.X-start.X), -absInt(end.Y-start.Y)
+	stepX, stepY := 1, 1
+	if start.X > end.X {
+		stepX = -1
+	}
+	if start.Y > end.Y {
+		stepY = -1
+	}
+
+	// Bresenham's line, which keeps diagonal walls free of gaps units could squeeze through
+	var cells []Vector2i
+	x, y, err := start.X, start.Y, dx+dy
+	for len(cells) < MaxWallSegments {
+		cells = append(cells, Vector2i{X: x, Y: y})
+		if x == end.X && y == end.Y {
+			break
+		}
+		if e2 := 2 * err; e2 >= dy {
+			err += dy
+			x += stepX
+		} else {
+			err += dx
+			y += stepY
+		}
+	}
+	return cells
+}
+
+// IssueWallCommand orders builders to place a row of wall segments from
+// start to end, splitting the segments between them in order along the
+// line; it returns how many segments were ordered
+func (cp *CommandProcessor) IssueWallCommand(unitIDs []int, buildingType string, start, end Vector3) (int, error) {
+	if !IsWallType(buildingType) && !IsGateType(buildingType) {
+		return 0, fmt.Errorf("%s is not a wall or gate", buildingType)
+	}
+	if len(unitIDs) == 0 {
+		return 0, fmt.Errorf("no builders for the wall")
+	}
+
+	// Tiles already blocked keep whatever stands there
+	var cells []Vector2i
+	for _, cell := range WallLine(cp.world.WorldToGrid(start).Grid, cp.world.WorldToGrid(end).Grid) {
+		if cp.world.IsPositionWalkable(cell) {
+			cells = append(cells, cell)
+		}
+	}
+	if len(cells) == 0 {
+		return 0, fmt.Errorf("no free tiles to build the wall on")
+	}
+
+	// Each builder takes a contiguous stretch of the wall
+	ordered := 0
+	perBuilder := (len(cells) + len(unitIDs) - 1) / len(unitIDs)
+	for i, unitID := range unitIDs {
+		first := i * perBuilder
+		if first >= len(cells) {
+			break
+		}
+		last := first + perBuilder
+		if last > len(cells) {
+			last = len(cells)
+		}
+		for j, cell := range cells[first:last] {
+			grid := GridPosition{Grid: cell, Offset: Vector2{X: 0.5, Y: 0.5}}
+			command := CreateGridBuildCommand(grid, buildingType, cp.world.tileSize, j > 0)
+			if err := cp.IssueCommand(unitID, command); err != nil {
+				return ordered, fmt.Errorf("builder %d: %w", unitID, err)
+			}
+			ordered++
+		}
+	}
+	return ordered, nil
+}
+
+// registerWall records a wall segment or gate on its tile once construction starts
+func (w *World) registerWall(building *GameBuilding, cell Vector2i) {
+	if !IsWallType(building.BuildingType) && !IsGateType(building.BuildingType) {
+		return
+	}
+
+	w.gridMutex.Lock()
+	defer w.gridMutex.Unlock()
+	if w.walls == nil {
+		w.walls = make(map[Vector2i]wallCell)
+	}
+	w.walls[cell] = wallCell{BuildingID: building.ID, PlayerID: building.PlayerID, Gate: IsGateType(building.BuildingType)}
+}
+
+// restoreWall blocks the tile of a wall segment or gate recreated from a
+// savegame and registers it, as a builder does when construction starts
+func (w *World) restoreWall(building *GameBuilding) {
+	if !IsWallType(building.BuildingType) && !IsGateType(building.BuildingType) {
+		return
+	}
+	cell := w.WorldToGrid(building.Position).Grid
+	w.SetOccupied(cell, true)
+	w.SetWalkable(cell, false)
+	w.registerWall(building, cell)
+}
+
+// clearWalls makes every wall and gate tile passable again and forgets them,
+// before the world state they belong to is replaced
+func (w *World) clearWalls() {
+	w.gridMutex.Lock()
+	defer w.gridMutex.Unlock()
+	for cell := range w.walls {
+		if w.isValidGridPosition(cell) {
+			w.occupancyGrid[cell.Y][cell.X] = false
+			w.walkableGrid[cell.Y][cell.X] = true
+		}
+	}
+	w.walls = nil
+}
+
+// releaseBuildingTile makes the tile of a destroyed or cancelled building
+// passable again, including any wall or gate registered on it
+func (w *World) releaseBuildingTile(position Vector3) {
+	cell := w.WorldToGrid(position).Grid
+
+	w.gridMutex.Lock()
+	defer w.gridMutex.Unlock()
+	if !w.isValidGridPosition(cell) {
+		return
+	}
+	w.occupancyGrid[cell.Y][cell.X] = false
+	w.walkableGrid[cell.Y][cell.X] = true
+	delete(w.walls, cell)
+}
+
+// WallAt returns the ID of the wall segment or gate on a tile, or 0
+func (w *World) WallAt(cell Vector2i) int {
+	w.gridMutex.RLock()
+	defer w.gridMutex.RUnlock()
+	return w.walls[cell].BuildingID
+}
+
+// CanPass reports whether a player's units may enter a tile: any walkable
+// tile, or a gate of the player or one of its allies
+func (w *World) CanPass(cell Vector2i, playerID int) bool {
+	w.gridMutex.RLock()
+	wall, isWall := w.walls[cell]
+	w.gridMutex.RUnlock()
+
+	if isWall && wall.Gate && (wall.PlayerID == playerID || w.AreAllied(wall.PlayerID, playerID)) {
+		return true
+	}
+	return w.IsPositionWalkable(cell)
+}
+
+// approachTile returns a tile next to a position that a player's units can
+// stand on (playerID 0 ignores gates)
+func (w *World) approachTile(position Vector3, playerID int) (GridPosition, bool) {
+	for _, neighbor := range GetNeighbors(w.WorldToGrid(position).Grid) {
+		if w.CanPass(neighbor, playerID) {
+			return GridPosition{Grid: neighbor, Offset: Vector2{X: 0.5, Y: 0.5}}, true
+		}
+	}
+	return GridPosition{}, false
+}
+
+// isEnemyWall reports whether a tile holds a wall or gate that blocks a player
+func (w *World) isEnemyWall(cell Vector2i, playerID int) bool {
+	w.gridMutex.RLock()
+	wall, isWall := w.walls[cell]
+	w.gridMutex.RUnlock()
+	return isWall && wall.PlayerID != playerID && !w.AreAllied(wall.PlayerID, playerID)
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+)
+
+// buildTestWall places a finished wall or gate segment the way a builder does
+func buildTestWall(t *testing.T, world *World, playerID int, buildingType string, cell Vector2i) *GameBuilding {
+	t.Helper()
+	world.SetOccupied(cell, true)
+	world.SetWalkable(cell, false)
+	position := GridToWorld(GridPosition{Grid: cell, Offset: Vector2{X: 0.5, Y: 0.5}}, world.tileSize)
+	wall, err := world.ObjectManager.CreateBuilding(playerID, buildingType, position, data.NewSimpleUnit(buildingType, 60, 0, "stone", nil))
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	wall.IsBuilt = true
+	world.registerWall(wall, cell)
+	return wall
+}
+
+// TestWallPlacement tests that a dragged wall covers every tile of the line,
+// is split between the builders and lets only allies through its gates
+func TestWallPlacement(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+
+	// Diagonal walls step one axis at a time so they have no gaps
+	line := WallLine(Vector2i{X: 2, Y: 2}, Vector2i{X: 7, Y: 5})
+	if len(line) != 9 || line[len(line)-1] != (Vector2i{X: 7, Y: 5}) {
+		t.Fatalf("Expected 9 tiles ending at the drag end, got %v", line)
+	}
+	for i := 1; i < len(line); i++ {
+		if absInt(line[i].X-line[i-1].X)+absInt(line[i].Y-line[i-1].Y) != 1 {
+			t.Errorf("Expected adjacent tiles, got %v then %v", line[i-1], line[i])
+		}
+	}
+	if long := WallLine(Vector2i{X: 0, Y: 0}, Vector2i{X: 31, Y: 0}); len(long) != MaxWallSegments {
+		t.Errorf("Expected a long wall to be capped at %d segments, got %d", MaxWallSegments, len(long))
+	}
+
+	// Two builders share six free tiles, the blocked one is left alone
+	worker := data.NewSimpleUnit("worker", 50, 0, "leather", nil)
+	first, _ := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 4, Z: 12}, worker)
+	second, _ := world.ObjectManager.CreateUnit(1, "worker", Vector3{X: 6, Z: 12}, worker)
+	world.SetWalkable(Vector2i{X: 8, Y: 10}, false)
+	segments, err := world.commandProcessor.IssueWallCommand([]int{first.ID, second.ID}, "wall", Vector3{X: 2, Z: 10}, Vector3{X: 8, Z: 10})
+	if err != nil {
+		t.Fatalf("Failed to order the wall: %v", err)
+	}
+	if segments != 6 {
+		t.Errorf("Expected 6 segments, got %d", segments)
+	}
+	if len(first.CommandQueue) != 2 || len(second.CommandQueue) != 2 {
+		t.Errorf("Expected 3 segments per builder, queues %d and %d", len(first.CommandQueue), len(second.CommandQueue))
+	}
+	if target := second.CurrentCommand.GridTarget; target == nil || target.Grid != (Vector2i{X: 5, Y: 10}) {
+		t.Errorf("Expected the second builder to start where the first one stops, got %v", target)
+	}
+	if _, err := world.commandProcessor.IssueWallCommand([]int{first.ID}, "barracks", Vector3{X: 2, Z: 10}, Vector3{X: 8, Z: 10}); err == nil {
+		t.Error("Expected other building types to be rejected")
+	}
+
+	// A wall across the map with a gate in it
+	world.GetPlayer(1).Team = 1
+	if err := world.AddPlayer(3, "Ally", "magic", false); err != nil {
+		t.Fatalf("Failed to add player: %v", err)
+	}
+	world.GetPlayer(3).Team = 1
+	var gate *GameBuilding
+	for y := 0; y < 32; y++ {
+		if y == 16 {
+			gate = buildTestWall(t, world, 1, "gate", Vector2i{X: 20, Y: y})
+			continue
+		}
+		buildTestWall(t, world, 1, "wall", Vector2i{X: 20, Y: y})
+	}
+	gateCell := Vector2i{X: 20, Y: 16}
+	if world.WallAt(gateCell) != gate.ID {
+		t.Fatalf("Expected the gate to be registered on its tile")
+	}
+	if !world.CanPass(gateCell, 1) || !world.CanPass(gateCell, 3) || world.CanPass(gateCell, 2) {
+		t.Error("Expected the gate to let its owner and allies through but not enemies")
+	}
+
+	pathfinder := NewPathfinder(world)
+	request := PathRequest{Start: GridPosition{Grid: Vector2i{X: 15, Y: 16}}, Target: GridPosition{Grid: Vector2i{X: 25, Y: 16}}, UnitSize: 1}
+	for _, playerID := range []int{1, 3} {
+		request.PlayerID = playerID
+		if result := pathfinder.FindPath(request); !result.Success {
+			t.Errorf("Expected player %d to path through the gate", playerID)
+		}
+	}
+	request.PlayerID = 2
+	if result := pathfinder.FindPath(request); result.Success {
+		t.Error("Expected the enemy to be shut out by the wall")
+	}
+
+	// Destroying the gate opens the tile for everyone
+	if !world.commandProcessor.combatSystem.ApplyBuildingDamage(gate, 100) {
+		t.Fatal("Expected the gate to be destroyed")
+	}
+	if world.WallAt(gateCell) != 0 || !world.IsPositionWalkable(gateCell) {
+		t.Error("Expected the destroyed gate to leave a walkable tile")
+	}
+	if result := pathfinder.FindPath(request); !result.Success {
+		t.Error("Expected the enemy to path through the breach")
+	}
+}
+
+// TestAIWallSiege tests that the AI sends its army against a wall that shuts
+// it out of the enemy base, and that the army breaks through
+func TestAIWallSiege(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	world.SetClock(clock)
+
+	base := data.NewSimpleUnit("castle", 2000, 0, "stone", nil)
+	if _, err := world.ObjectManager.CreateBuilding(1, "castle", Vector3{X: 26.5, Z: 16.5}, base); err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	for y := 0; y < 32; y++ {
+		buildTestWall(t, world, 1, "wall", Vector2i{X: 20, Y: y})
+	}
+
+	soldier, _ := world.ObjectManager.CreateUnit(2, "soldier", Vector3{X: 18.5, Z: 16.5}, data.NewSimpleUnit("soldier", 100, 0, "leather", nil))
+	soldier.AttackDamage = 20
+	soldier.AttackRange = 1
+	soldier.AttackSpeed = 1
+
+	// The soldier has the castle behind the wall in sight
+	ai := NewStrategicAI(2, world, BalancedPersonality, DifficultyNormal)
+	ai.GetIntel().Update()
+	military := NewMilitaryManager(2, world, ai)
+	wall := military.blockingWall()
+	if wall == nil || !IsWallType(wall.BuildingType) || wall.PlayerID != 1 {
+		t.Fatalf("Expected an enemy wall to block the attack, got %+v", wall)
+	}
+	military.identifyMilitaryTargets()
+	if len(military.militaryTargets) == 0 || military.militaryTargets[0].Type != "enemy_wall" {
+		t.Errorf("Expected the blocking wall to be the first target, got %+v", military.militaryTargets)
+	}
+
+	military.planSiegeOperation()
+	if soldier.CurrentCommand == nil || soldier.CurrentCommand.TargetBuilding != wall {
+		t.Fatalf("Expected the soldier to be sent against the wall")
+	}
+
+	for i := 0; i < 200 && wall.IsAlive(); i++ {
+		clock.Advance(100 * time.Millisecond)
+		world.Update(100 * time.Millisecond)
+	}
+	if wall.IsAlive() {
+		t.Fatalf("Expected the soldier to break the wall, health %d", wall.Health)
+	}
+	if soldier.CurrentCommand != nil {
+		t.Error("Expected the attack to end with the wall")
+	}
+	if military.blockingWall() != nil {
+		t.Error("Expected the breach to open the way to the base")
+	}
+}
+
+// TestWallSaveGameRoundTrip tests that a gate keeps blocking enemies after a
+// save is loaded, and that walls of the replaced state do not linger
+func TestWallSaveGameRoundTrip(t *testing.T) {
+	world, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	gateCell, wallCell := Vector2i{X: 10, Y: 10}, Vector2i{X: 12, Y: 10}
+	buildTestWall(t, world, 1, "gate", gateCell)
+
+	path := filepath.Join(t.TempDir(), "gate"+SaveGameExtension)
+	if err := WriteSaveGame(path, world.CaptureSaveGame()); err != nil {
+		t.Fatalf("WriteSaveGame failed: %v", err)
+	}
+
+	// A wall built after saving must be gone once the save is loaded
+	buildTestWall(t, world, 1, "wall", wallCell)
+	loaded, err := ReadSaveGame(path)
+	if err != nil {
+		t.Fatalf("ReadSaveGame failed: %v", err)
+	}
+	if err := world.RestoreSaveGame(loaded); err != nil {
+		t.Fatalf("RestoreSaveGame failed: %v", err)
+	}
+
+	gate := world.ObjectManager.GetBuilding(world.WallAt(gateCell))
+	if gate == nil || gate.BuildingType != "gate" {
+		t.Fatal("Expected the restored gate to be registered on its tile")
+	}
+	if !world.CanPass(gateCell, 1) || world.CanPass(gateCell, 2) {
+		t.Error("Expected the restored gate to let its owner through but not enemies")
+	}
+	if world.WallAt(wallCell) != 0 || !world.IsPositionWalkable(wallCell) {
+		t.Error("Expected the wall built after saving to be cleared")
+	}
+
+	// A world that never saw the gate rebuilds it from the save
+	fresh, err := NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	if err := fresh.RestoreSaveGame(loaded); err != nil {
+		t.Fatalf("RestoreSaveGame failed: %v", err)
+	}
+	if fresh.WallAt(gateCell) == 0 || !fresh.CanPass(gateCell, 1) || fresh.CanPass(gateCell, 2) {
+		t.Error("Expected the gate rebuilt in a fresh world to shut out enemies")
+	}
+}
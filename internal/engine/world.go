@@ -3,10 +3,14 @@ package engine
 import (
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"teraglest/internal/data"
+	"teraglest/internal/debugdraw"
+	"teraglest/internal/logging"
 )
 
 // World represents the complete game world state
@@ -14,12 +18,13 @@ type World struct {
 	mutex        sync.RWMutex                    // Thread-safe access to world state
 	settings     GameSettings                    // Game configuration
 	techTree     *data.TechTree                  // Tech tree data
-	assetMgr     *data.AssetManager              // Asset management
+	assetMgr     data.AssetProvider              // Tech tree, faction and unit data
 
 	// World state
 	players      map[int]*Player                 // All players in the game (human + AI)
 	ObjectManager *ObjectManager                 // Centralized object management
 	commandProcessor *CommandProcessor           // Command system integration
+	commandLog   commandHistory                  // Recently issued commands for crash reports
 	pathfindingMgr *PathfindingManager           // A* pathfinding system
 	behaviorTreeMgr *BehaviorTreeManager         // Unit AI behavior tree system
 	strategicAIMgr *StrategicAIManager           // Strategic AI management system
@@ -30,6 +35,22 @@ type World struct {
 	// World management
 	nextEntityID int                             // Next available entity ID
 	gameTime     time.Duration                   // Total game time elapsed
+	clock        Clock                           // Wall-clock source (nil uses the system clock)
+	events       worldEvents                     // Events raised by world systems for the game
+	market       Market                          // Resource exchange rates shared by all players
+	controlGroups ControlGroups                  // Players' numbered unit groups
+	mods         modRegistry                     // Hooks added by mods
+	shared       sharedControl                   // Players commanding another's faction (co-op)
+	inactivity   inactivityTracker               // When each human player last gave an order
+	outcome      matchOutcome                    // Whether the match is decided and who won
+	economy      economyTracker                  // Recent resource transactions for the economy report
+	upkeep       upkeepTracker                   // Army upkeep owed in upkeep mode
+	hazardTracker hazardTracker                  // Time units have stood on hazards
+	decals       decalPool                       // Scorch marks, craters and rubble on the ground
+	animations   animationTracker                // Functions called when unit animations pass markers
+	transforms   tickTransforms                  // Unit transforms of the last two ticks, for drawing between them
+	buckets      spatialBuckets                  // Units and buildings by chunk as of the last tick
+	mapAnalysis  mapAnalysisState                // Regions, chokepoints and expansion sites of the map
 	initialized  bool                            // Whether world has been initialized
 
 	// Spatial organization
@@ -40,9 +61,14 @@ type World struct {
 	TerrainMap   *TerrainMap                     // Terrain data for pathfinding
 
 	// Grid system for positioning and collision detection
+	gridMutex     sync.RWMutex                  // Guards the grids separately so systems can use them during Update
 	occupancyGrid [][]bool                      // Track which tiles have units/buildings
 	heightMap     [][]float32                   // Basic terrain heights
 	walkableGrid  [][]bool                      // Which tiles are passable
+	walls         map[Vector2i]wallCell         // Tiles blocked by wall segments and gates (see walls.go)
+	hazards       map[Vector2i]*SurfaceHazard   // Tiles that hurt units standing on them (see hazards.go)
+	groundLevel      float32                    // Average terrain height, measured from for sight bonuses
+	groundLevelKnown bool                       // Whether groundLevel is up to date with heightMap
 
 	// Game mechanics
 	resourceGenerationRate map[string]float32    // Resource generation rates
@@ -57,6 +83,7 @@ type Player struct {
 	FactionName  string                          // Faction being played
 	IsAI         bool                            // Whether this is an AI player
 	IsActive     bool                            // Whether player is still active
+	Team         int                             // Alliance team; players on the same non-zero team are allies
 
 	// Player state
 	Resources    map[string]int                  // Current resource amounts
@@ -118,7 +145,7 @@ func NewTerrainMap(width, height int) *TerrainMap {
 
 
 // NewWorld creates a new game world instance
-func NewWorld(settings GameSettings, techTree *data.TechTree, assetMgr *data.AssetManager) (*World, error) {
+func NewWorld(settings GameSettings, techTree *data.TechTree, assetMgr data.AssetProvider) (*World, error) {
 	world := &World{
 		settings:      settings,
 		techTree:      techTree,
@@ -170,9 +197,13 @@ func NewWorld(settings GameSettings, techTree *data.TechTree, assetMgr *data.Ass
 }
 
 // NewWorldFromMap creates a new game world instance from a map file
-func NewWorldFromMap(settings GameSettings, techTree *data.TechTree, assetMgr *data.AssetManager, mapName string) (*World, error) {
+func NewWorldFromMap(settings GameSettings, techTree *data.TechTree, assetMgr data.AssetProvider, mapName string) (*World, error) {
 	// Create MapManager for loading map data
-	dataRoot := "/home/solifugus/development/teraglest/megaglest-source/data/glest_game" // TODO: make configurable
+	dataRoot := settings.DataRoot
+	if dataRoot == "" && assetMgr != nil {
+		// Tech trees live in <dataRoot>/techs/<name>
+		dataRoot = filepath.Dir(filepath.Dir(assetMgr.GetTechTreeRoot()))
+	}
 	mapManager := NewMapManager(assetMgr, dataRoot)
 
 	// Load map data
@@ -236,6 +267,14 @@ func NewWorldFromMap(settings GameSettings, techTree *data.TechTree, assetMgr *d
 		return nil, fmt.Errorf("failed to initialize world from map: %w", err)
 	}
 
+	// Unit AI modders placed in <dataRoot>/behaviors, reloaded as it is edited
+	behaviorDir := filepath.Join(dataRoot, "behaviors")
+	if _, err := os.Stat(behaviorDir); err == nil {
+		if err := world.behaviorTreeMgr.WatchBehaviors(behaviorDir); err != nil {
+			logging.Warnf(logging.CategoryAI, "Behavior trees: %v", err)
+		}
+	}
+
 	return world, nil
 }
 
@@ -263,15 +302,37 @@ func (w *World) Initialize() error {
 		}
 	}
 
+	// Let co-op players command the factions they share
+	for controllerID, ownerID := range w.settings.SharedControl {
+		if err := w.ShareControl(controllerID, ownerID); err != nil {
+			return fmt.Errorf("failed to share player %d's faction: %w", ownerID, err)
+		}
+	}
+	w.SetSharedConflictPolicy(w.settings.SharedConflictPolicy)
+	w.SetTeamSharedControl(w.settings.TeamSharedControl)
+
+	// Keep the bare terrain for the map analysis before starting buildings block it
+	terrain := w.walkableSnapshot()
+
 	// Initialize starting units and resources for each player (no world lock needed)
 	for _, player := range w.players {
 		if err := w.initializePlayerStartingState(player); err != nil {
 			return fmt.Errorf("failed to initialize starting state for player %d: %w", player.ID, err)
 		}
 	}
+	w.linkTeamResources(true)
+
+	// Start the strategic AI of each AI player with its skirmish settings
+	for playerID := range w.settings.AIFactions {
+		slot := w.settings.AISlots[playerID]
+		if err := w.InitializeAIPlayer(playerID, slot.Personality, slot.Difficulty); err != nil {
+			return fmt.Errorf("failed to initialize AI for player %d: %w", playerID, err)
+		}
+	}
 
 	// Generate resource nodes on the map (simplified for now)
 	w.generateResourceNodes()
+	w.analyzeMap(terrain)
 
 	// Set initialized flag (with lock)
 	w.mutex.Lock()
@@ -284,26 +345,47 @@ func (w *World) Initialize() error {
 // Update advances the world state by the given delta time
 func (w *World) Update(deltaTime time.Duration) {
 	w.mutex.Lock()
-	defer w.mutex.Unlock()
-
 	if !w.initialized {
+		w.mutex.Unlock()
 		return
 	}
 
 	// Update game time
 	w.gameTime += deltaTime
+	w.economy.sample(w.players, w.gameTime)
+	players := w.players
+	w.mutex.Unlock()
+
+	// Subsystems guard their own state and call back into locking World
+	// accessors (GetPlayer, AddResources, ...), so the world lock is not held here
 
 	// Update all game objects through the ObjectManager
 	w.ObjectManager.Update(deltaTime)
 
-	// Process commands after object updates (pass players to avoid nested locking)
-	w.commandProcessor.UpdateWithPlayers(deltaTime, w.players)
+	// Process commands after object updates
+	w.commandProcessor.UpdateWithPlayers(deltaTime, players)
 
 	// Update production system (building construction and unit production)
 	if w.productionSys != nil {
 		w.productionSys.Update(deltaTime)
 	}
 
+	// Let market prices recover from trading
+	w.market.update(deltaTime)
+
+	// Charge army upkeep when the match plays in upkeep mode
+	w.updateUnitUpkeep(deltaTime)
+
+	// Hurt units standing on hazards, and let status effects run their course
+	w.updateHazards(deltaTime)
+	w.commandProcessor.statusEffectMgr.Update(deltaTime)
+
+	// Fade out old scorch marks, craters and rubble
+	w.updateDecals(deltaTime)
+
+	// Advance unit animations and fire the markers they pass, such as footsteps
+	w.updateAnimations(deltaTime)
+
 	// Update behavior trees for unit AI
 	w.behaviorTreeMgr.Update(deltaTime)
 
@@ -317,6 +399,22 @@ func (w *World) Update(deltaTime time.Duration) {
 		w.groupMgr.Update(deltaTime)
 	}
 
+	// Let mods act on the tick
+	w.modsTick(deltaTime)
+
+	// Warn about idle players and hand those past the timeout to the AI
+	w.updateInactivity()
+
+	// Visualize system state for the enabled debug draw categories
+	w.drawDebug(debugdraw.Default())
+
+	// Keep where units ended the tick, so frames can be drawn between ticks
+	w.recordTickTransforms(time.Now())
+	w.recordSpatialBuckets()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
 	// Update players (resource generation, etc.)
 	for _, player := range w.players {
 		w.updatePlayer(player, deltaTime)
@@ -377,6 +475,7 @@ func (w *World) AddPlayer(playerID int, name string, factionName string, isAI bo
 		FactionName:  factionName,
 		IsAI:         isAI,
 		IsActive:     true,
+		Team:         w.settings.Teams[playerID],
 		Resources:    make(map[string]int),
 		ResourcesGathered: make(map[string]int),
 		ResourcesSpent:    make(map[string]int),
@@ -392,6 +491,12 @@ func (w *World) AddPlayer(playerID int, name string, factionName string, isAI bo
 	return nil
 }
 
+// AI personality and difficulty names accepted by InitializeAIPlayer and AISlotSettings
+var (
+	AIPersonalityNames = []string{"conservative", "aggressive", "balanced", "technological", "expansionist"}
+	AIDifficultyNames  = []string{"easy", "normal", "hard", "expert"}
+)
+
 // InitializeAIPlayer creates AI behavior for a player
 func (w *World) InitializeAIPlayer(playerID int, personality string, difficulty string) error {
 	if w.strategicAIMgr == nil {
@@ -523,15 +628,17 @@ func (w *World) createPlayer(playerID int, factionName string, isAI bool) error
 		FactionName: factionName,
 		IsAI:        isAI,
 		IsActive:    true,
+		Team:        w.settings.Teams[playerID],
 		Resources:   make(map[string]int),
 		ResourcesGathered: make(map[string]int),
 		ResourcesSpent: make(map[string]int),
 		FactionData: factionData,
 	}
 
-	// Initialize starting resources
+	// Initialize starting resources, scaled by the player's handicap
+	handicap := w.resourceHandicap(playerID)
 	for _, startingRes := range factionData.Faction.StartingResources {
-		player.Resources[startingRes.Name] = startingRes.Amount
+		player.Resources[startingRes.Name] = int(float32(startingRes.Amount) * handicap)
 		player.ResourcesGathered[startingRes.Name] = 0
 		player.ResourcesSpent[startingRes.Name] = 0
 	}
@@ -598,7 +705,7 @@ func (w *World) updatePlayer(player *Player, deltaTime time.Duration) {
 	generatedResources := make(map[string]int)
 
 	for _, building := range playerBuildings {
-		if building.IsBuilt && building.Health > 0 {
+		if building.IsBuilt && building.Health > 0 && building.Powered {
 			// Process building-specific resource generation
 			for resourceType, rate := range building.ResourceGeneration {
 				generated := w.calculateResourceGeneration(rate, deltaTime, building)
@@ -654,9 +761,8 @@ func (w *World) DeductResources(playerID int, cost map[string]int, purpose strin
 		return fmt.Errorf("player %d not found", playerID)
 	}
 
-	// Validate before deduction using ResourceValidator
-	validator := NewResourceValidator(w)
-	result := validator.ValidateResources(ResourceCheck{
+	// Validate before deduction; the world lock is held, so check the player directly
+	result := validatePlayerResources(player, ResourceCheck{
 		PlayerID: playerID,
 		Required: cost,
 		Purpose:  purpose,
@@ -756,11 +862,11 @@ func (w *World) calculateResourceRates(playerID int) map[string]float32 {
 	// Get rates from buildings
 	playerBuildings := w.ObjectManager.GetBuildingsForPlayer(playerID)
 	for _, building := range playerBuildings {
-		if building.IsBuilt && building.Health > 0 {
+		if building.IsBuilt && building.Health > 0 && building.Powered {
 			for resType, rate := range building.ResourceGeneration {
 				// Apply upgrade multipliers and game settings
 				upgradeMultiplier := 1.0 + (float32(building.UpgradeLevel-1) * 0.2) // 20% per upgrade
-				gameMultiplier := w.settings.ResourceMultiplier
+				gameMultiplier := w.settings.ResourceMultiplier * w.resourceHandicap(playerID)
 				effectiveRate := rate * upgradeMultiplier * gameMultiplier
 				rates[resType] += effectiveRate
 			}
@@ -773,7 +879,7 @@ func (w *World) calculateResourceRates(playerID int) map[string]float32 {
 		if unit.State == UnitStateGathering && unit.GatherTarget != nil {
 			resType := unit.GatherTarget.ResourceType
 			if gatherRate, ok := unit.GatherRate[resType]; ok {
-				rates[resType] += gatherRate
+				rates[resType] += gatherRate * w.resourceHandicap(playerID)
 			}
 		}
 	}
@@ -785,7 +891,7 @@ func (w *World) calculateResourceRates(playerID int) map[string]float32 {
 func (w *World) calculateResourceGeneration(baseRate float32, deltaTime time.Duration, building *GameBuilding) int {
 	// Factor in building upgrade level and game settings
 	upgradeMultiplier := 1.0 + (float32(building.UpgradeLevel-1) * 0.2) // 20% per upgrade
-	gameMultiplier := w.settings.ResourceMultiplier
+	gameMultiplier := w.settings.ResourceMultiplier * w.resourceHandicap(building.PlayerID)
 
 	effectiveRate := baseRate * upgradeMultiplier * gameMultiplier
 	generated := int(effectiveRate * float32(deltaTime.Seconds()))
@@ -793,6 +899,15 @@ func (w *World) calculateResourceGeneration(baseRate float32, deltaTime time.Dur
 	return generated
 }
 
+// resourceHandicap returns the share of normal starting resources and income
+// a player gets: the AI slot's handicap percentage, 1.0 for everyone else
+func (w *World) resourceHandicap(playerID int) float32 {
+	if handicap := w.settings.AISlots[playerID].Handicap; handicap > 0 {
+		return float32(handicap) / 100
+	}
+	return 1.0
+}
+
 // processResourceDropoffs handles units returning resources to collection points
 func (w *World) processResourceDropoffs(player *Player) {
 	// Get all units for this player
@@ -804,16 +919,15 @@ func (w *World) processResourceDropoffs(player *Player) {
 			// Add carried resources to player pool
 			for resourceType, amount := range unit.CarriedResources {
 				if amount > 0 {
+					amount = int(float32(amount) * w.resourceHandicap(player.ID))
 					player.Resources[resourceType] += amount
 					player.ResourcesGathered[resourceType] += amount
 				}
 			}
 
-			// Clear carried resources
-			unit.CarriedResources = make(map[string]int)
-
-			// Log dropoff event
+			// Log dropoff event, then clear carried resources
 			w.logResourceTransaction(player.ID, unit.CarriedResources, "resource_dropoff", "addition")
+			unit.CarriedResources = make(map[string]int)
 		}
 	}
 }
@@ -848,6 +962,7 @@ func (w *World) logResourceTransaction(playerID int, resources map[string]int, s
 				Amount:          amount,
 				Source:          source,
 				Timestamp:       time.Now(),
+				GameTime:        w.gameTime,
 				TransactionType: transactionType,
 			}
 
@@ -865,6 +980,9 @@ func (w *World) logResourceTransaction(playerID int, resources map[string]int, s
 				continue // Skip unknown transaction types
 			}
 
+			// Keep the transaction for the economy report
+			w.economy.record(playerID, resourceType, amount, source, transactionType, w.gameTime)
+
 			// Create game event
 			gameEvent := GameEvent{
 				Type:      eventType,
@@ -880,13 +998,10 @@ func (w *World) logResourceTransaction(playerID int, resources map[string]int, s
 	}
 }
 
-// sendResourceEvent sends a resource event to the game's event system
+// sendResourceEvent sends a resource event to the game's event system, where
+// the StatsRecorder keeps it in the transaction log
 func (w *World) sendResourceEvent(event GameEvent) {
-	// TODO: This would be connected to the actual game instance's event system
-	// For now, we'll just log it (could be expanded to send to game.eventQueue)
-
-	// Simple logging for development - in production this would send to game.eventQueue
-	// fmt.Printf("[RESOURCE EVENT] %s\n", event.Message)
+	w.raiseEvent(event)
 }
 
 // CalculateDistance calculates the Euclidean distance between two 3D points
@@ -955,6 +1070,9 @@ func (w *World) initializeFromMap(mapData *Map) error {
 		}
 	}
 
+	// Mark tiles whose surface hurts units
+	w.applyTilesetHazards(mapData)
+
 	// Initialize player starting positions
 	if err := w.initializeStartPositions(mapData.StartPositions); err != nil {
 		return fmt.Errorf("failed to initialize start positions: %w", err)
@@ -1052,7 +1170,7 @@ func (w *World) placeResourceNodesFromMap(mapData *Map) error {
 		}
 	}
 
-	fmt.Printf("Placed %d resource nodes from map data\n", resourceNodeCount)
+	logging.Infof(logging.CategoryEngine, "Placed %d resource nodes from map data", resourceNodeCount)
 	return nil
 }
 
@@ -1148,8 +1266,8 @@ func (w *World) placeResourceNode(x, y int, resourceType string) error {
 
 // IsPositionWalkable checks if a grid position is walkable (not occupied and not blocked)
 func (w *World) IsPositionWalkable(gridPos Vector2i) bool {
-	w.mutex.RLock()
-	defer w.mutex.RUnlock()
+	w.gridMutex.RLock()
+	defer w.gridMutex.RUnlock()
 
 	// Check bounds
 	if !w.isValidGridPosition(gridPos) {
@@ -1162,8 +1280,8 @@ func (w *World) IsPositionWalkable(gridPos Vector2i) bool {
 
 // SetOccupied sets the occupancy status of a grid tile
 func (w *World) SetOccupied(gridPos Vector2i, occupied bool) {
-	w.mutex.Lock()
-	defer w.mutex.Unlock()
+	w.gridMutex.Lock()
+	defer w.gridMutex.Unlock()
 
 	if !w.isValidGridPosition(gridPos) {
 		return
@@ -1180,8 +1298,8 @@ func (w *World) SetOccupied(gridPos Vector2i, occupied bool) {
 
 // GetHeight returns the terrain height at a grid position
 func (w *World) GetHeight(gridPos Vector2i) float32 {
-	w.mutex.RLock()
-	defer w.mutex.RUnlock()
+	w.gridMutex.RLock()
+	defer w.gridMutex.RUnlock()
 
 	if !w.isValidGridPosition(gridPos) {
 		return 0.0 // Default ground level
@@ -1220,6 +1338,13 @@ func (w *World) SetOccupiedGrid(gridPos GridPosition, occupied bool) {
 	w.SetOccupied(gridPos.Grid, occupied)
 }
 
+// GetResourceNode returns a resource node by ID, or nil
+func (w *World) GetResourceNode(nodeID int) *ResourceNode {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	return w.resources[nodeID]
+}
+
 // GetAllResourceNodes returns all resource nodes in the world
 func (w *World) GetAllResourceNodes() []*ResourceNode {
 	w.mutex.RLock()
@@ -1234,20 +1359,21 @@ func (w *World) GetAllResourceNodes() []*ResourceNode {
 
 // SetHeight sets the terrain height at a grid position
 func (w *World) SetHeight(gridPos Vector2i, height float32) {
-	w.mutex.Lock()
-	defer w.mutex.Unlock()
+	w.gridMutex.Lock()
+	defer w.gridMutex.Unlock()
 
 	if !w.isValidGridPosition(gridPos) {
 		return
 	}
 
 	w.heightMap[gridPos.Y][gridPos.X] = height
+	w.groundLevelKnown = false
 }
 
 // SetWalkable sets whether a grid position is walkable
 func (w *World) SetWalkable(gridPos Vector2i, walkable bool) {
-	w.mutex.Lock()
-	defer w.mutex.Unlock()
+	w.gridMutex.Lock()
+	defer w.gridMutex.Unlock()
 
 	if !w.isValidGridPosition(gridPos) {
 		return
@@ -1270,8 +1396,8 @@ func (w *World) GetUnitsInTile(gridPos Vector2i) []*GameUnit {
 
 // GetNearestWalkablePosition finds the nearest walkable position to a target
 func (w *World) GetNearestWalkablePosition(targetPos Vector2i) Vector2i {
-	w.mutex.RLock()
-	defer w.mutex.RUnlock()
+	w.gridMutex.RLock()
+	defer w.gridMutex.RUnlock()
 
 	// If the target position is already walkable, return it
 	if w.isValidGridPosition(targetPos) && w.walkableGrid[targetPos.Y][targetPos.X] && !w.occupancyGrid[targetPos.Y][targetPos.X] {
@@ -0,0 +1,161 @@
+//go:build !js
+
+package graphics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Color LUT sizes accepted from .cube files
+const (
+	MinLUTSize = 2
+	MaxLUTSize = 128
+)
+
+// ColorLUT is a 3D color lookup table for color grading. Data holds RGB
+// triples with red changing fastest, then green, then blue, as in .cube files.
+type ColorLUT struct {
+	Title     string
+	Size      int
+	Data      []float32
+	DomainMin [3]float32 // Input color mapped to the first entry of each axis
+	DomainMax [3]float32 // Input color mapped to the last entry of each axis
+}
+
+// IdentityLUT returns a table mapping every color to itself
+func IdentityLUT(size int) *ColorLUT {
+	lut := &ColorLUT{
+		Title:     "identity",
+		Size:      size,
+		Data:      make([]float32, 0, size*size*size*3),
+		DomainMax: [3]float32{1, 1, 1},
+	}
+	scale := float32(size - 1)
+	for b := 0; b < size; b++ {
+		for g := 0; g < size; g++ {
+			for r := 0; r < size; r++ {
+				lut.Data = append(lut.Data, float32(r)/scale, float32(g)/scale, float32(b)/scale)
+			}
+		}
+	}
+	return lut
+}
+
+// LoadCubeLUT reads a 3D LUT in the Adobe/Resolve .cube format
+func LoadCubeLUT(path string) (*ColorLUT, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open color LUT: %w", err)
+	}
+	defer file.Close()
+
+	lut, err := ParseCubeLUT(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color LUT %s: %w", path, err)
+	}
+	return lut, nil
+}
+
+// ParseCubeLUT parses a 3D LUT in the .cube format
+func ParseCubeLUT(r io.Reader) (*ColorLUT, error) {
+	lut := &ColorLUT{DomainMax: [3]float32{1, 1, 1}}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+
+		switch fields[0] {
+		case "TITLE":
+			lut.Title = strings.Trim(strings.TrimSpace(strings.TrimPrefix(text, "TITLE")), `"`)
+		case "LUT_3D_SIZE":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: LUT_3D_SIZE needs one value", line)
+			}
+			size, err := strconv.Atoi(fields[1])
+			if err != nil || size < MinLUTSize || size > MaxLUTSize {
+				return nil, fmt.Errorf("line %d: LUT size must be %d-%d, got %s", line, MinLUTSize, MaxLUTSize, fields[1])
+			}
+			lut.Size = size
+			lut.Data = make([]float32, 0, size*size*size*3)
+		case "LUT_1D_SIZE":
+			return nil, fmt.Errorf("line %d: 1D LUTs are not supported", line)
+		case "DOMAIN_MIN", "DOMAIN_MAX":
+			values, err := parseLUTTriple(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if fields[0] == "DOMAIN_MIN" {
+				lut.DomainMin = values
+			} else {
+				lut.DomainMax = values
+			}
+		default:
+			if lut.Size == 0 {
+				return nil, fmt.Errorf("line %d: table data before LUT_3D_SIZE", line)
+			}
+			values, err := parseLUTTriple(fields)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if len(lut.Data) == cap(lut.Data) {
+				return nil, fmt.Errorf("line %d: more than %d table entries", line, lut.Size*lut.Size*lut.Size)
+			}
+			lut.Data = append(lut.Data, values[:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if lut.Size == 0 {
+		return nil, fmt.Errorf("missing LUT_3D_SIZE")
+	}
+	if expected := lut.Size * lut.Size * lut.Size * 3; len(lut.Data) != expected {
+		return nil, fmt.Errorf("expected %d table entries, got %d", expected/3, len(lut.Data)/3)
+	}
+
+	for channel := 0; channel < 3; channel++ {
+		if lut.DomainMax[channel] <= lut.DomainMin[channel] {
+			return nil, fmt.Errorf("empty domain for channel %d", channel)
+		}
+	}
+	return lut, nil
+}
+
+// parseLUTTriple parses three float values
+func parseLUTTriple(fields []string) ([3]float32, error) {
+	var values [3]float32
+	if len(fields) != 3 {
+		return values, fmt.Errorf("expected 3 values, got %d", len(fields))
+	}
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			return values, fmt.Errorf("invalid value %q", field)
+		}
+		values[i] = float32(value)
+	}
+	return values, nil
+}
+
+// Lookup returns the table entry nearest to a color
+func (lut *ColorLUT) Lookup(r, g, b float32) [3]float32 {
+	index := func(value float32, channel int) int {
+		normalized := (value - lut.DomainMin[channel]) / (lut.DomainMax[channel] - lut.DomainMin[channel])
+		i := int(normalized*float32(lut.Size-1) + 0.5)
+		return min(max(i, 0), lut.Size-1)
+	}
+	offset := ((index(b, 2)*lut.Size+index(g, 1))*lut.Size + index(r, 0)) * 3
+	return [3]float32{lut.Data[offset], lut.Data[offset+1], lut.Data[offset+2]}
+}
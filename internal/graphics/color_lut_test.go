@@ -0,0 +1,69 @@
+//go:build !js
+
+package graphics
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseCubeLUT tests reading a .cube color lookup table
+func TestParseCubeLUT(t *testing.T) {
+	// A 2x2x2 table swapping red and blue, over a domain of 0-2
+	cube := `# Swap red and blue
+TITLE "swap"
+LUT_3D_SIZE 2
+DOMAIN_MIN 0 0 0
+DOMAIN_MAX 2 2 2
+0 0 0
+0 0 1
+0 1 0
+0 1 1
+1 0 0
+1 0 1
+1 1 0
+1 1 1
+`
+	lut, err := ParseCubeLUT(strings.NewReader(cube))
+	if err != nil {
+		t.Fatalf("Failed to parse LUT: %v", err)
+	}
+	if lut.Title != "swap" || lut.Size != 2 || len(lut.Data) != 2*2*2*3 {
+		t.Fatalf("Expected a 2x2x2 table titled swap, got %q size %d with %d values", lut.Title, lut.Size, len(lut.Data))
+	}
+	if got := lut.Lookup(2, 0, 0); got != [3]float32{0, 0, 1} {
+		t.Errorf("Expected red to map to blue, got %v", got)
+	}
+	if got := lut.Lookup(0.4, 1.8, 0); got != [3]float32{0, 1, 0} {
+		t.Errorf("Expected the nearest entry within the domain, got %v", got)
+	}
+}
+
+// TestIdentityLUT tests that the identity table keeps colors
+func TestIdentityLUT(t *testing.T) {
+	lut := IdentityLUT(5)
+	if got := lut.Lookup(0.25, 0.5, 1); got != [3]float32{0.25, 0.5, 1} {
+		t.Errorf("Expected the identity table to keep colors, got %v", got)
+	}
+	if got := lut.Lookup(-1, 2, 0.5); got != [3]float32{0, 1, 0.5} {
+		t.Errorf("Expected colors outside the domain to clamp, got %v", got)
+	}
+}
+
+// TestParseCubeLUTErrors tests rejecting malformed tables
+func TestParseCubeLUTErrors(t *testing.T) {
+	cases := map[string]string{
+		"no size":      "0 0 0\n",
+		"1D table":     "LUT_1D_SIZE 4\n",
+		"bad size":     "LUT_3D_SIZE 1\n",
+		"short table":  "LUT_3D_SIZE 2\n0 0 0\n",
+		"long table":   "LUT_3D_SIZE 2\n" + strings.Repeat("0 0 0\n", 9),
+		"bad entry":    "LUT_3D_SIZE 2\n0 0\n",
+		"empty domain": "LUT_3D_SIZE 2\nDOMAIN_MIN 1 0 0\nDOMAIN_MAX 1 1 1\n" + strings.Repeat("0 0 0\n", 8),
+	}
+	for name, cube := range cases {
+		if _, err := ParseCubeLUT(strings.NewReader(cube)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Package device abstracts the graphics API behind the renderer: buffers,
+// textures, shader programs, render state and draw calls. The OpenGL backend
+// draws to the window; the null backend records what would be drawn, so
+// render code can run in headless tests and future backends can be slotted in
+// without changing it.
+package device
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Handles of device resources; zero is never a valid resource
+type (
+	Buffer      uint32
+	VertexArray uint32
+	Texture     uint32
+	Program     uint32
+)
+
+// BufferUsage hints how often a buffer's contents change
+type BufferUsage int
+
+const (
+	UsageStatic  BufferUsage = iota // Uploaded once, drawn many times
+	UsageDynamic                    // Updated now and then
+	UsageStream                     // Uploaded every frame
+)
+
+// Primitive is how vertices are assembled when drawing
+type Primitive int
+
+const (
+	Triangles Primitive = iota
+	Lines
+)
+
+// TextureFormat is the pixel layout of a texture
+type TextureFormat int
+
+const (
+	FormatRGBA8 TextureFormat = iota // Four bytes per pixel
+	FormatR8                         // One byte per pixel, read as red
+)
+
+// BytesPerPixel returns the size of a pixel of the format
+func (f TextureFormat) BytesPerPixel() int {
+	if f == FormatR8 {
+		return 1
+	}
+	return 4
+}
+
+// TextureDesc describes a texture to create
+type TextureDesc struct {
+	Width, Height int
+	Format        TextureFormat
+	Linear        bool // Linear filtering; nearest otherwise
+	ClampToEdge   bool // Clamp coordinates; repeat otherwise
+}
+
+// VertexLayout lists the component counts of interleaved float vertex
+// attributes, in attribute order: {3, 4} is a position then an RGBA color
+type VertexLayout []int
+
+// Stride returns the size of a vertex in floats
+func (l VertexLayout) Stride() int {
+	stride := 0
+	for _, size := range l {
+		stride += size
+	}
+	return stride
+}
+
+// StencilMode is how draws use the stencil buffer, which marks pixels with
+// one bit so later draws can be kept to or away from them
+type StencilMode int
+
+const (
+	StencilOff      StencilMode = iota // No stencil test
+	StencilMark                        // Mark the pixels drawn
+	StencilUnmarked                    // Draw only where no pixel was marked
+)
+
+// RenderState is the fixed-function state draws run with
+type RenderState struct {
+	DepthTest     bool // Pass fragments in front of or level with what is drawn
+	DepthGreater  bool // Pass fragments behind what is drawn instead
+	DepthReadOnly bool // Test depth without writing it
+	CullFace      bool // Cull back faces
+	AlphaBlend    bool // Blend by source alpha
+	Wireframe     bool // Rasterize polygon edges only
+	ColorOff      bool // Write depth and stencil only
+	Stencil       StencilMode
+}
+
+// OverlayState is the state of 2D and debug overlays: drawn over the scene,
+// blended and filled
+var OverlayState = RenderState{AlphaBlend: true}
+
+// GraphicsDevice creates GPU resources and draws with them. Calls must come
+// from the thread owning the graphics context.
+type GraphicsDevice interface {
+	// Name identifies the backend in logs
+	Name() string
+
+	// CreateVertexArray creates a vertex array reading the layout from a new vertex buffer
+	CreateVertexArray(layout VertexLayout) (VertexArray, Buffer)
+	// CreateInstancedVertexArray creates a vertex array reading the layout per
+	// vertex from a new vertex buffer and the instance layout per instance from
+	// a new instance buffer; instance attributes follow the vertex attributes
+	CreateInstancedVertexArray(layout, instanceLayout VertexLayout) (VertexArray, Buffer, Buffer)
+	// UploadVertices replaces the contents of a vertex buffer
+	UploadVertices(buffer Buffer, vertices []float32, usage BufferUsage)
+	DeleteVertexArray(vao VertexArray)
+	DeleteBuffer(buffer Buffer)
+
+	// CreateTexture creates a 2D texture; pixels may be nil to leave it uninitialized
+	CreateTexture(desc TextureDesc, pixels []byte) Texture
+	// UpdateTexture replaces all pixels of a texture created with desc
+	UpdateTexture(texture Texture, desc TextureDesc, pixels []byte)
+	// BindTexture binds a texture to a texture unit, 0 to unbind
+	BindTexture(unit int, texture Texture)
+	DeleteTexture(texture Texture)
+
+	// CreateProgram compiles and links a shader program from vertex and fragment sources
+	CreateProgram(vertexSource, fragmentSource string) (Program, error)
+	UseProgram(program Program)
+	// UniformLocation returns the location of a uniform, -1 if the program has none by that name
+	UniformLocation(program Program, name string) int32
+	SetUniformMat4(location int32, value mgl32.Mat4)
+	SetUniformVec4(location int32, value mgl32.Vec4)
+	SetUniformVec3(location int32, value mgl32.Vec3)
+	SetUniformVec2(location int32, value mgl32.Vec2)
+	SetUniformFloat(location int32, value float32)
+	SetUniformInt(location int32, value int32)
+	DeleteProgram(program Program)
+
+	// State returns the current render state
+	State() RenderState
+	// SetState changes the render state
+	SetState(state RenderState)
+
+	// Draw draws count vertices of a vertex array from first
+	Draw(vao VertexArray, primitive Primitive, first, count int)
+	// DrawInstanced draws count vertices of an instanced vertex array from
+	// first, once for each of the first instances in its instance buffer
+	DrawInstanced(vao VertexArray, primitive Primitive, first, count, instances int)
+}
@@ -0,0 +1,349 @@
+//go:build !js
+
+package device
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// GL is the OpenGL 3.3 core backend. It needs a current context.
+type GL struct{}
+
+// NewGL creates the OpenGL backend
+func NewGL() *GL {
+	return &GL{}
+}
+
+// Name identifies the backend in logs
+func (d *GL) Name() string {
+	return "opengl"
+}
+
+// CreateVertexArray creates a vertex array reading the layout from a new vertex buffer
+func (d *GL) CreateVertexArray(layout VertexLayout) (VertexArray, Buffer) {
+	var vao, vbo uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.GenBuffers(1, &vbo)
+	gl.BindVertexArray(vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	stride := int32(layout.Stride() * 4)
+	offset := 0
+	for i, size := range layout {
+		gl.VertexAttribPointer(uint32(i), int32(size), gl.FLOAT, false, stride, gl.PtrOffset(offset*4))
+		gl.EnableVertexAttribArray(uint32(i))
+		offset += size
+	}
+	gl.BindVertexArray(0)
+	return VertexArray(vao), Buffer(vbo)
+}
+
+// CreateInstancedVertexArray creates a vertex array reading the layout per
+// vertex and the instance layout per instance, from two new buffers
+func (d *GL) CreateInstancedVertexArray(layout, instanceLayout VertexLayout) (VertexArray, Buffer, Buffer) {
+	vao, vbo := d.CreateVertexArray(layout)
+	var instanceVBO uint32
+	gl.GenBuffers(1, &instanceVBO)
+	gl.BindVertexArray(uint32(vao))
+	gl.BindBuffer(gl.ARRAY_BUFFER, instanceVBO)
+	stride := int32(instanceLayout.Stride() * 4)
+	offset := 0
+	for i, size := range instanceLayout {
+		location := uint32(len(layout) + i)
+		gl.VertexAttribPointer(location, int32(size), gl.FLOAT, false, stride, gl.PtrOffset(offset*4))
+		gl.EnableVertexAttribArray(location)
+		gl.VertexAttribDivisor(location, 1)
+		offset += size
+	}
+	gl.BindVertexArray(0)
+	return vao, vbo, Buffer(instanceVBO)
+}
+
+// UploadVertices replaces the contents of a vertex buffer
+func (d *GL) UploadVertices(buffer Buffer, vertices []float32, usage BufferUsage) {
+	gl.BindBuffer(gl.ARRAY_BUFFER, uint32(buffer))
+	if len(vertices) == 0 {
+		gl.BufferData(gl.ARRAY_BUFFER, 0, nil, glUsage(usage))
+		return
+	}
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), glUsage(usage))
+}
+
+// DeleteVertexArray frees a vertex array
+func (d *GL) DeleteVertexArray(vao VertexArray) {
+	id := uint32(vao)
+	gl.DeleteVertexArrays(1, &id)
+}
+
+// DeleteBuffer frees a buffer
+func (d *GL) DeleteBuffer(buffer Buffer) {
+	id := uint32(buffer)
+	gl.DeleteBuffers(1, &id)
+}
+
+// CreateTexture creates a 2D texture; pixels may be nil to leave it uninitialized
+func (d *GL) CreateTexture(desc TextureDesc, pixels []byte) Texture {
+	var id uint32
+	gl.GenTextures(1, &id)
+	gl.BindTexture(gl.TEXTURE_2D, id)
+
+	internalFormat, format := int32(gl.RGBA8), uint32(gl.RGBA)
+	if desc.Format == FormatR8 {
+		internalFormat, format = gl.R8, gl.RED
+		gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+		defer gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	}
+	data := gl.Ptr(nil)
+	if len(pixels) > 0 {
+		data = gl.Ptr(pixels)
+	}
+	gl.TexImage2D(gl.TEXTURE_2D, 0, internalFormat, int32(desc.Width), int32(desc.Height), 0, format, gl.UNSIGNED_BYTE, data)
+
+	filter := int32(gl.NEAREST)
+	if desc.Linear {
+		filter = gl.LINEAR
+	}
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, filter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, filter)
+	if desc.ClampToEdge {
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return Texture(id)
+}
+
+// UpdateTexture replaces all pixels of a texture created with desc
+func (d *GL) UpdateTexture(texture Texture, desc TextureDesc, pixels []byte) {
+	if len(pixels) < desc.Width*desc.Height*desc.Format.BytesPerPixel() {
+		return
+	}
+	gl.BindTexture(gl.TEXTURE_2D, uint32(texture))
+	format := uint32(gl.RGBA)
+	if desc.Format == FormatR8 {
+		format = gl.RED
+		gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+		defer gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	}
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(desc.Width), int32(desc.Height), format, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// BindTexture binds a texture to a texture unit, 0 to unbind
+func (d *GL) BindTexture(unit int, texture Texture) {
+	gl.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+	gl.BindTexture(gl.TEXTURE_2D, uint32(texture))
+}
+
+// DeleteTexture frees a texture
+func (d *GL) DeleteTexture(texture Texture) {
+	id := uint32(texture)
+	gl.DeleteTextures(1, &id)
+}
+
+// CreateProgram compiles and links a shader program from vertex and fragment sources
+func (d *GL) CreateProgram(vertexSource, fragmentSource string) (Program, error) {
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compile vertex shader: %w", err)
+	}
+	defer gl.DeleteShader(vertexShader)
+
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compile fragment shader: %w", err)
+	}
+	defer gl.DeleteShader(fragmentShader)
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vertexShader)
+	gl.AttachShader(program, fragmentShader)
+	gl.LinkProgram(program)
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+		gl.DeleteProgram(program)
+		return 0, fmt.Errorf("program linking failed: %v", log)
+	}
+	return Program(program), nil
+}
+
+// compileShader compiles a single shader stage from source
+func compileShader(source string, shaderType uint32) (uint32, error) {
+	shader := gl.CreateShader(shaderType)
+	csource, free := gl.Strs(source + "\x00")
+	defer free()
+	gl.ShaderSource(shader, 1, csource, nil)
+	gl.CompileShader(shader)
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+		gl.DeleteShader(shader)
+		return 0, fmt.Errorf("shader compilation failed: %v", log)
+	}
+	return shader, nil
+}
+
+// UseProgram makes a program current
+func (d *GL) UseProgram(program Program) {
+	gl.UseProgram(uint32(program))
+}
+
+// UniformLocation returns the location of a uniform, -1 if the program has none by that name
+func (d *GL) UniformLocation(program Program, name string) int32 {
+	return gl.GetUniformLocation(uint32(program), gl.Str(name+"\x00"))
+}
+
+// SetUniformMat4 sets a mat4 uniform of the current program
+func (d *GL) SetUniformMat4(location int32, value mgl32.Mat4) {
+	gl.UniformMatrix4fv(location, 1, false, &value[0])
+}
+
+// SetUniformVec4 sets a vec4 uniform of the current program
+func (d *GL) SetUniformVec4(location int32, value mgl32.Vec4) {
+	gl.Uniform4fv(location, 1, &value[0])
+}
+
+// SetUniformVec3 sets a vec3 uniform of the current program
+func (d *GL) SetUniformVec3(location int32, value mgl32.Vec3) {
+	gl.Uniform3fv(location, 1, &value[0])
+}
+
+// SetUniformVec2 sets a vec2 uniform of the current program
+func (d *GL) SetUniformVec2(location int32, value mgl32.Vec2) {
+	gl.Uniform2fv(location, 1, &value[0])
+}
+
+// SetUniformFloat sets a float uniform of the current program
+func (d *GL) SetUniformFloat(location int32, value float32) {
+	gl.Uniform1f(location, value)
+}
+
+// SetUniformInt sets an int uniform of the current program
+func (d *GL) SetUniformInt(location int32, value int32) {
+	gl.Uniform1i(location, value)
+}
+
+// DeleteProgram frees a program
+func (d *GL) DeleteProgram(program Program) {
+	gl.DeleteProgram(uint32(program))
+}
+
+// State returns the current render state, read back from OpenGL since
+// code outside the device changes it too
+func (d *GL) State() RenderState {
+	var polygonMode [2]int32
+	gl.GetIntegerv(gl.POLYGON_MODE, &polygonMode[0])
+	var depthFunc, stencilFunc int32
+	gl.GetIntegerv(gl.DEPTH_FUNC, &depthFunc)
+	gl.GetIntegerv(gl.STENCIL_FUNC, &stencilFunc)
+	var depthMask bool
+	var colorMask [4]bool
+	gl.GetBooleanv(gl.DEPTH_WRITEMASK, &depthMask)
+	gl.GetBooleanv(gl.COLOR_WRITEMASK, &colorMask[0])
+
+	stencil := StencilOff
+	if gl.IsEnabled(gl.STENCIL_TEST) {
+		stencil = StencilMark
+		if stencilFunc == gl.NOTEQUAL {
+			stencil = StencilUnmarked
+		}
+	}
+	return RenderState{
+		DepthTest:     gl.IsEnabled(gl.DEPTH_TEST),
+		DepthGreater:  depthFunc == gl.GREATER,
+		DepthReadOnly: !depthMask,
+		CullFace:      gl.IsEnabled(gl.CULL_FACE),
+		AlphaBlend:    gl.IsEnabled(gl.BLEND),
+		Wireframe:     polygonMode[0] == gl.LINE,
+		ColorOff:      !colorMask[0],
+		Stencil:       stencil,
+	}
+}
+
+// SetState changes the render state
+func (d *GL) SetState(state RenderState) {
+	setEnabled(gl.DEPTH_TEST, state.DepthTest)
+	setEnabled(gl.CULL_FACE, state.CullFace)
+	setEnabled(gl.BLEND, state.AlphaBlend)
+	if state.AlphaBlend {
+		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	}
+	if state.Wireframe {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	} else {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	}
+	if state.DepthGreater {
+		gl.DepthFunc(gl.GREATER)
+	} else {
+		gl.DepthFunc(gl.LEQUAL)
+	}
+	gl.DepthMask(!state.DepthReadOnly)
+	gl.ColorMask(!state.ColorOff, !state.ColorOff, !state.ColorOff, !state.ColorOff)
+
+	setEnabled(gl.STENCIL_TEST, state.Stencil != StencilOff)
+	switch state.Stencil {
+	case StencilMark:
+		gl.StencilFunc(gl.ALWAYS, 1, 0xFF)
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+	case StencilUnmarked:
+		gl.StencilFunc(gl.NOTEQUAL, 1, 0xFF)
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+	}
+}
+
+// setEnabled enables or disables an OpenGL capability
+func setEnabled(capability uint32, enabled bool) {
+	if enabled {
+		gl.Enable(capability)
+	} else {
+		gl.Disable(capability)
+	}
+}
+
+// Draw draws count vertices of a vertex array from first
+func (d *GL) Draw(vao VertexArray, primitive Primitive, first, count int) {
+	gl.BindVertexArray(uint32(vao))
+	gl.DrawArrays(glPrimitive(primitive), int32(first), int32(count))
+	gl.BindVertexArray(0)
+}
+
+// DrawInstanced draws count vertices of an instanced vertex array from first, once per instance
+func (d *GL) DrawInstanced(vao VertexArray, primitive Primitive, first, count, instances int) {
+	gl.BindVertexArray(uint32(vao))
+	gl.DrawArraysInstanced(glPrimitive(primitive), int32(first), int32(count), int32(instances))
+	gl.BindVertexArray(0)
+}
+
+// glUsage converts a buffer usage hint
+func glUsage(usage BufferUsage) uint32 {
+	switch usage {
+	case UsageDynamic:
+		return gl.DYNAMIC_DRAW
+	case UsageStream:
+		return gl.STREAM_DRAW
+	}
+	return gl.STATIC_DRAW
+}
+
+// glPrimitive converts a primitive type
+func glPrimitive(primitive Primitive) uint32 {
+	if primitive == Lines {
+		return gl.LINES
+	}
+	return gl.TRIANGLES
+}
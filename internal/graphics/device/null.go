@@ -0,0 +1,246 @@
+package device
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// DrawCall is a draw recorded by the null backend
+type DrawCall struct {
+	Program   Program
+	Textures  [4]Texture // Bound to units 0-3
+	State     RenderState
+	Primitive Primitive
+	First     int
+	Count     int
+	Instances int // Instances drawn, 0 for a draw that is not instanced
+}
+
+// Null is a backend without a GPU: it hands out handles, keeps the data it
+// is given and records draw calls, so render code can be tested headless
+type Null struct {
+	next     uint32
+	buffers  map[Buffer][]float32
+	arrays   map[VertexArray]VertexLayout
+	textures map[Texture]TextureDesc
+	uploads  map[Texture]int              // Texture -> times its pixels were replaced
+	programs map[Program]map[string]int32 // Uniform names the sources declare -> location
+	uniforms map[Program]map[int32]interface{}
+
+	program Program
+	bound   [4]Texture
+	state   RenderState
+	draws   []DrawCall
+}
+
+// NewNull creates a null backend, with depth testing and culling on like a fresh 3D frame
+func NewNull() *Null {
+	return &Null{
+		buffers:  make(map[Buffer][]float32),
+		arrays:   make(map[VertexArray]VertexLayout),
+		textures: make(map[Texture]TextureDesc),
+		uploads:  make(map[Texture]int),
+		programs: make(map[Program]map[string]int32),
+		uniforms: make(map[Program]map[int32]interface{}),
+		state:    RenderState{DepthTest: true, CullFace: true},
+	}
+}
+
+// handle returns a new resource handle
+func (d *Null) handle() uint32 {
+	d.next++
+	return d.next
+}
+
+// Name identifies the backend in logs
+func (d *Null) Name() string {
+	return "null"
+}
+
+// CreateVertexArray creates a vertex array reading the layout from a new vertex buffer
+func (d *Null) CreateVertexArray(layout VertexLayout) (VertexArray, Buffer) {
+	vao, buffer := VertexArray(d.handle()), Buffer(d.handle())
+	d.arrays[vao] = layout
+	d.buffers[buffer] = nil
+	return vao, buffer
+}
+
+// CreateInstancedVertexArray creates a vertex array with a vertex buffer and an instance buffer
+func (d *Null) CreateInstancedVertexArray(layout, instanceLayout VertexLayout) (VertexArray, Buffer, Buffer) {
+	vao, buffer := d.CreateVertexArray(layout)
+	instances := Buffer(d.handle())
+	d.buffers[instances] = nil
+	return vao, buffer, instances
+}
+
+// UploadVertices replaces the contents of a vertex buffer
+func (d *Null) UploadVertices(buffer Buffer, vertices []float32, usage BufferUsage) {
+	d.buffers[buffer] = append(d.buffers[buffer][:0], vertices...)
+}
+
+// DeleteVertexArray frees a vertex array
+func (d *Null) DeleteVertexArray(vao VertexArray) {
+	delete(d.arrays, vao)
+}
+
+// DeleteBuffer frees a buffer
+func (d *Null) DeleteBuffer(buffer Buffer) {
+	delete(d.buffers, buffer)
+}
+
+// CreateTexture creates a 2D texture
+func (d *Null) CreateTexture(desc TextureDesc, pixels []byte) Texture {
+	texture := Texture(d.handle())
+	d.textures[texture] = desc
+	return texture
+}
+
+// UpdateTexture counts the update of a live texture given enough pixels
+func (d *Null) UpdateTexture(texture Texture, desc TextureDesc, pixels []byte) {
+	if _, ok := d.textures[texture]; ok && len(pixels) >= desc.Width*desc.Height*desc.Format.BytesPerPixel() {
+		d.uploads[texture]++
+	}
+}
+
+// BindTexture binds a texture to a texture unit, 0 to unbind
+func (d *Null) BindTexture(unit int, texture Texture) {
+	if unit >= 0 && unit < len(d.bound) {
+		d.bound[unit] = texture
+	}
+}
+
+// DeleteTexture frees a texture
+func (d *Null) DeleteTexture(texture Texture) {
+	delete(d.textures, texture)
+	delete(d.uploads, texture)
+}
+
+// CreateProgram creates a program with the uniforms declared in its sources;
+// sources without a main function fail to compile
+func (d *Null) CreateProgram(vertexSource, fragmentSource string) (Program, error) {
+	for stage, source := range map[string]string{"vertex": vertexSource, "fragment": fragmentSource} {
+		if !strings.Contains(source, "main") {
+			return 0, fmt.Errorf("%s shader has no main function", stage)
+		}
+	}
+	program := Program(d.handle())
+	locations := make(map[string]int32)
+	for _, source := range []string{vertexSource, fragmentSource} {
+		for _, line := range strings.Split(source, "\n") {
+			fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(line), ";"))
+			if len(fields) == 3 && fields[0] == "uniform" {
+				if _, declared := locations[fields[2]]; !declared {
+					locations[fields[2]] = int32(len(locations))
+				}
+			}
+		}
+	}
+	d.programs[program] = locations
+	d.uniforms[program] = make(map[int32]interface{})
+	return program, nil
+}
+
+// UseProgram makes a program current
+func (d *Null) UseProgram(program Program) {
+	d.program = program
+}
+
+// UniformLocation returns the location of a uniform, -1 if the program has none by that name
+func (d *Null) UniformLocation(program Program, name string) int32 {
+	if location, declared := d.programs[program][name]; declared {
+		return location
+	}
+	return -1
+}
+
+// setUniform stores a uniform value of the current program
+func (d *Null) setUniform(location int32, value interface{}) {
+	if values := d.uniforms[d.program]; values != nil && location >= 0 {
+		values[location] = value
+	}
+}
+
+// SetUniformMat4 sets a mat4 uniform of the current program
+func (d *Null) SetUniformMat4(location int32, value mgl32.Mat4) { d.setUniform(location, value) }
+
+// SetUniformVec4 sets a vec4 uniform of the current program
+func (d *Null) SetUniformVec4(location int32, value mgl32.Vec4) { d.setUniform(location, value) }
+
+// SetUniformVec3 sets a vec3 uniform of the current program
+func (d *Null) SetUniformVec3(location int32, value mgl32.Vec3) { d.setUniform(location, value) }
+
+// SetUniformVec2 sets a vec2 uniform of the current program
+func (d *Null) SetUniformVec2(location int32, value mgl32.Vec2) { d.setUniform(location, value) }
+
+// SetUniformFloat sets a float uniform of the current program
+func (d *Null) SetUniformFloat(location int32, value float32) { d.setUniform(location, value) }
+
+// SetUniformInt sets an int uniform of the current program
+func (d *Null) SetUniformInt(location int32, value int32) { d.setUniform(location, value) }
+
+// DeleteProgram frees a program
+func (d *Null) DeleteProgram(program Program) {
+	delete(d.programs, program)
+	delete(d.uniforms, program)
+}
+
+// State returns the current render state
+func (d *Null) State() RenderState {
+	return d.state
+}
+
+// SetState changes the render state
+func (d *Null) SetState(state RenderState) {
+	d.state = state
+}
+
+// Draw records a draw call
+func (d *Null) Draw(vao VertexArray, primitive Primitive, first, count int) {
+	d.draws = append(d.draws, DrawCall{
+		Program: d.program, Textures: d.bound, State: d.state, Primitive: primitive, First: first, Count: count,
+	})
+}
+
+// DrawInstanced records an instanced draw call
+func (d *Null) DrawInstanced(vao VertexArray, primitive Primitive, first, count, instances int) {
+	d.draws = append(d.draws, DrawCall{
+		Program: d.program, Textures: d.bound, State: d.state, Primitive: primitive, First: first, Count: count,
+		Instances: instances,
+	})
+}
+
+// Draws returns the draw calls recorded since the last Reset
+func (d *Null) Draws() []DrawCall {
+	return d.draws
+}
+
+// Reset forgets the recorded draw calls, as at the start of a frame
+func (d *Null) Reset() {
+	d.draws = d.draws[:0]
+}
+
+// Uniform returns the value last set for a uniform of a program, nil if none was
+func (d *Null) Uniform(program Program, name string) interface{} {
+	location, declared := d.programs[program][name]
+	if !declared {
+		return nil
+	}
+	return d.uniforms[program][location]
+}
+
+// TextureUpdates returns how many times a texture's pixels were replaced
+func (d *Null) TextureUpdates(texture Texture) int {
+	return d.uploads[texture]
+}
+
+// Vertices returns the contents of a vertex buffer
+func (d *Null) Vertices(buffer Buffer) []float32 {
+	return d.buffers[buffer]
+}
+
+// Resources returns how many buffers, textures and programs are alive, to find leaks
+func (d *Null) Resources() (buffers, textures, programs int) {
+	return len(d.buffers), len(d.textures), len(d.programs)
+}
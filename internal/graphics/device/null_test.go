@@ -0,0 +1,140 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	testVertexShader = `#version 330 core
+layout (location = 0) in vec2 aPosition;
+uniform vec2 uScreenSize;
+void main() { gl_Position = vec4(aPosition / uScreenSize, 0.0, 1.0); }`
+	testFragmentShader = `#version 330 core
+uniform sampler2D uTexture;
+out vec4 FragColor;
+void main() { FragColor = texture(uTexture, vec2(0.0)); }`
+)
+
+// TestNullDeviceDraws tests that the null backend records draws with the
+// program, textures and state they ran with
+func TestNullDeviceDraws(t *testing.T) {
+	var dev GraphicsDevice = NewNull()
+	null := dev.(*Null)
+
+	program, err := dev.CreateProgram(testVertexShader, testFragmentShader)
+	if err != nil {
+		t.Fatalf("Failed to create program: %v", err)
+	}
+	texture := dev.CreateTexture(TextureDesc{Width: 2, Height: 2}, make([]byte, 16))
+	vao, vbo := dev.CreateVertexArray(VertexLayout{2, 2, 4})
+
+	vertices := make([]float32, 6*VertexLayout{2, 2, 4}.Stride())
+	dev.UseProgram(program)
+	dev.SetUniformVec2(dev.UniformLocation(program, "uScreenSize"), mgl32.Vec2{800, 600})
+	previous := dev.State()
+	dev.SetState(OverlayState)
+	dev.BindTexture(0, texture)
+	dev.UploadVertices(vbo, vertices, UsageStream)
+	dev.Draw(vao, Triangles, 0, 6)
+	dev.SetState(previous)
+
+	draws := null.Draws()
+	if len(draws) != 1 {
+		t.Fatalf("Expected 1 draw call, got %d", len(draws))
+	}
+	draw := draws[0]
+	if draw.Program != program || draw.Textures[0] != texture || draw.Count != 6 || draw.State != OverlayState {
+		t.Errorf("Expected 6 overlay vertices with the program and texture, got %+v", draw)
+	}
+	if got := null.Uniform(program, "uScreenSize"); got != (mgl32.Vec2{800, 600}) {
+		t.Errorf("Expected the screen size uniform to be recorded, got %v", got)
+	}
+	if dev.UniformLocation(program, "uMissing") != -1 {
+		t.Error("Expected -1 for a uniform the program does not declare")
+	}
+	if len(null.Vertices(vbo)) != len(vertices) {
+		t.Errorf("Expected %d uploaded floats, got %d", len(vertices), len(null.Vertices(vbo)))
+	}
+	if dev.State() != previous || !previous.DepthTest {
+		t.Errorf("Expected the 3D state to be restored, got %+v", dev.State())
+	}
+
+	null.Reset()
+	if len(null.Draws()) != 0 {
+		t.Error("Expected no draw calls after a reset")
+	}
+}
+
+// TestNullDeviceResources tests resource bookkeeping and shader failures
+func TestNullDeviceResources(t *testing.T) {
+	null := NewNull()
+	if _, err := null.CreateProgram("", testFragmentShader); err == nil {
+		t.Error("Expected a vertex shader without main to fail")
+	}
+
+	program, _ := null.CreateProgram(testVertexShader, testFragmentShader)
+	texture := null.CreateTexture(TextureDesc{Width: 1, Height: 1, Format: FormatR8}, nil)
+	vao, vbo := null.CreateVertexArray(VertexLayout{3, 4})
+	if buffers, textures, programs := null.Resources(); buffers != 1 || textures != 1 || programs != 1 {
+		t.Errorf("Expected 1 buffer, texture and program, got %d, %d and %d", buffers, textures, programs)
+	}
+
+	null.DeleteProgram(program)
+	null.DeleteTexture(texture)
+	null.DeleteBuffer(vbo)
+	null.DeleteVertexArray(vao)
+	if buffers, textures, programs := null.Resources(); buffers+textures+programs != 0 {
+		t.Errorf("Expected every resource freed, got %d buffers, %d textures and %d programs", buffers, textures, programs)
+	}
+}
+
+// TestVertexLayout tests layout strides and pixel sizes
+func TestVertexLayout(t *testing.T) {
+	if stride := (VertexLayout{3, 4}).Stride(); stride != 7 {
+		t.Errorf("Expected a stride of 7 floats, got %d", stride)
+	}
+	if FormatR8.BytesPerPixel() != 1 || FormatRGBA8.BytesPerPixel() != 4 {
+		t.Error("Expected 1 byte R8 pixels and 4 byte RGBA8 pixels")
+	}
+}
+
+// TestNullDeviceInstancedDraw tests that instanced vertex arrays get their
+// own instance buffer and instanced draws record their instance count
+func TestNullDeviceInstancedDraw(t *testing.T) {
+	null := NewNull()
+	vao, vbo, instances := null.CreateInstancedVertexArray(VertexLayout{3}, VertexLayout{4})
+	if vbo == instances {
+		t.Fatal("Expected separate vertex and instance buffers")
+	}
+	null.UploadVertices(instances, make([]float32, 5*4), UsageStream)
+	null.DrawInstanced(vao, Triangles, 0, 18, 5)
+	if draws := null.Draws(); len(draws) != 1 || draws[0].Count != 18 || draws[0].Instances != 5 {
+		t.Errorf("Expected 5 instances of 18 vertices, got %+v", draws)
+	}
+	null.DeleteBuffer(vbo)
+	null.DeleteBuffer(instances)
+	null.DeleteVertexArray(vao)
+	if buffers, _, _ := null.Resources(); buffers != 0 {
+		t.Errorf("Expected every buffer freed, got %d", buffers)
+	}
+}
+
+// TestNullDeviceTextureUpdates tests that texture updates are counted only
+// for live textures given a full image
+func TestNullDeviceTextureUpdates(t *testing.T) {
+	null := NewNull()
+	desc := TextureDesc{Width: 4, Height: 2}
+	texture := null.CreateTexture(desc, nil)
+	null.UpdateTexture(texture, desc, make([]byte, 4*2*4))
+	null.UpdateTexture(texture, desc, make([]byte, 4))
+	if updates := null.TextureUpdates(texture); updates != 1 {
+		t.Errorf("Expected 1 update of the full image, got %d", updates)
+	}
+	null.DeleteTexture(texture)
+	null.UpdateTexture(texture, desc, make([]byte, 4*2*4))
+	if updates := null.TextureUpdates(texture); updates != 0 {
+		t.Errorf("Expected no updates of a deleted texture, got %d", updates)
+	}
+}
@@ -0,0 +1,459 @@
+//go:build js && wasm
+
+package device
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// WebGL constants used by the backend, from the WebGL 2 specification
+const (
+	webglTriangles        = 0x0004
+	webglLines            = 0x0001
+	webglArrayBuffer      = 0x8892
+	webglStaticDraw       = 0x88E4
+	webglStreamDraw       = 0x88E0
+	webglDynamicDraw      = 0x88E8
+	webglFloat            = 0x1406
+	webglUnsignedByte     = 0x1401
+	webglTexture2D        = 0x0DE1
+	webglTexture0         = 0x84C0
+	webglTextureMinFilter = 0x2801
+	webglTextureMagFilter = 0x2800
+	webglTextureWrapS     = 0x2802
+	webglTextureWrapT     = 0x2803
+	webglNearest          = 0x2600
+	webglLinear           = 0x2601
+	webglClampToEdge      = 0x812F
+	webglRGBA             = 0x1908
+	webglRGBA8            = 0x8058
+	webglRed              = 0x1903
+	webglR8               = 0x8229
+	webglUnpackAlignment  = 0x0CF5
+	webglVertexShader     = 0x8B31
+	webglFragmentShader   = 0x8B30
+	webglCompileStatus    = 0x8B81
+	webglLinkStatus       = 0x8B82
+	webglDepthTest        = 0x0B71
+	webglCullFace         = 0x0B44
+	webglBlend            = 0x0BE2
+	webglSrcAlpha         = 0x0302
+	webglOneMinusSrcAlpha = 0x0303
+	webglColorBufferBit   = 0x4000
+	webglDepthBufferBit   = 0x0100
+	webglStencilBufferBit = 0x0400
+	webglStencilTest      = 0x0B90
+	webglLessEqual        = 0x0203
+	webglGreater          = 0x0204
+	webglNotEqual         = 0x0205
+	webglAlways           = 0x0207
+	webglKeep             = 0x1E00
+	webglReplace          = 0x1E01
+)
+
+// WebGL is the WebGL 2 backend for browser builds. Desktop GLSL 330 shaders
+// are rewritten to GLSL ES 300, so the renderer's shaders run unchanged.
+type WebGL struct {
+	gl js.Value
+
+	next     uint32
+	objects  map[uint32]js.Value // Handle -> WebGL object
+	programs map[Program]map[string]int32
+	uniforms []js.Value // Location -> WebGLUniformLocation
+
+	wireframe bool        // WebGL has no polygon mode; kept so State round-trips
+	state     RenderState // Last state set, for the parts State cannot cheaply query
+}
+
+// NewWebGL creates the WebGL 2 backend drawing to a canvas element
+func NewWebGL(canvas js.Value) (*WebGL, error) {
+	context := canvas.Call("getContext", "webgl2", map[string]interface{}{"antialias": true, "stencil": true})
+	if context.IsNull() || context.IsUndefined() {
+		return nil, fmt.Errorf("browser does not support WebGL 2")
+	}
+	return &WebGL{
+		gl:       context,
+		objects:  make(map[uint32]js.Value),
+		programs: make(map[Program]map[string]int32),
+	}, nil
+}
+
+// Name identifies the backend in logs
+func (d *WebGL) Name() string {
+	return "webgl2"
+}
+
+// Viewport sets the drawable area, in canvas pixels
+func (d *WebGL) Viewport(width, height int) {
+	d.gl.Call("viewport", 0, 0, width, height)
+}
+
+// Clear clears the color, depth and stencil buffers
+func (d *WebGL) Clear(r, g, b float32) {
+	d.gl.Call("clearColor", r, g, b, 1)
+	d.gl.Call("clear", webglColorBufferBit|webglDepthBufferBit|webglStencilBufferBit)
+}
+
+// store keeps a WebGL object and returns its handle
+func (d *WebGL) store(object js.Value) uint32 {
+	d.next++
+	d.objects[d.next] = object
+	return d.next
+}
+
+// object returns the WebGL object of a handle, null for 0 or a freed handle
+func (d *WebGL) object(handle uint32) js.Value {
+	if object, exists := d.objects[handle]; exists {
+		return object
+	}
+	return js.Null()
+}
+
+// release frees a handle, returning its WebGL object
+func (d *WebGL) release(handle uint32) js.Value {
+	object := d.object(handle)
+	delete(d.objects, handle)
+	return object
+}
+
+// CreateVertexArray creates a vertex array reading the layout from a new vertex buffer
+func (d *WebGL) CreateVertexArray(layout VertexLayout) (VertexArray, Buffer) {
+	vao := d.gl.Call("createVertexArray")
+	vbo := d.gl.Call("createBuffer")
+	d.gl.Call("bindVertexArray", vao)
+	d.gl.Call("bindBuffer", webglArrayBuffer, vbo)
+	stride := layout.Stride() * 4
+	offset := 0
+	for i, size := range layout {
+		d.gl.Call("vertexAttribPointer", i, size, webglFloat, false, stride, offset*4)
+		d.gl.Call("enableVertexAttribArray", i)
+		offset += size
+	}
+	d.gl.Call("bindVertexArray", nil)
+	return VertexArray(d.store(vao)), Buffer(d.store(vbo))
+}
+
+// CreateInstancedVertexArray creates a vertex array reading the layout per
+// vertex and the instance layout per instance, from two new buffers
+func (d *WebGL) CreateInstancedVertexArray(layout, instanceLayout VertexLayout) (VertexArray, Buffer, Buffer) {
+	vao, vbo := d.CreateVertexArray(layout)
+	instanceVBO := d.gl.Call("createBuffer")
+	d.gl.Call("bindVertexArray", d.object(uint32(vao)))
+	d.gl.Call("bindBuffer", webglArrayBuffer, instanceVBO)
+	stride := instanceLayout.Stride() * 4
+	offset := 0
+	for i, size := range instanceLayout {
+		location := len(layout) + i
+		d.gl.Call("vertexAttribPointer", location, size, webglFloat, false, stride, offset*4)
+		d.gl.Call("enableVertexAttribArray", location)
+		d.gl.Call("vertexAttribDivisor", location, 1)
+		offset += size
+	}
+	d.gl.Call("bindVertexArray", nil)
+	return vao, vbo, Buffer(d.store(instanceVBO))
+}
+
+// UploadVertices replaces the contents of a vertex buffer
+func (d *WebGL) UploadVertices(buffer Buffer, vertices []float32, usage BufferUsage) {
+	d.gl.Call("bindBuffer", webglArrayBuffer, d.object(uint32(buffer)))
+	d.gl.Call("bufferData", webglArrayBuffer, float32Array(vertices), webglUsage(usage))
+}
+
+// DeleteVertexArray frees a vertex array
+func (d *WebGL) DeleteVertexArray(vao VertexArray) {
+	d.gl.Call("deleteVertexArray", d.release(uint32(vao)))
+}
+
+// DeleteBuffer frees a buffer
+func (d *WebGL) DeleteBuffer(buffer Buffer) {
+	d.gl.Call("deleteBuffer", d.release(uint32(buffer)))
+}
+
+// CreateTexture creates a 2D texture; pixels may be nil to leave it uninitialized
+func (d *WebGL) CreateTexture(desc TextureDesc, pixels []byte) Texture {
+	texture := d.gl.Call("createTexture")
+	d.gl.Call("bindTexture", webglTexture2D, texture)
+
+	internalFormat, format := webglRGBA8, webglRGBA
+	if desc.Format == FormatR8 {
+		internalFormat, format = webglR8, webglRed
+		d.gl.Call("pixelStorei", webglUnpackAlignment, 1)
+		defer d.gl.Call("pixelStorei", webglUnpackAlignment, 4)
+	}
+	data := js.Null()
+	if len(pixels) > 0 {
+		data = uint8Array(pixels)
+	}
+	d.gl.Call("texImage2D", webglTexture2D, 0, internalFormat, desc.Width, desc.Height, 0, format, webglUnsignedByte, data)
+
+	filter := webglNearest
+	if desc.Linear {
+		filter = webglLinear
+	}
+	d.gl.Call("texParameteri", webglTexture2D, webglTextureMinFilter, filter)
+	d.gl.Call("texParameteri", webglTexture2D, webglTextureMagFilter, filter)
+	if desc.ClampToEdge {
+		d.gl.Call("texParameteri", webglTexture2D, webglTextureWrapS, webglClampToEdge)
+		d.gl.Call("texParameteri", webglTexture2D, webglTextureWrapT, webglClampToEdge)
+	}
+	d.gl.Call("bindTexture", webglTexture2D, nil)
+	return Texture(d.store(texture))
+}
+
+// UpdateTexture replaces all pixels of a texture created with desc
+func (d *WebGL) UpdateTexture(texture Texture, desc TextureDesc, pixels []byte) {
+	if len(pixels) < desc.Width*desc.Height*desc.Format.BytesPerPixel() {
+		return
+	}
+	d.gl.Call("bindTexture", webglTexture2D, d.object(uint32(texture)))
+	format := webglRGBA
+	if desc.Format == FormatR8 {
+		format = webglRed
+		d.gl.Call("pixelStorei", webglUnpackAlignment, 1)
+		defer d.gl.Call("pixelStorei", webglUnpackAlignment, 4)
+	}
+	d.gl.Call("texSubImage2D", webglTexture2D, 0, 0, 0, desc.Width, desc.Height, format, webglUnsignedByte, uint8Array(pixels))
+	d.gl.Call("bindTexture", webglTexture2D, nil)
+}
+
+// BindTexture binds a texture to a texture unit, 0 to unbind
+func (d *WebGL) BindTexture(unit int, texture Texture) {
+	d.gl.Call("activeTexture", webglTexture0+unit)
+	d.gl.Call("bindTexture", webglTexture2D, d.object(uint32(texture)))
+}
+
+// DeleteTexture frees a texture
+func (d *WebGL) DeleteTexture(texture Texture) {
+	d.gl.Call("deleteTexture", d.release(uint32(texture)))
+}
+
+// CreateProgram compiles and links a shader program from vertex and fragment sources
+func (d *WebGL) CreateProgram(vertexSource, fragmentSource string) (Program, error) {
+	vertexShader, err := d.compileShader(esSource(vertexSource, false), webglVertexShader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compile vertex shader: %w", err)
+	}
+	defer d.gl.Call("deleteShader", vertexShader)
+
+	fragmentShader, err := d.compileShader(esSource(fragmentSource, true), webglFragmentShader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compile fragment shader: %w", err)
+	}
+	defer d.gl.Call("deleteShader", fragmentShader)
+
+	program := d.gl.Call("createProgram")
+	d.gl.Call("attachShader", program, vertexShader)
+	d.gl.Call("attachShader", program, fragmentShader)
+	d.gl.Call("linkProgram", program)
+	if !d.gl.Call("getProgramParameter", program, webglLinkStatus).Bool() {
+		log := d.gl.Call("getProgramInfoLog", program).String()
+		d.gl.Call("deleteProgram", program)
+		return 0, fmt.Errorf("program linking failed: %v", log)
+	}
+
+	handle := Program(d.store(program))
+	d.programs[handle] = make(map[string]int32)
+	return handle, nil
+}
+
+// compileShader compiles a single shader stage from source
+func (d *WebGL) compileShader(source string, shaderType int) (js.Value, error) {
+	shader := d.gl.Call("createShader", shaderType)
+	d.gl.Call("shaderSource", shader, source)
+	d.gl.Call("compileShader", shader)
+	if !d.gl.Call("getShaderParameter", shader, webglCompileStatus).Bool() {
+		log := d.gl.Call("getShaderInfoLog", shader).String()
+		d.gl.Call("deleteShader", shader)
+		return js.Null(), fmt.Errorf("shader compilation failed: %v", log)
+	}
+	return shader, nil
+}
+
+// esSource rewrites a desktop GLSL 330 shader as GLSL ES 300, which needs a
+// default float precision in fragment shaders
+func esSource(source string, fragment bool) string {
+	header := "#version 300 es\n"
+	if fragment {
+		header += "precision highp float;\n"
+	}
+	if strings.HasPrefix(strings.TrimSpace(source), "#version") {
+		source = strings.TrimSpace(source)
+		if newline := strings.Index(source, "\n"); newline >= 0 {
+			return header + source[newline+1:]
+		}
+		return header
+	}
+	return header + source
+}
+
+// UseProgram makes a program current
+func (d *WebGL) UseProgram(program Program) {
+	d.gl.Call("useProgram", d.object(uint32(program)))
+}
+
+// UniformLocation returns the location of a uniform, -1 if the program has none by that name
+func (d *WebGL) UniformLocation(program Program, name string) int32 {
+	locations, exists := d.programs[program]
+	if !exists {
+		return -1
+	}
+	if location, cached := locations[name]; cached {
+		return location
+	}
+	location := int32(-1)
+	if object := d.gl.Call("getUniformLocation", d.object(uint32(program)), name); !object.IsNull() {
+		location = int32(len(d.uniforms))
+		d.uniforms = append(d.uniforms, object)
+	}
+	locations[name] = location
+	return location
+}
+
+// uniform returns the WebGL object of a uniform location, null for -1
+func (d *WebGL) uniform(location int32) js.Value {
+	if location < 0 || int(location) >= len(d.uniforms) {
+		return js.Null()
+	}
+	return d.uniforms[location]
+}
+
+// SetUniformMat4 sets a mat4 uniform of the current program
+func (d *WebGL) SetUniformMat4(location int32, value mgl32.Mat4) {
+	d.gl.Call("uniformMatrix4fv", d.uniform(location), false, float32Array(value[:]))
+}
+
+// SetUniformVec4 sets a vec4 uniform of the current program
+func (d *WebGL) SetUniformVec4(location int32, value mgl32.Vec4) {
+	d.gl.Call("uniform4f", d.uniform(location), value[0], value[1], value[2], value[3])
+}
+
+// SetUniformVec3 sets a vec3 uniform of the current program
+func (d *WebGL) SetUniformVec3(location int32, value mgl32.Vec3) {
+	d.gl.Call("uniform3f", d.uniform(location), value[0], value[1], value[2])
+}
+
+// SetUniformVec2 sets a vec2 uniform of the current program
+func (d *WebGL) SetUniformVec2(location int32, value mgl32.Vec2) {
+	d.gl.Call("uniform2f", d.uniform(location), value[0], value[1])
+}
+
+// SetUniformFloat sets a float uniform of the current program
+func (d *WebGL) SetUniformFloat(location int32, value float32) {
+	d.gl.Call("uniform1f", d.uniform(location), value)
+}
+
+// SetUniformInt sets an int uniform of the current program
+func (d *WebGL) SetUniformInt(location int32, value int32) {
+	d.gl.Call("uniform1i", d.uniform(location), value)
+}
+
+// DeleteProgram frees a program. Its uniform locations stay allocated, since
+// programs are only deleted on shader reloads and shutdown.
+func (d *WebGL) DeleteProgram(program Program) {
+	delete(d.programs, program)
+	d.gl.Call("deleteProgram", d.release(uint32(program)))
+}
+
+// State returns the current render state
+func (d *WebGL) State() RenderState {
+	state := d.state
+	state.DepthTest = d.gl.Call("isEnabled", webglDepthTest).Bool()
+	state.CullFace = d.gl.Call("isEnabled", webglCullFace).Bool()
+	state.AlphaBlend = d.gl.Call("isEnabled", webglBlend).Bool()
+	state.Wireframe = d.wireframe
+	return state
+}
+
+// SetState changes the render state. Wireframe is ignored: WebGL can only
+// draw edges as line primitives.
+func (d *WebGL) SetState(state RenderState) {
+	d.setEnabled(webglDepthTest, state.DepthTest)
+	d.setEnabled(webglCullFace, state.CullFace)
+	d.setEnabled(webglBlend, state.AlphaBlend)
+	if state.AlphaBlend {
+		d.gl.Call("blendFunc", webglSrcAlpha, webglOneMinusSrcAlpha)
+	}
+	d.wireframe = state.Wireframe
+
+	depthFunc := webglLessEqual
+	if state.DepthGreater {
+		depthFunc = webglGreater
+	}
+	d.gl.Call("depthFunc", depthFunc)
+	d.gl.Call("depthMask", !state.DepthReadOnly)
+	d.gl.Call("colorMask", !state.ColorOff, !state.ColorOff, !state.ColorOff, !state.ColorOff)
+
+	d.setEnabled(webglStencilTest, state.Stencil != StencilOff)
+	switch state.Stencil {
+	case StencilMark:
+		d.gl.Call("stencilFunc", webglAlways, 1, 0xFF)
+		d.gl.Call("stencilOp", webglKeep, webglKeep, webglReplace)
+	case StencilUnmarked:
+		d.gl.Call("stencilFunc", webglNotEqual, 1, 0xFF)
+		d.gl.Call("stencilOp", webglKeep, webglKeep, webglKeep)
+	}
+	d.state = state
+}
+
+// setEnabled enables or disables a WebGL capability
+func (d *WebGL) setEnabled(capability int, enabled bool) {
+	if enabled {
+		d.gl.Call("enable", capability)
+	} else {
+		d.gl.Call("disable", capability)
+	}
+}
+
+// Draw draws count vertices of a vertex array from first
+func (d *WebGL) Draw(vao VertexArray, primitive Primitive, first, count int) {
+	mode := webglTriangles
+	if primitive == Lines {
+		mode = webglLines
+	}
+	d.gl.Call("bindVertexArray", d.object(uint32(vao)))
+	d.gl.Call("drawArrays", mode, first, count)
+	d.gl.Call("bindVertexArray", nil)
+}
+
+// DrawInstanced draws count vertices of an instanced vertex array from first, once per instance
+func (d *WebGL) DrawInstanced(vao VertexArray, primitive Primitive, first, count, instances int) {
+	mode := webglTriangles
+	if primitive == Lines {
+		mode = webglLines
+	}
+	d.gl.Call("bindVertexArray", d.object(uint32(vao)))
+	d.gl.Call("drawArraysInstanced", mode, first, count, instances)
+	d.gl.Call("bindVertexArray", nil)
+}
+
+// webglUsage converts a buffer usage hint
+func webglUsage(usage BufferUsage) int {
+	switch usage {
+	case UsageDynamic:
+		return webglDynamicDraw
+	case UsageStream:
+		return webglStreamDraw
+	}
+	return webglStaticDraw
+}
+
+// uint8Array copies bytes into a new JavaScript Uint8Array
+func uint8Array(data []byte) js.Value {
+	array := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(array, data)
+	return array
+}
+
+// float32Array copies floats into a new JavaScript Float32Array
+func float32Array(data []float32) js.Value {
+	if len(data) == 0 {
+		return js.Global().Get("Float32Array").New(0)
+	}
+	bytes := unsafe.Slice((*byte)(unsafe.Pointer(&data[0])), len(data)*4)
+	return js.Global().Get("Float32Array").New(uint8Array(bytes).Get("buffer"))
+}
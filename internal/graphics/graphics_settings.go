@@ -0,0 +1,230 @@
+//go:build !js
+
+package graphics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Quality is the quality level of a post-processing effect
+type Quality int
+
+const (
+	QualityOff Quality = iota
+	QualityLow
+	QualityMedium
+	QualityHigh
+)
+
+var qualityNames = []string{"off", "low", "medium", "high"}
+
+// String returns the name of a quality level
+func (q Quality) String() string {
+	if q < QualityOff || q > QualityHigh {
+		return fmt.Sprintf("quality(%d)", int(q))
+	}
+	return qualityNames[q]
+}
+
+// ParseQuality parses a quality level name
+func ParseQuality(name string) (Quality, error) {
+	for i, qualityName := range qualityNames {
+		if name == qualityName {
+			return Quality(i), nil
+		}
+	}
+	return QualityOff, fmt.Errorf("unknown quality %q, expected off, low, medium or high", name)
+}
+
+// MarshalText stores quality levels by name in settings files
+func (q Quality) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText reads a quality level name
+func (q *Quality) UnmarshalText(text []byte) error {
+	parsed, err := ParseQuality(string(text))
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// Next returns the following quality level, wrapping from high to off
+func (q Quality) Next() Quality {
+	return (q + 1) % (QualityHigh + 1)
+}
+
+// BloomParams are the bloom settings of a quality level
+type BloomParams struct {
+	Downscale  int // Divisor of the screen size for the blur buffers
+	Iterations int // Separable blur passes; more gives a wider, smoother glow
+}
+
+// Bloom returns the bloom parameters of a quality level
+func (q Quality) Bloom() BloomParams {
+	switch q {
+	case QualityLow:
+		return BloomParams{Downscale: 4, Iterations: 2}
+	case QualityMedium:
+		return BloomParams{Downscale: 2, Iterations: 4}
+	case QualityHigh:
+		return BloomParams{Downscale: 2, Iterations: 8}
+	}
+	return BloomParams{}
+}
+
+// FXAAParams are the FXAA settings of a quality level
+type FXAAParams struct {
+	SpanMax   float32 // Longest edge blur, in pixels
+	ReduceMul float32 // Damping of the blur direction; smaller keeps more detail
+}
+
+// FXAA returns the FXAA parameters of a quality level
+func (q Quality) FXAA() FXAAParams {
+	switch q {
+	case QualityLow:
+		return FXAAParams{SpanMax: 4, ReduceMul: 1.0 / 4}
+	case QualityMedium:
+		return FXAAParams{SpanMax: 8, ReduceMul: 1.0 / 8}
+	case QualityHigh:
+		return FXAAParams{SpanMax: 16, ReduceMul: 1.0 / 16}
+	}
+	return FXAAParams{}
+}
+
+// DetailParams are the grass and detail mesh settings of a quality level
+type DetailParams struct {
+	Density  float32 // Share of the tileset's detail objects grown
+	Distance float32 // Distance from the camera focus where detail has faded out, in world units
+}
+
+// Detail returns the grass and detail mesh parameters of a quality level
+func (q Quality) Detail() DetailParams {
+	switch q {
+	case QualityLow:
+		return DetailParams{Density: 0.35, Distance: 24}
+	case QualityMedium:
+		return DetailParams{Density: 0.65, Distance: 40}
+	case QualityHigh:
+		return DetailParams{Density: 1, Distance: 64}
+	}
+	return DetailParams{}
+}
+
+// GraphicsSettings are the user's rendering preferences
+type GraphicsSettings struct {
+	// Post-processing renders the scene to an off-screen HDR buffer first
+	PostProcessing bool    `json:"post_processing"`
+	FXAA           Quality `json:"fxaa"`
+	Bloom          Quality `json:"bloom"`
+	BloomThreshold float32 `json:"bloom_threshold"` // Brightness above which pixels glow; emissive materials exceed 1
+	BloomIntensity float32 `json:"bloom_intensity"`
+
+	// Color grading maps the final colors through a 3D lookup table; low
+	// quality samples the nearest entry, higher qualities interpolate
+	ColorGrading    Quality `json:"color_grading"`
+	ColorGradingLUT string  `json:"color_grading_lut"` // .cube file, relative to the settings file; empty for no change
+
+	// GPU memory textures and models may use before the least recently used
+	// are evicted, in megabytes; 0 for no limit
+	TextureBudgetMB int `json:"texture_budget_mb"`
+
+	// Grass and small detail meshes on grass surfaces; higher qualities grow
+	// more of them and draw them further from the camera
+	Detail Quality `json:"detail"`
+
+	// Pick units and buildings by what is drawn under the cursor, from an
+	// offscreen ID buffer, rather than by their distance to the ground point
+	GPUPicking bool `json:"gpu_picking"`
+
+	path string
+}
+
+// DefaultGraphicsSettings returns the settings used without a settings file
+func DefaultGraphicsSettings() *GraphicsSettings {
+	return &GraphicsSettings{
+		PostProcessing:  true,
+		FXAA:            QualityMedium,
+		Bloom:           QualityMedium,
+		BloomThreshold:  1.0,
+		BloomIntensity:  0.6,
+		ColorGrading:    QualityOff,
+		TextureBudgetMB: 512,
+		Detail:          QualityMedium,
+		GPUPicking:      true,
+	}
+}
+
+// LoadGraphicsSettings reads settings from a file; a missing file gives the
+// defaults, which Save then writes to path
+func LoadGraphicsSettings(path string) (*GraphicsSettings, error) {
+	settings := DefaultGraphicsSettings()
+	settings.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("failed to read graphics settings: %w", err)
+	}
+	if err := json.Unmarshal(data, settings); err != nil {
+		settings = DefaultGraphicsSettings()
+		settings.path = path
+		return settings, fmt.Errorf("failed to parse graphics settings %s: %w", path, err)
+	}
+	settings.validate()
+	return settings, nil
+}
+
+// Save writes the settings to the file they were loaded from
+func (gs *GraphicsSettings) Save() error {
+	if gs.path == "" {
+		return fmt.Errorf("graphics settings have no file")
+	}
+	gs.validate()
+	data, err := json.MarshalIndent(gs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode graphics settings: %w", err)
+	}
+	if err := os.WriteFile(gs.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write graphics settings: %w", err)
+	}
+	return nil
+}
+
+// LUTPath returns the color grading LUT file, resolved against the settings file, or "" for none
+func (gs *GraphicsSettings) LUTPath() string {
+	if gs.ColorGradingLUT == "" || filepath.IsAbs(gs.ColorGradingLUT) || gs.path == "" {
+		return gs.ColorGradingLUT
+	}
+	return filepath.Join(filepath.Dir(gs.path), gs.ColorGradingLUT)
+}
+
+// validate clamps values into their valid ranges
+func (gs *GraphicsSettings) validate() {
+	for _, quality := range []*Quality{&gs.FXAA, &gs.Bloom, &gs.ColorGrading, &gs.Detail} {
+		if *quality < QualityOff || *quality > QualityHigh {
+			*quality = QualityOff
+		}
+	}
+	if gs.BloomThreshold <= 0 {
+		gs.BloomThreshold = 1.0
+	}
+	gs.BloomIntensity = mgl32.Clamp(gs.BloomIntensity, 0, 4)
+	if gs.TextureBudgetMB < 0 {
+		gs.TextureBudgetMB = 0
+	}
+}
+
+// TextureBudget returns the GPU memory budget in bytes, 0 for no limit
+func (gs *GraphicsSettings) TextureBudget() int64 {
+	return int64(gs.TextureBudgetMB) << 20
+}
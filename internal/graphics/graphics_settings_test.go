@@ -0,0 +1,105 @@
+//go:build !js
+
+package graphics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestQuality tests quality level names and cycling
+func TestQuality(t *testing.T) {
+	for _, quality := range []Quality{QualityOff, QualityLow, QualityMedium, QualityHigh} {
+		parsed, err := ParseQuality(quality.String())
+		if err != nil || parsed != quality {
+			t.Errorf("Expected %s to parse back, got %v (%v)", quality, parsed, err)
+		}
+	}
+	if _, err := ParseQuality("ultra"); err == nil {
+		t.Error("Expected an unknown quality name to fail")
+	}
+	if QualityHigh.Next() != QualityOff || QualityOff.Next() != QualityLow {
+		t.Error("Expected quality levels to cycle from high back to off")
+	}
+	if QualityOff.Bloom().Iterations != 0 || QualityHigh.Bloom().Iterations <= QualityLow.Bloom().Iterations {
+		t.Error("Expected bloom to be off at quality off and widen with quality")
+	}
+	if QualityOff.FXAA().SpanMax != 0 || QualityHigh.FXAA().SpanMax <= QualityLow.FXAA().SpanMax {
+		t.Error("Expected FXAA to be off at quality off and search further with quality")
+	}
+	if QualityOff.Detail().Distance != 0 || QualityHigh.Detail().Density <= QualityLow.Detail().Density {
+		t.Error("Expected detail to be off at quality off and grow denser with quality")
+	}
+}
+
+// TestGraphicsSettingsSaveLoad tests the settings file round trip
+func TestGraphicsSettingsSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graphics_settings.json")
+
+	settings, err := LoadGraphicsSettings(path)
+	if err != nil {
+		t.Fatalf("Expected a missing file to give defaults, got %v", err)
+	}
+	if *settings != *withPath(DefaultGraphicsSettings(), path) {
+		t.Errorf("Expected default settings, got %+v", settings)
+	}
+
+	settings.FXAA = QualityHigh
+	settings.Bloom = QualityOff
+	settings.ColorGrading = QualityLow
+	settings.ColorGradingLUT = "luts/warm.cube"
+	settings.Detail = QualityHigh
+	if err := settings.Save(); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+
+	loaded, err := LoadGraphicsSettings(path)
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	if *loaded != *settings {
+		t.Errorf("Expected %+v after reloading, got %+v", settings, loaded)
+	}
+	if want := filepath.Join(filepath.Dir(path), "luts", "warm.cube"); loaded.LUTPath() != want {
+		t.Errorf("Expected the LUT relative to the settings file at %s, got %s", want, loaded.LUTPath())
+	}
+}
+
+// TestGraphicsSettingsValidation tests clamping and invalid files
+func TestGraphicsSettingsValidation(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "clamped.json")
+	os.WriteFile(path, []byte(`{"bloom_threshold": -1, "bloom_intensity": 10, "texture_budget_mb": -5}`), 0644)
+	settings, err := LoadGraphicsSettings(path)
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	if settings.BloomThreshold != 1.0 || settings.BloomIntensity != 4 {
+		t.Errorf("Expected threshold 1 and intensity 4 after clamping, got %v and %v", settings.BloomThreshold, settings.BloomIntensity)
+	}
+	if settings.TextureBudget() != 0 {
+		t.Errorf("Expected a negative texture budget to mean no limit, got %d bytes", settings.TextureBudget())
+	}
+
+	path = filepath.Join(dir, "invalid.json")
+	os.WriteFile(path, []byte(`{"fxaa": "ultra"}`), 0644)
+	settings, err = LoadGraphicsSettings(path)
+	if err == nil {
+		t.Error("Expected an unknown quality to fail")
+	}
+	if settings.FXAA != DefaultGraphicsSettings().FXAA {
+		t.Error("Expected defaults when the file cannot be parsed")
+	}
+
+	if err := DefaultGraphicsSettings().Save(); err == nil {
+		t.Error("Expected saving settings without a file to fail")
+	}
+}
+
+// withPath sets the file of settings for comparisons
+func withPath(settings *GraphicsSettings, path string) *GraphicsSettings {
+	settings.path = path
+	return settings
+}
@@ -0,0 +1,124 @@
+// Package icons supports rendering portrait icons from models: framing a
+// model for the icon camera, turning GPU readbacks into images, and a disk
+// cache so each icon is rendered once per model version.
+package icons
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// DefaultSize is the width and height of generated icons in pixels
+const DefaultSize = 64
+
+// Icon camera angles, for a three-quarter view from the front and above
+const (
+	iconYaw   = math.Pi / 4 // Radians around the vertical axis
+	iconPitch = math.Pi / 6 // Radians above the horizon
+	iconFill  = 0.95        // Share of the icon the model's bounding sphere spans
+)
+
+// Framing returns the view and orthographic projection matrices that fit a
+// model's bounding box into a square icon
+func Framing(min, max mgl32.Vec3) (view, projection mgl32.Mat4) {
+	center := min.Add(max).Mul(0.5)
+	radius := max.Sub(min).Len() / 2
+	if radius <= 0 {
+		radius = 1
+	}
+	direction := mgl32.Vec3{
+		float32(math.Cos(iconPitch) * math.Sin(iconYaw)),
+		float32(math.Sin(iconPitch)),
+		float32(math.Cos(iconPitch) * math.Cos(iconYaw)),
+	}
+	eye := center.Add(direction.Mul(3 * radius))
+	view = mgl32.LookAtV(eye, center, mgl32.Vec3{0, 1, 0})
+	half := radius / iconFill
+	projection = mgl32.Ortho(-half, half, -half, half, radius, 5*radius)
+	return view, projection
+}
+
+// FromReadback converts RGBA pixels read back from the GPU, bottom row first,
+// into an image with the top row first
+func FromReadback(pixels []byte, width, height int) (*image.NRGBA, error) {
+	if len(pixels) != width*height*4 {
+		return nil, fmt.Errorf("expected %d bytes for a %dx%d readback, got %d", width*height*4, width, height, len(pixels))
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	stride := width * 4
+	for row := 0; row < height; row++ {
+		copy(img.Pix[row*img.Stride:row*img.Stride+stride], pixels[(height-1-row)*stride:(height-row)*stride])
+	}
+	return img, nil
+}
+
+// Cache stores rendered icons as PNG files in a directory
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a cache in a directory, created on the first Store
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Key identifies the icon of a model file at a size; it changes when the
+// file does, so edited models get new icons
+func (c *Cache) Key(modelPath string, size int) (string, error) {
+	info, err := os.Stat(modelPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat model %s: %w", modelPath, err)
+	}
+	hash := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d|%d", filepath.ToSlash(modelPath), info.Size(), info.ModTime().UnixNano(), size)))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// Load returns a cached icon, or false if there is none
+func (c *Cache) Load(key string) (image.Image, bool) {
+	file, err := os.Open(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, false
+	}
+	return img, true
+}
+
+// Store saves an icon under a key
+func (c *Cache) Store(key string, img image.Image) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create icon cache: %w", err)
+	}
+	// Write to a temporary file first so a crash never leaves a truncated icon
+	tmp := c.path(key) + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create icon: %w", err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to encode icon: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write icon: %w", err)
+	}
+	return os.Rename(tmp, c.path(key))
+}
+
+// path returns the file of an icon
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".png")
+}
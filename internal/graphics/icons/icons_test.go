@@ -0,0 +1,89 @@
+package icons
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TestFraming tests that a model's bounding box fits inside the icon
+func TestFraming(t *testing.T) {
+	min, max := mgl32.Vec3{-1, 0, -2}, mgl32.Vec3{3, 5, 2}
+	view, projection := Framing(min, max)
+	viewProjection := projection.Mul4(view)
+	for i := 0; i < 8; i++ {
+		corner := mgl32.Vec3{min.X(), min.Y(), min.Z()}
+		if i&1 != 0 {
+			corner[0] = max.X()
+		}
+		if i&2 != 0 {
+			corner[1] = max.Y()
+		}
+		if i&4 != 0 {
+			corner[2] = max.Z()
+		}
+		clip := viewProjection.Mul4x1(corner.Vec4(1))
+		for axis, value := range clip.Vec3() {
+			if value < -1 || value > 1 {
+				t.Errorf("Corner %v is outside the icon on axis %d: %v", corner, axis, clip)
+			}
+		}
+	}
+}
+
+// TestFromReadback tests flipping GPU rows into image order
+func TestFromReadback(t *testing.T) {
+	// Two rows of one pixel: the bottom red, the top blue
+	img, err := FromReadback([]byte{255, 0, 0, 255, 0, 0, 255, 255}, 1, 2)
+	if err != nil {
+		t.Fatalf("FromReadback failed: %v", err)
+	}
+	if top := img.NRGBAAt(0, 0); top != (color.NRGBA{B: 255, A: 255}) {
+		t.Errorf("Expected the top pixel blue, got %v", top)
+	}
+	if _, err := FromReadback([]byte{1, 2, 3}, 1, 1); err == nil {
+		t.Error("Expected a short readback to be rejected")
+	}
+}
+
+// TestCache tests storing icons and invalidating them when models change
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "worker.g3d")
+	if err := os.WriteFile(model, []byte("g3d"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCache(filepath.Join(dir, "icons"))
+
+	key, err := cache.Key(model, DefaultSize)
+	if err != nil {
+		t.Fatalf("Key failed: %v", err)
+	}
+	if _, ok := cache.Load(key); ok {
+		t.Fatal("Expected an empty cache")
+	}
+	img, _ := FromReadback(make([]byte, 4*4*4), 4, 4)
+	if err := cache.Store(key, img); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if cached, ok := cache.Load(key); !ok || cached.Bounds().Dx() != 4 {
+		t.Errorf("Expected the stored icon back, got %v", cached)
+	}
+
+	// Another size, or an edited model, is another icon
+	if other, _ := cache.Key(model, 2*DefaultSize); other == key {
+		t.Error("Expected icon sizes to have their own keys")
+	}
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(model, later, later)
+	if edited, _ := cache.Key(model, DefaultSize); edited == key {
+		t.Error("Expected an edited model to get a new key")
+	}
+	if _, err := cache.Key(filepath.Join(dir, "missing.g3d"), DefaultSize); err == nil {
+		t.Error("Expected a missing model to fail")
+	}
+}
@@ -0,0 +1,160 @@
+//go:build !js
+
+package graphics
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Instance vertex attribute locations; the model matrix takes four consecutive locations
+const (
+	instanceTransformLocation = 3
+	instanceTeamColorLocation = 7
+	instanceFloats            = 16 + 3 // Model matrix and team color
+)
+
+// Instance is the per-instance data of an instanced draw
+type Instance struct {
+	Transform mgl32.Mat4 // Model matrix
+	TeamColor mgl32.Vec3 // Owner color for custom color meshes
+}
+
+// InstanceKey identifies the instances that can share one draw call
+type InstanceKey struct {
+	Model *Model
+	Frame int // Animation frame
+}
+
+// InstanceBatch is a group of instances drawn with one instanced draw call
+type InstanceBatch struct {
+	Key       InstanceKey
+	Instances []Instance
+}
+
+// InstanceBatcher groups instances by model and animation frame. It is reset
+// and refilled each frame; batch storage is reused between frames.
+type InstanceBatcher struct {
+	batches map[InstanceKey]*InstanceBatch
+	order   []*InstanceBatch // Batches in the order their first instance was added
+}
+
+// NewInstanceBatcher creates an empty batcher
+func NewInstanceBatcher() *InstanceBatcher {
+	return &InstanceBatcher{batches: make(map[InstanceKey]*InstanceBatch)}
+}
+
+// Add queues an instance of a model at an animation frame
+func (ib *InstanceBatcher) Add(model *Model, frame int, instance Instance) {
+	key := InstanceKey{Model: model, Frame: frame}
+	batch, exists := ib.batches[key]
+	if !exists {
+		batch = &InstanceBatch{Key: key}
+		ib.batches[key] = batch
+	}
+	if len(batch.Instances) == 0 {
+		ib.order = append(ib.order, batch)
+	}
+	batch.Instances = append(batch.Instances, instance)
+}
+
+// Batches returns the non-empty batches in the order they were first added to
+func (ib *InstanceBatcher) Batches() []*InstanceBatch {
+	return ib.order
+}
+
+// InstanceCount returns the number of queued instances
+func (ib *InstanceBatcher) InstanceCount() int {
+	count := 0
+	for _, batch := range ib.order {
+		count += len(batch.Instances)
+	}
+	return count
+}
+
+// Reset empties the batches, keeping their storage for the next frame;
+// batches of models that were not drawn last frame are dropped
+func (ib *InstanceBatcher) Reset() {
+	for key, batch := range ib.batches {
+		if len(batch.Instances) == 0 {
+			delete(ib.batches, key)
+			continue
+		}
+		batch.Instances = batch.Instances[:0]
+	}
+	ib.order = ib.order[:0]
+}
+
+// packInstances writes the instance attributes into an interleaved float buffer
+func packInstances(instances []Instance, buffer []float32) []float32 {
+	buffer = buffer[:0]
+	for _, instance := range instances {
+		buffer = append(buffer, instance.Transform[:]...)
+		buffer = append(buffer, instance.TeamColor[:]...)
+	}
+	return buffer
+}
+
+// RenderInstanced draws the model once per instance with a single draw call.
+// The shader reads the model matrix and team color from instance attributes;
+// view, projection and lighting uniforms must already be set.
+func (m *Model) RenderInstanced(shaderName string, shaderInterface ShaderInterface, instances []Instance) error {
+	if m.VAO == 0 {
+		return fmt.Errorf("model VAO not initialized")
+	}
+	if len(instances) == 0 {
+		return nil
+	}
+
+	if err := m.bindMaterial(shaderName, shaderInterface); err != nil {
+		return err
+	}
+	m.applyInstanceUniforms(shaderName, shaderInterface)
+	return m.DrawInstances(instances)
+}
+
+// DrawInstances draws the model's geometry once per instance without binding
+// its material, for passes that only need shapes, such as silhouettes. The
+// active shader reads the same instance attributes as for RenderInstanced.
+func (m *Model) DrawInstances(instances []Instance) error {
+	if m.VAO == 0 {
+		return fmt.Errorf("model VAO not initialized")
+	}
+	if len(instances) == 0 {
+		return nil
+	}
+
+	gl.BindVertexArray(m.VAO)
+	if m.instanceVBO == 0 {
+		m.setupInstanceAttributes()
+	}
+	m.instanceData = packInstances(instances, m.instanceData)
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.instanceVBO)
+	gl.BufferData(gl.ARRAY_BUFFER, len(m.instanceData)*4, gl.Ptr(m.instanceData), gl.STREAM_DRAW)
+
+	gl.DrawElementsInstanced(gl.TRIANGLES, m.IndexCount, gl.UNSIGNED_INT, gl.PtrOffset(0), int32(len(instances)))
+	gl.BindVertexArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	return nil
+}
+
+// setupInstanceAttributes creates the instance buffer and attaches it to the
+// model's VAO, advancing once per instance (VAO must be bound)
+func (m *Model) setupInstanceAttributes() {
+	gl.GenBuffers(1, &m.instanceVBO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.instanceVBO)
+
+	stride := int32(instanceFloats * 4)
+	for column := uint32(0); column < 4; column++ {
+		location := instanceTransformLocation + column
+		gl.EnableVertexAttribArray(location)
+		gl.VertexAttribPointer(location, 4, gl.FLOAT, false, stride, gl.PtrOffset(int(column)*4*4))
+		gl.VertexAttribDivisor(location, 1)
+	}
+	gl.EnableVertexAttribArray(instanceTeamColorLocation)
+	gl.VertexAttribPointer(instanceTeamColorLocation, 3, gl.FLOAT, false, stride, gl.PtrOffset(16*4))
+	gl.VertexAttribDivisor(instanceTeamColorLocation, 1)
+}
@@ -0,0 +1,70 @@
+//go:build !js
+
+package graphics
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TestInstanceBatcher tests grouping instances by model and animation frame
+func TestInstanceBatcher(t *testing.T) {
+	worker, soldier := &Model{Name: "worker"}, &Model{Name: "soldier"}
+	batcher := NewInstanceBatcher()
+
+	batcher.Add(worker, 0, Instance{Transform: mgl32.Translate3D(1, 0, 0), TeamColor: TeamColor(1)})
+	batcher.Add(soldier, 0, Instance{Transform: mgl32.Translate3D(2, 0, 0), TeamColor: TeamColor(2)})
+	batcher.Add(worker, 0, Instance{Transform: mgl32.Translate3D(3, 0, 0), TeamColor: TeamColor(2)})
+	batcher.Add(worker, 1, Instance{Transform: mgl32.Translate3D(4, 0, 0), TeamColor: TeamColor(1)})
+
+	batches := batcher.Batches()
+	if len(batches) != 3 {
+		t.Fatalf("Expected 3 batches, got %d", len(batches))
+	}
+	if batches[0].Key != (InstanceKey{Model: worker, Frame: 0}) || len(batches[0].Instances) != 2 {
+		t.Errorf("Expected the first batch to hold both frame 0 workers, got %v with %d instances", batches[0].Key, len(batches[0].Instances))
+	}
+	if batches[1].Key.Model != soldier || batches[2].Key.Frame != 1 {
+		t.Error("Expected batches in the order they were first added")
+	}
+	if batcher.InstanceCount() != 4 {
+		t.Errorf("Expected 4 instances, got %d", batcher.InstanceCount())
+	}
+
+	// The next frame starts empty and drops batches left unused
+	batcher.Reset()
+	if len(batcher.Batches()) != 0 || batcher.InstanceCount() != 0 {
+		t.Error("Expected no batches after reset")
+	}
+	batcher.Add(soldier, 0, Instance{})
+	batcher.Reset()
+	if len(batcher.batches) != 1 {
+		t.Errorf("Expected only the batch used last frame to be kept, got %d", len(batcher.batches))
+	}
+}
+
+// TestPackInstances tests the interleaved instance attribute layout
+func TestPackInstances(t *testing.T) {
+	instances := []Instance{
+		{Transform: mgl32.Translate3D(1, 2, 3), TeamColor: mgl32.Vec3{0.1, 0.2, 0.3}},
+		{Transform: mgl32.Ident4(), TeamColor: mgl32.Vec3{1, 1, 1}},
+	}
+	buffer := packInstances(instances, nil)
+	if len(buffer) != 2*instanceFloats {
+		t.Fatalf("Expected %d floats, got %d", 2*instanceFloats, len(buffer))
+	}
+	// Column-major: the translation is in the fourth column
+	if buffer[12] != 1 || buffer[13] != 2 || buffer[14] != 3 {
+		t.Errorf("Expected translation at floats 12-14, got %v", buffer[12:15])
+	}
+	if buffer[16] != 0.1 || buffer[instanceFloats+16] != 1 {
+		t.Errorf("Expected team colors after each matrix, got %v and %v", buffer[16], buffer[instanceFloats+16])
+	}
+
+	// The buffer is reused
+	again := packInstances(instances[:1], buffer)
+	if len(again) != instanceFloats || &again[0] != &buffer[0] {
+		t.Error("Expected the buffer storage to be reused")
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !js
+
 package graphics
 
 import "github.com/go-gl/mathgl/mgl32"
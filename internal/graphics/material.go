@@ -1,3 +1,5 @@
+//go:build !js
+
 package graphics
 
 import (
@@ -67,6 +69,10 @@ type AdvancedMaterial struct {
 	CastShadows    bool        // Whether this material casts shadows
 	ReceiveShadows bool        // Whether this material receives shadows
 	Transparent    bool        // Whether this material needs alpha blending
+
+	// Team color
+	TeamColorMask  bool        // Diffuse texture alpha masks the team colored regions
+	TeamColor      mgl32.Vec3  // Color shown in the masked regions
 }
 
 // MaterialManager manages advanced materials and their shaders
@@ -307,6 +313,10 @@ func (mat *AdvancedMaterial) ApplyMaterial(shaderInterface ShaderInterface, shad
 		}
 	}
 
+	// Team color mask
+	shaderInterface.SetUniformBool(shaderName, "uUseTeamColor", mat.TeamColorMask)
+	shaderInterface.SetUniformVec3(shaderName, "uTeamColor", mat.TeamColor)
+
 	// Texture enabled flags
 	shaderInterface.SetUniformBool(shaderName, "uUseDiffuseTexture", mat.HasTexture(DiffuseTexture))
 	shaderInterface.SetUniformBool(shaderName, "uUseNormalTexture", mat.HasTexture(NormalTexture))
@@ -386,6 +396,8 @@ func (mm *MaterialManager) CloneMaterial(originalName, newName string) *Advanced
 		CastShadows:    original.CastShadows,
 		ReceiveShadows: original.ReceiveShadows,
 		Transparent:    original.Transparent,
+		TeamColorMask:  original.TeamColorMask,
+		TeamColor:      original.TeamColor,
 	}
 
 	// Copy textures
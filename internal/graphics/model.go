@@ -1,3 +1,5 @@
+//go:build !js
+
 package graphics
 
 import (
@@ -32,6 +34,14 @@ type Model struct {
 	// Material properties from G3D
 	Material        Material
 	AdvancedMaterial *AdvancedMaterial  // Enhanced material system
+
+	// Per-instance appearance, set before rendering
+	TeamColor     mgl32.Vec3     // Owner color shown in the masked regions of custom color meshes
+	TextureRegion TextureRegion  // Region of a texture atlas the texture coordinates map into; zero means the whole texture
+
+	// Instanced rendering
+	instanceVBO  uint32    // Per-instance attributes, created on the first instanced draw
+	instanceData []float32 // Packed instance attributes, reused between draws
 }
 
 // Vertex represents a single vertex with all attributes
@@ -47,6 +57,19 @@ type Material struct {
 	SpecularColor mgl32.Vec3 // RGB specular color
 	SpecularPower float32    // Shininess/specular power
 	Opacity       float32    // Alpha/opacity value
+	CustomColor   bool       // Texture alpha masks the regions drawn in the team color
+	Glow          bool       // Self-illuminated, e.g. magic effects; bright enough to bloom
+}
+
+// GlowEmission scales the diffuse color of glow meshes into the HDR range picked up by bloom
+const GlowEmission = 1.5
+
+// Emission returns the self-illumination of the material
+func (mat Material) Emission() mgl32.Vec3 {
+	if !mat.Glow {
+		return mgl32.Vec3{}
+	}
+	return mat.DiffuseColor.Mul(GlowEmission)
 }
 
 // BoundingBox represents an axis-aligned bounding box
@@ -85,6 +108,7 @@ func NewModelFromG3D(g3dModel *formats.G3DModel) (*Model, error) {
 		CurrentFrame: 0,
 		Transform:    mgl32.Ident4(),
 		Material:     extractMaterial(mesh),
+		TeamColor:    NeutralTeamColor,
 	}
 
 	// Generate OpenGL objects
@@ -204,6 +228,8 @@ func extractMaterial(mesh *formats.G3DMesh) Material {
 		SpecularColor: mgl32.Vec3{header.SpecularColor[0], header.SpecularColor[1], header.SpecularColor[2]},
 		SpecularPower: header.SpecularPower,
 		Opacity:       header.Opacity,
+		CustomColor:   mesh.CustomColor,
+		Glow:          mesh.Glow,
 	}
 }
 
@@ -309,7 +335,7 @@ func (m *Model) GetEffectiveMaterial() *AdvancedMaterial {
 		Name:           m.Name + "_material",
 		DiffuseColor:   m.Material.DiffuseColor,
 		SpecularColor:  m.Material.SpecularColor,
-		EmissiveColor:  mgl32.Vec3{0, 0, 0},
+		EmissiveColor:  m.Material.Emission(),
 		Shininess:      m.Material.SpecularPower,
 		Metallic:       0.0,
 		Roughness:      1.0 - (m.Material.SpecularPower / 128.0),
@@ -325,6 +351,11 @@ func (m *Model) GetEffectiveMaterial() *AdvancedMaterial {
 		CastShadows:    true,
 		ReceiveShadows: true,
 		Transparent:    m.Material.Opacity < 1.0,
+		TeamColorMask:  m.Material.CustomColor,
+		TeamColor:      m.TeamColor,
+	}
+	if !m.TextureRegion.IsZero() {
+		advMaterial.SetTextureTransform(m.TextureRegion.Scale, m.TextureRegion.Offset)
 	}
 
 	// Set texture if available
@@ -350,6 +381,10 @@ func (m *Model) Cleanup() {
 		gl.DeleteBuffers(1, &m.EBO)
 		m.EBO = 0
 	}
+	if m.instanceVBO != 0 {
+		gl.DeleteBuffers(1, &m.instanceVBO)
+		m.instanceVBO = 0
+	}
 }
 
 // Render renders the model using the provided shader
@@ -378,8 +413,25 @@ func (m *Model) Render(shaderName string, shaderInterface ShaderInterface) error
 		return fmt.Errorf("failed to set normal matrix: %w", err)
 	}
 
+	if err := m.bindMaterial(shaderName, shaderInterface); err != nil {
+		return err
+	}
+
+	// Team color and atlas region (ignored by shaders without them)
+	m.applyInstanceUniforms(shaderName, shaderInterface)
+
+	// Render the model
+	gl.BindVertexArray(m.VAO)
+	gl.DrawElements(gl.TRIANGLES, m.IndexCount, gl.UNSIGNED_INT, gl.PtrOffset(0))
+	gl.BindVertexArray(0)
+
+	return nil
+}
+
+// bindMaterial sets the material uniforms and binds the model's texture
+func (m *Model) bindMaterial(shaderName string, shaderInterface ShaderInterface) error {
 	// Set material properties (try advanced shader format first, then fallback to basic)
-	err = shaderInterface.SetUniformVec3(shaderName, "material.diffuse", m.Material.DiffuseColor)
+	err := shaderInterface.SetUniformVec3(shaderName, "material.diffuse", m.Material.DiffuseColor)
 	if err != nil {
 		// Fallback to basic shader material uniforms
 		err = shaderInterface.SetUniformVec3(shaderName, "uDiffuseColor", m.Material.DiffuseColor)
@@ -417,6 +469,9 @@ func (m *Model) Render(shaderName string, shaderInterface ShaderInterface) error
 		if err != nil {
 			return fmt.Errorf("failed to set material opacity: %w", err)
 		}
+
+		// Glow meshes emit their diffuse color; ignored by shaders without emission
+		shaderInterface.SetUniformVec3(shaderName, "material.emissive", m.Material.Emission())
 	}
 
 	// Bind texture if available
@@ -447,14 +502,21 @@ func (m *Model) Render(shaderName string, shaderInterface ShaderInterface) error
 		}
 	}
 
-	// Render the model
-	gl.BindVertexArray(m.VAO)
-	gl.DrawElements(gl.TRIANGLES, m.IndexCount, gl.UNSIGNED_INT, gl.PtrOffset(0))
-	gl.BindVertexArray(0)
-
 	return nil
 }
 
+// applyInstanceUniforms sets the team color mask and texture atlas region uniforms
+func (m *Model) applyInstanceUniforms(shaderName string, shaderInterface ShaderInterface) {
+	region := m.TextureRegion
+	if region.IsZero() {
+		region = FullTexture
+	}
+	shaderInterface.SetUniformBool(shaderName, "uUseTeamColor", m.Material.CustomColor)
+	shaderInterface.SetUniformVec3(shaderName, "uTeamColor", m.TeamColor)
+	shaderInterface.SetUniformVec2(shaderName, "uTextureScale", region.Scale)
+	shaderInterface.SetUniformVec2(shaderName, "uTextureOffset", region.Offset)
+}
+
 // RenderWithAdvancedMaterial renders the model using the advanced material system
 func (m *Model) RenderWithAdvancedMaterial(materialManager *MaterialManager, shaderInterface ShaderInterface) error {
 	if m.VAO == 0 {
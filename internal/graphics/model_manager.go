@@ -1,10 +1,12 @@
+//go:build !js
+
 package graphics
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
+	"teraglest/internal/logging"
 	"teraglest/pkg/formats"
 )
 
@@ -13,6 +15,14 @@ type ModelManager struct {
 	modelCache   map[string]*Model         // Path -> loaded model
 	textureManager *TextureManager       // Texture management
 	loadedModels []*Model                 // All loaded models for batch operations
+	modelTextures map[string]string       // Path -> texture path the model holds a reference to
+	residency     *ResidencyCache         // Shared GPU memory budget; nil keeps every model
+}
+
+// modelKey identifies a model of a manager in a residency cache
+type modelKey struct {
+	manager *ModelManager
+	path    string
 }
 
 // NewModelManager creates a new model manager
@@ -21,13 +31,34 @@ func NewModelManager() *ModelManager {
 		modelCache:     make(map[string]*Model),
 		textureManager: NewTextureManager(),
 		loadedModels:   make([]*Model, 0),
+		modelTextures:  make(map[string]string),
 	}
 }
 
+// SetResidency puts the manager's models and textures under a shared GPU
+// memory budget. Models unused for a frame may then be evicted, and are
+// loaded again on their next use.
+func (mm *ModelManager) SetResidency(residency *ResidencyCache) {
+	mm.residency = residency
+	mm.textureManager.SetResidency(residency)
+	for path, model := range mm.modelCache {
+		mm.track(path, model)
+	}
+}
+
+// track adds a cached model to the residency budget, unloading it when evicted
+func (mm *ModelManager) track(path string, model *Model) {
+	mm.residency.Add(modelKey{mm, path}, ResourceModel, ModelBytes(model), func() {
+		logging.Debugf(logging.CategoryRender, "Evicted model: %s", path)
+		mm.UnloadModel(path)
+	})
+}
+
 // LoadG3DModel loads a G3D model from a file path
 func (mm *ModelManager) LoadG3DModel(filePath string) (*Model, error) {
 	// Check cache first
 	if model, exists := mm.modelCache[filePath]; exists {
+		mm.residency.Touch(modelKey{mm, filePath})
 		return model, nil
 	}
 
@@ -46,15 +77,16 @@ func (mm *ModelManager) LoadG3DModel(filePath string) (*Model, error) {
 	// Load and assign texture
 	err = mm.loadModelTexture(model, g3dModel, filePath)
 	if err != nil {
-		log.Printf("Warning: failed to load texture for model %s: %v", filePath, err)
+		logging.Warnf(logging.CategoryRender, "Failed to load texture for model %s: %v", filePath, err)
 		// Continue without texture - model will use default white texture
 	}
 
 	// Cache the model
 	mm.modelCache[filePath] = model
 	mm.loadedModels = append(mm.loadedModels, model)
+	mm.track(filePath, model)
 
-	log.Printf("Loaded G3D model: %s (%d vertices, %d triangles)",
+	logging.Debugf(logging.CategoryRender, "Loaded G3D model: %s (%d vertices, %d triangles)",
 		filePath, model.GetVertexCount(), model.GetTriangleCount())
 
 	return model, nil
@@ -83,8 +115,10 @@ func (mm *ModelManager) loadModelTexture(model *Model, g3dModel *formats.G3DMode
 		return fmt.Errorf("failed to find texture: %w", err)
 	}
 
-	// Assign texture to model
+	// Assign texture to model, keeping it resident while the model is
 	model.TextureID = texture.ID
+	mm.textureManager.Acquire(texture.Path)
+	mm.modelTextures[modelPath] = texture.Path
 
 	return nil
 }
@@ -93,6 +127,7 @@ func (mm *ModelManager) loadModelTexture(model *Model, g3dModel *formats.G3DMode
 func (mm *ModelManager) LoadModelFromMemory(g3dModel *formats.G3DModel, cacheKey string) (*Model, error) {
 	// Check cache first
 	if model, exists := mm.modelCache[cacheKey]; exists {
+		mm.residency.Touch(modelKey{mm, cacheKey})
 		return model, nil
 	}
 
@@ -108,17 +143,24 @@ func (mm *ModelManager) LoadModelFromMemory(g3dModel *formats.G3DModel, cacheKey
 		return nil, fmt.Errorf("failed to create default texture: %w", err)
 	}
 	model.TextureID = defaultTexture.ID
+	mm.textureManager.Acquire(defaultTexture.Path)
+	mm.modelTextures[cacheKey] = defaultTexture.Path
 
 	// Cache the model
 	mm.modelCache[cacheKey] = model
 	mm.loadedModels = append(mm.loadedModels, model)
+	mm.track(cacheKey, model)
 
 	return model, nil
 }
 
 // GetModel returns a cached model by path
 func (mm *ModelManager) GetModel(filePath string) *Model {
-	return mm.modelCache[filePath]
+	model := mm.modelCache[filePath]
+	if model != nil {
+		mm.residency.Touch(modelKey{mm, filePath})
+	}
+	return model
 }
 
 // GetAllModels returns all loaded models
@@ -147,8 +189,13 @@ func (mm *ModelManager) UnloadModel(filePath string) {
 
 		// Remove from cache
 		delete(mm.modelCache, filePath)
+		mm.residency.Remove(modelKey{mm, filePath})
+		if texturePath, holds := mm.modelTextures[filePath]; holds {
+			mm.textureManager.Release(texturePath)
+			delete(mm.modelTextures, filePath)
+		}
 
-		log.Printf("Unloaded model: %s", filePath)
+		logging.Debugf(logging.CategoryRender, "Unloaded model: %s", filePath)
 	}
 }
 
@@ -186,7 +233,7 @@ func (mm *ModelManager) CreateTestScene() error {
 	mm.modelCache["test_cube"] = cubeModel
 	mm.loadedModels = append(mm.loadedModels, cubeModel)
 
-	log.Println("Created test scene with cube model")
+	logging.Infof(logging.CategoryRender, "Created test scene with cube model")
 	return nil
 }
 
@@ -289,7 +336,8 @@ func (mm *ModelManager) Cleanup() {
 	// Cleanup all models
 	for path, model := range mm.modelCache {
 		model.Cleanup()
-		log.Printf("Cleaned up model: %s", path)
+		mm.residency.Remove(modelKey{mm, path})
+		logging.Debugf(logging.CategoryRender, "Cleaned up model: %s", path)
 	}
 
 	// Cleanup texture manager
@@ -298,8 +346,9 @@ func (mm *ModelManager) Cleanup() {
 	// Clear caches
 	mm.modelCache = make(map[string]*Model)
 	mm.loadedModels = make([]*Model, 0)
+	mm.modelTextures = make(map[string]string)
 
-	log.Println("ModelManager cleanup completed")
+	logging.Infof(logging.CategoryRender, "ModelManager cleanup completed")
 }
 
 // LoadModelsFromDirectory loads all G3D models from a directory
@@ -311,7 +360,7 @@ func (mm *ModelManager) LoadModelsFromDirectory(dirPath string) ([]string, error
 	// For now, return empty slice as this requires filesystem scanning
 	// which should be implemented based on specific requirements
 
-	log.Printf("LoadModelsFromDirectory not fully implemented for: %s", dirPath)
+	logging.Infof(logging.CategoryRender, "LoadModelsFromDirectory not fully implemented for: %s", dirPath)
 	return loadedPaths, nil
 }
 
@@ -0,0 +1,228 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/device"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// commandMarkerShader is the shader program drawing command markers; it is
+// the debug shape shader, loaded under its own name
+const commandMarkerShader = "command_marker"
+
+// CommandMarkerKind is the order a command marker confirms
+type CommandMarkerKind int
+
+const (
+	CommandMarkerMove   CommandMarkerKind = iota // Green flag at a move destination
+	CommandMarkerAttack                          // Red ring closing on an attack target
+	CommandMarkerGather                          // Gold diamond over a resource to gather
+)
+
+// CommandMarkerLifetime is how long a command marker shows
+const CommandMarkerLifetime = 800 * time.Millisecond
+
+// Command marker shapes, in world units
+const (
+	commandMarkerSegments = 20   // Segments of a marker's ring
+	commandMarkerLift     = 0.05 // Height of rings above the ground, against z-fighting
+	flagPoleHeight        = 1.2
+	flagLength            = 0.5
+)
+
+// commandMarkerColors are the marker colors by kind
+var commandMarkerColors = map[CommandMarkerKind]mgl32.Vec4{
+	CommandMarkerMove:   {0.2, 1, 0.3, 1},
+	CommandMarkerAttack: {1, 0.2, 0.15, 1},
+	CommandMarkerGather: {1, 0.85, 0.2, 1},
+}
+
+// commandMarker is an order's marker and when it was shown
+type commandMarker struct {
+	kind     CommandMarkerKind
+	position engine.Vector3
+	shown    time.Time
+}
+
+// highlightRing is a ring around an object for one frame
+type highlightRing struct {
+	position engine.Vector3
+	radius   float32
+	color    mgl32.Vec4
+}
+
+// CommandMarkerRenderer draws brief animated markers where orders were
+// given, so the player sees each click was taken, and rings around
+// highlighted objects
+type CommandMarkerRenderer struct {
+	shaders *ShaderManager
+	device  device.GraphicsDevice
+	vao     device.VertexArray
+	vbo     device.Buffer
+
+	markers    []commandMarker // Markers shown, oldest first
+	highlights []highlightRing // Rings of the next frame
+	lines      []float32       // Line vertices of the current frame
+}
+
+// NewCommandMarkerRenderer loads the marker shader and creates the vertex buffer
+func NewCommandMarkerRenderer(shaders *ShaderManager) (*CommandMarkerRenderer, error) {
+	err := shaders.LoadShader(commandMarkerShader,
+		"internal/graphics/shaders/debug_draw.vert",
+		"internal/graphics/shaders/debug_draw.frag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load command marker shader: %w", err)
+	}
+
+	cr := &CommandMarkerRenderer{shaders: shaders, device: shaders.Device()}
+	cr.vao, cr.vbo = cr.device.CreateVertexArray(debugVertexLayout)
+	return cr, nil
+}
+
+// CommandMarkers returns the command marker renderer, nil if its shader failed to load
+func (r *Renderer) CommandMarkers() *CommandMarkerRenderer {
+	return r.commandMarkers
+}
+
+// Show shows a marker at a point from now on
+func (cr *CommandMarkerRenderer) Show(kind CommandMarkerKind, position engine.Vector3, now time.Time) {
+	if cr == nil {
+		return
+	}
+	cr.markers = append(cr.markers, commandMarker{kind: kind, position: position, shown: now})
+}
+
+// Highlight rings an object on the ground in the next frame only, e.g. the
+// one under the cursor
+func (cr *CommandMarkerRenderer) Highlight(position engine.Vector3, radius float32, color mgl32.Vec4) {
+	if cr == nil {
+		return
+	}
+	cr.highlights = append(cr.highlights, highlightRing{position: position, radius: radius, color: color})
+}
+
+// active drops the markers that have run out and returns the rest
+func (cr *CommandMarkerRenderer) active(now time.Time) []commandMarker {
+	kept := cr.markers[:0]
+	for _, marker := range cr.markers {
+		if now.Sub(marker.shown) < CommandMarkerLifetime {
+			kept = append(kept, marker)
+		}
+	}
+	cr.markers = kept
+	return kept
+}
+
+// Render draws the markers shown within their lifetime and the highlights
+// over the scene
+func (cr *CommandMarkerRenderer) Render(world *engine.World, camera *Camera, now time.Time) error {
+	if cr == nil {
+		return nil
+	}
+	markers := cr.active(now)
+	if len(markers) == 0 && len(cr.highlights) == 0 {
+		return nil
+	}
+
+	ground := func(p engine.Vector3) mgl32.Vec3 {
+		return mgl32.Vec3{float32(p.X), world.HeightAt(p) + commandMarkerLift, float32(p.Z)}
+	}
+	cr.lines = cr.lines[:0]
+	for _, marker := range markers {
+		age := float32(now.Sub(marker.shown)) / float32(CommandMarkerLifetime)
+		cr.addMarker(marker.kind, ground(marker.position), age)
+	}
+	for _, highlight := range cr.highlights {
+		cr.addRing(ground(highlight.position), highlight.radius, highlight.color)
+	}
+	cr.highlights = cr.highlights[:0]
+
+	if err := cr.shaders.UseShader(commandMarkerShader); err != nil {
+		return err
+	}
+	cr.shaders.SetUniformMat4(commandMarkerShader, "uView", camera.GetViewMatrix())
+	cr.shaders.SetUniformMat4(commandMarkerShader, "uProjection", camera.GetProjectionMatrix())
+
+	// Markers stay visible over units and buildings
+	previous := cr.device.State()
+	cr.device.SetState(device.OverlayState)
+	cr.device.UploadVertices(cr.vbo, cr.lines, device.UsageStream)
+	cr.device.Draw(cr.vao, device.Lines, 0, len(cr.lines)/debugVertexFloats)
+	cr.device.SetState(previous)
+	return nil
+}
+
+// addMarker adds the lines of a marker age of the way through its lifetime,
+// fading out as it ends
+func (cr *CommandMarkerRenderer) addMarker(kind CommandMarkerKind, ground mgl32.Vec3, age float32) {
+	color := commandMarkerColors[kind]
+	color[3] = 1 - age
+
+	switch kind {
+	case CommandMarkerMove:
+		// A flag waving on its pole over a ring spreading from it
+		top := ground.Add(mgl32.Vec3{0, flagPoleHeight, 0})
+		wave := float32(math.Sin(float64(age)*4*math.Pi)) * 0.1
+		tip := top.Add(mgl32.Vec3{flagLength, -0.15 + wave, 0})
+		low := top.Add(mgl32.Vec3{0, -0.3, 0})
+		cr.addLine(ground, top, color)
+		cr.addLine(top, tip, color)
+		cr.addLine(tip, low, color)
+		cr.addRing(ground, 0.2+0.4*age, color)
+	case CommandMarkerAttack:
+		// A ring closing on the target around a cross
+		cr.addRing(ground, 0.9-0.5*age, color)
+		size := float32(0.3)
+		cr.addLine(ground.Add(mgl32.Vec3{-size, 0, -size}), ground.Add(mgl32.Vec3{size, 0, size}), color)
+		cr.addLine(ground.Add(mgl32.Vec3{-size, 0, size}), ground.Add(mgl32.Vec3{size, 0, -size}), color)
+	case CommandMarkerGather:
+		// A diamond rising over a ring on the resource
+		center := ground.Add(mgl32.Vec3{0, 0.6 + 0.4*age, 0})
+		corners := []mgl32.Vec3{{0, 0.25, 0}, {0.18, 0, 0}, {0, -0.25, 0}, {-0.18, 0, 0}}
+		for i, corner := range corners {
+			cr.addLine(center.Add(corner), center.Add(corners[(i+1)%len(corners)]), color)
+		}
+		cr.addRing(ground, 0.5, color)
+	}
+}
+
+// addRing adds a circle on the ground around a point
+func (cr *CommandMarkerRenderer) addRing(center mgl32.Vec3, radius float32, color mgl32.Vec4) {
+	point := func(i int) mgl32.Vec3 {
+		angle := 2 * math.Pi * float64(i) / commandMarkerSegments
+		return center.Add(mgl32.Vec3{radius * float32(math.Cos(angle)), 0, radius * float32(math.Sin(angle))})
+	}
+	for i := 0; i < commandMarkerSegments; i++ {
+		cr.addLine(point(i), point(i+1), color)
+	}
+}
+
+// addLine adds a line segment
+func (cr *CommandMarkerRenderer) addLine(from, to mgl32.Vec3, color mgl32.Vec4) {
+	cr.lines = append(cr.lines, from[0], from[1], from[2], color[0], color[1], color[2], color[3])
+	cr.lines = append(cr.lines, to[0], to[1], to[2], color[0], color[1], color[2], color[3])
+}
+
+// Clear removes the markers and rings shown, e.g. when their match ends
+func (cr *CommandMarkerRenderer) Clear() {
+	if cr == nil {
+		return
+	}
+	cr.markers, cr.highlights = nil, nil
+}
+
+// Destroy frees the vertex buffer
+func (cr *CommandMarkerRenderer) Destroy() {
+	if cr == nil {
+		return
+	}
+	cr.device.DeleteBuffer(cr.vbo)
+	cr.device.DeleteVertexArray(cr.vao)
+}
@@ -0,0 +1,39 @@
+//go:build !js
+
+package renderer
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/engine"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TestCommandMarkers tests that markers show for their lifetime and fade out
+func TestCommandMarkers(t *testing.T) {
+	markers := &CommandMarkerRenderer{}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	markers.Show(CommandMarkerMove, engine.Vector3{X: 4, Z: 4}, start)
+	markers.Show(CommandMarkerAttack, engine.Vector3{X: 6, Z: 4}, start.Add(CommandMarkerLifetime/2))
+
+	if active := markers.active(start.Add(CommandMarkerLifetime / 4)); len(active) != 2 {
+		t.Fatalf("Expected both markers shown, got %d", len(active))
+	}
+	active := markers.active(start.Add(CommandMarkerLifetime))
+	if len(active) != 1 || active[0].kind != CommandMarkerAttack {
+		t.Fatalf("Expected only the attack marker left, got %+v", active)
+	}
+
+	markers.addMarker(CommandMarkerGather, mgl32.Vec3{}, 0.75)
+	if len(markers.lines) == 0 || len(markers.lines)%debugVertexFloats != 0 {
+		t.Fatalf("Expected whole line vertices, got %d floats", len(markers.lines))
+	}
+	if alpha := markers.lines[debugVertexFloats-1]; alpha != 0.25 {
+		t.Errorf("Expected the marker faded to a quarter, got %.2f", alpha)
+	}
+
+	var nilMarkers *CommandMarkerRenderer
+	nilMarkers.Show(CommandMarkerMove, engine.Vector3{}, start) // Must not panic without the renderer
+}
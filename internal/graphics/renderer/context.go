@@ -1,10 +1,13 @@
+//go:build !js
+
 package renderer
 
 import (
 	"fmt"
-	"log"
 	"runtime"
 
+	"teraglest/internal/logging"
+
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -34,8 +37,9 @@ func NewRenderContext(title string, width, height int, fullscreen bool) (*Render
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
 
-	// Enable depth buffer
+	// Enable depth buffer, and a stencil buffer for silhouettes of hidden units
 	glfw.WindowHint(glfw.DepthBits, 24)
+	glfw.WindowHint(glfw.StencilBits, 8)
 
 	// Enable multisampling for anti-aliasing
 	glfw.WindowHint(glfw.Samples, 4)
@@ -99,8 +103,8 @@ func NewRenderContext(title string, width, height int, fullscreen bool) (*Render
 	// Print OpenGL version info
 	version := gl.GoStr(gl.GetString(gl.VERSION))
 	renderer := gl.GoStr(gl.GetString(gl.RENDERER))
-	log.Printf("OpenGL Version: %s", version)
-	log.Printf("OpenGL Renderer: %s", renderer)
+	logging.Infof(logging.CategoryRender, "OpenGL Version: %s", version)
+	logging.Infof(logging.CategoryRender, "OpenGL Renderer: %s", renderer)
 
 	return rc, nil
 }
@@ -189,7 +193,7 @@ func (rc *RenderContext) SetCursorPosCallback(callback glfw.CursorPosCallback) {
 
 // Clear clears the screen
 func (rc *RenderContext) Clear() {
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
 }
 
 // EnableWireframe enables wireframe rendering mode
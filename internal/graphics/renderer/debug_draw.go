@@ -0,0 +1,209 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"teraglest/internal/debugdraw"
+	"teraglest/internal/graphics/device"
+	"teraglest/internal/logging"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// debugShader is the shader program drawing debug shapes
+const debugShader = "debug_draw"
+
+// Debug shape tessellation
+const (
+	debugVertexFloats   = 7    // Position and RGBA color, see debugVertexLayout
+	debugCircleSegments = 24   // Segments of each of a sphere's three circles
+	debugGridLift       = 0.05 // Height of grid overlays above the ground, against z-fighting
+	debugLabelSize      = 0.4  // Half size of the marker drawn at labels
+)
+
+// debugVertexLayout is a position and an RGBA color
+var debugVertexLayout = device.VertexLayout{3, 4}
+
+// DebugRenderer draws the shapes published to a debugdraw.Drawer
+type DebugRenderer struct {
+	shaders *ShaderManager
+	device  device.GraphicsDevice
+	source  *debugdraw.Drawer
+	vao     device.VertexArray
+	vbo     device.Buffer
+
+	lines     []float32 // Line vertices of the current frame
+	triangles []float32 // Grid cell vertices of the current frame
+	labels    string    // Labels last logged, to log only changes
+
+	text *TextRenderer // Draws label texts, nil to log them instead
+}
+
+// NewDebugRenderer loads the debug shader and creates the vertex buffer.
+// Label texts go through text, or to the log when it is nil.
+func NewDebugRenderer(shaders *ShaderManager, source *debugdraw.Drawer, text *TextRenderer) (*DebugRenderer, error) {
+	err := shaders.LoadShader(debugShader,
+		"internal/graphics/shaders/debug_draw.vert",
+		"internal/graphics/shaders/debug_draw.frag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load debug draw shader: %w", err)
+	}
+
+	dr := &DebugRenderer{shaders: shaders, device: shaders.Device(), source: source, text: text}
+	dr.vao, dr.vbo = dr.device.CreateVertexArray(debugVertexLayout)
+	return dr, nil
+}
+
+// Render draws the published shapes over the scene
+func (dr *DebugRenderer) Render(camera *Camera) error {
+	if dr == nil || !dr.source.Active() {
+		return nil
+	}
+	shapes := dr.source.Snapshot()
+	if shapes.Count() == 0 {
+		return nil
+	}
+
+	dr.lines, dr.triangles = dr.lines[:0], dr.triangles[:0]
+	for _, line := range shapes.Lines {
+		dr.addLine(line.From, line.To, line.Color)
+	}
+	for _, sphere := range shapes.Spheres {
+		dr.addSphere(sphere)
+	}
+	for _, grid := range shapes.Grids {
+		dr.addGrid(grid)
+	}
+	for _, label := range shapes.Labels {
+		dr.addLabelMarker(label)
+	}
+	if dr.text == nil {
+		dr.logLabels(shapes.Labels)
+	}
+
+	if err := dr.shaders.UseShader(debugShader); err != nil {
+		return err
+	}
+	dr.shaders.SetUniformMat4(debugShader, "uView", camera.GetViewMatrix())
+	dr.shaders.SetUniformMat4(debugShader, "uProjection", camera.GetProjectionMatrix())
+
+	// Debug shapes are drawn over everything, blended, in filled mode
+	previous := dr.device.State()
+	dr.device.SetState(device.OverlayState)
+	dr.draw(device.Triangles, dr.triangles)
+	dr.draw(device.Lines, dr.lines)
+	dr.device.SetState(previous)
+	return nil
+}
+
+// draw uploads vertices and draws them as one primitive type
+func (dr *DebugRenderer) draw(primitive device.Primitive, vertices []float32) {
+	if len(vertices) == 0 {
+		return
+	}
+	dr.device.UploadVertices(dr.vbo, vertices, device.UsageStream)
+	dr.device.Draw(dr.vao, primitive, 0, len(vertices)/debugVertexFloats)
+}
+
+// addLine adds a line segment
+func (dr *DebugRenderer) addLine(from, to debugdraw.Point, color debugdraw.Color) {
+	dr.lines = appendDebugVertex(dr.lines, from, color)
+	dr.lines = appendDebugVertex(dr.lines, to, color)
+}
+
+// addSphere adds a wire sphere as three circles around its axes
+func (dr *DebugRenderer) addSphere(sphere debugdraw.Sphere) {
+	center, radius := sphere.Center, sphere.Radius
+	point := func(axis int, angle float64) debugdraw.Point {
+		u, v := radius*math.Cos(angle), radius*math.Sin(angle)
+		switch axis {
+		case 0: // Around the vertical axis, on the ground plane
+			return debugdraw.Point{X: center.X + u, Y: center.Y, Z: center.Z + v}
+		case 1:
+			return debugdraw.Point{X: center.X + u, Y: center.Y + v, Z: center.Z}
+		default:
+			return debugdraw.Point{X: center.X, Y: center.Y + u, Z: center.Z + v}
+		}
+	}
+	step := 2 * math.Pi / debugCircleSegments
+	for axis := 0; axis < 3; axis++ {
+		for i := 0; i < debugCircleSegments; i++ {
+			dr.addLine(point(axis, float64(i)*step), point(axis, float64(i+1)*step), sphere.Color)
+		}
+	}
+}
+
+// addGrid adds a quad for every visible cell of a grid overlay, at the cell's ground height
+func (dr *DebugRenderer) addGrid(grid debugdraw.Grid) {
+	size := grid.CellSize
+	for row := 0; row < grid.Height; row++ {
+		for column := 0; column < grid.Width; column++ {
+			cell := row*grid.Width + column
+			color := grid.Cells[cell]
+			if color.A <= 0 {
+				continue
+			}
+			y := grid.Origin.Y + debugGridLift
+			if grid.Heights != nil {
+				y += grid.Heights[cell]
+			}
+			x0, z0 := grid.Origin.X+float64(column)*size, grid.Origin.Z+float64(row)*size
+			corners := [4]debugdraw.Point{
+				{X: x0, Y: y, Z: z0}, {X: x0 + size, Y: y, Z: z0},
+				{X: x0 + size, Y: y, Z: z0 + size}, {X: x0, Y: y, Z: z0 + size},
+			}
+			for _, corner := range [6]int{0, 1, 2, 2, 3, 0} {
+				dr.triangles = appendDebugVertex(dr.triangles, corners[corner], color)
+			}
+		}
+	}
+}
+
+// addLabelMarker marks a label's anchor with a post and a cross at its top,
+// and queues the text above the cross
+func (dr *DebugRenderer) addLabelMarker(label debugdraw.Label) {
+	p := label.Position
+	top := debugdraw.Point{X: p.X, Y: p.Y + 4*debugLabelSize, Z: p.Z}
+	color := label.Color
+	dr.text.DrawWorldText(mgl32.Vec3{float32(top.X), float32(top.Y), float32(top.Z)},
+		label.Text, DefaultTextSize, mgl32.Vec4{color.R, color.G, color.B, color.A})
+	dr.addLine(p, top, label.Color)
+	dr.addLine(debugdraw.Point{X: top.X - debugLabelSize, Y: top.Y, Z: top.Z},
+		debugdraw.Point{X: top.X + debugLabelSize, Y: top.Y, Z: top.Z}, label.Color)
+	dr.addLine(debugdraw.Point{X: top.X, Y: top.Y, Z: top.Z - debugLabelSize},
+		debugdraw.Point{X: top.X, Y: top.Y, Z: top.Z + debugLabelSize}, label.Color)
+}
+
+// logLabels logs the label texts when they change, for renderers without text
+func (dr *DebugRenderer) logLabels(labels []debugdraw.Label) {
+	var text strings.Builder
+	for _, label := range labels {
+		fmt.Fprintf(&text, "\n  [%s] (%.1f, %.1f, %.1f) %s",
+			label.Category, label.Position.X, label.Position.Y, label.Position.Z, label.Text)
+	}
+	if text.String() == dr.labels {
+		return
+	}
+	dr.labels = text.String()
+	if dr.labels != "" {
+		logging.Debugf(logging.CategoryRender, "Debug labels:%s", dr.labels)
+	}
+}
+
+// Destroy frees the vertex buffer
+func (dr *DebugRenderer) Destroy() {
+	if dr == nil {
+		return
+	}
+	dr.device.DeleteBuffer(dr.vbo)
+	dr.device.DeleteVertexArray(dr.vao)
+}
+
+// appendDebugVertex appends a vertex with a color
+func appendDebugVertex(vertices []float32, p debugdraw.Point, color debugdraw.Color) []float32 {
+	return append(vertices, float32(p.X), float32(p.Y), float32(p.Z), color.R, color.G, color.B, color.A)
+}
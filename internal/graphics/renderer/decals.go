@@ -0,0 +1,105 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+	"math"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/device"
+)
+
+// decalShader is the shader program drawing ground decals
+const decalShader = "decal"
+
+// decalLift raises decals above the ground, against z-fighting
+const decalLift = 0.03
+
+// decalVertexLayout is a position, a coordinate across the decal and the
+// decal's kind, opacity and noise seed
+var decalVertexLayout = device.VertexLayout{3, 2, 4}
+
+// DecalRenderer draws the scorch marks, craters and rubble left on the ground
+type DecalRenderer struct {
+	shaders  *ShaderManager
+	device   device.GraphicsDevice
+	vao      device.VertexArray
+	vbo      device.Buffer
+	vertices []float32
+}
+
+// NewDecalRenderer loads the decal shader and creates the vertex buffer
+func NewDecalRenderer(shaders *ShaderManager) (*DecalRenderer, error) {
+	err := shaders.LoadShader(decalShader,
+		"internal/graphics/shaders/decal.vert",
+		"internal/graphics/shaders/decal.frag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load decal shader: %w", err)
+	}
+
+	dr := &DecalRenderer{shaders: shaders, device: shaders.Device()}
+	dr.vao, dr.vbo = dr.device.CreateVertexArray(decalVertexLayout)
+	return dr, nil
+}
+
+// Render draws the world's decals on the ground, as one quad each
+func (dr *DecalRenderer) Render(world *engine.World, camera *Camera) error {
+	if dr == nil {
+		return nil
+	}
+	decals := world.Decals()
+	if len(decals) == 0 {
+		return nil
+	}
+
+	dr.vertices = dr.vertices[:0]
+	for _, decal := range decals {
+		if opacity := decal.Opacity(); opacity > 0 {
+			y := float64(world.HeightAt(decal.Position)) + decalLift
+			dr.addDecal(decal, y, float32(opacity))
+		}
+	}
+	if len(dr.vertices) == 0 {
+		return nil
+	}
+
+	if err := dr.shaders.UseShader(decalShader); err != nil {
+		return err
+	}
+	dr.shaders.SetUniformMat4(decalShader, "uView", camera.GetViewMatrix())
+	dr.shaders.SetUniformMat4(decalShader, "uProjection", camera.GetProjectionMatrix())
+
+	// Decals are depth tested against the scene and blended onto the ground
+	previous := dr.device.State()
+	dr.device.SetState(device.RenderState{DepthTest: true, AlphaBlend: true})
+	dr.device.UploadVertices(dr.vbo, dr.vertices, device.UsageStream)
+	dr.device.Draw(dr.vao, device.Triangles, 0, len(dr.vertices)/decalVertexLayout.Stride())
+	dr.device.SetState(previous)
+	return nil
+}
+
+// addDecal adds a quad covering the decal, turned by its rotation, which
+// also seeds the noise so every mark looks different
+func (dr *DecalRenderer) addDecal(decal engine.Decal, y float64, opacity float32) {
+	sin, cos := math.Sincos(decal.Rotation)
+	seed := float32(decal.Rotation / (2 * math.Pi))
+	corner := func(u, v float64) []float32 {
+		x := decal.Position.X + decal.Radius*(u*cos-v*sin)
+		z := decal.Position.Z + decal.Radius*(u*sin+v*cos)
+		return []float32{float32(x), float32(y), float32(z), float32(u), float32(v), float32(decal.Kind), opacity, seed, 0}
+	}
+	corners := [4][]float32{corner(-1, -1), corner(1, -1), corner(1, 1), corner(-1, 1)}
+	for _, index := range [6]int{0, 1, 2, 2, 3, 0} {
+		dr.vertices = append(dr.vertices, corners[index]...)
+	}
+}
+
+// Destroy frees the vertex buffer
+func (dr *DecalRenderer) Destroy() {
+	if dr == nil {
+		return
+	}
+	dr.device.DeleteBuffer(dr.vbo)
+	dr.device.DeleteVertexArray(dr.vao)
+}
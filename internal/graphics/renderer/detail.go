@@ -0,0 +1,279 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics"
+	"teraglest/internal/graphics/device"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// detailShader is the shader program drawing grass tufts
+const detailShader = "detail"
+
+// detailChunkSize is the side of the square of tiles whose detail is placed
+// together and kept until the map or quality changes
+const detailChunkSize = 8
+
+// detailFadeStart is the share of the detail distance where grass starts fading
+const detailFadeStart = 0.7
+
+// tuftVertexCount is the vertices of a tuft: three cards of two triangles
+const tuftVertexCount = 3 * 6
+
+// Tuft vertices are a card corner and a coordinate across the card; tuft
+// instances are a root position and height, then a rotation and color
+var (
+	detailVertexLayout   = device.VertexLayout{3, 2}
+	detailInstanceLayout = device.VertexLayout{4, 4}
+)
+
+// detailMesh is a tileset detail mesh placed on the ground
+type detailMesh struct {
+	path      string // G3D file, relative to the data directory
+	transform mgl32.Mat4
+}
+
+// detailChunk is the detail placed on a square of tiles
+type detailChunk struct {
+	tufts  []float32 // Instance attributes of the grass tufts
+	meshes []detailMesh
+}
+
+// DetailRenderer draws instanced grass tufts and collects the detail meshes
+// growing on a map's grass surfaces, thinned by the map's detail density and
+// faded out with distance from the camera focus
+type DetailRenderer struct {
+	shaders *ShaderManager
+	device  device.GraphicsDevice
+	vao     device.VertexArray
+	vbo     device.Buffer
+	tuftVBO device.Buffer
+	start   time.Time
+
+	params  graphics.DetailParams
+	mapData *engine.Map // Map the density and chunks below were made for
+	density *engine.DetailDensityMap
+	chunks  map[engine.Vector2i]*detailChunk
+
+	instances []float32                 // Tuft instances drawn this frame
+	meshes    *graphics.InstanceBatcher // Detail meshes drawn this frame
+}
+
+// NewDetailRenderer loads the grass shader and creates the tuft geometry;
+// detail stays off until a quality is set
+func NewDetailRenderer(shaders *ShaderManager) (*DetailRenderer, error) {
+	err := shaders.LoadShader(detailShader,
+		"internal/graphics/shaders/detail.vert",
+		"internal/graphics/shaders/detail.frag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load detail shader: %w", err)
+	}
+
+	dr := &DetailRenderer{
+		shaders: shaders,
+		device:  shaders.Device(),
+		start:   time.Now(),
+		chunks:  make(map[engine.Vector2i]*detailChunk),
+		meshes:  graphics.NewInstanceBatcher(),
+	}
+	dr.vao, dr.vbo, dr.tuftVBO = dr.device.CreateInstancedVertexArray(detailVertexLayout, detailInstanceLayout)
+	dr.device.UploadVertices(dr.vbo, tuftVertices(), device.UsageStatic)
+	return dr, nil
+}
+
+// tuftVertices returns three cards crossed at 60 degrees, one unit high
+func tuftVertices() []float32 {
+	var vertices []float32
+	for card := 0; card < 3; card++ {
+		sin, cos := math.Sincos(float64(card) * math.Pi / 3)
+		corner := func(u, v float64) []float32 {
+			x, z := 0.4*u*cos, 0.4*u*sin
+			return []float32{float32(x), float32(v), float32(z), float32(u), float32(v)}
+		}
+		corners := [4][]float32{corner(-1, 0), corner(1, 0), corner(1, 1), corner(-1, 1)}
+		for _, index := range [6]int{0, 1, 2, 2, 3, 0} {
+			vertices = append(vertices, corners[index]...)
+		}
+	}
+	return vertices
+}
+
+// SetQuality changes how much detail grows and how far it is drawn
+func (dr *DetailRenderer) SetQuality(quality graphics.Quality) {
+	if dr == nil || dr.params == quality.Detail() {
+		return
+	}
+	dr.params = quality.Detail()
+	dr.chunks = make(map[engine.Vector2i]*detailChunk)
+}
+
+// Meshes returns the detail meshes of the last Render, batched for instanced drawing
+func (dr *DetailRenderer) Meshes() *graphics.InstanceBatcher {
+	return dr.meshes
+}
+
+// Render draws the grass within the detail distance of the camera focus and
+// batches the detail meshes there, loading their models with loadModel
+func (dr *DetailRenderer) Render(world *engine.World, camera *Camera, loadModel func(path string) *graphics.Model) error {
+	if dr == nil {
+		return nil
+	}
+	dr.meshes.Reset()
+	if dr.params.Distance <= 0 || world.Map == nil {
+		return nil
+	}
+	if world.Map != dr.mapData {
+		dr.mapData = world.Map
+		dr.density = world.Map.DetailDensity()
+		dr.chunks = make(map[engine.Vector2i]*detailChunk)
+	}
+
+	// Gather the chunks within reach of the focus
+	tileSize := world.GetTileSize()
+	focus := mgl32.Vec2{camera.Target.X(), camera.Target.Z()}
+	reach := int(math.Ceil(float64(dr.params.Distance/tileSize)/detailChunkSize)) + 1
+	center := engine.Vector2i{X: int(focus.X() / tileSize / detailChunkSize), Y: int(focus.Y() / tileSize / detailChunkSize)}
+	dr.instances = dr.instances[:0]
+	for y := center.Y - reach; y <= center.Y+reach; y++ {
+		for x := center.X - reach; x <= center.X+reach; x++ {
+			if x < 0 || y < 0 || x*detailChunkSize >= dr.density.Width || y*detailChunkSize >= dr.density.Height {
+				continue
+			}
+			chunk := dr.chunk(world, engine.Vector2i{X: x, Y: y})
+			dr.instances = append(dr.instances, chunk.tufts...)
+			for _, mesh := range chunk.meshes {
+				if model := loadModel(mesh.path); model != nil {
+					dr.meshes.Add(model, model.CurrentFrame, graphics.Instance{Transform: mesh.transform})
+				}
+			}
+		}
+	}
+	if len(dr.instances) == 0 {
+		return nil
+	}
+
+	if err := dr.shaders.UseShader(detailShader); err != nil {
+		return err
+	}
+	dr.shaders.SetUniformMat4(detailShader, "uView", camera.GetViewMatrix())
+	dr.shaders.SetUniformMat4(detailShader, "uProjection", camera.GetProjectionMatrix())
+	dr.shaders.SetUniformVec2(detailShader, "uFocus", focus)
+	dr.shaders.SetUniformVec2(detailShader, "uFade", mgl32.Vec2{dr.params.Distance * detailFadeStart, dr.params.Distance})
+	dr.shaders.SetUniformFloat(detailShader, "uTime", float32(time.Since(dr.start).Seconds()))
+
+	// Tuft cards are seen from both sides
+	previous := dr.device.State()
+	dr.device.SetState(device.RenderState{DepthTest: true})
+	dr.device.UploadVertices(dr.tuftVBO, dr.instances, device.UsageStream)
+	dr.device.DrawInstanced(dr.vao, device.Triangles, 0, tuftVertexCount, len(dr.instances)/detailInstanceLayout.Stride())
+	dr.device.SetState(previous)
+	return nil
+}
+
+// TuftCount returns the grass tufts drawn by the last Render
+func (dr *DetailRenderer) TuftCount() int {
+	if dr == nil {
+		return 0
+	}
+	return len(dr.instances) / detailInstanceLayout.Stride()
+}
+
+// chunk returns the detail placed on a chunk, placing it on first use. The
+// placement is seeded by tile, so it is the same every time the chunk is made.
+func (dr *DetailRenderer) chunk(world *engine.World, cell engine.Vector2i) *detailChunk {
+	if chunk, placed := dr.chunks[cell]; placed {
+		return chunk
+	}
+	chunk := &detailChunk{}
+	dr.chunks[cell] = chunk
+
+	tileSize := world.GetTileSize()
+	for y := cell.Y * detailChunkSize; y < (cell.Y+1)*detailChunkSize; y++ {
+		for x := cell.X * detailChunkSize; x < (cell.X+1)*detailChunkSize; x++ {
+			density, detail := dr.density.At(x, y)
+			if detail == nil {
+				continue
+			}
+			random := newTileRandom(x, y)
+			wanted := density * detail.Density * dr.params.Density
+			count := int(wanted)
+			if random.next() < wanted-float32(count) {
+				count++
+			}
+			for i := 0; i < count; i++ {
+				position := engine.Vector3{
+					X: (float64(x) + float64(random.next())) * float64(tileSize),
+					Z: (float64(y) + float64(random.next())) * float64(tileSize),
+				}
+				position.Y = float64(world.HeightAt(position))
+				rotation := random.next() * 2 * math.Pi
+
+				if len(detail.Models) > 0 && random.next() < detail.ModelChance {
+					model := detail.Models[int(random.next()*float32(len(detail.Models)))%len(detail.Models)]
+					chunk.meshes = append(chunk.meshes, detailMesh{
+						path: path.Join("tilesets", dr.mapData.TilesetName, model),
+						transform: mgl32.Translate3D(float32(position.X), float32(position.Y), float32(position.Z)).
+							Mul4(mgl32.HomogRotate3DY(rotation)),
+					})
+					continue
+				}
+
+				height := detail.Height * (0.7 + 0.6*random.next())
+				shade := 0.85 + 0.3*random.next()
+				chunk.tufts = append(chunk.tufts,
+					float32(position.X), float32(position.Y), float32(position.Z), height,
+					rotation, detail.Color[0]*shade, detail.Color[1]*shade, detail.Color[2]*shade)
+			}
+		}
+	}
+	return chunk
+}
+
+// tileRandom is a small generator seeded by a tile, so detail placement does
+// not depend on the order chunks are placed in
+type tileRandom uint32
+
+// newTileRandom seeds a generator from tile coordinates
+func newTileRandom(x, y int) *tileRandom {
+	seed := tileRandom(uint32(x)*73856093 ^ uint32(y)*19349663 | 1)
+	seed.next() // Spread the seeds of neighbouring tiles apart
+	return &seed
+}
+
+// next returns a value in [0, 1)
+func (r *tileRandom) next() float32 {
+	// xorshift32
+	*r ^= *r << 13
+	*r ^= *r >> 17
+	*r ^= *r << 5
+	return float32(uint32(*r)>>8) / (1 << 24)
+}
+
+// Release forgets the detail placed on the current map, e.g. when its match ends
+func (dr *DetailRenderer) Release() {
+	if dr == nil {
+		return
+	}
+	dr.mapData, dr.density = nil, nil
+	dr.chunks = make(map[engine.Vector2i]*detailChunk)
+	dr.instances = nil
+	dr.meshes.Reset()
+}
+
+// Destroy frees the tuft buffers
+func (dr *DetailRenderer) Destroy() {
+	if dr == nil {
+		return
+	}
+	dr.device.DeleteBuffer(dr.vbo)
+	dr.device.DeleteBuffer(dr.tuftVBO)
+	dr.device.DeleteVertexArray(dr.vao)
+}
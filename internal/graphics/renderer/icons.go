@@ -0,0 +1,264 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"teraglest/internal/data"
+	"teraglest/internal/graphics"
+	"teraglest/internal/graphics/icons"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/logging"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// iconShader is the shader program icons are rendered with
+const iconShader = "advanced_model"
+
+// IconRenderer makes portrait icons for units whose mod ships no usable icon
+// image, by rendering the unit's model into an offscreen framebuffer. Icons
+// are cached on disk, keyed by the model file, so each is rendered once.
+type IconRenderer struct {
+	shaders  *ShaderManager
+	models   *graphics.ModelManager
+	lights   *graphics.LightManager
+	assetMgr *data.AssetManager
+	sprites  *SpriteRenderer
+	cache    *icons.Cache // Nil to render icons every run
+	size     int
+
+	fbo   uint32 // Created on the first render
+	color uint32
+	depth uint32
+
+	modelIcons map[string]sprite.Texture // Model path -> icon, NoTexture if it failed
+	unitIcons  map[string]sprite.Texture // "faction/unit" -> icon
+}
+
+// NewIconRenderer creates an icon renderer uploading icons through a sprite renderer
+func NewIconRenderer(shaders *ShaderManager, models *graphics.ModelManager, lights *graphics.LightManager,
+	assetMgr *data.AssetManager, sprites *SpriteRenderer) *IconRenderer {
+	return &IconRenderer{
+		shaders:    shaders,
+		models:     models,
+		lights:     lights,
+		assetMgr:   assetMgr,
+		sprites:    sprites,
+		size:       icons.DefaultSize,
+		modelIcons: make(map[string]sprite.Texture),
+		unitIcons:  make(map[string]sprite.Texture),
+	}
+}
+
+// Icons returns the portrait icon renderer, nil if HUD sprites are unavailable
+func (r *Renderer) Icons() *IconRenderer {
+	return r.icons
+}
+
+// SetCacheDir sets the directory rendered icons are kept in between runs
+func (ir *IconRenderer) SetCacheDir(dir string) {
+	if ir == nil {
+		return
+	}
+	ir.cache = icons.NewCache(dir)
+}
+
+// UnitIcon returns the portrait of a unit or building: the icon image its
+// mod ships, else one rendered from its model. It returns sprite.NoTexture
+// when there is neither.
+func (ir *IconRenderer) UnitIcon(factionName, unitName string) sprite.Texture {
+	if ir == nil {
+		return sprite.NoTexture
+	}
+	key := factionName + "/" + unitName
+	if icon, known := ir.unitIcons[key]; known {
+		return icon
+	}
+	icon := sprite.NoTexture
+	portrait, err := ir.assetMgr.UnitPortrait(factionName, unitName)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "No portrait for %s: %v", key, err)
+	} else if portrait.Image != "" {
+		// Icons are kept by ID, so their textures must stay resident
+		icon = ir.sprites.Texture(portrait.Image)
+		ir.sprites.textures.Acquire(portrait.Image)
+	}
+	if icon == sprite.NoTexture && portrait.Model != "" {
+		icon = ir.ModelIcon(portrait.Model)
+	}
+	ir.unitIcons[key] = icon
+	return icon
+}
+
+// ModelIcon returns an icon rendered from a model relative to the tech tree
+// root, from the disk cache when it has one
+func (ir *IconRenderer) ModelIcon(modelPath string) sprite.Texture {
+	if ir == nil || ir.sprites == nil || modelPath == "" {
+		return sprite.NoTexture
+	}
+	if icon, known := ir.modelIcons[modelPath]; known {
+		return icon
+	}
+	icon, err := ir.loadModelIcon(modelPath)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "No icon for model %s: %v", modelPath, err)
+	}
+	ir.modelIcons[modelPath] = icon
+	return icon
+}
+
+// GenerateFactionIcons makes the icons of every unit and building of a
+// faction up front, so none is rendered in the middle of play; it returns
+// how many units have an icon
+func (ir *IconRenderer) GenerateFactionIcons(factionName string) (int, error) {
+	if ir == nil {
+		return 0, fmt.Errorf("icon rendering is not available")
+	}
+	names, err := ir.assetMgr.FactionUnitNames(factionName)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, name := range names {
+		if ir.UnitIcon(factionName, name) != sprite.NoTexture {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// loadModelIcon loads a model's icon from the cache, rendering and caching it on a miss
+func (ir *IconRenderer) loadModelIcon(modelPath string) (sprite.Texture, error) {
+	fullPath := filepath.Join(ir.assetMgr.GetTechTreeRoot(), filepath.FromSlash(modelPath))
+	key := ""
+	if ir.cache != nil {
+		var err error
+		if key, err = ir.cache.Key(fullPath, ir.size); err != nil {
+			return sprite.NoTexture, err
+		}
+		if img, cached := ir.cache.Load(key); cached {
+			return ir.upload(modelPath, img)
+		}
+	}
+
+	model, err := ir.models.LoadG3DModel(fullPath)
+	if err != nil {
+		return sprite.NoTexture, err
+	}
+	img, err := ir.render(model)
+	if err != nil {
+		return sprite.NoTexture, err
+	}
+	if ir.cache != nil {
+		if err := ir.cache.Store(key, img); err != nil {
+			logging.Warnf(logging.CategoryRender, "Failed to cache icon of %s: %v", modelPath, err)
+		}
+	}
+	return ir.upload(modelPath, img)
+}
+
+// upload makes an icon image a sprite texture
+func (ir *IconRenderer) upload(modelPath string, img image.Image) (sprite.Texture, error) {
+	texture, err := ir.sprites.textures.LoadTextureFromImage(img, "icon:"+modelPath)
+	if err != nil {
+		return sprite.NoTexture, err
+	}
+	ir.sprites.textures.Acquire(texture.Path)
+	return sprite.Texture(texture.ID), nil
+}
+
+// render draws a model alone into the icon framebuffer and reads it back,
+// restoring the framebuffer, viewport and clear color of the frame
+func (ir *IconRenderer) render(model *graphics.Model) (image.Image, error) {
+	if err := ir.ensureFramebuffer(); err != nil {
+		return nil, err
+	}
+	if err := ir.shaders.UseShader(iconShader); err != nil {
+		return nil, err
+	}
+
+	var framebuffer int32
+	var viewport [4]int32
+	var clearColor [4]float32
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &framebuffer)
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+	gl.GetFloatv(gl.COLOR_CLEAR_VALUE, &clearColor[0])
+	defer func() {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(framebuffer))
+		gl.Viewport(viewport[0], viewport[1], viewport[2], viewport[3])
+		gl.ClearColor(clearColor[0], clearColor[1], clearColor[2], clearColor[3])
+	}()
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, ir.fbo)
+	gl.Viewport(0, 0, int32(ir.size), int32(ir.size))
+	gl.ClearColor(0, 0, 0, 0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	gl.Enable(gl.DEPTH_TEST)
+
+	// The model is drawn at the origin, in neutral colors, with plain output
+	view, projection := icons.Framing(model.BoundingBox.Min, model.BoundingBox.Max)
+	ir.shaders.SetUniformMat4(iconShader, "uView", view)
+	ir.shaders.SetUniformMat4(iconShader, "uProjection", projection)
+	ir.shaders.SetUniformVec3(iconShader, "uViewPosition", view.Inv().Col(3).Vec3())
+	ir.shaders.SetUniformBool(iconShader, "uHDROutput", false)
+	if err := ir.lights.UpdateShaderUniforms(ir.shaders, iconShader); err != nil {
+		return nil, err
+	}
+	transform, teamColor := model.Transform, model.TeamColor
+	model.Transform, model.TeamColor = mgl32.Ident4(), graphics.NeutralTeamColor
+	err := model.Render(iconShader, ir.shaders)
+	model.Transform, model.TeamColor = transform, teamColor
+	if err != nil {
+		return nil, err
+	}
+
+	pixels := make([]byte, ir.size*ir.size*4)
+	gl.ReadPixels(0, 0, int32(ir.size), int32(ir.size), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+	return icons.FromReadback(pixels, ir.size, ir.size)
+}
+
+// ensureFramebuffer creates the icon framebuffer on first use
+func (ir *IconRenderer) ensureFramebuffer() error {
+	if ir.fbo != 0 {
+		return nil
+	}
+	size := int32(ir.size)
+	gl.GenTextures(1, &ir.color)
+	gl.BindTexture(gl.TEXTURE_2D, ir.color)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, size, size, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	gl.GenRenderbuffers(1, &ir.depth)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, ir.depth)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, size, size)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+	var previous int32
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &previous)
+	gl.GenFramebuffers(1, &ir.fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, ir.fbo)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, ir.color, 0)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, ir.depth)
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(previous))
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		ir.Destroy()
+		return fmt.Errorf("icon framebuffer incomplete: 0x%x", status)
+	}
+	return nil
+}
+
+// Destroy frees the icon framebuffer; the icons belong to the sprite renderer
+func (ir *IconRenderer) Destroy() {
+	if ir == nil || ir.fbo == 0 {
+		return
+	}
+	gl.DeleteFramebuffers(1, &ir.fbo)
+	gl.DeleteRenderbuffers(1, &ir.depth)
+	gl.DeleteTextures(1, &ir.color)
+	ir.fbo, ir.depth, ir.color = 0, 0, 0
+}
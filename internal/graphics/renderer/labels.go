@@ -0,0 +1,66 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// World label placement
+const (
+	nameTagHeight       = 4.0 // Height of player name tags above town centres
+	resourceLabelHeight = 2.0 // Height of resource amounts above nodes
+)
+
+// resourceLabelColor is the color of resource amounts
+var resourceLabelColor = mgl32.Vec4{1, 0.9, 0.5, 1}
+
+// queueWorldLabels queues the labels drawn over the map: every player's name
+// over their town centres, and the amount left in each resource node while
+// Alt is held
+func (r *Renderer) queueWorldLabels(world *engine.World) {
+	if r.text == nil {
+		return
+	}
+	for _, player := range world.GetAllPlayers() {
+		if player.Name == "" {
+			continue
+		}
+		color := graphics.TeamColor(player.ID).Vec4(1)
+		for _, building := range world.ObjectManager.GetBuildingsForPlayer(player.ID) {
+			if !building.IsBuilt || !engine.IsTownCenter(building) {
+				continue
+			}
+			p := building.Position
+			r.text.DrawWorldText(mgl32.Vec3{float32(p.X), float32(p.Y) + nameTagHeight, float32(p.Z)},
+				player.Name, DefaultTextSize, color)
+		}
+	}
+
+	if !r.altHeld() {
+		return
+	}
+	for _, node := range world.GetAllResourceNodes() {
+		p := node.Position
+		r.text.DrawWorldText(mgl32.Vec3{float32(p.X), float32(p.Y) + resourceLabelHeight, float32(p.Z)},
+			fmt.Sprintf("%s %d", node.ResourceType, node.Amount), DefaultTextSize, resourceLabelColor)
+	}
+}
+
+// altHeld reports whether either Alt key is down
+func (r *Renderer) altHeld() bool {
+	window := r.context.GetWindow()
+	return window.GetKey(glfw.KeyLeftAlt) == glfw.Press || window.GetKey(glfw.KeyRightAlt) == glfw.Press
+}
+
+// Text returns the renderer drawing labels, nil if its shader failed to load.
+// Text queued on it is drawn over the next frame.
+func (r *Renderer) Text() *TextRenderer {
+	return r.text
+}
@@ -0,0 +1,333 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics"
+	"teraglest/internal/graphics/device"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Picking shader programs
+const (
+	pickingShader    = "picking"     // Instanced unit models
+	pickingBoxShader = "picking_box" // Boxes for buildings and units without models
+)
+
+// pickingWindow is the side, in pixels, of the area around the cursor drawn
+// into the picking buffer and read back each frame
+const pickingWindow = 16
+
+// pickBuildingBit marks building IDs in the picking buffer; units and
+// buildings are numbered separately
+const pickBuildingBit = 1 << 23
+
+// PickKind is what kind of object a picking buffer pixel shows
+type PickKind int
+
+const (
+	PickNone     PickKind = iota // Nothing, the ground or sky
+	PickUnit                     // A unit
+	PickBuilding                 // A building
+)
+
+// Picked is the object drawn at a pixel of the picking buffer
+type Picked struct {
+	Kind PickKind
+	ID   int // Unit or building ID, 0 for PickNone
+}
+
+// pickingBox is a box drawn into the picking buffer
+type pickingBox struct {
+	transform mgl32.Mat4 // Of the unit cube centered on the origin
+	color     mgl32.Vec3
+}
+
+// PickingRenderer draws the IDs of units and buildings into an offscreen
+// buffer around the cursor and reads them back, so clicks and hovers select
+// exactly what is drawn under the cursor, whatever its footprint and
+// whatever stands in front of it. Units are drawn with their models;
+// buildings, which have no placed models yet, as boxes over their footprint.
+type PickingRenderer struct {
+	shaders *ShaderManager
+	device  device.GraphicsDevice
+	enabled bool
+
+	units  *graphics.InstanceBatcher // Unit models this frame, colored by ID
+	boxes  []pickingBox              // Boxes this frame
+	boxVAO device.VertexArray
+	boxVBO device.Buffer
+
+	target       renderTarget // Sized to the window, created on the first render
+	cursorX      int          // Cursor in window pixels from the top left
+	cursorY      int
+	areaX, areaY int // Area read back last frame, in window pixels from the top left
+	areaW, areaH int
+	pixels       []byte // RGBA rows of the area, bottom row first
+}
+
+// NewPickingRenderer loads the picking shaders and creates the box geometry
+func NewPickingRenderer(shaders *ShaderManager) (*PickingRenderer, error) {
+	err := shaders.LoadShader(pickingShader,
+		"internal/graphics/shaders/silhouette.vert",
+		"internal/graphics/shaders/picking.frag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load picking shader: %w", err)
+	}
+	err = shaders.LoadShader(pickingBoxShader,
+		"internal/graphics/shaders/picking_box.vert",
+		"internal/graphics/shaders/picking.frag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load picking box shader: %w", err)
+	}
+
+	pr := &PickingRenderer{shaders: shaders, device: shaders.Device(), enabled: true, units: graphics.NewInstanceBatcher()}
+	pr.boxVAO, pr.boxVBO = pr.device.CreateVertexArray(device.VertexLayout{3})
+	pr.device.UploadVertices(pr.boxVBO, unitCubeTriangles(), device.UsageStatic)
+	return pr, nil
+}
+
+// Picking returns the GPU picking renderer, nil if its shaders failed to load
+func (r *Renderer) Picking() *PickingRenderer {
+	return r.picking
+}
+
+// SetEnabled turns the picking pass on or off; while off, Pick finds nothing
+func (pr *PickingRenderer) SetEnabled(enabled bool) {
+	if pr == nil {
+		return
+	}
+	pr.enabled = enabled
+	if !enabled {
+		pr.areaW, pr.areaH = 0, 0
+	}
+}
+
+// SetCursor sets the window pixel, from the top left, whose surroundings
+// the next frames draw into the picking buffer
+func (pr *PickingRenderer) SetCursor(x, y int) {
+	if pr == nil {
+		return
+	}
+	pr.cursorX, pr.cursorY = x, y
+}
+
+// Pick returns the object drawn at a window pixel in the last frame; false
+// when the pixel was outside the area drawn around the cursor, so callers
+// fall back to picking by position
+func (pr *PickingRenderer) Pick(x, y int) (Picked, bool) {
+	if pr == nil || x < pr.areaX || y < pr.areaY || x >= pr.areaX+pr.areaW || y >= pr.areaY+pr.areaH {
+		return Picked{}, false
+	}
+	row := pr.areaY + pr.areaH - 1 - y // Rows are read back bottom first
+	i := (row*pr.areaW + x - pr.areaX) * 4
+	return decodePickID(pr.pixels[i], pr.pixels[i+1], pr.pixels[i+2]), true
+}
+
+// begin empties the objects of the last frame
+func (pr *PickingRenderer) begin() {
+	if pr == nil {
+		return
+	}
+	pr.units.Reset()
+	pr.boxes = pr.boxes[:0]
+}
+
+// addUnit adds a unit drawn with a model at an animation frame
+func (pr *PickingRenderer) addUnit(model *graphics.Model, frame int, transform mgl32.Mat4, unitID int) {
+	if pr == nil || !pr.enabled {
+		return
+	}
+	pr.units.Add(model, frame, graphics.Instance{Transform: transform, TeamColor: encodePickID(PickUnit, unitID)})
+}
+
+// addBox adds a box of a size standing on a point for an object
+func (pr *PickingRenderer) addBox(position engine.Vector3, size mgl32.Vec3, kind PickKind, id int) {
+	if pr == nil || !pr.enabled {
+		return
+	}
+	transform := mgl32.Translate3D(float32(position.X), float32(position.Y)+size.Y()/2, float32(position.Z)).
+		Mul4(mgl32.Scale3D(size.X(), size.Y(), size.Z()))
+	pr.boxes = append(pr.boxes, pickingBox{transform: transform, color: encodePickID(kind, id)})
+}
+
+// Render draws the objects of the frame into the picking buffer around the
+// cursor, with buildings as boxes over their footprints, and reads the area
+// back. The frame's framebuffer, viewport and clear color are restored.
+func (pr *PickingRenderer) Render(world *engine.World, camera *Camera, width, height int) error {
+	if pr == nil || !pr.enabled || width <= 0 || height <= 0 {
+		return nil
+	}
+	if pr.target.width != width || pr.target.height != height {
+		pr.target.release()
+		pr.target = renderTarget{}
+		target, err := newRenderTarget(width, height, gl.RGBA8, gl.UNSIGNED_BYTE, true)
+		if err != nil {
+			return fmt.Errorf("failed to create picking buffer: %w", err)
+		}
+		pr.target = target
+	}
+
+	tileSize := world.GetTileSize()
+	for _, player := range world.GetAllPlayers() {
+		for _, building := range world.ObjectManager.GetBuildingsForPlayer(player.ID) {
+			if !building.IsAlive() {
+				continue
+			}
+			size := float32(1)
+			if building.UnitDef != nil && building.UnitDef.Unit.Parameters.Size.Value > 0 {
+				size = float32(building.UnitDef.Unit.Parameters.Size.Value)
+			}
+			size *= tileSize
+			pr.addBox(building.GetPosition(), mgl32.Vec3{size, size, size}, PickBuilding, building.ID)
+		}
+	}
+
+	// Draw only the area around the cursor, clamped into the window
+	x := min(max(pr.cursorX-pickingWindow/2, 0), max(width-pickingWindow, 0))
+	y := min(max(pr.cursorY-pickingWindow/2, 0), max(height-pickingWindow, 0))
+	w, h := min(pickingWindow, width), min(pickingWindow, height)
+	glY := height - y - h
+
+	var framebuffer int32
+	var viewport [4]int32
+	var clearColor [4]float32
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &framebuffer)
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+	gl.GetFloatv(gl.COLOR_CLEAR_VALUE, &clearColor[0])
+	previous := pr.device.State()
+	defer func() {
+		gl.Disable(gl.SCISSOR_TEST)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(framebuffer))
+		gl.Viewport(viewport[0], viewport[1], viewport[2], viewport[3])
+		gl.ClearColor(clearColor[0], clearColor[1], clearColor[2], clearColor[3])
+		pr.device.SetState(previous)
+	}()
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, pr.target.fbo)
+	gl.Viewport(0, 0, int32(width), int32(height))
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(int32(x), int32(glY), int32(w), int32(h))
+	gl.ClearColor(0, 0, 0, 0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	pr.device.SetState(device.RenderState{DepthTest: true, CullFace: true})
+
+	if err := pr.drawUnits(camera); err != nil {
+		return err
+	}
+	if err := pr.drawBoxes(camera); err != nil {
+		return err
+	}
+
+	if len(pr.pixels) < w*h*4 {
+		pr.pixels = make([]byte, w*h*4)
+	}
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(int32(x), int32(glY), int32(w), int32(h), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pr.pixels))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 4)
+	pr.areaX, pr.areaY, pr.areaW, pr.areaH = x, y, w, h
+	return nil
+}
+
+// drawUnits draws the unit models of the frame in their ID colors
+func (pr *PickingRenderer) drawUnits(camera *Camera) error {
+	batches := pr.units.Batches()
+	if len(batches) == 0 {
+		return nil
+	}
+	if err := pr.shaders.UseShader(pickingShader); err != nil {
+		return err
+	}
+	pr.shaders.SetUniformMat4(pickingShader, "uView", camera.GetViewMatrix())
+	pr.shaders.SetUniformMat4(pickingShader, "uProjection", camera.GetProjectionMatrix())
+	for _, batch := range batches {
+		if err := batch.Key.Model.DrawInstances(batch.Instances); err != nil {
+			return fmt.Errorf("failed to pick %s: %w", batch.Key.Model.Name, err)
+		}
+	}
+	return nil
+}
+
+// drawBoxes draws the boxes of the frame in their ID colors
+func (pr *PickingRenderer) drawBoxes(camera *Camera) error {
+	if len(pr.boxes) == 0 {
+		return nil
+	}
+	if err := pr.shaders.UseShader(pickingBoxShader); err != nil {
+		return err
+	}
+	pr.shaders.SetUniformMat4(pickingBoxShader, "uView", camera.GetViewMatrix())
+	pr.shaders.SetUniformMat4(pickingBoxShader, "uProjection", camera.GetProjectionMatrix())
+	for _, box := range pr.boxes {
+		pr.shaders.SetUniformMat4(pickingBoxShader, "uModel", box.transform)
+		pr.shaders.SetUniformVec3(pickingBoxShader, "uColor", box.color)
+		pr.device.Draw(pr.boxVAO, device.Triangles, 0, 36)
+	}
+	return nil
+}
+
+// Destroy frees the picking buffer and box geometry
+func (pr *PickingRenderer) Destroy() {
+	if pr == nil {
+		return
+	}
+	pr.target.release()
+	pr.target = renderTarget{}
+	pr.device.DeleteBuffer(pr.boxVBO)
+	pr.device.DeleteVertexArray(pr.boxVAO)
+}
+
+// encodePickID packs an object's kind and ID into a color, a byte per channel
+func encodePickID(kind PickKind, id int) mgl32.Vec3 {
+	value := id & (pickBuildingBit - 1)
+	if kind == PickBuilding {
+		value |= pickBuildingBit
+	}
+	return mgl32.Vec3{
+		float32(value>>16&0xff) / 255,
+		float32(value>>8&0xff) / 255,
+		float32(value&0xff) / 255,
+	}
+}
+
+// decodePickID unpacks the object of a picking buffer pixel
+func decodePickID(r, g, b byte) Picked {
+	value := int(r)<<16 | int(g)<<8 | int(b)
+	switch {
+	case value == 0:
+		return Picked{}
+	case value&pickBuildingBit != 0:
+		return Picked{Kind: PickBuilding, ID: value &^ pickBuildingBit}
+	default:
+		return Picked{Kind: PickUnit, ID: value}
+	}
+}
+
+// unitCubeTriangles returns the triangles of the unit cube centered on the
+// origin, counterclockwise from outside
+func unitCubeTriangles() []float32 {
+	corners := [8]mgl32.Vec3{
+		{-0.5, -0.5, -0.5}, {0.5, -0.5, -0.5}, {0.5, 0.5, -0.5}, {-0.5, 0.5, -0.5},
+		{-0.5, -0.5, 0.5}, {0.5, -0.5, 0.5}, {0.5, 0.5, 0.5}, {-0.5, 0.5, 0.5},
+	}
+	faces := [6][4]int{
+		{4, 5, 6, 7}, // +Z
+		{1, 0, 3, 2}, // -Z
+		{5, 1, 2, 6}, // +X
+		{0, 4, 7, 3}, // -X
+		{7, 6, 2, 3}, // +Y
+		{0, 1, 5, 4}, // -Y
+	}
+	vertices := make([]float32, 0, 36*3)
+	for _, face := range faces {
+		for _, corner := range []int{face[0], face[1], face[2], face[0], face[2], face[3]} {
+			vertices = append(vertices, corners[corner][:]...)
+		}
+	}
+	return vertices
+}
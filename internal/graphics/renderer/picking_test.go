@@ -0,0 +1,46 @@
+//go:build !js
+
+package renderer
+
+import (
+	"math"
+	"testing"
+)
+
+// TestPickIDs tests that unit and building IDs survive the trip through a
+// color buffer, and that Pick reads the area drawn around the cursor
+func TestPickIDs(t *testing.T) {
+	toBytes := func(kind PickKind, id int) (byte, byte, byte) {
+		color := encodePickID(kind, id)
+		channel := func(v float32) byte { return byte(math.Round(float64(v) * 255)) }
+		return channel(color.X()), channel(color.Y()), channel(color.Z())
+	}
+	for _, want := range []Picked{{Kind: PickUnit, ID: 1}, {Kind: PickUnit, ID: 70000}, {Kind: PickBuilding, ID: 1}, {Kind: PickBuilding, ID: 513}} {
+		if got := decodePickID(toBytes(want.Kind, want.ID)); got != want {
+			t.Errorf("Expected %+v back, got %+v", want, got)
+		}
+	}
+	if got := decodePickID(0, 0, 0); got != (Picked{}) {
+		t.Errorf("Expected nothing on a cleared pixel, got %+v", got)
+	}
+
+	// A 2x2 area at (10, 20) with a building in its top right pixel
+	picking := &PickingRenderer{areaX: 10, areaY: 20, areaW: 2, areaH: 2, pixels: make([]byte, 2*2*4)}
+	r, g, b := toBytes(PickBuilding, 7)
+	copy(picking.pixels[(1*2+1)*4:], []byte{r, g, b, 255}) // Top row comes last
+	if picked, ok := picking.Pick(11, 20); !ok || picked != (Picked{Kind: PickBuilding, ID: 7}) {
+		t.Errorf("Expected the building at the top right, got %+v, %v", picked, ok)
+	}
+	if picked, ok := picking.Pick(10, 21); !ok || picked.Kind != PickNone {
+		t.Errorf("Expected nothing at the bottom left, got %+v, %v", picked, ok)
+	}
+	if _, ok := picking.Pick(12, 20); ok {
+		t.Error("Expected no answer outside the area drawn")
+	}
+
+	var nilPicking *PickingRenderer
+	if _, ok := nilPicking.Pick(0, 0); ok {
+		t.Error("Expected no answer without the picking renderer")
+	}
+	nilPicking.SetCursor(1, 1) // Must not panic without the renderer
+}
@@ -0,0 +1,368 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+
+	"teraglest/internal/graphics"
+	"teraglest/internal/logging"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Post-processing shader programs
+const (
+	shaderBloomBright = "post_bloom_bright"
+	shaderBloomBlur   = "post_bloom_blur"
+	shaderComposite   = "post_composite"
+	shaderFXAA        = "post_fxaa"
+)
+
+// renderTarget is a framebuffer with one color texture
+type renderTarget struct {
+	fbo, color, depth uint32 // depth is 0 for targets without a depth buffer
+	width, height     int
+}
+
+// PostProcessor renders the scene into an off-screen HDR buffer and runs the
+// post-processing chain on it: bloom, tone mapping with color grading, and FXAA
+type PostProcessor struct {
+	shaders  *ShaderManager
+	settings graphics.GraphicsSettings
+	enabled  bool // Post-processing is switched on and its resources are ready
+
+	scene      renderTarget    // HDR scene color and depth
+	toneMapped renderTarget    // Tone mapped image, the FXAA input
+	bloom      [2]renderTarget // Ping-pong blur buffers at reduced resolution
+	emptyVAO   uint32          // Full screen triangles are generated in the vertex shader
+
+	// Color grading
+	lut          uint32 // 3D texture, 0 without a table
+	lutSize      int
+	lutDomainMin mgl32.Vec3
+	lutDomainMax mgl32.Vec3
+}
+
+// NewPostProcessor loads the post-processing shaders; the chain stays off until settings are applied
+func NewPostProcessor(shaders *ShaderManager) (*PostProcessor, error) {
+	programs := []struct{ name, fragment string }{
+		{shaderBloomBright, "bloom_bright.frag"},
+		{shaderBloomBlur, "bloom_blur.frag"},
+		{shaderComposite, "composite.frag"},
+		{shaderFXAA, "fxaa.frag"},
+	}
+	for _, program := range programs {
+		err := shaders.LoadShader(program.name,
+			"internal/graphics/shaders/postprocess.vert",
+			"internal/graphics/shaders/"+program.fragment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load post-processing shader: %w", err)
+		}
+	}
+
+	pp := &PostProcessor{shaders: shaders, settings: *graphics.DefaultGraphicsSettings()}
+	pp.settings.PostProcessing = false
+	gl.GenVertexArrays(1, &pp.emptyVAO)
+	return pp, nil
+}
+
+// Apply configures the effects from graphics settings. A color grading table
+// that fails to load turns color grading off and is reported as an error;
+// the other effects are applied regardless.
+func (pp *PostProcessor) Apply(settings *graphics.GraphicsSettings) error {
+	if pp == nil {
+		return fmt.Errorf("post-processing is not available")
+	}
+	pp.settings = *settings
+	pp.enabled = settings.PostProcessing
+
+	// Force render targets to be recreated for the new bloom resolution
+	pp.releaseTargets()
+
+	if pp.lut != 0 {
+		gl.DeleteTextures(1, &pp.lut)
+		pp.lut = 0
+	}
+	if settings.ColorGrading == graphics.QualityOff || settings.ColorGradingLUT == "" {
+		return nil
+	}
+	lut, err := graphics.LoadCubeLUT(settings.LUTPath())
+	if err != nil {
+		pp.settings.ColorGrading = graphics.QualityOff
+		return err
+	}
+	pp.uploadLUT(lut, settings.ColorGrading)
+	return nil
+}
+
+// Enabled returns whether post-processing is switched on
+func (pp *PostProcessor) Enabled() bool {
+	return pp != nil && pp.enabled
+}
+
+// SetEnabled switches post-processing on or off, keeping the effect settings
+func (pp *PostProcessor) SetEnabled(enabled bool) {
+	if pp == nil {
+		return
+	}
+	pp.enabled = enabled
+	pp.settings.PostProcessing = enabled
+}
+
+// Settings returns the active effect settings
+func (pp *PostProcessor) Settings() graphics.GraphicsSettings {
+	return pp.settings
+}
+
+// Begin redirects scene rendering into the HDR buffer, (re)creating the
+// buffers for the framebuffer size; it returns false if post-processing is off
+func (pp *PostProcessor) Begin(width, height int) bool {
+	if !pp.Enabled() || width <= 0 || height <= 0 {
+		return false
+	}
+	if pp.scene.width != width || pp.scene.height != height {
+		if err := pp.createTargets(width, height); err != nil {
+			logging.Warnf(logging.CategoryRender, "Post-processing disabled: %v", err)
+			pp.releaseTargets()
+			pp.enabled = false
+			return false
+		}
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, pp.scene.fbo)
+	gl.Viewport(0, 0, int32(width), int32(height))
+	return true
+}
+
+// Resolve runs the effect chain on the HDR buffer and draws the result to the
+// window's framebuffer; it does nothing if Begin did not redirect the frame
+func (pp *PostProcessor) Resolve() error {
+	if !pp.Enabled() || pp.scene.fbo == 0 {
+		return nil
+	}
+
+	// Full screen passes: no depth, no culling, filled polygons even in wireframe mode
+	var polygonMode [2]int32
+	gl.GetIntegerv(gl.POLYGON_MODE, &polygonMode[0])
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.CULL_FACE)
+	gl.BindVertexArray(pp.emptyVAO)
+	defer func() {
+		gl.BindVertexArray(0)
+		gl.ActiveTexture(gl.TEXTURE0)
+		gl.Enable(gl.DEPTH_TEST)
+		gl.PolygonMode(gl.FRONT_AND_BACK, uint32(polygonMode[0]))
+	}()
+
+	bloom := pp.settings.Bloom.Bloom()
+	if bloom.Iterations > 0 {
+		if err := pp.renderBloom(bloom.Iterations); err != nil {
+			return err
+		}
+	}
+
+	// Tone mapping, bloom and color grading, into the FXAA input or straight to the window
+	fxaa := pp.settings.FXAA.FXAA()
+	target := uint32(0)
+	if fxaa.SpanMax > 0 {
+		target = pp.toneMapped.fbo
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, target)
+	gl.Viewport(0, 0, int32(pp.scene.width), int32(pp.scene.height))
+	if err := pp.shaders.UseShader(shaderComposite); err != nil {
+		return err
+	}
+	bindTexture(0, gl.TEXTURE_2D, pp.scene.color)
+	pp.shaders.SetUniformInt(shaderComposite, "uScene", 0)
+	bindTexture(1, gl.TEXTURE_2D, pp.bloom[0].color)
+	pp.shaders.SetUniformInt(shaderComposite, "uBloom", 1)
+	pp.shaders.SetUniformBool(shaderComposite, "uBloomEnabled", bloom.Iterations > 0)
+	pp.shaders.SetUniformFloat(shaderComposite, "uBloomIntensity", pp.settings.BloomIntensity)
+	bindTexture(2, gl.TEXTURE_3D, pp.lut)
+	pp.shaders.SetUniformInt(shaderComposite, "uLUT", 2)
+	pp.shaders.SetUniformBool(shaderComposite, "uGradingEnabled", pp.lut != 0)
+	pp.shaders.SetUniformFloat(shaderComposite, "uLUTSize", float32(pp.lutSize))
+	pp.shaders.SetUniformVec3(shaderComposite, "uLUTDomainMin", pp.lutDomainMin)
+	pp.shaders.SetUniformVec3(shaderComposite, "uLUTDomainMax", pp.lutDomainMax)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+
+	if target == 0 {
+		return nil
+	}
+
+	// Edge smoothing into the window
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if err := pp.shaders.UseShader(shaderFXAA); err != nil {
+		return err
+	}
+	bindTexture(0, gl.TEXTURE_2D, pp.toneMapped.color)
+	pp.shaders.SetUniformInt(shaderFXAA, "uImage", 0)
+	pp.shaders.SetUniformVec2(shaderFXAA, "uTexelSize",
+		mgl32.Vec2{1 / float32(pp.scene.width), 1 / float32(pp.scene.height)})
+	pp.shaders.SetUniformFloat(shaderFXAA, "uSpanMax", fxaa.SpanMax)
+	pp.shaders.SetUniformFloat(shaderFXAA, "uReduceMul", fxaa.ReduceMul)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+	return nil
+}
+
+// renderBloom extracts the bright parts of the scene into bloom[0] and blurs them
+func (pp *PostProcessor) renderBloom(iterations int) error {
+	width, height := pp.bloom[0].width, pp.bloom[0].height
+	gl.Viewport(0, 0, int32(width), int32(height))
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, pp.bloom[0].fbo)
+	if err := pp.shaders.UseShader(shaderBloomBright); err != nil {
+		return err
+	}
+	bindTexture(0, gl.TEXTURE_2D, pp.scene.color)
+	pp.shaders.SetUniformInt(shaderBloomBright, "uScene", 0)
+	pp.shaders.SetUniformFloat(shaderBloomBright, "uThreshold", pp.settings.BloomThreshold)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+
+	// Separable blur: horizontal into bloom[1], vertical back into bloom[0]
+	if err := pp.shaders.UseShader(shaderBloomBlur); err != nil {
+		return err
+	}
+	pp.shaders.SetUniformInt(shaderBloomBlur, "uImage", 0)
+	directions := [2]mgl32.Vec2{{1 / float32(width), 0}, {0, 1 / float32(height)}}
+	for i := 0; i < iterations; i++ {
+		for pass, direction := range directions {
+			source, destination := pp.bloom[pass], pp.bloom[1-pass]
+			gl.BindFramebuffer(gl.FRAMEBUFFER, destination.fbo)
+			bindTexture(0, gl.TEXTURE_2D, source.color)
+			pp.shaders.SetUniformVec2(shaderBloomBlur, "uDirection", direction)
+			gl.DrawArrays(gl.TRIANGLES, 0, 3)
+		}
+	}
+	return nil
+}
+
+// createTargets allocates the render targets for a framebuffer size
+func (pp *PostProcessor) createTargets(width, height int) error {
+	pp.releaseTargets()
+
+	var err error
+	if pp.scene, err = newRenderTarget(width, height, gl.RGBA16F, gl.FLOAT, true); err != nil {
+		return fmt.Errorf("HDR scene buffer: %w", err)
+	}
+	if pp.toneMapped, err = newRenderTarget(width, height, gl.RGBA8, gl.UNSIGNED_BYTE, false); err != nil {
+		return fmt.Errorf("tone mapped buffer: %w", err)
+	}
+	if bloom := pp.settings.Bloom.Bloom(); bloom.Downscale > 0 {
+		bloomWidth, bloomHeight := max(width/bloom.Downscale, 1), max(height/bloom.Downscale, 1)
+		for i := range pp.bloom {
+			if pp.bloom[i], err = newRenderTarget(bloomWidth, bloomHeight, gl.RGBA16F, gl.FLOAT, false); err != nil {
+				return fmt.Errorf("bloom buffer: %w", err)
+			}
+		}
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+
+	logging.Debugf(logging.CategoryRender, "Post-processing buffers: %dx%d, bloom %s, FXAA %s",
+		width, height, pp.settings.Bloom, pp.settings.FXAA)
+	return nil
+}
+
+// releaseTargets frees the render targets
+func (pp *PostProcessor) releaseTargets() {
+	pp.scene.release()
+	pp.toneMapped.release()
+	for i := range pp.bloom {
+		pp.bloom[i].release()
+	}
+}
+
+// uploadLUT creates the 3D texture of a color grading table; low quality samples the nearest entry
+func (pp *PostProcessor) uploadLUT(lut *graphics.ColorLUT, quality graphics.Quality) {
+	filter := int32(gl.LINEAR)
+	if quality == graphics.QualityLow {
+		filter = gl.NEAREST
+	}
+
+	gl.GenTextures(1, &pp.lut)
+	gl.BindTexture(gl.TEXTURE_3D, pp.lut)
+	size := int32(lut.Size)
+	gl.TexImage3D(gl.TEXTURE_3D, 0, gl.RGB16F, size, size, size, 0, gl.RGB, gl.FLOAT, gl.Ptr(lut.Data))
+	gl.TexParameteri(gl.TEXTURE_3D, gl.TEXTURE_MIN_FILTER, filter)
+	gl.TexParameteri(gl.TEXTURE_3D, gl.TEXTURE_MAG_FILTER, filter)
+	for _, wrap := range []uint32{gl.TEXTURE_WRAP_S, gl.TEXTURE_WRAP_T, gl.TEXTURE_WRAP_R} {
+		gl.TexParameteri(gl.TEXTURE_3D, wrap, gl.CLAMP_TO_EDGE)
+	}
+	gl.BindTexture(gl.TEXTURE_3D, 0)
+
+	pp.lutSize = lut.Size
+	pp.lutDomainMin = mgl32.Vec3(lut.DomainMin)
+	pp.lutDomainMax = mgl32.Vec3(lut.DomainMax)
+	logging.Infof(logging.CategoryRender, "Color grading with %s (%d³)", lut.Title, lut.Size)
+}
+
+// Destroy frees all GPU resources
+func (pp *PostProcessor) Destroy() {
+	if pp == nil {
+		return
+	}
+	pp.releaseTargets()
+	if pp.lut != 0 {
+		gl.DeleteTextures(1, &pp.lut)
+		pp.lut = 0
+	}
+	if pp.emptyVAO != 0 {
+		gl.DeleteVertexArrays(1, &pp.emptyVAO)
+		pp.emptyVAO = 0
+	}
+}
+
+// newRenderTarget creates a framebuffer with a linearly filtered color texture
+// and optionally a depth and stencil buffer
+func newRenderTarget(width, height int, internalFormat int32, pixelType uint32, depth bool) (renderTarget, error) {
+	target := renderTarget{width: width, height: height}
+
+	gl.GenFramebuffers(1, &target.fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, target.fbo)
+
+	gl.GenTextures(1, &target.color)
+	gl.BindTexture(gl.TEXTURE_2D, target.color)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, internalFormat, int32(width), int32(height), 0, gl.RGBA, pixelType, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, target.color, 0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	if depth {
+		gl.GenRenderbuffers(1, &target.depth)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, target.depth)
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH24_STENCIL8, int32(width), int32(height))
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_STENCIL_ATTACHMENT, gl.RENDERBUFFER, target.depth)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+	}
+
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		target.release()
+		return renderTarget{}, fmt.Errorf("framebuffer incomplete (status 0x%x)", status)
+	}
+	return target, nil
+}
+
+// release frees the framebuffer and its attachments
+func (rt *renderTarget) release() {
+	if rt.fbo != 0 {
+		gl.DeleteFramebuffers(1, &rt.fbo)
+	}
+	if rt.color != 0 {
+		gl.DeleteTextures(1, &rt.color)
+	}
+	if rt.depth != 0 {
+		gl.DeleteRenderbuffers(1, &rt.depth)
+	}
+	*rt = renderTarget{}
+}
+
+// bindTexture binds a texture to a texture unit
+func bindTexture(unit uint32, target, texture uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	gl.BindTexture(target, texture)
+}
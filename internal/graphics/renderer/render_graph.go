@@ -0,0 +1,234 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"teraglest/internal/engine"
+)
+
+// Standard render passes, in the order the default graph runs them
+const (
+	PassShadow      = "shadow"      // Shadow maps
+	PassOpaque      = "opaque"      // Terrain, units, buildings and resources
+	PassPicking     = "picking"     // IDs of the units and buildings under the cursor, offscreen
+	PassWater       = "water"       // Water surfaces, over the opaque scene
+	PassTransparent = "transparent" // Alpha blended geometry
+	PassParticles   = "particles"   // Particle effects
+	PassUI          = "ui"          // 3D interface elements such as model previews
+	PassPostProcess = "postprocess" // Bloom, tone mapping, color grading and FXAA
+	PassDebug       = "debug"       // Debug visualizations, drawn over the scene
+	PassHUD         = "hud"         // 2D HUD sprites such as the resource bar
+	PassLabels      = "labels"      // Name tags, resource amounts and other text, drawn over everything
+)
+
+// Frame is the state a render pass draws from
+type Frame struct {
+	World    *engine.World
+	Renderer *Renderer
+	Alpha    float32 // How far the frame is from the previous tick toward the last, for placing units
+}
+
+// RenderPass is one stage of rendering a frame
+type RenderPass struct {
+	Name      string
+	DependsOn []string           // Passes that must run before this one
+	Execute   func(*Frame) error // Nil for a slot that only orders other passes
+	Disabled  bool               // Skipped, but still orders the passes depending on it
+}
+
+// RenderGraph runs render passes in an order satisfying their dependencies.
+// Passes without a dependency between them run in the order they were added.
+type RenderGraph struct {
+	passes []*RenderPass
+	order  []*RenderPass // Cached execution order, nil when passes changed
+}
+
+// NewRenderGraph creates an empty render graph
+func NewRenderGraph() *RenderGraph {
+	return &RenderGraph{}
+}
+
+// AddPass adds a pass; names must be unique
+func (g *RenderGraph) AddPass(pass RenderPass) error {
+	if pass.Name == "" {
+		return fmt.Errorf("render pass needs a name")
+	}
+	if g.find(pass.Name) != nil {
+		return fmt.Errorf("render pass %s already exists", pass.Name)
+	}
+	g.passes = append(g.passes, &pass)
+	g.order = nil
+	return nil
+}
+
+// RemovePass removes a pass; passes depending on it fail to order until they are changed too
+func (g *RenderGraph) RemovePass(name string) error {
+	for i, pass := range g.passes {
+		if pass.Name == name {
+			g.passes = append(g.passes[:i], g.passes[i+1:]...)
+			g.order = nil
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown render pass %s", name)
+}
+
+// SetPassFunc sets the function drawing a pass, e.g. to fill a standard slot
+func (g *RenderGraph) SetPassFunc(name string, execute func(*Frame) error) error {
+	pass := g.find(name)
+	if pass == nil {
+		return fmt.Errorf("unknown render pass %s", name)
+	}
+	pass.Execute = execute
+	return nil
+}
+
+// SetPassEnabled turns a pass on or off
+func (g *RenderGraph) SetPassEnabled(name string, enabled bool) error {
+	pass := g.find(name)
+	if pass == nil {
+		return fmt.Errorf("unknown render pass %s", name)
+	}
+	pass.Disabled = !enabled
+	return nil
+}
+
+// Order returns the pass names in execution order, or an error for unknown
+// dependencies and dependency cycles
+func (g *RenderGraph) Order() ([]string, error) {
+	order, err := g.resolve()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(order))
+	for i, pass := range order {
+		names[i] = pass.Name
+	}
+	return names, nil
+}
+
+// Execute runs the enabled passes in dependency order, stopping at the first error
+func (g *RenderGraph) Execute(frame *Frame) error {
+	order, err := g.resolve()
+	if err != nil {
+		return err
+	}
+	for _, pass := range order {
+		if pass.Disabled || pass.Execute == nil {
+			continue
+		}
+		if err := pass.Execute(frame); err != nil {
+			return fmt.Errorf("render pass %s: %w", pass.Name, err)
+		}
+	}
+	return nil
+}
+
+// resolve returns the cached execution order, sorting the passes if they changed
+func (g *RenderGraph) resolve() ([]*RenderPass, error) {
+	if g.order != nil {
+		return g.order, nil
+	}
+
+	for _, pass := range g.passes {
+		for _, dependency := range pass.DependsOn {
+			if g.find(dependency) == nil {
+				return nil, fmt.Errorf("render pass %s depends on unknown pass %s", pass.Name, dependency)
+			}
+		}
+	}
+
+	// Repeatedly take the first pass, in insertion order, whose dependencies have all run
+	done := make(map[string]bool, len(g.passes))
+	order := make([]*RenderPass, 0, len(g.passes))
+	for len(order) < len(g.passes) {
+		progressed := false
+		for _, pass := range g.passes {
+			if done[pass.Name] || !dependenciesDone(pass, done) {
+				continue
+			}
+			done[pass.Name] = true
+			order = append(order, pass)
+			progressed = true
+			break
+		}
+		if !progressed {
+			var blocked []string
+			for _, pass := range g.passes {
+				if !done[pass.Name] {
+					blocked = append(blocked, pass.Name)
+				}
+			}
+			return nil, fmt.Errorf("render pass dependency cycle among %s", strings.Join(blocked, ", "))
+		}
+	}
+
+	g.order = order
+	return order, nil
+}
+
+// dependenciesDone reports whether every dependency of a pass has run
+func dependenciesDone(pass *RenderPass, done map[string]bool) bool {
+	for _, dependency := range pass.DependsOn {
+		if !done[dependency] {
+			return false
+		}
+	}
+	return true
+}
+
+// find returns the pass with a name, or nil
+func (g *RenderGraph) find(name string) *RenderPass {
+	for _, pass := range g.passes {
+		if pass.Name == name {
+			return pass
+		}
+	}
+	return nil
+}
+
+// newDefaultRenderGraph creates the standard passes. Passes the renderer does
+// not draw yet are empty slots that extensions can fill or order against.
+func (r *Renderer) newDefaultRenderGraph() *RenderGraph {
+	graph := NewRenderGraph()
+	passes := []RenderPass{
+		{Name: PassShadow},
+		{Name: PassOpaque, DependsOn: []string{PassShadow}, Execute: func(frame *Frame) error {
+			return r.renderWorldObjects(frame.World, frame.Alpha)
+		}},
+		{Name: PassPicking, DependsOn: []string{PassOpaque}, Execute: func(frame *Frame) error {
+			return r.picking.Render(frame.World, r.camera, r.context.GetWidth(), r.context.GetHeight())
+		}},
+		{Name: PassWater, DependsOn: []string{PassOpaque}},
+		{Name: PassTransparent, DependsOn: []string{PassWater}},
+		{Name: PassParticles, DependsOn: []string{PassTransparent}},
+		{Name: PassUI, DependsOn: []string{PassParticles}, Execute: func(frame *Frame) error {
+			if r.sceneOverlay != nil {
+				r.sceneOverlay()
+			}
+			return r.commandMarkers.Render(frame.World, r.camera, time.Now())
+		}},
+		{Name: PassPostProcess, DependsOn: []string{PassUI}, Execute: func(frame *Frame) error {
+			return r.post.Resolve()
+		}},
+		{Name: PassDebug, DependsOn: []string{PassPostProcess}, Execute: func(frame *Frame) error {
+			return r.debugShapes.Render(r.camera)
+		}},
+		{Name: PassHUD, DependsOn: []string{PassDebug}, Execute: func(frame *Frame) error {
+			return r.renderHUD()
+		}},
+		{Name: PassLabels, DependsOn: []string{PassHUD}, Execute: func(frame *Frame) error {
+			r.queueWorldLabels(frame.World)
+			return r.text.Render(r.camera, r.context.GetWidth(), r.context.GetHeight())
+		}},
+	}
+	for _, pass := range passes {
+		// Names are distinct, so adding cannot fail
+		graph.AddPass(pass)
+	}
+	return graph
+}
@@ -1,15 +1,18 @@
+//go:build !js
+
 package renderer
 
 import (
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"teraglest/internal/data"
+	"teraglest/internal/debugdraw"
 	"teraglest/internal/engine"
 	"teraglest/internal/graphics"
-	"teraglest/pkg/formats"
+	"teraglest/internal/graphics/device"
+	"teraglest/internal/logging"
 
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
@@ -38,6 +41,7 @@ type GPUModel struct {
 type Renderer struct {
 	// Core components
 	context     *RenderContext
+	device      device.GraphicsDevice // Graphics API that shaders, text, sprites and debug shapes go through
 	assetMgr    *data.AssetManager
 	shaderMgr   *ShaderManager
 	camera      *Camera
@@ -49,6 +53,9 @@ type Renderer struct {
 	modelCache   map[string]*GPUModel   // Path -> GPU model
 	textureCache map[string]*GPUTexture // Path -> GPU texture
 
+	// GPU memory budget of the model and texture caches, evicting the least recently used
+	residency *graphics.ResidencyCache
+
 	// Placeholder rendering
 	cubeVAO     uint32 // VAO for rendering unit placeholders
 	basicShader uint32 // Basic shader for placeholder rendering
@@ -57,12 +64,64 @@ type Renderer struct {
 	frameCount    uint64
 	lastFrameTime time.Time
 	fps           float32
+	drawCallRate  int // Instanced draw calls per frame over the last stats period
 
 	// Debug settings
 	wireframe bool
 	showStats bool
+
+	// Extra 3D drawing in the UI pass, before the frame is shown (optional)
+	sceneOverlay func()
+
+	// Render passes run by RenderWorld
+	renderGraph *RenderGraph
+
+	// Instanced unit rendering
+	unitModels  map[string]*graphics.Model // "faction/unit" -> model, nil if it failed to load
+	unitBatcher *graphics.InstanceBatcher  // Visible units grouped by model and frame
+	drawCalls   int                        // Instanced draw calls in the current stats period
+
+	// Silhouettes of units hidden behind buildings and terrain
+	silhouette        SilhouetteFunc            // Units shown as silhouettes and their colors (optional)
+	silhouetteBatcher *graphics.InstanceBatcher // Those units this frame, colored by silhouette
+	silhouettesReady  bool                      // Whether the silhouette shader loaded
+
+	// Post-processing chain, nil if its shaders failed to load
+	post *PostProcessor
+
+	// Debug shapes from engine systems, nil if its shader failed to load
+	debugShapes *DebugRenderer
+
+	// Map height field streamed in chunks, nil if its shader failed to load
+	terrain *TerrainRenderer
+
+	// Scorch marks, craters and rubble on the ground, nil if its shader failed to load
+	decals *DecalRenderer
+
+	// Markers confirming the player's orders, nil if its shader failed to load
+	commandMarkers *CommandMarkerRenderer
+
+	// IDs of the objects under the cursor, nil if its shaders failed to load
+	picking *PickingRenderer
+
+	// Grass and detail meshes on grass surfaces, nil if its shader failed to load
+	details      *DetailRenderer
+	detailModels map[string]*graphics.Model // Tileset detail mesh path -> model, nil if it failed to load
+
+	// Text labels in world and screen space, nil if its shader failed to load
+	text *TextRenderer
+
+	// 2D HUD sprites, nil if its shader failed to load, and the HUD drawing them (optional)
+	sprites *SpriteRenderer
+	hud     func(*HUDCanvas)
+
+	// Portrait icons rendered from models, nil without sprites
+	icons *IconRenderer
 }
 
+// instancedShader is the shader program drawing instance batches
+const instancedShader = "instanced_model"
+
 // NewRenderer creates a new renderer instance
 func NewRenderer(assetMgr *data.AssetManager, title string, width, height int) (*Renderer, error) {
 	// Create OpenGL context
@@ -71,8 +130,9 @@ func NewRenderer(assetMgr *data.AssetManager, title string, width, height int) (
 		return nil, fmt.Errorf("failed to create render context: %w", err)
 	}
 
-	// Initialize shader manager
-	shaderMgr := NewShaderManager()
+	// Draw through OpenGL and compile shaders on it
+	dev := device.NewGL()
+	shaderMgr := NewShaderManager(dev)
 
 	// Create camera for 3D rendering
 	camera := NewRTSCamera(width, height, 64.0) // Default map size of 64
@@ -86,37 +146,113 @@ func NewRenderer(assetMgr *data.AssetManager, title string, width, height int) (
 	// Create material manager for advanced material support
 	materialMgr := graphics.NewMaterialManager()
 
+	// Keep models and textures within the default budget until settings are applied
+	residency := graphics.NewResidencyCache(graphics.DefaultGraphicsSettings().TextureBudget())
+	modelMgr.SetResidency(residency)
+
 	renderer := &Renderer{
-		context:       context,
-		assetMgr:      assetMgr,
-		shaderMgr:     shaderMgr,
-		camera:        camera,
-		modelMgr:      modelMgr,
-		lightMgr:      lightMgr,
-		materialMgr:   materialMgr,
-		modelCache:    make(map[string]*GPUModel),
-		textureCache:  make(map[string]*GPUTexture),
-		lastFrameTime: time.Now(),
-		wireframe:     false,
-		showStats:     true,
+		context:           context,
+		device:            dev,
+		assetMgr:          assetMgr,
+		shaderMgr:         shaderMgr,
+		camera:            camera,
+		modelMgr:          modelMgr,
+		lightMgr:          lightMgr,
+		materialMgr:       materialMgr,
+		modelCache:        make(map[string]*GPUModel),
+		textureCache:      make(map[string]*GPUTexture),
+		residency:         residency,
+		unitModels:        make(map[string]*graphics.Model),
+		detailModels:      make(map[string]*graphics.Model),
+		unitBatcher:       graphics.NewInstanceBatcher(),
+		silhouetteBatcher: graphics.NewInstanceBatcher(),
+		lastFrameTime:     time.Now(),
+		wireframe:         false,
+		showStats:         false,
 	}
 
+	renderer.renderGraph = renderer.newDefaultRenderGraph()
+
 	// Initialize default lighting
 	err = renderer.setupDefaultLighting()
 	if err != nil {
-		log.Printf("Warning: Failed to setup default lighting: %v", err)
+		logging.Warnf(logging.CategoryRender, "Failed to setup default lighting: %v", err)
 	}
 
 	// Load advanced shaders
 	err = renderer.loadAdvancedShaders()
 	if err != nil {
-		log.Printf("Warning: Failed to load advanced shaders: %v", err)
+		logging.Warnf(logging.CategoryRender, "Failed to load advanced shaders: %v", err)
+	}
+
+	// Show hidden units as silhouettes once SetSilhouettes chooses which
+	err = renderer.loadSilhouetteShader()
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "Unit silhouettes unavailable: %v", err)
+	}
+
+	// Load the post-processing chain; it stays off until graphics settings are applied
+	renderer.post, err = NewPostProcessor(shaderMgr)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "Post-processing unavailable: %v", err)
+	}
+
+	// Draw name tags, resource amounts and other labels
+	renderer.text, err = NewTextRenderer(shaderMgr)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "Text rendering unavailable: %v", err)
+	}
+
+	// Draw the HUD's sprites without ImGui
+	renderer.sprites, err = NewSpriteRenderer(shaderMgr, assetMgr)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "HUD sprites unavailable: %v", err)
+	} else {
+		renderer.sprites.textures.SetResidency(residency)
+		renderer.icons = NewIconRenderer(shaderMgr, modelMgr, lightMgr, assetMgr, renderer.sprites)
+	}
+
+	// Build and stream the terrain chunks in the background
+	renderer.terrain, err = NewTerrainRenderer(shaderMgr)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "Terrain unavailable: %v", err)
+	}
+
+	// Draw the marks battles leave on the ground
+	renderer.decals, err = NewDecalRenderer(shaderMgr)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "Decals unavailable: %v", err)
+	}
+
+	// Mark where the player's orders go
+	renderer.commandMarkers, err = NewCommandMarkerRenderer(shaderMgr)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "Command markers unavailable: %v", err)
+	}
+
+	// Pick units and buildings by what is drawn under the cursor
+	renderer.picking, err = NewPickingRenderer(shaderMgr)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "GPU picking unavailable: %v", err)
+	}
+
+	// Grow grass on the map at the default quality until settings are applied
+	renderer.details, err = NewDetailRenderer(shaderMgr)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "Grass and detail meshes unavailable: %v", err)
+	}
+	renderer.details.SetQuality(graphics.DefaultGraphicsSettings().Detail)
+
+	// Draw debug shapes published by engine systems
+	renderer.debugShapes, err = NewDebugRenderer(shaderMgr, debugdraw.Default(), renderer.text)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "Debug drawing unavailable: %v", err)
 	}
 
 	// Set up basic input callbacks (can be enhanced later with game input handler)
 	renderer.setupInputCallbacks()
 
-	log.Printf("Renderer initialized: %dx%d", width, height)
+	logging.Infof(logging.CategoryRender, "Renderer initialized: %dx%d", width, height)
 	return renderer, nil
 }
 
@@ -134,14 +270,18 @@ func (r *Renderer) setupInputCallbacks() {
 				r.wireframe = !r.wireframe
 				if r.wireframe {
 					r.context.EnableWireframe()
-					log.Println("Wireframe mode enabled")
+					logging.Infof(logging.CategoryRender, "Wireframe mode enabled")
 				} else {
 					r.context.DisableWireframe()
-					log.Println("Wireframe mode disabled")
+					logging.Infof(logging.CategoryRender, "Wireframe mode disabled")
 				}
 			case glfw.KeyF2:
 				r.showStats = !r.showStats
-				log.Printf("Stats display: %v", r.showStats)
+				logging.Infof(logging.CategoryRender, "Stats display: %v", r.showStats)
+			case glfw.KeyF3:
+				r.togglePostProcessing()
+			case glfw.KeyF4:
+				r.toggleTileOverlay()
 			}
 		}
 	})
@@ -173,24 +313,28 @@ func (r *Renderer) SetupGameInputCallbacks(inputHandler interface{}) {
 		// Setup keyboard callback (enhanced version)
 		window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
 			// Handle basic renderer controls first
+			// ESC is forwarded so the game can show its pause menu
 			if action == glfw.Press {
 				switch key {
-				case glfw.KeyEscape:
-					w.SetShouldClose(true)
-					return
 				case glfw.KeyF1:
 					r.wireframe = !r.wireframe
 					if r.wireframe {
 						r.context.EnableWireframe()
-						log.Println("Wireframe mode enabled")
+						logging.Infof(logging.CategoryRender, "Wireframe mode enabled")
 					} else {
 						r.context.DisableWireframe()
-						log.Println("Wireframe mode disabled")
+						logging.Infof(logging.CategoryRender, "Wireframe mode disabled")
 					}
 					return
 				case glfw.KeyF2:
 					r.showStats = !r.showStats
-					log.Printf("Stats display: %v", r.showStats)
+					logging.Infof(logging.CategoryRender, "Stats display: %v", r.showStats)
+					return
+				case glfw.KeyF3:
+					r.togglePostProcessing()
+					return
+				case glfw.KeyF4:
+					r.toggleTileOverlay()
 					return
 				}
 			}
@@ -199,9 +343,9 @@ func (r *Renderer) SetupGameInputCallbacks(inputHandler interface{}) {
 			handler.HandleKeyboard(w, key, scancode, action, mods)
 		})
 
-		log.Println("Game input callbacks configured")
+		logging.Infof(logging.CategoryRender, "Game input callbacks configured")
 	} else {
-		log.Println("Warning: Invalid input handler provided to SetupGameInputCallbacks")
+		logging.Warnf(logging.CategoryRender, "Invalid input handler provided to SetupGameInputCallbacks")
 	}
 }
 
@@ -215,6 +359,11 @@ func (r *Renderer) GetContext() *RenderContext {
 	return r.context
 }
 
+// Device returns the graphics device the renderer draws with
+func (r *Renderer) Device() device.GraphicsDevice {
+	return r.device
+}
+
 // GetDisplaySize returns the current display dimensions
 func (r *Renderer) GetDisplaySize() (int, int) {
 	return r.context.GetWidth(), r.context.GetHeight()
@@ -233,9 +382,15 @@ func (r *Renderer) updateStats() {
 	r.lastFrameTime = now
 
 	// Log stats every 60 frames
-	if r.showStats && r.frameCount%60 == 0 {
-		log.Printf("Frame %d: FPS=%.1f, Models cached=%d, Textures cached=%d",
-			r.frameCount, r.fps, len(r.modelCache), len(r.textureCache))
+	if r.frameCount%60 == 0 {
+		r.drawCallRate = r.drawCalls / 60
+		r.drawCalls = 0
+		if r.showStats {
+			residency := r.residency.Stats()
+			logging.Debugf(logging.CategoryRender, "Frame %d: FPS=%.1f, Models resident=%d, Textures resident=%d, GPU memory=%.1f/%.0f MB, Units=%d in %d instanced draws/frame",
+				r.frameCount, r.fps, residency.Models, residency.Textures, megabytes(residency.Bytes), megabytes(residency.Budget),
+				r.unitBatcher.InstanceCount(), r.drawCallRate)
+		}
 	}
 }
 
@@ -271,6 +426,13 @@ func (r *Renderer) RenderWorld(world *engine.World) error {
 	// Update rendering statistics
 	r.updateStats()
 
+	// Resources left unused last frame may be evicted from now on
+	r.residency.BeginFrame()
+
+	// Draw the scene into the HDR buffer when post-processing is on
+	hdr := r.post.Begin(r.context.GetWidth(), r.context.GetHeight())
+	r.setHDROutput(hdr)
+
 	// Clear the screen
 	r.context.Clear()
 
@@ -280,10 +442,10 @@ func (r *Renderer) RenderWorld(world *engine.World) error {
 		return fmt.Errorf("failed to setup 3D rendering: %w", err)
 	}
 
-	// Render world objects
-	err = r.renderWorldObjects(world)
+	// Run the render passes
+	err = r.renderGraph.Execute(&Frame{World: world, Renderer: r, Alpha: world.TickAlpha(time.Now())})
 	if err != nil {
-		return fmt.Errorf("failed to render world objects: %w", err)
+		return fmt.Errorf("failed to render world: %w", err)
 	}
 
 	// For now, log that we're rendering a world
@@ -293,7 +455,7 @@ func (r *Renderer) RenderWorld(world *engine.World) error {
 			allUnits += len(world.ObjectManager.GetUnitsForPlayer(player.ID))
 		}
 
-		log.Printf("Rendering world: %dx%d, %d players, %d units, %d resources",
+		logging.Debugf(logging.CategoryRender, "Rendering world: %dx%d, %d players, %d units, %d resources",
 			world.Width, world.Height,
 			len(world.GetAllPlayers()),
 			allUnits,
@@ -309,9 +471,10 @@ func (r *Renderer) RenderWorld(world *engine.World) error {
 
 // setup3DRendering configures the rendering pipeline for 3D rendering
 func (r *Renderer) setup3DRendering() error {
-	// Enable depth testing for proper 3D rendering
+	// Enable depth testing for proper 3D rendering; level fragments pass too,
+	// as for device render states, so redrawn geometry matches itself
 	gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.LESS)
+	gl.DepthFunc(gl.LEQUAL)
 
 	// Enable back-face culling for performance
 	gl.Enable(gl.CULL_FACE)
@@ -360,16 +523,35 @@ func (r *Renderer) setup3DRendering() error {
 	return nil
 }
 
-// renderWorldObjects renders all objects in the game world
-func (r *Renderer) renderWorldObjects(world *engine.World) error {
+// renderWorldObjects renders all objects in the game world, placing units a
+// share alpha of the way from the previous tick to the last
+func (r *Renderer) renderWorldObjects(world *engine.World, alpha float32) error {
 	// 1. Render terrain (simplified grid for now)
 	err := r.renderTerrain(world)
 	if err != nil {
 		return fmt.Errorf("failed to render terrain: %w", err)
 	}
 
+	// Scorch marks, craters and rubble lie on the ground under everything else
+	err = r.decals.Render(world, r.camera)
+	if err != nil {
+		return fmt.Errorf("failed to render decals: %w", err)
+	}
+
+	// Grass and detail meshes grow on the ground, over the decals
+	err = r.details.Render(world, r.camera, r.loadDetailModel)
+	if err != nil {
+		return fmt.Errorf("failed to render grass: %w", err)
+	}
+	if r.details != nil {
+		err = r.drawInstanceBatches(r.details.Meshes())
+		if err != nil {
+			return fmt.Errorf("failed to render detail meshes: %w", err)
+		}
+	}
+
 	// 2. Render all units from the game world
-	err = r.renderUnits(world)
+	err = r.renderUnits(world, alpha)
 	if err != nil {
 		return fmt.Errorf("failed to render units: %w", err)
 	}
@@ -392,6 +574,12 @@ func (r *Renderer) renderWorldObjects(world *engine.World) error {
 		return fmt.Errorf("failed to render test models: %w", err)
 	}
 
+	// 6. Show the units hidden behind what was drawn above as silhouettes
+	err = r.renderSilhouettes()
+	if err != nil {
+		return fmt.Errorf("failed to render silhouettes: %w", err)
+	}
+
 	return nil
 }
 
@@ -444,7 +632,7 @@ func (r *Renderer) initializeCubeGeometry() {
 	// Unbind VAO
 	gl.BindVertexArray(0)
 
-	log.Printf("✅ Cube geometry initialized for unit placeholders")
+	logging.Debugf(logging.CategoryRender, "✅ Cube geometry initialized for unit placeholders")
 }
 
 // initializeBasicShader creates a simple shader for rendering colored placeholders
@@ -501,7 +689,7 @@ void main() {
 		return fmt.Errorf("program linking failed: %v", log)
 	}
 
-	log.Printf("✅ Basic shader initialized for unit placeholders")
+	logging.Debugf(logging.CategoryRender, "✅ Basic shader initialized for unit placeholders")
 	return nil
 }
 
@@ -555,6 +743,57 @@ func (r *Renderer) CreateTestScene() error {
 func (r *Renderer) ResizeViewport(width, height int) {
 	gl.Viewport(0, 0, int32(width), int32(height))
 	r.camera.SetAspectRatio(width, height)
+	// Post-processing buffers follow the framebuffer size on the next Begin
+}
+
+// ApplyGraphicsSettings configures post-processing, the GPU memory budget and picking from graphics settings
+func (r *Renderer) ApplyGraphicsSettings(settings *graphics.GraphicsSettings) error {
+	r.residency.SetBudget(settings.TextureBudget())
+	r.details.SetQuality(settings.Detail)
+	r.picking.SetEnabled(settings.GPUPicking)
+	if r.post == nil {
+		return fmt.Errorf("post-processing is not available")
+	}
+	if err := r.post.Apply(settings); err != nil {
+		return fmt.Errorf("failed to apply graphics settings: %w", err)
+	}
+	logging.Infof(logging.CategoryRender, "Post-processing %v: FXAA %s, bloom %s, color grading %s",
+		settings.PostProcessing, settings.FXAA, settings.Bloom, r.post.Settings().ColorGrading)
+	return nil
+}
+
+// PostProcessingEnabled returns whether the post-processing chain is on
+func (r *Renderer) PostProcessingEnabled() bool {
+	return r.post.Enabled()
+}
+
+// togglePostProcessing switches the post-processing chain on or off
+func (r *Renderer) togglePostProcessing() {
+	if r.post == nil {
+		logging.Warnf(logging.CategoryRender, "Post-processing is not available")
+		return
+	}
+	r.post.SetEnabled(!r.post.Enabled())
+	logging.Infof(logging.CategoryRender, "Post-processing: %v", r.post.Enabled())
+}
+
+// toggleTileOverlay shows or hides the walkable and occupancy grids over the terrain
+func (r *Renderer) toggleTileOverlay() {
+	dd := debugdraw.Default()
+	show := !(dd.Enabled(debugdraw.CategoryWalkable) && dd.Enabled(debugdraw.CategoryOccupancy))
+	dd.SetEnabled(debugdraw.CategoryWalkable, show)
+	dd.SetEnabled(debugdraw.CategoryOccupancy, show)
+	logging.Infof(logging.CategoryRender, "Tile overlay: %v", show)
+}
+
+// setHDROutput tells the model shaders whether they draw into the HDR buffer,
+// leaving tone mapping and gamma to the post-processing chain
+func (r *Renderer) setHDROutput(hdr bool) {
+	for _, shader := range []string{"advanced_model", instancedShader} {
+		if r.shaderMgr.UseShader(shader) == nil {
+			r.shaderMgr.SetUniformBool(shader, "uHDROutput", hdr)
+		}
+	}
 }
 
 // RenderModel renders a 3D model with the given transformation
@@ -599,6 +838,34 @@ func (r *Renderer) RenderModelAt(model *graphics.Model, x, y, z float32) error {
 	return err
 }
 
+// RenderGraph returns the render passes RenderWorld runs each frame, for
+// adding passes or filling the standard ones
+func (r *Renderer) RenderGraph() *RenderGraph {
+	return r.renderGraph
+}
+
+// SetSceneOverlay sets a function drawing into the 3D scene in the UI pass
+// each frame, e.g. model previews
+func (r *Renderer) SetSceneOverlay(draw func()) {
+	r.sceneOverlay = draw
+}
+
+// RenderModelTurntable renders a model at a position turned by yaw radians
+// around the vertical axis, e.g. for rotating previews
+func (r *Renderer) RenderModelTurntable(model *graphics.Model, x, y, z, yaw float32) error {
+	if model == nil {
+		return fmt.Errorf("model is nil")
+	}
+
+	originalTransform := model.GetModelMatrix()
+	model.Transform = mgl32.Translate3D(x, y, z).Mul4(mgl32.HomogRotate3DY(yaw))
+
+	err := r.RenderModel(model)
+
+	model.Transform = originalTransform
+	return err
+}
+
 // setupDefaultLighting initializes the default lighting configuration
 func (r *Renderer) setupDefaultLighting() error {
 	// Create default lighting setup
@@ -607,7 +874,7 @@ func (r *Renderer) setupDefaultLighting() error {
 		return fmt.Errorf("failed to create default lighting: %w", err)
 	}
 
-	log.Println("Default lighting setup completed")
+	logging.Infof(logging.CategoryRender, "Default lighting setup completed")
 	return nil
 }
 
@@ -623,6 +890,16 @@ func (r *Renderer) loadAdvancedShaders() error {
 		return fmt.Errorf("failed to load advanced model shader: %w", err)
 	}
 
+	// Load instanced model shader, sharing the advanced model lighting
+	err = r.shaderMgr.LoadShader(
+		instancedShader,
+		"internal/graphics/shaders/instanced_model.vert",
+		"internal/graphics/shaders/advanced_model.frag",
+	)
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "Failed to load instanced shader, drawing units one by one: %v", err)
+	}
+
 	// Load normal mapped material shader
 	err = r.shaderMgr.LoadShader(
 		"normal_mapped_material",
@@ -630,7 +907,7 @@ func (r *Renderer) loadAdvancedShaders() error {
 		"internal/graphics/shaders/normal_mapped_material.frag",
 	)
 	if err != nil {
-		log.Printf("Warning: Failed to load normal mapped shader: %v", err)
+		logging.Warnf(logging.CategoryRender, "Failed to load normal mapped shader: %v", err)
 	}
 
 	// Set up material shader mappings
@@ -639,7 +916,7 @@ func (r *Renderer) loadAdvancedShaders() error {
 	r.materialMgr.SetShaderMapping(graphics.NormalMappedMaterial, "normal_mapped_material")
 	r.materialMgr.SetShaderMapping(graphics.EmissiveMaterial, "advanced_model")
 
-	log.Println("Advanced shaders and materials loaded successfully")
+	logging.Infof(logging.CategoryRender, "Advanced shaders and materials loaded successfully")
 	return nil
 }
 
@@ -681,7 +958,7 @@ func (r *Renderer) LoadTexture(texturePath string) (*GPUTexture, error) {
 	// Cache the texture
 	r.textureCache[texturePath] = gpuTexture
 
-	log.Printf("Loaded texture: %s (ID=%d)", texturePath, textureID)
+	logging.Debugf(logging.CategoryRender, "Loaded texture: %s (ID=%d)", texturePath, textureID)
 	return gpuTexture, nil
 }
 
@@ -716,7 +993,7 @@ func (r *Renderer) LoadModel(modelPath string) (*GPUModel, error) {
 	// Cache the model
 	r.modelCache[modelPath] = gpuModel
 
-	log.Printf("Loaded model: %s (VAO=%d, vertices=%d, triangles=%d)",
+	logging.Debugf(logging.CategoryRender, "Loaded model: %s (VAO=%d, vertices=%d, triangles=%d)",
 		modelPath, vao, g3dModel.GetTotalVertexCount(), g3dModel.GetTotalTriangleCount())
 	return gpuModel, nil
 }
@@ -731,47 +1008,182 @@ func (r *Renderer) GetFrameCount() uint64 {
 	return r.frameCount
 }
 
-// renderTerrain renders the game world terrain
+// RenderStats are the figures shown by the performance HUD
+type RenderStats struct {
+	FPS       float32
+	DrawCalls int // Instanced draw calls per frame
+	Instances int // Units drawn by instancing in the last frame
+	Residency graphics.ResidencyStats
+	Terrain   TerrainStats
+}
+
+// Stats returns the current rendering statistics
+func (r *Renderer) Stats() RenderStats {
+	return RenderStats{
+		FPS:       r.fps,
+		DrawCalls: r.drawCallRate,
+		Instances: r.unitBatcher.InstanceCount(),
+		Residency: r.residency.Stats(),
+		Terrain:   r.terrain.Stats(),
+	}
+}
+
+// StatsVisible returns whether the performance HUD is on, toggled with F2
+func (r *Renderer) StatsVisible() bool {
+	return r.showStats
+}
+
+// megabytes converts a byte count for display
+func megabytes(bytes int64) float64 {
+	return float64(bytes) / (1 << 20)
+}
+
+// renderTerrain renders the game world terrain; on large maps only the
+// chunks around the camera are resident
 func (r *Renderer) renderTerrain(world *engine.World) error {
-	// For now, render a simple grid to represent the game world bounds
-	// TODO: Implement proper heightmap-based terrain rendering
+	return r.terrain.Render(world, r.camera)
+}
 
-	// Skip terrain rendering if world dimensions are too large to avoid performance issues
-	if world.Width > 100 || world.Height > 100 {
-		return nil
+// unitsToDraw returns the units near enough the camera to draw: every unit
+// on small maps, those in the streamed terrain chunks on large ones
+func (r *Renderer) unitsToDraw(world *engine.World) []*engine.GameUnit {
+	if from, to, streaming := streamedChunks(world, r.camera); streaming {
+		return world.UnitsInChunks(from, to)
+	}
+	var units []*engine.GameUnit
+	for _, player := range world.GetAllPlayers() {
+		for _, unit := range world.ObjectManager.GetUnitsForPlayer(player.ID) {
+			units = append(units, unit)
+		}
 	}
+	return units
+}
 
-	// Simple grid visualization using lines (placeholder implementation)
-	// In a full implementation, this would render textured quads or heightmap meshes
+// visibleUnits drops the units sheltering inside buildings, which are not drawn
+func visibleUnits(units []*engine.GameUnit) []*engine.GameUnit {
+	shown := make([]*engine.GameUnit, 0, len(units))
+	for _, unit := range units {
+		if !unit.IsGarrisoned() {
+			shown = append(shown, unit)
+		}
+	}
+	return shown
+}
 
-	return nil // Terrain rendering placeholder
+// buildingsToDraw returns the buildings near enough the camera to draw, as
+// unitsToDraw does units
+func (r *Renderer) buildingsToDraw(world *engine.World) []*engine.GameBuilding {
+	if from, to, streaming := streamedChunks(world, r.camera); streaming {
+		return world.BuildingsInChunks(from, to)
+	}
+	var buildings []*engine.GameBuilding
+	for _, player := range world.GetAllPlayers() {
+		for _, building := range world.ObjectManager.GetBuildingsForPlayer(player.ID) {
+			buildings = append(buildings, building)
+		}
+	}
+	return buildings
 }
 
-// renderUnits renders all units from the game world
-func (r *Renderer) renderUnits(world *engine.World) error {
+// renderUnits renders the units near the camera, drawing the units that
+// share a model and animation frame with one instanced draw call. Units are
+// placed between their transforms of the last two ticks, so they move
+// smoothly at any simulation rate.
+func (r *Renderer) renderUnits(world *engine.World, alpha float32) error {
+	r.unitBatcher.Reset()
+	r.silhouetteBatcher.Reset()
+	r.picking.begin()
 	allPlayers := world.GetAllPlayers()
 
-	for _, player := range allPlayers {
-		units := world.ObjectManager.GetUnitsForPlayer(player.ID)
+	for _, unit := range visibleUnits(r.unitsToDraw(world)) {
+		player := allPlayers[unit.PlayerID]
+		// Skip dead units and units of players who left
+		if unit.Health <= 0 || player == nil {
+			continue
+		}
+
+		pos, heading := world.InterpolatedTransform(unit, alpha)
+		model, err := r.loadUnitModel(player.FactionName, unit.UnitType)
+		if err != nil {
+			// Units are ALWAYS visible, even without proper models
+			if err := r.renderUnitPlaceholder(unit, pos); err != nil {
+				logging.Warnf(logging.CategoryRender, "Failed to render unit %d: %v", unit.ID, err)
+			}
+			size := float32(unit.Footprint())
+			r.picking.addBox(engine.Vector3{X: pos.X, Y: pos.Y - 0.5, Z: pos.Z}, mgl32.Vec3{size, 1, size}, PickUnit, unit.ID)
+			continue
+		}
+
+		// The frame is sampled from the animation the simulation advances
+		frame := unit.GetAnimation().Frame(model.FrameCount)
 
-		for _, unit := range units {
-			// Skip dead units
-			if unit.Health <= 0 {
-				continue
+		transform := mgl32.Translate3D(float32(pos.X), float32(pos.Y), float32(pos.Z)).
+			Mul4(mgl32.HomogRotate3DY(heading))
+		r.unitBatcher.Add(model, frame, graphics.Instance{
+			Transform: transform,
+			TeamColor: graphics.TeamColor(unit.PlayerID),
+		})
+		r.picking.addUnit(model, frame, transform, unit.ID)
+		if r.silhouette != nil {
+			if color, shown := r.silhouette(unit); shown {
+				r.silhouetteBatcher.Add(model, frame, graphics.Instance{Transform: transform, TeamColor: color})
 			}
+		}
+	}
+
+	return r.drawInstanceBatches(r.unitBatcher)
+}
+
+// drawInstanceBatches draws each batch with one instanced draw call, or one
+// draw per instance if the instanced shader is not available
+func (r *Renderer) drawInstanceBatches(batcher *graphics.InstanceBatcher) error {
+	batches := batcher.Batches()
+	if len(batches) == 0 {
+		return nil
+	}
 
-			err := r.renderUnitWithFaction(unit, player.FactionName)
-			if err != nil {
-				// Log error but continue rendering other units
-				log.Printf("Warning: Failed to render unit %d: %v", unit.ID, err)
-				continue
+	if err := r.useInstancedShader(); err != nil {
+		for _, batch := range batches {
+			for _, instance := range batch.Instances {
+				model := batch.Key.Model
+				model.TeamColor = instance.TeamColor
+				original := model.Transform
+				model.Transform = instance.Transform
+				err := r.RenderModel(model)
+				model.Transform = original
+				if err != nil {
+					return err
+				}
 			}
 		}
+		return nil
 	}
 
+	for _, batch := range batches {
+		err := batch.Key.Model.RenderInstanced(instancedShader, r.shaderMgr, batch.Instances)
+		if err != nil {
+			return fmt.Errorf("failed to render %d instances of %s: %w", len(batch.Instances), batch.Key.Model.Name, err)
+		}
+		r.drawCalls++
+	}
 	return nil
 }
 
+// useInstancedShader activates the instanced model shader with the camera and lighting uniforms
+func (r *Renderer) useInstancedShader() error {
+	if err := r.shaderMgr.UseShader(instancedShader); err != nil {
+		return err
+	}
+	if err := r.shaderMgr.SetUniformMat4(instancedShader, "uView", r.camera.GetViewMatrix()); err != nil {
+		return err
+	}
+	if err := r.shaderMgr.SetUniformMat4(instancedShader, "uProjection", r.camera.GetProjectionMatrix()); err != nil {
+		return err
+	}
+	r.shaderMgr.SetUniformVec3(instancedShader, "uViewPosition", r.camera.Position)
+	return r.lightMgr.UpdateShaderUniforms(r.shaderMgr, instancedShader)
+}
+
 // renderUnit renders a single game unit (legacy method, use renderUnitWithFaction)
 func (r *Renderer) renderUnit(unit *engine.GameUnit) error {
 	return r.renderUnitWithFaction(unit, "magic") // fallback to magic for backward compatibility
@@ -779,71 +1191,108 @@ func (r *Renderer) renderUnit(unit *engine.GameUnit) error {
 
 // renderUnitWithFaction renders a single game unit using the correct faction
 func (r *Renderer) renderUnitWithFaction(unit *engine.GameUnit, faction string) error {
-	// Get unit position from game state
 	pos := unit.GetPosition()
+	model, err := r.loadUnitModel(faction, unit.UnitType)
+	if err != nil {
+		return r.renderUnitPlaceholder(unit, pos)
+	}
 
-	// Load G3D model using the CORRECT faction instead of hardcoding "magic"
-	// Try multiple naming patterns for better compatibility
-	var g3dModel *formats.G3DModel
-	var err error
+	model.TeamColor = graphics.TeamColor(unit.PlayerID)
+	err = r.RenderModelAt(model, float32(pos.X), float32(pos.Y), float32(pos.Z))
+	if err != nil {
+		// If model rendering fails, fallback to placeholder
+		logging.Warnf(logging.CategoryRender, "❌ RENDER FAILED: OpenGL rendering failed for unit %d (%s): %v", unit.ID, unit.UnitType, err)
+		return r.renderUnitPlaceholder(unit, pos)
+	}
+	return nil
+}
 
-	// Pattern 1: Try with _standing suffix
-	modelPath := fmt.Sprintf("factions/%s/units/%s/models/%s_standing.g3d", faction, unit.UnitType, unit.UnitType)
-	log.Printf("🔍 DEBUG: Attempting to load model: %s for unit %s (faction: %s)", modelPath, unit.UnitType, faction)
-	g3dModel, err = r.assetMgr.LoadG3DModel(modelPath)
+// loadUnitModel returns the GPU model of a unit type, loading and converting
+// its G3D file on first use. Failures are cached too, so units without a
+// model do not retry the load every frame.
+func (r *Renderer) loadUnitModel(faction, unitType string) (*graphics.Model, error) {
+	key := faction + "/" + unitType
+	if model, cached := r.unitModels[key]; cached {
+		if model == nil {
+			return nil, fmt.Errorf("no model for unit %s", key)
+		}
+		r.residency.Touch(unitModelKey(key))
+		return model, nil
+	}
 
+	// Try multiple naming patterns for better compatibility
+	// Pattern 1: Try with _standing suffix
+	modelPath := fmt.Sprintf("factions/%s/units/%s/models/%s_standing.g3d", faction, unitType, unitType)
+	logging.Debugf(logging.CategoryRender, "🔍 Attempting to load model: %s for unit %s (faction: %s)", modelPath, unitType, faction)
+	g3dModel, err := r.assetMgr.LoadG3DModel(modelPath)
 	if err != nil {
 		// Pattern 2: Fallback - try without _standing suffix
-		modelPath = fmt.Sprintf("factions/%s/units/%s/models/%s.g3d", faction, unit.UnitType, unit.UnitType)
-		log.Printf("🔄 Fallback: Attempting model without _standing: %s", modelPath)
+		modelPath = fmt.Sprintf("factions/%s/units/%s/models/%s.g3d", faction, unitType, unitType)
+		logging.Debugf(logging.CategoryRender, "🔄 Fallback: Attempting model without _standing: %s", modelPath)
 		g3dModel, err = r.assetMgr.LoadG3DModel(modelPath)
-
-		if err != nil {
-			// Model loading failed with both patterns - render placeholder
-			log.Printf("❌ BOTH MODEL PATTERNS FAILED for unit %s:", unit.UnitType)
-			log.Printf("   Pattern 1 error: %v", err)
-			log.Printf("   Pattern 2 error: %v", err)
-			log.Printf("   Rendering placeholder at (%.1f, %.1f, %.1f)", pos.X, pos.Y, pos.Z)
-
-			// Use the terrain rendering to draw a simple colored indicator
-			// This ensures units are ALWAYS visible even without proper models
-			return r.renderUnitPlaceholder(unit, pos)
-		} else {
-			log.Printf("✅ SUCCESS: Loaded model with fallback pattern: %s", modelPath)
-		}
-	} else {
-		log.Printf("✅ SUCCESS: Loaded model with primary pattern: %s", modelPath)
+	}
+	if err != nil {
+		logging.Warnf(logging.CategoryRender, "❌ BOTH MODEL PATTERNS FAILED for unit %s: %v", unitType, err)
+		r.unitModels[key] = nil
+		return nil, err
 	}
 
-	// Convert G3DModel to our internal Model format for rendering (using ModelManager's logic)
-	log.Printf("🔄 Converting G3D model to internal format for unit %s...", unit.UnitType)
 	model, err := graphics.NewModelFromG3D(g3dModel)
 	if err != nil {
-		log.Printf("❌ CONVERSION FAILED: G3D to internal model conversion failed for unit %s: %v", unit.UnitType, err)
-		return r.renderUnitPlaceholder(unit, pos)
+		logging.Warnf(logging.CategoryRender, "❌ CONVERSION FAILED: G3D to internal model conversion failed for unit %s: %v", unitType, err)
+		r.unitModels[key] = nil
+		return nil, err
 	}
-	log.Printf("✅ CONVERSION SUCCESS: G3D model converted successfully for unit %s", unit.UnitType)
+	logging.Debugf(logging.CategoryRender, "✅ SUCCESS: Loaded model %s for unit %s", modelPath, unitType)
+
+	r.unitModels[key] = model
+	r.residency.Add(unitModelKey(key), graphics.ResourceModel, graphics.ModelBytes(model), func() {
+		logging.Debugf(logging.CategoryRender, "Evicted model of unit %s", key)
+		model.Cleanup()
+		delete(r.unitModels, key)
+	})
+	return model, nil
+}
 
-	// Create transformation matrix for unit position
-	// TODO: Add rotation based on unit facing direction
-	// TODO: Add animation state based on unit.State (moving, attacking, etc.)
+// unitModelKey identifies a unit model in the residency cache
+type unitModelKey string
 
-	log.Printf("🎨 About to render model for unit %s at position (%.1f, %.1f, %.1f)...", unit.UnitType, pos.X, pos.Y, pos.Z)
-	err = r.RenderModel(model)
+// loadDetailModel returns the GPU model of a tileset detail mesh, loading it
+// on first use; failures are cached as nil so they are not retried
+func (r *Renderer) loadDetailModel(path string) *graphics.Model {
+	if model, cached := r.detailModels[path]; cached {
+		if model != nil {
+			r.residency.Touch(detailModelKey(path))
+		}
+		return model
+	}
+
+	g3dModel, err := r.assetMgr.LoadG3DModel(path)
+	var model *graphics.Model
+	if err == nil {
+		model, err = graphics.NewModelFromG3D(g3dModel)
+	}
 	if err != nil {
-		// If model rendering fails, fallback to placeholder
-		log.Printf("❌ RENDER FAILED: OpenGL rendering failed for unit %d (%s): %v", unit.ID, unit.UnitType, err)
-		return r.renderUnitPlaceholder(unit, pos)
+		logging.Warnf(logging.CategoryRender, "Failed to load detail mesh %s: %v", path, err)
+		r.detailModels[path] = nil
+		return nil
 	}
-	log.Printf("✅ RENDER SUCCESS: Model rendered successfully for unit %s", unit.UnitType)
 
-	return nil
+	r.detailModels[path] = model
+	r.residency.Add(detailModelKey(path), graphics.ResourceModel, graphics.ModelBytes(model), func() {
+		model.Cleanup()
+		delete(r.detailModels, path)
+	})
+	return model
 }
 
+// detailModelKey identifies a tileset detail mesh in the residency cache
+type detailModelKey string
+
 // renderUnitPlaceholder renders a simple visible placeholder for units without models
 func (r *Renderer) renderUnitPlaceholder(unit *engine.GameUnit, pos engine.Vector3) error {
 	// Create a simple colored indicator that's definitely visible
-	log.Printf("🔲 Rendering placeholder for unit %d ('%s') at (%.1f, %.1f, %.1f)",
+	logging.Debugf(logging.CategoryRender, "🔲 Rendering placeholder for unit %d ('%s') at (%.1f, %.1f, %.1f)",
 		unit.ID, unit.UnitType, pos.X, pos.Y, pos.Z)
 
 	// Choose color based on unit type for visual distinction
@@ -908,25 +1357,19 @@ func (r *Renderer) renderColoredCube(pos engine.Vector3, color [3]float32, size
 	return nil
 }
 
-// renderBuildings renders all buildings from the game world
+// renderBuildings renders the buildings near the camera
 func (r *Renderer) renderBuildings(world *engine.World) error {
-	allPlayers := world.GetAllPlayers()
-
-	for _, player := range allPlayers {
-		buildings := world.ObjectManager.GetBuildingsForPlayer(player.ID)
-
-		for _, building := range buildings {
-			// Skip buildings that haven't finished construction
-			if !building.IsBuilt {
-				continue
-			}
+	for _, building := range r.buildingsToDraw(world) {
+		// Skip buildings that haven't finished construction
+		if !building.IsBuilt {
+			continue
+		}
 
-			err := r.renderBuilding(building)
-			if err != nil {
-				// Log error but continue rendering other buildings
-				log.Printf("Warning: Failed to render building %d: %v", building.ID, err)
-				continue
-			}
+		err := r.renderBuilding(building)
+		if err != nil {
+			// Log error but continue rendering other buildings
+			logging.Warnf(logging.CategoryRender, "Failed to render building %d: %v", building.ID, err)
+			continue
 		}
 	}
 
@@ -951,6 +1394,8 @@ func (r *Renderer) renderBuilding(building *engine.GameBuilding) error {
 	// Create transformation matrix for building position and rotation
 	// TODO: Apply building.Rotation for proper orientation
 
+	// Models are shared between buildings: set the owner's color for this draw
+	model.TeamColor = graphics.TeamColor(building.PlayerID)
 	err = r.RenderModel(model)
 	if err != nil {
 		return fmt.Errorf("failed to render building model: %w", err)
@@ -967,7 +1412,7 @@ func (r *Renderer) renderResourceNodes(world *engine.World) error {
 		err := r.renderResourceNode(node)
 		if err != nil {
 			// Log error but continue rendering other nodes
-			log.Printf("Warning: Failed to render resource node %d: %v", node.ID, err)
+			logging.Warnf(logging.CategoryRender, "Failed to render resource node %d: %v", node.ID, err)
 			continue
 		}
 	}
@@ -998,6 +1443,16 @@ func (r *Renderer) renderResourceNode(node *engine.ResourceNode) error {
 	return nil
 }
 
+// ReleaseWorld frees what the renderer keeps for the current world when its
+// match ends: the terrain chunks, the grass, the command markers and the
+// silhouette choice. Models and textures stay cached for the next match.
+func (r *Renderer) ReleaseWorld() {
+	r.terrain.Release()
+	r.details.Release()
+	r.commandMarkers.Clear()
+	r.silhouette = nil
+}
+
 // Destroy cleans up the renderer and releases resources
 func (r *Renderer) Destroy() {
 	// Clean up GPU models
@@ -1005,13 +1460,20 @@ func (r *Renderer) Destroy() {
 		gl.DeleteVertexArrays(1, &model.VAO)
 		gl.DeleteBuffers(1, &model.VBO)
 		gl.DeleteBuffers(1, &model.EBO)
-		log.Printf("Cleaned up model: %s", path)
+		logging.Debugf(logging.CategoryRender, "Cleaned up model: %s", path)
 	}
 
 	// Clean up GPU textures
 	for path, texture := range r.textureCache {
 		gl.DeleteTextures(1, &texture.ID)
-		log.Printf("Cleaned up texture: %s", path)
+		logging.Debugf(logging.CategoryRender, "Cleaned up texture: %s", path)
+	}
+
+	// Clean up unit models
+	for _, model := range r.unitModels {
+		if model != nil {
+			model.Cleanup()
+		}
 	}
 
 	// Clean up model manager
@@ -1019,6 +1481,18 @@ func (r *Renderer) Destroy() {
 		r.modelMgr.Cleanup()
 	}
 
+	// Clean up post-processing buffers, terrain, decals, command markers, picking, grass, debug shapes, text and sprites
+	r.post.Destroy()
+	r.terrain.Destroy()
+	r.decals.Destroy()
+	r.commandMarkers.Destroy()
+	r.picking.Destroy()
+	r.details.Destroy()
+	r.debugShapes.Destroy()
+	r.text.Destroy()
+	r.icons.Destroy()
+	r.sprites.Destroy()
+
 	// Clean up lighting manager (no cleanup needed - just references)
 	r.lightMgr = nil
 
@@ -1030,5 +1504,5 @@ func (r *Renderer) Destroy() {
 	// Destroy OpenGL context
 	r.context.Destroy()
 
-	log.Printf("Renderer destroyed after %d frames", r.frameCount)
+	logging.Infof(logging.CategoryRender, "Renderer destroyed after %d frames", r.frameCount)
 }
\ No newline at end of file
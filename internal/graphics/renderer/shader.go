@@ -1,29 +1,38 @@
+//go:build !js
+
 package renderer
 
 import (
 	"fmt"
 	"io/ioutil"
-	"log"
-	"strings"
 
-	"github.com/go-gl/gl/v3.3-core/gl"
+	"teraglest/internal/graphics/device"
+	"teraglest/internal/logging"
+
 	"github.com/go-gl/mathgl/mgl32"
 )
 
 // ShaderManager manages GLSL shader programs
 type ShaderManager struct {
-	programs map[string]uint32             // Shader program name -> OpenGL program ID
-	uniforms map[string]map[string]int32   // Program name -> uniform name -> location
+	device   device.GraphicsDevice
+	programs map[string]device.Program   // Shader program name -> device program
+	uniforms map[string]map[string]int32 // Program name -> uniform name -> location
 }
 
-// NewShaderManager creates a new shader manager
-func NewShaderManager() *ShaderManager {
+// NewShaderManager creates a shader manager compiling programs on a graphics device
+func NewShaderManager(dev device.GraphicsDevice) *ShaderManager {
 	return &ShaderManager{
-		programs: make(map[string]uint32),
+		device:   dev,
+		programs: make(map[string]device.Program),
 		uniforms: make(map[string]map[string]int32),
 	}
 }
 
+// Device returns the graphics device programs are compiled on
+func (sm *ShaderManager) Device() device.GraphicsDevice {
+	return sm.device
+}
+
 // LoadShader loads and compiles a shader program from vertex and fragment shader files
 func (sm *ShaderManager) LoadShader(name, vertexPath, fragmentPath string) error {
 	// Read vertex shader source
@@ -38,120 +47,39 @@ func (sm *ShaderManager) LoadShader(name, vertexPath, fragmentPath string) error
 		return fmt.Errorf("failed to read fragment shader %s: %w", fragmentPath, err)
 	}
 
-	// Compile vertex shader
-	vertexShader, err := sm.compileShader(string(vertexSource), gl.VERTEX_SHADER)
-	if err != nil {
-		return fmt.Errorf("failed to compile vertex shader %s: %w", vertexPath, err)
-	}
-	defer gl.DeleteShader(vertexShader)
-
-	// Compile fragment shader
-	fragmentShader, err := sm.compileShader(string(fragmentSource), gl.FRAGMENT_SHADER)
-	if err != nil {
-		return fmt.Errorf("failed to compile fragment shader %s: %w", fragmentPath, err)
-	}
-	defer gl.DeleteShader(fragmentShader)
-
-	// Link shader program
-	program, err := sm.linkProgram(vertexShader, fragmentShader)
+	// Compile and link the shader program
+	program, err := sm.device.CreateProgram(string(vertexSource), string(fragmentSource))
 	if err != nil {
-		return fmt.Errorf("failed to link shader program %s: %w", name, err)
+		return fmt.Errorf("failed to build shader program %s from %s and %s: %w", name, vertexPath, fragmentPath, err)
 	}
+	sm.store(name, program)
 
-	// Store the program
-	sm.programs[name] = program
-
-	// Initialize uniform location cache for this program
-	sm.uniforms[name] = make(map[string]int32)
-
-	log.Printf("Loaded shader program: %s (ID=%d)", name, program)
+	logging.Debugf(logging.CategoryRender, "Loaded shader program: %s (ID=%d)", name, program)
 	return nil
 }
 
 // LoadShaderFromSource loads and compiles a shader program from source strings
 func (sm *ShaderManager) LoadShaderFromSource(name, vertexSource, fragmentSource string) error {
-	// Compile vertex shader
-	vertexShader, err := sm.compileShader(vertexSource, gl.VERTEX_SHADER)
+	// Compile and link the shader program
+	program, err := sm.device.CreateProgram(vertexSource, fragmentSource)
 	if err != nil {
-		return fmt.Errorf("failed to compile vertex shader for %s: %w", name, err)
+		return fmt.Errorf("failed to build shader program %s: %w", name, err)
 	}
-	defer gl.DeleteShader(vertexShader)
+	sm.store(name, program)
 
-	// Compile fragment shader
-	fragmentShader, err := sm.compileShader(fragmentSource, gl.FRAGMENT_SHADER)
-	if err != nil {
-		return fmt.Errorf("failed to compile fragment shader for %s: %w", name, err)
-	}
-	defer gl.DeleteShader(fragmentShader)
+	logging.Debugf(logging.CategoryRender, "Loaded shader program from source: %s (ID=%d)", name, program)
+	return nil
+}
 
-	// Link shader program
-	program, err := sm.linkProgram(vertexShader, fragmentShader)
-	if err != nil {
-		return fmt.Errorf("failed to link shader program %s: %w", name, err)
+// store keeps a program under its name, replacing and freeing any previous one
+func (sm *ShaderManager) store(name string, program device.Program) {
+	if previous, exists := sm.programs[name]; exists {
+		sm.device.DeleteProgram(previous)
 	}
-
-	// Store the program
 	sm.programs[name] = program
 
 	// Initialize uniform location cache for this program
 	sm.uniforms[name] = make(map[string]int32)
-
-	log.Printf("Loaded shader program from source: %s (ID=%d)", name, program)
-	return nil
-}
-
-// compileShader compiles a single shader from source
-func (sm *ShaderManager) compileShader(source string, shaderType uint32) (uint32, error) {
-	shader := gl.CreateShader(shaderType)
-
-	// Set shader source
-	csource, free := gl.Strs(source + "\x00")
-	defer free()
-	gl.ShaderSource(shader, 1, csource, nil)
-
-	// Compile shader
-	gl.CompileShader(shader)
-
-	// Check compilation status
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
-
-		gl.DeleteShader(shader)
-		return 0, fmt.Errorf("shader compilation failed: %v", log)
-	}
-
-	return shader, nil
-}
-
-// linkProgram links vertex and fragment shaders into a program
-func (sm *ShaderManager) linkProgram(vertexShader, fragmentShader uint32) (uint32, error) {
-	program := gl.CreateProgram()
-
-	gl.AttachShader(program, vertexShader)
-	gl.AttachShader(program, fragmentShader)
-	gl.LinkProgram(program)
-
-	// Check linking status
-	var status int32
-	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
-
-		gl.DeleteProgram(program)
-		return 0, fmt.Errorf("program linking failed: %v", log)
-	}
-
-	return program, nil
 }
 
 // UseShader activates a shader program
@@ -161,7 +89,7 @@ func (sm *ShaderManager) UseShader(name string) error {
 		return fmt.Errorf("shader program %s not found", name)
 	}
 
-	gl.UseProgram(program)
+	sm.device.UseProgram(program)
 	return nil
 }
 
@@ -178,7 +106,7 @@ func (sm *ShaderManager) getUniformLocation(shaderName, uniformName string) (int
 	}
 
 	// Get uniform location
-	location := gl.GetUniformLocation(program, gl.Str(uniformName+"\x00"))
+	location := sm.device.UniformLocation(program, uniformName)
 	if location == -1 {
 		return -1, fmt.Errorf("uniform %s not found in shader %s", uniformName, shaderName)
 	}
@@ -195,7 +123,7 @@ func (sm *ShaderManager) SetUniformMat4(shaderName, uniformName string, matrix m
 		return err
 	}
 
-	gl.UniformMatrix4fv(location, 1, false, &matrix[0])
+	sm.device.SetUniformMat4(location, matrix)
 	return nil
 }
 
@@ -206,7 +134,7 @@ func (sm *ShaderManager) SetUniformVec3(shaderName, uniformName string, vector m
 		return err
 	}
 
-	gl.Uniform3fv(location, 1, &vector[0])
+	sm.device.SetUniformVec3(location, vector)
 	return nil
 }
 
@@ -217,7 +145,7 @@ func (sm *ShaderManager) SetUniformVec2(shaderName, uniformName string, vector m
 		return err
 	}
 
-	gl.Uniform2fv(location, 1, &vector[0])
+	sm.device.SetUniformVec2(location, vector)
 	return nil
 }
 
@@ -228,7 +156,7 @@ func (sm *ShaderManager) SetUniformFloat(shaderName, uniformName string, value f
 		return err
 	}
 
-	gl.Uniform1f(location, value)
+	sm.device.SetUniformFloat(location, value)
 	return nil
 }
 
@@ -239,7 +167,7 @@ func (sm *ShaderManager) SetUniformInt(shaderName, uniformName string, value int
 		return err
 	}
 
-	gl.Uniform1i(location, value)
+	sm.device.SetUniformInt(location, value)
 	return nil
 }
 
@@ -254,12 +182,12 @@ func (sm *ShaderManager) SetUniformBool(shaderName, uniformName string, value bo
 	if value {
 		intValue = 1
 	}
-	gl.Uniform1i(location, intValue)
+	sm.device.SetUniformInt(location, intValue)
 	return nil
 }
 
-// GetProgramID returns the OpenGL program ID for a shader
-func (sm *ShaderManager) GetProgramID(name string) (uint32, bool) {
+// GetProgramID returns the device program of a shader
+func (sm *ShaderManager) GetProgramID(name string) (device.Program, bool) {
 	program, exists := sm.programs[name]
 	return program, exists
 }
@@ -276,9 +204,9 @@ func (sm *ShaderManager) ListShaders() []string {
 // Destroy cleans up all shader programs
 func (sm *ShaderManager) Destroy() {
 	for name, program := range sm.programs {
-		gl.DeleteProgram(program)
-		log.Printf("Deleted shader program: %s", name)
+		sm.device.DeleteProgram(program)
+		logging.Debugf(logging.CategoryRender, "Deleted shader program: %s", name)
 	}
-	sm.programs = make(map[string]uint32)
+	sm.programs = make(map[string]device.Program)
 	sm.uniforms = make(map[string]map[string]int32)
 }
\ No newline at end of file
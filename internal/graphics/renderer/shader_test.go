@@ -0,0 +1,44 @@
+//go:build !js
+
+package renderer
+
+import (
+	"testing"
+
+	"teraglest/internal/graphics/device"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TestShaderManagerHeadless tests shader programs and uniforms on the null device
+func TestShaderManagerHeadless(t *testing.T) {
+	null := device.NewNull()
+	shaders := NewShaderManager(null)
+	vertex := "uniform mat4 uView;\nvoid main() {}"
+	fragment := "uniform float uAlpha;\nvoid main() {}"
+
+	if err := shaders.LoadShaderFromSource("test", vertex, fragment); err != nil {
+		t.Fatalf("Failed to load shader: %v", err)
+	}
+	if err := shaders.UseShader("test"); err != nil {
+		t.Fatalf("Failed to use shader: %v", err)
+	}
+	view := mgl32.Translate3D(1, 2, 3)
+	if err := shaders.SetUniformMat4("test", "uView", view); err != nil {
+		t.Errorf("Failed to set a declared uniform: %v", err)
+	}
+	if err := shaders.SetUniformFloat("test", "uMissing", 1); err == nil {
+		t.Error("Expected an undeclared uniform to fail")
+	}
+	program, _ := shaders.GetProgramID("test")
+	if got := null.Uniform(program, "uView"); got != view {
+		t.Errorf("Expected the view matrix on the device, got %v", got)
+	}
+
+	// Reloading a program frees the one it replaces
+	shaders.LoadShaderFromSource("test", vertex, fragment)
+	shaders.Destroy()
+	if _, _, programs := null.Resources(); programs != 0 {
+		t.Errorf("Expected every program freed, got %d", programs)
+	}
+}
@@ -0,0 +1,95 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics"
+	"teraglest/internal/graphics/device"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// silhouetteShader is the shader program drawing flat unit silhouettes
+const silhouetteShader = "silhouette"
+
+// silhouetteOpacity is how strongly a silhouette covers what hides the unit
+const silhouetteOpacity = 0.6
+
+// SelectedSilhouetteColor is the silhouette color of selected units
+var SelectedSilhouetteColor = mgl32.Vec3{0.4, 1.0, 0.4}
+
+// SilhouetteFunc returns the color a unit shows in where buildings or terrain
+// hide it, and false for units that stay hidden
+type SilhouetteFunc func(unit *engine.GameUnit) (mgl32.Vec3, bool)
+
+// OwnedAndSelectedSilhouettes shows the player's own units in their team
+// color and selected units of any player in the selection color
+func OwnedAndSelectedSilhouettes(playerID int, selected func(*engine.GameUnit) bool) SilhouetteFunc {
+	return func(unit *engine.GameUnit) (mgl32.Vec3, bool) {
+		switch {
+		case selected != nil && selected(unit):
+			return SelectedSilhouetteColor, true
+		case unit.PlayerID == playerID:
+			return graphics.TeamColor(playerID), true
+		}
+		return mgl32.Vec3{}, false
+	}
+}
+
+// SetSilhouettes sets which units show as silhouettes where they are hidden,
+// or nil for none
+func (r *Renderer) SetSilhouettes(silhouette SilhouetteFunc) {
+	r.silhouette = silhouette
+}
+
+// loadSilhouetteShader loads the silhouette shader; without it, hidden units stay hidden
+func (r *Renderer) loadSilhouetteShader() error {
+	err := r.shaderMgr.LoadShader(silhouetteShader,
+		"internal/graphics/shaders/silhouette.vert",
+		"internal/graphics/shaders/silhouette.frag")
+	if err != nil {
+		return fmt.Errorf("failed to load silhouette shader: %w", err)
+	}
+	r.silhouettesReady = true
+	return nil
+}
+
+// renderSilhouettes draws the hidden parts of the units queued in the
+// silhouette batcher, after everything that can hide them. The units are
+// drawn twice: first marking the stencil where they are in view, then in a
+// flat color where they are behind the depth buffer and not marked, so a
+// unit's own visible parts are not covered by its silhouette.
+func (r *Renderer) renderSilhouettes() error {
+	batches := r.silhouetteBatcher.Batches()
+	if len(batches) == 0 || !r.silhouettesReady {
+		return nil
+	}
+
+	if err := r.shaderMgr.UseShader(silhouetteShader); err != nil {
+		return err
+	}
+	r.shaderMgr.SetUniformMat4(silhouetteShader, "uView", r.camera.GetViewMatrix())
+	r.shaderMgr.SetUniformMat4(silhouetteShader, "uProjection", r.camera.GetProjectionMatrix())
+	r.shaderMgr.SetUniformFloat(silhouetteShader, "uOpacity", silhouetteOpacity)
+
+	previous := r.device.State()
+	passes := []device.RenderState{
+		{DepthTest: true, DepthReadOnly: true, CullFace: true, ColorOff: true, Stencil: device.StencilMark},
+		{DepthTest: true, DepthGreater: true, DepthReadOnly: true, CullFace: true, AlphaBlend: true, Stencil: device.StencilUnmarked},
+	}
+	for _, state := range passes {
+		r.device.SetState(state)
+		for _, batch := range batches {
+			if err := batch.Key.Model.DrawInstances(batch.Instances); err != nil {
+				r.device.SetState(previous)
+				return fmt.Errorf("failed to draw silhouettes of %s: %w", batch.Key.Model.Name, err)
+			}
+			r.drawCalls++
+		}
+	}
+	r.device.SetState(previous)
+	return nil
+}
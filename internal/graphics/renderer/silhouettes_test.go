@@ -0,0 +1,29 @@
+//go:build !js
+
+package renderer
+
+import (
+	"testing"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics"
+)
+
+// TestOwnedAndSelectedSilhouettes tests which units show through what hides
+// them, and in which color
+func TestOwnedAndSelectedSilhouettes(t *testing.T) {
+	own := &engine.GameUnit{ID: 1, PlayerID: 1}
+	enemy := &engine.GameUnit{ID: 2, PlayerID: 2}
+	selectedEnemy := &engine.GameUnit{ID: 3, PlayerID: 2}
+	silhouette := OwnedAndSelectedSilhouettes(1, func(unit *engine.GameUnit) bool { return unit == selectedEnemy })
+
+	if color, shown := silhouette(own); !shown || color != graphics.TeamColor(1) {
+		t.Errorf("Expected own units in their team color, got %v %v", color, shown)
+	}
+	if _, shown := silhouette(enemy); shown {
+		t.Error("Expected unselected enemy units to stay hidden")
+	}
+	if color, shown := silhouette(selectedEnemy); !shown || color != SelectedSilhouetteColor {
+		t.Errorf("Expected selected units in the selection color, got %v %v", color, shown)
+	}
+}
@@ -0,0 +1,203 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+
+	"teraglest/internal/data"
+	"teraglest/internal/graphics"
+	"teraglest/internal/graphics/device"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/logging"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// spriteShader is the shader program drawing HUD sprites
+const spriteShader = "sprite"
+
+// spriteVertexLayout is a screen position, texture coordinates and an RGBA color
+var spriteVertexLayout = device.VertexLayout{2, 2, 4}
+
+// HUDCanvas is what HUD elements draw on each frame: sprites and screen
+// text, in window pixels from the top left
+type HUDCanvas struct {
+	Sprites *sprite.Batch
+	Text    *TextRenderer // Nil when text rendering is unavailable
+	Width   int
+	Height  int
+
+	sprites *SpriteRenderer
+	icons   *IconRenderer
+}
+
+// UnitIcon returns the portrait of a unit or building, rendered from its
+// model when its mod ships no icon image
+func (c *HUDCanvas) UnitIcon(factionName, unitName string) sprite.Texture {
+	return c.icons.UnitIcon(factionName, unitName)
+}
+
+// ModelIcon returns an icon rendered from a model relative to the tech tree root
+func (c *HUDCanvas) ModelIcon(modelPath string) sprite.Texture {
+	return c.icons.ModelIcon(modelPath)
+}
+
+// Texture returns a texture of the tech tree for sprites, loading it on
+// first use; it returns sprite.NoTexture if the texture cannot be loaded
+func (c *HUDCanvas) Texture(path string) sprite.Texture {
+	return c.sprites.Texture(path)
+}
+
+// NewTexture creates an RGBA texture for sprites that the HUD draws itself,
+// freed with the renderer; it returns sprite.NoTexture without a renderer
+func (c *HUDCanvas) NewTexture(width, height int, pixels []byte) sprite.Texture {
+	return c.sprites.NewTexture(width, height, pixels)
+}
+
+// UpdateTexture replaces the pixels of a texture made with NewTexture
+func (c *HUDCanvas) UpdateTexture(texture sprite.Texture, pixels []byte) {
+	c.sprites.UpdateTexture(texture, pixels)
+}
+
+// SpriteRenderer draws 2D sprite batches over the frame, independently of ImGui
+type SpriteRenderer struct {
+	shaders  *ShaderManager
+	device   device.GraphicsDevice
+	assetMgr *data.AssetManager
+	textures *graphics.TextureManager
+	missing  map[string]bool                       // Textures that failed to load, not retried
+	drawn    map[sprite.Texture]device.TextureDesc // Textures the HUD draws itself
+	white    device.Texture                        // 1x1 white texture for plain color quads
+	vao      device.VertexArray
+	vbo      device.Buffer
+
+	batch *sprite.Batch
+}
+
+// NewSpriteRenderer loads the sprite shader and creates the vertex buffer.
+// Textures are loaded through the asset manager, relative to the tech tree.
+func NewSpriteRenderer(shaders *ShaderManager, assetMgr *data.AssetManager) (*SpriteRenderer, error) {
+	err := shaders.LoadShader(spriteShader,
+		"internal/graphics/shaders/sprite.vert",
+		"internal/graphics/shaders/sprite.frag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sprite shader: %w", err)
+	}
+
+	sr := &SpriteRenderer{
+		shaders:  shaders,
+		device:   shaders.Device(),
+		assetMgr: assetMgr,
+		textures: graphics.NewTextureManager(),
+		missing:  make(map[string]bool),
+		drawn:    make(map[sprite.Texture]device.TextureDesc),
+		batch:    sprite.NewBatch(),
+	}
+	sr.white = sr.device.CreateTexture(device.TextureDesc{Width: 1, Height: 1}, []byte{255, 255, 255, 255})
+	sr.vao, sr.vbo = sr.device.CreateVertexArray(spriteVertexLayout)
+	return sr, nil
+}
+
+// Texture returns a tech tree texture for sprites, loading it on first use;
+// textures that fail to load are logged once and drawn as plain color
+func (sr *SpriteRenderer) Texture(path string) sprite.Texture {
+	if sr == nil || path == "" || sr.missing[path] {
+		return sprite.NoTexture
+	}
+	if texture := sr.textures.GetTexture(path); texture != nil {
+		return sprite.Texture(texture.ID)
+	}
+	img, err := sr.assetMgr.LoadTexture(path)
+	if err == nil {
+		var texture *graphics.Texture
+		texture, err = sr.textures.LoadTextureFromImage(img, path)
+		if err == nil {
+			return sprite.Texture(texture.ID)
+		}
+	}
+	logging.Warnf(logging.CategoryRender, "No sprite texture %s: %v", path, err)
+	sr.missing[path] = true
+	return sprite.NoTexture
+}
+
+// NewTexture creates a linearly filtered RGBA texture from pixels
+func (sr *SpriteRenderer) NewTexture(width, height int, pixels []byte) sprite.Texture {
+	if sr == nil || width <= 0 || height <= 0 {
+		return sprite.NoTexture
+	}
+	desc := device.TextureDesc{Width: width, Height: height, Linear: true, ClampToEdge: true}
+	texture := sprite.Texture(sr.device.CreateTexture(desc, pixels))
+	sr.drawn[texture] = desc
+	return texture
+}
+
+// UpdateTexture replaces the pixels of a texture made with NewTexture
+func (sr *SpriteRenderer) UpdateTexture(texture sprite.Texture, pixels []byte) {
+	if sr == nil {
+		return
+	}
+	if desc, ok := sr.drawn[texture]; ok {
+		sr.device.UpdateTexture(device.Texture(texture), desc, pixels)
+	}
+}
+
+// Render draws the batch over the frame and empties it
+func (sr *SpriteRenderer) Render(width, height int) error {
+	if sr == nil || sr.batch.Empty() {
+		return nil
+	}
+	defer sr.batch.Reset()
+
+	if err := sr.shaders.UseShader(spriteShader); err != nil {
+		return err
+	}
+	sr.shaders.SetUniformVec2(spriteShader, "uScreenSize", mgl32.Vec2{float32(width), float32(height)})
+	sr.shaders.SetUniformInt(spriteShader, "uTexture", 0)
+
+	// Sprites are drawn over the scene, blended, in filled mode
+	previous := sr.device.State()
+	sr.device.SetState(device.OverlayState)
+	sr.device.UploadVertices(sr.vbo, sr.batch.Vertices(), device.UsageStream)
+	for _, call := range sr.batch.DrawCalls() {
+		texture := device.Texture(call.Texture)
+		if call.Texture == sprite.NoTexture {
+			texture = sr.white
+		}
+		sr.device.BindTexture(0, texture)
+		sr.device.Draw(sr.vao, device.Triangles, call.First, call.Count)
+	}
+	sr.device.BindTexture(0, 0)
+	sr.device.SetState(previous)
+	return nil
+}
+
+// Destroy frees the textures and vertex buffer
+func (sr *SpriteRenderer) Destroy() {
+	if sr == nil {
+		return
+	}
+	sr.textures.Cleanup()
+	for texture := range sr.drawn {
+		sr.device.DeleteTexture(device.Texture(texture))
+	}
+	sr.device.DeleteTexture(sr.white)
+	sr.device.DeleteBuffer(sr.vbo)
+	sr.device.DeleteVertexArray(sr.vao)
+}
+
+// SetHUD sets the function drawing the HUD each frame, or nil for none. It
+// draws after debug shapes and before labels, so HUD text stays readable.
+func (r *Renderer) SetHUD(draw func(*HUDCanvas)) {
+	r.hud = draw
+}
+
+// renderHUD lets the HUD draw its sprites, then draws them
+func (r *Renderer) renderHUD() error {
+	if r.hud == nil || r.sprites == nil {
+		return nil
+	}
+	width, height := r.context.GetWidth(), r.context.GetHeight()
+	r.hud(&HUDCanvas{Sprites: r.sprites.batch, Text: r.text, Width: width, Height: height, sprites: r.sprites, icons: r.icons})
+	return r.sprites.Render(width, height)
+}
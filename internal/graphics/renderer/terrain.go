@@ -0,0 +1,345 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+	"sort"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/device"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// terrainShader is the shader program drawing terrain chunks
+const terrainShader = "terrain_chunk"
+
+// Terrain streaming
+const (
+	terrainResidentReach   = 3  // Chunks around the camera's kept on the GPU on large maps
+	terrainEvictMargin     = 1  // Chunks past the reach a chunk may drift before it is freed
+	terrainUploadsPerFrame = 2  // Built chunks uploaded per frame, bounding the frame time
+	terrainQueueLength     = 16 // Chunks waiting to be built or uploaded
+)
+
+// Terrain vertices are a position, a normal, a tile coordinate and a surface type
+var terrainVertexLayout = device.VertexLayout{3, 3, 2, 1}
+
+// Terrain lighting
+var (
+	terrainLightDirection = mgl32.Vec3{0.4, 1, 0.3}
+	terrainLightColor     = mgl32.Vec3{0.75, 0.72, 0.65}
+	terrainAmbientColor   = mgl32.Vec3{0.4, 0.42, 0.45}
+)
+
+// terrainChunk is a chunk's mesh on the GPU
+type terrainChunk struct {
+	vao   device.VertexArray
+	vbo   device.Buffer
+	count int // Vertices
+}
+
+// terrainRequest asks the builder for a chunk's mesh
+type terrainRequest struct {
+	world *engine.World
+	cell  engine.Vector2i
+}
+
+// terrainBuild is a chunk's mesh built in the background
+type terrainBuild struct {
+	terrainRequest
+	vertices []float32
+}
+
+// TerrainStats are the terrain chunks resident on the GPU
+type TerrainStats struct {
+	Chunks  int
+	Bytes   int64
+	Pending int // Chunks being built or waiting for upload
+}
+
+// TerrainRenderer draws the map's height field in chunks of
+// engine.WorldChunkSize tiles. Chunk meshes are built by a background
+// goroutine and uploaded a few per frame. On maps of engine.StreamingMapSize
+// tiles or more only the chunks around the camera focus are kept on the GPU,
+// so memory and frame time stay the same however large the map is; smaller
+// maps keep every chunk.
+type TerrainRenderer struct {
+	shaders *ShaderManager
+	device  device.GraphicsDevice
+
+	world   *engine.World // World the chunks below were built for
+	chunks  map[engine.Vector2i]*terrainChunk
+	pending map[engine.Vector2i]bool // Requested and not uploaded yet
+	bytes   int64
+
+	requests chan terrainRequest
+	results  chan terrainBuild
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewTerrainRenderer loads the terrain shader and starts the chunk builder
+func NewTerrainRenderer(shaders *ShaderManager) (*TerrainRenderer, error) {
+	err := shaders.LoadShader(terrainShader,
+		"internal/graphics/shaders/terrain.vert",
+		"internal/graphics/shaders/terrain_chunk.frag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load terrain shader: %w", err)
+	}
+
+	tr := &TerrainRenderer{
+		shaders:  shaders,
+		device:   shaders.Device(),
+		chunks:   make(map[engine.Vector2i]*terrainChunk),
+		pending:  make(map[engine.Vector2i]bool),
+		requests: make(chan terrainRequest, terrainQueueLength),
+		results:  make(chan terrainBuild, terrainQueueLength),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go tr.build()
+	return tr, nil
+}
+
+// build builds the requested chunk meshes until the renderer is destroyed
+func (tr *TerrainRenderer) build() {
+	defer close(tr.done)
+	for {
+		select {
+		case <-tr.stop:
+			return
+		case request := <-tr.requests:
+			built := terrainBuild{terrainRequest: request, vertices: terrainChunkVertices(request.world, request.cell)}
+			select {
+			case tr.results <- built:
+			case <-tr.stop:
+				return
+			}
+		}
+	}
+}
+
+// streamedChunks returns the corners of the chunks drawn around the camera
+// focus, and whether the map is large enough to draw only those
+func streamedChunks(world *engine.World, camera *Camera) (from, to engine.Vector2i, streaming bool) {
+	if world.Width < engine.StreamingMapSize && world.Height < engine.StreamingMapSize {
+		across, down := world.ChunkCount()
+		return engine.Vector2i{}, engine.Vector2i{X: across - 1, Y: down - 1}, false
+	}
+	center := world.ChunkAt(engine.Vector3{X: float64(camera.Target.X()), Z: float64(camera.Target.Z())})
+	from = engine.Vector2i{X: center.X - terrainResidentReach, Y: center.Y - terrainResidentReach}
+	to = engine.Vector2i{X: center.X + terrainResidentReach, Y: center.Y + terrainResidentReach}
+	return from, to, true
+}
+
+// Render uploads the chunks built since the last frame, frees those the
+// camera has left behind, requests the missing ones nearest first and draws
+// the resident chunks
+func (tr *TerrainRenderer) Render(world *engine.World, camera *Camera) error {
+	if tr == nil || world.Width <= 0 || world.Height <= 0 {
+		return nil
+	}
+	if world != tr.world {
+		tr.free()
+		tr.world = world
+	}
+
+	from, to, _ := streamedChunks(world, camera)
+	center := engine.Vector2i{X: (from.X + to.X) / 2, Y: (from.Y + to.Y) / 2}
+	keep := func(cell engine.Vector2i) bool {
+		return cell.X >= from.X-terrainEvictMargin && cell.X <= to.X+terrainEvictMargin &&
+			cell.Y >= from.Y-terrainEvictMargin && cell.Y <= to.Y+terrainEvictMargin
+	}
+
+	for cell, chunk := range tr.chunks {
+		if !keep(cell) {
+			tr.release(chunk)
+			delete(tr.chunks, cell)
+		}
+	}
+
+	for uploaded := 0; uploaded < terrainUploadsPerFrame; {
+		var built terrainBuild
+		select {
+		case built = <-tr.results:
+		default:
+			uploaded = terrainUploadsPerFrame
+			continue
+		}
+		if built.world != world {
+			continue // Built for the world before
+		}
+		delete(tr.pending, built.cell)
+		if !keep(built.cell) || len(built.vertices) == 0 {
+			continue
+		}
+		chunk := &terrainChunk{count: len(built.vertices) / terrainVertexLayout.Stride()}
+		chunk.vao, chunk.vbo = tr.device.CreateVertexArray(terrainVertexLayout)
+		tr.device.UploadVertices(chunk.vbo, built.vertices, device.UsageStatic)
+		tr.chunks[built.cell] = chunk
+		tr.bytes += int64(len(built.vertices) * 4)
+		uploaded++
+	}
+
+	tr.request(world, from, to, center)
+	if len(tr.chunks) == 0 {
+		return nil
+	}
+
+	if err := tr.shaders.UseShader(terrainShader); err != nil {
+		return err
+	}
+	tr.shaders.SetUniformMat4(terrainShader, "uView", camera.GetViewMatrix())
+	tr.shaders.SetUniformMat4(terrainShader, "uProjection", camera.GetProjectionMatrix())
+	tr.shaders.SetUniformVec3(terrainShader, "uLightDirection", terrainLightDirection.Normalize())
+	tr.shaders.SetUniformVec3(terrainShader, "uLightColor", terrainLightColor)
+	tr.shaders.SetUniformVec3(terrainShader, "uAmbientColor", terrainAmbientColor)
+
+	previous := tr.device.State()
+	tr.device.SetState(device.RenderState{DepthTest: true})
+	for _, chunk := range tr.chunks {
+		tr.device.Draw(chunk.vao, device.Triangles, 0, chunk.count)
+	}
+	tr.device.SetState(previous)
+	return nil
+}
+
+// request asks the builder for the chunks on the map from one corner to the
+// other that are neither resident nor requested, nearest the center first,
+// as far as the queue has room
+func (tr *TerrainRenderer) request(world *engine.World, from, to, center engine.Vector2i) {
+	across, down := world.ChunkCount()
+	var missing []engine.Vector2i
+	for y := from.Y; y <= to.Y; y++ {
+		for x := from.X; x <= to.X; x++ {
+			cell := engine.Vector2i{X: x, Y: y}
+			if x < 0 || y < 0 || x >= across || y >= down || tr.chunks[cell] != nil || tr.pending[cell] {
+				continue
+			}
+			missing = append(missing, cell)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return chunkDistance(missing[i], center) < chunkDistance(missing[j], center)
+	})
+
+	for _, cell := range missing {
+		select {
+		case tr.requests <- terrainRequest{world: world, cell: cell}:
+			tr.pending[cell] = true
+		default:
+			return
+		}
+	}
+}
+
+// chunkDistance returns how many chunks apart two chunks are, diagonals counting as one
+func chunkDistance(a, b engine.Vector2i) int {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	return max(dx, dy)
+}
+
+// terrainChunkVertices returns the triangles of a chunk's tiles, two per
+// tile. Vertices take the height of the tile at their corner, so the mesh is
+// continuous across chunks, and each tile is of its own surface type.
+func terrainChunkVertices(world *engine.World, cell engine.Vector2i) []float32 {
+	x0, y0 := cell.X*engine.WorldChunkSize, cell.Y*engine.WorldChunkSize
+	x1, y1 := min(x0+engine.WorldChunkSize, world.Width), min(y0+engine.WorldChunkSize, world.Height)
+	if x0 >= x1 || y0 >= y1 {
+		return nil
+	}
+	tileSize := world.GetTileSize()
+
+	// Heights and normals of the corners, with a border for the normals
+	side := engine.WorldChunkSize + 3
+	heights := make([]float32, side*side)
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			tile := engine.Vector2i{
+				X: max(0, min(world.Width-1, x0+x-1)),
+				Y: max(0, min(world.Height-1, y0+y-1)),
+			}
+			heights[y*side+x] = world.GetHeight(tile)
+		}
+	}
+	height := func(x, y int) float32 {
+		return heights[(y-y0+1)*side+(x-x0+1)]
+	}
+	normal := func(x, y int) mgl32.Vec3 {
+		return mgl32.Vec3{height(x-1, y) - height(x+1, y), 2 * tileSize, height(x, y-1) - height(x, y+1)}.Normalize()
+	}
+
+	vertices := make([]float32, 0, (x1-x0)*(y1-y0)*6*terrainVertexLayout.Stride())
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			surface := engine.SurfaceGrass
+			if world.Map != nil {
+				surface = world.Map.GetSurfaceAt(x, y)
+			}
+			corners := [4][2]int{{x, y}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}}
+			for _, index := range [6]int{0, 3, 2, 2, 1, 0} {
+				cx, cy := corners[index][0], corners[index][1]
+				n := normal(cx, cy)
+				vertices = append(vertices,
+					float32(cx)*tileSize, height(cx, cy), float32(cy)*tileSize,
+					n.X(), n.Y(), n.Z(),
+					float32(cx), float32(cy),
+					float32(surface))
+			}
+		}
+	}
+	return vertices
+}
+
+// Stats returns the chunks resident on the GPU
+func (tr *TerrainRenderer) Stats() TerrainStats {
+	if tr == nil {
+		return TerrainStats{}
+	}
+	return TerrainStats{Chunks: len(tr.chunks), Bytes: tr.bytes, Pending: len(tr.pending)}
+}
+
+// release frees a chunk's buffers
+func (tr *TerrainRenderer) release(chunk *terrainChunk) {
+	tr.device.DeleteBuffer(chunk.vbo)
+	tr.device.DeleteVertexArray(chunk.vao)
+	tr.bytes -= int64(chunk.count * terrainVertexLayout.Stride() * 4)
+}
+
+// free frees every resident chunk and forgets the requested ones; meshes
+// still being built for them are dropped when they arrive
+func (tr *TerrainRenderer) free() {
+	for _, chunk := range tr.chunks {
+		tr.release(chunk)
+	}
+	tr.chunks = make(map[engine.Vector2i]*terrainChunk)
+	tr.pending = make(map[engine.Vector2i]bool)
+	tr.bytes = 0
+}
+
+// Release frees the chunks of the current world, e.g. when its match ends
+func (tr *TerrainRenderer) Release() {
+	if tr == nil {
+		return
+	}
+	tr.free()
+	tr.world = nil
+}
+
+// Destroy stops the chunk builder and frees the resident chunks
+func (tr *TerrainRenderer) Destroy() {
+	if tr == nil {
+		return
+	}
+	close(tr.stop)
+	<-tr.done
+	tr.free()
+}
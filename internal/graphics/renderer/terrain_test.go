@@ -0,0 +1,72 @@
+//go:build !js
+
+package renderer
+
+import (
+	"testing"
+
+	"teraglest/internal/engine"
+)
+
+// TestTerrainChunkVertices tests that chunk meshes cover their tiles, stop
+// at the map's edge and meet their neighbours at the same heights
+func TestTerrainChunkVertices(t *testing.T) {
+	world, err := engine.NewHeadlessWorld(40, 40)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	world.SetHeight(engine.Vector2i{X: 32, Y: 5}, 3)
+	stride := terrainVertexLayout.Stride()
+
+	first := terrainChunkVertices(world, engine.Vector2i{X: 0, Y: 0})
+	if len(first) != 32*32*6*stride {
+		t.Fatalf("Expected two triangles for each of 32x32 tiles, got %d vertices", len(first)/stride)
+	}
+	last := terrainChunkVertices(world, engine.Vector2i{X: 1, Y: 1})
+	if len(last) != 8*8*6*stride {
+		t.Errorf("Expected the chunk at the map's corner cut to 8x8 tiles, got %d vertices", len(last)/stride)
+	}
+	if vertices := terrainChunkVertices(world, engine.Vector2i{X: 2, Y: 0}); vertices != nil {
+		t.Errorf("Expected no mesh off the map, got %d vertices", len(vertices)/stride)
+	}
+
+	// heightAt returns the height of a mesh's vertices at a grid corner
+	heightAt := func(vertices []float32, x, z float32) (float32, bool) {
+		tileSize := world.GetTileSize()
+		for i := 0; i < len(vertices); i += stride {
+			if vertices[i] == x*tileSize && vertices[i+2] == z*tileSize {
+				return vertices[i+1], true
+			}
+		}
+		return 0, false
+	}
+	left, found := heightAt(first, 32, 5)
+	if !found {
+		t.Fatalf("Expected the first chunk to reach its right edge")
+	}
+	right, _ := heightAt(terrainChunkVertices(world, engine.Vector2i{X: 1, Y: 0}), 32, 5)
+	if left != 3 || right != 3 {
+		t.Errorf("Expected both chunks at the raised corner's height 3, got %.1f and %.1f", left, right)
+	}
+}
+
+// TestStreamedChunks tests that small maps are drawn whole and large ones
+// only around the camera focus
+func TestStreamedChunks(t *testing.T) {
+	camera := NewCamera(800, 600)
+	camera.SetTarget(100, 0, 200)
+
+	small, _ := engine.NewHeadlessWorld(128, 64)
+	from, to, streaming := streamedChunks(small, camera)
+	if streaming || from != (engine.Vector2i{}) || to != (engine.Vector2i{X: 3, Y: 1}) {
+		t.Errorf("Expected all 4x2 chunks of a small map, got %v to %v (streaming %v)", from, to, streaming)
+	}
+
+	large, _ := engine.NewHeadlessWorld(512, 512)
+	from, to, streaming = streamedChunks(large, camera)
+	center := large.ChunkAt(engine.Vector3{X: 100, Z: 200})
+	want := engine.Vector2i{X: center.X - terrainResidentReach, Y: center.Y - terrainResidentReach}
+	if !streaming || from != want || to.X-from.X != 2*terrainResidentReach {
+		t.Errorf("Expected the chunks within %d of %v, got %v to %v (streaming %v)", terrainResidentReach, center, from, to, streaming)
+	}
+}
@@ -0,0 +1,163 @@
+//go:build !js
+
+package renderer
+
+import (
+	"fmt"
+
+	"teraglest/internal/graphics/device"
+	"teraglest/internal/graphics/text"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// textShader is the shader program drawing SDF text
+const textShader = "text"
+
+// Text drawing
+const (
+	textVertexFloats = 8  // Screen position, texture coordinates and RGBA color
+	DefaultTextSize  = 14 // Glyph height in pixels of labels
+)
+
+// textVertexLayout is a screen position, texture coordinates and an RGBA color
+var textVertexLayout = device.VertexLayout{2, 2, 4}
+
+// textLabel is text queued for the current frame
+type textLabel struct {
+	text     string
+	world    bool       // Anchored at a world position rather than a screen one
+	position mgl32.Vec3 // World position, or screen pixels in X and Y
+	size     float32    // Glyph height in pixels
+	color    mgl32.Vec4
+	anchor   text.Anchor
+}
+
+// TextRenderer draws text from an SDF font atlas. Labels are queued during
+// the frame, in world or screen space, and drawn together by Render at a
+// constant size on screen.
+type TextRenderer struct {
+	shaders *ShaderManager
+	device  device.GraphicsDevice
+	atlas   *text.Atlas
+	texture device.Texture
+	vao     device.VertexArray
+	vbo     device.Buffer
+
+	labels   []textLabel
+	vertices []float32
+}
+
+// NewTextRenderer builds the default font atlas and uploads it
+func NewTextRenderer(shaders *ShaderManager) (*TextRenderer, error) {
+	err := shaders.LoadShader(textShader,
+		"internal/graphics/shaders/text.vert",
+		"internal/graphics/shaders/text.frag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load text shader: %w", err)
+	}
+	atlas, err := text.BuildAtlas(text.DefaultFont(), text.DefaultAtlasScale, text.DefaultAtlasSpread)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build font atlas: %w", err)
+	}
+
+	tr := &TextRenderer{shaders: shaders, device: shaders.Device(), atlas: atlas}
+	tr.texture = tr.device.CreateTexture(device.TextureDesc{
+		Width: atlas.Width, Height: atlas.Height, Format: device.FormatR8, Linear: true, ClampToEdge: true,
+	}, atlas.Pixels)
+	tr.vao, tr.vbo = tr.device.CreateVertexArray(textVertexLayout)
+	return tr, nil
+}
+
+// DrawWorldText queues text centred above a world position, size pixels high
+func (tr *TextRenderer) DrawWorldText(position mgl32.Vec3, s string, size float32, color mgl32.Vec4) {
+	if tr == nil || s == "" {
+		return
+	}
+	tr.labels = append(tr.labels, textLabel{
+		text: s, world: true, position: position, size: size, color: color, anchor: text.AnchorBottomCenter,
+	})
+}
+
+// DrawScreenText queues text at screen pixels, from the top left of the window
+func (tr *TextRenderer) DrawScreenText(x, y float32, s string, size float32, color mgl32.Vec4, anchor text.Anchor) {
+	if tr == nil || s == "" {
+		return
+	}
+	tr.labels = append(tr.labels, textLabel{
+		text: s, position: mgl32.Vec3{x, y, 0}, size: size, color: color, anchor: anchor,
+	})
+}
+
+// Render draws the queued text over the frame and empties the queue. World
+// labels behind the camera or off screen are dropped.
+func (tr *TextRenderer) Render(camera *Camera, width, height int) error {
+	if tr == nil || len(tr.labels) == 0 {
+		return nil
+	}
+	viewProjection := camera.GetProjectionMatrix().Mul4(camera.GetViewMatrix())
+
+	tr.vertices = tr.vertices[:0]
+	for _, label := range tr.labels {
+		x, y := label.position.X(), label.position.Y()
+		if label.world {
+			clip := viewProjection.Mul4x1(label.position.Vec4(1))
+			if clip.W() <= 0 {
+				continue
+			}
+			ndc := clip.Vec3().Mul(1 / clip.W())
+			if ndc.X() < -1 || ndc.X() > 1 || ndc.Y() < -1 || ndc.Y() > 1 || ndc.Z() > 1 {
+				continue
+			}
+			x = (ndc.X() + 1) / 2 * float32(width)
+			y = (1 - ndc.Y()) / 2 * float32(height)
+		}
+		for _, quad := range tr.atlas.Layout(label.text, label.size, label.anchor) {
+			tr.addQuad(quad, x, y, label.color)
+		}
+	}
+	tr.labels = tr.labels[:0]
+	if len(tr.vertices) == 0 {
+		return nil
+	}
+
+	if err := tr.shaders.UseShader(textShader); err != nil {
+		return err
+	}
+	tr.shaders.SetUniformVec2(textShader, "uScreenSize", mgl32.Vec2{float32(width), float32(height)})
+	tr.shaders.SetUniformInt(textShader, "uAtlas", 0)
+
+	// Text is drawn over everything, blended, in filled mode
+	previous := tr.device.State()
+	tr.device.SetState(device.OverlayState)
+	tr.device.BindTexture(0, tr.texture)
+	tr.device.UploadVertices(tr.vbo, tr.vertices, device.UsageStream)
+	tr.device.Draw(tr.vao, device.Triangles, 0, len(tr.vertices)/textVertexFloats)
+	tr.device.BindTexture(0, 0)
+	tr.device.SetState(previous)
+	return nil
+}
+
+// addQuad adds the two triangles of a glyph quad offset to a screen position
+func (tr *TextRenderer) addQuad(quad text.Quad, x, y float32, color mgl32.Vec4) {
+	corners := [4][4]float32{
+		{x + quad.X0, y + quad.Y0, quad.U0, quad.V0},
+		{x + quad.X1, y + quad.Y0, quad.U1, quad.V0},
+		{x + quad.X1, y + quad.Y1, quad.U1, quad.V1},
+		{x + quad.X0, y + quad.Y1, quad.U0, quad.V1},
+	}
+	for _, corner := range [6]int{0, 1, 2, 2, 3, 0} {
+		c := corners[corner]
+		tr.vertices = append(tr.vertices, c[0], c[1], c[2], c[3], color[0], color[1], color[2], color[3])
+	}
+}
+
+// Destroy frees the atlas texture and vertex buffer
+func (tr *TextRenderer) Destroy() {
+	if tr == nil {
+		return
+	}
+	tr.device.DeleteTexture(tr.texture)
+	tr.device.DeleteBuffer(tr.vbo)
+	tr.device.DeleteVertexArray(tr.vao)
+}
@@ -0,0 +1,24 @@
+//go:build !js
+
+package renderer
+
+import (
+	"testing"
+
+	"teraglest/internal/engine"
+)
+
+// TestVisibleUnits tests that units garrisoned in buildings are not drawn
+func TestVisibleUnits(t *testing.T) {
+	outside := &engine.GameUnit{ID: 1, PlayerID: 1}
+	inside := &engine.GameUnit{ID: 2, PlayerID: 1, GarrisonedIn: 7}
+	units := []*engine.GameUnit{outside, inside}
+
+	shown := visibleUnits(units)
+	if len(shown) != 1 || shown[0] != outside {
+		t.Errorf("Expected only the unit outside drawn, got %v", shown)
+	}
+	if units[1] != inside {
+		t.Error("Expected the units given to be left as they were")
+	}
+}
@@ -0,0 +1,199 @@
+//go:build !js
+
+package graphics
+
+import (
+	"container/list"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// ResourceKind is the kind of GPU resource a residency entry holds
+type ResourceKind int
+
+const (
+	ResourceTexture ResourceKind = iota
+	ResourceModel
+)
+
+// ResidencyStats describes the GPU memory held by a residency cache
+type ResidencyStats struct {
+	Budget       int64 // Bytes allowed, 0 for no limit
+	Bytes        int64 // Bytes held by all entries
+	Textures     int   // Resident textures
+	Models       int   // Resident models
+	TextureBytes int64
+	ModelBytes   int64
+	Referenced   int    // Entries held by a reference, which are never evicted
+	Evictions    uint64 // Entries evicted since the cache was created
+}
+
+// ResidencyCache tracks GPU resources shared by the texture and model caches
+// and evicts the least recently used ones while they exceed a memory budget.
+// Entries with references, and entries used in the current frame, are kept
+// even over budget: evicting them would free memory that is still drawn.
+type ResidencyCache struct {
+	budget    int64
+	bytes     int64
+	frame     uint64
+	evictions uint64
+	entries   map[interface{}]*list.Element
+	lru       *list.List // Front is the most recently used
+}
+
+// residentEntry is a resource held by a residency cache
+type residentEntry struct {
+	key      interface{}
+	kind     ResourceKind
+	size     int64
+	refs     int
+	lastUsed uint64 // Frame the entry was last used in
+	evict    func() // Frees the resource and drops it from its owner's cache
+}
+
+// NewResidencyCache creates a residency cache with a budget in bytes, 0 for no limit
+func NewResidencyCache(budget int64) *ResidencyCache {
+	return &ResidencyCache{
+		budget:  budget,
+		entries: make(map[interface{}]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Add records a resource that was just uploaded as the most recently used,
+// then evicts older resources if the budget is exceeded. Evict is called
+// when the resource itself is evicted.
+func (rc *ResidencyCache) Add(key interface{}, kind ResourceKind, size int64, evict func()) {
+	if rc == nil {
+		return
+	}
+	rc.Remove(key)
+	entry := &residentEntry{key: key, kind: kind, size: size, lastUsed: rc.frame, evict: evict}
+	rc.entries[key] = rc.lru.PushFront(entry)
+	rc.bytes += size
+	rc.Trim()
+}
+
+// Touch marks a resource as used in the current frame; it returns false if
+// the resource is not resident
+func (rc *ResidencyCache) Touch(key interface{}) bool {
+	if rc == nil {
+		return false
+	}
+	element, exists := rc.entries[key]
+	if !exists {
+		return false
+	}
+	element.Value.(*residentEntry).lastUsed = rc.frame
+	rc.lru.MoveToFront(element)
+	return true
+}
+
+// Acquire adds a reference to a resource, keeping it resident until released
+func (rc *ResidencyCache) Acquire(key interface{}) bool {
+	if !rc.Touch(key) {
+		return false
+	}
+	rc.entries[key].Value.(*residentEntry).refs++
+	return true
+}
+
+// Release drops a reference taken with Acquire
+func (rc *ResidencyCache) Release(key interface{}) {
+	if rc == nil {
+		return
+	}
+	if element, exists := rc.entries[key]; exists {
+		if entry := element.Value.(*residentEntry); entry.refs > 0 {
+			entry.refs--
+		}
+	}
+}
+
+// Remove forgets a resource its owner freed, without calling its evict function
+func (rc *ResidencyCache) Remove(key interface{}) {
+	if rc == nil {
+		return
+	}
+	if element, exists := rc.entries[key]; exists {
+		rc.bytes -= element.Value.(*residentEntry).size
+		rc.lru.Remove(element)
+		delete(rc.entries, key)
+	}
+}
+
+// BeginFrame starts a new frame: resources used in earlier frames become
+// evictable by the next upload that exceeds the budget
+func (rc *ResidencyCache) BeginFrame() {
+	if rc == nil {
+		return
+	}
+	rc.frame++
+}
+
+// SetBudget changes the budget in bytes, 0 for no limit, evicting resources
+// if the new budget is exceeded
+func (rc *ResidencyCache) SetBudget(budget int64) {
+	if rc == nil {
+		return
+	}
+	rc.budget = budget
+	rc.Trim()
+}
+
+// Trim evicts the least recently used evictable resources until the cache fits its budget
+func (rc *ResidencyCache) Trim() {
+	if rc == nil || rc.budget <= 0 {
+		return
+	}
+	for element := rc.lru.Back(); element != nil && rc.bytes > rc.budget; {
+		previous := element.Prev()
+		entry := element.Value.(*residentEntry)
+		if entry.refs == 0 && entry.lastUsed != rc.frame {
+			rc.Remove(entry.key)
+			rc.evictions++
+			if entry.evict != nil {
+				entry.evict()
+			}
+		}
+		element = previous
+	}
+}
+
+// Stats returns the memory held by the cache
+func (rc *ResidencyCache) Stats() ResidencyStats {
+	if rc == nil {
+		return ResidencyStats{}
+	}
+	stats := ResidencyStats{Budget: rc.budget, Bytes: rc.bytes, Evictions: rc.evictions}
+	for element := rc.lru.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*residentEntry)
+		switch entry.kind {
+		case ResourceTexture:
+			stats.Textures++
+			stats.TextureBytes += entry.size
+		case ResourceModel:
+			stats.Models++
+			stats.ModelBytes += entry.size
+		}
+		if entry.refs > 0 {
+			stats.Referenced++
+		}
+	}
+	return stats
+}
+
+// TextureBytes estimates the GPU memory of a texture, including its mipmaps
+func TextureBytes(width, height int32, format uint32) int64 {
+	bytesPerPixel := int64(4)
+	if format == gl.RGB {
+		bytesPerPixel = 3
+	}
+	return int64(width) * int64(height) * bytesPerPixel * 4 / 3
+}
+
+// ModelBytes estimates the GPU memory of a model's vertex and index buffers
+func ModelBytes(model *Model) int64 {
+	// ~24 bytes per vertex (position, normal, texture coordinate), 4 per index
+	return int64(model.GetVertexCount())*24 + int64(model.IndexCount)*4 + 1024
+}
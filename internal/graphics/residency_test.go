@@ -0,0 +1,101 @@
+//go:build !js
+
+package graphics
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// TestResidencyCacheEviction tests least recently used eviction under a budget
+func TestResidencyCacheEviction(t *testing.T) {
+	cache := NewResidencyCache(300)
+	var evicted []string
+	add := func(name string, kind ResourceKind) {
+		cache.Add(name, kind, 100, func() { evicted = append(evicted, name) })
+	}
+
+	add("grass", ResourceTexture)
+	add("worker", ResourceModel)
+	add("castle", ResourceTexture)
+	cache.BeginFrame()
+
+	// Using grass makes worker the least recently used
+	cache.Touch("grass")
+	add("archer", ResourceModel)
+	if len(evicted) != 1 || evicted[0] != "worker" {
+		t.Fatalf("Expected worker to be evicted, got %v", evicted)
+	}
+	if cache.Touch("worker") {
+		t.Error("Expected the evicted model to no longer be resident")
+	}
+
+	stats := cache.Stats()
+	if stats.Bytes != 300 || stats.Textures != 2 || stats.Models != 1 || stats.Evictions != 1 {
+		t.Errorf("Expected 2 textures and 1 model in 300 bytes after 1 eviction, got %+v", stats)
+	}
+	if stats.TextureBytes != 200 || stats.ModelBytes != 100 {
+		t.Errorf("Expected 200 texture and 100 model bytes, got %+v", stats)
+	}
+}
+
+// TestResidencyCacheKeepsInUse tests that referenced resources and those
+// used in the current frame are kept over budget
+func TestResidencyCacheKeepsInUse(t *testing.T) {
+	cache := NewResidencyCache(100)
+	evictions := 0
+	evict := func() { evictions++ }
+
+	cache.Add("portrait", ResourceTexture, 100, evict)
+	cache.Acquire("portrait")
+	cache.BeginFrame()
+	cache.Add("grass", ResourceTexture, 100, evict)
+	if evictions != 0 || !cache.Touch("portrait") {
+		t.Fatal("Expected a referenced texture to stay resident")
+	}
+
+	// Both were used this frame, so a third upload overflows the budget
+	cache.Add("castle", ResourceTexture, 100, evict)
+	if evictions != 0 || cache.Stats().Bytes != 300 {
+		t.Fatalf("Expected no eviction within a frame, got %d", evictions)
+	}
+
+	// Released and unused, the portrait goes once the budget shrinks
+	cache.Release("portrait")
+	cache.BeginFrame()
+	cache.SetBudget(50)
+	if evictions != 3 || cache.Stats().Bytes != 0 {
+		t.Errorf("Expected all 3 textures evicted, got %d with %d bytes left", evictions, cache.Stats().Bytes)
+	}
+}
+
+// TestResidencyCacheUnlimited tests that a zero budget never evicts and a
+// nil cache is a no-op
+func TestResidencyCacheUnlimited(t *testing.T) {
+	cache := NewResidencyCache(0)
+	for _, name := range []string{"a", "b", "c"} {
+		cache.Add(name, ResourceModel, 1<<30, func() { t.Errorf("Expected no eviction without a budget") })
+	}
+	cache.Remove("b")
+	if stats := cache.Stats(); stats.Models != 2 || stats.Bytes != 2<<30 {
+		t.Errorf("Expected 2 models after removing one, got %+v", stats)
+	}
+
+	var none *ResidencyCache
+	none.Add("a", ResourceTexture, 1, nil)
+	if none.Touch("a") || none.Stats() != (ResidencyStats{}) {
+		t.Error("Expected a nil cache to hold nothing")
+	}
+}
+
+// TestTextureBytes tests texture memory estimates
+func TestTextureBytes(t *testing.T) {
+	// 256x256 RGBA with a full mip chain is a third more than the base level
+	if got := TextureBytes(256, 256, gl.RGBA); got != 256*256*4*4/3 {
+		t.Errorf("Expected %d bytes for an RGBA texture, got %d", 256*256*4*4/3, got)
+	}
+	if TextureBytes(256, 256, gl.RGB) >= TextureBytes(256, 256, gl.RGBA) {
+		t.Error("Expected an RGB texture to be smaller than an RGBA one")
+	}
+}
@@ -13,10 +13,12 @@ in vec3 fragPos;        // World space position
 in vec3 fragNormal;     // World space normal
 in vec2 fragTexCoord;   // Texture coordinates
 in vec3 viewPos;        // Camera position in world space
+in vec3 fragTeamColor;  // Color of the owning player
 
 // Material uniforms
 uniform sampler2D uDiffuseTexture;   // Diffuse texture
 uniform bool uUseTexture;            // Whether to use texture
+uniform bool uUseTeamColor;          // Texture alpha masks the team colored regions
 
 // Material properties
 struct Material {
@@ -24,6 +26,7 @@ struct Material {
     vec3 specular;     // Specular color
     float shininess;   // Specular shininess
     float opacity;     // Material opacity
+    vec3 emissive;     // Self-illumination, e.g. glowing magic effects
 };
 
 uniform Material material;
@@ -50,6 +53,9 @@ uniform int uNumLights;              // Number of active lights
 uniform Light uLights[MAX_LIGHTS];   // Array of lights
 uniform vec3 uAmbientColor;          // Global ambient lighting
 
+// Post-processing tone maps and gamma corrects the HDR output itself
+uniform bool uHDROutput;
+
 // Output
 out vec4 FragColor;
 
@@ -131,10 +137,15 @@ void main() {
     vec3 baseColor;
     if (uUseTexture) {
         vec4 textureColor = texture(uDiffuseTexture, fragTexCoord);
-        baseColor = textureColor.rgb * material.diffuse;
-        // Handle texture alpha for transparency
-        if (textureColor.a < 0.1) {
-            discard;
+        if (uUseTeamColor) {
+            // Alpha is the team color mask, not transparency
+            baseColor = mix(fragTeamColor, textureColor.rgb, textureColor.a) * material.diffuse;
+        } else {
+            baseColor = textureColor.rgb * material.diffuse;
+            // Handle texture alpha for transparency
+            if (textureColor.a < 0.1) {
+                discard;
+            }
         }
     } else {
         baseColor = material.diffuse;
@@ -160,11 +171,16 @@ void main() {
         }
     }
 
-    // Apply gamma correction
-    result = pow(result, vec3(1.0 / 2.2));
+    // Self-illumination; above 1.0 it feeds the bloom pass
+    result += material.emissive;
+
+    if (!uHDROutput) {
+        // Apply gamma correction
+        result = pow(result, vec3(1.0 / 2.2));
 
-    // Ensure we don't exceed maximum brightness
-    result = min(result, vec3(1.0));
+        // Ensure we don't exceed maximum brightness
+        result = min(result, vec3(1.0));
+    }
 
     // Output final color with material opacity
     FragColor = vec4(result, material.opacity);
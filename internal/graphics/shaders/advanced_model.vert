@@ -11,6 +11,13 @@ uniform mat4 uView;
 uniform mat4 uProjection;
 uniform mat3 uNormalMatrix;
 
+// Texture atlas region the texture coordinates map into
+uniform vec2 uTextureScale;
+uniform vec2 uTextureOffset;
+
+// Color of the owning player
+uniform vec3 uTeamColor;
+
 // Camera position for lighting calculations
 uniform vec3 uViewPosition;
 
@@ -19,6 +26,7 @@ out vec3 fragPos;        // World space position
 out vec3 fragNormal;     // World space normal
 out vec2 fragTexCoord;   // Texture coordinates
 out vec3 viewPos;        // Camera position in world space
+out vec3 fragTeamColor;  // Color of the owning player
 
 void main() {
     // Transform vertex position to world space
@@ -28,11 +36,12 @@ void main() {
     // Transform normal to world space using normal matrix
     fragNormal = normalize(uNormalMatrix * aNormal);
 
-    // Pass through texture coordinates
-    fragTexCoord = aTexCoord;
+    // Map texture coordinates into the atlas region
+    fragTexCoord = aTexCoord * uTextureScale + uTextureOffset;
 
-    // Pass camera position
+    // Pass camera position and team color
     viewPos = uViewPosition;
+    fragTeamColor = uTeamColor;
 
     // Transform to clip space for final position
     gl_Position = uProjection * uView * worldPos;
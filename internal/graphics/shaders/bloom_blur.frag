@@ -0,0 +1,21 @@
+#version 330 core
+
+in vec2 fragTexCoord;
+
+uniform sampler2D uImage;
+uniform vec2 uDirection;      // One texel along the blur axis
+
+out vec4 FragColor;
+
+// 9-tap Gaussian using linear filtering between texels (5 fetches)
+const float offsets[3] = float[](0.0, 1.3846153846, 3.2307692308);
+const float weights[3] = float[](0.2270270270, 0.3162162162, 0.0702702703);
+
+void main() {
+    vec3 result = texture(uImage, fragTexCoord).rgb * weights[0];
+    for (int i = 1; i < 3; i++) {
+        result += texture(uImage, fragTexCoord + uDirection * offsets[i]).rgb * weights[i];
+        result += texture(uImage, fragTexCoord - uDirection * offsets[i]).rgb * weights[i];
+    }
+    FragColor = vec4(result, 1.0);
+}
@@ -0,0 +1,17 @@
+#version 330 core
+
+in vec2 fragTexCoord;
+
+uniform sampler2D uScene;     // HDR scene color
+uniform float uThreshold;     // Brightness above which pixels glow
+
+out vec4 FragColor;
+
+void main() {
+    vec3 color = texture(uScene, fragTexCoord).rgb;
+
+    // Keep only the part above the threshold; emissive materials exceed 1.0
+    float brightness = max(color.r, max(color.g, color.b));
+    float contribution = max(brightness - uThreshold, 0.0) / max(brightness, 0.0001);
+    FragColor = vec4(color * contribution, 1.0);
+}
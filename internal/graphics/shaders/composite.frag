@@ -0,0 +1,43 @@
+#version 330 core
+
+in vec2 fragTexCoord;
+
+uniform sampler2D uScene;           // HDR scene color
+uniform sampler2D uBloom;           // Blurred bright parts
+uniform bool uBloomEnabled;
+uniform float uBloomIntensity;
+
+uniform sampler3D uLUT;             // Color grading lookup table
+uniform bool uGradingEnabled;
+uniform float uLUTSize;
+uniform vec3 uLUTDomainMin;
+uniform vec3 uLUTDomainMax;
+
+out vec4 FragColor;
+
+void main() {
+    vec3 color = texture(uScene, fragTexCoord).rgb;
+    if (uBloomEnabled) {
+        color += texture(uBloom, fragTexCoord).rgb * uBloomIntensity;
+    }
+
+    // Tone map into displayable range (Reinhard on the brightest channel keeps hues)
+    float peak = max(color.r, max(color.g, color.b));
+    if (peak > 1.0) {
+        color *= (1.0 + peak / 4.0) / (1.0 + peak);
+    }
+    color = clamp(color, 0.0, 1.0);
+
+    // Gamma correction, done here instead of in the scene shaders for HDR rendering
+    color = pow(color, vec3(1.0 / 2.2));
+
+    if (uGradingEnabled) {
+        // Sample texel centers so the table's first and last entries map to the domain ends
+        vec3 coord = clamp((color - uLUTDomainMin) / (uLUTDomainMax - uLUTDomainMin), 0.0, 1.0);
+        coord = coord * ((uLUTSize - 1.0) / uLUTSize) + 0.5 / uLUTSize;
+        color = texture(uLUT, coord).rgb;
+    }
+
+    // Luma in alpha for the FXAA pass
+    FragColor = vec4(color, dot(color, vec3(0.299, 0.587, 0.114)));
+}
@@ -0,0 +1,9 @@
+#version 330 core
+
+in vec4 fragColor;
+
+out vec4 FragColor;
+
+void main() {
+    FragColor = fragColor;
+}
@@ -0,0 +1,15 @@
+#version 330 core
+
+// Debug lines and overlays with per-vertex colors
+layout (location = 0) in vec3 aPosition;
+layout (location = 1) in vec4 aColor;
+
+uniform mat4 uView;
+uniform mat4 uProjection;
+
+out vec4 fragColor;
+
+void main() {
+    fragColor = aColor;
+    gl_Position = uProjection * uView * vec4(aPosition, 1.0);
+}
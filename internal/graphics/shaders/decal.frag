@@ -0,0 +1,53 @@
+#version 330 core
+
+in vec2 fragCoord;
+in vec4 fragParams;
+
+out vec4 FragColor;
+
+// hash returns a pseudo-random value in [0, 1) for a cell
+float hash(vec2 cell) {
+    return fract(sin(dot(cell, vec2(127.1, 311.7))) * 43758.5453);
+}
+
+// noise is smooth value noise
+float noise(vec2 p) {
+    vec2 cell = floor(p);
+    vec2 f = fract(p);
+    vec2 u = f * f * (3.0 - 2.0 * f);
+    return mix(mix(hash(cell), hash(cell + vec2(1.0, 0.0)), u.x),
+               mix(hash(cell + vec2(0.0, 1.0)), hash(cell + vec2(1.0, 1.0)), u.x), u.y);
+}
+
+void main() {
+    int kind = int(fragParams.x + 0.5);
+    float opacity = fragParams.y;
+    vec2 p = fragCoord + fragParams.z;
+
+    // Ragged edge: the radius wobbles with the noise
+    float edge = 0.75 + 0.25 * noise(fragCoord * 3.0 + fragParams.z * 10.0);
+    float distance = length(fragCoord) / edge;
+    if (distance >= 1.0) {
+        discard;
+    }
+    float grain = noise(p * 8.0);
+
+    vec3 color;
+    float alpha;
+    if (kind == 1) {
+        // Crater: dark pit with a lighter rim of thrown-up earth
+        float rim = smoothstep(0.6, 0.85, distance) * (1.0 - smoothstep(0.85, 1.0, distance));
+        color = mix(vec3(0.08, 0.06, 0.05), vec3(0.35, 0.28, 0.2), rim) * (0.8 + 0.4 * grain);
+        alpha = 0.9 * (1.0 - smoothstep(0.9, 1.0, distance));
+    } else if (kind == 2) {
+        // Rubble: grey stone chunks over a dusty patch
+        float chunks = step(0.6, noise(p * 14.0));
+        color = mix(vec3(0.3, 0.27, 0.24), vec3(0.5, 0.48, 0.45), chunks) * (0.8 + 0.3 * grain);
+        alpha = mix(0.5, 0.95, chunks) * (1.0 - smoothstep(0.7, 1.0, distance));
+    } else {
+        // Scorch: soot fading out towards the edge
+        color = vec3(0.05, 0.04, 0.03) * (0.7 + 0.6 * grain);
+        alpha = 0.8 * (1.0 - smoothstep(0.2, 1.0, distance));
+    }
+    FragColor = vec4(color, alpha * opacity);
+}
@@ -0,0 +1,18 @@
+#version 330 core
+
+// Ground decals: scorch marks, craters and rubble
+layout (location = 0) in vec3 aPosition;
+layout (location = 1) in vec2 aTexCoord; // -1..1 across the decal
+layout (location = 2) in vec4 aParams;   // Kind, opacity, seed, unused
+
+uniform mat4 uView;
+uniform mat4 uProjection;
+
+out vec2 fragCoord;
+out vec4 fragParams;
+
+void main() {
+    fragCoord = aTexCoord;
+    fragParams = aParams;
+    gl_Position = uProjection * uView * vec4(aPosition, 1.0);
+}
@@ -0,0 +1,31 @@
+#version 330 core
+
+in vec2 fragCoord;
+in vec3 fragColor;
+in float fragFade;
+in float fragSeed;
+
+out vec4 FragColor;
+
+// hash returns a pseudo-random value in [0, 1) for a cell
+float hash(vec2 cell) {
+    return fract(sin(dot(cell, vec2(127.1, 311.7))) * 43758.5453);
+}
+
+void main() {
+    // Dithered fade, so grass thins out with distance instead of popping
+    if (fragFade < hash(gl_FragCoord.xy)) {
+        discard;
+    }
+
+    // Four blades across the card, each of its own height, narrowing to the tip
+    float across = (fragCoord.x * 0.5 + 0.5) * 4.0 + fragSeed;
+    float blade = abs(fract(across) - 0.5) * 2.0;
+    float tip = 0.6 + 0.4 * hash(vec2(floor(across), fragSeed));
+    if (fragCoord.y > tip || blade > 1.0 - fragCoord.y / tip) {
+        discard;
+    }
+
+    // Darker at the roots, lit at the tips
+    FragColor = vec4(fragColor * mix(0.45, 1.1, fragCoord.y / tip), 1.0);
+}
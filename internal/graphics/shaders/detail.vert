@@ -0,0 +1,36 @@
+#version 330 core
+
+// Instanced grass tufts: crossed cards of blades swaying in the wind
+layout (location = 0) in vec3 aPosition;  // Card corner of a tuft one unit high
+layout (location = 1) in vec2 aTexCoord;  // -1..1 across the card, 0..1 up the blades
+layout (location = 2) in vec4 aInstance;  // Root position and height
+layout (location = 3) in vec4 aAppearance; // Rotation around the vertical axis and color
+
+uniform mat4 uView;
+uniform mat4 uProjection;
+uniform vec2 uFocus; // Ground point the camera looks at
+uniform vec2 uFade;  // Distance from the focus the fade starts and ends at
+uniform float uTime; // Seconds, for the wind
+
+out vec2 fragCoord;
+out vec3 fragColor;
+out float fragFade;
+out float fragSeed;
+
+void main() {
+    float height = aInstance.w;
+    float c = cos(aAppearance.x);
+    float s = sin(aAppearance.x);
+    vec3 local = aPosition * height;
+    vec3 position = aInstance.xyz + vec3(c * local.x - s * local.z, local.y, s * local.x + c * local.z);
+
+    // Tips sway in the wind, roots stay put
+    float sway = sin(uTime * 1.7 + aInstance.x * 0.37 + aInstance.z * 0.23) * 0.15 * height * aTexCoord.y * aTexCoord.y;
+    position.xz += vec2(sway, sway * 0.5);
+
+    fragCoord = aTexCoord;
+    fragColor = aAppearance.yzw;
+    fragFade = 1.0 - smoothstep(uFade.x, uFade.y, distance(aInstance.xz, uFocus));
+    fragSeed = fract(aInstance.x * 12.9898 + aInstance.z * 78.233);
+    gl_Position = uProjection * uView * vec4(position, 1.0);
+}
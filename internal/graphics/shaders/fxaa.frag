@@ -0,0 +1,44 @@
+#version 330 core
+
+in vec2 fragTexCoord;
+
+uniform sampler2D uImage;     // Tone mapped color with luma in alpha
+uniform vec2 uTexelSize;      // 1 / resolution
+uniform float uSpanMax;       // Longest edge blur, in pixels
+uniform float uReduceMul;     // Damping of the blur direction
+
+out vec4 FragColor;
+
+const float REDUCE_MIN = 1.0 / 128.0;
+
+void main() {
+    // Luma of the pixel and its diagonal neighbours
+    float lumaNW = textureOffset(uImage, fragTexCoord, ivec2(-1, -1)).a;
+    float lumaNE = textureOffset(uImage, fragTexCoord, ivec2(1, -1)).a;
+    float lumaSW = textureOffset(uImage, fragTexCoord, ivec2(-1, 1)).a;
+    float lumaSE = textureOffset(uImage, fragTexCoord, ivec2(1, 1)).a;
+    vec4 center = texture(uImage, fragTexCoord);
+    float lumaM = center.a;
+
+    float lumaMin = min(lumaM, min(min(lumaNW, lumaNE), min(lumaSW, lumaSE)));
+    float lumaMax = max(lumaM, max(max(lumaNW, lumaNE), max(lumaSW, lumaSE)));
+
+    // Blur along the edge, perpendicular to the luma gradient
+    vec2 dir = vec2(-((lumaNW + lumaNE) - (lumaSW + lumaSE)), (lumaNW + lumaSW) - (lumaNE + lumaSE));
+    float dirReduce = max((lumaNW + lumaNE + lumaSW + lumaSE) * 0.25 * uReduceMul, REDUCE_MIN);
+    float rcpDirMin = 1.0 / (min(abs(dir.x), abs(dir.y)) + dirReduce);
+    dir = clamp(dir * rcpDirMin, vec2(-uSpanMax), vec2(uSpanMax)) * uTexelSize;
+
+    vec3 rgbA = 0.5 * (texture(uImage, fragTexCoord + dir * (1.0 / 3.0 - 0.5)).rgb +
+                       texture(uImage, fragTexCoord + dir * (2.0 / 3.0 - 0.5)).rgb);
+    vec3 rgbB = rgbA * 0.5 + 0.25 * (texture(uImage, fragTexCoord + dir * -0.5).rgb +
+                                      texture(uImage, fragTexCoord + dir * 0.5).rgb);
+
+    // Fall back to the narrower blur where the wide one crosses another edge
+    float lumaB = dot(rgbB, vec3(0.299, 0.587, 0.114));
+    if (lumaB < lumaMin || lumaB > lumaMax) {
+        FragColor = vec4(rgbA, 1.0);
+    } else {
+        FragColor = vec4(rgbB, 1.0);
+    }
+}
@@ -0,0 +1,48 @@
+#version 330 core
+
+// Input vertex attributes
+layout (location = 0) in vec3 aPosition;
+layout (location = 1) in vec3 aNormal;
+layout (location = 2) in vec2 aTexCoord;
+
+// Per-instance attributes
+layout (location = 3) in mat4 aInstanceModel;      // Model matrix (locations 3-6)
+layout (location = 7) in vec3 aInstanceTeamColor;  // Color of the owning player
+
+// Transformation matrices
+uniform mat4 uView;
+uniform mat4 uProjection;
+
+// Texture atlas region the texture coordinates map into
+uniform vec2 uTextureScale;
+uniform vec2 uTextureOffset;
+
+// Camera position for lighting calculations
+uniform vec3 uViewPosition;
+
+// Output to fragment shader (same as advanced_model.vert)
+out vec3 fragPos;        // World space position
+out vec3 fragNormal;     // World space normal
+out vec2 fragTexCoord;   // Texture coordinates
+out vec3 viewPos;        // Camera position in world space
+out vec3 fragTeamColor;  // Color of the owning player
+
+void main() {
+    // Transform vertex position to world space
+    vec4 worldPos = aInstanceModel * vec4(aPosition, 1.0);
+    fragPos = worldPos.xyz;
+
+    // Instance transforms are rotations, translations and uniform scales,
+    // so the upper 3x3 of the model matrix transforms normals correctly
+    fragNormal = normalize(mat3(aInstanceModel) * aNormal);
+
+    // Map texture coordinates into the atlas region
+    fragTexCoord = aTexCoord * uTextureScale + uTextureOffset;
+
+    // Pass camera position and team color
+    viewPos = uViewPosition;
+    fragTeamColor = aInstanceTeamColor;
+
+    // Transform to clip space for final position
+    gl_Position = uProjection * uView * worldPos;
+}
@@ -8,6 +8,8 @@ in vec2 fragTexCoord;   // Texture coordinates
 // Uniforms
 uniform sampler2D uDiffuseTexture;   // Diffuse texture
 uniform bool uUseTexture;            // Whether to use texture
+uniform bool uUseTeamColor;          // Texture alpha masks the team colored regions
+uniform vec3 uTeamColor;             // Color of the owning player
 uniform vec3 uDiffuseColor;          // Diffuse material color
 uniform vec3 uSpecularColor;         // Specular material color
 uniform float uSpecularPower;        // Specular shininess
@@ -18,6 +20,9 @@ uniform vec3 uLightDirection;        // Directional light direction (normalized)
 uniform vec3 uLightColor;            // Light color
 uniform vec3 uAmbientColor;          // Ambient light color
 
+// Post-processing tone maps and gamma corrects the HDR output itself
+uniform bool uHDROutput;
+
 // Output
 out vec4 FragColor;
 
@@ -25,7 +30,11 @@ void main() {
     // Sample texture or use solid color
     vec3 baseColor;
     if (uUseTexture) {
-        baseColor = texture(uDiffuseTexture, fragTexCoord).rgb * uDiffuseColor;
+        vec4 textureColor = texture(uDiffuseTexture, fragTexCoord);
+        if (uUseTeamColor) {
+            textureColor.rgb = mix(uTeamColor, textureColor.rgb, textureColor.a);
+        }
+        baseColor = textureColor.rgb * uDiffuseColor;
     } else {
         baseColor = uDiffuseColor;
     }
@@ -50,7 +59,9 @@ void main() {
     vec3 result = ambient + diffuse + specular;
 
     // Apply gamma correction (simple)
-    result = pow(result, vec3(1.0 / 2.2));
+    if (!uHDROutput) {
+        result = pow(result, vec3(1.0 / 2.2));
+    }
 
     // Output final color with alpha
     FragColor = vec4(result, uOpacity);
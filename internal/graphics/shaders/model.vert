@@ -11,6 +11,10 @@ uniform mat4 uView;
 uniform mat4 uProjection;
 uniform mat3 uNormalMatrix;
 
+// Texture atlas region the texture coordinates map into
+uniform vec2 uTextureScale;
+uniform vec2 uTextureOffset;
+
 // Output to fragment shader
 out vec3 fragPos;        // World space position
 out vec3 fragNormal;     // World space normal
@@ -24,8 +28,8 @@ void main() {
     // Transform normal to world space
     fragNormal = normalize(uNormalMatrix * aNormal);
 
-    // Pass through texture coordinates
-    fragTexCoord = aTexCoord;
+    // Map texture coordinates into the atlas region
+    fragTexCoord = aTexCoord * uTextureScale + uTextureOffset;
 
     // Transform to clip space
     gl_Position = uProjection * uView * worldPos;
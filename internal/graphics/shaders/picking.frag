@@ -0,0 +1,10 @@
+#version 330 core
+
+// Object IDs for GPU picking, packed into the color channels
+in vec3 fragColor;
+
+out vec4 FragColor;
+
+void main() {
+    FragColor = vec4(fragColor, 1.0);
+}
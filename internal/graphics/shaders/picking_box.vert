@@ -0,0 +1,16 @@
+#version 330 core
+
+// Boxes standing in the picking buffer for objects drawn without an instanced model
+layout (location = 0) in vec3 aPosition;
+
+uniform mat4 uModel;
+uniform mat4 uView;
+uniform mat4 uProjection;
+uniform vec3 uColor; // Packed object ID
+
+out vec3 fragColor;
+
+void main() {
+    fragColor = uColor;
+    gl_Position = uProjection * uView * uModel * vec4(aPosition, 1.0);
+}
@@ -0,0 +1,10 @@
+#version 330 core
+
+// Full screen triangle generated from the vertex ID; no vertex buffers needed
+out vec2 fragTexCoord;
+
+void main() {
+    vec2 position = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+    fragTexCoord = position;
+    gl_Position = vec4(position * 2.0 - 1.0, 0.0, 1.0);
+}
@@ -0,0 +1,13 @@
+#version 330 core
+
+in vec3 fragColor;
+
+uniform float uOpacity;
+
+out vec4 FragColor;
+
+void main() {
+    // Diagonal hatching tells a silhouette apart from a unit in plain view
+    float hatch = step(2.0, mod(gl_FragCoord.x + gl_FragCoord.y, 6.0));
+    FragColor = vec4(fragColor * mix(0.7, 1.0, hatch), uOpacity);
+}
@@ -0,0 +1,18 @@
+#version 330 core
+
+// Flat silhouettes of instanced models, for units hidden behind buildings and terrain
+layout (location = 0) in vec3 aPosition;
+
+// Per-instance attributes, as for instanced_model.vert
+layout (location = 3) in mat4 aInstanceModel;  // Model matrix (locations 3-6)
+layout (location = 7) in vec3 aInstanceColor;  // Silhouette color
+
+uniform mat4 uView;
+uniform mat4 uProjection;
+
+out vec3 fragColor;
+
+void main() {
+    fragColor = aInstanceColor;
+    gl_Position = uProjection * uView * aInstanceModel * vec4(aPosition, 1.0);
+}
@@ -0,0 +1,12 @@
+#version 330 core
+
+in vec2 texCoord;
+in vec4 tint;
+
+uniform sampler2D uTexture;
+
+out vec4 FragColor;
+
+void main() {
+    FragColor = texture(uTexture, texCoord) * tint;
+}
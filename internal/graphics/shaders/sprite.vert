@@ -0,0 +1,18 @@
+#version 330 core
+
+// HUD sprites in screen pixels, origin at the top left
+layout (location = 0) in vec2 aPosition;
+layout (location = 1) in vec2 aTexCoord;
+layout (location = 2) in vec4 aColor;
+
+uniform vec2 uScreenSize;
+
+out vec2 texCoord;
+out vec4 tint;
+
+void main() {
+    texCoord = aTexCoord;
+    tint = aColor;
+    vec2 ndc = aPosition / uScreenSize * 2.0 - 1.0;
+    gl_Position = vec4(ndc.x, -ndc.y, 0.0, 1.0);
+}
@@ -0,0 +1,41 @@
+#version 330 core
+
+in vec3 fragPos;
+in vec3 fragNormal;
+in vec2 fragTexCoord;
+in float fragSurfaceType;
+
+uniform vec3 uLightDirection; // Towards the light
+uniform vec3 uLightColor;
+uniform vec3 uAmbientColor;
+
+out vec4 FragColor;
+
+// hash returns a pseudo-random value in [0, 1) for a cell
+float hash(vec2 cell) {
+    return fract(sin(dot(cell, vec2(127.1, 311.7))) * 43758.5453);
+}
+
+// surfaceColor returns the flat color of a map surface type
+vec3 surfaceColor(int surface) {
+    if (surface == 2) {
+        return vec3(0.3, 0.45, 0.2);  // Secondary grass
+    } else if (surface == 3) {
+        return vec3(0.5, 0.42, 0.3);  // Road
+    } else if (surface == 4) {
+        return vec3(0.48, 0.47, 0.45); // Stone
+    } else if (surface == 5) {
+        return vec3(0.42, 0.33, 0.22); // Ground
+    }
+    return vec3(0.33, 0.52, 0.22);    // Grass
+}
+
+void main() {
+    vec3 color = surfaceColor(int(fragSurfaceType + 0.5));
+
+    // A little grain per quarter tile keeps large flat areas from looking painted
+    color *= 0.92 + 0.16 * hash(floor(fragTexCoord * 4.0));
+
+    float diffuse = max(dot(normalize(fragNormal), normalize(uLightDirection)), 0.0);
+    FragColor = vec4(color * (uAmbientColor + uLightColor * diffuse), 1.0);
+}
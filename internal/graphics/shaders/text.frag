@@ -0,0 +1,21 @@
+#version 330 core
+
+// Signed distance field text: 0.5 is the glyph edge, with a dark outline
+// just outside it so labels stay readable over any terrain
+in vec2 texCoord;
+in vec4 textColor;
+
+uniform sampler2D uAtlas;
+
+out vec4 FragColor;
+
+const float outlineEdge = 0.3;
+
+void main() {
+    float distance = texture(uAtlas, texCoord).r;
+    float width = max(fwidth(distance), 0.001);
+    float fill = smoothstep(0.5 - width, 0.5 + width, distance);
+    float outline = smoothstep(outlineEdge - width, outlineEdge + width, distance);
+    vec3 color = mix(vec3(0.0), textColor.rgb, fill);
+    FragColor = vec4(color, textColor.a * outline);
+}
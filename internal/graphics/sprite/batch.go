@@ -0,0 +1,159 @@
+// Package sprite batches 2D drawing for the HUD: textured and plain quads,
+// icons and nine-patch panels, in window pixels with Y growing downwards.
+// It builds vertex data only, so HUD layout can be tested without a GPU.
+package sprite
+
+// VertexFloats is the number of floats per vertex: position, texture
+// coordinates and RGBA color
+const VertexFloats = 8
+
+// Texture identifies a texture to the renderer; NoTexture draws plain color
+type Texture uint32
+
+// NoTexture fills quads with their color alone
+const NoTexture Texture = 0
+
+// Rect is an area in pixels
+type Rect struct {
+	X, Y, W, H float32
+}
+
+// Inset returns the rect shrunk by a margin on every side
+func (r Rect) Inset(margin float32) Rect {
+	return Rect{X: r.X + margin, Y: r.Y + margin, W: r.W - 2*margin, H: r.H - 2*margin}
+}
+
+// Contains reports whether a point is inside the rect
+func (r Rect) Contains(x, y float32) bool {
+	return x >= r.X && y >= r.Y && x < r.X+r.W && y < r.Y+r.H
+}
+
+// UV is an area of a texture in texture coordinates, V0 at the top
+type UV struct {
+	U0, V0, U1, V1 float32
+}
+
+// FullUV covers a whole texture
+var FullUV = UV{U0: 0, V0: 0, U1: 1, V1: 1}
+
+// Color is an RGBA color with components from 0 to 1
+type Color struct {
+	R, G, B, A float32
+}
+
+// White draws textures unchanged
+var White = Color{R: 1, G: 1, B: 1, A: 1}
+
+// NinePatch is a texture whose corners keep their size when stretched; the
+// edges stretch along one axis and the centre along both
+type NinePatch struct {
+	Texture                  Texture
+	Width, Height            int     // Texture size in texels
+	Left, Top, Right, Bottom int     // Border sizes in texels
+	Scale                    float32 // Screen pixels per texel of border, 1 if zero
+}
+
+// DrawCall is a run of vertices drawn with one texture
+type DrawCall struct {
+	Texture Texture
+	First   int // Index of the first vertex
+	Count   int // Number of vertices
+}
+
+// Batch collects quads in drawing order. Consecutive quads with the same
+// texture share a draw call.
+type Batch struct {
+	vertices []float32
+	calls    []DrawCall
+}
+
+// NewBatch creates an empty batch
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// Reset empties the batch, keeping its memory for the next frame
+func (b *Batch) Reset() {
+	b.vertices = b.vertices[:0]
+	b.calls = b.calls[:0]
+}
+
+// Vertices returns the vertex data, VertexFloats per vertex
+func (b *Batch) Vertices() []float32 {
+	return b.vertices
+}
+
+// DrawCalls returns the draw calls in order
+func (b *Batch) DrawCalls() []DrawCall {
+	return b.calls
+}
+
+// Empty reports whether nothing has been drawn
+func (b *Batch) Empty() bool {
+	return len(b.calls) == 0
+}
+
+// Fill draws a rect in plain color
+func (b *Batch) Fill(rect Rect, color Color) {
+	b.Quad(rect, NoTexture, FullUV, color)
+}
+
+// Icon draws a whole texture into a rect
+func (b *Batch) Icon(rect Rect, texture Texture, tint Color) {
+	b.Quad(rect, texture, FullUV, tint)
+}
+
+// Quad draws part of a texture into a rect, tinted by a color
+func (b *Batch) Quad(rect Rect, texture Texture, uv UV, color Color) {
+	if rect.W <= 0 || rect.H <= 0 {
+		return
+	}
+	if n := len(b.calls); n == 0 || b.calls[n-1].Texture != texture {
+		b.calls = append(b.calls, DrawCall{Texture: texture, First: len(b.vertices) / VertexFloats})
+	}
+	corners := [4][4]float32{
+		{rect.X, rect.Y, uv.U0, uv.V0},
+		{rect.X + rect.W, rect.Y, uv.U1, uv.V0},
+		{rect.X + rect.W, rect.Y + rect.H, uv.U1, uv.V1},
+		{rect.X, rect.Y + rect.H, uv.U0, uv.V1},
+	}
+	for _, corner := range [6]int{0, 1, 2, 2, 3, 0} {
+		c := corners[corner]
+		b.vertices = append(b.vertices, c[0], c[1], c[2], c[3], color.R, color.G, color.B, color.A)
+	}
+	b.calls[len(b.calls)-1].Count += 6
+}
+
+// Panel draws a nine-patch stretched over a rect. Borders shrink evenly when
+// the rect is smaller than them.
+func (b *Batch) Panel(rect Rect, patch NinePatch, tint Color) {
+	if patch.Width <= 0 || patch.Height <= 0 {
+		return
+	}
+	scale := patch.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	left, right := float32(patch.Left)*scale, float32(patch.Right)*scale
+	top, bottom := float32(patch.Top)*scale, float32(patch.Bottom)*scale
+	if left+right > rect.W {
+		fit := rect.W / (left + right)
+		left, right = left*fit, right*fit
+	}
+	if top+bottom > rect.H {
+		fit := rect.H / (top + bottom)
+		top, bottom = top*fit, bottom*fit
+	}
+
+	xs := [4]float32{rect.X, rect.X + left, rect.X + rect.W - right, rect.X + rect.W}
+	ys := [4]float32{rect.Y, rect.Y + top, rect.Y + rect.H - bottom, rect.Y + rect.H}
+	w, h := float32(patch.Width), float32(patch.Height)
+	us := [4]float32{0, float32(patch.Left) / w, 1 - float32(patch.Right)/w, 1}
+	vs := [4]float32{0, float32(patch.Top) / h, 1 - float32(patch.Bottom)/h, 1}
+	for row := 0; row < 3; row++ {
+		for column := 0; column < 3; column++ {
+			b.Quad(Rect{X: xs[column], Y: ys[row], W: xs[column+1] - xs[column], H: ys[row+1] - ys[row]},
+				patch.Texture, UV{U0: us[column], V0: vs[row], U1: us[column+1], V1: vs[row+1]}, tint)
+		}
+	}
+}
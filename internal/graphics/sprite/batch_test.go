@@ -0,0 +1,62 @@
+package sprite
+
+import "testing"
+
+// TestBatchDrawCalls tests that quads share draw calls by texture, in order
+func TestBatchDrawCalls(t *testing.T) {
+	batch := NewBatch()
+	batch.Fill(Rect{X: 0, Y: 0, W: 10, H: 10}, Color{A: 1})
+	batch.Fill(Rect{X: 10, Y: 0, W: 10, H: 10}, Color{A: 1})
+	batch.Icon(Rect{X: 0, Y: 10, W: 16, H: 16}, 7, White)
+	batch.Fill(Rect{X: 0, Y: 30, W: 0, H: 10}, Color{A: 1}) // Empty, dropped
+	batch.Fill(Rect{X: 0, Y: 30, W: 5, H: 5}, Color{A: 1})
+
+	calls := batch.DrawCalls()
+	want := []DrawCall{{NoTexture, 0, 12}, {7, 12, 6}, {NoTexture, 18, 6}}
+	if len(calls) != len(want) {
+		t.Fatalf("Expected %d draw calls, got %+v", len(want), calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("Draw call %d: expected %+v, got %+v", i, want[i], calls[i])
+		}
+	}
+	if len(batch.Vertices()) != 24*VertexFloats {
+		t.Errorf("Expected 24 vertices, got %d floats", len(batch.Vertices()))
+	}
+
+	batch.Reset()
+	if !batch.Empty() || len(batch.Vertices()) != 0 {
+		t.Error("Expected Reset to empty the batch")
+	}
+}
+
+// TestPanel tests that nine-patch corners keep their size
+func TestPanel(t *testing.T) {
+	batch := NewBatch()
+	patch := NinePatch{Texture: 3, Width: 32, Height: 32, Left: 8, Top: 8, Right: 8, Bottom: 8, Scale: 2}
+	batch.Panel(Rect{X: 100, Y: 50, W: 200, H: 40}, patch, White)
+
+	vertices := batch.Vertices()
+	if len(vertices) != 9*6*VertexFloats {
+		t.Fatalf("Expected 9 quads, got %d floats", len(vertices))
+	}
+	// The first vertex of each quad is its top left corner
+	corner := func(quad int) (x, y, u, v float32) {
+		i := quad * 6 * VertexFloats
+		return vertices[i], vertices[i+1], vertices[i+2], vertices[i+3]
+	}
+	if x, y, u, v := corner(4); x != 116 || y != 66 || u != 0.25 || v != 0.25 {
+		t.Errorf("Expected the centre at (116, 66) from uv 0.25, got (%v, %v) from (%v, %v)", x, y, u, v)
+	}
+	if x, y, _, _ := corner(8); x != 284 || y != 74 {
+		t.Errorf("Expected the bottom right corner at (284, 74), got (%v, %v)", x, y)
+	}
+
+	// A panel shorter than its borders squeezes them
+	batch.Reset()
+	batch.Panel(Rect{X: 0, Y: 0, W: 100, H: 16}, patch, White)
+	if _, y, _, _ := corner(3); y != 8 {
+		t.Errorf("Expected the top border squeezed to 8 pixels, got %v", y)
+	}
+}
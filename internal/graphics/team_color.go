@@ -0,0 +1,73 @@
+//go:build !js
+
+package graphics
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// TeamColors is the player color palette, indexed by player ID - 1. Meshes
+// flagged with the G3D custom color property show the color of their owner
+// wherever the diffuse texture's alpha channel is below one: the alpha is the
+// team color mask, 0 being pure team color and 1 the texture color.
+var TeamColors = []mgl32.Vec3{
+	{0.0, 0.4, 1.0}, // Blue
+	{1.0, 0.0, 0.0}, // Red
+	{0.0, 1.0, 0.0}, // Green
+	{1.0, 1.0, 0.0}, // Yellow
+	{1.0, 0.0, 1.0}, // Magenta
+	{0.0, 1.0, 1.0}, // Cyan
+	{1.0, 0.5, 0.0}, // Orange
+	{0.5, 0.0, 0.5}, // Purple
+}
+
+// NeutralTeamColor is used for objects without an owning player
+var NeutralTeamColor = mgl32.Vec3{0.8, 0.8, 0.8}
+
+// TeamColor returns the color of a player; IDs past the palette wrap around
+func TeamColor(playerID int) mgl32.Vec3 {
+	if playerID <= 0 {
+		return NeutralTeamColor
+	}
+	return TeamColors[(playerID-1)%len(TeamColors)]
+}
+
+// BlendTeamColor returns the color a team-colored texel is drawn with, the
+// CPU counterpart of the model shaders' mask blending
+func BlendTeamColor(texel mgl32.Vec4, teamColor mgl32.Vec3) mgl32.Vec3 {
+	mask := mgl32.Clamp(texel.W(), 0, 1)
+	return teamColor.Mul(1 - mask).Add(texel.Vec3().Mul(mask))
+}
+
+// TextureRegion is a sub-rectangle of a texture atlas in normalized texture
+// coordinates; mesh texture coordinates are mapped into it
+type TextureRegion struct {
+	Offset mgl32.Vec2 // Lower left corner
+	Scale  mgl32.Vec2 // Size
+}
+
+// FullTexture is the region covering a whole texture
+var FullTexture = TextureRegion{Scale: mgl32.Vec2{1, 1}}
+
+// AtlasRegion returns the region of a rectangle given in pixels within an atlas
+func AtlasRegion(x, y, width, height, atlasWidth, atlasHeight int) TextureRegion {
+	if atlasWidth <= 0 || atlasHeight <= 0 {
+		return FullTexture
+	}
+	w, h := float32(atlasWidth), float32(atlasHeight)
+	return TextureRegion{
+		Offset: mgl32.Vec2{float32(x) / w, float32(y) / h},
+		Scale:  mgl32.Vec2{float32(width) / w, float32(height) / h},
+	}
+}
+
+// IsZero reports whether the region is unset; unset regions mean the full texture
+func (tr TextureRegion) IsZero() bool {
+	return tr.Scale == (mgl32.Vec2{}) && tr.Offset == (mgl32.Vec2{})
+}
+
+// Map converts mesh texture coordinates into atlas texture coordinates
+func (tr TextureRegion) Map(uv mgl32.Vec2) mgl32.Vec2 {
+	if tr.IsZero() {
+		return uv
+	}
+	return mgl32.Vec2{uv.X()*tr.Scale.X() + tr.Offset.X(), uv.Y()*tr.Scale.Y() + tr.Offset.Y()}
+}
@@ -0,0 +1,76 @@
+//go:build !js
+
+package graphics
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TestTeamColor tests the player color palette lookup
+func TestTeamColor(t *testing.T) {
+	if TeamColor(1) != TeamColors[0] {
+		t.Errorf("Expected player 1 to get the first palette color, got %v", TeamColor(1))
+	}
+	if TeamColor(len(TeamColors)+2) != TeamColors[1] {
+		t.Errorf("Expected player IDs past the palette to wrap around, got %v", TeamColor(len(TeamColors)+2))
+	}
+	if TeamColor(0) != NeutralTeamColor || TeamColor(-1) != NeutralTeamColor {
+		t.Error("Expected objects without a player to be neutral")
+	}
+}
+
+// TestBlendTeamColor tests the texture alpha team color mask
+func TestBlendTeamColor(t *testing.T) {
+	team := mgl32.Vec3{1, 0, 0}
+	texel := mgl32.Vec4{0, 0, 1, 1}
+
+	if got := BlendTeamColor(texel, team); got != (mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("Expected an opaque texel to keep its color, got %v", got)
+	}
+	texel[3] = 0
+	if got := BlendTeamColor(texel, team); got != team {
+		t.Errorf("Expected a fully masked texel to show the team color, got %v", got)
+	}
+	texel[3] = 0.5
+	if got := BlendTeamColor(texel, team); !got.ApproxEqual(mgl32.Vec3{0.5, 0, 0.5}) {
+		t.Errorf("Expected a half masked texel to blend, got %v", got)
+	}
+}
+
+// TestAtlasRegion tests mapping texture coordinates into an atlas region
+func TestAtlasRegion(t *testing.T) {
+	region := AtlasRegion(256, 0, 256, 512, 1024, 512)
+	if !region.Offset.ApproxEqual(mgl32.Vec2{0.25, 0}) || !region.Scale.ApproxEqual(mgl32.Vec2{0.25, 1}) {
+		t.Errorf("Unexpected region: %+v", region)
+	}
+	if got := region.Map(mgl32.Vec2{1, 0.5}); !got.ApproxEqual(mgl32.Vec2{0.5, 0.5}) {
+		t.Errorf("Expected (1, 0.5) to map to (0.5, 0.5), got %v", got)
+	}
+
+	var unset TextureRegion
+	if got := unset.Map(mgl32.Vec2{0.3, 0.7}); got != (mgl32.Vec2{0.3, 0.7}) {
+		t.Errorf("Expected an unset region to leave coordinates unchanged, got %v", got)
+	}
+	if AtlasRegion(0, 0, 1, 1, 0, 0) != FullTexture {
+		t.Error("Expected an empty atlas to fall back to the full texture")
+	}
+}
+
+// TestEffectiveMaterialTeamColor tests that custom color meshes carry the team color into the material
+func TestEffectiveMaterialTeamColor(t *testing.T) {
+	model := &Model{
+		Name:          "unit",
+		Material:      Material{Opacity: 1, CustomColor: true},
+		TeamColor:     TeamColor(2),
+		TextureRegion: AtlasRegion(0, 0, 64, 64, 128, 128),
+	}
+	material := model.GetEffectiveMaterial()
+	if !material.TeamColorMask || material.TeamColor != TeamColor(2) {
+		t.Errorf("Expected team color mask with player 2 color, got %t %v", material.TeamColorMask, material.TeamColor)
+	}
+	if !material.TextureScale.ApproxEqual(mgl32.Vec2{0.5, 0.5}) {
+		t.Errorf("Expected the atlas region as texture transform, got %v", material.TextureScale)
+	}
+}
@@ -0,0 +1,114 @@
+package text
+
+import (
+	"fmt"
+	"math"
+)
+
+// Atlas defaults
+const (
+	DefaultAtlasScale  = 8  // Atlas texels per font pixel
+	DefaultAtlasSpread = 1  // Distance, in font pixels, covered by the field on each side of an edge
+	atlasColumns       = 16 // Glyph cells per atlas row
+)
+
+// Glyph locates one glyph in an atlas. The cell covers the glyph plus the
+// spread on every side, so quads drawn from it are larger than the glyph.
+type Glyph struct {
+	Rune           rune
+	U0, V0, U1, V1 float32 // Texture coordinates of the cell, V0 at the top
+}
+
+// Atlas is a single-channel SDF texture of every glyph of a font. A texel
+// of 128 lies on a glyph edge; higher values are inside the glyph.
+type Atlas struct {
+	Font   *BitmapFont
+	Width  int    // Texture width in texels
+	Height int    // Texture height in texels
+	Scale  int    // Texels per font pixel
+	Spread int    // Font pixels of padding around each glyph
+	Pixels []byte // Width*Height distance values, row by row from the top
+
+	glyphs map[rune]Glyph
+}
+
+// BuildAtlas renders the SDF atlas of a font. Distances are exact for the
+// font's square pixels, so no high resolution master is needed.
+func BuildAtlas(font *BitmapFont, scale, spread int) (*Atlas, error) {
+	if font == nil || len(font.Columns) == 0 {
+		return nil, fmt.Errorf("font has no glyphs")
+	}
+	if font.Height > 8 {
+		return nil, fmt.Errorf("font %s is %d pixels high; at most 8 are supported", font.Name, font.Height)
+	}
+	if scale < 1 || spread < 1 {
+		return nil, fmt.Errorf("invalid atlas scale %d or spread %d", scale, spread)
+	}
+
+	cellW := (font.Width + 2*spread) * scale
+	cellH := (font.Height + 2*spread) * scale
+	rows := (len(font.Columns) + atlasColumns - 1) / atlasColumns
+	atlas := &Atlas{
+		Font:   font,
+		Width:  cellW * atlasColumns,
+		Height: cellH * rows,
+		Scale:  scale,
+		Spread: spread,
+		glyphs: make(map[rune]Glyph, len(font.Columns)),
+	}
+	atlas.Pixels = make([]byte, atlas.Width*atlas.Height)
+
+	for i := range font.Columns {
+		r := font.First + rune(i)
+		cellX := (i % atlasColumns) * cellW
+		cellY := (i / atlasColumns) * cellH
+		for ty := 0; ty < cellH; ty++ {
+			for tx := 0; tx < cellW; tx++ {
+				// Texel centre in font pixels, relative to the glyph's top left
+				x := (float64(tx)+0.5)/float64(scale) - float64(spread)
+				y := (float64(ty)+0.5)/float64(scale) - float64(spread)
+				d := signedDistance(font, r, x, y, spread)
+				value := 0.5 + d/(2*float64(spread))
+				value = math.Max(0, math.Min(1, value))
+				atlas.Pixels[(cellY+ty)*atlas.Width+cellX+tx] = byte(math.Round(value * 255))
+			}
+		}
+		atlas.glyphs[r] = Glyph{
+			Rune: r,
+			U0:   float32(cellX) / float32(atlas.Width),
+			V0:   float32(cellY) / float32(atlas.Height),
+			U1:   float32(cellX+cellW) / float32(atlas.Width),
+			V1:   float32(cellY+cellH) / float32(atlas.Height),
+		}
+	}
+	return atlas, nil
+}
+
+// Glyph returns where a rune is in the atlas
+func (a *Atlas) Glyph(r rune) (Glyph, bool) {
+	glyph, exists := a.glyphs[r]
+	return glyph, exists
+}
+
+// signedDistance returns the distance, in font pixels, from a point to the
+// nearest edge of a glyph: positive inside, negative outside, clamped to the spread
+func signedDistance(font *BitmapFont, r rune, x, y float64, spread int) float64 {
+	px, py := int(math.Floor(x)), int(math.Floor(y))
+	inside := font.Pixel(r, px, py)
+	nearest := float64(spread)
+	for sy := py - spread - 1; sy <= py+spread+1; sy++ {
+		for sx := px - spread - 1; sx <= px+spread+1; sx++ {
+			// Inside points look for the nearest empty pixel, outside ones for a set pixel
+			if font.Pixel(r, sx, sy) == inside {
+				continue
+			}
+			dx := math.Max(math.Max(float64(sx)-x, 0), x-float64(sx+1))
+			dy := math.Max(math.Max(float64(sy)-y, 0), y-float64(sy+1))
+			nearest = math.Min(nearest, math.Hypot(dx, dy))
+		}
+	}
+	if inside {
+		return nearest
+	}
+	return -nearest
+}
@@ -0,0 +1,85 @@
+package text
+
+import "strings"
+
+// Anchor is the point of a laid out block of text placed at the origin
+type Anchor int
+
+const (
+	AnchorTopLeft      Anchor = iota // Text runs right and down from the origin
+	AnchorCenter                     // Text is centred on the origin
+	AnchorBottomCenter               // Text sits above the origin, centred; used for labels over objects
+	AnchorMiddleLeft                 // Text runs right from the origin, centred vertically; used for HUD values
+)
+
+// Quad is one glyph of laid out text. Positions are in the caller's units
+// with Y growing downwards, and include the glyph's SDF padding.
+type Quad struct {
+	X0, Y0, X1, Y1 float32
+	U0, V0, U1, V1 float32
+}
+
+// lineSpacing is the blank font pixels between lines
+const lineSpacing = 2
+
+// Measure returns the size of a block of text whose glyphs are size units high
+func (a *Atlas) Measure(s string, size float32) (width, height float32) {
+	unit := size / float32(a.Font.Height)
+	lines := strings.Split(s, "\n")
+	longest := 0
+	for _, line := range lines {
+		if n := len([]rune(line)); n > longest {
+			longest = n
+		}
+	}
+	if longest > 0 {
+		width = float32((longest-1)*a.Font.Advance+a.Font.Width) * unit
+	}
+	height = float32(len(lines)*(a.Font.Height+lineSpacing)-lineSpacing) * unit
+	return width, height
+}
+
+// Layout returns the quads that draw a string with glyphs size units high,
+// anchored at the origin. Lines break at newlines, each centred when the
+// anchor is; runes the font lacks are drawn as '?'.
+func (a *Atlas) Layout(s string, size float32, anchor Anchor) []Quad {
+	unit := size / float32(a.Font.Height)
+	width, height := a.Measure(s, size)
+	var originX, originY float32
+	switch anchor {
+	case AnchorCenter:
+		originX, originY = -width/2, -height/2
+	case AnchorBottomCenter:
+		originX, originY = -width/2, -height
+	case AnchorMiddleLeft:
+		originY = -height / 2
+	}
+	centred := anchor == AnchorCenter || anchor == AnchorBottomCenter
+	pad := float32(a.Spread) * unit
+
+	quads := make([]Quad, 0, len(s))
+	for row, line := range strings.Split(s, "\n") {
+		runes := []rune(line)
+		x := originX
+		if centred && len(runes) > 0 {
+			lineWidth := float32((len(runes)-1)*a.Font.Advance+a.Font.Width) * unit
+			x += (width - lineWidth) / 2
+		}
+		y := originY + float32(row*(a.Font.Height+lineSpacing))*unit
+		for _, r := range runes {
+			glyph, exists := a.Glyph(r)
+			if !exists {
+				glyph, _ = a.Glyph('?')
+			}
+			if r != ' ' {
+				quads = append(quads, Quad{
+					X0: x - pad, Y0: y - pad,
+					X1: x + float32(a.Font.Width)*unit + pad, Y1: y + size + pad,
+					U0: glyph.U0, V0: glyph.V0, U1: glyph.U1, V1: glyph.V1,
+				})
+			}
+			x += float32(a.Font.Advance) * unit
+		}
+	}
+	return quads
+}
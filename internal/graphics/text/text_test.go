@@ -0,0 +1,72 @@
+package text
+
+import "testing"
+
+// TestBuildAtlas tests that the distance field matches the font's pixels
+func TestBuildAtlas(t *testing.T) {
+	font := DefaultFont()
+	atlas, err := BuildAtlas(font, DefaultAtlasScale, DefaultAtlasSpread)
+	if err != nil {
+		t.Fatalf("BuildAtlas failed: %v", err)
+	}
+	if len(atlas.Pixels) != atlas.Width*atlas.Height {
+		t.Fatalf("Expected %d texels, got %d", atlas.Width*atlas.Height, len(atlas.Pixels))
+	}
+
+	// Sample the middle of a set and an unset pixel of 'I' (a vertical bar in column 2)
+	glyph, ok := atlas.Glyph('I')
+	if !ok {
+		t.Fatal("Expected a glyph for 'I'")
+	}
+	texel := func(px, py int) byte {
+		x := int(glyph.U0*float32(atlas.Width)) + (px+atlas.Spread)*atlas.Scale + atlas.Scale/2
+		y := int(glyph.V0*float32(atlas.Height)) + (py+atlas.Spread)*atlas.Scale + atlas.Scale/2
+		return atlas.Pixels[y*atlas.Width+x]
+	}
+	if !font.Pixel('I', 2, 3) || texel(2, 3) <= 128 {
+		t.Errorf("Expected the inside of 'I' above the edge value, got %d", texel(2, 3))
+	}
+	if font.Pixel('I', 0, 3) || texel(0, 3) >= 128 {
+		t.Errorf("Expected the outside of 'I' below the edge value, got %d", texel(0, 3))
+	}
+
+	if _, err := BuildAtlas(font, 0, 1); err == nil {
+		t.Error("Expected a zero scale to be rejected")
+	}
+}
+
+// TestLayout tests measuring and anchoring text
+func TestLayout(t *testing.T) {
+	atlas, err := BuildAtlas(DefaultFont(), 2, 1)
+	if err != nil {
+		t.Fatalf("BuildAtlas failed: %v", err)
+	}
+
+	// Glyphs 7 units high make one font pixel per unit
+	width, height := atlas.Measure("ab\nc", 7)
+	if width != 11 || height != 16 {
+		t.Errorf("Expected an 11x16 block, got %vx%v", width, height)
+	}
+
+	// Spaces advance without a quad, and unknown runes fall back to '?'
+	quads := atlas.Layout("a b\né", 7, AnchorTopLeft)
+	if len(quads) != 3 {
+		t.Fatalf("Expected 3 quads, got %d", len(quads))
+	}
+	if quads[0].X0 != -1 || quads[0].Y0 != -1 || quads[0].X1 != 6 || quads[0].Y1 != 8 {
+		t.Errorf("Unexpected first quad %+v", quads[0])
+	}
+	if quads[1].X0 != 11 {
+		t.Errorf("Expected 'b' two advances in, got %v", quads[1].X0)
+	}
+	question, _ := atlas.Glyph('?')
+	if quads[2].U0 != question.U0 || quads[2].Y0 != 8 {
+		t.Errorf("Expected '?' on the second line, got %+v", quads[2])
+	}
+
+	// Labels sit above and centred on their origin
+	quads = atlas.Layout("ab", 7, AnchorBottomCenter)
+	if left, right := quads[0].X0+1, quads[1].X1-1; left != -right || quads[0].Y1-1 != 0 {
+		t.Errorf("Expected the label centred above the origin, got %+v", quads)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !js
+
 package graphics
 
 import (
@@ -11,12 +13,21 @@ import (
 	"path/filepath"
 	"strings"
 
+	"teraglest/internal/logging"
+
 	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
 // TextureManager handles loading and management of textures
 type TextureManager struct {
 	textureCache map[string]*Texture
+	residency    *ResidencyCache // Shared GPU memory budget; nil keeps every texture
+}
+
+// textureKey identifies a texture of a manager in a residency cache
+type textureKey struct {
+	manager *TextureManager
+	path    string
 }
 
 // Texture represents an OpenGL texture
@@ -39,6 +50,7 @@ func NewTextureManager() *TextureManager {
 func (tm *TextureManager) LoadTexture(filePath string) (*Texture, error) {
 	// Check cache first
 	if texture, exists := tm.textureCache[filePath]; exists {
+		tm.residency.Touch(textureKey{tm, filePath})
 		return texture, nil
 	}
 
@@ -55,7 +67,7 @@ func (tm *TextureManager) LoadTexture(filePath string) (*Texture, error) {
 	}
 
 	// Cache the texture
-	tm.textureCache[filePath] = texture
+	tm.cache(filePath, texture)
 
 	return texture, nil
 }
@@ -64,6 +76,7 @@ func (tm *TextureManager) LoadTexture(filePath string) (*Texture, error) {
 func (tm *TextureManager) LoadTextureFromImage(img image.Image, path string) (*Texture, error) {
 	// Check cache first
 	if texture, exists := tm.textureCache[path]; exists {
+		tm.residency.Touch(textureKey{tm, path})
 		return texture, nil
 	}
 
@@ -74,14 +87,52 @@ func (tm *TextureManager) LoadTextureFromImage(img image.Image, path string) (*T
 	}
 
 	// Cache the texture
-	tm.textureCache[path] = texture
+	tm.cache(path, texture)
 
 	return texture, nil
 }
 
+// cache adds a new texture to the cache and to the residency budget
+func (tm *TextureManager) cache(path string, texture *Texture) {
+	tm.textureCache[path] = texture
+	tm.track(path, texture)
+}
+
+// track adds a cached texture to the residency budget, unloading it when evicted
+func (tm *TextureManager) track(path string, texture *Texture) {
+	tm.residency.Add(textureKey{tm, path}, ResourceTexture, TextureBytes(texture.Width, texture.Height, texture.Format), func() {
+		logging.Debugf(logging.CategoryRender, "Evicted texture: %s", path)
+		tm.UnloadTexture(path)
+	})
+}
+
+// SetResidency puts the manager's textures under a shared GPU memory budget.
+// Textures without a reference may then be evicted once unused for a frame,
+// so callers keeping a texture ID across frames must Acquire it.
+func (tm *TextureManager) SetResidency(residency *ResidencyCache) {
+	tm.residency = residency
+	for path, texture := range tm.textureCache {
+		tm.track(path, texture)
+	}
+}
+
+// Acquire keeps a cached texture resident until it is released
+func (tm *TextureManager) Acquire(filePath string) {
+	tm.residency.Acquire(textureKey{tm, filePath})
+}
+
+// Release drops a reference taken with Acquire
+func (tm *TextureManager) Release(filePath string) {
+	tm.residency.Release(textureKey{tm, filePath})
+}
+
 // GetTexture returns a cached texture or nil if not found
 func (tm *TextureManager) GetTexture(filePath string) *Texture {
-	return tm.textureCache[filePath]
+	texture := tm.textureCache[filePath]
+	if texture != nil {
+		tm.residency.Touch(textureKey{tm, filePath})
+	}
+	return texture
 }
 
 // UnloadTexture removes a texture from cache and GPU
@@ -89,6 +140,7 @@ func (tm *TextureManager) UnloadTexture(filePath string) {
 	if texture, exists := tm.textureCache[filePath]; exists {
 		texture.Cleanup()
 		delete(tm.textureCache, filePath)
+		tm.residency.Remove(textureKey{tm, filePath})
 	}
 }
 
@@ -99,8 +151,9 @@ func (tm *TextureManager) GetCacheSize() int {
 
 // Cleanup releases all cached textures
 func (tm *TextureManager) Cleanup() {
-	for _, texture := range tm.textureCache {
+	for path, texture := range tm.textureCache {
 		texture.Cleanup()
+		tm.residency.Remove(textureKey{tm, path})
 	}
 	tm.textureCache = make(map[string]*Texture)
 }
@@ -120,7 +173,7 @@ func loadImageFromFile(filePath string) (image.Image, error) {
 	}
 
 	// Log successful load for debugging
-	fmt.Printf("Loaded %s image: %dx%d from %s\n", format, img.Bounds().Dx(), img.Bounds().Dy(), filePath)
+	logging.Debugf(logging.CategoryRender, "Loaded %s image: %dx%d from %s", format, img.Bounds().Dx(), img.Bounds().Dy(), filePath)
 
 	return img, nil
 }
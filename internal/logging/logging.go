@@ -0,0 +1,339 @@
+// Package logging provides leveled, categorized logging with optional
+// rotating file output and runtime verbosity changes.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log message
+type Level int
+
+const (
+	LevelDebug Level = iota // Verbose diagnostics
+	LevelInfo               // Normal operational messages
+	LevelWarn               // Recoverable problems
+	LevelError              // Failures
+	LevelOff                // Suppress all output
+)
+
+// String returns the string representation of a Level
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelOff:
+		return "OFF"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseLevel converts a level name (case-insensitive) into a Level
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "off", "none":
+		return LevelOff, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// Category groups log messages by subsystem
+type Category string
+
+const (
+	CategoryGame   Category = "game"   // Application lifecycle
+	CategoryEngine Category = "engine" // Simulation and world state
+	CategoryRender Category = "render" // Graphics and rendering
+	CategoryAI     Category = "ai"     // Strategic and unit AI
+	CategoryNet    Category = "net"    // Networking
+	CategoryAudio  Category = "audio"  // Sound and music
+	CategoryUI     Category = "ui"     // User interface and input
+	CategoryData   Category = "data"   // Asset loading
+)
+
+// Categories lists every known category
+var Categories = []Category{
+	CategoryGame, CategoryEngine, CategoryRender, CategoryAI,
+	CategoryNet, CategoryAudio, CategoryUI, CategoryData,
+}
+
+// Logger writes leveled messages to the console and optionally to a rotating file
+type Logger struct {
+	mutex        sync.Mutex
+	defaultLevel Level
+	levels       map[Category]Level // Per-category overrides
+	console      io.Writer
+	file         *RotatingFile
+	now          func() time.Time
+}
+
+// New creates a logger writing Info and above to stderr
+func New() *Logger {
+	return &Logger{
+		defaultLevel: LevelInfo,
+		levels:       make(map[Category]Level),
+		console:      os.Stderr,
+		now:          time.Now,
+	}
+}
+
+// std is the process-wide logger used by the package-level functions
+var std = New()
+
+// Default returns the process-wide logger
+func Default() *Logger {
+	return std
+}
+
+// SetDefaultLevel sets the level used by categories without an override
+func (l *Logger) SetDefaultLevel(level Level) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.defaultLevel = level
+}
+
+// SetLevel overrides the level for one category
+func (l *Logger) SetLevel(category Category, level Level) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.levels[category] = level
+}
+
+// ResetLevels removes all per-category overrides
+func (l *Logger) ResetLevels() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.levels = make(map[Category]Level)
+}
+
+// LevelFor returns the effective level of a category
+func (l *Logger) LevelFor(category Category) Level {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.levelForLocked(category)
+}
+
+// Enabled reports whether a message at this level and category would be written
+func (l *Logger) Enabled(category Category, level Level) bool {
+	return level >= l.LevelFor(category) && level < LevelOff
+}
+
+// SetConsole sets the console writer (nil disables console output)
+func (l *Logger) SetConsole(w io.Writer) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.console = w
+}
+
+// EnableFileOutput additionally writes all enabled messages to a rotating log file
+func (l *Logger) EnableFileOutput(path string, maxSize int64, maxBackups int) error {
+	file, err := NewRotatingFile(path, maxSize, maxBackups)
+	if err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = file
+	return nil
+}
+
+// Close flushes and closes the log file, if any
+func (l *Logger) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Logf writes a formatted message if the category's level allows it
+func (l *Logger) Logf(category Category, level Level, format string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if level < l.levelForLocked(category) || level >= LevelOff {
+		return
+	}
+
+	line := fmt.Sprintf("%s %-5s [%s] %s\n",
+		l.now().Format("2006-01-02 15:04:05.000"), level, category,
+		strings.TrimRight(fmt.Sprintf(format, args...), "\n"))
+
+	if l.console != nil {
+		io.WriteString(l.console, line)
+	}
+	if l.file != nil {
+		l.file.Write([]byte(line))
+	}
+}
+
+// Debugf logs a debug message
+func (l *Logger) Debugf(category Category, format string, args ...interface{}) {
+	l.Logf(category, LevelDebug, format, args...)
+}
+
+// Infof logs an informational message
+func (l *Logger) Infof(category Category, format string, args ...interface{}) {
+	l.Logf(category, LevelInfo, format, args...)
+}
+
+// Warnf logs a warning
+func (l *Logger) Warnf(category Category, format string, args ...interface{}) {
+	l.Logf(category, LevelWarn, format, args...)
+}
+
+// Errorf logs an error
+func (l *Logger) Errorf(category Category, format string, args ...interface{}) {
+	l.Logf(category, LevelError, format, args...)
+}
+
+// HandleCommand applies a console command and returns a response:
+//
+//	log status                      show levels
+//	log level <level>               set the default level
+//	log level <category> <level>    set one category's level
+//	log reset                       clear category overrides
+func (l *Logger) HandleCommand(command string) (string, error) {
+	fields := strings.Fields(command)
+	if len(fields) > 0 && fields[0] == "log" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return "", fmt.Errorf("usage: log status | log level [category] <level> | log reset")
+	}
+
+	switch fields[0] {
+	case "status":
+		return l.status(), nil
+
+	case "reset":
+		l.ResetLevels()
+		return "log levels reset", nil
+
+	case "level":
+		switch len(fields) {
+		case 2:
+			level, err := ParseLevel(fields[1])
+			if err != nil {
+				return "", err
+			}
+			l.SetDefaultLevel(level)
+			return fmt.Sprintf("default log level set to %s", level), nil
+		case 3:
+			category := Category(strings.ToLower(fields[1]))
+			if !isKnownCategory(category) {
+				return "", fmt.Errorf("unknown log category %q", fields[1])
+			}
+			level, err := ParseLevel(fields[2])
+			if err != nil {
+				return "", err
+			}
+			l.SetLevel(category, level)
+			return fmt.Sprintf("%s log level set to %s", category, level), nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown log command %q", strings.Join(fields, " "))
+}
+
+// ApplySpec applies a comma-separated level spec such as "info,render=debug,ai=warn".
+// A bare level sets the default; category=level entries set overrides.
+func (l *Logger) ApplySpec(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		command := "level " + entry
+		if name, level, found := strings.Cut(entry, "="); found {
+			command = "level " + name + " " + level
+		}
+		if _, err := l.HandleCommand(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// status summarizes the effective level of every category
+func (l *Logger) status() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	names := make([]string, 0, len(Categories))
+	for _, category := range Categories {
+		names = append(names, fmt.Sprintf("%s=%s", category, l.levelForLocked(category)))
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("default=%s %s", l.defaultLevel, strings.Join(names, " "))
+}
+
+// levelForLocked returns a category's level (mutex must be held)
+func (l *Logger) levelForLocked(category Category) Level {
+	if level, ok := l.levels[category]; ok {
+		return level
+	}
+	return l.defaultLevel
+}
+
+// isKnownCategory reports whether a category is in Categories
+func isKnownCategory(category Category) bool {
+	for _, known := range Categories {
+		if known == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Package-level helpers writing to the default logger
+
+// Debugf logs a debug message to the default logger
+func Debugf(category Category, format string, args ...interface{}) {
+	std.Logf(category, LevelDebug, format, args...)
+}
+
+// Infof logs an informational message to the default logger
+func Infof(category Category, format string, args ...interface{}) {
+	std.Logf(category, LevelInfo, format, args...)
+}
+
+// Warnf logs a warning to the default logger
+func Warnf(category Category, format string, args ...interface{}) {
+	std.Logf(category, LevelWarn, format, args...)
+}
+
+// Errorf logs an error to the default logger
+func Errorf(category Category, format string, args ...interface{}) {
+	std.Logf(category, LevelError, format, args...)
+}
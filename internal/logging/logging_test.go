@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLogger creates a logger writing to a buffer with a fixed clock
+func newTestLogger() (*Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetConsole(&buf)
+	logger.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return logger, &buf
+}
+
+func TestLevelFiltering(t *testing.T) {
+	logger, buf := newTestLogger()
+
+	logger.Debugf(CategoryEngine, "hidden")
+	logger.Infof(CategoryEngine, "unit %d created", 7)
+	if strings.Contains(buf.String(), "hidden") {
+		t.Error("Debug message should be filtered at default Info level")
+	}
+	if !strings.Contains(buf.String(), "INFO  [engine] unit 7 created") {
+		t.Errorf("Unexpected log output: %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.SetLevel(CategoryAI, LevelDebug)
+	logger.Debugf(CategoryAI, "ai detail")
+	logger.Debugf(CategoryRender, "render detail")
+	if !strings.Contains(buf.String(), "ai detail") || strings.Contains(buf.String(), "render detail") {
+		t.Errorf("Category override not applied: %q", buf.String())
+	}
+
+	buf.Reset()
+	logger.SetDefaultLevel(LevelOff)
+	logger.Errorf(CategoryRender, "silenced")
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output at LevelOff, got %q", buf.String())
+	}
+}
+
+func TestHandleCommand(t *testing.T) {
+	logger, _ := newTestLogger()
+
+	if _, err := logger.HandleCommand("log level net debug"); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if logger.LevelFor(CategoryNet) != LevelDebug {
+		t.Errorf("Expected net level debug, got %s", logger.LevelFor(CategoryNet))
+	}
+
+	if _, err := logger.HandleCommand("log level warn"); err != nil {
+		t.Fatalf("HandleCommand failed: %v", err)
+	}
+	if logger.LevelFor(CategoryAudio) != LevelWarn {
+		t.Errorf("Expected default level warn, got %s", logger.LevelFor(CategoryAudio))
+	}
+
+	status, err := logger.HandleCommand("log status")
+	if err != nil || !strings.Contains(status, "net=DEBUG") {
+		t.Errorf("Unexpected status %q (err %v)", status, err)
+	}
+
+	if _, err := logger.HandleCommand("log level bogus debug"); err == nil {
+		t.Error("Expected error for unknown category")
+	}
+	if _, err := logger.HandleCommand("log level ai loud"); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+
+	logger.HandleCommand("log reset")
+	if logger.LevelFor(CategoryNet) != LevelWarn {
+		t.Errorf("Expected reset to remove override, got %s", logger.LevelFor(CategoryNet))
+	}
+
+	if err := logger.ApplySpec("error, render=debug"); err != nil {
+		t.Fatalf("ApplySpec failed: %v", err)
+	}
+	if logger.LevelFor(CategoryEngine) != LevelError || logger.LevelFor(CategoryRender) != LevelDebug {
+		t.Errorf("Spec not applied: engine=%s render=%s", logger.LevelFor(CategoryEngine), logger.LevelFor(CategoryRender))
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "game.log")
+	rf, err := NewRotatingFile(path, 20, 2)
+	if err != nil {
+		t.Fatalf("NewRotatingFile failed: %v", err)
+	}
+	defer rf.Close()
+
+	for _, line := range []string{"first line 12345\n", "second line 1234\n", "third line 12345\n", "fourth line 1234\n"} {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	current, _ := os.ReadFile(path)
+	newest, _ := os.ReadFile(path + ".1")
+	oldest, _ := os.ReadFile(path + ".2")
+	if string(current) != "fourth line 1234\n" || string(newest) != "third line 12345\n" || string(oldest) != "second line 1234\n" {
+		t.Errorf("Unexpected rotation: current=%q .1=%q .2=%q", current, newest, oldest)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected at most 2 backups")
+	}
+}
+
+func TestFileOutput(t *testing.T) {
+	logger, _ := newTestLogger()
+	path := filepath.Join(t.TempDir(), "game.log")
+	if err := logger.EnableFileOutput(path, 0, 0); err != nil {
+		t.Fatalf("EnableFileOutput failed: %v", err)
+	}
+
+	logger.Warnf(CategoryAudio, "device lost")
+	logger.Close()
+
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "WARN  [audio] device lost") {
+		t.Errorf("Expected warning in log file, got %q", content)
+	}
+}
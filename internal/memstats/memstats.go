@@ -0,0 +1,212 @@
+// Package memstats reports the memory held by the game's caches, the Go heap
+// and estimated GPU memory. Subsystems register a report function per cache;
+// a snapshot calls them all and reads the runtime's heap statistics. In soak
+// test mode a snapshot is taken at every match start, and figures that grow
+// across every one of the last few matches are flagged as likely leaks.
+package memstats
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is where a cache's memory lives
+type Kind int
+
+const (
+	Host Kind = iota // Go heap or other main memory
+	GPU              // Video memory, estimated from what was uploaded
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	if k == GPU {
+		return "gpu"
+	}
+	return "host"
+}
+
+// CacheStats is what a cache holds
+type CacheStats struct {
+	Entries int
+	Bytes   int64 // 0 if the cache does not know its size
+}
+
+// Cache is one cache's figures in a snapshot
+type Cache struct {
+	Name string
+	Kind Kind
+	CacheStats
+}
+
+// Snapshot is the memory held at one moment
+type Snapshot struct {
+	Time   time.Time
+	Caches []Cache // By name
+
+	HeapAlloc   uint64 // Bytes of live and not yet collected heap objects
+	HeapInuse   uint64 // Bytes in heap spans in use
+	HeapObjects uint64
+	Sys         uint64 // Bytes obtained from the OS
+	NumGC       uint32
+	Goroutines  int
+}
+
+// GPUBytes returns the estimated video memory held by the GPU caches
+func (s Snapshot) GPUBytes() int64 {
+	var bytes int64
+	for _, cache := range s.Caches {
+		if cache.Kind == GPU {
+			bytes += cache.Bytes
+		}
+	}
+	return bytes
+}
+
+// String formats the snapshot for the console, a line per cache
+func (s Snapshot) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "heap %.1f MB in use %.1f MB, %d objects, sys %.1f MB, %d GCs, %d goroutines",
+		megabytes(int64(s.HeapAlloc)), megabytes(int64(s.HeapInuse)), s.HeapObjects, megabytes(int64(s.Sys)), s.NumGC, s.Goroutines)
+	fmt.Fprintf(&b, "\ngpu estimate %.1f MB", megabytes(s.GPUBytes()))
+	for _, cache := range s.Caches {
+		size := "size unknown"
+		if cache.Bytes > 0 {
+			size = fmt.Sprintf("%.1f MB", megabytes(cache.Bytes))
+		}
+		fmt.Fprintf(&b, "\n%-10s %-4s %6d entries, %s", cache.Name, cache.Kind, cache.Entries, size)
+	}
+	return b.String()
+}
+
+// megabytes converts a byte count for display
+func megabytes(bytes int64) float64 {
+	return float64(bytes) / (1 << 20)
+}
+
+// source is a registered cache
+type source struct {
+	kind   Kind
+	report func() CacheStats
+}
+
+// Registry collects the caches whose memory is reported
+type Registry struct {
+	mutex   sync.Mutex
+	sources map[string]source
+	soak    *SoakTracker // Nil outside soak test mode
+}
+
+// New creates an empty registry
+func New() *Registry {
+	return &Registry{sources: make(map[string]source)}
+}
+
+// std is the process-wide registry subsystems report to
+var std = New()
+
+// Default returns the process-wide registry
+func Default() *Registry {
+	return std
+}
+
+// Register adds a cache, replacing one of the same name. The report
+// function is called from whichever goroutine takes a snapshot.
+func (r *Registry) Register(name string, kind Kind, report func() CacheStats) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.sources[name] = source{kind: kind, report: report}
+}
+
+// Unregister removes a cache
+func (r *Registry) Unregister(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.sources, name)
+}
+
+// Snapshot reports every cache and the heap as they are now
+func (r *Registry) Snapshot() Snapshot {
+	r.mutex.Lock()
+	sources := make(map[string]source, len(r.sources))
+	for name, source := range r.sources {
+		sources[name] = source
+	}
+	r.mutex.Unlock()
+
+	snapshot := Snapshot{Time: time.Now(), Goroutines: runtime.NumGoroutine()}
+	for name, source := range sources {
+		snapshot.Caches = append(snapshot.Caches, Cache{Name: name, Kind: source.kind, CacheStats: source.report()})
+	}
+	sort.Slice(snapshot.Caches, func(i, j int) bool { return snapshot.Caches[i].Name < snapshot.Caches[j].Name })
+
+	var heap runtime.MemStats
+	runtime.ReadMemStats(&heap)
+	snapshot.HeapAlloc, snapshot.HeapInuse, snapshot.HeapObjects = heap.HeapAlloc, heap.HeapInuse, heap.HeapObjects
+	snapshot.Sys, snapshot.NumGC = heap.Sys, heap.NumGC
+	return snapshot
+}
+
+// EnableSoak turns on soak test mode, flagging figures that grow across
+// every one of the last window match starts
+func (r *Registry) EnableSoak(window int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.soak = NewSoakTracker(window)
+}
+
+// Soak returns the soak tracker, nil outside soak test mode
+func (r *Registry) Soak() *SoakTracker {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.soak
+}
+
+// MatchStarted records a snapshot in soak test mode, after a collection so
+// garbage from the last match does not count, and returns the figures that
+// grew steadily. It does nothing outside soak test mode.
+func (r *Registry) MatchStarted() []Growth {
+	soak := r.Soak()
+	if soak == nil {
+		return nil
+	}
+	runtime.GC()
+	return soak.Record(r.Snapshot())
+}
+
+// HandleCommand applies a console command:
+//
+//	mem [dump]    show cache sizes, heap and GPU estimate
+//	mem gc        collect garbage first, then show them
+//	mem soak      show what soak test mode has recorded
+func (r *Registry) HandleCommand(command string) (string, error) {
+	fields := strings.Fields(command)
+	if len(fields) > 0 && fields[0] == "mem" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		fields = []string{"dump"}
+	}
+	if len(fields) > 1 {
+		return "", fmt.Errorf("usage: mem [dump|gc|soak]")
+	}
+
+	switch fields[0] {
+	case "dump":
+		return r.Snapshot().String(), nil
+	case "gc":
+		runtime.GC()
+		return r.Snapshot().String(), nil
+	case "soak":
+		soak := r.Soak()
+		if soak == nil {
+			return "", fmt.Errorf("soak test mode is off; start with -soak")
+		}
+		return soak.String(), nil
+	}
+	return "", fmt.Errorf("unknown mem command %q", fields[0])
+}
@@ -0,0 +1,71 @@
+package memstats
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSnapshot tests that snapshots report every registered cache by name
+// and total the GPU ones
+func TestSnapshot(t *testing.T) {
+	registry := New()
+	registry.Register("textures", GPU, func() CacheStats { return CacheStats{Entries: 3, Bytes: 3 << 20} })
+	registry.Register("models", GPU, func() CacheStats { return CacheStats{Entries: 2, Bytes: 1 << 20} })
+	registry.Register("paths", Host, func() CacheStats { return CacheStats{Entries: 7} })
+
+	snapshot := registry.Snapshot()
+	if len(snapshot.Caches) != 3 || snapshot.Caches[0].Name != "models" || snapshot.Caches[2].Name != "textures" {
+		t.Fatalf("Expected three caches by name, got %+v", snapshot.Caches)
+	}
+	if snapshot.GPUBytes() != 4<<20 {
+		t.Errorf("Expected 4 MB on the GPU, got %d bytes", snapshot.GPUBytes())
+	}
+	if snapshot.HeapAlloc == 0 || snapshot.Goroutines == 0 {
+		t.Errorf("Expected heap stats read from the runtime, got %+v", snapshot)
+	}
+
+	registry.Unregister("paths")
+	response, err := registry.HandleCommand("mem")
+	if err != nil {
+		t.Fatalf("Expected mem to dump, got %v", err)
+	}
+	if strings.Contains(response, "paths") || !strings.Contains(response, "gpu estimate 4.0 MB") {
+		t.Errorf("Expected the dump without paths and with the GPU estimate, got:\n%s", response)
+	}
+	if _, err := registry.HandleCommand("mem soak"); err == nil {
+		t.Errorf("Expected mem soak to fail outside soak test mode")
+	}
+}
+
+// TestSoakTracker tests that only figures growing at every match start in
+// the window are flagged, and heap noise is not
+func TestSoakTracker(t *testing.T) {
+	soak := NewSoakTracker(3)
+	snapshot := func(heap uint64, sprites, paths int) Snapshot {
+		return Snapshot{
+			HeapAlloc:  heap,
+			Goroutines: 10,
+			Caches: []Cache{
+				{Name: "sprites", CacheStats: CacheStats{Entries: sprites}},
+				{Name: "paths", CacheStats: CacheStats{Entries: paths}},
+			},
+		}
+	}
+
+	if growth := soak.Record(snapshot(100000, 5, 5)); growth != nil {
+		t.Errorf("Expected nothing flagged before the window fills, got %v", growth)
+	}
+	soak.Record(snapshot(100100, 6, 5))
+	growth := soak.Record(snapshot(100200, 7, 6))
+	if len(growth) != 1 || growth[0].Name != "sprites" || growth[0].From != 5 || growth[0].To != 7 {
+		t.Errorf("Expected only the sprites flagged, got %v", growth)
+	}
+	if !strings.Contains(soak.String(), "sprites grew from 5 to 7") {
+		t.Errorf("Expected the summary to name the growth, got %q", soak.String())
+	}
+
+	// A cache that shrinks once in the window is not leaking
+	if growth := soak.Record(snapshot(100300, 6, 6)); len(growth) != 0 {
+		t.Errorf("Expected nothing flagged after the sprites shrank, got %v", growth)
+	}
+}
@@ -0,0 +1,141 @@
+package memstats
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultSoakWindow is how many match starts a figure must grow across in a
+// row to be flagged
+const DefaultSoakWindow = 4
+
+// soakNoise is the growth over the whole window, as a share of where it
+// started, below which heap figures are not flagged: the heap moves a little
+// between matches even without leaks
+const soakNoise = 0.02
+
+// Growth is a figure that grew at every match start in the window
+type Growth struct {
+	Name     string
+	From, To int64
+}
+
+// String describes the growth
+func (g Growth) String() string {
+	return fmt.Sprintf("%s grew from %d to %d", g.Name, g.From, g.To)
+}
+
+// SoakTracker keeps the snapshots of the last match starts and flags the
+// figures that grew across all of them
+type SoakTracker struct {
+	mutex     sync.Mutex
+	window    int
+	snapshots []Snapshot // Oldest first, at most window
+	matches   int        // Match starts recorded in all
+	flagged   []Growth   // Growth found at the last record
+}
+
+// NewSoakTracker creates a tracker flagging growth across a window of match
+// starts; windows under three are too short to tell growth from noise
+func NewSoakTracker(window int) *SoakTracker {
+	if window < 3 {
+		window = 3
+	}
+	return &SoakTracker{window: window}
+}
+
+// Record adds the snapshot of a match start and returns the figures that
+// grew at every start in the window
+func (st *SoakTracker) Record(snapshot Snapshot) []Growth {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.snapshots = append(st.snapshots, snapshot)
+	if len(st.snapshots) > st.window {
+		st.snapshots = st.snapshots[len(st.snapshots)-st.window:]
+	}
+	st.matches++
+	st.flagged = nil
+	if len(st.snapshots) < st.window {
+		return nil
+	}
+
+	for _, name := range soakFigureNames(st.snapshots[len(st.snapshots)-1]) {
+		values := make([]int64, len(st.snapshots))
+		for i, snapshot := range st.snapshots {
+			values[i] = soakFigure(snapshot, name)
+		}
+		if !growing(values) {
+			continue
+		}
+		noise := int64(0)
+		if name == "heap" || name == "heap objects" {
+			noise = int64(float64(values[0]) * soakNoise)
+		}
+		if values[len(values)-1]-values[0] > noise {
+			st.flagged = append(st.flagged, Growth{Name: name, From: values[0], To: values[len(values)-1]})
+		}
+	}
+	return st.flagged
+}
+
+// String summarizes what has been recorded
+func (st *SoakTracker) String() string {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if st.matches < st.window {
+		return fmt.Sprintf("%d match starts recorded, %d needed before growth is checked", st.matches, st.window)
+	}
+	if len(st.flagged) == 0 {
+		return fmt.Sprintf("%d match starts recorded, nothing grew across the last %d", st.matches, st.window)
+	}
+	lines := []string{fmt.Sprintf("%d match starts recorded, growing across the last %d:", st.matches, st.window)}
+	for _, growth := range st.flagged {
+		lines = append(lines, "  "+growth.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// growing returns whether every value is above the one before
+func growing(values []int64) bool {
+	for i := 1; i < len(values); i++ {
+		if values[i] <= values[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// soakFigureNames returns the figures of a snapshot watched for growth: the
+// heap, goroutines, and each cache's bytes or, if it does not know them,
+// its entries
+func soakFigureNames(snapshot Snapshot) []string {
+	names := []string{"heap", "heap objects", "goroutines"}
+	for _, cache := range snapshot.Caches {
+		names = append(names, cache.Name)
+	}
+	return names
+}
+
+// soakFigure returns a named figure of a snapshot, 0 if it has none
+func soakFigure(snapshot Snapshot, name string) int64 {
+	switch name {
+	case "heap":
+		return int64(snapshot.HeapAlloc)
+	case "heap objects":
+		return int64(snapshot.HeapObjects)
+	case "goroutines":
+		return int64(snapshot.Goroutines)
+	}
+	for _, cache := range snapshot.Caches {
+		if cache.Name == name {
+			if cache.Bytes > 0 {
+				return cache.Bytes
+			}
+			return int64(cache.Entries)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,421 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// snapshotHistory is how many recent snapshots server and client keep as delta bases
+const snapshotHistory = 32
+
+// serverWelcomePayload tells a client which player it controls
+type serverWelcomePayload struct {
+	PlayerID     int           `json:"player_id"`
+	TickDuration time.Duration `json:"tick_duration"`
+}
+
+// snapshotAckPayload tells the server which snapshot a client has
+type snapshotAckPayload struct {
+	Tick int64 `json:"tick"`
+}
+
+// serverClient is a client connected to an authoritative server
+type serverClient struct {
+	playerID int
+	acked    int64 // Last snapshot tick the client confirmed (0 = none)
+}
+
+// AuthoritativeServer simulates the match itself and sends clients
+// delta-compressed snapshots, so clients need not simulate deterministically.
+// Clients drop in and out of the configured player slots, e.g. for co-op against AI.
+type AuthoritativeServer struct {
+	world        *engine.World
+	tickDuration time.Duration
+	playerIDs    []int // Player IDs clients may take, in assignment order
+	clients      map[*Conn]*serverClient
+	tick         int64
+	history      map[int64]*WorldSnapshot
+	pending      []NetCommand
+	validator    *CommandValidator // Checks every client command before it is executed
+	listener     net.Listener
+	closed       bool
+	mutex        sync.Mutex
+}
+
+// NewAuthoritativeServer creates a server simulating world; clients are given
+// the listed player IDs as they connect
+func NewAuthoritativeServer(world *engine.World, tickDuration time.Duration, playerIDs []int) *AuthoritativeServer {
+	return &AuthoritativeServer{
+		world:        world,
+		tickDuration: tickDuration,
+		playerIDs:    append([]int(nil), playerIDs...),
+		clients:      make(map[*Conn]*serverClient),
+		history:      make(map[int64]*WorldSnapshot),
+		validator:    NewCommandValidator(world),
+	}
+}
+
+// Validator returns the validator client commands must pass
+func (s *AuthoritativeServer) Validator() *CommandValidator {
+	return s.validator
+}
+
+// Listen accepts clients on address in the background
+func (s *AuthoritativeServer) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	s.mutex.Lock()
+	s.listener = listener
+	s.mutex.Unlock()
+
+	go s.acceptLoop(listener)
+	return nil
+}
+
+// Addr returns the address clients connect to, or "" before Listen
+func (s *AuthoritativeServer) Addr() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// ClientCount returns the number of connected clients
+func (s *AuthoritativeServer) ClientCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.clients)
+}
+
+// Step executes the queued commands that pass validation, advances the world one tick and sends
+// every client a snapshot delta against the last snapshot it acknowledged
+func (s *AuthoritativeServer) Step() *WorldSnapshot {
+	s.mutex.Lock()
+	s.tick++
+	tick := TickCommands{Tick: s.tick, Commands: s.pending}
+	s.pending = nil
+	s.mutex.Unlock()
+
+	tick.Commands = s.validator.Filter(tick.Tick, tick.Commands)
+
+	ApplyTick(s.world, tick)
+	s.world.Update(s.tickDuration)
+	snapshot := CaptureSnapshot(s.world, tick.Tick)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.history[snapshot.Tick] = snapshot
+	delete(s.history, snapshot.Tick-snapshotHistory)
+	for conn, client := range s.clients {
+		delta := snapshot.Diff(s.history[client.acked]) // Full snapshot if the base is gone
+		if err := conn.Send(MsgSnapshot, delta); err != nil {
+			logging.Warnf(logging.CategoryNet, "Failed to send snapshot %d to player %d: %v", snapshot.Tick, client.playerID, err)
+		}
+	}
+	return snapshot
+}
+
+// Run steps the simulation every tick until stop is closed
+func (s *AuthoritativeServer) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.tickDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Step()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Close disconnects every client and stops listening
+func (s *AuthoritativeServer) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for conn := range s.clients {
+		conn.Send(MsgLeave, nil)
+		conn.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// acceptLoop handles incoming clients until the listener closes
+func (s *AuthoritativeServer) acceptLoop(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serveClient(NewConn(conn))
+	}
+}
+
+// serveClient assigns a player to a client and queues its commands until it disconnects
+func (s *AuthoritativeServer) serveClient(conn *Conn) {
+	message, err := conn.ReceiveWithin(DefaultJoinTimeout)
+	if err != nil || message.Type != MsgHello {
+		conn.Close()
+		return
+	}
+	var hello helloPayload
+	if err := message.Decode(&hello); err != nil || hello.Version != ProtocolVersion {
+		conn.Send(MsgReject, rejectPayload{Reason: fmt.Sprintf("protocol version %d required", ProtocolVersion)})
+		conn.Close()
+		return
+	}
+
+	s.mutex.Lock()
+	playerID := s.freePlayerID()
+	if playerID != 0 {
+		s.clients[conn] = &serverClient{playerID: playerID}
+	}
+	s.mutex.Unlock()
+
+	if playerID == 0 {
+		conn.Send(MsgReject, rejectPayload{Reason: "no free player slot"})
+		conn.Close()
+		return
+	}
+	logging.Infof(logging.CategoryNet, "%s took player %d from %s", hello.PlayerName, playerID, conn.RemoteAddr())
+	conn.Send(MsgWelcome, serverWelcomePayload{PlayerID: playerID, TickDuration: s.tickDuration})
+
+	for {
+		message, err := conn.Receive()
+		if err != nil || message.Type == MsgLeave {
+			break
+		}
+		switch message.Type {
+		case MsgCommand:
+			var command NetCommand
+			if err := message.Decode(&command); err != nil {
+				continue
+			}
+			command.PlayerID = playerID
+			s.mutex.Lock()
+			s.pending = append(s.pending, command)
+			s.mutex.Unlock()
+		case MsgSnapshotAck:
+			var ack snapshotAckPayload
+			if err := message.Decode(&ack); err != nil {
+				continue
+			}
+			s.mutex.Lock()
+			if client := s.clients[conn]; client != nil && ack.Tick > client.acked {
+				client.acked = ack.Tick
+			}
+			s.mutex.Unlock()
+		}
+	}
+
+	s.mutex.Lock()
+	delete(s.clients, conn)
+	s.mutex.Unlock()
+	conn.Close()
+	logging.Infof(logging.CategoryNet, "Player %d left the server", playerID)
+}
+
+// freePlayerID returns the first player ID no client controls, or 0 (lock must be held)
+func (s *AuthoritativeServer) freePlayerID() int {
+	taken := make(map[int]bool, len(s.clients))
+	for _, client := range s.clients {
+		taken[client.playerID] = true
+	}
+	for _, playerID := range s.playerIDs {
+		if !taken[playerID] {
+			return playerID
+		}
+	}
+	return 0
+}
+
+// AuthoritativeClient receives snapshots from an authoritative server and
+// interpolates between the last two for smooth rendering
+type AuthoritativeClient struct {
+	conn         *Conn
+	playerID     int
+	tickDuration time.Duration
+	snapshots    map[int64]*WorldSnapshot // Recent snapshots, the bases of later deltas
+	previous     *WorldSnapshot
+	latest       *WorldSnapshot
+	latestAt     time.Time // When latest arrived
+	now          func() time.Time
+	done         chan struct{}
+	err          error // Why the connection ended (nil while connected)
+	mutex        sync.Mutex
+}
+
+// ConnectAuthoritative joins an authoritative server and waits for a player assignment
+func ConnectAuthoritative(address, playerName string, timeout time.Duration) (*AuthoritativeClient, error) {
+	conn, err := Dial(address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Send(MsgHello, helloPayload{Version: ProtocolVersion, PlayerName: playerName}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	message, err := conn.ReceiveWithin(timeout)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("no answer from server: %w", err)
+	}
+	switch message.Type {
+	case MsgWelcome:
+	case MsgReject:
+		var reject rejectPayload
+		message.Decode(&reject)
+		conn.Close()
+		return nil, fmt.Errorf("server rejected join: %s", reject.Reason)
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("unexpected %s message from server", message.Type)
+	}
+
+	var welcome serverWelcomePayload
+	if err := message.Decode(&welcome); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client := &AuthoritativeClient{
+		conn:         conn,
+		playerID:     welcome.PlayerID,
+		tickDuration: welcome.TickDuration,
+		snapshots:    make(map[int64]*WorldSnapshot),
+		now:          time.Now,
+		done:         make(chan struct{}),
+	}
+	go client.readLoop()
+	return client, nil
+}
+
+// PlayerID returns the player this client controls
+func (c *AuthoritativeClient) PlayerID() int {
+	return c.playerID
+}
+
+// SendCommand asks the server to execute a command in its next tick
+func (c *AuthoritativeClient) SendCommand(command NetCommand) error {
+	return c.conn.Send(MsgCommand, command)
+}
+
+// Latest returns the newest snapshot received, or nil before the first one
+func (c *AuthoritativeClient) Latest() *WorldSnapshot {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.latest
+}
+
+// View returns the world to render at time now: the client renders one tick
+// behind the server and blends the last two snapshots by the time elapsed
+func (c *AuthoritativeClient) View(now time.Time) *WorldSnapshot {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.latest == nil || c.tickDuration <= 0 {
+		return c.latest
+	}
+	alpha := float64(now.Sub(c.latestAt)) / float64(c.tickDuration)
+	return InterpolateSnapshots(c.previous, c.latest, alpha)
+}
+
+// Done is closed when the connection to the server ends
+func (c *AuthoritativeClient) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the connection ended, or nil while connected
+func (c *AuthoritativeClient) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+// Close leaves the server
+func (c *AuthoritativeClient) Close() error {
+	c.conn.Send(MsgLeave, nil)
+	return c.conn.Close()
+}
+
+// readLoop applies snapshot deltas and acknowledges them until the connection ends
+func (c *AuthoritativeClient) readLoop() {
+	defer close(c.done)
+
+	for {
+		message, err := c.conn.Receive()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				c.setErr(fmt.Errorf("connection to server lost: %w", err))
+			}
+			return
+		}
+
+		switch message.Type {
+		case MsgSnapshot:
+			var delta SnapshotDelta
+			if err := message.Decode(&delta); err != nil {
+				continue
+			}
+			if c.applyDelta(delta) {
+				c.conn.Send(MsgSnapshotAck, snapshotAckPayload{Tick: delta.Tick})
+			}
+		case MsgLeave:
+			c.setErr(fmt.Errorf("server closed the game"))
+			return
+		}
+	}
+}
+
+// applyDelta rebuilds a snapshot from a delta; deltas against a base the
+// client no longer has are dropped and the server falls back to an older base
+func (c *AuthoritativeClient) applyDelta(delta SnapshotDelta) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.latest != nil && delta.Tick <= c.latest.Tick {
+		return false
+	}
+	snapshot, err := delta.Apply(c.snapshots[delta.BaseTick])
+	if err != nil {
+		logging.Debugf(logging.CategoryNet, "Dropped snapshot %d: %v", delta.Tick, err)
+		return false
+	}
+
+	c.snapshots[snapshot.Tick] = snapshot
+	delete(c.snapshots, snapshot.Tick-snapshotHistory)
+	c.previous = c.latest
+	c.latest = snapshot
+	c.latestAt = c.now()
+	return true
+}
+
+// setErr records why the connection ended
+func (c *AuthoritativeClient) setErr(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.err = err
+}
@@ -0,0 +1,938 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// DefaultJoinTimeout bounds connecting to a host and waiting for its welcome
+const DefaultJoinTimeout = 10 * time.Second
+
+// SlotKind describes who occupies a lobby slot
+type SlotKind int
+
+const (
+	SlotOpen   SlotKind = iota // Free for a joining player
+	SlotHuman                  // Taken by a connected player
+	SlotAI                     // Played by the computer
+	SlotClosed                 // Not used in this match
+)
+
+// String returns the display name of a SlotKind
+func (k SlotKind) String() string {
+	switch k {
+	case SlotOpen:
+		return "Open"
+	case SlotHuman:
+		return "Human"
+	case SlotAI:
+		return "AI"
+	case SlotClosed:
+		return "Closed"
+	default:
+		return "Unknown"
+	}
+}
+
+// PlayerSlot is one seat in the lobby; its index + 1 becomes the player ID
+type PlayerSlot struct {
+	Index      int      `json:"index"`
+	Kind       SlotKind `json:"kind"`
+	PlayerName string   `json:"player_name"`
+	Faction    string   `json:"faction"`
+	Team       int      `json:"team"`
+	Ready      bool     `json:"ready"`
+
+	// AI slots only
+	AIPersonality string `json:"ai_personality,omitempty"` // One of engine.AIPersonalityNames
+	AIDifficulty  string `json:"ai_difficulty,omitempty"`  // One of engine.AIDifficultyNames
+	Handicap      int    `json:"handicap,omitempty"`       // Percent of normal starting resources and income
+}
+
+// HandicapSteps are the resource handicaps an AI slot can be given, in percent
+var HandicapSteps = []int{50, 75, 100, 125, 150, 200}
+
+// LobbyState is everything negotiated before a match starts
+type LobbyState struct {
+	Name              string       `json:"name"`
+	MapName           string       `json:"map_name"`
+	TechTree          string       `json:"tech_tree"`
+	AvailableMaps     []string     `json:"available_maps"`
+	AvailableFactions []string     `json:"available_factions"`
+	Slots             []PlayerSlot `json:"slots"`
+	UnitUpkeep        bool         `json:"unit_upkeep"` // Whether large armies drain resources every minute
+
+	InactivityTimeout time.Duration           `json:"inactivity_timeout,omitempty"` // How long a player may idle or stay disconnected (0 = no limit)
+	InactivityAction  engine.InactivityAction `json:"inactivity_action,omitempty"`  // What happens to a player idle past the timeout
+}
+
+// NewLobbyState creates a lobby with maxPlayers open slots
+func NewLobbyState(name, techTree string, maxPlayers int) *LobbyState {
+	state := &LobbyState{Name: name, TechTree: techTree}
+	for i := 0; i < maxPlayers; i++ {
+		state.Slots = append(state.Slots, PlayerSlot{Index: i, Kind: SlotOpen, Team: i + 1})
+	}
+	return state
+}
+
+// Clone returns a deep copy of the state
+func (s *LobbyState) Clone() LobbyState {
+	clone := *s
+	clone.AvailableMaps = append([]string(nil), s.AvailableMaps...)
+	clone.AvailableFactions = append([]string(nil), s.AvailableFactions...)
+	clone.Slots = append([]PlayerSlot(nil), s.Slots...)
+	return clone
+}
+
+// PlayerCount returns the number of human and AI slots
+func (s *LobbyState) PlayerCount() int {
+	count := 0
+	for _, slot := range s.Slots {
+		if slot.Kind == SlotHuman || slot.Kind == SlotAI {
+			count++
+		}
+	}
+	return count
+}
+
+// AllReady reports whether at least two players are seated, every human is
+// ready and every seated player has a faction
+func (s *LobbyState) AllReady() bool {
+	if s.PlayerCount() < 2 || s.MapName == "" {
+		return false
+	}
+	for _, slot := range s.Slots {
+		switch slot.Kind {
+		case SlotHuman:
+			if !slot.Ready || slot.Faction == "" {
+				return false
+			}
+		case SlotAI:
+			if slot.Faction == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SetMap changes the map and clears every ready flag, since players agreed to the old one
+func (s *LobbyState) SetMap(mapName string) error {
+	if len(s.AvailableMaps) > 0 && !contains(s.AvailableMaps, mapName) {
+		return fmt.Errorf("map %q is not available", mapName)
+	}
+	s.MapName = mapName
+	for i := range s.Slots {
+		s.Slots[i].Ready = false
+	}
+	return nil
+}
+
+// SetUnitUpkeep turns upkeep mode on or off and clears every ready flag,
+// since it changes the match players agreed to
+func (s *LobbyState) SetUnitUpkeep(enabled bool) {
+	s.UnitUpkeep = enabled
+	for i := range s.Slots {
+		s.Slots[i].Ready = false
+	}
+}
+
+// SetInactivity sets how long players may stay idle or disconnected and what
+// happens to them afterwards, clearing every ready flag
+func (s *LobbyState) SetInactivity(timeout time.Duration, action engine.InactivityAction) error {
+	if timeout < 0 {
+		return fmt.Errorf("inactivity timeout cannot be negative")
+	}
+	s.InactivityTimeout, s.InactivityAction = timeout, action
+	for i := range s.Slots {
+		s.Slots[i].Ready = false
+	}
+	return nil
+}
+
+// SetFaction sets the faction of a slot and clears its ready flag
+func (s *LobbyState) SetFaction(index int, faction string) error {
+	slot, err := s.occupiedSlot(index)
+	if err != nil {
+		return err
+	}
+	if len(s.AvailableFactions) > 0 && !contains(s.AvailableFactions, faction) {
+		return fmt.Errorf("faction %q is not available", faction)
+	}
+	slot.Faction = faction
+	slot.Ready = false
+	return nil
+}
+
+// SetTeam sets the team of a slot
+func (s *LobbyState) SetTeam(index, team int) error {
+	slot, err := s.occupiedSlot(index)
+	if err != nil {
+		return err
+	}
+	if team < 1 || team > len(s.Slots) {
+		return fmt.Errorf("team must be between 1 and %d", len(s.Slots))
+	}
+	slot.Team = team
+	return nil
+}
+
+// SetReady sets the ready flag of a human slot; a slot needs a faction to be ready
+func (s *LobbyState) SetReady(index int, ready bool) error {
+	slot, err := s.occupiedSlot(index)
+	if err != nil {
+		return err
+	}
+	if slot.Kind != SlotHuman {
+		return fmt.Errorf("slot %d is not a human player", index)
+	}
+	if ready && slot.Faction == "" {
+		return fmt.Errorf("choose a faction before getting ready")
+	}
+	slot.Ready = ready
+	return nil
+}
+
+// SetSlotKind opens, closes or assigns an AI to a slot that has no human in it
+func (s *LobbyState) SetSlotKind(index int, kind SlotKind) error {
+	if index < 0 || index >= len(s.Slots) {
+		return fmt.Errorf("slot %d does not exist", index)
+	}
+	slot := &s.Slots[index]
+	if slot.Kind == SlotHuman || kind == SlotHuman {
+		return fmt.Errorf("human slots are assigned by joining")
+	}
+	slot.Kind = kind
+	slot.Ready = false
+	slot.PlayerName = ""
+	slot.AIPersonality, slot.AIDifficulty, slot.Handicap = "", "", 0
+	if kind == SlotAI {
+		slot.PlayerName = fmt.Sprintf("AI %d", index+1)
+		slot.AIPersonality, slot.AIDifficulty, slot.Handicap = "balanced", "normal", 100
+	}
+	return nil
+}
+
+// SetAISettings sets the personality, difficulty and resource handicap of an AI slot
+func (s *LobbyState) SetAISettings(index int, personality, difficulty string, handicap int) error {
+	slot, err := s.occupiedSlot(index)
+	if err != nil {
+		return err
+	}
+	if slot.Kind != SlotAI {
+		return fmt.Errorf("slot %d is not an AI player", index)
+	}
+	if !contains(engine.AIPersonalityNames, personality) {
+		return fmt.Errorf("unknown AI personality %q", personality)
+	}
+	if !contains(engine.AIDifficultyNames, difficulty) {
+		return fmt.Errorf("unknown AI difficulty %q", difficulty)
+	}
+	if !containsInt(HandicapSteps, handicap) {
+		return fmt.Errorf("handicap must be one of %v percent", HandicapSteps)
+	}
+	slot.AIPersonality, slot.AIDifficulty, slot.Handicap = personality, difficulty, handicap
+	return nil
+}
+
+// GameSettings converts the lobby into engine settings; player IDs are slot index + 1.
+// Map and tech tree paths depend on the local data directory and are left to the caller.
+func (s *LobbyState) GameSettings() engine.GameSettings {
+	settings := engine.GameSettings{
+		PlayerFactions:     make(map[int]string),
+		AIFactions:         make(map[int]string),
+		AISlots:            make(map[int]engine.AISlotSettings),
+		Teams:              make(map[int]int),
+		GameSpeed:          1.0,
+		ResourceMultiplier: 1.0,
+		MaxPlayers:         len(s.Slots),
+		UnitUpkeep:         s.UnitUpkeep,
+		InactivityTimeout:  s.InactivityTimeout,
+		InactivityAction:   s.InactivityAction,
+	}
+	for _, slot := range s.Slots {
+		switch slot.Kind {
+		case SlotHuman:
+			settings.PlayerFactions[slot.Index+1] = slot.Faction
+		case SlotAI:
+			settings.AIFactions[slot.Index+1] = slot.Faction
+			settings.AISlots[slot.Index+1] = engine.AISlotSettings{
+				Personality: slot.AIPersonality,
+				Difficulty:  slot.AIDifficulty,
+				Handicap:    slot.Handicap,
+			}
+		default:
+			continue
+		}
+		settings.Teams[slot.Index+1] = slot.Team
+	}
+	return settings
+}
+
+// seatPlayer puts a joining player in the first open slot
+func (s *LobbyState) seatPlayer(name string) (int, error) {
+	for i := range s.Slots {
+		if s.Slots[i].Kind == SlotOpen {
+			s.Slots[i] = PlayerSlot{Index: i, Kind: SlotHuman, PlayerName: name, Team: s.Slots[i].Team}
+			if len(s.AvailableFactions) > 0 {
+				s.Slots[i].Faction = s.AvailableFactions[0]
+			}
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("lobby is full")
+}
+
+// occupiedSlot returns a human or AI slot
+func (s *LobbyState) occupiedSlot(index int) (*PlayerSlot, error) {
+	if index < 0 || index >= len(s.Slots) {
+		return nil, fmt.Errorf("slot %d does not exist", index)
+	}
+	slot := &s.Slots[index]
+	if slot.Kind != SlotHuman && slot.Kind != SlotAI {
+		return nil, fmt.Errorf("slot %d is %s", index, slot.Kind)
+	}
+	return slot, nil
+}
+
+// LaunchInfo is handed from the lobby to the match: the agreed state, the
+// shared random seed and which player this client controls
+type LaunchInfo struct {
+	Lobby         LobbyState `json:"lobby"`
+	Seed          int64      `json:"seed"`
+	LocalPlayerID int        `json:"local_player_id"`
+	SessionToken  string     `json:"session_token,omitempty"` // Lets a guest rejoin the running match
+}
+
+// LobbySession is the side of a lobby the UI drives; LobbyHost and LobbyGuest implement it
+type LobbySession interface {
+	State() LobbyState
+	LocalSlot() int
+	IsHost() bool
+	SetFaction(faction string) error
+	SetTeam(team int) error
+	SetReady(ready bool) error
+	Launched() <-chan LaunchInfo
+	Close() error
+}
+
+// helloPayload is sent by a guest when it connects
+type helloPayload struct {
+	Version    int    `json:"version"`
+	PlayerName string `json:"player_name"`
+}
+
+// welcomePayload tells a guest its slot
+type welcomePayload struct {
+	Slot  int        `json:"slot"`
+	State LobbyState `json:"state"`
+}
+
+// rejectPayload explains why a guest was refused
+type rejectPayload struct {
+	Reason string `json:"reason"`
+}
+
+// factionPayload, teamPayload and readyPayload carry guest slot changes
+type factionPayload struct {
+	Faction string `json:"faction"`
+}
+
+type teamPayload struct {
+	Team int `json:"team"`
+}
+
+type readyPayload struct {
+	Ready bool `json:"ready"`
+}
+
+// LobbyHost owns the lobby state and relays it to connected guests
+type LobbyHost struct {
+	state    *LobbyState
+	guests   map[int]*Conn // Slot index -> connection
+	listener net.Listener
+	launched chan LaunchInfo
+	session  *SessionHost // Set at launch; guest connections then carry the lockstep session
+	onChange func(LobbyState)
+	closed   bool
+	mutex    sync.Mutex
+}
+
+// NewLobbyHost creates a lobby with the host seated in slot 0
+func NewLobbyHost(state *LobbyState, hostName string) (*LobbyHost, error) {
+	if len(state.Slots) < 2 {
+		return nil, fmt.Errorf("a lobby needs at least 2 slots")
+	}
+	if _, err := state.seatPlayer(hostName); err != nil {
+		return nil, err
+	}
+	return &LobbyHost{
+		state:    state,
+		guests:   make(map[int]*Conn),
+		launched: make(chan LaunchInfo, 1),
+	}, nil
+}
+
+// Listen accepts guests on address (e.g. ":61357") in the background
+func (h *LobbyHost) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	h.mutex.Lock()
+	h.listener = listener
+	h.mutex.Unlock()
+
+	go h.acceptLoop(listener)
+	return nil
+}
+
+// Addr returns the address guests connect to, or "" before Listen
+func (h *LobbyHost) Addr() string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.listener == nil {
+		return ""
+	}
+	return h.listener.Addr().String()
+}
+
+// SetChangeHandler registers a function called with the new state after every change
+func (h *LobbyHost) SetChangeHandler(handler func(LobbyState)) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.onChange = handler
+}
+
+// State returns a copy of the current lobby state
+func (h *LobbyHost) State() LobbyState {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.state.Clone()
+}
+
+// LocalSlot returns the host's slot
+func (h *LobbyHost) LocalSlot() int { return 0 }
+
+// IsHost returns true
+func (h *LobbyHost) IsHost() bool { return true }
+
+// SetFaction changes the host's faction
+func (h *LobbyHost) SetFaction(faction string) error {
+	return h.update(func(s *LobbyState) error { return s.SetFaction(0, faction) })
+}
+
+// SetTeam changes the host's team
+func (h *LobbyHost) SetTeam(team int) error {
+	return h.update(func(s *LobbyState) error { return s.SetTeam(0, team) })
+}
+
+// SetReady changes the host's ready flag
+func (h *LobbyHost) SetReady(ready bool) error {
+	return h.update(func(s *LobbyState) error { return s.SetReady(0, ready) })
+}
+
+// SetMap changes the map, which clears every ready flag
+func (h *LobbyHost) SetMap(mapName string) error {
+	return h.update(func(s *LobbyState) error { return s.SetMap(mapName) })
+}
+
+// SetSlotKind opens, closes or assigns an AI to a slot
+func (h *LobbyHost) SetSlotKind(index int, kind SlotKind) error {
+	return h.update(func(s *LobbyState) error { return s.SetSlotKind(index, kind) })
+}
+
+// SetUnitUpkeep turns upkeep mode on or off, which clears every ready flag
+func (h *LobbyHost) SetUnitUpkeep(enabled bool) error {
+	return h.update(func(s *LobbyState) error { s.SetUnitUpkeep(enabled); return nil })
+}
+
+// SetInactivity sets the inactivity timeout and action, which clears every ready flag
+func (h *LobbyHost) SetInactivity(timeout time.Duration, action engine.InactivityAction) error {
+	return h.update(func(s *LobbyState) error { return s.SetInactivity(timeout, action) })
+}
+
+// SetSlotFaction sets the faction of any occupied slot (used for AI players)
+func (h *LobbyHost) SetSlotFaction(index int, faction string) error {
+	return h.update(func(s *LobbyState) error { return s.SetFaction(index, faction) })
+}
+
+// SetSlotAI sets the personality, difficulty and resource handicap of an AI slot
+func (h *LobbyHost) SetSlotAI(index int, personality, difficulty string, handicap int) error {
+	return h.update(func(s *LobbyState) error { return s.SetAISettings(index, personality, difficulty, handicap) })
+}
+
+// Launched returns a channel that receives the launch information once
+func (h *LobbyHost) Launched() <-chan LaunchInfo {
+	return h.launched
+}
+
+// Session returns the lockstep session, or nil before launch
+func (h *LobbyHost) Session() *SessionHost {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.session
+}
+
+// Launch starts the match once everyone is ready, telling each guest its
+// player ID and the token it needs to rejoin
+func (h *LobbyHost) Launch() (LaunchInfo, error) {
+	h.mutex.Lock()
+	if h.session != nil {
+		h.mutex.Unlock()
+		return LaunchInfo{}, fmt.Errorf("match already launched")
+	}
+	if !h.state.AllReady() {
+		h.mutex.Unlock()
+		return LaunchInfo{}, fmt.Errorf("not all players are ready")
+	}
+	info := LaunchInfo{Lobby: h.state.Clone(), Seed: rand.Int63(), LocalPlayerID: 1}
+	tokens := make(map[string]int, len(h.guests))
+	conns := make(map[int]*Conn, len(h.guests))
+	for slot, conn := range h.guests {
+		tokens[newSessionToken()] = slot + 1
+		conns[slot+1] = conn
+	}
+	h.session = newSessionHost(info, tokens, conns)
+
+	// Queued before the host loop can reach the session, so the launch goes
+	// out ahead of the first tick; a guest it fails to reach can rejoin
+	for token, playerID := range tokens {
+		guestInfo := info
+		guestInfo.LocalPlayerID = playerID
+		guestInfo.SessionToken = token
+		h.session.send(playerID, MsgLaunch, guestInfo)
+	}
+	h.mutex.Unlock()
+
+	h.launched <- info
+	return info, nil
+}
+
+// Close stops accepting guests and disconnects everyone
+func (h *LobbyHost) Close() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	if h.session != nil {
+		h.session.Close()
+	}
+	for _, conn := range h.guests {
+		conn.Send(MsgLeave, nil)
+		conn.Close()
+	}
+	if h.listener != nil {
+		return h.listener.Close()
+	}
+	return nil
+}
+
+// acceptLoop handles incoming connections until the listener closes
+func (h *LobbyHost) acceptLoop(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go h.serveGuest(NewConn(conn))
+	}
+}
+
+// serveGuest seats a guest (or rejoins it to the running match) and handles
+// its messages until it disconnects
+func (h *LobbyHost) serveGuest(conn *Conn) {
+	message, err := conn.ReceiveWithin(DefaultJoinTimeout)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	switch message.Type {
+	case MsgHello:
+		h.serveLobbyGuest(conn, message)
+	case MsgRejoin:
+		h.serveRejoin(conn, message)
+	default:
+		conn.Close()
+	}
+}
+
+// serveRejoin returns a disconnected guest to the running match
+func (h *LobbyHost) serveRejoin(conn *Conn, message Message) {
+	session := h.Session()
+	if session == nil {
+		conn.Send(MsgReject, rejectPayload{Reason: "no match is running"})
+		conn.Close()
+		return
+	}
+	playerID, err := session.rejoin(conn, message)
+	if err != nil {
+		conn.Send(MsgReject, rejectPayload{Reason: err.Error()})
+		conn.Close()
+		return
+	}
+	h.serveSession(session, playerID, conn)
+}
+
+// serveSession relays a guest's session messages until it disconnects
+func (h *LobbyHost) serveSession(session *SessionHost, playerID int, conn *Conn) {
+	for {
+		message, err := conn.Receive()
+		if err != nil || message.Type == MsgLeave {
+			break
+		}
+		h.handleSessionMessage(session, playerID, message)
+	}
+	session.disconnected(playerID, conn)
+}
+
+// handleSessionMessage passes a guest message to the session, logging rejections
+func (h *LobbyHost) handleSessionMessage(session *SessionHost, playerID int, message Message) {
+	if err := session.handleMessage(playerID, message); err != nil {
+		logging.Warnf(logging.CategoryNet, "Rejected session message %s from player %d: %v", message.Type, playerID, err)
+	}
+}
+
+// serveLobbyGuest seats a new guest and applies its lobby messages
+func (h *LobbyHost) serveLobbyGuest(conn *Conn, message Message) {
+	if h.Session() != nil {
+		conn.Send(MsgReject, rejectPayload{Reason: "match already running"})
+		conn.Close()
+		return
+	}
+	var hello helloPayload
+	if err := message.Decode(&hello); err != nil || hello.Version != ProtocolVersion {
+		conn.Send(MsgReject, rejectPayload{Reason: fmt.Sprintf("protocol version %d required", ProtocolVersion)})
+		conn.Close()
+		return
+	}
+
+	h.mutex.Lock()
+	slot, err := h.state.seatPlayer(hello.PlayerName)
+	if err == nil {
+		h.guests[slot] = conn
+	}
+	state := h.state.Clone()
+	h.mutex.Unlock()
+
+	if err != nil {
+		conn.Send(MsgReject, rejectPayload{Reason: err.Error()})
+		conn.Close()
+		return
+	}
+	logging.Infof(logging.CategoryNet, "Player %s joined lobby slot %d from %s", hello.PlayerName, slot, conn.RemoteAddr())
+	conn.Send(MsgWelcome, welcomePayload{Slot: slot, State: state})
+	h.broadcast()
+
+	for {
+		message, err := conn.Receive()
+		if err != nil || message.Type == MsgLeave {
+			break
+		}
+		if session := h.Session(); session != nil {
+			// The match launched; this connection now carries the session
+			h.handleSessionMessage(session, slot+1, message)
+			h.serveSession(session, slot+1, conn)
+			return
+		}
+		if err := h.applyGuestMessage(slot, message); err != nil {
+			logging.Warnf(logging.CategoryNet, "Rejected lobby message %s from slot %d: %v", message.Type, slot, err)
+			h.broadcast() // Resync the guest with the authoritative state
+		}
+	}
+
+	if session := h.Session(); session != nil {
+		session.disconnected(slot+1, conn)
+		return
+	}
+	h.removeGuest(slot, conn)
+}
+
+// applyGuestMessage applies a slot change requested by a guest
+func (h *LobbyHost) applyGuestMessage(slot int, message Message) error {
+	switch message.Type {
+	case MsgSetFaction:
+		var payload factionPayload
+		if err := message.Decode(&payload); err != nil {
+			return err
+		}
+		return h.update(func(s *LobbyState) error { return s.SetFaction(slot, payload.Faction) })
+	case MsgSetTeam:
+		var payload teamPayload
+		if err := message.Decode(&payload); err != nil {
+			return err
+		}
+		return h.update(func(s *LobbyState) error { return s.SetTeam(slot, payload.Team) })
+	case MsgSetReady:
+		var payload readyPayload
+		if err := message.Decode(&payload); err != nil {
+			return err
+		}
+		return h.update(func(s *LobbyState) error { return s.SetReady(slot, payload.Ready) })
+	default:
+		return fmt.Errorf("unexpected message type %s", message.Type)
+	}
+}
+
+// removeGuest frees the slot of a disconnected guest
+func (h *LobbyHost) removeGuest(slot int, conn *Conn) {
+	conn.Close()
+
+	h.mutex.Lock()
+	if h.closed || h.guests[slot] != conn {
+		h.mutex.Unlock()
+		return
+	}
+	delete(h.guests, slot)
+	team := h.state.Slots[slot].Team
+	h.state.Slots[slot] = PlayerSlot{Index: slot, Kind: SlotOpen, Team: team}
+	h.mutex.Unlock()
+
+	h.broadcast()
+}
+
+// update applies a change to the state and broadcasts it on success
+func (h *LobbyHost) update(change func(*LobbyState) error) error {
+	h.mutex.Lock()
+	err := change(h.state)
+	h.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+	h.broadcast()
+	return nil
+}
+
+// broadcast sends the current state to all guests and the change handler
+func (h *LobbyHost) broadcast() {
+	h.mutex.Lock()
+	state := h.state.Clone()
+	guests := make([]*Conn, 0, len(h.guests))
+	for _, conn := range h.guests {
+		guests = append(guests, conn)
+	}
+	handler := h.onChange
+	h.mutex.Unlock()
+
+	for _, conn := range guests {
+		conn.Send(MsgLobbyState, state)
+	}
+	if handler != nil {
+		handler(state)
+	}
+}
+
+// LobbyGuest is a client seated in a remote host's lobby
+type LobbyGuest struct {
+	conn     *Conn
+	address  string // Host address, kept for rejoining the match
+	slot     int
+	state    LobbyState
+	launched chan LaunchInfo
+	done     chan struct{}
+	err      error // Why the session ended (nil while connected)
+	onChange func(LobbyState)
+	mutex    sync.Mutex
+}
+
+// JoinLobby connects to a host and waits until it assigns a slot
+func JoinLobby(address, playerName string, timeout time.Duration) (*LobbyGuest, error) {
+	conn, err := Dial(address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Send(MsgHello, helloPayload{Version: ProtocolVersion, PlayerName: playerName}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	message, err := conn.ReceiveWithin(timeout)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("no answer from host: %w", err)
+	}
+	switch message.Type {
+	case MsgWelcome:
+	case MsgReject:
+		var reject rejectPayload
+		message.Decode(&reject)
+		conn.Close()
+		return nil, fmt.Errorf("host rejected join: %s", reject.Reason)
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("unexpected %s message from host", message.Type)
+	}
+
+	var welcome welcomePayload
+	if err := message.Decode(&welcome); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	guest := &LobbyGuest{
+		conn:     conn,
+		address:  address,
+		slot:     welcome.Slot,
+		state:    welcome.State,
+		launched: make(chan LaunchInfo, 1),
+		done:     make(chan struct{}),
+	}
+	go guest.readLoop()
+	return guest, nil
+}
+
+// SetChangeHandler registers a function called with every state received from the host
+func (g *LobbyGuest) SetChangeHandler(handler func(LobbyState)) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.onChange = handler
+}
+
+// State returns the last state received from the host
+func (g *LobbyGuest) State() LobbyState {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.state.Clone()
+}
+
+// LocalSlot returns the slot the host assigned
+func (g *LobbyGuest) LocalSlot() int { return g.slot }
+
+// IsHost returns false
+func (g *LobbyGuest) IsHost() bool { return false }
+
+// SetFaction asks the host to change this player's faction
+func (g *LobbyGuest) SetFaction(faction string) error {
+	return g.conn.Send(MsgSetFaction, factionPayload{Faction: faction})
+}
+
+// SetTeam asks the host to change this player's team
+func (g *LobbyGuest) SetTeam(team int) error {
+	return g.conn.Send(MsgSetTeam, teamPayload{Team: team})
+}
+
+// SetReady asks the host to change this player's ready flag
+func (g *LobbyGuest) SetReady(ready bool) error {
+	return g.conn.Send(MsgSetReady, readyPayload{Ready: ready})
+}
+
+// Launched returns a channel that receives the launch information from the host
+func (g *LobbyGuest) Launched() <-chan LaunchInfo {
+	return g.launched
+}
+
+// Done is closed when the connection to the host ends
+func (g *LobbyGuest) Done() <-chan struct{} {
+	return g.done
+}
+
+// Err returns why the session ended, or nil while connected or after launch
+func (g *LobbyGuest) Err() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.err
+}
+
+// StartSession hands the connection over to the lockstep session once the
+// launch has been received; the guest must not be used afterwards
+func (g *LobbyGuest) StartSession(info LaunchInfo) (*SessionClient, error) {
+	<-g.done // The read loop stops after the launch message
+	if info.SessionToken == "" {
+		return nil, fmt.Errorf("launch has no session token")
+	}
+
+	statuses := make(map[int]PlayerStatus)
+	for _, slot := range info.Lobby.Slots {
+		if slot.Kind == SlotHuman && slot.Index > 0 {
+			statuses[slot.Index+1] = PlayerConnected
+		}
+	}
+	ticket := SessionTicket{Address: g.address, Token: info.SessionToken, PlayerID: info.LocalPlayerID}
+	return newSessionClient(g.conn, ticket, statuses), nil
+}
+
+// Close leaves the lobby
+func (g *LobbyGuest) Close() error {
+	g.conn.Send(MsgLeave, nil)
+	return g.conn.Close()
+}
+
+// readLoop applies host messages until launch or disconnect
+func (g *LobbyGuest) readLoop() {
+	defer close(g.done)
+
+	for {
+		message, err := g.conn.Receive()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				g.setErr(fmt.Errorf("connection to host lost: %w", err))
+			}
+			return
+		}
+
+		switch message.Type {
+		case MsgLobbyState:
+			var state LobbyState
+			if err := message.Decode(&state); err != nil {
+				continue
+			}
+			g.mutex.Lock()
+			g.state = state
+			handler := g.onChange
+			g.mutex.Unlock()
+			if handler != nil {
+				handler(state)
+			}
+		case MsgLaunch:
+			var info LaunchInfo
+			if err := message.Decode(&info); err != nil {
+				g.setErr(err)
+				return
+			}
+			g.launched <- info
+			return
+		case MsgLeave:
+			g.setErr(fmt.Errorf("host closed the lobby"))
+			return
+		}
+	}
+}
+
+// setErr records why the session ended
+func (g *LobbyGuest) setErr(err error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.err = err
+}
+
+// contains reports whether list holds value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, value int) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,268 @@
+package network
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GameListing describes a hosted game announced to the master server
+type GameListing struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	HostPlayer string    `json:"host_player"`
+	Address    string    `json:"address"` // host:port guests connect to
+	MapName    string    `json:"map_name"`
+	TechTree   string    `json:"tech_tree"`
+	Players    int       `json:"players"`
+	MaxPlayers int       `json:"max_players"`
+	Version    int       `json:"version"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// IsFull returns whether every slot is taken
+func (l GameListing) IsFull() bool {
+	return l.MaxPlayers > 0 && l.Players >= l.MaxPlayers
+}
+
+// MasterClient talks to a master server over its HTTP/JSON API
+type MasterClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewMasterClient creates a client for the master server at baseURL (e.g. "http://master.example.org")
+func NewMasterClient(baseURL string) *MasterClient {
+	return &MasterClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Announce publishes a hosted game and returns the ID assigned by the master server
+func (mc *MasterClient) Announce(listing GameListing) (string, error) {
+	listing.Version = ProtocolVersion
+	var created GameListing
+	if err := mc.do(http.MethodPost, "/games", listing, &created); err != nil {
+		return "", fmt.Errorf("failed to announce game: %w", err)
+	}
+	return created.ID, nil
+}
+
+// Update refreshes an announced game; hosts call it periodically as a heartbeat
+func (mc *MasterClient) Update(listing GameListing) error {
+	listing.Version = ProtocolVersion
+	if err := mc.do(http.MethodPut, "/games/"+url.PathEscape(listing.ID), listing, nil); err != nil {
+		return fmt.Errorf("failed to update game %s: %w", listing.ID, err)
+	}
+	return nil
+}
+
+// Withdraw removes an announced game, e.g. when it launches or the host quits
+func (mc *MasterClient) Withdraw(id string) error {
+	if err := mc.do(http.MethodDelete, "/games/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("failed to withdraw game %s: %w", id, err)
+	}
+	return nil
+}
+
+// ListGames returns the open games known to the master server
+func (mc *MasterClient) ListGames() ([]GameListing, error) {
+	var listings []GameListing
+	if err := mc.do(http.MethodGet, "/games", nil, &listings); err != nil {
+		return nil, fmt.Errorf("failed to list games: %w", err)
+	}
+	return listings, nil
+}
+
+// Join looks up a game by ID and connects to its lobby
+func (mc *MasterClient) Join(id, playerName string) (*LobbyGuest, error) {
+	var listing GameListing
+	if err := mc.do(http.MethodGet, "/games/"+url.PathEscape(id), nil, &listing); err != nil {
+		return nil, fmt.Errorf("failed to look up game %s: %w", id, err)
+	}
+	if listing.Version != ProtocolVersion {
+		return nil, fmt.Errorf("game %s uses protocol version %d, this client uses %d", id, listing.Version, ProtocolVersion)
+	}
+	if listing.IsFull() {
+		return nil, fmt.Errorf("game %s is full", id)
+	}
+	return JoinLobby(listing.Address, playerName, DefaultJoinTimeout)
+}
+
+// do sends a JSON request and decodes a JSON response into out (if not nil)
+func (mc *MasterClient) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	request, err := http.NewRequest(method, mc.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := mc.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("master server returned %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+			return fmt.Errorf("invalid master server response: %w", err)
+		}
+	}
+	return nil
+}
+
+// MasterServer is an in-memory master server; listings that are not refreshed
+// within the expiry time are dropped
+type MasterServer struct {
+	games  map[string]GameListing
+	expiry time.Duration
+	now    func() time.Time
+	mutex  sync.Mutex
+}
+
+// NewMasterServer creates a master server whose listings expire after expiry
+func NewMasterServer(expiry time.Duration) *MasterServer {
+	return &MasterServer{
+		games:  make(map[string]GameListing),
+		expiry: expiry,
+		now:    time.Now,
+	}
+}
+
+// Handler returns the HTTP handler serving the master server API
+func (ms *MasterServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /games", ms.handleList)
+	mux.HandleFunc("POST /games", ms.handleAnnounce)
+	mux.HandleFunc("GET /games/{id}", ms.handleGet)
+	mux.HandleFunc("PUT /games/{id}", ms.handleUpdate)
+	mux.HandleFunc("DELETE /games/{id}", ms.handleWithdraw)
+	return mux
+}
+
+func (ms *MasterServer) handleList(w http.ResponseWriter, r *http.Request) {
+	ms.mutex.Lock()
+	ms.expire()
+	listings := make([]GameListing, 0, len(ms.games))
+	for _, listing := range ms.games {
+		listings = append(listings, listing)
+	}
+	ms.mutex.Unlock()
+
+	sort.Slice(listings, func(i, j int) bool { return listings[i].Name < listings[j].Name })
+	writeJSON(w, http.StatusOK, listings)
+}
+
+func (ms *MasterServer) handleAnnounce(w http.ResponseWriter, r *http.Request) {
+	var listing GameListing
+	if err := json.NewDecoder(r.Body).Decode(&listing); err != nil {
+		http.Error(w, "invalid listing", http.StatusBadRequest)
+		return
+	}
+	if listing.Address == "" {
+		http.Error(w, "listing needs an address", http.StatusBadRequest)
+		return
+	}
+
+	ms.mutex.Lock()
+	listing.ID = newGameID()
+	listing.LastSeen = ms.now()
+	ms.games[listing.ID] = listing
+	ms.mutex.Unlock()
+
+	writeJSON(w, http.StatusCreated, listing)
+}
+
+func (ms *MasterServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	ms.mutex.Lock()
+	ms.expire()
+	listing, exists := ms.games[r.PathValue("id")]
+	ms.mutex.Unlock()
+
+	if !exists {
+		http.Error(w, "game not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, listing)
+}
+
+func (ms *MasterServer) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var listing GameListing
+	if err := json.NewDecoder(r.Body).Decode(&listing); err != nil {
+		http.Error(w, "invalid listing", http.StatusBadRequest)
+		return
+	}
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.expire()
+
+	id := r.PathValue("id")
+	if _, exists := ms.games[id]; !exists {
+		http.Error(w, "game not found", http.StatusNotFound)
+		return
+	}
+	listing.ID = id
+	listing.LastSeen = ms.now()
+	ms.games[id] = listing
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ms *MasterServer) handleWithdraw(w http.ResponseWriter, r *http.Request) {
+	ms.mutex.Lock()
+	delete(ms.games, r.PathValue("id"))
+	ms.mutex.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// expire drops stale listings (lock must be held)
+func (ms *MasterServer) expire() {
+	if ms.expiry <= 0 {
+		return
+	}
+	cutoff := ms.now().Add(-ms.expiry)
+	for id, listing := range ms.games {
+		if listing.LastSeen.Before(cutoff) {
+			delete(ms.games, id)
+		}
+	}
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// newGameID returns a random 8-byte hex identifier
+func newGameID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
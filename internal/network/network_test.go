@@ -0,0 +1,251 @@
+package network
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// waitFor polls condition until it holds or the deadline passes
+func waitFor(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %s", description)
+}
+
+// newTestLobby starts a listening two-slot host with two factions and maps
+func newTestLobby(t *testing.T) *LobbyHost {
+	t.Helper()
+
+	state := NewLobbyState("test game", "megapack", 2)
+	state.AvailableFactions = []string{"magic", "tech"}
+	state.AvailableMaps = []string{"conflict", "four_rivers"}
+	state.MapName = "conflict"
+
+	host, err := NewLobbyHost(state, "host")
+	if err != nil {
+		t.Fatalf("Failed to create lobby host: %v", err)
+	}
+	if err := host.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { host.Close() })
+	return host
+}
+
+func TestMasterServerAnnounceListJoin(t *testing.T) {
+	host := newTestLobby(t)
+
+	master := NewMasterServer(time.Minute)
+	server := httptest.NewServer(master.Handler())
+	defer server.Close()
+	client := NewMasterClient(server.URL)
+
+	id, err := client.Announce(GameListing{
+		Name:       "test game",
+		HostPlayer: "host",
+		Address:    host.Addr(),
+		MapName:    "conflict",
+		Players:    1,
+		MaxPlayers: 2,
+	})
+	if err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+
+	games, err := client.ListGames()
+	if err != nil {
+		t.Fatalf("ListGames failed: %v", err)
+	}
+	if len(games) != 1 || games[0].ID != id || games[0].Address != host.Addr() {
+		t.Fatalf("Expected the announced game to be listed, got %+v", games)
+	}
+
+	guest, err := client.Join(id, "guest")
+	if err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+	defer guest.Close()
+	if guest.LocalSlot() != 1 {
+		t.Errorf("Expected guest in slot 1, got %d", guest.LocalSlot())
+	}
+
+	if err := client.Withdraw(id); err != nil {
+		t.Fatalf("Withdraw failed: %v", err)
+	}
+	if _, err := client.Join(id, "late"); err == nil {
+		t.Error("Expected joining a withdrawn game to fail")
+	}
+}
+
+func TestMasterServerExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	master := NewMasterServer(30 * time.Second)
+	master.now = func() time.Time { return now }
+	server := httptest.NewServer(master.Handler())
+	defer server.Close()
+	client := NewMasterClient(server.URL)
+
+	id, err := client.Announce(GameListing{Name: "stale", Address: "127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+
+	now = now.Add(20 * time.Second)
+	if err := client.Update(GameListing{ID: id, Name: "stale", Address: "127.0.0.1:1"}); err != nil {
+		t.Fatalf("Heartbeat failed: %v", err)
+	}
+
+	now = now.Add(20 * time.Second)
+	if games, _ := client.ListGames(); len(games) != 1 {
+		t.Fatalf("Expected refreshed game to survive, got %d games", len(games))
+	}
+
+	now = now.Add(time.Minute)
+	if games, _ := client.ListGames(); len(games) != 0 {
+		t.Fatalf("Expected stale game to expire, got %d games", len(games))
+	}
+}
+
+func TestLobbyNegotiationAndLaunch(t *testing.T) {
+	host := newTestLobby(t)
+
+	guest, err := JoinLobby(host.Addr(), "guest", time.Second)
+	if err != nil {
+		t.Fatalf("JoinLobby failed: %v", err)
+	}
+	defer guest.Close()
+
+	if err := guest.SetFaction("tech"); err != nil {
+		t.Fatalf("SetFaction failed: %v", err)
+	}
+	if err := guest.SetReady(true); err != nil {
+		t.Fatalf("SetReady failed: %v", err)
+	}
+	if err := host.SetReady(true); err != nil {
+		t.Fatalf("Host SetReady failed: %v", err)
+	}
+	waitFor(t, "guest to see everyone ready", func() bool { state := guest.State(); return state.AllReady() })
+
+	// Changing the map invalidates every ready flag
+	if err := host.SetMap("four_rivers"); err != nil {
+		t.Fatalf("SetMap failed: %v", err)
+	}
+	if err := host.SetUnitUpkeep(true); err != nil {
+		t.Fatalf("SetUnitUpkeep failed: %v", err)
+	}
+	waitFor(t, "guest to see the new map", func() bool { return guest.State().MapName == "four_rivers" && guest.State().UnitUpkeep })
+	if guest.State().Slots[1].Ready {
+		t.Error("Expected map change to clear the guest's ready flag")
+	}
+	if _, err := host.Launch(); err == nil {
+		t.Fatal("Expected launch to fail while players are not ready")
+	}
+
+	guest.SetReady(true)
+	host.SetReady(true)
+	waitFor(t, "host to see everyone ready", func() bool { state := host.State(); return state.AllReady() })
+
+	hostInfo, err := host.Launch()
+	if err != nil {
+		t.Fatalf("Launch failed: %v", err)
+	}
+
+	select {
+	case info := <-guest.Launched():
+		if info.LocalPlayerID != 2 || info.Seed != hostInfo.Seed {
+			t.Errorf("Unexpected guest launch info %+v (host seed %d)", info, hostInfo.Seed)
+		}
+		settings := info.Lobby.GameSettings()
+		if settings.PlayerFactions[1] != "magic" || settings.PlayerFactions[2] != "tech" {
+			t.Errorf("Unexpected factions in settings: %v", settings.PlayerFactions)
+		}
+		if !settings.UnitUpkeep {
+			t.Error("Expected the lobby's upkeep mode in the settings")
+		}
+		if settings.Teams[1] == 0 || settings.Teams[1] == settings.Teams[2] {
+			t.Errorf("Expected players on their own teams by default, got %v", settings.Teams)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Guest never received the launch")
+	}
+}
+
+func TestLobbyRejectsInvalidChanges(t *testing.T) {
+	host := newTestLobby(t)
+
+	if err := host.SetFaction("undead"); err == nil {
+		t.Error("Expected unknown faction to be rejected")
+	}
+	if err := host.SetMap("nowhere"); err == nil {
+		t.Error("Expected unknown map to be rejected")
+	}
+
+	guest, err := JoinLobby(host.Addr(), "guest", time.Second)
+	if err != nil {
+		t.Fatalf("JoinLobby failed: %v", err)
+	}
+	defer guest.Close()
+
+	if _, err := JoinLobby(host.Addr(), "third", time.Second); err == nil {
+		t.Error("Expected a full lobby to reject a third player")
+	}
+
+	// The guest's slot opens again when it leaves
+	guest.Close()
+	waitFor(t, "slot to reopen", func() bool { return host.State().Slots[1].Kind == SlotOpen })
+}
+
+func TestLobbyAISettings(t *testing.T) {
+	state := NewLobbyState("skirmish", "megapack", 3)
+	if err := state.SetSlotKind(1, SlotAI); err != nil {
+		t.Fatalf("SetSlotKind failed: %v", err)
+	}
+	if slot := state.Slots[1]; slot.AIPersonality != "balanced" || slot.AIDifficulty != "normal" || slot.Handicap != 100 {
+		t.Errorf("Expected a balanced, normal AI without handicap by default, got %+v", slot)
+	}
+
+	if err := state.SetAISettings(1, "aggressive", "hard", 150); err != nil {
+		t.Fatalf("SetAISettings failed: %v", err)
+	}
+	for _, invalid := range []struct {
+		index       int
+		personality string
+		difficulty  string
+		handicap    int
+	}{
+		{0, "aggressive", "hard", 100}, // Not an AI slot
+		{1, "reckless", "hard", 100},
+		{1, "aggressive", "impossible", 100},
+		{1, "aggressive", "hard", 110},
+	} {
+		if err := state.SetAISettings(invalid.index, invalid.personality, invalid.difficulty, invalid.handicap); err == nil {
+			t.Errorf("Expected %+v to be rejected", invalid)
+		}
+	}
+
+	state.Slots[0] = PlayerSlot{Index: 0, Kind: SlotHuman, PlayerName: "host", Faction: "magic", Team: 1}
+	state.Slots[1].Faction = "tech"
+	settings := state.GameSettings()
+	if slot := settings.AISlots[2]; slot.Personality != "aggressive" || slot.Difficulty != "hard" || slot.Handicap != 150 {
+		t.Errorf("Expected the AI slot's settings for player 2, got %+v", settings.AISlots)
+	}
+	if _, ok := settings.AISlots[1]; ok {
+		t.Error("Expected no AI settings for the human player")
+	}
+
+	// Reopening the slot drops its AI settings
+	if err := state.SetSlotKind(1, SlotOpen); err != nil {
+		t.Fatalf("SetSlotKind failed: %v", err)
+	}
+	if slot := state.Slots[1]; slot.AIDifficulty != "" || slot.Handicap != 0 {
+		t.Errorf("Expected an open slot without AI settings, got %+v", slot)
+	}
+}
@@ -0,0 +1,143 @@
+// Package network implements multiplayer support: the master server client
+// used to announce and find games, the lobby where players negotiate slots,
+// factions and the map, the lockstep session that relays commands once the
+// match runs, the spectator relay that broadcasts it, an optional
+// server-authoritative mode with delta-compressed snapshots, and the message
+// protocol shared by all of them.
+package network
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// ProtocolVersion is sent in the hello message; peers with another version are rejected
+const ProtocolVersion = 1
+
+// MessageType identifies the kind of a protocol message
+type MessageType string
+
+const (
+	MsgHello      MessageType = "hello"       // Guest asks to join a lobby
+	MsgWelcome    MessageType = "welcome"     // Host accepts a guest and assigns a slot
+	MsgReject     MessageType = "reject"      // Host refuses a guest
+	MsgLobbyState MessageType = "lobby_state" // Host broadcasts the full lobby state
+	MsgSetFaction MessageType = "set_faction" // Guest picks a faction for its slot
+	MsgSetTeam    MessageType = "set_team"    // Guest picks a team for its slot
+	MsgSetReady   MessageType = "set_ready"   // Guest toggles its ready flag
+	MsgLaunch     MessageType = "launch"      // Host starts the match
+	MsgLeave      MessageType = "leave"       // Either side closes the session
+
+	MsgCommand      MessageType = "command"       // Guest submits a command for the next tick
+	MsgTick         MessageType = "tick"          // Host broadcasts the commands of a tick
+	MsgRejoin       MessageType = "rejoin"        // Disconnected guest asks to return to a running match
+	MsgResync       MessageType = "resync"        // Host sends a snapshot and command backlog to a rejoining guest
+	MsgCaughtUp     MessageType = "caught_up"     // Rejoined guest has fast-forwarded to the current tick
+	MsgPlayerStatus MessageType = "player_status" // Host reports a player's connection status
+
+	MsgSpectate        MessageType = "spectate"         // Spectator asks to watch a broadcast
+	MsgBroadcastHeader MessageType = "broadcast_header" // Relay sends the match settings before the ticks
+
+	MsgSnapshot    MessageType = "snapshot"     // Authoritative server sends a snapshot delta
+	MsgSnapshotAck MessageType = "snapshot_ack" // Client confirms a snapshot, making it the next delta base
+)
+
+// Message is the envelope of every protocol message
+type Message struct {
+	Type    MessageType     `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Decode unmarshals the message payload into v
+func (m Message) Decode(v interface{}) error {
+	if len(m.Payload) == 0 {
+		return fmt.Errorf("%s message has no payload", m.Type)
+	}
+	if err := json.Unmarshal(m.Payload, v); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", m.Type, err)
+	}
+	return nil
+}
+
+// Conn exchanges newline-delimited JSON messages over a network connection
+type Conn struct {
+	conn       net.Conn
+	reader     *bufio.Reader
+	writeMutex sync.Mutex
+}
+
+// NewConn wraps an established connection
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		conn:   conn,
+		reader: bufio.NewReaderSize(conn, 64*1024),
+	}
+}
+
+// Dial connects to a host and wraps the connection
+func Dial(address string, timeout time.Duration) (*Conn, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	return NewConn(conn), nil
+}
+
+// Send writes one message; it is safe to call from several goroutines
+func (c *Conn) Send(messageType MessageType, payload interface{}) error {
+	message := Message{Type: messageType}
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s payload: %w", messageType, err)
+		}
+		message.Payload = encoded
+	}
+
+	line, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s message: %w", messageType, err)
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	if _, err := c.conn.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to send %s message: %w", messageType, err)
+	}
+	return nil
+}
+
+// Receive blocks until the next message arrives
+func (c *Conn) Receive() (Message, error) {
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		return Message{}, err
+	}
+
+	var message Message
+	if err := json.Unmarshal(line, &message); err != nil {
+		return Message{}, fmt.Errorf("malformed message: %w", err)
+	}
+	return message, nil
+}
+
+// ReceiveWithin waits at most timeout for the next message
+func (c *Conn) ReceiveWithin(timeout time.Duration) (Message, error) {
+	c.conn.SetReadDeadline(time.Now().Add(timeout))
+	defer c.conn.SetReadDeadline(time.Time{})
+	return c.Receive()
+}
+
+// RemoteAddr returns the address of the peer
+func (c *Conn) RemoteAddr() string {
+	return c.conn.RemoteAddr().String()
+}
+
+// Close closes the underlying connection
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,465 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// DefaultBroadcastDelay keeps spectators far enough behind that they cannot scout for a player
+const DefaultBroadcastDelay = 2 * time.Minute
+
+// Replay is a recorded match: the launch settings and every lockstep tick.
+// It is written as a .replay.tgs file and is also the format of live broadcasts,
+// which send everything but the ticks first and then stream the ticks.
+type Replay struct {
+	Header       engine.SaveGameHeader `json:"header"` // Read by the savegame catalog
+	Launch       LaunchInfo            `json:"launch"`
+	TickDuration time.Duration         `json:"tick_duration"`
+	Ticks        []TickCommands        `json:"ticks,omitempty"`
+}
+
+// NewReplay creates an empty recording for a launched match
+func NewReplay(launch LaunchInfo, tickDuration time.Duration, description string) *Replay {
+	replay := &Replay{
+		Header: engine.SaveGameHeader{
+			Version:     engine.SaveGameVersion,
+			SavedAt:     time.Now(),
+			MapPath:     launch.Lobby.MapName,
+			Description: description,
+		},
+		Launch:       launch,
+		TickDuration: tickDuration,
+	}
+	for _, slot := range launch.Lobby.Slots {
+		if slot.Kind == SlotHuman || slot.Kind == SlotAI {
+			replay.Header.PlayerCount++
+			replay.Header.PlayerNames = append(replay.Header.PlayerNames, slot.PlayerName)
+		}
+	}
+	// A broadcast must not carry a player's identity or rejoin token
+	replay.Launch.LocalPlayerID = 0
+	replay.Launch.SessionToken = ""
+	return replay
+}
+
+// Record appends a tick and updates the recorded game time
+func (r *Replay) Record(tick TickCommands) {
+	r.Ticks = append(r.Ticks, tick)
+	r.Header.GameTime = time.Duration(tick.Tick) * r.TickDuration
+}
+
+// WriteReplay writes a replay file (use engine.ReplayExtension) with
+// engine.DefaultSaveCompression
+func WriteReplay(path string, replay *Replay) error {
+	return WriteReplayCompressed(path, replay, engine.DefaultSaveCompression)
+}
+
+// WriteReplayCompressed writes a replay file with the given compression
+func WriteReplayCompressed(path string, replay *Replay, compression engine.SaveCompression) error {
+	if err := engine.WriteSaveFile(path, compression, replay); err != nil {
+		return fmt.Errorf("failed to write replay: %w", err)
+	}
+	return nil
+}
+
+// ReadReplay reads a replay file written by WriteReplay, or an uncompressed
+// one from before compression
+func ReadReplay(path string) (*Replay, error) {
+	var replay Replay
+	if _, err := engine.ReadSaveFile(path, &replay); err != nil {
+		return nil, fmt.Errorf("failed to read replay: %w", err)
+	}
+	if replay.Header.Version == 0 {
+		return nil, fmt.Errorf("replay %s has no version header", path)
+	}
+	if replay.Header.Version > engine.SaveGameVersion {
+		return nil, fmt.Errorf("replay version %d is newer than supported version %d", replay.Header.Version, engine.SaveGameVersion)
+	}
+	return &replay, nil
+}
+
+// spectatePayload is sent by a spectator when it connects
+type spectatePayload struct {
+	Version int `json:"version"`
+}
+
+// delayedTick is a published tick waiting for the broadcast delay to pass
+type delayedTick struct {
+	tick        TickCommands
+	publishedAt time.Time
+}
+
+// SpectatorRelay streams a match's ticks to read-only spectators after a
+// configurable delay. Spectators cannot send anything into the match; a
+// relay can also be fed from another relay to fan a broadcast out.
+type SpectatorRelay struct {
+	header     Replay         // Broadcast header (ticks are never stored here)
+	released   []TickCommands // Ticks already past the delay, sent to every new spectator
+	waiting    []delayedTick  // Ticks still inside the delay
+	spectators map[*Conn]bool
+	delay      time.Duration
+	listener   net.Listener
+	stop       chan struct{}
+	closed     bool
+	now        func() time.Time
+	mutex      sync.Mutex
+}
+
+// NewSpectatorRelay creates a relay for a launched match
+func NewSpectatorRelay(launch LaunchInfo, tickDuration, delay time.Duration) *SpectatorRelay {
+	return newSpectatorRelay(*NewReplay(launch, tickDuration, launch.Lobby.Name), delay)
+}
+
+// newSpectatorRelay creates a relay broadcasting under the given header
+func newSpectatorRelay(header Replay, delay time.Duration) *SpectatorRelay {
+	header.Ticks = nil
+	return &SpectatorRelay{
+		header:     header,
+		spectators: make(map[*Conn]bool),
+		delay:      delay,
+		stop:       make(chan struct{}),
+		now:        time.Now,
+	}
+}
+
+// Listen accepts spectators on address and releases delayed ticks in the background
+func (r *SpectatorRelay) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	r.mutex.Lock()
+	r.listener = listener
+	r.mutex.Unlock()
+
+	go r.acceptLoop(listener)
+	go r.releaseLoop()
+	return nil
+}
+
+// Addr returns the address spectators connect to, or "" before Listen
+func (r *SpectatorRelay) Addr() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.listener == nil {
+		return ""
+	}
+	return r.listener.Addr().String()
+}
+
+// Delay returns the broadcast delay
+func (r *SpectatorRelay) Delay() time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.delay
+}
+
+// SpectatorCount returns the number of connected spectators
+func (r *SpectatorRelay) SpectatorCount() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.spectators)
+}
+
+// Publish queues a tick for broadcast once the delay has passed; the host
+// calls it with every tick returned by SessionHost.AdvanceTick
+func (r *SpectatorRelay) Publish(tick TickCommands) {
+	r.mutex.Lock()
+	r.waiting = append(r.waiting, delayedTick{tick: tick, publishedAt: r.now()})
+	r.mutex.Unlock()
+
+	r.Flush()
+}
+
+// Flush sends every tick whose delay has passed
+func (r *SpectatorRelay) Flush() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cutoff := r.now().Add(-r.delay)
+	due := 0
+	for due < len(r.waiting) && !r.waiting[due].publishedAt.After(cutoff) {
+		due++
+	}
+	for _, waiting := range r.waiting[:due] {
+		r.released = append(r.released, waiting.tick)
+		for conn := range r.spectators {
+			if err := conn.Send(MsgTick, waiting.tick); err != nil {
+				r.dropSpectator(conn)
+			}
+		}
+	}
+	r.waiting = r.waiting[due:]
+}
+
+// Recording returns a replay of everything broadcast so far
+func (r *SpectatorRelay) Recording() *Replay {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	replay := r.header
+	for _, tick := range r.released {
+		replay.Record(tick)
+	}
+	return &replay
+}
+
+// NewRelayFromSpectator rebroadcasts what a spectator receives, so relays can
+// be chained to reach more viewers. The upstream delay already applies, so
+// delay is usually 0 here.
+func NewRelayFromSpectator(source *Spectator, delay time.Duration) *SpectatorRelay {
+	relay := newSpectatorRelay(source.Header(), delay)
+	go func() {
+		for tick := range source.Ticks() {
+			relay.Publish(tick)
+		}
+	}()
+	return relay
+}
+
+// Close disconnects every spectator and stops listening
+func (r *SpectatorRelay) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	close(r.stop)
+	for conn := range r.spectators {
+		conn.Send(MsgLeave, nil)
+		conn.Close()
+	}
+	r.spectators = make(map[*Conn]bool)
+	if r.listener != nil {
+		return r.listener.Close()
+	}
+	return nil
+}
+
+// acceptLoop handles incoming spectators until the listener closes
+func (r *SpectatorRelay) acceptLoop(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go r.serveSpectator(NewConn(conn))
+	}
+}
+
+// releaseLoop flushes delayed ticks even while no new ones are published
+func (r *SpectatorRelay) releaseLoop() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// serveSpectator sends the header and backlog, then keeps the spectator
+// registered until it disconnects; anything it sends is ignored
+func (r *SpectatorRelay) serveSpectator(conn *Conn) {
+	message, err := conn.ReceiveWithin(DefaultJoinTimeout)
+	if err != nil || message.Type != MsgSpectate {
+		conn.Close()
+		return
+	}
+	var request spectatePayload
+	if err := message.Decode(&request); err != nil || request.Version != ProtocolVersion {
+		conn.Send(MsgReject, rejectPayload{Reason: fmt.Sprintf("protocol version %d required", ProtocolVersion)})
+		conn.Close()
+		return
+	}
+
+	// Register under the lock so no tick is released between the backlog and the stream
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		conn.Close()
+		return
+	}
+	err = conn.Send(MsgBroadcastHeader, r.header)
+	for _, tick := range r.released {
+		if err != nil {
+			break
+		}
+		err = conn.Send(MsgTick, tick)
+	}
+	if err == nil {
+		r.spectators[conn] = true
+	}
+	r.mutex.Unlock()
+
+	if err != nil {
+		conn.Close()
+		return
+	}
+	logging.Infof(logging.CategoryNet, "Spectator connected from %s", conn.RemoteAddr())
+
+	for {
+		message, err := conn.Receive()
+		if err != nil || message.Type == MsgLeave {
+			break
+		}
+	}
+
+	r.mutex.Lock()
+	r.dropSpectator(conn)
+	r.mutex.Unlock()
+}
+
+// dropSpectator disconnects a spectator (lock must be held)
+func (r *SpectatorRelay) dropSpectator(conn *Conn) {
+	if r.spectators[conn] {
+		delete(r.spectators, conn)
+		conn.Close()
+	}
+}
+
+// Spectator receives a delayed broadcast from a relay
+type Spectator struct {
+	conn      *Conn
+	header    Replay
+	ticks     chan TickCommands
+	recording *Replay
+	done      chan struct{}
+	err       error // Why the broadcast ended (nil while connected)
+	mutex     sync.Mutex
+}
+
+// WatchBroadcast connects to a relay as a spectator
+func WatchBroadcast(address string, timeout time.Duration) (*Spectator, error) {
+	conn, err := Dial(address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Send(MsgSpectate, spectatePayload{Version: ProtocolVersion}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	message, err := conn.ReceiveWithin(timeout)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("no answer from relay: %w", err)
+	}
+	switch message.Type {
+	case MsgBroadcastHeader:
+	case MsgReject:
+		var reject rejectPayload
+		message.Decode(&reject)
+		conn.Close()
+		return nil, fmt.Errorf("relay rejected spectator: %s", reject.Reason)
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("unexpected %s message from relay", message.Type)
+	}
+
+	var header Replay
+	if err := message.Decode(&header); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	recording := header
+	spectator := &Spectator{
+		conn:      conn,
+		header:    header,
+		ticks:     make(chan TickCommands, 256),
+		recording: &recording,
+		done:      make(chan struct{}),
+	}
+	go spectator.readLoop()
+	return spectator, nil
+}
+
+// Header returns the broadcast header: the match settings without ticks
+func (s *Spectator) Header() Replay {
+	return s.header
+}
+
+// Ticks delivers the broadcast ticks in order; it is closed when the broadcast ends
+func (s *Spectator) Ticks() <-chan TickCommands {
+	return s.ticks
+}
+
+// Recording returns a replay of everything received so far, e.g. to save with WriteReplay
+func (s *Spectator) Recording() *Replay {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	replay := *s.recording
+	replay.Ticks = append([]TickCommands(nil), s.recording.Ticks...)
+	return &replay
+}
+
+// Done is closed when the connection to the relay ends
+func (s *Spectator) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why the broadcast ended, or nil while connected
+func (s *Spectator) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+// Close stops watching
+func (s *Spectator) Close() error {
+	s.conn.Send(MsgLeave, nil)
+	return s.conn.Close()
+}
+
+// readLoop records and delivers ticks until the broadcast ends
+func (s *Spectator) readLoop() {
+	defer close(s.done)
+	defer close(s.ticks)
+
+	for {
+		message, err := s.conn.Receive()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				s.setErr(fmt.Errorf("connection to relay lost: %w", err))
+			}
+			return
+		}
+
+		switch message.Type {
+		case MsgTick:
+			var tick TickCommands
+			if err := message.Decode(&tick); err != nil {
+				s.setErr(err)
+				return
+			}
+			s.mutex.Lock()
+			s.recording.Record(tick)
+			s.mutex.Unlock()
+			s.ticks <- tick
+		case MsgLeave:
+			return
+		}
+	}
+}
+
+// setErr records why the broadcast ended
+func (s *Spectator) setErr(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = err
+}
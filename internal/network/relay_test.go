@@ -0,0 +1,158 @@
+package network
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"teraglest/internal/engine"
+)
+
+// newTestRelay starts a relay for a two-player launch whose clock the test controls
+func newTestRelay(t *testing.T, delay time.Duration) (*SpectatorRelay, func(time.Duration)) {
+	t.Helper()
+
+	state := NewLobbyState("final", "megapack", 2)
+	state.seatPlayer("alice")
+	state.seatPlayer("bob")
+	state.MapName = "conflict"
+	launch := LaunchInfo{Lobby: state.Clone(), Seed: 42, LocalPlayerID: 1, SessionToken: "secret"}
+
+	relay := NewSpectatorRelay(launch, 100*time.Millisecond, delay)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	relay.now = func() time.Time { return now }
+	advance := func(d time.Duration) {
+		relay.mutex.Lock()
+		now = now.Add(d)
+		relay.mutex.Unlock()
+	}
+
+	if err := relay.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { relay.Close() })
+	return relay, advance
+}
+
+// watch connects a spectator to a relay
+func watch(t *testing.T, relay *SpectatorRelay) *Spectator {
+	t.Helper()
+
+	spectator, err := WatchBroadcast(relay.Addr(), time.Second)
+	if err != nil {
+		t.Fatalf("WatchBroadcast failed: %v", err)
+	}
+	t.Cleanup(func() { spectator.Close() })
+	waitFor(t, "spectator to register", func() bool { return relay.SpectatorCount() > 0 })
+	return spectator
+}
+
+// expectTick waits for the next broadcast tick
+func expectTick(t *testing.T, spectator *Spectator, want int64) {
+	t.Helper()
+
+	select {
+	case tick := <-spectator.Ticks():
+		if tick.Tick != want {
+			t.Fatalf("Expected tick %d, got %d", want, tick.Tick)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for tick %d", want)
+	}
+}
+
+func TestRelayDelaysTicks(t *testing.T) {
+	relay, advance := newTestRelay(t, 30*time.Second)
+	spectator := watch(t, relay)
+
+	header := spectator.Header()
+	if header.Launch.LocalPlayerID != 0 || header.Launch.SessionToken != "" {
+		t.Errorf("Broadcast header leaks player identity: %+v", header.Launch)
+	}
+	if header.Header.PlayerCount != 2 || header.Launch.Seed != 42 {
+		t.Errorf("Unexpected broadcast header %+v", header.Header)
+	}
+
+	relay.Publish(TickCommands{Tick: 1})
+	advance(10 * time.Second)
+	relay.Publish(TickCommands{Tick: 2})
+
+	select {
+	case tick := <-spectator.Ticks():
+		t.Fatalf("Tick %d broadcast before the delay passed", tick.Tick)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	advance(25 * time.Second)
+	relay.Flush()
+	expectTick(t, spectator, 1)
+
+	// A late spectator gets every released tick, but nothing still inside the delay
+	late := watch(t, relay)
+	expectTick(t, late, 1)
+
+	advance(10 * time.Second)
+	relay.Flush()
+	expectTick(t, spectator, 2)
+	expectTick(t, late, 2)
+}
+
+func TestRelayChaining(t *testing.T) {
+	upstream, _ := newTestRelay(t, 0)
+	upstream.Publish(TickCommands{Tick: 1})
+
+	source := watch(t, upstream)
+	downstream := NewRelayFromSpectator(source, 0)
+	if err := downstream.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer downstream.Close()
+
+	waitFor(t, "downstream to receive the backlog", func() bool { return len(downstream.Recording().Ticks) == 1 })
+	viewer := watch(t, downstream)
+	expectTick(t, viewer, 1)
+
+	upstream.Publish(TickCommands{Tick: 2, Commands: []NetCommand{{PlayerID: 1, Type: engine.CommandStop}}})
+	expectTick(t, viewer, 2)
+
+	if viewer.Header().Launch.Lobby.Name != "final" {
+		t.Errorf("Expected downstream to keep the upstream header, got %+v", viewer.Header().Launch.Lobby)
+	}
+}
+
+func TestReplayRecordingRoundTrip(t *testing.T) {
+	relay, _ := newTestRelay(t, 0)
+	spectator := watch(t, relay)
+
+	for tick := int64(1); tick <= 3; tick++ {
+		relay.Publish(TickCommands{Tick: tick})
+		expectTick(t, spectator, tick)
+	}
+
+	recording := spectator.Recording()
+	if len(recording.Ticks) != 3 || recording.Header.GameTime != 300*time.Millisecond {
+		t.Fatalf("Expected 3 ticks and 300ms recorded, got %d and %v", len(recording.Ticks), recording.Header.GameTime)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "final"+engine.ReplayExtension)
+	if err := WriteReplay(path, recording); err != nil {
+		t.Fatalf("WriteReplay failed: %v", err)
+	}
+	loaded, err := ReadReplay(path)
+	if err != nil {
+		t.Fatalf("ReadReplay failed: %v", err)
+	}
+	if len(loaded.Ticks) != 3 || loaded.Launch.Lobby.MapName != "conflict" {
+		t.Errorf("Unexpected replay after round trip: %+v", loaded)
+	}
+
+	// The savegame catalog lists broadcasts saved this way as replays
+	files, err := engine.ListSaveFiles(dir, engine.SaveFileKindReplay)
+	if err != nil {
+		t.Fatalf("ListSaveFiles failed: %v", err)
+	}
+	if len(files) != 1 || !files[0].Valid || files[0].Header.MapPath != "conflict" {
+		t.Errorf("Expected the replay in the catalog, got %+v", files)
+	}
+}
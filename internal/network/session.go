@@ -0,0 +1,800 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// DefaultReconnectTimeout is how long a disconnected player's slot is kept for a rejoin
+const DefaultReconnectTimeout = 2 * time.Minute
+
+// peerQueueLength is how many messages may wait for a guest before it counts
+// as too slow and is disconnected
+const peerQueueLength = 1024
+
+// PlayerStatus is the connection state of a player in a running match
+type PlayerStatus int
+
+const (
+	PlayerConnected    PlayerStatus = iota // Sending commands normally
+	PlayerReconnecting                     // Disconnected or fast-forwarding after a rejoin
+	PlayerDropped                          // Did not come back within the reconnect timeout
+)
+
+// String returns the display name of a PlayerStatus
+func (s PlayerStatus) String() string {
+	switch s {
+	case PlayerConnected:
+		return "Connected"
+	case PlayerReconnecting:
+		return "Reconnecting"
+	case PlayerDropped:
+		return "Dropped"
+	default:
+		return "Unknown"
+	}
+}
+
+// NetCommand is a command sent over the network; objects are referenced by ID
+type NetCommand struct {
+	PlayerID         int                    `json:"player_id"` // Set by the host from the sending connection
+	UnitIDs          []int                  `json:"unit_ids,omitempty"`
+	BuildingID       int                    `json:"building_id,omitempty"`
+	Type             engine.CommandType     `json:"type"`
+	Target           *engine.Vector3        `json:"target,omitempty"`
+	TargetUnitID     int                    `json:"target_unit_id,omitempty"`
+	TargetBuildingID int                    `json:"target_building_id,omitempty"`
+	Parameters       map[string]interface{} `json:"parameters,omitempty"`
+	Queued           bool                   `json:"queued,omitempty"`
+	Tribute          *TributeOrder          `json:"tribute,omitempty"`  // Resources sent to an ally instead of a unit command
+	Presence         *PresenceChange        `json:"presence,omitempty"` // Connection change reported by the host instead of a unit command
+	Chat             string                 `json:"chat,omitempty"`     // Chat command such as "/surrender" instead of a unit command
+}
+
+// PresenceChange tells every peer a player lost or regained its connection,
+// so inactivity handling sees it on the same tick everywhere
+type PresenceChange struct {
+	PlayerID  int  `json:"player_id"`
+	Connected bool `json:"connected"`
+}
+
+// TributeOrder sends resources to an allied player
+type TributeOrder struct {
+	ToPlayerID int            `json:"to_player_id"`
+	Resources  map[string]int `json:"resources"`
+}
+
+// TickCommands holds every command executed in one lockstep tick
+type TickCommands struct {
+	Tick     int64        `json:"tick"`
+	Commands []NetCommand `json:"commands,omitempty"`
+}
+
+// Resync is what a rejoining guest needs to reach the current tick: the last
+// checkpoint and every tick executed since
+type Resync struct {
+	Launch       LaunchInfo           `json:"launch"`
+	Snapshot     *engine.SaveGame     `json:"snapshot,omitempty"` // nil replays from the start of the match
+	SnapshotTick int64                `json:"snapshot_tick"`
+	Backlog      []TickCommands       `json:"backlog"`
+	CurrentTick  int64                `json:"current_tick"`
+	Statuses     map[int]PlayerStatus `json:"statuses"`
+}
+
+// SessionTicket is persisted by a guest so it can rejoin after a disconnect or restart
+type SessionTicket struct {
+	Address  string `json:"address"`
+	Token    string `json:"token"`
+	PlayerID int    `json:"player_id"`
+}
+
+// SaveSessionTicket writes a ticket to disk
+func SaveSessionTicket(path string, ticket SessionTicket) error {
+	data, err := json.MarshalIndent(ticket, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session ticket: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write session ticket: %w", err)
+	}
+	return nil
+}
+
+// LoadSessionTicket reads a ticket written by SaveSessionTicket
+func LoadSessionTicket(path string) (SessionTicket, error) {
+	var ticket SessionTicket
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ticket, fmt.Errorf("failed to read session ticket: %w", err)
+	}
+	if err := json.Unmarshal(data, &ticket); err != nil {
+		return ticket, fmt.Errorf("failed to parse session ticket: %w", err)
+	}
+	if ticket.Address == "" || ticket.Token == "" {
+		return ticket, fmt.Errorf("session ticket %s is incomplete", path)
+	}
+	return ticket, nil
+}
+
+// ApplyTick issues the commands of a tick to the world. Commands for objects
+// the sending player may not command (its own, or those of a faction it
+// shares) are skipped, so every peer applies the same set.
+func ApplyTick(world *engine.World, tick TickCommands) {
+	for _, netCommand := range tick.Commands {
+		if netCommand.Presence != nil {
+			world.SetPlayerConnected(netCommand.Presence.PlayerID, netCommand.Presence.Connected)
+			continue
+		}
+		if netCommand.Chat != "" {
+			if command, args, ok := engine.ParseChatCommand(netCommand.Chat); ok {
+				if err := world.RunChatCommand(netCommand.PlayerID, command, args); err != nil {
+					logging.Debugf(logging.CategoryNet, "Tick %d: chat command failed: %v", tick.Tick, err)
+				}
+			}
+			continue
+		}
+		if netCommand.Tribute != nil {
+			fromPlayerID := world.CommandedPlayer(netCommand.PlayerID)
+			if err := world.SendTribute(fromPlayerID, netCommand.Tribute.ToPlayerID, netCommand.Tribute.Resources); err != nil {
+				logging.Debugf(logging.CategoryNet, "Tick %d: tribute failed: %v", tick.Tick, err)
+			}
+			continue
+		}
+		command := netCommand.unitCommand(world)
+
+		if netCommand.BuildingID != 0 {
+			if err := world.IssuePlayerBuildingCommand(netCommand.PlayerID, netCommand.BuildingID, command); err != nil {
+				logging.Debugf(logging.CategoryNet, "Tick %d: building command failed: %v", tick.Tick, err)
+			}
+		}
+		for _, unitID := range netCommand.UnitIDs {
+			if err := world.IssuePlayerCommand(netCommand.PlayerID, unitID, command); err != nil {
+				logging.Debugf(logging.CategoryNet, "Tick %d: unit command failed: %v", tick.Tick, err)
+			}
+		}
+	}
+}
+
+// FastForward brings a freshly initialized world to the host's current tick:
+// it restores the checkpoint (if any) and replays the backlog
+func FastForward(world *engine.World, resync *Resync, tickDuration time.Duration) error {
+	if resync.Snapshot != nil {
+		if err := world.RestoreSaveGame(resync.Snapshot); err != nil {
+			return fmt.Errorf("failed to restore checkpoint at tick %d: %w", resync.SnapshotTick, err)
+		}
+	}
+	for _, tick := range resync.Backlog {
+		ApplyTick(world, tick)
+		world.Update(tickDuration)
+	}
+	return nil
+}
+
+// unitCommand resolves the object references of a network command
+func (nc NetCommand) unitCommand(world *engine.World) engine.UnitCommand {
+	command := engine.UnitCommand{
+		Type:       nc.Type,
+		Target:     nc.Target,
+		Parameters: nc.Parameters,
+		IsQueued:   nc.Queued,
+	}
+	if nc.TargetUnitID != 0 {
+		command.TargetUnit = world.ObjectManager.GetUnit(nc.TargetUnitID)
+	}
+	if nc.TargetBuildingID != 0 {
+		command.TargetBuilding = world.ObjectManager.GetBuilding(nc.TargetBuildingID)
+	}
+	return command
+}
+
+// rejoinPayload is sent by a guest returning to a running match
+type rejoinPayload struct {
+	Version int    `json:"version"`
+	Token   string `json:"token"`
+}
+
+// statusPayload reports a player's connection status
+type statusPayload struct {
+	PlayerID int          `json:"player_id"`
+	Status   PlayerStatus `json:"status"`
+}
+
+// peerMessage is a message waiting to be written to a guest
+type peerMessage struct {
+	messageType MessageType
+	payload     interface{}
+}
+
+// peer is the connection of a guest in a running match. Messages are queued
+// in order, e.g. under the session lock, and written by the peer's own
+// goroutine, so a slow guest never holds up the host.
+type peer struct {
+	conn    *Conn
+	queue   chan peerMessage
+	dropped bool // Too far behind; waiting for the session to notice
+}
+
+// newPeer starts writing the messages queued for a guest
+func newPeer(conn *Conn) *peer {
+	p := &peer{conn: conn, queue: make(chan peerMessage, peerQueueLength)}
+	go p.writeLoop()
+	return p
+}
+
+// send queues a message; a guest too far behind is disconnected and may rejoin
+func (p *peer) send(messageType MessageType, payload interface{}) {
+	select {
+	case p.queue <- peerMessage{messageType: messageType, payload: payload}:
+	default:
+		if p.dropped {
+			return
+		}
+		p.dropped = true
+		logging.Warnf(logging.CategoryNet, "Guest at %s is %d messages behind, disconnecting", p.conn.RemoteAddr(), peerQueueLength)
+		p.conn.Close()
+	}
+}
+
+// close closes the connection once the queued messages are written; the
+// peer must not be sent anything afterwards
+func (p *peer) close() {
+	close(p.queue)
+}
+
+// writeLoop writes queued messages until the queue is closed or a write fails
+func (p *peer) writeLoop() {
+	defer p.conn.Close()
+	for message := range p.queue {
+		if err := p.conn.Send(message.messageType, message.payload); err != nil {
+			logging.Warnf(logging.CategoryNet, "Failed to send %s to %s: %v", message.messageType, p.conn.RemoteAddr(), err)
+			break
+		}
+	}
+	for range p.queue {
+		// Drop what is left until the session closes the queue
+	}
+}
+
+// SessionHost runs the lockstep relay on the hosting machine. The host's game
+// loop calls AdvanceTick once per tick and Checkpoint now and then; guest
+// commands are collected in between and executed in the next tick.
+type SessionHost struct {
+	launch           LaunchInfo     // The host's own launch information
+	tokens           map[string]int // Session token -> player ID
+	peers            map[int]*peer  // Player ID -> guest connection
+	statuses         map[int]PlayerStatus
+	disconnectedAt   map[int]time.Time
+	tick             int64
+	pending          []NetCommand
+	backlog          []TickCommands   // Ticks executed since the checkpoint
+	checkpoint       *engine.SaveGame // World state after checkpointTick
+	checkpointTick   int64
+	reconnectTimeout time.Duration
+	onStatus         func(playerID int, status PlayerStatus)
+	validator        *CommandValidator      // Checks guest commands (nil = trusted)
+	latency          *engine.LatencySampler // Artificial command delay (nil = none)
+	tickDuration     time.Duration          // Game time per tick, to turn delays into ticks
+	delayed          []delayedNetCommand    // Commands waiting out the artificial delay, in order
+	now              func() time.Time
+	mutex            sync.Mutex
+}
+
+// newSessionHost starts a session for the guests connected at launch
+func newSessionHost(launch LaunchInfo, tokens map[string]int, conns map[int]*Conn) *SessionHost {
+	session := &SessionHost{
+		launch:           launch,
+		tokens:           tokens,
+		peers:            make(map[int]*peer, len(conns)),
+		statuses:         make(map[int]PlayerStatus),
+		disconnectedAt:   make(map[int]time.Time),
+		reconnectTimeout: DefaultReconnectTimeout,
+		now:              time.Now,
+	}
+	for _, playerID := range tokens {
+		session.statuses[playerID] = PlayerConnected
+	}
+	for playerID, conn := range conns {
+		session.peers[playerID] = newPeer(conn)
+	}
+	return session
+}
+
+// send queues a message for a guest, dropping it if the guest is not connected
+func (s *SessionHost) send(playerID int, messageType MessageType, payload interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if peer := s.peers[playerID]; peer != nil {
+		peer.send(messageType, payload)
+	}
+}
+
+// SetReconnectTimeout changes how long a disconnected player may take to rejoin
+func (s *SessionHost) SetReconnectTimeout(timeout time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.reconnectTimeout = timeout
+}
+
+// SetStatusHandler registers a function called when a player's connection status changes
+func (s *SessionHost) SetStatusHandler(handler func(playerID int, status PlayerStatus)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.onStatus = handler
+}
+
+// SetValidator makes the session check every guest command against the
+// host's world before executing it; rejected commands are dropped and logged
+func (s *SessionHost) SetValidator(validator *CommandValidator) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.validator = validator
+}
+
+// SetLatency delays every command by the given latency and jitter before it
+// is executed, for testing how a match feels under network lag. Commands are
+// held back whole ticks of tickDuration, so every peer still runs them in
+// the same tick. Zero settings turn the delay off.
+func (s *SessionHost) SetLatency(settings engine.LatencySettings, tickDuration time.Duration) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	if tickDuration <= 0 {
+		return fmt.Errorf("tick duration must be positive")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.latency, s.tickDuration = nil, tickDuration
+	if settings.Enabled() {
+		s.latency = engine.NewLatencySampler(settings)
+	}
+	return nil
+}
+
+// Tick returns the number of ticks executed so far
+func (s *SessionHost) Tick() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.tick
+}
+
+// Statuses returns the connection status of every guest
+func (s *SessionHost) Statuses() map[int]PlayerStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return copyStatuses(s.statuses)
+}
+
+// SubmitCommand queues a command of the host player for the next tick
+func (s *SessionHost) SubmitCommand(command NetCommand) {
+	command.PlayerID = s.launch.LocalPlayerID
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.submitLocked(command)
+}
+
+// delayedNetCommand is a command held back by the artificial latency
+type delayedNetCommand struct {
+	tick    int64 // Tick the command is executed in
+	command NetCommand
+}
+
+// submitLocked queues a command for the next tick, or a later one under
+// artificial latency (lock must be held)
+func (s *SessionHost) submitLocked(command NetCommand) {
+	if s.latency == nil && len(s.delayed) == 0 {
+		s.pending = append(s.pending, command)
+		return
+	}
+	tick := s.tick + 1
+	if s.latency != nil {
+		due := s.latency.Due(time.Duration(s.tick) * s.tickDuration)
+		if dueTick := int64((due + s.tickDuration - 1) / s.tickDuration); dueTick > tick {
+			tick = dueTick
+		}
+	}
+	// Never overtake a command still waiting
+	if last := len(s.delayed) - 1; last >= 0 && s.delayed[last].tick > tick {
+		tick = s.delayed[last].tick
+	}
+	s.delayed = append(s.delayed, delayedNetCommand{tick: tick, command: command})
+}
+
+// releaseDelayed moves the delayed commands due this tick to the pending
+// ones (lock must be held)
+func (s *SessionHost) releaseDelayed() {
+	count := 0
+	for count < len(s.delayed) && s.delayed[count].tick <= s.tick {
+		s.pending = append(s.pending, s.delayed[count].command)
+		count++
+	}
+	s.delayed = s.delayed[count:]
+}
+
+// AdvanceTick closes the current tick, sends its commands to every connected
+// guest and returns them for the host to execute
+func (s *SessionHost) AdvanceTick() TickCommands {
+	s.mutex.Lock()
+	s.tick++
+	s.releaseDelayed()
+	tick := TickCommands{Tick: s.tick, Commands: s.validatePending()}
+	s.pending = nil
+	s.backlog = append(s.backlog, tick)
+
+	// Queue under the lock so a rejoining guest cannot miss a tick between
+	// its resync and the stream; the peers write without it
+	for _, peer := range s.peers {
+		peer.send(MsgTick, tick)
+	}
+	dropped := s.expireDisconnected()
+	s.mutex.Unlock()
+
+	for _, playerID := range dropped {
+		s.notifyStatus(playerID, PlayerDropped)
+	}
+	return tick
+}
+
+// validatePending returns the pending commands the validator accepts; the
+// host player's own commands are trusted (lock must be held)
+func (s *SessionHost) validatePending() []NetCommand {
+	if s.validator == nil {
+		return s.pending
+	}
+	valid := make([]NetCommand, 0, len(s.pending))
+	for _, command := range s.pending {
+		if command.PlayerID == s.launch.LocalPlayerID || s.validator.accept(s.tick, command) {
+			valid = append(valid, command)
+		}
+	}
+	return valid
+}
+
+// Checkpoint records the world state after the current tick; rejoining guests
+// restore it and replay only later ticks. Call it between AdvanceTick and the next one.
+func (s *SessionHost) Checkpoint(save *engine.SaveGame) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.checkpoint = save
+	s.checkpointTick = s.tick
+	s.backlog = nil
+}
+
+// Close ends the session and disconnects every guest
+func (s *SessionHost) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, peer := range s.peers {
+		peer.send(MsgLeave, nil)
+		peer.close()
+	}
+	s.peers = make(map[int]*peer)
+}
+
+// handleMessage processes a message from a guest's connection
+func (s *SessionHost) handleMessage(playerID int, message Message) error {
+	switch message.Type {
+	case MsgCommand:
+		var command NetCommand
+		if err := message.Decode(&command); err != nil {
+			return err
+		}
+		command.PlayerID = playerID // Guests can only command their own objects
+		command.Presence = nil      // Only the host reports connections
+		s.mutex.Lock()
+		s.submitLocked(command)
+		s.mutex.Unlock()
+		return nil
+	case MsgCaughtUp:
+		s.reportPresence(playerID, true)
+		s.setStatus(playerID, PlayerConnected)
+		return nil
+	default:
+		return fmt.Errorf("unexpected message type %s", message.Type)
+	}
+}
+
+// rejoin validates a rejoin request and sends the guest everything it needs to catch up
+func (s *SessionHost) rejoin(conn *Conn, message Message) (int, error) {
+	var request rejoinPayload
+	if err := message.Decode(&request); err != nil {
+		return 0, err
+	}
+	if request.Version != ProtocolVersion {
+		return 0, fmt.Errorf("protocol version %d required", ProtocolVersion)
+	}
+
+	s.mutex.Lock()
+	playerID, known := s.tokens[request.Token]
+	if !known {
+		s.mutex.Unlock()
+		return 0, fmt.Errorf("unknown session")
+	}
+	if s.statuses[playerID] == PlayerDropped {
+		s.mutex.Unlock()
+		return 0, fmt.Errorf("player %d was dropped from the match", playerID)
+	}
+	if old := s.peers[playerID]; old != nil {
+		old.conn.Close() // A stale connection the host has not noticed yet
+		old.close()
+	}
+
+	launch := s.launch
+	launch.LocalPlayerID = playerID
+	launch.SessionToken = request.Token
+	s.statuses[playerID] = PlayerReconnecting
+	resync := Resync{
+		Launch:       launch,
+		Snapshot:     s.checkpoint,
+		SnapshotTick: s.checkpointTick,
+		Backlog:      append([]TickCommands(nil), s.backlog...),
+		CurrentTick:  s.tick,
+		Statuses:     copyStatuses(s.statuses),
+	}
+	rejoined := newPeer(conn)
+	rejoined.send(MsgResync, resync)
+	s.peers[playerID] = rejoined
+	delete(s.disconnectedAt, playerID)
+	s.mutex.Unlock()
+
+	logging.Infof(logging.CategoryNet, "Player %d rejoined at tick %d from %s", playerID, resync.CurrentTick, conn.RemoteAddr())
+	s.notifyStatus(playerID, PlayerReconnecting)
+	return playerID, nil
+}
+
+// disconnected marks a player as reconnecting when its connection drops
+func (s *SessionHost) disconnected(playerID int, conn *Conn) {
+	conn.Close()
+
+	s.mutex.Lock()
+	peer := s.peers[playerID]
+	if peer == nil || peer.conn != conn {
+		s.mutex.Unlock()
+		return // Already replaced by a rejoin
+	}
+	peer.close()
+	delete(s.peers, playerID)
+	s.disconnectedAt[playerID] = s.now()
+	s.mutex.Unlock()
+	s.reportPresence(playerID, false)
+
+	logging.Warnf(logging.CategoryNet, "Player %d disconnected, waiting for rejoin", playerID)
+	s.setStatus(playerID, PlayerReconnecting)
+}
+
+// reportPresence queues a connection change for the next tick
+func (s *SessionHost) reportPresence(playerID int, connected bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.submitLocked(NetCommand{
+		PlayerID: s.launch.LocalPlayerID,
+		Presence: &PresenceChange{PlayerID: playerID, Connected: connected},
+	})
+}
+
+// expireDisconnected drops players whose reconnect timeout passed (lock must be held)
+func (s *SessionHost) expireDisconnected() []int {
+	var dropped []int
+	for playerID, since := range s.disconnectedAt {
+		if s.now().Sub(since) >= s.reconnectTimeout {
+			delete(s.disconnectedAt, playerID)
+			s.statuses[playerID] = PlayerDropped
+			dropped = append(dropped, playerID)
+		}
+	}
+	return dropped
+}
+
+// setStatus changes a player's status and notifies everyone if it changed
+func (s *SessionHost) setStatus(playerID int, status PlayerStatus) {
+	s.mutex.Lock()
+	changed := s.statuses[playerID] != status
+	s.statuses[playerID] = status
+	s.mutex.Unlock()
+
+	if changed {
+		s.notifyStatus(playerID, status)
+	}
+}
+
+// notifyStatus broadcasts a status change to the guests and the status handler
+func (s *SessionHost) notifyStatus(playerID int, status PlayerStatus) {
+	s.mutex.Lock()
+	for _, peer := range s.peers {
+		peer.send(MsgPlayerStatus, statusPayload{PlayerID: playerID, Status: status})
+	}
+	handler := s.onStatus
+	s.mutex.Unlock()
+
+	if handler != nil {
+		handler(playerID, status)
+	}
+}
+
+// SessionClient is a guest's side of the lockstep session
+type SessionClient struct {
+	conn     *Conn
+	ticket   SessionTicket
+	ticks    chan TickCommands
+	statuses map[int]PlayerStatus
+	onStatus func(playerID int, status PlayerStatus)
+	done     chan struct{}
+	err      error // Why the session ended (nil while connected)
+	mutex    sync.Mutex
+}
+
+// newSessionClient starts reading ticks from an established connection
+func newSessionClient(conn *Conn, ticket SessionTicket, statuses map[int]PlayerStatus) *SessionClient {
+	client := &SessionClient{
+		conn:     conn,
+		ticket:   ticket,
+		ticks:    make(chan TickCommands, 256),
+		statuses: statuses,
+		done:     make(chan struct{}),
+	}
+	go client.readLoop()
+	return client
+}
+
+// Rejoin reconnects to a running match. The returned Resync must be passed to
+// FastForward before ticks from Ticks are applied; call NotifyCaughtUp afterwards.
+func Rejoin(ticket SessionTicket, timeout time.Duration) (*SessionClient, *Resync, error) {
+	conn, err := Dial(ticket.Address, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := conn.Send(MsgRejoin, rejoinPayload{Version: ProtocolVersion, Token: ticket.Token}); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	message, err := conn.ReceiveWithin(timeout)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("no answer from host: %w", err)
+	}
+	switch message.Type {
+	case MsgResync:
+	case MsgReject:
+		var reject rejectPayload
+		message.Decode(&reject)
+		conn.Close()
+		return nil, nil, fmt.Errorf("host rejected rejoin: %s", reject.Reason)
+	default:
+		conn.Close()
+		return nil, nil, fmt.Errorf("unexpected %s message from host", message.Type)
+	}
+
+	var resync Resync
+	if err := message.Decode(&resync); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	ticket.PlayerID = resync.Launch.LocalPlayerID
+	return newSessionClient(conn, ticket, resync.Statuses), &resync, nil
+}
+
+// Ticket returns the ticket needed to rejoin this session
+func (c *SessionClient) Ticket() SessionTicket {
+	return c.ticket
+}
+
+// Ticks delivers the host's ticks in order; each must be applied with ApplyTick
+// and followed by one world update
+func (c *SessionClient) Ticks() <-chan TickCommands {
+	return c.ticks
+}
+
+// SendCommand submits a command for the next tick
+func (c *SessionClient) SendCommand(command NetCommand) error {
+	return c.conn.Send(MsgCommand, command)
+}
+
+// NotifyCaughtUp tells the host a rejoin finished fast-forwarding
+func (c *SessionClient) NotifyCaughtUp() error {
+	return c.conn.Send(MsgCaughtUp, nil)
+}
+
+// SetStatusHandler registers a function called when another player's connection status changes
+func (c *SessionClient) SetStatusHandler(handler func(playerID int, status PlayerStatus)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.onStatus = handler
+}
+
+// Statuses returns the last known connection status of every guest
+func (c *SessionClient) Statuses() map[int]PlayerStatus {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return copyStatuses(c.statuses)
+}
+
+// Done is closed when the connection to the host ends
+func (c *SessionClient) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the session ended, or nil while connected
+func (c *SessionClient) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+// Close leaves the match; the ticket stays valid until the host drops the player
+func (c *SessionClient) Close() error {
+	return c.conn.Close()
+}
+
+// readLoop delivers ticks and status changes until the connection ends
+func (c *SessionClient) readLoop() {
+	defer close(c.done)
+
+	for {
+		message, err := c.conn.Receive()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				c.setErr(fmt.Errorf("connection to host lost: %w", err))
+			}
+			return
+		}
+
+		switch message.Type {
+		case MsgTick:
+			var tick TickCommands
+			if err := message.Decode(&tick); err != nil {
+				c.setErr(err)
+				return
+			}
+			c.ticks <- tick
+		case MsgPlayerStatus:
+			var status statusPayload
+			if err := message.Decode(&status); err != nil {
+				continue
+			}
+			c.mutex.Lock()
+			c.statuses[status.PlayerID] = status.Status
+			handler := c.onStatus
+			c.mutex.Unlock()
+			if handler != nil {
+				handler(status.PlayerID, status.Status)
+			}
+		case MsgLeave:
+			c.setErr(fmt.Errorf("host ended the match"))
+			return
+		}
+	}
+}
+
+// setErr records why the session ended
+func (c *SessionClient) setErr(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.err = err
+}
+
+// newSessionToken returns a random 16-byte hex token
+func newSessionToken() string {
+	token := make([]byte, 16)
+	rand.Read(token)
+	return hex.EncodeToString(token)
+}
+
+// copyStatuses returns a copy of a status map
+func copyStatuses(statuses map[int]PlayerStatus) map[int]PlayerStatus {
+	result := make(map[int]PlayerStatus, len(statuses))
+	for playerID, status := range statuses {
+		result[playerID] = status
+	}
+	return result
+}
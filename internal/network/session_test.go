@@ -0,0 +1,335 @@
+package network
+
+import (
+	"io"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+)
+
+// launchTestSession runs a lobby with one guest up to launch and returns both session ends
+func launchTestSession(t *testing.T) (*LobbyHost, *SessionHost, *SessionClient) {
+	t.Helper()
+
+	host := newTestLobby(t)
+	guest, err := JoinLobby(host.Addr(), "guest", time.Second)
+	if err != nil {
+		t.Fatalf("JoinLobby failed: %v", err)
+	}
+	guest.SetReady(true)
+	host.SetReady(true)
+	waitFor(t, "everyone ready", func() bool { state := host.State(); return state.AllReady() })
+
+	if _, err := host.Launch(); err != nil {
+		t.Fatalf("Launch failed: %v", err)
+	}
+	var info LaunchInfo
+	select {
+	case info = <-guest.Launched():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Guest never received the launch")
+	}
+
+	client, err := guest.StartSession(info)
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return host, host.Session(), client
+}
+
+// receiveTick waits for the next tick on a client
+func receiveTick(t *testing.T, client *SessionClient) TickCommands {
+	t.Helper()
+
+	select {
+	case tick := <-client.Ticks():
+		return tick
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for a tick")
+		return TickCommands{}
+	}
+}
+
+func TestSessionRelaysCommands(t *testing.T) {
+	_, session, client := launchTestSession(t)
+
+	if ticket := client.Ticket(); ticket.PlayerID != 2 || ticket.Token == "" {
+		t.Fatalf("Unexpected session ticket %+v", ticket)
+	}
+
+	// A guest cannot command another player's objects, whatever it claims
+	if err := client.SendCommand(NetCommand{PlayerID: 1, UnitIDs: []int{7}, Type: engine.CommandStop}); err != nil {
+		t.Fatalf("SendCommand failed: %v", err)
+	}
+	session.SubmitCommand(NetCommand{UnitIDs: []int{1}, Type: engine.CommandHold})
+
+	var relayed []NetCommand
+	for tickNumber := int64(1); len(relayed) < 2 && tickNumber < 100; tickNumber++ {
+		hostTick := session.AdvanceTick()
+		guestTick := receiveTick(t, client)
+		if guestTick.Tick != tickNumber || len(guestTick.Commands) != len(hostTick.Commands) {
+			t.Fatalf("Guest tick %+v differs from host tick %+v", guestTick, hostTick)
+		}
+		relayed = append(relayed, guestTick.Commands...)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if len(relayed) != 2 {
+		t.Fatalf("Expected 2 relayed commands, got %+v", relayed)
+	}
+	for _, command := range relayed {
+		if command.Type == engine.CommandStop && command.PlayerID != 2 {
+			t.Errorf("Guest command relayed as player %d", command.PlayerID)
+		}
+		if command.Type == engine.CommandHold && command.PlayerID != 1 {
+			t.Errorf("Host command relayed as player %d", command.PlayerID)
+		}
+	}
+}
+
+func TestSessionRejoinResync(t *testing.T) {
+	_, session, client := launchTestSession(t)
+	ticket := client.Ticket()
+
+	session.AdvanceTick()
+	receiveTick(t, client)
+	client.Close()
+	waitFor(t, "guest to be reconnecting", func() bool { return session.Statuses()[2] == PlayerReconnecting })
+
+	session.AdvanceTick()
+	session.Checkpoint(&engine.SaveGame{Header: engine.SaveGameHeader{Version: engine.SaveGameVersion}})
+	session.SubmitCommand(NetCommand{UnitIDs: []int{1}, Type: engine.CommandStop})
+	session.AdvanceTick()
+	session.AdvanceTick()
+
+	rejoined, resync, err := Rejoin(ticket, time.Second)
+	if err != nil {
+		t.Fatalf("Rejoin failed: %v", err)
+	}
+	defer rejoined.Close()
+
+	if resync.Snapshot == nil || resync.SnapshotTick != 2 || resync.CurrentTick != 4 {
+		t.Errorf("Expected checkpoint at tick 2 and current tick 4, got %d and %d", resync.SnapshotTick, resync.CurrentTick)
+	}
+	if len(resync.Backlog) != 2 || resync.Backlog[0].Tick != 3 || len(resync.Backlog[0].Commands) != 1 {
+		t.Errorf("Expected ticks 3 and 4 in the backlog, got %+v", resync.Backlog)
+	}
+	if resync.Launch.LocalPlayerID != 2 || resync.Launch.Lobby.MapName != "conflict" {
+		t.Errorf("Unexpected launch info in resync: %+v", resync.Launch)
+	}
+	if session.Statuses()[2] != PlayerReconnecting {
+		t.Error("Expected guest to stay reconnecting until it caught up")
+	}
+
+	// Ticks continue where the backlog ends
+	session.AdvanceTick()
+	if tick := receiveTick(t, rejoined); tick.Tick != 5 {
+		t.Errorf("Expected tick 5 after the backlog, got %d", tick.Tick)
+	}
+
+	if err := rejoined.NotifyCaughtUp(); err != nil {
+		t.Fatalf("NotifyCaughtUp failed: %v", err)
+	}
+	waitFor(t, "guest to be connected", func() bool { return session.Statuses()[2] == PlayerConnected })
+}
+
+func TestSessionDropsPlayerAfterTimeout(t *testing.T) {
+	_, session, client := launchTestSession(t)
+	ticket := client.Ticket()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	session.mutex.Lock()
+	session.now = func() time.Time { return now }
+	session.mutex.Unlock()
+	session.SetReconnectTimeout(30 * time.Second)
+
+	var reported []PlayerStatus
+	var reportedMutex sync.Mutex
+	session.SetStatusHandler(func(playerID int, status PlayerStatus) {
+		reportedMutex.Lock()
+		defer reportedMutex.Unlock()
+		reported = append(reported, status)
+	})
+
+	client.Close()
+	waitFor(t, "guest to be reconnecting", func() bool {
+		reportedMutex.Lock()
+		defer reportedMutex.Unlock()
+		return len(reported) == 1
+	})
+
+	session.mutex.Lock()
+	now = now.Add(time.Minute)
+	session.mutex.Unlock()
+	tick := session.AdvanceTick()
+
+	// Every peer learns of the disconnect in the same tick
+	if len(tick.Commands) != 1 || tick.Commands[0].Presence == nil || *tick.Commands[0].Presence != (PresenceChange{PlayerID: 2}) {
+		t.Errorf("Expected the tick to report the disconnect, got %+v", tick.Commands)
+	}
+
+	if status := session.Statuses()[2]; status != PlayerDropped {
+		t.Fatalf("Expected guest to be dropped, got %s", status)
+	}
+	reportedMutex.Lock()
+	defer reportedMutex.Unlock()
+	if len(reported) != 2 || reported[0] != PlayerReconnecting || reported[1] != PlayerDropped {
+		t.Errorf("Unexpected reported statuses %v", reported)
+	}
+	if _, _, err := Rejoin(ticket, time.Second); err == nil {
+		t.Error("Expected a dropped player to be refused")
+	}
+}
+
+func TestSessionTicketRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	ticket := SessionTicket{Address: "127.0.0.1:61357", Token: "abc123", PlayerID: 3}
+
+	if err := SaveSessionTicket(path, ticket); err != nil {
+		t.Fatalf("SaveSessionTicket failed: %v", err)
+	}
+	loaded, err := LoadSessionTicket(path)
+	if err != nil {
+		t.Fatalf("LoadSessionTicket failed: %v", err)
+	}
+	if loaded != ticket {
+		t.Errorf("Expected %+v, got %+v", ticket, loaded)
+	}
+
+	if err := SaveSessionTicket(path, SessionTicket{PlayerID: 1}); err != nil {
+		t.Fatalf("SaveSessionTicket failed: %v", err)
+	}
+	if _, err := LoadSessionTicket(path); err == nil {
+		t.Error("Expected an incomplete ticket to be rejected")
+	}
+}
+
+func TestFastForwardMatchesHost(t *testing.T) {
+	const tickDuration = 100 * time.Millisecond
+
+	hostWorld, err := engine.NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create host world: %v", err)
+	}
+	definition := data.NewSimpleUnit("soldier", 100, 2, "leather", map[string]int{"gold": 50})
+	for i := 0; i < 3; i++ {
+		if _, err := hostWorld.ObjectManager.CreateUnit(2, "soldier", engine.Vector3{X: float64(2 + i*2), Z: 2}, definition); err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+	}
+	hostWorld.ObjectManager.RemoveUnit(1) // Leave a gap so IDs only match if restored as saved
+	checkpoint := hostWorld.CaptureSaveGame()
+
+	target := engine.Vector3{X: 20, Z: 20}
+	resync := &Resync{Snapshot: checkpoint}
+	for tickNumber := int64(1); tickNumber <= 30; tickNumber++ {
+		tick := TickCommands{Tick: tickNumber}
+		if tickNumber == 1 {
+			tick.Commands = []NetCommand{
+				{PlayerID: 2, UnitIDs: []int{2}, Type: engine.CommandMove, Target: &target},
+				{PlayerID: 1, UnitIDs: []int{3}, Type: engine.CommandMove, Target: &target}, // Not player 1's unit
+			}
+		}
+		ApplyTick(hostWorld, tick)
+		hostWorld.Update(tickDuration)
+		resync.Backlog = append(resync.Backlog, tick)
+	}
+
+	clientWorld, err := engine.NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create client world: %v", err)
+	}
+	if err := FastForward(clientWorld, resync, tickDuration); err != nil {
+		t.Fatalf("FastForward failed: %v", err)
+	}
+
+	for _, unitID := range []int{2, 3} {
+		hostUnit := hostWorld.ObjectManager.GetUnit(unitID)
+		clientUnit := clientWorld.ObjectManager.GetUnit(unitID)
+		if clientUnit == nil {
+			t.Fatalf("Unit %d missing after fast-forward", unitID)
+		}
+		if hostUnit.Position != clientUnit.Position {
+			t.Errorf("Unit %d at %+v on the client, %+v on the host", unitID, clientUnit.Position, hostUnit.Position)
+		}
+	}
+	if start := checkpoint.Units[0].Position; hostWorld.ObjectManager.GetUnit(2).Position == start {
+		t.Error("Expected the commanded unit to move")
+	}
+
+	next, err := clientWorld.ObjectManager.CreateUnit(2, "soldier", engine.Vector3{X: 10, Z: 10}, definition)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	if next.ID != 4 {
+		t.Errorf("Expected the next unit ID to continue from the host's counter, got %d", next.ID)
+	}
+}
+
+func TestSessionLatency(t *testing.T) {
+	_, session, client := launchTestSession(t)
+
+	if err := session.SetLatency(engine.LatencySettings{Jitter: -time.Millisecond}, 50*time.Millisecond); err == nil {
+		t.Error("Expected negative jitter to be rejected")
+	}
+	// 120-180ms at 50ms per tick: three or four ticks late
+	settings := engine.LatencySettings{Delay: 150 * time.Millisecond, Jitter: 30 * time.Millisecond, Seed: 5}
+	if err := session.SetLatency(settings, 50*time.Millisecond); err != nil {
+		t.Fatalf("SetLatency failed: %v", err)
+	}
+
+	session.SubmitCommand(NetCommand{UnitIDs: []int{1}, Type: engine.CommandHold})
+	session.SubmitCommand(NetCommand{UnitIDs: []int{1}, Type: engine.CommandStop})
+
+	var relayed []NetCommand
+	executedAt := int64(0)
+	for tickNumber := int64(1); tickNumber <= 6; tickNumber++ {
+		hostTick := session.AdvanceTick()
+		guestTick := receiveTick(t, client)
+		if len(guestTick.Commands) != len(hostTick.Commands) {
+			t.Fatalf("Guest tick %+v differs from host tick %+v", guestTick, hostTick)
+		}
+		if len(hostTick.Commands) > 0 && executedAt == 0 {
+			executedAt = hostTick.Tick
+		}
+		relayed = append(relayed, hostTick.Commands...)
+	}
+
+	if executedAt < 3 || executedAt > 4 {
+		t.Errorf("Expected the delayed commands in tick 3 or 4, got tick %d", executedAt)
+	}
+	if len(relayed) != 2 || relayed[0].Type != engine.CommandHold || relayed[1].Type != engine.CommandStop {
+		t.Errorf("Expected both commands in the order given, got %+v", relayed)
+	}
+}
+
+func TestSessionDisconnectsStalledGuest(t *testing.T) {
+	hostEnd, guestEnd := net.Pipe()
+	defer guestEnd.Close()
+	session := newSessionHost(LaunchInfo{LocalPlayerID: 1}, map[string]int{"token": 2}, map[int]*Conn{2: NewConn(hostEnd)})
+
+	// The guest never reads, so every write would block
+	advanced := make(chan struct{})
+	go func() {
+		for i := 0; i <= peerQueueLength+1; i++ {
+			session.AdvanceTick()
+		}
+		close(advanced)
+	}()
+	select {
+	case <-advanced:
+	case <-time.After(2 * time.Second):
+		t.Fatal("AdvanceTick waited for a guest that does not read")
+	}
+
+	guestEnd.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := guestEnd.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Expected the stalled guest disconnected, read returned %v", err)
+	}
+}
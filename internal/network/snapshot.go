@@ -0,0 +1,302 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"teraglest/internal/engine"
+)
+
+// EntityKind separates units from buildings, whose IDs come from different counters
+type EntityKind int
+
+const (
+	EntityUnit     EntityKind = iota // A GameUnit
+	EntityBuilding                   // A GameBuilding
+)
+
+// EntityKey identifies an entity in a snapshot
+type EntityKey struct {
+	Kind EntityKind `json:"kind"`
+	ID   int        `json:"id"`
+}
+
+// EntityState is what a client needs to draw an entity
+type EntityState struct {
+	Key       EntityKey      `json:"key"`
+	PlayerID  int            `json:"player_id"`
+	Type      string         `json:"type"`
+	Position  engine.Vector3 `json:"position"`
+	Rotation  float32        `json:"rotation"`
+	Health    int            `json:"health"`
+	MaxHealth int            `json:"max_health"`
+	Progress  float32        `json:"progress,omitempty"` // Build progress of buildings
+}
+
+// EntityDelta carries only the fields of an entity that changed since the base snapshot
+type EntityDelta struct {
+	Key       EntityKey       `json:"key"`
+	PlayerID  *int            `json:"player_id,omitempty"`
+	Position  *engine.Vector3 `json:"position,omitempty"`
+	Rotation  *float32        `json:"rotation,omitempty"`
+	Health    *int            `json:"health,omitempty"`
+	MaxHealth *int            `json:"max_health,omitempty"`
+	Progress  *float32        `json:"progress,omitempty"`
+}
+
+// WorldSnapshot is the visible world state at one server tick
+type WorldSnapshot struct {
+	Tick      int64
+	GameTime  time.Duration
+	Entities  map[EntityKey]EntityState
+	Resources map[int]map[string]int // Player ID -> resource amounts
+}
+
+// SnapshotDelta turns the snapshot at BaseTick into the one at Tick. A delta
+// with BaseTick 0 is a full snapshot.
+type SnapshotDelta struct {
+	BaseTick  int64                  `json:"base_tick"`
+	Tick      int64                  `json:"tick"`
+	GameTime  time.Duration          `json:"game_time"`
+	Added     []EntityState          `json:"added,omitempty"`
+	Changed   []EntityDelta          `json:"changed,omitempty"`
+	Removed   []EntityKey            `json:"removed,omitempty"`
+	Resources map[int]map[string]int `json:"resources,omitempty"` // Only players whose resources changed
+}
+
+// emptySnapshot is the base of full snapshots
+var emptySnapshot = &WorldSnapshot{Entities: map[EntityKey]EntityState{}, Resources: map[int]map[string]int{}}
+
+// CaptureSnapshot records the visible state of a world
+func CaptureSnapshot(world *engine.World, tick int64) *WorldSnapshot {
+	save := world.CaptureSaveGame()
+	snapshot := &WorldSnapshot{
+		Tick:      tick,
+		GameTime:  save.Header.GameTime,
+		Entities:  make(map[EntityKey]EntityState, len(save.Units)+len(save.Buildings)),
+		Resources: make(map[int]map[string]int, len(save.Players)),
+	}
+	for _, unit := range save.Units {
+		if unit.GarrisonedIn != 0 {
+			continue // Not visible while inside a building
+		}
+		key := EntityKey{Kind: EntityUnit, ID: unit.ID}
+		snapshot.Entities[key] = EntityState{
+			Key:       key,
+			PlayerID:  unit.PlayerID,
+			Type:      unit.UnitType,
+			Position:  unit.Position,
+			Rotation:  unit.Rotation,
+			Health:    unit.Health,
+			MaxHealth: unit.MaxHealth,
+		}
+	}
+	for _, building := range save.Buildings {
+		key := EntityKey{Kind: EntityBuilding, ID: building.ID}
+		snapshot.Entities[key] = EntityState{
+			Key:       key,
+			PlayerID:  building.PlayerID,
+			Type:      building.BuildingType,
+			Position:  building.Position,
+			Rotation:  building.Rotation,
+			Health:    building.Health,
+			MaxHealth: building.MaxHealth,
+			Progress:  building.BuildProgress,
+		}
+	}
+	for _, player := range save.Players {
+		snapshot.Resources[player.ID] = player.Resources
+	}
+	return snapshot
+}
+
+// Diff returns the delta from base to s; a nil base produces a full snapshot
+func (s *WorldSnapshot) Diff(base *WorldSnapshot) SnapshotDelta {
+	delta := SnapshotDelta{Tick: s.Tick, GameTime: s.GameTime}
+	if base == nil {
+		base = emptySnapshot
+	} else {
+		delta.BaseTick = base.Tick
+	}
+
+	for _, key := range sortedEntityKeys(s.Entities) {
+		current := s.Entities[key]
+		previous, existed := base.Entities[key]
+		if !existed || previous.Type != current.Type {
+			delta.Added = append(delta.Added, current)
+			continue
+		}
+		if change, changed := diffEntity(previous, current); changed {
+			delta.Changed = append(delta.Changed, change)
+		}
+	}
+	for _, key := range sortedEntityKeys(base.Entities) {
+		if _, exists := s.Entities[key]; !exists {
+			delta.Removed = append(delta.Removed, key)
+		}
+	}
+
+	for playerID, resources := range s.Resources {
+		if !sameResources(base.Resources[playerID], resources) {
+			if delta.Resources == nil {
+				delta.Resources = make(map[int]map[string]int)
+			}
+			delta.Resources[playerID] = resources
+		}
+	}
+	return delta
+}
+
+// Apply builds the snapshot a delta describes; base must be the snapshot at
+// delta.BaseTick (nil for a full snapshot)
+func (d SnapshotDelta) Apply(base *WorldSnapshot) (*WorldSnapshot, error) {
+	if d.BaseTick == 0 {
+		base = emptySnapshot
+	} else if base == nil || base.Tick != d.BaseTick {
+		return nil, fmt.Errorf("delta for tick %d needs base tick %d", d.Tick, d.BaseTick)
+	}
+
+	snapshot := &WorldSnapshot{
+		Tick:      d.Tick,
+		GameTime:  d.GameTime,
+		Entities:  make(map[EntityKey]EntityState, len(base.Entities)+len(d.Added)),
+		Resources: make(map[int]map[string]int, len(base.Resources)),
+	}
+	for key, entity := range base.Entities {
+		snapshot.Entities[key] = entity
+	}
+	for playerID, resources := range base.Resources {
+		snapshot.Resources[playerID] = resources
+	}
+
+	for _, key := range d.Removed {
+		delete(snapshot.Entities, key)
+	}
+	for _, entity := range d.Added {
+		snapshot.Entities[entity.Key] = entity
+	}
+	for _, change := range d.Changed {
+		entity, exists := snapshot.Entities[change.Key]
+		if !exists {
+			return nil, fmt.Errorf("delta for tick %d changes unknown entity %+v", d.Tick, change.Key)
+		}
+		snapshot.Entities[change.Key] = change.applyTo(entity)
+	}
+	for playerID, resources := range d.Resources {
+		snapshot.Resources[playerID] = resources
+	}
+	return snapshot, nil
+}
+
+// InterpolateSnapshots blends positions, rotations and health between two
+// snapshots; alpha 0 gives from, 1 gives to. Entities only in to appear as they
+// are, entities only in from are gone.
+func InterpolateSnapshots(from, to *WorldSnapshot, alpha float64) *WorldSnapshot {
+	if from == nil || alpha >= 1 {
+		return to
+	}
+	if alpha < 0 {
+		alpha = 0
+	}
+
+	result := &WorldSnapshot{
+		Tick:      to.Tick,
+		GameTime:  from.GameTime + time.Duration(float64(to.GameTime-from.GameTime)*alpha),
+		Entities:  make(map[EntityKey]EntityState, len(to.Entities)),
+		Resources: to.Resources,
+	}
+	for key, target := range to.Entities {
+		source, existed := from.Entities[key]
+		if !existed {
+			result.Entities[key] = target
+			continue
+		}
+		blended := target
+		blended.Position = engine.Vector3{
+			X: source.Position.X + (target.Position.X-source.Position.X)*alpha,
+			Y: source.Position.Y + (target.Position.Y-source.Position.Y)*alpha,
+			Z: source.Position.Z + (target.Position.Z-source.Position.Z)*alpha,
+		}
+		blended.Rotation = engine.LerpAngle(source.Rotation, target.Rotation, float32(alpha))
+		blended.Health = source.Health + int(float64(target.Health-source.Health)*alpha)
+		result.Entities[key] = blended
+	}
+	return result
+}
+
+// diffEntity returns the fields of current that differ from previous
+func diffEntity(previous, current EntityState) (EntityDelta, bool) {
+	delta := EntityDelta{Key: current.Key}
+	changed := false
+	if previous.PlayerID != current.PlayerID {
+		delta.PlayerID, changed = &current.PlayerID, true
+	}
+	if previous.Position != current.Position {
+		delta.Position, changed = &current.Position, true
+	}
+	if previous.Rotation != current.Rotation {
+		delta.Rotation, changed = &current.Rotation, true
+	}
+	if previous.Health != current.Health {
+		delta.Health, changed = &current.Health, true
+	}
+	if previous.MaxHealth != current.MaxHealth {
+		delta.MaxHealth, changed = &current.MaxHealth, true
+	}
+	if previous.Progress != current.Progress {
+		delta.Progress, changed = &current.Progress, true
+	}
+	return delta, changed
+}
+
+// applyTo returns entity with the delta's fields applied
+func (d EntityDelta) applyTo(entity EntityState) EntityState {
+	if d.PlayerID != nil {
+		entity.PlayerID = *d.PlayerID
+	}
+	if d.Position != nil {
+		entity.Position = *d.Position
+	}
+	if d.Rotation != nil {
+		entity.Rotation = *d.Rotation
+	}
+	if d.Health != nil {
+		entity.Health = *d.Health
+	}
+	if d.MaxHealth != nil {
+		entity.MaxHealth = *d.MaxHealth
+	}
+	if d.Progress != nil {
+		entity.Progress = *d.Progress
+	}
+	return entity
+}
+
+// sameResources reports whether two resource maps hold the same amounts
+func sameResources(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for resource, amount := range a {
+		if other, exists := b[resource]; !exists || other != amount {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedEntityKeys returns the keys of an entity map in a stable order
+func sortedEntityKeys(entities map[EntityKey]EntityState) []EntityKey {
+	keys := make([]EntityKey, 0, len(entities))
+	for key := range entities {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Kind != keys[j].Kind {
+			return keys[i].Kind < keys[j].Kind
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
@@ -0,0 +1,167 @@
+package network
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+)
+
+// testSnapshot builds a snapshot with one unit, one building and some gold
+func testSnapshot(tick int64, unitX float64, gold int) *WorldSnapshot {
+	unit := EntityKey{Kind: EntityUnit, ID: 1}
+	building := EntityKey{Kind: EntityBuilding, ID: 1}
+	return &WorldSnapshot{
+		Tick:     tick,
+		GameTime: time.Duration(tick) * 100 * time.Millisecond,
+		Entities: map[EntityKey]EntityState{
+			unit:     {Key: unit, PlayerID: 1, Type: "soldier", Position: engine.Vector3{X: unitX}, Health: 100, MaxHealth: 100},
+			building: {Key: building, PlayerID: 1, Type: "castle", Health: 500, MaxHealth: 500, Progress: 1},
+		},
+		Resources: map[int]map[string]int{1: {"gold": gold}, 2: {"gold": 100}},
+	}
+}
+
+func TestSnapshotDeltaRoundTrip(t *testing.T) {
+	base := testSnapshot(1, 0, 100)
+	next := testSnapshot(2, 5, 80)
+	archer := EntityKey{Kind: EntityUnit, ID: 2}
+	next.Entities[archer] = EntityState{Key: archer, PlayerID: 2, Type: "archer", Health: 60, MaxHealth: 60}
+	delete(next.Entities, EntityKey{Kind: EntityBuilding, ID: 1})
+
+	delta := next.Diff(base)
+	if delta.BaseTick != 1 || len(delta.Added) != 1 || len(delta.Removed) != 1 || len(delta.Changed) != 1 {
+		t.Fatalf("Unexpected delta %+v", delta)
+	}
+	change := delta.Changed[0]
+	if change.Position == nil || change.Health != nil || change.PlayerID != nil {
+		t.Errorf("Expected only the position in the entity delta, got %+v", change)
+	}
+	if len(delta.Resources) != 1 || delta.Resources[1]["gold"] != 80 {
+		t.Errorf("Expected only player 1's resources in the delta, got %v", delta.Resources)
+	}
+
+	rebuilt, err := delta.Apply(base)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !reflect.DeepEqual(rebuilt, next) {
+		t.Errorf("Rebuilt snapshot differs:\n got %+v\nwant %+v", rebuilt, next)
+	}
+
+	if _, err := delta.Apply(testSnapshot(3, 0, 100)); err == nil {
+		t.Error("Expected a delta applied to the wrong base to fail")
+	}
+
+	full := next.Diff(nil)
+	if full.BaseTick != 0 || len(full.Added) != 2 {
+		t.Fatalf("Expected a full snapshot with 2 entities, got %+v", full)
+	}
+	if rebuilt, err := full.Apply(nil); err != nil || !reflect.DeepEqual(rebuilt, next) {
+		t.Errorf("Full snapshot did not rebuild: %v", err)
+	}
+}
+
+func TestInterpolateSnapshots(t *testing.T) {
+	from := testSnapshot(1, 0, 100)
+	to := testSnapshot(2, 10, 100)
+
+	halfway := InterpolateSnapshots(from, to, 0.5)
+	unit := halfway.Entities[EntityKey{Kind: EntityUnit, ID: 1}]
+	if unit.Position.X != 5 {
+		t.Errorf("Expected unit halfway at X=5, got %v", unit.Position.X)
+	}
+	if halfway.GameTime != 150*time.Millisecond {
+		t.Errorf("Expected interpolated game time 150ms, got %v", halfway.GameTime)
+	}
+
+	// Rotation turns the short way across ±π instead of spinning through 0
+	key := EntityKey{Kind: EntityUnit, ID: 1}
+	turnFrom, turnTo := from.Entities[key], to.Entities[key]
+	turnFrom.Rotation, turnTo.Rotation = 3, -3
+	from.Entities[key], to.Entities[key] = turnFrom, turnTo
+	if rotation := InterpolateSnapshots(from, to, 0.5).Entities[key].Rotation; math.Abs(float64(rotation)-math.Pi) > 0.01 {
+		t.Errorf("Expected the rotation halfway at π, got %v", rotation)
+	}
+	if InterpolateSnapshots(from, to, 2) != to {
+		t.Error("Expected alpha past 1 to give the target snapshot")
+	}
+	if InterpolateSnapshots(nil, to, 0.5) != to {
+		t.Error("Expected a missing source to give the target snapshot")
+	}
+}
+
+func TestAuthoritativeServerStreamsSnapshots(t *testing.T) {
+	world, err := engine.NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	definition := data.NewSimpleUnit("soldier", 100, 2, "leather", map[string]int{"gold": 50})
+	soldier, err := world.ObjectManager.CreateUnit(2, "soldier", engine.Vector3{X: 2, Z: 2}, definition)
+	if err != nil {
+		t.Fatalf("Failed to create unit: %v", err)
+	}
+	start := soldier.Position
+
+	server := NewAuthoritativeServer(world, 100*time.Millisecond, []int{2})
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer server.Close()
+
+	client, err := ConnectAuthoritative(server.Addr(), "coop", time.Second)
+	if err != nil {
+		t.Fatalf("ConnectAuthoritative failed: %v", err)
+	}
+	defer client.Close()
+	if client.PlayerID() != 2 {
+		t.Errorf("Expected player 2, got %d", client.PlayerID())
+	}
+	if _, err := ConnectAuthoritative(server.Addr(), "extra", time.Second); err == nil {
+		t.Error("Expected a second client to be rejected when no player is free")
+	}
+	waitFor(t, "client to register", func() bool { return server.ClientCount() == 1 })
+
+	server.Step()
+	waitFor(t, "first snapshot", func() bool { latest := client.Latest(); return latest != nil && latest.Tick == 1 })
+	key := EntityKey{Kind: EntityUnit, ID: soldier.ID}
+	if entity, exists := client.Latest().Entities[key]; !exists || entity.Position != start {
+		t.Fatalf("Expected soldier at %+v in the first snapshot, got %+v", start, entity)
+	}
+
+	// Commands only reach the server's simulation; the client sees their effect in snapshots
+	target := engine.Vector3{X: 20, Z: 20}
+	if err := client.SendCommand(NetCommand{UnitIDs: []int{soldier.ID}, Type: engine.CommandMove, Target: &target}); err != nil {
+		t.Fatalf("SendCommand failed: %v", err)
+	}
+	for tick := int64(2); tick <= 30; tick++ {
+		time.Sleep(2 * time.Millisecond)
+		server.Step()
+		waitFor(t, "next snapshot", func() bool { return client.Latest().Tick == tick })
+	}
+
+	latest := client.Latest()
+	if latest.Entities[key].Position == start {
+		t.Error("Expected the commanded soldier to have moved")
+	}
+	server.mutex.Lock()
+	for _, c := range server.clients {
+		if c.acked < 2 {
+			t.Errorf("Expected the client to acknowledge snapshots, last ack %d", c.acked)
+		}
+	}
+	server.mutex.Unlock()
+
+	// Rendering blends the last two snapshots
+	client.mutex.Lock()
+	latestAt := client.latestAt
+	previous := client.previous.Entities[key].Position
+	client.mutex.Unlock()
+	view := client.View(latestAt.Add(50 * time.Millisecond))
+	if got := view.Entities[key].Position; got == latest.Entities[key].Position && previous != got {
+		t.Errorf("Expected an interpolated position between %+v and %+v, got %+v", previous, latest.Entities[key].Position, got)
+	}
+}
@@ -0,0 +1,287 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+const (
+	// DefaultCommandRate is how many commands a player may issue per second of game time
+	DefaultCommandRate = 20
+	// DefaultSightRange is the sight in tiles of objects whose definition sets none
+	DefaultSightRange = engine.DefaultSightRange
+)
+
+// trustedParameters are command parameters only the local game may set; a
+// remote player could use them to override costs and production times
+var trustedParameters = []string{"cost", "duration"}
+
+// CommandValidator checks commands from remote players against the host's
+// world before they are executed, so a modified client cannot command objects
+// it does not own, target what it cannot see, spend resources it does not have,
+// skip cooldowns or flood the host with commands. Validate runs on the game loop, between ticks.
+type CommandValidator struct {
+	world     *engine.World
+	resources *engine.ResourceValidator
+	rate      int                     // Commands per second of game time (0 = unlimited)
+	issued    map[int][]time.Duration // Player ID -> game times of commands accepted in the last second
+	rejected  map[int]int             // Player ID -> number of commands rejected
+	mutex     sync.Mutex
+}
+
+// NewCommandValidator creates a validator for commands executed in world
+func NewCommandValidator(world *engine.World) *CommandValidator {
+	return &CommandValidator{
+		world:     world,
+		resources: engine.NewResourceValidator(world),
+		rate:      DefaultCommandRate,
+		issued:    make(map[int][]time.Duration),
+		rejected:  make(map[int]int),
+	}
+}
+
+// SetCommandRate changes how many commands a player may issue per second of game time
+func (v *CommandValidator) SetCommandRate(perSecond int) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.rate = perSecond
+}
+
+// Rejected returns how many commands of a player have been rejected
+func (v *CommandValidator) Rejected(playerID int) int {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	return v.rejected[playerID]
+}
+
+// Validate returns why a command must not be executed, or nil if it may
+func (v *CommandValidator) Validate(command NetCommand) error {
+	if command.Tribute != nil {
+		if err := v.checkTribute(command); err != nil {
+			return err
+		}
+		return v.checkRate(command.PlayerID)
+	}
+	if command.Chat != "" {
+		if _, _, ok := engine.ParseChatCommand(command.Chat); !ok {
+			return fmt.Errorf("chat line %q is not a command", command.Chat)
+		}
+		return v.checkRate(command.PlayerID)
+	}
+	if err := v.checkOwnership(command); err != nil {
+		return err
+	}
+	if err := v.checkParameters(command); err != nil {
+		return err
+	}
+	if err := v.checkVisibility(command); err != nil {
+		return err
+	}
+	if err := v.checkResources(command); err != nil {
+		return err
+	}
+	if err := v.checkCooldowns(command); err != nil {
+		return err
+	}
+	return v.checkRate(command.PlayerID)
+}
+
+// Filter returns the commands that pass validation, logging every rejected one
+func (v *CommandValidator) Filter(tick int64, commands []NetCommand) []NetCommand {
+	valid := make([]NetCommand, 0, len(commands))
+	for _, command := range commands {
+		if v.accept(tick, command) {
+			valid = append(valid, command)
+		}
+	}
+	return valid
+}
+
+// accept validates one command, counting and logging it if rejected
+func (v *CommandValidator) accept(tick int64, command NetCommand) bool {
+	err := v.Validate(command)
+	if err == nil {
+		return true
+	}
+	v.mutex.Lock()
+	v.rejected[command.PlayerID]++
+	v.mutex.Unlock()
+	logging.Warnf(logging.CategoryNet, "Tick %d: rejected %s command from player %d: %v", tick, command.Type, command.PlayerID, err)
+	return false
+}
+
+// checkOwnership rejects commands for objects the player may not command:
+// only its own, or those of a faction it shares
+func (v *CommandValidator) checkOwnership(command NetCommand) error {
+	if command.BuildingID == 0 && len(command.UnitIDs) == 0 {
+		return fmt.Errorf("command has no units or building")
+	}
+	if command.BuildingID != 0 {
+		building := v.world.ObjectManager.GetBuilding(command.BuildingID)
+		if building == nil {
+			return fmt.Errorf("building %d does not exist", command.BuildingID)
+		}
+		if !v.world.CanCommand(command.PlayerID, building.PlayerID) {
+			return fmt.Errorf("building %d belongs to player %d", building.ID, building.PlayerID)
+		}
+	}
+	for _, unitID := range command.UnitIDs {
+		unit := v.world.ObjectManager.GetUnit(unitID)
+		if unit == nil {
+			return fmt.Errorf("unit %d does not exist", unitID)
+		}
+		if !v.world.CanCommand(command.PlayerID, unit.PlayerID) {
+			return fmt.Errorf("unit %d belongs to player %d", unit.ID, unit.PlayerID)
+		}
+	}
+	return nil
+}
+
+// checkTribute rejects tributes to non-allies and tributes the player cannot afford
+func (v *CommandValidator) checkTribute(command NetCommand) error {
+	tribute := command.Tribute
+	if command.BuildingID != 0 || len(command.UnitIDs) > 0 {
+		return fmt.Errorf("tribute may not carry units or a building")
+	}
+	fromPlayerID := v.world.CommandedPlayer(command.PlayerID)
+	if !v.world.AreAllied(fromPlayerID, tribute.ToPlayerID) {
+		return fmt.Errorf("player %d is not an ally", tribute.ToPlayerID)
+	}
+	if len(tribute.Resources) == 0 {
+		return fmt.Errorf("tribute has no resources")
+	}
+	for resource, amount := range tribute.Resources {
+		if amount <= 0 {
+			return fmt.Errorf("tribute of %s must be positive, got %d", resource, amount)
+		}
+	}
+	result := v.resources.ValidateResources(engine.ResourceCheck{
+		PlayerID: fromPlayerID,
+		Required: tribute.Resources,
+		Purpose:  "tribute",
+	})
+	if !result.Valid {
+		return fmt.Errorf("%s", result.Error)
+	}
+	return nil
+}
+
+// checkParameters rejects parameters that override values the host decides
+func (v *CommandValidator) checkParameters(command NetCommand) error {
+	for _, name := range trustedParameters {
+		if _, exists := command.Parameters[name]; exists {
+			return fmt.Errorf("parameter %q may not be set remotely", name)
+		}
+	}
+	return nil
+}
+
+// checkVisibility rejects targets the player cannot see. Positions only need
+// to be on the map, except build sites, which must be in sight. Players
+// sharing a faction see what it sees.
+func (v *CommandValidator) checkVisibility(command NetCommand) error {
+	playerID := v.world.CommandedPlayer(command.PlayerID)
+	if command.Target != nil {
+		grid := v.world.WorldToGrid(*command.Target).Grid
+		if grid.X < 0 || grid.Y < 0 || grid.X >= v.world.Width || grid.Y >= v.world.Height {
+			return fmt.Errorf("target %+v is off the map", *command.Target)
+		}
+		if command.Type == engine.CommandBuild && !v.world.CanSee(playerID, *command.Target) {
+			return fmt.Errorf("build site %+v is not in sight", *command.Target)
+		}
+	}
+	if command.TargetUnitID != 0 {
+		target := v.world.ObjectManager.GetUnit(command.TargetUnitID)
+		if target == nil {
+			return fmt.Errorf("target unit %d does not exist", command.TargetUnitID)
+		}
+		if target.PlayerID != playerID && !v.world.CanSee(playerID, target.Position) {
+			return fmt.Errorf("target unit %d is not in sight", target.ID)
+		}
+	}
+	if command.TargetBuildingID != 0 {
+		target := v.world.ObjectManager.GetBuilding(command.TargetBuildingID)
+		if target == nil {
+			return fmt.Errorf("target building %d does not exist", command.TargetBuildingID)
+		}
+		if target.PlayerID != playerID && !v.world.CanSee(playerID, target.Position) {
+			return fmt.Errorf("target building %d is not in sight", target.ID)
+		}
+	}
+	return nil
+}
+
+// checkResources rejects production and construction the player's faction
+// cannot afford
+func (v *CommandValidator) checkResources(command NetCommand) error {
+	playerID := v.world.CommandedPlayer(command.PlayerID)
+	var result engine.ValidationResult
+	switch command.Type {
+	case engine.CommandProduce:
+		unitType, _ := command.Parameters["unit_type"].(string)
+		result = v.resources.ValidateUnitCost(playerID, unitType)
+	case engine.CommandBuild:
+		buildingType, _ := command.Parameters["building_type"].(string)
+		result = v.resources.ValidateBuildingCost(playerID, buildingType)
+	default:
+		return nil
+	}
+	// Types without a known cost are left to the command processor
+	if !result.Valid && len(result.Missing) > 0 {
+		return fmt.Errorf("%s", result.Error)
+	}
+	return nil
+}
+
+// checkCooldowns rejects attacks by units whose attack has not recovered, and
+// production and upgrades in buildings that are unfinished or already
+// upgrading
+func (v *CommandValidator) checkCooldowns(command NetCommand) error {
+	switch command.Type {
+	case engine.CommandAttack, engine.CommandGroupAttack:
+		for _, unitID := range command.UnitIDs {
+			if unit := v.world.ObjectManager.GetUnit(unitID); unit != nil && !v.world.CanAttackNow(unit) {
+				return fmt.Errorf("unit %d is on attack cooldown", unit.ID)
+			}
+		}
+	case engine.CommandProduce, engine.CommandUpgrade:
+		building := v.world.ObjectManager.GetBuilding(command.BuildingID)
+		if building == nil {
+			return nil
+		}
+		if !building.IsBuilt {
+			return fmt.Errorf("building %d is not finished", building.ID)
+		}
+		if command.Type == engine.CommandUpgrade && building.Production().Upgrade != "" {
+			return fmt.Errorf("building %d is already upgrading", building.ID)
+		}
+	}
+	return nil
+}
+
+// checkRate counts a command against the player's rate limit
+func (v *CommandValidator) checkRate(playerID int) error {
+	now := v.world.GetGameTime()
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.rate <= 0 {
+		return nil
+	}
+	recent := v.issued[playerID][:0]
+	for _, issuedAt := range v.issued[playerID] {
+		if now-issuedAt < time.Second {
+			recent = append(recent, issuedAt)
+		}
+	}
+	if len(recent) >= v.rate {
+		v.issued[playerID] = recent
+		return fmt.Errorf("more than %d commands per second", v.rate)
+	}
+	v.issued[playerID] = append(recent, now)
+	return nil
+}
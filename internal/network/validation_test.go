@@ -0,0 +1,242 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+)
+
+// newValidationWorld creates a world where player 1 has a soldier at the map
+// corner and player 2 has one soldier nearby and one across the map
+func newValidationWorld(t *testing.T) (*engine.World, *engine.GameUnit, *engine.GameUnit, *engine.GameUnit) {
+	t.Helper()
+
+	world, err := engine.NewHeadlessWorld(64, 64)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	definition := data.NewSimpleUnit("soldier", 100, 2, "leather", map[string]int{"gold": 50})
+	create := func(playerID int, x, z float64) *engine.GameUnit {
+		unit, err := world.ObjectManager.CreateUnit(playerID, "soldier", engine.Vector3{X: x, Z: z}, definition)
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		return unit
+	}
+	return world, create(1, 2, 2), create(2, 6, 6), create(2, 60, 60)
+}
+
+func TestCommandValidatorRejectsCheats(t *testing.T) {
+	world, own, nearby, hidden := newValidationWorld(t)
+	validator := NewCommandValidator(world)
+	offMap := engine.Vector3{X: 100, Z: 5}
+	site := engine.Vector3{X: 50, Z: 50}
+
+	tests := []struct {
+		name    string
+		command NetCommand
+		valid   bool
+	}{
+		{"own unit", NetCommand{PlayerID: 1, UnitIDs: []int{own.ID}, Type: engine.CommandStop}, true},
+		{"visible target", NetCommand{PlayerID: 1, UnitIDs: []int{own.ID}, Type: engine.CommandAttack, TargetUnitID: nearby.ID}, true},
+		{"foreign unit", NetCommand{PlayerID: 1, UnitIDs: []int{nearby.ID}, Type: engine.CommandStop}, false},
+		{"missing unit", NetCommand{PlayerID: 1, UnitIDs: []int{999}, Type: engine.CommandStop}, false},
+		{"no objects", NetCommand{PlayerID: 1, Type: engine.CommandStop}, false},
+		{"hidden target", NetCommand{PlayerID: 1, UnitIDs: []int{own.ID}, Type: engine.CommandAttack, TargetUnitID: hidden.ID}, false},
+		{"off the map", NetCommand{PlayerID: 1, UnitIDs: []int{own.ID}, Type: engine.CommandMove, Target: &offMap}, false},
+		{"unseen build site", NetCommand{PlayerID: 1, UnitIDs: []int{own.ID}, Type: engine.CommandBuild, Target: &site}, false},
+		{"cost override", NetCommand{PlayerID: 1, UnitIDs: []int{own.ID}, Type: engine.CommandProduce, Parameters: map[string]interface{}{"cost": 0}}, false},
+	}
+	for _, test := range tests {
+		err := validator.Validate(test.command)
+		if test.valid && err != nil {
+			t.Errorf("%s: expected the command to pass, got %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected the command to be rejected", test.name)
+		}
+	}
+
+	// Moves into unexplored land are fine; only build sites must be in sight
+	if err := validator.Validate(NetCommand{PlayerID: 1, UnitIDs: []int{own.ID}, Type: engine.CommandMove, Target: &site}); err != nil {
+		t.Errorf("Expected a move into the unknown to pass, got %v", err)
+	}
+}
+
+func TestCommandValidatorRejectsCooldowns(t *testing.T) {
+	world, own, nearby, _ := newValidationWorld(t)
+	validator := NewCommandValidator(world)
+	clock := engine.NewManualClock(time.Unix(1000, 0))
+	world.SetClock(clock)
+
+	// The soldier attacked just now and attacks once a second
+	own.AttackSpeed = 1
+	own.LastAttack = clock.Now()
+	attack := NetCommand{PlayerID: 1, UnitIDs: []int{own.ID}, Type: engine.CommandAttack, TargetUnitID: nearby.ID}
+	if err := validator.Validate(attack); err == nil {
+		t.Error("Expected an attack on cooldown to be rejected")
+	}
+	clock.Advance(time.Second)
+	if err := validator.Validate(attack); err != nil {
+		t.Errorf("Expected the attack to pass once recovered, got %v", err)
+	}
+
+	barracks, err := world.ObjectManager.CreateBuilding(1, "barracks", engine.Vector3{X: 4, Z: 4}, data.NewSimpleUnit("barracks", 1000, 0, "stone", nil))
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	produce := NetCommand{PlayerID: 1, BuildingID: barracks.ID, Type: engine.CommandProduce, Parameters: map[string]interface{}{"unit_type": "soldier"}}
+	upgrade := NetCommand{PlayerID: 1, BuildingID: barracks.ID, Type: engine.CommandUpgrade}
+	barracks.IsBuilt = false
+	if err := validator.Validate(produce); err == nil {
+		t.Error("Expected production in an unfinished building to be rejected")
+	}
+	barracks.IsBuilt = true
+	if err := validator.Validate(produce); err != nil {
+		t.Errorf("Expected production in a finished building to pass, got %v", err)
+	}
+	barracks.CurrentUpgrade = &engine.UpgradeItem{UpgradeName: "Level 2 Upgrade"}
+	if err := validator.Validate(upgrade); err == nil {
+		t.Error("Expected an upgrade while upgrading to be rejected")
+	}
+}
+
+func TestCommandValidatorRateLimit(t *testing.T) {
+	world, own, _, _ := newValidationWorld(t)
+	validator := NewCommandValidator(world)
+	validator.SetCommandRate(3)
+
+	stop := NetCommand{PlayerID: 1, UnitIDs: []int{own.ID}, Type: engine.CommandStop}
+	commands := []NetCommand{stop, stop, stop, stop, stop}
+	if valid := validator.Filter(1, commands); len(valid) != 3 {
+		t.Fatalf("Expected 3 commands within the rate limit, got %d", len(valid))
+	}
+	if validator.Rejected(1) != 2 {
+		t.Errorf("Expected 2 rejected commands, got %d", validator.Rejected(1))
+	}
+
+	// The limit applies per second of game time
+	world.Update(time.Second)
+	if valid := validator.Filter(2, []NetCommand{stop}); len(valid) != 1 {
+		t.Error("Expected the rate limit to recover after a second")
+	}
+}
+
+func TestTributeCommand(t *testing.T) {
+	world, _, _, _ := newValidationWorld(t)
+	validator := NewCommandValidator(world)
+	tribute := NetCommand{PlayerID: 1, Tribute: &TributeOrder{ToPlayerID: 2, Resources: map[string]int{"gold": 100}}}
+
+	if err := validator.Validate(tribute); err == nil {
+		t.Error("Expected tribute to an enemy to be rejected")
+	}
+
+	world.GetPlayer(1).Team = 1
+	world.GetPlayer(2).Team = 1
+	if err := validator.Validate(tribute); err != nil {
+		t.Fatalf("Expected tribute to an ally to pass, got %v", err)
+	}
+	expensive := NetCommand{PlayerID: 1, Tribute: &TributeOrder{ToPlayerID: 2, Resources: map[string]int{"gold": 5000}}}
+	if err := validator.Validate(expensive); err == nil {
+		t.Error("Expected an unaffordable tribute to be rejected")
+	}
+
+	before := world.GetResourceStatus(2).Resources["gold"]
+	ApplyTick(world, TickCommands{Tick: 1, Commands: []NetCommand{tribute}})
+	if gold := world.GetResourceStatus(2).Resources["gold"]; gold != before+100 {
+		t.Errorf("Expected the ally to receive 100 gold, has %d (had %d)", gold, before)
+	}
+}
+
+func TestChatSurrenderCommand(t *testing.T) {
+	world, _, _, _ := newValidationWorld(t)
+	validator := NewCommandValidator(world)
+
+	if err := validator.Validate(NetCommand{PlayerID: 2, Chat: "well played"}); err == nil {
+		t.Error("Expected an ordinary chat line to be rejected as a command")
+	}
+	surrender := NetCommand{PlayerID: 2, Chat: "/surrender"}
+	if err := validator.Validate(surrender); err != nil {
+		t.Fatalf("Expected the surrender command to pass, got %v", err)
+	}
+	ApplyTick(world, TickCommands{Tick: 1, Commands: []NetCommand{surrender}})
+	if world.GetPlayer(2).IsActive {
+		t.Error("Expected player 2 to have surrendered")
+	}
+	if winners, over := world.MatchOver(); !over || len(winners) != 1 || winners[0] != 1 {
+		t.Errorf("Expected player 1 to win, got %v", winners)
+	}
+}
+
+func TestAuthoritativeServerRejectsForeignCommands(t *testing.T) {
+	world, own, _, _ := newValidationWorld(t)
+	start := own.Position
+
+	server := NewAuthoritativeServer(world, 100*time.Millisecond, []int{2})
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer server.Close()
+
+	client, err := ConnectAuthoritative(server.Addr(), "cheater", time.Second)
+	if err != nil {
+		t.Fatalf("ConnectAuthoritative failed: %v", err)
+	}
+	defer client.Close()
+	waitFor(t, "client to register", func() bool { return server.ClientCount() == 1 })
+
+	// Player 2 tries to march player 1's soldier away
+	target := engine.Vector3{X: 30, Z: 30}
+	if err := client.SendCommand(NetCommand{UnitIDs: []int{own.ID}, Type: engine.CommandMove, Target: &target}); err != nil {
+		t.Fatalf("SendCommand failed: %v", err)
+	}
+	waitFor(t, "command to arrive", func() bool {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		return len(server.pending) == 1
+	})
+	for i := 0; i < 10; i++ {
+		server.Step()
+	}
+
+	if server.Validator().Rejected(2) != 1 {
+		t.Errorf("Expected the command to be rejected, got %d rejections", server.Validator().Rejected(2))
+	}
+	if own.Position != start {
+		t.Errorf("Expected the soldier to stay at %+v, got %+v", start, own.Position)
+	}
+}
+
+func TestSessionValidatesGuestCommands(t *testing.T) {
+	world, own, nearby, _ := newValidationWorld(t)
+	_, session, client := launchTestSession(t)
+	validator := NewCommandValidator(world)
+	session.SetValidator(validator)
+
+	// The guest plays player 2 and cannot command player 1's soldier
+	if err := client.SendCommand(NetCommand{UnitIDs: []int{own.ID}, Type: engine.CommandStop}); err != nil {
+		t.Fatalf("SendCommand failed: %v", err)
+	}
+	if err := client.SendCommand(NetCommand{UnitIDs: []int{nearby.ID}, Type: engine.CommandStop}); err != nil {
+		t.Fatalf("SendCommand failed: %v", err)
+	}
+	waitFor(t, "commands to arrive", func() bool {
+		session.mutex.Lock()
+		defer session.mutex.Unlock()
+		return len(session.pending) == 2
+	})
+	session.SubmitCommand(NetCommand{Type: engine.CommandStop}) // The host's own commands are trusted
+
+	tick := session.AdvanceTick()
+	if len(tick.Commands) != 2 || tick.Commands[0].UnitIDs[0] != nearby.ID {
+		t.Errorf("Expected the guest's own command and the host's, got %+v", tick.Commands)
+	}
+	if validator.Rejected(2) != 1 {
+		t.Errorf("Expected 1 rejected guest command, got %d", validator.Rejected(2))
+	}
+	if relayed := receiveTick(t, client); len(relayed.Commands) != 2 {
+		t.Errorf("Expected the guest to receive the validated tick, got %+v", relayed)
+	}
+}
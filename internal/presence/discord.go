@@ -0,0 +1,218 @@
+package presence
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Discord IPC opcodes
+const (
+	discordHandshake = 0
+	discordFrame     = 1
+	discordClose     = 2
+)
+
+// discordRetryInterval is how long the provider waits before dialing a
+// Discord client that was not running again
+const discordRetryInterval = 30 * time.Second
+
+// discordTimeout bounds the wait for Discord to answer a request
+const discordTimeout = 5 * time.Second
+
+// maxDiscordFrame bounds the size of a frame read from Discord
+const maxDiscordFrame = 64 * 1024
+
+// DiscordProvider shows the activity as Discord Rich Presence through the
+// local Discord client's IPC socket. Nothing is shown, and nothing fails,
+// while Discord is not running; the provider reconnects when it starts.
+type DiscordProvider struct {
+	clientID   string // Application ID from the Discord developer portal
+	largeImage string // Art asset key of the application ("" for none)
+
+	dial     func() (io.ReadWriteCloser, error)
+	conn     io.ReadWriteCloser
+	nextDial time.Time
+	nonce    int
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewDiscordProvider creates a provider for a Discord application. largeImage
+// names an art asset uploaded to the application, shown next to the presence.
+func NewDiscordProvider(clientID, largeImage string) *DiscordProvider {
+	return &DiscordProvider{clientID: clientID, largeImage: largeImage, dial: dialDiscord}
+}
+
+// Name returns the provider name
+func (d *DiscordProvider) Name() string {
+	return "Discord"
+}
+
+// SetActivity shows an activity, connecting to Discord first when needed
+func (d *DiscordProvider) SetActivity(activity Activity) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.conn == nil {
+		if time.Now().Before(d.nextDial) {
+			return nil // Not running at the last attempt
+		}
+		if err := d.connectLocked(); err != nil {
+			d.nextDial = time.Now().Add(discordRetryInterval)
+			return err
+		}
+	}
+
+	d.nonce++
+	command := map[string]interface{}{
+		"cmd":   "SET_ACTIVITY",
+		"nonce": strconv.Itoa(d.nonce),
+		"args": map[string]interface{}{
+			"pid":      os.Getpid(),
+			"activity": d.discordActivity(activity),
+		},
+	}
+	if err := d.requestLocked(command); err != nil {
+		d.disconnectLocked()
+		return err
+	}
+	return nil
+}
+
+// Close clears the presence and disconnects from Discord
+func (d *DiscordProvider) Close() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.conn == nil {
+		return nil
+	}
+	writeDiscordFrame(d.conn, discordClose, map[string]interface{}{})
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
+
+// discordActivity converts an activity to Discord's activity object; the
+// menus show no match details
+func (d *DiscordProvider) discordActivity(activity Activity) map[string]interface{} {
+	result := map[string]interface{}{"state": string(activity.Phase)}
+	if activity.InMatch() {
+		result["details"] = fmt.Sprintf("%s on %s", activity.Faction, activity.Map)
+		if !activity.Started.IsZero() {
+			// Discord counts the elapsed time up from the start itself
+			result["timestamps"] = map[string]int64{"start": activity.Started.Unix()}
+		}
+	}
+	if d.largeImage != "" {
+		result["assets"] = map[string]string{"large_image": d.largeImage, "large_text": "TeraGlest"}
+	}
+	return result
+}
+
+// connectLocked dials Discord and completes the handshake (lock must be held)
+func (d *DiscordProvider) connectLocked() error {
+	conn, err := d.dial()
+	if err != nil {
+		return err
+	}
+	d.conn = conn
+	d.setDeadlineLocked()
+	if err := writeDiscordFrame(conn, discordHandshake, map[string]interface{}{"v": 1, "client_id": d.clientID}); err != nil {
+		d.disconnectLocked()
+		return err
+	}
+	if _, err := d.readResponseLocked(); err != nil {
+		d.disconnectLocked()
+		return fmt.Errorf("discord handshake failed: %w", err)
+	}
+	return nil
+}
+
+// requestLocked sends a command and waits for its response (lock must be held)
+func (d *DiscordProvider) requestLocked(command map[string]interface{}) error {
+	d.setDeadlineLocked()
+	if err := writeDiscordFrame(d.conn, discordFrame, command); err != nil {
+		return err
+	}
+	response, err := d.readResponseLocked()
+	if err != nil {
+		return err
+	}
+	if response.Event == "ERROR" {
+		return fmt.Errorf("discord rejected %s: %s", command["cmd"], response.Data.Message)
+	}
+	return nil
+}
+
+// discordResponse is the part of a Discord reply the provider reads
+type discordResponse struct {
+	Command string `json:"cmd"`
+	Event   string `json:"evt"`
+	Data    struct {
+		Message string `json:"message"`
+	} `json:"data"`
+}
+
+// readResponseLocked reads one frame from Discord (lock must be held)
+func (d *DiscordProvider) readResponseLocked() (discordResponse, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(d.conn, header[:]); err != nil {
+		return discordResponse{}, err
+	}
+	opcode := binary.LittleEndian.Uint32(header[:4])
+	length := binary.LittleEndian.Uint32(header[4:])
+	if length > maxDiscordFrame {
+		return discordResponse{}, fmt.Errorf("discord frame of %d bytes is too large", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(d.conn, payload); err != nil {
+		return discordResponse{}, err
+	}
+
+	var response discordResponse
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return discordResponse{}, fmt.Errorf("invalid discord frame: %w", err)
+	}
+	if opcode == discordClose {
+		return response, fmt.Errorf("discord closed the connection: %s", response.Data.Message)
+	}
+	return response, nil
+}
+
+// setDeadlineLocked limits the next exchange to discordTimeout on
+// connections that support deadlines (lock must be held)
+func (d *DiscordProvider) setDeadlineLocked() {
+	if conn, ok := d.conn.(interface{ SetDeadline(time.Time) error }); ok {
+		conn.SetDeadline(time.Now().Add(discordTimeout))
+	}
+}
+
+// disconnectLocked drops the connection (lock must be held)
+func (d *DiscordProvider) disconnectLocked() {
+	if d.conn != nil {
+		d.conn.Close()
+		d.conn = nil
+	}
+}
+
+// writeDiscordFrame writes one IPC frame: opcode and length, little endian,
+// then the JSON payload
+func writeDiscordFrame(w io.Writer, opcode uint32, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 8+len(body))
+	binary.LittleEndian.PutUint32(frame[:4], opcode)
+	binary.LittleEndian.PutUint32(frame[4:8], uint32(len(body)))
+	copy(frame[8:], body)
+	_, err = w.Write(frame)
+	return err
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+
+package presence
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// dialDiscord connects to the IPC socket of a running Discord client, which
+// lives in the runtime or temporary directory, also for Flatpak and Snap
+// installs
+func dialDiscord() (io.ReadWriteCloser, error) {
+	var dirs []string
+	for _, variable := range []string{"XDG_RUNTIME_DIR", "TMPDIR", "TMP", "TEMP"} {
+		if dir := os.Getenv(variable); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	dirs = append(dirs, "/tmp")
+
+	for _, dir := range dirs {
+		for _, sub := range []string{"", "app/com.discordapp.Discord", "snap.discord"} {
+			for i := 0; i < 10; i++ {
+				path := filepath.Join(dir, sub, "discord-ipc-"+strconv.Itoa(i))
+				if conn, err := net.Dial("unix", path); err == nil {
+					return conn, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("discord is not running")
+}
@@ -0,0 +1,18 @@
+package presence
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// dialDiscord opens the named pipe of a running Discord client
+func dialDiscord() (io.ReadWriteCloser, error) {
+	for i := 0; i < 10; i++ {
+		if pipe, err := os.OpenFile(`\\.\pipe\discord-ipc-`+strconv.Itoa(i), os.O_RDWR, 0); err == nil {
+			return pipe, nil
+		}
+	}
+	return nil, fmt.Errorf("discord is not running")
+}
@@ -0,0 +1,263 @@
+// Package presence publishes what the player is doing (map, faction, game
+// phase and elapsed time) to rich presence services such as Discord. Each
+// service is a Provider, so other platforms can be added without touching
+// the game.
+package presence
+
+import (
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/logging"
+)
+
+// Phase is the stage of play shown in the presence
+type Phase string
+
+const (
+	PhaseMenu    Phase = "In menus"
+	PhaseEarly   Phase = "Early game"
+	PhaseMid     Phase = "Mid game"
+	PhaseLate    Phase = "Late game"
+	PhasePaused  Phase = "Paused"
+	PhaseVictory Phase = "Victory"
+	PhaseDefeat  Phase = "Defeat"
+)
+
+// Phase boundaries in game time
+const (
+	MidGameStart  = 8 * time.Minute
+	LateGameStart = 20 * time.Minute
+)
+
+// RefreshInterval is how often the presence is republished when only the
+// elapsed time changed
+const RefreshInterval = time.Minute
+
+// PhaseAt returns the phase of a running match at a game time
+func PhaseAt(gameTime time.Duration) Phase {
+	switch {
+	case gameTime >= LateGameStart:
+		return PhaseLate
+	case gameTime >= MidGameStart:
+		return PhaseMid
+	default:
+		return PhaseEarly
+	}
+}
+
+// Activity is what the presence shows
+type Activity struct {
+	Map     string        // Map played ("" in menus)
+	Faction string        // Faction of the local player ("" in menus)
+	Phase   Phase         // Stage of play
+	Started time.Time     // Wall time the match started (zero in menus)
+	Elapsed time.Duration // Game time played
+}
+
+// InMatch reports whether the activity is a match rather than the menus
+func (a Activity) InMatch() bool {
+	return a.Phase != PhaseMenu
+}
+
+// Provider publishes activities to one rich presence service
+type Provider interface {
+	Name() string
+	SetActivity(activity Activity) error
+	Close() error
+}
+
+// Presence follows the match from engine events and game time and publishes
+// the activity to its providers. Providers are called from a background
+// goroutine, so a slow or absent service never stalls a frame; only the
+// latest activity is sent.
+type Presence struct {
+	providers []Provider
+	playerID  int // Local player, whose outcome ends the match
+
+	activity  Activity
+	outcome   bool // Victory or defeat decided; the phase no longer follows game time
+	paused    bool
+	published Activity
+	lastSent  time.Time
+
+	pending chan Activity // Latest activity waiting for the publisher (capacity 1)
+	done    chan struct{}
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// New creates a presence for the local player publishing to the providers.
+// It starts in the menus; call Close when the game exits.
+func New(playerID int, providers ...Provider) *Presence {
+	p := &Presence{
+		providers: providers,
+		playerID:  playerID,
+		activity:  Activity{Phase: PhaseMenu},
+		pending:   make(chan Activity, 1),
+		done:      make(chan struct{}),
+	}
+	go p.publish()
+	p.queue(p.activity)
+	return p
+}
+
+// Activity returns what the presence currently shows
+func (p *Presence) Activity() Activity {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.activity
+}
+
+// StartMatch shows a match beginning now on a map with a faction
+func (p *Presence) StartMatch(mapName, faction string, now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.activity = Activity{Map: mapName, Faction: faction, Phase: PhaseEarly, Started: now}
+	p.outcome, p.paused = false, false
+	p.changedLocked(now)
+}
+
+// EndMatch returns the presence to the menus
+func (p *Presence) EndMatch(now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.activity = Activity{Phase: PhaseMenu}
+	p.outcome, p.paused = false, false
+	p.changedLocked(now)
+}
+
+// SetOutcome shows the local player's victory or defeat for the rest of the match
+func (p *Presence) SetOutcome(won bool, now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.setOutcomeLocked(won, now)
+}
+
+// HandleEvent follows pauses and the end of the match
+func (p *Presence) HandleEvent(event engine.GameEvent) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.activity.InMatch() {
+		return
+	}
+
+	now := event.Timestamp
+	switch event.Type {
+	case engine.EventTypeGamePause:
+		p.paused = true
+	case engine.EventTypeGameResume:
+		p.paused = false
+	case engine.EventTypePlayerVictory, engine.EventTypePlayerDefeated:
+		if event.PlayerID == p.playerID {
+			p.setOutcomeLocked(event.Type == engine.EventTypePlayerVictory, now)
+		}
+		return
+	case engine.EventTypeGameEnd:
+		p.activity = Activity{Phase: PhaseMenu}
+		p.outcome, p.paused = false, false
+		p.changedLocked(now)
+		return
+	default:
+		return
+	}
+	p.activity.Phase = p.phaseLocked()
+	p.changedLocked(now)
+}
+
+// Update records the game time played and republishes the activity when its
+// phase changed or it is due for a refresh
+func (p *Presence) Update(gameTime time.Duration, now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.activity.InMatch() {
+		return
+	}
+	p.activity.Elapsed = gameTime
+	p.activity.Phase = p.phaseLocked()
+	p.changedLocked(now)
+}
+
+// Close stops publishing and closes the providers
+func (p *Presence) Close() {
+	p.mutex.Lock()
+	select {
+	case <-p.done:
+		p.mutex.Unlock()
+		return
+	default:
+		close(p.done)
+	}
+	p.mutex.Unlock()
+
+	for _, provider := range p.providers {
+		if err := provider.Close(); err != nil {
+			logging.Debugf(logging.CategoryGame, "Closing %s presence failed: %v", provider.Name(), err)
+		}
+	}
+}
+
+// phaseLocked returns the phase for the current state (lock must be held)
+func (p *Presence) phaseLocked() Phase {
+	switch {
+	case p.outcome:
+		return p.activity.Phase
+	case p.paused:
+		return PhasePaused
+	default:
+		return PhaseAt(p.activity.Elapsed)
+	}
+}
+
+// setOutcomeLocked fixes the phase to the outcome (lock must be held)
+func (p *Presence) setOutcomeLocked(won bool, now time.Time) {
+	if !p.activity.InMatch() || p.outcome {
+		return
+	}
+	p.outcome = true
+	p.activity.Phase = PhaseDefeat
+	if won {
+		p.activity.Phase = PhaseVictory
+	}
+	p.changedLocked(now)
+}
+
+// changedLocked queues the activity when it differs from the one published
+// in more than the elapsed time, or the last one is older than
+// RefreshInterval (lock must be held)
+func (p *Presence) changedLocked(now time.Time) {
+	shown, current := p.published, p.activity
+	shown.Elapsed, current.Elapsed = 0, 0
+	if shown == current && now.Sub(p.lastSent) < RefreshInterval {
+		return
+	}
+	p.published, p.lastSent = p.activity, now
+	p.queue(p.activity)
+}
+
+// queue hands an activity to the publisher, replacing one not sent yet
+func (p *Presence) queue(activity Activity) {
+	select {
+	case <-p.pending:
+	default:
+	}
+	p.pending <- activity
+}
+
+// publish sends queued activities to the providers until Close
+func (p *Presence) publish() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case activity := <-p.pending:
+			for _, provider := range p.providers {
+				if err := provider.SetActivity(activity); err != nil {
+					logging.Debugf(logging.CategoryGame, "%s presence update failed: %v", provider.Name(), err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package presence
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"teraglest/internal/engine"
+)
+
+// recordingProvider hands every published activity to a channel
+type recordingProvider struct {
+	activities chan Activity
+}
+
+func (r *recordingProvider) Name() string { return "recording" }
+
+func (r *recordingProvider) SetActivity(activity Activity) error {
+	r.activities <- activity
+	return nil
+}
+
+func (r *recordingProvider) Close() error { return nil }
+
+// next waits for the next published activity
+func (r *recordingProvider) next(t *testing.T) Activity {
+	t.Helper()
+	select {
+	case activity := <-r.activities:
+		return activity
+	case <-time.After(time.Second):
+		t.Fatal("No activity published")
+		return Activity{}
+	}
+}
+
+// TestPresence tests following a match through its phases
+func TestPresence(t *testing.T) {
+	provider := &recordingProvider{activities: make(chan Activity, 16)}
+	presence := New(1, provider)
+	defer presence.Close()
+
+	if activity := provider.next(t); activity.Phase != PhaseMenu {
+		t.Errorf("Expected the menus at start, got %+v", activity)
+	}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	presence.StartMatch("forest", "magic", start)
+	if activity := provider.next(t); activity.Phase != PhaseEarly || activity.Map != "forest" || activity.Faction != "magic" || !activity.Started.Equal(start) {
+		t.Errorf("Unexpected match start %+v", activity)
+	}
+
+	// Only the elapsed time changed, and the refresh is not due
+	presence.Update(30*time.Second, start.Add(30*time.Second))
+	presence.Update(MidGameStart, start.Add(MidGameStart))
+	if activity := provider.next(t); activity.Phase != PhaseMid || activity.Elapsed != MidGameStart {
+		t.Errorf("Expected the mid game, got %+v", activity)
+	}
+
+	presence.HandleEvent(engine.GameEvent{Type: engine.EventTypeGamePause, Timestamp: start.Add(9 * time.Minute), PlayerID: -1})
+	if activity := provider.next(t); activity.Phase != PhasePaused {
+		t.Errorf("Expected a pause, got %+v", activity)
+	}
+	presence.HandleEvent(engine.GameEvent{Type: engine.EventTypeGameResume, Timestamp: start.Add(10 * time.Minute), PlayerID: -1})
+	if activity := provider.next(t); activity.Phase != PhaseMid {
+		t.Errorf("Expected the mid game after resuming, got %+v", activity)
+	}
+
+	// Another player's defeat does not end the match for the local player
+	presence.HandleEvent(engine.GameEvent{Type: engine.EventTypePlayerDefeated, Timestamp: start.Add(11 * time.Minute), PlayerID: 2})
+	presence.SetOutcome(true, start.Add(12*time.Minute))
+	if activity := provider.next(t); activity.Phase != PhaseVictory {
+		t.Errorf("Expected victory, got %+v", activity)
+	}
+	presence.Update(LateGameStart, start.Add(LateGameStart+time.Minute))
+	if activity := provider.next(t); activity.Phase != PhaseVictory || activity.Elapsed != LateGameStart {
+		t.Errorf("Expected victory to stay on refresh, got %+v", activity)
+	}
+
+	presence.HandleEvent(engine.GameEvent{Type: engine.EventTypeGameEnd, Timestamp: start.Add(30 * time.Minute), PlayerID: -1})
+	if activity := provider.next(t); activity.Phase != PhaseMenu || activity.Map != "" {
+		t.Errorf("Expected the menus after the match, got %+v", activity)
+	}
+}
+
+// TestDiscordProvider tests the IPC exchange with a fake Discord client
+func TestDiscordProvider(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	provider := NewDiscordProvider("1234", "logo")
+	provider.dial = func() (io.ReadWriteCloser, error) { return client, nil }
+
+	frames := make(chan map[string]interface{}, 4)
+	go func() {
+		for {
+			var header [8]byte
+			if _, err := io.ReadFull(server, header[:]); err != nil {
+				return
+			}
+			payload := make([]byte, binary.LittleEndian.Uint32(header[4:]))
+			if _, err := io.ReadFull(server, payload); err != nil {
+				return
+			}
+			var frame map[string]interface{}
+			json.Unmarshal(payload, &frame)
+			frame["opcode"] = float64(binary.LittleEndian.Uint32(header[:4]))
+			frames <- frame
+			if frame["opcode"] == float64(discordClose) {
+				return
+			}
+			writeDiscordFrame(server, discordFrame, map[string]interface{}{"cmd": "DISPATCH", "evt": "READY"})
+		}
+	}()
+
+	start := time.Unix(1700000000, 0)
+	if err := provider.SetActivity(Activity{Map: "forest", Faction: "tech", Phase: PhaseLate, Started: start}); err != nil {
+		t.Fatalf("SetActivity failed: %v", err)
+	}
+
+	handshake := <-frames
+	if handshake["opcode"] != float64(discordHandshake) || handshake["client_id"] != "1234" {
+		t.Errorf("Unexpected handshake %v", handshake)
+	}
+	command := <-frames
+	activity := command["args"].(map[string]interface{})["activity"].(map[string]interface{})
+	if command["cmd"] != "SET_ACTIVITY" || activity["details"] != "tech on forest" || activity["state"] != string(PhaseLate) {
+		t.Errorf("Unexpected activity command %v", command)
+	}
+	if started := activity["timestamps"].(map[string]interface{})["start"]; started != float64(start.Unix()) {
+		t.Errorf("Expected start timestamp %d, got %v", start.Unix(), started)
+	}
+
+	if err := provider.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if closing := <-frames; closing["opcode"] != float64(discordClose) {
+		t.Errorf("Expected a close frame, got %v", closing)
+	}
+
+	// Without a running client the provider waits before dialing again
+	dials := 0
+	provider.dial = func() (io.ReadWriteCloser, error) { dials++; return nil, io.ErrClosedPipe }
+	if err := provider.SetActivity(Activity{Phase: PhaseMenu}); err == nil {
+		t.Error("Expected an error without Discord running")
+	}
+	if err := provider.SetActivity(Activity{Phase: PhaseMenu}); err != nil || dials != 1 {
+		t.Errorf("Expected no retry within the retry interval, got %v after %d dials", err, dials)
+	}
+}
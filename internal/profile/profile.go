@@ -0,0 +1,379 @@
+// Package profile stores local player profiles: the player's name, preferred
+// faction and hotkey profile, and career statistics updated after each match.
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"teraglest/internal/userdata"
+)
+
+// FormatVersion is the version of the profile file format
+const FormatVersion = 1
+
+// DirName is the directory under the user data root holding profiles
+const DirName = "profiles"
+
+// DefaultHotkeyProfile is the hotkey profile of new profiles
+const DefaultHotkeyProfile = "default"
+
+// fileExtension is the extension of profile files
+const fileExtension = ".json"
+
+// activeFile names the file recording the active profile
+const activeFile = "active"
+
+// Record counts the matches won and lost
+type Record struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+}
+
+// Played returns the number of matches in the record
+func (r Record) Played() int {
+	return r.Wins + r.Losses
+}
+
+// WinRate returns the fraction of matches won, or 0 if none were played
+func (r Record) WinRate() float64 {
+	if r.Played() == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Played())
+}
+
+// CareerStats holds a player's results per faction played and per map
+type CareerStats struct {
+	ByFaction map[string]Record `json:"by_faction"`
+	ByMap     map[string]Record `json:"by_map"`
+	PlayTime  time.Duration     `json:"play_time"`
+	LastMatch time.Time         `json:"last_match,omitempty"`
+	Counters  map[string]int    `json:"counters,omitempty"` // Match statistics summed over the career
+}
+
+// Total returns the record over every match
+func (s CareerStats) Total() Record {
+	var total Record
+	for _, record := range s.ByFaction {
+		total.Wins += record.Wins
+		total.Losses += record.Losses
+	}
+	return total
+}
+
+// MatchResult is the outcome of one match for the local player
+type MatchResult struct {
+	Faction  string
+	Map      string
+	Won      bool
+	Duration time.Duration
+	EndedAt  time.Time
+}
+
+// Profile is a local player profile
+type Profile struct {
+	Version          int                  `json:"version"`
+	Name             string               `json:"name"`
+	PreferredFaction string               `json:"preferred_faction,omitempty"`
+	HotkeyProfile    string               `json:"hotkey_profile"`
+	Created          time.Time            `json:"created"`
+	Stats            CareerStats          `json:"stats"`
+	Achievements     map[string]time.Time `json:"achievements,omitempty"` // Achievement ID -> when it was unlocked
+}
+
+// New creates an empty profile
+func New(name string) *Profile {
+	return &Profile{
+		Version:       FormatVersion,
+		Name:          name,
+		HotkeyProfile: DefaultHotkeyProfile,
+		Created:       time.Now(),
+		Stats: CareerStats{
+			ByFaction: make(map[string]Record),
+			ByMap:     make(map[string]Record),
+		},
+	}
+}
+
+// RecordMatch adds a match result to the career statistics
+func (p *Profile) RecordMatch(result MatchResult) {
+	if p.Stats.ByFaction == nil {
+		p.Stats.ByFaction = make(map[string]Record)
+	}
+	if p.Stats.ByMap == nil {
+		p.Stats.ByMap = make(map[string]Record)
+	}
+	p.Stats.ByFaction[result.Faction] = addResult(p.Stats.ByFaction[result.Faction], result.Won)
+	p.Stats.ByMap[result.Map] = addResult(p.Stats.ByMap[result.Map], result.Won)
+	p.Stats.PlayTime += result.Duration
+	p.Stats.LastMatch = result.EndedAt
+}
+
+// AddCounters adds the statistics of a match to the career counters
+func (p *Profile) AddCounters(counters map[string]int) {
+	if p.Stats.Counters == nil {
+		p.Stats.Counters = make(map[string]int)
+	}
+	for name, value := range counters {
+		p.Stats.Counters[name] += value
+	}
+}
+
+// Unlock records an achievement as unlocked at the given time; it returns
+// false if the achievement was already unlocked
+func (p *Profile) Unlock(achievementID string, at time.Time) bool {
+	if _, unlocked := p.Achievements[achievementID]; unlocked {
+		return false
+	}
+	if p.Achievements == nil {
+		p.Achievements = make(map[string]time.Time)
+	}
+	p.Achievements[achievementID] = at
+	return true
+}
+
+// Unlocked reports whether an achievement is unlocked and when
+func (p *Profile) Unlocked(achievementID string) (time.Time, bool) {
+	at, unlocked := p.Achievements[achievementID]
+	return at, unlocked
+}
+
+// Factions returns the factions the player has played, most played first
+func (p *Profile) Factions() []string {
+	return sortedByPlayed(p.Stats.ByFaction)
+}
+
+// Maps returns the maps the player has played, most played first
+func (p *Profile) Maps() []string {
+	return sortedByPlayed(p.Stats.ByMap)
+}
+
+// Validate checks that a profile can be stored
+func (p *Profile) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("profile has no name")
+	}
+	if p.Version > FormatVersion {
+		return fmt.Errorf("profile %q has format version %d, newer than supported %d", p.Name, p.Version, FormatVersion)
+	}
+	return nil
+}
+
+// Store keeps profiles as JSON files in one directory
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultStore returns the store in the user data directory
+func DefaultStore(paths userdata.Paths) *Store {
+	return NewStore(filepath.Join(paths.Data, DirName))
+}
+
+// Dir returns the directory the store keeps profiles in
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// List returns the names of all stored profiles, sorted
+func (s *Store) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExtension {
+			continue
+		}
+		profile, err := readProfile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue // Skip damaged files rather than hiding every profile
+		}
+		names = append(names, profile.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Load reads a stored profile by name
+func (s *Store) Load(name string) (*Profile, error) {
+	return readProfile(s.path(name))
+}
+
+// Save writes a profile, replacing any stored profile of the same name
+func (s *Store) Save(profile *Profile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return writeProfile(s.path(profile.Name), profile)
+}
+
+// Delete removes a stored profile
+func (s *Store) Delete(name string) error {
+	if err := os.Remove(s.path(name)); err != nil {
+		return fmt.Errorf("failed to delete profile %q: %w", name, err)
+	}
+	return nil
+}
+
+// Active returns the profile last selected with SetActive. If none was
+// selected it returns the first stored profile, or a new profile named
+// fallbackName (saved) if the store is empty.
+func (s *Store) Active(fallbackName string) (*Profile, error) {
+	if name, err := os.ReadFile(filepath.Join(s.dir, activeFile)); err == nil {
+		if profile, err := s.Load(strings.TrimSpace(string(name))); err == nil {
+			return profile, nil
+		}
+	}
+
+	names, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 {
+		return s.Load(names[0])
+	}
+
+	profile := New(fallbackName)
+	if err := s.Save(profile); err != nil {
+		return nil, err
+	}
+	return profile, s.SetActive(profile.Name)
+}
+
+// SetActive selects the profile Active returns
+func (s *Store) SetActive(name string) error {
+	if _, err := s.Load(name); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, activeFile), []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to select profile %q: %w", name, err)
+	}
+	return nil
+}
+
+// RecordMatch adds a match result to a stored profile and saves it
+func (s *Store) RecordMatch(name string, result MatchResult) (*Profile, error) {
+	profile, err := s.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	profile.RecordMatch(result)
+	return profile, s.Save(profile)
+}
+
+// Export copies a stored profile to path, e.g. to move it to another machine
+func (s *Store) Export(name, path string) error {
+	profile, err := s.Load(name)
+	if err != nil {
+		return err
+	}
+	return writeProfile(path, profile)
+}
+
+// Import stores the profile exported to path. An existing profile of the same
+// name is only replaced if overwrite is set.
+func (s *Store) Import(path string, overwrite bool) (*Profile, error) {
+	profile, err := readProfile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !overwrite {
+		if _, err := os.Stat(s.path(profile.Name)); err == nil {
+			return nil, fmt.Errorf("profile %q already exists", profile.Name)
+		}
+	}
+	return profile, s.Save(profile)
+}
+
+// path returns the file of a profile
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, fileName(name)+fileExtension)
+}
+
+// fileName turns a profile name into a safe file name
+func fileName(name string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			builder.WriteRune(r)
+		default:
+			fmt.Fprintf(&builder, "~%x", r) // Keeps distinct names distinct
+		}
+	}
+	return builder.String()
+}
+
+// readProfile reads and validates a profile file
+func readProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+	profile := New("")
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", path, err)
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// writeProfile writes a profile file
+func writeProfile(path string, profile *Profile) error {
+	profile.Version = FormatVersion
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profile %q: %w", profile.Name, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write profile %q: %w", profile.Name, err)
+	}
+	return nil
+}
+
+// addResult adds one match to a record
+func addResult(record Record, won bool) Record {
+	if won {
+		record.Wins++
+	} else {
+		record.Losses++
+	}
+	return record
+}
+
+// sortedByPlayed returns the keys of a record map, most played first then by name
+func sortedByPlayed(records map[string]Record) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := records[keys[i]].Played(), records[keys[j]].Played()
+		if a != b {
+			return a > b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,105 @@
+package profile
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecordMatch(t *testing.T) {
+	profile := New("Alice")
+	ended := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	profile.RecordMatch(MatchResult{Faction: "magic", Map: "conflict", Won: true, Duration: 20 * time.Minute, EndedAt: ended})
+	profile.RecordMatch(MatchResult{Faction: "magic", Map: "island", Won: false, Duration: 10 * time.Minute, EndedAt: ended})
+	profile.RecordMatch(MatchResult{Faction: "tech", Map: "conflict", Won: true, Duration: 15 * time.Minute, EndedAt: ended})
+
+	if got := profile.Stats.ByFaction["magic"]; got != (Record{Wins: 1, Losses: 1}) {
+		t.Errorf("Unexpected magic record %+v", got)
+	}
+	if got := profile.Stats.ByMap["conflict"]; got != (Record{Wins: 2}) {
+		t.Errorf("Unexpected conflict record %+v", got)
+	}
+	if total := profile.Stats.Total(); total.Played() != 3 || total.WinRate() < 0.66 || total.WinRate() > 0.67 {
+		t.Errorf("Unexpected total %+v", total)
+	}
+	if profile.Stats.PlayTime != 45*time.Minute || !profile.Stats.LastMatch.Equal(ended) {
+		t.Errorf("Unexpected play time %v or last match %v", profile.Stats.PlayTime, profile.Stats.LastMatch)
+	}
+	if got := profile.Factions(); !reflect.DeepEqual(got, []string{"magic", "tech"}) {
+		t.Errorf("Expected factions by matches played, got %v", got)
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	active, err := store.Active("Player")
+	if err != nil {
+		t.Fatalf("Active failed: %v", err)
+	}
+	if active.Name != "Player" || active.HotkeyProfile != DefaultHotkeyProfile {
+		t.Fatalf("Expected a new default profile, got %+v", active)
+	}
+
+	bob := New("Bob the Builder")
+	bob.PreferredFaction = "tech"
+	if err := store.Save(bob); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.SetActive(bob.Name); err != nil {
+		t.Fatalf("SetActive failed: %v", err)
+	}
+	if active, err := store.Active("Player"); err != nil || active.Name != bob.Name {
+		t.Fatalf("Expected %q to be active, got %+v (%v)", bob.Name, active, err)
+	}
+
+	updated, err := store.RecordMatch(bob.Name, MatchResult{Faction: "tech", Map: "conflict", Won: true})
+	if err != nil {
+		t.Fatalf("RecordMatch failed: %v", err)
+	}
+	loaded, err := store.Load(bob.Name)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.PreferredFaction != "tech" || loaded.Stats.Total() != updated.Stats.Total() {
+		t.Errorf("Unexpected profile after reload: %+v", loaded)
+	}
+
+	names, err := store.List()
+	if err != nil || !reflect.DeepEqual(names, []string{"Bob the Builder", "Player"}) {
+		t.Errorf("Unexpected profile list %v (%v)", names, err)
+	}
+	if err := store.Save(New("  ")); err == nil {
+		t.Error("Expected a profile without a name to be rejected")
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	source := NewStore(t.TempDir())
+	alice := New("Alice")
+	alice.RecordMatch(MatchResult{Faction: "magic", Map: "conflict", Won: true})
+	if err := source.Save(alice); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	exported := filepath.Join(t.TempDir(), "alice-profile.json")
+	if err := source.Export("Alice", exported); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	target := NewStore(t.TempDir())
+	imported, err := target.Import(exported, false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if imported.Stats.ByFaction["magic"].Wins != 1 {
+		t.Errorf("Expected the career stats to move with the profile, got %+v", imported.Stats)
+	}
+	if _, err := target.Import(exported, false); err == nil {
+		t.Error("Expected importing over an existing profile to need overwrite")
+	}
+	if _, err := target.Import(exported, true); err != nil {
+		t.Errorf("Import with overwrite failed: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+// Package startup holds bootstrapping shared by all TeraGlest programs, such as
+// locating the MegaGlest game data directory.
+package startup
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"teraglest/internal/userdata"
+)
+
+// DataEnvVar names the environment variable that points at the game data directory
+const DataEnvVar = "TERAGLEST_DATA"
+
+// ConfigFileName is the user config file that may contain a data_root entry
+const ConfigFileName = "config.json"
+
+// DefaultTechTree is the tech tree used when a program does not choose one
+const DefaultTechTree = "megapack"
+
+// DownloadHint explains how to obtain the game data when it cannot be found
+const DownloadHint = `TeraGlest uses the MegaGlest game data. To install it:
+  git clone https://github.com/MegaGlest/megaglest-data megaglest-source/data/glest_game
+or install MegaGlest from https://megaglest.org/ and then run with
+  --data /path/to/glest_game   or   ` + DataEnvVar + `=/path/to/glest_game`
+
+// DataNotFoundError reports every location that was searched for game data
+type DataNotFoundError struct {
+	Searched []string // Locations checked, in order
+}
+
+// Error returns the list of searched locations followed by the download hint
+func (e *DataNotFoundError) Error() string {
+	var b strings.Builder
+	b.WriteString("game data directory not found; searched:\n")
+	for _, location := range e.Searched {
+		b.WriteString("  ")
+		b.WriteString(location)
+		b.WriteString("\n")
+	}
+	b.WriteString(DownloadHint)
+	return b.String()
+}
+
+// Flags holds the command line options shared by all programs
+type Flags struct {
+	DataRoot string // Value of --data
+}
+
+// RegisterFlags adds the shared options to a flag set
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{}
+	fs.StringVar(&flags.DataRoot, "data", "", "path to the MegaGlest data directory (glest_game)")
+	return flags
+}
+
+// DiscoverDataRoot locates the data directory, preferring the --data flag
+func (f *Flags) DiscoverDataRoot() (string, error) {
+	return DiscoverDataRoot(f.DataRoot)
+}
+
+// ParseDataRoot registers the shared flags, parses the command line and locates the data directory
+func ParseDataRoot() (string, error) {
+	flags := RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	return flags.DiscoverDataRoot()
+}
+
+// DiscoverDataRoot finds the game data directory. Sources are tried in order:
+// the explicit flag value, the TERAGLEST_DATA environment variable, the user
+// config file, then common install locations.
+func DiscoverDataRoot(flagValue string) (string, error) {
+	d := discoverer{
+		getenv:     os.Getenv,
+		configPath: userdata.MustResolve().ConfigFile(ConfigFileName),
+		candidates: defaultCandidates(),
+	}
+	return d.discover(flagValue)
+}
+
+// TechTreeRoot returns the directory of a tech tree inside the data directory
+func TechTreeRoot(dataRoot, techTree string) string {
+	return filepath.Join(dataRoot, "techs", techTree)
+}
+
+// TechTreeFile returns the XML definition file of a tech tree inside the data directory
+func TechTreeFile(dataRoot, techTree string) string {
+	return filepath.Join(TechTreeRoot(dataRoot, techTree), techTree+".xml")
+}
+
+// IsDataRoot reports whether a directory looks like a MegaGlest data directory
+func IsDataRoot(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "techs"))
+	return err == nil && info.IsDir()
+}
+
+// discoverer holds the inputs to data discovery (separated for testing)
+type discoverer struct {
+	getenv     func(string) string
+	configPath string
+	candidates []string
+}
+
+// discover checks each source in priority order
+func (d discoverer) discover(flagValue string) (string, error) {
+	searched := make([]string, 0)
+
+	// Explicit locations must be valid; a typo should not silently fall through
+	if flagValue != "" {
+		if IsDataRoot(flagValue) {
+			return filepath.Clean(flagValue), nil
+		}
+		return "", fmt.Errorf("--data %s is not a MegaGlest data directory (no techs folder)\n%s", flagValue, DownloadHint)
+	}
+	if envValue := d.getenv(DataEnvVar); envValue != "" {
+		if IsDataRoot(envValue) {
+			return filepath.Clean(envValue), nil
+		}
+		return "", fmt.Errorf("%s=%s is not a MegaGlest data directory (no techs folder)\n%s", DataEnvVar, envValue, DownloadHint)
+	}
+
+	if configValue := readConfiguredDataRoot(d.configPath); configValue != "" {
+		if IsDataRoot(configValue) {
+			return filepath.Clean(configValue), nil
+		}
+		searched = append(searched, fmt.Sprintf("%s (from %s)", configValue, d.configPath))
+	}
+
+	for _, candidate := range d.candidates {
+		if IsDataRoot(candidate) {
+			return filepath.Clean(candidate), nil
+		}
+		searched = append(searched, candidate)
+	}
+
+	return "", &DataNotFoundError{Searched: searched}
+}
+
+// readConfiguredDataRoot returns the data_root entry of the user config file, if any
+func readConfiguredDataRoot(configPath string) string {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return ""
+	}
+
+	var config struct {
+		DataRoot string `json:"data_root"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return ""
+	}
+	return config.DataRoot
+}
+
+// defaultCandidates returns common data locations relative to the working
+// directory and executable, plus typical system install paths
+func defaultCandidates() []string {
+	relative := filepath.Join("megaglest-source", "data", "glest_game")
+	candidates := []string{
+		relative,
+		filepath.Join("..", relative),
+		filepath.Join("..", "..", relative),
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		exeDir := filepath.Dir(exe)
+		candidates = append(candidates,
+			filepath.Join(exeDir, relative),
+			filepath.Join(exeDir, "data", "glest_game"),
+		)
+	}
+
+	candidates = append(candidates,
+		"/usr/share/megaglest",
+		"/usr/share/games/megaglest",
+		"/usr/local/share/megaglest",
+		"/usr/local/share/games/megaglest",
+		"/Applications/MegaGlest.app/Contents/Resources/megaglest-game",
+	)
+
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".local", "share", "megaglest"))
+	}
+	if programFiles := os.Getenv("ProgramFiles"); programFiles != "" {
+		candidates = append(candidates, filepath.Join(programFiles, "MegaGlest"))
+	}
+
+	return candidates
+}
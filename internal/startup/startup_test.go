@@ -0,0 +1,88 @@
+package startup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// makeDataRoot creates a directory that passes IsDataRoot
+func makeDataRoot(t *testing.T, dir string) string {
+	if err := os.MkdirAll(filepath.Join(dir, "techs"), 0755); err != nil {
+		t.Fatalf("Failed to create data root: %v", err)
+	}
+	return dir
+}
+
+func TestDiscoverPriorityOrder(t *testing.T) {
+	base := t.TempDir()
+	flagRoot := makeDataRoot(t, filepath.Join(base, "flag"))
+	envRoot := makeDataRoot(t, filepath.Join(base, "env"))
+	configRoot := makeDataRoot(t, filepath.Join(base, "config"))
+	candidateRoot := makeDataRoot(t, filepath.Join(base, "candidate"))
+
+	configPath := filepath.Join(base, "config.json")
+	os.WriteFile(configPath, []byte(`{"data_root": "`+filepath.ToSlash(configRoot)+`"}`), 0644)
+
+	env := map[string]string{DataEnvVar: envRoot}
+	d := discoverer{
+		getenv:     func(key string) string { return env[key] },
+		configPath: configPath,
+		candidates: []string{filepath.Join(base, "missing"), candidateRoot},
+	}
+
+	if root, err := d.discover(flagRoot); err != nil || root != flagRoot {
+		t.Errorf("Expected flag root %s, got %s (err %v)", flagRoot, root, err)
+	}
+	if root, err := d.discover(""); err != nil || root != envRoot {
+		t.Errorf("Expected env root %s, got %s (err %v)", envRoot, root, err)
+	}
+
+	delete(env, DataEnvVar)
+	if root, err := d.discover(""); err != nil || filepath.Clean(root) != filepath.Clean(configRoot) {
+		t.Errorf("Expected config root %s, got %s (err %v)", configRoot, root, err)
+	}
+
+	d.configPath = filepath.Join(base, "no-config.json")
+	if root, err := d.discover(""); err != nil || root != candidateRoot {
+		t.Errorf("Expected candidate root %s, got %s (err %v)", candidateRoot, root, err)
+	}
+}
+
+func TestDiscoverInvalidExplicitPath(t *testing.T) {
+	d := discoverer{getenv: func(string) string { return "" }}
+
+	_, err := d.discover(t.TempDir())
+	if err == nil {
+		t.Fatal("Expected error for --data without a techs folder")
+	}
+	if !strings.Contains(err.Error(), "megaglest-data") {
+		t.Errorf("Expected download hint in error, got: %v", err)
+	}
+}
+
+func TestDiscoverNotFound(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "nowhere")
+	d := discoverer{
+		getenv:     func(string) string { return "" },
+		candidates: []string{missing},
+	}
+
+	_, err := d.discover("")
+	var notFound *DataNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected DataNotFoundError, got %v", err)
+	}
+	if len(notFound.Searched) != 1 || notFound.Searched[0] != missing {
+		t.Errorf("Expected searched locations to be reported, got %v", notFound.Searched)
+	}
+}
+
+func TestTechTreePaths(t *testing.T) {
+	root := filepath.Join("data", "glest_game")
+	if got := TechTreeFile(root, DefaultTechTree); got != filepath.Join(root, "techs", "megapack", "megapack.xml") {
+		t.Errorf("Unexpected tech tree file: %s", got)
+	}
+}
@@ -0,0 +1,292 @@
+// Package tutorial runs scripted tutorial scenarios: sequences of hints that
+// each wait for a trigger (a player action, a match statistic or a delay)
+// before moving on to the next step.
+package tutorial
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+)
+
+//go:embed scenarios/*.json
+var builtinScenarios embed.FS
+
+// TriggerType selects what a step waits for
+type TriggerType string
+
+const (
+	TriggerAction TriggerType = "action" // The player performed a UI action, see the ui.Action* constants
+	TriggerStat   TriggerType = "stat"   // A match statistic grew by Count since the step began
+	TriggerWait   TriggerType = "wait"   // Seconds passed since the step began
+)
+
+// Trigger is the condition completing a step
+type Trigger struct {
+	Type    TriggerType `json:"type"`
+	Action  string      `json:"action,omitempty"`  // Action name for TriggerAction
+	Stat    string      `json:"stat,omitempty"`    // Statistic for TriggerStat, see the engine.Stat* constants
+	Count   int         `json:"count,omitempty"`   // Increase needed for TriggerStat (default 1)
+	Seconds float64     `json:"seconds,omitempty"` // Delay for TriggerWait
+}
+
+// Validate checks a trigger for mistakes
+func (t Trigger) Validate() error {
+	switch t.Type {
+	case TriggerAction:
+		if t.Action == "" {
+			return fmt.Errorf("action trigger without an action")
+		}
+	case TriggerStat:
+		if t.Stat == "" {
+			return fmt.Errorf("stat trigger without a statistic")
+		}
+		if t.Count < 0 {
+			return fmt.Errorf("stat trigger with negative count %d", t.Count)
+		}
+	case TriggerWait:
+		if t.Seconds <= 0 {
+			return fmt.Errorf("wait trigger needs a positive delay")
+		}
+	default:
+		return fmt.Errorf("unknown trigger type %q", t.Type)
+	}
+	return nil
+}
+
+// Step is one hint of a scenario
+type Step struct {
+	ID        string  `json:"id"`
+	Hint      string  `json:"hint"`                // Text shown to the player
+	Highlight string  `json:"highlight,omitempty"` // UI element to draw attention to, e.g. "minimap"
+	Trigger   Trigger `json:"trigger"`
+}
+
+// Scenario is a tutorial: the match it is played in and its steps
+type Scenario struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Faction     string `json:"faction,omitempty"` // Faction the player starts with, default the profile's
+	Steps       []Step `json:"steps"`
+}
+
+// Validate checks a scenario for mistakes
+func (s *Scenario) Validate() error {
+	if s.ID == "" {
+		return fmt.Errorf("tutorial scenario without an id")
+	}
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("tutorial %s has no steps", s.ID)
+	}
+	for i, step := range s.Steps {
+		if step.Hint == "" {
+			return fmt.Errorf("tutorial %s step %d has no hint", s.ID, i+1)
+		}
+		if err := step.Trigger.Validate(); err != nil {
+			return fmt.Errorf("tutorial %s step %d: %w", s.ID, i+1, err)
+		}
+	}
+	return nil
+}
+
+// ParseScenario reads a scenario from JSON
+func ParseScenario(data []byte) (*Scenario, error) {
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse tutorial: %w", err)
+	}
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// LoadScenario reads a scenario from a JSON file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tutorial: %w", err)
+	}
+	return ParseScenario(data)
+}
+
+// Builtin returns a scenario shipped with the game by ID
+func Builtin(id string) (*Scenario, error) {
+	data, err := builtinScenarios.ReadFile(path.Join("scenarios", id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown tutorial %q (available: %s)", id, strings.Join(BuiltinIDs(), ", "))
+	}
+	return ParseScenario(data)
+}
+
+// BuiltinIDs lists the IDs of the scenarios shipped with the game, sorted
+func BuiltinIDs() []string {
+	entries, _ := builtinScenarios.ReadDir("scenarios")
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Tutorial runs a scenario: it shows one step at a time and advances when the
+// step's trigger fires. Report actions with Notify and call Update regularly
+// with the player's match statistics.
+type Tutorial struct {
+	scenario *Scenario
+	current  int            // Index of the current step; len(Steps) once finished
+	started  time.Time      // When the current step began
+	baseline map[string]int // Statistics when the current step began
+	latest   engine.PlayerStats
+	actions  map[string]bool // Actions reported during the current step
+	onStep   func(step Step, index int)
+	onFinish func()
+	mutex    sync.Mutex
+}
+
+// NewTutorial creates a tutorial for a scenario; it begins with Start
+func NewTutorial(scenario *Scenario) *Tutorial {
+	return &Tutorial{
+		scenario: scenario,
+		current:  -1,
+		actions:  make(map[string]bool),
+	}
+}
+
+// Scenario returns the scenario being played
+func (t *Tutorial) Scenario() *Scenario {
+	return t.scenario
+}
+
+// SetStepHandler registers a function called whenever a step begins
+func (t *Tutorial) SetStepHandler(handler func(step Step, index int)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.onStep = handler
+}
+
+// SetFinishHandler registers a function called once the last step completed
+func (t *Tutorial) SetFinishHandler(handler func()) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.onFinish = handler
+}
+
+// Start begins the first step
+func (t *Tutorial) Start(now time.Time) {
+	t.mutex.Lock()
+	t.latest = engine.PlayerStats{}
+	callback := t.begin(0, now)
+	t.mutex.Unlock()
+	callback()
+}
+
+// Current returns the step shown and its index, or false before Start and once finished
+func (t *Tutorial) Current() (Step, int, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.current < 0 || t.current >= len(t.scenario.Steps) {
+		return Step{}, t.current, false
+	}
+	return t.scenario.Steps[t.current], t.current, true
+}
+
+// Finished reports whether every step was completed
+func (t *Tutorial) Finished() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.current >= len(t.scenario.Steps)
+}
+
+// Notify reports a player action; the current step completes on its next
+// Update if it waits for that action
+func (t *Tutorial) Notify(action string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.current >= 0 {
+		t.actions[action] = true
+	}
+}
+
+// Update checks the current step's trigger against the latest statistics and
+// reported actions, advancing as long as triggers fire. It returns whether
+// the tutorial moved on.
+func (t *Tutorial) Update(stats engine.PlayerStats, now time.Time) bool {
+	t.mutex.Lock()
+	t.latest = stats
+	advanced := false
+	var callbacks []func()
+	for t.current >= 0 && t.current < len(t.scenario.Steps) && t.triggered(now) {
+		callbacks = append(callbacks, t.begin(t.current+1, now))
+		advanced = true
+	}
+	t.mutex.Unlock()
+
+	for _, callback := range callbacks {
+		callback()
+	}
+	return advanced
+}
+
+// Skip completes the current step without waiting for its trigger
+func (t *Tutorial) Skip(now time.Time) {
+	t.mutex.Lock()
+	if t.current < 0 || t.current >= len(t.scenario.Steps) {
+		t.mutex.Unlock()
+		return
+	}
+	callback := t.begin(t.current+1, now)
+	t.mutex.Unlock()
+	callback()
+}
+
+// triggered reports whether the current step's trigger fired (lock must be held)
+func (t *Tutorial) triggered(now time.Time) bool {
+	trigger := t.scenario.Steps[t.current].Trigger
+	switch trigger.Type {
+	case TriggerAction:
+		return t.actions[trigger.Action]
+	case TriggerStat:
+		count := trigger.Count
+		if count == 0 {
+			count = 1
+		}
+		return t.latest[trigger.Stat]-t.baseline[trigger.Stat] >= count
+	case TriggerWait:
+		return now.Sub(t.started).Seconds() >= trigger.Seconds
+	}
+	return false
+}
+
+// begin moves to a step and returns the handler call to make once the lock is released (lock must be held)
+func (t *Tutorial) begin(index int, now time.Time) func() {
+	t.current = index
+	t.started = now
+	t.actions = make(map[string]bool)
+	t.baseline = make(map[string]int, len(t.latest))
+	for stat, value := range t.latest {
+		t.baseline[stat] = value
+	}
+
+	if index >= len(t.scenario.Steps) {
+		if handler := t.onFinish; handler != nil {
+			return handler
+		}
+		return func() {}
+	}
+	if handler := t.onStep; handler != nil {
+		step := t.scenario.Steps[index]
+		return func() { handler(step, index) }
+	}
+	return func() {}
+}
@@ -0,0 +1,80 @@
+package tutorial
+
+import (
+	"testing"
+	"time"
+
+	"teraglest/internal/engine"
+)
+
+func TestBuiltinScenariosParse(t *testing.T) {
+	ids := BuiltinIDs()
+	if len(ids) == 0 {
+		t.Fatal("Expected at least one built-in tutorial")
+	}
+	for _, id := range ids {
+		if _, err := Builtin(id); err != nil {
+			t.Errorf("Built-in tutorial %s is invalid: %v", id, err)
+		}
+	}
+	if _, err := Builtin("missing"); err == nil {
+		t.Error("Expected an error for an unknown tutorial")
+	}
+}
+
+func TestParseScenarioRejectsBadTriggers(t *testing.T) {
+	bad := []string{
+		`{"id": "t", "steps": []}`,
+		`{"id": "t", "steps": [{"hint": "h", "trigger": {"type": "dance"}}]}`,
+		`{"id": "t", "steps": [{"hint": "h", "trigger": {"type": "action"}}]}`,
+		`{"id": "t", "steps": [{"hint": "h", "trigger": {"type": "wait"}}]}`,
+		`{"id": "t", "steps": [{"trigger": {"type": "wait", "seconds": 1}}]}`,
+	}
+	for _, data := range bad {
+		if _, err := ParseScenario([]byte(data)); err == nil {
+			t.Errorf("Expected %s to be rejected", data)
+		}
+	}
+}
+
+func TestTutorialAdvancesOnTriggers(t *testing.T) {
+	scenario := &Scenario{ID: "test", Steps: []Step{
+		{ID: "wait", Hint: "wait", Trigger: Trigger{Type: TriggerWait, Seconds: 2}},
+		{ID: "select", Hint: "select", Trigger: Trigger{Type: TriggerAction, Action: "select_units"}},
+		{ID: "train", Hint: "train", Trigger: Trigger{Type: TriggerStat, Stat: "units_trained:worker", Count: 2}},
+	}}
+	tutorial := NewTutorial(scenario)
+	var shown []string
+	finished := false
+	tutorial.SetStepHandler(func(step Step, index int) { shown = append(shown, step.ID) })
+	tutorial.SetFinishHandler(func() { finished = true })
+
+	start := time.Unix(0, 0)
+	tutorial.Start(start)
+	if tutorial.Update(nil, start.Add(time.Second)) {
+		t.Error("Expected the wait step to hold for 2 seconds")
+	}
+	tutorial.Notify("select_units") // Before its step: must not count
+	tutorial.Update(nil, start.Add(2*time.Second))
+	if step, _, _ := tutorial.Current(); step.ID != "select" {
+		t.Fatalf("Expected the select step, got %s", step.ID)
+	}
+	tutorial.Notify("select_units")
+	stats := engine.PlayerStats{"units_trained:worker": 5} // Counted from the step's start
+	tutorial.Update(stats, start.Add(3*time.Second))
+	if step, _, _ := tutorial.Current(); step.ID != "train" {
+		t.Fatalf("Expected the train step, got %s", step.ID)
+	}
+
+	tutorial.Update(engine.PlayerStats{"units_trained:worker": 6}, start.Add(4*time.Second))
+	if tutorial.Finished() {
+		t.Fatal("Expected one trained worker not to complete the step")
+	}
+	tutorial.Update(engine.PlayerStats{"units_trained:worker": 7}, start.Add(5*time.Second))
+	if !tutorial.Finished() || !finished {
+		t.Error("Expected the tutorial to finish")
+	}
+	if len(shown) != 3 {
+		t.Errorf("Expected 3 steps shown, got %v", shown)
+	}
+}
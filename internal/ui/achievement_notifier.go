@@ -0,0 +1,68 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"teraglest/internal/achievement"
+)
+
+// DefaultNotificationDuration is how long an unlock notification stays on screen
+const DefaultNotificationDuration = 5 * time.Second
+
+// achievementNotification is an unlock waiting to be shown or on screen
+type achievementNotification struct {
+	definition achievement.Definition
+	shownAt    time.Time // Zero until first rendered
+}
+
+// AchievementNotifier shows a notification for each unlocked achievement, one at a time
+type AchievementNotifier struct {
+	queue    []achievementNotification
+	duration time.Duration
+	now      func() time.Time
+	mutex    sync.Mutex
+}
+
+// NewAchievementNotifier creates a notifier showing each unlock for DefaultNotificationDuration
+func NewAchievementNotifier() *AchievementNotifier {
+	return &AchievementNotifier{duration: DefaultNotificationDuration, now: time.Now}
+}
+
+// Notify queues the notification of an unlocked achievement; use it as the tracker's unlock handler
+func (an *AchievementNotifier) Notify(definition achievement.Definition) {
+	an.mutex.Lock()
+	defer an.mutex.Unlock()
+	an.queue = append(an.queue, achievementNotification{definition: definition})
+}
+
+// Pending returns the number of notifications not yet dismissed
+func (an *AchievementNotifier) Pending() int {
+	an.mutex.Lock()
+	defer an.mutex.Unlock()
+	return len(an.queue)
+}
+
+// Render shows the current notification and moves on once it expired
+// (console output until text rendering exists)
+func (an *AchievementNotifier) Render() {
+	an.mutex.Lock()
+	defer an.mutex.Unlock()
+
+	now := an.now()
+	for len(an.queue) > 0 {
+		current := &an.queue[0]
+		if current.shownAt.IsZero() {
+			current.shownAt = now
+			fmt.Printf("*** Achievement unlocked: %s - %s ***\n", current.definition.Name, current.definition.Description)
+			return
+		}
+		if now.Sub(current.shownAt) < an.duration {
+			return
+		}
+		an.queue = an.queue[1:]
+	}
+}
@@ -0,0 +1,216 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Attack alert presentation
+const (
+	attackFlashDuration = 4 * time.Second // How long an alert stays on the minimap and screen edge
+	attackFlashRate     = 3.0             // Minimap flashes per second
+	attackEdgeMargin    = 24              // Distance of edge indicators from the screen border, in pixels
+)
+
+// EdgeIndicator points from the screen edge towards an off-screen attack
+type EdgeIndicator struct {
+	X, Y  float32 // Screen position on the edge, in pixels from the top left
+	Angle float32 // Direction towards the attack, in radians counterclockwise from screen right
+	Alert engine.AttackAlert
+}
+
+// MinimapFlash marks the location of an attack on the minimap
+type MinimapFlash struct {
+	X, Y      float32 // Position as a fraction of the map width and height
+	Intensity float32 // Current brightness, 0-1, blinking while the alert lasts
+}
+
+// attackFlash is an alert being shown
+type attackFlash struct {
+	alert     engine.AttackAlert
+	start     time.Time
+	announced bool // Whether Render has shown the alert
+}
+
+// AttackAlertIndicator flashes the minimap location of attacks on the local
+// player's units and buildings, and points at them from the screen edge while
+// they are off screen
+type AttackAlertIndicator struct {
+	camera    *renderer.Camera
+	mapWidth  float32 // World size covered by the minimap, in world units
+	mapHeight float32
+	flashes   []attackFlash
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewAttackAlertIndicator creates an indicator for a camera and a map of the given world size
+func NewAttackAlertIndicator(camera *renderer.Camera, mapWidth, mapHeight float32) *AttackAlertIndicator {
+	return &AttackAlertIndicator{camera: camera, mapWidth: mapWidth, mapHeight: mapHeight}
+}
+
+// HandleEvent shows under-attack events; it returns false for other events
+func (ai *AttackAlertIndicator) HandleEvent(event engine.GameEvent) bool {
+	alert, ok := event.Data.(engine.AttackAlert)
+	if event.Type != engine.EventTypeUnderAttack || !ok {
+		return false
+	}
+
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+	ai.flashes = append(ai.flashes, attackFlash{alert: alert, start: event.Timestamp})
+	return true
+}
+
+// MinimapFlashes returns the attack locations to flash on the minimap
+func (ai *AttackAlertIndicator) MinimapFlashes(now time.Time) []MinimapFlash {
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+	ai.expireLocked(now)
+
+	flashes := make([]MinimapFlash, 0, len(ai.flashes))
+	for _, flash := range ai.flashes {
+		phase := now.Sub(flash.start).Seconds() * attackFlashRate * 2 * math.Pi
+		flashes = append(flashes, MinimapFlash{
+			X:         float32(flash.alert.Position.X) / ai.mapWidth,
+			Y:         float32(flash.alert.Position.Z) / ai.mapHeight,
+			Intensity: float32(0.5 + 0.5*math.Cos(phase)),
+		})
+	}
+	return flashes
+}
+
+// EdgeIndicators returns an indicator for each alert outside the camera's view
+func (ai *AttackAlertIndicator) EdgeIndicators(screenWidth, screenHeight int, now time.Time) []EdgeIndicator {
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+	ai.expireLocked(now)
+	return ai.edgeIndicatorsLocked(screenWidth, screenHeight, nil)
+}
+
+// Render shows alerts once as they arrive (console output until text
+// rendering exists); attacks already on screen only flash the minimap
+func (ai *AttackAlertIndicator) Render(screenWidth, screenHeight int, now time.Time) {
+	ai.mutex.Lock()
+	defer ai.mutex.Unlock()
+	ai.expireLocked(now)
+
+	var fresh []int
+	for i := range ai.flashes {
+		if !ai.flashes[i].announced {
+			ai.flashes[i].announced = true
+			fresh = append(fresh, i)
+		}
+	}
+	if len(fresh) == 0 {
+		return
+	}
+
+	offScreen := make(map[int]EdgeIndicator)
+	ai.edgeIndicatorsLocked(screenWidth, screenHeight, func(index int, indicator EdgeIndicator) {
+		offScreen[index] = indicator
+	})
+	for _, index := range fresh {
+		alert := ai.flashes[index].alert
+		kind := "unit"
+		if alert.IsBuilding {
+			kind = "building"
+		}
+		line := fmt.Sprintf("Your %s %s is under attack (minimap %.0f%%, %.0f%%)", alert.TargetType, kind,
+			100*alert.Position.X/float64(ai.mapWidth), 100*alert.Position.Z/float64(ai.mapHeight))
+		if indicator, exists := offScreen[index]; exists {
+			line += fmt.Sprintf(" - off screen, %s", edgeName(indicator, screenWidth, screenHeight))
+		}
+		fmt.Println(line)
+	}
+}
+
+// expireLocked drops alerts older than attackFlashDuration (lock must be held)
+func (ai *AttackAlertIndicator) expireLocked(now time.Time) {
+	kept := ai.flashes[:0]
+	for _, flash := range ai.flashes {
+		if now.Sub(flash.start) < attackFlashDuration {
+			kept = append(kept, flash)
+		}
+	}
+	ai.flashes = kept
+}
+
+// edgeIndicatorsLocked projects the alerts and clamps the off-screen ones to
+// the screen edge, reporting each to visit along with its index (lock must be held)
+func (ai *AttackAlertIndicator) edgeIndicatorsLocked(screenWidth, screenHeight int, visit func(int, EdgeIndicator)) []EdgeIndicator {
+	viewProjection := ai.camera.GetProjectionMatrix().Mul4(ai.camera.GetViewMatrix())
+	halfWidth, halfHeight := float32(screenWidth)/2, float32(screenHeight)/2
+
+	var indicators []EdgeIndicator
+	for i, flash := range ai.flashes {
+		position := flash.alert.Position
+		clip := viewProjection.Mul4x1(mgl32.Vec4{float32(position.X), float32(position.Y), float32(position.Z), 1})
+
+		// Points behind the camera project mirrored, so their direction is flipped
+		x, y := clip.X(), clip.Y()
+		if clip.W() > 0 {
+			x, y = x/clip.W(), y/clip.W()
+			if x >= -1 && x <= 1 && y >= -1 && y <= 1 {
+				continue // On screen
+			}
+		} else {
+			x, y = -x, -y
+		}
+		if x == 0 && y == 0 {
+			y = -1 // Directly behind: point down
+		}
+
+		// Scale the direction from the screen center until it touches the margin
+		dx, dy := x*halfWidth, -y*halfHeight
+		scale := float32(math.Min(
+			float64((halfWidth-attackEdgeMargin)/float32(math.Abs(float64(dx)))),
+			float64((halfHeight-attackEdgeMargin)/float32(math.Abs(float64(dy))))))
+		indicator := EdgeIndicator{
+			X:     halfWidth + dx*scale,
+			Y:     halfHeight + dy*scale,
+			Angle: float32(math.Atan2(float64(-dy), float64(dx))),
+			Alert: flash.alert,
+		}
+		indicators = append(indicators, indicator)
+		if visit != nil {
+			visit(i, indicator)
+		}
+	}
+	return indicators
+}
+
+// edgeName names the screen edge or corner an indicator sits on
+func edgeName(indicator EdgeIndicator, screenWidth, screenHeight int) string {
+	vertical, horizontal := "", ""
+	switch {
+	case indicator.Y <= attackEdgeMargin+1:
+		vertical = "top"
+	case indicator.Y >= float32(screenHeight)-attackEdgeMargin-1:
+		vertical = "bottom"
+	}
+	switch {
+	case indicator.X <= attackEdgeMargin+1:
+		horizontal = "left"
+	case indicator.X >= float32(screenWidth)-attackEdgeMargin-1:
+		horizontal = "right"
+	}
+	switch {
+	case vertical != "" && horizontal != "":
+		return vertical + "-" + horizontal
+	case vertical != "":
+		return vertical
+	default:
+		return horizontal
+	}
+}
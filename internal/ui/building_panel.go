@@ -0,0 +1,323 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/graphics/text"
+	"teraglest/internal/logging"
+)
+
+// Building panel layout, in pixels
+const (
+	buildingPanelRows    = 4 // Rally point, buttons, queue and garrison
+	buildingRowHeight    = 30
+	buildingPanelHeight  = buildingPanelRows*buildingRowHeight + 2*unitPanelPadding
+	buildingButtonWidth  = 64
+	buildingSlotSize     = 26
+	buildingControlGap   = 4
+	buildingCaptionWidth = 64
+)
+
+// gatherPickRadius is how near to a resource node, in tiles, a rally click
+// makes the node the building's gather point
+const gatherPickRadius = 1.5
+
+// buildingControl is an area of the building panel: a caption, or a button
+// or slot that runs an action on the building when clicked
+type buildingControl struct {
+	rect    sprite.Rect
+	label   string // Text drawn without an icon
+	icon    string // Unit whose portrait is drawn, "" for none
+	caption bool   // Text only, not clickable
+	run     func() error
+}
+
+// BuildingPanel shows the controls of the player's selected building above
+// the unit panel, with the HUD sprite layer: its rally or gather point, the
+// production queue with a click cancelling a queued unit, the garrison
+// slots with a click ejecting a unit, and the auto-production toggle. The
+// rally button makes the next world click set the rally point, or the gather
+// point when it lands on a resource.
+type BuildingPanel struct {
+	world     *engine.World
+	playerID  int
+	selection *SimpleUIManager
+
+	height       int                  // Height of the HUD last drawn on, for hit tests
+	placingRally *engine.GameBuilding // Building whose rally point the next world click sets
+
+	mutex sync.Mutex
+}
+
+// NewBuildingPanel creates a building panel for a player's buildings selected in a UI manager
+func NewBuildingPanel(world *engine.World, playerID int, selection *SimpleUIManager) *BuildingPanel {
+	return &BuildingPanel{world: world, playerID: playerID, selection: selection}
+}
+
+// Draw draws the panel on the HUD; nothing is drawn unless one of the
+// player's finished buildings is selected
+func (bp *BuildingPanel) Draw(canvas *renderer.HUDCanvas) {
+	bp.mutex.Lock()
+	bp.height = canvas.Height
+	bp.mutex.Unlock()
+
+	building := bp.building()
+	if building == nil {
+		return
+	}
+	factionName := ""
+	if player := bp.world.GetPlayer(building.PlayerID); player != nil {
+		factionName = player.FactionName
+	}
+
+	panel, controls := bp.layout(building)
+	canvas.Sprites.Fill(panel, hudPanelColor)
+	for _, control := range controls {
+		if control.caption {
+			canvas.Text.DrawScreenText(control.rect.X, control.rect.Y+control.rect.H/2, control.label,
+				renderer.DefaultTextSize*0.8, hudTextColor, text.AnchorMiddleLeft)
+			continue
+		}
+		if control.icon != "" {
+			if icon := canvas.UnitIcon(factionName, control.icon); icon != sprite.NoTexture {
+				canvas.Sprites.Icon(control.rect, icon, sprite.White)
+				continue
+			}
+		}
+		canvas.Sprites.Fill(control.rect, commandSlotColor)
+		canvas.Text.DrawScreenText(control.rect.X+control.rect.W/2, control.rect.Y+control.rect.H/2, control.label,
+			renderer.DefaultTextSize*0.7, hudTextColor, text.AnchorCenter)
+	}
+}
+
+// HandleClick runs the control under a click on the HUD, returning true if
+// the click landed on the panel
+func (bp *BuildingPanel) HandleClick(x, y float32) bool {
+	building := bp.building()
+	if building == nil {
+		return false
+	}
+	panel, controls := bp.layout(building)
+	if !panel.Contains(x, y) {
+		return false
+	}
+	for _, control := range controls {
+		if control.run == nil || !control.rect.Contains(x, y) {
+			continue
+		}
+		if err := control.run(); err != nil {
+			logging.Warnf(logging.CategoryUI, "%s: %v", building.BuildingType, err)
+		}
+		break
+	}
+	return true
+}
+
+// IsPlacingRallyPoint reports whether the next world click sets a rally point
+func (bp *BuildingPanel) IsPlacingRallyPoint() bool {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	return bp.placingRally != nil
+}
+
+// HandleWorldClick sets the rally point, or the gather point on a resource,
+// of the building being placed for; it returns false when not placing one
+func (bp *BuildingPanel) HandleWorldClick(position engine.Vector3) bool {
+	bp.mutex.Lock()
+	building := bp.placingRally
+	bp.placingRally = nil
+	bp.mutex.Unlock()
+
+	// Placement ends when the building is no longer selected
+	if building == nil || building != bp.building() {
+		return false
+	}
+
+	productionSys := bp.world.GetProductionSystem()
+	var err error
+	if node := bp.resourceAt(position); node != nil {
+		err = productionSys.SetGatherPoint(building.ID, node.ID)
+	} else {
+		err = productionSys.SetRallyPoint(building.ID, position)
+	}
+	if err != nil {
+		logging.Warnf(logging.CategoryUI, "Failed to set rally point: %v", err)
+	}
+	return true
+}
+
+// building returns the selected building when it is one of the player's
+// and finished, or nil
+func (bp *BuildingPanel) building() *engine.GameBuilding {
+	building := bp.selection.GetSelectedBuilding()
+	if building == nil || building.PlayerID != bp.playerID || !building.IsAlive() || !building.IsBuilt {
+		return nil
+	}
+	return building
+}
+
+// layout places the panel above the unit panel and the controls of a
+// building in it, one row each for the rally point, the buttons, the
+// production queue and the garrison
+func (bp *BuildingPanel) layout(building *engine.GameBuilding) (sprite.Rect, []buildingControl) {
+	bp.mutex.Lock()
+	height := bp.height
+	placing := bp.placingRally == building
+	bp.mutex.Unlock()
+
+	panel := sprite.Rect{
+		X: unitPanelMargin,
+		Y: float32(height) - unitPanelHeight - buildingPanelHeight - 2*unitPanelMargin,
+		W: unitPanelWidth,
+		H: buildingPanelHeight,
+	}
+	inner := panel.Inset(unitPanelPadding)
+	productionSys := bp.world.GetProductionSystem()
+	row := func(i int) sprite.Rect {
+		return sprite.Rect{X: inner.X, Y: inner.Y + float32(i*buildingRowHeight), W: inner.W, H: buildingRowHeight}
+	}
+	controls := []buildingControl{{rect: row(0), label: rallyLabel(building, placing), caption: true}}
+
+	// Buttons
+	buttons := row(1)
+	button := func(i int) sprite.Rect {
+		return sprite.Rect{
+			X: buttons.X + float32(i)*(buildingButtonWidth+buildingControlGap),
+			Y: buttons.Y + (buttons.H-buildingSlotSize)/2,
+			W: buildingButtonWidth,
+			H: buildingSlotSize,
+		}
+	}
+	rallyText := "Rally"
+	if placing {
+		rallyText = "Cancel"
+	}
+	autoText := "Auto: off"
+	if building.AutoProduction {
+		autoText = "Auto: on"
+	}
+	controls = append(controls,
+		buildingControl{rect: button(0), label: rallyText, run: func() error {
+			bp.togglePlacingRally(building)
+			return nil
+		}},
+		buildingControl{rect: button(1), label: "Clear", run: func() error {
+			return productionSys.ClearRallyPoint(building.ID)
+		}},
+		buildingControl{rect: button(2), label: autoText, run: func() error {
+			return productionSys.SetAutoProduction(building.ID, !building.AutoProduction)
+		}},
+	)
+
+	// Production queue, the unit in production first; a click cancels a
+	// unit and refunds what was not spent on it
+	queue := row(2)
+	controls = append(controls, buildingControl{rect: queue, label: "Queue", caption: true})
+	production := building.Production()
+	items := production.Queued
+	if production.Current != "" {
+		items = append([]string{production.Current}, items...)
+	}
+	for i, item := range items {
+		slot := buildingSlot(queue, i)
+		if slot.X+slot.W > queue.X+queue.W {
+			break
+		}
+		index := i
+		if production.Current != "" {
+			index-- // The unit in production
+		}
+		controls = append(controls, buildingControl{rect: slot, label: "X", icon: item, run: func() error {
+			if index < 0 {
+				return productionSys.CancelProduction(building.ID)
+			}
+			return productionSys.CancelQueuedProduction(building.ID, index)
+		}})
+	}
+
+	// Garrison slots, an occupied one ejecting its unit
+	if building.GarrisonCapacity > 0 {
+		garrison := row(3)
+		units, _ := productionSys.GetGarrisonedUnits(building.ID)
+		controls = append(controls, buildingControl{rect: garrison,
+			label: fmt.Sprintf("Garrison %d/%d", len(units), building.GarrisonCapacity), caption: true})
+		if len(units) > 0 {
+			controls = append(controls, buildingControl{rect: button(3), label: "Eject all", run: func() error {
+				return productionSys.EjectAll(building.ID)
+			}})
+		}
+		for slot := 0; slot < building.GarrisonCapacity; slot++ {
+			rect := buildingSlot(garrison, slot)
+			if rect.X+rect.W > garrison.X+garrison.W {
+				break
+			}
+			if slot >= len(units) {
+				controls = append(controls, buildingControl{rect: rect, label: "-"})
+				continue
+			}
+			unit := units[slot]
+			controls = append(controls, buildingControl{rect: rect, label: "Out", icon: unit.UnitType, run: func() error {
+				return productionSys.EjectUnit(building.ID, unit.ID)
+			}})
+		}
+	}
+	return panel, controls
+}
+
+// togglePlacingRally starts or cancels rally point placement for a building
+func (bp *BuildingPanel) togglePlacingRally(building *engine.GameBuilding) {
+	bp.mutex.Lock()
+	defer bp.mutex.Unlock()
+	if bp.placingRally == building {
+		bp.placingRally = nil
+	} else {
+		bp.placingRally = building
+	}
+}
+
+// resourceAt returns the resource node left with resources nearest to a
+// position within the gather pick radius, or nil
+func (bp *BuildingPanel) resourceAt(position engine.Vector3) *engine.ResourceNode {
+	var nearest *engine.ResourceNode
+	best := gatherPickRadius
+	for _, node := range bp.world.GetAllResourceNodes() {
+		if node.Amount <= 0 {
+			continue
+		}
+		if distance := math.Hypot(node.Position.X-position.X, node.Position.Z-position.Z); distance <= best {
+			nearest, best = node, distance
+		}
+	}
+	return nearest
+}
+
+// buildingSlot returns the i-th square slot of a row, after its caption
+func buildingSlot(row sprite.Rect, i int) sprite.Rect {
+	return sprite.Rect{
+		X: row.X + buildingCaptionWidth + float32(i)*(buildingSlotSize+buildingControlGap),
+		Y: row.Y + (row.H-buildingSlotSize)/2,
+		W: buildingSlotSize,
+		H: buildingSlotSize,
+	}
+}
+
+// rallyLabel describes where a building sends the units it produces
+func rallyLabel(building *engine.GameBuilding, placing bool) string {
+	switch {
+	case placing:
+		return "Click the map to set the rally point"
+	case building.RallyResource != nil:
+		return fmt.Sprintf("Gather: %s (%d left)", building.RallyResource.ResourceType, building.RallyResource.Amount)
+	case building.RallyPoint != nil:
+		return fmt.Sprintf("Rally: %.0f, %.0f", building.RallyPoint.X, building.RallyPoint.Z)
+	default:
+		return "Rally: none"
+	}
+}
@@ -0,0 +1,114 @@
+//go:build !js
+
+package ui
+
+import (
+	"testing"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+)
+
+// clickBuildingControl clicks the middle of the first control of the
+// selected building showing a label or icon
+func clickBuildingControl(t *testing.T, panel *BuildingPanel, name string) {
+	t.Helper()
+	_, controls := panel.layout(panel.building())
+	for _, control := range controls {
+		if control.run != nil && (control.label == name || control.icon == name) {
+			if !panel.HandleClick(control.rect.X+control.rect.W/2, control.rect.Y+control.rect.H/2) {
+				t.Fatalf("Expected the panel to take the click on %s", name)
+			}
+			return
+		}
+	}
+	t.Fatalf("No %s control on the panel", name)
+}
+
+// TestBuildingPanel tests that the building panel cancels queued units,
+// ejects garrisoned ones, toggles auto-production and places rally points
+func TestBuildingPanel(t *testing.T) {
+	world, err := engine.NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	barracksDef := data.NewSimpleUnit("barracks", 1000, 0, "stone", nil)
+	barracksDef.Unit.Parameters.Garrison = &data.UnitGarrison{Capacity: 3}
+	barracks, err := world.ObjectManager.CreateBuilding(1, "barracks", engine.Vector3{X: 10.5, Z: 10.5}, barracksDef)
+	if err != nil {
+		t.Fatalf("Failed to create building: %v", err)
+	}
+	barracks.IsBuilt = true
+	barracks.ProductionQueue = []engine.ProductionItem{
+		{ItemType: "unit", ItemName: "swordman", Cost: map[string]int{"gold": 50}},
+		{ItemType: "unit", ItemName: "archer", Cost: map[string]int{"gold": 40}},
+	}
+	guard, _ := world.ObjectManager.CreateUnit(1, "guard", engine.Vector3{X: 13.5, Z: 10.5}, data.NewSimpleUnit("guard", 100, 0, "leather", nil))
+	if err := world.GetProductionSystem().GarrisonUnit(barracks.ID, guard.ID); err != nil {
+		t.Fatalf("GarrisonUnit failed: %v", err)
+	}
+	enemyDef := data.NewSimpleUnit("barracks", 1000, 0, "stone", nil)
+	enemy, _ := world.ObjectManager.CreateBuilding(2, "barracks", engine.Vector3{X: 20.5, Z: 20.5}, enemyDef)
+	enemy.IsBuilt = true
+
+	manager := NewSimpleUIManager(world)
+	panel := NewBuildingPanel(world, 1, manager)
+	panel.height = 600
+	manager.AddPanel(panel)
+
+	if manager.HandleClick(20, 400) {
+		t.Error("Expected no panel without a selected building")
+	}
+	manager.SelectBuilding(enemy)
+	if panel.building() != nil {
+		t.Error("Expected no controls for another player's building")
+	}
+	manager.SelectBuilding(barracks)
+
+	// Queue: the archer is cancelled and refunded
+	gold := world.GetPlayer(1).Resources["gold"]
+	clickBuildingControl(t, panel, "archer")
+	if production := barracks.Production(); len(production.Queued) != 1 || production.Queued[0] != "swordman" {
+		t.Errorf("Expected only the swordman left queued, got %v", production.Queued)
+	}
+	if refunded := world.GetPlayer(1).Resources["gold"] - gold; refunded != 40 {
+		t.Errorf("Expected the archer's 40 gold refunded, got %d", refunded)
+	}
+
+	// Garrison: the guard's slot ejects it
+	clickBuildingControl(t, panel, "guard")
+	if guard.IsGarrisoned() || len(barracks.GarrisonedUnits) != 0 {
+		t.Error("Expected the guard ejected")
+	}
+
+	clickBuildingControl(t, panel, "Auto: off")
+	if !barracks.AutoProduction {
+		t.Error("Expected auto-production on")
+	}
+
+	// Rally: the button makes the next map click the rally point
+	if manager.HandleWorldClick(engine.Vector3{X: 5, Z: 5}) {
+		t.Error("Expected map clicks to select while not placing a rally point")
+	}
+	clickBuildingControl(t, panel, "Rally")
+	if !panel.IsPlacingRallyPoint() {
+		t.Fatal("Expected rally point placement")
+	}
+	if !manager.HandleWorldClick(engine.Vector3{X: 5, Z: 6}) {
+		t.Fatal("Expected the map click to place the rally point")
+	}
+	if barracks.RallyPoint == nil || barracks.RallyPoint.X != 5 || barracks.RallyPoint.Z != 6 || panel.IsPlacingRallyPoint() {
+		t.Errorf("Expected the rally point at 5, 6, got %v", barracks.RallyPoint)
+	}
+	clickBuildingControl(t, panel, "Clear")
+	if barracks.RallyPoint != nil {
+		t.Error("Expected the rally point cleared")
+	}
+
+	// Placement ends with the selection
+	clickBuildingControl(t, panel, "Rally")
+	manager.ClearSelection()
+	if manager.HandleWorldClick(engine.Vector3{X: 5, Z: 6}) || barracks.RallyPoint != nil {
+		t.Error("Expected no rally point after the building was deselected")
+	}
+}
@@ -0,0 +1,122 @@
+//go:build !js
+
+package ui
+
+import (
+	"math"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/logging"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// CameraBookmarkCount is the number of camera bookmarks (F5..F8)
+const CameraBookmarkCount = 4
+
+// cameraFollowRate is how quickly a followed unit is caught up with, per
+// second; the remaining distance shrinks by e^-rate each second
+const cameraFollowRate = 6.0
+
+// CameraControls moves the camera to bookmarks, after a followed unit and to
+// the location of the latest event
+type CameraControls struct {
+	camera *renderer.Camera
+
+	bookmarks [CameraBookmarkCount]*mgl32.Vec3 // Saved look-at points, nil when unset
+	following *engine.GameUnit                 // Unit the camera tracks, nil when not following
+	lastEvent *mgl32.Vec3                      // Location of the most recent alert, nil before any
+}
+
+// NewCameraControls creates camera controls for a camera
+func NewCameraControls(camera *renderer.Camera) *CameraControls {
+	return &CameraControls{camera: camera}
+}
+
+// SetBookmark saves the point the camera looks at in a bookmark slot
+func (cc *CameraControls) SetBookmark(slot int) bool {
+	if slot < 0 || slot >= CameraBookmarkCount {
+		return false
+	}
+	target := cc.camera.Target
+	cc.bookmarks[slot] = &target
+	logging.Infof(logging.CategoryUI, "Camera bookmark %d set", slot+1)
+	return true
+}
+
+// JumpToBookmark moves the camera to a saved bookmark; it stops following a unit
+func (cc *CameraControls) JumpToBookmark(slot int) bool {
+	if slot < 0 || slot >= CameraBookmarkCount || cc.bookmarks[slot] == nil {
+		return false
+	}
+	cc.StopFollowing()
+	cc.lookAt(*cc.bookmarks[slot])
+	return true
+}
+
+// Follow makes the camera track a unit until it dies or following stops
+func (cc *CameraControls) Follow(unit *engine.GameUnit) {
+	cc.following = unit
+	if unit != nil {
+		logging.Infof(logging.CategoryUI, "Camera following %s %d", unit.UnitType, unit.ID)
+	}
+}
+
+// StopFollowing stops tracking a unit
+func (cc *CameraControls) StopFollowing() {
+	cc.following = nil
+}
+
+// Following returns the tracked unit, or nil
+func (cc *CameraControls) Following() *engine.GameUnit {
+	return cc.following
+}
+
+// RecordEvent remembers the location of an alert for JumpToLastEvent
+func (cc *CameraControls) RecordEvent(location engine.Vector3) {
+	point := mgl32.Vec3{float32(location.X), float32(location.Y), float32(location.Z)}
+	cc.lastEvent = &point
+}
+
+// JumpToLastEvent moves the camera to the most recent alert; it stops following a unit
+func (cc *CameraControls) JumpToLastEvent() bool {
+	if cc.lastEvent == nil {
+		return false
+	}
+	cc.StopFollowing()
+	cc.lookAt(*cc.lastEvent)
+	return true
+}
+
+// JumpTo moves the camera to a point in the world; it stops following a unit
+func (cc *CameraControls) JumpTo(location engine.Vector3) {
+	cc.StopFollowing()
+	cc.lookAt(mgl32.Vec3{float32(location.X), float32(location.Y), float32(location.Z)})
+}
+
+// Update glides the camera after a followed unit
+func (cc *CameraControls) Update(deltaTime time.Duration) {
+	if cc.following == nil {
+		return
+	}
+	if !cc.following.IsAlive() {
+		cc.following = nil
+		return
+	}
+
+	position := cc.following.GetPosition()
+	unit := mgl32.Vec3{float32(position.X), float32(position.Y), float32(position.Z)}
+	remaining := unit.Sub(cc.camera.Target)
+
+	// Frame-rate independent exponential smoothing
+	step := 1 - float32(math.Exp(-cameraFollowRate*deltaTime.Seconds()))
+	cc.lookAt(cc.camera.Target.Add(remaining.Mul(step)))
+}
+
+// lookAt moves the camera to look at a point, keeping its angle and distance
+func (cc *CameraControls) lookAt(point mgl32.Vec3) {
+	offset := point.Sub(cc.camera.Target)
+	cc.camera.Move(offset.X(), offset.Y(), offset.Z())
+}
@@ -0,0 +1,139 @@
+//go:build !js
+
+package ui
+
+import (
+	"path"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/graphics/text"
+)
+
+// Command card layout, in pixels
+const (
+	commandCardColumns = 4
+	commandIconSize    = 48
+	commandIconGap     = 4
+	commandCardMargin  = 8
+	queueIconSize      = 32
+	queueProgressBar   = 4
+)
+
+// Command card colors
+var (
+	commandSlotColor   = sprite.Color{R: 0.2, G: 0.2, B: 0.25, A: 0.9}
+	queueProgressColor = sprite.Color{R: 0.3, G: 0.8, B: 0.3, A: 1}
+)
+
+// CommandCard draws the commands of the current selection as a grid of icons
+// in the bottom right corner, with the HUD sprite layer, and a selected
+// building's production queue above it
+type CommandCard struct {
+	world     *engine.World
+	playerID  int // Player whose selections show commands
+	selection *SimpleUIManager
+}
+
+// NewCommandCard creates a command card showing a player's commands for the
+// selection of a UI manager
+func NewCommandCard(world *engine.World, playerID int, selection *SimpleUIManager) *CommandCard {
+	return &CommandCard{world: world, playerID: playerID, selection: selection}
+}
+
+// Draw draws the card on the HUD; nothing is drawn without a selection, or
+// for a selection of another player's, which can only be inspected
+func (cc *CommandCard) Draw(canvas *renderer.HUDCanvas) {
+	playerID, unitDef, building := cc.selected()
+	if unitDef == nil || len(unitDef.Unit.Commands) == 0 || playerID != cc.playerID {
+		return
+	}
+	player := cc.world.GetPlayer(playerID)
+	if player == nil {
+		return
+	}
+	unitDir := path.Join("factions", player.FactionName, "units", unitDef.Name)
+
+	commands := unitDef.Unit.Commands
+	rows := (len(commands) + commandCardColumns - 1) / commandCardColumns
+	step := float32(commandIconSize + commandIconGap)
+	card := sprite.Rect{
+		W: commandCardColumns*step + commandIconGap,
+		H: float32(rows)*step + commandIconGap,
+	}
+	card.X = float32(canvas.Width) - card.W - commandCardMargin
+	card.Y = float32(canvas.Height) - card.H - commandCardMargin
+	canvas.Sprites.Fill(card, hudPanelColor)
+
+	for i, command := range commands {
+		slot := sprite.Rect{
+			X: card.X + commandIconGap + float32(i%commandCardColumns)*step,
+			Y: card.Y + commandIconGap + float32(i/commandCardColumns)*step,
+			W: commandIconSize,
+			H: commandIconSize,
+		}
+		icon := sprite.NoTexture
+		if command.Image.Path != "" {
+			icon = canvas.Texture(path.Join(unitDir, command.Image.Path))
+		}
+		if icon == sprite.NoTexture && command.ProducedUnit != nil {
+			icon = canvas.UnitIcon(player.FactionName, command.ProducedUnit.Name)
+		}
+		if icon != sprite.NoTexture {
+			canvas.Sprites.Icon(slot, icon, sprite.White)
+			continue
+		}
+		// Without an icon, the slot shows the command's name
+		canvas.Sprites.Fill(slot, commandSlotColor)
+		canvas.Text.DrawScreenText(slot.X+slot.W/2, slot.Y+slot.H/2, command.Name.Value,
+			renderer.DefaultTextSize*0.6, hudTextColor, text.AnchorCenter)
+	}
+
+	if building != nil {
+		cc.drawQueue(canvas, building, player.FactionName, card)
+	}
+}
+
+// drawQueue draws what a building is producing in a row above the card: the
+// current unit with its progress, then the queued ones
+func (cc *CommandCard) drawQueue(canvas *renderer.HUDCanvas, building *engine.GameBuilding, factionName string, card sprite.Rect) {
+	production := building.Production()
+	items := production.Queued
+	if production.Current != "" {
+		items = append([]string{production.Current}, items...)
+	}
+	for i, item := range items {
+		slot := sprite.Rect{
+			X: card.X + float32(i)*(queueIconSize+commandIconGap),
+			Y: card.Y - queueIconSize - queueProgressBar - commandIconGap,
+			W: queueIconSize,
+			H: queueIconSize,
+		}
+		if slot.X+slot.W > card.X+card.W {
+			break
+		}
+		if icon := canvas.UnitIcon(factionName, item); icon != sprite.NoTexture {
+			canvas.Sprites.Icon(slot, icon, sprite.White)
+		} else {
+			canvas.Sprites.Fill(slot, commandSlotColor)
+		}
+		if i == 0 && production.Current != "" {
+			progress := sprite.Rect{X: slot.X, Y: slot.Y + slot.H, W: slot.W * production.Progress, H: queueProgressBar}
+			canvas.Sprites.Fill(progress, queueProgressColor)
+		}
+	}
+}
+
+// selected returns the definition whose commands are shown: the selected
+// building's, or else that of the selection's active unit type
+func (cc *CommandCard) selected() (int, *data.UnitDefinition, *engine.GameBuilding) {
+	if building := cc.selection.GetSelectedBuilding(); building != nil {
+		return building.PlayerID, building.UnitDef, building
+	}
+	if units := cc.selection.ActiveUnits(); len(units) > 0 {
+		return units[0].PlayerID, units[0].UnitDef, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,106 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/graphics/text"
+	"teraglest/internal/network"
+)
+
+// connectionIndicatorWidth is the width of the indicator box in pixels
+const connectionIndicatorWidth = 320
+
+// ConnectionIndicator shows which players of a multiplayer match are
+// reconnecting or were dropped; connected players are not listed
+type ConnectionIndicator struct {
+	names    map[int]string               // Player ID -> display name
+	statuses map[int]network.PlayerStatus // Player ID -> last reported status
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewConnectionIndicator creates an indicator for the players of a launched lobby
+func NewConnectionIndicator(lobby network.LobbyState) *ConnectionIndicator {
+	indicator := &ConnectionIndicator{
+		names:    make(map[int]string),
+		statuses: make(map[int]network.PlayerStatus),
+	}
+	for _, slot := range lobby.Slots {
+		if slot.Kind == network.SlotHuman {
+			indicator.names[slot.Index+1] = slot.PlayerName
+		}
+	}
+	return indicator
+}
+
+// SetStatus records a status change; it matches the session status handler signature
+func (ci *ConnectionIndicator) SetStatus(playerID int, status network.PlayerStatus) {
+	ci.mutex.Lock()
+	defer ci.mutex.Unlock()
+
+	ci.statuses[playerID] = status
+}
+
+// Lines returns one line per player that is not connected, ordered by player ID
+func (ci *ConnectionIndicator) Lines() []string {
+	ci.mutex.Lock()
+	defer ci.mutex.Unlock()
+	return ci.linesLocked()
+}
+
+// Draw shows the players that are not connected in a box under the
+// resource bar; nothing is drawn while everyone is connected
+func (ci *ConnectionIndicator) Draw(canvas *renderer.HUDCanvas) {
+	ci.mutex.Lock()
+	lines := ci.linesLocked()
+	ci.mutex.Unlock()
+
+	if len(lines) == 0 {
+		return
+	}
+	box := sprite.Rect{
+		X: (float32(canvas.Width) - connectionIndicatorWidth) / 2,
+		Y: resourceBarHeight + commandCardMargin,
+		W: connectionIndicatorWidth,
+		H: float32(len(lines)*screenLineStep + 2*screenPadding),
+	}
+	canvas.Sprites.Fill(box, hudPanelColor)
+
+	inner := box.Inset(screenPadding)
+	for i, line := range lines {
+		canvas.Text.DrawScreenText(inner.X, inner.Y+float32(i*screenLineStep), line, renderer.DefaultTextSize, hudTextColor, text.AnchorTopLeft)
+	}
+}
+
+// linesLocked builds the indicator lines (lock must be held)
+func (ci *ConnectionIndicator) linesLocked() []string {
+	playerIDs := make([]int, 0, len(ci.statuses))
+	for playerID, status := range ci.statuses {
+		if status != network.PlayerConnected {
+			playerIDs = append(playerIDs, playerID)
+		}
+	}
+	sort.Ints(playerIDs)
+
+	lines := make([]string, 0, len(playerIDs))
+	for _, playerID := range playerIDs {
+		name := ci.names[playerID]
+		if name == "" {
+			name = fmt.Sprintf("Player %d", playerID)
+		}
+		switch ci.statuses[playerID] {
+		case network.PlayerReconnecting:
+			lines = append(lines, fmt.Sprintf("%s is reconnecting...", name))
+		case network.PlayerDropped:
+			lines = append(lines, fmt.Sprintf("%s has left the game", name))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,37 @@
+//go:build !js
+
+package ui
+
+import (
+	"testing"
+
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/network"
+)
+
+// TestConnectionIndicator tests that the indicator is drawn only while a
+// player is not connected
+func TestConnectionIndicator(t *testing.T) {
+	lobby := network.NewLobbyState("test", "megapack", 2)
+	lobby.Slots[0] = network.PlayerSlot{Index: 0, Kind: network.SlotHuman, PlayerName: "host"}
+	lobby.Slots[1] = network.PlayerSlot{Index: 1, Kind: network.SlotHuman, PlayerName: "guest"}
+	indicator := NewConnectionIndicator(*lobby)
+
+	connected := &renderer.HUDCanvas{Sprites: sprite.NewBatch(), Width: 1024, Height: 768}
+	indicator.SetStatus(2, network.PlayerConnected)
+	indicator.Draw(connected)
+	if !connected.Sprites.Empty() {
+		t.Error("Expected nothing drawn while everyone is connected")
+	}
+
+	reconnecting := &renderer.HUDCanvas{Sprites: sprite.NewBatch(), Width: 1024, Height: 768}
+	indicator.SetStatus(2, network.PlayerReconnecting)
+	indicator.Draw(reconnecting)
+	if reconnecting.Sprites.Empty() {
+		t.Fatal("Expected the indicator drawn while a player reconnects")
+	}
+	if lines := indicator.Lines(); len(lines) != 1 || lines[0] != "guest is reconnecting..." {
+		t.Errorf("Unexpected indicator lines %q", lines)
+	}
+}
@@ -0,0 +1,241 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/text"
+)
+
+// Diplomacy panel rows
+const (
+	diplomacyRowAlly = iota
+	diplomacyRowResource
+	diplomacyRowAmount
+	diplomacyRowCount
+)
+
+// diplomacyNotice is a tribute or help notice waiting to be shown or on screen
+type diplomacyNotice struct {
+	text    string
+	shownAt time.Time // Zero until first drawn
+}
+
+// DiplomacyPanel sends tribute to allies: pick the ally and the resource, set
+// the amount with a slider and confirm. It also announces tribute received.
+type DiplomacyPanel struct {
+	world    *engine.World
+	playerID int
+
+	allies    []int    // Allied player IDs, sorted
+	resources []string // Resources that can be sent, sorted
+	row       int      // Highlighted row
+	ally      int      // Index into allies
+	resource  int      // Index into resources
+	amount    int      // Slider value
+	open      bool
+	message   string            // Result of the last tribute
+	received  []diplomacyNotice // Tribute and help notices not expired yet
+	now       func() time.Time
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewDiplomacyPanel creates a diplomacy panel for a player
+func NewDiplomacyPanel(world *engine.World, playerID int) *DiplomacyPanel {
+	return &DiplomacyPanel{world: world, playerID: playerID, now: time.Now}
+}
+
+// IsOpen returns whether the panel is shown
+func (dp *DiplomacyPanel) IsOpen() bool {
+	dp.mutex.Lock()
+	defer dp.mutex.Unlock()
+	return dp.open
+}
+
+// Open shows the panel; it returns false (and stays closed) when the player has no allies
+func (dp *DiplomacyPanel) Open() bool {
+	allies := dp.world.GetAllies(dp.playerID)
+	if len(allies) == 0 {
+		return false
+	}
+
+	dp.mutex.Lock()
+	defer dp.mutex.Unlock()
+
+	dp.resources = dp.resources[:0]
+	for resource := range dp.world.GetResourceStatus(dp.playerID).Resources {
+		dp.resources = append(dp.resources, resource)
+	}
+	sort.Strings(dp.resources)
+	if len(dp.resources) == 0 {
+		return false
+	}
+	dp.allies = allies
+	if dp.ally >= len(dp.allies) {
+		dp.ally = 0
+	}
+	if dp.resource >= len(dp.resources) {
+		dp.resource = 0
+	}
+	dp.row, dp.amount, dp.message = diplomacyRowAlly, 0, ""
+	dp.open = true
+	return true
+}
+
+// Close hides the panel
+func (dp *DiplomacyPanel) Close() {
+	dp.mutex.Lock()
+	defer dp.mutex.Unlock()
+	dp.open = false
+}
+
+// HandleEvent announces tribute sent to the player and allies calling for
+// help; it returns false for other events
+func (dp *DiplomacyPanel) HandleEvent(event engine.GameEvent) bool {
+	if request, ok := event.Data.(engine.HelpRequest); ok && event.Type == engine.EventTypeHelpRequested {
+		if !dp.world.AreAllied(dp.playerID, request.PlayerID) {
+			return false
+		}
+		dp.mutex.Lock()
+		defer dp.mutex.Unlock()
+		dp.received = append(dp.received, diplomacyNotice{text: fmt.Sprintf("Player %d is under attack at (%.0f, %.0f) and asks for help",
+			request.PlayerID, request.Position.X, request.Position.Z)})
+		return true
+	}
+
+	tribute, ok := event.Data.(engine.Tribute)
+	if event.Type != engine.EventTypeTribute || !ok || tribute.ToPlayerID != dp.playerID {
+		return false
+	}
+
+	dp.mutex.Lock()
+	defer dp.mutex.Unlock()
+	dp.received = append(dp.received, diplomacyNotice{text: fmt.Sprintf("Player %d sent you %s", tribute.FromPlayerID, tribute)})
+	return true
+}
+
+// HandleKey processes a key press while the panel is open, returning true if it was consumed
+func (dp *DiplomacyPanel) HandleKey(key glfw.Key) bool {
+	dp.mutex.Lock()
+	if !dp.open {
+		dp.mutex.Unlock()
+		return false
+	}
+
+	send := false
+	switch key {
+	case glfw.KeyEscape, glfw.KeyT:
+		dp.open = false
+	case glfw.KeyUp, glfw.KeyW:
+		dp.row = (dp.row + diplomacyRowCount - 1) % diplomacyRowCount
+	case glfw.KeyDown, glfw.KeyS:
+		dp.row = (dp.row + 1) % diplomacyRowCount
+	case glfw.KeyLeft, glfw.KeyA:
+		dp.adjust(-1)
+	case glfw.KeyRight, glfw.KeyD:
+		dp.adjust(1)
+	case glfw.KeyEnter, glfw.KeyKPEnter, glfw.KeySpace:
+		send = true
+	}
+	ally, resource, amount := dp.allies[dp.ally], dp.resources[dp.resource], dp.amount
+	dp.mutex.Unlock()
+
+	// Send without the panel lock; the world takes its own locks
+	if send {
+		message := "Set an amount to send"
+		if amount > 0 {
+			if err := dp.world.SendTribute(dp.playerID, ally, map[string]int{resource: amount}); err != nil {
+				message = fmt.Sprintf("Tribute failed: %v", err)
+			} else {
+				message = fmt.Sprintf("Sent %d %s to player %d", amount, resource, ally)
+			}
+		}
+		dp.mutex.Lock()
+		dp.message, dp.amount = message, 0
+		dp.mutex.Unlock()
+	}
+
+	// The panel is modal: swallow every key while it is open
+	return true
+}
+
+// Draw shows received tribute and help calls under the resource bar for
+// DefaultNotificationDuration each, and draws the panel in the middle of the
+// HUD while it is open
+func (dp *DiplomacyPanel) Draw(canvas *renderer.HUDCanvas) {
+	dp.mutex.Lock()
+	defer dp.mutex.Unlock()
+
+	dp.drawNotices(canvas)
+	if !dp.open {
+		return
+	}
+
+	ally, resource := dp.allies[dp.ally], dp.resources[dp.resource]
+	stock := dp.world.GetResourceStatus(dp.playerID).Resources
+	allyStock := dp.world.GetResourceStatus(ally).Resources
+
+	allyName := fmt.Sprintf("Player %d", ally)
+	if player := dp.world.GetPlayer(ally); player != nil {
+		allyName = player.Name
+	}
+	lines := []screenLine{
+		{text: fmt.Sprintf("Ally:     < %s >", allyName)},
+		{text: fmt.Sprintf("Resource: < %s >  (you have %d, ally has %d)", resource, stock[resource], allyStock[resource])},
+		{text: fmt.Sprintf("Amount:   %d", dp.amount)},
+	}
+	lines[dp.row].selected = true
+	panel := drawScreen(canvas, "Diplomacy", lines, dp.message, "Up/Down to pick a row, Left/Right to change it, Enter to send, ESC to close")
+	drawSlider(canvas, panel, diplomacyRowAmount, dp.amount, stock[resource])
+}
+
+// drawNotices draws the notices received, dropping the expired ones (lock must be held)
+func (dp *DiplomacyPanel) drawNotices(canvas *renderer.HUDCanvas) {
+	now := dp.now()
+	kept := dp.received[:0]
+	for _, notice := range dp.received {
+		if notice.shownAt.IsZero() {
+			notice.shownAt = now
+		}
+		if now.Sub(notice.shownAt) < DefaultNotificationDuration {
+			kept = append(kept, notice)
+		}
+	}
+	dp.received = kept
+
+	y := float32(resourceBarHeight+commandCardMargin) + screenLineStep/2
+	for _, notice := range dp.received {
+		canvas.Text.DrawScreenText(float32(canvas.Width)/2, y, notice.text, renderer.DefaultTextSize, hudTextColor, text.AnchorCenter)
+		y += screenLineStep
+	}
+}
+
+// adjust changes the highlighted row's value (lock must be held)
+func (dp *DiplomacyPanel) adjust(delta int) {
+	switch dp.row {
+	case diplomacyRowAlly:
+		dp.ally = (dp.ally + delta + len(dp.allies)) % len(dp.allies)
+	case diplomacyRowResource:
+		dp.resource = (dp.resource + delta + len(dp.resources)) % len(dp.resources)
+		dp.amount = 0
+	case diplomacyRowAmount:
+		stock := dp.world.GetResourceStatus(dp.playerID).Resources[dp.resources[dp.resource]]
+		dp.amount += delta * marketSliderStep
+		if dp.amount > stock {
+			dp.amount = stock
+		}
+		if dp.amount < 0 {
+			dp.amount = 0
+		}
+	}
+}
@@ -0,0 +1,168 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+)
+
+// Economy panel layout
+const (
+	economyRefreshInterval = 5 * time.Second // Game time between report refreshes while open
+	economyGraphWidth      = 30              // Most trend samples drawn per resource
+	economyBarWidth        = 5               // Width of a trend sample on the HUD, in pixels
+)
+
+// economyGraphColor is the color of trend bars on the HUD
+var economyGraphColor = sprite.Color{R: 0.9, G: 0.75, B: 0.3, A: 1}
+
+// EconomyPanel shows the player's economy: per-resource income and expense
+// rates, a stockpile trend graph and the top consumers over the last few minutes
+type EconomyPanel struct {
+	world    *engine.World
+	playerID int
+
+	open       bool
+	report     *engine.EconomyReport // Report drawn; nil until the panel opens
+	reportedAt time.Duration         // Game time of the report
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewEconomyPanel creates an economy panel for a player
+func NewEconomyPanel(world *engine.World, playerID int) *EconomyPanel {
+	return &EconomyPanel{world: world, playerID: playerID}
+}
+
+// IsOpen returns whether the panel is shown
+func (ep *EconomyPanel) IsOpen() bool {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+	return ep.open
+}
+
+// Open shows the panel with a fresh report
+func (ep *EconomyPanel) Open() {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+	ep.open, ep.report = true, nil
+}
+
+// Close hides the panel
+func (ep *EconomyPanel) Close() {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+	ep.open = false
+}
+
+// HandleKey processes a key press while the panel is open, returning true if it was consumed
+func (ep *EconomyPanel) HandleKey(key glfw.Key) bool {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+	if !ep.open {
+		return false
+	}
+
+	switch key {
+	case glfw.KeyEscape, glfw.KeyE:
+		ep.open = false
+	}
+
+	// The panel is modal: swallow every key while it is open
+	return true
+}
+
+// Draw draws the panel in the middle of the HUD while it is open, with a
+// report refreshed every refresh interval of game time
+func (ep *EconomyPanel) Draw(canvas *renderer.HUDCanvas) {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+
+	if !ep.open {
+		return
+	}
+	now := ep.world.GetGameTime()
+	if ep.report == nil || now-ep.reportedAt >= economyRefreshInterval {
+		report := ep.world.GetEconomyReport(ep.playerID)
+		ep.report, ep.reportedAt = &report, now
+	}
+
+	resources := make([]string, 0, len(ep.report.Resources))
+	for resource := range ep.report.Resources {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	lines := make([]screenLine, 0, 2*len(resources))
+	rows := make([]int, len(resources)) // Line of each resource
+	for i, resource := range resources {
+		flow := ep.report.Resources[resource]
+		rows[i] = len(lines)
+		lines = append(lines, screenLine{text: fmt.Sprintf("%-8s %6d  +%.0f/min  -%.0f/min",
+			resource, flow.Stock, flow.IncomeRate, flow.ExpenseRate)})
+
+		consumers := make([]string, 0, len(flow.TopConsumers))
+		for _, consumer := range flow.TopConsumers {
+			consumers = append(consumers, fmt.Sprintf("%s %d", consumer.Source, consumer.Amount))
+		}
+		if len(consumers) > 0 {
+			lines = append(lines, screenLine{text: "         spent on: " + strings.Join(consumers, ", ")})
+		}
+	}
+	title := fmt.Sprintf("Economy (last %v)", ep.report.Window.Truncate(time.Second))
+	panel := drawScreen(canvas, title, lines, "", "E or ESC to close")
+
+	// Stockpile trends at the right of each resource's row
+	for i, resource := range resources {
+		trend := ep.report.Resources[resource].Trend
+		if len(trend) > economyGraphWidth {
+			trend = trend[len(trend)-economyGraphWidth:]
+		}
+		drawTrend(canvas, panel, rows[i], trendLevels(trend))
+	}
+}
+
+// trendLevels scales values between their lowest and highest, from 0 to 1
+func trendLevels(values []int) []float32 {
+	if len(values) == 0 {
+		return nil
+	}
+
+	low, high := values[0], values[0]
+	for _, value := range values {
+		low, high = min(low, value), max(high, value)
+	}
+
+	levels := make([]float32, len(values))
+	for i, value := range values {
+		levels[i] = 0.5 // Flat
+		if high > low {
+			levels[i] = float32(value-low) / float32(high-low)
+		}
+	}
+	return levels
+}
+
+// drawTrend draws levels as bars at the right of a row of a modal screen,
+// latest last
+func drawTrend(canvas *renderer.HUDCanvas, panel sprite.Rect, row int, levels []float32) {
+	inner := panel.Inset(screenPadding)
+	bottom := inner.Y + float32((2+row)*screenLineStep) - 4
+	x := inner.X + inner.W - float32(economyGraphWidth*economyBarWidth)
+	for _, level := range levels {
+		h := max(1, level*(screenLineStep-4))
+		canvas.Sprites.Fill(sprite.Rect{X: x, Y: bottom - h, W: economyBarWidth - 1, H: h}, economyGraphColor)
+		x += economyBarWidth
+	}
+}
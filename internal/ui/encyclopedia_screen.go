@@ -0,0 +1,284 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/data"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+)
+
+// PreviewTurnSpeed is how fast the model preview rotates, in radians per second
+const PreviewTurnSpeed = 0.8
+
+// Encyclopedia layout on the HUD; the screen keeps to the right, clear of
+// the model preview in the middle
+const (
+	encyclopediaPortraitSize = 96 // In pixels
+	encyclopediaWidth        = 380
+	encyclopediaLineChars    = 48
+	encyclopediaListLength   = 10 // Entries listed around the selected one
+)
+
+// EncyclopediaScreen browses the factions, units, buildings and upgrades of
+// the loaded tech tree with their stats, lore and a rotating model preview
+type EncyclopediaScreen struct {
+	book    *data.Encyclopedia
+	faction int // Index into book.Factions
+	kind    int // Index into data.EntryKinds
+	entry   int // Index into the entries of the faction and kind
+	open    bool
+	shownAt time.Time // When the current entry was selected; the preview turns from there
+	now     func() time.Time
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewEncyclopediaScreen creates a screen browsing an encyclopedia
+func NewEncyclopediaScreen(book *data.Encyclopedia) *EncyclopediaScreen {
+	return &EncyclopediaScreen{book: book, now: time.Now}
+}
+
+// IsOpen returns whether the screen is shown
+func (es *EncyclopediaScreen) IsOpen() bool {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+	return es.open
+}
+
+// Open shows the screen at the last viewed entry
+func (es *EncyclopediaScreen) Open() {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+	es.show()
+}
+
+// OpenEntry shows the entry of a unit, building or upgrade; it returns false
+// (and leaves the screen closed) if the encyclopedia has no such entry
+func (es *EncyclopediaScreen) OpenEntry(factionName, name string) bool {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+
+	for f, faction := range es.book.Factions {
+		if faction.Name != factionName {
+			continue
+		}
+		for k, kind := range data.EntryKinds {
+			for e, entry := range faction.EntriesOf(kind) {
+				if entry.Name == name {
+					es.faction, es.kind, es.entry = f, k, e
+					es.show()
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Close hides the screen
+func (es *EncyclopediaScreen) Close() {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+	es.open = false
+}
+
+// Current returns the entry shown, or nil if the screen is closed or the faction has no entries of the kind
+func (es *EncyclopediaScreen) Current() *data.EncyclopediaEntry {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+	if !es.open {
+		return nil
+	}
+	return es.current()
+}
+
+// DrawPortrait draws the shown entry's portrait on the HUD, below the
+// resource bar: the image it ships, else an icon rendered from its model
+func (es *EncyclopediaScreen) DrawPortrait(canvas *renderer.HUDCanvas) {
+	entry := es.Current()
+	if entry == nil {
+		return
+	}
+	icon := canvas.Texture(entry.Image)
+	if icon == sprite.NoTexture {
+		icon = canvas.ModelIcon(entry.Model)
+	}
+	if icon == sprite.NoTexture {
+		return
+	}
+	portrait := sprite.Rect{X: commandCardMargin, Y: resourceBarHeight + commandCardMargin,
+		W: encyclopediaPortraitSize, H: encyclopediaPortraitSize}
+	canvas.Sprites.Fill(portrait.Inset(-commandIconGap), hudPanelColor)
+	canvas.Sprites.Icon(portrait, icon, sprite.White)
+}
+
+// PreviewAngle returns the rotation of the model preview, in radians
+func (es *EncyclopediaScreen) PreviewAngle() float32 {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+	angle := es.now().Sub(es.shownAt).Seconds() * PreviewTurnSpeed
+	return float32(math.Mod(angle, 2*math.Pi))
+}
+
+// HandleKey processes a key press while the screen is open; it returns true if the key was used
+func (es *EncyclopediaScreen) HandleKey(key glfw.Key) bool {
+	if !es.IsOpen() {
+		return false
+	}
+
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+	switch key {
+	case glfw.KeyUp:
+		es.moveEntry(-1)
+	case glfw.KeyDown:
+		es.moveEntry(1)
+	case glfw.KeyTab:
+		es.kind = (es.kind + 1) % len(data.EntryKinds)
+		es.entry = 0
+		es.selected()
+	case glfw.KeyLeft:
+		es.moveFaction(-1)
+	case glfw.KeyRight:
+		es.moveFaction(1)
+	case glfw.KeyEscape, glfw.KeyEnter, glfw.KeyKPEnter:
+		es.open = false
+	}
+
+	// The screen is modal: swallow every key while it is open
+	return true
+}
+
+// Draw draws the entries of the faction and kind shown, with the details
+// of the selected one, at the right of the HUD while the screen is open
+func (es *EncyclopediaScreen) Draw(canvas *renderer.HUDCanvas) {
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+
+	if !es.open {
+		return
+	}
+	x := float32(canvas.Width) - encyclopediaWidth - commandCardMargin
+	hint := "Up/Down entry, Tab units/buildings/upgrades, Left/Right faction, ESC back"
+	if len(es.book.Factions) == 0 {
+		drawScreenAt(canvas, x, encyclopediaWidth, "Encyclopedia: no factions loaded", nil, "", hint)
+		return
+	}
+	faction := &es.book.Factions[es.faction]
+	kind := data.EntryKinds[es.kind]
+
+	lines := make([]screenLine, 0)
+	if faction.Lore != "" && es.entry == 0 {
+		lines = append(lines, wrappedLines(encyclopediaLineChars, faction.Lore)...)
+	}
+	entries := faction.EntriesOf(kind)
+	first := min(max(0, es.entry-encyclopediaListLength/2), max(0, len(entries)-encyclopediaListLength))
+	for i := first; i < len(entries) && i < first+encyclopediaListLength; i++ {
+		lines = append(lines, screenLine{text: entries[i].Title, selected: i == es.entry})
+	}
+	if entry := es.current(); entry != nil {
+		lines = append(lines, wrappedLines(encyclopediaLineChars, entryDetails(entry)...)...)
+	}
+	title := fmt.Sprintf("%s - %s (%s)", es.book.TechTree, faction.Title, kind)
+	drawScreenAt(canvas, x, encyclopediaWidth, title, lines, "", hint)
+}
+
+// entryDetails describes an entry: its stats, costs, requirements and lore
+func entryDetails(entry *data.EncyclopediaEntry) []string {
+	details := []string{"--- " + entry.Title + " ---"}
+	if stats := entry.Stats; stats != nil {
+		line := fmt.Sprintf("HP %d  Armor %d (%s)", stats.HP, stats.Armor, orNone(stats.ArmorType))
+		if stats.AttackSkill != "" {
+			line += fmt.Sprintf("  Attack %d %s, range %d, %.1f DPS", stats.Damage, stats.AttackType, stats.AttackRange, stats.DPS)
+		}
+		details = append(details, line)
+	}
+	if len(entry.Costs) > 0 {
+		details = append(details, fmt.Sprintf("Cost: %s  Time: %d", formatCosts(entry.Costs), entry.Time))
+	}
+	if len(entry.Requirements) > 0 {
+		details = append(details, "Requires: "+strings.Join(entry.Requirements, ", "))
+	}
+	if len(entry.Produces) > 0 {
+		details = append(details, "Produces: "+strings.Join(entry.Produces, ", "))
+	}
+	if len(entry.Affects) > 0 {
+		details = append(details, "Improves: "+strings.Join(entry.Affects, ", "))
+	}
+	if entry.Lore != "" {
+		details = append(details, entry.Lore)
+	}
+	return details
+}
+
+// formatCosts lists resource costs sorted by resource, e.g. "gold 100, wood 50"
+func formatCosts(costs map[string]int) string {
+	resources := make([]string, 0, len(costs))
+	for resource := range costs {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	parts := make([]string, len(resources))
+	for i, resource := range resources {
+		parts[i] = fmt.Sprintf("%s %d", resource, costs[resource])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// show opens the screen (lock must be held)
+func (es *EncyclopediaScreen) show() {
+	es.open = true
+	es.selected()
+}
+
+// selected restarts the preview after the entry changed (lock must be held)
+func (es *EncyclopediaScreen) selected() {
+	es.shownAt = es.now()
+}
+
+// moveEntry selects the previous or next entry, wrapping around (lock must be held)
+func (es *EncyclopediaScreen) moveEntry(delta int) {
+	if len(es.book.Factions) == 0 {
+		return
+	}
+	count := len(es.book.Factions[es.faction].EntriesOf(data.EntryKinds[es.kind]))
+	if count == 0 {
+		return
+	}
+	es.entry = (es.entry + delta + count) % count
+	es.selected()
+}
+
+// moveFaction selects the previous or next faction, wrapping around (lock must be held)
+func (es *EncyclopediaScreen) moveFaction(delta int) {
+	count := len(es.book.Factions)
+	if count == 0 {
+		return
+	}
+	es.faction = (es.faction + delta + count) % count
+	es.entry = 0
+	es.selected()
+}
+
+// current returns the selected entry, or nil (lock must be held)
+func (es *EncyclopediaScreen) current() *data.EncyclopediaEntry {
+	if es.faction >= len(es.book.Factions) {
+		return nil
+	}
+	entries := es.book.Factions[es.faction].EntriesOf(data.EntryKinds[es.kind])
+	if es.entry >= len(entries) {
+		return nil
+	}
+	return &entries[es.entry]
+}
@@ -0,0 +1,291 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/logging"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// GamepadMapping configures how a controller drives the game
+type GamepadMapping struct {
+	Deadzone    float32 // Stick deflection ignored as drift, 0-1
+	CursorSpeed float32 // Cursor pixels per second at full deflection of the left stick
+	CameraSpeed float32 // Camera world units per second at full deflection of the right stick
+	ZoomSpeed   float32 // Camera zoom per second with a trigger fully pulled
+
+	Select      glfw.GamepadButton // Left click: select, or drag a selection box while held
+	Command     glfw.GamepadButton // Right click: move, attack, gather or repair
+	Modifier    glfw.GamepadButton // Held like shift, to add to the selection or queue orders
+	RadialMenu  glfw.GamepadButton // Held to open the radial command menu, aimed with the right stick
+	Pause       glfw.GamepadButton // Opens the pause menu
+	JumpToEvent glfw.GamepadButton // Jumps the camera to the latest alert
+}
+
+// DefaultGamepadMapping returns the standard controller layout: sticks move
+// the cursor and camera, triggers zoom, A selects and B commands
+func DefaultGamepadMapping() GamepadMapping {
+	return GamepadMapping{
+		Deadzone:    0.2,
+		CursorSpeed: 900,
+		CameraSpeed: 25,
+		ZoomSpeed:   30,
+		Select:      glfw.ButtonA,
+		Command:     glfw.ButtonB,
+		Modifier:    glfw.ButtonRightBumper,
+		RadialMenu:  glfw.ButtonLeftBumper,
+		Pause:       glfw.ButtonStart,
+		JumpToEvent: glfw.ButtonBack,
+	}
+}
+
+// RadialCommand is an entry of the radial command menu; it is issued as its
+// keyboard shortcut so both inputs stay in step
+type RadialCommand struct {
+	Name string
+	Key  glfw.Key
+	Mods glfw.ModifierKey
+}
+
+// radialCommands are the radial menu entries, clockwise from the top
+var radialCommands = []RadialCommand{
+	{Name: "Stop", Key: glfw.KeyS},
+	{Name: "Hold", Key: glfw.KeyH},
+	{Name: "Follow", Key: glfw.KeyF},
+	{Name: "Info", Key: glfw.KeyI},
+	{Name: "Group", Key: glfw.KeyG},
+	{Name: "Select all", Key: glfw.KeyA, Mods: glfw.ModControl},
+}
+
+// radialAimThreshold is the right stick deflection that picks a radial menu entry
+const radialAimThreshold = 0.5
+
+// GamepadController drives the input handler from the first connected
+// gamepad: it emulates the mouse cursor and buttons, pans and zooms the
+// camera and offers the unit commands in a radial menu
+type GamepadController struct {
+	mapping GamepadMapping
+	window  *glfw.Window
+	input   *InputHandler
+	camera  *renderer.Camera
+
+	joystick  glfw.Joystick     // Gamepad in use, -1 when none is connected
+	connected string            // Name of the gamepad in use
+	previous  glfw.GamepadState // State at the last update, to detect presses
+	radial    bool              // Whether the radial menu is open
+	choice    int               // Aimed radial entry, -1 for none
+	dirty     bool              // Radial menu needs to be redrawn
+}
+
+// NewGamepadController creates a controller layer feeding an input handler
+func NewGamepadController(window *glfw.Window, input *InputHandler, camera *renderer.Camera, mapping GamepadMapping) *GamepadController {
+	return &GamepadController{
+		mapping:  mapping,
+		window:   window,
+		input:    input,
+		camera:   camera,
+		joystick: -1,
+		choice:   -1,
+	}
+}
+
+// Update polls the gamepad and applies its input; call it once per frame after polling events
+func (gc *GamepadController) Update(deltaTime time.Duration) {
+	state := gc.poll()
+	if state == nil {
+		return
+	}
+	defer func() { gc.previous = *state }()
+
+	dt := float32(deltaTime.Seconds())
+	mods := glfw.ModifierKey(0)
+	if state.Buttons[gc.mapping.Modifier] == glfw.Press {
+		mods |= glfw.ModShift
+	}
+
+	// Open menus and screens take the D-pad and face buttons as keys
+	if gc.input.menuOpen() {
+		gc.closeRadialMenu(false)
+		for button, key := range map[glfw.GamepadButton]glfw.Key{
+			glfw.ButtonDpadUp: glfw.KeyUp, glfw.ButtonDpadDown: glfw.KeyDown,
+			glfw.ButtonDpadLeft: glfw.KeyLeft, glfw.ButtonDpadRight: glfw.KeyRight,
+			gc.mapping.Select: glfw.KeyEnter, gc.mapping.Command: glfw.KeyEscape, gc.mapping.Pause: glfw.KeyEscape,
+		} {
+			if gc.pressed(state, button) {
+				gc.input.HandleKeyboard(gc.window, key, 0, glfw.Press, 0)
+			}
+		}
+		return
+	}
+
+	gc.moveCursor(state, dt)
+	gc.clickButton(state, gc.mapping.Select, glfw.MouseButtonLeft, mods)
+	gc.clickButton(state, gc.mapping.Command, glfw.MouseButtonRight, mods)
+
+	if gc.pressed(state, gc.mapping.Pause) {
+		gc.input.HandleKeyboard(gc.window, glfw.KeyEscape, 0, glfw.Press, 0)
+	}
+	if gc.pressed(state, gc.mapping.JumpToEvent) {
+		gc.input.HandleKeyboard(gc.window, glfw.KeySpace, 0, glfw.Press, 0)
+	}
+
+	// The D-pad jumps to the camera bookmarks, or sets them with the modifier held
+	bookmarkMods := glfw.ModifierKey(0)
+	if mods&glfw.ModShift != 0 {
+		bookmarkMods = glfw.ModControl
+	}
+	for i, button := range []glfw.GamepadButton{glfw.ButtonDpadUp, glfw.ButtonDpadRight, glfw.ButtonDpadDown, glfw.ButtonDpadLeft} {
+		if gc.pressed(state, button) {
+			gc.input.HandleKeyboard(gc.window, glfw.KeyF5+glfw.Key(i), 0, glfw.Press, bookmarkMods)
+		}
+	}
+
+	// The right stick aims the radial menu while it is held, and pans the camera otherwise
+	rightX := gc.deadzone(state.Axes[glfw.AxisRightX])
+	rightY := gc.deadzone(state.Axes[glfw.AxisRightY])
+	if state.Buttons[gc.mapping.RadialMenu] == glfw.Press {
+		gc.aimRadialMenu(state.Axes[glfw.AxisRightX], state.Axes[glfw.AxisRightY])
+	} else {
+		gc.closeRadialMenu(true)
+		if gc.camera != nil && (rightX != 0 || rightY != 0) {
+			gc.camera.Move(rightX*gc.mapping.CameraSpeed*dt, 0, rightY*gc.mapping.CameraSpeed*dt)
+		}
+	}
+
+	// Triggers rest at -1; the right one zooms in and the left one out
+	zoom := (state.Axes[glfw.AxisRightTrigger] - state.Axes[glfw.AxisLeftTrigger]) / 2
+	if gc.camera != nil && math.Abs(float64(zoom)) > float64(gc.mapping.Deadzone) {
+		gc.camera.Zoom(zoom * gc.mapping.ZoomSpeed * dt)
+	}
+}
+
+// Render shows the radial menu when it has changed (console output until text rendering exists)
+func (gc *GamepadController) Render() {
+	if !gc.dirty {
+		return
+	}
+	gc.dirty = false
+	if !gc.radial {
+		return
+	}
+
+	entries := make([]string, len(radialCommands))
+	for i, command := range radialCommands {
+		if i == gc.choice {
+			entries[i] = "[" + command.Name + "]"
+		} else {
+			entries[i] = command.Name
+		}
+	}
+	fmt.Println("Commands: " + strings.Join(entries, "  "))
+}
+
+// poll returns the state of the gamepad in use, picking the first connected
+// one when there is none; it returns nil without a gamepad
+func (gc *GamepadController) poll() *glfw.GamepadState {
+	if gc.joystick >= 0 && gc.joystick.IsGamepad() {
+		return gc.joystick.GetGamepadState()
+	}
+	if gc.joystick >= 0 {
+		logging.Infof(logging.CategoryUI, "Gamepad disconnected: %s", gc.connected)
+		gc.joystick, gc.previous = -1, glfw.GamepadState{}
+		gc.closeRadialMenu(false)
+	}
+
+	for joystick := glfw.Joystick1; joystick <= glfw.JoystickLast; joystick++ {
+		if joystick.IsGamepad() {
+			gc.joystick, gc.connected = joystick, joystick.GetGamepadName()
+			logging.Infof(logging.CategoryUI, "Gamepad connected: %s", gc.connected)
+			return joystick.GetGamepadState()
+		}
+	}
+	return nil
+}
+
+// pressed reports whether a button went down since the last update
+func (gc *GamepadController) pressed(state *glfw.GamepadState, button glfw.GamepadButton) bool {
+	return state.Buttons[button] == glfw.Press && gc.previous.Buttons[button] != glfw.Press
+}
+
+// clickButton turns a gamepad button's changes into mouse button presses and releases
+func (gc *GamepadController) clickButton(state *glfw.GamepadState, button glfw.GamepadButton, mouse glfw.MouseButton, mods glfw.ModifierKey) {
+	switch action := state.Buttons[button]; {
+	case action == glfw.Press && gc.previous.Buttons[button] != glfw.Press:
+		gc.input.HandleMouseButton(gc.window, mouse, glfw.Press, mods)
+	case action != glfw.Press && gc.previous.Buttons[button] == glfw.Press:
+		gc.input.HandleMouseButton(gc.window, mouse, glfw.Release, mods)
+	}
+}
+
+// moveCursor moves the mouse cursor with the left stick, starting from where
+// the mouse left it so both can be used together
+func (gc *GamepadController) moveCursor(state *glfw.GamepadState, dt float32) {
+	x := gc.deadzone(state.Axes[glfw.AxisLeftX])
+	y := gc.deadzone(state.Axes[glfw.AxisLeftY])
+	if x == 0 && y == 0 {
+		return
+	}
+
+	width, height := gc.window.GetSize()
+	cursorX, cursorY := gc.window.GetCursorPos()
+	cursorX = math.Max(0, math.Min(float64(width-1), cursorX+float64(x*gc.mapping.CursorSpeed*dt)))
+	cursorY = math.Max(0, math.Min(float64(height-1), cursorY+float64(y*gc.mapping.CursorSpeed*dt)))
+	gc.window.SetCursorPos(cursorX, cursorY)
+	gc.input.HandleMouseMove(gc.window, cursorX, cursorY)
+}
+
+// aimRadialMenu opens the radial menu and picks the entry the stick points at
+func (gc *GamepadController) aimRadialMenu(x, y float32) {
+	if !gc.radial {
+		gc.radial, gc.choice, gc.dirty = true, -1, true
+	}
+	choice := radialChoice(x, y, len(radialCommands))
+	if choice != gc.choice {
+		gc.choice, gc.dirty = choice, true
+	}
+}
+
+// closeRadialMenu closes the radial menu, issuing the aimed command if asked to
+func (gc *GamepadController) closeRadialMenu(issue bool) {
+	if !gc.radial {
+		return
+	}
+	if issue && gc.choice >= 0 {
+		command := radialCommands[gc.choice]
+		logging.Debugf(logging.CategoryUI, "Radial menu: %s", command.Name)
+		gc.input.HandleKeyboard(gc.window, command.Key, 0, glfw.Press, command.Mods)
+	}
+	gc.radial, gc.choice, gc.dirty = false, -1, true
+}
+
+// deadzone zeroes small stick deflections and rescales the rest to start at zero
+func (gc *GamepadController) deadzone(value float32) float32 {
+	magnitude := float32(math.Abs(float64(value)))
+	if magnitude < gc.mapping.Deadzone {
+		return 0
+	}
+	scaled := (magnitude - gc.mapping.Deadzone) / (1 - gc.mapping.Deadzone)
+	return float32(math.Copysign(float64(scaled), float64(value)))
+}
+
+// radialChoice returns the entry of a radial menu with count entries that a
+// stick points at, clockwise from the top, or -1 when it is barely deflected
+func radialChoice(x, y float32, count int) int {
+	if math.Hypot(float64(x), float64(y)) < radialAimThreshold || count == 0 {
+		return -1
+	}
+	// Stick Y grows downwards; measure clockwise from straight up
+	angle := math.Atan2(float64(x), float64(-y))
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	sector := 2 * math.Pi / float64(count)
+	return int(math.Floor((angle+sector/2)/sector)) % count
+}
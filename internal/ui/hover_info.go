@@ -0,0 +1,129 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/graphics/text"
+)
+
+// Hover popup layout, in pixels
+const (
+	hoverPopupWidth   = 170
+	hoverPopupHeight  = 50
+	hoverPopupOffset  = 18 // From the cursor, down and right
+	hoverPopupPadding = 6
+	healthBarHeight   = 5
+)
+
+// Hover highlight rings, in tiles
+const (
+	hoverUnitMargin  = 0.1 // Around a unit's body
+	hoverRingMargin  = 0.3 // Around a building's footprint
+	hoverRingOpacity = 0.5
+)
+
+// Health bar colors
+var (
+	healthBarBackColor = sprite.Color{R: 0.3, G: 0.05, B: 0.05, A: 0.9}
+	healthBarColor     = sprite.Color{R: 0.2, G: 0.85, B: 0.2, A: 1}
+)
+
+// HoverInfo rings the unit or building under the cursor and shows a compact
+// popup beside the cursor with its name, owner and health, as far as the
+// player knows them
+type HoverInfo struct {
+	world    *engine.World
+	playerID int
+	input    *InputHandler
+}
+
+// NewHoverInfo creates the hover popup of a player for the objects an input
+// handler finds under the cursor
+func NewHoverInfo(world *engine.World, playerID int, input *InputHandler) *HoverInfo {
+	return &HoverInfo{world: world, playerID: playerID, input: input}
+}
+
+// hovered returns what the player knows of the object under the cursor, its
+// position and the radius of its ring; false when there is none or the
+// player knows nothing of it
+func (hi *HoverInfo) hovered() (engine.ObjectInfo, engine.Vector3, float32, bool) {
+	unit, building, _, _ := hi.input.Hovered()
+	var info engine.ObjectInfo
+	var position engine.Vector3
+	var radius float32
+	switch {
+	case unit != nil && unit.IsAlive():
+		info, position = hi.world.UnitInfo(hi.playerID, unit), unit.GetPosition()
+		radius = float32(unit.CollisionRadius()) + hoverUnitMargin
+	case building != nil && building.IsAlive():
+		info, position = hi.world.BuildingInfo(hi.playerID, building), building.GetPosition()
+		if building.UnitDef != nil && building.UnitDef.Unit.Parameters.Size.Value > 0 {
+			radius = float32(building.UnitDef.Unit.Parameters.Size.Value)/2 + hoverRingMargin
+		}
+	default:
+		return engine.ObjectInfo{}, engine.Vector3{}, 0, false
+	}
+	return info, position, radius, info.Knowledge != engine.KnowledgeNone
+}
+
+// Highlight rings the object under the cursor in its owner's color in the
+// next frame
+func (hi *HoverInfo) Highlight(markers *renderer.CommandMarkerRenderer) {
+	if info, position, radius, known := hi.hovered(); known {
+		markers.Highlight(position, radius, graphics.TeamColor(info.PlayerID).Vec4(hoverRingOpacity))
+	}
+}
+
+// Draw draws the popup beside the cursor on the HUD
+func (hi *HoverInfo) Draw(canvas *renderer.HUDCanvas) {
+	info, _, _, known := hi.hovered()
+	if !known {
+		return
+	}
+	_, _, x, y := hi.input.Hovered()
+
+	popup := sprite.Rect{X: float32(x) + hoverPopupOffset, Y: float32(y) + hoverPopupOffset, W: hoverPopupWidth, H: hoverPopupHeight}
+	popup.X = min(popup.X, float32(canvas.Width)-popup.W)
+	popup.Y = min(popup.Y, float32(canvas.Height)-popup.H)
+	canvas.Sprites.Fill(popup, hudPanelColor)
+
+	inner := popup.Inset(hoverPopupPadding)
+	canvas.Text.DrawScreenText(inner.X, inner.Y, data.DisplayName(info.Name),
+		renderer.DefaultTextSize, hudTextColor, text.AnchorTopLeft)
+	canvas.Text.DrawScreenText(inner.X, inner.Y+renderer.DefaultTextSize+2, ownerLabel(info),
+		renderer.DefaultTextSize*0.8, graphics.TeamColor(info.PlayerID).Vec4(1), text.AnchorTopLeft)
+	drawHealthBar(canvas, sprite.Rect{X: inner.X, Y: inner.Y + inner.H - healthBarHeight, W: inner.W, H: healthBarHeight}, info)
+}
+
+// ownerLabel names an object's owner and how it stands to the player
+func ownerLabel(info engine.ObjectInfo) string {
+	owner := info.Owner
+	if owner == "" {
+		owner = fmt.Sprintf("Player %d", info.PlayerID)
+	}
+	switch {
+	case info.Controlled:
+		return owner
+	case info.Knowledge == engine.KnowledgeFull:
+		return owner + " (ally)"
+	default:
+		return owner + " (enemy)"
+	}
+}
+
+// drawHealthBar fills a bar by an object's share of health left
+func drawHealthBar(canvas *renderer.HUDCanvas, bar sprite.Rect, info engine.ObjectInfo) {
+	if info.MaxHealth <= 0 {
+		return
+	}
+	canvas.Sprites.Fill(bar, healthBarBackColor)
+	bar.W *= float32(max(0, info.Health)) / float32(info.MaxHealth)
+	canvas.Sprites.Fill(bar, healthBarColor)
+}
@@ -1,15 +1,24 @@
+//go:build !js
+
 package ui
 
 import (
-	"fmt"
 	"math"
+	"sort"
+	"time"
 
 	"teraglest/internal/engine"
 	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/logging"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/go-gl/mathgl/mgl32"
 )
 
+// doubleClickInterval is the longest time between two clicks on a unit that
+// still makes a double-click
+const doubleClickInterval = 400 * time.Millisecond
+
 // InputHandler manages game input events for unit selection and commands
 type InputHandler struct {
 	world     *engine.World
@@ -23,8 +32,10 @@ type InputHandler struct {
 	dragStartY   float64
 
 	// Selection state
-	selectionBox SelectionBox
-	isSelecting  bool
+	selectionBox  SelectionBox
+	isSelecting   bool
+	lastClickUnit *engine.GameUnit // Unit of the previous click, for double-clicks
+	lastClickTime time.Time
 
 	// Camera reference for world coordinate conversion
 	camera *renderer.Camera
@@ -32,8 +43,81 @@ type InputHandler struct {
 	// Screen dimensions for coordinate conversion
 	screenWidth  int
 	screenHeight int
+
+	// Pause menu (optional; ESC quits when not set)
+	pauseMenu *PauseMenu
+
+	// Profile screen opened from the pause menu (optional)
+	profileScreen *ProfileScreen
+
+	// Options panel opened from the pause menu (optional)
+	optionsPanel *OptionsPanel
+
+	// Savegame and replay browser opened from the pause menu (optional)
+	saveBrowser *SaveGameBrowser
+
+	// Encyclopedia opened from the pause menu or for the selection (optional)
+	encyclopedia *EncyclopediaScreen
+
+	// Market trading panel opened with M (optional)
+	marketPanel *MarketPanel
+
+	// Diplomacy panel for sending tribute to allies, opened with T (optional)
+	diplomacyPanel *DiplomacyPanel
+
+	// Economy dashboard opened with E (optional)
+	economyPanel *EconomyPanel
+
+	// Multiplayer lobby shown over the paused game until the match launches (optional)
+	lobbyScreen *LobbyScreen
+
+	// Observer panel opened with O, set for spectators and once the match is decided (optional)
+	observerPanel *ObserverPanel
+
+	// Receives the player actions the input produced, e.g. for tutorials (optional)
+	actionHandler func(action string)
+
+	// Camera bookmarks, unit following and event jumps (optional)
+	cameraControls *CameraControls
+
+	// Pauses or resumes the game on P, without opening the pause menu (optional)
+	pauseToggle func()
+
+	// Markers confirming right-click orders (optional)
+	commandMarkers *renderer.CommandMarkerRenderer
+
+	// Objects drawn under the cursor, from the GPU picking pass (optional)
+	picking *renderer.PickingRenderer
+
+	// Cursor shown while hovering, and the standard cursors created for it
+	cursor  glfw.StandardCursor
+	cursors map[glfw.StandardCursor]*glfw.Cursor
+
+	// Unit or building under the cursor, nil when none
+	hoveredUnit     *engine.GameUnit
+	hoveredBuilding *engine.GameBuilding
+
+	// Wall placement started with B: the next left drag lays a row of this
+	// building type with the selected workers ("" = not placing)
+	wallPlacement string
+	wallStart     *engine.Vector3
 }
 
+// Player actions reported to the action handler
+const (
+	ActionSelectUnits    = "select_units"
+	ActionSelectBuilding = "select_building"
+	ActionMove           = "move"
+	ActionAttack         = "attack"
+	ActionGather         = "gather"
+	ActionRepair         = "repair"
+	ActionStaff          = "staff"
+	ActionBuildWall      = "build_wall"
+	ActionHold           = "hold"
+	ActionStop           = "stop"
+	ActionPauseMenu      = "pause_menu"
+)
+
 // SelectionBox represents a selection rectangle
 type SelectionBox struct {
 	StartX, StartY float64
@@ -46,6 +130,7 @@ func NewInputHandler(world *engine.World, uiManager *SimpleUIManager) *InputHand
 	return &InputHandler{
 		world:     world,
 		uiManager: uiManager,
+		cursor:    glfw.ArrowCursor,
 	}
 }
 
@@ -60,6 +145,89 @@ func (ih *InputHandler) SetScreenDimensions(width, height int) {
 	ih.screenHeight = height
 }
 
+// SetPauseMenu sets the pause menu opened by ESC
+func (ih *InputHandler) SetPauseMenu(pauseMenu *PauseMenu) {
+	ih.pauseMenu = pauseMenu
+}
+
+// SetProfileScreen sets the profile screen, which takes the keys while open
+func (ih *InputHandler) SetProfileScreen(profileScreen *ProfileScreen) {
+	ih.profileScreen = profileScreen
+}
+
+// SetOptionsPanel sets the options panel, which takes the keys while open
+func (ih *InputHandler) SetOptionsPanel(panel *OptionsPanel) {
+	ih.optionsPanel = panel
+}
+
+// SetSaveGameBrowser sets the savegame and replay browser opened from the pause menu
+func (ih *InputHandler) SetSaveGameBrowser(browser *SaveGameBrowser) {
+	ih.saveBrowser = browser
+}
+
+// SetEncyclopediaScreen sets the encyclopedia, which takes the keys while open
+func (ih *InputHandler) SetEncyclopediaScreen(encyclopedia *EncyclopediaScreen) {
+	ih.encyclopedia = encyclopedia
+}
+
+// SetMarketPanel sets the market panel, which takes the keys while open
+func (ih *InputHandler) SetMarketPanel(panel *MarketPanel) {
+	ih.marketPanel = panel
+}
+
+// SetDiplomacyPanel sets the diplomacy panel, which takes the keys while open
+func (ih *InputHandler) SetDiplomacyPanel(panel *DiplomacyPanel) {
+	ih.diplomacyPanel = panel
+}
+
+// SetEconomyPanel sets the economy panel, which takes the keys while open
+func (ih *InputHandler) SetEconomyPanel(panel *EconomyPanel) {
+	ih.economyPanel = panel
+}
+
+// SetLobbyScreen sets the multiplayer lobby, which takes the keys while open
+func (ih *InputHandler) SetLobbyScreen(screen *LobbyScreen) {
+	ih.lobbyScreen = screen
+}
+
+// SetObserverPanel sets the observer panel, which takes the keys while open
+func (ih *InputHandler) SetObserverPanel(panel *ObserverPanel) {
+	ih.observerPanel = panel
+}
+
+// SetCameraControls sets the camera controls driven by F5..F8, F and Space
+func (ih *InputHandler) SetCameraControls(controls *CameraControls) {
+	ih.cameraControls = controls
+}
+
+// SetPauseToggle sets the function pausing or resuming the game on P
+func (ih *InputHandler) SetPauseToggle(toggle func()) {
+	ih.pauseToggle = toggle
+}
+
+// SetCommandMarkers sets the renderer marking where right-click orders go
+func (ih *InputHandler) SetCommandMarkers(markers *renderer.CommandMarkerRenderer) {
+	ih.commandMarkers = markers
+}
+
+// SetPicking picks units and buildings by what is drawn under the cursor;
+// without it, or where it has not drawn, they are found near the ground point
+func (ih *InputHandler) SetPicking(picking *renderer.PickingRenderer) {
+	ih.picking = picking
+}
+
+// SetActionHandler sets the function told about each player action (see the Action* constants)
+func (ih *InputHandler) SetActionHandler(handler func(action string)) {
+	ih.actionHandler = handler
+}
+
+// reportAction passes a player action to the action handler, if any
+func (ih *InputHandler) reportAction(action string) {
+	if ih.actionHandler != nil {
+		ih.actionHandler(action)
+	}
+}
+
 // getCurrentPlayerID returns the current player's ID (for now, assumes player 1)
 func (ih *InputHandler) getCurrentPlayerID() int {
 	// TODO: In a full multiplayer implementation, this would determine
@@ -102,30 +270,112 @@ func (ih *InputHandler) deleteSelectedUnits() {
 	ih.uiManager.ClearSelection() // Clear selection
 }
 
-// groupSelectedUnits groups selected units (placeholder for future group management)
+// groupSelectedUnits forms the selected units into an army group that moves in formation
 func (ih *InputHandler) groupSelectedUnits() {
 	selectedUnits := ih.uiManager.GetSelectedUnits()
-	if len(selectedUnits) > 0 {
-		fmt.Printf("Grouped %d units\n", len(selectedUnits))
-		// TODO: Implement actual group management
+	if len(selectedUnits) == 0 {
+		return
 	}
+	group, err := ih.world.GetGroupManager().CreateGroup(ih.getCurrentPlayerID(), selectedUnits, engine.FormationLine)
+	if err != nil {
+		logging.Warnf(logging.CategoryUI, "Failed to group units: %v", err)
+		return
+	}
+	logging.Infof(logging.CategoryUI, "Army group %d formed from %d units", group.ID, len(selectedUnits))
+}
+
+// useControlGroup selects a control group, or with Ctrl assigns the
+// selection to it and with Shift adds the selection to it. With Alt and a
+// building selected, the building's produced units join the group (Alt+0
+// stops that).
+func (ih *InputHandler) useControlGroup(group int, mods glfw.ModifierKey) {
+	playerID := ih.getCurrentPlayerID()
+	groups := ih.world.GetControlGroups()
+	switch {
+	case (mods & glfw.ModAlt) != 0:
+		building := ih.uiManager.GetSelectedBuilding()
+		if building == nil || building.PlayerID != playerID {
+			return
+		}
+		if err := ih.world.GetProductionSystem().SetProductionGroup(building.ID, group, building.JoinArmyGroup); err != nil {
+			logging.Warnf(logging.CategoryUI, "Failed to set production group: %v", err)
+		} else if group == 0 {
+			logging.Infof(logging.CategoryUI, "%s units no longer join a control group", building.BuildingType)
+		} else {
+			logging.Infof(logging.CategoryUI, "%s units join control group %d", building.BuildingType, group)
+		}
+	case group == 0:
+		return
+	case (mods & glfw.ModControl) != 0:
+		if err := groups.Assign(playerID, group, ih.uiManager.GetSelectedUnits()); err == nil {
+			logging.Infof(logging.CategoryUI, "Control group %d set", group)
+		}
+	case (mods & glfw.ModShift) != 0:
+		if err := groups.Add(playerID, group, ih.uiManager.GetSelectedUnits()...); err == nil {
+			logging.Infof(logging.CategoryUI, "Added to control group %d", group)
+		}
+	default:
+		if units := groups.Units(ih.world.ObjectManager, playerID, group); len(units) > 0 {
+			ih.uiManager.SelectUnits(units)
+			ih.reportAction(ActionSelectUnits)
+		}
+	}
+}
+
+// cycleProductionArmyGroup makes the selected building's produced units join
+// the player's next army group, or none after the last one
+func (ih *InputHandler) cycleProductionArmyGroup() {
+	building := ih.uiManager.GetSelectedBuilding()
+	if building == nil || building.PlayerID != ih.getCurrentPlayerID() {
+		return
+	}
+	var ids []int
+	for _, group := range ih.world.GetGroupManager().GetPlayerGroups(building.PlayerID) {
+		ids = append(ids, group.ID)
+	}
+	sort.Ints(ids)
+
+	next := 0
+	for _, id := range ids {
+		if id > building.JoinArmyGroup {
+			next = id
+			break
+		}
+	}
+	if err := ih.world.GetProductionSystem().SetProductionGroup(building.ID, building.JoinControlGroup, next); err != nil {
+		logging.Warnf(logging.CategoryUI, "Failed to set production group: %v", err)
+	} else if next == 0 {
+		logging.Infof(logging.CategoryUI, "%s units no longer join an army group", building.BuildingType)
+	} else {
+		logging.Infof(logging.CategoryUI, "%s units join army group %d", building.BuildingType, next)
+	}
+}
+
+// ownSelection returns the selected units when they are the player's own, and
+// nil for a selected enemy, which is only inspected and never ordered
+func (ih *InputHandler) ownSelection() []*engine.GameUnit {
+	selectedUnits := ih.uiManager.GetSelectedUnits()
+	if len(selectedUnits) == 0 || selectedUnits[0].PlayerID != ih.getCurrentPlayerID() {
+		return nil
+	}
+	return selectedUnits
 }
 
 // issueHoldCommand makes selected units hold their position
 func (ih *InputHandler) issueHoldCommand() {
-	selectedUnits := ih.uiManager.GetSelectedUnits()
-	if len(selectedUnits) > 0 {
+	if len(ih.ownSelection()) > 0 {
 		params := map[string]interface{}{}
 		ih.uiManager.IssueCommand(engine.CommandHold, params)
+		ih.reportAction(ActionHold)
 	}
 }
 
 // issueStopCommand makes selected units stop their current action
 func (ih *InputHandler) issueStopCommand() {
-	selectedUnits := ih.uiManager.GetSelectedUnits()
-	if len(selectedUnits) > 0 {
+	if len(ih.ownSelection()) > 0 {
 		params := map[string]interface{}{}
 		ih.uiManager.IssueCommand(engine.CommandStop, params)
+		ih.reportAction(ActionStop)
 	}
 }
 
@@ -136,10 +386,40 @@ func (ih *InputHandler) HandleMouseButton(window *glfw.Window, button glfw.Mouse
 		return
 	}
 
+	// The pause menu is modal
+	if ih.pauseMenu != nil && ih.pauseMenu.IsOpen() {
+		return
+	}
+	if ih.saveBrowser != nil && ih.saveBrowser.IsOpen() {
+		return
+	}
+	if ih.encyclopedia != nil && ih.encyclopedia.IsOpen() {
+		return
+	}
+	if ih.marketPanel != nil && ih.marketPanel.IsOpen() {
+		return
+	}
+	if ih.diplomacyPanel != nil && ih.diplomacyPanel.IsOpen() {
+		return
+	}
+	if ih.economyPanel != nil && ih.economyPanel.IsOpen() {
+		return
+	}
+	if ih.observerPanel != nil && ih.observerPanel.IsOpen() {
+		return
+	}
+	if ih.lobbyScreen != nil && ih.lobbyScreen.IsOpen() {
+		return
+	}
+
 	xpos, ypos := window.GetCursorPos()
 
 	switch button {
 	case glfw.MouseButtonLeft:
+		// Clicks on HUD panels don't reach the world
+		if action == glfw.Press && ih.uiManager.HandleClick(xpos, ypos) {
+			return
+		}
 		if action == glfw.Press {
 			ih.handleLeftMousePress(xpos, ypos, mods)
 		} else if action == glfw.Release {
@@ -164,19 +444,167 @@ func (ih *InputHandler) HandleMouseMove(window *glfw.Window, xpos, ypos float64)
 		ih.selectionBox.EndY = ypos
 		ih.selectionBox.Active = true
 	}
+
+	// Track what is under the cursor and show what a right click would order
+	ih.picking.SetCursor(int(xpos), int(ypos))
+	worldX, worldZ := ih.screenToWorld(xpos, ypos)
+	ih.hoveredUnit, ih.hoveredBuilding = ih.objectAt(xpos, ypos, worldX, worldZ)
+	ih.setCursor(window, ih.hoverCursor(worldX, worldZ))
+}
+
+// Hovered returns the unit or building under the cursor, nil when there is
+// none, and the cursor's position in window pixels
+func (ih *InputHandler) Hovered() (*engine.GameUnit, *engine.GameBuilding, float64, float64) {
+	return ih.hoveredUnit, ih.hoveredBuilding, ih.lastMouseX, ih.lastMouseY
+}
+
+// hoverCursor returns the cursor for what a right click at a world point
+// would order the selection: a crosshair over enemies, a hand over
+// resources, and the arrow otherwise
+func (ih *InputHandler) hoverCursor(worldX, worldZ float64) glfw.StandardCursor {
+	playerID := ih.getCurrentPlayerID()
+	if len(ih.ownSelection()) == 0 || ih.wallPlacement != "" {
+		return glfw.ArrowCursor
+	}
+
+	if unit := ih.hoveredUnit; unit != nil && unit.PlayerID != playerID {
+		return glfw.CrosshairCursor
+	}
+	if ih.findResourceAtPosition(worldX, worldZ) != nil {
+		return glfw.HandCursor
+	}
+	if building := ih.hoveredBuilding; building != nil && building.PlayerID != playerID {
+		return glfw.CrosshairCursor
+	}
+	return glfw.ArrowCursor
+}
+
+// setCursor shows a standard cursor over the window, creating it on first use
+func (ih *InputHandler) setCursor(window *glfw.Window, shape glfw.StandardCursor) {
+	if window == nil || shape == ih.cursor {
+		return
+	}
+	if ih.cursors == nil {
+		ih.cursors = make(map[glfw.StandardCursor]*glfw.Cursor)
+	}
+	cursor, created := ih.cursors[shape]
+	if !created {
+		cursor = glfw.CreateStandardCursor(shape)
+		ih.cursors[shape] = cursor
+	}
+	window.SetCursor(cursor)
+	ih.cursor = shape
+}
+
+// showCommandMarker marks where a right-click order goes
+func (ih *InputHandler) showCommandMarker(kind renderer.CommandMarkerKind, position engine.Vector3) {
+	ih.commandMarkers.Show(kind, position, time.Now())
 }
 
 // HandleKeyboard processes keyboard events
 func (ih *InputHandler) HandleKeyboard(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	// The lobby sits on top of everything until the match launches
+	if ih.lobbyScreen != nil && ih.lobbyScreen.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.lobbyScreen.HandleKey(key)
+		}
+		return
+	}
+
+	// The profile screen sits on top of the pause menu
+	if ih.profileScreen != nil && ih.profileScreen.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.profileScreen.HandleKey(key)
+		}
+		return
+	}
+
+	// So do the options
+	if ih.optionsPanel != nil && ih.optionsPanel.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.optionsPanel.HandleKey(key)
+		}
+		return
+	}
+
+	// And the savegame browser
+	if ih.saveBrowser != nil && ih.saveBrowser.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.saveBrowser.HandleKey(key)
+		}
+		return
+	}
+
+	// The encyclopedia can be opened from the pause menu too
+	if ih.encyclopedia != nil && ih.encyclopedia.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.encyclopedia.HandleKey(key)
+		}
+		return
+	}
+
+	if ih.marketPanel != nil && ih.marketPanel.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.marketPanel.HandleKey(key)
+		}
+		return
+	}
+
+	if ih.diplomacyPanel != nil && ih.diplomacyPanel.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.diplomacyPanel.HandleKey(key)
+		}
+		return
+	}
+
+	if ih.economyPanel != nil && ih.economyPanel.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.economyPanel.HandleKey(key)
+		}
+		return
+	}
+
+	// The post-game review sits on the pause menu
+	if ih.observerPanel != nil && ih.observerPanel.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.observerPanel.HandleKey(key)
+		}
+		return
+	}
+
+	// Route all keys to the pause menu while it is open
+	if ih.pauseMenu != nil && ih.pauseMenu.IsOpen() {
+		if action == glfw.Press || action == glfw.Repeat {
+			ih.pauseMenu.HandleKey(key)
+		}
+		return
+	}
+
+	// HUD panels only see the press, not the repeats of a held key
+	if action == glfw.Press && ih.uiManager.HandleKey(key) {
+		return
+	}
+
 	if action == glfw.Press || action == glfw.Repeat {
 		switch key {
 		case glfw.KeyEscape:
-			// Exit game (handled by main loop via window.SetShouldClose)
-			window.SetShouldClose(true)
+			if ih.wallPlacement != "" {
+				// Leave wall placement before anything else
+				ih.wallPlacement, ih.wallStart = "", nil
+				logging.Infof(logging.CategoryUI, "Wall placement cancelled")
+			} else if ih.pauseMenu != nil {
+				// Open the pause menu instead of closing the window
+				ih.pauseMenu.Open()
+				ih.reportAction(ActionPauseMenu)
+			} else {
+				// Exit game (handled by main loop via window.SetShouldClose)
+				window.SetShouldClose(true)
+			}
 		case glfw.KeyP:
-			// Pause/Resume (this will be handled by main game loop)
-			// For now, just log the key press
-			fmt.Println("Pause key pressed - handled by main game loop")
+			// Pause/Resume; orders given while paused run on resume
+			if ih.pauseToggle != nil {
+				ih.pauseToggle()
+			}
 		case glfw.KeyA:
 			// Select all units
 			if (mods & glfw.ModControl) != 0 {
@@ -186,18 +614,164 @@ func (ih *InputHandler) HandleKeyboard(window *glfw.Window, key glfw.Key, scanco
 			// Delete selected units (for debugging/testing)
 			ih.deleteSelectedUnits()
 		case glfw.KeyG:
-			// Group selected units (for future group management)
+			// Form the selected units into an army group
 			ih.groupSelectedUnits()
+		case glfw.Key0, glfw.Key1, glfw.Key2, glfw.Key3, glfw.Key4,
+			glfw.Key5, glfw.Key6, glfw.Key7, glfw.Key8, glfw.Key9:
+			// Select, set or add to a control group; Alt sends a building's units to it
+			ih.useControlGroup(int(key-glfw.Key0), mods)
+		case glfw.KeyJ:
+			// Send the selected building's units to the next army group
+			ih.cycleProductionArmyGroup()
 		case glfw.KeyH:
 			// Hold position command
 			ih.issueHoldCommand()
 		case glfw.KeyS:
 			// Stop command
 			ih.issueStopCommand()
+		case glfw.KeyI:
+			// Look up the selection in the encyclopedia
+			ih.showSelectionInfo()
+		case glfw.KeyF5, glfw.KeyF6, glfw.KeyF7, glfw.KeyF8:
+			// Ctrl sets a camera bookmark, otherwise jump to it
+			ih.useCameraBookmark(int(key-glfw.KeyF5), (mods&glfw.ModControl) != 0)
+		case glfw.KeyM:
+			// Trade resources at the player's market
+			if ih.marketPanel != nil && !ih.marketPanel.Open() {
+				logging.Debugf(logging.CategoryUI, "No market to trade at")
+			}
+		case glfw.KeyT:
+			// Send tribute to an ally
+			if ih.diplomacyPanel != nil && !ih.diplomacyPanel.Open() {
+				logging.Debugf(logging.CategoryUI, "No allies to send tribute to")
+			}
+		case glfw.KeyE:
+			// Show income, spending and stockpile trends
+			if ih.economyPanel != nil {
+				ih.economyPanel.Open()
+			}
+		case glfw.KeyO:
+			// Watch every player's production and army and economy value
+			if ih.observerPanel != nil {
+				ih.observerPanel.Open()
+			} else {
+				logging.Debugf(logging.CategoryUI, "The observer panel opens for spectators and after the match")
+			}
+		case glfw.KeyB:
+			// Drag out a wall with the selected workers; shift places a gate
+			ih.startWallPlacement((mods & glfw.ModShift) != 0)
+		case glfw.KeyF:
+			// Follow the selected unit, or stop following
+			ih.toggleFollowSelection()
+		case glfw.KeySpace:
+			// Jump to the most recent alert
+			if ih.cameraControls != nil && !ih.cameraControls.JumpToLastEvent() {
+				logging.Debugf(logging.CategoryUI, "No event to jump to")
+			}
+		case glfw.KeyBackspace:
+			// Cycle the camera through the player's town centers
+			ih.jumpToNextTownCenter()
+		case glfw.KeyHome:
+			// Select the largest army group and jump to it
+			ih.jumpToLargestArmy()
+		case glfw.KeyX:
+			// Drop the workers from the selection, keeping the military
+			ih.uiManager.DropWorkers()
 		}
 	}
 }
 
+// menuOpen reports whether a modal menu or screen takes the keys
+func (ih *InputHandler) menuOpen() bool {
+	return (ih.pauseMenu != nil && ih.pauseMenu.IsOpen()) ||
+		(ih.profileScreen != nil && ih.profileScreen.IsOpen()) ||
+		(ih.optionsPanel != nil && ih.optionsPanel.IsOpen()) ||
+		(ih.saveBrowser != nil && ih.saveBrowser.IsOpen()) ||
+		(ih.encyclopedia != nil && ih.encyclopedia.IsOpen()) ||
+		(ih.marketPanel != nil && ih.marketPanel.IsOpen()) ||
+		(ih.diplomacyPanel != nil && ih.diplomacyPanel.IsOpen()) ||
+		(ih.economyPanel != nil && ih.economyPanel.IsOpen()) ||
+		(ih.observerPanel != nil && ih.observerPanel.IsOpen()) ||
+		(ih.lobbyScreen != nil && ih.lobbyScreen.IsOpen())
+}
+
+// useCameraBookmark sets or jumps to a camera bookmark
+func (ih *InputHandler) useCameraBookmark(slot int, set bool) {
+	if ih.cameraControls == nil {
+		return
+	}
+	if set {
+		ih.cameraControls.SetBookmark(slot)
+	} else if !ih.cameraControls.JumpToBookmark(slot) {
+		logging.Debugf(logging.CategoryUI, "Camera bookmark %d is not set", slot+1)
+	}
+}
+
+// jumpToNextTownCenter selects the player's next town center and moves the camera to it
+func (ih *InputHandler) jumpToNextTownCenter() {
+	if ih.cameraControls == nil {
+		return
+	}
+	center := ih.uiManager.Selection().NextTownCenter(ih.getCurrentPlayerID())
+	if center == nil {
+		logging.Debugf(logging.CategoryUI, "No town center to jump to")
+		return
+	}
+	ih.uiManager.SelectBuilding(center)
+	ih.cameraControls.JumpTo(center.GetPosition())
+}
+
+// jumpToLargestArmy selects the player's largest army group and moves the camera to it
+func (ih *InputHandler) jumpToLargestArmy() {
+	if ih.cameraControls == nil {
+		return
+	}
+	center, ok := ih.uiManager.SelectLargestArmy(ih.getCurrentPlayerID())
+	if !ok {
+		logging.Debugf(logging.CategoryUI, "No army to jump to")
+		return
+	}
+	ih.cameraControls.JumpTo(center)
+}
+
+// toggleFollowSelection makes the camera follow the first selected unit, or stops following
+func (ih *InputHandler) toggleFollowSelection() {
+	if ih.cameraControls == nil {
+		return
+	}
+	if ih.cameraControls.Following() != nil {
+		ih.cameraControls.StopFollowing()
+		logging.Infof(logging.CategoryUI, "Camera follow off")
+		return
+	}
+	if units := ih.uiManager.GetSelectedUnits(); len(units) > 0 {
+		ih.cameraControls.Follow(units[0])
+	}
+}
+
+// showSelectionInfo opens the encyclopedia entry of the selected unit or building
+func (ih *InputHandler) showSelectionInfo() {
+	if ih.encyclopedia == nil {
+		return
+	}
+
+	var playerID int
+	var name string
+	if units := ih.uiManager.GetSelectedUnits(); len(units) > 0 {
+		playerID, name = units[0].PlayerID, units[0].UnitType
+	} else if building := ih.uiManager.GetSelectedBuilding(); building != nil {
+		playerID, name = building.PlayerID, building.BuildingType
+	} else {
+		ih.encyclopedia.Open()
+		return
+	}
+
+	player := ih.world.GetPlayer(playerID)
+	if player == nil || !ih.encyclopedia.OpenEntry(player.FactionName, name) {
+		logging.Debugf(logging.CategoryUI, "No encyclopedia entry for %s", name)
+	}
+}
+
 // handleLeftMousePress handles left mouse button press
 func (ih *InputHandler) handleLeftMousePress(xpos, ypos float64, mods glfw.ModifierKey) {
 	// Check if shift is held for additive selection
@@ -206,30 +780,75 @@ func (ih *InputHandler) handleLeftMousePress(xpos, ypos float64, mods glfw.Modif
 	// Convert screen coordinates to world coordinates
 	worldX, worldZ := ih.screenToWorld(xpos, ypos)
 
+	// In wall placement the drag lays the wall instead of selecting
+	if ih.wallPlacement != "" {
+		ih.wallStart = &engine.Vector3{X: worldX, Z: worldZ}
+		return
+	}
+
+	// A panel waiting for a map click, e.g. to place a rally point, takes it
+	if ih.uiManager.HandleWorldClick(engine.Vector3{X: worldX, Z: worldZ}) {
+		return
+	}
+
 	// Try to select unit or building at clicked position
-	selectedUnit := ih.findUnitAtPosition(worldX, worldZ)
-	selectedBuilding := ih.findBuildingAtPosition(worldX, worldZ)
+	selectedUnit, selectedBuilding := ih.objectAt(xpos, ypos, worldX, worldZ)
 
 	if selectedUnit != nil {
-		if additive {
-			// Add to existing selection
-			currentUnits := ih.uiManager.GetSelectedUnits()
-			currentUnits = append(currentUnits, selectedUnit)
-			ih.uiManager.SelectUnits(currentUnits)
-		} else {
-			// New selection
-			ih.uiManager.SelectUnits([]*engine.GameUnit{selectedUnit})
-		}
+		gesture := ih.selectionGesture(selectedUnit, mods)
+		ih.uiManager.ApplySelectionGesture(gesture, selectedUnit, ih.isUnitOnScreen)
+		ih.reportAction(ActionSelectUnits)
 	} else if selectedBuilding != nil {
 		ih.uiManager.SelectBuilding(selectedBuilding)
+		ih.reportAction(ActionSelectBuilding)
 	} else if !additive {
 		// Start drag selection
 		ih.startDragSelection(xpos, ypos)
 	}
 }
 
+// selectionGesture tells the gesture of a click on a unit from the modifiers
+// and the previous click: alt selects only the unit, ctrl toggles it, a
+// double-click selects its type and shift adds it
+func (ih *InputHandler) selectionGesture(unit *engine.GameUnit, mods glfw.ModifierKey) engine.SelectionGesture {
+	now := time.Now()
+	doubleClick := unit == ih.lastClickUnit && now.Sub(ih.lastClickTime) <= doubleClickInterval
+	ih.lastClickUnit, ih.lastClickTime = unit, now
+
+	switch {
+	case mods&glfw.ModAlt != 0:
+		return engine.GestureSelectOnly
+	case mods&glfw.ModControl != 0:
+		return engine.GestureToggle
+	case doubleClick:
+		ih.lastClickUnit = nil // A third click starts over
+		return engine.GestureSelectType
+	case mods&glfw.ModShift != 0:
+		return engine.GestureAdd
+	default:
+		return engine.GestureSelect
+	}
+}
+
+// isUnitOnScreen reports whether a unit is in the camera's view
+func (ih *InputHandler) isUnitOnScreen(unit *engine.GameUnit) bool {
+	if ih.camera == nil {
+		return true
+	}
+	position := unit.GetPosition()
+	center := mgl32.Vec3{float32(position.X), float32(position.Y), float32(position.Z)}
+	extent := mgl32.Vec3{0.5, 0.5, 0.5}
+	return ih.camera.IsInFrustum(center.Sub(extent), center.Add(extent))
+}
+
 // handleLeftMouseRelease handles left mouse button release
 func (ih *InputHandler) handleLeftMouseRelease(xpos, ypos float64, mods glfw.ModifierKey) {
+	if ih.wallPlacement != "" && ih.wallStart != nil {
+		worldX, worldZ := ih.screenToWorld(xpos, ypos)
+		ih.placeWall(*ih.wallStart, engine.Vector3{X: worldX, Z: worldZ})
+		return
+	}
+
 	if ih.isDragging && ih.isSelecting {
 		ih.finishDragSelection(mods)
 	}
@@ -240,9 +859,59 @@ func (ih *InputHandler) handleLeftMouseRelease(xpos, ypos float64, mods glfw.Mod
 	ih.selectionBox.Active = false
 }
 
+// startWallPlacement enters wall placement when workers are selected
+func (ih *InputHandler) startWallPlacement(gate bool) {
+	if len(ih.selectedBuilders()) == 0 {
+		logging.Debugf(logging.CategoryUI, "Select workers to build a wall")
+		return
+	}
+	ih.wallPlacement, ih.wallStart = "wall", nil
+	if gate {
+		ih.wallPlacement = "gate"
+	}
+	logging.Infof(logging.CategoryUI, "Drag to place a %s (ESC cancels)", ih.wallPlacement)
+}
+
+// placeWall orders the selected workers to build the wall dragged from start
+// to end; a gate takes only the tile where the drag started
+func (ih *InputHandler) placeWall(start, end engine.Vector3) {
+	buildingType := ih.wallPlacement
+	ih.wallPlacement, ih.wallStart = "", nil
+	if buildingType == "gate" {
+		end = start
+	}
+
+	var builders []int
+	for _, unit := range ih.selectedBuilders() {
+		builders = append(builders, unit.ID)
+	}
+	commandProcessor, ok := ih.world.GetCommandProcessor().(*engine.CommandProcessor)
+	if !ok || commandProcessor == nil {
+		return
+	}
+	segments, err := commandProcessor.IssueWallCommand(builders, buildingType, start, end)
+	if err != nil {
+		logging.Warnf(logging.CategoryUI, "Cannot build %s: %v", buildingType, err)
+		return
+	}
+	logging.Infof(logging.CategoryUI, "Ordered %d %s segments", segments, buildingType)
+	ih.reportAction(ActionBuildWall)
+}
+
+// selectedBuilders returns the selected units that can construct buildings
+func (ih *InputHandler) selectedBuilders() []*engine.GameUnit {
+	var builders []*engine.GameUnit
+	for _, unit := range ih.uiManager.GetSelectedUnits() {
+		if unit.PlayerID == ih.getCurrentPlayerID() && engine.IsWorkerType(unit.UnitType) {
+			builders = append(builders, unit)
+		}
+	}
+	return builders
+}
+
 // handleRightMousePress handles right mouse button press (issue commands)
 func (ih *InputHandler) handleRightMousePress(xpos, ypos float64, mods glfw.ModifierKey) {
-	selectedUnits := ih.uiManager.GetSelectedUnits()
+	selectedUnits := ih.ownSelection()
 	if len(selectedUnits) == 0 {
 		return
 	}
@@ -251,13 +920,15 @@ func (ih *InputHandler) handleRightMousePress(xpos, ypos float64, mods glfw.Modi
 	worldX, worldZ := ih.screenToWorld(xpos, ypos)
 
 	// Check if clicking on an enemy unit (attack command)
-	targetUnit := ih.findUnitAtPosition(worldX, worldZ)
+	targetUnit, targetBuilding := ih.objectAt(xpos, ypos, worldX, worldZ)
 	if targetUnit != nil && targetUnit.PlayerID != selectedUnits[0].PlayerID {
 		// Issue attack command
 		params := map[string]interface{}{
 			"target_unit": targetUnit,
 		}
 		ih.uiManager.IssueCommand(engine.CommandAttack, params)
+		ih.showCommandMarker(renderer.CommandMarkerAttack, targetUnit.GetPosition())
+		ih.reportAction(ActionAttack)
 		return
 	}
 
@@ -268,17 +939,28 @@ func (ih *InputHandler) handleRightMousePress(xpos, ypos float64, mods glfw.Modi
 			"target_resource": resourceNode,
 		}
 		ih.uiManager.IssueCommand(engine.CommandGather, params)
+		ih.showCommandMarker(renderer.CommandMarkerGather, resourceNode.Position)
+		ih.reportAction(ActionGather)
 		return
 	}
 
 	// Check if clicking on a building (could be repair or other interaction)
-	targetBuilding := ih.findBuildingAtPosition(worldX, worldZ)
+	if targetBuilding != nil && targetBuilding.PlayerID == selectedUnits[0].PlayerID && targetBuilding.RequiredWorkers > 0 {
+		// Staff a friendly building that needs workers with the selected workers
+		if assigned, err := ih.world.GetProductionSystem().StaffBuilding(targetBuilding.ID, ih.uiManager.ActiveUnits()); err == nil {
+			logging.Infof(logging.CategoryUI, "Assigned %d workers to %s", assigned, targetBuilding.BuildingType)
+			ih.reportAction(ActionStaff)
+			return
+		}
+	}
 	if targetBuilding != nil && targetBuilding.PlayerID != selectedUnits[0].PlayerID {
 		// Attack building
 		params := map[string]interface{}{
 			"target_building": targetBuilding,
 		}
 		ih.uiManager.IssueCommand(engine.CommandAttack, params)
+		ih.showCommandMarker(renderer.CommandMarkerAttack, targetBuilding.GetPosition())
+		ih.reportAction(ActionAttack)
 		return
 	} else if targetBuilding != nil && targetBuilding.Health < targetBuilding.MaxHealth {
 		// Repair friendly building
@@ -286,17 +968,21 @@ func (ih *InputHandler) handleRightMousePress(xpos, ypos float64, mods glfw.Modi
 			"target_building": targetBuilding,
 		}
 		ih.uiManager.IssueCommand(engine.CommandRepair, params)
+		ih.reportAction(ActionRepair)
 		return
 	}
 
-	// Default: move command
+	// Default: move command; Alt orders the units straight across hazards
 	queueCommand := (mods & glfw.ModShift) != 0
 	params := map[string]interface{}{
 		"target_x":     worldX,
 		"target_z":     worldZ,
 		"queue":        queueCommand,
+		engine.ParamThroughHazards: (mods & glfw.ModAlt) != 0,
 	}
 	ih.uiManager.IssueCommand(engine.CommandMove, params)
+	ih.showCommandMarker(renderer.CommandMarkerMove, engine.Vector3{X: worldX, Z: worldZ})
+	ih.reportAction(ActionMove)
 }
 
 // startDragSelection begins a drag selection operation
@@ -342,16 +1028,10 @@ func (ih *InputHandler) finishDragSelection(mods glfw.ModifierKey) {
 		}
 	}
 
-	// Apply selection
-	additive := (mods & glfw.ModShift) != 0
-	if additive && len(filteredUnits) > 0 {
-		// Add to existing selection
-		currentUnits := ih.uiManager.GetSelectedUnits()
-		currentUnits = append(currentUnits, filteredUnits...)
-		ih.uiManager.SelectUnits(currentUnits)
-	} else if len(filteredUnits) > 0 {
-		// New selection
-		ih.uiManager.SelectUnits(filteredUnits)
+	// Apply selection; the selection filters drop workers and buildings from mixed bands
+	if len(filteredUnits) > 0 {
+		ih.uiManager.SelectBand(filteredUnits, (mods&glfw.ModShift) != 0)
+		ih.reportAction(ActionSelectUnits)
 	}
 }
 
@@ -394,22 +1074,45 @@ func (ih *InputHandler) screenToWorld(screenX, screenY float64) (float64, float6
 	return worldX, worldZ
 }
 
+// objectAt returns the unit or building at a window pixel, nil when there
+// is none: the one the picking pass drew there when it covered the pixel,
+// else a unit or, failing that, a building near the ground point under it
+func (ih *InputHandler) objectAt(xpos, ypos, worldX, worldZ float64) (*engine.GameUnit, *engine.GameBuilding) {
+	if picked, ok := ih.picking.Pick(int(xpos), int(ypos)); ok {
+		switch picked.Kind {
+		case renderer.PickUnit:
+			if unit := ih.world.ObjectManager.GetUnit(picked.ID); unit != nil && unit.IsAlive() {
+				return unit, nil
+			}
+		case renderer.PickBuilding:
+			if building := ih.world.ObjectManager.GetBuilding(picked.ID); building != nil && building.IsAlive() {
+				return nil, building
+			}
+		}
+		return nil, nil
+	}
+	if unit := ih.findUnitAtPosition(worldX, worldZ); unit != nil {
+		return unit, nil
+	}
+	return nil, ih.findBuildingAtPosition(worldX, worldZ)
+}
+
 // findUnitAtPosition finds a unit at the given world position
 func (ih *InputHandler) findUnitAtPosition(worldX, worldZ float64) *engine.GameUnit {
-	// Search radius for unit selection
-	searchRadius := 1.0
+	tileSize := float64(ih.world.GetTileSize())
 
 	// Get all units from all players
 	for playerID := range ih.world.GetPlayers() {
 		units := ih.world.ObjectManager.GetUnitsForPlayer(playerID)
 		for _, unit := range units {
-			if unit.IsAlive() {
+			if unit.IsAlive() && !unit.IsGarrisoned() {
 				// Calculate distance to unit
 				dx := unit.Position.X - worldX
 				dz := unit.Position.Z - worldZ
 				distance := math.Sqrt(dx*dx + dz*dz)
 
-				if distance <= searchRadius {
+				// Larger units are selected further from their position
+				if distance <= unit.SelectionRadius()*tileSize {
 					return unit
 				}
 			}
@@ -483,10 +1186,11 @@ func (ih *InputHandler) findUnitsInRectangle(minX, minZ, maxX, maxZ float64) []*
 	for playerID := range ih.world.GetPlayers() {
 		units := ih.world.ObjectManager.GetUnitsForPlayer(playerID)
 		for _, unit := range units {
-			if unit.IsAlive() {
-				// Check if unit is within rectangle
-				if unit.Position.X >= minX && unit.Position.X <= maxX &&
-					unit.Position.Z >= minZ && unit.Position.Z <= maxZ {
+			if unit.IsAlive() && !unit.IsGarrisoned() {
+				// Check if the unit's body reaches into the rectangle
+				radius := unit.CollisionRadius() * float64(ih.world.GetTileSize())
+				if unit.Position.X >= minX-radius && unit.Position.X <= maxX+radius &&
+					unit.Position.Z >= minZ-radius && unit.Position.Z <= maxZ+radius {
 					selectedUnits = append(selectedUnits, unit)
 				}
 			}
@@ -0,0 +1,377 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/network"
+)
+
+// LobbyScreen shows the player slots of a multiplayer lobby and lets the local
+// player pick a faction and team, toggle ready and (as host) change the map,
+// game mode, add AI players and change their settings and launch. It stays
+// open until the match launches or the player leaves.
+type LobbyScreen struct {
+	session  network.LobbySession
+	open     bool
+	message  string // Result of the last action
+	selected int    // Slot whose AI settings the host is editing
+
+	// Callbacks
+	onLaunch func(info network.LaunchInfo)
+	onLeave  func()
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewLobbyScreen creates a lobby screen driving the given host or guest session
+func NewLobbyScreen(session network.LobbySession) *LobbyScreen {
+	return &LobbyScreen{session: session, open: true}
+}
+
+// IsOpen returns whether the lobby is shown
+func (ls *LobbyScreen) IsOpen() bool {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	return ls.open
+}
+
+// SetLaunchHandler registers the function called when the match starts
+func (ls *LobbyScreen) SetLaunchHandler(handler func(info network.LaunchInfo)) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	ls.onLaunch = handler
+}
+
+// SetLeaveHandler registers the function called when the player leaves the lobby
+func (ls *LobbyScreen) SetLeaveHandler(handler func()) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	ls.onLeave = handler
+}
+
+// Update checks whether the match was launched and hands over to the launch handler
+func (ls *LobbyScreen) Update() {
+	select {
+	case info := <-ls.session.Launched():
+		ls.mutex.Lock()
+		ls.open = false
+		handler := ls.onLaunch
+		ls.mutex.Unlock()
+		if handler != nil {
+			handler(info)
+		}
+	default:
+	}
+}
+
+// CycleFaction switches the local player to the next (or previous) available faction
+func (ls *LobbyScreen) CycleFaction(delta int) error {
+	state := ls.session.State()
+	slot := state.Slots[ls.session.LocalSlot()]
+	next, err := cycle(state.AvailableFactions, slot.Faction, delta)
+	if err != nil {
+		return ls.report("Change faction", err)
+	}
+	return ls.report("Change faction", ls.session.SetFaction(next))
+}
+
+// CycleTeam moves the local player to the next team, wrapping around
+func (ls *LobbyScreen) CycleTeam() error {
+	state := ls.session.State()
+	slot := state.Slots[ls.session.LocalSlot()]
+	team := slot.Team%len(state.Slots) + 1
+	return ls.report("Change team", ls.session.SetTeam(team))
+}
+
+// ToggleReady flips the local player's ready flag
+func (ls *LobbyScreen) ToggleReady() error {
+	state := ls.session.State()
+	slot := state.Slots[ls.session.LocalSlot()]
+	return ls.report("Ready", ls.session.SetReady(!slot.Ready))
+}
+
+// CycleMap switches to the next available map (host only)
+func (ls *LobbyScreen) CycleMap(delta int) error {
+	host, ok := ls.session.(*network.LobbyHost)
+	if !ok {
+		return ls.report("Change map", fmt.Errorf("only the host can change the map"))
+	}
+	state := host.State()
+	next, err := cycle(state.AvailableMaps, state.MapName, delta)
+	if err != nil {
+		return ls.report("Change map", err)
+	}
+	return ls.report("Change map", host.SetMap(next))
+}
+
+// ToggleUpkeep turns upkeep mode on or off (host only)
+func (ls *LobbyScreen) ToggleUpkeep() error {
+	host, ok := ls.session.(*network.LobbyHost)
+	if !ok {
+		return ls.report("Change upkeep", fmt.Errorf("only the host can change the game mode"))
+	}
+	return ls.report("Change upkeep", host.SetUnitUpkeep(!host.State().UnitUpkeep))
+}
+
+// AddAI seats an AI player in the first open slot and selects it (host only)
+func (ls *LobbyScreen) AddAI() error {
+	host, ok := ls.session.(*network.LobbyHost)
+	if !ok {
+		return ls.report("Add AI", fmt.Errorf("only the host can add AI players"))
+	}
+	state := host.State()
+	if len(state.AvailableFactions) == 0 {
+		return ls.report("Add AI", fmt.Errorf("no factions to give the AI"))
+	}
+	for _, slot := range state.Slots {
+		if slot.Kind != network.SlotOpen {
+			continue
+		}
+		if err := host.SetSlotKind(slot.Index, network.SlotAI); err != nil {
+			return ls.report("Add AI", err)
+		}
+		ls.mutex.Lock()
+		ls.selected = slot.Index
+		ls.mutex.Unlock()
+		return ls.report("Add AI", host.SetSlotFaction(slot.Index, state.AvailableFactions[0]))
+	}
+	return ls.report("Add AI", fmt.Errorf("there are no open slots"))
+}
+
+// RemoveAI opens the selected AI player's slot again (host only)
+func (ls *LobbyScreen) RemoveAI() error {
+	host, ok := ls.session.(*network.LobbyHost)
+	if !ok {
+		return ls.report("Remove AI", fmt.Errorf("only the host can remove AI players"))
+	}
+	ls.mutex.Lock()
+	index := ls.selected
+	ls.mutex.Unlock()
+	if state := host.State(); index >= len(state.Slots) || state.Slots[index].Kind != network.SlotAI {
+		return ls.report("Remove AI", fmt.Errorf("select an AI player first"))
+	}
+	return ls.report("Remove AI", host.SetSlotKind(index, network.SlotOpen))
+}
+
+// SelectSlot moves the host's AI settings cursor to the next (or previous) AI slot
+func (ls *LobbyScreen) SelectSlot(delta int) error {
+	if !ls.session.IsHost() {
+		return ls.report("Select AI", fmt.Errorf("only the host can set up AI players"))
+	}
+	state := ls.session.State()
+	ls.mutex.Lock()
+	count := len(state.Slots)
+	for step := 1; step <= count; step++ {
+		index := ((ls.selected+step*delta)%count + count) % count
+		if state.Slots[index].Kind == network.SlotAI {
+			ls.selected = index
+			ls.mutex.Unlock()
+			return ls.report("Select AI", nil)
+		}
+	}
+	ls.mutex.Unlock()
+	return ls.report("Select AI", fmt.Errorf("there are no AI players"))
+}
+
+// CycleAIPersonality switches the selected AI slot to the next personality (host only)
+func (ls *LobbyScreen) CycleAIPersonality() error {
+	return ls.changeAI("Change AI personality", func(slot *network.PlayerSlot) error {
+		next, err := cycle(engine.AIPersonalityNames, slot.AIPersonality, 1)
+		slot.AIPersonality = next
+		return err
+	})
+}
+
+// CycleAIDifficulty switches the selected AI slot to the next difficulty (host only)
+func (ls *LobbyScreen) CycleAIDifficulty() error {
+	return ls.changeAI("Change AI difficulty", func(slot *network.PlayerSlot) error {
+		next, err := cycle(engine.AIDifficultyNames, slot.AIDifficulty, 1)
+		slot.AIDifficulty = next
+		return err
+	})
+}
+
+// CycleHandicap switches the selected AI slot to the next resource handicap (host only)
+func (ls *LobbyScreen) CycleHandicap() error {
+	return ls.changeAI("Change handicap", func(slot *network.PlayerSlot) error {
+		index := 0
+		for i, step := range network.HandicapSteps {
+			if step == slot.Handicap {
+				index = i + 1
+			}
+		}
+		slot.Handicap = network.HandicapSteps[index%len(network.HandicapSteps)]
+		return nil
+	})
+}
+
+// changeAI edits a copy of the selected AI slot and sends it to the host lobby
+func (ls *LobbyScreen) changeAI(action string, change func(slot *network.PlayerSlot) error) error {
+	host, ok := ls.session.(*network.LobbyHost)
+	if !ok {
+		return ls.report(action, fmt.Errorf("only the host can set up AI players"))
+	}
+	ls.mutex.Lock()
+	index := ls.selected
+	ls.mutex.Unlock()
+
+	state := host.State()
+	if index >= len(state.Slots) || state.Slots[index].Kind != network.SlotAI {
+		return ls.report(action, fmt.Errorf("select an AI player first"))
+	}
+	slot := state.Slots[index]
+	if err := change(&slot); err != nil {
+		return ls.report(action, err)
+	}
+	return ls.report(action, host.SetSlotAI(index, slot.AIPersonality, slot.AIDifficulty, slot.Handicap))
+}
+
+// Launch starts the match (host only); guests start when the host's launch arrives
+func (ls *LobbyScreen) Launch() error {
+	host, ok := ls.session.(*network.LobbyHost)
+	if !ok {
+		return ls.report("Launch", fmt.Errorf("waiting for the host to launch"))
+	}
+	_, err := host.Launch()
+	if err == nil {
+		ls.Update()
+	}
+	return ls.report("Launch", err)
+}
+
+// Leave closes the session and notifies the leave handler
+func (ls *LobbyScreen) Leave() {
+	ls.session.Close()
+
+	ls.mutex.Lock()
+	ls.open = false
+	handler := ls.onLeave
+	ls.mutex.Unlock()
+	if handler != nil {
+		handler()
+	}
+}
+
+// HandleKey processes a key press, returning true if it was consumed
+func (ls *LobbyScreen) HandleKey(key glfw.Key) bool {
+	switch key {
+	case glfw.KeyLeft:
+		ls.CycleFaction(-1)
+	case glfw.KeyRight, glfw.KeyF:
+		ls.CycleFaction(1)
+	case glfw.KeyT:
+		ls.CycleTeam()
+	case glfw.KeyR, glfw.KeySpace:
+		ls.ToggleReady()
+	case glfw.KeyPageUp:
+		ls.CycleMap(-1)
+	case glfw.KeyPageDown, glfw.KeyM:
+		ls.CycleMap(1)
+	case glfw.KeyU:
+		ls.ToggleUpkeep()
+	case glfw.KeyA:
+		ls.AddAI()
+	case glfw.KeyX:
+		ls.RemoveAI()
+	case glfw.KeyUp:
+		ls.SelectSlot(-1)
+	case glfw.KeyDown:
+		ls.SelectSlot(1)
+	case glfw.KeyP:
+		ls.CycleAIPersonality()
+	case glfw.KeyD:
+		ls.CycleAIDifficulty()
+	case glfw.KeyH:
+		ls.CycleHandicap()
+	case glfw.KeyEnter, glfw.KeyKPEnter:
+		ls.Launch()
+	case glfw.KeyEscape:
+		ls.Leave()
+	default:
+		return false
+	}
+	return true
+}
+
+// Draw draws the lobby in the middle of the HUD while it is open
+func (ls *LobbyScreen) Draw(canvas *renderer.HUDCanvas) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	if !ls.open {
+		return
+	}
+	state := ls.session.State()
+	local := ls.session.LocalSlot()
+
+	upkeep := "Unit upkeep: off"
+	if state.UnitUpkeep {
+		upkeep = "Unit upkeep: on (large armies cost resources every minute)"
+	}
+	lines := []screenLine{
+		{text: fmt.Sprintf("Map: %s  Tech tree: %s", orNone(state.MapName), state.TechTree)},
+		{text: upkeep},
+	}
+	for _, slot := range state.Slots {
+		ready := ""
+		if slot.Ready {
+			ready = " [ready]"
+		}
+		line := screenLine{selected: slot.Index == local}
+		switch slot.Kind {
+		case network.SlotHuman:
+			line.text = fmt.Sprintf("%d. %-16s %-12s team %d%s", slot.Index+1, slot.PlayerName, slot.Faction, slot.Team, ready)
+		case network.SlotAI:
+			line.selected = ls.session.IsHost() && slot.Index == ls.selected
+			line.text = fmt.Sprintf("%d. %-16s %-12s team %d  %s %s, %d%% resources", slot.Index+1, slot.PlayerName, slot.Faction, slot.Team,
+				slot.AIDifficulty, slot.AIPersonality, slot.Handicap)
+		default:
+			line.text = fmt.Sprintf("%d. (%s)", slot.Index+1, slot.Kind)
+		}
+		lines = append(lines, line)
+	}
+
+	hint := "Left/Right faction, T team, R ready, ESC leave"
+	if ls.session.IsHost() {
+		lines = append(lines, textLines("A add AI, X remove AI, Up/Down pick AI, P personality, D difficulty, H handicap")...)
+		hint = "Left/Right faction, T team, R ready, M map, U upkeep, Enter launch, ESC leave"
+	}
+	drawScreen(canvas, "Lobby: "+state.Name, lines, ls.message, hint)
+}
+
+// report records the outcome of an action for display and returns err
+func (ls *LobbyScreen) report(action string, err error) error {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	if err != nil {
+		ls.message = fmt.Sprintf("%s failed: %v", action, err)
+	} else {
+		ls.message = ""
+	}
+	return err
+}
+
+// cycle returns the entry delta steps away from current, wrapping around
+func cycle(options []string, current string, delta int) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("nothing to choose from")
+	}
+	index := 0
+	for i, option := range options {
+		if option == current {
+			index = i
+			break
+		}
+	}
+	count := len(options)
+	return options[((index+delta)%count+count)%count], nil
+}
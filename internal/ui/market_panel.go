@@ -0,0 +1,207 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+)
+
+// Market panel layout
+const (
+	marketSliderStep   = 50  // Amount the slider moves per key press
+	marketSliderWidth  = 160 // In pixels
+	marketSliderHeight = 10
+)
+
+// Market panel rows
+const (
+	marketRowSell = iota
+	marketRowBuy
+	marketRowAmount
+	marketRowCount
+)
+
+// MarketPanel trades resources at the player's market: pick the resource to
+// sell and to buy, set the amount with a slider and confirm
+type MarketPanel struct {
+	world    *engine.World
+	playerID int
+
+	resources []string // Tradeable resources, sorted
+	row       int      // Highlighted row
+	sell, buy int      // Indexes into resources
+	amount    int      // Slider value, in units of the sold resource
+	open      bool
+	message   string // Result of the last trade
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewMarketPanel creates a market panel for a player
+func NewMarketPanel(world *engine.World, playerID int) *MarketPanel {
+	return &MarketPanel{world: world, playerID: playerID}
+}
+
+// IsOpen returns whether the panel is shown
+func (mp *MarketPanel) IsOpen() bool {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	return mp.open
+}
+
+// Open shows the panel; it returns false (and stays closed) when the player has no market
+func (mp *MarketPanel) Open() bool {
+	if !mp.world.HasMarket(mp.playerID) {
+		return false
+	}
+
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	mp.resources = mp.resources[:0]
+	for resource := range mp.world.GetResourceStatus(mp.playerID).Resources {
+		mp.resources = append(mp.resources, resource)
+	}
+	sort.Strings(mp.resources)
+	if len(mp.resources) < 2 {
+		return false
+	}
+	if mp.sell >= len(mp.resources) || mp.buy >= len(mp.resources) || mp.sell == mp.buy {
+		mp.sell, mp.buy = 0, 1
+	}
+	mp.row, mp.amount, mp.message = marketRowSell, 0, ""
+	mp.open = true
+	return true
+}
+
+// Close hides the panel
+func (mp *MarketPanel) Close() {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+	mp.open = false
+}
+
+// HandleKey processes a key press while the panel is open, returning true if it was consumed
+func (mp *MarketPanel) HandleKey(key glfw.Key) bool {
+	mp.mutex.Lock()
+	if !mp.open {
+		mp.mutex.Unlock()
+		return false
+	}
+
+	trade := false
+	switch key {
+	case glfw.KeyEscape, glfw.KeyM:
+		mp.open = false
+	case glfw.KeyUp, glfw.KeyW:
+		mp.row = (mp.row + marketRowCount - 1) % marketRowCount
+	case glfw.KeyDown, glfw.KeyS:
+		mp.row = (mp.row + 1) % marketRowCount
+	case glfw.KeyLeft, glfw.KeyA:
+		mp.adjust(-1)
+	case glfw.KeyRight, glfw.KeyD:
+		mp.adjust(1)
+	case glfw.KeyEnter, glfw.KeyKPEnter, glfw.KeySpace:
+		trade = true
+	}
+	sell, buy, amount := mp.resources[mp.sell], mp.resources[mp.buy], mp.amount
+	mp.mutex.Unlock()
+
+	// Trade without the panel lock; the world takes its own locks
+	if trade {
+		message := "Set an amount to trade"
+		if amount > 0 {
+			bought, err := mp.world.Trade(mp.playerID, sell, buy, amount)
+			if err != nil {
+				message = fmt.Sprintf("Trade failed: %v", err)
+			} else {
+				message = fmt.Sprintf("Traded %d %s for %d %s", amount, sell, bought, buy)
+			}
+		}
+		mp.mutex.Lock()
+		mp.message, mp.amount = message, 0
+		mp.mutex.Unlock()
+	}
+
+	// The panel is modal: swallow every key while it is open
+	return true
+}
+
+// Draw draws the panel in the middle of the HUD while it is open, with the
+// amount to trade as a slider over the stock of the sold resource
+func (mp *MarketPanel) Draw(canvas *renderer.HUDCanvas) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	if !mp.open {
+		return
+	}
+	market := mp.world.GetMarket()
+	stock := mp.world.GetResourceStatus(mp.playerID).Resources
+	sell, buy := mp.resources[mp.sell], mp.resources[mp.buy]
+
+	lines := []screenLine{
+		{text: fmt.Sprintf("Sell:   < %s >  (have %d, price %.0f)", sell, stock[sell], market.Price(sell))},
+		{text: fmt.Sprintf("Buy:    < %s >  (have %d, price %.0f)", buy, stock[buy], market.Price(buy))},
+		{text: fmt.Sprintf("Amount: %d -> %d %s", mp.amount, market.Quote(sell, buy, mp.amount), buy)},
+	}
+	lines[mp.row].selected = true
+	panel := drawScreen(canvas, "Market", lines, mp.message, "Up/Down to pick a row, Left/Right to change it, Enter to trade, ESC to close")
+
+	drawSlider(canvas, panel, marketRowAmount, mp.amount, stock[sell])
+}
+
+// drawSlider draws an amount as a slider over what the player has, at the
+// right of a row of a modal screen
+func drawSlider(canvas *renderer.HUDCanvas, panel sprite.Rect, row, amount, have int) {
+	filled := float32(0)
+	if have > 0 {
+		filled = min(1, float32(amount)/float32(have)) // Stock may be spent since the amount was set
+	}
+	inner := panel.Inset(screenPadding)
+	slider := sprite.Rect{
+		X: inner.X + inner.W - marketSliderWidth,
+		Y: inner.Y + float32((1+row)*screenLineStep) + (screenLineStep-marketSliderHeight)/2 - 2,
+		W: marketSliderWidth,
+		H: marketSliderHeight,
+	}
+	canvas.Sprites.Fill(slider, commandSlotColor)
+	slider.W *= filled
+	canvas.Sprites.Fill(slider, queueProgressColor)
+}
+
+// adjust changes the highlighted row's value (lock must be held)
+func (mp *MarketPanel) adjust(delta int) {
+	count := len(mp.resources)
+	switch mp.row {
+	case marketRowSell:
+		mp.sell = (mp.sell + delta + count) % count
+		if mp.sell == mp.buy {
+			mp.sell = (mp.sell + delta + count) % count
+		}
+		mp.amount = 0
+	case marketRowBuy:
+		mp.buy = (mp.buy + delta + count) % count
+		if mp.buy == mp.sell {
+			mp.buy = (mp.buy + delta + count) % count
+		}
+	case marketRowAmount:
+		stock := mp.world.GetResourceStatus(mp.playerID).Resources[mp.resources[mp.sell]]
+		mp.amount += delta * marketSliderStep
+		if mp.amount > stock {
+			mp.amount = stock
+		}
+		if mp.amount < 0 {
+			mp.amount = 0
+		}
+	}
+}
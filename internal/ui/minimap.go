@@ -0,0 +1,301 @@
+//go:build !js
+
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/logging"
+)
+
+// Minimap layout, in pixels
+const (
+	minimapSize   = 192 // Longer side; the shorter follows the map's shape
+	minimapMargin = 8
+	minimapBorder = 2
+	minimapFlash  = 8 // Side of an attack flash
+)
+
+// Minimap timing
+const (
+	minimapRefreshInterval = 100 * time.Millisecond // Fog and dots are redrawn at 10 Hz
+	minimapFrameBudget     = 500 * time.Microsecond // Draw cost per frame on the render thread
+	minimapCostSmoothing   = 0.05                   // Weight of the latest frame in the average cost
+)
+
+// Minimap colors, as RGBA bytes
+var (
+	minimapFogColor      = [4]byte{0, 0, 0, 150}
+	minimapResourceColor = [4]byte{230, 200, 60, 255}
+	minimapTerrainColors = map[engine.AmbientEnvironment][3]float32{
+		engine.AmbientOpen:     {0.35, 0.55, 0.25},
+		engine.AmbientForest:   {0.15, 0.35, 0.15},
+		engine.AmbientWater:    {0.15, 0.3, 0.6},
+		engine.AmbientMountain: {0.5, 0.47, 0.43},
+	}
+	minimapFlashColor = sprite.Color{R: 1, G: 0.15, B: 0.1}
+)
+
+// Minimap shows the map in the top left corner under the resource bar with
+// the HUD sprite layer. The terrain is drawn once into a texture; fog of war
+// and the dots of units, buildings and resources are redrawn into a second
+// texture by a background goroutine at 10 Hz, so a frame only uploads the
+// newest overlay, if any, and draws two quads. Attack alerts flash on top.
+type Minimap struct {
+	world    *engine.World
+	playerID int
+	alerts   *AttackAlertIndicator // Nil for no flashes
+	width    int                   // Map size, in tiles; a texel per tile
+	height   int
+
+	terrain       sprite.Texture
+	overlay       sprite.Texture
+	terrainPixels []byte // Until the terrain is uploaded
+
+	cost       time.Duration // Average draw cost
+	overBudget bool          // Whether the cost was reported over budget
+
+	// Overlay exchange with the refresh goroutine
+	mutex sync.Mutex
+	ready []byte // Newest overlay, not uploaded yet
+	spare []byte // Uploaded overlay to draw the next into
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewMinimap creates a player's minimap of a world, flashing the attacks of
+// an alert indicator, and draws its terrain
+func NewMinimap(world *engine.World, playerID int, alerts *AttackAlertIndicator) *Minimap {
+	mm := &Minimap{world: world, playerID: playerID, alerts: alerts, width: world.Width, height: world.Height}
+	mm.terrainPixels = mm.drawTerrain()
+	return mm
+}
+
+// Start redraws the overlay in the background until Stop
+func (mm *Minimap) Start() {
+	if mm.stop != nil {
+		return
+	}
+	mm.stop, mm.done = make(chan struct{}), make(chan struct{})
+	go mm.refresh(mm.stop, mm.done)
+}
+
+// Stop stops redrawing the overlay and waits for the goroutine to exit
+func (mm *Minimap) Stop() {
+	if mm.stop == nil {
+		return
+	}
+	close(mm.stop)
+	<-mm.done
+	mm.stop, mm.done = nil, nil
+}
+
+// FrameCost returns the average time a frame spends drawing the minimap
+func (mm *Minimap) FrameCost() time.Duration {
+	return mm.cost
+}
+
+// refresh redraws the overlay at the refresh interval
+func (mm *Minimap) refresh(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(minimapRefreshInterval)
+	defer ticker.Stop()
+	for {
+		mm.publish(mm.drawOverlay(mm.takeSpare()))
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// takeSpare returns a buffer for the next overlay
+func (mm *Minimap) takeSpare() []byte {
+	mm.mutex.Lock()
+	pixels := mm.spare
+	mm.spare = nil
+	mm.mutex.Unlock()
+	if pixels == nil {
+		pixels = make([]byte, mm.width*mm.height*4)
+	}
+	return pixels
+}
+
+// publish makes an overlay the next to upload, replacing one not uploaded yet
+func (mm *Minimap) publish(pixels []byte) {
+	mm.mutex.Lock()
+	defer mm.mutex.Unlock()
+	if mm.ready != nil {
+		mm.spare = mm.ready
+	}
+	mm.ready = pixels
+}
+
+// Draw uploads the newest overlay and draws the minimap on the HUD
+func (mm *Minimap) Draw(canvas *renderer.HUDCanvas) {
+	if mm.width <= 0 || mm.height <= 0 {
+		return
+	}
+	start := time.Now()
+	defer mm.measure(start)
+
+	if mm.terrain == sprite.NoTexture {
+		mm.terrain = canvas.NewTexture(mm.width, mm.height, mm.terrainPixels)
+		if mm.terrain == sprite.NoTexture {
+			return
+		}
+		mm.overlay = canvas.NewTexture(mm.width, mm.height, make([]byte, mm.width*mm.height*4))
+		mm.terrainPixels = nil
+	}
+
+	mm.mutex.Lock()
+	pixels := mm.ready
+	mm.ready = nil
+	mm.mutex.Unlock()
+	if pixels != nil {
+		canvas.UpdateTexture(mm.overlay, pixels)
+		mm.mutex.Lock()
+		mm.spare = pixels
+		mm.mutex.Unlock()
+	}
+
+	rect := mm.rect()
+	canvas.Sprites.Fill(rect.Inset(-minimapBorder), hudPanelColor)
+	canvas.Sprites.Icon(rect, mm.terrain, sprite.White)
+	canvas.Sprites.Icon(rect, mm.overlay, sprite.White)
+	if mm.alerts != nil {
+		for _, flash := range mm.alerts.MinimapFlashes(start) {
+			color := minimapFlashColor
+			color.A = flash.Intensity
+			canvas.Sprites.Fill(sprite.Rect{
+				X: rect.X + flash.X*rect.W - minimapFlash/2,
+				Y: rect.Y + flash.Y*rect.H - minimapFlash/2,
+				W: minimapFlash,
+				H: minimapFlash,
+			}, color)
+		}
+	}
+}
+
+// rect returns where the minimap is drawn, keeping the map's shape
+func (mm *Minimap) rect() sprite.Rect {
+	w, h := float32(minimapSize), float32(minimapSize)
+	if mm.width > mm.height {
+		h = w * float32(mm.height) / float32(mm.width)
+	} else {
+		w = h * float32(mm.width) / float32(mm.height)
+	}
+	return sprite.Rect{X: minimapMargin, Y: resourceBarHeight + minimapMargin, W: w, H: h}
+}
+
+// measure adds a frame's draw cost to the average, reporting once when the
+// average goes over budget
+func (mm *Minimap) measure(start time.Time) {
+	elapsed := time.Since(start)
+	if mm.cost == 0 {
+		mm.cost = elapsed
+	} else {
+		mm.cost += time.Duration(minimapCostSmoothing * float64(elapsed-mm.cost))
+	}
+	if mm.cost > minimapFrameBudget && !mm.overBudget {
+		mm.overBudget = true
+		logging.Warnf(logging.CategoryRender, "Minimap takes %v per frame, over its %v budget", mm.cost, minimapFrameBudget)
+	}
+}
+
+// drawTerrain colors each tile by its environment, lighter on high ground;
+// without map data, walkable tiles are shaded by height
+func (mm *Minimap) drawTerrain() []byte {
+	pixels := make([]byte, mm.width*mm.height*4)
+	gameMap := mm.world.Map
+	for y := 0; y < mm.height; y++ {
+		for x := 0; x < mm.width; x++ {
+			environment := engine.AmbientOpen
+			var height float32
+			if gameMap != nil && gameMap.IsValidPosition(x, y) {
+				environment = gameMap.AmbientEnvironmentAt(x, y)
+				height = gameMap.GetHeightAt(x, y) - gameMap.WaterLevel
+			} else {
+				position := engine.Vector2i{X: x, Y: y}
+				if !mm.world.IsPositionWalkable(position) {
+					environment = engine.AmbientMountain
+				}
+				height = mm.world.GetHeight(position)
+			}
+			shade := 0.85 + 0.03*height
+			shade = max(0.6, min(1.3, shade))
+			color := minimapTerrainColors[environment]
+			i := (y*mm.width + x) * 4
+			for c := 0; c < 3; c++ {
+				pixels[i+c] = byte(min(255, color[c]*shade*255))
+			}
+			pixels[i+3] = 255
+		}
+	}
+	return pixels
+}
+
+// drawOverlay draws fog over the tiles out of the player's sight and a dot
+// for each resource, building and unit the player can see
+func (mm *Minimap) drawOverlay(pixels []byte) []byte {
+	visible := mm.world.VisibleTiles(mm.playerID)
+	for tile, seen := range visible {
+		color := minimapFogColor
+		if seen {
+			color = [4]byte{}
+		}
+		copy(pixels[tile*4:tile*4+4], color[:])
+	}
+
+	tileSize := float64(mm.world.GetTileSize())
+	// dot colors the tiles of a square of a size centered on a position,
+	// skipping enemies' out of sight
+	dot := func(position engine.Vector3, size int, color [4]byte, always bool) {
+		x0 := int(position.X/tileSize) - size/2
+		y0 := int(position.Z/tileSize) - size/2
+		for y := max(y0, 0); y < min(y0+size, mm.height); y++ {
+			for x := max(x0, 0); x < min(x0+size, mm.width); x++ {
+				if tile := y*mm.width + x; always || visible[tile] {
+					copy(pixels[tile*4:tile*4+4], color[:])
+				}
+			}
+		}
+	}
+
+	for _, node := range mm.world.GetAllResourceNodes() {
+		if node.Amount > 0 {
+			dot(node.Position, 1, minimapResourceColor, false)
+		}
+	}
+	for _, player := range mm.world.GetAllPlayers() {
+		known := player.ID == mm.playerID || mm.world.AreAllied(mm.playerID, player.ID)
+		color := minimapTeamColor(player.ID)
+		for _, building := range mm.world.ObjectManager.GetBuildingsForPlayer(player.ID) {
+			size := 2
+			if building.UnitDef != nil && building.UnitDef.Unit.Parameters.Size.Value > 0 {
+				size = building.UnitDef.Unit.Parameters.Size.Value
+			}
+			if building.IsAlive() {
+				dot(building.GetPosition(), size, color, known)
+			}
+		}
+		for _, unit := range mm.world.ObjectManager.GetUnitsForPlayer(player.ID) {
+			if unit.IsAlive() && unit.GarrisonedIn == 0 {
+				dot(unit.GetPosition(), 1, color, known)
+			}
+		}
+	}
+	return pixels
+}
+
+// minimapTeamColor returns a player's color as RGBA bytes
+func minimapTeamColor(playerID int) [4]byte {
+	color := graphics.TeamColor(playerID)
+	return [4]byte{byte(color.X() * 255), byte(color.Y() * 255), byte(color.Z() * 255), 255}
+}
@@ -0,0 +1,169 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+)
+
+// Observer panel tabs
+const (
+	observerTabProduction = iota
+	observerTabGraphs
+	observerTabCount
+)
+
+// ObserverPanel shows spectators and post-game review every player's
+// production queues, army and economy value graphs and APM. It reads through
+// an ObserverView, which ignores fog of war, so only spectators get one.
+type ObserverPanel struct {
+	view  *engine.ObserverView
+	world *engine.World
+
+	tab  int
+	open bool
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewObserverPanel creates an observer panel for a spectated world
+func NewObserverPanel(world *engine.World, recorder *engine.StatsRecorder) *ObserverPanel {
+	return &ObserverPanel{view: engine.NewObserverView(world, recorder), world: world}
+}
+
+// IsOpen returns whether the panel is shown
+func (op *ObserverPanel) IsOpen() bool {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	return op.open
+}
+
+// Open shows the panel
+func (op *ObserverPanel) Open() {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	op.open = true
+}
+
+// Close hides the panel
+func (op *ObserverPanel) Close() {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	op.open = false
+}
+
+// HandleKey processes a key press while the panel is open, returning true if it was consumed
+func (op *ObserverPanel) HandleKey(key glfw.Key) bool {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	if !op.open {
+		return false
+	}
+
+	switch key {
+	case glfw.KeyEscape, glfw.KeyO:
+		op.open = false
+	case glfw.KeyTab, glfw.KeyRight:
+		op.tab = (op.tab + 1) % observerTabCount
+	case glfw.KeyLeft:
+		op.tab = (op.tab + observerTabCount - 1) % observerTabCount
+	}
+
+	// The panel is modal: swallow every key while it is open
+	return true
+}
+
+// Draw draws the open tab in the middle of the HUD while the panel is open
+func (op *ObserverPanel) Draw(canvas *renderer.HUDCanvas) {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	if !op.open {
+		return
+	}
+	hint := "Tab or Left/Right to switch tabs, O or ESC to close"
+	switch op.tab {
+	case observerTabProduction:
+		drawScreen(canvas, "Observer: production", op.productionLines(), "", hint)
+	case observerTabGraphs:
+		op.drawGraphs(canvas, hint)
+	}
+}
+
+// productionLines lists what every player has in production (lock must be held)
+func (op *ObserverPanel) productionLines() []screenLine {
+	lines := make([]screenLine, 0)
+	for _, playerID := range op.view.Players() {
+		lines = append(lines, screenLine{text: fmt.Sprintf("%s (APM %.0f)", op.view.PlayerName(playerID), op.view.APM(playerID))})
+		production := op.view.Production(playerID)
+		if len(production) == 0 {
+			lines = append(lines, screenLine{text: "  nothing in production"})
+		}
+		for _, entry := range production {
+			line := fmt.Sprintf("  %-14s", entry.BuildingType)
+			if entry.Current != "" {
+				line += fmt.Sprintf(" %s %3.0f%%", entry.Current, entry.Progress*100)
+			}
+			if len(entry.Queued) > 0 {
+				line += "  then " + strings.Join(entry.Queued, ", ")
+			}
+			if entry.Upgrade != "" {
+				line += "  upgrading " + entry.Upgrade
+			}
+			lines = append(lines, screenLine{text: line})
+		}
+	}
+	return lines
+}
+
+// drawGraphs draws every player's APM and army and economy value, with
+// their value over the match as graphs (lock must be held)
+func (op *ObserverPanel) drawGraphs(canvas *renderer.HUDCanvas, hint string) {
+	players := op.view.Players()
+	lines := make([]screenLine, 0, 3*len(players))
+	armies := make([][]int, len(players))
+	economies := make([][]int, len(players))
+	for i, playerID := range players {
+		values := op.view.Values(playerID)
+		armies[i] = make([]int, len(values))
+		economies[i] = make([]int, len(values))
+		for j, sample := range values {
+			armies[i][j], economies[i][j] = sample.Army, sample.Economy
+		}
+		current := engine.ValueSample{}
+		if len(values) > 0 {
+			current = values[len(values)-1]
+		}
+		lines = append(lines,
+			screenLine{text: fmt.Sprintf("%s  APM %.0f", op.view.PlayerName(playerID), op.view.APM(playerID))},
+			screenLine{text: fmt.Sprintf("  army    %6d", current.Army)},
+			screenLine{text: fmt.Sprintf("  economy %6d", current.Economy)})
+	}
+
+	panel := drawScreen(canvas, "Observer: army and economy value", lines, "", hint)
+	for i := range players {
+		drawTrend(canvas, panel, 3*i+1, trendLevels(downsample(armies[i], economyGraphWidth)))
+		drawTrend(canvas, panel, 3*i+2, trendLevels(downsample(economies[i], economyGraphWidth)))
+	}
+}
+
+// downsample picks evenly spaced values so a whole match fits in a graph
+// of the given width, keeping the latest value
+func downsample(values []int, width int) []int {
+	if len(values) <= width {
+		return values
+	}
+	picked := make([]int, width)
+	for i := range picked {
+		picked[i] = values[(i+1)*len(values)/width-1]
+	}
+	return picked
+}
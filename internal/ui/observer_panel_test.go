@@ -0,0 +1,66 @@
+//go:build !js
+
+package ui
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+)
+
+// TestObserverPanel tests that O opens the panel only once it is given to
+// the input, and that the graphs tab draws the values sampled by the
+// statistics recorder
+func TestObserverPanel(t *testing.T) {
+	world, err := engine.NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	for playerID := 1; playerID <= 2; playerID++ {
+		soldier, err := world.ObjectManager.CreateUnit(playerID, "soldier", engine.Vector3{X: float64(playerID*10) + 0.5, Z: 2.5},
+			data.NewSimpleUnit("soldier", 100, 0, "leather", map[string]int{"gold": 75}))
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		soldier.AttackDamage = 10
+	}
+	recorder := engine.NewStatsRecorder(world)
+	recorder.Sample()
+	recorder.Sample()
+
+	panel := NewObserverPanel(world, recorder)
+	input := NewInputHandler(world, NewSimpleUIManager(world))
+	input.HandleKeyboard(nil, glfw.KeyO, 0, glfw.Press, 0)
+	if panel.IsOpen() {
+		t.Fatal("Expected O to do nothing before the panel is given to the input")
+	}
+	input.SetObserverPanel(panel)
+	input.HandleKeyboard(nil, glfw.KeyO, 0, glfw.Press, 0)
+	if !panel.IsOpen() {
+		t.Fatal("Expected O to open the observer panel")
+	}
+
+	production := &renderer.HUDCanvas{Sprites: sprite.NewBatch(), Width: 1024, Height: 768}
+	panel.Draw(production)
+	if production.Sprites.Empty() {
+		t.Fatal("Expected the production tab drawn on the HUD")
+	}
+
+	// Two samples of army and economy value for each of the two players
+	input.HandleKeyboard(nil, glfw.KeyTab, 0, glfw.Press, 0)
+	graphs := &renderer.HUDCanvas{Sprites: sprite.NewBatch(), Width: 1024, Height: 768}
+	panel.Draw(graphs)
+	if len(graphs.Sprites.Vertices()) <= len(production.Sprites.Vertices()) {
+		t.Error("Expected the graphs tab to draw value bars over its panel")
+	}
+
+	input.HandleKeyboard(nil, glfw.KeyEscape, 0, glfw.Press, 0)
+	if panel.IsOpen() || input.menuOpen() {
+		t.Error("Expected ESC to close the observer panel")
+	}
+}
@@ -0,0 +1,146 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/graphics/renderer"
+)
+
+// Option is a setting on the options panel, cycled through a list of values
+// and applied as soon as it changes
+type Option struct {
+	Label   string
+	Values  []string
+	Current int                   // Index of the value in effect
+	Apply   func(index int) error // Puts the value at index into effect
+}
+
+// OptionsPanel lists the game options opened from the pause menu: Up/Down
+// picks an option and Left/Right changes it, applying it right away
+type OptionsPanel struct {
+	options  []*Option
+	selected int
+	open     bool
+	message  string // Result of the last change
+
+	mutex sync.Mutex
+}
+
+// NewOptionsPanel creates an options panel without options
+func NewOptionsPanel() *OptionsPanel {
+	return &OptionsPanel{}
+}
+
+// SetOptions replaces the options listed, e.g. with their values in effect
+// before the panel opens
+func (op *OptionsPanel) SetOptions(options []*Option) {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	op.options = options
+	op.selected = 0
+}
+
+// IsOpen returns whether the panel is shown
+func (op *OptionsPanel) IsOpen() bool {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	return op.open
+}
+
+// Open shows the panel with the first option selected
+func (op *OptionsPanel) Open() {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	op.open, op.selected, op.message = true, 0, ""
+}
+
+// Close hides the panel
+func (op *OptionsPanel) Close() {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	op.open = false
+}
+
+// HandleKey processes a key press while the panel is open, returning true
+// if it was consumed
+func (op *OptionsPanel) HandleKey(key glfw.Key) bool {
+	if !op.IsOpen() {
+		return false
+	}
+
+	switch key {
+	case glfw.KeyEscape, glfw.KeyEnter, glfw.KeyKPEnter:
+		op.Close()
+	case glfw.KeyUp, glfw.KeyW:
+		op.moveSelection(-1)
+	case glfw.KeyDown, glfw.KeyS:
+		op.moveSelection(1)
+	case glfw.KeyLeft, glfw.KeyA:
+		op.Change(-1)
+	case glfw.KeyRight, glfw.KeyD:
+		op.Change(1)
+	}
+
+	// The panel is modal: swallow every key while it is open
+	return true
+}
+
+// Change steps the selected option through its values and applies the new
+// one; the option keeps its value when applying fails
+func (op *OptionsPanel) Change(delta int) error {
+	op.mutex.Lock()
+	if len(op.options) == 0 {
+		op.mutex.Unlock()
+		return nil
+	}
+	option := op.options[op.selected]
+	count := len(option.Values)
+	next := ((option.Current+delta)%count + count) % count
+	op.mutex.Unlock()
+
+	// Options apply without the panel lock, as they may reach into the game
+	var err error
+	if option.Apply != nil {
+		err = option.Apply(next)
+	}
+
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+	if err != nil {
+		op.message = fmt.Sprintf("%s: %v", option.Label, err)
+		return err
+	}
+	option.Current = next
+	op.message = ""
+	return nil
+}
+
+// Draw draws the panel in the middle of the HUD while it is open
+func (op *OptionsPanel) Draw(canvas *renderer.HUDCanvas) {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	if !op.open {
+		return
+	}
+	lines := make([]screenLine, len(op.options))
+	for i, option := range op.options {
+		lines[i] = screenLine{text: fmt.Sprintf("%-16s < %s >", option.Label, option.Values[option.Current]), selected: i == op.selected}
+	}
+	drawScreen(canvas, "Options", lines, op.message, "Up/Down to pick, Left/Right to change, ESC to go back")
+}
+
+// moveSelection moves the highlight up or down, wrapping around
+func (op *OptionsPanel) moveSelection(delta int) {
+	op.mutex.Lock()
+	defer op.mutex.Unlock()
+
+	if count := len(op.options); count > 0 {
+		op.selected = ((op.selected+delta)%count + count) % count
+	}
+}
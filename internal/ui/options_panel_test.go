@@ -0,0 +1,69 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+)
+
+// TestOptionsPanel tests that changing an option applies it at once, that a
+// failed change keeps the value in effect, and that the pause menu and the
+// panel draw on the HUD only while open
+func TestOptionsPanel(t *testing.T) {
+	applied := make([]int, 0)
+	vsync := &Option{Label: "VSync", Values: []string{"Off", "On"}, Current: 1, Apply: func(index int) error {
+		applied = append(applied, index)
+		return nil
+	}}
+	audio := &Option{Label: "Audio", Values: []string{"Off", "On"}, Apply: func(index int) error {
+		return fmt.Errorf("audio failed to start")
+	}}
+
+	menu := NewPauseMenu()
+	panel := NewOptionsPanel()
+	menu.SetHandler(PauseMenuOptions, func() error {
+		panel.SetOptions([]*Option{vsync, audio})
+		panel.Open()
+		return nil
+	})
+
+	canvas := &renderer.HUDCanvas{Sprites: sprite.NewBatch(), Width: 1024, Height: 768}
+	menu.Draw(canvas)
+	panel.Draw(canvas)
+	if !canvas.Sprites.Empty() {
+		t.Fatal("Expected nothing drawn while the menus are closed")
+	}
+
+	menu.Open()
+	if err := menu.ActivateAction(PauseMenuOptions); err != nil || !panel.IsOpen() {
+		t.Fatalf("Expected Options to open the panel, got %v", err)
+	}
+	menu.Draw(canvas)
+	panel.Draw(canvas)
+	if canvas.Sprites.Empty() {
+		t.Error("Expected the pause menu and options drawn on the HUD")
+	}
+
+	// Right wraps VSync round to Off and applies it
+	panel.HandleKey(glfw.KeyRight)
+	if len(applied) != 1 || applied[0] != 0 || vsync.Current != 0 {
+		t.Errorf("Expected VSync switched off, got applied %v and value %d", applied, vsync.Current)
+	}
+
+	panel.HandleKey(glfw.KeyDown)
+	panel.HandleKey(glfw.KeyRight)
+	if audio.Current != 0 || panel.message == "" {
+		t.Errorf("Expected the failed audio change reported and undone, got value %d", audio.Current)
+	}
+
+	panel.HandleKey(glfw.KeyEscape)
+	if panel.IsOpen() || !menu.IsOpen() {
+		t.Error("Expected ESC to go back to the pause menu")
+	}
+}
@@ -0,0 +1,332 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/graphics/text"
+)
+
+// Modal screen layout, in pixels
+const (
+	screenWidth     = 560
+	screenPadding   = 12
+	screenLineStep  = renderer.DefaultTextSize + 6
+	screenLineChars = 72 // Longer text is wrapped onto further lines
+)
+
+// screenSelectionColor backs the highlighted line of a modal screen
+var screenSelectionColor = sprite.Color{R: 0.2, G: 0.4, B: 0.8, A: 0.6}
+
+// screenLine is a line of text on a modal screen
+type screenLine struct {
+	text     string
+	selected bool // Drawn over the selection highlight
+}
+
+// PauseMenuAction identifies an entry in the pause menu
+type PauseMenuAction int
+
+const (
+	PauseMenuResume       PauseMenuAction = iota // Close the menu and resume play
+	PauseMenuSaveGame                            // Save the current match
+	PauseMenuLoadGame                            // Load a saved match
+	PauseMenuOptions                             // Show game options
+	PauseMenuProfile                             // Show the player profile
+	PauseMenuEncyclopedia                        // Browse the units, buildings and upgrades
+	PauseMenuSurrender                           // Concede the match
+	PauseMenuRematch                             // Play the match again with the same settings
+	PauseMenuNewMatch                            // Start a fresh match in a new world
+	PauseMenuQuitToMenu                          // Leave the match
+)
+
+// String returns the display label of a PauseMenuAction
+func (a PauseMenuAction) String() string {
+	switch a {
+	case PauseMenuResume:
+		return "Resume"
+	case PauseMenuSaveGame:
+		return "Save Game"
+	case PauseMenuLoadGame:
+		return "Load Game"
+	case PauseMenuOptions:
+		return "Options"
+	case PauseMenuProfile:
+		return "Profile"
+	case PauseMenuEncyclopedia:
+		return "Encyclopedia"
+	case PauseMenuSurrender:
+		return "Surrender"
+	case PauseMenuRematch:
+		return "Rematch"
+	case PauseMenuNewMatch:
+		return "New Match"
+	case PauseMenuQuitToMenu:
+		return "Quit to Menu"
+	default:
+		return "Unknown"
+	}
+}
+
+// PauseMenu is the in-game overlay shown when the player presses ESC
+type PauseMenu struct {
+	items    []PauseMenuAction
+	selected int
+	open     bool
+	message  string // Result of the last action
+
+	// Callbacks
+	handlers           map[PauseMenuAction]func() error
+	onVisibilityChange func(open bool)
+
+	// Threading
+	mutex sync.RWMutex
+}
+
+// NewPauseMenu creates a new pause menu with the default entries
+func NewPauseMenu() *PauseMenu {
+	return &PauseMenu{
+		items: []PauseMenuAction{
+			PauseMenuResume,
+			PauseMenuSaveGame,
+			PauseMenuLoadGame,
+			PauseMenuOptions,
+			PauseMenuProfile,
+			PauseMenuEncyclopedia,
+			PauseMenuSurrender,
+			PauseMenuRematch,
+			PauseMenuNewMatch,
+			PauseMenuQuitToMenu,
+		},
+		handlers: make(map[PauseMenuAction]func() error),
+	}
+}
+
+// SetHandler registers the function run when an action is activated
+func (pm *PauseMenu) SetHandler(action PauseMenuAction, handler func() error) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.handlers[action] = handler
+}
+
+// SetVisibilityHandler registers a function called when the menu opens or closes
+func (pm *PauseMenu) SetVisibilityHandler(handler func(open bool)) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.onVisibilityChange = handler
+}
+
+// IsOpen returns whether the menu is currently shown
+func (pm *PauseMenu) IsOpen() bool {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	return pm.open
+}
+
+// Open shows the menu with the first entry selected
+func (pm *PauseMenu) Open() {
+	pm.setOpen(true)
+}
+
+// Close hides the menu
+func (pm *PauseMenu) Close() {
+	pm.setOpen(false)
+}
+
+// Toggle opens the menu if closed, otherwise closes it
+func (pm *PauseMenu) Toggle() {
+	pm.setOpen(!pm.IsOpen())
+}
+
+// SelectedAction returns the currently highlighted action
+func (pm *PauseMenu) SelectedAction() PauseMenuAction {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+	return pm.items[pm.selected]
+}
+
+// MoveSelection moves the highlight up or down, wrapping around
+func (pm *PauseMenu) MoveSelection(delta int) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	count := len(pm.items)
+	pm.selected = ((pm.selected+delta)%count + count) % count
+}
+
+// Activate runs the highlighted action
+func (pm *PauseMenu) Activate() error {
+	return pm.ActivateAction(pm.SelectedAction())
+}
+
+// ActivateAction runs a specific action; Resume, Rematch, New Match and Quit to Menu also close the menu
+func (pm *PauseMenu) ActivateAction(action PauseMenuAction) error {
+	pm.mutex.RLock()
+	handler := pm.handlers[action]
+	pm.mutex.RUnlock()
+
+	// Handlers run without the menu lock so they can call back into the menu
+	var err error
+	if handler != nil {
+		err = handler()
+	}
+
+	pm.mutex.Lock()
+	if err != nil {
+		pm.message = fmt.Sprintf("%s failed: %v", action, err)
+	} else {
+		pm.message = ""
+	}
+	pm.mutex.Unlock()
+
+	if err == nil && (action == PauseMenuResume || action == PauseMenuRematch || action == PauseMenuNewMatch || action == PauseMenuQuitToMenu) {
+		pm.Close()
+	}
+	return err
+}
+
+// HandleKey processes a key press while the menu is open, returning true if it was consumed
+func (pm *PauseMenu) HandleKey(key glfw.Key) bool {
+	if !pm.IsOpen() {
+		return false
+	}
+
+	switch key {
+	case glfw.KeyEscape:
+		pm.ActivateAction(PauseMenuResume)
+	case glfw.KeyUp, glfw.KeyW:
+		pm.MoveSelection(-1)
+	case glfw.KeyDown, glfw.KeyS:
+		pm.MoveSelection(1)
+	case glfw.KeyEnter, glfw.KeyKPEnter, glfw.KeySpace:
+		pm.Activate()
+	}
+
+	// The menu is modal: swallow every key while it is open
+	return true
+}
+
+// Draw draws the menu in the middle of the HUD while it is open
+func (pm *PauseMenu) Draw(canvas *renderer.HUDCanvas) {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	if !pm.open {
+		return
+	}
+	lines := make([]screenLine, len(pm.items))
+	for i, item := range pm.items {
+		lines[i] = screenLine{text: item.String(), selected: i == pm.selected}
+	}
+	drawScreen(canvas, "Game Paused", lines, pm.message, "Up/Down to select, Enter to confirm, ESC to resume")
+}
+
+// setOpen changes visibility and notifies the visibility handler
+func (pm *PauseMenu) setOpen(open bool) {
+	pm.mutex.Lock()
+	if pm.open == open {
+		pm.mutex.Unlock()
+		return
+	}
+	pm.open = open
+	if open {
+		pm.selected = 0
+		pm.message = ""
+	}
+	handler := pm.onVisibilityChange
+	pm.mutex.Unlock()
+
+	if handler != nil {
+		handler(open)
+	}
+}
+
+// drawScreen draws a modal screen in the middle of the HUD: a title over
+// lines of text, the message of the last action and a hint of the keys. It
+// returns the screen's rectangle.
+func drawScreen(canvas *renderer.HUDCanvas, title string, lines []screenLine, message, hint string) sprite.Rect {
+	return drawScreenAt(canvas, (float32(canvas.Width)-screenWidth)/2, screenWidth, title, lines, message, hint)
+}
+
+// drawScreenAt draws a modal screen of a width at a distance from the left
+// edge of the HUD, centered vertically
+func drawScreenAt(canvas *renderer.HUDCanvas, x, width float32, title string, lines []screenLine, message, hint string) sprite.Rect {
+	rows := len(lines) + 2 // Title and hint
+	if message != "" {
+		rows++
+	}
+	panel := sprite.Rect{X: x, W: width, H: float32(rows*screenLineStep + 2*screenPadding)}
+	panel.Y = max(0, (float32(canvas.Height)-panel.H)/2)
+	canvas.Sprites.Fill(panel, hudPanelColor)
+
+	inner := panel.Inset(screenPadding)
+	y := inner.Y
+	canvas.Text.DrawScreenText(inner.X, y, title, renderer.DefaultTextSize*1.2, hudTextColor, text.AnchorTopLeft)
+	y += screenLineStep
+	for _, line := range lines {
+		if line.selected {
+			canvas.Sprites.Fill(sprite.Rect{X: inner.X - 4, Y: y - 2, W: inner.W + 8, H: screenLineStep}, screenSelectionColor)
+		}
+		canvas.Text.DrawScreenText(inner.X, y, line.text, renderer.DefaultTextSize, hudTextColor, text.AnchorTopLeft)
+		y += screenLineStep
+	}
+	if message != "" {
+		canvas.Text.DrawScreenText(inner.X, y, message, renderer.DefaultTextSize, hudTextColor, text.AnchorTopLeft)
+		y += screenLineStep
+	}
+	canvas.Text.DrawScreenText(inner.X, y, hint, renderer.DefaultTextSize*0.8, hudTextColor, text.AnchorTopLeft)
+	return panel
+}
+
+// choiceLines returns a line for each choice, the selected one highlighted
+func choiceLines(choices []string, selected int) []screenLine {
+	lines := make([]screenLine, len(choices))
+	for i, choice := range choices {
+		lines[i] = screenLine{text: choice, selected: i == selected}
+	}
+	return lines
+}
+
+// textLines returns lines of text, wrapping the long ones at word breaks
+func textLines(texts ...string) []screenLine {
+	return wrappedLines(screenLineChars, texts...)
+}
+
+// wrappedLines returns lines of text, wrapping those longer than limit
+// characters at word breaks
+func wrappedLines(limit int, texts ...string) []screenLine {
+	lines := make([]screenLine, 0, len(texts))
+	for _, t := range texts {
+		for _, wrapped := range wrapText(t, limit) {
+			lines = append(lines, screenLine{text: wrapped})
+		}
+	}
+	return lines
+}
+
+// wrapText breaks text into lines of at most limit characters at word breaks
+func wrapText(t string, limit int) []string {
+	wrapped := make([]string, 0, 1)
+	line := ""
+	for _, word := range strings.Fields(t) {
+		if line != "" && len(line)+1+len(word) > limit {
+			wrapped = append(wrapped, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" || len(wrapped) == 0 {
+		wrapped = append(wrapped, line)
+	}
+	return wrapped
+}
@@ -0,0 +1,66 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/graphics/text"
+)
+
+// Performance overlay layout, in pixels
+const (
+	performanceLineHeight = 18
+	performancePadding    = 6
+	performanceWidth      = 300
+	performanceTop        = resourceBarHeight + 8
+)
+
+// PerformanceOverlay shows frame rate, draw calls and the GPU memory held by
+// model and texture caches in the top right corner, while stats are toggled on
+type PerformanceOverlay struct {
+	renderer *renderer.Renderer
+}
+
+// NewPerformanceOverlay creates an overlay showing a renderer's statistics
+func NewPerformanceOverlay(r *renderer.Renderer) *PerformanceOverlay {
+	return &PerformanceOverlay{renderer: r}
+}
+
+// Draw draws the overlay on the HUD when the renderer's stats are visible
+func (po *PerformanceOverlay) Draw(canvas *renderer.HUDCanvas) {
+	if !po.renderer.StatsVisible() || canvas.Text == nil {
+		return
+	}
+	stats := po.renderer.Stats()
+	residency := stats.Residency
+
+	budget := "unlimited"
+	if residency.Budget > 0 {
+		budget = fmt.Sprintf("%.0f MB", toMegabytes(residency.Budget))
+	}
+	lines := []string{
+		fmt.Sprintf("%.0f FPS, %d draws, %d instances", stats.FPS, stats.DrawCalls, stats.Instances),
+		fmt.Sprintf("GPU memory %.1f MB of %s", toMegabytes(residency.Bytes), budget),
+		fmt.Sprintf("Textures %d (%.1f MB), models %d (%.1f MB)",
+			residency.Textures, toMegabytes(residency.TextureBytes), residency.Models, toMegabytes(residency.ModelBytes)),
+		fmt.Sprintf("%d held, %d evicted", residency.Referenced, residency.Evictions),
+		fmt.Sprintf("Terrain %d chunks (%.1f MB), %d pending",
+			stats.Terrain.Chunks, toMegabytes(stats.Terrain.Bytes), stats.Terrain.Pending),
+	}
+
+	x := float32(canvas.Width - performanceWidth)
+	panel := sprite.Rect{X: x, Y: performanceTop, W: performanceWidth, H: float32(len(lines)*performanceLineHeight + 2*performancePadding)}
+	canvas.Sprites.Fill(panel, hudPanelColor)
+	for i, line := range lines {
+		y := float32(performanceTop + performancePadding + i*performanceLineHeight)
+		canvas.Text.DrawScreenText(x+performancePadding, y, line, renderer.DefaultTextSize, hudTextColor, text.AnchorTopLeft)
+	}
+}
+
+// toMegabytes converts a byte count for display
+func toMegabytes(bytes int64) float64 {
+	return float64(bytes) / (1 << 20)
+}
@@ -0,0 +1,70 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"teraglest/internal/engine"
+)
+
+// PowerIndicator marks the local player's buildings that paused production
+// for lack of workers or energy, and announces when they pause or resume
+type PowerIndicator struct {
+	unpowered map[int]engine.BuildingPower // Building ID -> why it is paused
+	notices   []string                     // Changes not shown yet
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewPowerIndicator creates an empty power indicator
+func NewPowerIndicator() *PowerIndicator {
+	return &PowerIndicator{unpowered: make(map[int]engine.BuildingPower)}
+}
+
+// HandleEvent tracks building power events; it returns false for other events
+func (pi *PowerIndicator) HandleEvent(event engine.GameEvent) bool {
+	power, ok := event.Data.(engine.BuildingPower)
+	if event.Type != engine.EventTypeBuildingPower || !ok {
+		return false
+	}
+
+	pi.mutex.Lock()
+	defer pi.mutex.Unlock()
+	if power.Powered {
+		delete(pi.unpowered, power.BuildingID)
+		pi.notices = append(pi.notices, fmt.Sprintf("Your %s resumed production", power.BuildingType))
+	} else {
+		pi.unpowered[power.BuildingID] = power
+		pi.notices = append(pi.notices, fmt.Sprintf("Your %s paused production: %s", power.BuildingType, power.Shortage))
+	}
+	return true
+}
+
+// Unpowered returns the buildings currently paused, by building ID
+func (pi *PowerIndicator) Unpowered() []engine.BuildingPower {
+	pi.mutex.Lock()
+	defer pi.mutex.Unlock()
+
+	buildings := make([]engine.BuildingPower, 0, len(pi.unpowered))
+	for _, power := range pi.unpowered {
+		buildings = append(buildings, power)
+	}
+	sort.Slice(buildings, func(i, j int) bool { return buildings[i].BuildingID < buildings[j].BuildingID })
+	return buildings
+}
+
+// Render shows power changes once as they arrive (console output until text
+// rendering exists)
+func (pi *PowerIndicator) Render() {
+	pi.mutex.Lock()
+	defer pi.mutex.Unlock()
+
+	for _, notice := range pi.notices {
+		fmt.Println(notice)
+	}
+	pi.notices = pi.notices[:0]
+}
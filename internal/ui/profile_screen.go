@@ -0,0 +1,224 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/achievement"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/profile"
+)
+
+// ProfileScreen shows the active player profile with its career statistics and
+// lets the player change the preferred faction and export or import profiles
+type ProfileScreen struct {
+	store    *profile.Store
+	profile  *profile.Profile
+	factions []string // Factions the preferred faction cycles through
+	statuses func() []achievement.Status
+	open     bool
+	message  string // Result of the last action
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewProfileScreen creates a screen for the active profile of a store
+func NewProfileScreen(store *profile.Store, active *profile.Profile, factions []string) *ProfileScreen {
+	return &ProfileScreen{
+		store:    store,
+		profile:  active,
+		factions: append([]string(nil), factions...),
+	}
+}
+
+// Profile returns the profile shown
+func (ps *ProfileScreen) Profile() *profile.Profile {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	return ps.profile
+}
+
+// SetProfile shows another profile, e.g. after a match updated the statistics
+func (ps *ProfileScreen) SetProfile(active *profile.Profile) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.profile = active
+}
+
+// SetAchievementSource sets where the achievement list and progress come from
+func (ps *ProfileScreen) SetAchievementSource(statuses func() []achievement.Status) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.statuses = statuses
+}
+
+// IsOpen returns whether the screen is shown
+func (ps *ProfileScreen) IsOpen() bool {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	return ps.open
+}
+
+// Open shows the screen
+func (ps *ProfileScreen) Open() {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.open = true
+	ps.message = ""
+}
+
+// Close hides the screen
+func (ps *ProfileScreen) Close() {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.open = false
+}
+
+// CycleFaction changes the preferred faction to the next (or previous) one and saves the profile
+func (ps *ProfileScreen) CycleFaction(delta int) error {
+	ps.mutex.Lock()
+	next, err := cycle(ps.factions, ps.profile.PreferredFaction, delta)
+	if err == nil {
+		ps.profile.PreferredFaction = next
+		err = ps.store.Save(ps.profile)
+	}
+	ps.mutex.Unlock()
+	return ps.report("Change faction", err)
+}
+
+// Export writes the profile to path for moving it to another machine
+func (ps *ProfileScreen) Export(path string) error {
+	ps.mutex.Lock()
+	name := ps.profile.Name
+	ps.mutex.Unlock()
+
+	err := ps.store.Export(name, path)
+	if err == nil {
+		ps.mutex.Lock()
+		ps.message = fmt.Sprintf("Exported to %s", path)
+		ps.mutex.Unlock()
+		return nil
+	}
+	return ps.report("Export", err)
+}
+
+// Import stores the profile exported to path, replacing a profile of the
+// same name, and makes it the active profile
+func (ps *ProfileScreen) Import(path string) error {
+	imported, err := ps.store.Import(path, true)
+	if err == nil {
+		err = ps.store.SetActive(imported.Name)
+	}
+	if err == nil {
+		ps.SetProfile(imported)
+	}
+	return ps.report("Import", err)
+}
+
+// HandleKey processes a key press while the screen is open, returning true if it was consumed
+func (ps *ProfileScreen) HandleKey(key glfw.Key) bool {
+	if !ps.IsOpen() {
+		return false
+	}
+
+	switch key {
+	case glfw.KeyLeft:
+		ps.CycleFaction(-1)
+	case glfw.KeyRight, glfw.KeyF:
+		ps.CycleFaction(1)
+	case glfw.KeyEscape, glfw.KeyEnter, glfw.KeyKPEnter:
+		ps.Close()
+	}
+
+	// The screen is modal: swallow every key while it is open
+	return true
+}
+
+// Draw draws the profile in the middle of the HUD while the screen is open
+func (ps *ProfileScreen) Draw(canvas *renderer.HUDCanvas) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if !ps.open {
+		return
+	}
+
+	p := ps.profile
+	total := p.Stats.Total()
+	texts := []string{
+		fmt.Sprintf("Preferred faction: %s  Hotkeys: %s", orNone(p.PreferredFaction), p.HotkeyProfile),
+		fmt.Sprintf("Career: %d played, %d won, %d lost (%.0f%%), %s played",
+			total.Played(), total.Wins, total.Losses, total.WinRate()*100, p.Stats.PlayTime.Round(time.Second)),
+	}
+	if factions := p.Factions(); len(factions) > 0 {
+		records := make([]string, len(factions))
+		for i, faction := range factions {
+			record := p.Stats.ByFaction[faction]
+			records[i] = fmt.Sprintf("%s %d-%d", faction, record.Wins, record.Losses)
+		}
+		texts = append(texts, "By faction: "+strings.Join(records, ", "))
+	}
+	if maps := p.Maps(); len(maps) > 0 {
+		records := make([]string, len(maps))
+		for i, name := range maps {
+			record := p.Stats.ByMap[name]
+			records[i] = fmt.Sprintf("%s %d-%d", name, record.Wins, record.Losses)
+		}
+		texts = append(texts, "By map: "+strings.Join(records, ", "))
+	}
+	if ps.statuses != nil {
+		texts = append(texts, achievementLines(ps.statuses())...)
+	}
+	drawScreen(canvas, "Profile: "+p.Name, textLines(texts...), ps.message,
+		"Left/Right preferred faction, ESC back; console: profile export|import <file>")
+}
+
+// achievementLines lists achievements with their progress; hidden ones stay secret until unlocked
+func achievementLines(statuses []achievement.Status) []string {
+	unlocked := 0
+	for _, status := range statuses {
+		if status.Unlocked {
+			unlocked++
+		}
+	}
+	lines := []string{fmt.Sprintf("Achievements: %d/%d", unlocked, len(statuses))}
+	for _, status := range statuses {
+		switch {
+		case status.Unlocked:
+			lines = append(lines, fmt.Sprintf("[x] %s: %s (%s)", status.Name, status.Description, status.UnlockedAt.Format("2006-01-02")))
+		case status.Hidden:
+			lines = append(lines, "[ ] Hidden: ???")
+		default:
+			lines = append(lines, fmt.Sprintf("[ ] %s: %s (%.0f%%)", status.Name, status.Description, status.Progress*100))
+		}
+	}
+	return lines
+}
+
+// report records the outcome of an action for display and returns err
+func (ps *ProfileScreen) report(action string, err error) error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if err != nil {
+		ps.message = fmt.Sprintf("%s failed: %v", action, err)
+	} else {
+		ps.message = ""
+	}
+	return err
+}
+
+// orNone returns value, or "(none)" if it is empty
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
@@ -0,0 +1,110 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/graphics/text"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Resource bar layout, in pixels
+const (
+	resourceBarHeight  = 28
+	resourceBarPadding = 4
+	resourceIconSize   = 20
+	resourceEntryWidth = 110
+)
+
+// Resource bar colors
+var (
+	hudPanelColor = sprite.Color{R: 0.08, G: 0.08, B: 0.1, A: 0.8}
+	hudTextColor  = mgl32.Vec4{1, 1, 1, 1}
+)
+
+// ResourceBar draws a player's stockpile across the top of the screen with
+// the HUD sprite layer: an icon and the amount for each resource
+type ResourceBar struct {
+	world    *engine.World
+	playerID int
+	order    []string          // Resources in tech tree order
+	icons    map[string]string // Resource -> icon relative to the tech tree root
+	panel    *sprite.NinePatch // Background; nil for a plain panel
+}
+
+// NewResourceBar creates a resource bar for a player, with icons from the
+// tech tree's resource definitions
+func NewResourceBar(world *engine.World, playerID int, resources []data.ResourceDefinition) *ResourceBar {
+	rb := &ResourceBar{world: world, playerID: playerID, icons: make(map[string]string)}
+	for _, resource := range resources {
+		rb.order = append(rb.order, resource.Name)
+		if image := resource.Resource.Image.Path; image != "" {
+			rb.icons[resource.Name] = path.Join("resources", resource.Name, image)
+		}
+	}
+	return rb
+}
+
+// SetPanel sets the nine-patch drawn behind the bar
+func (rb *ResourceBar) SetPanel(panel sprite.NinePatch) {
+	rb.panel = &panel
+}
+
+// Draw draws the bar on the HUD
+func (rb *ResourceBar) Draw(canvas *renderer.HUDCanvas) {
+	stock := rb.world.GetResourceStatus(rb.playerID).Resources
+	if len(stock) == 0 {
+		return
+	}
+	resources := rb.resources(stock)
+
+	bar := sprite.Rect{X: 0, Y: 0, W: float32(canvas.Width), H: resourceBarHeight}
+	if rb.panel != nil {
+		canvas.Sprites.Panel(bar, *rb.panel, sprite.White)
+	} else {
+		canvas.Sprites.Fill(bar, hudPanelColor)
+	}
+
+	x := float32(resourceBarPadding)
+	iconY := float32(resourceBarHeight-resourceIconSize) / 2
+	for _, resource := range resources {
+		label := fmt.Sprintf("%d", stock[resource])
+		if icon := canvas.Texture(rb.icons[resource]); icon != sprite.NoTexture {
+			canvas.Sprites.Icon(sprite.Rect{X: x, Y: iconY, W: resourceIconSize, H: resourceIconSize}, icon, sprite.White)
+		} else {
+			label = fmt.Sprintf("%s %d", resource, stock[resource])
+		}
+		canvas.Text.DrawScreenText(x+resourceIconSize+resourceBarPadding, resourceBarHeight/2,
+			label, renderer.DefaultTextSize, hudTextColor, text.AnchorMiddleLeft)
+		x += resourceEntryWidth
+	}
+}
+
+// resources returns the resources to show: the tech tree's in its order,
+// then any others the player holds, sorted
+func (rb *ResourceBar) resources(stock map[string]int) []string {
+	shown := make(map[string]bool, len(rb.order))
+	resources := make([]string, 0, len(stock))
+	for _, resource := range rb.order {
+		if _, held := stock[resource]; held {
+			resources = append(resources, resource)
+			shown[resource] = true
+		}
+	}
+	var others []string
+	for resource := range stock {
+		if !shown[resource] {
+			others = append(others, resource)
+		}
+	}
+	sort.Strings(others)
+	return append(resources, others...)
+}
@@ -0,0 +1,333 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/engine"
+)
+
+// saveSortFields are the orders the browser cycles through, with their labels
+var saveSortFields = []struct {
+	field engine.SaveFileSortField
+	label string
+}{
+	{engine.SortSaveFilesByDate, "date"},
+	{engine.SortSaveFilesByName, "name"},
+	{engine.SortSaveFilesByMap, "map"},
+	{engine.SortSaveFilesByDuration, "duration"},
+}
+
+// SaveGameBrowser lists the savegames and replays in the user data
+// directory with their map, players, duration and date; it sorts, renames
+// and deletes them, and loads a savegame or plays a replay through handlers
+type SaveGameBrowser struct {
+	dirs  map[engine.SaveFileKind]string
+	kind  engine.SaveFileKind // Savegames or replays shown
+	files []engine.SaveFileInfo
+
+	selected      int
+	sort          int // Index into saveSortFields
+	ascending     bool
+	confirmDelete bool // Delete pressed once on the selected file
+	open          bool
+	dirty         bool   // Screen needs to be redrawn
+	message       string // Result of the last action
+
+	// Callbacks
+	onLoad  func(path string) error
+	onWatch func(path string) error
+
+	// Threading
+	mutex sync.Mutex
+}
+
+// NewSaveGameBrowser creates a browser for the savegame and replay directories
+func NewSaveGameBrowser(saveDir, replayDir string) *SaveGameBrowser {
+	return &SaveGameBrowser{
+		dirs: map[engine.SaveFileKind]string{
+			engine.SaveFileKindSaveGame: saveDir,
+			engine.SaveFileKindReplay:   replayDir,
+		},
+	}
+}
+
+// SetLoadHandler registers the function loading a savegame
+func (sb *SaveGameBrowser) SetLoadHandler(handler func(path string) error) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	sb.onLoad = handler
+}
+
+// SetWatchHandler registers the function playing a replay
+func (sb *SaveGameBrowser) SetWatchHandler(handler func(path string) error) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	sb.onWatch = handler
+}
+
+// IsOpen returns whether the browser is shown
+func (sb *SaveGameBrowser) IsOpen() bool {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	return sb.open
+}
+
+// Open shows the savegames, newest first
+func (sb *SaveGameBrowser) Open() {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	sb.open = true
+	sb.kind = engine.SaveFileKindSaveGame
+	sb.selected = 0
+	sb.message = ""
+	sb.refresh()
+}
+
+// Close hides the browser
+func (sb *SaveGameBrowser) Close() {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	sb.open = false
+	sb.confirmDelete = false
+}
+
+// Files returns the files listed, in display order
+func (sb *SaveGameBrowser) Files() []engine.SaveFileInfo {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	return append([]engine.SaveFileInfo(nil), sb.files...)
+}
+
+// Selected returns the highlighted file, false when the list is empty
+func (sb *SaveGameBrowser) Selected() (engine.SaveFileInfo, bool) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	if sb.selected >= len(sb.files) {
+		return engine.SaveFileInfo{}, false
+	}
+	return sb.files[sb.selected], true
+}
+
+// Rename renames the highlighted file, keeping it highlighted
+func (sb *SaveGameBrowser) Rename(name string) error {
+	file, ok := sb.Selected()
+	if !ok {
+		return sb.report("Rename", fmt.Errorf("no file selected"))
+	}
+	path, err := engine.RenameSaveFile(file.Path, name)
+	if err == nil {
+		sb.mutex.Lock()
+		sb.refresh()
+		sb.selectPath(path)
+		sb.mutex.Unlock()
+	}
+	return sb.report("Rename", err)
+}
+
+// HandleKey processes a key press while the browser is open, returning true if it was consumed
+func (sb *SaveGameBrowser) HandleKey(key glfw.Key) bool {
+	if !sb.IsOpen() {
+		return false
+	}
+
+	// Delete asks again; any other key cancels it
+	sb.mutex.Lock()
+	confirmed := sb.confirmDelete && key == glfw.KeyDelete
+	sb.confirmDelete = false
+	sb.mutex.Unlock()
+
+	switch key {
+	case glfw.KeyUp:
+		sb.moveSelection(-1)
+	case glfw.KeyDown:
+		sb.moveSelection(1)
+	case glfw.KeyTab:
+		sb.mutex.Lock()
+		if sb.kind == engine.SaveFileKindSaveGame {
+			sb.kind = engine.SaveFileKindReplay
+		} else {
+			sb.kind = engine.SaveFileKindSaveGame
+		}
+		sb.selected = 0
+		sb.refresh()
+		sb.mutex.Unlock()
+	case glfw.KeyS:
+		sb.mutex.Lock()
+		sb.sort = (sb.sort + 1) % len(saveSortFields)
+		sb.refresh()
+		sb.mutex.Unlock()
+	case glfw.KeyO:
+		sb.mutex.Lock()
+		sb.ascending = !sb.ascending
+		sb.refresh()
+		sb.mutex.Unlock()
+	case glfw.KeyDelete:
+		if confirmed {
+			sb.deleteSelected()
+		} else if file, ok := sb.Selected(); ok {
+			sb.mutex.Lock()
+			sb.confirmDelete = true
+			sb.message = fmt.Sprintf("Press Delete again to delete %s", file.Name)
+			sb.dirty = true
+			sb.mutex.Unlock()
+		}
+	case glfw.KeyEnter, glfw.KeyKPEnter:
+		sb.activate()
+	case glfw.KeyEscape:
+		sb.Close()
+	}
+
+	// The browser is modal: swallow every key while it is open
+	return true
+}
+
+// Render draws the list when it has changed (console output until text rendering exists)
+func (sb *SaveGameBrowser) Render() {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	if !sb.open || !sb.dirty {
+		return
+	}
+	sb.dirty = false
+
+	order := "descending"
+	if sb.ascending {
+		order = "ascending"
+	}
+	fmt.Printf("=== %ss (by %s, %s) ===\n", sb.kind, saveSortFields[sb.sort].label, order)
+	if len(sb.files) == 0 {
+		fmt.Println("  (none)")
+	}
+	for i, file := range sb.files {
+		marker := "  "
+		if i == sb.selected {
+			marker = "> "
+		}
+		fmt.Printf("%s%s\n", marker, saveFileSummary(file))
+	}
+	if sb.message != "" {
+		fmt.Println(sb.message)
+	}
+	fmt.Println("(Up/Down select, Tab savegames/replays, S sort, O order, Enter load, Delete delete, ESC back; console: save rename <name>)")
+}
+
+// activate loads the highlighted savegame or plays the highlighted replay,
+// closing the browser when it worked
+func (sb *SaveGameBrowser) activate() {
+	file, ok := sb.Selected()
+	if !ok {
+		return
+	}
+	sb.mutex.Lock()
+	action, handler := "Load", sb.onLoad
+	if file.Kind == engine.SaveFileKindReplay {
+		action, handler = "Replay", sb.onWatch
+	}
+	sb.mutex.Unlock()
+
+	// Handlers run without the browser lock so they can call back into it
+	var err error
+	switch {
+	case !file.Valid:
+		err = fmt.Errorf("%s is unreadable", file.Name)
+	case handler == nil:
+		err = fmt.Errorf("not available")
+	default:
+		err = handler(file.Path)
+	}
+	if sb.report(action, err) == nil {
+		sb.Close()
+	}
+}
+
+// deleteSelected deletes the highlighted file
+func (sb *SaveGameBrowser) deleteSelected() {
+	file, ok := sb.Selected()
+	if !ok {
+		return
+	}
+	err := engine.DeleteSaveFile(file.Path)
+	if err == nil {
+		sb.mutex.Lock()
+		sb.refresh()
+		sb.mutex.Unlock()
+	}
+	sb.report("Delete", err)
+}
+
+// moveSelection moves the highlight up or down, wrapping around
+func (sb *SaveGameBrowser) moveSelection(delta int) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	if count := len(sb.files); count > 0 {
+		sb.selected = ((sb.selected+delta)%count + count) % count
+	}
+	sb.dirty = true
+}
+
+// refresh rescans the directory of the kind shown and sorts it (mutex must be held)
+func (sb *SaveGameBrowser) refresh() {
+	files, err := engine.ListSaveFiles(sb.dirs[sb.kind], sb.kind)
+	if err != nil {
+		sb.message = fmt.Sprintf("Listing failed: %v", err)
+	}
+	engine.SortSaveFiles(files, saveSortFields[sb.sort].field, sb.ascending)
+	sb.files = files
+	if sb.selected >= len(files) {
+		sb.selected = max(0, len(files)-1)
+	}
+	sb.dirty = true
+}
+
+// selectPath highlights the file at a path, if listed (mutex must be held)
+func (sb *SaveGameBrowser) selectPath(path string) {
+	for i, file := range sb.files {
+		if file.Path == path {
+			sb.selected = i
+		}
+	}
+}
+
+// report records the outcome of an action for display and returns err
+func (sb *SaveGameBrowser) report(action string, err error) error {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	if err != nil {
+		sb.message = fmt.Sprintf("%s failed: %v", action, err)
+	} else {
+		sb.message = ""
+	}
+	sb.dirty = true
+	return err
+}
+
+// saveFileSummary formats a save file's name and metadata on one line
+func saveFileSummary(file engine.SaveFileInfo) string {
+	if !file.Valid {
+		return fmt.Sprintf("%-24s (unreadable)  %s", file.Name, file.ModTime.Format("2006-01-02 15:04"))
+	}
+	header := file.Header
+	mapName := "random map"
+	if header.MapPath != "" {
+		mapName = strings.TrimSuffix(filepath.Base(header.MapPath), filepath.Ext(header.MapPath))
+	}
+	return fmt.Sprintf("%-24s %-16s %-24s %8s  %s", file.Name, mapName, strings.Join(header.PlayerNames, ", "),
+		formatGameTime(header.GameTime), header.SavedAt.Format("2006-01-02 15:04"))
+}
+
+// formatGameTime formats an in-game duration as h:mm:ss
+func formatGameTime(d time.Duration) string {
+	total := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", total/3600, total/60%60, total%60)
+}
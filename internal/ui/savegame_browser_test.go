@@ -0,0 +1,108 @@
+//go:build !js
+
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/engine"
+)
+
+// writeBrowserTestSave writes a save file with a header for the browser to list
+func writeBrowserTestSave(t *testing.T, path string, savedAt time.Time, mapPath string) {
+	t.Helper()
+	header := engine.SaveGameHeader{Version: engine.SaveGameVersion, SavedAt: savedAt, MapPath: mapPath,
+		GameTime: 90 * time.Second, PlayerNames: []string{"Ann", "Bot"}}
+	if err := engine.WriteSaveGame(path, &engine.SaveGame{Header: header}); err != nil {
+		t.Fatalf("Failed to write save file: %v", err)
+	}
+}
+
+// browserNames returns the names of the files a browser lists
+func browserNames(browser *SaveGameBrowser) []string {
+	names := make([]string, 0)
+	for _, file := range browser.Files() {
+		names = append(names, file.Name)
+	}
+	return names
+}
+
+// TestSaveGameBrowser tests listing, sorting, loading, renaming and deleting
+// savegames, and listing replays
+func TestSaveGameBrowser(t *testing.T) {
+	saveDir, replayDir := t.TempDir(), t.TempDir()
+	now := time.Now()
+	writeBrowserTestSave(t, filepath.Join(saveDir, "alpha"+engine.SaveGameExtension), now.Add(-time.Hour), "maps/valley.gbm")
+	writeBrowserTestSave(t, filepath.Join(saveDir, "beta"+engine.SaveGameExtension), now, "maps/coast.gbm")
+	writeBrowserTestSave(t, filepath.Join(replayDir, "final"+engine.ReplayExtension), now, "maps/coast.gbm")
+
+	browser := NewSaveGameBrowser(saveDir, replayDir)
+	var loaded string
+	browser.SetLoadHandler(func(path string) error {
+		loaded = path
+		return nil
+	})
+	browser.Open()
+	if names := browserNames(browser); len(names) != 2 || names[0] != "beta" {
+		t.Fatalf("Expected the newest savegame first, got %v", names)
+	}
+	if summary := saveFileSummary(browser.Files()[0]); summary == "" {
+		t.Error("Expected a summary of the savegame")
+	}
+
+	// S sorts by name, O flips the order
+	browser.HandleKey(glfw.KeyS)
+	browser.HandleKey(glfw.KeyO)
+	if names := browserNames(browser); names[0] != "alpha" {
+		t.Errorf("Expected alpha first by name ascending, got %v", names)
+	}
+
+	browser.HandleKey(glfw.KeyEnter)
+	if loaded != filepath.Join(saveDir, "alpha"+engine.SaveGameExtension) || browser.IsOpen() {
+		t.Errorf("Expected alpha loaded and the browser closed, got %q", loaded)
+	}
+
+	// Rename keeps the file highlighted
+	browser.Open()
+	if err := browser.Rename("gamma"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	file, _ := browser.Selected()
+	if file.Name != "gamma" {
+		t.Errorf("Expected the renamed file highlighted, got %s", file.Name)
+	}
+
+	// Delete asks again; another key cancels
+	path := file.Path
+	browser.HandleKey(glfw.KeyDelete)
+	browser.HandleKey(glfw.KeyDown)
+	browser.HandleKey(glfw.KeyDelete)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal("Expected a single Delete to keep the file")
+	}
+	browser.HandleKey(glfw.KeyUp)
+	browser.HandleKey(glfw.KeyDelete)
+	browser.HandleKey(glfw.KeyDelete)
+	if _, err := os.Stat(path); !os.IsNotExist(err) || len(browser.Files()) != 1 {
+		t.Errorf("Expected %s deleted, got %v", path, browserNames(browser))
+	}
+
+	// Tab shows the replays; without playback they cannot be started
+	browser.HandleKey(glfw.KeyTab)
+	if names := browserNames(browser); len(names) != 1 || names[0] != "final" {
+		t.Fatalf("Expected the replay listed, got %v", names)
+	}
+	browser.HandleKey(glfw.KeyEnter)
+	if !browser.IsOpen() || browser.message == "" {
+		t.Error("Expected the browser to stay open and report that replays cannot play")
+	}
+	browser.HandleKey(glfw.KeyEscape)
+	if browser.IsOpen() {
+		t.Error("Expected ESC to close the browser")
+	}
+}
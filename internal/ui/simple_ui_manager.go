@@ -1,11 +1,17 @@
+//go:build !js
+
 package ui
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/go-gl/glfw/v3.3/glfw"
+
 	"teraglest/internal/engine"
+	"teraglest/internal/logging"
 )
 
 // SimpleUIManager is a minimal UI manager without ImGui dependencies for testing
@@ -14,21 +20,43 @@ type SimpleUIManager struct {
 	world *engine.World
 
 	// Input state
-	selectedUnits    []*engine.GameUnit
+	selection        *engine.SelectionManager
 	selectedBuilding *engine.GameBuilding
+	activeType       string // Unit type of a mixed selection Tab picked, "" for the first
 
 	// UI state
 	showDebugInfo bool
 
+	// HUD panels that take clicks, in the order they are tried
+	panels []HUDPanel
+
 	// Threading
 	mutex sync.RWMutex
 }
 
+// HUDPanel is a panel drawn on the HUD that takes the clicks landing on it
+type HUDPanel interface {
+	// HandleClick handles a click at a HUD position, returning true if it
+	// landed on the panel
+	HandleClick(x, y float32) bool
+}
+
+// worldClickHandler is a HUD panel that can take the next click on the map,
+// e.g. to place a rally point
+type worldClickHandler interface {
+	HandleWorldClick(position engine.Vector3) bool
+}
+
+// keyHandler is a HUD panel that takes key presses
+type keyHandler interface {
+	HandleKey(key glfw.Key) bool
+}
+
 // NewSimpleUIManager creates a new simple UI manager without ImGui
 func NewSimpleUIManager(world *engine.World) *SimpleUIManager {
 	return &SimpleUIManager{
 		world:         world,
-		selectedUnits: make([]*engine.GameUnit, 0),
+		selection:     engine.NewSelectionManager(world),
 		showDebugInfo: false,
 	}
 }
@@ -50,13 +78,23 @@ func (ui *SimpleUIManager) Render() {
 
 // GetSelectedUnits returns currently selected units
 func (ui *SimpleUIManager) GetSelectedUnits() []*engine.GameUnit {
-	ui.mutex.RLock()
-	defer ui.mutex.RUnlock()
+	return ui.selection.Units()
+}
 
-	// Return copy to avoid race conditions
-	result := make([]*engine.GameUnit, len(ui.selectedUnits))
-	copy(result, ui.selectedUnits)
-	return result
+// Selection returns the unit selection, for applying selection gestures
+func (ui *SimpleUIManager) Selection() *engine.SelectionManager {
+	return ui.selection
+}
+
+// ApplySelectionGesture applies a selection gesture on a unit, clearing any building selection
+func (ui *SimpleUIManager) ApplySelectionGesture(gesture engine.SelectionGesture, unit *engine.GameUnit, visible func(*engine.GameUnit) bool) []*engine.GameUnit {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	units := ui.selection.Apply(gesture, unit, visible)
+	ui.selectedBuilding = nil
+	logging.Infof(logging.CategoryUI, "%s: %d units selected", gesture, len(units))
+	return units
 }
 
 // GetSelectedBuilding returns currently selected building
@@ -72,13 +110,37 @@ func (ui *SimpleUIManager) SelectUnits(units []*engine.GameUnit) {
 	ui.mutex.Lock()
 	defer ui.mutex.Unlock()
 
-	ui.selectedUnits = make([]*engine.GameUnit, len(units))
-	copy(ui.selectedUnits, units)
+	ui.selection.Set(units)
 	ui.selectedBuilding = nil // Clear building selection
 
 	if len(units) > 0 {
-		fmt.Printf("Selected %d units\n", len(units))
+		logging.Infof(logging.CategoryUI, "Selected %d units", len(units))
+	}
+}
+
+// SelectBand selects the units in a dragged selection box through the
+// selection filters, clearing any building selection; add keeps the current
+// selection. It returns the selection afterwards.
+func (ui *SimpleUIManager) SelectBand(units []*engine.GameUnit, add bool) []*engine.GameUnit {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	selected := ui.selection.SelectBand(units, add)
+	if len(selected) > 0 {
+		ui.selectedBuilding = nil
+		logging.Infof(logging.CategoryUI, "Selected %d units", len(selected))
 	}
+	return selected
+}
+
+// DropWorkers removes the workers from the unit selection
+func (ui *SimpleUIManager) DropWorkers() []*engine.GameUnit {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	units := ui.selection.DropWorkers()
+	logging.Infof(logging.CategoryUI, "Dropped workers: %d units selected", len(units))
+	return units
 }
 
 // SelectBuilding sets the selected building
@@ -87,40 +149,135 @@ func (ui *SimpleUIManager) SelectBuilding(building *engine.GameBuilding) {
 	defer ui.mutex.Unlock()
 
 	ui.selectedBuilding = building
-	ui.selectedUnits = ui.selectedUnits[:0] // Clear unit selection
+	ui.selection.Clear() // Clear unit selection
 
 	if building != nil {
-		fmt.Printf("Selected building: %s\n", building.BuildingType)
+		logging.Infof(logging.CategoryUI, "Selected building: %s", building.BuildingType)
 	}
 }
 
+// SelectLargestArmy selects the player's largest army group, clearing any
+// building selection, and returns the group's center
+func (ui *SimpleUIManager) SelectLargestArmy(playerID int) (engine.Vector3, bool) {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	center, ok := ui.selection.SelectLargestArmy(playerID)
+	if ok {
+		ui.selectedBuilding = nil
+		logging.Infof(logging.CategoryUI, "Selected largest army: %d units", len(ui.selection.Units()))
+	}
+	return center, ok
+}
+
 // ClearSelection clears all selections
 func (ui *SimpleUIManager) ClearSelection() {
 	ui.mutex.Lock()
 	defer ui.mutex.Unlock()
 
-	ui.selectedUnits = ui.selectedUnits[:0]
+	ui.selection.Clear()
 	ui.selectedBuilding = nil
-	fmt.Println("Selection cleared")
+	logging.Infof(logging.CategoryUI, "Selection cleared")
 }
 
-// IssueCommand issues a command to selected units
-func (ui *SimpleUIManager) IssueCommand(commandType engine.CommandType, params map[string]interface{}) error {
+// ActiveType returns the unit type of the selection whose commands are
+// offered, the first by name until Tab picks another; "" without living
+// units selected
+func (ui *SimpleUIManager) ActiveType() string {
+	types := unitTypes(ui.livingUnits())
+	if len(types) == 0 {
+		return ""
+	}
+
 	ui.mutex.RLock()
 	defer ui.mutex.RUnlock()
+	return activeType(types, ui.activeType)
+}
+
+// CycleActiveType picks the next unit type of a mixed selection, returning
+// false when the selection has a single type
+func (ui *SimpleUIManager) CycleActiveType() bool {
+	types := unitTypes(ui.livingUnits())
+	if len(types) < 2 {
+		return false
+	}
+
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+	current := activeType(types, ui.activeType)
+	ui.activeType = types[(sort.SearchStrings(types, current)+1)%len(types)]
+	logging.Infof(logging.CategoryUI, "Active unit type: %s", ui.activeType)
+	return true
+}
+
+// ActiveUnits returns the selected units of the active type
+func (ui *SimpleUIManager) ActiveUnits() []*engine.GameUnit {
+	unitType := ui.ActiveType()
+	units := make([]*engine.GameUnit, 0)
+	for _, unit := range ui.livingUnits() {
+		if unit.UnitType == unitType {
+			units = append(units, unit)
+		}
+	}
+	return units
+}
+
+// livingUnits returns the living selected units when no building is selected
+func (ui *SimpleUIManager) livingUnits() []*engine.GameUnit {
+	if ui.GetSelectedBuilding() != nil {
+		return nil
+	}
+	units := make([]*engine.GameUnit, 0)
+	for _, unit := range ui.selection.Units() {
+		if unit.IsAlive() {
+			units = append(units, unit)
+		}
+	}
+	return units
+}
 
-	if len(ui.selectedUnits) == 0 {
+// sharedCommand reports whether every unit of a mixed selection takes a
+// command; the others only go to the units of the active type
+func sharedCommand(commandType engine.CommandType) bool {
+	switch commandType {
+	case engine.CommandMove, engine.CommandAttack, engine.CommandStop, engine.CommandHold,
+		engine.CommandPatrol, engine.CommandFollow, engine.CommandGuard:
+		return true
+	}
+	return false
+}
+
+// IssueCommand issues a command to selected units: to all of them for
+// moving and fighting, and to the units of the active type for the rest
+func (ui *SimpleUIManager) IssueCommand(commandType engine.CommandType, params map[string]interface{}) error {
+	selectedUnits := ui.selection.Units()
+	if !sharedCommand(commandType) {
+		selectedUnits = ui.ActiveUnits()
+	}
+	if len(selectedUnits) == 0 {
 		return fmt.Errorf("no units selected")
 	}
 
+	// A move of several units is planned once for all of them
+	if commandType == engine.CommandMove && len(selectedUnits) > 1 {
+		return ui.issueGroupMove(selectedUnits, params)
+	}
+
 	// Create command for each selected unit
-	for _, unit := range ui.selectedUnits {
+	for _, unit := range selectedUnits {
 		command := engine.UnitCommand{
 			Type:       commandType,
 			Parameters: params,
 			CreatedAt:  time.Now(),
 			IsQueued:   false, // TODO: Check for shift key modifier
 		}
+		if building, ok := params["target_building"].(*engine.GameBuilding); ok {
+			command.TargetBuilding = building
+		}
+		if x, ok := params["target_x"].(float64); ok {
+			z, _ := params["target_z"].(float64)
+			command.Target = &engine.Vector3{X: x, Z: z}
+		}
 
 		// Issue command through world's command processor
 		world := ui.world
@@ -128,18 +285,104 @@ func (ui *SimpleUIManager) IssueCommand(commandType engine.CommandType, params m
 			return fmt.Errorf("world is nil")
 		}
 
-		// Create command processor for this command
-		commandProcessor := engine.NewCommandProcessor(world)
-		err := commandProcessor.IssueCommand(unit.GetID(), command)
+		// The world's processor holds commands given while the game is paused
+		// and delays them when simulating network lag
+		commandProcessor, ok := world.GetCommandProcessor().(*engine.CommandProcessor)
+		if !ok || commandProcessor == nil {
+			return fmt.Errorf("world has no command processor")
+		}
+		err := commandProcessor.SubmitCommand(unit.GetID(), command)
 		if err != nil {
 			return fmt.Errorf("failed to issue command to unit %d: %w", unit.GetID(), err)
 		}
 	}
 
-	fmt.Printf("Issued %s command to %d units\n", commandType, len(ui.selectedUnits))
+	logging.Infof(logging.CategoryUI, "Issued %s command to %d units", commandType, len(selectedUnits))
+	return nil
+}
+
+// issueGroupMove orders the selected units to a target with one shared
+// plan rather than a path search for each
+func (ui *SimpleUIManager) issueGroupMove(selectedUnits []*engine.GameUnit, params map[string]interface{}) error {
+	x, ok := params["target_x"].(float64)
+	if !ok {
+		return fmt.Errorf("move command has no target")
+	}
+	z, _ := params["target_z"].(float64)
+	if ui.world == nil {
+		return fmt.Errorf("world is nil")
+	}
+	commandProcessor, ok := ui.world.GetCommandProcessor().(*engine.CommandProcessor)
+	if !ok || commandProcessor == nil {
+		return fmt.Errorf("world has no command processor")
+	}
+
+	unitIDs := make([]int, len(selectedUnits))
+	for i, unit := range selectedUnits {
+		unitIDs[i] = unit.GetID()
+	}
+	command := engine.UnitCommand{
+		Type:       engine.CommandMove,
+		Target:     &engine.Vector3{X: x, Z: z},
+		Parameters: params,
+		CreatedAt:  time.Now(),
+	}
+	if err := commandProcessor.SubmitGroupMove(unitIDs, command); err != nil {
+		return err
+	}
+	logging.Infof(logging.CategoryUI, "Issued %s command to %d units", engine.CommandMove, len(selectedUnits))
 	return nil
 }
 
+// AddPanel registers a HUD panel to take clicks
+func (ui *SimpleUIManager) AddPanel(panel HUDPanel) {
+	ui.mutex.Lock()
+	defer ui.mutex.Unlock()
+
+	ui.panels = append(ui.panels, panel)
+}
+
+// HandleClick passes a click at a screen position to the HUD panels,
+// returning true if one took it
+func (ui *SimpleUIManager) HandleClick(x, y float64) bool {
+	for _, panel := range ui.hudPanels() {
+		if panel.HandleClick(float32(x), float32(y)) {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleWorldClick passes a click on the map to the HUD panels waiting for
+// one, returning true if one took it
+func (ui *SimpleUIManager) HandleWorldClick(position engine.Vector3) bool {
+	for _, panel := range ui.hudPanels() {
+		if handler, ok := panel.(worldClickHandler); ok && handler.HandleWorldClick(position) {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleKey passes a key press to the HUD panels, returning true if one took it
+func (ui *SimpleUIManager) HandleKey(key glfw.Key) bool {
+	for _, panel := range ui.hudPanels() {
+		if handler, ok := panel.(keyHandler); ok && handler.HandleKey(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// hudPanels returns the registered HUD panels; panels run without the
+// manager lock, as they read the selection
+func (ui *SimpleUIManager) hudPanels() []HUDPanel {
+	ui.mutex.RLock()
+	defer ui.mutex.RUnlock()
+
+	return append([]HUDPanel(nil), ui.panels...)
+}
+
 // IsMouseOverUI returns false for simple UI (no UI elements to check)
 func (ui *SimpleUIManager) IsMouseOverUI() bool {
 	return false
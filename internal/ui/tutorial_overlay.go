@@ -0,0 +1,78 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+
+	"teraglest/internal/tutorial"
+)
+
+// TutorialOverlay shows the hint of the current tutorial step and the UI
+// element it points at
+type TutorialOverlay struct {
+	title    string
+	total    int
+	step     tutorial.Step
+	index    int
+	active   bool
+	finished bool
+	dirty    bool // Content changed since the last render
+	mutex    sync.Mutex
+}
+
+// NewTutorialOverlay creates an overlay for a scenario
+func NewTutorialOverlay(scenario *tutorial.Scenario) *TutorialOverlay {
+	return &TutorialOverlay{title: scenario.Name, total: len(scenario.Steps)}
+}
+
+// ShowStep displays a step; use it as the tutorial's step handler
+func (to *TutorialOverlay) ShowStep(step tutorial.Step, index int) {
+	to.mutex.Lock()
+	defer to.mutex.Unlock()
+	to.step = step
+	to.index = index
+	to.active = true
+	to.dirty = true
+}
+
+// Finish replaces the hints with the completion message; use it as the tutorial's finish handler
+func (to *TutorialOverlay) Finish() {
+	to.mutex.Lock()
+	defer to.mutex.Unlock()
+	to.active = false
+	to.finished = true
+	to.dirty = true
+}
+
+// Highlight returns the UI element the current step points at, "" if none
+func (to *TutorialOverlay) Highlight() string {
+	to.mutex.Lock()
+	defer to.mutex.Unlock()
+	if !to.active {
+		return ""
+	}
+	return to.step.Highlight
+}
+
+// Render shows the current hint (console output until text rendering exists)
+func (to *TutorialOverlay) Render() {
+	to.mutex.Lock()
+	defer to.mutex.Unlock()
+
+	if !to.dirty {
+		return
+	}
+	to.dirty = false
+
+	if to.finished {
+		fmt.Printf("=== %s complete ===\n", to.title)
+		return
+	}
+	fmt.Printf("=== %s (%d/%d) ===\n", to.title, to.index+1, to.total)
+	fmt.Println(to.step.Hint)
+	if to.step.Highlight != "" {
+		fmt.Printf(">> Look at: %s\n", to.step.Highlight)
+	}
+}
@@ -0,0 +1,332 @@
+//go:build !js
+
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+	"teraglest/internal/graphics"
+	"teraglest/internal/graphics/renderer"
+	"teraglest/internal/graphics/sprite"
+	"teraglest/internal/graphics/text"
+)
+
+// Unit panel layout, in pixels
+const (
+	unitPanelWidth    = 300
+	unitPanelHeight   = 96
+	unitPanelMargin   = 8
+	unitPanelPadding  = 8
+	unitPortraitSize  = 64
+	unitPanelLineStep = renderer.DefaultTextSize + 4
+)
+
+// Multi-selection grid layout, in pixels
+const (
+	unitGridColumns  = 8
+	unitGridRows     = 3 // Further units are only counted
+	unitGridPortrait = 30
+	unitGridGap      = 4
+	unitGridRowStep  = unitGridPortrait + healthBarHeight + unitGridGap
+)
+
+// activeTypeColor frames the portraits of the unit type Tab picked
+var activeTypeColor = sprite.Color{R: 0.2, G: 0.4, B: 0.8, A: 0.9}
+
+// UnitPanel shows what the player knows of the selected unit or building in
+// the bottom left corner, with the HUD sprite layer: portrait, name, owner,
+// health and combat figures, and what it is doing when it is the player's or
+// an ally's. Enemies can be selected to inspect them; the panel is read
+// only, and fog of war hides enemies that go out of sight.
+//
+// A selection of several units shows a grid of their portraits with health
+// bars, grouped by type, under the group's total health and damage per
+// second. Clicking a portrait selects that unit alone, and Tab cycles which
+// of the unit types is active: highlighted here, offered on the command card
+// and given the orders only that type carries out.
+type UnitPanel struct {
+	world     *engine.World
+	playerID  int
+	selection *SimpleUIManager
+
+	height int // Height of the HUD last drawn on, for hit tests
+
+	mutex sync.Mutex
+}
+
+// unitSlot is a portrait in the multi-selection grid
+type unitSlot struct {
+	rect sprite.Rect
+	unit *engine.GameUnit
+	info engine.ObjectInfo
+}
+
+// groupStats sums up the units of a selection
+type groupStats struct {
+	Units     int
+	Health    int
+	MaxHealth int
+	DPS       float32 // Damage per second of all the units attacking
+}
+
+// NewUnitPanel creates a unit panel showing a player's view of the selection of a UI manager
+func NewUnitPanel(world *engine.World, playerID int, selection *SimpleUIManager) *UnitPanel {
+	return &UnitPanel{world: world, playerID: playerID, selection: selection}
+}
+
+// Draw draws the panel on the HUD; nothing is drawn without a selection
+func (up *UnitPanel) Draw(canvas *renderer.HUDCanvas) {
+	up.mutex.Lock()
+	up.height = canvas.Height
+	up.mutex.Unlock()
+
+	if units := up.group(); len(units) > 1 {
+		up.drawGroup(canvas, units)
+		return
+	}
+
+	info, ok := up.selected()
+	if !ok {
+		return
+	}
+
+	panel := sprite.Rect{
+		X: unitPanelMargin,
+		Y: float32(canvas.Height) - unitPanelHeight - unitPanelMargin,
+		W: unitPanelWidth,
+		H: unitPanelHeight,
+	}
+	canvas.Sprites.Fill(panel, hudPanelColor)
+	inner := panel.Inset(unitPanelPadding)
+	if info.Knowledge == engine.KnowledgeNone {
+		canvas.Text.DrawScreenText(inner.X, inner.Y, "Out of sight",
+			renderer.DefaultTextSize, hudTextColor, text.AnchorTopLeft)
+		return
+	}
+
+	portrait := sprite.Rect{X: inner.X, Y: inner.Y, W: unitPortraitSize, H: unitPortraitSize}
+	if player := up.world.GetPlayer(info.PlayerID); player != nil {
+		if icon := canvas.UnitIcon(player.FactionName, info.Name); icon != sprite.NoTexture {
+			canvas.Sprites.Icon(portrait, icon, sprite.White)
+		}
+	}
+
+	x, y := portrait.X+portrait.W+unitPanelPadding, inner.Y
+	canvas.Text.DrawScreenText(x, y, data.DisplayName(info.Name), renderer.DefaultTextSize, hudTextColor, text.AnchorTopLeft)
+	y += unitPanelLineStep
+	canvas.Text.DrawScreenText(x, y, ownerLabel(info), renderer.DefaultTextSize*0.8,
+		graphics.TeamColor(info.PlayerID).Vec4(1), text.AnchorTopLeft)
+	y += unitPanelLineStep
+	canvas.Text.DrawScreenText(x, y, unitPanelStats(info), renderer.DefaultTextSize*0.8, hudTextColor, text.AnchorTopLeft)
+	if info.Activity != "" {
+		y += unitPanelLineStep
+		canvas.Text.DrawScreenText(x, y, info.Activity, renderer.DefaultTextSize*0.8, hudTextColor, text.AnchorTopLeft)
+	}
+	drawHealthBar(canvas, sprite.Rect{X: portrait.X, Y: portrait.Y + portrait.H + 4, W: portrait.W, H: healthBarHeight}, info)
+}
+
+// HandleClick selects the unit whose portrait is clicked alone, returning
+// true if the click landed on the multi-selection grid
+func (up *UnitPanel) HandleClick(x, y float32) bool {
+	units := up.group()
+	if len(units) < 2 {
+		return false
+	}
+	panel, slots := up.layoutGroup(units)
+	if !panel.Contains(x, y) {
+		return false
+	}
+	for _, slot := range slots {
+		if slot.rect.Contains(x, y) {
+			up.selection.SelectUnits([]*engine.GameUnit{slot.unit})
+			break
+		}
+	}
+	return true
+}
+
+// HandleKey cycles the active unit type of a mixed selection on Tab,
+// returning true if the key was used
+func (up *UnitPanel) HandleKey(key glfw.Key) bool {
+	return key == glfw.KeyTab && up.selection.CycleActiveType()
+}
+
+// ActiveType returns the highlighted unit type of the selection, whose
+// commands the command card offers; "" without units selected
+func (up *UnitPanel) ActiveType() string {
+	return up.selection.ActiveType()
+}
+
+// drawGroup draws the multi-selection grid under the group's stats
+func (up *UnitPanel) drawGroup(canvas *renderer.HUDCanvas, units []*engine.GameUnit) {
+	panel, slots := up.layoutGroup(units)
+	canvas.Sprites.Fill(panel, hudPanelColor)
+	inner := panel.Inset(unitPanelPadding)
+
+	infos := make([]engine.ObjectInfo, len(slots))
+	for i, slot := range slots {
+		infos[i] = slot.info
+	}
+	header := fmt.Sprintf("%d units", len(units))
+	if hidden := len(units) - len(slots); hidden > 0 {
+		header += fmt.Sprintf(" (%d not shown)", hidden)
+	}
+	activeType := up.ActiveType()
+	if len(unitTypes(units)) > 1 {
+		header += "  Tab: " + data.DisplayName(activeType)
+	}
+	canvas.Text.DrawScreenText(inner.X, inner.Y, header, renderer.DefaultTextSize, hudTextColor, text.AnchorTopLeft)
+	canvas.Text.DrawScreenText(inner.X, inner.Y+unitPanelLineStep, groupStatsLabel(up.sumGroup(units)),
+		renderer.DefaultTextSize*0.8, hudTextColor, text.AnchorTopLeft)
+
+	for _, slot := range slots {
+		if slot.info.Name == activeType && len(unitTypes(units)) > 1 {
+			canvas.Sprites.Fill(slot.rect.Inset(-2), activeTypeColor)
+		}
+		icon := sprite.NoTexture
+		if player := up.world.GetPlayer(slot.info.PlayerID); player != nil {
+			icon = canvas.UnitIcon(player.FactionName, slot.info.Name)
+		}
+		if icon != sprite.NoTexture {
+			canvas.Sprites.Icon(slot.rect, icon, sprite.White)
+		} else {
+			canvas.Sprites.Fill(slot.rect, commandSlotColor)
+			canvas.Text.DrawScreenText(slot.rect.X+slot.rect.W/2, slot.rect.Y+slot.rect.H/2, shortName(slot.info.Name),
+				renderer.DefaultTextSize*0.6, hudTextColor, text.AnchorCenter)
+		}
+		drawHealthBar(canvas, sprite.Rect{X: slot.rect.X, Y: slot.rect.Y + slot.rect.H, W: slot.rect.W, H: healthBarHeight}, slot.info)
+	}
+}
+
+// layoutGroup sizes the panel to the grid of a group's portraits, grouped
+// by type, and places them in it
+func (up *UnitPanel) layoutGroup(units []*engine.GameUnit) (sprite.Rect, []unitSlot) {
+	up.mutex.Lock()
+	height := up.height
+	up.mutex.Unlock()
+
+	// Portraits of one type stay together, in selection order
+	order := make(map[string]int)
+	for i, unitType := range unitTypes(units) {
+		order[unitType] = i
+	}
+	sorted := append([]*engine.GameUnit(nil), units...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return order[sorted[i].UnitType] < order[sorted[j].UnitType]
+	})
+
+	slots := make([]unitSlot, 0, len(sorted))
+	for _, unit := range sorted {
+		if len(slots) == unitGridColumns*unitGridRows {
+			break
+		}
+		info := up.world.UnitInfo(up.playerID, unit)
+		if info.Knowledge == engine.KnowledgeNone {
+			continue
+		}
+		slots = append(slots, unitSlot{unit: unit, info: info})
+	}
+
+	rows := (len(slots) + unitGridColumns - 1) / unitGridColumns
+	panelHeight := float32(2*unitPanelPadding + 2*unitPanelLineStep + rows*unitGridRowStep)
+	panel := sprite.Rect{
+		X: unitPanelMargin,
+		Y: float32(height) - panelHeight - unitPanelMargin,
+		W: unitPanelWidth,
+		H: panelHeight,
+	}
+	gridX, gridY := panel.X+unitPanelPadding, panel.Y+unitPanelPadding+2*unitPanelLineStep
+	for i := range slots {
+		slots[i].rect = sprite.Rect{
+			X: gridX + float32(i%unitGridColumns)*(unitGridPortrait+unitGridGap),
+			Y: gridY + float32(i/unitGridColumns)*unitGridRowStep,
+			W: unitGridPortrait,
+			H: unitGridPortrait,
+		}
+	}
+	return panel, slots
+}
+
+// sumGroup sums up what the player knows of a group's units
+func (up *UnitPanel) sumGroup(units []*engine.GameUnit) groupStats {
+	var stats groupStats
+	for _, unit := range units {
+		info := up.world.UnitInfo(up.playerID, unit)
+		if info.Knowledge == engine.KnowledgeNone {
+			continue
+		}
+		stats.Units++
+		stats.Health += info.Health
+		stats.MaxHealth += info.MaxHealth
+		stats.DPS += float32(info.AttackDamage) * info.AttackSpeed
+	}
+	return stats
+}
+
+// group returns the living selected units when no building is selected
+func (up *UnitPanel) group() []*engine.GameUnit {
+	return up.selection.livingUnits()
+}
+
+// selected returns what the player knows of the selected building, or else
+// of the first selected unit; false without a selection
+func (up *UnitPanel) selected() (engine.ObjectInfo, bool) {
+	if building := up.selection.GetSelectedBuilding(); building != nil && building.IsAlive() {
+		return up.world.BuildingInfo(up.playerID, building), true
+	}
+	if units := up.selection.GetSelectedUnits(); len(units) > 0 {
+		return up.world.UnitInfo(up.playerID, units[0]), true
+	}
+	return engine.ObjectInfo{}, false
+}
+
+// unitPanelStats formats an object's health and combat figures
+func unitPanelStats(info engine.ObjectInfo) string {
+	stats := fmt.Sprintf("HP %d/%d  Armor %d", info.Health, info.MaxHealth, info.Armor)
+	if info.AttackDamage > 0 {
+		stats += fmt.Sprintf("  Attack %d  Range %.1f", info.AttackDamage, info.AttackRange)
+	}
+	return stats
+}
+
+// groupStatsLabel formats a group's total health and damage per second
+func groupStatsLabel(stats groupStats) string {
+	return fmt.Sprintf("HP %d/%d  DPS %.1f", stats.Health, stats.MaxHealth, stats.DPS)
+}
+
+// unitTypes returns the distinct types of units, sorted by name
+func unitTypes(units []*engine.GameUnit) []string {
+	seen := make(map[string]bool)
+	types := make([]string, 0)
+	for _, unit := range units {
+		if !seen[unit.UnitType] {
+			seen[unit.UnitType] = true
+			types = append(types, unit.UnitType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// activeType returns the picked type when the sorted types include it, and
+// otherwise the first
+func activeType(types []string, picked string) string {
+	if i := sort.SearchStrings(types, picked); i < len(types) && types[i] == picked {
+		return picked
+	}
+	return types[0]
+}
+
+// shortName abbreviates a unit type for a portrait without an icon
+func shortName(name string) string {
+	if len(name) <= 4 {
+		return name
+	}
+	return name[:4]
+}
@@ -0,0 +1,101 @@
+//go:build !js
+
+package ui
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"teraglest/internal/data"
+	"teraglest/internal/engine"
+)
+
+// TestUnitPanelGroup tests the multi-selection grid: its group stats, Tab
+// cycling the active type once per press, the active type's commands and
+// orders, and a portrait click selecting its unit alone
+func TestUnitPanelGroup(t *testing.T) {
+	world, err := engine.NewHeadlessWorld(32, 32)
+	if err != nil {
+		t.Fatalf("Failed to create world: %v", err)
+	}
+	units := make([]*engine.GameUnit, 0)
+	for i, name := range []string{"swordman", "archer", "swordman", "archer", "swordman"} {
+		unit, err := world.ObjectManager.CreateUnit(1, name, engine.Vector3{X: float64(i) + 2.5, Z: 2.5}, data.NewSimpleUnit(name, 100, 0, "leather", nil))
+		if err != nil {
+			t.Fatalf("Failed to create unit: %v", err)
+		}
+		unit.AttackDamage, unit.AttackSpeed = 10, 0.5
+		units = append(units, unit)
+	}
+	units[0].Health = 40
+
+	manager := NewSimpleUIManager(world)
+	panel := NewUnitPanel(world, 1, manager)
+	panel.height = 600
+	manager.AddPanel(panel)
+	input := NewInputHandler(world, manager)
+	manager.SelectUnits(units)
+
+	stats := panel.sumGroup(panel.group())
+	if stats.Units != 5 || stats.Health != 440 || stats.MaxHealth != 500 || stats.DPS != 25 {
+		t.Errorf("Expected 5 units with 440/500 HP and 25 DPS, got %+v", stats)
+	}
+
+	// Tab cycles the types on each press, not while the key is held
+	if panel.ActiveType() != "archer" {
+		t.Errorf("Expected the archers highlighted first, got %s", panel.ActiveType())
+	}
+	input.HandleKeyboard(nil, glfw.KeyTab, 0, glfw.Press, 0)
+	for i := 0; i < 3; i++ {
+		input.HandleKeyboard(nil, glfw.KeyTab, 0, glfw.Repeat, 0)
+	}
+	if panel.ActiveType() != "swordman" {
+		t.Errorf("Expected one press to highlight the swordmen, got %s", panel.ActiveType())
+	}
+
+	// The active type's commands are offered, and only its units take the
+	// orders not every unit carries out
+	if _, unitDef, _ := NewCommandCard(world, 1, manager).selected(); unitDef == nil || unitDef.Name != "swordman" {
+		t.Errorf("Expected the command card to offer the swordmen's commands, got %v", unitDef)
+	}
+	if err := manager.IssueCommand(engine.CommandRepair, map[string]interface{}{}); err != nil {
+		t.Fatalf("Failed to issue a repair command: %v", err)
+	}
+	for _, unit := range units {
+		repairing := unit.CurrentCommand != nil && unit.CurrentCommand.Type == engine.CommandRepair
+		if repairing != (unit.UnitType == "swordman") {
+			t.Errorf("Expected only the swordmen to repair, got %v for a %s", repairing, unit.UnitType)
+		}
+	}
+	if err := manager.IssueCommand(engine.CommandHold, map[string]interface{}{}); err != nil {
+		t.Fatalf("Failed to issue a hold command: %v", err)
+	}
+	for _, unit := range units {
+		if unit.CurrentCommand == nil || unit.CurrentCommand.Type != engine.CommandHold {
+			t.Errorf("Expected every unit to hold, got %v for a %s", unit.CurrentCommand, unit.UnitType)
+		}
+	}
+
+	input.HandleKeyboard(nil, glfw.KeyTab, 0, glfw.Release, 0)
+	input.HandleKeyboard(nil, glfw.KeyTab, 0, glfw.Press, 0)
+	if panel.ActiveType() != "archer" {
+		t.Errorf("Expected the next press to wrap to the archers, got %s", panel.ActiveType())
+	}
+
+	// The portraits are grouped by type; a click selects one alone
+	_, slots := panel.layoutGroup(panel.group())
+	if len(slots) != 5 || slots[0].unit != units[1] || slots[1].unit != units[3] || slots[2].unit != units[0] {
+		t.Fatalf("Expected the archers' portraits before the swordmen's, got %d slots", len(slots))
+	}
+	rect := slots[2].rect
+	if !manager.HandleClick(float64(rect.X+rect.W/2), float64(rect.Y+rect.H/2)) {
+		t.Fatal("Expected the grid to take the click")
+	}
+	if selected := manager.GetSelectedUnits(); len(selected) != 1 || selected[0] != units[0] {
+		t.Errorf("Expected the clicked swordman selected alone, got %d units", len(selected))
+	}
+	if manager.HandleClick(float64(rect.X+rect.W/2), float64(rect.Y+rect.H/2)) || panel.HandleKey(glfw.KeyTab) {
+		t.Error("Expected no grid for a single unit")
+	}
+}
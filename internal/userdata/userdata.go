@@ -0,0 +1,158 @@
+// Package userdata resolves where TeraGlest stores per-user files (config, saves,
+// replays, logs, screenshots and caches) following each platform's conventions.
+package userdata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// AppName is the directory name used under the platform data locations
+const AppName = "teraglest"
+
+// HomeEnvVar overrides the platform locations with a single root directory
+const HomeEnvVar = "TERAGLEST_HOME"
+
+// Paths holds the resolved user directories
+type Paths struct {
+	Config      string // Configuration files
+	Data        string // Root for per-user game data
+	Saves       string // Savegames
+	Replays     string // Recorded replays
+	Logs        string // Log files and crash reports
+	Screenshots string // Screenshots
+	Cache       string // Generated files that can be rebuilt, such as model icons
+}
+
+// Resolve returns the user directories for the current platform
+func Resolve() (Paths, error) {
+	return resolve(runtime.GOOS, os.Getenv, os.UserHomeDir)
+}
+
+// MustResolve returns the user directories, falling back to the working directory on error
+func MustResolve() Paths {
+	paths, err := Resolve()
+	if err != nil {
+		return fromRoot(".")
+	}
+	return paths
+}
+
+// Ensure creates every user directory that does not exist yet
+func (p Paths) Ensure() error {
+	for _, dir := range []string{p.Config, p.Data, p.Saves, p.Replays, p.Logs, p.Screenshots, p.Cache} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create user directory %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// ConfigFile returns the path of a file in the config directory
+func (p Paths) ConfigFile(name string) string {
+	return filepath.Join(p.Config, name)
+}
+
+// SaveFile returns the path of a file in the saves directory
+func (p Paths) SaveFile(name string) string {
+	return filepath.Join(p.Saves, name)
+}
+
+// ReplayFile returns the path of a file in the replays directory
+func (p Paths) ReplayFile(name string) string {
+	return filepath.Join(p.Replays, name)
+}
+
+// LogFile returns the path of a file in the logs directory
+func (p Paths) LogFile(name string) string {
+	return filepath.Join(p.Logs, name)
+}
+
+// ScreenshotFile returns the path of a file in the screenshots directory
+func (p Paths) ScreenshotFile(name string) string {
+	return filepath.Join(p.Screenshots, name)
+}
+
+// CacheDir returns the path of a directory in the cache directory
+func (p Paths) CacheDir(name string) string {
+	return filepath.Join(p.Cache, name)
+}
+
+// resolve computes the user directories for a platform (separated for testing)
+func resolve(goos string, getenv func(string) string, homeDir func() (string, error)) (Paths, error) {
+	if root := getenv(HomeEnvVar); root != "" {
+		return fromRoot(root), nil
+	}
+
+	home, err := homeDir()
+	if err != nil && goos != "windows" {
+		return Paths{}, fmt.Errorf("failed to determine home directory: %w", err)
+	}
+
+	switch goos {
+	case "windows":
+		// Roaming AppData for settings and saves, local AppData for logs
+		roaming := getenv("APPDATA")
+		if roaming == "" {
+			if home == "" {
+				return Paths{}, fmt.Errorf("neither APPDATA nor a home directory is available")
+			}
+			roaming = filepath.Join(home, "AppData", "Roaming")
+		}
+		local := getenv("LOCALAPPDATA")
+		if local == "" {
+			local = roaming
+		}
+
+		paths := fromRoot(filepath.Join(roaming, AppName))
+		paths.Logs = filepath.Join(local, AppName, "logs")
+		paths.Cache = filepath.Join(local, AppName, "cache")
+		return paths, nil
+
+	case "darwin":
+		paths := fromRoot(filepath.Join(home, "Library", "Application Support", AppName))
+		paths.Logs = filepath.Join(home, "Library", "Logs", AppName)
+		paths.Cache = filepath.Join(home, "Library", "Caches", AppName)
+		return paths, nil
+
+	default:
+		// XDG Base Directory specification (Linux and other Unix systems)
+		configHome := getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		dataHome := getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		stateHome := getenv("XDG_STATE_HOME")
+		if stateHome == "" {
+			stateHome = filepath.Join(home, ".local", "state")
+		}
+		cacheHome := getenv("XDG_CACHE_HOME")
+		if cacheHome == "" {
+			cacheHome = filepath.Join(home, ".cache")
+		}
+
+		paths := fromRoot(filepath.Join(dataHome, AppName))
+		paths.Config = filepath.Join(configHome, AppName)
+		paths.Logs = filepath.Join(stateHome, AppName, "logs")
+		paths.Cache = filepath.Join(cacheHome, AppName)
+		return paths, nil
+	}
+}
+
+// fromRoot lays out every user directory under a single root
+func fromRoot(root string) Paths {
+	return Paths{
+		Config:      filepath.Join(root, "config"),
+		Data:        root,
+		Saves:       filepath.Join(root, "saves"),
+		Replays:     filepath.Join(root, "replays"),
+		Logs:        filepath.Join(root, "logs"),
+		Screenshots: filepath.Join(root, "screenshots"),
+		Cache:       filepath.Join(root, "cache"),
+	}
+}
@@ -0,0 +1,100 @@
+package userdata
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// fakeEnv returns a getenv function backed by a map
+func fakeEnv(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+// fakeHome returns a home directory function with a fixed result
+func fakeHome(home string) func() (string, error) {
+	return func() (string, error) {
+		if home == "" {
+			return "", fmt.Errorf("no home")
+		}
+		return home, nil
+	}
+}
+
+func TestResolveLinuxDefaults(t *testing.T) {
+	paths, err := resolve("linux", fakeEnv(nil), fakeHome("/home/player"))
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"config": filepath.Join("/home/player", ".config", AppName),
+		"saves":  filepath.Join("/home/player", ".local", "share", AppName, "saves"),
+		"logs":   filepath.Join("/home/player", ".local", "state", AppName, "logs"),
+		"cache":  filepath.Join("/home/player", ".cache", AppName),
+	}
+	actual := map[string]string{"config": paths.Config, "saves": paths.Saves, "logs": paths.Logs, "cache": paths.Cache}
+	for key, want := range expected {
+		if actual[key] != want {
+			t.Errorf("Expected %s dir %s, got %s", key, want, actual[key])
+		}
+	}
+}
+
+func TestResolveLinuxXDGOverrides(t *testing.T) {
+	env := fakeEnv(map[string]string{
+		"XDG_CONFIG_HOME": "/cfg",
+		"XDG_DATA_HOME":   "/data",
+	})
+	paths, err := resolve("linux", env, fakeHome("/home/player"))
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+
+	if paths.Config != filepath.Join("/cfg", AppName) {
+		t.Errorf("Expected XDG config dir, got %s", paths.Config)
+	}
+	if paths.Replays != filepath.Join("/data", AppName, "replays") {
+		t.Errorf("Expected XDG data dir for replays, got %s", paths.Replays)
+	}
+}
+
+func TestResolveWindowsAndMac(t *testing.T) {
+	env := fakeEnv(map[string]string{"APPDATA": `C:\Users\p\AppData\Roaming`})
+	paths, err := resolve("windows", env, fakeHome(""))
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if paths.Saves != filepath.Join(`C:\Users\p\AppData\Roaming`, AppName, "saves") {
+		t.Errorf("Unexpected Windows saves dir: %s", paths.Saves)
+	}
+
+	if _, err := resolve("windows", fakeEnv(nil), fakeHome("")); err == nil {
+		t.Error("Expected error without APPDATA or home directory")
+	}
+
+	paths, err = resolve("darwin", fakeEnv(nil), fakeHome("/Users/p"))
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if paths.Config != filepath.Join("/Users/p", "Library", "Application Support", AppName, "config") {
+		t.Errorf("Unexpected macOS config dir: %s", paths.Config)
+	}
+}
+
+func TestResolveHomeOverrideAndEnsure(t *testing.T) {
+	root := t.TempDir()
+	paths, err := resolve("linux", fakeEnv(map[string]string{HomeEnvVar: root}), fakeHome(""))
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if paths.SaveFile("a.json") != filepath.Join(root, "saves", "a.json") {
+		t.Errorf("Unexpected save file path: %s", paths.SaveFile("a.json"))
+	}
+
+	if err := paths.Ensure(); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+}
@@ -89,6 +89,50 @@ type G3DModel struct {
 	Meshes      []G3DMesh
 }
 
+// G3D v4 mesh property flags
+const (
+	MeshCustomColor = 1 << 0 // mpfCustomColor: tinted with the player color
+	MeshTwoSided    = 1 << 1 // mpfTwoSided: no back face culling
+	MeshNoSelect    = 1 << 2 // mpfNoSelect: ignored by selection
+	MeshGlow        = 1 << 3 // mpfGlow: drawn unlit
+)
+
+// G3D v3 mesh property flags, translated to the v4 flags on load
+const (
+	meshV3NoTexture   = 1 << 0
+	meshV3TwoSided    = 1 << 1
+	meshV3CustomColor = 1 << 2
+)
+
+// TextureDiffuse is the texture flag of the diffuse map, the only texture of v2 and v3 meshes
+const TextureDiffuse = 1 << 0
+
+// g3dMeshHeaderV3 is the mesh header of G3D version 3
+type g3dMeshHeaderV3 struct {
+	VertexFrameCount   uint32
+	NormalFrameCount   uint32
+	TexCoordFrameCount uint32
+	ColorFrameCount    uint32
+	PointCount         uint32
+	IndexCount         uint32
+	Properties         uint32
+	TexName            [MapPathSize]byte
+}
+
+// g3dMeshHeaderV2 is the mesh header of G3D version 2
+type g3dMeshHeaderV2 struct {
+	VertexFrameCount   uint32
+	NormalFrameCount   uint32
+	TexCoordFrameCount uint32
+	ColorFrameCount    uint32
+	PointCount         uint32
+	IndexCount         uint32
+	HasTexture         uint8
+	Primitive          uint8 // 0 = triangles, the only primitive MegaGlest supports
+	CullFace           uint8
+	TexName            [MapPathSize]byte
+}
+
 // LoadG3D loads and parses a G3D model file with COMPLETE vertex data parsing
 func LoadG3D(filepath string) (*G3DModel, error) {
 	// Open the file
@@ -103,8 +147,15 @@ func LoadG3D(filepath string) (*G3DModel, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read G3D file: %w", err)
 	}
+	return ParseG3D(data)
+}
 
-	if len(data) < 7 { // Minimum size for headers
+// ParseG3D parses G3D model data of version 2, 3 or 4. Meshes of the older
+// versions are normalized to the v4 layout: their header is filled in from the
+// legacy fields, properties are translated to the v4 flags and the single
+// texture becomes the diffuse texture.
+func ParseG3D(data []byte) (*G3DModel, error) {
+	if len(data) < 6 { // File header and mesh count
 		return nil, fmt.Errorf("G3D file too small: %d bytes", len(data))
 	}
 
@@ -112,7 +163,7 @@ func LoadG3D(filepath string) (*G3DModel, error) {
 	model := &G3DModel{}
 
 	// Read file header
-	err = binary.Read(reader, binary.LittleEndian, &model.FileHeader)
+	err := binary.Read(reader, binary.LittleEndian, &model.FileHeader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read G3D file header: %w", err)
 	}
@@ -122,13 +173,21 @@ func LoadG3D(filepath string) (*G3DModel, error) {
 		return nil, fmt.Errorf("invalid G3D file: expected 'G3D', got '%s'", string(model.FileHeader.ID[:]))
 	}
 
-	// Check version support
-	if model.FileHeader.Version < 2 || model.FileHeader.Version > 4 {
+	// Pick the mesh reader of the version; only v4 has a mesh type in the model header
+	var readMesh func(*bytes.Reader) (*G3DMesh, error)
+	switch model.FileHeader.Version {
+	case G3DVersion4:
+		readMesh = readG3DMeshV4
+		err = binary.Read(reader, binary.LittleEndian, &model.ModelHeader)
+	case G3DVersion3:
+		readMesh = readG3DMeshV3
+		err = binary.Read(reader, binary.LittleEndian, &model.ModelHeader.MeshCount)
+	case G3DVersion2:
+		readMesh = readG3DMeshV2
+		err = binary.Read(reader, binary.LittleEndian, &model.ModelHeader.MeshCount)
+	default:
 		return nil, fmt.Errorf("unsupported G3D version: %d", model.FileHeader.Version)
 	}
-
-	// Read model header
-	err = binary.Read(reader, binary.LittleEndian, &model.ModelHeader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read G3D model header: %w", err)
 	}
@@ -138,7 +197,7 @@ func LoadG3D(filepath string) (*G3DModel, error) {
 
 	// Read each mesh with COMPLETE data including vertex arrays
 	for i := 0; i < int(model.ModelHeader.MeshCount); i++ {
-		mesh, err := readG3DMeshComplete(reader, model.FileHeader.Version)
+		mesh, err := readMesh(reader)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read mesh %d: %w", i, err)
 		}
@@ -148,8 +207,8 @@ func LoadG3D(filepath string) (*G3DModel, error) {
 	return model, nil
 }
 
-// readG3DMeshComplete reads a complete mesh including ALL vertex data
-func readG3DMeshComplete(reader *bytes.Reader, version uint8) (*G3DMesh, error) {
+// readG3DMeshV4 reads a complete v4 mesh including ALL vertex data
+func readG3DMeshV4(reader *bytes.Reader) (*G3DMesh, error) {
 	mesh := &G3DMesh{}
 
 	// Read mesh header
@@ -161,75 +220,186 @@ func readG3DMeshComplete(reader *bytes.Reader, version uint8) (*G3DMesh, error)
 	// Parse name from header
 	mesh.Name = string(bytes.TrimRight(mesh.Header.Name[:], "\x00"))
 
-	// Parse property flags based on MegaGlest implementation
-	mesh.TwoSided = (mesh.Header.Properties & (1 << 0)) != 0    // mpfTwoSided
-	mesh.CustomColor = (mesh.Header.Properties & (1 << 1)) != 0 // mpfCustomColor
-	mesh.NoSelect = (mesh.Header.Properties & (1 << 2)) != 0    // mpfNoSelect
-	mesh.Glow = (mesh.Header.Properties & (1 << 3)) != 0        // mpfGlow
-
 	// Read texture names if textures are present
 	textureFlag := uint32(1)
 	maxTextures := 8 // meshTextureCount from MegaGlest source
 	for i := 0; i < maxTextures; i++ {
-		if mesh.Header.Textures & textureFlag != 0 {
-			// Read texture path
-			texturePath := make([]byte, MapPathSize)
-			_, err := reader.Read(texturePath)
+		if mesh.Header.Textures&textureFlag != 0 {
+			texture, err := readG3DPath(reader)
 			if err != nil {
 				return nil, fmt.Errorf("failed to read texture path %d: %w", i, err)
 			}
-
-			// Convert to string and store
-			textureStr := string(bytes.TrimRight(texturePath, "\x00"))
-			mesh.TextureNames = append(mesh.TextureNames, textureStr)
+			mesh.TextureNames = append(mesh.TextureNames, texture)
 		}
 		textureFlag <<= 1
 	}
 
-	// NOW READ THE ACTUAL VERTEX DATA - This was the critical missing piece!
-
 	frameCount := mesh.Header.FrameCount
+	if err := mesh.readGeometry(reader, frameCount, frameCount, mesh.Header.Textures != 0); err != nil {
+		return nil, err
+	}
+	if err := mesh.readIndices(reader); err != nil {
+		return nil, err
+	}
+
+	mesh.setFlags()
+	return mesh, nil
+}
+
+// readG3DMeshV3 reads a v3 mesh: frame counts per attribute, one optional
+// texture and a color table after the texture coordinates
+func readG3DMeshV3(reader *bytes.Reader) (*G3DMesh, error) {
+	var header g3dMeshHeaderV3
+	if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("failed to read mesh header: %w", err)
+	}
+
+	mesh := &G3DMesh{}
+	mesh.Header.FrameCount = header.VertexFrameCount
+	mesh.Header.VertexCount = header.PointCount
+	mesh.Header.IndexCount = header.IndexCount
+	if header.Properties&meshV3TwoSided != 0 {
+		mesh.Header.Properties |= MeshTwoSided
+	}
+	if header.Properties&meshV3CustomColor != 0 {
+		mesh.Header.Properties |= MeshCustomColor
+	}
+	textured := header.Properties&meshV3NoTexture == 0
+	if textured {
+		mesh.Header.Textures = TextureDiffuse
+		mesh.TextureNames = []string{string(bytes.TrimRight(header.TexName[:], "\x00"))}
+	}
+
+	if err := mesh.readGeometry(reader, header.VertexFrameCount, header.NormalFrameCount, textured); err != nil {
+		return nil, err
+	}
+	if err := mesh.readColors(reader, header.ColorFrameCount); err != nil {
+		return nil, err
+	}
+	if err := mesh.readIndices(reader); err != nil {
+		return nil, err
+	}
+
+	mesh.setFlags()
+	return mesh, nil
+}
+
+// readG3DMeshV2 reads a v2 mesh: like v3 but without properties, a texture
+// flag byte and back face culling instead of a two-sided flag
+func readG3DMeshV2(reader *bytes.Reader) (*G3DMesh, error) {
+	var header g3dMeshHeaderV2
+	if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("failed to read mesh header: %w", err)
+	}
+	if header.Primitive != 0 {
+		return nil, fmt.Errorf("unsupported primitive type %d, only triangles are supported", header.Primitive)
+	}
+
+	mesh := &G3DMesh{}
+	mesh.Header.FrameCount = header.VertexFrameCount
+	mesh.Header.VertexCount = header.PointCount
+	mesh.Header.IndexCount = header.IndexCount
+	if header.CullFace == 0 {
+		mesh.Header.Properties |= MeshTwoSided
+	}
+	textured := header.HasTexture != 0
+	if textured {
+		mesh.Header.Textures = TextureDiffuse
+		mesh.TextureNames = []string{string(bytes.TrimRight(header.TexName[:], "\x00"))}
+	}
+
+	if err := mesh.readGeometry(reader, header.VertexFrameCount, header.NormalFrameCount, textured); err != nil {
+		return nil, err
+	}
+	if err := mesh.readColors(reader, header.ColorFrameCount); err != nil {
+		return nil, err
+	}
+	if err := mesh.readIndices(reader); err != nil {
+		return nil, err
+	}
+
+	mesh.setFlags()
+	return mesh, nil
+}
+
+// readG3DPath reads a fixed size, null padded path
+func readG3DPath(reader *bytes.Reader) (string, error) {
+	path := make([]byte, MapPathSize)
+	if _, err := io.ReadFull(reader, path); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(path, "\x00")), nil
+}
+
+// readGeometry reads the vertex and normal frames and, for textured meshes,
+// one frame of texture coordinates
+func (mesh *G3DMesh) readGeometry(reader *bytes.Reader, vertexFrames, normalFrames uint32, textured bool) error {
 	vertexCount := mesh.Header.VertexCount
-	indexCount := mesh.Header.IndexCount
 
 	// Read vertices (frameCount * vertexCount) - positions for all animation frames
-	totalVertices := frameCount * vertexCount
-	if totalVertices > 0 {
-		mesh.Vertices = make([]Vec3f, totalVertices)
-		err = binary.Read(reader, binary.LittleEndian, mesh.Vertices)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read vertices: %w", err)
+	if total := vertexFrames * vertexCount; total > 0 {
+		mesh.Vertices = make([]Vec3f, total)
+		if err := binary.Read(reader, binary.LittleEndian, mesh.Vertices); err != nil {
+			return fmt.Errorf("failed to read vertices: %w", err)
 		}
 	}
 
 	// Read normals (frameCount * vertexCount) - normals for all animation frames
-	if totalVertices > 0 {
-		mesh.Normals = make([]Vec3f, totalVertices)
-		err = binary.Read(reader, binary.LittleEndian, mesh.Normals)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read normals: %w", err)
+	if total := normalFrames * vertexCount; total > 0 {
+		mesh.Normals = make([]Vec3f, total)
+		if err := binary.Read(reader, binary.LittleEndian, mesh.Normals); err != nil {
+			return fmt.Errorf("failed to read normals: %w", err)
 		}
 	}
 
 	// Read texture coordinates (vertexCount) - only if textures are present
-	if mesh.Header.Textures != 0 && vertexCount > 0 {
+	if textured && vertexCount > 0 {
 		mesh.TexCoords = make([]Vec2f, vertexCount)
-		err = binary.Read(reader, binary.LittleEndian, mesh.TexCoords)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read texture coordinates: %w", err)
+		if err := binary.Read(reader, binary.LittleEndian, mesh.TexCoords); err != nil {
+			return fmt.Errorf("failed to read texture coordinates: %w", err)
 		}
 	}
+	return nil
+}
 
-	// Read indices (indexCount) - triangle indices for rendering
-	if indexCount > 0 {
-		mesh.Indices = make([]uint32, indexCount)
-		err = binary.Read(reader, binary.LittleEndian, mesh.Indices)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read indices: %w", err)
-		}
+// readColors reads the color table of v2 and v3 meshes; the first color
+// becomes the diffuse color and opacity, the other frames are skipped
+func (mesh *G3DMesh) readColors(reader *bytes.Reader, frames uint32) error {
+	if frames == 0 {
+		mesh.Header.DiffuseColor = [3]float32{1, 1, 1}
+		mesh.Header.Opacity = 1
+		return nil
 	}
+	var color [4]float32
+	if err := binary.Read(reader, binary.LittleEndian, &color); err != nil {
+		return fmt.Errorf("failed to read mesh color: %w", err)
+	}
+	mesh.Header.DiffuseColor = [3]float32{color[0], color[1], color[2]}
+	mesh.Header.Opacity = color[3]
+	if _, err := reader.Seek(int64(frames-1)*16, io.SeekCurrent); err != nil {
+		return fmt.Errorf("failed to skip color frames: %w", err)
+	}
+	return nil
+}
 
-	return mesh, nil
+// readIndices reads the triangle indices
+func (mesh *G3DMesh) readIndices(reader *bytes.Reader) error {
+	if mesh.Header.IndexCount == 0 {
+		return nil
+	}
+	mesh.Indices = make([]uint32, mesh.Header.IndexCount)
+	if err := binary.Read(reader, binary.LittleEndian, mesh.Indices); err != nil {
+		return fmt.Errorf("failed to read indices: %w", err)
+	}
+	return nil
+}
+
+// setFlags derives the boolean flags from the v4 property bits
+func (mesh *G3DMesh) setFlags() {
+	mesh.TwoSided = mesh.Header.Properties&MeshTwoSided != 0
+	mesh.CustomColor = mesh.Header.Properties&MeshCustomColor != 0
+	mesh.NoSelect = mesh.Header.Properties&MeshNoSelect != 0
+	mesh.Glow = mesh.Header.Properties&MeshGlow != 0
 }
 
 // Helper methods for the complete model
@@ -1,6 +1,10 @@
 package formats
 
 import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -167,4 +171,228 @@ func TestG3DModelUtilityFunctions(t *testing.T) {
 	if model.HasTextures() != expectedHasTextures {
 		t.Errorf("HasTextures(): expected %t, got %t", expectedHasTextures, model.HasTextures())
 	}
-}
\ No newline at end of file
+}
+
+// g3dSample holds the geometry shared by the sample files of every version:
+// one triangle with two animation frames
+var g3dSample = struct {
+	vertices  []Vec3f
+	normals   []Vec3f
+	texCoords []Vec2f
+	indices   []uint32
+}{
+	vertices: []Vec3f{
+		{0, 0, 0}, {1, 0, 0}, {0, 1, 0},
+		{0, 0, 1}, {1, 0, 1}, {0, 1, 1},
+	},
+	normals: []Vec3f{
+		{0, 0, 1}, {0, 0, 1}, {0, 0, 1},
+		{0, 0, 1}, {0, 0, 1}, {0, 0, 1},
+	},
+	texCoords: []Vec2f{{0, 0}, {1, 0}, {0, 1}},
+	indices:   []uint32{0, 1, 2},
+}
+
+// g3dPath returns a fixed size path field
+func g3dPath(name string) [MapPathSize]byte {
+	var path [MapPathSize]byte
+	copy(path[:], name)
+	return path
+}
+
+// writeG3DSample writes the parts of a sample file to a temporary file and returns its path
+func writeG3DSample(t *testing.T, version uint8, parts ...interface{}) string {
+	t.Helper()
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, G3DFileHeader{ID: [3]byte{'G', '3', 'D'}, Version: version})
+	for _, part := range parts {
+		if err := binary.Write(&buf, binary.LittleEndian, part); err != nil {
+			t.Fatalf("Failed to encode sample: %v", err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "sample.g3d")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write sample: %v", err)
+	}
+	return path
+}
+
+// checkG3DSample verifies the geometry of a loaded sample
+func checkG3DSample(t *testing.T, model *G3DModel, textured bool) *G3DMesh {
+	t.Helper()
+	if len(model.Meshes) != 1 {
+		t.Fatalf("Expected 1 mesh, got %d", len(model.Meshes))
+	}
+	mesh := &model.Meshes[0]
+	if mesh.Header.FrameCount != 2 || mesh.Header.VertexCount != 3 || mesh.Header.IndexCount != 3 {
+		t.Errorf("Expected 2 frames, 3 vertices, 3 indices, got %d, %d, %d",
+			mesh.Header.FrameCount, mesh.Header.VertexCount, mesh.Header.IndexCount)
+	}
+	if len(mesh.Vertices) != 6 || mesh.Vertices[4] != (Vec3f{1, 0, 1}) {
+		t.Errorf("Vertices not loaded correctly: %v", mesh.Vertices)
+	}
+	if len(mesh.Normals) != 6 {
+		t.Errorf("Expected 6 normals, got %d", len(mesh.Normals))
+	}
+	if textured && (len(mesh.TexCoords) != 3 || mesh.TexCoords[1] != (Vec2f{1, 0})) {
+		t.Errorf("Texture coordinates not loaded correctly: %v", mesh.TexCoords)
+	}
+	if !textured && len(mesh.TexCoords) != 0 {
+		t.Errorf("Expected no texture coordinates, got %d", len(mesh.TexCoords))
+	}
+	if len(mesh.Indices) != 3 || mesh.Indices[2] != 2 {
+		t.Errorf("Indices not loaded correctly: %v", mesh.Indices)
+	}
+	return mesh
+}
+
+func TestLoadG3DVersion4Sample(t *testing.T) {
+	header := G3DMeshHeader{
+		FrameCount:   2,
+		VertexCount:  3,
+		IndexCount:   3,
+		DiffuseColor: [3]float32{0.5, 0.5, 0.5},
+		Opacity:      1,
+		Properties:   MeshCustomColor | MeshNoSelect,
+		Textures:     TextureDiffuse | 1<<2, // Diffuse and normal map
+	}
+	copy(header.Name[:], "body")
+	path := writeG3DSample(t, G3DVersion4,
+		G3DModelHeader{MeshCount: 1, Type: MorphMesh}, header,
+		g3dPath("body.tga"), g3dPath("body_normal.tga"),
+		g3dSample.vertices, g3dSample.normals, g3dSample.texCoords, g3dSample.indices)
+
+	model, err := LoadG3D(path)
+	if err != nil {
+		t.Fatalf("Failed to load v4 sample: %v", err)
+	}
+	mesh := checkG3DSample(t, model, true)
+	if mesh.Name != "body" {
+		t.Errorf("Expected mesh name 'body', got '%s'", mesh.Name)
+	}
+	if len(mesh.TextureNames) != 2 || mesh.TextureNames[0] != "body.tga" || mesh.TextureNames[1] != "body_normal.tga" {
+		t.Errorf("Unexpected texture names: %v", mesh.TextureNames)
+	}
+	if !mesh.CustomColor || !mesh.NoSelect || mesh.TwoSided || mesh.Glow {
+		t.Errorf("Unexpected flags: custom color %t, no select %t, two-sided %t, glow %t",
+			mesh.CustomColor, mesh.NoSelect, mesh.TwoSided, mesh.Glow)
+	}
+}
+
+func TestLoadG3DVersion3Sample(t *testing.T) {
+	header := g3dMeshHeaderV3{
+		VertexFrameCount:   2,
+		NormalFrameCount:   2,
+		TexCoordFrameCount: 1,
+		ColorFrameCount:    2,
+		PointCount:         3,
+		IndexCount:         3,
+		Properties:         meshV3TwoSided | meshV3CustomColor,
+		TexName:            g3dPath("skin.bmp"),
+	}
+	colors := [][4]float32{{0.2, 0.4, 0.6, 0.8}, {1, 1, 1, 1}}
+	path := writeG3DSample(t, G3DVersion3, uint16(1), header,
+		g3dSample.vertices, g3dSample.normals, g3dSample.texCoords, colors, g3dSample.indices)
+
+	model, err := LoadG3D(path)
+	if err != nil {
+		t.Fatalf("Failed to load v3 sample: %v", err)
+	}
+	if model.ModelHeader.MeshCount != 1 || model.ModelHeader.Type != MorphMesh {
+		t.Errorf("Unexpected model header: %+v", model.ModelHeader)
+	}
+	mesh := checkG3DSample(t, model, true)
+	if mesh.Header.Textures != TextureDiffuse || len(mesh.TextureNames) != 1 || mesh.TextureNames[0] != "skin.bmp" {
+		t.Errorf("Expected diffuse texture skin.bmp, got flags %d and %v", mesh.Header.Textures, mesh.TextureNames)
+	}
+	if !mesh.TwoSided || !mesh.CustomColor {
+		t.Errorf("Expected two-sided custom color mesh, got two-sided %t, custom color %t", mesh.TwoSided, mesh.CustomColor)
+	}
+	if mesh.Header.DiffuseColor != [3]float32{0.2, 0.4, 0.6} || mesh.Header.Opacity != 0.8 {
+		t.Errorf("Expected color from the first color frame, got %v opacity %v", mesh.Header.DiffuseColor, mesh.Header.Opacity)
+	}
+}
+
+func TestLoadG3DVersion3Untextured(t *testing.T) {
+	header := g3dMeshHeaderV3{
+		VertexFrameCount: 2,
+		NormalFrameCount: 2,
+		ColorFrameCount:  1,
+		PointCount:       3,
+		IndexCount:       3,
+		Properties:       meshV3NoTexture,
+	}
+	path := writeG3DSample(t, G3DVersion3, uint16(1), header,
+		g3dSample.vertices, g3dSample.normals, [4]float32{1, 0, 0, 1}, g3dSample.indices)
+
+	model, err := LoadG3D(path)
+	if err != nil {
+		t.Fatalf("Failed to load v3 sample: %v", err)
+	}
+	mesh := checkG3DSample(t, model, false)
+	if model.HasTextures() || len(mesh.TextureNames) != 0 {
+		t.Errorf("Expected no textures, got %v", mesh.TextureNames)
+	}
+	if mesh.TwoSided || mesh.CustomColor {
+		t.Errorf("Expected no flags, got two-sided %t, custom color %t", mesh.TwoSided, mesh.CustomColor)
+	}
+}
+
+func TestLoadG3DVersion2Sample(t *testing.T) {
+	header := g3dMeshHeaderV2{
+		VertexFrameCount:   2,
+		NormalFrameCount:   2,
+		TexCoordFrameCount: 1,
+		ColorFrameCount:    1,
+		PointCount:         3,
+		IndexCount:         3,
+		HasTexture:         1,
+		CullFace:           0,
+		TexName:            g3dPath("old.tga"),
+	}
+	path := writeG3DSample(t, G3DVersion2, uint16(1), header,
+		g3dSample.vertices, g3dSample.normals, g3dSample.texCoords, [4]float32{1, 1, 1, 0.5}, g3dSample.indices)
+
+	model, err := LoadG3D(path)
+	if err != nil {
+		t.Fatalf("Failed to load v2 sample: %v", err)
+	}
+	if model.FileHeader.Version != G3DVersion2 {
+		t.Errorf("Expected version 2, got %d", model.FileHeader.Version)
+	}
+	mesh := checkG3DSample(t, model, true)
+	if len(mesh.TextureNames) != 1 || mesh.TextureNames[0] != "old.tga" {
+		t.Errorf("Expected texture old.tga, got %v", mesh.TextureNames)
+	}
+	if !mesh.TwoSided {
+		t.Error("Expected a mesh without face culling to be two-sided")
+	}
+	if mesh.Header.Opacity != 0.5 {
+		t.Errorf("Expected opacity 0.5, got %v", mesh.Header.Opacity)
+	}
+}
+
+func TestParseG3DRejectsUnsupported(t *testing.T) {
+	if _, err := ParseG3D([]byte{'G', '3', 'D', 5, 0, 0}); err == nil {
+		t.Error("Expected an error for version 5")
+	}
+	if _, err := ParseG3D([]byte{'X', 'Y', 'Z', 4, 0, 0, 0}); err == nil {
+		t.Error("Expected an error for a bad file ID")
+	}
+
+	// A v2 mesh must consist of triangles
+	header := g3dMeshHeaderV2{VertexFrameCount: 1, PointCount: 3, IndexCount: 3, Primitive: 1}
+	path := writeG3DSample(t, G3DVersion2, uint16(1), header)
+	if _, err := LoadG3D(path); err == nil {
+		t.Error("Expected an error for a non-triangle v2 mesh")
+	}
+
+	// Truncated data must fail instead of returning a partial mesh
+	data, err := os.ReadFile(writeG3DSample(t, G3DVersion3, uint16(1), g3dMeshHeaderV3{VertexFrameCount: 1, PointCount: 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseG3D(data); err == nil {
+		t.Error("Expected an error for truncated vertex data")
+	}
+}